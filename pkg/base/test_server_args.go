@@ -17,6 +17,7 @@ package base
 import (
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
@@ -72,6 +73,9 @@ type TestServerArgs struct {
 	// ExternalIODir is used to initialize field in cluster.Settings.
 	ExternalIODir string
 
+	// Locality is the locality of the test server.
+	Locality roachpb.Locality
+
 	// Fields copied to the server.Config.
 	Insecure                 bool
 	RetryOptions             retry.Options
@@ -15,6 +15,9 @@ import (
 	// ccl init hooks
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/buildccl"
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/cliccl"
+	_ "github.com/cockroachdb/cockroach/pkg/ccl/gssapiccl"
+	_ "github.com/cockroachdb/cockroach/pkg/ccl/jwtauthccl"
+	_ "github.com/cockroachdb/cockroach/pkg/ccl/ldapccl"
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/sqlccl"
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl"
@@ -0,0 +1,146 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+// +build gss
+
+package gssapiccl
+
+// #cgo LDFLAGS: -lgssapi_krb5 -lkrb5 -lk5crypto -lkrb5support -lcom_err
+//
+// #include <gssapi/gssapi.h>
+// #include <stdlib.h>
+import "C"
+
+import (
+	"crypto/tls"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+)
+
+// This file implements the `gss` host-based authentication method, which
+// authenticates clients with Kerberos through the GSSAPI. The server's keys
+// are read from the keytab named by the KRB5_KTNAME environment variable.
+// The method takes the same options as in Postgres:
+//
+//   include_realm  whether the realm is kept in the system identity of the
+//                  principal (1, the default) or stripped from it (0)
+//   krb_realm      the realm principals must belong to
+//   map            the identity map mapping principals to SQL users
+
+const (
+	authTypeGSS         = 7
+	authTypeGSSContinue = 8
+)
+
+func init() {
+	pgwire.RegisterAuthMethod("gss", authGSS, "include_realm", "krb_realm")
+}
+
+// authGSS implements pgwire.AuthMethod.
+func authGSS(
+	ctx context.Context,
+	c pgwire.AuthConn,
+	_ tls.ConnectionState,
+	execCfg *sql.ExecutorConfig,
+	options map[string]string,
+) (string, error) {
+	if err := utilccl.CheckEnterpriseEnabled(
+		execCfg.Settings, execCfg.ClusterID(), execCfg.Organization(), "GSS authentication",
+	); err != nil {
+		return "", err
+	}
+
+	var (
+		majStat, minStat, lminS C.OM_uint32
+		contextHandle           C.gss_ctx_id_t // GSS_C_NO_CONTEXT
+		srcName                 C.gss_name_t
+		outputToken             C.gss_buffer_desc
+	)
+	defer func() {
+		if srcName != nil {
+			C.gss_release_name(&lminS, &srcName)
+		}
+		if contextHandle != nil {
+			C.gss_delete_sec_context(&lminS, &contextHandle, nil /* GSS_C_NO_BUFFER */)
+		}
+	}()
+
+	if err := c.SendAuthRequest(authTypeGSS, nil); err != nil {
+		return "", err
+	}
+	// The client sends tokens until the security context is established,
+	// each of which may call for an output token in return.
+	for {
+		token, err := c.GetPwdData()
+		if err != nil {
+			return "", err
+		}
+		var inputToken C.gss_buffer_desc
+		inputToken.length = C.size_t(len(token))
+		inputToken.value = C.CBytes(token)
+		majStat = C.gss_accept_sec_context(
+			&minStat,
+			&contextHandle,
+			nil, /* GSS_C_NO_CREDENTIAL */
+			&inputToken,
+			nil, /* GSS_C_NO_CHANNEL_BINDINGS */
+			&srcName,
+			nil, /* mech_type */
+			&outputToken,
+			nil, /* ret_flags */
+			nil, /* time_rec */
+			nil, /* delegated_cred_handle */
+		)
+		C.free(inputToken.value)
+
+		if outputToken.length != 0 {
+			out := C.GoBytes(outputToken.value, C.int(outputToken.length))
+			C.gss_release_buffer(&lminS, &outputToken)
+			if err := c.SendAuthRequest(authTypeGSSContinue, out); err != nil {
+				return "", err
+			}
+		}
+		if majStat != C.GSS_S_COMPLETE && majStat != C.GSS_S_CONTINUE_NEEDED {
+			return "", gssError("accepting GSS security context failed", majStat, minStat)
+		}
+		if majStat != C.GSS_S_CONTINUE_NEEDED {
+			break
+		}
+	}
+
+	var nameBuf C.gss_buffer_desc
+	majStat = C.gss_display_name(&minStat, srcName, &nameBuf, nil /* output_name_type */)
+	if majStat != C.GSS_S_COMPLETE {
+		return "", gssError("retrieving GSS user name failed", majStat, minStat)
+	}
+	principal := C.GoStringN((*C.char)(nameBuf.value), C.int(nameBuf.length))
+	C.gss_release_buffer(&lminS, &nameBuf)
+
+	return principalIdentity(principal, options)
+}
+
+// gssError returns an error with msg and the descriptions of the GSSAPI
+// status codes.
+func gssError(msg string, majStat, minStat C.OM_uint32) error {
+	return errors.Errorf("%s: %s: %s", msg,
+		gssStatusString(majStat, C.GSS_C_GSS_CODE), gssStatusString(minStat, C.GSS_C_MECH_CODE))
+}
+
+func gssStatusString(stat C.OM_uint32, statType C.int) string {
+	var lminS, msgCtx C.OM_uint32
+	var buf C.gss_buffer_desc
+	C.gss_display_status(&lminS, stat, statType, nil /* GSS_C_NO_OID */, &msgCtx, &buf)
+	s := C.GoStringN((*C.char)(buf.value), C.int(buf.length))
+	C.gss_release_buffer(&lminS, &buf)
+	return s
+}
@@ -0,0 +1,37 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package gssapiccl
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// principalIdentity returns the system identity of an authenticated Kerberos
+// principal given the options of the host-based authentication entry: the
+// principal must belong to krb_realm, if set, and loses its realm when
+// include_realm is 0.
+func principalIdentity(principal string, options map[string]string) (string, error) {
+	name, realm := principal, ""
+	if pos := strings.LastIndexByte(principal, '@'); pos >= 0 {
+		name, realm = principal[:pos], principal[pos+1:]
+	}
+	if krbRealm, ok := options["krb_realm"]; ok && realm != krbRealm {
+		return "", errors.Errorf("principal %q is not in realm %q", principal, krbRealm)
+	}
+	switch includeRealm := options["include_realm"]; includeRealm {
+	case "", "1":
+		return principal, nil
+	case "0":
+		return name, nil
+	default:
+		return "", errors.Errorf("invalid include_realm value %q", includeRealm)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package gssapiccl
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func TestPrincipalIdentity(t *testing.T) {
+	testCases := []struct {
+		principal string
+		options   map[string]string
+		identity  string
+		err       string
+	}{
+		{"alice@EXAMPLE.COM", nil, "alice@EXAMPLE.COM", ``},
+		{"alice@EXAMPLE.COM", map[string]string{"include_realm": "1"}, "alice@EXAMPLE.COM", ``},
+		{"alice@EXAMPLE.COM", map[string]string{"include_realm": "0"}, "alice", ``},
+		{"svc/host@ORG@EXAMPLE.COM", map[string]string{"include_realm": "0"}, "svc/host@ORG", ``},
+		{"alice", map[string]string{"include_realm": "0"}, "alice", ``},
+		{"alice@EXAMPLE.COM", map[string]string{"krb_realm": "EXAMPLE.COM"}, "alice@EXAMPLE.COM", ``},
+		{"alice@OTHER.COM", map[string]string{"krb_realm": "EXAMPLE.COM"}, "",
+			`principal "alice@OTHER.COM" is not in realm "EXAMPLE.COM"`},
+		{"alice", map[string]string{"krb_realm": "EXAMPLE.COM"}, "",
+			`principal "alice" is not in realm "EXAMPLE.COM"`},
+		{"alice@EXAMPLE.COM", map[string]string{"include_realm": "yes"}, "",
+			`invalid include_realm value "yes"`},
+	}
+	for _, tc := range testCases {
+		identity, err := principalIdentity(tc.principal, tc.options)
+		if !testutils.IsError(err, tc.err) {
+			t.Errorf("%s %v: expected error %q, got %v", tc.principal, tc.options, tc.err, err)
+			continue
+		}
+		if identity != tc.identity {
+			t.Errorf("%s %v: expected %q, got %q", tc.principal, tc.options, tc.identity, identity)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package jwtauthccl
+
+import (
+	"crypto/tls"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// This file implements the `jwt` host-based authentication method, which
+// authenticates clients with a JSON Web Token, e.g. an OpenID Connect ID
+// token, sent in place of their password. The token must be signed by one of
+// the keys of server.jwt_authentication.jwks and issued by one of
+// server.jwt_authentication.issuers, and it must not have expired. Its
+// server.jwt_authentication.claim claim is the system identity of the client,
+// which is mapped to a SQL user by the identity map named by the `map` option
+// or must be the SQL user otherwise. The token must also be intended for
+// server.jwt_authentication.audience: as long as it is not set, no token is
+// accepted, lest the tokens issued to other services be replayed.
+
+var jwtIssuers = settings.RegisterStringSetting(
+	"server.jwt_authentication.issuers",
+	"the comma-separated list of the issuers (iss claims) of the tokens accepted by the jwt authentication method",
+	"",
+)
+
+var jwtAudience = settings.RegisterStringSetting(
+	"server.jwt_authentication.audience",
+	"the audience (aud claim) the tokens accepted by the jwt authentication method must be intended for; no token is accepted if unset",
+	"",
+)
+
+var jwtJWKS = settings.RegisterValidatedStringSetting(
+	"server.jwt_authentication.jwks",
+	"the JSON Web Key Set of the public keys trusted to sign the tokens accepted by the jwt authentication method",
+	`{"keys":[]}`,
+	func(s string) error {
+		_, err := parseJWKS(s)
+		return err
+	},
+)
+
+var jwtClaim = settings.RegisterStringSetting(
+	"server.jwt_authentication.claim",
+	"the claim of the tokens holding the identity of the user, mapped to a SQL user by the identity map",
+	"sub",
+)
+
+const authTypeCleartextPassword = 3
+
+func init() {
+	pgwire.RegisterAuthMethod("jwt", authJWT)
+}
+
+// authJWT implements pgwire.AuthMethod.
+func authJWT(
+	ctx context.Context,
+	c pgwire.AuthConn,
+	_ tls.ConnectionState,
+	execCfg *sql.ExecutorConfig,
+	_ map[string]string,
+) (string, error) {
+	if err := utilccl.CheckEnterpriseEnabled(
+		execCfg.Settings, execCfg.ClusterID(), execCfg.Organization(), "JWT authentication",
+	); err != nil {
+		return "", err
+	}
+	sv := &execCfg.Settings.SV
+	keys, err := parseJWKS(jwtJWKS.Get(sv))
+	if err != nil {
+		return "", err
+	}
+	cfg := tokenConfig{
+		audience: jwtAudience.Get(sv),
+		keys:     keys,
+		claim:    jwtClaim.Get(sv),
+	}
+	for _, iss := range strings.Split(jwtIssuers.Get(sv), ",") {
+		if iss = strings.TrimSpace(iss); iss != "" {
+			cfg.issuers = append(cfg.issuers, iss)
+		}
+	}
+
+	if err := c.SendAuthRequest(authTypeCleartextPassword, nil); err != nil {
+		return "", err
+	}
+	data, err := c.GetPwdData()
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSuffix(string(data), "\x00")
+	identity, err := verifyToken(token, cfg, timeutil.Now())
+	if err != nil {
+		return "", errors.Wrapf(err, "JWT authentication failed for user %s", c.User())
+	}
+	return identity, nil
+}
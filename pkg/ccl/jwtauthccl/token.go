@@ -0,0 +1,189 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package jwtauthccl
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	// The hash functions of the signature algorithms.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+
+	"github.com/pkg/errors"
+)
+
+// ecdsaCurves maps the ECDSA signature algorithms to the curve their keys
+// must be on.
+var ecdsaCurves = map[string]string{
+	"ES256": "P-256",
+	"ES384": "P-384",
+	"ES512": "P-521",
+}
+
+// signatureHashes maps the supported signature algorithms (RFC 7518) to their
+// hash function. The unsigned `none` and the HMAC algorithms, whose key would
+// have to be shared, are not supported.
+var signatureHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// clockSkew is the difference between the clocks of the issuers and of the
+// nodes that is tolerated when checking the validity period of tokens.
+const clockSkew = time.Minute
+
+// tokenConfig is the configuration the tokens are validated against.
+type tokenConfig struct {
+	// issuers are the accepted values of the `iss` claim.
+	issuers []string
+	// audience must be listed in the `aud` claim. No token is accepted if it
+	// is empty.
+	audience string
+	keys     jwkSet
+	// claim is the claim holding the identity of the user.
+	claim string
+}
+
+// tokenHeader is the JOSE header of a token.
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// verifyToken checks the signature and the claims of a JSON Web Token in
+// compact serialization and returns the identity it carries.
+func verifyToken(token string, cfg tokenConfig, now time.Time) (string, error) {
+	if cfg.audience == "" {
+		return "", errors.New("no audience configured in server.jwt_authentication.audience")
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("invalid token: malformed")
+	}
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return "", errors.Wrap(err, "invalid token header")
+	}
+	hash, ok := signatureHashes[header.Alg]
+	if !ok {
+		return "", errors.Errorf("invalid token: unsupported algorithm %q", header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errors.Wrap(err, "invalid token signature")
+	}
+	h := hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	digest := h.Sum(nil)
+	verified := false
+	for _, key := range cfg.keys.lookup(header.Kid, header.Alg) {
+		if verifySignature(key, header.Alg, hash, digest, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return "", errors.New("invalid token: signature not verified by any trusted key")
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", errors.Wrap(err, "invalid token claims")
+	}
+	iss, _ := claims["iss"].(string)
+	if !containsString(cfg.issuers, iss) {
+		return "", errors.Errorf("invalid token: untrusted issuer %q", iss)
+	}
+	if !containsString(stringOrList(claims["aud"]), cfg.audience) {
+		return "", errors.Errorf("invalid token: audience %q not listed", cfg.audience)
+	}
+	// Tokens must expire, since they stand for short-lived credentials.
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return "", errors.New("invalid token: missing expiration time")
+	}
+	if now.Add(-clockSkew).After(time.Unix(int64(exp), 0)) {
+		return "", errors.New("invalid token: expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return "", errors.New("invalid token: not valid yet")
+	}
+	identity, _ := claims[cfg.claim].(string)
+	if identity == "" {
+		return "", errors.Errorf("invalid token: missing %s claim", cfg.claim)
+	}
+	return identity, nil
+}
+
+func decodeSegment(s string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func verifySignature(
+	key crypto.PublicKey, alg string, hash crypto.Hash, digest, sig []byte,
+) bool {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return alg[0] == 'R' && rsa.VerifyPKCS1v15(key, hash, digest, sig) == nil
+	case *ecdsa.PublicKey:
+		// The signature is the concatenation of r and s, each padded to the
+		// size of the curve.
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if ecdsaCurves[alg] != key.Curve.Params().Name || len(sig) != 2*size {
+			return false
+		}
+		r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(key, digest, r, s)
+	default:
+		return false
+	}
+}
+
+// stringOrList returns the values of a claim holding a string or an array of
+// strings, like `aud`.
+func stringOrList(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var res []string
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	default:
+		return nil
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package jwtauthccl
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+// signToken returns a token with the given header and claims signed by key.
+func signToken(
+	t *testing.T, key crypto.Signer, header map[string]string, claims map[string]interface{},
+) string {
+	signed := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	hash := signatureHashes[header["alg"]]
+	if hash == 0 {
+		return signed + "."
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+	var sig []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest); err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		sig = make([]byte, 2*size)
+		rb, sb := r.Bytes(), s.Bytes()
+		copy(sig[size-len(rb):size], rb)
+		copy(sig[2*size-len(sb):], sb)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestVerifyToken(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := parseJWKS(fmt.Sprintf(`{"keys": [
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": %q, "e": %q},
+		{"kty": "EC", "kid": "ec", "alg": "ES256", "crv": "P-256", "x": %q, "y": %q},
+		{"kty": "EC", "kid": "p384", "crv": "P-384", "x": %q, "y": %q},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"}
+	]}`, encodeBigInt(rsaKey.N), encodeBigInt(big.NewInt(int64(rsaKey.E))),
+		encodeBigInt(ecKey.X), encodeBigInt(ecKey.Y), encodeBigInt(p384Key.X), encodeBigInt(p384Key.Y)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("expected the encryption key to be skipped, got %d keys", len(keys))
+	}
+
+	now := time.Unix(1500000000, 0)
+	cfg := tokenConfig{
+		issuers:  []string{"https://accounts.example.com", "https://idp.example.org"},
+		audience: "cockroach",
+		keys:     keys,
+		claim:    "sub",
+	}
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss": "https://idp.example.org",
+			"aud": []string{"other", "cockroach"},
+			"sub": "alice",
+			"exp": now.Add(time.Hour).Unix(),
+			"nbf": now.Add(-time.Hour).Unix(),
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+	rs256 := map[string]string{"alg": "RS256", "kid": "rsa"}
+	es256 := map[string]string{"alg": "ES256", "kid": "ec"}
+
+	testCases := []struct {
+		token    string
+		identity string
+		err      string
+	}{
+		{signToken(t, rsaKey, rs256, claims(nil)), "alice", ``},
+		{signToken(t, ecKey, es256, claims(nil)), "alice", ``},
+		{signToken(t, p384Key, map[string]string{"alg": "ES384", "kid": "p384"}, claims(nil)), "alice", ``},
+		// Without a key ID, every key is tried.
+		{signToken(t, ecKey, map[string]string{"alg": "ES256"}, claims(nil)), "alice", ``},
+		{signToken(t, rsaKey, map[string]string{"alg": "RS512"}, claims(map[string]interface{}{
+			"aud": "cockroach", "iss": "https://accounts.example.com",
+		})), "alice", ``},
+		// The validity period tolerates some clock skew.
+		{signToken(t, rsaKey, rs256, claims(map[string]interface{}{
+			"exp": now.Add(-30 * time.Second).Unix(),
+		})), "alice", ``},
+
+		{"alice", "", `invalid token: malformed`},
+		{signToken(t, rsaKey, map[string]string{"alg": "none"}, claims(nil)), "",
+			`unsupported algorithm "none"`},
+		{signToken(t, rsaKey, map[string]string{"alg": "HS256"}, claims(nil)), "",
+			`unsupported algorithm "HS256"`},
+		{signToken(t, otherKey, es256, claims(nil)), "",
+			`signature not verified by any trusted key`},
+		{signToken(t, ecKey, map[string]string{"alg": "ES256", "kid": "rsa"}, claims(nil)), "",
+			`signature not verified by any trusted key`},
+		// The curve of the key must match the algorithm.
+		{signToken(t, p384Key, map[string]string{"alg": "ES256", "kid": "p384"}, claims(nil)), "",
+			`signature not verified by any trusted key`},
+		{signToken(t, p384Key, map[string]string{"alg": "ES512", "kid": "p384"}, claims(nil)), "",
+			`signature not verified by any trusted key`},
+		{signToken(t, rsaKey, rs256, claims(map[string]interface{}{"iss": "https://evil.example.com"})), "",
+			`untrusted issuer "https://evil.example.com"`},
+		{signToken(t, rsaKey, rs256, claims(map[string]interface{}{"aud": "other"})), "",
+			`audience "cockroach" not listed`},
+		{signToken(t, rsaKey, rs256, claims(map[string]interface{}{"exp": nil})), "",
+			`missing expiration time`},
+		{signToken(t, rsaKey, rs256, claims(map[string]interface{}{"exp": now.Add(-time.Hour).Unix()})), "",
+			`invalid token: expired`},
+		{signToken(t, rsaKey, rs256, claims(map[string]interface{}{"nbf": now.Add(time.Hour).Unix()})), "",
+			`not valid yet`},
+		{signToken(t, rsaKey, rs256, claims(map[string]interface{}{"sub": nil})), "",
+			`missing sub claim`},
+	}
+	for i, tc := range testCases {
+		identity, err := verifyToken(tc.token, cfg, now)
+		if !testutils.IsError(err, tc.err) {
+			t.Errorf("%d: expected error %q, got %v", i, tc.err, err)
+			continue
+		}
+		if identity != tc.identity {
+			t.Errorf("%d: expected %q, got %q", i, tc.identity, identity)
+		}
+	}
+
+	// No token is accepted until an audience is configured.
+	noAudience := cfg
+	noAudience.audience = ""
+	if _, err := verifyToken(
+		signToken(t, rsaKey, rs256, claims(nil)), noAudience, now,
+	); !testutils.IsError(err, `no audience configured`) {
+		t.Errorf("expected the token to be rejected without an audience, got %v", err)
+	}
+
+	// Tampering with the claims invalidates the signature.
+	token := signToken(t, rsaKey, rs256, claims(nil))
+	forged := signToken(t, rsaKey, rs256, claims(map[string]interface{}{"sub": "root"}))
+	forged = forged[:strings.LastIndex(forged, ".")] + token[strings.LastIndex(token, "."):]
+	if _, err := verifyToken(forged, cfg, now); !testutils.IsError(err, `signature not verified`) {
+		t.Errorf("expected a forged token to be rejected, got %v", err)
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	testCases := []struct {
+		jwks string
+		err  string
+	}{
+		{`{"keys": []}`, ``},
+		{`{`, `invalid JSON Web Key Set`},
+		{`{"keys": [{"kty": "oct", "k": "c2VjcmV0"}]}`, `unsupported key type "oct"`},
+		{`{"keys": [{"kty": "EC", "crv": "P-192", "x": "AQ", "y": "AQ"}]}`, `unsupported curve "P-192"`},
+		{`{"keys": [{"kty": "EC", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`, `point not on the curve`},
+		{`{"keys": [{"kty": "RSA", "n": "AQAB", "e": ""}]}`, `invalid exponent`},
+	}
+	for _, tc := range testCases {
+		if _, err := parseJWKS(tc.jwks); !testutils.IsError(err, tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.jwks, tc.err, err)
+		}
+	}
+}
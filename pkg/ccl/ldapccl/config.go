@@ -0,0 +1,155 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"crypto/tls"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/ldap.v2"
+)
+
+// ldapOptions are the options of the `ldap` host-based authentication method.
+var ldapOptions = []string{
+	"ldapserver", "ldapport", "ldapscheme", "ldaptls",
+	"ldapprefix", "ldapsuffix",
+	"ldapbasedn", "ldapbinddn", "ldapbindpasswd", "ldapsearchattribute", "ldapsearchfilter",
+}
+
+// ldapConfig is the configuration of the LDAP authentication of a host-based
+// authentication entry. In the simple bind mode, the client binds as the
+// distinguished name made of prefix, the user name and suffix. In the search
+// and bind mode, which is used if baseDN is set, the server first binds as
+// bindDN, or anonymously, and searches the entry of the user under baseDN.
+type ldapConfig struct {
+	server   string
+	port     int
+	useTLS   bool // ldaps
+	startTLS bool
+
+	prefix, suffix string
+
+	baseDN, bindDN, bindPasswd string
+	searchAttribute            string
+	searchFilter               string
+}
+
+// parseLDAPConfig validates the options of an `ldap` entry.
+func parseLDAPConfig(options map[string]string) (ldapConfig, error) {
+	c := ldapConfig{
+		server:          options["ldapserver"],
+		prefix:          options["ldapprefix"],
+		suffix:          options["ldapsuffix"],
+		baseDN:          options["ldapbasedn"],
+		bindDN:          options["ldapbinddn"],
+		bindPasswd:      options["ldapbindpasswd"],
+		searchAttribute: options["ldapsearchattribute"],
+		searchFilter:    options["ldapsearchfilter"],
+	}
+	if c.server == "" {
+		return ldapConfig{}, errors.New("LDAP authentication requires the ldapserver option")
+	}
+	switch scheme := options["ldapscheme"]; scheme {
+	case "", "ldap":
+	case "ldaps":
+		c.useTLS = true
+	default:
+		return ldapConfig{}, errors.Errorf("invalid ldapscheme value %q", scheme)
+	}
+	switch tlsOpt := options["ldaptls"]; tlsOpt {
+	case "", "0":
+	case "1":
+		if c.useTLS {
+			return ldapConfig{}, errors.New("ldaptls cannot be used with ldapscheme ldaps")
+		}
+		c.startTLS = true
+	default:
+		return ldapConfig{}, errors.Errorf("invalid ldaptls value %q", tlsOpt)
+	}
+	c.port = 389
+	if c.useTLS {
+		c.port = 636
+	}
+	if port, ok := options["ldapport"]; ok {
+		var err error
+		if c.port, err = strconv.Atoi(port); err != nil || c.port <= 0 || c.port > 65535 {
+			return ldapConfig{}, errors.Errorf("invalid ldapport value %q", port)
+		}
+	}
+
+	if c.baseDN == "" {
+		if c.bindDN != "" || c.bindPasswd != "" || c.searchAttribute != "" || c.searchFilter != "" {
+			return ldapConfig{}, errors.New(
+				"ldapbinddn, ldapbindpasswd, ldapsearchattribute and ldapsearchfilter require ldapbasedn")
+		}
+		return c, nil
+	}
+	if c.prefix != "" || c.suffix != "" {
+		return ldapConfig{}, errors.New("ldapprefix and ldapsuffix cannot be used with ldapbasedn")
+	}
+	if c.searchAttribute != "" && c.searchFilter != "" {
+		return ldapConfig{}, errors.New("ldapsearchattribute cannot be used with ldapsearchfilter")
+	}
+	if c.searchFilter != "" && !strings.Contains(c.searchFilter, "$username") {
+		return ldapConfig{}, errors.New("ldapsearchfilter must contain $username")
+	}
+	if c.searchAttribute == "" && c.searchFilter == "" {
+		c.searchAttribute = "uid"
+	}
+	return c, nil
+}
+
+// searchMode returns whether the entry of the user is searched before
+// binding as it.
+func (c ldapConfig) searchMode() bool {
+	return c.baseDN != ""
+}
+
+// userDN returns the distinguished name of the user in the simple bind mode.
+// The user names that would need escaping in a distinguished name are
+// rejected.
+func (c ldapConfig) userDN(user string) (string, error) {
+	if strings.ContainsAny(user, `,+"\<>;=#`) {
+		return "", errors.Errorf("invalid character in user name %q for LDAP authentication", user)
+	}
+	return c.prefix + user + c.suffix, nil
+}
+
+// userFilter returns the filter searching the entry of the user in the
+// search and bind mode.
+func (c ldapConfig) userFilter(user string) string {
+	user = ldap.EscapeFilter(user)
+	if c.searchFilter != "" {
+		return strings.Replace(c.searchFilter, "$username", user, -1)
+	}
+	return "(" + c.searchAttribute + "=" + user + ")"
+}
+
+// dial connects to the LDAP server, over TLS if configured.
+func (c ldapConfig) dial() (*ldap.Conn, error) {
+	addr := net.JoinHostPort(c.server, strconv.Itoa(c.port))
+	tlsConfig := &tls.Config{ServerName: c.server}
+	if c.useTLS {
+		return ldap.DialTLS("tcp", addr, tlsConfig)
+	}
+	conn, err := ldap.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if c.startTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
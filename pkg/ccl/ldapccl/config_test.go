@@ -0,0 +1,85 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func TestParseLDAPConfig(t *testing.T) {
+	testCases := []struct {
+		options map[string]string
+		exp     ldapConfig
+		err     string
+	}{
+		{map[string]string{"ldapserver": "ldap.example.com", "ldapprefix": "uid=", "ldapsuffix": ",dc=example,dc=com"},
+			ldapConfig{server: "ldap.example.com", port: 389, prefix: "uid=", suffix: ",dc=example,dc=com"}, ``},
+		{map[string]string{"ldapserver": "ldap.example.com", "ldapscheme": "ldaps"},
+			ldapConfig{server: "ldap.example.com", port: 636, useTLS: true}, ``},
+		{map[string]string{"ldapserver": "ldap.example.com", "ldaptls": "1", "ldapport": "10389"},
+			ldapConfig{server: "ldap.example.com", port: 10389, startTLS: true}, ``},
+		{map[string]string{"ldapserver": "ldap.example.com", "ldapbasedn": "dc=example,dc=com"},
+			ldapConfig{server: "ldap.example.com", port: 389, baseDN: "dc=example,dc=com", searchAttribute: "uid"}, ``},
+		{map[string]string{"ldapserver": "ldap.example.com", "ldapbasedn": "dc=example,dc=com",
+			"ldapsearchfilter": "(|(uid=$username)(mail=$username))"},
+			ldapConfig{server: "ldap.example.com", port: 389, baseDN: "dc=example,dc=com",
+				searchFilter: "(|(uid=$username)(mail=$username))"}, ``},
+		{map[string]string{}, ldapConfig{},
+			`LDAP authentication requires the ldapserver option`},
+		{map[string]string{"ldapserver": "ldap.example.com", "ldapscheme": "ldapi"}, ldapConfig{},
+			`invalid ldapscheme value "ldapi"`},
+		{map[string]string{"ldapserver": "ldap.example.com", "ldapscheme": "ldaps", "ldaptls": "1"}, ldapConfig{},
+			`ldaptls cannot be used with ldapscheme ldaps`},
+		{map[string]string{"ldapserver": "ldap.example.com", "ldaptls": "yes"}, ldapConfig{},
+			`invalid ldaptls value "yes"`},
+		{map[string]string{"ldapserver": "ldap.example.com", "ldapport": "0"}, ldapConfig{},
+			`invalid ldapport value "0"`},
+		{map[string]string{"ldapserver": "ldap.example.com", "ldapbinddn": "cn=admin"}, ldapConfig{},
+			`ldapbinddn, ldapbindpasswd, ldapsearchattribute and ldapsearchfilter require ldapbasedn`},
+		{map[string]string{"ldapserver": "ldap.example.com", "ldapbasedn": "dc=example,dc=com", "ldapprefix": "uid="},
+			ldapConfig{}, `ldapprefix and ldapsuffix cannot be used with ldapbasedn`},
+		{map[string]string{"ldapserver": "ldap.example.com", "ldapbasedn": "dc=example,dc=com",
+			"ldapsearchattribute": "uid", "ldapsearchfilter": "(uid=$username)"},
+			ldapConfig{}, `ldapsearchattribute cannot be used with ldapsearchfilter`},
+		{map[string]string{"ldapserver": "ldap.example.com", "ldapbasedn": "dc=example,dc=com",
+			"ldapsearchfilter": "(uid=alice)"},
+			ldapConfig{}, `ldapsearchfilter must contain \$username`},
+	}
+	for _, tc := range testCases {
+		c, err := parseLDAPConfig(tc.options)
+		if !testutils.IsError(err, tc.err) {
+			t.Errorf("%v: expected error %q, got %v", tc.options, tc.err, err)
+			continue
+		}
+		if c != tc.exp {
+			t.Errorf("%v: expected %+v, got %+v", tc.options, tc.exp, c)
+		}
+	}
+}
+
+func TestLDAPUserDNAndFilter(t *testing.T) {
+	bind := ldapConfig{prefix: "uid=", suffix: ",dc=example,dc=com"}
+	if dn, err := bind.userDN("alice"); err != nil || dn != "uid=alice,dc=example,dc=com" {
+		t.Errorf("expected uid=alice,dc=example,dc=com, got %q (%v)", dn, err)
+	}
+	if _, err := bind.userDN("alice,dc=other"); !testutils.IsError(err, `invalid character in user name`) {
+		t.Errorf("expected an invalid character error, got %v", err)
+	}
+
+	search := ldapConfig{searchAttribute: "uid"}
+	if f := search.userFilter("a*)"); f != `(uid=a\2a\29)` {
+		t.Errorf(`expected (uid=a\2a\29), got %q`, f)
+	}
+	search = ldapConfig{searchFilter: "(|(uid=$username)(mail=$username))"}
+	if f := search.userFilter("alice"); f != "(|(uid=alice)(mail=alice))" {
+		t.Errorf("expected (|(uid=alice)(mail=alice)), got %q", f)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"crypto/tls"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"gopkg.in/ldap.v2"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+)
+
+// This file implements the `ldap` host-based authentication method, which
+// authenticates clients by binding to an LDAP server with the user name and
+// the cleartext password they send. The method takes the same options as in
+// Postgres:
+//
+//   ldapserver           the host name or address of the LDAP server
+//   ldapport             its port, 389 by default or 636 with ldaps
+//   ldapscheme           ldap (the default) or ldaps, for LDAP over TLS
+//   ldaptls              whether the connection is upgraded with StartTLS
+//                        (1) or not (0, the default)
+//   ldapprefix           the prefix and
+//   ldapsuffix           the suffix of the user name forming the
+//                        distinguished name to bind as (simple bind mode)
+//   ldapbasedn           the root of the search of the user's entry, whose
+//                        distinguished name is the one to bind as (search
+//                        and bind mode)
+//   ldapbinddn           the distinguished name and
+//   ldapbindpasswd       the password to bind as for the search; the search
+//                        is anonymous without them
+//   ldapsearchattribute  the attribute matched against the user name in the
+//                        search, uid by default
+//   ldapsearchfilter     the filter of the search, in which $username is
+//                        replaced by the user name
+//   map                  the identity map mapping LDAP users to SQL users
+
+const authTypeCleartextPassword = 3
+
+func init() {
+	pgwire.RegisterAuthMethod("ldap", authLDAP, ldapOptions...)
+}
+
+// authLDAP implements pgwire.AuthMethod.
+func authLDAP(
+	ctx context.Context,
+	c pgwire.AuthConn,
+	_ tls.ConnectionState,
+	execCfg *sql.ExecutorConfig,
+	options map[string]string,
+) (string, error) {
+	if err := utilccl.CheckEnterpriseEnabled(
+		execCfg.Settings, execCfg.ClusterID(), execCfg.Organization(), "LDAP authentication",
+	); err != nil {
+		return "", err
+	}
+	cfg, err := parseLDAPConfig(options)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.SendAuthRequest(authTypeCleartextPassword, nil); err != nil {
+		return "", err
+	}
+	data, err := c.GetPwdData()
+	if err != nil {
+		return "", err
+	}
+	password := strings.TrimSuffix(string(data), "\x00")
+	user := c.User()
+	// LDAP servers treat a bind with an empty password as an anonymous bind,
+	// which succeeds.
+	if password == "" {
+		return "", errors.Errorf("LDAP authentication failed for user %s: empty password", user)
+	}
+
+	conn, err := cfg.dial()
+	if err != nil {
+		return "", errors.Wrap(err, "could not connect to the LDAP server")
+	}
+	defer conn.Close()
+
+	dn, err := userDN(conn, cfg, user)
+	if err != nil {
+		return "", err
+	}
+	if err := conn.Bind(dn, password); err != nil {
+		return "", errors.Wrapf(err, "LDAP authentication failed for user %s", user)
+	}
+	return user, nil
+}
+
+// userDN returns the distinguished name to bind as to authenticate the user,
+// searching it over conn in the search and bind mode.
+func userDN(conn *ldap.Conn, cfg ldapConfig, user string) (string, error) {
+	if !cfg.searchMode() {
+		return cfg.userDN(user)
+	}
+	if cfg.bindDN != "" {
+		if err := conn.Bind(cfg.bindDN, cfg.bindPasswd); err != nil {
+			return "", errors.Wrapf(err, "could not bind to the LDAP server as %s", cfg.bindDN)
+		}
+	}
+	// A size limit of 2 is enough to detect a user matching several entries.
+	res, err := conn.Search(ldap.NewSearchRequest(
+		cfg.baseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2 /* sizeLimit */, 0, /* timeLimit */
+		false /* typesOnly */, cfg.userFilter(user), []string{"dn"}, nil, /* controls */
+	))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return "", errors.Errorf("LDAP user %s is not unique", user)
+	} else if err != nil {
+		return "", errors.Wrapf(err, "could not search the LDAP entry of user %s", user)
+	}
+	switch len(res.Entries) {
+	case 0:
+		return "", errors.Errorf("LDAP user %s does not exist", user)
+	case 1:
+		return res.Entries[0].DN, nil
+	default:
+		return "", errors.Errorf("LDAP user %s is not unique", user)
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"crypto/tls"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"gopkg.in/ldap.v2"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// The LDAP group synchronization periodically makes the members of the SQL
+// roles named after the groups of an LDAP directory the members of those
+// groups. Only the roles and users that already exist are synchronized. Every
+// node runs the synchronization, which is idempotent.
+
+var ldapSyncInterval = settings.RegisterNonNegativeDurationSetting(
+	"server.ldap_sync.interval",
+	"the interval between synchronizations of the LDAP groups into the members of the roles (0 to disable)",
+	0,
+)
+
+var ldapSyncURL = settings.RegisterValidatedStringSetting(
+	"server.ldap_sync.url",
+	"the URL of the LDAP server the groups are synchronized from, as ldap://host[:port] or ldaps://host[:port]",
+	"",
+	func(s string) error {
+		if s == "" {
+			return nil
+		}
+		_, _, err := parseLDAPURL(s)
+		return err
+	},
+)
+
+var ldapSyncBindDN = settings.RegisterStringSetting(
+	"server.ldap_sync.bind_dn",
+	"the distinguished name to bind as to search the LDAP groups; the search is anonymous if empty",
+	"",
+)
+
+var ldapSyncBindPassword = settings.RegisterStringSetting(
+	"server.ldap_sync.bind_password",
+	"the password of server.ldap_sync.bind_dn",
+	"",
+)
+
+var ldapSyncGroupBaseDN = settings.RegisterStringSetting(
+	"server.ldap_sync.group_base_dn",
+	"the root of the search of the LDAP groups",
+	"",
+)
+
+var ldapSyncGroupFilter = settings.RegisterStringSetting(
+	"server.ldap_sync.group_filter",
+	"the filter of the search of the LDAP groups",
+	"(objectClass=groupOfNames)",
+)
+
+var ldapSyncGroupNameAttribute = settings.RegisterStringSetting(
+	"server.ldap_sync.group_name_attribute",
+	"the attribute of the LDAP groups naming their role",
+	"cn",
+)
+
+var ldapSyncMemberAttribute = settings.RegisterStringSetting(
+	"server.ldap_sync.member_attribute",
+	"the attribute of the LDAP groups listing their members, as distinguished names or user names",
+	"member",
+)
+
+var ldapSyncUserNameAttribute = settings.RegisterStringSetting(
+	"server.ldap_sync.user_name_attribute",
+	"the attribute of the distinguished names of the members naming their user",
+	"uid",
+)
+
+// ldapSyncDisabledPollInterval is how often the synchronization checks
+// whether it was enabled.
+const ldapSyncDisabledPollInterval = time.Minute
+
+func init() {
+	// Like the license, the password is kept out of SHOW ALL CLUSTER SETTINGS.
+	ldapSyncBindPassword.Hide()
+	sql.AddBackgroundWorker(syncLDAPGroups)
+}
+
+// syncLDAPGroups implements sql.BackgroundWorker.
+func syncLDAPGroups(ctx context.Context, stopper *stop.Stopper, e *sql.Executor) {
+	st := e.Cfg().Settings
+	var timer timeutil.Timer
+	defer timer.Stop()
+	for {
+		interval := ldapSyncInterval.Get(&st.SV)
+		if interval == 0 {
+			interval = ldapSyncDisabledPollInterval
+		}
+		timer.Reset(interval)
+		select {
+		case <-stopper.ShouldQuiesce():
+			return
+		case <-timer.C:
+			timer.Read = true
+		}
+		if ldapSyncInterval.Get(&st.SV) == 0 || ldapSyncURL.Get(&st.SV) == "" {
+			continue
+		}
+		if err := syncLDAPGroupsOnce(ctx, e); err != nil {
+			log.Warningf(ctx, "LDAP group synchronization failed: %v", err)
+		}
+	}
+}
+
+func syncLDAPGroupsOnce(ctx context.Context, e *sql.Executor) error {
+	cfg := e.Cfg()
+	if err := utilccl.CheckEnterpriseEnabled(
+		cfg.Settings, cfg.ClusterID(), cfg.Organization(), "LDAP group synchronization",
+	); err != nil {
+		return err
+	}
+	sv := &cfg.Settings.SV
+
+	addr, useTLS, err := parseLDAPURL(ldapSyncURL.Get(sv))
+	if err != nil {
+		return err
+	}
+	var conn *ldap.Conn
+	if useTLS {
+		host, _, _ := net.SplitHostPort(addr)
+		conn, err = ldap.DialTLS("tcp", addr, &tls.Config{ServerName: host})
+	} else {
+		conn, err = ldap.Dial("tcp", addr)
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not connect to the LDAP server")
+	}
+	defer conn.Close()
+	if bindDN := ldapSyncBindDN.Get(sv); bindDN != "" {
+		if err := conn.Bind(bindDN, ldapSyncBindPassword.Get(sv)); err != nil {
+			return errors.Wrapf(err, "could not bind to the LDAP server as %s", bindDN)
+		}
+	}
+
+	nameAttr, memberAttr := ldapSyncGroupNameAttribute.Get(sv), ldapSyncMemberAttribute.Get(sv)
+	res, err := conn.SearchWithPaging(ldap.NewSearchRequest(
+		ldapSyncGroupBaseDN.Get(sv), ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0 /* sizeLimit */, 0 /* timeLimit */, false, /* typesOnly */
+		ldapSyncGroupFilter.Get(sv), []string{nameAttr, memberAttr}, nil, /* controls */
+	), 500 /* pagingSize */)
+	if err != nil {
+		return errors.Wrap(err, "could not search the LDAP groups")
+	}
+
+	members := groupMembers(res.Entries, nameAttr, memberAttr, ldapSyncUserNameAttribute.Get(sv))
+	granted, revoked, err := sql.SyncRoleMembers(ctx, e, members)
+	if err != nil {
+		return err
+	}
+	if granted > 0 || revoked > 0 {
+		log.Infof(ctx, "LDAP group synchronization granted %d and revoked %d role memberships",
+			granted, revoked)
+	}
+	return nil
+}
+
+// parseLDAPURL returns the address of the LDAP server of an ldap:// or
+// ldaps:// URL and whether it is reached over TLS.
+func parseLDAPURL(s string) (addr string, useTLS bool, err error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", false, err
+	}
+	port := "389"
+	switch u.Scheme {
+	case "ldap":
+	case "ldaps":
+		port, useTLS = "636", true
+	default:
+		return "", false, errors.Errorf("invalid LDAP URL %q: the scheme must be ldap or ldaps", s)
+	}
+	if u.Hostname() == "" {
+		return "", false, errors.Errorf("invalid LDAP URL %q: missing host", s)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	return net.JoinHostPort(u.Hostname(), port), useTLS, nil
+}
+
+// groupMembers maps the names of the LDAP groups in entries to the names of
+// their members. The members are listed either as distinguished names, whose
+// first attribute of type userAttr names the user, or directly as user names,
+// like the memberUid attribute of the posixGroup object class.
+func groupMembers(
+	entries []*ldap.Entry, nameAttr, memberAttr, userAttr string,
+) map[string][]string {
+	members := make(map[string][]string)
+	for _, entry := range entries {
+		name := entry.GetAttributeValue(nameAttr)
+		if name == "" {
+			continue
+		}
+		// Groups without members are synchronized too.
+		if _, ok := members[name]; !ok {
+			members[name] = nil
+		}
+		for _, value := range entry.GetAttributeValues(memberAttr) {
+			if user, ok := memberName(value, userAttr); ok {
+				members[name] = append(members[name], user)
+			}
+		}
+	}
+	return members
+}
+
+// memberName returns the user name of a member of an LDAP group.
+func memberName(value, userAttr string) (string, bool) {
+	if !strings.Contains(value, "=") {
+		return value, value != ""
+	}
+	dn, err := ldap.ParseDN(value)
+	if err != nil {
+		return "", false
+	}
+	for _, rdn := range dn.RDNs {
+		for _, attr := range rdn.Attributes {
+			if strings.EqualFold(attr.Type, userAttr) {
+				return attr.Value, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,74 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package ldapccl
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/ldap.v2"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func TestParseLDAPURL(t *testing.T) {
+	testCases := []struct {
+		url    string
+		addr   string
+		useTLS bool
+		err    string
+	}{
+		{"ldap://ldap.example.com", "ldap.example.com:389", false, ``},
+		{"ldaps://ldap.example.com", "ldap.example.com:636", true, ``},
+		{"ldap://ldap.example.com:10389", "ldap.example.com:10389", false, ``},
+		{"http://ldap.example.com", "", false, `the scheme must be ldap or ldaps`},
+		{"ldap://", "", false, `missing host`},
+	}
+	for _, tc := range testCases {
+		addr, useTLS, err := parseLDAPURL(tc.url)
+		if !testutils.IsError(err, tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.url, tc.err, err)
+			continue
+		}
+		if addr != tc.addr || useTLS != tc.useTLS {
+			t.Errorf("%s: expected %s %t, got %s %t", tc.url, tc.addr, tc.useTLS, addr, useTLS)
+		}
+	}
+}
+
+func TestGroupMembers(t *testing.T) {
+	entries := []*ldap.Entry{
+		{DN: "cn=dev,ou=groups,dc=example,dc=com", Attributes: []*ldap.EntryAttribute{
+			{Name: "cn", Values: []string{"dev"}},
+			{Name: "member", Values: []string{
+				"uid=alice,ou=people,dc=example,dc=com",
+				"UID=bob,ou=people,dc=example,dc=com",
+				// Members whose distinguished name doesn't name a user are
+				// skipped.
+				"cn=ops,ou=groups,dc=example,dc=com",
+			}},
+		}},
+		{DN: "cn=ops,ou=groups,dc=example,dc=com", Attributes: []*ldap.EntryAttribute{
+			{Name: "cn", Values: []string{"ops"}},
+			{Name: "member", Values: []string{"carol"}},
+		}},
+		{DN: "cn=empty,ou=groups,dc=example,dc=com", Attributes: []*ldap.EntryAttribute{
+			{Name: "cn", Values: []string{"empty"}},
+		}},
+		{DN: "ou=unnamed,dc=example,dc=com"},
+	}
+	exp := map[string][]string{
+		"dev":   {"alice", "bob"},
+		"ops":   {"carol"},
+		"empty": nil,
+	}
+	if members := groupMembers(entries, "cn", "member", "uid"); !reflect.DeepEqual(members, exp) {
+		t.Errorf("expected %v, got %v", exp, members)
+	}
+}
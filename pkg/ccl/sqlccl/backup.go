@@ -11,6 +11,7 @@ package sqlccl
 import (
 	"bytes"
 	"io/ioutil"
+	"net/url"
 	"sort"
 	"time"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/interval"
@@ -57,13 +59,27 @@ const (
 )
 
 const (
-	backupOptRevisionHistory = "experimental_revision_history"
+	backupOptRevisionHistory = "revision_history"
+	// backupOptExperimentalRevisionHistory is the name revision_history had
+	// while it was experimental. It is still accepted.
+	backupOptExperimentalRevisionHistory = "experimental_revision_history"
 )
 
 var backupOptionExpectValues = map[string]bool{
-	backupOptRevisionHistory: false,
+	backupOptRevisionHistory:             false,
+	backupOptExperimentalRevisionHistory: false,
 }
 
+const (
+	// localityURLParam is the query parameter of the URIs of a partitioned
+	// backup giving the locality tier of the nodes writing to each URI.
+	localityURLParam = "COCKROACH_LOCALITY"
+	// defaultLocalityValue is the value of localityURLParam marking the URI of
+	// a partitioned backup used by the nodes matching none of the other
+	// localities. The backup descriptor is written there.
+	defaultLocalityValue = "default"
+)
+
 // BackupCheckpointInterval is the interval at which backup progress is saved
 // to durable storage.
 var BackupCheckpointInterval = time.Minute
@@ -79,6 +95,74 @@ func exportStorageFromURI(
 	return storageccl.MakeExportStorage(ctx, conf, settings)
 }
 
+// getURIsByLocalityKV splits the URIs of a partitioned backup into the default
+// URI and the URIs of each locality tier, keyed by the "key=value" string of
+// the tier. The locality parameter is removed from the returned URIs. A
+// single URI may omit the locality parameter, in which case it is the default.
+func getURIsByLocalityKV(to []string) (string, map[string]string, error) {
+	var defaultURI string
+	urisByLocalityKV := make(map[string]string)
+	for _, uri := range to {
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			return "", nil, err
+		}
+		q := parsed.Query()
+		localityKV := q.Get(localityURLParam)
+		if localityKV == "" {
+			if len(to) > 1 {
+				return "", nil, errors.Errorf(
+					"multiple URLs are provided for a partitioned backup, but %s is not specified for each",
+					localityURLParam)
+			}
+			localityKV = defaultLocalityValue
+		}
+		q.Del(localityURLParam)
+		parsed.RawQuery = q.Encode()
+
+		if localityKV == defaultLocalityValue {
+			if defaultURI != "" {
+				return "", nil, errors.Errorf("multiple default URLs provided for partitioned backup")
+			}
+			defaultURI = parsed.String()
+			continue
+		}
+		var tier roachpb.Tier
+		if err := tier.FromString(localityKV); err != nil {
+			return "", nil, errors.Wrapf(err, "invalid %s", localityURLParam)
+		}
+		if _, ok := urisByLocalityKV[localityKV]; ok {
+			return "", nil, errors.Errorf("duplicate URLs for locality %s", localityKV)
+		}
+		urisByLocalityKV[localityKV] = parsed.String()
+	}
+	if defaultURI == "" {
+		return "", nil, errors.Errorf(
+			"no default URL provided for partitioned backup: one of the URLs must have %s=%s",
+			localityURLParam, defaultLocalityValue)
+	}
+	return defaultURI, urisByLocalityKV, nil
+}
+
+// exportStorageConfsByLocalityKV returns the ExportStorage configurations of
+// the locality URIs of a partitioned backup.
+func exportStorageConfsByLocalityKV(
+	urisByLocalityKV map[string]string,
+) (map[string]*roachpb.ExportStorage, error) {
+	if len(urisByLocalityKV) == 0 {
+		return nil, nil
+	}
+	confs := make(map[string]*roachpb.ExportStorage, len(urisByLocalityKV))
+	for kv, uri := range urisByLocalityKV {
+		conf, err := storageccl.ExportStorageConfFromURI(uri)
+		if err != nil {
+			return nil, err
+		}
+		confs[kv] = &conf
+	}
+	return confs, nil
+}
+
 // ReadBackupDescriptorFromURI creates an export store from the given URI, then
 // reads and unmarshals a BackupDescriptor at the standard location in the
 // export storage.
@@ -95,11 +179,35 @@ func ReadBackupDescriptorFromURI(
 		return BackupDescriptor{}, err
 	}
 	backupDesc.Dir = exportStore.Conf()
-	// TODO(dan): Sanity check this BackupDescriptor: non-empty EndTime,
-	// non-empty Paths, and non-overlapping Spans and keyranges in Files.
+	if err := validateBackupDescriptor(backupDesc); err != nil {
+		return BackupDescriptor{}, errors.Wrapf(err, "invalid backup descriptor in %q", uri)
+	}
 	return backupDesc, nil
 }
 
+// validateBackupDescriptor sanity checks a BackupDescriptor read from export
+// storage: it must have an EndTime, and its Files must have non-empty paths and
+// cover non-overlapping keyranges.
+func validateBackupDescriptor(desc BackupDescriptor) error {
+	if desc.EndTime == (hlc.Timestamp{}) {
+		return errors.New("missing end time")
+	}
+	if desc.EndTime.Less(desc.StartTime) {
+		return errors.Errorf("end time %s is before start time %s", desc.EndTime, desc.StartTime)
+	}
+	files := append(backupFileDescriptors(nil), desc.Files...)
+	sort.Sort(files)
+	for i, file := range files {
+		if file.Path == "" {
+			return errors.Errorf("file for span %s has an empty path", file.Span)
+		}
+		if i > 0 && files[i-1].Span.EndKey.Compare(file.Span.Key) > 0 {
+			return errors.Errorf("file for span %s overlaps file for span %s", file.Span, files[i-1].Span)
+		}
+	}
+	return nil
+}
+
 // readBackupDescriptor reads and unmarshals a BackupDescriptor from filename in
 // the provided export store.
 func readBackupDescriptor(
@@ -143,7 +251,7 @@ func ValidatePreviousBackups(
 	// timestamps to validate the previous backups that this one is incremental
 	// from.
 	lowWaterMark := keys.MinKey
-	_, endTime, err := makeImportSpans(spans, backups, lowWaterMark)
+	_, endTime, err := makeImportSpans(spans, backups, nil /* localityDirs */, lowWaterMark)
 	return endTime, errors.Wrap(err, "invalid previous backups (a new full backup may be required if a table has been created, dropped or truncated)")
 }
 
@@ -301,7 +409,7 @@ func splitAndFilterSpans(
 }
 
 func backupJobDescription(
-	backup *tree.Backup, to string, incrementalFrom []string,
+	backup *tree.Backup, to []string, incrementalFrom []string,
 ) (string, error) {
 	b := &tree.Backup{
 		AsOf:    backup.AsOf,
@@ -309,11 +417,13 @@ func backupJobDescription(
 		Targets: backup.Targets,
 	}
 
-	to, err := storageccl.SanitizeExportStorageURI(to)
-	if err != nil {
-		return "", err
+	for _, uri := range to {
+		sanitizedTo, err := storageccl.SanitizeExportStorageURI(uri)
+		if err != nil {
+			return "", err
+		}
+		b.To = append(b.To, tree.NewDString(sanitizedTo))
 	}
-	b.To = tree.NewDString(to)
 
 	for _, from := range incrementalFrom {
 		sanitizedFrom, err := storageccl.SanitizeExportStorageURI(from)
@@ -420,11 +530,17 @@ func resolveTargetsToDescriptors(
 // - <dir> is given by the user and may be cloud storage
 // - Each file contains data for a key range that doesn't overlap with any other
 //   file.
+//
+// If storageByLocalityKV is not empty, a range is written to the storage of
+// the first locality tier of its leaseholder found in storageByLocalityKV, or
+// to exportStore if there is none. The backup descriptor is always written to
+// exportStore.
 func backup(
 	ctx context.Context,
 	db *client.DB,
 	gossip *gossip.Gossip,
 	exportStore storageccl.ExportStorage,
+	storageByLocalityKV map[string]*roachpb.ExportStorage,
 	job *jobs.Job,
 	backupDesc *BackupDescriptor,
 	checkpointDesc *BackupDescriptor,
@@ -482,6 +598,7 @@ func backup(
 		startFraction: job.Payload().FractionCompleted,
 	}
 
+	releaseCtx := ctx
 	ctx, cancel := context.WithCancel(ctx)
 	cancelFn := func() {
 		cancel()
@@ -491,6 +608,26 @@ func backup(
 	if err := job.Created(ctx, cancelFn); err != nil {
 		return err
 	}
+
+	// Protect the history read by the backup from garbage collection until it
+	// completes, successfully or not. The record of a job which doesn't
+	// complete, because its node died, is replaced when it is resumed.
+	jobID := *job.ID()
+	if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		return protectedts.Protect(ctx, txn, protectedts.MakeJobRecord(
+			jobID, backupProtectedTimestamp(backupDesc), backupDesc.Spans,
+		))
+	}); err != nil {
+		return errors.Wrap(err, "protecting backup timestamp")
+	}
+	defer func() {
+		if err := db.Txn(releaseCtx, func(ctx context.Context, txn *client.Txn) error {
+			return protectedts.Release(ctx, txn, protectedts.JobRecordID(jobID))
+		}); err != nil {
+			log.Warningf(releaseCtx, "unable to release protected timestamp of job %d: %+v", jobID, err)
+		}
+	}()
+
 	if err := job.Started(ctx); err != nil {
 		return err
 	}
@@ -534,10 +671,11 @@ func backup(
 			defer func() { <-exportsSem }()
 
 			req := &roachpb.ExportRequest{
-				Span:       span,
-				Storage:    exportStore.Conf(),
-				StartTime:  backupDesc.StartTime,
-				MVCCFilter: roachpb.MVCCFilter(backupDesc.MVCCFilter),
+				Span:                span,
+				Storage:             exportStore.Conf(),
+				StorageByLocalityKV: storageByLocalityKV,
+				StartTime:           backupDesc.StartTime,
+				MVCCFilter:          roachpb.MVCCFilter(backupDesc.MVCCFilter),
 			}
 			res, pErr := client.SendWrappedWith(gCtx, db.GetSender(), header, req)
 			if pErr != nil {
@@ -551,6 +689,7 @@ func backup(
 					Path:        file.Path,
 					Sha512:      file.Sha512,
 					EntryCounts: file.Exported,
+					LocalityKV:  file.LocalityKV,
 				})
 				mu.exported.Add(file.Exported)
 			}
@@ -600,6 +739,45 @@ func backup(
 	return nil
 }
 
+// backupProtectedTimestamp returns the earliest time from which the history
+// of the spans of a backup is read: the start time of an incremental backup,
+// whose changes since then are exported, the start of the revision history of
+// a full backup, or else its end time.
+func backupProtectedTimestamp(backupDesc *BackupDescriptor) hlc.Timestamp {
+	if backupDesc.StartTime != (hlc.Timestamp{}) {
+		return backupDesc.StartTime
+	}
+	if backupDesc.RevisionStartTime != (hlc.Timestamp{}) {
+		return backupDesc.RevisionStartTime
+	}
+	return backupDesc.EndTime
+}
+
+// gcWindowStart returns the earliest time at which the revisions of the given
+// tables are still guaranteed to be readable at endTime, according to the
+// smallest GC TTL of the zones, and subzones, of the tables.
+func gcWindowStart(
+	ctx context.Context, txn *client.Txn, tables []*sqlbase.TableDescriptor, endTime hlc.Timestamp,
+) (hlc.Timestamp, error) {
+	var minTTLSeconds int32
+	for _, table := range tables {
+		_, zone, _, err := sql.GetZoneConfigInTxn(ctx, txn, uint32(table.ID), nil, "")
+		if err != nil {
+			return hlc.Timestamp{}, err
+		}
+		ttlSeconds := zone.GC.TTLSeconds
+		for _, subzone := range zone.Subzones {
+			if t := subzone.Config.GC.TTLSeconds; t > 0 && t < ttlSeconds {
+				ttlSeconds = t
+			}
+		}
+		if minTTLSeconds == 0 || ttlSeconds < minTTLSeconds {
+			minTTLSeconds = ttlSeconds
+		}
+	}
+	return endTime.Add(-int64(minTTLSeconds)*time.Second.Nanoseconds(), 0), nil
+}
+
 // verifyUsableExportTarget ensures that the target location does not already
 // contain a BACKUP or checkpoint and writes an empty checkpoint, both verifying
 // that the location is writable and locking out accidental concurrent
@@ -647,7 +825,7 @@ func backupPlanHook(
 		return nil, nil, err
 	}
 
-	toFn, err := p.TypeAsString(backupStmt.To, "BACKUP")
+	toFn, err := p.TypeAsStringArray(tree.Exprs(backupStmt.To), "BACKUP")
 	if err != nil {
 		return nil, nil, err
 	}
@@ -696,11 +874,19 @@ func backupPlanHook(
 			}
 		}
 
-		exportStore, err := exportStorageFromURI(ctx, to, p.ExecCfg().Settings)
+		defaultURI, urisByLocalityKV, err := getURIsByLocalityKV(to)
+		if err != nil {
+			return err
+		}
+		exportStore, err := exportStorageFromURI(ctx, defaultURI, p.ExecCfg().Settings)
 		if err != nil {
 			return err
 		}
 		defer exportStore.Close()
+		storageByLocalityKV, err := exportStorageConfsByLocalityKV(urisByLocalityKV)
+		if err != nil {
+			return err
+		}
 
 		opts, err := optsFn()
 		if err != nil {
@@ -740,24 +926,59 @@ func backupPlanHook(
 			if err != nil {
 				return err
 			}
+			if !startTime.Less(endTime) {
+				return errors.Errorf(
+					"incremental backup end time %s must be after the end time %s of the previous backups",
+					endTime, startTime)
+			}
 		}
 
 		mvccFilter := MVCCFilter_Latest
-		if _, ok := opts[backupOptRevisionHistory]; ok {
+		var revisionStartTime hlc.Timestamp
+		_, revisionHistory := opts[backupOptRevisionHistory]
+		if _, ok := opts[backupOptExperimentalRevisionHistory]; ok {
+			revisionHistory = true
+		}
+		if revisionHistory {
 			mvccFilter = MVCCFilter_All
+			revisionStartTime = startTime
+			if backupStmt.IncrementalFrom == nil {
+				// A full backup contains the revisions still in the GC window. An
+				// incremental backup contains all the revisions since the previous
+				// backup, which must have been taken within the GC window for the
+				// export to succeed.
+				if err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+					var err error
+					revisionStartTime, err = gcWindowStart(ctx, txn, tables, endTime)
+					return err
+				}); err != nil {
+					return err
+				}
+			}
+		}
+
+		var sanitizedIncrementalFrom []string
+		for _, from := range incrementalFrom {
+			sanitizedFrom, err := storageccl.SanitizeExportStorageURI(from)
+			if err != nil {
+				return err
+			}
+			sanitizedIncrementalFrom = append(sanitizedIncrementalFrom, sanitizedFrom)
 		}
 
 		backupDesc := BackupDescriptor{
-			StartTime:     startTime,
-			EndTime:       endTime,
-			MVCCFilter:    mvccFilter,
-			Descriptors:   targetDescs,
-			CompleteDbs:   completeDBs,
-			Spans:         spans,
-			FormatVersion: BackupFormatDescriptorTrackingVersion,
-			BuildInfo:     build.GetInfo(),
-			NodeID:        p.ExecCfg().NodeID.Get(),
-			ClusterID:     p.ExecCfg().ClusterID(),
+			StartTime:         startTime,
+			EndTime:           endTime,
+			RevisionStartTime: revisionStartTime,
+			MVCCFilter:        mvccFilter,
+			Descriptors:       targetDescs,
+			CompleteDbs:       completeDBs,
+			Spans:             spans,
+			FormatVersion:     BackupFormatDescriptorTrackingVersion,
+			BuildInfo:         build.GetInfo(),
+			NodeID:            p.ExecCfg().NodeID.Get(),
+			ClusterID:         p.ExecCfg().ClusterID(),
+			IncrementalFrom:   sanitizedIncrementalFrom,
 		}
 
 		description, err := backupJobDescription(backupStmt, to, incrementalFrom)
@@ -765,7 +986,7 @@ func backupPlanHook(
 			return err
 		}
 
-		if err := verifyUsableExportTarget(ctx, exportStore, to); err != nil {
+		if err := verifyUsableExportTarget(ctx, exportStore, defaultURI); err != nil {
 			return err
 		}
 
@@ -779,9 +1000,10 @@ func backupPlanHook(
 				return sqlDescIDs
 			}(),
 			Details: jobs.BackupDetails{
-				StartTime: startTime,
-				EndTime:   endTime,
-				URI:       to,
+				StartTime:        startTime,
+				EndTime:          endTime,
+				URI:              defaultURI,
+				URIsByLocalityKV: urisByLocalityKV,
 			},
 		})
 		var checkpointDesc *BackupDescriptor
@@ -789,6 +1011,7 @@ func backupPlanHook(
 			p.ExecCfg().DB,
 			p.ExecCfg().Gossip,
 			exportStore,
+			storageByLocalityKV,
 			job,
 			&backupDesc,
 			checkpointDesc,
@@ -870,6 +1093,10 @@ func backupResumeHook(
 		if err != nil {
 			return nil
 		}
+		storageByLocalityKV, err := exportStorageConfsByLocalityKV(details.URIsByLocalityKV)
+		if err != nil {
+			return err
+		}
 		var checkpointDesc *BackupDescriptor
 		if desc, err := readBackupDescriptor(ctx, exportStore, BackupDescriptorCheckpointName); err == nil {
 			// If the checkpoint is from a different cluster, it's meaningless to us.
@@ -885,7 +1112,9 @@ func backupResumeHook(
 			// implementations.
 			log.Warningf(ctx, "unable to load backup checkpoint while resuming job %d: %v", *job.ID(), err)
 		}
-		return backup(ctx, job.DB(), job.Gossip(), exportStore, job, &backupDesc, checkpointDesc)
+		return backup(
+			ctx, job.DB(), job.Gossip(), exportStore, storageByLocalityKV, job, &backupDesc, checkpointDesc,
+		)
 	}
 }
 
@@ -918,6 +1147,8 @@ func showBackupPlanHook(
 		{Name: "end_time", Typ: types.Timestamp},
 		{Name: "size_bytes", Typ: types.Int},
 		{Name: "rows", Typ: types.Int},
+		{Name: "backup_type", Typ: types.String},
+		{Name: "incremental_from", Typ: types.TArray{Typ: types.String}},
 	}
 	fn := func(ctx context.Context, resultsCh chan<- tree.Datums) error {
 		// TODO(dan): Move this span into sql.
@@ -956,8 +1187,22 @@ func showBackupPlanHook(
 			descSizes[sqlbase.ID(tableID)] = s
 		}
 		start := tree.DNull
+		backupType := tree.NewDString("full")
 		if desc.StartTime.WallTime != 0 {
 			start = tree.MakeDTimestamp(timeutil.Unix(0, desc.StartTime.WallTime), time.Nanosecond)
+			backupType = tree.NewDString("incremental")
+		}
+		// The incremental chain isn't recorded by the backups taken before
+		// IncrementalFrom was added to the descriptor.
+		incrementalFrom := tree.DNull
+		if len(desc.IncrementalFrom) > 0 {
+			from := tree.NewDArray(types.String)
+			for _, uri := range desc.IncrementalFrom {
+				if err := from.Append(tree.NewDString(uri)); err != nil {
+					return err
+				}
+			}
+			incrementalFrom = from
 		}
 		for _, descriptor := range desc.Descriptors {
 			if table := descriptor.GetTable(); table != nil {
@@ -969,6 +1214,8 @@ func showBackupPlanHook(
 					tree.MakeDTimestamp(timeutil.Unix(0, desc.EndTime.WallTime), time.Nanosecond),
 					tree.NewDInt(tree.DInt(descSizes[table.ID].DataSize)),
 					tree.NewDInt(tree.DInt(descSizes[table.ID].Rows)),
+					backupType,
+					incrementalFrom,
 				}
 			}
 		}
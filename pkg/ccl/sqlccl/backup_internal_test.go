@@ -0,0 +1,76 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlccl
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestValidateBackupDescriptor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	file := func(start, end, path string) BackupDescriptor_File {
+		return BackupDescriptor_File{
+			Span: roachpb.Span{Key: roachpb.Key(start), EndKey: roachpb.Key(end)},
+			Path: path,
+		}
+	}
+
+	tests := []struct {
+		desc  BackupDescriptor
+		error string
+	}{
+		{
+			desc: BackupDescriptor{EndTime: hlc.Timestamp{WallTime: 2}},
+		},
+		{
+			desc: BackupDescriptor{
+				StartTime: hlc.Timestamp{WallTime: 1},
+				EndTime:   hlc.Timestamp{WallTime: 2},
+				Files:     []BackupDescriptor_File{file("c", "d", "2.sst"), file("a", "c", "1.sst")},
+			},
+		},
+		{
+			desc:  BackupDescriptor{},
+			error: "missing end time",
+		},
+		{
+			desc: BackupDescriptor{
+				StartTime: hlc.Timestamp{WallTime: 2},
+				EndTime:   hlc.Timestamp{WallTime: 1},
+			},
+			error: "is before start time",
+		},
+		{
+			desc: BackupDescriptor{
+				EndTime: hlc.Timestamp{WallTime: 2},
+				Files:   []BackupDescriptor_File{file("a", "b", "")},
+			},
+			error: "has an empty path",
+		},
+		{
+			desc: BackupDescriptor{
+				EndTime: hlc.Timestamp{WallTime: 2},
+				Files:   []BackupDescriptor_File{file("b", "d", "2.sst"), file("a", "c", "1.sst")},
+			},
+			error: "overlaps file",
+		},
+	}
+	for i, test := range tests {
+		err := validateBackupDescriptor(test.desc)
+		if !testutils.IsError(err, test.error) {
+			t.Errorf("%d: expected error %q, got %v", i, test.error, err)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlccl
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/cron"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// scheduledBackupExecutorType is the executor type of the schedules created
+// by CREATE SCHEDULE FOR BACKUP.
+const scheduledBackupExecutorType = "backup"
+
+// scheduledBackupDirFormat is the layout of the time a scheduled backup
+// starts in the name of the subdirectory of the collection it is written to.
+const scheduledBackupDirFormat = "20060102-150405"
+
+// scheduledBackupPlanHook implements sql.PlanHookFn. The schedule runs the
+// backup in a new subdirectory of the destination every time it is due. If
+// the schedule has a full backup recurrence, the backups between the times
+// it is due are incremental on top of the backups since the last full one.
+func scheduledBackupPlanHook(
+	stmt tree.Statement, p sql.PlanHookState,
+) (func(context.Context, chan<- tree.Datums) error, sqlbase.ResultColumns, error) {
+	schedStmt, ok := stmt.(*tree.ScheduledBackup)
+	if !ok {
+		return nil, nil, nil
+	}
+
+	if err := utilccl.CheckEnterpriseEnabled(
+		p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(),
+		"CREATE SCHEDULE FOR BACKUP",
+	); err != nil {
+		return nil, nil, err
+	}
+
+	if err := p.RequireSuperUser("CREATE SCHEDULE FOR BACKUP"); err != nil {
+		return nil, nil, err
+	}
+
+	if len(schedStmt.To) != 1 {
+		return nil, nil, errors.New("scheduled backups only support a single destination")
+	}
+	toFn, err := p.TypeAsString(schedStmt.To[0], "CREATE SCHEDULE FOR BACKUP")
+	if err != nil {
+		return nil, nil, err
+	}
+	recurrenceFn, err := p.TypeAsString(schedStmt.Recurrence, "CREATE SCHEDULE FOR BACKUP")
+	if err != nil {
+		return nil, nil, err
+	}
+	labelFn := func() (string, error) { return "BACKUP", nil }
+	if schedStmt.ScheduleLabel != nil {
+		if labelFn, err = p.TypeAsString(schedStmt.ScheduleLabel, "CREATE SCHEDULE FOR BACKUP"); err != nil {
+			return nil, nil, err
+		}
+	}
+	fullRecurrenceFn := func() (string, error) { return "", nil }
+	if schedStmt.FullBackup != nil && !schedStmt.FullBackup.AlwaysFull {
+		if fullRecurrenceFn, err = p.TypeAsString(
+			schedStmt.FullBackup.Recurrence, "CREATE SCHEDULE FOR BACKUP",
+		); err != nil {
+			return nil, nil, err
+		}
+	}
+	// The options are only validated here: they are passed to the backups as
+	// written.
+	optsFn, err := p.TypeAsStringOpts(schedStmt.BackupOptions, backupOptionExpectValues)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := sqlbase.ResultColumns{
+		{Name: "schedule_id", Typ: types.Int},
+		{Name: "name", Typ: types.String},
+		{Name: "recurrence", Typ: types.String},
+		{Name: "next_run", Typ: types.Timestamp},
+	}
+
+	fn := func(ctx context.Context, resultsCh chan<- tree.Datums) error {
+		if err := schedStmt.Targets.NormalizeTablesWithDatabase(p.EvalContext().Database); err != nil {
+			return err
+		}
+
+		to, err := toFn()
+		if err != nil {
+			return err
+		}
+		recurrence, err := recurrenceFn()
+		if err != nil {
+			return err
+		}
+		label, err := labelFn()
+		if err != nil {
+			return err
+		}
+		fullRecurrence, err := fullRecurrenceFn()
+		if err != nil {
+			return err
+		}
+		if _, err := optsFn(); err != nil {
+			return err
+		}
+
+		if _, err := cron.Parse(recurrence); err != nil {
+			return err
+		}
+		if fullRecurrence != "" {
+			if _, err := cron.Parse(fullRecurrence); err != nil {
+				return err
+			}
+		}
+
+		exportStore, err := exportStorageFromURI(ctx, to, p.ExecCfg().Settings)
+		if err != nil {
+			return err
+		}
+		exportStore.Close()
+
+		// The targets are resolved now so that a schedule backing up nothing
+		// is rejected, but they are resolved again by every backup.
+		if _, _, err := resolveTargetsToDescriptors(
+			ctx, p, p.ExecCfg().Clock.Now(), schedStmt.Targets,
+		); err != nil {
+			return err
+		}
+
+		backupStmt := &tree.Backup{
+			Targets: schedStmt.Targets,
+			To:      tree.PartitionedBackup{tree.NewDString(to)},
+			Options: schedStmt.BackupOptions,
+		}
+		args, err := protoutil.Marshal(&ScheduledBackupArgs{
+			BackupStatement:      tree.AsStringWithFlags(backupStmt, tree.FmtSimpleQualified),
+			CollectionURI:        to,
+			FullBackupRecurrence: fullRecurrence,
+		})
+		if err != nil {
+			return err
+		}
+
+		sj := &sql.ScheduledJob{
+			Name:          label,
+			Owner:         p.User(),
+			ScheduleExpr:  recurrence,
+			ExecutorType:  scheduledBackupExecutorType,
+			ExecutionArgs: args,
+		}
+		if err := sql.CreateScheduledJob(ctx, p.ExecCfg(), sj, timeutil.Now()); err != nil {
+			return err
+		}
+
+		resultsCh <- tree.Datums{
+			tree.NewDInt(tree.DInt(sj.ID)),
+			tree.NewDString(sj.Name),
+			tree.NewDString(sj.ScheduleExpr),
+			tree.MakeDTimestamp(sj.NextRun, time.Microsecond),
+		}
+		return nil
+	}
+	return fn, header, nil
+}
+
+// startScheduledBackup implements sql.ScheduledJobExecutor. It skips the run
+// while the previous backup of the schedule is still running, so that the
+// incremental backups of a schedule form a chain.
+func startScheduledBackup(
+	ctx context.Context, txn *client.Txn, e *sql.Executor, sj *sql.ScheduledJob, now time.Time,
+) (func(context.Context) (string, error), error) {
+	cfg := e.Cfg()
+	if err := utilccl.CheckEnterpriseEnabled(
+		cfg.Settings, cfg.ClusterID(), cfg.Organization(), "CREATE SCHEDULE FOR BACKUP",
+	); err != nil {
+		return nil, err
+	}
+
+	var args ScheduledBackupArgs
+	if err := protoutil.Unmarshal(sj.ExecutionArgs, &args); err != nil {
+		return nil, err
+	}
+	var state ScheduledBackupState
+	if err := protoutil.Unmarshal(sj.State, &state); err != nil {
+		return nil, err
+	}
+
+	if state.InFlightURI != "" {
+		status, err := scheduledBackupJobStatus(ctx, txn, cfg, &state)
+		if err != nil {
+			return nil, err
+		}
+		switch status {
+		case jobs.StatusPending, jobs.StatusRunning, jobs.StatusPaused:
+			sj.Status = fmt.Sprintf("skipped: the backup to %s is still %s",
+				sanitizeScheduledBackupURI(state.InFlightURI), status)
+			return nil, nil
+		case jobs.StatusSucceeded:
+			state.Chain = append(state.Chain, state.InFlightURI)
+		}
+		state.InFlightURI, state.InFlightStartedMicros = "", 0
+	}
+
+	nowMicros := now.UnixNano() / 1e3
+	full := len(state.Chain) == 0 || args.FullBackupRecurrence == "" ||
+		nowMicros >= state.NextFullBackupMicros
+	kind := "incremental"
+	if full {
+		kind = "full"
+		state.Chain = nil
+		if args.FullBackupRecurrence != "" {
+			sched, err := cron.Parse(args.FullBackupRecurrence)
+			if err != nil {
+				return nil, err
+			}
+			state.NextFullBackupMicros = sched.Next(now).UnixNano() / 1e3
+		}
+	}
+
+	dest, err := scheduledBackupDestination(args.CollectionURI, now, kind)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := parser.ParseOne(args.BackupStatement)
+	if err != nil {
+		return nil, err
+	}
+	backupStmt, ok := parsed.(*tree.Backup)
+	if !ok {
+		return nil, errors.Errorf("unexpected statement in schedule: %s", args.BackupStatement)
+	}
+	backupStmt.To = tree.PartitionedBackup{tree.NewDString(dest)}
+	backupStmt.IncrementalFrom = nil
+	for _, uri := range state.Chain {
+		backupStmt.IncrementalFrom = append(backupStmt.IncrementalFrom, tree.NewDString(uri))
+	}
+	stmt := tree.AsStringWithFlags(backupStmt, tree.FmtSimpleQualified)
+
+	state.InFlightURI, state.InFlightStartedMicros = dest, nowMicros
+	if sj.State, err = protoutil.Marshal(&state); err != nil {
+		return nil, err
+	}
+	sanitizedDest := sanitizeScheduledBackupURI(dest)
+	sj.Status = fmt.Sprintf("started %s backup to %s", kind, sanitizedDest)
+
+	owner := sj.Owner
+	return func(ctx context.Context) (string, error) {
+		rows, err := e.ExecuteStatementAsUser(ctx, owner, stmt)
+		if err != nil {
+			return "", err
+		}
+		if len(rows) != 1 {
+			return "", errors.Errorf("expected 1 row from the backup, got %d", len(rows))
+		}
+		return fmt.Sprintf("succeeded: %s backup job %s to %s", kind, rows[0][0], sanitizedDest), nil
+	}, nil
+}
+
+// scheduledBackupJobStatus returns the status of the job of the in-flight
+// backup of a schedule, or the empty status if there is no such job, which
+// happens when the backup failed before creating it.
+func scheduledBackupJobStatus(
+	ctx context.Context, txn *client.Txn, cfg *sql.ExecutorConfig, state *ScheduledBackupState,
+) (jobs.Status, error) {
+	ie := sql.InternalExecutor{LeaseManager: cfg.LeaseManager}
+	started := timeutil.Unix(0, state.InFlightStartedMicros*1e3)
+	rows, err := ie.QueryRowsInTransaction(ctx, "scheduled-backup-job-status", txn,
+		`SELECT status, payload FROM system.jobs WHERE created >= $1`, started)
+	if err != nil {
+		return "", err
+	}
+	for _, row := range rows {
+		payload, err := jobs.UnmarshalPayload(row[1])
+		if err != nil {
+			return "", err
+		}
+		if details := payload.GetBackup(); details != nil && details.URI == state.InFlightURI {
+			return jobs.Status(tree.MustBeDString(row[0])), nil
+		}
+	}
+	return "", nil
+}
+
+// scheduledBackupDestination returns the URI of the subdirectory of the
+// collection a backup started at now is written to.
+func scheduledBackupDestination(collection string, now time.Time, kind string) (string, error) {
+	uri, err := url.Parse(collection)
+	if err != nil {
+		return "", err
+	}
+	uri.Path = path.Join(uri.Path, now.UTC().Format(scheduledBackupDirFormat)+"-"+kind)
+	return uri.String(), nil
+}
+
+// sanitizeScheduledBackupURI returns the URI without its secrets, to be
+// recorded in the status of a schedule.
+func sanitizeScheduledBackupURI(uri string) string {
+	sanitized, err := storageccl.SanitizeExportStorageURI(uri)
+	if err != nil {
+		return "<invalid URI>"
+	}
+	return sanitized
+}
+
+func init() {
+	sql.AddPlanHook(scheduledBackupPlanHook)
+	sql.AddScheduledJobExecutor(scheduledBackupExecutorType, startScheduledBackup)
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlccl_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestScheduledBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	_, _, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, 1, numAccounts, initNone)
+	defer cleanupFn()
+
+	var id int64
+	var name, recurrence string
+	sqlDB.QueryRow(t,
+		`CREATE SCHEDULE 'nightly' FOR BACKUP DATABASE data TO $1 RECURRING '@daily' FULL BACKUP '@weekly'`,
+		localFoo,
+	).Scan(&id, &name, &recurrence, new(interface{}))
+	if name != "nightly" || recurrence != "@daily" {
+		t.Fatalf("unexpected schedule %q recurring %q", name, recurrence)
+	}
+	var count int
+	sqlDB.QueryRow(t, `SELECT count(*) FROM [SHOW SCHEDULES] WHERE id = $1`, id).Scan(&count)
+	if count != 1 {
+		t.Fatalf("expected the schedule in SHOW SCHEDULES, got %d rows", count)
+	}
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING jobs.scheduler.interval = '10ms'`)
+
+	// runNow makes the schedule due and waits for the status of its run.
+	runNow := func(expectedStatus string) {
+		t.Helper()
+		sqlDB.Exec(t,
+			`UPDATE system.scheduled_jobs SET next_run = '2000-01-01'::TIMESTAMP WHERE schedule_id = $1`,
+			id)
+		testutils.SucceedsSoon(t, func() error {
+			var status string
+			sqlDB.QueryRow(t,
+				`SELECT schedule_status FROM system.scheduled_jobs WHERE schedule_id = $1`, id,
+			).Scan(&status)
+			if !strings.HasPrefix(status, expectedStatus) {
+				return errors.Errorf("expected status %q, got %q", expectedStatus, status)
+			}
+			return nil
+		})
+	}
+	runNow("succeeded: full backup job")
+	runNow("succeeded: incremental backup job")
+
+	var backups int
+	sqlDB.QueryRow(t,
+		`SELECT count(*) FROM [SHOW JOBS] WHERE job_type = 'BACKUP' AND status = 'succeeded'`,
+	).Scan(&backups)
+	if backups != 2 {
+		t.Fatalf("expected 2 succeeded backups, got %d", backups)
+	}
+}
+
+func TestScheduledBackupErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	_, _, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, 1, 0, initNone)
+	defer cleanupFn()
+
+	for _, test := range []struct {
+		stmt  string
+		error string
+	}{
+		{`CREATE SCHEDULE FOR BACKUP DATABASE data TO 'nodelocal:///foo' RECURRING 'nope'`,
+			`invalid crontab expression "nope"`},
+		{`CREATE SCHEDULE FOR BACKUP DATABASE data TO 'nodelocal:///foo' RECURRING '@daily' FULL BACKUP '* *'`,
+			`invalid crontab expression "\* \*"`},
+		{`CREATE SCHEDULE FOR BACKUP DATABASE data TO ('nodelocal:///a', 'nodelocal:///b') RECURRING '@daily'`,
+			`scheduled backups only support a single destination`},
+		{`CREATE SCHEDULE FOR BACKUP DATABASE nope TO 'nodelocal:///foo' RECURRING '@daily'`,
+			`database "nope" does not exist`},
+		{`CREATE SCHEDULE FOR BACKUP DATABASE data TO 'nope://foo' RECURRING '@daily'`,
+			`unsupported storage scheme`},
+		{`CREATE SCHEDULE FOR BACKUP DATABASE data TO 'nodelocal:///foo' WITH nope RECURRING '@daily'`,
+			`invalid option "nope"`},
+	} {
+		if _, err := sqlDB.DB.Exec(test.stmt); !testutils.IsError(err, test.error) {
+			t.Errorf("%s: expected error %q, got %v", test.stmt, test.error, err)
+		}
+	}
+}
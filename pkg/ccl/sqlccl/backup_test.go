@@ -1649,6 +1649,14 @@ func TestBackupAsOfSystemTime(t *testing.T) {
 	equalDir := filepath.Join(localFoo, `equalTs`)
 	sqlDB.Exec(t, fmt.Sprintf(`BACKUP DATABASE data TO '%s' AS OF SYSTEM TIME %s`, equalDir, equalTs))
 
+	// An incremental backup can't end before the backups it builds on.
+	incDir := filepath.Join(localFoo, `incBeforeTs`)
+	if _, err := sqlDB.DB.Exec(fmt.Sprintf(
+		`BACKUP DATABASE data TO '%s' AS OF SYSTEM TIME %s INCREMENTAL FROM '%s'`, incDir, beforeTs, equalDir,
+	)); !testutils.IsError(err, "must be after the end time") {
+		t.Fatalf("expected end time error, got %v", err)
+	}
+
 	sqlDB.Exec(t, `DROP TABLE data.bank`)
 	sqlDB.Exec(t, `RESTORE data.* FROM $1`, beforeDir)
 	sqlDB.QueryRow(t, `SELECT COUNT(*) FROM data.bank`).Scan(&rowCount)
@@ -1686,7 +1694,7 @@ func TestRestoreAsOfSystemTime(t *testing.T) {
 
 	fullBackup, latestBackup := filepath.Join(dir, "full"), filepath.Join(dir, "latest")
 	sqlDB.Exec(t,
-		fmt.Sprintf(`BACKUP DATABASE data TO $1 AS OF SYSTEM TIME %s WITH experimental_revision_history`, ts[2]),
+		fmt.Sprintf(`BACKUP DATABASE data TO $1 AS OF SYSTEM TIME %s WITH revision_history`, ts[2]),
 		fullBackup,
 	)
 	sqlDB.Exec(t,
@@ -1748,6 +1756,105 @@ func TestRestoreAsOfSystemTime(t *testing.T) {
 	})
 }
 
+func TestRestoreAsOfSystemTimeGCWindow(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	_, _, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+	const dir = "nodelocal:///"
+
+	sqlDB.Exec(t, `ALTER TABLE data.bank CONFIGURE ZONE USING gc.ttlseconds = 1`)
+
+	var beforeGC string
+	sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&beforeGC)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = 1`)
+	// Wait for the revisions before beforeGC to leave the GC window.
+	time.Sleep(time.Second)
+	var afterGC string
+	sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&afterGC)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = 2`)
+
+	backup := filepath.Join(dir, "revisions")
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 WITH revision_history`, backup)
+
+	sqlDB.Exec(t, `CREATE DATABASE err`)
+	_, err := sqlDB.DB.Exec(
+		fmt.Sprintf(`RESTORE data.* FROM $1 EXPERIMENTAL AS OF SYSTEM TIME %s WITH into_db='err'`, beforeGC),
+		backup,
+	)
+	if !testutils.IsError(err, "may have been garbage collected") {
+		t.Errorf("expected 'may have been garbage collected' error got %+v", err)
+	}
+
+	sqlDB.Exec(t, `CREATE DATABASE restored`)
+	sqlDB.Exec(t,
+		fmt.Sprintf(`RESTORE data.* FROM $1 EXPERIMENTAL AS OF SYSTEM TIME %s WITH into_db='restored'`, afterGC),
+		backup,
+	)
+	sqlDB.CheckQueryResults(t,
+		`SELECT * FROM restored.bank ORDER BY id`,
+		sqlDB.QueryStr(t, fmt.Sprintf(`SELECT * FROM data.bank AS OF SYSTEM TIME %s ORDER BY id`, afterGC)),
+	)
+}
+
+func TestBackupRestorePartitioned(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 100
+	params := base.TestClusterArgs{}
+	params.ServerArgs.Locality = roachpb.Locality{
+		Tiers: []roachpb.Tier{{Key: "region", Value: "east"}},
+	}
+	_, _, sqlDB, dir, cleanupFn := backupRestoreTestSetupWithParams(
+		t, singleNode, numAccounts, initNone, params,
+	)
+	defer cleanupFn()
+
+	localityURI := func(path, localityKV string) string {
+		return fmt.Sprintf("nodelocal:///%s?COCKROACH_LOCALITY=%s", path, url.QueryEscape(localityKV))
+	}
+	defaultURI := localityURI("default", "default")
+	eastURI := localityURI("east", "region=east")
+	westURI := localityURI("west", "region=west")
+
+	sqlDB.Exec(t, `BACKUP DATABASE data TO ($1, $2, $3)`, defaultURI, eastURI, westURI)
+
+	// The only node is in region=east, so all the data files are written to
+	// the east partition, and only the backup descriptor to the default one.
+	for path, expected := range map[string]bool{"default": false, "east": true, "west": false} {
+		files, err := filepath.Glob(filepath.Join(dir, path, "*.sst"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if written := len(files) > 0; written != expected {
+			t.Errorf("%s: expected data files written %t, got %d files", path, expected, len(files))
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "default", sqlccl.BackupDescriptorName)); err != nil {
+		t.Fatal(err)
+	}
+
+	sqlDB.Exec(t, `CREATE DATABASE restored`)
+	sqlDB.Exec(t, `RESTORE data.bank FROM ($1, $2) WITH into_db='restored'`, eastURI, defaultURI)
+	sqlDB.CheckQueryResults(t,
+		`SELECT * FROM restored.bank ORDER BY id`,
+		sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`),
+	)
+
+	sqlDB.Exec(t, `CREATE DATABASE err`)
+	if _, err := sqlDB.DB.Exec(
+		`RESTORE data.bank FROM $1 WITH into_db='err'`, defaultURI,
+	); !testutils.IsError(err, "no URL provided for the partition of locality region=east") {
+		t.Errorf("expected missing partition error, got %+v", err)
+	}
+	if _, err := sqlDB.DB.Exec(
+		`BACKUP DATABASE data TO ($1, $2)`, eastURI, westURI,
+	); !testutils.IsError(err, "no default URL provided for partitioned backup") {
+		t.Errorf("expected missing default URL error, got %+v", err)
+	}
+}
+
 func TestAsOfSystemTimeOnRestoredData(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -1995,9 +2102,14 @@ func TestRestoreInto(t *testing.T) {
 
 	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, localFoo)
 
+	_, err := sqlDB.DB.Exec(`RESTORE data.bank FROM $1 WITH into_db = ''`, localFoo)
+	if !testutils.IsError(err, "\"into_db\" option requires a non-empty database name") {
+		t.Fatal(err)
+	}
+
 	restoreStmt := fmt.Sprintf(`RESTORE data.bank FROM '%s' WITH into_db = 'data 2'`, localFoo)
 
-	_, err := sqlDB.DB.Exec(restoreStmt)
+	_, err = sqlDB.DB.Exec(restoreStmt)
 	if !testutils.IsError(err, "a database named \"data 2\" needs to exist") {
 		t.Fatal(err)
 	}
@@ -2203,7 +2315,7 @@ func TestShowBackup(t *testing.T) {
 	var start, end *time.Time
 	var dataSize, rows uint64
 	sqlDB.QueryRow(t, `SELECT * FROM [SHOW BACKUP $1] WHERE "table" = 'bank'`, full).Scan(
-		&unused, &unused, &start, &end, &dataSize, &rows,
+		&unused, &unused, &start, &end, &dataSize, &rows, &unused, &unused,
 	)
 	if start != nil {
 		t.Errorf("expected null start time on full backup, got %v", *start)
@@ -2217,6 +2329,12 @@ func TestShowBackup(t *testing.T) {
 	if rows != numAccounts {
 		t.Errorf("expected %d got: %d", numAccounts, rows)
 	}
+	const chainQuery = `SELECT DISTINCT backup_type, incremental_from[1] FROM [SHOW BACKUP $1]`
+	if expected, actual := [][]string{{"full", "NULL"}}, sqlDB.QueryStr(
+		t, chainQuery, full,
+	); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v got: %v", expected, actual)
+	}
 
 	// Mess with half the rows.
 	affectedRows, err := sqlDB.Exec(t,
@@ -2232,7 +2350,7 @@ func TestShowBackup(t *testing.T) {
 	sqlDB.Exec(t, `BACKUP data.bank TO $1 INCREMENTAL FROM $2`, inc, full)
 
 	sqlDB.QueryRow(t, `SELECT * FROM [SHOW BACKUP $1] WHERE "table" = 'bank'`, inc).Scan(
-		&unused, &unused, &start, &end, &dataSize, &rows,
+		&unused, &unused, &start, &end, &dataSize, &rows, &unused, &unused,
 	)
 	if start == nil {
 		t.Errorf("expected start time on inc backup, got %v", *start)
@@ -2248,6 +2366,11 @@ func TestShowBackup(t *testing.T) {
 	if expected := affectedRows * 2; rows != uint64(expected) {
 		t.Errorf("expected %d got: %d", expected, rows)
 	}
+	if expected, actual := [][]string{{"incremental", full}}, sqlDB.QueryStr(
+		t, chainQuery, inc,
+	); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v got: %v", expected, actual)
+	}
 }
 
 func TestBackupAzureAccountName(t *testing.T) {
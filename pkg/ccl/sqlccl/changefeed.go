@@ -0,0 +1,846 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlccl
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+const (
+	changefeedOptUpdated                 = "updated"
+	changefeedOptResolved                = "resolved"
+	changefeedOptEnvelope                = "envelope"
+	changefeedOptFormat                  = "format"
+	changefeedOptSchemaChangePolicy      = "schema_change_policy"
+	changefeedOptConfluentSchemaRegistry = "confluent_schema_registry"
+
+	// changefeedEnvelopeRow emits the new value of a row, or a nil value if it
+	// was deleted. changefeedEnvelopeKeyOnly emits an empty value instead of
+	// the new value of a row. changefeedEnvelopeWrapped emits an object with
+	// the values of the row before and after the change.
+	changefeedEnvelopeRow     = "row"
+	changefeedEnvelopeKeyOnly = "key_only"
+	changefeedEnvelopeWrapped = "wrapped"
+
+	changefeedFormatJSON = "json"
+	changefeedFormatAvro = "experimental_avro"
+
+	// changefeedSchemaChangeBackfill emits every row of a table again once its
+	// columns changed. changefeedSchemaChangeStop fails the changefeed instead,
+	// and changefeedSchemaChangeIgnore emits the following changes with the
+	// new columns.
+	changefeedSchemaChangeBackfill = "backfill"
+	changefeedSchemaChangeStop     = "stop"
+	changefeedSchemaChangeIgnore   = "ignore"
+
+	// changefeedMetadataField is the field of the emitted JSON objects that
+	// holds the CockroachDB-specific metadata of a message, as opposed to the
+	// columns of a row.
+	changefeedMetadataField = "__crdb__"
+)
+
+var changefeedOptionExpectValues = map[string]bool{
+	changefeedOptUpdated:                 false,
+	changefeedOptResolved:                true,
+	changefeedOptEnvelope:                true,
+	changefeedOptFormat:                  true,
+	changefeedOptSchemaChangePolicy:      true,
+	changefeedOptConfluentSchemaRegistry: true,
+}
+
+var changefeedPollInterval = settings.RegisterNonNegativeDurationSetting(
+	"changefeed.experimental_poll_interval",
+	"polling interval for the changefeeds",
+	1*time.Second,
+)
+
+// changefeedPlanHook implements sql.PlanHookFn. A changefeed with a sink is
+// run as a job, whose ID is returned as soon as it is running. A sinkless
+// changefeed instead runs as part of the statement and returns the changes
+// to the client until the statement is canceled.
+func changefeedPlanHook(
+	stmt tree.Statement, p sql.PlanHookState,
+) (func(context.Context, chan<- tree.Datums) error, sqlbase.ResultColumns, error) {
+	changefeedStmt, ok := stmt.(*tree.CreateChangefeed)
+	if !ok {
+		return nil, nil, nil
+	}
+	sinkless := changefeedStmt.SinkURI == nil
+
+	if !sinkless {
+		if err := utilccl.CheckEnterpriseEnabled(
+			p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(), "CHANGEFEED",
+		); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := p.RequireSuperUser(stmt.StatementTag()); err != nil {
+		return nil, nil, err
+	}
+
+	if changefeedStmt.Targets.Databases != nil {
+		return nil, nil, errors.New("CHANGEFEED cannot target databases")
+	}
+
+	optsFn, err := p.TypeAsStringOpts(changefeedStmt.Options, changefeedOptionExpectValues)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if sinkless {
+		header := sqlbase.ResultColumns{
+			{Name: "table", Typ: types.String},
+			{Name: "key", Typ: types.Bytes},
+			{Name: "value", Typ: types.Bytes},
+		}
+		fn := func(ctx context.Context, resultsCh chan<- tree.Datums) error {
+			opts, err := optsFn()
+			if err != nil {
+				return err
+			}
+			details, _, err := makeChangefeedDetails(ctx, p, changefeedStmt.Targets, opts)
+			if err != nil {
+				return err
+			}
+			cf := &changefeed{
+				db:      p.ExecCfg().DB,
+				details: details,
+				sink:    &sqlSink{resultsCh: resultsCh},
+			}
+			return cf.run(ctx, p.ExecCfg().Settings)
+		}
+		return fn, header, nil
+	}
+
+	sinkURIFn, err := p.TypeAsString(changefeedStmt.SinkURI, stmt.StatementTag())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := sqlbase.ResultColumns{
+		{Name: "job_id", Typ: types.Int},
+	}
+
+	fn := func(ctx context.Context, resultsCh chan<- tree.Datums) error {
+		sinkURI, err := sinkURIFn()
+		if err != nil {
+			return err
+		}
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+
+		details, tableNames, err := makeChangefeedDetails(ctx, p, changefeedStmt.Targets, opts)
+		if err != nil {
+			return err
+		}
+		details.SinkURI = sinkURI
+
+		// Connect to the sink up front so that a bad URI or an unreachable sink
+		// is reported to the user instead of failing the job later.
+		sink, err := getChangefeedSink(sinkURI, tableNames)
+		if err != nil {
+			return err
+		}
+		if err := sink.Close(); err != nil {
+			return err
+		}
+
+		description, err := changefeedJobDescription(changefeedStmt, sinkURI)
+		if err != nil {
+			return err
+		}
+		job := p.ExecCfg().JobRegistry.NewJob(jobs.Record{
+			Description:   description,
+			Username:      p.User(),
+			DescriptorIDs: details.TableIDs,
+			Details:       details,
+		})
+
+		// The changefeed outlives this statement, so it runs under a context
+		// that is not canceled when the statement finishes.
+		bgCtx := p.ExecCfg().AmbientCtx.AnnotateCtx(context.Background())
+		jobCtx, cancel := context.WithCancel(bgCtx)
+		if err := job.Created(jobCtx, cancel); err != nil {
+			cancel()
+			return err
+		}
+		if err := job.Started(jobCtx); err != nil {
+			cancel()
+			return err
+		}
+		if err := p.ExecCfg().DistSQLSrv.Stopper.RunAsyncTask(jobCtx, "changefeed", func(ctx context.Context) {
+			defer cancel()
+			changefeedErr := runChangefeed(ctx, job, p.ExecCfg().Settings, details, tableNames)
+			// The job context may have been canceled, so record the outcome
+			// using the background context.
+			if err := job.FinishedWith(bgCtx, changefeedErr); err != nil {
+				log.Errorf(bgCtx, "changefeed job %d: ignoring FinishedWith error: %+v", *job.ID(), err)
+			}
+		}); err != nil {
+			cancel()
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(*job.ID()))}:
+			return nil
+		}
+	}
+	return fn, header, nil
+}
+
+// makeChangefeedDetails returns the details of a changefeed, without its sink,
+// for the given targets and options, along with the names of its tables.
+func makeChangefeedDetails(
+	ctx context.Context, p sql.PlanHookState, targets tree.TargetList, opts map[string]string,
+) (jobs.ChangefeedDetails, []string, error) {
+	var details jobs.ChangefeedDetails
+	_, details.Updated = opts[changefeedOptUpdated]
+	if override, ok := opts[changefeedOptResolved]; ok {
+		interval, err := time.ParseDuration(override)
+		if err != nil {
+			return details, nil, errors.Wrapf(err, "invalid %s value", changefeedOptResolved)
+		}
+		if interval <= 0 {
+			return details, nil, errors.Errorf(
+				"invalid %s value %s: must be positive", changefeedOptResolved, override)
+		}
+		details.ResolvedIntervalNanos = interval.Nanoseconds()
+	}
+	var err error
+	if details.Envelope, err = changefeedEnumOption(opts, changefeedOptEnvelope,
+		changefeedEnvelopeRow, changefeedEnvelopeKeyOnly, changefeedEnvelopeWrapped,
+	); err != nil {
+		return details, nil, err
+	}
+	if details.Format, err = changefeedEnumOption(opts, changefeedOptFormat,
+		changefeedFormatJSON, changefeedFormatAvro,
+	); err != nil {
+		return details, nil, err
+	}
+	if details.SchemaChangePolicy, err = changefeedEnumOption(opts, changefeedOptSchemaChangePolicy,
+		changefeedSchemaChangeBackfill, changefeedSchemaChangeStop, changefeedSchemaChangeIgnore,
+	); err != nil {
+		return details, nil, err
+	}
+	details.ConfluentSchemaRegistry = opts[changefeedOptConfluentSchemaRegistry]
+	if err := validateChangefeedEncoding(details); err != nil {
+		return details, nil, err
+	}
+
+	statementTime := p.ExecCfg().Clock.Now()
+	targetDescs, _, err := resolveTargetsToDescriptors(ctx, p, statementTime, targets)
+	if err != nil {
+		return details, nil, err
+	}
+	var tableNames []string
+	for _, desc := range targetDescs {
+		tableDesc := desc.GetTable()
+		if tableDesc == nil {
+			continue
+		}
+		if err := validateChangefeedTable(tableDesc); err != nil {
+			return details, nil, err
+		}
+		details.TableIDs = append(details.TableIDs, tableDesc.ID)
+		tableNames = append(tableNames, tableDesc.Name)
+	}
+	return details, tableNames, nil
+}
+
+func changefeedJobDescription(changefeed *tree.CreateChangefeed, sinkURI string) (string, error) {
+	c := &tree.CreateChangefeed{
+		Targets: changefeed.Targets,
+		SinkURI: tree.NewDString(sinkURI),
+		Options: changefeed.Options,
+	}
+	return tree.AsStringWithFlags(c, tree.FmtSimpleQualified), nil
+}
+
+// changefeedEnumOption returns the value of the given option, which must be
+// one of the given values, the first of which is the default.
+func changefeedEnumOption(opts map[string]string, name string, values ...string) (string, error) {
+	value, ok := opts[name]
+	if !ok {
+		return values[0], nil
+	}
+	for _, v := range values {
+		if value == v {
+			return value, nil
+		}
+	}
+	return "", errors.Errorf("unknown %s: %s", name, value)
+}
+
+// validateChangefeedEncoding returns an error if the envelope and the format
+// of a changefeed cannot be combined with its other options.
+func validateChangefeedEncoding(details jobs.ChangefeedDetails) error {
+	if details.Envelope == changefeedEnvelopeKeyOnly && details.Updated {
+		return errors.Errorf("%s is not supported with %s=%s",
+			changefeedOptUpdated, changefeedOptEnvelope, changefeedEnvelopeKeyOnly)
+	}
+	if details.Format != changefeedFormatAvro {
+		if details.ConfluentSchemaRegistry != "" {
+			return errors.Errorf("%s requires %s=%s",
+				changefeedOptConfluentSchemaRegistry, changefeedOptFormat, changefeedFormatAvro)
+		}
+		return nil
+	}
+	if details.ConfluentSchemaRegistry == "" {
+		return errors.Errorf("%s=%s requires %s",
+			changefeedOptFormat, changefeedFormatAvro, changefeedOptConfluentSchemaRegistry)
+	}
+	if details.Envelope == changefeedEnvelopeWrapped {
+		return errors.Errorf("%s=%s is not supported with %s=%s",
+			changefeedOptEnvelope, changefeedEnvelopeWrapped, changefeedOptFormat, changefeedFormatAvro)
+	}
+	if details.Updated {
+		return errors.Errorf("%s is not supported with %s=%s",
+			changefeedOptUpdated, changefeedOptFormat, changefeedFormatAvro)
+	}
+	if details.ResolvedIntervalNanos != 0 {
+		return errors.Errorf("%s is not supported with %s=%s",
+			changefeedOptResolved, changefeedOptFormat, changefeedFormatAvro)
+	}
+	return nil
+}
+
+// validateChangefeedTable returns an error if the given table cannot be
+// watched by a changefeed.
+func validateChangefeedTable(desc *sqlbase.TableDescriptor) error {
+	if desc.IsView() {
+		return errors.Errorf("CHANGEFEED cannot target views: %s", desc.Name)
+	}
+	if desc.IsSequence() {
+		return errors.Errorf("CHANGEFEED cannot target sequences: %s", desc.Name)
+	}
+	if len(desc.Families) != 1 {
+		return errors.Errorf(
+			"CHANGEFEEDs are currently supported on tables with exactly 1 column family: %s has %d",
+			desc.Name, len(desc.Families))
+	}
+	if desc.IsInterleaved() || len(desc.PrimaryIndex.InterleavedBy) > 0 {
+		return errors.Errorf("CHANGEFEEDs are currently not supported on interleaved tables: %s", desc.Name)
+	}
+	if desc.Dropped() {
+		return errors.Errorf("CHANGEFEED cannot target a dropped table: %s", desc.Name)
+	}
+	return nil
+}
+
+// runChangefeed emits the changes of the job's tables to its sink until the
+// context is canceled or an error occurs.
+func runChangefeed(
+	ctx context.Context,
+	job *jobs.Job,
+	settings *cluster.Settings,
+	details jobs.ChangefeedDetails,
+	tableNames []string,
+) error {
+	sink, err := getChangefeedSink(details.SinkURI, tableNames)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := sink.Close(); err != nil {
+			log.Warningf(ctx, "failed to close changefeed sink: %+v", err)
+		}
+	}()
+
+	cf := &changefeed{
+		db:       job.DB(),
+		details:  details,
+		sink:     sink,
+		progress: job,
+		jobID:    *job.ID(),
+	}
+	defer func() {
+		// The context may have been canceled, which must not prevent the
+		// release of the protected timestamp.
+		if err := cf.releaseProtectedTimestamp(context.Background()); err != nil {
+			log.Warningf(ctx, "failed to release changefeed protected timestamp: %+v", err)
+		}
+	}()
+	return cf.run(ctx, settings)
+}
+
+// changefeedEvent is a change to a row, encoded for emission to a sink.
+type changefeedEvent struct {
+	topic string
+	key   []byte
+	// value is nil for a deletion, unless the envelope is wrapped.
+	value []byte
+	ts    hlc.Timestamp
+}
+
+// changefeedProgress receives the highwater timestamp of a changefeed every
+// time it advances. It is satisfied by *jobs.Job.
+type changefeedProgress interface {
+	Progressed(ctx context.Context, fractionCompleted float32, progressedFn jobs.ProgressedFn) error
+}
+
+// changefeed polls the primary indexes of its tables for the changes made
+// since the previous poll and emits them to a sink.
+type changefeed struct {
+	db       *client.DB
+	details  jobs.ChangefeedDetails
+	sink     changefeedSink
+	progress changefeedProgress
+	// jobID is the ID of the job running the changefeed, if any. The job
+	// protects the history of the tables after the highwater from garbage
+	// collection, so that a changefeed falling behind doesn't lose changes.
+	jobID int64
+
+	// encoder is created by the first poll.
+	encoder changefeedEncoder
+	// columns are the columns of each table at the last poll, which tell the
+	// polls apart by the schema changes made in between.
+	columns map[sqlbase.ID][]sqlbase.ColumnDescriptor
+	// lastResolved is when a resolved timestamp was last emitted.
+	lastResolved time.Time
+	alloc        sqlbase.DatumAlloc
+}
+
+func (cf *changefeed) run(ctx context.Context, st *cluster.Settings) error {
+	for {
+		if err := cf.poll(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(changefeedPollInterval.Get(&st.SV)):
+		}
+	}
+}
+
+// poll emits every change in the watched tables between the current highwater
+// and now, in timestamp order, and then advances the highwater. If the
+// highwater is unset, the current contents of the tables are emitted instead.
+// The contents of a table whose columns changed since the previous poll are
+// emitted again after its changes, unless the schema change policy says
+// otherwise.
+func (cf *changefeed) poll(ctx context.Context) error {
+	if cf.encoder == nil {
+		var err error
+		if cf.encoder, err = makeChangefeedEncoder(cf.details); err != nil {
+			return err
+		}
+	}
+
+	var tables []*sqlbase.TableDescriptor
+	var nextHighwater hlc.Timestamp
+	if err := cf.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		tables = tables[:0]
+		for _, id := range cf.details.TableIDs {
+			desc, err := sqlbase.GetTableDescFromID(ctx, txn, id)
+			if err != nil {
+				return err
+			}
+			if err := validateChangefeedTable(desc); err != nil {
+				return err
+			}
+			tables = append(tables, desc)
+		}
+		nextHighwater = txn.OrigTimestamp()
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var events []changefeedEvent
+	for _, desc := range tables {
+		tableEvents, err := cf.fetchChanges(ctx, desc, nextHighwater, false /* backfill */)
+		if err != nil {
+			return err
+		}
+		events = append(events, tableEvents...)
+	}
+	for _, desc := range cf.schemaChanged(tables) {
+		switch cf.details.SchemaChangePolicy {
+		case changefeedSchemaChangeStop:
+			return errors.Errorf("the columns of %s changed, stopping the changefeed", desc.Name)
+		case changefeedSchemaChangeIgnore:
+		default:
+			tableEvents, err := cf.fetchChanges(ctx, desc, nextHighwater, true /* backfill */)
+			if err != nil {
+				return err
+			}
+			events = append(events, tableEvents...)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].ts.Less(events[j].ts) })
+
+	for _, event := range events {
+		if err := cf.sink.EmitRow(ctx, event.topic, event.key, event.value); err != nil {
+			return err
+		}
+	}
+	if err := cf.sink.Flush(ctx); err != nil {
+		return err
+	}
+	cf.details.Highwater = nextHighwater
+	cf.columns = make(map[sqlbase.ID][]sqlbase.ColumnDescriptor, len(tables))
+	for _, desc := range tables {
+		cf.columns[desc.ID] = desc.Columns
+	}
+
+	if cf.progress != nil {
+		if err := cf.progress.Progressed(ctx, 0, func(_ context.Context, details interface{}) {
+			details.(*jobs.Payload_Changefeed).Changefeed.Highwater = nextHighwater
+		}); err != nil {
+			return err
+		}
+	}
+	if cf.jobID != 0 {
+		spans := make([]roachpb.Span, len(tables))
+		for i, desc := range tables {
+			spans[i] = desc.PrimaryIndexSpan()
+		}
+		if err := cf.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return protectedts.Protect(ctx, txn, protectedts.MakeJobRecord(cf.jobID, nextHighwater, spans))
+		}); err != nil {
+			return errors.Wrap(err, "protecting changefeed highwater")
+		}
+	}
+
+	resolvedInterval := time.Duration(cf.details.ResolvedIntervalNanos)
+	if resolvedInterval > 0 && timeutil.Since(cf.lastResolved) >= resolvedInterval {
+		payload, err := encodeResolvedTimestamp(nextHighwater)
+		if err != nil {
+			return err
+		}
+		if err := cf.sink.EmitResolvedTimestamp(ctx, payload); err != nil {
+			return err
+		}
+		if err := cf.sink.Flush(ctx); err != nil {
+			return err
+		}
+		cf.lastResolved = timeutil.Now()
+	}
+	return nil
+}
+
+// releaseProtectedTimestamp releases the protected timestamp of the job
+// running the changefeed, if any.
+func (cf *changefeed) releaseProtectedTimestamp(ctx context.Context) error {
+	if cf.jobID == 0 {
+		return nil
+	}
+	err := cf.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		return protectedts.Release(ctx, txn, protectedts.JobRecordID(cf.jobID))
+	})
+	if err == protectedts.ErrNotFound {
+		// The changefeed stopped before its first poll completed.
+		return nil
+	}
+	return err
+}
+
+// schemaChanged returns the tables whose columns changed since the previous
+// poll.
+func (cf *changefeed) schemaChanged(tables []*sqlbase.TableDescriptor) []*sqlbase.TableDescriptor {
+	var changed []*sqlbase.TableDescriptor
+	for _, desc := range tables {
+		prev, ok := cf.columns[desc.ID]
+		if ok && changefeedColumnsChanged(prev, desc.Columns) {
+			changed = append(changed, desc)
+		}
+	}
+	return changed
+}
+
+// changefeedColumnsChanged returns whether the rows of a table with the
+// columns cur are emitted differently than with the columns prev.
+func changefeedColumnsChanged(prev, cur []sqlbase.ColumnDescriptor) bool {
+	if len(prev) != len(cur) {
+		return true
+	}
+	for i := range prev {
+		if prev[i].ID != cur[i].ID || prev[i].Name != cur[i].Name ||
+			prev[i].Type.SQLString() != cur[i].Type.SQLString() {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchChanges returns the changes to the given table's rows in (highwater,
+// end]. If backfill is set, the rows of the table at end are returned
+// instead, as changes at end.
+func (cf *changefeed) fetchChanges(
+	ctx context.Context, desc *sqlbase.TableDescriptor, end hlc.Timestamp, backfill bool,
+) ([]changefeedEvent, error) {
+	req := &roachpb.ExportRequest{
+		Span:       desc.PrimaryIndexSpan(),
+		StartTime:  cf.details.Highwater,
+		MVCCFilter: roachpb.MVCCFilter_All,
+		ReturnSST:  true,
+	}
+	if backfill {
+		req.StartTime = hlc.Timestamp{}
+	}
+	if req.StartTime == (hlc.Timestamp{}) {
+		// Emit the current contents of the table.
+		req.MVCCFilter = roachpb.MVCCFilter_Latest
+	}
+	res, pErr := client.SendWrappedWith(ctx, cf.db.GetSender(), roachpb.Header{Timestamp: end}, req)
+	if pErr != nil {
+		return nil, errors.Wrapf(pErr.GoError(), "fetching changes for %s", desc.Name)
+	}
+
+	decoder, err := makeChangefeedRowDecoder(desc, &cf.alloc)
+	if err != nil {
+		return nil, err
+	}
+	var rows []changefeedRow
+	var rowKeys []roachpb.Key
+	for _, file := range res.(*roachpb.ExportResponse).Files {
+		if err := forEachSSTKV(file.SST, func(kv roachpb.KeyValue) error {
+			key, row, err := decoder.decode(ctx, kv)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, changefeedRow{key: key, after: row, ts: kv.Value.Timestamp})
+			rowKeys = append(rowKeys, append(roachpb.Key(nil), kv.Key...))
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if cf.details.Envelope == changefeedEnvelopeWrapped && req.StartTime != (hlc.Timestamp{}) {
+		if err := cf.fetchBefore(ctx, decoder, rows, rowKeys); err != nil {
+			return nil, err
+		}
+	}
+
+	events := make([]changefeedEvent, len(rows))
+	for i, row := range rows {
+		if backfill {
+			row.ts = end
+		}
+		event := changefeedEvent{topic: desc.Name, ts: row.ts}
+		if event.key, err = cf.encoder.encodeKey(ctx, desc, row.key); err != nil {
+			return nil, err
+		}
+		if event.value, err = cf.encoder.encodeValue(ctx, desc, row); err != nil {
+			return nil, err
+		}
+		events[i] = event
+	}
+	return events, nil
+}
+
+// fetchBefore sets the row before each of the given changes. The versions of
+// a key are iterated from the newest to the oldest, so the row before a change
+// is the next one of the same key, except for the oldest change of a key,
+// before which the row is read at the highwater.
+func (cf *changefeed) fetchBefore(
+	ctx context.Context, decoder *changefeedRowDecoder, rows []changefeedRow, rowKeys []roachpb.Key,
+) error {
+	var oldest []int
+	for i := range rows {
+		if i+1 < len(rows) && rowKeys[i].Equal(rowKeys[i+1]) {
+			rows[i].before = rows[i+1].after
+		} else {
+			oldest = append(oldest, i)
+		}
+	}
+	if len(oldest) == 0 {
+		return nil
+	}
+	return cf.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		txn.SetFixedTimestamp(ctx, cf.details.Highwater)
+		b := txn.NewBatch()
+		for _, i := range oldest {
+			b.Get(rowKeys[i])
+		}
+		if err := txn.Run(ctx, b); err != nil {
+			return err
+		}
+		for j, i := range oldest {
+			kv := b.Results[j].Rows[0]
+			rows[i].before = nil
+			if kv.Value == nil {
+				continue
+			}
+			_, before, err := decoder.decode(ctx, roachpb.KeyValue{Key: kv.Key, Value: *kv.Value})
+			if err != nil {
+				return err
+			}
+			rows[i].before = before
+		}
+		return nil
+	})
+}
+
+// forEachSSTKV invokes fn on every key/value pair of the given SST.
+func forEachSSTKV(data []byte, fn func(roachpb.KeyValue) error) error {
+	sst := engine.MakeRocksDBSstFileReader()
+	defer sst.Close()
+	if err := sst.IngestExternalFile(data); err != nil {
+		return err
+	}
+	start, end := engine.MVCCKey{Key: keys.MinKey}, engine.MVCCKey{Key: keys.MaxKey}
+	return sst.Iterate(start, end, func(kv engine.MVCCKeyValue) (bool, error) {
+		return false, fn(roachpb.KeyValue{
+			Key:   kv.Key.Key,
+			Value: roachpb.Value{RawBytes: kv.Value, Timestamp: kv.Key.Timestamp},
+		})
+	})
+}
+
+// changefeedRowDecoder decodes the key/value pairs of a table's primary index
+// into rows. Only tables with a single column family are supported, so every
+// key/value pair is a complete row.
+type changefeedRowDecoder struct {
+	desc     *sqlbase.TableDescriptor
+	keyTypes []sqlbase.ColumnType
+	keyDirs  []encoding.Direction
+	keyVals  []sqlbase.EncDatum
+	alloc    *sqlbase.DatumAlloc
+	fetcher  sqlbase.MultiRowFetcher
+}
+
+func makeChangefeedRowDecoder(
+	desc *sqlbase.TableDescriptor, alloc *sqlbase.DatumAlloc,
+) (*changefeedRowDecoder, error) {
+	d := &changefeedRowDecoder{desc: desc, alloc: alloc}
+	var err error
+	d.keyTypes, err = sqlbase.GetColumnTypes(desc, desc.PrimaryIndex.ColumnIDs)
+	if err != nil {
+		return nil, err
+	}
+	d.keyDirs = make([]encoding.Direction, len(desc.PrimaryIndex.ColumnDirections))
+	for i, dir := range desc.PrimaryIndex.ColumnDirections {
+		if d.keyDirs[i], err = dir.ToEncodingDirection(); err != nil {
+			return nil, err
+		}
+	}
+	d.keyVals = make([]sqlbase.EncDatum, len(d.keyTypes))
+
+	var valNeededForCol util.FastIntSet
+	valNeededForCol.AddRange(0, len(desc.Columns)-1)
+	if err := d.fetcher.Init(
+		false /* reverse */, false /* returnRangeInfo */, alloc,
+		sqlbase.MultiRowFetcherTableArgs{
+			Desc:             desc,
+			Index:            &desc.PrimaryIndex,
+			ColIdxMap:        sqlbase.ColIDtoRowIndexFromCols(desc.Columns),
+			IsSecondaryIndex: false,
+			Cols:             desc.Columns,
+			ValNeededForCol:  valNeededForCol,
+		},
+	); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// decode returns the primary key of the row in the given key/value pair and,
+// unless the row was deleted, the row itself.
+func (d *changefeedRowDecoder) decode(
+	ctx context.Context, kv roachpb.KeyValue,
+) (tree.Datums, tree.Datums, error) {
+	_, matches, err := sqlbase.DecodeIndexKey(
+		d.desc, &d.desc.PrimaryIndex, d.keyTypes, d.keyVals, d.keyDirs, kv.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !matches {
+		return nil, nil, errors.Errorf("unexpected key %s in table %s", kv.Key, d.desc.Name)
+	}
+	key := make(tree.Datums, len(d.keyVals))
+	for i := range d.keyVals {
+		if err := d.keyVals[i].EnsureDecoded(&d.keyTypes[i], d.alloc); err != nil {
+			return nil, nil, err
+		}
+		key[i] = d.keyVals[i].Datum
+	}
+
+	if len(kv.Value.RawBytes) == 0 {
+		// A deletion.
+		return key, nil, nil
+	}
+	if err := d.fetcher.StartScanFrom(ctx, &sqlbase.SpanKVFetcher{KVs: []roachpb.KeyValue{kv}}); err != nil {
+		return nil, nil, err
+	}
+	row, _, _, err := d.fetcher.NextRowDecoded(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, append(tree.Datums(nil), row...), nil
+}
+
+func changefeedResumeHook(
+	typ jobs.Type, settings *cluster.Settings,
+) func(context.Context, *jobs.Job) error {
+	if typ != jobs.TypeChangefeed {
+		return nil
+	}
+
+	return func(ctx context.Context, job *jobs.Job) error {
+		details := job.Record.Details.(jobs.ChangefeedDetails)
+
+		var tableNames []string
+		if err := job.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			tableNames = tableNames[:0]
+			for _, id := range details.TableIDs {
+				desc, err := sqlbase.GetTableDescFromID(ctx, txn, id)
+				if err != nil {
+					return err
+				}
+				tableNames = append(tableNames, desc.Name)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		if err := job.Created(ctx, cancel); err != nil {
+			return err
+		}
+		return runChangefeed(ctx, job, settings, details, tableNames)
+	}
+}
+
+func init() {
+	sql.AddPlanHook(changefeedPlanHook)
+	jobs.AddResumeHook(changefeedResumeHook)
+}
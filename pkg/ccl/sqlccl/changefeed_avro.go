@@ -0,0 +1,295 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlccl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// confluentAvroMagic is the first byte of the messages in the Confluent wire
+// format, followed by the ID of the schema of the message in the registry, as
+// a big-endian 32-bit integer, and then by the message in the Avro binary
+// encoding.
+const confluentAvroMagic = 0
+
+// avroSchemaRegistryTimeout bounds the requests to the schema registry.
+const avroSchemaRegistryTimeout = 10 * time.Second
+
+// avroEncoder encodes keys and rows as Avro records in the Confluent wire
+// format. The schemas of the records of a table are registered in the schema
+// registry under the subjects <table>-key and <table>-value, the first time a
+// version of the table is emitted.
+type avroEncoder struct {
+	envelope string
+	registry *url.URL
+	client   *http.Client
+
+	schemas map[sqlbase.ID]*avroTableSchemas
+}
+
+var _ changefeedEncoder = &avroEncoder{}
+
+// avroTableSchemas are the registered schemas of a version of a table.
+type avroTableSchemas struct {
+	version sqlbase.DescriptorVersion
+	key     *avroRecord
+	keyID   int32
+	value   *avroRecord
+	valueID int32
+}
+
+func makeAvroEncoder(envelope string, registry string) (*avroEncoder, error) {
+	u, err := url.Parse(registry)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", changefeedOptConfluentSchemaRegistry)
+	}
+	return &avroEncoder{
+		envelope: envelope,
+		registry: u,
+		client:   &http.Client{Timeout: avroSchemaRegistryTimeout},
+		schemas:  make(map[sqlbase.ID]*avroTableSchemas),
+	}, nil
+}
+
+// encodeKey implements the changefeedEncoder interface.
+func (e *avroEncoder) encodeKey(
+	ctx context.Context, desc *sqlbase.TableDescriptor, key tree.Datums,
+) ([]byte, error) {
+	schemas, err := e.tableSchemas(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	return schemas.key.encode(schemas.keyID, key)
+}
+
+// encodeValue implements the changefeedEncoder interface. With the key_only
+// envelope, the value of a change that isn't a deletion is empty.
+func (e *avroEncoder) encodeValue(
+	ctx context.Context, desc *sqlbase.TableDescriptor, row changefeedRow,
+) ([]byte, error) {
+	if row.after == nil {
+		return nil, nil
+	}
+	if e.envelope == changefeedEnvelopeKeyOnly {
+		return []byte{}, nil
+	}
+	schemas, err := e.tableSchemas(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	return schemas.value.encode(schemas.valueID, row.after)
+}
+
+// tableSchemas returns the schemas of the current version of a table,
+// registering them if needed.
+func (e *avroEncoder) tableSchemas(
+	ctx context.Context, desc *sqlbase.TableDescriptor,
+) (*avroTableSchemas, error) {
+	if schemas, ok := e.schemas[desc.ID]; ok && schemas.version == desc.Version {
+		return schemas, nil
+	}
+	keyCols := make([]sqlbase.ColumnDescriptor, len(desc.PrimaryIndex.ColumnIDs))
+	for i, id := range desc.PrimaryIndex.ColumnIDs {
+		col, err := desc.FindColumnByID(id)
+		if err != nil {
+			return nil, err
+		}
+		keyCols[i] = *col
+	}
+	schemas := &avroTableSchemas{
+		version: desc.Version,
+		key:     makeAvroRecord(desc.Name+"_key", keyCols),
+		value:   makeAvroRecord(desc.Name, desc.Columns),
+	}
+	var err error
+	if schemas.keyID, err = e.register(ctx, desc.Name+"-key", schemas.key); err != nil {
+		return nil, err
+	}
+	if e.envelope != changefeedEnvelopeKeyOnly {
+		if schemas.valueID, err = e.register(ctx, desc.Name+"-value", schemas.value); err != nil {
+			return nil, err
+		}
+	}
+	e.schemas[desc.ID] = schemas
+	return schemas, nil
+}
+
+// register registers the schema of a record under the given subject, and
+// returns its ID. Registering a schema again returns the same ID.
+func (e *avroEncoder) register(ctx context.Context, subject string, r *avroRecord) (int32, error) {
+	schema, err := json.Marshal(r)
+	if err != nil {
+		return 0, err
+	}
+	body, err := json.Marshal(map[string]string{"schema": string(schema)})
+	if err != nil {
+		return 0, err
+	}
+	u := *e.registry
+	u.Path = path.Join(u.Path, "subjects", subject, "versions")
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	resp, err := e.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, errors.Wrapf(err, "registering the avro schema of %s", subject)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return 0, errors.Errorf("registering the avro schema of %s: %s %q", subject, resp.Status, msg)
+	}
+	var res struct {
+		ID int32 `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return 0, errors.Wrapf(err, "registering the avro schema of %s", subject)
+	}
+	return res.ID, nil
+}
+
+// avroRecord is the Avro schema of a record with a field per column. It is
+// marshaled by encoding/json into the JSON form of the schema.
+type avroRecord struct {
+	Type   string       `json:"type"`
+	Name   string       `json:"name"`
+	Fields []*avroField `json:"fields"`
+}
+
+// avroField is a field of an avroRecord. The field of a nullable column is a
+// union of null and the type of the column.
+type avroField struct {
+	Name string      `json:"name"`
+	Type interface{} `json:"type"`
+
+	nullable bool
+	encode   func(buf []byte, d tree.Datum) []byte
+}
+
+func makeAvroRecord(name string, cols []sqlbase.ColumnDescriptor) *avroRecord {
+	r := &avroRecord{Type: "record", Name: avroName(name)}
+	for _, col := range cols {
+		f := &avroField{Name: avroName(col.Name), nullable: col.Nullable}
+		var typ string
+		switch col.Type.SemanticType {
+		case sqlbase.ColumnType_BOOL:
+			typ, f.encode = "boolean", appendAvroBool
+		case sqlbase.ColumnType_INT:
+			typ, f.encode = "long", appendAvroInt
+		case sqlbase.ColumnType_FLOAT:
+			typ, f.encode = "double", appendAvroFloat
+		case sqlbase.ColumnType_BYTES:
+			typ, f.encode = "bytes", appendAvroDBytes
+		default:
+			// Types without a natural Avro representation are encoded as their
+			// string representation, as in JSON.
+			typ, f.encode = "string", appendAvroString
+		}
+		f.Type = typ
+		if f.nullable {
+			f.Type = []string{"null", typ}
+		}
+		r.Fields = append(r.Fields, f)
+	}
+	return r
+}
+
+// encode returns the given values of the fields of the record, in the
+// Confluent wire format with the given schema ID.
+func (r *avroRecord) encode(schemaID int32, values tree.Datums) ([]byte, error) {
+	buf := []byte{confluentAvroMagic, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(buf[1:], uint32(schemaID))
+	for i, f := range r.Fields {
+		d := values[i]
+		if f.nullable {
+			// The index of the branch of the union.
+			if d == tree.DNull {
+				buf = appendAvroLong(buf, 0)
+				continue
+			}
+			buf = appendAvroLong(buf, 1)
+		} else if d == tree.DNull {
+			return nil, errors.Errorf("unexpected NULL in non-nullable field %s", f.Name)
+		}
+		buf = f.encode(buf, d)
+	}
+	return buf, nil
+}
+
+// avroName returns a valid Avro name for the given name, whose characters
+// other than ASCII letters, digits and underscores are replaced by
+// underscores.
+func avroName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')) {
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 {
+		return "_"
+	}
+	return string(b)
+}
+
+// appendAvroLong appends the zig-zag varint encoding of a long.
+func appendAvroLong(buf []byte, v int64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
+
+func appendAvroBytes(buf []byte, b []byte) []byte {
+	buf = appendAvroLong(buf, int64(len(b)))
+	return append(buf, b...)
+}
+
+func appendAvroBool(buf []byte, d tree.Datum) []byte {
+	if *d.(*tree.DBool) {
+		return append(buf, 1)
+	}
+	return append(buf, 0)
+}
+
+func appendAvroInt(buf []byte, d tree.Datum) []byte {
+	return appendAvroLong(buf, int64(tree.MustBeDInt(d)))
+}
+
+func appendAvroFloat(buf []byte, d tree.Datum) []byte {
+	var tmp [8]byte
+	binary.LittleEndian.PutUint64(tmp[:], math.Float64bits(float64(*d.(*tree.DFloat))))
+	return append(buf, tmp[:]...)
+}
+
+func appendAvroDBytes(buf []byte, d tree.Datum) []byte {
+	return appendAvroBytes(buf, []byte(*d.(*tree.DBytes)))
+}
+
+func appendAvroString(buf []byte, d tree.Datum) []byte {
+	if s, ok := tree.AsDString(d); ok {
+		return appendAvroBytes(buf, []byte(s))
+	}
+	return appendAvroBytes(buf, []byte(tree.AsStringWithFlags(d, tree.FmtBareStrings)))
+}
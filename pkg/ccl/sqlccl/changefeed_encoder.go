@@ -0,0 +1,189 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlccl
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/cockroachdb/apd"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+const (
+	// changefeedWrappedAfter and changefeedWrappedBefore are the fields of the
+	// values emitted with the wrapped envelope holding the row after and
+	// before the change.
+	changefeedWrappedAfter  = "after"
+	changefeedWrappedBefore = "before"
+)
+
+// changefeedRow is a decoded change to a row.
+type changefeedRow struct {
+	key tree.Datums
+	// after is the row after the change, or nil if it was deleted.
+	after tree.Datums
+	// before is the row before the change, or nil if it didn't exist. It is
+	// only fetched for the wrapped envelope.
+	before tree.Datums
+	// ts is the timestamp of the change.
+	ts hlc.Timestamp
+}
+
+// changefeedEncoder encodes the keys and the values of the messages emitted
+// by a changefeed, according to its envelope and format.
+type changefeedEncoder interface {
+	// encodeKey encodes the primary key of a row of the given table.
+	encodeKey(ctx context.Context, desc *sqlbase.TableDescriptor, key tree.Datums) ([]byte, error)
+	// encodeValue encodes a change to a row of the given table. It returns
+	// nil for a deletion, unless the envelope is wrapped.
+	encodeValue(ctx context.Context, desc *sqlbase.TableDescriptor, row changefeedRow) ([]byte, error)
+}
+
+func makeChangefeedEncoder(details jobs.ChangefeedDetails) (changefeedEncoder, error) {
+	if details.Format == changefeedFormatAvro {
+		return makeAvroEncoder(details.Envelope, details.ConfluentSchemaRegistry)
+	}
+	return &jsonEncoder{envelope: details.Envelope, updated: details.Updated}, nil
+}
+
+// jsonEncoder encodes keys as JSON arrays of their values, and rows as JSON
+// objects from column names to values.
+type jsonEncoder struct {
+	envelope string
+	// updated is whether the values include the timestamp of the change.
+	updated bool
+}
+
+var _ changefeedEncoder = &jsonEncoder{}
+
+// encodeKey implements the changefeedEncoder interface.
+func (e *jsonEncoder) encodeKey(
+	_ context.Context, _ *sqlbase.TableDescriptor, key tree.Datums,
+) ([]byte, error) {
+	return encodeChangefeedKey(key)
+}
+
+// encodeValue implements the changefeedEncoder interface. With the key_only
+// envelope, the value of a change that isn't a deletion is empty.
+func (e *jsonEncoder) encodeValue(
+	_ context.Context, desc *sqlbase.TableDescriptor, row changefeedRow,
+) ([]byte, error) {
+	var updated hlc.Timestamp
+	if e.updated {
+		updated = row.ts
+	}
+	switch e.envelope {
+	case changefeedEnvelopeKeyOnly:
+		if row.after == nil {
+			return nil, nil
+		}
+		return []byte{}, nil
+	case changefeedEnvelopeWrapped:
+		values := map[string]interface{}{
+			changefeedWrappedAfter:  nil,
+			changefeedWrappedBefore: nil,
+		}
+		if row.after != nil {
+			values[changefeedWrappedAfter] = changefeedRowToJSON(desc, row.after)
+		}
+		if row.before != nil {
+			values[changefeedWrappedBefore] = changefeedRowToJSON(desc, row.before)
+		}
+		addChangefeedMetadata(values, updated)
+		return json.Marshal(values)
+	default:
+		if row.after == nil {
+			return nil, nil
+		}
+		return encodeChangefeedRow(desc, row.after, updated)
+	}
+}
+
+// encodeChangefeedKey encodes a primary key as a JSON array of its values.
+func encodeChangefeedKey(key tree.Datums) ([]byte, error) {
+	values := make([]interface{}, len(key))
+	for i, d := range key {
+		values[i] = datumToJSONValue(d)
+	}
+	return json.Marshal(values)
+}
+
+// encodeChangefeedRow encodes a row as a JSON object from column names to
+// values. If updated is set, it is included in the metadata of the row.
+func encodeChangefeedRow(
+	desc *sqlbase.TableDescriptor, row tree.Datums, updated hlc.Timestamp,
+) ([]byte, error) {
+	values := changefeedRowToJSON(desc, row)
+	addChangefeedMetadata(values, updated)
+	return json.Marshal(values)
+}
+
+// changefeedRowToJSON returns the JSON object from column names to values of
+// a row.
+func changefeedRowToJSON(desc *sqlbase.TableDescriptor, row tree.Datums) map[string]interface{} {
+	values := make(map[string]interface{}, len(row)+1)
+	for i, d := range row {
+		values[desc.Columns[i].Name] = datumToJSONValue(d)
+	}
+	return values
+}
+
+// addChangefeedMetadata adds the metadata of a row to its JSON object. If
+// updated is set, it is included in the metadata.
+func addChangefeedMetadata(values map[string]interface{}, updated hlc.Timestamp) {
+	if updated != (hlc.Timestamp{}) {
+		values[changefeedMetadataField] = map[string]interface{}{
+			changefeedOptUpdated: tree.TimestampToDecimal(updated).String(),
+		}
+	}
+}
+
+// encodeResolvedTimestamp encodes the message announcing that every change at
+// or before the given timestamp has been emitted.
+func encodeResolvedTimestamp(resolved hlc.Timestamp) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		changefeedMetadataField: map[string]interface{}{
+			changefeedOptResolved: tree.TimestampToDecimal(resolved).String(),
+		},
+	})
+}
+
+// datumToJSONValue returns the value encoding/json should emit for a datum.
+// Types without a natural JSON representation are emitted as their string
+// representation.
+func datumToJSONValue(d tree.Datum) interface{} {
+	if d == tree.DNull {
+		return nil
+	}
+	switch t := d.(type) {
+	case *tree.DBool:
+		return bool(*t)
+	case *tree.DInt:
+		return int64(*t)
+	case *tree.DFloat:
+		if f := float64(*t); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return f
+		}
+	case *tree.DDecimal:
+		if t.Form == apd.Finite {
+			return json.Number(t.Decimal.String())
+		}
+	case *tree.DString:
+		return string(*t)
+	case *tree.DJSON:
+		return json.RawMessage(t.JSON.String())
+	}
+	return tree.AsStringWithFlags(d, tree.FmtBareStrings)
+}
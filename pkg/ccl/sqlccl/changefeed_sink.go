@@ -0,0 +1,234 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlccl
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+const (
+	sinkSchemeKafka = "kafka"
+
+	sinkParamTopicPrefix = "topic_prefix"
+)
+
+// changefeedSink is an abstraction for anything that a changefeed may emit
+// into.
+type changefeedSink interface {
+	// EmitRow enqueues a row message for asynchronous delivery on the given
+	// topic. A nil value indicates that the row was deleted.
+	EmitRow(ctx context.Context, topic string, key, value []byte) error
+	// EmitResolvedTimestamp enqueues a resolved timestamp message for
+	// asynchronous delivery on every topic of the sink.
+	EmitResolvedTimestamp(ctx context.Context, payload []byte) error
+	// Flush blocks until every message enqueued by EmitRow and
+	// EmitResolvedTimestamp has been delivered.
+	Flush(ctx context.Context) error
+	// Close releases the resources of the sink.
+	Close() error
+}
+
+// getChangefeedSink returns the sink described by the given URI for a
+// changefeed watching the given tables.
+func getChangefeedSink(sinkURI string, tableNames []string) (changefeedSink, error) {
+	u, err := url.Parse(sinkURI)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+
+	var s changefeedSink
+	switch u.Scheme {
+	case sinkSchemeKafka:
+		topicPrefix := q.Get(sinkParamTopicPrefix)
+		q.Del(sinkParamTopicPrefix)
+		if len(q) > 0 {
+			return nil, errors.Errorf("unknown %s sink query parameters: %s", u.Scheme, q.Encode())
+		}
+		s, err = makeKafkaSink(u.Host, topicPrefix, tableNames)
+	default:
+		return nil, errors.Errorf("unsupported sink: %s", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// sqlSink emits to the client of a sinkless changefeed, as rows of the table
+// name, key and value of each change. Resolved timestamps have no table name
+// and key.
+type sqlSink struct {
+	resultsCh chan<- tree.Datums
+}
+
+// EmitRow implements the changefeedSink interface.
+func (s *sqlSink) EmitRow(ctx context.Context, topic string, key, value []byte) error {
+	row := tree.Datums{tree.NewDString(topic), tree.NewDBytes(tree.DBytes(key)), tree.DNull}
+	if value != nil {
+		row[2] = tree.NewDBytes(tree.DBytes(value))
+	}
+	return s.emit(ctx, row)
+}
+
+// EmitResolvedTimestamp implements the changefeedSink interface.
+func (s *sqlSink) EmitResolvedTimestamp(ctx context.Context, payload []byte) error {
+	return s.emit(ctx, tree.Datums{tree.DNull, tree.DNull, tree.NewDBytes(tree.DBytes(payload))})
+}
+
+func (s *sqlSink) emit(ctx context.Context, row tree.Datums) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case s.resultsCh <- row:
+		return nil
+	}
+}
+
+// Flush implements the changefeedSink interface. Every row is sent to the
+// client as soon as it is emitted.
+func (s *sqlSink) Flush(_ context.Context) error {
+	return nil
+}
+
+// Close implements the changefeedSink interface.
+func (s *sqlSink) Close() error {
+	return nil
+}
+
+// kafkaSink emits to Kafka. Each table is emitted to its own topic, named
+// after the table.
+type kafkaSink struct {
+	client      sarama.Client
+	producer    sarama.SyncProducer
+	topicPrefix string
+	topics      []string
+
+	// pending holds the messages enqueued since the last Flush.
+	pending []*sarama.ProducerMessage
+}
+
+func makeKafkaSink(
+	bootstrapServers string, topicPrefix string, tableNames []string,
+) (*kafkaSink, error) {
+	sink := &kafkaSink{topicPrefix: topicPrefix}
+	for _, name := range tableNames {
+		sink.topics = append(sink.topics, topicPrefix+name)
+	}
+
+	config := sarama.NewConfig()
+	config.ClientID = "CockroachDB"
+	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = newChangefeedPartitioner
+
+	var err error
+	sink.client, err = sarama.NewClient(strings.Split(bootstrapServers, ","), config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to kafka: %s", bootstrapServers)
+	}
+	sink.producer, err = sarama.NewSyncProducerFromClient(sink.client)
+	if err != nil {
+		_ = sink.client.Close()
+		return nil, errors.Wrapf(err, "connecting to kafka: %s", bootstrapServers)
+	}
+	return sink, nil
+}
+
+// EmitRow implements the changefeedSink interface.
+func (s *kafkaSink) EmitRow(_ context.Context, topic string, key, value []byte) error {
+	msg := &sarama.ProducerMessage{
+		Topic: s.topicPrefix + topic,
+		Key:   sarama.ByteEncoder(key),
+	}
+	if value != nil {
+		msg.Value = sarama.ByteEncoder(value)
+	}
+	s.pending = append(s.pending, msg)
+	return nil
+}
+
+// EmitResolvedTimestamp implements the changefeedSink interface. The message
+// is sent to every partition of every topic, so that a consumer of any
+// partition learns of it.
+func (s *kafkaSink) EmitResolvedTimestamp(_ context.Context, payload []byte) error {
+	// Refresh the metadata so that partitions added since the last resolved
+	// timestamp are included.
+	if err := s.client.RefreshMetadata(s.topics...); err != nil {
+		return err
+	}
+	for _, topic := range s.topics {
+		partitions, err := s.client.Partitions(topic)
+		if err != nil {
+			return err
+		}
+		for _, partition := range partitions {
+			s.pending = append(s.pending, &sarama.ProducerMessage{
+				Topic:     topic,
+				Partition: partition,
+				Value:     sarama.ByteEncoder(payload),
+			})
+		}
+	}
+	return nil
+}
+
+// Flush implements the changefeedSink interface.
+func (s *kafkaSink) Flush(_ context.Context) error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	err := s.producer.SendMessages(s.pending)
+	s.pending = s.pending[:0]
+	return err
+}
+
+// Close implements the changefeedSink interface.
+func (s *kafkaSink) Close() error {
+	// The producer does not close a client it did not create.
+	if err := s.producer.Close(); err != nil {
+		_ = s.client.Close()
+		return err
+	}
+	return s.client.Close()
+}
+
+// changefeedPartitioner hashes the keys of row messages, so that all the
+// changes to a row go to the same partition, in order. Resolved timestamp
+// messages have no key and go to the partition they are addressed to.
+type changefeedPartitioner struct {
+	hash sarama.Partitioner
+}
+
+var _ sarama.Partitioner = &changefeedPartitioner{}
+
+func newChangefeedPartitioner(topic string) sarama.Partitioner {
+	return &changefeedPartitioner{hash: sarama.NewHashPartitioner(topic)}
+}
+
+// Partition implements the sarama.Partitioner interface.
+func (p *changefeedPartitioner) Partition(
+	message *sarama.ProducerMessage, numPartitions int32,
+) (int32, error) {
+	if message.Key == nil {
+		return message.Partition, nil
+	}
+	return p.hash.Partition(message, numPartitions)
+}
+
+// RequiresConsistency implements the sarama.Partitioner interface.
+func (p *changefeedPartitioner) RequiresConsistency() bool {
+	return true
+}
@@ -0,0 +1,435 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlccl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// bufferSink is a changefeedSink that remembers what it was sent.
+type bufferSink struct {
+	rows     []string
+	resolved int
+}
+
+var _ changefeedSink = &bufferSink{}
+
+func (s *bufferSink) EmitRow(_ context.Context, topic string, key, value []byte) error {
+	s.rows = append(s.rows, fmt.Sprintf("%s: %s->%s", topic, key, value))
+	return nil
+}
+
+func (s *bufferSink) EmitResolvedTimestamp(_ context.Context, _ []byte) error {
+	s.resolved++
+	return nil
+}
+
+func (s *bufferSink) Flush(_ context.Context) error { return nil }
+
+func (s *bufferSink) Close() error { return nil }
+
+// next returns the rows emitted since the last call.
+func (s *bufferSink) next() []string {
+	rows := s.rows
+	s.rows = nil
+	return rows
+}
+
+func TestChangefeedPoll(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE d.foo (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(t, `INSERT INTO d.foo VALUES (1, 'a'), (2, 'b')`)
+	desc := sqlbase.GetTableDescriptor(kvDB, "d", "foo")
+
+	sink := &bufferSink{}
+	cf := &changefeed{
+		db: kvDB,
+		details: jobs.ChangefeedDetails{
+			TableIDs:              []sqlbase.ID{desc.ID},
+			ResolvedIntervalNanos: 1,
+		},
+		sink: sink,
+	}
+
+	poll := func(expected ...string) {
+		t.Helper()
+		if err := cf.poll(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if rows := sink.next(); !reflect.DeepEqual(rows, expected) {
+			t.Fatalf("expected %q, got %q", expected, rows)
+		}
+	}
+
+	// The first poll emits the current contents of the table.
+	poll(`foo: [1]->{"a":1,"b":"a"}`, `foo: [2]->{"a":2,"b":"b"}`)
+	poll()
+
+	sqlDB.Exec(t, `UPSERT INTO d.foo VALUES (1, 'c')`)
+	sqlDB.Exec(t, `DELETE FROM d.foo WHERE a = 2`)
+	sqlDB.Exec(t, `UPDATE d.foo SET b = NULL WHERE a = 1`)
+	poll(`foo: [1]->{"a":1,"b":"c"}`, `foo: [2]->`, `foo: [1]->{"a":1,"b":null}`)
+
+	if sink.resolved != 3 {
+		t.Fatalf("expected 3 resolved timestamps, got %d", sink.resolved)
+	}
+}
+
+func TestChangefeedWrappedEnvelope(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE d.foo (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(t, `INSERT INTO d.foo VALUES (1, 'a'), (2, 'b')`)
+	desc := sqlbase.GetTableDescriptor(kvDB, "d", "foo")
+
+	sink := &bufferSink{}
+	cf := &changefeed{
+		db: kvDB,
+		details: jobs.ChangefeedDetails{
+			TableIDs: []sqlbase.ID{desc.ID},
+			Envelope: changefeedEnvelopeWrapped,
+		},
+		sink: sink,
+	}
+	poll := func(expected ...string) {
+		t.Helper()
+		if err := cf.poll(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if rows := sink.next(); !reflect.DeepEqual(rows, expected) {
+			t.Fatalf("expected %q, got %q", expected, rows)
+		}
+	}
+
+	poll(
+		`foo: [1]->{"after":{"a":1,"b":"a"},"before":null}`,
+		`foo: [2]->{"after":{"a":2,"b":"b"},"before":null}`,
+	)
+	// The row before the first change to a row in a poll is read at the
+	// highwater, and the row before the following ones is the previous change.
+	sqlDB.Exec(t, `UPSERT INTO d.foo VALUES (1, 'c')`)
+	sqlDB.Exec(t, `DELETE FROM d.foo WHERE a = 2`)
+	sqlDB.Exec(t, `UPDATE d.foo SET b = NULL WHERE a = 1`)
+	sqlDB.Exec(t, `INSERT INTO d.foo VALUES (3, 'd')`)
+	poll(
+		`foo: [1]->{"after":{"a":1,"b":"c"},"before":{"a":1,"b":"a"}}`,
+		`foo: [2]->{"after":null,"before":{"a":2,"b":"b"}}`,
+		`foo: [1]->{"after":{"a":1,"b":null},"before":{"a":1,"b":"c"}}`,
+		`foo: [3]->{"after":{"a":3,"b":"d"},"before":null}`,
+	)
+}
+
+func TestChangefeedSchemaChangePolicy(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	for _, test := range []struct {
+		policy   string
+		expected []string
+		error    string
+	}{
+		{policy: changefeedSchemaChangeBackfill, expected: []string{`foo: [1]->{"a":1,"c":"a"}`}},
+		{policy: changefeedSchemaChangeIgnore},
+		{policy: changefeedSchemaChangeStop, error: `the columns of foo changed`},
+	} {
+		t.Run(test.policy, func(t *testing.T) {
+			sqlDB.Exec(t, `DROP TABLE IF EXISTS d.foo`)
+			sqlDB.Exec(t, `CREATE TABLE d.foo (a INT PRIMARY KEY, b STRING)`)
+			sqlDB.Exec(t, `INSERT INTO d.foo VALUES (1, 'a')`)
+			desc := sqlbase.GetTableDescriptor(kvDB, "d", "foo")
+
+			sink := &bufferSink{}
+			cf := &changefeed{
+				db: kvDB,
+				details: jobs.ChangefeedDetails{
+					TableIDs:           []sqlbase.ID{desc.ID},
+					SchemaChangePolicy: test.policy,
+				},
+				sink: sink,
+			}
+			if err := cf.poll(ctx); err != nil {
+				t.Fatal(err)
+			}
+			sink.next()
+
+			// Renaming a column doesn't rewrite the rows, but changes how they
+			// are emitted.
+			sqlDB.Exec(t, `ALTER TABLE d.foo RENAME COLUMN b TO c`)
+			if err := cf.poll(ctx); !testutils.IsError(err, test.error) {
+				t.Fatalf("expected error %q, got %v", test.error, err)
+			}
+			if rows := sink.next(); !reflect.DeepEqual(rows, test.expected) {
+				t.Fatalf("expected %q, got %q", test.expected, rows)
+			}
+		})
+	}
+}
+
+func TestChangefeedEncoders(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	desc := &sqlbase.TableDescriptor{
+		ID:      51,
+		Name:    "foo",
+		Version: 1,
+		Columns: []sqlbase.ColumnDescriptor{
+			{ID: 1, Name: "a", Type: sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}},
+			{ID: 2, Name: "b", Type: sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_STRING}, Nullable: true},
+		},
+		PrimaryIndex: sqlbase.IndexDescriptor{ColumnIDs: []sqlbase.ColumnID{1}},
+	}
+	key := tree.Datums{tree.NewDInt(1)}
+	upsert := changefeedRow{key: key, after: tree.Datums{tree.NewDInt(1), tree.NewDString("a")}}
+	deletion := changefeedRow{key: key}
+
+	// The key_only envelope tells deletions apart by their nil value.
+	keyOnly, err := makeChangefeedEncoder(jobs.ChangefeedDetails{Envelope: changefeedEnvelopeKeyOnly})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := keyOnly.encodeValue(ctx, desc, upsert); err != nil || value == nil || len(value) != 0 {
+		t.Fatalf("expected an empty value, got %q (%v)", value, err)
+	}
+	if value, err := keyOnly.encodeValue(ctx, desc, deletion); err != nil || value != nil {
+		t.Fatalf("expected a nil value, got %q (%v)", value, err)
+	}
+
+	var registered []string
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		var req struct{ Schema string }
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Error(err)
+		}
+		registered = append(registered, r.URL.Path+" "+req.Schema)
+		fmt.Fprintf(w, `{"id":%d}`, len(registered))
+	}))
+	defer registry.Close()
+
+	avro, err := makeChangefeedEncoder(jobs.ChangefeedDetails{
+		Format:                  changefeedFormatAvro,
+		ConfluentSchemaRegistry: registry.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyBytes, err := avro.encodeKey(ctx, desc, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []byte{0, 0, 0, 0, 1, 2}; !bytes.Equal(keyBytes, expected) {
+		t.Errorf("expected key %v, got %v", expected, keyBytes)
+	}
+	value, err := avro.encodeValue(ctx, desc, upsert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []byte{0, 0, 0, 0, 2, 2, 2, 2, 'a'}; !bytes.Equal(value, expected) {
+		t.Errorf("expected value %v, got %v", expected, value)
+	}
+	if value, err := avro.encodeValue(ctx, desc, deletion); err != nil || value != nil {
+		t.Fatalf("expected a nil value, got %v (%v)", value, err)
+	}
+	expectedSchemas := []string{
+		`/subjects/foo-key/versions {"type":"record","name":"foo_key","fields":[{"name":"a","type":"long"}]}`,
+		`/subjects/foo-value/versions {"type":"record","name":"foo","fields":[` +
+			`{"name":"a","type":"long"},{"name":"b","type":["null","string"]}]}`,
+	}
+	if !reflect.DeepEqual(registered, expectedSchemas) {
+		t.Errorf("expected schemas %q, got %q", expectedSchemas, registered)
+	}
+}
+
+func TestChangefeedProtectedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE d.foo (a INT PRIMARY KEY, b STRING)`)
+	desc := sqlbase.GetTableDescriptor(kvDB, "d", "foo")
+
+	const jobID = 123
+	cf := &changefeed{
+		db:      kvDB,
+		details: jobs.ChangefeedDetails{TableIDs: []sqlbase.ID{desc.ID}},
+		sink:    &bufferSink{},
+		jobID:   jobID,
+	}
+	getRecord := func() (*protectedts.Record, error) {
+		var r *protectedts.Record
+		err := kvDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			var err error
+			r, err = protectedts.GetRecord(ctx, txn, protectedts.JobRecordID(jobID))
+			return err
+		})
+		return r, err
+	}
+
+	// Every poll protects the history after the new highwater.
+	for i := 0; i < 2; i++ {
+		if err := cf.poll(ctx); err != nil {
+			t.Fatal(err)
+		}
+		r, err := getRecord()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Timestamp != cf.details.Highwater {
+			t.Errorf("expected protected timestamp %s, got %s", cf.details.Highwater, r.Timestamp)
+		}
+		if expected := []roachpb.Span{desc.PrimaryIndexSpan()}; !reflect.DeepEqual(r.Spans, expected) {
+			t.Errorf("expected protected spans %s, got %s", expected, r.Spans)
+		}
+	}
+
+	if err := cf.releaseProtectedTimestamp(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := getRecord(); err != protectedts.ErrNotFound {
+		t.Fatalf("expected %v, got %v", protectedts.ErrNotFound, err)
+	}
+}
+
+func TestChangefeedErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE d.foo (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(t, `CREATE TABLE d.families (a INT PRIMARY KEY, b STRING, FAMILY (a), FAMILY (b))`)
+	sqlDB.Exec(t, `CREATE VIEW d.v AS SELECT a FROM d.foo`)
+
+	for _, test := range []struct {
+		stmt  string
+		error string
+	}{
+		{`CREATE CHANGEFEED FOR DATABASE d INTO 'kafka://nope'`, `cannot target databases`},
+		{`CREATE CHANGEFEED FOR TABLE d.families INTO 'kafka://nope'`, `exactly 1 column family`},
+		{`CREATE CHANGEFEED FOR TABLE d.v INTO 'kafka://nope'`, `cannot target views`},
+		{`CREATE CHANGEFEED FOR TABLE d.foo INTO 'nope://x'`, `unsupported sink: nope`},
+		{`CREATE CHANGEFEED FOR TABLE d.foo INTO 'kafka://nope?foo=bar'`, `unknown kafka sink query parameters`},
+		{`CREATE CHANGEFEED FOR TABLE d.foo INTO 'kafka://nope' WITH resolved = 'soon'`, `invalid resolved value`},
+		{`CREATE CHANGEFEED FOR TABLE d.foo INTO 'kafka://nope' WITH envelope = 'nope'`, `unknown envelope: nope`},
+		{`CREATE CHANGEFEED FOR TABLE d.foo INTO 'kafka://nope' WITH schema_change_policy = 'nope'`,
+			`unknown schema_change_policy: nope`},
+		{`CREATE CHANGEFEED FOR TABLE d.foo INTO 'kafka://nope' WITH envelope = 'key_only', updated`,
+			`updated is not supported with envelope=key_only`},
+		{`CREATE CHANGEFEED FOR TABLE d.foo INTO 'kafka://nope' WITH format = 'experimental_avro'`,
+			`format=experimental_avro requires confluent_schema_registry`},
+		{`CREATE CHANGEFEED FOR TABLE d.foo INTO 'kafka://nope' WITH confluent_schema_registry = 'http://nope'`,
+			`confluent_schema_registry requires format=experimental_avro`},
+		{`CREATE CHANGEFEED FOR TABLE d.foo INTO 'kafka://nope' ` +
+			`WITH format = 'experimental_avro', confluent_schema_registry = 'http://nope', envelope = 'wrapped'`,
+			`envelope=wrapped is not supported with format=experimental_avro`},
+	} {
+		if _, err := db.Exec(test.stmt); !testutils.IsError(err, test.error) {
+			t.Errorf("%s: expected error %q, got %v", test.stmt, test.error, err)
+		}
+	}
+}
+
+func TestChangefeedSinkless(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING changefeed.experimental_poll_interval = '10ms'`)
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE d.foo (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(t, `INSERT INTO d.foo VALUES (1, 'a')`)
+
+	// The changefeed holds on to its connection until it's canceled.
+	rows, err := db.Query(`EXPERIMENTAL CHANGEFEED FOR TABLE d.foo`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	expectRow := func(expected string) {
+		t.Helper()
+		if !rows.Next() {
+			t.Fatalf("expected %s, got %v", expected, rows.Err())
+		}
+		var table, key, value []byte
+		if err := rows.Scan(&table, &key, &value); err != nil {
+			t.Fatal(err)
+		}
+		if row := fmt.Sprintf("%s: %s->%s", table, key, value); row != expected {
+			t.Fatalf("expected %s, got %s", expected, row)
+		}
+	}
+	expectRow(`foo: [1]->{"a":1,"b":"a"}`)
+	sqlDB.Exec(t, `INSERT INTO d.foo VALUES (2, 'b')`)
+	expectRow(`foo: [2]->{"a":2,"b":"b"}`)
+	sqlDB.Exec(t, `DELETE FROM d.foo WHERE a = 1`)
+	expectRow(`foo: [1]->`)
+
+	sqlDB.Exec(t,
+		`CANCEL QUERY (SELECT query_id FROM [SHOW CLUSTER QUERIES] WHERE query LIKE 'EXPERIMENTAL CHANGEFEED%')`,
+	)
+	for rows.Next() {
+	}
+	if err := rows.Err(); !testutils.IsError(err, "query execution canceled") {
+		t.Fatalf("expected the changefeed to be canceled, got %v", err)
+	}
+}
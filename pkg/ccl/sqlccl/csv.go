@@ -62,6 +62,12 @@ const (
 	importOptionTemp          = "temp"
 )
 
+const (
+	importFormatCSV       = "CSV"
+	importFormatPgDump    = "PGDUMP"
+	importFormatMysqlDump = "MYSQLDUMP"
+)
+
 var importOptionExpectValues = map[string]bool{
 	importOptionDelimiter:     true,
 	importOptionComment:       true,
@@ -151,7 +157,7 @@ func LoadCSV(
 	defer r.Close()
 
 	return doLocalCSVTransform(
-		ctx, nil, parentID, tableDesc, dest, dataFiles, comma, comment, nullif, sstMaxSize, r, walltime, nil,
+		ctx, nil, parentID, tableDesc, dest, dataFiles, importFormatCSV, comma, comment, nullif, sstMaxSize, r, walltime, nil,
 	)
 }
 
@@ -162,6 +168,7 @@ func doLocalCSVTransform(
 	tableDesc *sqlbase.TableDescriptor,
 	dest string,
 	dataFiles []string,
+	format string,
 	comma, comment rune,
 	nullif *string,
 	sstMaxSize int64,
@@ -222,7 +229,11 @@ func doLocalCSVTransform(
 	group.Go(func() error {
 		defer close(recordCh)
 		var err error
-		csvCount, err = readCSV(gCtx, comma, comment, len(tableDesc.VisibleColumns()), dataFiles, recordCh, readProgressFn, st)
+		if format == importFormatCSV {
+			csvCount, err = readCSV(gCtx, comma, comment, len(tableDesc.VisibleColumns()), dataFiles, recordCh, readProgressFn, st)
+		} else {
+			csvCount, err = readDump(gCtx, format, tableDesc.Name, len(tableDesc.VisibleColumns()), dataFiles, recordCh, readProgressFn, st)
+		}
 		return err
 	})
 	group.Go(func() error {
@@ -511,7 +522,10 @@ func (b *byteCounter) Read(p []byte) (int, error) {
 }
 
 type csvRecord struct {
-	r         [][]string
+	r [][]string
+	// nulls, if set, marks the fields of r that are NULL. It is used by the
+	// dump formats, which distinguish NULL from every string value.
+	nulls     [][]bool
 	file      string
 	rowOffset int
 }
@@ -565,7 +579,7 @@ func convertRecord(
 			rowNum := batch.rowOffset + batchIdx
 			for i, v := range record {
 				col := visibleCols[i]
-				if nullif != nil && v == *nullif {
+				if (nullif != nil && v == *nullif) || (batch.nulls != nil && batch.nulls[batchIdx][i]) {
 					datums[i] = tree.DNull
 				} else {
 					datums[i], err = parser.ParseStringAs(col.Type.ToDatumType(), v, &evalCtx)
@@ -892,7 +906,9 @@ func importPlanHook(
 		}
 	}
 
-	if importStmt.FileFormat != "CSV" {
+	switch importStmt.FileFormat {
+	case importFormatCSV, importFormatPgDump, importFormatMysqlDump:
+	default:
 		// not possible with current parser rules.
 		return nil, nil, errors.Errorf("unsupported import format: %q", importStmt.FileFormat)
 	}
@@ -923,6 +939,18 @@ func importPlanHook(
 		}
 
 		_, transformOnly := opts[importOptionTransformOnly]
+		_, distributed := opts[importOptionDistributed]
+
+		if importStmt.FileFormat != importFormatCSV {
+			if distributed {
+				return errors.Errorf("%s does not support the %q option", importStmt.FileFormat, importOptionDistributed)
+			}
+			for _, csvOpt := range []string{importOptionDelimiter, importOptionComment, importOptionNullIf} {
+				if _, ok := opts[csvOpt]; ok {
+					return errors.Errorf("%s does not support the %q option", importStmt.FileFormat, csvOpt)
+				}
+			}
+		}
 
 		var targetDB string
 		if !transformOnly {
@@ -1048,7 +1076,7 @@ func importPlanHook(
 		}
 
 		var importErr error
-		if _, distributed := opts[importOptionDistributed]; distributed {
+		if distributed {
 			_, importErr = doDistributedCSVTransform(
 				ctx, job, files, p, tableDesc, temp,
 				comma, comment, nullif, walltime,
@@ -1057,7 +1085,7 @@ func importPlanHook(
 		} else {
 			_, _, _, importErr = doLocalCSVTransform(
 				ctx, job, parentID, tableDesc, temp, files,
-				comma, comment, nullif, sstSize,
+				importStmt.FileFormat, comma, comment, nullif, sstSize,
 				p.ExecCfg().DistSQLSrv.TempStorage,
 				walltime, p.ExecCfg(),
 			)
@@ -1090,9 +1118,9 @@ func importPlanHook(
 			Targets: tree.TargetList{
 				Tables: []tree.TablePattern{&tree.AllTablesSelector{Database: csvDatabaseName}},
 			},
-			From: tree.Exprs{tree.NewDString(temp)},
+			From: tree.PartitionedBackups{{tree.NewDString(temp)}},
 		}
-		from := []string{temp}
+		from := [][]string{{temp}}
 		endTime := hlc.Timestamp{}
 		opts = map[string]string{restoreOptIntoDB: targetDB}
 
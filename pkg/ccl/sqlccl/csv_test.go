@@ -694,6 +694,40 @@ func TestImportStmt(t *testing.T) {
 		// Expect it to succeed with correct columns.
 		sqlDB.Exec(t, fmt.Sprintf(`IMPORT TABLE t (a INT, b STRING) CSV DATA (%s) WITH temp = $1, transform_only`, files[0]), nodetmp)
 	})
+
+	// Verify the rows of a table can be imported from pg_dump and mysqldump
+	// files, skipping the other tables in the dumps.
+	dumps := map[string]string{
+		"PGDUMP": "SET client_encoding = 'UTF8';\n" +
+			"COPY public.other (a) FROM stdin;\n1\n\\.\n" +
+			"COPY public.t (a, b) FROM stdin;\n1\tfoo\n2\t\\N\n3\tbar\\tbaz\n\\.\n",
+		"MYSQLDUMP": "/*!40101 SET NAMES utf8 */;\n" +
+			"INSERT INTO `other` VALUES (1);\n" +
+			"INSERT INTO `t` VALUES (1,'foo'),(2,NULL),(3,'bar\\tbaz');\n",
+	}
+	for format, dump := range dumps {
+		t.Run(strings.ToLower(format), func(t *testing.T) {
+			if err := ioutil.WriteFile(filepath.Join(dir, "dump.sql"), []byte(dump), 0666); err != nil {
+				t.Fatal(err)
+			}
+			db := fmt.Sprintf("%s_import", strings.ToLower(format))
+			sqlDB.Exec(t, fmt.Sprintf(`CREATE DATABASE %s`, db))
+
+			_, err := conn.Exec(fmt.Sprintf(
+				`IMPORT TABLE t (a INT PRIMARY KEY, b STRING) %s DATA ('nodelocal:///dump.sql') WITH temp = $1, distributed`,
+				format), "nodelocal:///"+db)
+			if !testutils.IsError(err, fmt.Sprintf("%s does not support the \"distributed\" option", format)) {
+				t.Fatalf("unexpected: %v", err)
+			}
+
+			sqlDB.Exec(t, fmt.Sprintf(
+				`IMPORT TABLE t (a INT PRIMARY KEY, b STRING) %s DATA ('nodelocal:///dump.sql') WITH temp = $1, into_db = $2`,
+				format), "nodelocal:///"+db, db)
+			sqlDB.CheckQueryResults(t, fmt.Sprintf(`SELECT a, b FROM %s.t ORDER BY a`, db), [][]string{
+				{"1", "foo"}, {"2", "NULL"}, {"3", "bar\tbaz"},
+			})
+		})
+	}
 }
 
 func BenchmarkImport(b *testing.B) {
@@ -0,0 +1,508 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlccl
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+)
+
+// readDump reads the rows of the named table out of pg_dump or mysqldump
+// files and sends them on recordCh. Only the data of the table is read: its
+// schema must be given to IMPORT separately, and the rest of the dump (other
+// tables, DDL, SET statements, comments, etc.) is skipped.
+//
+// For pg_dump files, the rows are read from the COPY ... FROM stdin block of
+// the table, in the default text format. For mysqldump files, they are read
+// from the INSERT INTO statements of the table.
+func readDump(
+	ctx context.Context,
+	format string,
+	table string,
+	expectedCols int,
+	dataFiles []string,
+	recordCh chan<- csvRecord,
+	progressFn func(float32),
+	settings *cluster.Settings,
+) (int64, error) {
+	done := ctx.Done()
+	var count int64
+	var found bool
+
+	for dataFileI, dataFile := range dataFiles {
+		select {
+		case <-done:
+			return 0, ctx.Err()
+		default:
+		}
+
+		err := func() error {
+			conf, err := storageccl.ExportStorageConfFromURI(dataFile)
+			if err != nil {
+				return err
+			}
+			es, err := storageccl.MakeExportStorage(ctx, conf, settings)
+			if err != nil {
+				return err
+			}
+			defer es.Close()
+			f, err := es.ReadFile(ctx, "")
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			b := dumpBatcher{
+				ctx:          ctx,
+				recordCh:     recordCh,
+				expectedCols: expectedCols,
+				batch:        newDumpBatch(dataFile, 1),
+			}
+			r := bufio.NewReader(f)
+			switch format {
+			case importFormatPgDump:
+				err = readPgDump(r, table, &b)
+			case importFormatMysqlDump:
+				err = readMysqlDump(r, table, &b)
+			default:
+				err = errors.Errorf("unsupported dump format: %q", format)
+			}
+			if err != nil {
+				return err
+			}
+			if err := b.flush(); err != nil {
+				return err
+			}
+			count += b.count
+			found = found || b.found
+			return nil
+		}()
+		if err != nil {
+			return 0, errors.Wrap(err, dataFile)
+		}
+		if progressFn != nil {
+			progressFn(float32(dataFileI+1) / float32(len(dataFiles)))
+		}
+	}
+	if !found {
+		return 0, errors.Errorf("no data for table %q found in %s files", table, format)
+	}
+	return count, nil
+}
+
+// dumpBatcher accumulates the rows read from a dump file into csvRecord
+// batches. The row offsets of the batches count the rows of the table in the
+// file, as a line of a dump can hold several rows.
+type dumpBatcher struct {
+	ctx          context.Context
+	recordCh     chan<- csvRecord
+	expectedCols int
+	batch        csvRecord
+	// line is the line of the dump file being read.
+	line  int
+	count int64
+	found bool
+}
+
+const dumpBatchSize = 500
+
+func newDumpBatch(file string, rowOffset int) csvRecord {
+	return csvRecord{
+		file:      file,
+		rowOffset: rowOffset,
+		r:         make([][]string, 0, dumpBatchSize),
+		nulls:     make([][]bool, 0, dumpBatchSize),
+	}
+}
+
+// add adds a row, read on the current line, to the batch.
+func (b *dumpBatcher) add(row []string, nulls []bool) error {
+	if len(row) != b.expectedCols {
+		return errors.Errorf("expected %d fields, got %d", b.expectedCols, len(row))
+	}
+	b.batch.r = append(b.batch.r, row)
+	b.batch.nulls = append(b.batch.nulls, nulls)
+	if len(b.batch.r) >= dumpBatchSize {
+		return b.flush()
+	}
+	return nil
+}
+
+// flush sends the batch, if it isn't empty, and starts a new one.
+func (b *dumpBatcher) flush() error {
+	if len(b.batch.r) == 0 {
+		return nil
+	}
+	select {
+	case <-b.ctx.Done():
+		return b.ctx.Err()
+	case b.recordCh <- b.batch:
+		b.count += int64(len(b.batch.r))
+	}
+	b.batch = newDumpBatch(b.batch.file, b.batch.rowOffset+len(b.batch.r))
+	return nil
+}
+
+// readDumpLine returns the next line of r, without its line terminator, and
+// whether the end of r was reached.
+func readDumpLine(r *bufio.Reader) (string, bool, error) {
+	line, err := r.ReadString('\n')
+	if err == io.EOF {
+		return line, true, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), false, nil
+}
+
+// dumpTableNameMatches returns whether the possibly qualified and quoted table
+// name used in a dump statement names table.
+func dumpTableNameMatches(name, table string, quote byte) bool {
+	// Strip the schema or database the table is qualified with, if any.
+	inQuote := false
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c == quote {
+			inQuote = !inQuote
+		} else if c == '.' && !inQuote {
+			name, i = name[i+1:], -1
+		}
+	}
+	if len(name) >= 2 && name[0] == quote && name[len(name)-1] == quote {
+		name = name[1 : len(name)-1]
+	}
+	return name == table
+}
+
+// readPgDump reads the rows of table from the COPY block for it in a pg_dump
+// file. The COPY block looks like:
+//
+//   COPY public.t (a, b) FROM stdin;
+//   1	foo
+//   2	\N
+//   \.
+//
+// Fields are tab-separated, \N is NULL, and backslash escapes the special
+// characters in the values.
+func readPgDump(r *bufio.Reader, table string, b *dumpBatcher) error {
+	inCopy := false
+	for {
+		line, eof, err := readDumpLine(r)
+		if err != nil {
+			return err
+		}
+		b.line++
+		if eof && line == "" {
+			if inCopy {
+				return errors.Errorf("line %d: unterminated COPY data", b.line)
+			}
+			return nil
+		}
+
+		if !inCopy {
+			if !strings.HasPrefix(line, "COPY ") || !strings.HasSuffix(line, " FROM stdin;") {
+				continue
+			}
+			target := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "COPY "), " FROM stdin;"))
+			name, cols := target, ""
+			if i := strings.IndexByte(target, '('); i >= 0 {
+				name, cols = strings.TrimSpace(target[:i]), target[i:]
+			}
+			if !dumpTableNameMatches(name, table, '"') {
+				continue
+			}
+			if cols != "" {
+				if n := strings.Count(cols, ",") + 1; n != b.expectedCols {
+					return errors.Errorf("line %d: COPY of %d columns into a table with %d columns",
+						b.line, n, b.expectedCols)
+				}
+			}
+			inCopy = true
+			b.found = true
+			continue
+		}
+
+		if line == `\.` {
+			inCopy = false
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		row := make([]string, len(fields))
+		nulls := make([]bool, len(fields))
+		for i, f := range fields {
+			if f == `\N` {
+				nulls[i] = true
+				continue
+			}
+			if row[i], err = unescapePgCopyField(f); err != nil {
+				return errors.Wrapf(err, "line %d", b.line)
+			}
+		}
+		if err := b.add(row, nulls); err != nil {
+			return errors.Wrapf(err, "line %d", b.line)
+		}
+	}
+}
+
+// unescapePgCopyField decodes the backslash escapes of a field of the text
+// format of COPY.
+func unescapePgCopyField(f string) (string, error) {
+	if strings.IndexByte(f, '\\') < 0 {
+		return f, nil
+	}
+	var buf bytes.Buffer
+	for i := 0; i < len(f); i++ {
+		c := f[i]
+		if c != '\\' {
+			buf.WriteByte(c)
+			continue
+		}
+		i++
+		if i == len(f) {
+			return "", errors.New("unterminated escape sequence")
+		}
+		switch c = f[i]; c {
+		case 'b':
+			buf.WriteByte('\b')
+		case 'f':
+			buf.WriteByte('\f')
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 't':
+			buf.WriteByte('\t')
+		case 'v':
+			buf.WriteByte('\v')
+		case 'x':
+			// \xh or \xhh.
+			j := i + 1
+			for j < len(f) && j < i+3 && isHexDigit(f[j]) {
+				j++
+			}
+			if j == i+1 {
+				buf.WriteByte('x')
+				continue
+			}
+			v, err := strconv.ParseUint(f[i+1:j], 16, 8)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteByte(byte(v))
+			i = j - 1
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			// \o, \oo or \ooo.
+			j := i + 1
+			for j < len(f) && j < i+3 && f[j] >= '0' && f[j] <= '7' {
+				j++
+			}
+			v, err := strconv.ParseUint(f[i:j], 8, 8)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteByte(byte(v))
+			i = j - 1
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String(), nil
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// readMysqlDump reads the rows of table from the INSERT statements for it in a
+// mysqldump file. mysqldump writes each INSERT on its own line, e.g.:
+//
+//   INSERT INTO `t` VALUES (1,'foo'),(2,NULL);
+func readMysqlDump(r *bufio.Reader, table string, b *dumpBatcher) error {
+	for {
+		line, eof, err := readDumpLine(r)
+		if err != nil {
+			return err
+		}
+		b.line++
+		if eof && line == "" {
+			return nil
+		}
+		if !strings.HasPrefix(line, "INSERT INTO ") {
+			continue
+		}
+		s := mysqlInsertScanner{s: line, pos: len("INSERT INTO ")}
+		name := s.identifier()
+		if !dumpTableNameMatches(name, table, '`') {
+			continue
+		}
+		b.found = true
+		if err := s.rows(b); err != nil {
+			return errors.Wrapf(err, "line %d", b.line)
+		}
+	}
+}
+
+// mysqlInsertScanner scans the table name and the rows of a mysqldump INSERT
+// statement.
+type mysqlInsertScanner struct {
+	s   string
+	pos int
+}
+
+func (s *mysqlInsertScanner) skipSpace() {
+	for s.pos < len(s.s) && s.s[s.pos] == ' ' {
+		s.pos++
+	}
+}
+
+// identifier scans a possibly qualified and backquoted identifier.
+func (s *mysqlInsertScanner) identifier() string {
+	start := s.pos
+	inQuote := false
+	for ; s.pos < len(s.s); s.pos++ {
+		c := s.s[s.pos]
+		if c == '`' {
+			inQuote = !inQuote
+		} else if !inQuote && (c == ' ' || c == '(') {
+			break
+		}
+	}
+	return s.s[start:s.pos]
+}
+
+func (s *mysqlInsertScanner) expect(c byte) error {
+	s.skipSpace()
+	if s.pos >= len(s.s) || s.s[s.pos] != c {
+		return errors.Errorf("expected %q at position %d", c, s.pos)
+	}
+	s.pos++
+	return nil
+}
+
+// rows scans the optional column list and the VALUES of the statement, and
+// adds the rows to b.
+func (s *mysqlInsertScanner) rows(b *dumpBatcher) error {
+	s.skipSpace()
+	if s.pos < len(s.s) && s.s[s.pos] == '(' {
+		// Skip the column list of a --complete-insert dump.
+		end := strings.IndexByte(s.s[s.pos:], ')')
+		if end < 0 {
+			return errors.New("unterminated column list")
+		}
+		s.pos += end + 1
+		s.skipSpace()
+	}
+	if !strings.HasPrefix(s.s[s.pos:], "VALUES") {
+		return errors.Errorf("expected VALUES at position %d", s.pos)
+	}
+	s.pos += len("VALUES")
+	for {
+		if err := s.expect('('); err != nil {
+			return err
+		}
+		var row []string
+		var nulls []bool
+		for {
+			s.skipSpace()
+			v, null, err := s.value()
+			if err != nil {
+				return err
+			}
+			row = append(row, v)
+			nulls = append(nulls, null)
+			s.skipSpace()
+			if s.pos < len(s.s) && s.s[s.pos] == ',' {
+				s.pos++
+				continue
+			}
+			if err := s.expect(')'); err != nil {
+				return err
+			}
+			break
+		}
+		if err := b.add(row, nulls); err != nil {
+			return err
+		}
+		s.skipSpace()
+		if s.pos < len(s.s) && s.s[s.pos] == ',' {
+			s.pos++
+			continue
+		}
+		return s.expect(';')
+	}
+}
+
+// value scans a NULL, a quoted string or a bare value such as a number.
+func (s *mysqlInsertScanner) value() (string, bool, error) {
+	if s.pos < len(s.s) && s.s[s.pos] == '\'' {
+		return s.quoted()
+	}
+	start := s.pos
+	for s.pos < len(s.s) && s.s[s.pos] != ',' && s.s[s.pos] != ')' {
+		s.pos++
+	}
+	v := strings.TrimSpace(s.s[start:s.pos])
+	if v == "" {
+		return "", false, errors.Errorf("expected value at position %d", start)
+	}
+	if v == "NULL" {
+		return "", true, nil
+	}
+	return v, false, nil
+}
+
+// quoted scans a single-quoted string with the backslash escapes of MySQL.
+func (s *mysqlInsertScanner) quoted() (string, bool, error) {
+	var buf bytes.Buffer
+	for s.pos++; s.pos < len(s.s); s.pos++ {
+		c := s.s[s.pos]
+		switch c {
+		case '\'':
+			if s.pos+1 < len(s.s) && s.s[s.pos+1] == '\'' {
+				buf.WriteByte('\'')
+				s.pos++
+				continue
+			}
+			s.pos++
+			return buf.String(), false, nil
+		case '\\':
+			s.pos++
+			if s.pos == len(s.s) {
+				return "", false, errors.New("unterminated escape sequence")
+			}
+			switch e := s.s[s.pos]; e {
+			case '0':
+				buf.WriteByte(0)
+			case 'b':
+				buf.WriteByte('\b')
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case 'Z':
+				buf.WriteByte(26)
+			default:
+				buf.WriteByte(e)
+			}
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return "", false, errors.New("unterminated string")
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlccl
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestReadDump(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const pgDump = `--
+-- PostgreSQL database dump
+--
+
+SET statement_timeout = 0;
+
+CREATE TABLE public.other (a integer);
+
+COPY public.other (a) FROM stdin;
+1
+\.
+
+COPY public.t (a, b) FROM stdin;
+1	foo
+2	\N
+3	tab\there\\ \101
+\.
+`
+	const mysqlDump = "-- MySQL dump\n" +
+		"INSERT INTO `other` VALUES (1);\n" +
+		"INSERT INTO `t` VALUES (1,'foo'),(2,NULL);\n" +
+		"INSERT INTO `db`.`t` (`a`, `b`) VALUES (3,'it''s \\'quoted\\'\\n');\n"
+
+	type row struct {
+		values []string
+		nulls  []bool
+	}
+
+	tests := []struct {
+		name   string
+		read   func(*bufio.Reader, string, *dumpBatcher) error
+		dump   string
+		expect []row
+		error  string
+	}{
+		{
+			name: "pgdump",
+			read: readPgDump,
+			dump: pgDump,
+			expect: []row{
+				{[]string{"1", "foo"}, []bool{false, false}},
+				{[]string{"2", ""}, []bool{false, true}},
+				{[]string{"3", "tab\there\\ A"}, []bool{false, false}},
+			},
+		},
+		{
+			name:  "pgdump unterminated",
+			read:  readPgDump,
+			dump:  "COPY t (a, b) FROM stdin;\n1\tfoo\n",
+			error: "unterminated COPY data",
+		},
+		{
+			name:  "pgdump columns",
+			read:  readPgDump,
+			dump:  "COPY t (a) FROM stdin;\n1\n\\.\n",
+			error: "COPY of 1 columns into a table with 2 columns",
+		},
+		{
+			name: "mysqldump",
+			read: readMysqlDump,
+			dump: mysqlDump,
+			expect: []row{
+				{[]string{"1", "foo"}, []bool{false, false}},
+				{[]string{"2", ""}, []bool{false, true}},
+				{[]string{"3", "it's 'quoted'\n"}, []bool{false, false}},
+			},
+		},
+		{
+			name:  "mysqldump fields",
+			read:  readMysqlDump,
+			dump:  "INSERT INTO `t` VALUES (1);\n",
+			error: "line 1: expected 2 fields, got 1",
+		},
+		{
+			name:  "mysqldump unterminated",
+			read:  readMysqlDump,
+			dump:  "INSERT INTO `t` VALUES (1,'foo);\n",
+			error: "unterminated string",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recordCh := make(chan csvRecord, 10)
+			b := dumpBatcher{
+				ctx:          context.Background(),
+				recordCh:     recordCh,
+				expectedCols: 2,
+				batch:        newDumpBatch("dump", 1),
+			}
+			err := test.read(bufio.NewReader(strings.NewReader(test.dump)), "t", &b)
+			if err == nil {
+				err = b.flush()
+			}
+			if !testutils.IsError(err, test.error) {
+				t.Fatalf("expected error %q, got %v", test.error, err)
+			}
+			close(recordCh)
+			var rows []row
+			for batch := range recordCh {
+				for i := range batch.r {
+					rows = append(rows, row{batch.r[i], batch.nulls[i]})
+				}
+			}
+			if test.error == "" && !reflect.DeepEqual(rows, test.expect) {
+				t.Fatalf("expected %v, got %v", test.expect, rows)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlccl
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+const (
+	exportOptionDelimiter = "delimiter"
+	exportOptionNullAs    = "nullas"
+	exportOptionChunkRows = "chunk_rows"
+
+	exportChunkRowsDefault = 100000
+)
+
+var exportOptionExpectValues = map[string]bool{
+	exportOptionDelimiter: true,
+	exportOptionNullAs:    true,
+	exportOptionChunkRows: true,
+}
+
+// csvExporter accumulates rows into an in-memory CSV file which is flushed to
+// export storage every chunkRows rows.
+type csvExporter struct {
+	buf       bytes.Buffer
+	csvWriter *csv.Writer
+	nullAs    string
+	rowBuf    []string
+	rows      int
+}
+
+func newCSVExporter(delimiter rune, nullAs string) *csvExporter {
+	e := &csvExporter{nullAs: nullAs}
+	e.csvWriter = csv.NewWriter(&e.buf)
+	if delimiter != 0 {
+		e.csvWriter.Comma = delimiter
+	}
+	return e
+}
+
+// write appends a row to the current file.
+func (e *csvExporter) write(row tree.Datums) error {
+	e.rowBuf = e.rowBuf[:0]
+	for _, d := range row {
+		if d == tree.DNull {
+			e.rowBuf = append(e.rowBuf, e.nullAs)
+			continue
+		}
+		e.rowBuf = append(e.rowBuf, tree.AsStringWithFlags(d, tree.FmtBareStrings))
+	}
+	if err := e.csvWriter.Write(e.rowBuf); err != nil {
+		return err
+	}
+	e.rows++
+	return nil
+}
+
+// finish returns the contents of the current file and resets the exporter
+// for the next one.
+func (e *csvExporter) finish() ([]byte, error) {
+	e.csvWriter.Flush()
+	if err := e.csvWriter.Error(); err != nil {
+		return nil, err
+	}
+	content := append([]byte(nil), e.buf.Bytes()...)
+	e.buf.Reset()
+	e.rows = 0
+	return content, nil
+}
+
+// exportPlanHook implements sql.planHookFn. The query is run on the gateway
+// and its results are written to export storage in files of chunk_rows rows.
+func exportPlanHook(
+	stmt tree.Statement, p sql.PlanHookState,
+) (func(context.Context, chan<- tree.Datums) error, sqlbase.ResultColumns, error) {
+	exportStmt, ok := stmt.(*tree.Export)
+	if !ok {
+		return nil, nil, nil
+	}
+
+	if err := utilccl.CheckEnterpriseEnabled(
+		p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(), "EXPORT",
+	); err != nil {
+		return nil, nil, err
+	}
+
+	if err := p.RequireSuperUser("EXPORT"); err != nil {
+		return nil, nil, err
+	}
+
+	if exportStmt.FileFormat != "CSV" {
+		// not possible with current parser rules.
+		return nil, nil, errors.Errorf("unsupported export format: %q", exportStmt.FileFormat)
+	}
+
+	fileFn, err := p.TypeAsString(exportStmt.File, "EXPORT")
+	if err != nil {
+		return nil, nil, err
+	}
+	optsFn, err := p.TypeAsStringOpts(exportStmt.Options, exportOptionExpectValues)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := sqlbase.ResultColumns{
+		{Name: "filename", Typ: types.String},
+		{Name: "rows", Typ: types.Int},
+		{Name: "bytes", Typ: types.Int},
+	}
+
+	fn := func(ctx context.Context, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer tracing.FinishSpan(span)
+
+		file, err := fileFn()
+		if err != nil {
+			return err
+		}
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+
+		var delimiter rune
+		if override, ok := opts[exportOptionDelimiter]; ok {
+			delimiter, err = util.GetSingleRune(override)
+			if err != nil {
+				return errors.Wrap(err, "invalid delimiter value")
+			}
+		}
+
+		chunkRows := exportChunkRowsDefault
+		if override, ok := opts[exportOptionChunkRows]; ok {
+			chunkRows, err = strconv.Atoi(override)
+			if err != nil {
+				return errors.Wrapf(err, "invalid %s value", exportOptionChunkRows)
+			}
+			if chunkRows < 1 {
+				return errors.Errorf("invalid %s value %d: must be positive", exportOptionChunkRows, chunkRows)
+			}
+		}
+
+		exportStore, err := exportStorageFromURI(ctx, file, p.ExecCfg().Settings)
+		if err != nil {
+			return err
+		}
+		defer exportStore.Close()
+
+		exporter := newCSVExporter(delimiter, opts[exportOptionNullAs])
+		nodeID := p.ExecCfg().NodeID.Get()
+		chunk := 0
+		flush := func() error {
+			rows := exporter.rows
+			content, err := exporter.finish()
+			if err != nil {
+				return err
+			}
+			filename := fmt.Sprintf("n%d.%d.csv", nodeID, chunk)
+			chunk++
+			if err := exportStore.WriteFile(ctx, filename, bytes.NewReader(content)); err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case resultsCh <- tree.Datums{
+				tree.NewDString(filename),
+				tree.NewDInt(tree.DInt(rows)),
+				tree.NewDInt(tree.DInt(len(content))),
+			}:
+				return nil
+			}
+		}
+
+		if err := p.ForEachQueryRow(ctx, exportStmt.Query, func(row tree.Datums) error {
+			if err := exporter.write(row); err != nil {
+				return err
+			}
+			if exporter.rows >= chunkRows {
+				return flush()
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if exporter.rows > 0 || chunk == 0 {
+			return flush()
+		}
+		return nil
+	}
+	return fn, header, nil
+}
+
+func init() {
+	sql.AddPlanHook(exportPlanHook)
+}
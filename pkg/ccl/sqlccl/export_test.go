@@ -0,0 +1,72 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlccl
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestExportCSV(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE d.t (a INT PRIMARY KEY, b STRING)`)
+	sqlDB.Exec(t, `INSERT INTO d.t VALUES (1, 'one'), (2, NULL), (3, 'a,b'), (4, 'four')`)
+
+	sqlDB.CheckQueryResults(t,
+		`EXPORT INTO CSV 'nodelocal:///chunked' WITH chunk_rows = '3', nullas = 'N/A' FROM SELECT * FROM d.t`,
+		[][]string{{"n1.0.csv", "3", "21"}, {"n1.1.csv", "1", "7"}},
+	)
+	sqlDB.CheckQueryResults(t,
+		`EXPORT INTO CSV 'nodelocal:///delimited' WITH delimiter = '|' FROM SELECT a, b FROM d.t WHERE a > 3`,
+		[][]string{{"n1.0.csv", "1", "7"}},
+	)
+	for file, expected := range map[string]string{
+		"chunked/n1.0.csv":   "1,one\n2,N/A\n3,\"a,b\"\n",
+		"chunked/n1.1.csv":   "4,four\n",
+		"delimited/n1.0.csv": "4|four\n",
+	} {
+		content, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != expected {
+			t.Errorf("%s: expected %q, got %q", file, expected, content)
+		}
+	}
+
+	sqlDB.CheckQueryResults(t,
+		`EXPORT INTO CSV 'nodelocal:///empty' FROM SELECT * FROM d.t WHERE a > 10`,
+		[][]string{{"n1.0.csv", "0", "0"}},
+	)
+
+	if _, err := db.Exec(
+		`EXPORT INTO CSV 'nodelocal:///bad' WITH chunk_rows = '0' FROM TABLE d.t`,
+	); !testutils.IsError(err, "invalid chunk_rows value") {
+		t.Fatalf("expected invalid chunk_rows error, got %v", err)
+	}
+}
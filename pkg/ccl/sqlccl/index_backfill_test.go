@@ -0,0 +1,88 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package sqlccl
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestIndexBackfillConcurrentWrites checks that the index entries of rows
+// deleted, updated or inserted while an index is backfilled are correct, both
+// when the entries are ingested as SSTables and when they are written in
+// transactions.
+func TestIndexBackfillConcurrentWrites(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// concurrentWrites is run before the first chunk of each backfill, after
+	// the backfill picked the timestamp at which it reads the table.
+	var concurrentWrites atomic.Value
+	concurrentWrites.Store(func() error { return nil })
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			SQLSchemaChanger: &sql.SchemaChangerTestingKnobs{
+				BackfillChunkSize: 10,
+			},
+			DistSQL: &distsqlrun.TestingKnobs{
+				RunBeforeBackfillChunk: func(_ roachpb.Span) error {
+					fn := concurrentWrites.Load().(func() error)
+					concurrentWrites.Store(func() error { return nil })
+					return fn()
+				},
+			},
+		},
+	})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	for _, bulk := range []bool{true, false} {
+		t.Run(fmt.Sprintf("bulk=%t", bulk), func(t *testing.T) {
+			sqlDB.Exec(t, fmt.Sprintf(
+				`SET CLUSTER SETTING schemachanger.bulk_index_backfill.enabled = %t`, bulk,
+			))
+			sqlDB.Exec(t, `DROP TABLE IF EXISTS d.t`)
+			sqlDB.Exec(t, `CREATE TABLE d.t (k INT PRIMARY KEY, v INT)`)
+			sqlDB.Exec(t, `INSERT INTO d.t SELECT x, x FROM generate_series(0, 99) AS g(x)`)
+
+			concurrentWrites.Store(func() error {
+				for _, stmt := range []string{
+					`DELETE FROM d.t WHERE k < 10`,
+					`UPDATE d.t SET v = v + 1000 WHERE k >= 90`,
+					`INSERT INTO d.t SELECT x, x FROM generate_series(100, 109) AS g(x)`,
+				} {
+					if _, err := db.Exec(stmt); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			sqlDB.Exec(t, `CREATE INDEX foo ON d.t (v)`)
+
+			expected := sqlDB.QueryStr(t, `SELECT k, v FROM d.t@primary ORDER BY v`)
+			if len(expected) != 100 {
+				t.Fatalf("expected 100 rows, got %d", len(expected))
+			}
+			sqlDB.CheckQueryResults(t, `SELECT k, v FROM d.t@foo ORDER BY v`, expected)
+		})
+	}
+}
@@ -10,6 +10,7 @@ package sqlccl
 
 import (
 	"math"
+	"net/url"
 	"runtime"
 	"sort"
 	"sync/atomic"
@@ -55,22 +56,97 @@ var restoreOptionExpectValues = map[string]bool{
 	restoreOptSkipMissingFKs: false,
 }
 
+// loadBackupDescs reads the descriptors of the given backups, each given by
+// the URIs of its partitions. It also returns, for each backup, the storage of
+// its locality partitions keyed by the LocalityKV of their files.
 func loadBackupDescs(
-	ctx context.Context, uris []string, settings *cluster.Settings,
-) ([]BackupDescriptor, error) {
-	backupDescs := make([]BackupDescriptor, len(uris))
+	ctx context.Context, from [][]string, settings *cluster.Settings,
+) ([]BackupDescriptor, []map[string]roachpb.ExportStorage, error) {
+	backupDescs := make([]BackupDescriptor, len(from))
+	localityDirs := make([]map[string]roachpb.ExportStorage, len(from))
 
-	for i, uri := range uris {
-		desc, err := ReadBackupDescriptorFromURI(ctx, uri, settings)
+	for i, uris := range from {
+		defaultURI, urisByLocalityKV, err := getURIsByLocalityKV(uris)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to read backup descriptor")
+			return nil, nil, err
+		}
+		desc, err := ReadBackupDescriptorFromURI(ctx, defaultURI, settings)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read backup descriptor")
 		}
 		backupDescs[i] = desc
+		localityDirs[i] = make(map[string]roachpb.ExportStorage, len(urisByLocalityKV))
+		for kv, uri := range urisByLocalityKV {
+			conf, err := storageccl.ExportStorageConfFromURI(uri)
+			if err != nil {
+				return nil, nil, err
+			}
+			localityDirs[i][kv] = conf
+		}
 	}
 	if len(backupDescs) == 0 {
-		return nil, errors.Errorf("no backups found")
+		return nil, nil, errors.Errorf("no backups found")
+	}
+	return backupDescs, localityDirs, nil
+}
+
+// flattenPartitionedBackupURIs returns the URIs of the given backups as a
+// single list, in which the default URI of each backup comes first, followed
+// by the URIs of its locality partitions. This is how the URIs are stored in
+// the RestoreDetails of a job, which splitPartitionedBackupURIs reverses.
+func flattenPartitionedBackupURIs(from [][]string) ([]string, error) {
+	var flattened []string
+	for _, uris := range from {
+		var defaultURI string
+		var localityURIs []string
+		for _, uri := range uris {
+			isDefault, err := isDefaultLocalityURI(uri)
+			if err != nil {
+				return nil, err
+			}
+			if isDefault && defaultURI == "" {
+				defaultURI = uri
+			} else {
+				localityURIs = append(localityURIs, uri)
+			}
+		}
+		if defaultURI == "" {
+			return nil, errors.Errorf(
+				"no default URL provided for partitioned backup: one of the URLs must have %s=%s",
+				localityURLParam, defaultLocalityValue)
+		}
+		flattened = append(flattened, defaultURI)
+		flattened = append(flattened, localityURIs...)
+	}
+	return flattened, nil
+}
+
+// splitPartitionedBackupURIs splits the URIs flattened by
+// flattenPartitionedBackupURIs into the URIs of each backup.
+func splitPartitionedBackupURIs(flattened []string) ([][]string, error) {
+	var from [][]string
+	for _, uri := range flattened {
+		isDefault, err := isDefaultLocalityURI(uri)
+		if err != nil {
+			return nil, err
+		}
+		if isDefault || len(from) == 0 {
+			from = append(from, nil)
+		}
+		from[len(from)-1] = append(from[len(from)-1], uri)
+	}
+	return from, nil
+}
+
+// isDefaultLocalityURI returns whether the URI is the default URI of a backup:
+// either it has no locality parameter, or its locality is the default one.
+func isDefaultLocalityURI(uri string) (bool, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return false, err
 	}
-	return backupDescs, nil
+	localityKV := parsed.Query().Get(localityURLParam)
+	return localityKV == "" || localityKV == defaultLocalityValue, nil
 }
 
 func selectTargets(
@@ -106,7 +182,7 @@ func selectTargets(
 // allocateTableRewrites determines the new ID and parentID (a "TableRewrite")
 // for each table in sqlDescs and returns a mapping from old ID to said
 // TableRewrite. It first validates that the provided sqlDescs can be restored
-// into their original database (or the database specified in opst) to avoid
+// into their original database (or the database specified in opts) to avoid
 // leaking table IDs if we can be sure the restore would fail.
 func allocateTableRewrites(
 	ctx context.Context,
@@ -116,7 +192,10 @@ func allocateTableRewrites(
 	opts map[string]string,
 ) (tableRewriteMap, error) {
 	tableRewrites := make(tableRewriteMap)
-	_, renaming := opts[restoreOptIntoDB]
+	intoDB, renaming := opts[restoreOptIntoDB]
+	if renaming && intoDB == "" {
+		return nil, errors.Errorf("%q option requires a non-empty database name", restoreOptIntoDB)
+	}
 
 	restoreDBNames := make(map[string]*sqlbase.DatabaseDescriptor, len(restoreDBs))
 	for _, db := range restoreDBs {
@@ -183,8 +262,8 @@ func allocateTableRewrites(
 
 		for _, table := range tablesByID {
 			var targetDB string
-			if override, ok := opts[restoreOptIntoDB]; ok {
-				targetDB = override
+			if renaming {
+				targetDB = intoDB
 			} else {
 				database, ok := databasesByID[table.ParentID]
 				if !ok {
@@ -444,8 +523,15 @@ type importEntry struct {
 //
 // NB: All grouping operates in the pre-rewrite keyspace, meaning the keyranges
 // as they were backed up, not as they're being restored.
+//
+// The files of a partitioned backup are read from the storage of their
+// locality in localityDirs, which holds an entry per backup. localityDirs may
+// be nil when only the spans and the end time are needed.
 func makeImportSpans(
-	tableSpans []roachpb.Span, backups []BackupDescriptor, lowWaterMark roachpb.Key,
+	tableSpans []roachpb.Span,
+	backups []BackupDescriptor,
+	localityDirs []map[string]roachpb.ExportStorage,
+	lowWaterMark roachpb.Key,
 ) ([]importEntry, hlc.Timestamp, error) {
 	// Put the covering for the already-completed spans into the
 	// OverlapCoveringMerge input first. Payloads are returned in the same order
@@ -483,7 +569,7 @@ func makeImportSpans(
 	// backup2 files) so they will retain that alternation in the output of
 	// OverlapCoveringMerge.
 	var maxEndTime hlc.Timestamp
-	for _, b := range backups {
+	for i, b := range backups {
 		if maxEndTime.Less(b.EndTime) {
 			maxEndTime = b.EndTime
 		}
@@ -499,13 +585,22 @@ func makeImportSpans(
 		backupCoverings = append(backupCoverings, backupSpanCovering)
 		var backupFileCovering intervalccl.Covering
 		for _, f := range b.Files {
+			dir := b.Dir
+			if f.LocalityKV != "" && localityDirs != nil {
+				var ok bool
+				if dir, ok = localityDirs[i][f.LocalityKV]; !ok {
+					return nil, hlc.Timestamp{}, errors.Errorf(
+						"no URL provided for the partition of locality %s of the backup ending at %s",
+						f.LocalityKV, b.EndTime)
+				}
+			}
 			backupFileCovering = append(backupFileCovering, intervalccl.Range{
 				Start: f.Span.Key,
 				End:   f.Span.EndKey,
 				Payload: importEntry{
 					Span:      f.Span,
 					entryType: backupFile,
-					dir:       b.Dir,
+					dir:       dir,
 					file:      f,
 				},
 			})
@@ -723,7 +818,7 @@ func restoreTableDescs(
 		wroteDBs := make(map[sqlbase.ID]*sqlbase.DatabaseDescriptor)
 		for _, desc := range databases {
 			// TODO(dt): support restoring privs.
-			desc.Privileges = sqlbase.NewDefaultPrivilegeDescriptor()
+			desc.Privileges = sqlbase.NewDefaultDatabasePrivilegeDescriptor()
 			wroteDBs[desc.ID] = desc
 			b.CPut(sqlbase.MakeDescMetadataKey(desc.ID), sqlbase.WrapDescriptor(desc), nil)
 			b.CPut(sqlbase.MakeNameMetadataKey(keys.RootNamespaceID, desc.Name), desc.ID, nil)
@@ -760,20 +855,22 @@ func restoreTableDescs(
 	return errors.Wrap(err, "restoring table desc and namespace entries")
 }
 
-func restoreJobDescription(restore *tree.Restore, from []string) (string, error) {
+func restoreJobDescription(restore *tree.Restore, from [][]string) (string, error) {
 	r := &tree.Restore{
 		AsOf:    restore.AsOf,
 		Options: restore.Options,
 		Targets: restore.Targets,
-		From:    make(tree.Exprs, len(restore.From)),
+		From:    make(tree.PartitionedBackups, len(from)),
 	}
 
-	for i, f := range from {
-		sf, err := storageccl.SanitizeExportStorageURI(f)
-		if err != nil {
-			return "", err
+	for i, uris := range from {
+		for _, f := range uris {
+			sf, err := storageccl.SanitizeExportStorageURI(f)
+			if err != nil {
+				return "", err
+			}
+			r.From[i] = append(r.From[i], tree.NewDString(sf))
 		}
-		r.From[i] = tree.NewDString(sf)
 	}
 
 	return tree.AsStringWithFlags(r, tree.FmtSimpleQualified), nil
@@ -786,6 +883,7 @@ func restore(
 	db *client.DB,
 	gossip *gossip.Gossip,
 	backupDescs []BackupDescriptor,
+	localityDirs []map[string]roachpb.ExportStorage,
 	endTime hlc.Timestamp,
 	sqlDescs []sqlbase.Descriptor,
 	tableRewrites tableRewriteMap,
@@ -799,9 +897,16 @@ func restore(
 
 	if endTime != (hlc.Timestamp{}) {
 		for _, b := range backupDescs {
-			if b.StartTime.Less(endTime) && endTime.Less(b.EndTime) && b.MVCCFilter != MVCCFilter_All {
-				return failed, errors.Errorf(
-					"incompatible RESTORE timestamp (BACKUP needs option '%s')", backupOptRevisionHistory)
+			if b.StartTime.Less(endTime) && endTime.Less(b.EndTime) {
+				if b.MVCCFilter != MVCCFilter_All {
+					return failed, errors.Errorf(
+						"incompatible RESTORE timestamp (BACKUP needs option '%s')", backupOptRevisionHistory)
+				}
+				if endTime.Less(b.RevisionStartTime) {
+					return failed, errors.Errorf(
+						"invalid RESTORE timestamp: the revisions before %s may have been garbage collected when the backup was taken",
+						b.RevisionStartTime)
+				}
 			}
 		}
 	}
@@ -854,7 +959,7 @@ func restore(
 	// Pivot the backups, which are grouped by time, into requests for import,
 	// which are grouped by keyrange.
 	lowWaterMark := job.Record.Details.(jobs.RestoreDetails).LowWaterMark
-	importSpans, _, err := makeImportSpans(spans, backupDescs, lowWaterMark)
+	importSpans, _, err := makeImportSpans(spans, backupDescs, localityDirs, lowWaterMark)
 	if err != nil {
 		return failed, errors.Wrapf(err, "making import requests for %d backups", len(backupDescs))
 	}
@@ -1050,9 +1155,13 @@ func restorePlanHook(
 		return nil, nil, err
 	}
 
-	fromFn, err := p.TypeAsStringArray(restoreStmt.From, "RESTORE")
-	if err != nil {
-		return nil, nil, err
+	fromFns := make([]func() ([]string, error), len(restoreStmt.From))
+	for i := range restoreStmt.From {
+		fromFn, err := p.TypeAsStringArray(tree.Exprs(restoreStmt.From[i]), "RESTORE")
+		if err != nil {
+			return nil, nil, err
+		}
+		fromFns[i] = fromFn
 	}
 
 	optsFn, err := p.TypeAsStringOpts(restoreStmt.Options, restoreOptionExpectValues)
@@ -1065,9 +1174,13 @@ func restorePlanHook(
 		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
 		defer tracing.FinishSpan(span)
 
-		from, err := fromFn()
-		if err != nil {
-			return err
+		from := make([][]string, len(fromFns))
+		for i, fromFn := range fromFns {
+			uris, err := fromFn()
+			if err != nil {
+				return err
+			}
+			from[i] = uris
 		}
 		var endTime hlc.Timestamp
 		if restoreStmt.AsOf.Expr != nil {
@@ -1093,7 +1206,7 @@ func doRestorePlan(
 	ctx context.Context,
 	restoreStmt *tree.Restore,
 	p sql.PlanHookState,
-	from []string,
+	from [][]string,
 	endTime hlc.Timestamp,
 	opts map[string]string,
 	resultsCh chan<- tree.Datums,
@@ -1101,7 +1214,7 @@ func doRestorePlan(
 	if err := restoreStmt.Targets.NormalizeTablesWithDatabase(p.EvalContext().Database); err != nil {
 		return err
 	}
-	backupDescs, err := loadBackupDescs(ctx, from, p.ExecCfg().Settings)
+	backupDescs, localityDirs, err := loadBackupDescs(ctx, from, p.ExecCfg().Settings)
 	if err != nil {
 		return err
 	}
@@ -1118,6 +1231,10 @@ func doRestorePlan(
 	if err != nil {
 		return err
 	}
+	uris, err := flattenPartitionedBackupURIs(from)
+	if err != nil {
+		return err
+	}
 	job := p.ExecCfg().JobRegistry.NewJob(jobs.Record{
 		Description: description,
 		Username:    p.User(),
@@ -1130,7 +1247,7 @@ func doRestorePlan(
 		Details: jobs.RestoreDetails{
 			EndTime:       endTime,
 			TableRewrites: tableRewrites,
-			URIs:          from,
+			URIs:          uris,
 		},
 	})
 	res, restoreErr := restore(
@@ -1138,6 +1255,7 @@ func doRestorePlan(
 		p.ExecCfg().DB,
 		p.ExecCfg().Gossip,
 		backupDescs,
+		localityDirs,
 		endTime,
 		sqlDescs,
 		tableRewrites,
@@ -1164,10 +1282,14 @@ func doRestorePlan(
 
 func loadBackupSQLDescs(
 	ctx context.Context, details jobs.RestoreDetails, settings *cluster.Settings,
-) ([]BackupDescriptor, []sqlbase.Descriptor, error) {
-	backupDescs, err := loadBackupDescs(ctx, details.URIs, settings)
+) ([]BackupDescriptor, []map[string]roachpb.ExportStorage, []sqlbase.Descriptor, error) {
+	from, err := splitPartitionedBackupURIs(details.URIs)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
+	}
+	backupDescs, localityDirs, err := loadBackupDescs(ctx, from, settings)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 	lastBackupDesc := backupDescs[len(backupDescs)-1]
 
@@ -1177,7 +1299,7 @@ func loadBackupSQLDescs(
 			sqlDescs = append(sqlDescs, desc)
 		}
 	}
-	return backupDescs, sqlDescs, nil
+	return backupDescs, localityDirs, sqlDescs, nil
 }
 
 // restoreFailHook removes KV data that has been committed from a restore that
@@ -1191,7 +1313,7 @@ func restoreFailHook(
 	if err := txn.SetSystemConfigTrigger(); err != nil {
 		return err
 	}
-	_, sqlDescs, err := loadBackupSQLDescs(ctx, *details, settings)
+	_, _, sqlDescs, err := loadBackupSQLDescs(ctx, *details, settings)
 	if err != nil {
 		return err
 	}
@@ -1222,7 +1344,7 @@ func restoreResumeHook(
 	return func(ctx context.Context, job *jobs.Job) error {
 		details := job.Record.Details.(jobs.RestoreDetails)
 
-		backupDescs, sqlDescs, err := loadBackupSQLDescs(ctx, details, settings)
+		backupDescs, localityDirs, sqlDescs, err := loadBackupSQLDescs(ctx, details, settings)
 		if err != nil {
 			return err
 		}
@@ -1232,6 +1354,7 @@ func restoreResumeHook(
 			job.DB(),
 			job.Gossip(),
 			backupDescs,
+			localityDirs,
 			details.EndTime,
 			sqlDescs,
 			details.TableRewrites,
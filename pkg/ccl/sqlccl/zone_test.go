@@ -202,6 +202,137 @@ func TestValidIndexPartitionSetShowZones(t *testing.T) {
 	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, `INDEX "primary"`, primaryRow)
 }
 
+func TestSetIndexPartitionZoneConfigSettings(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `
+		CREATE DATABASE d;
+		USE d;
+		CREATE TABLE t (c STRING PRIMARY KEY, v INT, INDEX i (v)) PARTITION BY LIST (c) (
+			PARTITION p0 VALUES IN ('a'),
+			PARTITION p1 VALUES IN (DEFAULT)
+		)`)
+
+	tableZone := config.DefaultZoneConfig()
+	tableZone.NumReplicas = 5
+	tableRow := sqlutils.ZoneRow{
+		ID:           keys.MaxReservedDescID + 2,
+		CLISpecifier: "d.t",
+		Config:       tableZone,
+	}
+	sqlDB.Exec(t, `ALTER TABLE t CONFIGURE ZONE USING num_replicas = 5`)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.t", tableRow)
+
+	// The index and partition zone configs inherit the fields that are not set
+	// from the table zone config.
+	iZone := tableZone
+	iZone.GC.TTLSeconds = 42
+	iRow := sqlutils.ZoneRow{
+		ID:           keys.MaxReservedDescID + 2,
+		CLISpecifier: "d.t@i",
+		Config:       iZone,
+	}
+	sqlDB.Exec(t, `ALTER INDEX t@i CONFIGURE ZONE USING gc.ttlseconds = 42`)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "INDEX d.t@i", iRow)
+
+	p0Zone := tableZone
+	p0Zone.LeasePreferences = []config.LeasePreference{{
+		Constraints: []config.Constraint{{Type: config.Constraint_REQUIRED, Key: "dc", Value: "dc1"}},
+	}}
+	p0Row := sqlutils.ZoneRow{
+		ID:           keys.MaxReservedDescID + 2,
+		CLISpecifier: "d.t.p0",
+		Config:       p0Zone,
+	}
+	sqlDB.Exec(t, `ALTER TABLE t PARTITION p0 CONFIGURE ZONE USING lease_preferences = '[[+dc=dc1]]'`)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.t PARTITION p0", p0Row)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "INDEX d.t@i", iRow)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.t", tableRow)
+
+	// Discarding the table zone config keeps the subzones.
+	sqlDB.Exec(t, `ALTER TABLE t CONFIGURE ZONE DISCARD`)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.t PARTITION p0", p0Row)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "INDEX d.t@i", iRow)
+	sqlDB.Exec(t, `ALTER TABLE t PARTITION p0 CONFIGURE ZONE DISCARD`)
+	sqlDB.Exec(t, `ALTER INDEX t@i CONFIGURE ZONE DISCARD`)
+	sqlutils.VerifyAllZoneConfigs(t, sqlDB, sqlutils.ZoneRow{
+		ID:           keys.RootNamespaceID,
+		CLISpecifier: ".default",
+		Config:       config.DefaultZoneConfig(),
+	})
+}
+
+func TestRegionZoneConfigs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `
+		CREATE DATABASE d;
+		USE d;
+		ALTER DATABASE d SET PRIMARY REGION east;
+		CREATE TABLE g (a INT PRIMARY KEY) LOCALITY GLOBAL;
+		CREATE TABLE r (crdb_region STRING, a INT, PRIMARY KEY (crdb_region, a)) LOCALITY REGIONAL BY ROW;
+		CREATE TABLE t (a INT PRIMARY KEY)`)
+
+	inRegion := func(zone config.ZoneConfig, region string) config.ZoneConfig {
+		c := []config.Constraint{{Type: config.Constraint_REQUIRED, Key: "region", Value: region}}
+		zone.Constraints = config.Constraints{Constraints: c}
+		zone.LeasePreferences = []config.LeasePreference{{Constraints: c}}
+		return zone
+	}
+
+	// The database, and the REGIONAL BY TABLE tables, are in the primary
+	// region.
+	dbRow := sqlutils.ZoneRow{
+		ID:           keys.MaxReservedDescID + 1,
+		CLISpecifier: "d",
+		Config:       inRegion(config.DefaultZoneConfig(), "east"),
+	}
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "DATABASE d", dbRow)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.t", dbRow)
+
+	// The replicas of GLOBAL tables are in every region, and their leases in
+	// the primary region.
+	globalZone := inRegion(config.DefaultZoneConfig(), "east")
+	globalZone.Constraints = config.Constraints{}
+	globalRow := sqlutils.ZoneRow{
+		ID:           keys.MaxReservedDescID + 2,
+		CLISpecifier: "d.g",
+		Config:       globalZone,
+	}
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.g", globalRow)
+
+	// Every partition of REGIONAL BY ROW tables is in its region, including
+	// the partitions of the regions added later.
+	sqlDB.Exec(t, `ALTER DATABASE d ADD REGION west`)
+	for _, region := range []string{"east", "west"} {
+		sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.r PARTITION "+region, sqlutils.ZoneRow{
+			ID:           keys.MaxReservedDescID + 3,
+			CLISpecifier: "d.r." + region,
+			Config:       inRegion(config.DefaultZoneConfig(), region),
+		})
+	}
+	sqlDB.Exec(t, `INSERT INTO r VALUES ('west', 1)`)
+	sqlDB.CheckQueryResults(t, `SELECT name, list_value FROM crdb_internal.partitions ORDER BY name`,
+		[][]string{{"east", "('east')"}, {"west", "('west')"}})
+
+	// Changing the locality of a table replaces its zone configs.
+	sqlDB.Exec(t, `
+		ALTER TABLE g SET LOCALITY REGIONAL BY TABLE;
+		ALTER TABLE r SET LOCALITY GLOBAL`)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.g", dbRow)
+	globalRow.ID, globalRow.CLISpecifier = keys.MaxReservedDescID+3, "d.r"
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.r", globalRow)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM crdb_internal.partitions`, [][]string{{"0"}})
+}
+
 func TestInvalidIndexPartitionSetShowZones(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
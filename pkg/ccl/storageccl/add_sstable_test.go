@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval"
+	"github.com/cockroachdb/cockroach/pkg/storage/bulk"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -342,3 +343,51 @@ func TestAddSSTableMVCCStats(t *testing.T) {
 		}
 	}
 }
+
+// TestSSTBatcherAcrossRanges checks that an SSTable spanning several ranges
+// is split at their boundaries when it is ingested.
+func TestSSTBatcherAcrossRanges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, db := serverutils.StartServer(t, base.TestServerArgs{Insecure: true})
+	ctx := context.Background()
+	defer s.Stopper().Stop(ctx)
+
+	for _, splitKey := range []string{"d", "g"} {
+		if err := db.AdminSplit(ctx, splitKey, splitKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := bulk.MakeSSTBatcher(db, s.ClusterSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	var expected []string
+	for c := 'a'; c <= 'j'; c++ {
+		key := roachpb.Key(string(c))
+		value := roachpb.MakeValueFromString(string(c))
+		value.InitChecksum(key)
+		if err := b.Add(
+			engine.MVCCKey{Key: key, Timestamp: hlc.Timestamp{WallTime: 1}}, value.RawBytes,
+		); err != nil {
+			t.Fatal(err)
+		}
+		expected = append(expected, string(c))
+	}
+	if err := b.Finish(ctx); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	kvs, err := db.Scan(ctx, "a", "k", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, kv := range kvs {
+		actual = append(actual, string(kv.ValueBytes()))
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
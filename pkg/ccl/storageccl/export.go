@@ -128,12 +128,6 @@ func evalExport(
 	defer exportRequestLimiter.endLimitedRequest()
 	log.Infof(ctx, "export [%s,%s)", args.Key, args.EndKey)
 
-	exportStore, err := MakeExportStorage(ctx, args.Storage, cArgs.EvalCtx.ClusterSettings())
-	if err != nil {
-		return result.Result{}, err
-	}
-	defer exportStore.Close()
-
 	sst, err := engine.MakeRocksDBSstFileWriter()
 	if err != nil {
 		return result.Result{}, err
@@ -207,18 +201,36 @@ func evalExport(
 		return result.Result{}, err
 	}
 
-	filename := fmt.Sprintf("%d.sst", builtins.GenerateUniqueInt(cArgs.EvalCtx.NodeID()))
-	if err := exportStore.WriteFile(ctx, filename, bytes.NewReader(sstContents)); err != nil {
-		return result.Result{}, err
-	}
-
-	reply.Files = []roachpb.ExportResponse_File{{
+	exported := roachpb.ExportResponse_File{
 		Span:     args.Span,
-		Path:     filename,
 		Exported: rows.BulkOpSummary,
 		Sha512:   checksum,
-	}}
+	}
+
+	if args.ReturnSST {
+		exported.SST = sstContents
+	} else {
+		conf := args.Storage
+		for _, tier := range cArgs.EvalCtx.GetNodeLocality().Tiers {
+			if s, ok := args.StorageByLocalityKV[tier.String()]; ok {
+				conf = *s
+				exported.LocalityKV = tier.String()
+				break
+			}
+		}
+		exportStore, err := MakeExportStorage(ctx, conf, cArgs.EvalCtx.ClusterSettings())
+		if err != nil {
+			return result.Result{}, err
+		}
+		defer exportStore.Close()
+
+		exported.Path = fmt.Sprintf("%d.sst", builtins.GenerateUniqueInt(cArgs.EvalCtx.NodeID()))
+		if err := exportStore.WriteFile(ctx, exported.Path, bytes.NewReader(sstContents)); err != nil {
+			return result.Result{}, err
+		}
+	}
 
+	reply.Files = []roachpb.ExportResponse_File{exported}
 	return result.Result{}, nil
 }
 
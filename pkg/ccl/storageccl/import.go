@@ -19,12 +19,12 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl/engineccl"
-	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval"
+	"github.com/cockroachdb/cockroach/pkg/storage/bulk"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
@@ -78,61 +78,6 @@ func maxImportBatchSize(st *cluster.Settings) int64 {
 	return desiredSize
 }
 
-type sstBatcher struct {
-	sstWriter     engine.RocksDBSstFileWriter
-	batchStartKey []byte
-	batchEndKey   []byte
-}
-
-func (b *sstBatcher) Add(key engine.MVCCKey, value []byte) error {
-	// Update the range currently represented in this batch, as
-	// necessary.
-	if len(b.batchStartKey) == 0 || bytes.Compare(key.Key, b.batchStartKey) < 0 {
-		b.batchStartKey = append(b.batchStartKey[:0], key.Key...)
-	}
-	if len(b.batchEndKey) == 0 || bytes.Compare(key.Key, b.batchEndKey) > 0 {
-		b.batchEndKey = append(b.batchEndKey[:0], key.Key...)
-	}
-
-	return b.sstWriter.Add(engine.MVCCKeyValue{Key: key, Value: value})
-}
-
-func (b *sstBatcher) Size() int64 {
-	return b.sstWriter.DataSize
-}
-
-func (b *sstBatcher) Finish(ctx context.Context, db *client.DB) error {
-	start := roachpb.Key(b.batchStartKey)
-	// The end key of the WriteBatch request is exclusive, but batchEndKey is
-	// currently the largest key in the batch. Increment it.
-	end := roachpb.Key(b.batchEndKey).Next()
-
-	sstBytes, err := b.sstWriter.Finish()
-	if err != nil {
-		return errors.Wrapf(err, "finishing constructed sstable")
-	}
-
-	const maxAddSSTableRetries = 10
-	for i := 0; ; i++ {
-		log.VEventf(ctx, 2, "sending AddSSTable [%s,%s)", start, end)
-		// TODO(dan): This will fail if the range has split.
-		err := db.AddSSTable(ctx, start, end, sstBytes)
-		if err == nil {
-			return nil
-		}
-		if _, ok := err.(*roachpb.AmbiguousResultError); i == maxAddSSTableRetries || !ok {
-			return errors.Wrapf(err, "addsstable [%s,%s)", start, end)
-		}
-		log.Warningf(ctx, "addsstable [%s,%s) attempt %d failed: %+v",
-			start, end, i, err)
-		continue
-	}
-}
-
-func (b *sstBatcher) Close() {
-	b.sstWriter.Close()
-}
-
 // evalImport bulk loads key/value entries.
 func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.ImportResponse, error) {
 	args := cArgs.Args.(*roachpb.ImportRequest)
@@ -199,17 +144,14 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 		iters = append(iters, iter)
 	}
 
-	var batcher *sstBatcher
+	var batcher *bulk.SSTBatcher
 	makeBatcher := func() error {
 		if batcher != nil {
 			return errors.New("cannot overwrite a batcher")
 		}
-		sstWriter, err := engine.MakeRocksDBSstFileWriter()
-		if err != nil {
-			return errors.Wrapf(err, "making sstBatcher")
-		}
-		batcher = &sstBatcher{sstWriter: sstWriter}
-		return nil
+		var err error
+		batcher, err = bulk.MakeSSTBatcher(db, cArgs.EvalCtx.ClusterSettings())
+		return err
 	}
 	if err := makeBatcher(); err != nil {
 		return nil, err
@@ -291,7 +233,7 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 			g.Go(func() error {
 				defer log.Event(ctx, "finished batch")
 				defer finishBatcher.Close()
-				return errors.Wrapf(finishBatcher.Finish(gCtx, db), "import [%s, %s)", startKeyMVCC.Key, endKeyMVCC.Key)
+				return errors.Wrapf(finishBatcher.Finish(gCtx), "import [%s, %s)", startKeyMVCC.Key, endKeyMVCC.Key)
 			})
 			if err := makeBatcher(); err != nil {
 				return nil, err
@@ -303,7 +245,7 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 		g.Go(func() error {
 			defer log.Event(ctx, "finished batch")
 			defer batcher.Close()
-			return batcher.Finish(gCtx, db)
+			return batcher.Finish(gCtx)
 		})
 	}
 	log.Event(ctx, "waiting for batchers to finish")
@@ -698,6 +698,7 @@ func Example_sql() {
 	// INSERT 1
 	// sql -e delete from t.f
 	// pq: rejected: DELETE without WHERE clause (sql_safe_updates = true)
+	// HINT: to run the statement anyway, first use SET sql_safe_updates = false
 	// sql -e select 3 -e select * from t.f
 	// 3
 	// 3
@@ -1818,6 +1819,11 @@ writing ` + os.DevNull + `
   debug/nodes/1/ranges/15
   debug/nodes/1/ranges/16
   debug/nodes/1/ranges/17
+  debug/nodes/1/ranges/18
+  debug/nodes/1/ranges/19
+  debug/nodes/1/ranges/20
+  debug/nodes/1/ranges/21
+  debug/nodes/1/ranges/22
   debug/schema/system@details
   debug/schema/system/descriptor
   debug/schema/system/eventlog
@@ -1825,7 +1831,12 @@ writing ` + os.DevNull + `
   debug/schema/system/lease
   debug/schema/system/namespace
   debug/schema/system/rangelog
+  debug/schema/system/role_members
+  debug/schema/system/role_options
+  debug/schema/system/scheduled_jobs
   debug/schema/system/settings
+  debug/schema/system/statement_bundles
+  debug/schema/system/statement_diagnostics_requests
   debug/schema/system/table_statistics
   debug/schema/system/ui
   debug/schema/system/users
@@ -291,6 +291,25 @@ Note: when given a path to a unix socket, most postgres clients will
 open "<given path>/.s.PGSQL.<server port>"`,
 	}
 
+	SocketDir = FlagInfo{
+		Name:   "socket-dir",
+		EnvVar: "COCKROACH_SOCKET_DIR",
+		Description: `
+Directory in which to listen for postgresql protocol connections on a
+Unix socket named like those of PostgreSQL, ".s.PGSQL.<server port>".
+Local clients connect to it by giving the directory as their host, for
+example with <PRE>
+
+  psql -h <directory> -p <server port>
+
+</PRE>
+Connections over the socket never use SSL. In secure mode, they are
+authenticated with the methods of the "local" entries of the host-based
+authentication configuration, among which "peer" authenticates clients
+with their operating system user name, and with a password when the
+configuration is empty. Ignored if --socket is given.`,
+	}
+
 	ClientInsecure = FlagInfo{
 		Name:   "insecure",
 		EnvVar: "COCKROACH_INSECURE",
@@ -882,6 +882,8 @@ func parseGossipValues(gossipInfo *gossip.InfoStatus) (string, error) {
 				return "", errors.Wrapf(err, "failed to parse value for key %q", key)
 			}
 			output = append(output, fmt.Sprintf("%q: %+v", key, deadReplicas))
+		} else if strings.HasPrefix(key, gossip.KeyNotificationPrefix) {
+			output = append(output, fmt.Sprintf("%q: %s", key, bytes))
 		}
 	}
 
@@ -19,6 +19,7 @@ import (
 	"flag"
 	"fmt"
 	"net"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -40,6 +41,7 @@ var sqlConnURL, sqlConnUser, sqlConnDBName string
 var serverConnHost, serverConnPort, serverAdvertiseHost, serverAdvertisePort string
 var serverHTTPHost, serverHTTPPort string
 var clientConnHost, clientConnPort string
+var serverSocketDir string
 var tempDir string
 var externalIODir string
 
@@ -223,6 +225,7 @@ func init() {
 		// Thus, we keep it hidden and use it for testing only.
 		stringFlag(f, &serverCfg.SocketFile, cliflags.Socket, "")
 		_ = f.MarkHidden(cliflags.Socket.Name)
+		stringFlag(f, &serverSocketDir, cliflags.SocketDir, "")
 
 		stringFlag(f, &serverCfg.ListeningURLFile, cliflags.ListeningURLFile, "")
 
@@ -399,6 +402,11 @@ func extraServerFlagInit() {
 		serverHTTPHost = serverConnHost
 	}
 	serverCfg.HTTPAddr = net.JoinHostPort(serverHTTPHost, serverHTTPPort)
+	if serverCfg.SocketFile == "" && serverSocketDir != "" {
+		// Name the socket like PostgreSQL does, so that clients given the
+		// directory as host and the server port find it.
+		serverCfg.SocketFile = filepath.Join(serverSocketDir, ".s.PGSQL."+serverConnPort)
+	}
 }
 
 func extraClientFlagInit() {
@@ -248,3 +248,37 @@ func TestHttpHostFlagValue(t *testing.T) {
 		}
 	}
 }
+
+func TestSocketDirFlagValue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	f := startCmd.Flags()
+	testData := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"start"}, ""},
+		{[]string{"start", "--socket-dir", "/tmp"}, "/tmp/.s.PGSQL." + base.DefaultPort},
+		{[]string{"start", "--socket-dir", "/tmp", "--port", "12345"}, "/tmp/.s.PGSQL.12345"},
+		{[]string{"start", "--socket-dir", "/tmp", "--socket", "/tmp/sock"}, "/tmp/sock"},
+	}
+
+	for i, td := range testData {
+		// Ensure each test case starts with empty package-level variables.
+		serverSocketDir = ""
+		serverCfg.SocketFile = ""
+		serverConnPort = base.DefaultPort
+
+		if err := f.Parse(td.args); err != nil {
+			t.Fatalf("Parse(%#v) got unexpected error: %v", td.args, err)
+		}
+
+		extraServerFlagInit()
+		if td.expected != serverCfg.SocketFile {
+			t.Errorf("%d. serverCfg.SocketFile expected '%s', but got '%s'. td.args was '%#v'.",
+				i, td.expected, serverCfg.SocketFile, td.args)
+		}
+	}
+	serverSocketDir = ""
+	serverCfg.SocketFile = ""
+}
@@ -29,7 +29,7 @@ import (
 
 func queryZoneSpecifiers(conn *sqlConn) ([]string, error) {
 	rows, err := makeQuery(
-		`SELECT cli_specifier FROM [EXPERIMENTAL SHOW ZONE CONFIGURATIONS] ORDER BY cli_specifier`,
+		`SELECT cli_specifier FROM [SHOW ZONE CONFIGURATIONS] ORDER BY cli_specifier`,
 	)(conn)
 	if err != nil {
 		return nil, err
@@ -86,7 +86,7 @@ func runGetZone(cmd *cobra.Command, args []string) error {
 	defer conn.Close()
 
 	vals, err := conn.QueryRow(fmt.Sprintf(
-		`SELECT cli_specifier, config_yaml FROM [EXPERIMENTAL SHOW ZONE CONFIGURATION FOR %s]`, zs), nil)
+		`SELECT cli_specifier, config_yaml FROM [SHOW ZONE CONFIGURATION FOR %s]`, zs), nil)
 	if err != nil {
 		return err
 	}
@@ -167,7 +167,7 @@ func runRmZone(cmd *cobra.Command, args []string) error {
 	defer conn.Close()
 
 	return runQueryAndFormatResults(conn, os.Stdout,
-		makeQuery(fmt.Sprintf(`ALTER %s EXPERIMENTAL CONFIGURE ZONE NULL`, zs)))
+		makeQuery(fmt.Sprintf(`ALTER %s CONFIGURE ZONE DISCARD`, zs)))
 }
 
 // A setZoneCmd command creates a new or updates an existing zone config.
@@ -186,6 +186,7 @@ The zone config format has the following YAML schema:
   range_max_bytes: <size-in-bytes>
   gc:
     ttlseconds: <time-in-seconds>
+  lease_preferences: [[comma-separated attribute list], ...]
 
 For example, to set the zone config for the system database, run:
 $ cockroach zone set system -f - << EOF
@@ -195,6 +196,9 @@ EOF
 
 Note that the specified zone config is merged with the existing zone config for
 the database or table.
+
+The zone config can also be set with SQL, e.g.:
+  ALTER DATABASE system CONFIGURE ZONE USING num_replicas = 3, constraints = '[ssd, -mem]'
 `,
 	RunE: MaybeDecorateGRPCError(runSetZone),
 }
@@ -247,7 +251,7 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		vals, err := conn.QueryRow(fmt.Sprintf(
-			`SELECT config_yaml FROM [EXPERIMENTAL SHOW ZONE CONFIGURATION FOR %s]`, zs), nil)
+			`SELECT config_yaml FROM [SHOW ZONE CONFIGURATION FOR %s]`, zs), nil)
 		if err != nil {
 			return err
 		}
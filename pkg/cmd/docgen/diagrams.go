@@ -312,6 +312,7 @@ var diagramCmd = func() *cobra.Command {
 					match:  []*regexp.Regexp{regexp.MustCompile("'COMMIT'|'END'")},
 				},
 				{name: "cancel_query", stmt: "cancel_query_stmt", replace: map[string]string{"a_expr": "query_id"}, unlink: []string{"query_id"}},
+				{name: "cancel_session", stmt: "cancel_session_stmt", replace: map[string]string{"a_expr": "session_id"}, unlink: []string{"session_id"}},
 				{name: "create_database_stmt", inline: []string{"opt_encoding_clause"}, replace: map[string]string{"'SCONST'": "encoding"}, unlink: []string{"name", "encoding"}},
 				{
 					name:   "create_index_stmt",
@@ -586,6 +587,7 @@ var diagramCmd = func() *cobra.Command {
 				{name: "show_jobs", stmt: "show_jobs_stmt"},
 				{name: "show_keys", stmt: "show_stmt", match: []*regexp.Regexp{regexp.MustCompile("'SHOW' 'KEYS'")}},
 				{name: "show_queries", stmt: "show_queries_stmt"},
+				{name: "show_ranges", stmt: "show_ranges_stmt"},
 				{name: "show_sessions", stmt: "show_sessions_stmt"},
 				{name: "show_tables", stmt: "show_stmt", match: []*regexp.Regexp{regexp.MustCompile("'SHOW' 'TABLES'")}},
 				{name: "show_trace", stmt: "show_trace_stmt"},
@@ -249,6 +249,28 @@ func (c *Constraints) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	return nil
 }
 
+var _ yaml.Marshaler = LeasePreference{}
+var _ yaml.Unmarshaler = &LeasePreference{}
+
+// MarshalYAML implements yaml.Marshaler.
+func (l LeasePreference) MarshalYAML() (interface{}, error) {
+	short := make([]string, len(l.Constraints))
+	for i, c := range l.Constraints {
+		short[i] = c.String()
+	}
+	return short, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (l *LeasePreference) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var constraints Constraints
+	if err := constraints.UnmarshalYAML(unmarshal); err != nil {
+		return err
+	}
+	l.Constraints = constraints.Constraints
+	return nil
+}
+
 // minRangeMaxBytes is the minimum value for range max bytes.
 const minRangeMaxBytes = 64 << 10 // 64 KB
 
@@ -321,6 +343,16 @@ func (z *ZoneConfig) Validate() error {
 		return fmt.Errorf("RangeMinBytes %d is greater than or equal to RangeMaxBytes %d",
 			z.RangeMinBytes, z.RangeMaxBytes)
 	}
+	for _, lp := range z.LeasePreferences {
+		if len(lp.Constraints) == 0 {
+			return fmt.Errorf("every lease preference must include at least one constraint")
+		}
+		for _, c := range lp.Constraints {
+			if c.Type == Constraint_POSITIVE {
+				return fmt.Errorf("lease preference constraints must be required (+) or prohibited (-), not %q", c)
+			}
+		}
+	}
 	return nil
 }
 
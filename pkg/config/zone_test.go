@@ -63,6 +63,34 @@ func TestZoneConfigValidate(t *testing.T) {
 			},
 			"is greater than or equal to RangeMaxBytes",
 		},
+		{
+			config.ZoneConfig{
+				NumReplicas:      1,
+				RangeMaxBytes:    config.DefaultZoneConfig().RangeMaxBytes,
+				LeasePreferences: []config.LeasePreference{{}},
+			},
+			"every lease preference must include at least one constraint",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   1,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				LeasePreferences: []config.LeasePreference{
+					{Constraints: []config.Constraint{{Type: config.Constraint_POSITIVE, Value: "ssd"}}},
+				},
+			},
+			`lease preference constraints must be required \(\+\) or prohibited \(-\), not "ssd"`,
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   1,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				LeasePreferences: []config.LeasePreference{
+					{Constraints: []config.Constraint{{Type: config.Constraint_REQUIRED, Key: "region", Value: "us"}}},
+				},
+			},
+			"",
+		},
 	}
 	for i, c := range testCases {
 		err := c.cfg.Validate()
@@ -164,6 +192,29 @@ func TestZoneConfigMarshalYAML(t *testing.T) {
 				},
 			},
 		},
+		LeasePreferences: []config.LeasePreference{
+			{
+				Constraints: []config.Constraint{
+					{
+						Type:  config.Constraint_REQUIRED,
+						Key:   "duck",
+						Value: "foo",
+					},
+				},
+			},
+			{
+				Constraints: []config.Constraint{
+					{
+						Type:  config.Constraint_PROHIBITED,
+						Value: "bar",
+					},
+					{
+						Type:  config.Constraint_REQUIRED,
+						Value: "baz",
+					},
+				},
+			},
+		},
 	}
 
 	expected := `range_min_bytes: 1
@@ -172,6 +223,7 @@ gc:
   ttlseconds: 1
 num_replicas: 1
 constraints: [foo, +duck=foo, -duck=foo]
+lease_preferences: [[+duck=foo], [-bar, +baz]]
 `
 
 	body, err := yaml.Marshal(original)
@@ -76,6 +76,10 @@ const (
 	// KeyDistSQLNodeVersionKeyPrefix is key prefix for each node's DistSQL
 	// version.
 	KeyDistSQLNodeVersionKeyPrefix = "distsql-version"
+
+	// KeyNotificationPrefix is the key prefix for the SQL notifications sent
+	// with NOTIFY. Each notification is gossiped under its own key.
+	KeyNotificationPrefix = "notification"
 )
 
 // MakeKey creates a canonical key under which to gossip a piece of
@@ -137,3 +141,9 @@ func MakeDeadReplicasKey(storeID roachpb.StoreID) string {
 func MakeDistSQLNodeVersionKey(nodeID roachpb.NodeID) string {
 	return MakeKey(KeyDistSQLNodeVersionKeyPrefix, nodeID.String())
 }
+
+// MakeNotificationKey returns the gossip key for the seq-th SQL notification
+// sent from the given node.
+func MakeNotificationKey(nodeID roachpb.NodeID, seq int64) string {
+	return MakeKey(KeyNotificationPrefix, nodeID.String(), strconv.FormatInt(seq, 10))
+}
@@ -0,0 +1,154 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlsmith
+
+import (
+	gosql "database/sql"
+	"reflect"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+)
+
+// DistSQLModes are the values of the distsql session variable under which
+// the results of the generated queries are compared by default. The "on"
+// mode distributes the queries supported by DistSQL and runs the others
+// locally, while "always" would reject the latter.
+var DistSQLModes = []string{"off", "on"}
+
+// LoadTables returns the tables of the given database, as listed in
+// information_schema.columns. The data types of the columns are parsed with
+// parseType, which is usually parser.ParseType; it is passed in so that this
+// package doesn't depend on the parser, which allows the builtins to use it.
+func LoadTables(
+	ctx context.Context,
+	db *gosql.DB,
+	database string,
+	parseType func(string) (coltypes.CastTargetType, error),
+) ([]Table, error) {
+	rows, err := db.QueryContext(ctx, `
+SELECT table_name, column_name, data_type, is_nullable = 'YES'
+  FROM information_schema.columns
+ WHERE table_schema = $1
+ ORDER BY table_name, ordinal_position`, database)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tables []Table
+	for rows.Next() {
+		var table, column, dataType string
+		var nullable bool
+		if err := rows.Scan(&table, &column, &dataType, &nullable); err != nil {
+			return nil, err
+		}
+		typ, err := parseType(dataType)
+		if err != nil {
+			// The generator doesn't support the columns of this type anyway.
+			continue
+		}
+		tables = AppendColumn(tables, database, table, Column{
+			Name:     column,
+			Type:     coltypes.CastTargetToDatumType(typ),
+			Nullable: nullable,
+		})
+	}
+	return tables, rows.Err()
+}
+
+// CompareModes runs the query under each of the given values of the distsql
+// session variable and returns an error if the results differ. The queries
+// which fail under any of the modes aren't compared: a random query may
+// legitimately fail (e.g. on a division by zero), and whether it does may
+// depend on the order in which the rows are processed. An error is returned
+// however if the query is rejected as invalid, which points at a bug of the
+// generator.
+func CompareModes(ctx context.Context, db *gosql.DB, query string, modes []string) error {
+	var first [][]string
+	for i, mode := range modes {
+		res, err := runInMode(ctx, db, query, mode)
+		if err != nil {
+			return err
+		}
+		if res == nil {
+			return nil
+		}
+		if i == 0 {
+			first = res
+		} else if !reflect.DeepEqual(first, res) {
+			return errors.Errorf("different results with distsql = %s and %s for query:\n%s\n%v\n%v",
+				modes[0], mode, query, first, res)
+		}
+	}
+	return nil
+}
+
+// runInMode runs the query with the given value of the distsql session
+// variable, on a connection of its own, and returns its rows formatted as
+// strings. It returns nil rows and no error if the query fails.
+func runInMode(ctx context.Context, db *gosql.DB, query, mode string) ([][]string, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "SET distsql = "+lex.EscapeSQLString(mode)); err != nil {
+		return nil, err
+	}
+	// The connection goes back to the pool of db.
+	defer func() { _, _ = conn.ExecContext(ctx, "RESET distsql") }()
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		// The errors of class 42 are the syntax errors and the errors of
+		// name resolution and type checking.
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Class() == "42" {
+			return nil, errors.Wrapf(err, "invalid query:\n%s", query)
+		}
+		return nil, nil
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	res := [][]string{}
+	vals := make([]gosql.NullString, len(cols))
+	ptrs := make([]interface{}, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make([]string, len(vals))
+		for i, v := range vals {
+			if v.Valid {
+				row[i] = v.String
+			} else {
+				row[i] = "NULL"
+			}
+		}
+		res = append(res, row)
+	}
+	if rows.Err() != nil {
+		return nil, nil
+	}
+	return res, nil
+}
@@ -0,0 +1,437 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package sqlsmith generates random queries against arbitrary schemas.
+//
+// Unlike the random syntax generator of the rsg package, which produces
+// random sentences of the SQL grammar, most of which are rejected by the
+// planner, the queries generated here are well typed and only reference the
+// tables and columns of the schema they are generated against. They only use
+// deterministic operators and their results are fully ordered, so that the
+// results of the executions of a query in different modes (e.g. local and
+// distributed) can be compared.
+package sqlsmith
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// Column is a column of a table against which queries are generated.
+type Column struct {
+	Name     string
+	Type     types.T
+	Nullable bool
+}
+
+// Table is a table against which queries are generated.
+type Table struct {
+	// Database is the database of the table. If empty, the table is
+	// referenced by its name only, i.e. in the current database.
+	Database string
+	Name     string
+	Columns  []Column
+}
+
+// AppendColumn appends a column to the last of the tables, or to a new table
+// appended to the tables if the column belongs to another table. It is used
+// to build the tables from a listing of the columns grouped by table, such
+// as information_schema.columns.
+func AppendColumn(tables []Table, database, table string, col Column) []Table {
+	if n := len(tables); n > 0 && tables[n-1].Database == database && tables[n-1].Name == table {
+		tables[n-1].Columns = append(tables[n-1].Columns, col)
+		return tables
+	}
+	return append(tables, Table{Database: database, Name: table, Columns: []Column{col}})
+}
+
+// scalarTypes are the types of the generated expressions, with their names
+// in the casts of the generated constants. The values of these types can be
+// ordered, so the rows of the results of the queries can be fully ordered.
+var scalarTypes = []struct {
+	typ  types.T
+	name string
+}{
+	{types.Bool, "BOOL"},
+	{types.Int, "INT"},
+	{types.Float, "FLOAT"},
+	{types.Decimal, "DECIMAL"},
+	{types.String, "STRING"},
+	{types.Bytes, "BYTES"},
+	{types.Date, "DATE"},
+	{types.Timestamp, "TIMESTAMP"},
+	{types.TimestampTZ, "TIMESTAMPTZ"},
+	{types.Interval, "INTERVAL"},
+}
+
+// scalarTypeName returns the name of a scalar type, or the empty string if
+// the type isn't one of the scalarTypes.
+func scalarTypeName(typ types.T) string {
+	for _, t := range scalarTypes {
+		if t.typ == typ {
+			return t.name
+		}
+	}
+	return ""
+}
+
+// binOp is a binary operator whose operands and result are of scalar types.
+type binOp struct {
+	op          tree.BinaryOperator
+	left, right types.T
+}
+
+// binOpsByType maps the scalar types to the binary operators returning them.
+// The operators are sorted so that the queries generated from a given seed
+// don't depend on the iteration order of tree.BinOps.
+var binOpsByType = func() map[types.T][]binOp {
+	m := make(map[types.T][]binOp)
+	for op, overloads := range tree.BinOps {
+		for _, o := range overloads {
+			b, ok := o.(tree.BinOp)
+			if !ok || scalarTypeName(b.LeftType) == "" || scalarTypeName(b.RightType) == "" ||
+				scalarTypeName(b.ReturnType) == "" {
+				continue
+			}
+			m[b.ReturnType] = append(m[b.ReturnType], binOp{op: op, left: b.LeftType, right: b.RightType})
+		}
+	}
+	for _, ops := range m {
+		sort.Slice(ops, func(i, j int) bool {
+			if ops[i].op != ops[j].op {
+				return ops[i].op < ops[j].op
+			}
+			if l, r := ops[i].left.String(), ops[j].left.String(); l != r {
+				return l < r
+			}
+			return ops[i].right.String() < ops[j].right.String()
+		})
+	}
+	return m
+}()
+
+// cmpOps are the comparison operators used in the generated predicates. They
+// apply to two operands of the same scalar type.
+var cmpOps = []tree.ComparisonOperator{
+	tree.EQ, tree.NE, tree.LT, tree.LE, tree.GT, tree.GE,
+	tree.IsDistinctFrom, tree.IsNotDistinctFrom,
+}
+
+const (
+	// maxExprDepth is the maximum depth of the generated expressions.
+	maxExprDepth = 3
+	// maxSelectExprs is the maximum number of rendered expressions of the
+	// generated queries.
+	maxSelectExprs = 4
+	// maxLimit bounds the LIMIT of the generated queries.
+	maxLimit = 100
+)
+
+// Smither generates random queries against a set of tables.
+type Smither struct {
+	rnd    *rand.Rand
+	tables []Table
+}
+
+// NewSmither creates a Smither which generates queries against the given
+// tables, using the given source of randomness. The columns whose types
+// aren't supported by the generator are ignored, and so are the tables
+// without any supported column. If there are no tables, the generated
+// queries don't read any table.
+func NewSmither(rnd *rand.Rand, tables []Table) *Smither {
+	s := &Smither{rnd: rnd}
+	for _, t := range tables {
+		var cols []Column
+		for _, c := range t.Columns {
+			if scalarTypeName(c.Type) != "" {
+				cols = append(cols, c)
+			}
+		}
+		if len(cols) > 0 {
+			t.Columns = cols
+			s.tables = append(s.tables, t)
+		}
+	}
+	return s
+}
+
+// scopeColumn is a column which can be referenced by the expressions of a
+// query.
+type scopeColumn struct {
+	// ref is the qualified reference to the column, e.g. t0.a.
+	ref string
+	typ types.T
+}
+
+// Generate returns a random SELECT query. The query is ordered by all its
+// rendered columns.
+func (s *Smither) Generate() string {
+	var buf bytes.Buffer
+	var scope []scopeColumn
+	from := s.makeFrom(&scope)
+
+	var renders []string
+	var groupBy []string
+	distinct := false
+	if len(scope) > 0 && s.rnd.Intn(4) == 0 {
+		renders, groupBy = s.makeAggregation(scope)
+	} else {
+		for n := 1 + s.rnd.Intn(maxSelectExprs); len(renders) < n; {
+			renders = append(renders, s.makeExpr(s.randType(scope), scope, maxExprDepth))
+		}
+		distinct = s.rnd.Intn(5) == 0
+	}
+
+	buf.WriteString("SELECT ")
+	if distinct {
+		buf.WriteString("DISTINCT ")
+	}
+	for i, r := range renders {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%s AS c%d", r, i)
+	}
+	if from != "" {
+		buf.WriteString(" FROM ")
+		buf.WriteString(from)
+	}
+	if s.rnd.Intn(2) == 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(s.makeExpr(types.Bool, scope, maxExprDepth))
+	}
+	for i, g := range groupBy {
+		if i == 0 {
+			buf.WriteString(" GROUP BY ")
+		} else {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(g)
+	}
+	buf.WriteString(" ORDER BY ")
+	for i := range renders {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%d", i+1)
+	}
+	if s.rnd.Intn(3) == 0 {
+		fmt.Fprintf(&buf, " LIMIT %d", s.rnd.Intn(maxLimit))
+	}
+	return buf.String()
+}
+
+// makeFrom returns the FROM clause of a query, without the FROM keyword, and
+// adds the columns of its tables to the scope. It returns the empty string if
+// there are no tables.
+func (s *Smither) makeFrom(scope *[]scopeColumn) string {
+	if len(s.tables) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	s.addTable(&buf, scope, "t0")
+	if s.rnd.Intn(3) == 0 {
+		joins := []string{"JOIN", "LEFT JOIN", "CROSS JOIN"}
+		join := joins[s.rnd.Intn(len(joins))]
+		fmt.Fprintf(&buf, " %s ", join)
+		s.addTable(&buf, scope, "t1")
+		if join != "CROSS JOIN" {
+			buf.WriteString(" ON ")
+			buf.WriteString(s.makeExpr(types.Bool, *scope, maxExprDepth))
+		}
+	}
+	return buf.String()
+}
+
+// addTable writes a random table, with the given alias, and adds its columns
+// to the scope.
+func (s *Smither) addTable(buf *bytes.Buffer, scope *[]scopeColumn, alias string) {
+	t := s.tables[s.rnd.Intn(len(s.tables))]
+	if t.Database != "" {
+		lex.EncodeRestrictedSQLIdent(buf, t.Database, lex.EncodeFlags{})
+		buf.WriteByte('.')
+	}
+	lex.EncodeRestrictedSQLIdent(buf, t.Name, lex.EncodeFlags{})
+	buf.WriteString(" AS ")
+	buf.WriteString(alias)
+	for _, c := range t.Columns {
+		var ref bytes.Buffer
+		ref.WriteString(alias)
+		ref.WriteByte('.')
+		lex.EncodeRestrictedSQLIdent(&ref, c.Name, lex.EncodeFlags{})
+		*scope = append(*scope, scopeColumn{ref: ref.String(), typ: c.Type})
+	}
+}
+
+// makeAggregation returns the rendered expressions and the grouping columns
+// of an aggregation over the columns of the scope.
+func (s *Smither) makeAggregation(scope []scopeColumn) (renders, groupBy []string) {
+	numGroupBy := s.rnd.Intn(3)
+	if numGroupBy > len(scope) {
+		numGroupBy = len(scope)
+	}
+	for _, i := range s.rnd.Perm(len(scope))[:numGroupBy] {
+		groupBy = append(groupBy, scope[i].ref)
+	}
+	renders = append(renders, groupBy...)
+	for n := len(renders) + 1 + s.rnd.Intn(2); len(renders) < n; {
+		if s.rnd.Intn(4) == 0 {
+			renders = append(renders, "count(*)")
+			continue
+		}
+		c := scope[s.rnd.Intn(len(scope))]
+		fns := []string{"count"}
+		if c.typ != types.Bool {
+			fns = append(fns, "min", "max")
+		}
+		if c.typ == types.Int || c.typ == types.Decimal {
+			fns = append(fns, "sum")
+		}
+		renders = append(renders, fmt.Sprintf("%s(%s)", fns[s.rnd.Intn(len(fns))], c.ref))
+	}
+	return renders, groupBy
+}
+
+// randType returns a random scalar type, preferably the type of one of the
+// columns of the scope.
+func (s *Smither) randType(scope []scopeColumn) types.T {
+	if len(scope) > 0 && s.rnd.Intn(4) != 0 {
+		return scope[s.rnd.Intn(len(scope))].typ
+	}
+	return scalarTypes[s.rnd.Intn(len(scalarTypes))].typ
+}
+
+// makeExpr returns a random expression of the given type over the columns of
+// the scope, with at most depth levels of operators.
+func (s *Smither) makeExpr(typ types.T, scope []scopeColumn, depth int) string {
+	if depth <= 0 || s.rnd.Intn(3) == 0 {
+		return s.makeLeaf(typ, scope)
+	}
+	depth--
+	if typ == types.Bool {
+		switch s.rnd.Intn(5) {
+		case 0:
+			op := "AND"
+			if s.rnd.Intn(2) == 0 {
+				op = "OR"
+			}
+			return fmt.Sprintf("(%s %s %s)",
+				s.makeExpr(typ, scope, depth), op, s.makeExpr(typ, scope, depth))
+		case 1:
+			return fmt.Sprintf("(NOT %s)", s.makeExpr(typ, scope, depth))
+		case 2:
+			isNull := "IS NULL"
+			if s.rnd.Intn(2) == 0 {
+				isNull = "IS NOT NULL"
+			}
+			return fmt.Sprintf("(%s %s)", s.makeExpr(s.randType(scope), scope, depth), isNull)
+		default:
+			operandType := s.randType(scope)
+			return fmt.Sprintf("(%s %s %s)",
+				s.makeExpr(operandType, scope, depth),
+				cmpOps[s.rnd.Intn(len(cmpOps))],
+				s.makeExpr(operandType, scope, depth))
+		}
+	}
+	if ops := binOpsByType[typ]; len(ops) > 0 && s.rnd.Intn(3) != 0 {
+		op := ops[s.rnd.Intn(len(ops))]
+		return fmt.Sprintf("(%s %s %s)",
+			s.makeExpr(op.left, scope, depth), op.op, s.makeExpr(op.right, scope, depth))
+	}
+	return fmt.Sprintf("(CASE WHEN %s THEN %s ELSE %s END)",
+		s.makeExpr(types.Bool, scope, depth),
+		s.makeExpr(typ, scope, depth),
+		s.makeExpr(typ, scope, depth))
+}
+
+// makeLeaf returns a random column of the scope of the given type or, if
+// there is none, a random constant of the type.
+func (s *Smither) makeLeaf(typ types.T, scope []scopeColumn) string {
+	if s.rnd.Intn(3) != 0 {
+		var refs []string
+		for _, c := range scope {
+			if c.typ == typ {
+				refs = append(refs, c.ref)
+			}
+		}
+		if len(refs) > 0 {
+			return refs[s.rnd.Intn(len(refs))]
+		}
+	}
+	// The constants are cast so that the type of the expressions they are
+	// part of doesn't depend on the type inference of the constants.
+	d := tree.DNull
+	if s.rnd.Intn(10) != 0 {
+		d = s.randDatum(typ)
+	}
+	return fmt.Sprintf("CAST(%s AS %s)", tree.AsStringWithFlags(d, tree.FmtParsable), scalarTypeName(typ))
+}
+
+// randDatum returns a random non-NULL datum of the given scalar type. The
+// values are chosen from small ranges, so that the predicates comparing
+// columns to constants select some rows.
+func (s *Smither) randDatum(typ types.T) tree.Datum {
+	switch typ {
+	case types.Bool:
+		return tree.MakeDBool(s.rnd.Intn(2) == 0)
+	case types.Int:
+		if s.rnd.Intn(10) == 0 {
+			return tree.NewDInt(tree.DInt(s.rnd.Int63()))
+		}
+		return tree.NewDInt(tree.DInt(s.rnd.Intn(201) - 100))
+	case types.Float:
+		return tree.NewDFloat(tree.DFloat(s.rnd.NormFloat64() * 100))
+	case types.Decimal:
+		d, err := tree.ParseDDecimal(fmt.Sprintf("%d.%02d", s.rnd.Intn(201)-100, s.rnd.Intn(100)))
+		if err != nil {
+			panic(err)
+		}
+		return d
+	case types.String:
+		return tree.NewDString(s.randString())
+	case types.Bytes:
+		return tree.NewDBytes(tree.DBytes(s.randString()))
+	case types.Date:
+		return tree.NewDDate(tree.DDate(s.rnd.Intn(20000)))
+	case types.Timestamp:
+		return tree.MakeDTimestamp(timeutil.Unix(s.rnd.Int63n(2000000000), 0), time.Microsecond)
+	case types.TimestampTZ:
+		return tree.MakeDTimestampTZ(timeutil.Unix(s.rnd.Int63n(2000000000), 0), time.Microsecond)
+	case types.Interval:
+		return &tree.DInterval{Duration: duration.Duration{
+			Days:  s.rnd.Int63n(100),
+			Nanos: s.rnd.Int63n(int64(24 * time.Hour)),
+		}}
+	default:
+		panic(fmt.Sprintf("unsupported type %s", typ))
+	}
+}
+
+// randString returns a short random string of lowercase letters.
+func (s *Smither) randString() string {
+	b := make([]byte, s.rnd.Intn(4))
+	for i := range b {
+		b[i] = byte('a' + s.rnd.Intn(4))
+	}
+	return string(b)
+}
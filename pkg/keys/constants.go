@@ -240,6 +240,13 @@ var (
 	// StatusNodePrefix stores all status info for nodes.
 	StatusNodePrefix = roachpb.Key(makeKey(StatusPrefix, roachpb.RKey("node-")))
 
+	// ProtectedTimestampPrefix is the key prefix for the protected timestamp
+	// records.
+	ProtectedTimestampPrefix = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("protectedts-")))
+	// ProtectedTimestampKeyMax is the maximum value for any protected
+	// timestamp record key.
+	ProtectedTimestampKeyMax = ProtectedTimestampPrefix.PrefixEnd()
+
 	// TimeseriesPrefix is the key prefix for all timeseries data.
 	TimeseriesPrefix = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("tsd")))
 
@@ -305,14 +312,19 @@ const (
 	// to "Ranges" instead of a Table - these IDs are needed to store custom
 	// configuration for non-table ranges (e.g. Zone Configs).
 	// NOTE: IDs must be <= MaxReservedDescID.
-	LeaseTableID           = 11
-	EventLogTableID        = 12
-	RangeEventTableID      = 13
-	UITableID              = 14
-	JobsTableID            = 15
-	MetaRangesID           = 16
-	SystemRangesID         = 17
-	TimeseriesRangesID     = 18
-	WebSessionsTableID     = 19
-	TableStatisticsTableID = 20
+	LeaseTableID                        = 11
+	EventLogTableID                     = 12
+	RangeEventTableID                   = 13
+	UITableID                           = 14
+	JobsTableID                         = 15
+	MetaRangesID                        = 16
+	SystemRangesID                      = 17
+	TimeseriesRangesID                  = 18
+	WebSessionsTableID                  = 19
+	TableStatisticsTableID              = 20
+	RoleOptionsTableID                  = 21
+	RoleMembersTableID                  = 22
+	ScheduledJobsTableID                = 23
+	StatementBundlesTableID             = 24
+	StatementDiagnosticsRequestsTableID = 25
 )
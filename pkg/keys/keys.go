@@ -76,6 +76,15 @@ func NodeStatusKey(nodeID roachpb.NodeID) roachpb.Key {
 	return key
 }
 
+// ProtectedTimestampKey returns the key of the protected timestamp record
+// with the given ID.
+func ProtectedTimestampKey(id uuid.UUID) roachpb.Key {
+	key := make(roachpb.Key, 0, len(ProtectedTimestampPrefix)+len(id))
+	key = append(key, ProtectedTimestampPrefix...)
+	key = append(key, id.GetBytes()...)
+	return key
+}
+
 func makePrefixWithRangeID(prefix []byte, rangeID roachpb.RangeID, infix roachpb.RKey) roachpb.Key {
 	// Size the key buffer so that it is large enough for most callers.
 	key := make(roachpb.Key, 0, 32)
@@ -115,6 +115,10 @@ var (
 				ppFunc: decodeKeyPrint,
 				psFunc: parseUnsupported,
 			},
+			{name: "/ProtectedTimestamp", prefix: ProtectedTimestampPrefix,
+				ppFunc: protectedTimestampKeyPrint,
+				psFunc: parseUnsupported,
+			},
 			{name: "/tsd", prefix: TimeseriesPrefix,
 				ppFunc: decodeTimeseriesKey,
 				psFunc: parseUnsupported,
@@ -440,6 +444,14 @@ func abortSpanKeyPrint(key roachpb.Key) string {
 	return fmt.Sprintf("/%q", txnID)
 }
 
+func protectedTimestampKeyPrint(key roachpb.Key) string {
+	id, err := uuid.FromBytes(key)
+	if err != nil {
+		return fmt.Sprintf("/%q/err:%v", key, err)
+	}
+	return fmt.Sprintf("/%s", id)
+}
+
 func print(key roachpb.Key) string {
 	return fmt.Sprintf("/%q", []byte(key))
 }
@@ -79,6 +79,7 @@ func TestPrettyPrint(t *testing.T) {
 
 		{NodeLivenessKey(10033), "/System/NodeLiveness/10033"},
 		{NodeStatusKey(1111), "/System/StatusNode/1111"},
+		{ProtectedTimestampKey(txnID), fmt.Sprintf("/System/ProtectedTimestamp/%s", txnID)},
 
 		{SystemMax, "/System/Max"},
 
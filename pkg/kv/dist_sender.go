@@ -17,6 +17,7 @@ package kv
 import (
 	"fmt"
 	"sync/atomic"
+	"time"
 	"unsafe"
 
 	"golang.org/x/net/context"
@@ -29,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -422,6 +424,26 @@ func (ds *DistSender) getDescriptor(
 	return desc, returnToken, nil
 }
 
+// CanUseFollowerRead returns whether a consistent read at the given timestamp
+// is old enough to be served by any replica, rather than by the lease holder.
+// The timestamp must include the read's uncertainty interval.
+func (ds *DistSender) CanUseFollowerRead(ts hlc.Timestamp) bool {
+	return storagebase.CanUseFollowerRead(&ds.st.SV, ds.clock.Now(), ts)
+}
+
+// canUseFollowerRead returns whether the batch is a consistent read which can
+// be served by any replica.
+func (ds *DistSender) canUseFollowerRead(ba roachpb.BatchRequest) bool {
+	if !ba.IsReadOnly() || ba.ReadConsistency != roachpb.CONSISTENT {
+		return false
+	}
+	ts := ba.Timestamp
+	if ba.Txn != nil {
+		ts.Forward(ba.Txn.MaxTimestamp)
+	}
+	return ds.CanUseFollowerRead(ts)
+}
+
 // sendSingleRange gathers and rearranges the replicas, and makes an RPC call.
 func (ds *DistSender) sendSingleRange(
 	ctx context.Context, ba roachpb.BatchRequest, desc *roachpb.RangeDescriptor,
@@ -435,8 +457,10 @@ func (ds *DistSender) sendSingleRange(
 	replicas.OptimizeReplicaOrder(ds.getNodeDescriptor())
 
 	// If this request needs to go to a lease holder and we know who that is, move
-	// it to the front.
-	if !(ba.IsReadOnly() && ba.ReadConsistency == roachpb.INCONSISTENT) {
+	// it to the front. The reads which any replica can serve go to the closest
+	// one instead.
+	if !(ba.IsReadOnly() && ba.ReadConsistency == roachpb.INCONSISTENT) &&
+		!ds.canUseFollowerRead(ba) {
 		if storeID, ok := ds.leaseHolderCache.Lookup(ctx, desc.RangeID); ok {
 			if i := replicas.FindReplica(storeID); i >= 0 {
 				replicas.MoveToFront(i)
@@ -1226,6 +1250,7 @@ func (ds *DistSender) sendToReplicas(
 	// Must be buffered because tests have blocking SendNext implementations.
 	done := make(chan BatchCall, 1)
 	log.VEventf(ctx, 2, "r%d: sending batch %s to %s", rangeID, args.Summary(), transport.NextReplica())
+	sendStart := timeutil.Now()
 	transport.SendNext(ctx, done)
 
 	// Wait for completions. This loop will retry operations that fail
@@ -1267,6 +1292,9 @@ func (ds *DistSender) sendToReplicas(
 				propagateError := false
 				switch tErr := call.Reply.Error.GetDetail().(type) {
 				case nil:
+					if log.ExpensiveLogEnabled(ctx, 2) {
+						logBatchResponse(ctx, rangeID, args, call.Reply, timeutil.Since(sendStart))
+					}
 					return call.Reply, nil
 				case *roachpb.StoreNotFoundError, *roachpb.NodeUnavailableError:
 					// These errors are likely to be unique to the replica that reported
@@ -1323,7 +1351,29 @@ func (ds *DistSender) sendToReplicas(
 
 			ds.metrics.NextReplicaErrCount.Inc(1)
 			log.VEventf(ctx, 2, "error: %v; trying next peer %s", call, transport.NextReplica())
+			sendStart = timeutil.Now()
 			transport.SendNext(ctx, done)
 		}
 	}
 }
+
+// logBatchResponse logs the successful response to a batch sent to a range,
+// along with the span of the batch, the sizes of the request and of the
+// response, and the time spent waiting for the response. The message is
+// part of the KV trace.
+func logBatchResponse(
+	ctx context.Context,
+	rangeID roachpb.RangeID,
+	ba roachpb.BatchRequest,
+	br *roachpb.BatchResponse,
+	latency time.Duration,
+) {
+	span, err := keys.Range(ba)
+	if err != nil {
+		log.VEventf(ctx, 2, "r%d: received response to batch %s in %s (%d bytes sent, %d bytes received)",
+			rangeID, ba.Summary(), latency, ba.Size(), br.Size())
+		return
+	}
+	log.VEventf(ctx, 2, "r%d: received response to batch %s for %s in %s (%d bytes sent, %d bytes received)",
+		rangeID, ba.Summary(), span, latency, ba.Size(), br.Size())
+}
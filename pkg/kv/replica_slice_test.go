@@ -16,6 +16,7 @@ package kv
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -113,6 +114,33 @@ func TestReplicaSetMoveToFront(t *testing.T) {
 	}
 }
 
+func TestReplicaSetSortByLocality(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	locality := func(tiers ...string) roachpb.Locality {
+		var l roachpb.Locality
+		if err := l.Set(strings.Join(tiers, ",")); err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+	localities := []roachpb.Locality{
+		locality("region=west", "zone=a"),
+		locality("region=east", "zone=b"),
+		locality("region=east", "zone=a"),
+		locality("region=west", "zone=b"),
+		locality("region=east", "zone=b"),
+	}
+	rs := createReplicaSlice()
+	for i := range rs {
+		rs[i].NodeDesc = &roachpb.NodeDescriptor{NodeID: rs[i].NodeID, Locality: localities[i]}
+	}
+	rs.SortByLocality(locality("region=east", "zone=b"))
+	exp := []roachpb.StoreID{2, 5, 3, 1, 4}
+	if stores := getStores(rs); !reflect.DeepEqual(stores, exp) {
+		t.Errorf("expected order %s, got %s", exp, stores)
+	}
+}
+
 // TestMoveLocalReplicaToFront verifies that OptimizeReplicaOrder correctly
 // move the local replica to the front.
 func TestMoveLocalReplicaToFront(t *testing.T) {
@@ -27,6 +27,11 @@ const (
 	NodeUser = "node"
 	// RootUser is the default cluster administrator.
 	RootUser = "root"
+	// PublicRole is the pseudo-role whose privileges every user has.
+	PublicRole = "public"
+	// AdminRole is the built-in role whose members, like root, can inspect
+	// the internals of the nodes and the activity of every user.
+	AdminRole = "admin"
 )
 
 // UserAuthHook authenticates a user based on their username and whether their
@@ -145,3 +150,28 @@ func UserAuthPasswordHook(insecureMode bool, password string, hashedPassword []b
 		return nil
 	}
 }
+
+// UserAuthSCRAMHook builds an authentication hook for users whose password is
+// stored as a SCRAM verifier. The exchange function runs the SCRAM exchange
+// with the client and returns an error if the client fails to authenticate.
+func UserAuthSCRAMHook(insecureMode bool, exchange func() error) UserAuthHook {
+	return func(requestedUser string, clientConnection bool) error {
+		if len(requestedUser) == 0 {
+			return errors.New("user is missing")
+		}
+
+		if !clientConnection {
+			return errors.New("password authentication is only available for client connections")
+		}
+
+		if insecureMode {
+			return nil
+		}
+
+		if requestedUser == RootUser {
+			return errors.Errorf("user %s must use certificate authentication instead of password authentication", RootUser)
+		}
+
+		return exchange()
+	}
+}
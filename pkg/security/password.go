@@ -28,24 +28,42 @@ import (
 
 // BCrypt cost should increase along with computation power.
 // For estimates, see: http://security.stackexchange.com/questions/17207/recommended-of-rounds-for-bcrypt
-// For now, we use the library's default cost.
-const bcryptCost = bcrypt.DefaultCost
+// By default, we use the library's default cost.
+const (
+	DefaultBcryptCost = bcrypt.DefaultCost
+	MinBcryptCost     = bcrypt.MinCost
+	MaxBcryptCost     = bcrypt.MaxCost
+)
 
 // ErrEmptyPassword indicates that an empty password was attempted to be set.
 var ErrEmptyPassword = errors.New("empty passwords are not permitted")
 
 // CompareHashAndPassword tests that the provided bytes are equivalent to the
 // hash of the supplied password. If they are not equivalent, returns an
-// error.
+// error. The hash is either a bcrypt hash or a SCRAM verifier.
 func CompareHashAndPassword(hashedPassword []byte, password string) error {
+	if IsSCRAMVerifier(hashedPassword) {
+		return compareSCRAMVerifierAndPassword(hashedPassword, password)
+	}
 	h := sha256.New()
 	return bcrypt.CompareHashAndPassword(hashedPassword, h.Sum([]byte(password)))
 }
 
 // HashPassword takes a raw password and returns a bcrypt hashed password.
 func HashPassword(password string) ([]byte, error) {
+	return HashPasswordWithCost(password, DefaultBcryptCost)
+}
+
+// HashPasswordWithCost takes a raw password and returns a bcrypt hashed
+// password of the given cost.
+func HashPasswordWithCost(password string, cost int) ([]byte, error) {
 	h := sha256.New()
-	return bcrypt.GenerateFromPassword(h.Sum([]byte(password)), bcryptCost)
+	return bcrypt.GenerateFromPassword(h.Sum([]byte(password)), cost)
+}
+
+// BcryptCost returns the cost of a bcrypt hashed password.
+func BcryptCost(hashedPassword []byte) (int, error) {
+	return bcrypt.Cost(hashedPassword)
 }
 
 // PromptForPassword prompts for a password.
@@ -0,0 +1,273 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// This file implements SCRAM-SHA-256 (RFC 5802 and RFC 7677), the password
+// authentication mechanism of the SASL exchange in the pgwire handshake.
+//
+// Passwords are stored as SCRAM verifiers, in the same format as Postgres:
+//
+//   SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey>
+//
+// where the binary values are base64-encoded. A verifier still allows the
+// password to be checked when the client sends it in cleartext. Passwords
+// aren't normalized with SASLprep, which makes no difference for ASCII
+// passwords.
+
+// SCRAMMechanism is the name of the SASL mechanism implemented here.
+const SCRAMMechanism = "SCRAM-SHA-256"
+
+const (
+	scramVerifierPrefix = SCRAMMechanism + "$"
+	// scramIterations is the iteration count of new verifiers, the same as the
+	// default of Postgres.
+	scramIterations = 4096
+	scramSaltLen    = 16
+	scramNonceLen   = 18
+)
+
+var errInvalidSCRAMMessage = errors.New("malformed SCRAM message")
+
+type scramVerifier struct {
+	iterations int
+	salt       []byte
+	storedKey  []byte
+	serverKey  []byte
+}
+
+// IsSCRAMVerifier returns whether the hashed password is a SCRAM verifier, as
+// opposed to a bcrypt hash.
+func IsSCRAMVerifier(hashedPassword []byte) bool {
+	return bytes.HasPrefix(hashedPassword, []byte(scramVerifierPrefix))
+}
+
+// HashPasswordSCRAM takes a raw password and returns its SCRAM verifier.
+func HashPasswordSCRAM(password string) ([]byte, error) {
+	salt := make([]byte, scramSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	v := makeSCRAMVerifier(password, salt, scramIterations)
+	enc := base64.StdEncoding
+	return []byte(fmt.Sprintf("%s%d:%s$%s:%s", scramVerifierPrefix, v.iterations,
+		enc.EncodeToString(v.salt), enc.EncodeToString(v.storedKey),
+		enc.EncodeToString(v.serverKey))), nil
+}
+
+func makeSCRAMVerifier(password string, salt []byte, iterations int) scramVerifier {
+	saltedPassword := scramHi([]byte(password), salt, iterations)
+	clientKey := scramHMAC(saltedPassword, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+	return scramVerifier{
+		iterations: iterations,
+		salt:       salt,
+		storedKey:  storedKey[:],
+		serverKey:  scramHMAC(saltedPassword, []byte("Server Key")),
+	}
+}
+
+func parseSCRAMVerifier(hashedPassword []byte) (scramVerifier, error) {
+	var v scramVerifier
+	errInvalid := errors.New("invalid SCRAM verifier")
+	if !IsSCRAMVerifier(hashedPassword) {
+		return v, errInvalid
+	}
+	parts := strings.Split(string(hashedPassword[len(scramVerifierPrefix):]), "$")
+	if len(parts) != 2 {
+		return v, errInvalid
+	}
+	params := strings.Split(parts[0], ":")
+	keys := strings.Split(parts[1], ":")
+	if len(params) != 2 || len(keys) != 2 {
+		return v, errInvalid
+	}
+	var err error
+	if v.iterations, err = strconv.Atoi(params[0]); err != nil || v.iterations <= 0 {
+		return v, errInvalid
+	}
+	enc := base64.StdEncoding
+	if v.salt, err = enc.DecodeString(params[1]); err != nil {
+		return v, errInvalid
+	}
+	if v.storedKey, err = enc.DecodeString(keys[0]); err != nil {
+		return v, errInvalid
+	}
+	if v.serverKey, err = enc.DecodeString(keys[1]); err != nil {
+		return v, errInvalid
+	}
+	return v, nil
+}
+
+// compareSCRAMVerifierAndPassword tests that the SCRAM verifier was computed
+// from the supplied password.
+func compareSCRAMVerifierAndPassword(hashedPassword []byte, password string) error {
+	v, err := parseSCRAMVerifier(hashedPassword)
+	if err != nil {
+		return err
+	}
+	expected := makeSCRAMVerifier(password, v.salt, v.iterations)
+	if subtle.ConstantTimeCompare(expected.storedKey, v.storedKey) != 1 ||
+		subtle.ConstantTimeCompare(expected.serverKey, v.serverKey) != 1 {
+		return errors.New("password mismatch")
+	}
+	return nil
+}
+
+// scramHi is the Hi function of RFC 5802, PBKDF2 with HMAC-SHA-256 producing
+// a single block.
+func scramHi(password, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, password)
+	_, _ = mac.Write(salt)
+	_, _ = mac.Write([]byte{0, 0, 0, 1})
+	u := mac.Sum(nil)
+	result := append([]byte(nil), u...)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		_, _ = mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range result {
+			result[j] ^= u[j]
+		}
+	}
+	return result
+}
+
+func scramHMAC(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// SCRAMConversation is the server side of a SCRAM exchange. The client's
+// messages are passed to ClientFirst and then to ClientFinal, which return
+// the server's responses.
+type SCRAMConversation struct {
+	verifier scramVerifier
+
+	gs2Header       string
+	clientFirstBare string
+	serverFirst     string
+	nonce           string
+}
+
+// NewSCRAMConversation starts a SCRAM exchange authenticating the client
+// against the given verifier.
+func NewSCRAMConversation(hashedPassword []byte) (*SCRAMConversation, error) {
+	v, err := parseSCRAMVerifier(hashedPassword)
+	if err != nil {
+		return nil, err
+	}
+	return &SCRAMConversation{verifier: v}, nil
+}
+
+// ClientFirst processes the client-first-message and returns the
+// server-first-message.
+func (s *SCRAMConversation) ClientFirst(msg string) (string, error) {
+	// The message starts with the GS2 header: a channel binding flag and an
+	// authorization identity, followed by the client-first-message-bare.
+	parts := strings.SplitN(msg, ",", 3)
+	if len(parts) != 3 {
+		return "", errInvalidSCRAMMessage
+	}
+	switch {
+	case parts[0] == "n" || parts[0] == "y":
+	case strings.HasPrefix(parts[0], "p="):
+		return "", errors.New("SCRAM channel binding is not supported")
+	default:
+		return "", errInvalidSCRAMMessage
+	}
+	if parts[1] != "" {
+		return "", errors.New("SCRAM authorization identities are not supported")
+	}
+	s.gs2Header = parts[0] + "," + parts[1] + ","
+	s.clientFirstBare = parts[2]
+
+	// The user name is ignored: as in Postgres, the one given in the startup
+	// message is authenticated.
+	attrs := strings.Split(s.clientFirstBare, ",")
+	if strings.HasPrefix(attrs[0], "m=") {
+		return "", errors.New("SCRAM extensions are not supported")
+	}
+	if len(attrs) < 2 || !strings.HasPrefix(attrs[0], "n=") {
+		return "", errInvalidSCRAMMessage
+	}
+	if !strings.HasPrefix(attrs[1], "r=") || len(attrs[1]) == 2 {
+		return "", errInvalidSCRAMMessage
+	}
+	serverNonce := make([]byte, scramNonceLen)
+	if _, err := rand.Read(serverNonce); err != nil {
+		return "", err
+	}
+	s.nonce = attrs[1][2:] + base64.StdEncoding.EncodeToString(serverNonce)
+	s.serverFirst = fmt.Sprintf("r=%s,s=%s,i=%d",
+		s.nonce, base64.StdEncoding.EncodeToString(s.verifier.salt), s.verifier.iterations)
+	return s.serverFirst, nil
+}
+
+// ClientFinal processes the client-final-message and returns the
+// server-final-message. It returns an error if the client's proof doesn't
+// match the verifier.
+func (s *SCRAMConversation) ClientFinal(msg string) (string, error) {
+	if s.serverFirst == "" {
+		return "", errInvalidSCRAMMessage
+	}
+	proofPos := strings.LastIndex(msg, ",p=")
+	if proofPos < 0 {
+		return "", errInvalidSCRAMMessage
+	}
+	withoutProof := msg[:proofPos]
+	attrs := strings.Split(withoutProof, ",")
+	if len(attrs) < 2 || !strings.HasPrefix(attrs[0], "c=") || !strings.HasPrefix(attrs[1], "r=") {
+		return "", errInvalidSCRAMMessage
+	}
+	channelBinding, err := base64.StdEncoding.DecodeString(attrs[0][2:])
+	if err != nil || string(channelBinding) != s.gs2Header {
+		return "", errors.New("SCRAM channel binding check failed")
+	}
+	if attrs[1][2:] != s.nonce {
+		return "", errors.New("SCRAM nonce mismatch")
+	}
+	proof, err := base64.StdEncoding.DecodeString(msg[proofPos+3:])
+	if err != nil || len(proof) != sha256.Size {
+		return "", errInvalidSCRAMMessage
+	}
+
+	authMessage := []byte(s.clientFirstBare + "," + s.serverFirst + "," + withoutProof)
+	clientSignature := scramHMAC(s.verifier.storedKey, authMessage)
+	clientKey := make([]byte, len(proof))
+	for i := range proof {
+		clientKey[i] = proof[i] ^ clientSignature[i]
+	}
+	storedKey := sha256.Sum256(clientKey)
+	if subtle.ConstantTimeCompare(storedKey[:], s.verifier.storedKey) != 1 {
+		return "", errors.New("invalid password")
+	}
+	serverSignature := scramHMAC(s.verifier.serverKey, authMessage)
+	return "v=" + base64.StdEncoding.EncodeToString(serverSignature), nil
+}
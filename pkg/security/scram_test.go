@@ -0,0 +1,174 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// scramClientFinal computes the client-final-message of a SCRAM exchange for
+// the given password, and the server signature the client expects in return.
+func scramClientFinal(
+	t *testing.T, password, clientFirstBare, serverFirst string,
+) (string, string) {
+	var nonce, salt string
+	var iterations int
+	for _, attr := range strings.Split(serverFirst, ",") {
+		switch {
+		case strings.HasPrefix(attr, "r="):
+			nonce = attr[2:]
+		case strings.HasPrefix(attr, "s="):
+			salt = attr[2:]
+		case strings.HasPrefix(attr, "i="):
+			var err error
+			if iterations, err = strconv.Atoi(attr[2:]); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	saltBytes, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SaltedPassword := Hi(password, salt, iterations).
+	u := hmacSHA256([]byte(password), string(saltBytes)+"\x00\x00\x00\x01")
+	saltedPassword := append([]byte(nil), u...)
+	for i := 1; i < iterations; i++ {
+		u = hmacSHA256([]byte(password), string(u))
+		for j := range saltedPassword {
+			saltedPassword[j] ^= u[j]
+		}
+	}
+	clientKey := hmacSHA256(saltedPassword, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+	withoutProof := "c=" + base64.StdEncoding.EncodeToString([]byte("n,,")) + ",r=" + nonce
+	authMessage := clientFirstBare + "," + serverFirst + "," + withoutProof
+	clientSignature := hmacSHA256(storedKey[:], authMessage)
+	proof := make([]byte, len(clientKey))
+	for i := range clientKey {
+		proof[i] = clientKey[i] ^ clientSignature[i]
+	}
+	serverSignature := hmacSHA256(hmacSHA256(saltedPassword, "Server Key"), authMessage)
+	return withoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof),
+		"v=" + base64.StdEncoding.EncodeToString(serverSignature)
+}
+
+func TestSCRAMConversation(t *testing.T) {
+	verifier, err := security.HashPasswordSCRAM("pencil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !security.IsSCRAMVerifier(verifier) {
+		t.Fatalf("not a SCRAM verifier: %s", verifier)
+	}
+
+	for _, tc := range []struct {
+		password string
+		success  bool
+	}{
+		{"pencil", true},
+		{"pen", false},
+	} {
+		t.Run(tc.password, func(t *testing.T) {
+			s, err := security.NewSCRAMConversation(verifier)
+			if err != nil {
+				t.Fatal(err)
+			}
+			const clientFirstBare = "n=,r=rOprNGfwEbeRWgbNEkqO"
+			serverFirst, err := s.ClientFirst("n,," + clientFirstBare)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(serverFirst, "r=rOprNGfwEbeRWgbNEkqO") {
+				t.Fatalf("server nonce doesn't extend client nonce: %s", serverFirst)
+			}
+			clientFinal, expected := scramClientFinal(t, tc.password, clientFirstBare, serverFirst)
+			serverFinal, err := s.ClientFinal(clientFinal)
+			if !tc.success {
+				if !testutils.IsError(err, "invalid password") {
+					t.Fatalf("expected invalid password error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if serverFinal != expected {
+				t.Fatalf("expected %s, got %s", expected, serverFinal)
+			}
+		})
+	}
+
+	// The verifier is also used to check cleartext passwords.
+	if err := security.CompareHashAndPassword(verifier, "pencil"); err != nil {
+		t.Fatal(err)
+	}
+	if err := security.CompareHashAndPassword(verifier, "pen"); err == nil {
+		t.Fatal("expected mismatch")
+	}
+}
+
+func TestSCRAMConversationErrors(t *testing.T) {
+	verifier, err := security.HashPasswordSCRAM("pencil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		clientFirst string
+		expected    string
+	}{
+		{"n,,r=abc", "malformed SCRAM message"},
+		{"n,,n=,r=", "malformed SCRAM message"},
+		{"p=tls-server-end-point,,n=,r=abc", "channel binding is not supported"},
+		{"n,a=admin,n=,r=abc", "authorization identities are not supported"},
+		{"n,,m=ext,n=,r=abc", "extensions are not supported"},
+	} {
+		s, err := security.NewSCRAMConversation(verifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.ClientFirst(tc.clientFirst); !testutils.IsError(err, tc.expected) {
+			t.Errorf("%s: expected %q, got %v", tc.clientFirst, tc.expected, err)
+		}
+	}
+
+	// The client-final-message must carry the nonce of the exchange.
+	s, err := security.NewSCRAMConversation(verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverFirst, err := s.ClientFirst("n,,n=,r=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientFinal, _ := scramClientFinal(t, "pencil", "n=,r=abc", strings.Replace(serverFirst, "r=abc", "r=abd", 1))
+	if _, err := s.ClientFinal(clientFinal); !testutils.IsError(err, "nonce mismatch") {
+		t.Errorf("expected nonce mismatch, got %v", err)
+	}
+}
@@ -284,14 +284,14 @@ func TestAdminAPIDatabases(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if a, e := len(details.Grants), 3; a != e {
+	if a, e := len(details.Grants), 5; a != e {
 		t.Fatalf("# of grants %d != expected %d", a, e)
 	}
 
 	userGrants := make(map[string][]string)
 	for _, grant := range details.Grants {
 		switch grant.User {
-		case security.RootUser, testuser:
+		case security.RootUser, security.PublicRole, testuser:
 			userGrants[grant.User] = append(userGrants[grant.User], grant.Privileges...)
 		default:
 			t.Fatalf("unknown grant to user %s", grant.User)
@@ -303,6 +303,11 @@ func TestAdminAPIDatabases(t *testing.T) {
 			if !reflect.DeepEqual(p, []string{"ALL"}) {
 				t.Fatalf("privileges %v != expected %v", p, privileges)
 			}
+		case security.PublicRole:
+			sort.Strings(p)
+			if e := []string{"CONNECT", "USAGE"}; !reflect.DeepEqual(p, e) {
+				t.Fatalf("privileges %v != expected %v", p, e)
+			}
 		case testuser:
 			sort.Strings(p)
 			if !reflect.DeepEqual(p, privileges) {
@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	gwruntime "github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/pkg/errors"
@@ -246,7 +247,22 @@ func (s *authenticationServer) verifyPassword(
 	if !exists {
 		return false, nil
 	}
-	return (security.CompareHashAndPassword(hashedPassword, password) == nil), nil
+	if security.CompareHashAndPassword(hashedPassword, password) != nil {
+		return false, nil
+	}
+	validUntil, err := sql.GetUserPasswordValidUntil(ctx, s.server.sqlExecutor, s.memMetrics, username)
+	if err != nil {
+		return false, err
+	}
+	if sql.CheckPasswordValidUntil(username, validUntil) != nil {
+		return false, nil
+	}
+	if err := sql.MaybeRehashUserPassword(
+		ctx, s.server.sqlExecutor, s.memMetrics, username, password, hashedPassword,
+	); err != nil {
+		log.Warningf(ctx, "unable to rehash the password of user %s: %v", username, err)
+	}
+	return true, nil
 }
 
 // newAuthSession attempts to create a new authentication session for the given
@@ -53,6 +53,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/status"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/colenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
@@ -340,6 +341,9 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 
 	sqlExecutor := sql.InternalExecutor{LeaseManager: s.leaseMgr}
 
+	// The contention events recorded by the stores are exposed by SQL.
+	contentionEvents := storage.NewContentionRegistry()
+
 	// TODO(bdarnell): make StoreConfig configurable.
 	storeCfg := storage.StoreConfig{
 		Settings:                st,
@@ -359,6 +363,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		SQLExecutor:             sqlExecutor,
 		LogRangeEvents:          s.cfg.EventLogEnabled,
 		TimeSeriesDataStore:     s.tsDB,
+		ContentionEvents:        contentionEvents,
 
 		EnableEpochRangeLeases: true,
 	}
@@ -457,9 +462,11 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		StatusServer:            s.status,
 		SessionRegistry:         s.sessionRegistry,
 		JobRegistry:             s.jobRegistry,
+		ColumnEncryptionKeys:    colenc.NewKeyManager(s.st),
 		HistogramWindowInterval: s.cfg.HistogramWindowInterval(),
 		RangeDescriptorCache:    s.distSender.RangeDescriptorCache(),
 		LeaseHolderCache:        s.distSender.LeaseHolderCache(),
+		ContentionEvents:        contentionEvents,
 	}
 	if sqlExecutorTestingKnobs := s.cfg.TestingKnobs.SQLExecutor; sqlExecutorTestingKnobs != nil {
 		execCfg.TestingKnobs = sqlExecutorTestingKnobs.(*sql.ExecutorTestingKnobs)
@@ -477,7 +484,10 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	s.pgServer = pgwire.MakeServer(
 		s.cfg.AmbientCtx,
 		s.cfg.Config,
+		s.st,
 		s.sqlExecutor,
+		&s.nodeIDContainer,
+		s.gossip,
 		&s.internalMemMetrics,
 		&rootSQLMemoryMonitor,
 		s.cfg.HistogramWindowInterval(),
@@ -969,8 +979,10 @@ If problems persist, please see ` + base.DocsURL("cluster-setup-troubleshooting.
 	s.stopper.AddCloser(stop.CloserFn(gwCancel))
 
 	var authHandler http.Handler = gwMux
+	var bundleHandler http.Handler = http.HandlerFunc(s.handleStatementBundle)
 	if s.cfg.RequireWebSession() {
 		authHandler = newAuthenticationMux(s.authentication, authHandler)
+		bundleHandler = newAuthenticationMux(s.authentication, bundleHandler)
 	}
 
 	// Setup HTTP<->gRPC handlers.
@@ -1045,6 +1057,7 @@ If problems persist, please see ` + base.DocsURL("cluster-setup-troubleshooting.
 
 	// We can now add the node registry.
 	s.recorder.AddNode(s.registry, s.node.Descriptor, s.node.startedAt, s.cfg.AdvertiseAddr, s.cfg.HTTPAddr)
+	s.recorder.AddPrometheusIterable(s.sqlExecutor.FingerprintMetrics())
 
 	// Begin recording runtime statistics.
 	s.startSampleEnvironment(s.cfg.MetricsSampleInterval)
@@ -1107,6 +1120,7 @@ If problems persist, please see ` + base.DocsURL("cluster-setup-troubleshooting.
 	s.serveMode.set(modeOperational)
 
 	s.mux.Handle(adminPrefix, authHandler)
+	s.mux.Handle(sql.StatementBundlePath, bundleHandler)
 	s.mux.Handle(ts.URLPrefix, authHandler)
 	s.mux.Handle(statusPrefix, authHandler)
 	s.mux.Handle(authPrefix, gwMux)
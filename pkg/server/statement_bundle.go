@@ -0,0 +1,56 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// handleStatementBundle serves the zip archive of a bundle collected by
+// EXPLAIN ANALYZE (DEBUG), whose ID follows sql.StatementBundlePath in the
+// path of the request.
+func (s *Server) handleStatementBundle(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, sql.StatementBundlePath), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid statement bundle ID", http.StatusBadRequest)
+		return
+	}
+	ctx := s.AnnotateCtx(r.Context())
+	ie := sql.InternalExecutor{LeaseManager: s.leaseMgr}
+	row, err := ie.QueryRow(
+		ctx, "read-statement-bundle",
+		`SELECT bundle FROM system.statement_bundles WHERE id = $1`, id,
+	)
+	if err != nil {
+		http.Error(w, apiInternalError(ctx, err).Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(row) == 0 {
+		http.Error(w, fmt.Sprintf("statement bundle %d not found", id), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"stmt-bundle-%d.zip\"", id))
+	_, _ = w.Write([]byte(*row[0].(*tree.DBytes)))
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestStatementBundle(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `CREATE TABLE d.kv (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `INSERT INTO d.kv VALUES (1, 1), (2, 2)`)
+
+	var id int64
+	var url string
+	sqlDB.QueryRow(t, `EXPLAIN ANALYZE (DEBUG) SELECT v FROM d.kv WHERE k = 1`).Scan(&id, &url)
+	if !strings.HasSuffix(url, sql.StatementBundlePath+strconv.FormatInt(id, 10)) {
+		t.Fatalf("unexpected bundle URL %q for bundle %d", url, id)
+	}
+
+	body, err := getText(s, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("invalid bundle: %v\n%s", err, body)
+	}
+	contents := make(map[string]string)
+	var names []string
+	for _, f := range z.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[f.Name] = string(b)
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	expected := []string{
+		"env.txt", "plan.txt", "schema.sql", "settings.txt", "stats-kv.json", "statement.sql",
+		"trace.txt",
+	}
+	if strings.Join(names, " ") != strings.Join(expected, " ") {
+		t.Fatalf("expected the files %v, got %v", expected, names)
+	}
+	for name, substr := range map[string]string{
+		"statement.sql": "SELECT v FROM d.kv WHERE k = 1",
+		"schema.sql":    "CREATE TABLE kv",
+		"plan.txt":      "scan",
+		"env.txt":       "session variables",
+	} {
+		if !strings.Contains(contents[name], substr) {
+			t.Errorf("expected %s to contain %q, got:\n%s", name, substr, contents[name])
+		}
+	}
+
+	body, err = getText(s, s.AdminURL()+sql.StatementBundlePath+strconv.FormatInt(id+1, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), "not found") {
+		t.Errorf("expected a missing bundle to be reported, got %q", body)
+	}
+}
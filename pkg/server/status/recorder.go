@@ -112,6 +112,10 @@ type MetricsRecorder struct {
 		clock           *hlc.Clock
 		stores          map[roachpb.StoreID]storeMetrics
 
+		// prometheusIterables contains the metrics which are only exported to
+		// prometheus.
+		prometheusIterables []metric.PrometheusIterable
+
 		// Counts to help optimize slice allocation.
 		lastDataCount        int
 		lastSummaryCount     int
@@ -172,6 +176,14 @@ func (mr *MetricsRecorder) AddNode(
 	reg.AddMetric(nodeIDGauge)
 }
 
+// AddPrometheusIterable adds a collection of metrics which are exported to
+// prometheus, but not recorded as time series.
+func (mr *MetricsRecorder) AddPrometheusIterable(it metric.PrometheusIterable) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	mr.mu.prometheusIterables = append(mr.mu.prometheusIterables, it)
+}
+
 // AddStore adds the Registry from the provided store as a store-level registry
 // in this recorder. A reference to the store is kept for the purpose of
 // gathering some additional information which is present in store status
@@ -225,6 +237,9 @@ func (mr *MetricsRecorder) scrapePrometheusLocked() {
 	for _, reg := range mr.mu.storeRegistries {
 		mr.mu.prometheusExporter.ScrapeRegistry(reg)
 	}
+	for _, it := range mr.mu.prometheusIterables {
+		mr.mu.prometheusExporter.ScrapeIterable(it)
+	}
 }
 
 // PrintAsText writes the current metrics values as plain-text to the writer.
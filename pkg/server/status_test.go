@@ -517,6 +517,43 @@ func TestStatusVars(t *testing.T) {
 	}
 }
 
+// TestStatusVarsStatementFingerprints verifies that the metrics of the
+// statement fingerprints are available via the /_status/vars endpoint.
+func TestStatusVarsStatementFingerprints(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := db.Exec(`
+SET CLUSTER SETTING sql.metrics.statement_details.prometheus_top_n = 1000;
+SET application_name = 'prom';
+CREATE DATABASE t;
+CREATE TABLE t.kv (k INT PRIMARY KEY, v INT);
+`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := db.Exec(fmt.Sprintf(`SELECT v FROM t.kv WHERE k = %d`, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	body, err := getText(s, s.AdminURL()+statusPrefix+"vars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const labels = `{application_name="prom",fingerprint="SELECT v FROM t.kv WHERE k = _"}`
+	for _, expected := range []string{
+		"sql_statements_fingerprint_count" + labels + " 3",
+		"sql_statements_fingerprint_failure_count" + labels + " 0",
+		"sql_statements_fingerprint_service_latency_count" + labels + " 3",
+	} {
+		if !bytes.Contains(body, []byte(expected)) {
+			t.Errorf("expected %s, got: %s", expected, body)
+		}
+	}
+}
+
 func TestSpanStatsResponse(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	ts := startServer(t)
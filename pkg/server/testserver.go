@@ -116,6 +116,7 @@ func makeTestConfigFromParams(params base.TestServerArgs) Config {
 		cfg.JoinList = []string{params.JoinAddr}
 	}
 	cfg.Insecure = params.Insecure
+	cfg.Locality = params.Locality
 	cfg.SocketFile = params.SocketFile
 	cfg.RetryOptions = params.RetryOptions
 	if params.MetricsSampleInterval != 0 {
@@ -230,6 +230,7 @@ func (s *Server) maybeReportDiagnostics(
 	}
 	s.sqlExecutor.ResetStatementStats(ctx)
 	s.sqlExecutor.ResetUnimplementedCounts()
+	s.sqlExecutor.ResetFeatureUsageCounts()
 
 	return scheduled.Add(diagnosticReportFrequency.Get(&s.st.SV))
 }
@@ -261,6 +262,8 @@ func (s *Server) getReportingInfo(ctx context.Context) *diagnosticspb.Diagnostic
 	info.SqlStats = s.sqlExecutor.GetScrubbedStmtStats()
 	info.UnimplementedErrors = make(map[string]int64)
 	s.sqlExecutor.FillUnimplementedErrorCounts(info.UnimplementedErrors)
+	info.FeatureUsage = make(map[string]int64)
+	s.sqlExecutor.FillFeatureUsageCounts(info.FeatureUsage)
 	return &info
 }
 
@@ -267,6 +267,15 @@ func TestReportUsage(t *testing.T) {
 		}
 	}
 
+	for _, feat := range []string{"statement.INSERT", "builtin.length"} {
+		if minExpected, actual := int64(20), r.last.FeatureUsage[feat]; minExpected > actual {
+			t.Fatalf(
+				"expected at least %d uses of %q, got %d from %v",
+				minExpected, feat, actual, r.last.FeatureUsage,
+			)
+		}
+	}
+
 	if expected, actual := 9, len(r.last.SqlStats); expected != actual {
 		t.Fatalf("expected %d queries in stats report, got %d", expected, actual)
 	}
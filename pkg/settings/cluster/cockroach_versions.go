@@ -36,6 +36,7 @@ const (
 	VersionMVCCNetworkStats
 	VersionMeta2Splits
 	VersionRPCNetworkStats
+	VersionSCRAMPasswords
 
 	// Add new versions here (step one of two)
 
@@ -97,6 +98,12 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     VersionRPCNetworkStats,
 		Version: roachpb.Version{Major: 1, Minor: 1, Unstable: 4},
 	},
+	{
+		// VersionSCRAMPasswords stores new passwords as SCRAM-SHA-256 verifiers,
+		// which older nodes can't check, instead of bcrypt hashes.
+		Key:     VersionSCRAMPasswords,
+		Version: roachpb.Version{Major: 1, Minor: 1, Unstable: 5},
+	},
 
 	// Add new versions here (step two of two).
 
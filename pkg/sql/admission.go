@@ -0,0 +1,240 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"container/heap"
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// admissionMaxConcurrentStatements is the number of statements a node
+// executes at the same time before it starts queuing new ones. Queued
+// statements are admitted by priority, so that small transactional queries
+// don't wait behind large analytic ones when the node is saturated.
+var admissionMaxConcurrentStatements = settings.RegisterIntSetting(
+	"sql.admission.max_concurrent_statements",
+	"maximum number of SQL statements executing concurrently on a node before "+
+		"further statements are queued by priority (0 to disable)",
+	0,
+)
+
+// qualityOfService is the value of the quality_of_service session variable.
+// It is the most significant component of the priority statements are
+// admitted with.
+type qualityOfService int
+
+const (
+	qosRegular qualityOfService = iota
+	qosBackground
+	qosCritical
+)
+
+func (q qualityOfService) String() string {
+	switch q {
+	case qosRegular:
+		return "regular"
+	case qosBackground:
+		return "background"
+	case qosCritical:
+		return "critical"
+	default:
+		return fmt.Sprintf("invalid (%d)", q)
+	}
+}
+
+// level orders the qualities of service.
+func (q qualityOfService) level() int {
+	switch q {
+	case qosBackground:
+		return -1
+	case qosCritical:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// admissionPriority is the priority with which a statement waits for
+// admission. Statements are admitted by quality of service first, then by
+// transaction priority and finally in favor of statements that are not
+// distributed, which the DistSQL planner only recommends for queries that
+// process a lot of data (sorts, aggregations, large scans).
+type admissionPriority struct {
+	qos         int
+	txnPriority int
+	small       bool
+}
+
+func makeAdmissionPriority(session *Session, distributed bool) admissionPriority {
+	pri := admissionPriority{
+		qos:   session.QualityOfService.level(),
+		small: !distributed,
+	}
+	switch up := session.TxnState.priority; {
+	case up == roachpb.UnspecifiedUserPriority:
+	case up < roachpb.NormalUserPriority:
+		pri.txnPriority = -1
+	case up > roachpb.NormalUserPriority:
+		pri.txnPriority = 1
+	}
+	return pri
+}
+
+// less returns true if p is admitted after o.
+func (p admissionPriority) less(o admissionPriority) bool {
+	if p.qos != o.qos {
+		return p.qos < o.qos
+	}
+	if p.txnPriority != o.txnPriority {
+		return p.txnPriority < o.txnPriority
+	}
+	return !p.small && o.small
+}
+
+// admissionWaiter is a statement waiting in an admissionQueue.
+type admissionWaiter struct {
+	priority admissionPriority
+	// seq orders waiters of equal priority by arrival.
+	seq uint64
+	// admitted is closed when the waiter is handed a slot.
+	admitted chan struct{}
+	// index is the position of the waiter in the heap, or -1 once it has
+	// been popped.
+	index int
+}
+
+// admissionWaiters is a max-heap of waiters.
+type admissionWaiters []*admissionWaiter
+
+func (h admissionWaiters) Len() int { return len(h) }
+
+func (h admissionWaiters) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[j].priority.less(h[i].priority)
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h admissionWaiters) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *admissionWaiters) Push(x interface{}) {
+	w := x.(*admissionWaiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *admissionWaiters) Pop() interface{} {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*h = old[:n-1]
+	return w
+}
+
+// admissionQueue limits the number of statements executing concurrently on a
+// node. Once the limit is reached, statements wait for a slot and slots are
+// handed over to the waiter with the highest priority.
+type admissionQueue struct {
+	st *cluster.Settings
+
+	mu struct {
+		syncutil.Mutex
+		// running is the number of statements holding a slot.
+		running int
+		waiters admissionWaiters
+		seq     uint64
+	}
+}
+
+func makeAdmissionQueue(st *cluster.Settings) admissionQueue {
+	return admissionQueue{st: st}
+}
+
+// admit blocks until the statement can run or ctx is canceled. If it returns
+// no error, the caller must call the returned function once the statement is
+// done executing.
+func (q *admissionQueue) admit(ctx context.Context, pri admissionPriority) (func(), error) {
+	limit := int(admissionMaxConcurrentStatements.Get(&q.st.SV))
+	if limit <= 0 {
+		return func() {}, nil
+	}
+
+	q.mu.Lock()
+	if q.mu.running < limit && len(q.mu.waiters) == 0 {
+		q.mu.running++
+		q.mu.Unlock()
+		return q.release, nil
+	}
+	q.mu.seq++
+	w := &admissionWaiter{priority: pri, seq: q.mu.seq, admitted: make(chan struct{})}
+	heap.Push(&q.mu.waiters, w)
+	q.mu.Unlock()
+
+	log.VEventf(ctx, 2, "waiting for admission with priority %+v", pri)
+	select {
+	case <-w.admitted:
+		return q.release, nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		if w.index >= 0 {
+			heap.Remove(&q.mu.waiters, w.index)
+			q.mu.Unlock()
+			return nil, ctx.Err()
+		}
+		q.mu.Unlock()
+		// We were handed a slot concurrently with the cancellation; give it
+		// to the next waiter.
+		q.release()
+		return nil, ctx.Err()
+	}
+}
+
+// release gives up a slot, handing it over to the waiters with the highest
+// priority. More than one waiter can be admitted if the limit was raised (or
+// the queue disabled) while they were waiting.
+func (q *admissionQueue) release() {
+	limit := int(admissionMaxConcurrentStatements.Get(&q.st.SV))
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.mu.running--
+	for len(q.mu.waiters) > 0 && (limit <= 0 || q.mu.running < limit) {
+		close(heap.Pop(&q.mu.waiters).(*admissionWaiter).admitted)
+		q.mu.running++
+	}
+}
+
+// admitStatement waits for the admission of a statement of the session.
+// Statements run by the node itself are not subject to admission control.
+func (e *Executor) admitStatement(session *Session, distributed bool) (func(), error) {
+	if session.User == security.NodeUser {
+		return func() {}, nil
+	}
+	return e.admission.admit(session.Ctx(), makeAdmissionPriority(session, distributed))
+}
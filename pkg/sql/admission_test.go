@@ -0,0 +1,105 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestAdmissionQueue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	admissionMaxConcurrentStatements.Override(&st.SV, 1)
+	q := makeAdmissionQueue(st)
+	ctx := context.Background()
+
+	release, err := q.admit(ctx, admissionPriority{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Queue waiters with various priorities; they must be admitted by
+	// priority, and by arrival order for equal priorities.
+	priorities := []admissionPriority{
+		{qos: -1, small: true},
+		{small: false},
+		{small: true},
+		{txnPriority: 1},
+		{qos: 1},
+		{small: true},
+	}
+	expected := []int{4, 3, 2, 5, 1, 0}
+
+	admitted := make(chan int, len(priorities))
+	for i, pri := range priorities {
+		i, pri := i, pri
+		go func() {
+			r, err := q.admit(ctx, pri)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			admitted <- i
+			r()
+		}()
+		// Wait for the waiter to be queued so that arrival order is known.
+		testutils.SucceedsSoon(t, func() error {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			if n := len(q.mu.waiters); n != i+1 {
+				return errors.Errorf("expected %d waiters, got %d", i+1, n)
+			}
+			return nil
+		})
+	}
+
+	release()
+	for _, e := range expected {
+		if i := <-admitted; i != e {
+			t.Fatalf("expected waiter %d to be admitted, got %d", e, i)
+		}
+	}
+
+	// A canceled waiter gives up its place in the queue.
+	release, err = q.admit(ctx, admissionPriority{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelCtx, cancel := context.WithCancel(ctx)
+	errCh := make(chan error)
+	go func() {
+		_, err := q.admit(cancelCtx, admissionPriority{})
+		errCh <- err
+	}()
+	cancel()
+	if err := <-errCh; err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	release()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.mu.running != 0 || len(q.mu.waiters) != 0 {
+		t.Fatalf("expected an empty queue, got %d running and %d waiting",
+			q.mu.running, len(q.mu.waiters))
+	}
+}
@@ -76,11 +76,11 @@ func (n *alterSequenceNode) Start(params runParams) error {
 		EventLogAlterSequence,
 		int32(n.seqDesc.ID),
 		int32(params.evalCtx.NodeID),
-		struct {
-			SequenceName string
-			Statement    string
-			User         string
-		}{n.seqDesc.Name, n.n.String(), params.p.session.User},
+		&sqlbase.AlterSequenceEvent{
+			SequenceName: n.seqDesc.Name,
+			Statement:    n.n.String(),
+			User:         params.p.session.User,
+		},
 	); err != nil {
 		return err
 	}
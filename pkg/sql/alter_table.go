@@ -16,15 +16,21 @@ package sql
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 )
 
 type alterTableNode struct {
@@ -66,6 +72,12 @@ func (n *alterTableNode) Start(params runParams) error {
 	descriptorChanged := false
 	origNumMutations := len(n.tableDesc.Mutations)
 	var droppedViews []string
+	// The sequences whose back-references to the columns of the table were
+	// modified.
+	affectedSequences := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
+	// The foreign keys on columns added by this statement. They are resolved
+	// once the columns and their indexes have IDs.
+	var addedColumnFKs []*tree.ForeignKeyConstraintTableDef
 
 	for _, cmd := range n.n.Cmds {
 		switch t := cmd.(type) {
@@ -75,10 +87,6 @@ func (n *alterTableNode) Start(params runParams) error {
 				return pgerror.Unimplemented(
 					"alter add check", "adding a CHECK constraint via ALTER not supported")
 			}
-			if d.HasFKConstraint() {
-				return pgerror.Unimplemented(
-					"alter add fk", "adding a REFERENCES constraint via ALTER not supported")
-			}
 			col, idx, err := sqlbase.MakeColumnDefDescs(d, &params.p.semaCtx, params.evalCtx)
 			if err != nil {
 				return err
@@ -91,7 +99,7 @@ func (n *alterTableNode) Start(params runParams) error {
 					return err
 				}
 				if len(kvs) > 0 {
-					return sqlbase.NewNonNullViolationError(col.Name)
+					return sqlbase.NewNonNullViolationError(n.tableDesc.Name, col.Name)
 				}
 			}
 			_, dropped, err := n.tableDesc.FindColumnByName(d.Name)
@@ -104,12 +112,34 @@ func (n *alterTableNode) Start(params runParams) error {
 				}
 			}
 
+			if err := params.p.updateSequenceDependencies(
+				params.ctx, n.tableDesc, col, affectedSequences,
+			); err != nil {
+				return err
+			}
 			n.tableDesc.AddColumnMutation(*col, sqlbase.DescriptorMutation_ADD)
 			if idx != nil {
 				if err := n.tableDesc.AddIndexMutation(*idx, sqlbase.DescriptorMutation_ADD); err != nil {
 					return err
 				}
 			}
+			if d.HasFKConstraint() {
+				var targetCol tree.NameList
+				if d.References.Col != "" {
+					targetCol = append(targetCol, d.References.Col)
+				}
+				fk := &tree.ForeignKeyConstraintTableDef{
+					Table:    d.References.Table,
+					FromCols: tree.NameList{d.Name},
+					ToCols:   targetCol,
+					Name:     d.References.ConstraintName,
+					Actions:  d.References.Actions,
+				}
+				if _, err := fk.Table.NormalizeWithDatabaseName(params.p.session.Database); err != nil {
+					return err
+				}
+				addedColumnFKs = append(addedColumnFKs, fk)
+			}
 			if d.HasColumnFamily() {
 				err := n.tableDesc.AddColumnToFamilyMaybeCreate(
 					col.Name, string(d.Family.Name), d.Family.Create,
@@ -172,6 +202,11 @@ func (n *alterTableNode) Start(params runParams) error {
 				if _, err := d.Table.NormalizeWithDatabaseName(params.p.session.Database); err != nil {
 					return err
 				}
+				if _, err := n.tableDesc.FindActiveColumnsByNames(d.FromCols); err != nil {
+					// The columns may be added by this statement.
+					addedColumnFKs = append(addedColumnFKs, d)
+					break
+				}
 				affected := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
 				err := params.p.resolveFK(params.ctx, n.tableDesc, d, affected, sqlbase.ConstraintValidity_Unvalidated)
 				if err != nil {
@@ -238,6 +273,9 @@ func (n *alterTableNode) Start(params runParams) error {
 			if n.tableDesc.PrimaryIndex.ContainsColumnID(col.ID) {
 				return fmt.Errorf("column %q is referenced by the primary key", col.Name)
 			}
+			if ttl := n.tableDesc.RowLevelTTL; ttl != nil && ttl.ExpirationColumnID == col.ID {
+				return fmt.Errorf("column %q is the expiration column of the TTL of the table, use RESET TTL first", col.Name)
+			}
 			for _, idx := range n.tableDesc.AllNonDropIndexes() {
 				// We automatically drop indexes on that column that only
 				// index that column (and no other columns). If CASCADE is
@@ -304,6 +342,11 @@ func (n *alterTableNode) Start(params runParams) error {
 					}
 				}
 			}
+			if err := params.p.removeSequenceDependencies(
+				params.ctx, n.tableDesc, &col, affectedSequences,
+			); err != nil {
+				return err
+			}
 			found := false
 			for i := range n.tableDesc.Columns {
 				if n.tableDesc.Columns[i].ID == col.ID {
@@ -409,7 +452,7 @@ func (n *alterTableNode) Start(params runParams) error {
 				if err != nil {
 					panic(err)
 				}
-				if err := params.p.validateForeignKey(params.ctx, n.tableDesc, idx); err != nil {
+				if err := params.p.validateForeignKey(params.ctx, n.tableDesc, idx, publicColumns); err != nil {
 					return err
 				}
 				idx.ForeignKey.Validity = sqlbase.ConstraintValidity_Validated
@@ -419,6 +462,66 @@ func (n *alterTableNode) Start(params runParams) error {
 				return errors.Errorf("validating %s constraint %q unsupported", constraint.Kind, t.Constraint)
 			}
 
+		case *tree.AlterTableAlterPrimaryKey:
+			if err := alterPrimaryKey(n.tableDesc, t); err != nil {
+				return err
+			}
+
+		case *tree.AlterTableInjectStats:
+			if err := injectTableStats(params, n.tableDesc, t.Stats); err != nil {
+				return err
+			}
+
+		case *tree.AlterTableSetTTL:
+			if err := setTableTTL(
+				params, n.tableDesc, t, tree.AsStringWithFlags(n.n, tree.FmtSimpleQualified),
+			); err != nil {
+				return err
+			}
+			descriptorChanged = true
+
+		case *tree.AlterTableResetTTL:
+			if n.tableDesc.RowLevelTTL != nil {
+				if err := resetTableTTL(n.tableDesc); err != nil {
+					return err
+				}
+				descriptorChanged = true
+			}
+
+		case *tree.AlterTableSetRowLevelSecurity:
+			if n.tableDesc.RowLevelSecurity != t.Enable {
+				n.tableDesc.RowLevelSecurity = t.Enable
+				descriptorChanged = true
+			}
+
+		case *tree.AlterTableSetAudit:
+			if err := params.p.RequireSuperUser("change the auditing settings of a table"); err != nil {
+				return err
+			}
+			mode := sqlbase.TableDescriptor_DISABLED
+			if t.Mode == tree.AuditModeReadWrite {
+				mode = sqlbase.TableDescriptor_READWRITE
+			}
+			if n.tableDesc.AuditMode != mode {
+				n.tableDesc.AuditMode = mode
+				descriptorChanged = true
+			}
+
+		case *tree.AlterTableSetLocality:
+			dbDesc, err := MustGetDatabaseDescByID(params.ctx, params.p.txn, n.tableDesc.ParentID)
+			if err != nil {
+				return err
+			}
+			if err := setTableLocality(
+				params.ctx, params.evalCtx, dbDesc, n.tableDesc, t.Locality,
+			); err != nil {
+				return err
+			}
+			if err := params.p.writeTableLocalityZoneConfig(params.ctx, dbDesc, n.tableDesc); err != nil {
+				return err
+			}
+			descriptorChanged = true
+
 		case tree.ColumnMutationCmd:
 			// Column mutations
 			col, dropped, err := n.tableDesc.FindColumnByName(t.GetColumn())
@@ -433,6 +536,13 @@ func (n *alterTableNode) Start(params runParams) error {
 			); err != nil {
 				return err
 			}
+			if _, ok := t.(*tree.AlterTableSetDefault); ok {
+				if err := params.p.updateSequenceDependencies(
+					params.ctx, n.tableDesc, &col, affectedSequences,
+				); err != nil {
+					return err
+				}
+			}
 			n.tableDesc.UpdateColumnDescriptor(col)
 			descriptorChanged = true
 
@@ -448,7 +558,7 @@ func (n *alterTableNode) Start(params runParams) error {
 	// this line, but tests that run redundant operations like dropping
 	// a column when it's already dropped will hit this condition and exit.
 	addedMutations := len(n.tableDesc.Mutations) > origNumMutations
-	if !addedMutations && !descriptorChanged {
+	if !addedMutations && !descriptorChanged && len(addedColumnFKs) == 0 {
 		return nil
 	}
 
@@ -456,6 +566,17 @@ func (n *alterTableNode) Start(params runParams) error {
 		return err
 	}
 
+	// The foreign keys on added columns are placed on indexes being added,
+	// and validated by the schema changer after the backfills.
+	fkBackrefs := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
+	for _, d := range addedColumnFKs {
+		if err := params.p.resolveFK(
+			params.ctx, n.tableDesc, d, fkBackrefs, sqlbase.ConstraintValidity_Unvalidated,
+		); err != nil {
+			return err
+		}
+	}
+
 	mutationID := sqlbase.InvalidMutationID
 	var err error
 	if addedMutations {
@@ -471,6 +592,16 @@ func (n *alterTableNode) Start(params runParams) error {
 	if err := params.p.writeTableDesc(params.ctx, n.tableDesc); err != nil {
 		return err
 	}
+	for _, seqDesc := range affectedSequences {
+		if err := params.p.saveNonmutationAndNotify(params.ctx, seqDesc); err != nil {
+			return err
+		}
+	}
+	for _, updated := range fkBackrefs {
+		if err := params.p.saveNonmutationAndNotify(params.ctx, updated); err != nil {
+			return err
+		}
+	}
 
 	// Record this table alteration in the event log. This is an auditable log
 	// event and is recorded in the same transaction as the table descriptor
@@ -481,13 +612,13 @@ func (n *alterTableNode) Start(params runParams) error {
 		EventLogAlterTable,
 		int32(n.tableDesc.ID),
 		int32(params.evalCtx.NodeID),
-		struct {
-			TableName           string
-			Statement           string
-			User                string
-			MutationID          uint32
-			CascadeDroppedViews []string
-		}{n.tableDesc.Name, n.n.String(), params.p.session.User, uint32(mutationID), droppedViews},
+		&sqlbase.AlterTableEvent{
+			TableName:           n.tableDesc.Name,
+			Statement:           n.n.String(),
+			User:                params.p.session.User,
+			MutationID:          uint32(mutationID),
+			CascadeDroppedViews: droppedViews,
+		},
 	); err != nil {
 		return err
 	}
@@ -528,6 +659,281 @@ func applyColumnMutation(
 	return nil
 }
 
+// alterPrimaryKey adds the mutation of a primary key change to the given
+// table: a new primary index on the given columns, a copy of every secondary
+// index rewritten to reference it, and a unique index keeping the columns of
+// the current primary key unique. Once they are backfilled, they replace the
+// current indexes (see TableDescriptor.MakePrimaryKeySwapComplete).
+func alterPrimaryKey(tableDesc *sqlbase.TableDescriptor, t *tree.AlterTableAlterPrimaryKey) error {
+	if len(tableDesc.Mutations) > 0 {
+		return pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"cannot change the primary key of table %q while it has other schema changes in progress",
+			tableDesc.Name)
+	}
+	unsupported := func(reason string) error {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot change the primary key of table %q: %s", tableDesc.Name, reason)
+	}
+	if len(tableDesc.Families) > 1 {
+		return unsupported("it has more than one column family")
+	}
+	if tableDesc.IsInterleaved() {
+		return unsupported("it is interleaved")
+	}
+	for _, index := range tableDesc.AllNonDropIndexes() {
+		if index.ForeignKey.IsSet() || len(index.ReferencedBy) > 0 {
+			return unsupported("it has foreign key references")
+		}
+		if index.Partitioning.NumColumns > 0 {
+			return unsupported("it is partitioned")
+		}
+	}
+	for _, ref := range tableDesc.DependedOnBy {
+		if ref.IndexID != 0 {
+			return unsupported("views depend on its indexes")
+		}
+	}
+
+	newPrimary := sqlbase.IndexDescriptor{
+		Name:         "new_primary_key",
+		Unique:       true,
+		EncodingType: sqlbase.IndexDescriptor_PRIMARY,
+	}
+	if err := newPrimary.FillColumns(t.Columns); err != nil {
+		return err
+	}
+	for _, name := range newPrimary.ColumnNames {
+		col, err := tableDesc.FindActiveColumnByName(name)
+		if err != nil {
+			return err
+		}
+		if col.Nullable {
+			return pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+				"cannot use nullable column %q in primary key", col.Name)
+		}
+		if newPrimary.ContainsColumnID(col.ID) {
+			return pgerror.NewErrorf(pgerror.CodeDuplicateColumnError,
+				"column %q appears twice in primary key", col.Name)
+		}
+		newPrimary.ColumnIDs = append(newPrimary.ColumnIDs, col.ID)
+	}
+	if primary := &tableDesc.PrimaryIndex; len(primary.ColumnIDs) == len(newPrimary.ColumnIDs) {
+		unchanged := true
+		for i := range primary.ColumnIDs {
+			if primary.ColumnIDs[i] != newPrimary.ColumnIDs[i] ||
+				primary.ColumnDirections[i] != newPrimary.ColumnDirections[i] {
+				unchanged = false
+				break
+			}
+		}
+		if unchanged {
+			return nil
+		}
+	}
+	for _, col := range tableDesc.Columns {
+		if !newPrimary.ContainsColumnID(col.ID) {
+			newPrimary.StoreColumnNames = append(newPrimary.StoreColumnNames, col.Name)
+		}
+	}
+
+	// The IDs of the new indexes are allocated here, since the primary key
+	// swap refers to them.
+	indexes := []sqlbase.IndexDescriptor{newPrimary}
+	swap := &sqlbase.TableDescriptor_PrimaryKeySwap{MutationID: tableDesc.NextMutationID}
+	for i := range tableDesc.Indexes {
+		index := protoutil.Clone(&tableDesc.Indexes[i]).(*sqlbase.IndexDescriptor)
+		index.Name += "_rewrite_for_primary_key_change"
+		// The extra and stored column IDs are recomputed against the new
+		// primary index, in the current format.
+		index.ExtraColumnIDs = nil
+		index.StoreColumnIDs = nil
+		indexes = append(indexes, *index)
+	}
+	for i := range indexes {
+		if _, _, err := tableDesc.FindIndexByName(indexes[i].Name); err == nil {
+			return fmt.Errorf("index %q already exists", indexes[i].Name)
+		}
+		indexes[i].ID = tableDesc.NextIndexID
+		tableDesc.NextIndexID++
+		if i == 0 {
+			swap.NewPrimaryIndexID = indexes[i].ID
+		} else {
+			swap.OldIndexIDs = append(swap.OldIndexIDs, tableDesc.Indexes[i-1].ID)
+			swap.NewIndexIDs = append(swap.NewIndexIDs, indexes[i].ID)
+		}
+		if err := tableDesc.AddIndexMutation(indexes[i], sqlbase.DescriptorMutation_ADD); err != nil {
+			return err
+		}
+	}
+	if index, ok := oldPrimaryKeyIndex(tableDesc, &newPrimary); ok {
+		// The index is named by AllocateIDs.
+		index.ID = tableDesc.NextIndexID
+		tableDesc.NextIndexID++
+		if err := tableDesc.AddIndexMutation(index, sqlbase.DescriptorMutation_ADD); err != nil {
+			return err
+		}
+	}
+	tableDesc.PrimaryKeySwap = swap
+	return nil
+}
+
+// oldPrimaryKeyIndex returns the unique index on the columns of the current
+// primary key of the table which keeps them unique once newPrimary replaces
+// it. It returns false if the primary key is the hidden rowid column, or if
+// the new primary index or a unique secondary index on some of its columns
+// already keeps them unique.
+func oldPrimaryKeyIndex(
+	tableDesc *sqlbase.TableDescriptor, newPrimary *sqlbase.IndexDescriptor,
+) (sqlbase.IndexDescriptor, bool) {
+	primary := &tableDesc.PrimaryIndex
+	if len(primary.ColumnIDs) == 1 {
+		if col, err := tableDesc.FindColumnByID(primary.ColumnIDs[0]); err == nil && col.Hidden {
+			return sqlbase.IndexDescriptor{}, false
+		}
+	}
+	// The columns of the primary key are not nullable, so any unique index
+	// on some of them keeps them unique.
+	inPrimaryKey := func(index *sqlbase.IndexDescriptor) bool {
+		for _, id := range index.ColumnIDs {
+			if !primary.ContainsColumnID(id) {
+				return false
+			}
+		}
+		return true
+	}
+	if inPrimaryKey(newPrimary) {
+		return sqlbase.IndexDescriptor{}, false
+	}
+	for i := range tableDesc.Indexes {
+		if index := &tableDesc.Indexes[i]; index.Unique && inPrimaryKey(index) {
+			return sqlbase.IndexDescriptor{}, false
+		}
+	}
+	return sqlbase.IndexDescriptor{
+		Unique:           true,
+		ColumnNames:      append([]string(nil), primary.ColumnNames...),
+		ColumnDirections: append([]sqlbase.IndexDescriptor_Direction(nil), primary.ColumnDirections...),
+		ColumnIDs:        append([]sqlbase.ColumnID(nil), primary.ColumnIDs...),
+	}, true
+}
+
+// injectTableStats replaces the statistics of a table with the ones in the
+// JSON array of stats.JSONStatistic given to INJECT STATISTICS.
+func injectTableStats(
+	params runParams, desc *sqlbase.TableDescriptor, statsExpr tree.Expr,
+) error {
+	typedExpr, err := tree.TypeCheckAndRequire(
+		statsExpr, &params.p.semaCtx, types.JSON, "INJECT STATISTICS",
+	)
+	if err != nil {
+		return err
+	}
+	val, err := typedExpr.Eval(params.evalCtx)
+	if err != nil {
+		return err
+	}
+	if val == tree.DNull {
+		return fmt.Errorf("statistics cannot be NULL")
+	}
+	var jsonStats []stats.JSONStatistic
+	if err := json.Unmarshal([]byte(val.(*tree.DJSON).JSON.String()), &jsonStats); err != nil {
+		return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"invalid statistics: %v", err)
+	}
+
+	ie := InternalExecutor{LeaseManager: params.p.LeaseMgr()}
+	if _, err := ie.ExecuteStatementInTransaction(
+		params.ctx,
+		"delete-stats",
+		params.p.txn,
+		`DELETE FROM system.table_statistics WHERE "tableID" = $1`,
+		desc.ID,
+	); err != nil {
+		return err
+	}
+
+	for i := range jsonStats {
+		s := &jsonStats[i]
+		if len(s.Columns) == 0 {
+			return fmt.Errorf("statistic %d has no columns", i)
+		}
+		columnIDs := tree.NewDArray(types.Int)
+		for _, colName := range s.Columns {
+			col, err := desc.FindActiveColumnByName(colName)
+			if err != nil {
+				return err
+			}
+			if err := columnIDs.Append(tree.NewDInt(tree.DInt(col.ID))); err != nil {
+				return err
+			}
+		}
+
+		var name interface{}
+		if s.Name != "" {
+			name = s.Name
+		}
+		createdAt, err := parser.ParseStringAs(types.Timestamp, s.CreatedAt, params.evalCtx)
+		if err != nil {
+			return err
+		}
+
+		var histogram interface{}
+		if len(s.HistogramBuckets) > 0 {
+			if len(s.Columns) != 1 {
+				return fmt.Errorf("statistic %d has a histogram but more than one column", i)
+			}
+			col, err := desc.FindActiveColumnByName(s.Columns[0])
+			if err != nil {
+				return err
+			}
+			h := stats.HistogramData{
+				Buckets: make([]stats.HistogramData_Bucket, len(s.HistogramBuckets)),
+			}
+			for j, b := range s.HistogramBuckets {
+				upperBound, err := parser.ParseStringAs(
+					col.Type.ToDatumType(), b.UpperBound, params.evalCtx,
+				)
+				if err != nil {
+					return err
+				}
+				h.Buckets[j].NumEq = b.NumEq
+				h.Buckets[j].NumRange = b.NumRange
+				h.Buckets[j].UpperBound, err = sqlbase.EncodeTableKey(
+					nil, upperBound, encoding.Ascending,
+				)
+				if err != nil {
+					return err
+				}
+			}
+			histogram, err = protoutil.Marshal(&h)
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := ie.ExecuteStatementInTransaction(
+			params.ctx,
+			"insert-stats",
+			params.p.txn,
+			`INSERT INTO system.table_statistics (
+					"tableID", "statisticID", name, "columnIDs", "createdAt",
+					"rowCount", "distinctCount", "nullCount", histogram
+				) VALUES ($1, unique_rowid(), $2, $3, $4, $5, $6, $7, $8)`,
+			desc.ID,
+			name,
+			columnIDs,
+			createdAt,
+			s.RowCount,
+			s.DistinctCount,
+			s.NullCount,
+			histogram,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func labeledRowValues(cols []sqlbase.ColumnDescriptor, values tree.Datums) string {
 	var s bytes.Buffer
 	for i := range cols {
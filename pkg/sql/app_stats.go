@@ -19,8 +19,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"sort"
 	"strconv"
+	"sync/atomic"
+	"time"
 
+	"github.com/codahale/hdrhistogram"
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -30,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/pkg/errors"
 )
 
@@ -52,6 +57,24 @@ type stmtStats struct {
 	syncutil.Mutex
 
 	data roachpb.StatementStatistics
+
+	// serviceLat records the service latencies of the statement, in
+	// microseconds, to compute their percentiles. It is not part of data
+	// because it isn't reported.
+	serviceLat *hdrhistogram.Histogram
+	// lastErrTime is the time at which data.LastErr was recorded.
+	lastErrTime time.Time
+}
+
+// stmtServiceLatMax is the maximum service latency recorded in the
+// histograms of the statements. Larger latencies are recorded as
+// stmtServiceLatMax.
+const stmtServiceLatMax = time.Hour
+
+func newStmtServiceLatHistogram() *hdrhistogram.Histogram {
+	// A single significant figure keeps the histograms small enough to be
+	// maintained for every statement.
+	return hdrhistogram.New(0, int64(stmtServiceLatMax/time.Microsecond), 1)
 }
 
 // stmtStatsEnable determines whether to collect per-statement
@@ -131,6 +154,7 @@ func (a *appStats) recordStatement(
 	s.data.Count++
 	if err != nil {
 		s.data.LastErr = err.Error()
+		s.lastErrTime = timeutil.Now()
 	}
 	if automaticRetryCount == 0 {
 		s.data.FirstAttemptCount++
@@ -143,6 +167,13 @@ func (a *appStats) recordStatement(
 	s.data.RunLat.Record(s.data.Count, runLat)
 	s.data.ServiceLat.Record(s.data.Count, svcLat)
 	s.data.OverheadLat.Record(s.data.Count, ovhLat)
+	svcLatMicros := int64(svcLat * float64(time.Second/time.Microsecond))
+	if max := s.serviceLat.HighestTrackableValue(); svcLatMicros > max {
+		svcLatMicros = max
+	}
+	// The value is within the range of the histogram, so RecordValue cannot
+	// fail.
+	_ = s.serviceLat.RecordValue(svcLatMicros)
 	s.Unlock()
 }
 
@@ -153,17 +184,100 @@ func (a *appStats) getStatsForStmt(key stmtKey) *stmtStats {
 	// doesn't exist yet.
 	s, ok := a.stmts[key]
 	if !ok {
-		s = &stmtStats{}
+		s = &stmtStats{serviceLat: newStmtServiceLatHistogram()}
 		a.stmts[key] = s
 	}
 	a.Unlock()
 	return s
 }
 
+// stmtFingerprintStats holds the statistics of all the executions of the
+// statements with the same fingerprint, regardless of whether they failed or
+// were distributed.
+type stmtFingerprintStats struct {
+	fingerprint  string
+	data         roachpb.StatementStatistics
+	failureCount int64
+	distSQLCount int64
+	serviceLat   *hdrhistogram.Histogram
+	lastErrTime  time.Time
+}
+
+// fingerprintStats merges the statistics of the statements of the
+// application by fingerprint. The result is sorted by fingerprint.
+func (a *appStats) fingerprintStats() []*stmtFingerprintStats {
+	a.Lock()
+	keys := make(stmtList, 0, len(a.stmts))
+	stats := make([]*stmtStats, 0, len(a.stmts))
+	for k, s := range a.stmts {
+		keys = append(keys, k)
+		stats = append(stats, s)
+	}
+	a.Unlock()
+
+	byFingerprint := make(map[string]*stmtFingerprintStats)
+	var res []*stmtFingerprintStats
+	for i, key := range keys {
+		f, ok := byFingerprint[key.stmt]
+		if !ok {
+			f = &stmtFingerprintStats{
+				fingerprint: key.stmt,
+				serviceLat:  newStmtServiceLatHistogram(),
+			}
+			byFingerprint[key.stmt] = f
+			res = append(res, f)
+		}
+		s := stats[i]
+		s.Lock()
+		f.add(key, s)
+		s.Unlock()
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].fingerprint < res[j].fingerprint })
+	return res
+}
+
+// add merges the statistics of a statement into f. The caller must hold the
+// lock of s.
+func (f *stmtFingerprintStats) add(key stmtKey, s *stmtStats) {
+	if s.data.Count == 0 {
+		return
+	}
+	if key.failed {
+		f.failureCount += s.data.Count
+	}
+	if key.distSQLUsed {
+		f.distSQLCount += s.data.Count
+	}
+	if s.data.LastErr != "" && !s.lastErrTime.Before(f.lastErrTime) {
+		f.data.LastErr = s.data.LastErr
+		f.lastErrTime = s.lastErrTime
+	}
+	if s.data.MaxRetries > f.data.MaxRetries {
+		f.data.MaxRetries = s.data.MaxRetries
+	}
+	f.data.FirstAttemptCount += s.data.FirstAttemptCount
+	if f.data.Count == 0 {
+		// AddNumericStats is undefined when both counts are zero.
+		f.data.NumRows = s.data.NumRows
+		f.data.ServiceLat = s.data.ServiceLat
+	} else {
+		f.data.NumRows = roachpb.AddNumericStats(
+			f.data.NumRows, s.data.NumRows, f.data.Count, s.data.Count)
+		f.data.ServiceLat = roachpb.AddNumericStats(
+			f.data.ServiceLat, s.data.ServiceLat, f.data.Count, s.data.Count)
+	}
+	f.data.Count += s.data.Count
+	f.serviceLat.Merge(s.serviceLat)
+}
+
+// serviceLatQuantile returns the given quantile (between 0 and 100) of the
+// service latencies of the statements, in seconds.
+func (f *stmtFingerprintStats) serviceLatQuantile(q float64) float64 {
+	return time.Duration(f.serviceLat.ValueAtQuantile(q) * int64(time.Microsecond)).Seconds()
+}
+
 func (a *appStats) getStrForStmt(stmt Statement) string {
-	var buf bytes.Buffer
-	tree.FormatNode(&buf, tree.FmtHideConstants, stmt.AST)
-	return buf.String()
+	return tree.StatementFingerprint(stmt.AST)
 }
 
 // sqlStats carries per-application statistics for all applications on
@@ -177,13 +291,28 @@ type sqlStats struct {
 	apps map[string]*appStats
 }
 
-// resetApplicationName initializes both Session.mu.ApplicationName and
-// the cached pointer to per-application statistics. It is meant to be
-// used upon session initialization and upon SET APPLICATION_NAME.
+// applicationNameTag is the value of the `appname` log tag of sessions. It
+// is a fmt.Stringer so that log messages show the application name at the
+// time they are emitted. It is safe for concurrent use.
+type applicationNameTag struct {
+	name atomic.Value
+}
+
+// String implements the fmt.Stringer interface.
+func (t *applicationNameTag) String() string {
+	name, _ := t.name.Load().(string)
+	return name
+}
+
+// resetApplicationName initializes Session.mu.ApplicationName, the
+// application name shown in the session's log messages and the cached
+// pointer to per-application statistics. It is meant to be used upon
+// session initialization and upon SET APPLICATION_NAME.
 func (s *Session) resetApplicationName(appName string) {
 	s.mu.Lock()
 	s.mu.ApplicationName = appName
 	s.mu.Unlock()
+	s.appNameTag.name.Store(appName)
 	if s.sqlStats != nil {
 		s.appStats = s.sqlStats.getStatsForApplication(appName)
 	}
@@ -334,6 +463,18 @@ func (e *Executor) ResetStatementStats(ctx context.Context) {
 	e.sqlStats.resetStats(ctx)
 }
 
+// ResetStatementStatistics is part of the tree.EvalPlanner interface.
+func (p *planner) ResetStatementStatistics(ctx context.Context) error {
+	if err := p.requireAdminRole(ctx, "reset the statement statistics"); err != nil {
+		return err
+	}
+	if p.session.sqlStats == nil {
+		return errors.New("cannot access sql statistics from this context")
+	}
+	p.session.sqlStats.resetStats(ctx)
+	return nil
+}
+
 // FillUnimplementedErrorCounts fills the passed map with the executor's current
 // counts of how often individual unimplemented features have been encountered.
 func (e *Executor) FillUnimplementedErrorCounts(fill map[string]int64) {
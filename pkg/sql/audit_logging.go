@@ -0,0 +1,86 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// auditLogger receives the audit records of the statements that access the
+// tables whose accesses are audited (see ALTER TABLE ... EXPERIMENTAL_AUDIT).
+// The records are synced to disk before the statements return, so that no
+// access goes unrecorded if the node crashes.
+var auditLogger = log.NewSecondaryLogger("sql-audit", true /* forceSyncWrites */)
+
+// auditEvent is an access of a statement to an audited table.
+type auditEvent struct {
+	desc *sqlbase.TableDescriptor
+	// writing is set if the statement writes to the table.
+	writing bool
+}
+
+// maybeAudit records an access of the current statement to the table, if
+// the accesses of the table are audited. It is called before the privilege
+// checks, so that the attempts rejected by the checks are audited too.
+func (p *planner) maybeAudit(desc *sqlbase.TableDescriptor, writing bool) {
+	if desc.AuditMode == sqlbase.TableDescriptor_DISABLED {
+		return
+	}
+	for i := range p.auditEvents {
+		if p.auditEvents[i].desc.ID == desc.ID {
+			p.auditEvents[i].writing = p.auditEvents[i].writing || writing
+			return
+		}
+	}
+	p.auditEvents = append(p.auditEvents, auditEvent{desc: desc, writing: writing})
+}
+
+// maybeAuditStatement logs an audit record for the statement if it accessed
+// audited tables. The record contains the session user, the client address,
+// the accessed tables and how they were accessed, the statement, the number
+// of rows affected and the outcome of the statement, for instance:
+//
+//   user=root client=127.0.0.1:50000 {"t"[51]:READWRITE} "UPDATE t SET x = 1" rows=3 OK
+func (p *planner) maybeAuditStatement(ctx context.Context, stmt Statement, rows int, err error) {
+	if len(p.auditEvents) == 0 {
+		return
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "user=%s client=%s {", p.session.User, p.session.ClientAddr)
+	for i, ev := range p.auditEvents {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		mode := "READ"
+		if ev.writing {
+			mode = "READWRITE"
+		}
+		fmt.Fprintf(&buf, "%q[%d]:%s", ev.desc.Name, ev.desc.ID, mode)
+	}
+	fmt.Fprintf(&buf, "} %q rows=%d ", stmt.String(), rows)
+	if err != nil {
+		fmt.Fprintf(&buf, "ERROR: %s", err)
+	} else {
+		buf.WriteString("OK")
+	}
+	auditLogger.Logf(ctx, "%s", buf.String())
+	p.auditEvents = nil
+}
@@ -17,8 +17,12 @@ package sql
 import (
 	"fmt"
 
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
 
@@ -50,20 +54,137 @@ func CheckPrivilege(
 		user, privilege, descriptor.TypeName(), descriptor.GetName())
 }
 
-// CheckPrivilege implements the AuthorizationAccessor interface.
+// CheckPrivilege implements the AuthorizationAccessor interface. The
+// privilege is checked for the current user, and may also be held by a role
+// whose privileges the user inherits.
 func (p *planner) CheckPrivilege(
 	descriptor sqlbase.DescriptorProto, privilege privilege.Kind,
 ) error {
-	return CheckPrivilege(p.session.User, descriptor, privilege)
+	if ok, err := p.userHasPrivilege(descriptor.GetPrivileges(), privilege); err != nil || ok {
+		return err
+	}
+	return CheckPrivilege(p.currentUser(), descriptor, privilege)
+}
+
+// currentUser returns the user whose privileges are checked: the owner of
+// the view whose query is being planned, if the query runs with the
+// privileges of the owner, or else the session user.
+func (p *planner) currentUser() string {
+	if p.viewOwner != "" {
+		return p.viewOwner
+	}
+	return p.session.User
+}
+
+// userHasPrivilege returns whether the current user, or one of the roles
+// whose privileges it inherits, has the privilege in privs.
+func (p *planner) userHasPrivilege(
+	privs *sqlbase.PrivilegeDescriptor, privilege privilege.Kind,
+) (bool, error) {
+	if privs == nil {
+		return false, nil
+	}
+	if privs.CheckPrivilege(p.currentUser(), privilege) {
+		return true, nil
+	}
+	roles, err := p.inheritedRoles(p.session.Ctx())
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if privs.CheckPrivilege(role, privilege) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkDatabaseUsage verifies that the current user has the USAGE privilege
+// on the database of the table, without which the objects of the database
+// can't be accessed whatever the privileges on them.
+func (p *planner) checkDatabaseUsage(ctx context.Context, desc *sqlbase.TableDescriptor) error {
+	if p.currentUser() == security.RootUser || desc.IsVirtualTable() ||
+		desc.ParentID == keys.SystemDatabaseID {
+		return nil
+	}
+	dbDesc, err := MustGetDatabaseDescByID(ctx, p.txn, desc.ParentID)
+	if err != nil {
+		return err
+	}
+	return p.CheckPrivilege(dbDesc, privilege.USAGE)
+}
+
+// anyColumnPrivilege returns whether the current user has the privilege on
+// any column of the table.
+func (p *planner) anyColumnPrivilege(
+	desc *sqlbase.TableDescriptor, privilege privilege.Kind,
+) (bool, error) {
+	for i := range desc.Columns {
+		if ok, err := p.userHasPrivilege(desc.Columns[i].Privileges, privilege); err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// checkColumnPrivileges verifies that the session user can select the needed
+// columns of the scans of tables it can only select some columns of. It is
+// called once the needed columns are known, and before index selection turns
+// filters into spans, so that the columns used by filters are checked too.
+// Sub-queries not optimized yet are skipped: they are checked when they are.
+func (p *planner) checkColumnPrivileges(ctx context.Context, plan planNode) error {
+	subqueryPlans := make(map[planNode]struct{})
+	var err error
+	observer := planObserver{
+		subqueryNode: func(_ context.Context, sq *subquery) error {
+			if sq.plan != nil && !sq.expanded {
+				subqueryPlans[sq.plan] = struct{}{}
+			}
+			return nil
+		},
+		enterNode: func(_ context.Context, _ string, node planNode) bool {
+			if _, ok := subqueryPlans[node]; ok || err != nil {
+				return false
+			}
+			if n, ok := node.(*scanNode); ok && n.checkColumnPrivileges {
+				err = p.checkScanColumnPrivileges(n)
+			}
+			return err == nil
+		},
+	}
+	if walkErr := walkPlan(ctx, plan, observer); walkErr != nil {
+		return walkErr
+	}
+	return err
+}
+
+func (p *planner) checkScanColumnPrivileges(n *scanNode) error {
+	// The scan may belong to the query of a view, planned with the
+	// privileges of the owner of the view.
+	defer func(prev string) { p.viewOwner = prev }(p.viewOwner)
+	p.viewOwner = n.viewOwner
+	for _, i := range n.valNeededForCol.Ordered() {
+		col := &n.cols[i]
+		ok, err := p.userHasPrivilege(col.Privileges, privilege.SELECT)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("user %s does not have %s privilege on column %s of %s %s",
+				p.currentUser(), privilege.SELECT, col.Name, n.desc.TypeName(), n.desc.Name)
+		}
+	}
+	n.checkColumnPrivileges = false
+	return nil
 }
 
 // anyPrivilege implements the AuthorizationAccessor interface.
 func (p *planner) anyPrivilege(descriptor sqlbase.DescriptorProto) error {
-	if userCanSeeDescriptor(descriptor, p.session.User) {
+	if p.userCanSeeDescriptor(descriptor) {
 		return nil
 	}
 	return fmt.Errorf("user %s has no privileges on %s %s",
-		p.session.User, descriptor.TypeName(), descriptor.GetName())
+		p.currentUser(), descriptor.TypeName(), descriptor.GetName())
 }
 
 // RequireSuperUser implements the AuthorizationAccessor interface.
@@ -74,6 +195,204 @@ func (p *planner) RequireSuperUser(action string) error {
 	return nil
 }
 
-func userCanSeeDescriptor(descriptor sqlbase.DescriptorProto, user string) bool {
-	return descriptor.GetPrivileges().AnyPrivilege(user) || isVirtualDescriptor(descriptor)
+// hasAdminRole returns whether the current user is root, or a member of the
+// admin role whose privileges it inherits.
+func (p *planner) hasAdminRole(ctx context.Context) (bool, error) {
+	user := p.currentUser()
+	if user == security.RootUser || user == security.NodeUser {
+		return true, nil
+	}
+	roles, err := p.inheritedRoles(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if role == security.AdminRole {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// requireAdminRole errors if the current user is neither root nor a member
+// of the admin role. Includes the named action in the error message.
+func (p *planner) requireAdminRole(ctx context.Context, action string) error {
+	ok, err := p.hasAdminRole(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("only %s and the members of the %s role are allowed to %s",
+			security.RootUser, security.AdminRole, action)
+	}
+	return nil
+}
+
+// userCanSeeDescriptor returns whether the current user, or one of the roles
+// whose privileges it inherits, has any privilege on the descriptor, or on a
+// column of the table it describes.
+func (p *planner) userCanSeeDescriptor(descriptor sqlbase.DescriptorProto) bool {
+	if isVirtualDescriptor(descriptor) {
+		return true
+	}
+	privs := []*sqlbase.PrivilegeDescriptor{descriptor.GetPrivileges()}
+	if table, ok := descriptor.(*sqlbase.TableDescriptor); ok {
+		for i := range table.Columns {
+			if table.Columns[i].Privileges != nil {
+				privs = append(privs, table.Columns[i].Privileges)
+			}
+		}
+	}
+	for _, priv := range privs {
+		if priv.AnyPrivilege(p.currentUser()) {
+			return true
+		}
+	}
+	// The descriptor is hidden if the roles of the user can't be determined.
+	roles, _ := p.inheritedRoles(p.session.Ctx())
+	for _, role := range roles {
+		for _, priv := range privs {
+			if priv.AnyPrivilege(role) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// roleMembership is a row of system.role_members: member is a member of role,
+// and can grant role to others if isAdmin is set.
+type roleMembership struct {
+	role, member string
+	isAdmin      bool
+}
+
+// roleGraph holds the role memberships of the cluster.
+type roleGraph struct {
+	// memberships maps each user or role to its direct memberships.
+	memberships map[string][]roleMembership
+	// noInherit is the set of the users and roles created with NOINHERIT.
+	noInherit map[string]bool
+}
+
+// loadRoleGraph reads the role memberships in the planner's transaction.
+func (p *planner) loadRoleGraph(ctx context.Context) (roleGraph, error) {
+	g := roleGraph{
+		memberships: make(map[string][]roleMembership),
+		noInherit:   make(map[string]bool),
+	}
+	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	rows, err := ie.QueryRowsInTransaction(
+		ctx, "load-role-members", p.txn, `SELECT role, member, "isAdmin" FROM system.role_members`,
+	)
+	if err != nil {
+		return roleGraph{}, err
+	}
+	for _, row := range rows {
+		g.addMembership(roleMembership{
+			role:    string(tree.MustBeDString(row[0])),
+			member:  string(tree.MustBeDString(row[1])),
+			isAdmin: row[2] == tree.DBoolTrue,
+		})
+	}
+	rows, err = ie.QueryRowsInTransaction(
+		ctx, "load-role-members", p.txn,
+		`SELECT username FROM system.role_options WHERE option = $1`, roleOptionNoInherit,
+	)
+	if err != nil {
+		return roleGraph{}, err
+	}
+	for _, row := range rows {
+		g.noInherit[string(tree.MustBeDString(row[0]))] = true
+	}
+	return g, nil
+}
+
+func (g roleGraph) addMembership(m roleMembership) {
+	g.memberships[m.member] = append(g.memberships[m.member], m)
+}
+
+func (g roleGraph) removeMembership(role, member string) {
+	ms := g.memberships[member]
+	for i := range ms {
+		if ms[i].role == role {
+			g.memberships[member] = append(ms[:i:i], ms[i+1:]...)
+			return
+		}
+	}
+}
+
+// memberOf returns the roles member is a member of, directly or through other
+// roles, mapped to whether member holds the admin option on them. If
+// inheritingOnly is set, the memberships of the roles that don't inherit the
+// privileges of their own roles are not followed, and the result is empty if
+// member itself doesn't inherit them.
+func (g roleGraph) memberOf(member string, inheritingOnly bool) map[string]bool {
+	roles := make(map[string]bool)
+	if inheritingOnly && g.noInherit[member] {
+		return roles
+	}
+	visited := map[string]bool{member: true}
+	for queue := []string{member}; len(queue) > 0; queue = queue[1:] {
+		for _, m := range g.memberships[queue[0]] {
+			roles[m.role] = roles[m.role] || m.isAdmin
+			if visited[m.role] {
+				continue
+			}
+			visited[m.role] = true
+			if !(inheritingOnly && g.noInherit[m.role]) {
+				queue = append(queue, m.role)
+			}
+		}
+	}
+	return roles
+}
+
+// inheritancePaths returns the roles whose privileges member inherits, like
+// memberOf with inheritingOnly set, mapped to the chain of roles through
+// which member inherits them: from the role member is a direct member of to
+// the role itself. The shortest chain is kept when there are several.
+func (g roleGraph) inheritancePaths(member string) map[string][]string {
+	paths := make(map[string][]string)
+	if g.noInherit[member] {
+		return paths
+	}
+	visited := map[string]bool{member: true}
+	for queue := []string{member}; len(queue) > 0; queue = queue[1:] {
+		for _, m := range g.memberships[queue[0]] {
+			if visited[m.role] {
+				continue
+			}
+			visited[m.role] = true
+			paths[m.role] = append(append([]string(nil), paths[queue[0]]...), m.role)
+			if !g.noInherit[m.role] {
+				queue = append(queue, m.role)
+			}
+		}
+	}
+	return paths
+}
+
+// inheritedRoles returns the roles whose privileges the current user
+// inherits. They are only looked up once per statement and user.
+func (p *planner) inheritedRoles(ctx context.Context) ([]string, error) {
+	user := p.currentUser()
+	if p.roles.populated && p.roles.user == user {
+		return p.roles.inherited, p.roles.err
+	}
+	p.roles.populated, p.roles.user, p.roles.inherited, p.roles.err = true, user, nil, nil
+	// Root has all the privileges already, and the roles can't be looked up
+	// without a transaction.
+	if user == security.RootUser || p.txn == nil {
+		return nil, nil
+	}
+	g, err := p.loadRoleGraph(ctx)
+	if err != nil {
+		p.roles.err = err
+		return nil, err
+	}
+	for role := range g.memberOf(user, true /* inheritingOnly */) {
+		p.roles.inherited = append(p.roles.inherited, role)
+	}
+	return p.roles.inherited, nil
 }
@@ -55,6 +55,12 @@ const (
 	// many ranges.
 	indexBackfillChunkSize = 100
 
+	// indexBulkBackfillChunkSize is the maximum number of rows backfilled per
+	// chunk during an index backfill which ingests its index entries as
+	// SSTables. It is larger than indexBackfillChunkSize because each chunk is
+	// written with a few AddSSTable requests rather than with a transaction.
+	indexBulkBackfillChunkSize = 5000
+
 	// checkpointInterval is the interval after which a checkpoint of the
 	// schema change is posted.
 	checkpointInterval = 10 * time.Second
@@ -107,14 +113,6 @@ func (sc *SchemaChanger) runBackfill(
 		return err
 	}
 
-	// Mutations are applied in a FIFO order. Only apply the first set of
-	// mutations. Collect the elements that are part of the mutation.
-	var droppedIndexDescs []sqlbase.IndexDescriptor
-	var addedIndexDescs []sqlbase.IndexDescriptor
-	// Indexes within the Mutations slice for checkpointing.
-	mutationSentinel := -1
-	var droppedIndexMutationIdx int
-
 	var tableDesc *sqlbase.TableDescriptor
 	if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
 		var err error
@@ -132,59 +130,34 @@ func (sc *SchemaChanger) runBackfill(
 	log.VEventf(ctx, 0, "Running backfill for %q, v=%d, m=%d",
 		tableDesc.Name, tableDesc.Version, sc.mutationID)
 
-	needColumnBackfill := false
-	for i, m := range tableDesc.Mutations {
-		if m.MutationID != sc.mutationID {
-			break
-		}
-		switch m.Direction {
-		case sqlbase.DescriptorMutation_ADD:
-			switch t := m.Descriptor_.(type) {
-			case *sqlbase.DescriptorMutation_Column:
-				desc := m.GetColumn()
-				if desc.DefaultExpr != nil || !desc.Nullable {
-					needColumnBackfill = true
-				}
-			case *sqlbase.DescriptorMutation_Index:
-				addedIndexDescs = append(addedIndexDescs, *t.Index)
-			default:
-				return errors.Errorf("unsupported mutation: %+v", m)
-			}
-
-		case sqlbase.DescriptorMutation_DROP:
-			switch t := m.Descriptor_.(type) {
-			case *sqlbase.DescriptorMutation_Column:
-				needColumnBackfill = true
-			case *sqlbase.DescriptorMutation_Index:
-				droppedIndexDescs = append(droppedIndexDescs, *t.Index)
-				if droppedIndexMutationIdx == mutationSentinel {
-					droppedIndexMutationIdx = i
-				}
-			default:
-				return errors.Errorf("unsupported mutation: %+v", m)
-			}
-		}
-	}
-
-	// First drop indexes, then add/drop columns, and only then add indexes.
-
-	// Drop indexes.
-	if err := sc.truncateIndexes(
-		ctx, lease, version, droppedIndexDescs, droppedIndexMutationIdx,
-	); err != nil {
+	plan, err := makeSchemaChangePlan(tableDesc, sc.mutationID)
+	if err != nil {
 		return err
 	}
 
-	// Add and drop columns.
-	if needColumnBackfill {
-		if err := sc.truncateAndBackfillColumns(ctx, evalCtx, lease, version); err != nil {
-			return err
+	// Run the stages in their dependency order.
+	for s := schemaChangeStage(0); s < numSchemaChangeStages; s++ {
+		elements := plan[s]
+		if len(elements) == 0 {
+			continue
 		}
-	}
-
-	// Add new indexes.
-	if len(addedIndexDescs) > 0 {
-		if err := sc.backfillIndexes(ctx, evalCtx, lease, version); err != nil {
+		log.VEventf(ctx, 2, "running stage %q for %d elements", s, len(elements))
+		var err error
+		switch s {
+		case stageTruncateIndexes:
+			err = sc.truncateIndexes(
+				ctx, lease, version, plan.indexes(s), elements[0].mutationIdx,
+			)
+		case stageBackfillColumns:
+			err = sc.truncateAndBackfillColumns(ctx, evalCtx, lease, version)
+		case stageBackfillIndexes:
+			err = sc.backfillIndexes(ctx, evalCtx, lease, version, plan.indexes(s))
+		case stageValidateForeignKeys:
+			err = sc.validateForeignKeys(ctx, lease, plan.indexes(s))
+		default:
+			err = errors.Errorf("unknown schema change stage %d", s)
+		}
+		if err != nil {
 			return err
 		}
 	}
@@ -470,6 +443,9 @@ func (sc *SchemaChanger) distBackfill(
 			return err
 		}
 		jobID, err := sc.getJobIDForMutation(ctx, version, mutation.MutationID)
+		if err != nil {
+			return err
+		}
 
 		var tableDesc *sqlbase.TableDescriptor
 		err = sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
@@ -573,6 +549,7 @@ func (sc *SchemaChanger) backfillIndexes(
 	evalCtx tree.EvalContext,
 	lease *sqlbase.TableDescriptor_SchemaChangeLease,
 	version sqlbase.DescriptorVersion,
+	added []sqlbase.IndexDescriptor,
 ) error {
 	// Pick a read timestamp for our index backfill, or reuse the previously
 	// stored one.
@@ -595,8 +572,12 @@ func (sc *SchemaChanger) backfillIndexes(
 		fn()
 	}
 
+	chunkSize := int64(indexBackfillChunkSize)
+	if distsqlrun.CanIngestIndexBackfill(sc.distSQLPlanner.st, added) {
+		chunkSize = indexBulkBackfillChunkSize
+	}
 	return sc.distBackfill(
-		ctx, evalCtx, lease, version, indexBackfill, indexBackfillChunkSize,
+		ctx, evalCtx, lease, version, indexBackfill, chunkSize,
 		distsqlrun.IndexMutationFilter)
 }
 
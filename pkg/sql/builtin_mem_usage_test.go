@@ -94,6 +94,39 @@ func TestAggregatesMonitorMemory(t *testing.T) {
 	}
 }
 
+// TestSetOperationsMonitorMemory verifies that UNION, INTERSECT and EXCEPT
+// record the memory used by the rows they keep track of.
+func TestSetOperationsMonitorMemory(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// longStrings produces numRows distinct strings of about rowSize bytes each.
+	// The set operation has to remember all of them, which exceeds
+	// lowMemoryBudget. The COUNT prevents the rows themselves from being
+	// buffered anywhere else.
+	longStrings := fmt.Sprintf(
+		`SELECT REPEAT('a', %d) || x::STRING FROM GENERATE_SERIES(1, %d) AS t(x)`, rowSize, numRows,
+	)
+	statements := []string{
+		fmt.Sprintf(`SELECT COUNT(*) FROM (%s UNION VALUES ('b'))`, longStrings),
+		fmt.Sprintf(`SELECT COUNT(*) FROM (%s INTERSECT ALL VALUES ('b'))`, longStrings),
+		fmt.Sprintf(`SELECT COUNT(*) FROM (VALUES ('b') EXCEPT %s)`, longStrings),
+	}
+
+	for _, statement := range statements {
+		t.Run("", func(t *testing.T) {
+			s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{
+				SQLMemoryPoolSize: lowMemoryBudget,
+			})
+			defer s.Stopper().Stop(context.Background())
+
+			_, err := sqlDB.Exec(statement)
+			if pqErr, ok := err.(*pq.Error); !ok || pqErr.Code != pgerror.CodeOutOfMemoryError {
+				t.Fatalf("Expected \"%s\" to consume too much memory, got %v", statement, err)
+			}
+		})
+	}
+}
+
 func TestBuiltinsAccountForMemory(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -30,9 +30,17 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
 
-// checkHelper validates check constraints on rows, on INSERT and UPDATE.
+// checkHelper validates check constraints on rows, on INSERT and UPDATE. It
+// also validates the rows against the row-level security policies of the
+// table.
 type checkHelper struct {
-	exprs        []tree.TypedExpr
+	exprs []tree.TypedExpr
+	// names are the names of the CHECK constraints of exprs.
+	names []string
+	// policyExpr is the expression the rows have to satisfy when row-level
+	// security applies to the statement, or nil.
+	policyExpr   tree.TypedExpr
+	tableName    string
 	cols         []sqlbase.ColumnDescriptor
 	sourceInfo   *dataSourceInfo
 	ivarHelper   *tree.IndexedVarHelper
@@ -40,9 +48,17 @@ type checkHelper struct {
 }
 
 func (c *checkHelper) init(
-	ctx context.Context, p *planner, tn *tree.TableName, tableDesc *sqlbase.TableDescriptor,
+	ctx context.Context,
+	p *planner,
+	tn *tree.TableName,
+	tableDesc *sqlbase.TableDescriptor,
+	policyCommand string,
 ) error {
-	if len(tableDesc.Checks) == 0 {
+	policyExpr, err := p.rowLevelSecurityExpr(ctx, tableDesc, policyCommand, true /* withCheck */)
+	if err != nil {
+		return err
+	}
+	if len(tableDesc.Checks) == 0 && policyExpr == nil {
 		return nil
 	}
 
@@ -51,10 +67,13 @@ func (c *checkHelper) init(
 		*tn, sqlbase.ResultColumnsFromColDescs(tableDesc.Columns),
 	)
 
+	c.tableName = tableDesc.Name
 	c.exprs = make([]tree.TypedExpr, len(tableDesc.Checks))
+	c.names = make([]string, len(tableDesc.Checks))
 	exprStrings := make([]string, len(tableDesc.Checks))
 	for i, check := range tableDesc.Checks {
 		exprStrings[i] = check.Expr
+		c.names[i] = check.Name
 	}
 	exprs, err := parser.ParseExprs(exprStrings)
 	if err != nil {
@@ -70,6 +89,13 @@ func (c *checkHelper) init(
 		}
 		c.exprs[i] = typedExpr
 	}
+	if policyExpr != nil {
+		c.policyExpr, err = p.analyzeExpr(ctx, policyExpr, multiSourceInfo{c.sourceInfo}, ivarHelper,
+			types.Bool, false, "")
+		if err != nil {
+			return err
+		}
+	}
 	c.ivarHelper = &ivarHelper
 	c.curSourceRow = make(tree.Datums, len(c.cols))
 	return nil
@@ -79,7 +105,7 @@ func (c *checkHelper) init(
 // Any value not passed is set to NULL, unless `merge` is true, in which
 // case it is left unchanged (allowing updating a subset of a row's values).
 func (c *checkHelper) loadRow(colIdx map[sqlbase.ColumnID]int, row tree.Datums, merge bool) error {
-	if len(c.exprs) == 0 {
+	if len(c.exprs) == 0 && c.policyExpr == nil {
 		return nil
 	}
 	// Populate IndexedVars.
@@ -92,7 +118,8 @@ func (c *checkHelper) loadRow(colIdx map[sqlbase.ColumnID]int, row tree.Datums,
 			if row[ri] != tree.DNull {
 				expected, provided := ivar.ResolvedType(), row[ri].ResolvedType()
 				if !expected.Equivalent(provided) {
-					return errors.Errorf("%s value does not match CHECK expr type %s", provided, expected)
+					return pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+						"%s value does not match CHECK expr type %s", provided, expected)
 				}
 			}
 			c.curSourceRow[ivar.Idx] = row[ri]
@@ -121,15 +148,27 @@ func (c *checkHelper) IndexedVarNodeFormatter(idx int) tree.NodeFormatter {
 func (c *checkHelper) check(ctx *tree.EvalContext) error {
 	ctx.IVarHelper = c.ivarHelper
 	defer func() { ctx.IVarHelper = nil }()
-	for _, expr := range c.exprs {
+	for i, expr := range c.exprs {
 		if d, err := expr.Eval(ctx); err != nil {
 			return err
 		} else if res, err := tree.GetBool(d); err != nil {
 			return err
 		} else if !res && d != tree.DNull {
 			// Failed to satisfy CHECK constraint.
-			return pgerror.NewErrorf(pgerror.CodeCheckViolationError,
+			err := pgerror.NewErrorf(pgerror.CodeCheckViolationError,
 				"failed to satisfy CHECK constraint (%s)", expr)
+			err.TableName = c.tableName
+			err.ConstraintName = c.names[i]
+			return err
+		}
+	}
+	if c.policyExpr != nil {
+		// Unlike CHECK constraints, the policies reject NULL results.
+		if d, err := c.policyExpr.Eval(ctx); err != nil {
+			return err
+		} else if d != tree.DBoolTrue {
+			return pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+				"new row violates row-level security policy for table %q", c.tableName)
 		}
 	}
 	return nil
@@ -172,14 +211,23 @@ func (p *planner) validateCheckExpr(
 		return err
 	}
 	if next {
-		return errors.Errorf("validation of CHECK %q failed on row: %s",
+		return pgerror.NewErrorf(pgerror.CodeCheckViolationError,
+			"validation of CHECK %q failed on row: %s",
 			expr.String(), labeledRowValues(tableDesc.Columns, rows.Values()))
 	}
 	return nil
 }
 
+// validateForeignKey checks that the rows of srcTable have a match in the
+// table referenced by the foreign key of srcIdx. When scanVisibility
+// includes the non-public columns, the foreign key may be on an index being
+// added to srcTable, on columns being added as well; the validation then
+// scans the primary index of srcTable.
 func (p *planner) validateForeignKey(
-	ctx context.Context, srcTable *sqlbase.TableDescriptor, srcIdx *sqlbase.IndexDescriptor,
+	ctx context.Context,
+	srcTable *sqlbase.TableDescriptor,
+	srcIdx *sqlbase.IndexDescriptor,
+	scanVisibility scanVisibility,
 ) error {
 	targetTable, err := sqlbase.GetTableDescFromID(ctx, p.txn, srcIdx.ForeignKey.Table)
 	if err != nil {
@@ -220,10 +268,15 @@ func (p *planner) validateForeignKey(
 		where[i] = fmt.Sprintf("(%s IS NOT NULL AND %s IS NULL)", srcCols[i], targetCols[i])
 	}
 
+	src := srcName
+	if scanVisibility == publicColumns {
+		// Non-public indexes cannot be used as index hints.
+		src = fmt.Sprintf("%s@%s", src, escape(srcIdx.Name))
+	}
 	query := fmt.Sprintf(
-		`SELECT %s FROM %s@%s AS s LEFT OUTER JOIN %s@%s AS t ON %s WHERE %s LIMIT 1`,
+		`SELECT %s FROM %s AS s LEFT OUTER JOIN %s@%s AS t ON %s WHERE %s LIMIT 1`,
 		strings.Join(srcCols, ", "),
-		srcName, escape(srcIdx.Name), targetName, escape(targetIdx.Name),
+		src, targetName, escape(targetIdx.Name),
 		strings.Join(join, " AND "),
 		strings.Join(where, " OR "),
 	)
@@ -234,7 +287,12 @@ func (p *planner) validateForeignKey(
 		query,
 	)
 
-	values, err := p.queryRows(ctx, query)
+	var values []tree.Datums
+	if scanVisibility == publicColumns {
+		values, err = p.queryRows(ctx, query)
+	} else {
+		values, err = p.queryRowsWithVisibility(ctx, query, scanVisibility)
+	}
 	if err != nil {
 		return err
 	}
@@ -247,9 +305,56 @@ func (p *planner) validateForeignKey(
 			}
 			pairs.WriteString(fmt.Sprintf("%s=%v", srcIdx.ColumnNames[i], values[0][i]))
 		}
-		return pgerror.NewErrorf(pgerror.CodeForeignKeyViolationError,
+		err := pgerror.NewErrorf(pgerror.CodeForeignKeyViolationError,
 			"foreign key violation: %q row %s has no match in %q",
 			srcTable.Name, pairs.String(), targetTable.Name)
+		err.TableName = srcTable.Name
+		err.ConstraintName = srcIdx.ForeignKey.Name
+		return err
 	}
 	return nil
 }
+
+// queryRowsWithVisibility runs a SELECT query like queryRows, with the
+// columns of its data sources determined by scanVisibility.
+func (p *planner) queryRowsWithVisibility(
+	ctx context.Context, sql string, scanVisibility scanVisibility,
+) ([]tree.Datums, error) {
+	stmt, err := parser.ParseOne(sql)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.(*tree.Select)
+	if !ok {
+		return nil, errors.Errorf("expected a SELECT query, got %s", stmt.StatementTag())
+	}
+	clause, ok := sel.Select.(*tree.SelectClause)
+	if !ok {
+		return nil, errors.Errorf("expected a simple SELECT query, got %s", sel)
+	}
+	plan, err := p.SelectClause(ctx, clause, sel.OrderBy, sel.Limit, nil, scanVisibility)
+	if err != nil {
+		return nil, err
+	}
+	plan, err = p.optimizePlan(ctx, plan, allColumns(plan))
+	if err != nil {
+		return nil, err
+	}
+	defer plan.Close(ctx)
+	if err := p.startPlan(ctx, plan); err != nil {
+		return nil, err
+	}
+	params := runParams{
+		ctx:     ctx,
+		evalCtx: &p.evalCtx,
+		p:       p,
+	}
+	var rows []tree.Datums
+	if err := forEachRow(params, plan, func(values tree.Datums) error {
+		rows = append(rows, append(tree.Datums(nil), values...))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// CheckConsistency is part of the tree.EvalPlanner interface. The ranges
+// are checked one at a time, each with a CheckConsistency request limited
+// to the part of the range within the span. An empty endKey stands for
+// the end of the key space. The local keys can't be checked along with the
+// global keys, so the span starts at keys.LocalMax at the earliest.
+//
+// A replica whose checksum differs from the one of the lease holder is
+// handled as by the consistency queue: the lease holder logs the
+// difference, and by default terminates. The status of the range only
+// reflects whether the checksums could be compared.
+func (p *planner) CheckConsistency(
+	ctx context.Context, startKey, endKey []byte,
+) ([]tree.RangeConsistencyCheck, error) {
+	if err := p.requireAdminRole(ctx, "check the consistency of ranges"); err != nil {
+		return nil, err
+	}
+	span := roachpb.Span{Key: startKey, EndKey: endKey}
+	if span.Key.Compare(keys.LocalMax) < 0 {
+		span.Key = keys.LocalMax
+	}
+	if len(span.EndKey) == 0 {
+		span.EndKey = keys.MaxKey
+	}
+	if span.EndKey.Compare(span.Key) <= 0 {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"end key %s must be greater than start key %s", span.EndKey, span.Key)
+	}
+
+	// The meta keys addressing the ranges which hold meta keys themselves
+	// can't be derived from the bounds of the span, so the lookup of the
+	// ranges covers the meta keys entirely whenever the span overlaps them.
+	// The ranges are limited to the span below.
+	scanSpan := span
+	if scanSpan.Key.Compare(keys.SystemPrefix) < 0 {
+		scanSpan.Key = keys.MinKey
+	}
+	if scanSpan.EndKey.Compare(keys.SystemPrefix) < 0 {
+		scanSpan.EndKey = keys.SystemPrefix
+	}
+	kvs, err := scanMetaKVs(ctx, p.txn, scanSpan)
+	if err != nil {
+		return nil, err
+	}
+	var res []tree.RangeConsistencyCheck
+	var desc roachpb.RangeDescriptor
+	for _, kv := range kvs {
+		if err := kv.ValueProto(&desc); err != nil {
+			return nil, err
+		}
+		start, end := desc.StartKey.AsRawKey(), desc.EndKey.AsRawKey()
+		if start.Compare(span.Key) < 0 {
+			start = span.Key
+		}
+		if end.Compare(span.EndKey) > 0 {
+			end = span.EndKey
+		}
+		if start.Compare(end) >= 0 {
+			continue
+		}
+		check := tree.RangeConsistencyCheck{
+			RangeID:  int64(desc.RangeID),
+			StartKey: desc.StartKey,
+			Status:   tree.RangeConsistencyCheckOK,
+		}
+		if err := p.ExecCfg().DB.CheckConsistency(ctx, start, end, false /* withDiff */); err != nil {
+			check.Status = tree.RangeConsistencyCheckFailed
+			check.Detail = err.Error()
+		}
+		res = append(res, check)
+	}
+	return res, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package colenc
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	vault := NewFakeVault()
+	defer vault.Close()
+
+	st := cluster.MakeTestingClusterSettings()
+	m := NewKeyManager(st)
+	if _, err := m.Encrypt(ctx, []byte("a")); !testutils.IsError(err, "requires the cluster setting") {
+		t.Fatalf("expected error, got %v", err)
+	}
+	if err := st.MakeUpdater().Set("sql.column_encryption.kms_uri", vault.URI("pii"), "s"); err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte("4111 1111 1111 1111")
+	c1, err := m.Encrypt(ctx, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := m.Encrypt(ctx, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(c1, c2) {
+		t.Fatal("expected different ciphertexts for the same value")
+	}
+	if bytes.Contains(c1, plaintext) {
+		t.Fatalf("ciphertext %q contains the plaintext", c1)
+	}
+	if n := vault.Wraps(); n != 1 {
+		t.Fatalf("expected the data key to be wrapped once, got %d", n)
+	}
+
+	// Another node decrypts the values after unwrapping their data key once.
+	other := NewKeyManager(st)
+	for _, c := range [][]byte{c1, c2} {
+		p, err := other.Decrypt(ctx, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(p, plaintext) {
+			t.Fatalf("expected %q, got %q", plaintext, p)
+		}
+	}
+	if n := vault.Unwraps(); n != 1 {
+		t.Fatalf("expected the data key to be unwrapped once, got %d", n)
+	}
+
+	// Values encrypted after a rotation of the master key can be decrypted
+	// along with the older ones.
+	vault.Rotate()
+	c3, err := NewKeyManager(st).Encrypt(ctx, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(c3, []byte("vault:v2:")) {
+		t.Fatalf("expected a data key wrapped by the new master key, got %q", c3)
+	}
+	for _, c := range [][]byte{c1, c3} {
+		if _, err := other.Decrypt(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Tampering with any part of a ciphertext is detected.
+	for i := range c1 {
+		tampered := append([]byte(nil), c1...)
+		tampered[i] ^= 1
+		if _, err := other.Decrypt(ctx, tampered); err == nil {
+			t.Fatalf("%d: expected error decrypting tampered ciphertext", i)
+		}
+	}
+	for _, c := range [][]byte{nil, []byte("abc"), c1[:len(c1)-1]} {
+		if _, err := other.Decrypt(ctx, c); !testutils.IsError(err, "invalid ciphertext") {
+			t.Fatalf("%q: expected invalid ciphertext error, got %v", c, err)
+		}
+	}
+}
+
+func TestMakeKMS(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, tc := range []struct {
+		uri string
+		err string
+	}{
+		{"vault://localhost:8200/transit/pii?token=t", ""},
+		{"vault+http://localhost:8200/a/b/pii?token=t", ""},
+		{"aws-kms://pii", `unsupported KMS scheme "aws-kms"`},
+		{"vault:///transit/pii?token=t", "missing host"},
+		{"vault://localhost:8200/pii?token=t", "path must be"},
+		{"vault://localhost:8200/transit/?token=t", "path must be"},
+	} {
+		_, err := MakeKMS(tc.uri)
+		if !testutils.IsError(err, tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.uri, tc.err, err)
+		}
+	}
+}
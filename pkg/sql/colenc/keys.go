@@ -0,0 +1,284 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package colenc implements the envelope encryption of column values: values
+// are encrypted with AES-256-GCM under data keys, and the data keys are
+// wrapped by a master key held by an external KMS. Every ciphertext carries
+// the wrapped data key that encrypted it, so no key is ever stored in the
+// cluster in the clear, and ciphertexts can be decrypted by any node having
+// access to the KMS.
+//
+// The format of a ciphertext is:
+//
+//   version (1 byte) | length of the wrapped data key (uvarint) |
+//   wrapped data key | nonce (12 bytes) | sealed value
+//
+// The version and the wrapped data key are authenticated along with the
+// value.
+package colenc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// KMSURI is the URI of the KMS wrapping the data keys.
+var KMSURI = settings.RegisterValidatedStringSetting(
+	"sql.column_encryption.kms_uri",
+	"the URI of the KMS wrapping the data keys of encrypt_column() "+
+		"(e.g. vault://<host>:8200/transit/<key>)",
+	"",
+	func(uri string) error {
+		if uri == "" {
+			return nil
+		}
+		_, err := MakeKMS(uri)
+		return err
+	},
+)
+
+// dataKeyLifetime is the time after which each node stops encrypting new
+// values with its data key and creates a new one.
+var dataKeyLifetime = settings.RegisterValidatedDurationSetting(
+	"sql.column_encryption.data_key_lifetime",
+	"the time after which each node starts encrypting new values with a new data key",
+	24*time.Hour,
+	func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf("sql.column_encryption.data_key_lifetime must be positive: %s", v)
+		}
+		return nil
+	},
+)
+
+const (
+	formatVersion = 1
+	dataKeySize   = 32
+	// maxUnwrappedKeys is the number of unwrapped data keys a KeyManager
+	// keeps, to avoid calling the KMS to decrypt every value.
+	maxUnwrappedKeys = 1024
+)
+
+// KeyManager encrypts and decrypts column values. It holds the data key new
+// values are encrypted with, and caches the data keys unwrapped to decrypt
+// values. It is safe for concurrent use.
+type KeyManager struct {
+	st *cluster.Settings
+
+	mu struct {
+		syncutil.Mutex
+		// kmsURI is the value of sql.column_encryption.kms_uri kms was made
+		// from. The keys are forgotten when the setting changes.
+		kmsURI string
+		kms    KMS
+		// current is the data key new values are encrypted with, or nil if
+		// it hasn't been created yet.
+		current *dataKey
+		// unwrapped maps the wrapped data keys to their cipher.AEAD.
+		unwrapped *cache.UnorderedCache
+	}
+}
+
+type dataKey struct {
+	kms     KMS
+	wrapped []byte
+	aead    cipher.AEAD
+	created time.Time
+}
+
+// NewKeyManager creates a KeyManager.
+func NewKeyManager(st *cluster.Settings) *KeyManager {
+	m := &KeyManager{st: st}
+	m.mu.unwrapped = cache.NewUnorderedCache(cache.Config{
+		Policy: cache.CacheLRU,
+		ShouldEvict: func(size int, _, _ interface{}) bool {
+			return size > maxUnwrappedKeys
+		},
+	})
+	return m
+}
+
+// Encrypt encrypts a value with the current data key.
+func (m *KeyManager) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	k, err := m.currentKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, 1+binary.MaxVarintLen64, 1+binary.MaxVarintLen64+len(k.wrapped))
+	header[0] = formatVersion
+	n := binary.PutUvarint(header[1:], uint64(len(k.wrapped)))
+	header = append(header[:1+n], k.wrapped...)
+
+	nonceSize := k.aead.NonceSize()
+	out := make([]byte, len(header)+nonceSize, len(header)+nonceSize+len(plaintext)+k.aead.Overhead())
+	copy(out, header)
+	nonce := out[len(header):]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return k.aead.Seal(out, nonce, plaintext, header), nil
+}
+
+// Decrypt decrypts a value returned by Encrypt.
+func (m *KeyManager) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	header, wrapped, rest, err := splitCiphertext(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := m.unwrap(ctx, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, errInvalidCiphertext
+	}
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], header)
+	if err != nil {
+		return nil, errInvalidCiphertext
+	}
+	return plaintext, nil
+}
+
+var errInvalidCiphertext = pgerror.NewError(pgerror.CodeDataExceptionError,
+	"invalid ciphertext: not a value encrypted by encrypt_column(), or corrupted")
+
+// splitCiphertext splits a ciphertext into its authenticated header, the
+// wrapped data key in the header, and the nonce followed by the sealed value.
+func splitCiphertext(ciphertext []byte) (header, wrapped, rest []byte, err error) {
+	if len(ciphertext) == 0 || ciphertext[0] != formatVersion {
+		return nil, nil, nil, errInvalidCiphertext
+	}
+	l, n := binary.Uvarint(ciphertext[1:])
+	if n <= 0 || l == 0 || l > uint64(len(ciphertext)-1-n) {
+		return nil, nil, nil, errInvalidCiphertext
+	}
+	headerLen := 1 + n + int(l)
+	return ciphertext[:headerLen], ciphertext[1+n : headerLen], ciphertext[headerLen:], nil
+}
+
+// getKMS returns the KMS of sql.column_encryption.kms_uri.
+func (m *KeyManager) getKMS() (KMS, error) {
+	uri := KMSURI.Get(&m.st.SV)
+	if uri == "" {
+		return nil, pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"column encryption requires the cluster setting sql.column_encryption.kms_uri")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.mu.kms == nil || uri != m.mu.kmsURI {
+		kms, err := MakeKMS(uri)
+		if err != nil {
+			return nil, err
+		}
+		m.mu.kmsURI = uri
+		m.mu.kms = kms
+		m.mu.current = nil
+		m.mu.unwrapped.Clear()
+	}
+	return m.mu.kms, nil
+}
+
+// currentKey returns the data key new values are encrypted with, creating a
+// new one if there is none or if it has expired.
+func (m *KeyManager) currentKey(ctx context.Context) (*dataKey, error) {
+	kms, err := m.getKMS()
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	k := m.mu.current
+	m.mu.Unlock()
+	if k != nil && k.kms == kms && timeutil.Since(k.created) < dataKeyLifetime.Get(&m.st.SV) {
+		return k, nil
+	}
+
+	// The KMS is called without holding the lock. Concurrent callers may each
+	// create a data key: only the last one remains current, and all of them
+	// stay valid.
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	wrapped, err := kms.Wrap(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	k = &dataKey{kms: kms, wrapped: wrapped, aead: aead, created: timeutil.Now()}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.mu.kms == kms {
+		m.mu.current = k
+		m.mu.unwrapped.Add(string(wrapped), aead)
+	}
+	return k, nil
+}
+
+// unwrap returns the cipher.AEAD of a wrapped data key.
+func (m *KeyManager) unwrap(ctx context.Context, wrapped []byte) (cipher.AEAD, error) {
+	kms, err := m.getKMS()
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	aead, ok := m.mu.unwrapped.Get(string(wrapped))
+	m.mu.Unlock()
+	if ok {
+		return aead.(cipher.AEAD), nil
+	}
+
+	key, err := kms.Unwrap(ctx, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != dataKeySize {
+		return nil, errors.Errorf("unwrapped data key has %d bytes, expected %d", len(key), dataKeySize)
+	}
+	a, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.mu.kms == kms {
+		m.mu.unwrapped.Add(string(wrapped), a)
+	}
+	return a, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,69 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package colenc
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// KMS is an external key management service holding the master key that
+// wraps the data keys of column encryption. The master key never leaves the
+// KMS.
+type KMS interface {
+	// Wrap encrypts a data key with the current version of the master key.
+	Wrap(ctx context.Context, key []byte) ([]byte, error)
+	// Unwrap decrypts a data key returned by Wrap, with whichever version of
+	// the master key wrapped it.
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// KMSFactory creates a KMS from the URI set in
+// sql.column_encryption.kms_uri.
+type KMSFactory func(uri *url.URL) (KMS, error)
+
+var kmsFactories = map[string]KMSFactory{}
+
+// RegisterKMS registers the factory of the KMS of the given URI scheme. It
+// must be called from an init function.
+func RegisterKMS(scheme string, factory KMSFactory) {
+	if _, ok := kmsFactories[scheme]; ok {
+		panic(errors.Errorf("KMS scheme %q already registered", scheme))
+	}
+	kmsFactories[scheme] = factory
+}
+
+// MakeKMS creates the KMS of the given URI.
+func MakeKMS(uri string) (KMS, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid KMS URI")
+	}
+	factory, ok := kmsFactories[u.Scheme]
+	if !ok {
+		schemes := make([]string, 0, len(kmsFactories))
+		for s := range kmsFactories {
+			schemes = append(schemes, s)
+		}
+		sort.Strings(schemes)
+		return nil, errors.Errorf("unsupported KMS scheme %q, expected one of: %s",
+			u.Scheme, strings.Join(schemes, ", "))
+	}
+	return factory(u)
+}
@@ -0,0 +1,124 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package colenc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// This file contains utility functions for tests (in other packages).
+
+// FakeVault is an HTTP server implementing the encrypt and decrypt endpoints
+// of the transit secrets engine of Vault, for tests.
+type FakeVault struct {
+	server *httptest.Server
+
+	mu struct {
+		syncutil.Mutex
+		// version is the current version of the master key.
+		version int
+		// keys maps the wrapped data keys to the plaintext ones.
+		keys           map[string]string
+		wraps, unwraps int
+	}
+}
+
+// NewFakeVault starts a FakeVault.
+func NewFakeVault() *FakeVault {
+	v := &FakeVault{}
+	v.mu.version = 1
+	v.mu.keys = make(map[string]string)
+	v.server = httptest.NewServer(http.HandlerFunc(v.serve))
+	return v
+}
+
+// Close stops the server.
+func (v *FakeVault) Close() {
+	v.server.Close()
+}
+
+// URI returns the value of sql.column_encryption.kms_uri using the given key
+// of the server.
+func (v *FakeVault) URI(key string) string {
+	return fmt.Sprintf("%s://%s/transit/%s?%s=test",
+		vaultHTTPScheme, strings.TrimPrefix(v.server.URL, "http://"), key, vaultTokenParam)
+}
+
+// Rotate creates a new version of the master key.
+func (v *FakeVault) Rotate() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.mu.version++
+}
+
+// Wraps returns the number of data keys wrapped by the server.
+func (v *FakeVault) Wraps() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.mu.wraps
+}
+
+// Unwraps returns the number of data keys unwrapped by the server.
+func (v *FakeVault) Unwraps() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.mu.unwraps
+}
+
+func (v *FakeVault) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Plaintext  string `json:"plaintext"`
+		Ciphertext string `json:"ciphertext"`
+	}
+	if r.Header.Get("X-Vault-Token") == "" {
+		http.Error(w, `{"errors":["missing client token"]}`, http.StatusBadRequest)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"errors":["invalid request"]}`, http.StatusBadRequest)
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	var data map[string]string
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/transit/encrypt/"):
+		v.mu.wraps++
+		key := strings.TrimPrefix(r.URL.Path, "/v1/transit/encrypt/")
+		wrapped := fmt.Sprintf("vault:v%d:%s:%d", v.mu.version, key, len(v.mu.keys))
+		v.mu.keys[wrapped] = req.Plaintext
+		data = map[string]string{"ciphertext": wrapped}
+	case strings.HasPrefix(r.URL.Path, "/v1/transit/decrypt/"):
+		v.mu.unwraps++
+		plaintext, ok := v.mu.keys[req.Ciphertext]
+		if !ok {
+			http.Error(w, `{"errors":["invalid ciphertext"]}`, http.StatusBadRequest)
+			return
+		}
+		data = map[string]string{"plaintext": plaintext}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
@@ -0,0 +1,176 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package colenc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+)
+
+// This file implements a KMS backed by the transit secrets engine of
+// HashiCorp Vault. The URI names the engine's mount path and key:
+//
+//   vault://<host>:<port>/<mount path>/<key name>?token=<token>
+//
+// The vault scheme connects with HTTPS, and vault+http with plain HTTP. If
+// the URI has no token, the VAULT_TOKEN environment variable is used.
+//
+// Rotating the key in Vault makes the new data keys wrapped by its new
+// version; the data keys wrapped by older versions are unwrapped as long as
+// Vault allows decrypting with them.
+
+const (
+	vaultScheme     = "vault"
+	vaultHTTPScheme = "vault+http"
+	vaultTokenParam = "token"
+	vaultTokenEnv   = "VAULT_TOKEN"
+	vaultTimeout    = 10 * time.Second
+)
+
+func init() {
+	RegisterKMS(vaultScheme, makeVaultKMS)
+	RegisterKMS(vaultHTTPScheme, makeVaultKMS)
+}
+
+type vaultKMS struct {
+	client http.Client
+	// encryptURL and decryptURL are the endpoints of the transit engine for
+	// the key.
+	encryptURL, decryptURL string
+	token                  string
+}
+
+var _ KMS = &vaultKMS{}
+
+func makeVaultKMS(u *url.URL) (KMS, error) {
+	if u.Host == "" {
+		return nil, errors.New("missing host in KMS URI")
+	}
+	p := strings.Trim(u.Path, "/")
+	i := strings.LastIndex(p, "/")
+	if i <= 0 || i == len(p)-1 {
+		return nil, errors.Errorf("KMS URI path must be /<mount path>/<key name>, got %q", u.Path)
+	}
+	mount, key := p[:i], p[i+1:]
+
+	token := u.Query().Get(vaultTokenParam)
+	if token == "" {
+		token = os.Getenv(vaultTokenEnv)
+	}
+	if token == "" {
+		return nil, errors.Errorf("missing Vault token: set the %s parameter of the KMS URI or %s",
+			vaultTokenParam, vaultTokenEnv)
+	}
+
+	base := url.URL{Scheme: "https", Host: u.Host}
+	if u.Scheme == vaultHTTPScheme {
+		base.Scheme = "http"
+	}
+	endpoint := func(op string) string {
+		e := base
+		e.Path = "/" + path.Join("v1", mount, op, key)
+		return e.String()
+	}
+	return &vaultKMS{
+		client:     http.Client{Timeout: vaultTimeout},
+		encryptURL: endpoint("encrypt"),
+		decryptURL: endpoint("decrypt"),
+		token:      token,
+	}, nil
+}
+
+// Wrap implements the KMS interface.
+func (v *vaultKMS) Wrap(ctx context.Context, key []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	req := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(key)}
+	if err := v.post(ctx, v.encryptURL, req, &resp); err != nil {
+		return nil, errors.Wrap(err, "unable to wrap data key")
+	}
+	if resp.Data.Ciphertext == "" {
+		return nil, errors.New("unable to wrap data key: empty response from Vault")
+	}
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+// Unwrap implements the KMS interface.
+func (v *vaultKMS) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	req := map[string]string{"ciphertext": string(wrapped)}
+	if err := v.post(ctx, v.decryptURL, req, &resp); err != nil {
+		return nil, errors.Wrap(err, "unable to unwrap data key")
+	}
+	key, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to unwrap data key")
+	}
+	return key, nil
+}
+
+func (v *vaultKMS) post(
+	ctx context.Context, endpoint string, request, response interface{},
+) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Vault-Token", v.token)
+	req.Header.Set(httputil.ContentTypeHeader, httputil.JSONContentType)
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(b, &vaultErr) == nil && len(vaultErr.Errors) > 0 {
+			return errors.Errorf("error response from Vault: %s: %s",
+				resp.Status, strings.Join(vaultErr.Errors, "; "))
+		}
+		return errors.Errorf("error response from Vault: %s %q", resp.Status, b)
+	}
+	return json.Unmarshal(b, response)
+}
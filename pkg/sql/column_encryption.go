@@ -0,0 +1,344 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/colenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+const (
+	// keyRotationChunkSize is the number of rows re-encrypted per
+	// transaction by a key rotation job.
+	keyRotationChunkSize = 100
+	// keyRotationCheckpointInterval is the interval at which a key rotation
+	// job checkpoints its progress.
+	keyRotationCheckpointInterval = 10 * time.Second
+)
+
+// EncryptColumnValue implements the tree.EvalPlanner interface.
+func (p *planner) EncryptColumnValue(ctx context.Context, plaintext []byte) ([]byte, error) {
+	keys, err := p.columnEncryptionKeys()
+	if err != nil {
+		return nil, err
+	}
+	return keys.Encrypt(ctx, plaintext)
+}
+
+// DecryptColumnValue implements the tree.EvalPlanner interface.
+func (p *planner) DecryptColumnValue(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	keys, err := p.columnEncryptionKeys()
+	if err != nil {
+		return nil, err
+	}
+	return keys.Decrypt(ctx, ciphertext)
+}
+
+// columnEncryptionKeys returns the KeyManager of the node. Internal planners
+// have none.
+func (p *planner) columnEncryptionKeys() (*colenc.KeyManager, error) {
+	if cfg := p.ExecCfg(); cfg != nil && cfg.ColumnEncryptionKeys != nil {
+		return cfg.ColumnEncryptionKeys, nil
+	}
+	return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+		"column encryption is not supported in this context")
+}
+
+// RotateEncryptionKey re-encrypts the values of columns under a new data key
+// wrapped by the current version of the master key. The values are rewritten
+// by a job which checkpoints its progress, so that it is resumed by another
+// node if the gateway fails.
+// Privileges: SELECT and UPDATE on table.
+func (p *planner) RotateEncryptionKey(
+	ctx context.Context, n *tree.RotateEncryptionKey,
+) (planNode, error) {
+	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	if err != nil {
+		return nil, err
+	}
+	tableDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), tn)
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc == nil {
+		return nil, sqlbase.NewUndefinedRelationError(tn)
+	}
+	for _, priv := range []privilege.Kind{privilege.SELECT, privilege.UPDATE} {
+		if err := p.CheckPrivilege(tableDesc, priv); err != nil {
+			return nil, err
+		}
+	}
+
+	columnIDs := make([]sqlbase.ColumnID, len(n.Columns))
+	for i, name := range n.Columns {
+		col, err := tableDesc.FindActiveColumnByName(string(name))
+		if err != nil {
+			return nil, err
+		}
+		if col.Type.SemanticType != sqlbase.ColumnType_BYTES {
+			return nil, pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+				"column %s is of type %s, expected BYTES holding values of encrypt_column()",
+				col.Name, col.Type.SQLString())
+		}
+		columnIDs[i] = col.ID
+	}
+	if err := checkKeyRotationTable(tableDesc, columnIDs); err != nil {
+		return nil, err
+	}
+
+	header := sqlbase.ResultColumns{
+		{Name: "job_id", Typ: types.Int},
+		{Name: "status", Typ: types.String},
+		{Name: "values_rewritten", Typ: types.Int},
+	}
+	description := tree.AsStringWithFlags(n, tree.FmtSimpleQualified)
+	fn := func(ctx context.Context, resultsCh chan<- tree.Datums) error {
+		job := p.ExecCfg().JobRegistry.NewJob(jobs.Record{
+			Description:   description,
+			Username:      p.User(),
+			DescriptorIDs: sqlbase.IDs{tableDesc.ID},
+			Details: jobs.KeyRotationDetails{
+				TableID:    tableDesc.ID,
+				ColumnIDs:  columnIDs,
+				ResumeSpan: tableDesc.PrimaryIndexSpan(),
+			},
+		})
+		rewritten, rotateErr := rotateEncryptionKey(ctx, p.ExecCfg().DB, p.ExecCfg().Settings, job)
+		if err := job.FinishedWith(ctx, rotateErr); err != nil {
+			return err
+		}
+		if rotateErr != nil {
+			return rotateErr
+		}
+		resultsCh <- tree.Datums{
+			tree.NewDInt(tree.DInt(*job.ID())),
+			tree.NewDString(string(jobs.StatusSucceeded)),
+			tree.NewDInt(tree.DInt(rewritten)),
+		}
+		return nil
+	}
+	return &hookFnNode{f: fn, header: header}, nil
+}
+
+// checkKeyRotationTable checks that the values of the columns of a table can
+// be rewritten by a key rotation job: the values must not be encoded in any
+// index, and no schema change must be in progress.
+func checkKeyRotationTable(desc *sqlbase.TableDescriptor, columnIDs []sqlbase.ColumnID) error {
+	if len(desc.Mutations) > 0 {
+		return pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"cannot rotate the encryption key of table %s while a schema change is in progress",
+			desc.Name)
+	}
+	for _, id := range columnIDs {
+		for _, idx := range desc.AllNonDropIndexes() {
+			if idx.ContainsColumnID(id) {
+				col, err := desc.FindColumnByID(id)
+				if err != nil {
+					return err
+				}
+				return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+					"cannot rotate the encryption key of column %s: it is stored in index %s",
+					col.Name, idx.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// rotateEncryptionKey runs a key rotation job, starting at the span of its
+// details recorded by the last checkpoint. It returns the number of values
+// re-encrypted by the job.
+//
+// The values are re-encrypted by a KeyManager of their own, so they all share
+// a new data key, created by the first one. The data keys of the values
+// written by the nodes in the meantime are renewed according to
+// sql.column_encryption.data_key_lifetime.
+func rotateEncryptionKey(
+	ctx context.Context, db *client.DB, st *cluster.Settings, job *jobs.Job,
+) (int64, error) {
+	details := job.Record.Details.(jobs.KeyRotationDetails)
+	if err := job.Created(ctx, jobs.WithoutCancel); err != nil {
+		return 0, err
+	}
+	if err := job.Started(ctx); err != nil {
+		return 0, err
+	}
+
+	keys := colenc.NewKeyManager(st)
+	lastCheckpoint := timeutil.Now()
+	for details.ResumeSpan.Key != nil {
+		resumeKey, n, err := rotateEncryptionKeyChunk(ctx, db, keys, details)
+		if err != nil {
+			return 0, err
+		}
+		details.ResumeSpan.Key = resumeKey
+		details.ValuesRewritten += n
+		if resumeKey != nil && timeutil.Since(lastCheckpoint) < keyRotationCheckpointInterval {
+			continue
+		}
+		checkpoint := details
+		if err := job.Progressed(ctx, job.Payload().FractionCompleted,
+			func(ctx context.Context, d interface{}) {
+				d.(*jobs.Payload_KeyRotation).KeyRotation = &checkpoint
+			},
+		); err != nil {
+			return 0, err
+		}
+		lastCheckpoint = timeutil.Now()
+	}
+	return details.ValuesRewritten, nil
+}
+
+// rotateEncryptionKeyChunk re-encrypts the values of up to
+// keyRotationChunkSize rows of a table in a transaction. It returns the key
+// to resume at, or nil if there are no more rows, and the number of values
+// re-encrypted.
+func rotateEncryptionKeyChunk(
+	ctx context.Context, db *client.DB, keys *colenc.KeyManager, details jobs.KeyRotationDetails,
+) (roachpb.Key, int64, error) {
+	var resumeKey roachpb.Key
+	var rewritten int64
+	err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		resumeKey, rewritten = nil, 0
+		desc, err := sqlbase.GetTableDescFromID(ctx, txn, details.TableID)
+		if err != nil {
+			return err
+		}
+		if desc.Dropped() {
+			return errTableDropped
+		}
+		if err := checkKeyRotationTable(desc, details.ColumnIDs); err != nil {
+			return err
+		}
+		colIdxMap := make(map[sqlbase.ColumnID]int, len(desc.Columns))
+		for i, c := range desc.Columns {
+			colIdxMap[c.ID] = i
+		}
+		updateCols := make([]sqlbase.ColumnDescriptor, len(details.ColumnIDs))
+		for i, id := range details.ColumnIDs {
+			idx, ok := colIdxMap[id]
+			if !ok {
+				return pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
+					"column %d of table %s was dropped", id, desc.Name)
+			}
+			updateCols[i] = desc.Columns[idx]
+		}
+
+		fkTables := sqlbase.TablesNeededForFKs(*desc, sqlbase.CheckUpdates)
+		for id := range fkTables {
+			table, err := sqlbase.GetTableDescFromID(ctx, txn, id)
+			if err != nil {
+				return err
+			}
+			fkTables[id] = sqlbase.TableLookup{Table: table}
+		}
+		var alloc sqlbase.DatumAlloc
+		ru, err := sqlbase.MakeRowUpdater(
+			txn, desc, fkTables, updateCols, desc.Columns, sqlbase.RowUpdaterOnlyColumns, &alloc,
+		)
+		if err != nil {
+			return err
+		}
+
+		var valNeededForCol util.FastIntSet
+		valNeededForCol.AddRange(0, len(desc.Columns)-1)
+		var fetcher sqlbase.MultiRowFetcher
+		if err := fetcher.Init(
+			false /* reverse */, false /* returnRangeInfo */, &alloc,
+			sqlbase.MultiRowFetcherTableArgs{
+				Desc:            desc,
+				Index:           &desc.PrimaryIndex,
+				ColIdxMap:       colIdxMap,
+				Cols:            desc.Columns,
+				ValNeededForCol: valNeededForCol,
+			},
+		); err != nil {
+			return err
+		}
+		if err := fetcher.StartScan(
+			ctx, txn, roachpb.Spans{details.ResumeSpan}, true /* limitBatches */, keyRotationChunkSize,
+			false, /* traceKV */
+		); err != nil {
+			return err
+		}
+
+		oldValues := make(tree.Datums, len(ru.FetchCols))
+		updateValues := make(tree.Datums, len(updateCols))
+		b := txn.NewBatch()
+		for i := 0; i < keyRotationChunkSize; i++ {
+			datums, _, _, err := fetcher.NextRowDecoded(ctx)
+			if err != nil {
+				return err
+			}
+			if datums == nil {
+				break
+			}
+			for j, col := range updateCols {
+				d := datums[colIdxMap[col.ID]]
+				if d == tree.DNull {
+					updateValues[j] = d
+					continue
+				}
+				plaintext, err := keys.Decrypt(ctx, []byte(*d.(*tree.DBytes)))
+				if err != nil {
+					return err
+				}
+				ciphertext, err := keys.Encrypt(ctx, plaintext)
+				if err != nil {
+					return err
+				}
+				updateValues[j] = tree.NewDBytes(tree.DBytes(ciphertext))
+				rewritten++
+			}
+			copy(oldValues, datums)
+			if _, err := ru.UpdateRow(ctx, b, oldValues, updateValues, false /* traceKV */); err != nil {
+				return err
+			}
+		}
+		resumeKey = fetcher.Key()
+		return txn.CommitInBatch(ctx, b)
+	})
+	return resumeKey, rewritten, err
+}
+
+func keyRotationResumeHook(
+	typ jobs.Type, settings *cluster.Settings,
+) func(context.Context, *jobs.Job) error {
+	if typ != jobs.TypeKeyRotation {
+		return nil
+	}
+	return func(ctx context.Context, job *jobs.Job) error {
+		_, err := rotateEncryptionKey(ctx, job.DB(), settings, job)
+		return err
+	}
+}
+
+func init() {
+	jobs.AddResumeHook(keyRotationResumeHook)
+}
@@ -0,0 +1,91 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/colenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/tests"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestColumnEncryption(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	vault := colenc.NewFakeVault()
+	defer vault.Close()
+
+	params, _ := tests.CreateTestServerParams()
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+	r := sqlutils.MakeSQLRunner(sqlDB)
+
+	r.Exec(t, fmt.Sprintf(`SET CLUSTER SETTING sql.column_encryption.kms_uri = '%s'`, vault.URI("pii")))
+	r.Exec(t, `CREATE DATABASE d; CREATE TABLE d.cards (id INT PRIMARY KEY, number BYTES, cvv BYTES)`)
+	// The setting is applied asynchronously.
+	testutils.SucceedsSoon(t, func() error {
+		_, err := sqlDB.Exec(`
+INSERT INTO d.cards
+  SELECT i, encrypt_column(i::STRING), CASE WHEN i % 2 = 0 THEN encrypt_column(b'123') END
+  FROM generate_series(1, 1000) AS g(i)`)
+		return err
+	})
+
+	checkValues := func(version string) {
+		t.Helper()
+		r.CheckQueryResults(t, `
+SELECT count(*) FROM d.cards
+WHERE decrypt_column(number) = id::STRING::BYTES AND (decrypt_column(cvv) = b'123' OR id % 2 = 1)`,
+			[][]string{{"1000"}},
+		)
+		rows := r.Query(t, `SELECT number FROM d.cards`)
+		defer rows.Close()
+		for rows.Next() {
+			var number []byte
+			if err := rows.Scan(&number); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Contains(number, []byte(version)) {
+				t.Fatalf("expected a data key wrapped by the master key %s, got %q", version, number)
+			}
+		}
+	}
+	checkValues("vault:v1:")
+
+	// After the rotation of the master key, the job re-encrypts the values
+	// under a data key wrapped by its new version.
+	vault.Rotate()
+	var jobID, rewritten int64
+	var status string
+	r.QueryRow(t, `ALTER TABLE d.cards ROTATE ENCRYPTION KEY (number, cvv)`).Scan(
+		&jobID, &status, &rewritten,
+	)
+	if status != string(jobs.StatusSucceeded) {
+		t.Fatalf("expected job status %s, got %s", jobs.StatusSucceeded, status)
+	}
+	if rewritten != 1500 {
+		t.Fatalf("expected 1500 values re-encrypted, got %d", rewritten)
+	}
+	checkValues("vault:v2:")
+}
@@ -0,0 +1,116 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// maxConnections limits the client connections a node accepts. Connections
+// of root are not limited and don't count towards the limit, so that an
+// administrator can always connect to a node saturated by other users.
+var maxConnections = settings.RegisterIntSetting(
+	"server.max_connections",
+	"maximum number of SQL connections of users other than root per node (0 for no limit)",
+	0,
+)
+
+// maxConnectionsPerUser is the connection limit of the users without a
+// CONNECTION LIMIT option.
+var maxConnectionsPerUser = settings.RegisterIntSetting(
+	"server.max_connections_per_user",
+	"maximum number of SQL connections of a user per node, unless set for the user "+
+		"with CONNECTION LIMIT (0 for no limit)",
+	0,
+)
+
+// roleOptionConnectionLimit is the system.role_options option holding the
+// limit set with CONNECTION LIMIT. As in PostgreSQL, -1 means no limit.
+const roleOptionConnectionLimit = "CONNECTION LIMIT"
+
+func validateConnectionLimit(limit int64) error {
+	if limit < -1 {
+		return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"invalid connection limit: %d", limit)
+	}
+	return nil
+}
+
+// ReserveConnection counts a new client connection of the given user, unless
+// it would exceed server.max_connections or the connection limit of the
+// user: userLimit, the limit set with CONNECTION LIMIT, or
+// server.max_connections_per_user if it is nil. Root is never refused. The
+// returned function must be called once the connection is closed.
+func (r *SessionRegistry) ReserveConnection(
+	sv *settings.Values, user string, userLimit *int64,
+) (func(), error) {
+	root := user == security.RootUser
+	// Like CONNECTION LIMIT, limit is -1 when there is no limit; the setting
+	// uses 0 instead.
+	limit := int64(-1)
+	if userLimit != nil {
+		limit = *userLimit
+	} else if l := maxConnectionsPerUser.Get(sv); l > 0 {
+		limit = l
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	if !root {
+		if max := maxConnections.Get(sv); max > 0 && r.numConns >= max {
+			return nil, pgerror.NewErrorf(pgerror.CodeTooManyConnectionsError,
+				"sorry, too many clients already")
+		}
+		if limit >= 0 && r.conns[user] >= limit {
+			return nil, pgerror.NewErrorf(pgerror.CodeTooManyConnectionsError,
+				"too many connections for user %s", user)
+		}
+		r.numConns++
+	}
+	r.conns[user]++
+	return func() {
+		r.Lock()
+		defer r.Unlock()
+		if !root {
+			r.numConns--
+		}
+		if r.conns[user]--; r.conns[user] == 0 {
+			delete(r.conns, user)
+		}
+	}, nil
+}
+
+// userConnections is the number of client connections of a user.
+type userConnections struct {
+	user  string
+	count int64
+}
+
+// connectionCounts returns the number of client connections of each user
+// with connections to this node, ordered by user.
+func (r *SessionRegistry) connectionCounts() []userConnections {
+	r.Lock()
+	counts := make([]userConnections, 0, len(r.conns))
+	for user, count := range r.conns {
+		counts = append(counts, userConnections{user: user, count: count})
+	}
+	r.Unlock()
+	sort.Slice(counts, func(i, j int) bool { return counts[i].user < counts[j].user })
+	return counts
+}
@@ -17,12 +17,13 @@ package sql
 import (
 	"bytes"
 	"fmt"
-	"io"
+	"strings"
 	"unsafe"
 
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -37,11 +38,14 @@ import (
 // statements before the COPY has finished will result in an error.
 //
 // The copyNode hold two buffers: raw bytes and rows. All copy data is
-// appended to the byte buffer. Afterward, rows are extracted from the
-// bytes. When the number of rows has reached some limit (whose purpose is
+// appended to the byte buffer. Afterward, complete rows are extracted from
+// the bytes; an incomplete row stays in the buffer until the rest of it
+// arrives. When the number of rows has reached some limit (whose purpose is
 // to increase performance by batching inserts), they are inserted with an
 // insertNode. A CopyDone message will flush and insert all remaining data.
 //
+// Both the text and the CSV formats are supported.
+//
 // See: https://www.postgresql.org/docs/9.5/static/sql-copy.html
 type copyNode struct {
 	table         tree.TableExpr
@@ -50,6 +54,39 @@ type copyNode struct {
 	buf           bytes.Buffer
 	rows          []*tree.Tuple
 	rowsMemAcc    mon.BoundAccount
+
+	opts CopyOptions
+	// skipHeader is set until the header line of a CSV COPY has been skipped.
+	skipHeader bool
+}
+
+// CopyFormat is the format of the data of a COPY.
+type CopyFormat int
+
+const (
+	// CopyFormatText is PostgreSQL's text format: fields are separated by tabs
+	// and special characters are escaped with backslashes.
+	CopyFormatText CopyFormat = iota
+	// CopyFormatCSV is the comma-separated values format.
+	CopyFormatCSV
+)
+
+// CopyOptions describes the format of the data of a COPY FROM or COPY TO.
+type CopyOptions struct {
+	Format    CopyFormat
+	Delimiter byte
+	Null      string
+	// Quote and Escape are only used by the CSV format.
+	Quote  byte
+	Escape byte
+	// Header is set if the first line of CSV data holds the column names.
+	Header bool
+}
+
+// copyField is a single field of a COPY row.
+type copyField struct {
+	val  string
+	null bool
 }
 
 func (*copyNode) Values() tree.Datums          { return nil }
@@ -83,10 +120,130 @@ func (p *planner) CopyFrom(ctx context.Context, n *tree.CopyFrom) (planNode, err
 	for i, c := range cols {
 		cn.resultColumns[i] = sqlbase.ResultColumn{Typ: c.Type.ToDatumType()}
 	}
+	if cn.opts, err = ParseCopyOptions(n.Options); err != nil {
+		return nil, err
+	}
+	cn.skipHeader = cn.opts.Header
 	cn.rowsMemAcc = p.session.mon.MakeBoundAccount()
 	return cn, nil
 }
 
+// ParseCopyOptions returns the data format described by the options of a
+// COPY statement. Options that were not specified take the default of the
+// format in use.
+func ParseCopyOptions(opts tree.KVOptions) (CopyOptions, error) {
+	var res CopyOptions
+	var delimiter, null, quote, escape *string
+	for _, o := range opts {
+		val, err := copyOptionValue(o)
+		if err != nil {
+			return res, err
+		}
+		switch strings.ToLower(string(o.Key)) {
+		case "format":
+			switch strings.ToLower(val) {
+			case "text":
+				res.Format = CopyFormatText
+			case "csv":
+				res.Format = CopyFormatCSV
+			case "binary":
+				return res, pgerror.Unimplemented("copy.binary", "binary format for COPY is not supported")
+			default:
+				return res, fmt.Errorf("COPY format %q not recognized", val)
+			}
+		case "csv":
+			// Pre-9.0 syntax for FORMAT csv.
+			res.Format = CopyFormatCSV
+		case "binary":
+			return res, pgerror.Unimplemented("copy.binary", "binary format for COPY is not supported")
+		case "delimiter":
+			delimiter = &val
+		case "null":
+			null = &val
+		case "quote":
+			quote = &val
+		case "escape":
+			escape = &val
+		case "header":
+			if o.Value == nil {
+				res.Header = true
+				break
+			}
+			b, err := tree.ParseDBool(val)
+			if err != nil {
+				return res, fmt.Errorf("header requires a Boolean value")
+			}
+			res.Header = bool(*b)
+		default:
+			return res, fmt.Errorf("option %q not recognized", o.Key)
+		}
+	}
+
+	if res.Format == CopyFormatCSV {
+		res.Delimiter, res.Null, res.Quote = ',', "", '"'
+	} else {
+		res.Delimiter, res.Null = fieldDelim, nullString
+		if res.Header {
+			return res, fmt.Errorf("COPY HEADER available only in CSV mode")
+		}
+		if quote != nil {
+			return res, fmt.Errorf("COPY quote available only in CSV mode")
+		}
+		if escape != nil {
+			return res, fmt.Errorf("COPY escape available only in CSV mode")
+		}
+	}
+	var err error
+	if delimiter != nil {
+		if res.Delimiter, err = copySingleByte("delimiter", *delimiter); err != nil {
+			return res, err
+		}
+	}
+	if null != nil {
+		res.Null = *null
+	}
+	if quote != nil {
+		if res.Quote, err = copySingleByte("quote", *quote); err != nil {
+			return res, err
+		}
+	}
+	res.Escape = res.Quote
+	if escape != nil {
+		if res.Escape, err = copySingleByte("escape", *escape); err != nil {
+			return res, err
+		}
+	}
+	if res.Format == CopyFormatCSV && res.Delimiter == res.Quote {
+		return res, fmt.Errorf("COPY delimiter and quote must be different")
+	}
+	if res.Delimiter == lineDelim || res.Delimiter == '\r' {
+		return res, fmt.Errorf("COPY delimiter cannot be newline or carriage return")
+	}
+	return res, nil
+}
+
+// copyOptionValue returns the value of a COPY option as a string.
+func copyOptionValue(o tree.KVOption) (string, error) {
+	switch v := o.Value.(type) {
+	case nil:
+		return "", nil
+	case *tree.StrVal:
+		return v.RawString(), nil
+	case *tree.DBool:
+		return v.String(), nil
+	default:
+		return "", fmt.Errorf("invalid value for COPY option %q: %s", o.Key, o.Value)
+	}
+}
+
+// copySingleByte checks that the value of a COPY option is a single byte.
+func copySingleByte(opt, val string) (byte, error) {
+	if len(val) != 1 {
+		return 0, fmt.Errorf("COPY %s must be a single one-byte character", opt)
+	}
+	return val[0], nil
+}
+
 // Start implements the planNode interface.
 func (n *copyNode) Start(params runParams) error {
 	// Should never happen because the executor prevents non-COPY messages during
@@ -112,10 +269,7 @@ const (
 
 	nullString = `\N`
 	lineDelim  = '\n'
-)
-
-var (
-	fieldDelim = []byte{'\t'}
+	fieldDelim = '\t'
 )
 
 // ProcessCopyData appends data to the planner's internal COPY state as
@@ -127,7 +281,7 @@ func (s *Session) ProcessCopyData(
 	ctx context.Context, data string, msg copyMsg,
 ) (StatementList, error) {
 	cf := s.copyFrom
-	buf := cf.buf
+	buf := &cf.buf
 
 	evalCtx := s.evalCtx()
 
@@ -138,7 +292,7 @@ func (s *Session) ProcessCopyData(
 		var err error
 		// If there's a line in the buffer without \n at EOL, add it here.
 		if buf.Len() > 0 {
-			err = cf.addRow(ctx, buf.Bytes(), &evalCtx)
+			err = cf.processLines(ctx, &evalCtx, true /* final */)
 		}
 		return StatementList{{AST: CopyDataBlock{Done: true}}}, err
 	default:
@@ -146,55 +300,152 @@ func (s *Session) ProcessCopyData(
 	}
 
 	buf.WriteString(data)
+	if err := cf.processLines(ctx, &evalCtx, false /* final */); err != nil {
+		return nil, err
+	}
+	return StatementList{{AST: CopyDataBlock{}}}, nil
+}
+
+// processLines adds a row for every complete line in the buffer. Unless final
+// is set, a trailing incomplete line is left in the buffer.
+func (n *copyNode) processLines(
+	ctx context.Context, evalCtx *tree.EvalContext, final bool,
+) error {
+	buf := &n.buf
 	for buf.Len() > 0 {
-		line, err := buf.ReadBytes(lineDelim)
-		if err != nil {
-			if err != io.EOF {
-				return nil, err
-			}
-		} else {
+		var line []byte
+		if i := n.lineEnd(buf.Bytes()); i >= 0 {
 			// Remove lineDelim from end.
+			line = buf.Next(i + 1)[:i]
+		} else if final {
+			line = buf.Next(buf.Len())
+		} else {
+			break
+		}
+		// Remove a single '\r' at EOL, if present.
+		if len(line) > 0 && line[len(line)-1] == '\r' {
 			line = line[:len(line)-1]
-			// Remove a single '\r' at EOL, if present.
-			if len(line) > 0 && line[len(line)-1] == '\r' {
-				line = line[:len(line)-1]
-			}
 		}
-		if buf.Len() == 0 && bytes.Equal(line, []byte(`\.`)) {
+		if bytes.Equal(line, []byte(`\.`)) {
+			// End-of-data marker; anything following it is ignored.
+			buf.Reset()
 			break
 		}
-		if err := cf.addRow(ctx, line, &evalCtx); err != nil {
-			return nil, err
+		if n.skipHeader {
+			n.skipHeader = false
+			continue
+		}
+		if err := n.addRow(ctx, line, evalCtx); err != nil {
+			return err
 		}
 	}
-	return StatementList{{AST: CopyDataBlock{}}}, nil
+	return nil
 }
 
-func (n *copyNode) addRow(ctx context.Context, line []byte, evalCtx *tree.EvalContext) error {
-	var err error
-	parts := bytes.Split(line, fieldDelim)
-	if len(parts) != len(n.resultColumns) {
-		return fmt.Errorf("expected %d values, got %d", len(n.resultColumns), len(parts))
+// lineEnd returns the index of the newline that terminates the first line in
+// data, or -1 if data doesn't contain a complete line. In the CSV format,
+// newlines within quoted fields are part of the data.
+func (n *copyNode) lineEnd(data []byte) int {
+	if n.opts.Format != CopyFormatCSV {
+		return bytes.IndexByte(data, lineDelim)
 	}
-	exprs := make(tree.Exprs, len(parts))
+	inQuotes := false
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case inQuotes && c == n.opts.Escape && i+1 < len(data) &&
+			(data[i+1] == n.opts.Quote || data[i+1] == n.opts.Escape):
+			i++
+		case c == n.opts.Quote:
+			inQuotes = !inQuotes
+		case c == lineDelim && !inQuotes:
+			return i
+		}
+	}
+	return -1
+}
+
+// splitText splits a line in the text format into its fields.
+func (n *copyNode) splitText(line []byte) []copyField {
+	parts := bytes.Split(line, []byte{n.opts.Delimiter})
+	fields := make([]copyField, len(parts))
 	for i, part := range parts {
 		s := string(part)
-		if s == nullString {
+		fields[i] = copyField{val: s, null: s == n.opts.Null}
+	}
+	return fields
+}
+
+// splitCSV splits a line in the CSV format into its fields. Only unquoted
+// fields can match the null string, so that "" can be used for the empty
+// string when the default null string is used.
+func (n *copyNode) splitCSV(line []byte) ([]copyField, error) {
+	var fields []copyField
+	var field bytes.Buffer
+	quoted, inQuotes := false, false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case inQuotes && c == n.opts.Escape && i+1 < len(line) &&
+			(line[i+1] == n.opts.Quote || line[i+1] == n.opts.Escape):
+			i++
+			field.WriteByte(line[i])
+		case inQuotes && c == n.opts.Quote:
+			inQuotes = false
+		case inQuotes:
+			field.WriteByte(c)
+		case c == n.opts.Quote:
+			quoted, inQuotes = true, true
+		case c == n.opts.Delimiter:
+			s := field.String()
+			fields = append(fields, copyField{val: s, null: !quoted && s == n.opts.Null})
+			field.Reset()
+			quoted = false
+		default:
+			field.WriteByte(c)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated CSV quoted field")
+	}
+	s := field.String()
+	return append(fields, copyField{val: s, null: !quoted && s == n.opts.Null}), nil
+}
+
+func (n *copyNode) addRow(ctx context.Context, line []byte, evalCtx *tree.EvalContext) error {
+	var fields []copyField
+	if n.opts.Format == CopyFormatCSV {
+		var err error
+		if fields, err = n.splitCSV(line); err != nil {
+			return err
+		}
+	} else {
+		fields = n.splitText(line)
+	}
+	if len(fields) != len(n.resultColumns) {
+		return fmt.Errorf("expected %d values, got %d", len(n.resultColumns), len(fields))
+	}
+	exprs := make(tree.Exprs, len(fields))
+	for i, field := range fields {
+		if field.null {
 			exprs[i] = tree.DNull
 			continue
 		}
-		switch t := n.resultColumns[i].Typ; t {
-		case types.Bytes,
-			types.Date,
-			types.Interval,
-			types.INet,
-			types.String,
-			types.Timestamp,
-			types.TimestampTZ,
-			types.UUID:
-			s, err = decodeCopy(s)
-			if err != nil {
-				return err
+		s := field.val
+		// Only the text format uses backslash escapes.
+		if n.opts.Format == CopyFormatText {
+			switch t := n.resultColumns[i].Typ; t {
+			case types.Bytes,
+				types.Date,
+				types.Interval,
+				types.INet,
+				types.String,
+				types.Timestamp,
+				types.TimestampTZ,
+				types.UUID:
+				var err error
+				s, err = decodeCopy(s)
+				if err != nil {
+					return err
+				}
 			}
 		}
 		d, err := parser.ParseStringAs(n.resultColumns[i].Typ, s, evalCtx)
@@ -311,6 +562,36 @@ var decodeMap = map[byte]byte{
 	'\\': '\\',
 }
 
+// AppendCopyTextField appends a field in the COPY text format to buf,
+// escaping backslashes, control characters and the delimiter. It is the
+// inverse of decodeCopy.
+func AppendCopyTextField(buf []byte, field []byte, delimiter byte) []byte {
+	for _, ch := range field {
+		switch ch {
+		case '\\':
+			buf = append(buf, '\\', '\\')
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		case '\v':
+			buf = append(buf, '\\', 'v')
+		default:
+			if ch == delimiter {
+				buf = append(buf, '\\')
+			}
+			buf = append(buf, ch)
+		}
+	}
+	return buf
+}
+
 // CopyData is the statement type after a block of COPY data has been
 // received. There may be additional rows ready to insert. If so, return an
 // insertNode, otherwise emptyNode.
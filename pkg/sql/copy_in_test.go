@@ -306,6 +306,103 @@ func TestCopyError(t *testing.T) {
 	}
 }
 
+// TestCopyCSV verifies that rows in the CSV format are inserted, including
+// rows split across several CopyData messages.
+func TestCopyCSV(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, _ := tests.CreateTestServerParams()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := db.Exec(`
+		CREATE DATABASE d;
+		SET DATABASE = d;
+		CREATE TABLE t (
+			i INT PRIMARY KEY,
+			s STRING,
+			n INT NULL
+		);
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	txn, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stmt, err := txn.Prepare(`COPY t FROM STDIN WITH (FORMAT csv, HEADER)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// lib/pq sends each argument as a line of COPY data, so CSV lines can be
+	// passed as single strings as long as they don't need text escaping.
+	lines := []string{
+		`i,s,n`,
+		`1,"hello, world",`,
+		`2,"",3`,
+		`3,"say ""hi""",4`,
+	}
+	// lib/pq buffers 64KiB before sending a message, so these rows end up split
+	// across messages.
+	const numRows = 5000
+	for i := 0; i < numRows; i++ {
+		lines = append(lines, fmt.Sprintf(`%d,"row %d, some padding to make the line longer",%d`, i+10, i, i))
+	}
+	for _, line := range lines {
+		if _, err := stmt.Exec(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := stmt.Exec(); err != nil {
+		t.Fatal(err)
+	}
+	if err := stmt.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.Query(`SELECT i, s, n FROM t WHERE i < 10 ORDER BY i`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var results []string
+	for rows.Next() {
+		var i int
+		var s string
+		var n *int
+		if err := rows.Scan(&i, &s, &n); err != nil {
+			t.Fatal(err)
+		}
+		r := fmt.Sprintf("%d|%s|", i, s)
+		if n != nil {
+			r += strconv.Itoa(*n)
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{`1|hello, world|`, `2||3`, `3|say "hi"|4`}
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("expected %q, got %q", expected, results)
+	}
+
+	var count, sum int
+	if err := db.QueryRow(`SELECT COUNT(*), SUM(n) FROM t WHERE i >= 10`).Scan(&count, &sum); err != nil {
+		t.Fatal(err)
+	}
+	if count != numRows || sum != numRows*(numRows-1)/2 {
+		t.Fatalf("expected %d rows with sum %d, got %d rows with sum %d",
+			numRows, numRows*(numRows-1)/2, count, sum)
+	}
+}
+
 // TestCopyOne verifies that only one COPY can run at once.
 func TestCopyOne(t *testing.T) {
 	defer leaktest.AfterTest(t)()
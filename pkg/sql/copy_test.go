@@ -15,8 +15,11 @@
 package sql
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 )
 
@@ -88,3 +91,70 @@ func TestDecodeCopy(t *testing.T) {
 		}
 	}
 }
+
+func TestCopyCSVLines(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		opts   tree.KVOptions
+		data   string
+		fields []copyField
+	}{
+		{
+			data:   "a,b,c\nd",
+			fields: []copyField{{val: "a"}, {val: "b"}, {val: "c"}},
+		},
+		{
+			data:   `"a` + "\n" + `b","",` + "\n",
+			fields: []copyField{{val: "a\nb"}, {val: ""}, {null: true}},
+		},
+		{
+			data:   `"a""b",x"y,z"` + "\n",
+			fields: []copyField{{val: `a"b`}, {val: "xy,z"}},
+		},
+		{
+			opts: tree.KVOptions{
+				{Key: "delimiter", Value: tree.NewStrVal(";")},
+				{Key: "null", Value: tree.NewStrVal("NULL")},
+				{Key: "quote", Value: tree.NewStrVal("'")},
+				{Key: "escape", Value: tree.NewStrVal(`\`)},
+			},
+			data:   `'a;\'b';NULL;'NULL';` + "\n",
+			fields: []copyField{{val: "a;'b"}, {val: "NULL", null: true}, {val: "NULL"}, {val: ""}},
+		},
+	}
+	for _, tc := range testCases {
+		opts, err := ParseCopyOptions(
+			append(tree.KVOptions{{Key: "format", Value: tree.NewStrVal("csv")}}, tc.opts...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := &copyNode{opts: opts}
+		end := n.lineEnd([]byte(tc.data))
+		if end < 0 {
+			t.Fatalf("%q: no complete line found", tc.data)
+		}
+		fields, err := n.splitCSV([]byte(tc.data[:end]))
+		if err != nil {
+			t.Fatalf("%q: %s", tc.data, err)
+		}
+		if !reflect.DeepEqual(fields, tc.fields) {
+			t.Errorf("%q: expected %+v, got %+v", tc.data, tc.fields, fields)
+		}
+	}
+
+	opts, err := ParseCopyOptions(tree.KVOptions{{Key: "csv"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := &copyNode{opts: opts}
+	if end := n.lineEnd([]byte(`1,"unterminated` + "\n")); end != -1 {
+		t.Errorf("expected an incomplete line, got end %d", end)
+	}
+	if _, err := n.splitCSV([]byte(`1,"unterminated`)); !testutils.IsError(err, "unterminated CSV quoted field") {
+		t.Errorf("expected unterminated field error, got %v", err)
+	}
+	if _, err := ParseCopyOptions(tree.KVOptions{{Key: "header"}}); !testutils.IsError(err, "COPY HEADER available only in CSV mode") {
+		t.Errorf("expected header error, got %v", err)
+	}
+}
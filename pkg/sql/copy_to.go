@@ -0,0 +1,51 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// CopyTo plans a COPY TO STDOUT statement. The plan is simply that of the
+// query being copied, or of a SELECT of the table's columns; its rows are
+// encoded as COPY data by the connection, according to the statement's
+// options, as they are produced.
+// Privileges: SELECT on table.
+func (p *planner) CopyTo(ctx context.Context, n *tree.CopyTo) (planNode, error) {
+	// Validate the options now, so that invalid options are reported before
+	// the client has been switched to COPY mode.
+	if _, err := ParseCopyOptions(n.Options); err != nil {
+		return nil, err
+	}
+	sel := n.Select
+	if sel == nil {
+		exprs := tree.SelectExprs{tree.StarSelectExpr()}
+		if len(n.Columns) > 0 {
+			exprs = make(tree.SelectExprs, len(n.Columns))
+			for i, c := range n.Columns {
+				exprs[i] = tree.SelectExpr{Expr: c}
+			}
+		}
+		sel = &tree.Select{
+			Select: &tree.SelectClause{
+				Exprs: exprs,
+				From:  &tree.From{Tables: tree.TableExprs{&n.Table}},
+			},
+		}
+	}
+	return p.newPlan(ctx, sel, nil /* desiredTypes */)
+}
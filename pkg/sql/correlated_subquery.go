@@ -0,0 +1,198 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// correlatedSubqueryCacheSize is the maximum number of results of a
+// correlated sub-query that are cached, per distinct combination of values of
+// the outer columns it refers to.
+const correlatedSubqueryCacheSize = 1000
+
+// correlationScope describes the query surrounding a sub-query, for the
+// purpose of resolving references to its columns from within the sub-query.
+//
+// A correlated sub-query is planned once during the planning of the
+// surrounding query, with references to outer columns resolved to
+// outerColumnRefs; this determines which outer columns are needed (cols) and
+// the result type. Then, for every row of the surrounding query, it is
+// planned again with values set, in which case the references are resolved
+// to the values of the current row. This way, the outer values appear as
+// constants to the sub-query and can be used for index selection.
+type correlationScope struct {
+	sources multiSourceInfo
+	// cols are the indexes (in sources) of the outer columns that are referred
+	// to by the sub-query.
+	cols []int
+	// values, if set, are the values of the outer columns in cols.
+	values tree.Datums
+}
+
+// resolve resolves a column name that was not found in the sub-query's own
+// sources. It returns false if the column is not a column of the
+// surrounding query either.
+func (s *correlationScope) resolve(c *tree.ColumnItem) (tree.Expr, bool) {
+	srcIdx, colIdx, err := s.sources.findColumn(c)
+	if err != nil {
+		return nil, false
+	}
+	col := colIdx
+	for _, src := range s.sources[:srcIdx] {
+		col += len(src.sourceColumns)
+	}
+	pos := -1
+	for i, existing := range s.cols {
+		if existing == col {
+			pos = i
+			break
+		}
+	}
+	if s.values != nil {
+		if pos == -1 {
+			// The set of outer columns was determined during the initial
+			// planning and must not change.
+			return nil, false
+		}
+		return s.values[pos], true
+	}
+	if pos == -1 {
+		s.cols = append(s.cols, col)
+	}
+	return &outerColumnRef{name: c, typ: s.sources[srcIdx].sourceColumns[colIdx].Typ}, true
+}
+
+// outerColumnRef is a reference from a correlated sub-query to a column of
+// the surrounding query. It only appears in the plan built during the
+// initial planning of the sub-query, which is never run.
+type outerColumnRef struct {
+	name *tree.ColumnItem
+	typ  types.T
+}
+
+var _ tree.TypedExpr = &outerColumnRef{}
+var _ tree.VariableExpr = &outerColumnRef{}
+
+// Format implements the NodeFormatter interface.
+func (r *outerColumnRef) Format(buf *bytes.Buffer, f tree.FmtFlags) {
+	tree.FormatNode(buf, f, r.name)
+}
+
+func (r *outerColumnRef) String() string { return tree.AsString(r) }
+
+// Walk implements the Expr interface.
+func (r *outerColumnRef) Walk(_ tree.Visitor) tree.Expr { return r }
+
+// TypeCheck implements the Expr interface.
+func (r *outerColumnRef) TypeCheck(_ *tree.SemaContext, _ types.T) (tree.TypedExpr, error) {
+	return r, nil
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (r *outerColumnRef) ResolvedType() types.T { return r.typ }
+
+// Eval implements the TypedExpr interface.
+func (r *outerColumnRef) Eval(_ *tree.EvalContext) (tree.Datum, error) {
+	return nil, errors.Errorf("outer column reference %s cannot be evaluated", r)
+}
+
+// Variable implements the VariableExpr interface.
+func (*outerColumnRef) Variable() {}
+
+// subqueryCorrelation holds the state needed to evaluate a correlated
+// sub-query for the rows of the surrounding query.
+type subqueryCorrelation struct {
+	p     *planner
+	scope *correlationScope
+	// outerVars are the expressions, in the surrounding query, for the outer
+	// columns referred to by the sub-query (one per scope.cols).
+	outerVars []tree.TypedExpr
+	// cache maps the encoded values of outerVars to the result of the
+	// sub-query for these values.
+	cache map[string]tree.Datum
+}
+
+// eval evaluates the sub-query s for the current row of the surrounding
+// query. The sub-query is planned and run for every distinct combination of
+// values of the outer columns; the results are cached so that repeated values
+// do not cause the sub-query to run again.
+func (c *subqueryCorrelation) eval(evalCtx *tree.EvalContext, s *subquery) (tree.Datum, error) {
+	values := make(tree.Datums, len(c.outerVars))
+	var key []byte
+	cacheable := true
+	for i, e := range c.outerVars {
+		d, err := e.Eval(evalCtx)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = d
+		if cacheable {
+			if key, err = sqlbase.EncodeDatum(key, d); err != nil {
+				// Some types cannot be encoded; don't cache their results.
+				cacheable = false
+			}
+		}
+	}
+	if cacheable {
+		if result, ok := c.cache[string(key)]; ok {
+			return result, nil
+		}
+	}
+
+	p := c.p
+	ctx := evalCtx.Ctx()
+	// Running the sub-query changes the IndexedVarHelper of the evaluation
+	// context; restore it for the rest of the surrounding expression.
+	defer func(h *tree.IndexedVarHelper) { evalCtx.IVarHelper = h }(evalCtx.IVarHelper)
+
+	prevScope := p.correlationScope
+	p.correlationScope = &correlationScope{
+		sources: c.scope.sources, cols: c.scope.cols, values: values,
+	}
+	plan, err := p.newPlan(ctx, s.subquery.Select, nil)
+	p.correlationScope = prevScope
+	if err != nil {
+		return nil, err
+	}
+
+	sq := &subquery{subquery: s.subquery, execMode: s.execMode, typ: s.typ, plan: plan}
+	init := subqueryInitializer{p: p}
+	if err := init.subqueryNode(ctx, sq); err != nil {
+		sq.plan.Close(ctx)
+		return nil, err
+	}
+	run := subqueryPlanVisitor{p: p}
+	if err := run.subqueryNode(ctx, sq); err != nil {
+		if sq.plan != nil {
+			sq.plan.Close(ctx)
+		}
+		return nil, err
+	}
+
+	if cacheable {
+		if len(c.cache) >= correlatedSubqueryCacheSize {
+			c.cache = make(map[string]tree.Datum)
+		}
+		c.cache[string(key)] = sq.result
+	}
+	return sq.result, nil
+}
@@ -0,0 +1,241 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// countRangeStatsMode controls whether an unfiltered COUNT(*) over a table
+// may be answered from the MVCC statistics of the ranges holding the table
+// instead of scanning every row.
+//
+// The statistics always describe the latest state of the ranges, so a count
+// obtained from them does not participate in the isolation of the
+// transaction: it may include the effects of transactions that committed
+// after the reading transaction started. This is why the optimization is
+// opt-in.
+type countRangeStatsMode int64
+
+const (
+	// countRangeStatsOff means that COUNT(*) always scans the table.
+	countRangeStatsOff countRangeStatsMode = iota
+	// countRangeStatsExact means that the range statistics are used only when
+	// they yield an exact row count; otherwise the table is scanned.
+	countRangeStatsExact
+	// countRangeStatsEstimate means that the range statistics are used even
+	// when they only yield an estimate of the row count.
+	countRangeStatsEstimate
+)
+
+func (m countRangeStatsMode) String() string {
+	switch m {
+	case countRangeStatsOff:
+		return "off"
+	case countRangeStatsExact:
+		return "exact"
+	case countRangeStatsEstimate:
+		return "estimate"
+	default:
+		return fmt.Sprintf("invalid (%d)", m)
+	}
+}
+
+// maybeCountFromRangeStats checks whether the groupNode computes nothing but
+// COUNT(*) over all the rows of a single table and, if so and the session
+// allows it, arranges for the count to be derived from range statistics when
+// the plan is expanded. The groupNode is kept as a fallback for when the
+// statistics cannot provide a count that is good enough.
+func (p *planner) maybeCountFromRangeStats(
+	parsed *tree.SelectClause, r *renderNode, group *groupNode, result planNode,
+) {
+	mode := p.session.CountFromRangeStats
+	if mode == countRangeStatsOff || p.asOfSystemTime {
+		// The range statistics only describe the present.
+		return
+	}
+	if parsed.Where != nil || len(parsed.GroupBy) > 0 || parsed.Having != nil {
+		return
+	}
+	postRender, ok := result.(*renderNode)
+	if !ok || postRender.source.plan != group || len(group.funcs) != 1 {
+		return
+	}
+	f := group.funcs[0]
+	fn, ok := f.expr.(*tree.FuncExpr)
+	if !ok || f.hasFilter || fn.Type == tree.DistinctFuncType ||
+		!strings.EqualFold(fn.Func.FunctionReference.String(), "count_rows") {
+		return
+	}
+	scan, ok := r.source.plan.(*scanNode)
+	if !ok || scan.desc.IsVirtualTable() || scan.desc.IsInterleaved() {
+		return
+	}
+	desc := scan.desc
+	postRender.source.plan = &delayedNode{
+		name:    fmt.Sprintf("count(*) from range statistics of %s", desc.Name),
+		columns: group.columns,
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			count, exact, err := p.rangeStatsRowCount(ctx, desc)
+			if err != nil {
+				log.VEventf(ctx, 2, "cannot count %s from range statistics: %v", desc.Name, err)
+				return group, nil
+			}
+			if !exact && mode != countRangeStatsEstimate {
+				log.VEventf(ctx, 2, "range statistics of %s only yield an estimate; scanning", desc.Name)
+				return group, nil
+			}
+			if exact {
+				log.VEventf(ctx, 2, "counted %d rows in %s from range statistics", count, desc.Name)
+			} else {
+				log.VEventf(ctx, 2, "estimated %d rows in %s from range statistics", count, desc.Name)
+			}
+			group.Close(ctx)
+			v := p.newContainerValuesNode(group.columns, 1)
+			if _, err := v.rows.AddRow(ctx, tree.Datums{tree.NewDInt(tree.DInt(count))}); err != nil {
+				v.Close(ctx)
+				return nil, err
+			}
+			return v, nil
+		},
+	}
+}
+
+// rangeStatsRowCount computes the number of rows in the given table by
+// summing the live key counts of the ranges spanning it. The second return
+// value is true if the count is exact, which requires the table to store
+// exactly one key per row (i.e. to have a single column family and no
+// secondary indexes) and the statistics of every range to have been read
+// from its lease holder and to contain neither estimates nor intents.
+// Otherwise the live key count is divided by the number of indexes of the
+// table, which assumes one key per row in each of them.
+//
+// An error is returned if the statistics of some range cannot be obtained
+// or describe data outside of the table.
+func (p *planner) rangeStatsRowCount(
+	ctx context.Context, desc *sqlbase.TableDescriptor,
+) (int64, bool, error) {
+	stats, err := p.tableRangeStats(ctx, desc)
+	if err != nil {
+		return 0, false, err
+	}
+	count, exact := stats.rowCount(desc)
+	return count, exact, nil
+}
+
+// tableRangeStats are the MVCC statistics of the ranges spanning a table.
+type tableRangeStats struct {
+	stats      enginepb.MVCCStats
+	rangeCount int64
+	// fromLeaseHolders is set if the statistics of every range were read
+	// from its lease holder.
+	fromLeaseHolders bool
+}
+
+// rowCount derives the number of rows in the table from its statistics, as
+// described in rangeStatsRowCount.
+func (s *tableRangeStats) rowCount(desc *sqlbase.TableDescriptor) (int64, bool) {
+	if len(desc.Families) == 1 && len(desc.Indexes) == 0 && len(desc.Mutations) == 0 &&
+		s.fromLeaseHolders && !s.stats.ContainsEstimates && s.stats.IntentCount == 0 {
+		return s.stats.LiveCount, true
+	}
+	return s.stats.LiveCount / int64(1+len(desc.Indexes)), false
+}
+
+// tableRangeStats sums the MVCC statistics of the ranges spanning the given
+// table, which are read from the lease holder of each range when it is
+// known. An error is returned if the statistics of some range cannot be
+// obtained or describe data outside of the table.
+func (p *planner) tableRangeStats(
+	ctx context.Context, desc *sqlbase.TableDescriptor,
+) (tableRangeStats, error) {
+	var res tableRangeStats
+	statusServer := p.ExecCfg().StatusServer
+	if statusServer == nil {
+		return res, errors.New("range statistics are not available")
+	}
+	tableSpan := desc.TableSpan()
+	startKey, err := keys.Addr(tableSpan.Key)
+	if err != nil {
+		return res, err
+	}
+	endKey, err := keys.Addr(tableSpan.EndKey)
+	if err != nil {
+		return res, err
+	}
+	rspan := roachpb.RSpan{Key: startKey, EndKey: endKey}
+
+	res.fromLeaseHolders = true
+	ri := kv.NewRangeIterator(p.ExecCfg().DistSender)
+	for ri.Seek(ctx, rspan.Key, kv.Ascending); ; ri.Next(ctx) {
+		if !ri.Valid() {
+			return res, ri.Error().GoError()
+		}
+		rangeDesc := ri.Desc()
+		if rangeDesc.StartKey.Less(rspan.Key) || rspan.EndKey.Less(rangeDesc.EndKey) {
+			return res, errors.Errorf("range %d extends beyond the table", rangeDesc.RangeID)
+		}
+		if len(rangeDesc.Replicas) == 0 {
+			return res, errors.Errorf("range %d has no replicas", rangeDesc.RangeID)
+		}
+
+		// Prefer the lease holder, whose statistics are up to date. Any
+		// other replica may be lagging behind.
+		replica := rangeDesc.Replicas[0]
+		fromLeaseHolder := len(rangeDesc.Replicas) == 1
+		if storeID, ok := ri.LeaseHolderStoreID(ctx); ok {
+			for _, r := range rangeDesc.Replicas {
+				if r.StoreID == storeID {
+					replica = r
+					fromLeaseHolder = true
+					break
+				}
+			}
+		}
+		resp, err := statusServer.SpanStats(ctx, &serverpb.SpanStatsRequest{
+			NodeID:   replica.NodeID.String(),
+			StartKey: rangeDesc.StartKey,
+			EndKey:   rangeDesc.EndKey,
+		})
+		if err != nil {
+			return res, err
+		}
+		if resp.RangeCount != 1 {
+			return res, errors.Errorf(
+				"range %d not found on n%d", rangeDesc.RangeID, replica.NodeID)
+		}
+		res.stats.Add(resp.TotalStats)
+		res.rangeCount++
+		res.fromLeaseHolders = res.fromLeaseHolders && fromLeaseHolder
+
+		if !ri.NeedAnother(rspan) {
+			break
+		}
+	}
+	return res, nil
+}
@@ -16,10 +16,12 @@ package sql
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,20 +31,26 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
 const crdbInternalName = "crdb_internal"
@@ -56,21 +64,35 @@ var crdbInternal = virtualSchema{
 		crdbInternalClusterQueriesTable,
 		crdbInternalClusterSessionsTable,
 		crdbInternalClusterSettingsTable,
+		crdbInternalContentionEventsTable,
 		crdbInternalCreateStmtsTable,
+		crdbInternalEventLogTable,
+		crdbInternalFeatureUsageTable,
 		crdbInternalForwardDependenciesTable,
+		crdbInternalGossipLivenessTable,
+		crdbInternalGossipNodesTable,
 		crdbInternalIndexColumnsTable,
+		crdbInternalIndexContentionStatisticsTable,
+		crdbInternalIndexUsageStatisticsTable,
 		crdbInternalJobsTable,
+		crdbInternalKVNodeStatusTable,
+		crdbInternalKVStoreStatusTable,
 		crdbInternalLeasesTable,
+		crdbInternalLocalConnectionsTable,
 		crdbInternalLocalQueriesTable,
 		crdbInternalLocalSessionsTable,
+		crdbInternalPartitionsTable,
 		crdbInternalRangesTable,
 		crdbInternalRuntimeInfoTable,
 		crdbInternalSchemaChangesTable,
+		crdbInternalScrubErrorsTable,
 		crdbInternalSessionTraceTable,
 		crdbInternalSessionVariablesTable,
+		crdbInternalStmtFingerprintStatsTable,
 		crdbInternalStmtStatsTable,
 		crdbInternalTableColumnsTable,
 		crdbInternalTableIndexesTable,
+		crdbInternalTableRowStatisticsTable,
 		crdbInternalTablesTable,
 		crdbInternalZonesTable,
 	},
@@ -117,9 +139,9 @@ CREATE TABLE crdb_internal.node_runtime_info (
   value     STRING NOT NULL
 );
 `,
-	populate: func(_ context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
-		if p.session.User != security.RootUser {
-			return errors.New("only root can access the node runtime information")
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		if err := p.requireAdminRole(ctx, "access the node runtime information"); err != nil {
+			return err
 		}
 
 		node := p.ExecCfg().NodeInfo
@@ -200,7 +222,7 @@ CREATE TABLE crdb_internal.tables (
 		// include added and dropped descriptors.
 		for _, desc := range descs {
 			table, ok := desc.(*sqlbase.TableDescriptor)
-			if !ok || !userCanSeeDescriptor(table, p.session.User) {
+			if !ok || !p.userCanSeeDescriptor(table) {
 				continue
 			}
 			dbName := dbNames[table.GetParentID()]
@@ -261,7 +283,7 @@ CREATE TABLE crdb_internal.schema_changes (
 		// include added and dropped descriptors.
 		for _, desc := range descs {
 			table, ok := desc.(*sqlbase.TableDescriptor)
-			if !ok || !userCanSeeDescriptor(table, p.session.User) {
+			if !ok || !p.userCanSeeDescriptor(table) {
 				continue
 			}
 			tableID := tree.NewDInt(tree.DInt(int64(table.ID)))
@@ -310,10 +332,16 @@ CREATE TABLE crdb_internal.leases (
   deleted     BOOL NOT NULL
 );
 `,
-	populate: func(_ context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
 		leaseMgr := p.LeaseMgr()
 		nodeID := tree.NewDInt(tree.DInt(int64(leaseMgr.nodeID.Get())))
 
+		// Looking up the roles of the user acquires leases, so it must be done
+		// before locking the lease manager.
+		if _, err := p.inheritedRoles(ctx); err != nil {
+			return err
+		}
+
 		leaseMgr.mu.Lock()
 		defer leaseMgr.mu.Unlock()
 
@@ -327,7 +355,7 @@ CREATE TABLE crdb_internal.leases (
 				dropped := tree.MakeDBool(tree.DBool(ts.mu.dropped))
 
 				for _, state := range ts.mu.active.data {
-					if !userCanSeeDescriptor(&state.TableDescriptor, p.session.User) {
+					if !p.userCanSeeDescriptor(&state.TableDescriptor) {
 						continue
 					}
 
@@ -376,7 +404,20 @@ CREATE TABLE crdb_internal.jobs (
 );
 `,
 	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
-		p = makeInternalPlanner("jobs", p.txn, p.evalCtx.User, p.session.memMetrics)
+		// The members of the admin role read the jobs as root. The other users
+		// need privileges on system.jobs, and don't see the description and
+		// the error of the jobs of the other users, which contain the text of
+		// their statements.
+		isAdmin, err := p.hasAdminRole(ctx)
+		if err != nil {
+			return err
+		}
+		user := p.evalCtx.User
+		if isAdmin {
+			user = security.RootUser
+		}
+		currentUser := p.session.User
+		p = makeInternalPlanner("jobs", p.txn, user, p.session.memMetrics)
 		defer finishInternalPlanner(p)
 		rows, err := p.queryRows(ctx, `SELECT id, status, created, payload FROM system.jobs`)
 		if err != nil {
@@ -406,10 +447,15 @@ CREATE TABLE crdb_internal.jobs (
 			if payload.Lease != nil {
 				leaseNode = tree.NewDInt(tree.DInt(payload.Lease.NodeID))
 			}
+			description, errString := tree.DNull, tree.DNull
+			if isAdmin || payload.Username == currentUser {
+				description = tree.NewDString(payload.Description)
+				errString = tree.NewDString(payload.Error)
+			}
 			if err := addRow(
 				id,
 				tree.NewDString(payload.Type().String()),
-				tree.NewDString(payload.Description),
+				description,
 				tree.NewDString(payload.Username),
 				descriptorIDs,
 				status,
@@ -418,7 +464,7 @@ CREATE TABLE crdb_internal.jobs (
 				tsOrNull(payload.FinishedMicros),
 				tsOrNull(payload.ModifiedMicros),
 				tree.NewDFloat(tree.DFloat(payload.FractionCompleted)),
-				tree.NewDString(payload.Error),
+				errString,
 				leaseNode,
 			); err != nil {
 				return err
@@ -467,9 +513,12 @@ CREATE TABLE crdb_internal.node_statement_statistics (
   overhead_lat_var    FLOAT NOT NULL
 );
 `,
-	populate: func(_ context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
-		if p.session.User != security.RootUser {
-			return errors.New("only root can access application statistics")
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		// The users who aren't members of the admin role only see the
+		// statements anonymized, without their last error.
+		isAdmin, err := p.hasAdminRole(ctx)
+		if err != nil {
+			return err
 		}
 
 		sqlStats := p.session.sqlStats
@@ -510,19 +559,26 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 				if ok {
 					anonymized = tree.NewDString(anonStr)
 				}
+				key := stmtKey.stmt
+				if !isAdmin {
+					if !ok {
+						continue
+					}
+					key = anonStr
+				}
 
 				s := appStats.getStatsForStmt(stmtKey)
 
 				s.Lock()
 				errString := tree.DNull
-				if s.data.LastErr != "" {
+				if s.data.LastErr != "" && isAdmin {
 					errString = tree.NewDString(s.data.LastErr)
 				}
 				err := addRow(
 					nodeID,
 					tree.NewDString(appName),
 					tree.NewDString(stmtKey.flags()),
-					tree.NewDString(stmtKey.stmt),
+					tree.NewDString(key),
 					anonymized,
 					tree.NewDInt(tree.DInt(s.data.Count)),
 					tree.NewDInt(tree.DInt(s.data.FirstAttemptCount)),
@@ -551,6 +607,171 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 	},
 }
 
+// crdbInternalStmtFingerprintStatsTable exposes the statistics of the
+// statements executed on this node, merged by fingerprint. Unlike
+// node_statement_statistics, it doesn't separate the executions which failed
+// or were distributed, and reports percentiles of the service latency.
+var crdbInternalStmtFingerprintStatsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.statement_statistics (
+  node_id             INT NOT NULL,
+  application_name    STRING NOT NULL,
+  fingerprint         STRING NOT NULL,
+  fingerprint_id      STRING NOT NULL,
+  count               INT NOT NULL,
+  failure_count       INT NOT NULL,
+  distsql_count       INT NOT NULL,
+  first_attempt_count INT NOT NULL,
+  max_retries         INT NOT NULL,
+  last_error          STRING,
+  rows_avg            FLOAT NOT NULL,
+  service_lat_avg     FLOAT NOT NULL,
+  service_lat_p50     FLOAT NOT NULL,
+  service_lat_p90     FLOAT NOT NULL,
+  service_lat_p99     FLOAT NOT NULL,
+  service_lat_max     FLOAT NOT NULL
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		// As in node_statement_statistics, the users who aren't members of the
+		// admin role only see the anonymized fingerprints, without the last
+		// error.
+		isAdmin, err := p.hasAdminRole(ctx)
+		if err != nil {
+			return err
+		}
+
+		sqlStats := p.session.sqlStats
+		if sqlStats == nil {
+			return errors.New("cannot access sql statistics from this context")
+		}
+
+		leaseMgr := p.LeaseMgr()
+		nodeID := tree.NewDInt(tree.DInt(int64(leaseMgr.nodeID.Get())))
+
+		var appNames []string
+		sqlStats.Lock()
+		for n := range sqlStats.apps {
+			appNames = append(appNames, n)
+		}
+		sqlStats.Unlock()
+		sort.Strings(appNames)
+
+		for _, appName := range appNames {
+			appStats := sqlStats.getStatsForApplication(appName)
+			for _, f := range appStats.fingerprintStats() {
+				fingerprint := f.fingerprint
+				fingerprintID := tree.NewDString(tree.StatementFingerprintID(f.fingerprint))
+				errString := tree.DNull
+				if isAdmin {
+					if f.data.LastErr != "" {
+						errString = tree.NewDString(f.data.LastErr)
+					}
+				} else {
+					anonStr, ok := scrubStmtStatKey(p.session.virtualSchemas, fingerprint)
+					if !ok {
+						continue
+					}
+					fingerprint = anonStr
+				}
+				if err := addRow(
+					nodeID,
+					tree.NewDString(appName),
+					tree.NewDString(fingerprint),
+					fingerprintID,
+					tree.NewDInt(tree.DInt(f.data.Count)),
+					tree.NewDInt(tree.DInt(f.failureCount)),
+					tree.NewDInt(tree.DInt(f.distSQLCount)),
+					tree.NewDInt(tree.DInt(f.data.FirstAttemptCount)),
+					tree.NewDInt(tree.DInt(f.data.MaxRetries)),
+					errString,
+					tree.NewDFloat(tree.DFloat(f.data.NumRows.Mean)),
+					tree.NewDFloat(tree.DFloat(f.data.ServiceLat.Mean)),
+					tree.NewDFloat(tree.DFloat(f.serviceLatQuantile(50))),
+					tree.NewDFloat(tree.DFloat(f.serviceLatQuantile(90))),
+					tree.NewDFloat(tree.DFloat(f.serviceLatQuantile(99))),
+					tree.NewDFloat(tree.DFloat(f.serviceLatQuantile(100))),
+				); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalFeatureUsageTable exposes the counts of the uses of the SQL
+// features by the statements executed on this node, per application. See
+// feature_usage.go for the naming of the features.
+var crdbInternalFeatureUsageTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.feature_usage (
+  node_id          INT NOT NULL,
+  application_name STRING NOT NULL,
+  feature_name     STRING NOT NULL,
+  usage_count      INT NOT NULL
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		if p.session.featureUsage == nil {
+			return errors.New("cannot access feature usage from this context")
+		}
+		nodeID := tree.NewDInt(tree.DInt(int64(p.LeaseMgr().nodeID.Get())))
+		for _, c := range p.session.featureUsage.counts() {
+			if err := addRow(
+				nodeID,
+				tree.NewDString(c.appName),
+				tree.NewDString(c.feature),
+				tree.NewDInt(tree.DInt(c.count)),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalScrubErrorsTable exposes the errors found by the latest
+// background SCRUB check of each table checked by this node.
+var crdbInternalScrubErrorsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.scrub_errors (
+  node_id       INT NOT NULL,
+  error_type    STRING NOT NULL,
+  database_name STRING NOT NULL,
+  table_name    STRING NOT NULL,
+  primary_key   STRING,
+  timestamp     TIMESTAMP NOT NULL,
+  details       JSONB
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		if err := p.requireAdminRole(ctx, "read crdb_internal.scrub_errors"); err != nil {
+			return err
+		}
+		if p.session.backgroundScrub == nil {
+			return errors.New("cannot access the SCRUB errors from this context")
+		}
+		nodeID := tree.NewDInt(tree.DInt(int64(p.ExecCfg().NodeID.Get())))
+		for _, row := range p.session.backgroundScrub.rows() {
+			// The rows are in the format of scrubColumns, without the job
+			// UUID and the repaired flag, which are always unset.
+			if err := addRow(
+				nodeID,
+				row[1], // error_type
+				row[2], // database
+				row[3], // table
+				row[4], // primary_key
+				row[5], // timestamp
+				row[7], // details
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // crdbInternalSessionTraceTable exposes the latest trace collected on this
 // session (via SET TRACING={ON/OFF})
 var crdbInternalSessionTraceTable = virtualSchemaTable{
@@ -622,17 +843,92 @@ CREATE TABLE crdb_internal.cluster_settings (
 var crdbInternalSessionVariablesTable = virtualSchemaTable{
 	schema: `
 CREATE TABLE crdb_internal.session_variables (
-  variable STRING NOT NULL,
-  value    STRING NOT NULL
+  variable    STRING NOT NULL,
+  value       STRING NOT NULL,
+  "default"   STRING NOT NULL,
+  description STRING NOT NULL
 );
 `,
 	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
 		for _, vName := range varNames {
 			gen := varGen[vName]
-			value := gen.Get(p.session)
 			if err := addRow(
 				tree.NewDString(vName),
-				tree.NewDString(value),
+				tree.NewDString(gen.Get(p.session)),
+				tree.NewDString(gen.getDefault(p.session)),
+				tree.NewDString(gen.Description),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalLocalConnectionsTable exposes the number of SQL connections of
+// each user to the current node, along with the limit they are subject to.
+// The results are dependent on the current user.
+var crdbInternalLocalConnectionsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.node_connections (
+  node_id          INT NOT NULL,    -- the node the connections are to
+  username         STRING NOT NULL, -- the user of the connections
+  connections      INT NOT NULL,    -- the number of SQL connections of the user to the node
+  connection_limit INT              -- the connection limit of the user on the node, NULL if none
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		// The role options are read as root, since system.role_options is
+		// only readable by root.
+		ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+		rows, err := ie.QueryRowsInTransaction(
+			ctx, "node-connections", p.txn,
+			`SELECT username, value FROM system.role_options WHERE option = $1`,
+			roleOptionConnectionLimit,
+		)
+		if err != nil {
+			return err
+		}
+		userLimits := make(map[string]tree.Datum, len(rows))
+		for _, row := range rows {
+			limit := tree.DNull
+			if row[1] != tree.DNull {
+				l, err := strconv.ParseInt(string(tree.MustBeDString(row[1])), 10, 64)
+				if err != nil {
+					return err
+				}
+				if l >= 0 {
+					limit = tree.NewDInt(tree.DInt(l))
+				}
+			}
+			userLimits[string(tree.MustBeDString(row[0]))] = limit
+		}
+		defaultLimit := tree.DNull
+		if l := maxConnectionsPerUser.Get(&p.ExecCfg().Settings.SV); l > 0 {
+			defaultLimit = tree.NewDInt(tree.DInt(l))
+		}
+
+		isAdmin, err := p.hasAdminRole(ctx)
+		if err != nil {
+			return err
+		}
+		nodeID := tree.NewDInt(tree.DInt(int64(p.ExecCfg().NodeID.Get())))
+		for _, c := range p.ExecCfg().SessionRegistry.connectionCounts() {
+			if !(isAdmin || p.session.User == c.user) {
+				continue
+			}
+			limit, ok := userLimits[c.user]
+			if !ok {
+				limit = defaultLimit
+			}
+			if c.user == security.RootUser {
+				limit = tree.DNull
+			}
+			if err := addRow(
+				nodeID,
+				tree.NewDString(c.user),
+				tree.NewDInt(tree.DInt(c.count)),
+				limit,
 			); err != nil {
 				return err
 			}
@@ -651,7 +947,9 @@ CREATE TABLE crdb_internal.%s (
   client_address   STRING,         -- the address of the client that issued the query
   application_name STRING,         -- the name of the application as per SET application_name
   distributed      BOOL,           -- whether the query is running distributed
-  phase            STRING          -- the current execution phase
+  phase            STRING,         -- the current execution phase
+  fingerprint      STRING,         -- the SQL code of the query with constants hidden
+  session_id       INT             -- the ID of the session running the query
 );
 `
 
@@ -660,7 +958,10 @@ CREATE TABLE crdb_internal.%s (
 var crdbInternalLocalQueriesTable = virtualSchemaTable{
 	schema: fmt.Sprintf(queriesSchemaPattern, "node_queries"),
 	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
-		req := serverpb.ListSessionsRequest{Username: p.session.User}
+		req, err := p.listSessionsRequest(ctx)
+		if err != nil {
+			return err
+		}
 		response, err := p.session.execCfg.StatusServer.ListLocalSessions(ctx, &req)
 		if err != nil {
 			return err
@@ -674,7 +975,10 @@ var crdbInternalLocalQueriesTable = virtualSchemaTable{
 var crdbInternalClusterQueriesTable = virtualSchemaTable{
 	schema: fmt.Sprintf(queriesSchemaPattern, "cluster_queries"),
 	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
-		req := serverpb.ListSessionsRequest{Username: p.session.User}
+		req, err := p.listSessionsRequest(ctx)
+		if err != nil {
+			return err
+		}
 		response, err := p.session.execCfg.StatusServer.ListSessions(ctx, &req)
 		if err != nil {
 			return err
@@ -683,6 +987,20 @@ var crdbInternalClusterQueriesTable = virtualSchemaTable{
 	},
 }
 
+// listSessionsRequest returns the request listing the sessions the current
+// user can see: the members of the admin role see the sessions of every user,
+// which the status server lists for root, and the other users their own.
+func (p *planner) listSessionsRequest(ctx context.Context) (serverpb.ListSessionsRequest, error) {
+	isAdmin, err := p.hasAdminRole(ctx)
+	if err != nil {
+		return serverpb.ListSessionsRequest{}, err
+	}
+	if isAdmin {
+		return serverpb.ListSessionsRequest{Username: security.RootUser}, nil
+	}
+	return serverpb.ListSessionsRequest{Username: p.session.User}, nil
+}
+
 func populateQueriesTable(
 	ctx context.Context, addRow func(...tree.Datum) error, response *serverpb.ListSessionsResponse,
 ) error {
@@ -706,6 +1024,8 @@ func populateQueriesTable(
 				tree.NewDString(session.ApplicationName),
 				isDistributedDatum,
 				tree.NewDString(phase),
+				tree.NewDString(query.Fingerprint),
+				tree.NewDInt(tree.DInt(session.ID)),
 			); err != nil {
 				return err
 			}
@@ -726,6 +1046,8 @@ func populateQueriesTable(
 				tree.DNull,
 				tree.DNull,
 				tree.DNull,
+				tree.DNull,
+				tree.DNull,
 			); err != nil {
 				return err
 			}
@@ -736,6 +1058,7 @@ func populateQueriesTable(
 
 const sessionsSchemaPattern = `
 CREATE TABLE crdb_internal.%s (
+  session_id         INT,            -- the ID of the session, as returned by pg_backend_pid()
   node_id            INT NOT NULL,   -- the node on which the query is running
   username           STRING,         -- the user running the query
   client_address     STRING,         -- the address of the client that issued the query
@@ -744,7 +1067,10 @@ CREATE TABLE crdb_internal.%s (
   last_active_query  STRING,         -- the query that finished last on this session as SQL
   session_start      TIMESTAMP,      -- the time when the session was opened
   oldest_query_start TIMESTAMP,      -- the time when the oldest query in the session was started
-  kv_txn             STRING          -- the ID of the current KV transaction
+  kv_txn             STRING,         -- the ID of the current KV transaction
+  txn_start          TIMESTAMP,      -- the time when the current transaction was started
+  alloc_bytes        INT,            -- the number of bytes currently allocated by the session
+  max_alloc_bytes    INT             -- the high water mark of bytes allocated by the session
 );
 `
 
@@ -753,7 +1079,10 @@ CREATE TABLE crdb_internal.%s (
 var crdbInternalLocalSessionsTable = virtualSchemaTable{
 	schema: fmt.Sprintf(sessionsSchemaPattern, "node_sessions"),
 	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
-		req := serverpb.ListSessionsRequest{Username: p.session.User}
+		req, err := p.listSessionsRequest(ctx)
+		if err != nil {
+			return err
+		}
 		response, err := p.session.execCfg.StatusServer.ListLocalSessions(ctx, &req)
 		if err != nil {
 			return err
@@ -767,7 +1096,10 @@ var crdbInternalLocalSessionsTable = virtualSchemaTable{
 var crdbInternalClusterSessionsTable = virtualSchemaTable{
 	schema: fmt.Sprintf(sessionsSchemaPattern, "cluster_sessions"),
 	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
-		req := serverpb.ListSessionsRequest{Username: p.session.User}
+		req, err := p.listSessionsRequest(ctx)
+		if err != nil {
+			return err
+		}
 		response, err := p.session.execCfg.StatusServer.ListSessions(ctx, &req)
 		if err != nil {
 			return err
@@ -807,7 +1139,13 @@ func populateSessionsTable(
 			kvTxnIDDatum = tree.NewDString(session.KvTxnID.String())
 		}
 
+		txnStartDatum := tree.DNull
+		if session.TxnStart != nil {
+			txnStartDatum = tree.MakeDTimestamp(*session.TxnStart, time.Microsecond)
+		}
+
 		if err := addRow(
+			tree.NewDInt(tree.DInt(session.ID)),
 			tree.NewDInt(tree.DInt(session.NodeID)),
 			tree.NewDString(session.Username),
 			tree.NewDString(session.ClientAddress),
@@ -817,6 +1155,9 @@ func populateSessionsTable(
 			tree.MakeDTimestamp(session.Start, time.Microsecond),
 			oldestStartDatum,
 			kvTxnIDDatum,
+			txnStartDatum,
+			tree.NewDInt(tree.DInt(session.AllocBytes)),
+			tree.NewDInt(tree.DInt(session.MaxAllocBytes)),
 		); err != nil {
 			return err
 		}
@@ -827,6 +1168,7 @@ func populateSessionsTable(
 		if rpcErr.NodeID != 0 {
 			// Add a row with this node ID, and nulls for all other columns
 			if err := addRow(
+				tree.DNull,
 				tree.NewDInt(tree.DInt(rpcErr.NodeID)),
 				tree.DNull,
 				tree.DNull,
@@ -836,6 +1178,9 @@ func populateSessionsTable(
 				tree.DNull,
 				tree.DNull,
 				tree.DNull,
+				tree.DNull,
+				tree.DNull,
+				tree.DNull,
 			); err != nil {
 				return err
 			}
@@ -874,8 +1219,8 @@ CREATE TABLE crdb_internal.builtin_functions (
 	},
 }
 
-// crdbInternalCreateStmtsTable exposes the CREATE TABLE/CREATE VIEW
-// statements.
+// crdbInternalCreateStmtsTable exposes the CREATE TABLE/CREATE VIEW/CREATE
+// SEQUENCE statements.
 var crdbInternalCreateStmtsTable = virtualSchemaTable{
 	schema: `
 CREATE TABLE crdb_internal.create_statements (
@@ -896,16 +1241,32 @@ CREATE TABLE crdb_internal.create_statements (
 				var err error
 				var typeView = tree.DString("view")
 				var typeTable = tree.DString("table")
-				if table.IsView() {
+				var typeSequence = tree.DString("sequence")
+				switch {
+				case table.IsView():
 					descType = &typeView
 					stmt, err = p.showCreateView(ctx, tree.Name(table.Name), table)
-				} else {
+				case table.IsSequence():
+					descType = &typeSequence
+					stmt = showCreateSequence(tree.Name(table.Name), table, table.SequenceOpts.Start)
+				default:
 					descType = &typeTable
 					stmt, err = p.showCreateTable(ctx, tree.Name(table.Name), prefix, table)
 				}
 				if err != nil {
 					return err
 				}
+				if table.IsTable() {
+					// The zone configs are appended as comments, so that the
+					// statement can still be run as is.
+					zoneStmts, err := p.showCreateZoneConfigs(ctx, tree.Name(table.Name), table)
+					if err != nil {
+						return err
+					}
+					for _, zoneStmt := range zoneStmts {
+						stmt += "\n-- " + zoneStmt + ";"
+					}
+				}
 
 				descID := tree.DNull
 				if table.ID != keys.VirtualDescriptorID {
@@ -1133,6 +1494,205 @@ CREATE TABLE crdb_internal.index_columns (
 	},
 }
 
+// crdbInternalIndexUsageStatisticsTable exposes the reads and writes of the
+// indexes by the statements executed on this node. The indexes which were
+// never used on this node are listed too, so that the unused indexes can be
+// found. See index_usage.go.
+var crdbInternalIndexUsageStatisticsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.index_usage_statistics (
+  node_id          INT NOT NULL,
+  descriptor_id    INT NOT NULL,
+  descriptor_name  STRING NOT NULL,
+  index_id         INT NOT NULL,
+  index_name       STRING NOT NULL,
+  total_reads      INT NOT NULL,
+  total_writes     INT NOT NULL,
+  last_read        TIMESTAMP
+)
+`,
+	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
+		if p.session.indexUsage == nil {
+			return errors.New("cannot access index usage statistics from this context")
+		}
+		nodeID := tree.NewDInt(tree.DInt(int64(p.ExecCfg().NodeID.Get())))
+		return forEachTableDescAll(ctx, p, prefix,
+			func(_ *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+				if table.IsVirtualTable() {
+					return nil
+				}
+				tableID := tree.NewDInt(tree.DInt(table.ID))
+				tableName := tree.NewDString(table.Name)
+				return table.ForeachNonDropIndex(func(idx *sqlbase.IndexDescriptor) error {
+					u := p.session.indexUsage.get(table.ID, idx.ID)
+					lastRead := tree.DNull
+					if !u.lastRead.IsZero() {
+						lastRead = tree.MakeDTimestamp(u.lastRead, time.Microsecond)
+					}
+					return addRow(
+						nodeID,
+						tableID,
+						tableName,
+						tree.NewDInt(tree.DInt(idx.ID)),
+						tree.NewDString(idx.Name),
+						tree.NewDInt(tree.DInt(u.reads)),
+						tree.NewDInt(tree.DInt(u.writes)),
+						lastRead,
+					)
+				})
+			})
+	},
+}
+
+// crdbInternalTableRowStatisticsTable exposes estimates of the number of
+// rows and of the size of the tables, derived from the MVCC statistics of
+// their ranges without scanning them. The statistics of a table are left
+// NULL when they can't be attributed to it, e.g. when the table shares a
+// range with another table. See rangeStatsRowCount for the estimation of
+// the row counts.
+var crdbInternalTableRowStatisticsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.table_row_statistics (
+  table_id            INT NOT NULL,
+  database_name       STRING NOT NULL,
+  table_name          STRING NOT NULL,
+  estimated_row_count INT,
+  row_count_is_exact  BOOL,
+  range_count         INT,
+  live_bytes          INT,
+  total_bytes         INT
+)
+`,
+	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
+		return forEachTableDesc(ctx, p, prefix,
+			func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+				if !table.IsPhysicalTable() {
+					return nil
+				}
+				rowCount, isExact, rangeCount := tree.DNull, tree.DNull, tree.DNull
+				liveBytes, totalBytes := tree.DNull, tree.DNull
+				if table.IsInterleaved() {
+					log.VEventf(ctx, 2, "%s shares its ranges with the tables it is interleaved with", table.Name)
+				} else if s, err := p.tableRangeStats(ctx, table); err != nil {
+					log.VEventf(ctx, 2, "cannot read the range statistics of %s: %v", table.Name, err)
+				} else {
+					count, exact := s.rowCount(table)
+					rowCount = tree.NewDInt(tree.DInt(count))
+					isExact = tree.MakeDBool(tree.DBool(exact))
+					rangeCount = tree.NewDInt(tree.DInt(s.rangeCount))
+					liveBytes = tree.NewDInt(tree.DInt(s.stats.LiveBytes))
+					totalBytes = tree.NewDInt(tree.DInt(s.stats.Total()))
+				}
+				return addRow(
+					tree.NewDInt(tree.DInt(table.ID)),
+					tree.NewDString(db.Name),
+					tree.NewDString(table.Name),
+					rowCount,
+					isExact,
+					rangeCount,
+					liveBytes,
+					totalBytes,
+				)
+			})
+	},
+}
+
+// crdbInternalContentionEventsTable exposes the most recent contention
+// events recorded by the stores of this node: the requests which had to
+// wait for a conflicting transaction, either on one of its intents or
+// behind a deadlock. The keys contain the data of the rows, so the table
+// is restricted to admins.
+var crdbInternalContentionEventsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.contention_events (
+  node_id          INT NOT NULL,
+  start            TIMESTAMP NOT NULL,
+  duration         INTERVAL NOT NULL,
+  waiting_txn_id   UUID,
+  blocking_txn_id  UUID NOT NULL,
+  key              BYTES NOT NULL,
+  pretty_key       STRING NOT NULL,
+  deadlock         BOOL NOT NULL
+)
+`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		if err := p.requireAdminRole(ctx, "read crdb_internal.contention_events"); err != nil {
+			return err
+		}
+		nodeID := tree.NewDInt(tree.DInt(int64(p.ExecCfg().NodeID.Get())))
+		for _, ev := range p.ExecCfg().ContentionEvents.Events() {
+			waitingTxnID := tree.DNull
+			if ev.WaitingTxnID != (uuid.UUID{}) {
+				waitingTxnID = tree.NewDUuid(tree.DUuid{UUID: ev.WaitingTxnID})
+			}
+			if err := addRow(
+				nodeID,
+				tree.MakeDTimestamp(ev.Start, time.Microsecond),
+				&tree.DInterval{Duration: duration.Duration{Nanos: ev.Duration.Nanoseconds()}},
+				waitingTxnID,
+				tree.NewDUuid(tree.DUuid{UUID: ev.BlockingTxnID}),
+				tree.NewDBytes(tree.DBytes(ev.Key)),
+				tree.NewDString(keys.PrettyPrint(ev.Key)),
+				tree.MakeDBool(tree.DBool(ev.Deadlock)),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalIndexContentionStatisticsTable exposes the cumulative
+// contention events recorded by the stores of this node on the keys of each
+// index, since the node started. Only the indexes with contention events
+// are listed.
+var crdbInternalIndexContentionStatisticsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.index_contention_statistics (
+  node_id                INT NOT NULL,
+  descriptor_id          INT NOT NULL,
+  descriptor_name        STRING NOT NULL,
+  index_id               INT NOT NULL,
+  index_name             STRING NOT NULL,
+  num_contention_events  INT NOT NULL,
+  total_contention_time  INTERVAL NOT NULL,
+  num_deadlocks          INT NOT NULL
+)
+`,
+	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
+		stats := make(map[[2]uint64]storage.ContentionIndexStats)
+		for _, s := range p.ExecCfg().ContentionEvents.IndexStats() {
+			stats[[2]uint64{s.TableID, s.IndexID}] = s
+		}
+		if len(stats) == 0 {
+			return nil
+		}
+		nodeID := tree.NewDInt(tree.DInt(int64(p.ExecCfg().NodeID.Get())))
+		return forEachTableDescAll(ctx, p, prefix,
+			func(_ *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+				if table.IsVirtualTable() {
+					return nil
+				}
+				return table.ForeachNonDropIndex(func(idx *sqlbase.IndexDescriptor) error {
+					s, ok := stats[[2]uint64{uint64(table.ID), uint64(idx.ID)}]
+					if !ok {
+						return nil
+					}
+					return addRow(
+						nodeID,
+						tree.NewDInt(tree.DInt(table.ID)),
+						tree.NewDString(table.Name),
+						tree.NewDInt(tree.DInt(idx.ID)),
+						tree.NewDString(idx.Name),
+						tree.NewDInt(tree.DInt(s.NumEvents)),
+						&tree.DInterval{Duration: duration.Duration{Nanos: s.TotalDuration.Nanoseconds()}},
+						tree.NewDInt(tree.DInt(s.NumDeadlocks)),
+					)
+				})
+			})
+	},
+}
+
 // crdbInternalBackwardDependenciesTable exposes the backward
 // inter-descriptor dependencies.
 var crdbInternalBackwardDependenciesTable = virtualSchemaTable{
@@ -1151,6 +1711,7 @@ CREATE TABLE crdb_internal.backward_dependencies (
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
 		fkDep := tree.NewDString("fk")
 		viewDep := tree.NewDString("view")
+		sequenceDep := tree.NewDString("sequence")
 		interleaveDep := tree.NewDString("interleave")
 		return forEachTableDescAll(ctx, p, prefix,
 			func(_ *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
@@ -1221,11 +1782,93 @@ CREATE TABLE crdb_internal.backward_dependencies (
 					}
 				}
 
+				// Record the sequence dependencies.
+				for _, col := range table.Columns {
+					for _, seqID := range col.UsesSequenceIDs {
+						if err := addRow(
+							tableID, tableName,
+							tree.DNull,
+							tree.NewDInt(tree.DInt(seqID)),
+							sequenceDep,
+							tree.DNull,
+							tree.DNull,
+							tree.NewDString(fmt.Sprintf("Column: %d", col.ID)),
+						); err != nil {
+							return err
+						}
+					}
+				}
+
 				return nil
 			})
 	},
 }
 
+// crdbInternalEventLogTable exposes the events of system.eventlog, with the
+// target, user and statement of the schema changes, the changes of
+// privileges and roles and the cluster setting changes decoded from their
+// payloads.
+var crdbInternalEventLogTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.eventlog (
+  timestamp    TIMESTAMP NOT NULL,
+  event_type   STRING NOT NULL,
+  target_id    INT NOT NULL,
+  reporting_id INT NOT NULL,
+  target       STRING,
+  user_name    STRING,
+  statement    STRING,
+  info         STRING
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		// The events are read with the privileges of the current user on
+		// system.eventlog.
+		p = makeInternalPlanner("eventlog", p.txn, p.session.User, p.session.memMetrics)
+		defer finishInternalPlanner(p)
+		rows, err := p.queryRows(ctx,
+			`SELECT timestamp, "eventType", "targetID", "reportingID", info FROM system.eventlog`)
+		if err != nil {
+			return err
+		}
+
+		stringOrNull := func(s string) tree.Datum {
+			if s == "" {
+				return tree.DNull
+			}
+			return tree.NewDString(s)
+		}
+		for _, r := range rows {
+			eventType, info := r[1], r[4]
+			target, user, statement := tree.DNull, tree.DNull, tree.DNull
+			if s, ok := info.(*tree.DString); ok {
+				if newInfo, ok := eventLogInfoTypes[EventLogType(*eventType.(*tree.DString))]; ok {
+					ev := newInfo()
+					// The events recorded by older versions may not decode,
+					// in which case only their raw info is shown.
+					if err := json.Unmarshal([]byte(*s), ev); err == nil {
+						t, u, stmt := ev.EventDetails()
+						target, user, statement = stringOrNull(t), stringOrNull(u), stringOrNull(stmt)
+					}
+				}
+			}
+			if err := addRow(
+				r[0],
+				eventType,
+				r[2],
+				r[3],
+				target,
+				user,
+				statement,
+				info,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // crdbInternalForwardDependenciesTable exposes the forward
 // inter-descriptor dependencies.
 var crdbInternalForwardDependenciesTable = virtualSchemaTable{
@@ -1244,6 +1887,7 @@ CREATE TABLE crdb_internal.forward_dependencies (
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
 		fkDep := tree.NewDString("fk")
 		viewDep := tree.NewDString("view")
+		sequenceDep := tree.NewDString("sequence")
 		interleaveDep := tree.NewDString("interleave")
 		return forEachTableDescAll(ctx, p, prefix,
 			func(_ *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
@@ -1298,13 +1942,23 @@ CREATE TABLE crdb_internal.forward_dependencies (
 					}
 				}
 
-				// Record the view dependencies.
+				// Record the view dependencies, and the columns using a sequence.
 				for _, dep := range table.DependedOnBy {
+					depType := viewDep
+					if table.IsSequence() {
+						dependent, err := sqlbase.GetTableDescFromID(ctx, p.txn, dep.ID)
+						if err != nil {
+							return err
+						}
+						if !dependent.IsView() {
+							depType = sequenceDep
+						}
+					}
 					if err := addRow(
 						tableID, tableName,
 						tree.DNull,
 						tree.NewDInt(tree.DInt(dep.ID)),
-						viewDep,
+						depType,
 						tree.NewDInt(tree.DInt(dep.IndexID)),
 						tree.DNull,
 						tree.NewDString(fmt.Sprintf("Columns: %v", dep.ColumnIDs)),
@@ -1335,7 +1989,7 @@ CREATE TABLE crdb_internal.ranges (
 )
 `,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
-		if err := p.RequireSuperUser("read crdb_internal.ranges"); err != nil {
+		if err := p.requireAdminRole(ctx, "read crdb_internal.ranges"); err != nil {
 			return err
 		}
 		descs, err := getAllDescriptors(ctx, p.txn)
@@ -1525,3 +2179,404 @@ CREATE TABLE crdb_internal.zones (
 		return nil
 	},
 }
+
+// crdbInternalPartitionsTable decodes and exposes the partitions of each
+// index, including subpartitions. list_value holds the tuples of a list
+// partition and range_value the exclusive upper bound of a range partition.
+var crdbInternalPartitionsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.partitions (
+  table_id     INT NOT NULL,
+  index_id     INT NOT NULL,
+  parent_name  STRING,
+  name         STRING NOT NULL,
+  columns      INT NOT NULL,
+  column_names STRING NOT NULL,
+  list_value   STRING,
+  range_value  STRING
+)
+`,
+	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
+		var a sqlbase.DatumAlloc
+		return forEachTableDescAll(ctx, p, prefix,
+			func(_ *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+				return table.ForeachNonDropIndex(func(index *sqlbase.IndexDescriptor) error {
+					return addPartitioningRows(&a, table, index, &index.Partitioning,
+						tree.DNull, 0 /* colOffset */, addRow)
+				})
+			})
+	},
+}
+
+// addPartitioningRows adds a row to crdb_internal.partitions for each
+// partition of partDesc and, recursively, of its subpartitions. parentName is
+// the name of the partition partDesc subpartitions, or NULL.
+func addPartitioningRows(
+	a *sqlbase.DatumAlloc,
+	table *sqlbase.TableDescriptor,
+	index *sqlbase.IndexDescriptor,
+	partDesc *sqlbase.PartitioningDescriptor,
+	parentName tree.Datum,
+	colOffset int,
+	addRow func(...tree.Datum) error,
+) error {
+	numColumns := int(partDesc.NumColumns)
+	if numColumns == 0 {
+		return nil
+	}
+	tableID := tree.NewDInt(tree.DInt(table.ID))
+	indexID := tree.NewDInt(tree.DInt(index.ID))
+	columns := tree.NewDInt(tree.DInt(numColumns))
+	columnNames := tree.NewDString(
+		strings.Join(index.ColumnNames[colOffset:colOffset+numColumns], ", "))
+
+	// As in SHOW CREATE TABLE, only the datums of the decoded values are used,
+	// so the prefixes don't need to be real.
+	fakePrefixDatums := make([]tree.Datum, colOffset)
+	for i := range fakePrefixDatums {
+		fakePrefixDatums[i] = tree.DNull
+	}
+
+	for _, l := range partDesc.List {
+		var buf bytes.Buffer
+		for j, values := range l.Values {
+			if j != 0 {
+				buf.WriteString(`, `)
+			}
+			datums, _, err := sqlbase.TranslateValueEncodingToSpan(
+				a, table, index, partDesc, values, fakePrefixDatums,
+			)
+			if err != nil {
+				return err
+			}
+			buf.WriteString(`(`)
+			sqlbase.PrintPartitioningTuple(&buf, datums, numColumns, "DEFAULT")
+			buf.WriteString(`)`)
+		}
+		name := tree.NewDString(l.Name)
+		if err := addRow(
+			tableID, indexID, parentName, name, columns, columnNames,
+			tree.NewDString(buf.String()), tree.DNull,
+		); err != nil {
+			return err
+		}
+		if err := addPartitioningRows(
+			a, table, index, &l.Subpartitioning, name, colOffset+numColumns, addRow,
+		); err != nil {
+			return err
+		}
+	}
+	for _, r := range partDesc.Range {
+		var buf bytes.Buffer
+		datums, _, err := sqlbase.TranslateValueEncodingToSpan(
+			a, table, index, partDesc, r.UpperBound, fakePrefixDatums,
+		)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(`(`)
+		sqlbase.PrintPartitioningTuple(&buf, datums, numColumns, "MAXVALUE")
+		buf.WriteString(`)`)
+		if err := addRow(
+			tableID, indexID, parentName, tree.NewDString(r.Name), columns, columnNames,
+			tree.DNull, tree.NewDString(buf.String()),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// crdbInternalGossipNodesTable exposes the node descriptors gossiped in the
+// cluster, as seen by the current node.
+var crdbInternalGossipNodesTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.gossip_nodes (
+  node_id        INT NOT NULL,
+  network        STRING NOT NULL,
+  address        STRING NOT NULL,
+  attrs          JSON NOT NULL,
+  locality       STRING NOT NULL,
+  server_version STRING NOT NULL,
+  is_live        BOOL NOT NULL
+)
+`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		if err := p.requireAdminRole(ctx, "read crdb_internal.gossip_nodes"); err != nil {
+			return err
+		}
+		g := p.ExecCfg().Gossip
+		var descs []roachpb.NodeDescriptor
+		if err := forEachGossipInfo(g, gossip.KeyNodeIDPrefix, func(v roachpb.Value) error {
+			var desc roachpb.NodeDescriptor
+			if err := v.GetProto(&desc); err != nil {
+				return err
+			}
+			descs = append(descs, desc)
+			return nil
+		}); err != nil {
+			return err
+		}
+		sort.Slice(descs, func(i, j int) bool { return descs[i].NodeID < descs[j].NodeID })
+
+		clock := p.ExecCfg().Clock
+		for _, desc := range descs {
+			var l storage.Liveness
+			isLive := g.GetInfoProto(gossip.MakeNodeLivenessKey(desc.NodeID), &l) == nil &&
+				l.IsLive(clock.Now(), clock.MaxOffset())
+			attrs, err := stringsToJSON(desc.Attrs.Attrs)
+			if err != nil {
+				return err
+			}
+			if err := addRow(
+				tree.NewDInt(tree.DInt(desc.NodeID)),
+				tree.NewDString(desc.Address.NetworkField),
+				tree.NewDString(desc.Address.AddressField),
+				attrs,
+				tree.NewDString(desc.Locality.String()),
+				tree.NewDString(desc.ServerVersion.String()),
+				tree.MakeDBool(tree.DBool(isLive)),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalGossipLivenessTable exposes the node liveness records gossiped
+// in the cluster, as seen by the current node.
+var crdbInternalGossipLivenessTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.gossip_liveness (
+  node_id         INT NOT NULL,
+  epoch           INT NOT NULL,
+  expiration      STRING NOT NULL,
+  draining        BOOL NOT NULL,
+  decommissioning BOOL NOT NULL,
+  is_live         BOOL NOT NULL
+)
+`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		if err := p.requireAdminRole(ctx, "read crdb_internal.gossip_liveness"); err != nil {
+			return err
+		}
+		var livenesses []storage.Liveness
+		if err := forEachGossipInfo(
+			p.ExecCfg().Gossip, gossip.KeyNodeLivenessPrefix, func(v roachpb.Value) error {
+				var l storage.Liveness
+				if err := v.GetProto(&l); err != nil {
+					return err
+				}
+				livenesses = append(livenesses, l)
+				return nil
+			},
+		); err != nil {
+			return err
+		}
+		sort.Slice(livenesses, func(i, j int) bool { return livenesses[i].NodeID < livenesses[j].NodeID })
+
+		clock := p.ExecCfg().Clock
+		for i := range livenesses {
+			l := &livenesses[i]
+			if err := addRow(
+				tree.NewDInt(tree.DInt(l.NodeID)),
+				tree.NewDInt(tree.DInt(l.Epoch)),
+				tree.NewDString(hlc.Timestamp(l.Expiration).String()),
+				tree.MakeDBool(tree.DBool(l.Draining)),
+				tree.MakeDBool(tree.DBool(l.Decommissioning)),
+				tree.MakeDBool(tree.DBool(l.IsLive(clock.Now(), clock.MaxOffset()))),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// forEachGossipInfo calls fn with the value of each gossiped info whose key
+// has the given prefix.
+func forEachGossipInfo(g *gossip.Gossip, prefix string, fn func(roachpb.Value) error) error {
+	for key, info := range g.GetInfoStatus().Infos {
+		if !strings.HasPrefix(key, prefix+":") {
+			continue
+		}
+		if err := fn(info.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// crdbInternalKVNodeStatusTable exposes the status records the nodes of the
+// cluster periodically persist.
+var crdbInternalKVNodeStatusTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.kv_node_status (
+  node_id        INT NOT NULL,
+  network        STRING NOT NULL,
+  address        STRING NOT NULL,
+  attrs          JSON NOT NULL,
+  locality       STRING NOT NULL,
+  server_version STRING NOT NULL,
+  go_version     STRING NOT NULL,
+  tag            STRING NOT NULL,
+  time           STRING NOT NULL,
+  revision       STRING NOT NULL,
+  cgo_compiler   STRING NOT NULL,
+  platform       STRING NOT NULL,
+  distribution   STRING NOT NULL,
+  type           STRING NOT NULL,
+  started_at     TIMESTAMP NOT NULL,
+  updated_at     TIMESTAMP NOT NULL,
+  metrics        JSON NOT NULL,
+  args           JSON NOT NULL,
+  env            JSON NOT NULL
+)
+`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		if err := p.requireAdminRole(ctx, "read crdb_internal.kv_node_status"); err != nil {
+			return err
+		}
+		statuses, err := getNodeStatuses(ctx, p.txn)
+		if err != nil {
+			return err
+		}
+		for i := range statuses {
+			s := &statuses[i]
+			attrs, err := stringsToJSON(s.Desc.Attrs.Attrs)
+			if err != nil {
+				return err
+			}
+			metrics, err := metricsToJSON(s.Metrics)
+			if err != nil {
+				return err
+			}
+			args, err := stringsToJSON(s.Args)
+			if err != nil {
+				return err
+			}
+			env, err := stringsToJSON(s.Env)
+			if err != nil {
+				return err
+			}
+			if err := addRow(
+				tree.NewDInt(tree.DInt(s.Desc.NodeID)),
+				tree.NewDString(s.Desc.Address.NetworkField),
+				tree.NewDString(s.Desc.Address.AddressField),
+				attrs,
+				tree.NewDString(s.Desc.Locality.String()),
+				tree.NewDString(s.Desc.ServerVersion.String()),
+				tree.NewDString(s.BuildInfo.GoVersion),
+				tree.NewDString(s.BuildInfo.Tag),
+				tree.NewDString(s.BuildInfo.Time),
+				tree.NewDString(s.BuildInfo.Revision),
+				tree.NewDString(s.BuildInfo.CgoCompiler),
+				tree.NewDString(s.BuildInfo.Platform),
+				tree.NewDString(s.BuildInfo.Distribution),
+				tree.NewDString(s.BuildInfo.Type),
+				tree.MakeDTimestamp(timeutil.Unix(0, s.StartedAt), time.Microsecond),
+				tree.MakeDTimestamp(timeutil.Unix(0, s.UpdatedAt), time.Microsecond),
+				metrics,
+				args,
+				env,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalKVStoreStatusTable exposes the status records of the stores of
+// the cluster, which the nodes periodically persist.
+var crdbInternalKVStoreStatusTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.kv_store_status (
+  node_id           INT NOT NULL,
+  store_id          INT NOT NULL,
+  attrs             JSON NOT NULL,
+  capacity          INT NOT NULL,
+  available         INT NOT NULL,
+  used              INT NOT NULL,
+  logical_bytes     INT NOT NULL,
+  range_count       INT NOT NULL,
+  lease_count       INT NOT NULL,
+  writes_per_second FLOAT NOT NULL,
+  metrics           JSON NOT NULL
+)
+`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		if err := p.requireAdminRole(ctx, "read crdb_internal.kv_store_status"); err != nil {
+			return err
+		}
+		statuses, err := getNodeStatuses(ctx, p.txn)
+		if err != nil {
+			return err
+		}
+		for i := range statuses {
+			for j := range statuses[i].StoreStatuses {
+				s := &statuses[i].StoreStatuses[j]
+				attrs, err := stringsToJSON(s.Desc.Attrs.Attrs)
+				if err != nil {
+					return err
+				}
+				metrics, err := metricsToJSON(s.Metrics)
+				if err != nil {
+					return err
+				}
+				capacity := s.Desc.Capacity
+				if err := addRow(
+					tree.NewDInt(tree.DInt(s.Desc.Node.NodeID)),
+					tree.NewDInt(tree.DInt(s.Desc.StoreID)),
+					attrs,
+					tree.NewDInt(tree.DInt(capacity.Capacity)),
+					tree.NewDInt(tree.DInt(capacity.Available)),
+					tree.NewDInt(tree.DInt(capacity.Used)),
+					tree.NewDInt(tree.DInt(capacity.LogicalBytes)),
+					tree.NewDInt(tree.DInt(capacity.RangeCount)),
+					tree.NewDInt(tree.DInt(capacity.LeaseCount)),
+					tree.NewDFloat(tree.DFloat(capacity.WritesPerSecond)),
+					metrics,
+				); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// getNodeStatuses returns the status records persisted by the nodes, ordered
+// by node ID.
+func getNodeStatuses(ctx context.Context, txn *client.Txn) ([]status.NodeStatus, error) {
+	rows, err := txn.Scan(ctx, keys.StatusNodePrefix, keys.StatusNodePrefix.PrefixEnd(), 0)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]status.NodeStatus, len(rows))
+	for i, row := range rows {
+		if err := row.ValueProto(&statuses[i]); err != nil {
+			return nil, err
+		}
+	}
+	return statuses, nil
+}
+
+// stringsToJSON returns a JSON array of the given strings.
+func stringsToJSON(strs []string) (tree.Datum, error) {
+	arr := make([]interface{}, len(strs))
+	for i, s := range strs {
+		arr[i] = s
+	}
+	return tree.MakeDJSON(arr)
+}
+
+// metricsToJSON returns a JSON object of the given metrics.
+func metricsToJSON(metrics map[string]float64) (tree.Datum, error) {
+	obj := make(map[string]interface{}, len(metrics))
+	for k, v := range metrics {
+		obj[k] = v
+	}
+	return tree.MakeDJSON(obj)
+}
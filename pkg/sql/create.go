@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/context"
@@ -25,6 +26,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -91,6 +94,7 @@ func (p *planner) CreateDatabase(n *tree.CreateDatabase) (planNode, error) {
 
 func (n *createDatabaseNode) Start(params runParams) error {
 	desc := makeDatabaseDesc(n.n)
+	desc.Privileges.Owner = params.p.session.User
 
 	created, err := params.p.createDatabase(params.ctx, &desc, n.n.IfNotExists)
 	if err != nil {
@@ -105,11 +109,11 @@ func (n *createDatabaseNode) Start(params runParams) error {
 			EventLogCreateDatabase,
 			int32(desc.ID),
 			int32(params.evalCtx.NodeID),
-			struct {
-				DatabaseName string
-				Statement    string
-				User         string
-			}{n.n.Name.String(), n.n.String(), params.p.session.User},
+			&sqlbase.CreateDatabaseEvent{
+				DatabaseName: n.n.Name.String(),
+				Statement:    n.n.String(),
+				User:         params.p.session.User,
+			},
 		); err != nil {
 			return err
 		}
@@ -214,13 +218,13 @@ func (n *createIndexNode) Start(params runParams) error {
 		EventLogCreateIndex,
 		int32(n.tableDesc.ID),
 		int32(params.evalCtx.NodeID),
-		struct {
-			TableName  string
-			IndexName  string
-			Statement  string
-			User       string
-			MutationID uint32
-		}{n.tableDesc.Name, n.n.Name.String(), n.n.String(), params.p.session.User, uint32(mutationID)},
+		&sqlbase.CreateIndexEvent{
+			TableName:  n.tableDesc.Name,
+			IndexName:  n.n.Name.String(),
+			Statement:  n.n.String(),
+			User:       params.p.session.User,
+			MutationID: uint32(mutationID),
+		},
 	); err != nil {
 		return err
 	}
@@ -239,7 +243,17 @@ type userAuthInfo struct {
 }
 
 type createUserNode struct {
-	ifNotExists  bool
+	n           *tree.CreateUser
+	ifNotExists bool
+	// isRole is set for CREATE ROLE.
+	isRole bool
+	// connectionLimit is nil if no CONNECTION LIMIT was specified.
+	connectionLimit *int64
+	// validUntil is nil if no VALID UNTIL was specified.
+	validUntil func() (string, error)
+	// noLogin and noInherit are the role options of the new user or role.
+	noLogin      bool
+	noInherit    bool
 	rowsAffected int
 	userAuthInfo
 }
@@ -259,8 +273,29 @@ func (p *planner) getUserAuthInfo(nameE, passwordE tree.Expr, ctx string) (userA
 	return userAuthInfo{name: name, password: password}, nil
 }
 
-// resolve returns the actual user name and (hashed) password.
-func (ua *userAuthInfo) resolve() (string, []byte, error) {
+const (
+	passwordEncryptionBcrypt = iota
+	passwordEncryptionSCRAM
+)
+
+// passwordEncryption selects how new passwords are hashed. Users whose
+// password is stored as a SCRAM verifier authenticate with a SCRAM exchange,
+// which clients that predate SCRAM, including the lib/pq version used by the
+// CLI, don't support.
+var passwordEncryption = settings.RegisterEnumSetting(
+	"server.user_login.password_encryption",
+	"the algorithm used to hash new passwords; users with a scram-sha-256 password "+
+		"can only log in with clients that support SCRAM authentication",
+	"bcrypt",
+	map[int64]string{
+		passwordEncryptionBcrypt: "bcrypt",
+		passwordEncryptionSCRAM:  "scram-sha-256",
+	},
+)
+
+// resolve returns the actual user name and (hashed) password. New passwords
+// must satisfy the complexity requirements of the cluster settings.
+func (ua *userAuthInfo) resolve(st *cluster.Settings) (string, []byte, error) {
 	name, err := ua.name()
 	if err != nil {
 		return "", nil, err
@@ -282,8 +317,11 @@ func (ua *userAuthInfo) resolve() (string, []byte, error) {
 		if resolvedPassword == "" {
 			return "", nil, security.ErrEmptyPassword
 		}
+		if err := validatePassword(st, resolvedPassword); err != nil {
+			return "", nil, err
+		}
 
-		hashedPassword, err = security.HashPassword(resolvedPassword)
+		hashedPassword, err = hashPassword(st, resolvedPassword)
 		if err != nil {
 			return "", nil, err
 		}
@@ -292,7 +330,7 @@ func (ua *userAuthInfo) resolve() (string, []byte, error) {
 	return normalizedUsername, hashedPassword, nil
 }
 
-// CreateUser creates a user.
+// CreateUser creates a user or a role.
 // Privileges: INSERT on system.users.
 //   notes: postgres allows the creation of users with an empty password. We do
 //          as well, but disallow password authentication for these users.
@@ -306,14 +344,52 @@ func (p *planner) CreateUser(ctx context.Context, n *tree.CreateUser) (planNode,
 		return nil, err
 	}
 
-	ua, err := p.getUserAuthInfo(n.Name, n.Password, "CREATE USER")
+	ua, err := p.getUserAuthInfo(n.Name, n.Password, n.StatementTag())
 	if err != nil {
 		return nil, err
 	}
 
+	if n.ConnectionLimit != nil {
+		if err := validateConnectionLimit(*n.ConnectionLimit); err != nil {
+			return nil, err
+		}
+	}
+	var validUntil func() (string, error)
+	if n.ValidUntil != nil {
+		validUntil, err = p.TypeAsString(n.ValidUntil, n.StatementTag())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Like in PostgreSQL, roles can't log in unless created with LOGIN.
+	noLogin, noInherit := n.IsRole, false
+	var seenLogin, seenInherit bool
+	for _, o := range n.RoleOptions {
+		var seen *bool
+		switch o {
+		case tree.RoleOptionLogin, tree.RoleOptionNoLogin:
+			seen = &seenLogin
+			noLogin = o == tree.RoleOptionNoLogin
+		case tree.RoleOptionInherit, tree.RoleOptionNoInherit:
+			seen = &seenInherit
+			noInherit = o == tree.RoleOptionNoInherit
+		}
+		if *seen {
+			return nil, pgerror.NewError(pgerror.CodeSyntaxError, "conflicting or redundant options")
+		}
+		*seen = true
+	}
+
 	return &createUserNode{
-		userAuthInfo: ua,
-		ifNotExists:  n.IfNotExists,
+		n:               n,
+		userAuthInfo:    ua,
+		ifNotExists:     n.IfNotExists,
+		isRole:          n.IsRole,
+		connectionLimit: n.ConnectionLimit,
+		validUntil:      validUntil,
+		noLogin:         noLogin,
+		noInherit:       noInherit,
 	}, nil
 }
 
@@ -323,7 +399,9 @@ const usernameHelp = "usernames are case insensitive, must start with a letter "
 var usernameRE = regexp.MustCompile(`^[\p{Ll}_][\p{Ll}0-9_]{0,62}$`)
 
 var blacklistedUsernames = map[string]struct{}{
-	security.NodeUser: {},
+	security.NodeUser:   {},
+	security.PublicRole: {},
+	security.AdminRole:  {},
 }
 
 // NormalizeAndValidateUsername case folds the specified username and verifies
@@ -342,7 +420,7 @@ func NormalizeAndValidateUsername(username string) (string, error) {
 var errNoUserNameSpecified = errors.New("no username specified")
 
 func (n *createUserNode) Start(params runParams) error {
-	normalizedUsername, hashedPassword, err := n.userAuthInfo.resolve()
+	normalizedUsername, hashedPassword, err := n.userAuthInfo.resolve(params.p.ExecCfg().Settings)
 	if err != nil {
 		return err
 	}
@@ -369,7 +447,7 @@ func (n *createUserNode) Start(params runParams) error {
 				n.rowsAffected = 0
 				return nil
 			}
-			err = errors.Errorf("user %s already exists", normalizedUsername)
+			err = errors.Errorf("%s %s already exists", n.typeName(), normalizedUsername)
 		}
 		return err
 	} else if n.rowsAffected != 1 {
@@ -378,7 +456,80 @@ func (n *createUserNode) Start(params runParams) error {
 		)
 	}
 
-	return nil
+	if n.connectionLimit != nil {
+		if err := setConnectionLimit(params, normalizedUsername, *n.connectionLimit); err != nil {
+			return err
+		}
+	}
+	if n.validUntil != nil {
+		validUntil, err := n.validUntil()
+		if err != nil {
+			return err
+		}
+		if err := setValidUntil(params, normalizedUsername, validUntil); err != nil {
+			return err
+		}
+	}
+	if n.noLogin {
+		if err := setRoleOption(params, normalizedUsername, roleOptionNoLogin, nil); err != nil {
+			return err
+		}
+	}
+	if n.noInherit {
+		if err := setRoleOption(params, normalizedUsername, roleOptionNoInherit, nil); err != nil {
+			return err
+		}
+	}
+
+	eventType := EventLogCreateUser
+	if n.isRole {
+		eventType = EventLogCreateRole
+	}
+	return logRoleEvent(params, eventType, &sqlbase.CreateRoleEvent{
+		RoleName:  normalizedUsername,
+		Statement: n.n.String(),
+		User:      params.p.session.User,
+	})
+}
+
+func (n *createUserNode) typeName() string {
+	if n.isRole {
+		return "role"
+	}
+	return "user"
+}
+
+// logRoleEvent records the creation, the removal or the alteration of a user
+// or role in the event log. This is an auditable log event and is recorded in
+// the same transaction as the changes of system.users and
+// system.role_options.
+func logRoleEvent(params runParams, eventType EventLogType, info sqlbase.EventLogInfo) error {
+	return MakeEventLogger(params.p.LeaseMgr()).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		eventType,
+		0, /* no target */
+		int32(params.evalCtx.NodeID),
+		info,
+	)
+}
+
+// logAlterUserEvent records the alteration of a user or role in the event
+// log.
+func logAlterUserEvent(params runParams, stmt tree.Statement, name string) error {
+	return logRoleEvent(params, EventLogAlterUser, &sqlbase.AlterRoleEvent{
+		RoleName:  name,
+		Statement: stmt.String(),
+		User:      params.p.session.User,
+	})
+}
+
+// setConnectionLimit records the connection limit of a user in
+// system.role_options.
+func setConnectionLimit(params runParams, normalizedUsername string, limit int64) error {
+	return setRoleOption(
+		params, normalizedUsername, roleOptionConnectionLimit, strconv.FormatInt(limit, 10),
+	)
 }
 
 func (n *createUserNode) FastPathResults() (int, bool) { return n.rowsAffected, true }
@@ -388,6 +539,7 @@ func (*createUserNode) Values() tree.Datums            { return tree.Datums{} }
 
 // alterUserSetPasswordNode represents an ALTER USER ... WITH PASSWORD statement.
 type alterUserSetPasswordNode struct {
+	n *tree.AlterUserSetPassword
 	userAuthInfo
 	ifExists     bool
 	rowsAffected int
@@ -411,6 +563,7 @@ func (p *planner) AlterUserSetPassword(
 	}
 
 	return &alterUserSetPasswordNode{
+		n:            n,
 		userAuthInfo: ua,
 		ifExists:     n.IfExists,
 	}, nil
@@ -421,7 +574,7 @@ func (n *alterUserSetPasswordNode) FastPathResults() (int, bool) {
 }
 
 func (n *alterUserSetPasswordNode) Start(params runParams) error {
-	normalizedUsername, hashedPassword, err := n.userAuthInfo.resolve()
+	normalizedUsername, hashedPassword, err := n.userAuthInfo.resolve(params.p.ExecCfg().Settings)
 	if err != nil {
 		return err
 	}
@@ -438,16 +591,409 @@ func (n *alterUserSetPasswordNode) Start(params runParams) error {
 	if err != nil {
 		return err
 	}
-	if n.rowsAffected == 0 && !n.ifExists {
+	if n.rowsAffected == 0 {
+		if n.ifExists {
+			return nil
+		}
 		return errors.Errorf("user %s does not exist", normalizedUsername)
 	}
-	return err
+	return logAlterUserEvent(params, n.n, normalizedUsername)
 }
 
 func (*alterUserSetPasswordNode) Next(runParams) (bool, error) { return false, nil }
 func (*alterUserSetPasswordNode) Close(context.Context)        {}
 func (*alterUserSetPasswordNode) Values() tree.Datums          { return tree.Datums{} }
 
+// alterUserSetConnectionLimitNode represents an ALTER USER ... WITH
+// CONNECTION LIMIT statement.
+type alterUserSetConnectionLimitNode struct {
+	n               *tree.AlterUserSetConnectionLimit
+	name            func() (string, error)
+	connectionLimit int64
+	ifExists        bool
+	rowsAffected    int
+}
+
+// AlterUserSetConnectionLimit changes the connection limit of a user.
+// Privileges: UPDATE on system.users.
+func (p *planner) AlterUserSetConnectionLimit(
+	ctx context.Context, n *tree.AlterUserSetConnectionLimit,
+) (planNode, error) {
+	tDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), &tree.TableName{DatabaseName: "system", TableName: "users"})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(tDesc, privilege.UPDATE); err != nil {
+		return nil, err
+	}
+
+	if err := validateConnectionLimit(n.ConnectionLimit); err != nil {
+		return nil, err
+	}
+
+	name, err := p.TypeAsString(n.Name, "ALTER USER")
+	if err != nil {
+		return nil, err
+	}
+
+	return &alterUserSetConnectionLimitNode{
+		n:               n,
+		name:            name,
+		connectionLimit: n.ConnectionLimit,
+		ifExists:        n.IfExists,
+	}, nil
+}
+
+func (n *alterUserSetConnectionLimitNode) FastPathResults() (int, bool) {
+	return n.rowsAffected, true
+}
+
+func (n *alterUserSetConnectionLimitNode) Start(params runParams) error {
+	name, err := n.name()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return errNoUserNameSpecified
+	}
+	normalizedUsername, err := NormalizeAndValidateUsername(name)
+	if err != nil {
+		return err
+	}
+	if normalizedUsername == security.RootUser {
+		return pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+			"cannot set the connection limit of user %s", security.RootUser)
+	}
+
+	internalExecutor := InternalExecutor{LeaseManager: params.p.LeaseMgr()}
+	row, err := internalExecutor.QueryRowInTransaction(
+		params.ctx,
+		"alter-user",
+		params.p.txn,
+		"SELECT 1 FROM system.users WHERE username = $1",
+		normalizedUsername,
+	)
+	if err != nil {
+		return err
+	}
+	if len(row) == 0 {
+		if n.ifExists {
+			return nil
+		}
+		return errors.Errorf("user %s does not exist", normalizedUsername)
+	}
+	n.rowsAffected = 1
+	if err := setConnectionLimit(params, normalizedUsername, n.connectionLimit); err != nil {
+		return err
+	}
+	return logAlterUserEvent(params, n.n, normalizedUsername)
+}
+
+func (*alterUserSetConnectionLimitNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterUserSetConnectionLimitNode) Close(context.Context)        {}
+func (*alterUserSetConnectionLimitNode) Values() tree.Datums          { return tree.Datums{} }
+
+// alterUserSetValidUntilNode represents an ALTER USER ... WITH VALID UNTIL
+// statement.
+type alterUserSetValidUntilNode struct {
+	n            *tree.AlterUserSetValidUntil
+	name         func() (string, error)
+	validUntil   func() (string, error)
+	ifExists     bool
+	rowsAffected int
+}
+
+// AlterUserSetValidUntil changes the time after which the password of a
+// user no longer authenticates it.
+// Privileges: UPDATE on system.users.
+func (p *planner) AlterUserSetValidUntil(
+	ctx context.Context, n *tree.AlterUserSetValidUntil,
+) (planNode, error) {
+	tDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), &tree.TableName{DatabaseName: "system", TableName: "users"})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(tDesc, privilege.UPDATE); err != nil {
+		return nil, err
+	}
+
+	name, err := p.TypeAsString(n.Name, "ALTER USER")
+	if err != nil {
+		return nil, err
+	}
+	validUntil, err := p.TypeAsString(n.ValidUntil, "ALTER USER")
+	if err != nil {
+		return nil, err
+	}
+
+	return &alterUserSetValidUntilNode{
+		n:          n,
+		name:       name,
+		validUntil: validUntil,
+		ifExists:   n.IfExists,
+	}, nil
+}
+
+func (n *alterUserSetValidUntilNode) FastPathResults() (int, bool) {
+	return n.rowsAffected, true
+}
+
+func (n *alterUserSetValidUntilNode) Start(params runParams) error {
+	name, err := n.name()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return errNoUserNameSpecified
+	}
+	normalizedUsername, err := NormalizeAndValidateUsername(name)
+	if err != nil {
+		return err
+	}
+	validUntil, err := n.validUntil()
+	if err != nil {
+		return err
+	}
+
+	internalExecutor := InternalExecutor{LeaseManager: params.p.LeaseMgr()}
+	row, err := internalExecutor.QueryRowInTransaction(
+		params.ctx,
+		"alter-user",
+		params.p.txn,
+		"SELECT 1 FROM system.users WHERE username = $1",
+		normalizedUsername,
+	)
+	if err != nil {
+		return err
+	}
+	if len(row) == 0 {
+		if n.ifExists {
+			return nil
+		}
+		return errors.Errorf("user %s does not exist", normalizedUsername)
+	}
+	n.rowsAffected = 1
+	if err := setValidUntil(params, normalizedUsername, validUntil); err != nil {
+		return err
+	}
+	return logAlterUserEvent(params, n.n, normalizedUsername)
+}
+
+func (*alterUserSetValidUntilNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterUserSetValidUntilNode) Close(context.Context)        {}
+func (*alterUserSetValidUntilNode) Values() tree.Datums          { return tree.Datums{} }
+
+// alterUserSetStatementsNode represents an ALTER USER ... WITH ALLOW
+// STATEMENTS, DENY STATEMENTS or ALLOW ALL STATEMENTS statement.
+type alterUserSetStatementsNode struct {
+	n    *tree.AlterUserSetStatements
+	name func() (string, error)
+	// option is the system.role_options option to set to value, or "" to
+	// remove the restrictions.
+	option       string
+	value        string
+	ifExists     bool
+	rowsAffected int
+}
+
+// AlterUserSetStatements changes the classes of statements a user may
+// execute.
+// Privileges: UPDATE on system.users.
+func (p *planner) AlterUserSetStatements(
+	ctx context.Context, n *tree.AlterUserSetStatements,
+) (planNode, error) {
+	tDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), &tree.TableName{DatabaseName: "system", TableName: "users"})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(tDesc, privilege.UPDATE); err != nil {
+		return nil, err
+	}
+
+	var option, value string
+	if n.Classes != nil {
+		if value, err = normalizeStatementClasses(n.Classes); err != nil {
+			return nil, err
+		}
+		option = roleOptionAllowStatements
+		if n.Deny {
+			option = roleOptionDenyStatements
+		}
+	}
+
+	name, err := p.TypeAsString(n.Name, "ALTER USER")
+	if err != nil {
+		return nil, err
+	}
+
+	return &alterUserSetStatementsNode{
+		n:        n,
+		name:     name,
+		option:   option,
+		value:    value,
+		ifExists: n.IfExists,
+	}, nil
+}
+
+func (n *alterUserSetStatementsNode) FastPathResults() (int, bool) {
+	return n.rowsAffected, true
+}
+
+func (n *alterUserSetStatementsNode) Start(params runParams) error {
+	name, err := n.name()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return errNoUserNameSpecified
+	}
+	normalizedUsername, err := NormalizeAndValidateUsername(name)
+	if err != nil {
+		return err
+	}
+	if normalizedUsername == security.RootUser {
+		return pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+			"cannot restrict the statements of user %s", security.RootUser)
+	}
+
+	internalExecutor := InternalExecutor{LeaseManager: params.p.LeaseMgr()}
+	row, err := internalExecutor.QueryRowInTransaction(
+		params.ctx,
+		"alter-user",
+		params.p.txn,
+		"SELECT 1 FROM system.users WHERE username = $1",
+		normalizedUsername,
+	)
+	if err != nil {
+		return err
+	}
+	if len(row) == 0 {
+		if n.ifExists {
+			return nil
+		}
+		return errors.Errorf("user %s does not exist", normalizedUsername)
+	}
+	n.rowsAffected = 1
+	if err := setStatementRestrictions(params, normalizedUsername, n.option, n.value); err != nil {
+		return err
+	}
+	return logAlterUserEvent(params, n.n, normalizedUsername)
+}
+
+func (*alterUserSetStatementsNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterUserSetStatementsNode) Close(context.Context)        {}
+func (*alterUserSetStatementsNode) Values() tree.Datums          { return tree.Datums{} }
+
+// alterUserSetQuotaNode represents an ALTER USER ... WITH QUOTA statement.
+type alterUserSetQuotaNode struct {
+	n    *tree.AlterUserSetQuota
+	name func() (string, error)
+	// option is the system.role_options option of the quota.
+	option       string
+	limit        int64
+	ifExists     bool
+	rowsAffected int
+}
+
+// AlterUserSetQuota changes a quota of a user or role.
+// Privileges: UPDATE on system.users.
+func (p *planner) AlterUserSetQuota(
+	ctx context.Context, n *tree.AlterUserSetQuota,
+) (planNode, error) {
+	tDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), &tree.TableName{DatabaseName: "system", TableName: "users"})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(tDesc, privilege.UPDATE); err != nil {
+		return nil, err
+	}
+
+	option, err := roleQuotaOption(n.Quota, n.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := p.TypeAsString(n.Name, "ALTER USER")
+	if err != nil {
+		return nil, err
+	}
+
+	return &alterUserSetQuotaNode{
+		n:        n,
+		name:     name,
+		option:   option,
+		limit:    n.Limit,
+		ifExists: n.IfExists,
+	}, nil
+}
+
+func (n *alterUserSetQuotaNode) FastPathResults() (int, bool) {
+	return n.rowsAffected, true
+}
+
+func (n *alterUserSetQuotaNode) Start(params runParams) error {
+	name, err := n.name()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return errNoUserNameSpecified
+	}
+	normalizedUsername, err := NormalizeAndValidateUsername(name)
+	if err != nil {
+		return err
+	}
+	if normalizedUsername == security.RootUser {
+		return pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+			"cannot set the quotas of user %s", security.RootUser)
+	}
+
+	internalExecutor := InternalExecutor{LeaseManager: params.p.LeaseMgr()}
+	row, err := internalExecutor.QueryRowInTransaction(
+		params.ctx,
+		"alter-user",
+		params.p.txn,
+		"SELECT 1 FROM system.users WHERE username = $1",
+		normalizedUsername,
+	)
+	if err != nil {
+		return err
+	}
+	if len(row) == 0 {
+		if n.ifExists {
+			return nil
+		}
+		return errors.Errorf("user %s does not exist", normalizedUsername)
+	}
+	n.rowsAffected = 1
+	if n.limit == -1 {
+		_, err := internalExecutor.ExecuteStatementInTransaction(
+			params.ctx,
+			"reset-role-quota",
+			params.p.txn,
+			"DELETE FROM system.role_options WHERE username = $1 AND option = $2",
+			normalizedUsername,
+			n.option,
+		)
+		if err != nil {
+			return err
+		}
+	} else if err := setRoleOption(
+		params, normalizedUsername, n.option, strconv.FormatInt(n.limit, 10),
+	); err != nil {
+		return err
+	}
+	return logAlterUserEvent(params, n.n, normalizedUsername)
+}
+
+func (*alterUserSetQuotaNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterUserSetQuotaNode) Close(context.Context)        {}
+func (*alterUserSetQuotaNode) Values() tree.Datums          { return tree.Datums{} }
+
 // createViewNode represents a CREATE VIEW statement.
 type createViewNode struct {
 	n             *tree.CreateView
@@ -549,7 +1095,7 @@ func (n *createViewNode) Start(params runParams) error {
 	}
 
 	// Inherit permissions from the database descriptor.
-	privs := n.dbDesc.GetPrivileges()
+	privs := params.p.newTablePrivileges(n.dbDesc)
 
 	desc, err := n.makeViewTableDesc(
 		params,
@@ -609,11 +1155,11 @@ func (n *createViewNode) Start(params runParams) error {
 		EventLogCreateView,
 		int32(desc.ID),
 		int32(params.evalCtx.NodeID),
-		struct {
-			ViewName  string
-			Statement string
-			User      string
-		}{n.n.Name.String(), n.n.String(), params.p.session.User},
+		&sqlbase.CreateViewEvent{
+			ViewName:  n.n.Name.String(),
+			Statement: n.n.String(),
+			User:      params.p.session.User,
+		},
 	)
 }
 
@@ -647,6 +1193,13 @@ func (p *planner) CreateTable(ctx context.Context, n *tree.CreateTable) (planNod
 		return nil, err
 	}
 
+	if n.Interleave != nil {
+		p.noteFeature("interleave")
+	}
+	if n.PartitionBy != nil {
+		p.noteFeature("partition_by")
+	}
+
 	HoistConstraints(n)
 	for _, def := range n.Defs {
 		switch t := def.(type) {
@@ -735,7 +1288,7 @@ func (n *createTableNode) Start(params runParams) error {
 
 	// If a new system table is being created (which should only be doable by
 	// an internal user account), make sure it gets the correct privileges.
-	privs := n.dbDesc.GetPrivileges()
+	privs := params.p.newTablePrivileges(n.dbDesc)
 	if n.dbDesc.ID == keys.SystemDatabaseID {
 		privs = sqlbase.NewDefaultPrivilegeDescriptor()
 	}
@@ -752,6 +1305,11 @@ func (n *createTableNode) Start(params runParams) error {
 	if err != nil {
 		return err
 	}
+	if n.n.Locality != nil {
+		if err := setTableLocality(params.ctx, params.evalCtx, n.dbDesc, &desc, *n.n.Locality); err != nil {
+			return err
+		}
+	}
 
 	// We need to validate again after adding the FKs.
 	// Only validate the table because backreferences aren't created yet.
@@ -764,6 +1322,11 @@ func (n *createTableNode) Start(params runParams) error {
 	if err := params.p.createDescriptorWithID(params.ctx, key, id, &desc); err != nil {
 		return err
 	}
+	if n.n.Locality != nil {
+		if err := params.p.writeTableLocalityZoneConfig(params.ctx, n.dbDesc, &desc); err != nil {
+			return err
+		}
+	}
 
 	for _, updated := range affected {
 		if err := params.p.saveNonmutationAndNotify(params.ctx, updated); err != nil {
@@ -794,11 +1357,11 @@ func (n *createTableNode) Start(params runParams) error {
 		EventLogCreateTable,
 		int32(desc.ID),
 		int32(params.evalCtx.NodeID),
-		struct {
-			TableName string
-			Statement string
-			User      string
-		}{n.n.Table.String(), n.n.String(), params.p.session.User},
+		&sqlbase.CreateTableEvent{
+			TableName: n.n.Table.String(),
+			Statement: n.n.String(),
+			User:      params.p.session.User,
+		},
 	); err != nil {
 		return err
 	}
@@ -947,7 +1510,7 @@ func resolveFK(
 		}
 	}
 
-	srcCols, err := tbl.FindActiveColumnsByNames(d.FromCols)
+	srcCols, srcColsAdded, err := fkSourceColumns(tbl, d.FromCols, mode)
 	if err != nil {
 		return err
 	}
@@ -1029,7 +1592,16 @@ func resolveFK(
 	}
 	backref := sqlbase.ForeignKeyReference{Table: tbl.ID}
 
-	if matchesIndex(srcCols, tbl.PrimaryIndex, matchPrefix) {
+	if srcColsAdded {
+		// Some of the columns are being added to the table. The foreign key is
+		// placed on an index being added along with them, and is validated by
+		// the schema changer once the columns and the index are backfilled.
+		added, err := addFKToIndexMutation(tbl, srcCols, constraintName, ref)
+		if err != nil {
+			return err
+		}
+		backref.Index = added
+	} else if matchesIndex(srcCols, tbl.PrimaryIndex, matchPrefix) {
 		if tbl.PrimaryIndex.ForeignKey.IsSet() {
 			return pgerror.NewErrorf(pgerror.CodeInvalidForeignKeyError,
 				"columns cannot be used by multiple foreign key constraints")
@@ -1105,6 +1677,85 @@ func addIndexForFK(
 	return added.ID, nil
 }
 
+// fkSourceColumns returns the descriptors of the referencing columns of a
+// foreign key, and whether some of them are being added to the table by the
+// current statement. Columns being added are only accepted for foreign keys
+// created unvalidated, that is by ALTER TABLE.
+func fkSourceColumns(
+	tbl *sqlbase.TableDescriptor, names tree.NameList, mode sqlbase.ConstraintValidity,
+) ([]sqlbase.ColumnDescriptor, bool, error) {
+	cols, err := tbl.FindActiveColumnsByNames(names)
+	if err == nil || mode != sqlbase.ConstraintValidity_Unvalidated {
+		return cols, false, err
+	}
+	cols = make([]sqlbase.ColumnDescriptor, len(names))
+	added := false
+	for i, name := range names {
+		if c, activeErr := tbl.FindActiveColumnByName(string(name)); activeErr == nil {
+			cols[i] = c
+			continue
+		}
+		found := false
+		for _, m := range tbl.Mutations {
+			if c := m.GetColumn(); c != nil && c.Name == string(name) &&
+				m.Direction == sqlbase.DescriptorMutation_ADD && m.MutationID == tbl.NextMutationID {
+				cols[i] = *c
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false, err
+		}
+		added = true
+	}
+	return cols, added, nil
+}
+
+// addFKToIndexMutation places a foreign key on an index being added to the
+// table by the current statement, adding an index for it if none of them
+// supports using `srcCols` as the referencing side of the foreign key. The
+// foreign key is unvalidated until the schema changer validates it.
+func addFKToIndexMutation(
+	tbl *sqlbase.TableDescriptor,
+	srcCols []sqlbase.ColumnDescriptor,
+	constraintName string,
+	ref sqlbase.ForeignKeyReference,
+) (sqlbase.IndexID, error) {
+	ref.Validity = sqlbase.ConstraintValidity_Unvalidated
+	for _, m := range tbl.Mutations {
+		idx := m.GetIndex()
+		if idx == nil || m.Direction != sqlbase.DescriptorMutation_ADD ||
+			m.MutationID != tbl.NextMutationID || !matchesIndex(srcCols, *idx, matchPrefix) {
+			continue
+		}
+		if idx.ForeignKey.IsSet() {
+			return 0, pgerror.NewErrorf(pgerror.CodeInvalidForeignKeyError,
+				"columns cannot be used by multiple foreign key constraints")
+		}
+		idx.ForeignKey = ref
+		return idx.ID, nil
+	}
+
+	idx := sqlbase.IndexDescriptor{
+		Name:             fmt.Sprintf("%s_auto_index_%s", tbl.Name, constraintName),
+		ColumnNames:      make([]string, len(srcCols)),
+		ColumnDirections: make([]sqlbase.IndexDescriptor_Direction, len(srcCols)),
+		ForeignKey:       ref,
+	}
+	for i, c := range srcCols {
+		idx.ColumnDirections[i] = sqlbase.IndexDescriptor_ASC
+		idx.ColumnNames[i] = c.Name
+	}
+	if err := tbl.AddIndexMutation(idx, sqlbase.DescriptorMutation_ADD); err != nil {
+		return 0, err
+	}
+	if err := tbl.AllocateIDs(); err != nil {
+		return 0, err
+	}
+	return tbl.Mutations[len(tbl.Mutations)-1].GetIndex().ID, nil
+}
+
 // colNames converts a []colDesc to a human-readable string for use in error messages.
 func colNames(cols []sqlbase.ColumnDescriptor) string {
 	var s bytes.Buffer
@@ -1427,6 +2078,7 @@ func (n *createViewNode) makeViewTableDesc(
 ) (sqlbase.TableDescriptor, error) {
 	desc := initTableDescriptor(id, parentID, viewName, params.p.txn.OrigTimestamp(), privileges)
 	desc.ViewQuery = tree.AsStringWithFlags(n.n.AsSource, tree.FmtParsable)
+	desc.ViewSecurityInvoker = n.n.SecurityInvoker
 	for i, colRes := range resultColumns {
 		colType, err := coltypes.DatumTypeToColumnType(colRes.Typ)
 		if err != nil {
@@ -1723,7 +2375,7 @@ func (p *planner) makeTableDesc(
 	privileges *sqlbase.PrivilegeDescriptor,
 	affected map[sqlbase.ID]*sqlbase.TableDescriptor,
 ) (sqlbase.TableDescriptor, error) {
-	return MakeTableDesc(
+	desc, err := MakeTableDesc(
 		ctx,
 		p.txn,
 		&p.session.virtualSchemas,
@@ -1737,6 +2389,15 @@ func (p *planner) makeTableDesc(
 		&p.semaCtx,
 		&p.evalCtx,
 	)
+	if err != nil {
+		return desc, err
+	}
+	for i := range desc.Columns {
+		if err := p.updateSequenceDependencies(ctx, &desc, &desc.Columns[i], affected); err != nil {
+			return desc, err
+		}
+	}
+	return desc, nil
 }
 
 // dummyColumnItem is used in makeCheckConstraint to construct an expression
@@ -1912,7 +2573,7 @@ func (n *createSequenceNode) Start(params runParams) error {
 	}
 
 	// Inherit permissions from the database descriptor.
-	privs := n.dbDesc.GetPrivileges()
+	privs := params.p.newTablePrivileges(n.dbDesc)
 
 	desc, err := n.makeSequenceTableDesc(params, seqName, n.dbDesc.ID, id, privs)
 	if err != nil {
@@ -1950,11 +2611,11 @@ func (n *createSequenceNode) Start(params runParams) error {
 		EventLogCreateSequence,
 		int32(desc.ID),
 		int32(params.evalCtx.NodeID),
-		struct {
-			SequenceName string
-			Statement    string
-			User         string
-		}{n.n.Name.String(), n.n.String(), params.p.session.User},
+		&sqlbase.CreateSequenceEvent{
+			SequenceName: n.n.Name.String(),
+			Statement:    n.n.String(),
+			User:         params.p.session.User,
+		},
 	)
 }
 
@@ -0,0 +1,273 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+)
+
+// This file implements the DECLARE, FETCH, MOVE and CLOSE statements.
+//
+// Cursors are read-only and can only be declared inside a transaction
+// block; they are closed when the transaction ends (there is no support
+// for WITH HOLD). The query of a cursor is planned and started when the
+// cursor is declared, and its plan is then advanced by each FETCH and
+// MOVE, so that only the rows that are requested are computed. Cursors can
+// only move forward.
+//
+// Because the plan runs in the transaction that declared the cursor, its
+// rows may reflect the writes performed by the transaction after the cursor
+// was declared.
+
+// sqlCursor is a cursor declared with DECLARE.
+type sqlCursor struct {
+	// p is the planner used to plan and run the cursor's query. It is
+	// distinct from the planners of the statements that use the cursor
+	// because it lives as long as the cursor.
+	p    *planner
+	plan planNode
+	// acc accounts for the constants folded while planning the query.
+	acc mon.BoundAccount
+
+	// pos is the position of the cursor: 0 before the first row, n on the
+	// n-th row, and one past the last row once the query is exhausted.
+	pos int64
+	// done is set once the query has been exhausted.
+	done bool
+	// row is the row the cursor is positioned on.
+	row tree.Datums
+}
+
+// next advances the cursor by one row. It returns false once the cursor has
+// moved past the last row.
+func (c *sqlCursor) next(ctx context.Context) (bool, error) {
+	if c.done {
+		return false, nil
+	}
+	next, err := c.plan.Next(runParams{ctx: ctx, evalCtx: &c.p.evalCtx, p: c.p})
+	if err != nil {
+		return false, err
+	}
+	c.pos++
+	if !next {
+		c.done = true
+		c.row = nil
+		return false, nil
+	}
+	c.row = append(c.row[:0], c.plan.Values()...)
+	return true, nil
+}
+
+func (c *sqlCursor) close(ctx context.Context) {
+	c.plan.Close(ctx)
+	c.acc.Close(ctx)
+}
+
+// closeCursors closes all the cursors declared in the transaction.
+func (ts *txnState) closeCursors() {
+	for name, c := range ts.cursors {
+		c.close(ts.Ctx)
+		delete(ts.cursors, name)
+	}
+}
+
+var errCursorScrollNotSupported = pgerror.NewError(pgerror.CodeObjectNotInPrerequisiteStateError,
+	"cursor can only scan forward")
+
+func (p *planner) lookupCursor(name tree.Name) (*sqlCursor, error) {
+	c, ok := p.session.TxnState.cursors[name]
+	if !ok {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidCursorNameError,
+			"cursor %q does not exist", string(name))
+	}
+	return c, nil
+}
+
+// DeclareCursor implements the DECLARE statement.
+// See https://www.postgresql.org/docs/current/static/sql-declare.html for details.
+// Privileges: those of the cursor's query.
+func (p *planner) DeclareCursor(ctx context.Context, n *tree.DeclareCursor) (planNode, error) {
+	ts := &p.session.TxnState
+	if ts.implicitTxn {
+		return nil, pgerror.NewError(pgerror.CodeNoActiveSQLTransactionError,
+			"DECLARE CURSOR can only be used in transaction blocks")
+	}
+	if _, ok := ts.cursors[n.Name]; ok {
+		return nil, pgerror.NewErrorf(pgerror.CodeDuplicateCursorError,
+			"cursor %q already exists", string(n.Name))
+	}
+
+	cp := p.session.newPlanner(nil /* executor */, p.txn)
+	cp.evalCtx.ClusterID = p.evalCtx.ClusterID
+	cp.evalCtx.NodeID = p.evalCtx.NodeID
+	cp.evalCtx.ReCache = p.evalCtx.ReCache
+	cp.evalCtx.TxnTimestamp = p.evalCtx.TxnTimestamp
+	cp.evalCtx.StmtTimestamp = p.evalCtx.StmtTimestamp
+	cp.semaCtx.Placeholders = p.semaCtx.Placeholders
+	cp.evalCtx.Placeholders = &cp.semaCtx.Placeholders
+	cp.avoidCachedDescriptors = p.avoidCachedDescriptors
+	cp.cancelChecker = p.cancelChecker
+
+	c := &sqlCursor{p: cp, acc: p.evalCtx.Mon.MakeBoundAccount()}
+	cp.evalCtx.ActiveMemAcc = &c.acc
+
+	plan, err := cp.newPlan(ctx, n.Select, nil /* desiredTypes */)
+	if err != nil {
+		c.acc.Close(ctx)
+		return nil, err
+	}
+	c.plan = plan
+	if err := cp.semaCtx.Placeholders.AssertAllAssigned(); err != nil {
+		c.close(ctx)
+		return nil, err
+	}
+	// The plan must be closed even if optimizing or starting it fails, since
+	// it may contain monitor-registered memory.
+	c.plan, err = cp.optimizePlan(ctx, plan, allColumns(plan))
+	if err == nil {
+		err = cp.startPlan(ctx, c.plan)
+	}
+	if err != nil {
+		c.close(ctx)
+		return nil, err
+	}
+
+	if ts.cursors == nil {
+		ts.cursors = make(map[tree.Name]*sqlCursor)
+	}
+	ts.cursors[n.Name] = c
+	return &zeroNode{}, nil
+}
+
+// CloseCursor implements the CLOSE statement.
+// See https://www.postgresql.org/docs/current/static/sql-close.html for details.
+// Privileges: None.
+func (p *planner) CloseCursor(ctx context.Context, n *tree.CloseCursor) (planNode, error) {
+	ts := &p.session.TxnState
+	if n.Name == "" {
+		ts.closeCursors()
+		return &zeroNode{}, nil
+	}
+	c, err := p.lookupCursor(n.Name)
+	if err != nil {
+		return nil, err
+	}
+	c.close(ctx)
+	delete(ts.cursors, n.Name)
+	return &zeroNode{}, nil
+}
+
+// FetchCursor implements the FETCH and MOVE statements.
+// See https://www.postgresql.org/docs/current/static/sql-fetch.html for details.
+// Privileges: None.
+func (p *planner) FetchCursor(ctx context.Context, n *tree.FetchCursor) (planNode, error) {
+	c, err := p.lookupCursor(n.Name)
+	if err != nil {
+		return nil, err
+	}
+	node := &fetchNode{n: n, cursor: c}
+	if !n.Move {
+		node.columns = planColumns(c.plan)
+	}
+	return node, nil
+}
+
+// fetchNode produces the rows of a FETCH statement, or counts the rows
+// skipped by a MOVE statement.
+type fetchNode struct {
+	n       *tree.FetchCursor
+	cursor  *sqlCursor
+	columns sqlbase.ResultColumns
+
+	// current is set if the statement re-fetches the current row.
+	current bool
+	// skip is the number of rows to move past before producing rows.
+	skip int64
+	// rows is the number of rows left to produce.
+	rows int64
+}
+
+func (n *fetchNode) Start(params runParams) error {
+	c := n.cursor
+	count := n.n.Count
+	switch n.n.Direction {
+	case tree.FetchForward:
+		if count < 0 {
+			return errCursorScrollNotSupported
+		}
+		n.current = count == 0
+		n.rows = count
+	case tree.FetchBackward:
+		if count != 0 {
+			return errCursorScrollNotSupported
+		}
+		n.current = true
+	case tree.FetchRelative:
+		if count < 0 {
+			return errCursorScrollNotSupported
+		}
+		if count == 0 {
+			n.current = true
+		} else {
+			n.skip, n.rows = count-1, 1
+		}
+	case tree.FetchAbsolute:
+		if count < c.pos {
+			return errCursorScrollNotSupported
+		}
+		if count == c.pos {
+			n.current = true
+		} else {
+			n.skip, n.rows = count-c.pos-1, 1
+		}
+	}
+	return nil
+}
+
+func (n *fetchNode) Next(params runParams) (bool, error) {
+	c := n.cursor
+	// The cursor's query is run on behalf of the current statement, which is
+	// the one that can be canceled.
+	c.p.cancelChecker = params.p.cancelChecker
+	if n.current {
+		n.current = false
+		return c.pos > 0 && !c.done, nil
+	}
+	for ; n.skip > 0; n.skip-- {
+		if next, err := c.next(params.ctx); !next || err != nil {
+			n.rows = 0
+			return false, err
+		}
+	}
+	if n.rows == 0 {
+		return false, nil
+	}
+	n.rows--
+	return c.next(params.ctx)
+}
+
+func (n *fetchNode) Values() tree.Datums {
+	if n.n.Move {
+		return nil
+	}
+	return n.cursor.row
+}
+
+func (*fetchNode) Close(context.Context) {}
@@ -404,6 +404,11 @@ func (p *planner) getDataSource(
 		return p.makeJoin(ctx, t.Join, left, right, t.Cond)
 
 	case *tree.StatementSource:
+		// The statement is subject to the classes of statements allowed to
+		// the user like the statement using it as a data source.
+		if err := p.session.statementRestrictions.check(p.session.User, t.Statement); err != nil {
+			return planDataSource{}, err
+		}
 		plan, err := p.newPlan(ctx, t.Statement, nil)
 		if err != nil {
 			return planDataSource{}, err
@@ -612,14 +617,14 @@ func (p *planner) getPlanForDesc(
 
 	// This name designates a real table.
 	scan := p.Scan()
-	if err := scan.initTable(p, desc, hints, scanVisibility, wantedColumns); err != nil {
+	if err := scan.initTable(ctx, p, desc, hints, scanVisibility, wantedColumns); err != nil {
 		return planDataSource{}, err
 	}
 
-	return planDataSource{
+	return p.addRowLevelSecurityScanFilter(ctx, desc, planDataSource{
 		info: newSourceInfoForSingleTable(*tn, planColumns(scan)),
 		plan: scan,
-	}, nil
+	})
 }
 
 // getViewPlan builds a planDataSource for the view specified by the
@@ -637,17 +642,20 @@ func (p *planner) getViewPlan(
 			errors.Errorf("failed to parse underlying query from view %q as a select", tn)
 	}
 
-	// When constructing the subquery plan, we don't want to check for the SELECT
-	// privilege on the underlying tables, just on the view itself. Checking on
-	// the underlying tables as well would defeat the purpose of having separate
-	// SELECT privileges on the view, which is intended to allow for exposing
-	// some subset of a restricted table's data to less privileged users.
-	if !p.skipSelectPrivilegeChecks {
-		if err := p.CheckPrivilege(desc, privilege.SELECT); err != nil {
-			return planDataSource{}, err
-		}
-		p.skipSelectPrivilegeChecks = true
-		defer func() { p.skipSelectPrivilegeChecks = false }()
+	if err := p.checkDatabaseUsage(ctx, desc); err != nil {
+		return planDataSource{}, err
+	}
+	if err := p.CheckPrivilege(desc, privilege.SELECT); err != nil {
+		return planDataSource{}, err
+	}
+	// Unless the view was created with SECURITY INVOKER, the subquery plan is
+	// constructed with the privileges of the owner of the view rather than the
+	// current user's. This allows for exposing some subset of a restricted
+	// table's data to less privileged users, who only need the SELECT
+	// privilege on the view.
+	if !desc.ViewSecurityInvoker {
+		defer func(prev string) { p.viewOwner = prev }(p.viewOwner)
+		p.viewOwner = desc.Privileges.GetOwner()
 	}
 
 	// Register the dependency to the planner, if requested.
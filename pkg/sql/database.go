@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -79,10 +80,34 @@ func (dc *databaseCache) setID(name string, id sqlbase.ID) {
 func makeDatabaseDesc(p *tree.CreateDatabase) sqlbase.DatabaseDescriptor {
 	return sqlbase.DatabaseDescriptor{
 		Name:       string(p.Name),
-		Privileges: sqlbase.NewDefaultPrivilegeDescriptor(),
+		Privileges: sqlbase.NewDefaultDatabasePrivilegeDescriptor(),
 	}
 }
 
+// GrantDefaultPublicDatabasePrivileges grants the
+// DefaultPublicDatabasePrivileges to the public role on the existing
+// databases, so that the users keep accessing the databases created before
+// the CONNECT and USAGE privileges were checked. Used by a migration.
+func GrantDefaultPublicDatabasePrivileges(ctx context.Context, txn *client.Txn) error {
+	if err := txn.SetSystemConfigTrigger(); err != nil {
+		return err
+	}
+	descs, err := getAllDescriptors(ctx, txn)
+	if err != nil {
+		return err
+	}
+	b := txn.NewBatch()
+	for _, desc := range descs {
+		dbDesc, ok := desc.(*sqlbase.DatabaseDescriptor)
+		if !ok || dbDesc.ID == keys.SystemDatabaseID {
+			continue
+		}
+		dbDesc.Privileges.Grant(security.PublicRole, sqlbase.DefaultPublicDatabasePrivileges)
+		b.Put(sqlbase.MakeDescMetadataKey(dbDesc.ID), sqlbase.WrapDescriptor(dbDesc))
+	}
+	return txn.Run(ctx, b)
+}
+
 // getKeysForDatabaseDescriptor retrieves the KV keys corresponding to
 // the zone, name and descriptor of a database.
 func getKeysForDatabaseDescriptor(
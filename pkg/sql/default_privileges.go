@@ -0,0 +1,199 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+)
+
+// AlterDefaultPrivileges changes the privileges granted on the tables, views
+// and sequences that the given roles create in the given databases.
+// Privileges: CREATE on the databases, and membership in the roles.
+//   Notes: postgres only requires the membership in the roles.
+func (p *planner) AlterDefaultPrivileges(
+	ctx context.Context, n *tree.AlterDefaultPrivileges,
+) (planNode, error) {
+	if err := sqlbase.ValidatePrivileges(n.Privileges, privilege.Table); err != nil {
+		return nil, pgerror.NewError(pgerror.CodeInvalidGrantOperationError, err.Error())
+	}
+	roles, err := p.getDefaultPrivilegesRoles(ctx, n.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := n.Schemas
+	if schemas == nil {
+		if p.session.Database == "" {
+			return nil, errNoDatabase
+		}
+		schemas = tree.NameList{tree.Name(p.session.Database)}
+	}
+
+	var descs []*sqlbase.DatabaseDescriptor
+	for _, schema := range schemas {
+		dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), string(schema))
+		if err != nil {
+			return nil, err
+		}
+		if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
+			return nil, err
+		}
+		for _, role := range roles {
+			changeDefaultPrivileges(dbDesc, role, n.Grantees, func(privs *sqlbase.PrivilegeDescriptor, grantee string) {
+				if n.IsGrant {
+					privs.Grant(grantee, n.Privileges)
+				} else {
+					privs.Revoke(grantee, n.Privileges, privilege.Table)
+				}
+			})
+		}
+		if err := dbDesc.Validate(); err != nil {
+			return nil, err
+		}
+		descs = append(descs, dbDesc)
+	}
+
+	b := p.txn.NewBatch()
+	for _, dbDesc := range descs {
+		b.Put(sqlbase.MakeDescMetadataKey(dbDesc.ID), sqlbase.WrapDescriptor(dbDesc))
+	}
+	if err := p.txn.Run(ctx, b); err != nil {
+		return nil, err
+	}
+
+	// Record the changes of the default privileges in the event log. This is
+	// an auditable log event and is recorded in the same transaction as the
+	// descriptor updates.
+	for _, dbDesc := range descs {
+		if err := MakeEventLogger(p.LeaseMgr()).InsertEventRecord(
+			ctx,
+			p.txn,
+			EventLogAlterDefaultPrivileges,
+			int32(dbDesc.ID),
+			int32(p.evalCtx.NodeID),
+			&sqlbase.ChangePrivilegesEvent{
+				TargetName: dbDesc.Name,
+				Grantees:   n.Grantees.ToStrings(),
+				Privileges: n.Privileges.SortedNames(),
+				Statement:  n.String(),
+				User:       p.session.User,
+			},
+		); err != nil {
+			return nil, err
+		}
+	}
+	return &zeroNode{}, nil
+}
+
+// getDefaultPrivilegesRoles returns the normalized names of the roles whose
+// default privileges are changed, and checks that the session user is a
+// member of them.
+func (p *planner) getDefaultPrivilegesRoles(
+	ctx context.Context, names tree.NameList,
+) ([]string, error) {
+	if names == nil {
+		return []string{p.session.User}, nil
+	}
+	var memberOf map[string]bool
+	if p.session.User != security.RootUser {
+		g, err := p.loadRoleGraph(ctx)
+		if err != nil {
+			return nil, err
+		}
+		memberOf = g.memberOf(p.session.User, false /* inheritingOnly */)
+	}
+	roles := make([]string, len(names))
+	for i, name := range names {
+		role := name.Normalize()
+		if err := p.checkRoleExists(ctx, role); err != nil {
+			return nil, err
+		}
+		if p.session.User != security.RootUser && role != p.session.User {
+			if _, ok := memberOf[role]; !ok {
+				return nil, pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+					"user %s must be a member of role %s", p.session.User, role)
+			}
+		}
+		roles[i] = role
+	}
+	return roles, nil
+}
+
+// changeDefaultPrivileges applies changePrivilege to the default privileges
+// of each grantee on the tables created by the role in the database.
+func changeDefaultPrivileges(
+	dbDesc *sqlbase.DatabaseDescriptor,
+	role string,
+	grantees tree.NameList,
+	changePrivilege func(*sqlbase.PrivilegeDescriptor, string),
+) {
+	idx := -1
+	for i := range dbDesc.DefaultPrivileges {
+		if dbDesc.DefaultPrivileges[i].Role == role {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		dbDesc.DefaultPrivileges = append(dbDesc.DefaultPrivileges,
+			sqlbase.DatabaseDescriptor_DefaultPrivileges{Role: role})
+		idx = len(dbDesc.DefaultPrivileges) - 1
+	}
+	rule := &dbDesc.DefaultPrivileges[idx]
+	for _, grantee := range grantees {
+		changePrivilege(&rule.Tables, string(grantee))
+	}
+	if len(rule.Tables.Users) == 0 {
+		dbDesc.DefaultPrivileges = append(dbDesc.DefaultPrivileges[:idx], dbDesc.DefaultPrivileges[idx+1:]...)
+	}
+}
+
+// newTablePrivileges returns the privileges of a table, view or sequence
+// created by the session user in the database: the privileges on the
+// database that apply to tables, plus the default privileges of the session
+// user in the database. The session user owns the new table.
+func (p *planner) newTablePrivileges(
+	dbDesc *sqlbase.DatabaseDescriptor,
+) *sqlbase.PrivilegeDescriptor {
+	privs := protoutil.Clone(dbDesc.GetPrivileges()).(*sqlbase.PrivilegeDescriptor)
+	tableBits := privilege.TablePrivileges.ToBitField()
+	users := privs.Users[:0]
+	for _, u := range privs.Users {
+		if u.Privileges&privilege.ALL.Mask() == 0 {
+			u.Privileges &= tableBits
+		}
+		if u.Privileges != 0 {
+			users = append(users, u)
+		}
+	}
+	privs.Users = users
+	privs.Owner = p.session.User
+	for _, rule := range dbDesc.DefaultPrivileges {
+		if rule.Role != p.session.User {
+			continue
+		}
+		for _, u := range rule.Tables.Users {
+			privs.Grant(u.User, privilege.ListFromBitField(u.Privileges))
+		}
+	}
+	return privs
+}
@@ -91,10 +91,14 @@ func (p *planner) Delete(
 	// this node's initSelect() method both does type checking and also
 	// performs index selection. We cannot perform index selection
 	// properly until the placeholder values are known.
+	where, err := p.addRowLevelSecurityFilter(ctx, en.tableDesc, policyCommandDelete, n.Where)
+	if err != nil {
+		return nil, err
+	}
 	rows, err := p.SelectClause(ctx, &tree.SelectClause{
 		Exprs: sqlbase.ColumnsSelectors(rd.FetchCols),
 		From:  &tree.From{Tables: []tree.TableExpr{n.Table}},
-		Where: n.Where,
+		Where: where,
 	}, n.OrderBy, n.Limit, nil, publicAndNonPublicColumns)
 	if err != nil {
 		return nil, err
@@ -213,10 +217,6 @@ func canDeleteWithoutScan(
 // `rows` would scan. Should only be used if `canDeleteWithoutScan` indicates
 // that it is safe to do so.
 func (d *deleteNode) fastDelete(params runParams, scan *scanNode) error {
-	if err := scan.initScan(params); err != nil {
-		return err
-	}
-
 	if err := d.tw.init(params.p.txn); err != nil {
 		return err
 	}
@@ -32,16 +32,21 @@ func (p *planner) Discard(ctx context.Context, s *tree.Discard) (planNode, error
 		}
 
 		// RESET ALL
-		for _, v := range varGen {
-			if v.Reset != nil {
-				if err := v.Reset(p.session); err != nil {
-					return nil, err
-				}
-			}
+		if err := resetAllSessionVars(p.session); err != nil {
+			return nil, err
 		}
 
 		// DEALLOCATE ALL
 		p.session.PreparedStatements.DeleteAll(ctx)
+
+		// UNLISTEN *
+		p.session.notifications.unlistenAll()
+
+	case tree.DiscardModePlans, tree.DiscardModeSequences, tree.DiscardModeTemp:
+		// We don't cache plans or sequence values and don't have temporary
+		// tables, so there is nothing to discard. These are accepted for the
+		// benefit of connection poolers that issue them between clients.
+
 	default:
 		return nil, pgerror.NewErrorf(pgerror.CodeInternalError,
 			"unknown mode for DISCARD: %d", s.Mode)
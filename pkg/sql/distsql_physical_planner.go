@@ -99,12 +99,33 @@ var distributeIndexJoin = settings.RegisterBoolSetting(
 	true,
 )
 
+// If true, the final stage of a distributed DISTINCT is made up of one
+// processor on each node that produced results in the previous stage, with the
+// rows hashed by the distinct columns among them. If false, the final stage is a
+// single processor on the gateway.
+var distributeDistinct = settings.RegisterBoolSetting(
+	"sql.distsql.distribute_distinct.enabled",
+	"if set, the final stage of DISTINCT is spread by hash across the nodes "+
+		"producing its input; if not set, it runs on the gateway",
+	false,
+)
+
 var planMergeJoins = settings.RegisterBoolSetting(
 	"sql.distsql.merge_joins.enabled",
 	"if set, we plan merge joins when possible",
 	true,
 )
 
+// tableReadersPerNode is the maximum number of table readers planned on each
+// node for a scan. When it is greater than one, the ranges assigned to a node
+// are split between several table readers, which scan them concurrently.
+var tableReadersPerNode = settings.RegisterIntSetting(
+	"sql.distsql.table_readers_per_node",
+	"maximum number of table readers that concurrently scan the ranges of a "+
+		"table assigned to the same node",
+	1,
+)
+
 // NewDistSQLPlanner initializes a DistSQLPlanner
 func NewDistSQLPlanner(
 	ctx context.Context,
@@ -358,7 +379,15 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		return dsp.checkSupportForNode(n.plan)
 
 	case *distinctNode:
-		return dsp.checkSupportForNode(n.plan)
+		rec, err := dsp.checkSupportForNode(n.plan)
+		if err != nil {
+			return 0, err
+		}
+		// If the deduplication can be spread across nodes, distribute the query.
+		if distributeDistinct.Get(&dsp.st.SV) {
+			rec = rec.compose(shouldDistribute)
+		}
+		return rec, nil
 
 	case *valuesNode:
 		if n.n == nil {
@@ -488,6 +517,16 @@ type spanPartition struct {
 // such nodes are assigned to the gateway.
 func (dsp *DistSQLPlanner) partitionSpans(
 	planCtx *planningCtx, spans roachpb.Spans,
+) ([]spanPartition, error) {
+	return dsp.partitionSpansImpl(planCtx, spans, true /* mergeRanges */)
+}
+
+// partitionSpansImpl implements partitionSpans. If mergeRanges is false, the
+// pieces of the spans belonging to consecutive ranges owned by the same node
+// are not merged, i.e. each span in a partition is contained in a single
+// range.
+func (dsp *DistSQLPlanner) partitionSpansImpl(
+	planCtx *planningCtx, spans roachpb.Spans, mergeRanges bool,
 ) ([]spanPartition, error) {
 	if len(spans) == 0 {
 		panic("no spans")
@@ -610,7 +649,7 @@ func (dsp *DistSQLPlanner) partitionSpans(
 			}
 			partition := &partitions[partitionIdx]
 
-			if lastNodeID == nodeID {
+			if mergeRanges && lastNodeID == nodeID {
 				// Two consecutive ranges on the same node, merge the spans.
 				partition.spans[len(partition.spans)-1].EndKey = endKey.AsRawKey()
 			} else {
@@ -741,7 +780,10 @@ func (dsp *DistSQLPlanner) createTableReaders(
 		return physicalPlan{}, err
 	}
 
-	spanPartitions, err := dsp.partitionSpans(planCtx, n.spans)
+	readersPerNode := int(tableReadersPerNode.Get(&dsp.st.SV))
+	spanPartitions, err := dsp.partitionSpansImpl(
+		planCtx, n.spans, readersPerNode <= 1, /* mergeRanges */
+	)
 	if err != nil {
 		return physicalPlan{}, err
 	}
@@ -749,26 +791,28 @@ func (dsp *DistSQLPlanner) createTableReaders(
 	var p physicalPlan
 	stageID := p.NewStageID()
 
-	p.ResultRouters = make([]distsqlplan.ProcessorIdx, len(spanPartitions))
-	for i, sp := range spanPartitions {
-		tr := &distsqlrun.TableReaderSpec{}
-		*tr = spec
-		tr.Spans = make([]distsqlrun.TableReaderSpan, len(sp.spans))
-		for j := range sp.spans {
-			tr.Spans[j].Span = sp.spans[j]
-		}
+	p.ResultRouters = make([]distsqlplan.ProcessorIdx, 0, len(spanPartitions))
+	for _, sp := range spanPartitions {
+		for _, spans := range splitSpans(sp.spans, readersPerNode) {
+			tr := &distsqlrun.TableReaderSpec{}
+			*tr = spec
+			tr.Spans = make([]distsqlrun.TableReaderSpan, len(spans))
+			for j := range spans {
+				tr.Spans[j].Span = spans[j]
+			}
 
-		proc := distsqlplan.Processor{
-			Node: sp.node,
-			Spec: distsqlrun.ProcessorSpec{
-				Core:    distsqlrun.ProcessorCoreUnion{TableReader: tr},
-				Output:  []distsqlrun.OutputRouterSpec{{Type: distsqlrun.OutputRouterSpec_PASS_THROUGH}},
-				StageID: stageID,
-			},
-		}
+			proc := distsqlplan.Processor{
+				Node: sp.node,
+				Spec: distsqlrun.ProcessorSpec{
+					Core:    distsqlrun.ProcessorCoreUnion{TableReader: tr},
+					Output:  []distsqlrun.OutputRouterSpec{{Type: distsqlrun.OutputRouterSpec_PASS_THROUGH}},
+					StageID: stageID,
+				},
+			}
 
-		pIdx := p.AddProcessor(proc)
-		p.ResultRouters[i] = pIdx
+			pIdx := p.AddProcessor(proc)
+			p.ResultRouters = append(p.ResultRouters, pIdx)
+		}
 	}
 
 	planToStreamColMap := identityMapInPlace(make([]int, len(n.resultColumns)))
@@ -801,6 +845,32 @@ func (dsp *DistSQLPlanner) createTableReaders(
 	return p, nil
 }
 
+// splitSpans splits the given ordered spans into at most n groups of
+// consecutive spans, each with roughly the same number of spans. If there is
+// more than one group, adjacent spans within a group are merged.
+func splitSpans(spans roachpb.Spans, n int) []roachpb.Spans {
+	if n <= 1 {
+		return []roachpb.Spans{spans}
+	}
+	if n > len(spans) {
+		n = len(spans)
+	}
+	groups := make([]roachpb.Spans, 0, n)
+	for i := 0; i < n; i++ {
+		start, end := i*len(spans)/n, (i+1)*len(spans)/n
+		var group roachpb.Spans
+		for _, sp := range spans[start:end] {
+			if last := len(group) - 1; last >= 0 && group[last].EndKey.Equal(sp.Key) {
+				group[last].EndKey = sp.EndKey
+				continue
+			}
+			group = append(group, sp)
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
 // selectRenders takes a physicalPlan that produces the results corresponding to
 // the select data source (a n.source) and updates it to produce results
 // corresponding to the render node itself. An evaluator stage is added if the
@@ -1548,23 +1618,10 @@ func (dsp *DistSQLPlanner) createPlanForJoin(
 
 	// Set up the output columns.
 	if numEq := len(n.pred.leftEqualityIndices); numEq != 0 {
-		// TODO(radu): for now we run a join processor on every node that produces
-		// data for either source. In the future we should be smarter here.
-		seen := make(map[roachpb.NodeID]struct{})
-		for _, pIdx := range leftRouters {
-			n := p.Processors[pIdx].Node
-			if _, ok := seen[n]; !ok {
-				seen[n] = struct{}{}
-				nodes = append(nodes, n)
-			}
-		}
-		for _, pIdx := range rightRouters {
-			n := p.Processors[pIdx].Node
-			if _, ok := seen[n]; !ok {
-				seen[n] = struct{}{}
-				nodes = append(nodes, n)
-			}
-		}
+		// Hash-partition both inputs on the equality columns across all the
+		// nodes that produce data for either of them, so that the join work is
+		// spread across the cluster and each bucket is joined locally.
+		nodes = findJoinProcessorNodes(leftRouters, rightRouters, p.Processors)
 
 		// Set up the equality columns.
 		leftEqCols = make([]uint32, numEq)
@@ -1761,6 +1818,28 @@ func (dsp *DistSQLPlanner) createPlanForJoin(
 	return p, nil
 }
 
+// findJoinProcessorNodes returns the set of nodes on which the processors of
+// a distributed hash join should be planned: every node that runs a result
+// router for either of the join inputs. Rows are hash-partitioned across
+// these nodes, so each join processor only sees its own bucket of both
+// inputs. The left input's nodes come first, in the order of the routers.
+func findJoinProcessorNodes(
+	leftRouters, rightRouters []distsqlplan.ProcessorIdx, processors []distsqlplan.Processor,
+) []roachpb.NodeID {
+	var nodes []roachpb.NodeID
+	seen := make(map[roachpb.NodeID]struct{})
+	for _, routers := range [][]distsqlplan.ProcessorIdx{leftRouters, rightRouters} {
+		for _, pIdx := range routers {
+			n := processors[pIdx].Node
+			if _, ok := seen[n]; !ok {
+				seen[n] = struct{}{}
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	return nodes
+}
+
 func (dsp *DistSQLPlanner) createPlanForNode(
 	planCtx *planningCtx, node planNode,
 ) (physicalPlan, error) {
@@ -1946,8 +2025,56 @@ func (dsp *DistSQLPlanner) createPlanForDistinct(
 	// Add distinct processors local to each existing current result processor.
 	plan.AddNoGroupingStage(distinctSpec, distsqlrun.PostProcessSpec{}, plan.ResultTypes, plan.MergeOrdering)
 
-	// TODO(arjun): We could distribute this final stage by hash.
-	plan.AddSingleGroupStage(dsp.nodeDesc.NodeID, distinctSpec, distsqlrun.PostProcessSpec{}, plan.ResultTypes)
+	if !distributeDistinct.Get(&dsp.st.SV) {
+		plan.AddSingleGroupStage(dsp.nodeDesc.NodeID, distinctSpec, distsqlrun.PostProcessSpec{}, plan.ResultTypes)
+		return plan, nil
+	}
+
+	// Rows that are equal on the distinct columns hash to the same bucket, so
+	// each final processor can deduplicate its share of the rows independently.
+	for _, resultProc := range plan.ResultRouters {
+		plan.Processors[resultProc].Spec.Output[0] = distsqlrun.OutputRouterSpec{
+			Type:        distsqlrun.OutputRouterSpec_BY_HASH,
+			HashColumns: distinctColumns,
+		}
+	}
+
+	stageID := plan.NewStageID()
+
+	// As for aggregations, we have one final stage processor for each result
+	// router.
+	pIdxStart := distsqlplan.ProcessorIdx(len(plan.Processors))
+	for _, resultProc := range plan.ResultRouters {
+		proc := distsqlplan.Processor{
+			Node: plan.Processors[resultProc].Node,
+			Spec: distsqlrun.ProcessorSpec{
+				Input: []distsqlrun.InputSyncSpec{{
+					// The other fields will be filled in by mergeResultStreams.
+					ColumnTypes: plan.ResultTypes,
+				}},
+				Core: distinctSpec,
+				Output: []distsqlrun.OutputRouterSpec{{
+					Type: distsqlrun.OutputRouterSpec_PASS_THROUGH,
+				}},
+				StageID: stageID,
+			},
+		}
+		plan.AddProcessor(proc)
+	}
+
+	// Connect the streams. Each bucket merges its inputs according to the
+	// current ordering, which the final distinct processors preserve; the
+	// ordering of the plan thus still applies when these streams are merged at
+	// the gateway.
+	for bucket := 0; bucket < len(plan.ResultRouters); bucket++ {
+		pIdx := pIdxStart + distsqlplan.ProcessorIdx(bucket)
+		plan.MergeResultStreams(plan.ResultRouters, bucket, plan.MergeOrdering, pIdx, 0)
+	}
+
+	// Set the new result routers.
+	for i := 0; i < len(plan.ResultRouters); i++ {
+		plan.ResultRouters[i] = pIdxStart + distsqlplan.ProcessorIdx(i)
+	}
 	return plan, nil
 }
 
@@ -17,6 +17,7 @@ package sql
 
 import (
 	gosql "database/sql"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"reflect"
@@ -25,6 +26,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
@@ -379,6 +381,87 @@ func TestDistSQLRangeCachesIntegrationTest(t *testing.T) {
 	}
 }
 
+// TestDistSQLHashJoinIsDistributed verifies that a hash join between two
+// tables whose ranges are spread across the cluster runs a joiner on every
+// node holding data for either input, with both inputs hash-routed to the
+// joiners.
+func TestDistSQLHashJoinIsDistributed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numNodes = 3
+	tc := serverutils.StartTestCluster(t, numNodes, base.TestClusterArgs{
+		ReplicationMode: base.ReplicationManual,
+		ServerArgs:      base.TestServerArgs{UseDatabase: "test"},
+	})
+	defer tc.Stopper().Stop(context.TODO())
+
+	r := sqlutils.MakeSQLRunner(tc.ServerConn(0))
+	// Use a single connection so that the session setting applies to all the
+	// queries below.
+	r.DB.SetMaxOpenConns(1)
+	r.Exec(t, "SET DISTSQL = ALWAYS")
+	sqlutils.CreateTable(t, r.DB, "l",
+		"k INT PRIMARY KEY, v INT",
+		30, /* numRows */
+		sqlutils.ToRowFn(sqlutils.RowIdxFn, sqlutils.RowModuloFn(10)))
+	sqlutils.CreateTable(t, r.DB, "r",
+		"k INT PRIMARY KEY, v INT",
+		30, /* numRows */
+		sqlutils.ToRowFn(sqlutils.RowIdxFn, sqlutils.RowModuloFn(7)))
+
+	// The left table is spread across the first two nodes and the right table
+	// lives on the third one.
+	r.Exec(t, fmt.Sprintf(`
+	ALTER TABLE l SPLIT AT VALUES (15);
+	ALTER TABLE l TESTING_RELOCATE VALUES (ARRAY[%d], 1), (ARRAY[%d], 15);
+	ALTER TABLE r TESTING_RELOCATE VALUES (ARRAY[%d], 1);
+	`,
+		tc.Server(0).GetFirstStoreID(),
+		tc.Server(1).GetFirstStoreID(),
+		tc.Server(2).GetFirstStoreID()))
+
+	const query = `SELECT l.k, r.k FROM l JOIN r ON l.v = r.v`
+	testutils.SucceedsSoon(t, func() error {
+		// Run the query so that the range cache of the gateway learns about the
+		// new lease holders.
+		r.Exec(t, query)
+
+		var planJSON string
+		r.QueryRow(t, fmt.Sprintf(`SELECT "JSON" FROM [EXPLAIN (DISTSQL) %s]`, query)).Scan(&planJSON)
+		var plan struct {
+			Processors []struct {
+				NodeIdx int `json:"nodeIdx"`
+				Core    struct {
+					Title string `json:"title"`
+				} `json:"core"`
+				Outputs []struct {
+					Title string `json:"title"`
+				} `json:"outputs"`
+			} `json:"processors"`
+		}
+		if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+			t.Fatal(err)
+		}
+
+		joinerNodes := make(map[int]struct{})
+		for _, p := range plan.Processors {
+			switch p.Core.Title {
+			case "HashJoiner":
+				joinerNodes[p.NodeIdx] = struct{}{}
+			case "TableReader":
+				if len(p.Outputs) != 1 || p.Outputs[0].Title != "by hash" {
+					return errors.Errorf("expected the table readers to be hash-routed: %s", planJSON)
+				}
+			}
+		}
+		if len(joinerNodes) != numNodes {
+			return errors.Errorf("expected joiners on %d nodes, found %d: %s",
+				numNodes, len(joinerNodes), planJSON)
+		}
+		return nil
+	})
+}
+
 func TestDistSQLDeadHosts(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -997,3 +1080,83 @@ func TestPartitionSpansSkipsNodesNotInGossip(t *testing.T) {
 		t.Errorf("expected partitions:\n  %v\ngot:\n  %v", expectedPartitions, resMap)
 	}
 }
+
+func TestFindJoinProcessorNodes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Processors 0-2 produce the left input and processors 3-5 the right input.
+	processors := []distsqlplan.Processor{
+		{Node: 2}, {Node: 1}, {Node: 2},
+		{Node: 3}, {Node: 1}, {Node: 4},
+	}
+	testCases := []struct {
+		left, right []distsqlplan.ProcessorIdx
+		expected    []roachpb.NodeID
+	}{
+		{
+			left:     []distsqlplan.ProcessorIdx{0, 1, 2},
+			right:    []distsqlplan.ProcessorIdx{3, 4, 5},
+			expected: []roachpb.NodeID{2, 1, 3, 4},
+		},
+		{
+			left:     []distsqlplan.ProcessorIdx{0, 2},
+			right:    []distsqlplan.ProcessorIdx{4},
+			expected: []roachpb.NodeID{2, 1},
+		},
+		{
+			// Both inputs on a single node: there should be a single joiner and no
+			// need for hash routing.
+			left:     []distsqlplan.ProcessorIdx{0},
+			right:    []distsqlplan.ProcessorIdx{2},
+			expected: []roachpb.NodeID{2},
+		},
+	}
+	for i, tc := range testCases {
+		nodes := findJoinProcessorNodes(tc.left, tc.right, processors)
+		if !reflect.DeepEqual(nodes, tc.expected) {
+			t.Errorf("%d: expected nodes %v, found %v", i, tc.expected, nodes)
+		}
+	}
+}
+
+func TestSplitSpans(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	span := func(start, end string) roachpb.Span {
+		return roachpb.Span{Key: roachpb.Key(start), EndKey: roachpb.Key(end)}
+	}
+	spans := roachpb.Spans{span("a", "b"), span("b", "c"), span("d", "e"), span("e", "f")}
+
+	testCases := []struct {
+		n        int
+		expected []roachpb.Spans
+	}{
+		{
+			n:        1,
+			expected: []roachpb.Spans{spans},
+		},
+		{
+			n:        2,
+			expected: []roachpb.Spans{{span("a", "c")}, {span("d", "f")}},
+		},
+		{
+			n:        3,
+			expected: []roachpb.Spans{{span("a", "b")}, {span("b", "c")}, {span("d", "f")}},
+		},
+		{
+			// There can't be more groups than spans.
+			n: 10,
+			expected: []roachpb.Spans{
+				{span("a", "b")}, {span("b", "c")}, {span("d", "e")}, {span("e", "f")},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("n=%d", tc.n), func(t *testing.T) {
+			groups := splitSpans(spans, tc.n)
+			if !reflect.DeepEqual(groups, tc.expected) {
+				t.Errorf("expected %v, found %v", tc.expected, groups)
+			}
+		})
+	}
+}
@@ -361,7 +361,7 @@ func (r *distSQLReceiver) Push(
 		return r.status
 	}
 
-	if r.resultWriter.StatementType() != tree.Rows {
+	if typ := r.resultWriter.StatementType(); typ != tree.Rows && typ != tree.CopyOut {
 		// We only need the row count.
 		r.resultWriter.IncrementRowsAffected(1)
 		return r.status
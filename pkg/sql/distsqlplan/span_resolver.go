@@ -67,7 +67,8 @@ import (
 //
 type SpanResolver interface {
 	// NewSpanResolverIterator creates a new SpanResolverIterator.
-	// The txn is only used by the "fake" implementation (used for testing).
+	// The txn is used to detect reads old enough to be served by any replica,
+	// and by the "fake" implementation (used for testing).
 	NewSpanResolverIterator(txn *client.Txn) SpanResolverIterator
 }
 
@@ -205,18 +206,35 @@ type spanResolverIterator struct {
 	// for ranges resolved already by the iterator.
 	queryState oracleQueryState
 
+	// followerRead is set when the txn reads at a timestamp old enough to be
+	// served by any replica, in which case the closest replica is chosen
+	// instead of the lease holder.
+	followerRead bool
+	// nodeDesc is the descriptor of the current node, used to choose the
+	// closest replica for follower reads.
+	nodeDesc roachpb.NodeDescriptor
+
 	err error
 }
 
 var _ SpanResolverIterator = &spanResolverIterator{}
 
 // NewSpanResolverIterator creates a new SpanResolverIterator.
-func (sr *spanResolver) NewSpanResolverIterator(_ *client.Txn) SpanResolverIterator {
+func (sr *spanResolver) NewSpanResolverIterator(txn *client.Txn) SpanResolverIterator {
+	var followerRead bool
+	if txn != nil {
+		proto := txn.Proto()
+		ts := proto.OrigTimestamp
+		ts.Forward(proto.MaxTimestamp)
+		followerRead = sr.distSender.CanUseFollowerRead(ts)
+	}
 	return &spanResolverIterator{
-		gossip:     sr.gossip,
-		it:         kv.NewRangeIterator(sr.distSender),
-		oracle:     sr.oracle,
-		queryState: makeOracleQueryState(),
+		gossip:       sr.gossip,
+		it:           kv.NewRangeIterator(sr.distSender),
+		oracle:       sr.oracle,
+		queryState:   makeOracleQueryState(),
+		followerRead: followerRead,
+		nodeDesc:     sr.nodeDesc,
 	}
 }
 
@@ -306,6 +324,16 @@ func (it *spanResolverIterator) ReplicaInfo(ctx context.Context) (kv.ReplicaInfo
 		panic(it.Error())
 	}
 
+	if it.followerRead {
+		replicas, err := replicaSliceOrErr(*it.it.Desc(), it.gossip)
+		if err != nil {
+			return kv.ReplicaInfo{}, err
+		}
+		replicas.OptimizeReplicaOrder(&it.nodeDesc)
+		it.queryState.rangesPerNode[replicas[0].NodeID]++
+		return replicas[0], nil
+	}
+
 	resolvedLH := false
 	var repl kv.ReplicaInfo
 	if storeID, ok := it.it.LeaseHolderStoreID(ctx); ok {
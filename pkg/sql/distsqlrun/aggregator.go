@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
@@ -100,11 +101,22 @@ type aggregator struct {
 	datumAlloc  sqlbase.DatumAlloc
 
 	bucketsAcc mon.BoundAccount
+	// seenAcc accounts for the values tracked by DISTINCT aggregations. It is
+	// not subject to the limit on bucketsAcc since those values belong to
+	// groups that are already in memory and can't be moved to disk.
+	seenAcc mon.BoundAccount
 
 	groupCols    columns
 	aggregations []AggregatorSpec_Aggregation
 
 	buckets map[string]struct{} // The set of bucket keys.
+
+	// useTempStorage is set if rows belonging to groups that don't fit in memory
+	// can be stored in spilledRows, in which case those groups are aggregated
+	// one at a time after the in-memory groups have been emitted.
+	useTempStorage bool
+	spilled        bool
+	spilledRows    diskRowContainer
 }
 
 var _ Processor = &aggregator{}
@@ -125,6 +137,7 @@ func newAggregator(
 		funcs:        make([]*aggregateFuncHolder, len(spec.Aggregations)),
 		outputTypes:  make([]sqlbase.ColumnType, len(spec.Aggregations)),
 		bucketsAcc:   flowCtx.EvalCtx.Mon.MakeBoundAccount(),
+		seenAcc:      flowCtx.EvalCtx.Mon.MakeBoundAccount(),
 	}
 
 	// Loop over the select expressions and extract any aggregate functions --
@@ -177,7 +190,33 @@ func (ag *aggregator) Run(ctx context.Context, wg *sync.WaitGroup) {
 	if wg != nil {
 		defer wg.Done()
 	}
+
+	st := ag.flowCtx.Settings
+	ag.useTempStorage = len(ag.groupCols) > 0 &&
+		(settingUseTempStorageAggregations.Get(&st.SV) ||
+			ag.flowCtx.testingKnobs.MemoryLimitBytes > 0)
+	if ag.useTempStorage {
+		// Limit the memory use by creating a child monitor with a hard limit.
+		// Groups that don't fit within this limit are aggregated from disk.
+		limit := ag.flowCtx.testingKnobs.MemoryLimitBytes
+		if limit <= 0 {
+			limit = settingWorkMemBytes.Get(&st.SV)
+		}
+		parentMon := ag.flowCtx.EvalCtx.Mon
+		limitedMon := mon.MakeMonitorInheritWithLimit("aggregator-limited", limit, parentMon)
+		limitedMon.Start(ctx, parentMon, mon.BoundAccount{})
+		defer limitedMon.Stop(ctx)
+
+		ag.bucketsAcc.Close(ctx)
+		ag.bucketsAcc = limitedMon.MakeBoundAccount()
+	}
 	defer ag.bucketsAcc.Close(ctx)
+	defer ag.seenAcc.Close(ctx)
+	defer func() {
+		if ag.spilled {
+			ag.spilledRows.Close(ctx)
+		}
+	}()
 	defer func() {
 		for _, f := range ag.funcs {
 			for _, aggFunc := range f.buckets {
@@ -212,25 +251,25 @@ func (ag *aggregator) Run(ctx context.Context, wg *sync.WaitGroup) {
 	var consumerDone bool
 	row := make(sqlbase.EncDatumRow, len(ag.funcs))
 	for bucket := range ag.buckets {
-		for i, f := range ag.funcs {
-			result, err := f.get(bucket)
-			if err != nil {
-				DrainAndClose(ctx, ag.out.output, err, ag.input)
-				return
-			}
-			if result == nil {
-				// Special case useful when this is a local stage of a distributed
-				// aggregation.
-				result = tree.DNull
-			}
-			row[i] = sqlbase.DatumToEncDatum(ag.outputTypes[i], result)
+		if err := ag.render(bucket, row); err != nil {
+			DrainAndClose(ctx, ag.out.output, err, ag.input)
+			return
 		}
-
 		consumerDone = !emitHelper(ctx, &ag.out, row, ProducerMetadata{})
 		if consumerDone {
 			break
 		}
 	}
+
+	if !consumerDone && ag.spilled {
+		var err error
+		consumerDone, err = ag.aggregateSpilledRows(ctx, row)
+		if err != nil {
+			DrainAndClose(ctx, ag.out.output, err, ag.input)
+			return
+		}
+	}
+
 	// If the consumer has been found to be done, emitHelper() already closed the
 	// output.
 	if !consumerDone {
@@ -284,61 +323,212 @@ func (ag *aggregator) accumulateRows(ctx context.Context) (err error) {
 		if err != nil {
 			return err
 		}
+		scratch = encoded[:0]
 
-		if err := ag.bucketsAcc.Grow(ctx, int64(len(encoded))); err != nil {
-			return err
-		}
-
-		ag.buckets[string(encoded)] = struct{}{}
-		// Feed the func holders for this bucket the non-grouping datums.
-		for i, a := range ag.aggregations {
-			if a.FilterColIdx != nil {
-				col := *a.FilterColIdx
-				if err := row[col].EnsureDecoded(&ag.inputTypes[col], &ag.datumAlloc); err != nil {
+		if _, ok := ag.buckets[string(encoded)]; !ok {
+			if ag.spilled {
+				// New groups are no longer created in memory once we've started
+				// storing rows on disk.
+				if err := ag.spilledRows.AddRow(ctx, row); err != nil {
 					return err
 				}
-				if row[*a.FilterColIdx].Datum != tree.DBoolTrue {
-					// This row doesn't contribute to this aggregation.
-					continue
-				}
+				continue
 			}
-			// Extract the corresponding arguments from the row to feed into the
-			// aggregate function.
-			// Most functions require at most one argument thus we separate
-			// the first argument and allocation of (if applicable) a variadic
-			// collection of arguments thereafter.
-			var firstArg tree.Datum
-			var otherArgs tree.Datums
-			if len(a.ColIdx) > 1 {
-				otherArgs = make(tree.Datums, len(a.ColIdx)-1)
-			}
-			isFirstArg := true
-			for j, c := range a.ColIdx {
-				if err := row[c].EnsureDecoded(&ag.inputTypes[c], &ag.datumAlloc); err != nil {
+			if err := ag.bucketsAcc.Grow(ctx, ag.bucketUsage(encoded)); err != nil {
+				if !ag.useTempStorage || !isMemoryError(err) {
 					return err
 				}
-				if isFirstArg {
-					firstArg = row[c].Datum
-					isFirstArg = false
-					continue
+				if err := ag.spillToDisk(ctx, row); err != nil {
+					return err
 				}
-				otherArgs[j-1] = row[c].Datum
+				continue
 			}
+			ag.buckets[string(encoded)] = struct{}{}
+		}
+
+		if err := ag.accumulateRow(ctx, encoded, row); err != nil {
+			return err
+		}
+	}
+}
+
+// bucketUsage returns the memory accounted for when creating a new bucket
+// with the given key: the key itself plus one AggregateFunc per aggregation.
+func (ag *aggregator) bucketUsage(bucket []byte) int64 {
+	// TODO(radu): we should account for the size of impl (this needs to be done
+	// in each aggregate constructor).
+	// TODO(radu): this model of each func having a map of buckets (one per
+	// group) for each func plus a global map is very wasteful. We should have a
+	// single map that stores all the AggregateFuncs.
+	return int64(len(bucket)) + int64(len(ag.funcs))*(int64(len(bucket))+sizeOfAggregateFunc)
+}
+
+// spillToDisk is called when the buckets no longer fit in memory. The groups
+// accumulated so far stay in memory; the given row and all subsequent rows
+// belonging to other groups are stored on disk, sorted by the grouping
+// columns.
+func (ag *aggregator) spillToDisk(ctx context.Context, row sqlbase.EncDatumRow) error {
+	log.VEventf(ctx, 2, "falling back to disk with %d groups in memory", len(ag.buckets))
+	ag.flowCtx.noteDiskSpill()
+	ordering := make(sqlbase.ColumnOrdering, len(ag.groupCols))
+	for i, c := range ag.groupCols {
+		ordering[i] = sqlbase.ColumnOrderInfo{ColIdx: int(c), Direction: encoding.Ascending}
+	}
+	ag.spilledRows = makeDiskRowContainer(
+		ctx, ag.flowCtx.diskMonitor, ag.inputTypes, ordering, ag.flowCtx.TempStorage,
+	)
+	ag.spilled = true
+	return ag.spilledRows.AddRow(ctx, row)
+}
 
-			if err := ag.funcs[i].add(ctx, encoded, firstArg, otherArgs); err != nil {
+// aggregateSpilledRows aggregates and emits the groups stored on disk. Since
+// rows are sorted by the grouping columns, only one group needs to be held in
+// memory at a time. The row argument is used as scratch space for the output.
+// Returns true if the consumer indicated that it doesn't need any more rows.
+func (ag *aggregator) aggregateSpilledRows(
+	ctx context.Context, row sqlbase.EncDatumRow,
+) (consumerDone bool, err error) {
+	// The in-memory groups have all been emitted; release them.
+	ag.resetBuckets(ctx)
+
+	i := ag.spilledRows.NewIterator(ctx)
+	defer i.Close()
+
+	var spilledRows, spilledGroups int
+	var scratch, curBucket []byte
+	haveBucket := false
+	for i.Rewind(); ; i.Next() {
+		if ok, err := i.Valid(); err != nil {
+			return false, err
+		} else if !ok {
+			break
+		}
+		inputRow, err := i.Row()
+		if err != nil {
+			return false, err
+		}
+		encoded, err := ag.encode(scratch, inputRow)
+		if err != nil {
+			return false, err
+		}
+		scratch = encoded[:0]
+		if haveBucket && string(encoded) != string(curBucket) {
+			if err := ag.render(string(curBucket), row); err != nil {
+				return false, err
+			}
+			if !emitHelper(ctx, &ag.out, row, ProducerMetadata{}) {
+				return true, nil
+			}
+			ag.resetBuckets(ctx)
+			spilledGroups++
+		}
+		curBucket = append(curBucket[:0], encoded...)
+		haveBucket = true
+		if err := ag.accumulateRow(ctx, curBucket, inputRow); err != nil {
+			return false, err
+		}
+		spilledRows++
+	}
+	if haveBucket {
+		if err := ag.render(string(curBucket), row); err != nil {
+			return false, err
+		}
+		if !emitHelper(ctx, &ag.out, row, ProducerMetadata{}) {
+			return true, nil
+		}
+		spilledGroups++
+	}
+	log.VEventf(ctx, 2, "aggregated %d rows in %d groups from disk", spilledRows, spilledGroups)
+	return false, nil
+}
+
+// resetBuckets closes all the aggregate functions and releases the memory
+// used by the buckets.
+func (ag *aggregator) resetBuckets(ctx context.Context) {
+	for _, f := range ag.funcs {
+		for _, aggFunc := range f.buckets {
+			aggFunc.Close(ctx)
+		}
+		f.buckets = make(map[string]tree.AggregateFunc)
+		if f.seen != nil {
+			f.seen = make(map[string]struct{})
+		}
+	}
+	ag.buckets = make(map[string]struct{})
+	ag.bucketsAcc.Clear(ctx)
+	ag.seenAcc.Clear(ctx)
+}
+
+// render computes the results of all the aggregations for the given bucket
+// into row.
+func (ag *aggregator) render(bucket string, row sqlbase.EncDatumRow) error {
+	for i, f := range ag.funcs {
+		result, err := f.get(bucket)
+		if err != nil {
+			return err
+		}
+		if result == nil {
+			// Special case useful when this is a local stage of a distributed
+			// aggregation.
+			result = tree.DNull
+		}
+		row[i] = sqlbase.DatumToEncDatum(ag.outputTypes[i], result)
+	}
+	return nil
+}
+
+// accumulateRow feeds the func holders for the given bucket the non-grouping
+// datums of the row.
+func (ag *aggregator) accumulateRow(
+	ctx context.Context, bucket []byte, row sqlbase.EncDatumRow,
+) error {
+	for i, a := range ag.aggregations {
+		if a.FilterColIdx != nil {
+			col := *a.FilterColIdx
+			if err := row[col].EnsureDecoded(&ag.inputTypes[col], &ag.datumAlloc); err != nil {
 				return err
 			}
+			if row[*a.FilterColIdx].Datum != tree.DBoolTrue {
+				// This row doesn't contribute to this aggregation.
+				continue
+			}
+		}
+		// Extract the corresponding arguments from the row to feed into the
+		// aggregate function.
+		// Most functions require at most one argument thus we separate
+		// the first argument and allocation of (if applicable) a variadic
+		// collection of arguments thereafter.
+		var firstArg tree.Datum
+		var otherArgs tree.Datums
+		if len(a.ColIdx) > 1 {
+			otherArgs = make(tree.Datums, len(a.ColIdx)-1)
+		}
+		isFirstArg := true
+		for j, c := range a.ColIdx {
+			if err := row[c].EnsureDecoded(&ag.inputTypes[c], &ag.datumAlloc); err != nil {
+				return err
+			}
+			if isFirstArg {
+				firstArg = row[c].Datum
+				isFirstArg = false
+				continue
+			}
+			otherArgs[j-1] = row[c].Datum
+		}
+
+		if err := ag.funcs[i].add(ctx, bucket, firstArg, otherArgs); err != nil {
+			return err
 		}
-		scratch = encoded[:0]
 	}
+	return nil
 }
 
 type aggregateFuncHolder struct {
-	create        func(*tree.EvalContext) tree.AggregateFunc
-	group         *aggregator
-	buckets       map[string]tree.AggregateFunc
-	seen          map[string]struct{}
-	bucketsMemAcc *mon.BoundAccount
+	create     func(*tree.EvalContext) tree.AggregateFunc
+	group      *aggregator
+	buckets    map[string]tree.AggregateFunc
+	seen       map[string]struct{}
+	seenMemAcc *mon.BoundAccount
 }
 
 const sizeOfAggregateFunc = int64(unsafe.Sizeof(tree.AggregateFunc(nil)))
@@ -347,10 +537,10 @@ func (ag *aggregator) newAggregateFuncHolder(
 	create func(*tree.EvalContext) tree.AggregateFunc,
 ) *aggregateFuncHolder {
 	return &aggregateFuncHolder{
-		create:        create,
-		group:         ag,
-		buckets:       make(map[string]tree.AggregateFunc),
-		bucketsMemAcc: &ag.bucketsAcc,
+		create:     create,
+		group:      ag,
+		buckets:    make(map[string]tree.AggregateFunc),
+		seenMemAcc: &ag.seenAcc,
 	}
 }
 
@@ -373,7 +563,7 @@ func (a *aggregateFuncHolder) add(
 			// skip
 			return nil
 		}
-		if err := a.bucketsMemAcc.Grow(ctx, int64(len(encoded))); err != nil {
+		if err := a.seenMemAcc.Grow(ctx, int64(len(encoded))); err != nil {
 			return err
 		}
 		a.seen[string(encoded)] = struct{}{}
@@ -381,17 +571,9 @@ func (a *aggregateFuncHolder) add(
 
 	impl, ok := a.buckets[string(bucket)]
 	if !ok {
-		// TODO(radu): we should account for the size of impl (this needs to be done
-		// in each aggregate constructor).
+		// The memory for impl was accounted for when the bucket was created (see
+		// aggregator.bucketUsage).
 		impl = a.create(&a.group.flowCtx.EvalCtx)
-		usage := int64(len(bucket))
-		usage += sizeOfAggregateFunc
-		// TODO(radu): this model of each func having a map of buckets (one per
-		// group) for each func plus a global map is very wasteful. We should have a
-		// single map that stores all the AggregateFuncs.
-		if err := a.bucketsMemAcc.Grow(ctx, usage); err != nil {
-			return err
-		}
 		a.buckets[string(bucket)] = impl
 	}
 
@@ -15,16 +15,22 @@
 package distsqlrun
 
 import (
+	"fmt"
+	"math"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 )
 
 // TODO(irfansharif): Add tests to verify the following aggregation functions:
@@ -388,3 +394,104 @@ func TestAggregator(t *testing.T) {
 		})
 	}
 }
+
+// TestAggregatorDiskSpill verifies that an aggregator whose groups don't fit
+// in memory falls back to aggregating from disk and produces the same results.
+func TestAggregatorDiskSpill(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numGroups = 50
+	const rowsPerGroup = 4
+	var input sqlbase.EncDatumRows
+	for i := 0; i < rowsPerGroup; i++ {
+		for g := 0; g < numGroups; g++ {
+			input = append(input, sqlbase.EncDatumRow{
+				sqlbase.DatumToEncDatum(intType, tree.NewDInt(tree.DInt(g))),
+				sqlbase.DatumToEncDatum(intType, tree.NewDInt(tree.DInt(i%2))),
+			})
+		}
+	}
+
+	// SELECT @0, SUM(@1), COUNT(DISTINCT @1) GROUP BY @0
+	spec := AggregatorSpec{
+		GroupCols: []uint32{0},
+		Aggregations: []AggregatorSpec_Aggregation{
+			{Func: AggregatorSpec_IDENT, ColIdx: []uint32{0}},
+			{Func: AggregatorSpec_SUM_INT, ColIdx: []uint32{1}},
+			{Func: AggregatorSpec_COUNT, ColIdx: []uint32{1}, Distinct: true},
+		},
+	}
+	var expected []string
+	for g := 0; g < numGroups; g++ {
+		expected = append(expected, fmt.Sprintf("[%d %d %d]", g, rowsPerGroup/2, 2))
+	}
+	sort.Strings(expected)
+	expStr := strings.Join(expected, "")
+
+	ctx := context.Background()
+	tempEngine, err := engine.NewTempEngine(base.DefaultTestTempStorageConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tempEngine.Close()
+
+	evalCtx := tree.MakeTestingEvalContext()
+	defer evalCtx.Stop(ctx)
+	diskMonitor := mon.MakeMonitor(
+		"test-disk",
+		mon.DiskResource,
+		nil, /* curCount */
+		nil, /* maxHist */
+		-1,  /* increment: use default block size */
+		math.MaxInt64,
+	)
+	diskMonitor.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(math.MaxInt64))
+	defer diskMonitor.Stop(ctx)
+
+	// Test with several memory limits:
+	// 0: Use the default limit; everything fits in memory.
+	// 1: Immediately switch to disk.
+	// 1024: Some groups are aggregated in memory, the rest from disk.
+	for _, memLimit := range []int64{0, 1, 1024} {
+		t.Run(fmt.Sprintf("MemLimit=%d", memLimit), func(t *testing.T) {
+			metrics := MakeDistSQLMetrics(time.Hour /* histogramWindow */)
+			flowCtx := FlowCtx{
+				EvalCtx:     evalCtx,
+				Settings:    cluster.MakeTestingClusterSettings(),
+				TempStorage: tempEngine,
+				diskMonitor: &diskMonitor,
+				metrics:     &metrics,
+			}
+			flowCtx.testingKnobs.MemoryLimitBytes = memLimit
+
+			in := NewRowBuffer(twoIntCols, input, RowBufferArgs{})
+			out := &RowBuffer{}
+			ag, err := newAggregator(&flowCtx, &spec, in, &PostProcessSpec{}, out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ag.Run(ctx, nil /* wg */)
+			if !out.ProducerClosed {
+				t.Fatalf("output RowReceiver not closed")
+			}
+
+			var rets []string
+			for {
+				row := out.NextNoMeta(t)
+				if row == nil {
+					break
+				}
+				rets = append(rets, row.String(threeIntCols))
+			}
+			sort.Strings(rets)
+			if retStr := strings.Join(rets, ""); expStr != retStr {
+				t.Errorf("invalid results; expected:\n   %s\ngot:\n   %s", expStr, retStr)
+			}
+
+			spilled := metrics.DiskSpillsTotal.Count() > 0
+			if expSpilled := memLimit > 0; spilled != expSpilled {
+				t.Errorf("expected spilled=%t, found %t", expSpilled, spilled)
+			}
+		})
+	}
+}
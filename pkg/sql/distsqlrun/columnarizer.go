@@ -0,0 +1,177 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// columnarizer turns a RowSource input into an exec.Operator output, by
+// reading the input in chunks of size exec.ColBatchSize and converting each
+// chunk into an exec.ColBatch column by column. Metadata from the input is
+// forwarded directly to the metadata sink.
+type columnarizer struct {
+	input NoMetadataRowSource
+
+	types []sqlbase.ColumnType
+	typs  []exec.T
+
+	da       sqlbase.DatumAlloc
+	buffered sqlbase.EncDatumRows
+	batch    exec.ColBatch
+}
+
+var _ exec.Operator = &columnarizer{}
+
+// newColumnarizer returns a new columnarizer reading from input. It returns
+// an error if any of the input's column types is not supported by the columnar
+// engine.
+func newColumnarizer(input RowSource, metadataSink RowReceiver) (*columnarizer, error) {
+	types := input.Types()
+	typs, ok := exec.FromColumnTypes(types)
+	if !ok {
+		return nil, errors.Errorf("unsupported column types for columnar execution: %v", types)
+	}
+	return &columnarizer{
+		input: MakeNoMetadataRowSource(input, metadataSink),
+		types: types,
+		typs:  typs,
+	}, nil
+}
+
+// Init is part of the exec.Operator interface.
+func (c *columnarizer) Init() {
+	c.batch = exec.NewMemBatch(c.typs)
+	c.buffered = make(sqlbase.EncDatumRows, exec.ColBatchSize)
+}
+
+// Next is part of the exec.Operator interface.
+func (c *columnarizer) Next() exec.ColBatch {
+	c.batch.SetSelection(false)
+	// Buffer up to exec.ColBatchSize rows.
+	nRows := uint16(0)
+	for ; nRows < exec.ColBatchSize; nRows++ {
+		row, err := c.input.NextRow()
+		if err != nil {
+			exec.RaiseError(err)
+		}
+		if row == nil {
+			break
+		}
+		c.buffered[nRows] = row
+	}
+
+	// Write each column into the output batch.
+	for idx := range c.types {
+		if err := encDatumRowsToColVec(
+			c.buffered[:nRows], c.batch.ColVec(idx), idx, &c.types[idx], &c.da,
+		); err != nil {
+			exec.RaiseError(err)
+		}
+	}
+	c.batch.SetLength(nRows)
+	return c.batch
+}
+
+// encDatumRowsToColVec converts the column columnIdx of rows into vec. The
+// column type must be supported by exec.FromColumnType.
+func encDatumRowsToColVec(
+	rows sqlbase.EncDatumRows,
+	vec exec.ColVec,
+	columnIdx int,
+	columnType *sqlbase.ColumnType,
+	alloc *sqlbase.DatumAlloc,
+) error {
+	vec.UnsetNulls()
+	switch columnType.SemanticType {
+	case sqlbase.ColumnType_BOOL:
+		col := vec.Bool()
+		for i := range rows {
+			row := rows[i]
+			if err := row[columnIdx].EnsureDecoded(columnType, alloc); err != nil {
+				return err
+			}
+			datum := row[columnIdx].Datum
+			if datum == tree.DNull {
+				vec.SetNull(uint16(i))
+				continue
+			}
+			col[i] = bool(*datum.(*tree.DBool))
+		}
+	case sqlbase.ColumnType_INT:
+		col := vec.Int64()
+		for i := range rows {
+			row := rows[i]
+			if err := row[columnIdx].EnsureDecoded(columnType, alloc); err != nil {
+				return err
+			}
+			datum := row[columnIdx].Datum
+			if datum == tree.DNull {
+				vec.SetNull(uint16(i))
+				continue
+			}
+			col[i] = int64(*datum.(*tree.DInt))
+		}
+	case sqlbase.ColumnType_FLOAT:
+		col := vec.Float64()
+		for i := range rows {
+			row := rows[i]
+			if err := row[columnIdx].EnsureDecoded(columnType, alloc); err != nil {
+				return err
+			}
+			datum := row[columnIdx].Datum
+			if datum == tree.DNull {
+				vec.SetNull(uint16(i))
+				continue
+			}
+			col[i] = float64(*datum.(*tree.DFloat))
+		}
+	case sqlbase.ColumnType_BYTES:
+		col := vec.Bytes()
+		for i := range rows {
+			row := rows[i]
+			if err := row[columnIdx].EnsureDecoded(columnType, alloc); err != nil {
+				return err
+			}
+			datum := row[columnIdx].Datum
+			if datum == tree.DNull {
+				vec.SetNull(uint16(i))
+				continue
+			}
+			col[i] = []byte(*datum.(*tree.DBytes))
+		}
+	case sqlbase.ColumnType_STRING:
+		col := vec.Bytes()
+		for i := range rows {
+			row := rows[i]
+			if err := row[columnIdx].EnsureDecoded(columnType, alloc); err != nil {
+				return err
+			}
+			datum := row[columnIdx].Datum
+			if datum == tree.DNull {
+				vec.SetNull(uint16(i))
+				continue
+			}
+			col[i] = []byte(*datum.(*tree.DString))
+		}
+	default:
+		return errors.Errorf("unsupported column type %s", columnType.SQLString())
+	}
+	return nil
+}
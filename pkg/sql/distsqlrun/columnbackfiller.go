@@ -221,7 +221,7 @@ func (cb *columnBackfiller) runChunk(
 					return sqlbase.NewInvalidSchemaDefinitionError(err)
 				}
 				if j < len(cb.added) && !cb.added[j].Nullable && val == tree.DNull {
-					return sqlbase.NewNonNullViolationError(cb.added[j].Name)
+					return sqlbase.NewNonNullViolationError(cb.spec.Table.Name, cb.added[j].Name)
 				}
 				updateValues[j] = val
 			}
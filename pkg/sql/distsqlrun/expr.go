@@ -84,6 +84,9 @@ type exprHelper struct {
 	noCopy util.NoCopy
 
 	expr tree.TypedExpr
+	// compiled is expr compiled into closures; it is what actually gets
+	// evaluated for every row.
+	compiled tree.CompiledExpr
 	// vars is used to generate IndexedVars that are "backed" by the values in
 	// `row`.
 	vars tree.IndexedVarHelper
@@ -142,6 +145,7 @@ func (eh *exprHelper) init(
 	if t.AggregateInExpr(eh.expr, evalCtx.SearchPath) {
 		return errors.Errorf("expression '%s' has aggregate", eh.expr)
 	}
+	eh.compiled = tree.CompileFilter(eh.expr)
 	return nil
 }
 
@@ -150,7 +154,7 @@ func (eh *exprHelper) init(
 func (eh *exprHelper) evalFilter(row sqlbase.EncDatumRow) (bool, error) {
 	eh.row = row
 	eh.evalCtx.IVarHelper = &eh.vars
-	pass, err := sqlbase.RunFilter(eh.expr, eh.evalCtx)
+	pass, err := tree.RunCompiledFilter(eh.compiled, eh.evalCtx)
 	eh.evalCtx.IVarHelper = nil
 	return pass, err
 }
@@ -165,7 +169,7 @@ func (eh *exprHelper) eval(row sqlbase.EncDatumRow) (tree.Datum, error) {
 	eh.row = row
 
 	eh.evalCtx.IVarHelper = &eh.vars
-	d, err := eh.expr.Eval(eh.evalCtx)
+	d, err := eh.compiled(eh.evalCtx)
 	eh.evalCtx.IVarHelper = nil
 	return d, err
 }
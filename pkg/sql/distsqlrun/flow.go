@@ -83,11 +83,22 @@ type FlowCtx struct {
 	TempStorage engine.Engine
 	// diskMonitor is used to monitor temporary storage disk usage.
 	diskMonitor *mon.BytesMonitor
+	// metrics is used to record processors falling back to temporary storage.
+	// It can be nil in tests.
+	metrics *DistSQLMetrics
 
 	// JobRegistry is used during backfill to load jobs which keep state.
 	JobRegistry *jobs.Registry
 }
 
+// noteDiskSpill records that a processor in this flow had to fall back to
+// temporary storage because its working set exceeded its memory limit.
+func (ctx *FlowCtx) noteDiskSpill() {
+	if ctx.metrics != nil {
+		ctx.metrics.DiskSpillsTotal.Inc(1)
+	}
+}
+
 // NewEvalCtx returns a modifiable copy of the FlowCtx's EvalContext.
 // Processors should use this method any time they need to store a pointer to
 // the EvalContext, since processors may mutate the EvalContext. Specifically,
@@ -358,6 +358,7 @@ func (h *hashJoiner) buildPhase(
 	}
 
 	log.VEventf(ctx, 2, "build phase falling back to disk")
+	h.flowCtx.noteDiskSpill()
 
 	storedDiskRows := makeHashDiskRowContainer(h.flowCtx.diskMonitor, h.flowCtx.TempStorage)
 	if err := storedDiskRows.Init(
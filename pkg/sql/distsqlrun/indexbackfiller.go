@@ -15,13 +15,21 @@
 package distsqlrun
 
 import (
+	"bytes"
+	"sort"
+
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/bulk"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -43,6 +51,29 @@ type indexBackfiller struct {
 var _ Processor = &indexBackfiller{}
 var _ chunkBackfiller = &indexBackfiller{}
 
+var bulkIndexBackfillEnabled = settings.RegisterBoolSetting(
+	"schemachanger.bulk_index_backfill.enabled",
+	"set to true to backfill non-unique indexes by ingesting SSTables rather than writing "+
+		"index entries in transactions",
+	true,
+)
+
+// CanIngestIndexBackfill returns whether the entries of the given new indexes
+// are backfilled by ingesting them with AddSSTable. This requires AddSSTable
+// to be implemented and the indexes to be non-unique: an SSTable is ingested
+// blindly, so it cannot detect duplicate values.
+func CanIngestIndexBackfill(st *cluster.Settings, added []sqlbase.IndexDescriptor) bool {
+	if !bulkIndexBackfillEnabled.Get(&st.SV) || !storage.AddSSTableImplemented() {
+		return false
+	}
+	for i := range added {
+		if added[i].Unique {
+			return false
+		}
+	}
+	return true
+}
+
 // IndexMutationFilter is a filter that allows mutations that add indexes.
 func IndexMutationFilter(m sqlbase.DescriptorMutation) bool {
 	return m.GetIndex() != nil && m.Direction == sqlbase.DescriptorMutation_ADD
@@ -229,6 +260,19 @@ func (ib *indexBackfiller) runChunk(
 		return nil, err
 	}
 
+	if CanIngestIndexBackfill(ib.flowCtx.Settings, added) {
+		// The entries are ingested at the timestamp they were read at. Writes
+		// to the new index by concurrent transactions are more recent, so an
+		// entry deleted or updated since readAsOf is shadowed by the tombstone
+		// or the new value written by the transaction that changed its row.
+		if err := ingestIndexEntries(
+			ctx, ib.flowCtx.clientDB, ib.flowCtx.Settings, entries, readAsOf,
+		); err != nil {
+			return nil, err
+		}
+		return ib.fetcher.Key(), nil
+	}
+
 	retried := false
 	// Write the new index values.
 	if err := ib.flowCtx.clientDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
@@ -277,3 +321,36 @@ func (ib *indexBackfiller) runChunk(
 
 	return ib.fetcher.Key(), nil
 }
+
+// ingestIndexEntries writes the given index entries at the given timestamp by
+// ingesting them as an SSTable, split at the boundaries of the ranges they
+// belong to.
+func ingestIndexEntries(
+	ctx context.Context,
+	db *client.DB,
+	st *cluster.Settings,
+	entries []sqlbase.IndexEntry,
+	ts hlc.Timestamp,
+) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].Key, entries[j].Key) < 0
+	})
+
+	b, err := bulk.MakeSSTBatcher(db, st)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+	for _, entry := range entries {
+		value := entry.Value
+		value.ClearChecksum()
+		value.InitChecksum(entry.Key)
+		if err := b.Add(engine.MVCCKey{Key: entry.Key, Timestamp: ts}, value.RawBytes); err != nil {
+			return err
+		}
+	}
+	return b.Finish(ctx)
+}
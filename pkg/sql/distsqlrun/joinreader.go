@@ -21,15 +21,31 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
+// IndexJoinBatchSize is the number of input rows whose primary keys are looked
+// up in a single KV batch when joining an index back to the primary index. It
+// is used both by the joinReader and by local index joins.
+//
 // TODO(radu): we currently create one batch at a time and run the KV operations
 // on this node. In the future we may want to build separate batches for the
 // nodes that "own" the respective ranges, and send out flows on those nodes.
-const joinReaderBatchSize = 100
+var IndexJoinBatchSize = settings.RegisterValidatedIntSetting(
+	"sql.index_join.batch_size",
+	"number of rows whose primary keys are looked up in a single batch when "+
+		"joining an index back to the primary index",
+	100,
+	func(v int64) error {
+		if v < 1 {
+			return errors.Errorf("cannot set sql.index_join.batch_size to a value < 1: %d", v)
+		}
+		return nil
+	},
+)
 
 type joinReader struct {
 	processorBase
@@ -44,6 +60,9 @@ type joinReader struct {
 
 	input      RowSource
 	inputTypes []sqlbase.ColumnType
+
+	// batchSize is the number of input rows looked up in a single KV batch.
+	batchSize int
 }
 
 var _ Processor = &joinReader{}
@@ -65,6 +84,7 @@ func newJoinReader(
 		desc:       spec.Table,
 		input:      input,
 		inputTypes: input.Types(),
+		batchSize:  int(IndexJoinBatchSize.Get(&flowCtx.Settings.SV)),
 	}
 
 	types := make([]sqlbase.ColumnType, len(spec.Table.Columns))
@@ -116,7 +136,7 @@ func (jr *joinReader) mainLoop(ctx context.Context) error {
 	primaryKeyPrefix := sqlbase.MakeIndexKeyPrefix(&jr.desc, jr.index.ID)
 
 	var alloc sqlbase.DatumAlloc
-	spans := make(roachpb.Spans, 0, joinReaderBatchSize)
+	spans := make(roachpb.Spans, 0, jr.batchSize)
 
 	txn := jr.flowCtx.txn
 	if txn == nil {
@@ -132,7 +152,7 @@ func (jr *joinReader) mainLoop(ctx context.Context) error {
 		// TODO(radu): figure out how to send smaller batches if the source has
 		// a soft limit (perhaps send the batch out if we don't get a result
 		// within a certain amount of time).
-		for spans = spans[:0]; len(spans) < joinReaderBatchSize; {
+		for spans = spans[:0]; len(spans) < jr.batchSize; {
 			row, meta := jr.input.Next()
 			if !meta.Empty() {
 				if meta.Err != nil {
@@ -190,7 +210,7 @@ func (jr *joinReader) mainLoop(ctx context.Context) error {
 			}
 		}
 
-		if len(spans) != joinReaderBatchSize {
+		if len(spans) != jr.batchSize {
 			// This was the last batch.
 			sendTraceData(ctx, jr.out.output)
 			jr.out.Close()
@@ -16,6 +16,7 @@ package distsqlrun
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"golang.org/x/net/context"
@@ -100,54 +101,59 @@ func TestJoinReader(t *testing.T) {
 		},
 	}
 	for _, c := range testCases {
-		t.Run("", func(t *testing.T) {
-			evalCtx := tree.MakeTestingEvalContext()
-			defer evalCtx.Stop(context.Background())
-			flowCtx := FlowCtx{
-				EvalCtx:  evalCtx,
-				Settings: cluster.MakeTestingClusterSettings(),
-				// Pass a DB without a TxnCoordSender.
-				txn: client.NewTxn(client.NewDB(s.DistSender(), s.Clock()), s.NodeID()),
-			}
-
-			encRows := make(sqlbase.EncDatumRows, len(c.input))
-			for rowIdx, row := range c.input {
-				encRow := make(sqlbase.EncDatumRow, len(row))
-				for i, d := range row {
-					encRow[i] = sqlbase.DatumToEncDatum(intType, d)
+		// Look up the rows in batches of various sizes.
+		for _, batchSize := range []int64{1, 2, 100} {
+			t.Run(fmt.Sprintf("BatchSize=%d", batchSize), func(t *testing.T) {
+				evalCtx := tree.MakeTestingEvalContext()
+				defer evalCtx.Stop(context.Background())
+				st := cluster.MakeTestingClusterSettings()
+				IndexJoinBatchSize.Override(&st.SV, batchSize)
+				flowCtx := FlowCtx{
+					EvalCtx:  evalCtx,
+					Settings: st,
+					// Pass a DB without a TxnCoordSender.
+					txn: client.NewTxn(client.NewDB(s.DistSender(), s.Clock()), s.NodeID()),
 				}
-				encRows[rowIdx] = encRow
-			}
-			in := NewRowBuffer(twoIntCols, encRows, RowBufferArgs{})
-
-			out := &RowBuffer{}
-			jr, err := newJoinReader(&flowCtx, &JoinReaderSpec{Table: *td}, in, &c.post, out)
-			if err != nil {
-				t.Fatal(err)
-			}
-
-			jr.Run(context.Background(), nil)
-
-			if !in.Done {
-				t.Fatal("joinReader didn't consume all the rows")
-			}
-			if !out.ProducerClosed {
-				t.Fatalf("output RowReceiver not closed")
-			}
-
-			var res sqlbase.EncDatumRows
-			for {
-				row := out.NextNoMeta(t)
-				if row == nil {
-					break
+
+				encRows := make(sqlbase.EncDatumRows, len(c.input))
+				for rowIdx, row := range c.input {
+					encRow := make(sqlbase.EncDatumRow, len(row))
+					for i, d := range row {
+						encRow[i] = sqlbase.DatumToEncDatum(intType, d)
+					}
+					encRows[rowIdx] = encRow
+				}
+				in := NewRowBuffer(twoIntCols, encRows, RowBufferArgs{})
+
+				out := &RowBuffer{}
+				jr, err := newJoinReader(&flowCtx, &JoinReaderSpec{Table: *td}, in, &c.post, out)
+				if err != nil {
+					t.Fatal(err)
 				}
-				res = append(res, row)
-			}
 
-			if result := res.String(c.outputTypes); result != c.expected {
-				t.Errorf("invalid results: %s, expected %s'", result, c.expected)
-			}
-		})
+				jr.Run(context.Background(), nil)
+
+				if !in.Done {
+					t.Fatal("joinReader didn't consume all the rows")
+				}
+				if !out.ProducerClosed {
+					t.Fatalf("output RowReceiver not closed")
+				}
+
+				var res sqlbase.EncDatumRows
+				for {
+					row := out.NextNoMeta(t)
+					if row == nil {
+						break
+					}
+					res = append(res, row)
+				}
+
+				if result := res.String(c.outputTypes); result != c.expected {
+					t.Errorf("invalid results: %s, expected %s'", result, c.expected)
+				}
+			})
+		}
 	}
 }
 
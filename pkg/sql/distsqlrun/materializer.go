@@ -0,0 +1,140 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// materializer is a processor that runs a tree of columnar operators and
+// converts the batches they produce back into rows, which are passed through
+// the processor's post-processing stage.
+type materializer struct {
+	processorBase
+
+	flowCtx *FlowCtx
+	input   exec.Operator
+	// inputs are the row sources feeding the columnar operators. They are
+	// drained or closed when the consumer stops accepting rows.
+	inputs []RowSource
+
+	types []sqlbase.ColumnType
+	row   sqlbase.EncDatumRow
+	da    sqlbase.DatumAlloc
+}
+
+var _ Processor = &materializer{}
+
+// newMaterializer creates a materializer for the columnar operator input,
+// whose output schema is described by types.
+func newMaterializer(
+	flowCtx *FlowCtx,
+	input exec.Operator,
+	types []sqlbase.ColumnType,
+	inputs []RowSource,
+	post *PostProcessSpec,
+	output RowReceiver,
+) (*materializer, error) {
+	m := &materializer{
+		flowCtx: flowCtx,
+		input:   input,
+		inputs:  inputs,
+		types:   types,
+		row:     make(sqlbase.EncDatumRow, len(types)),
+	}
+	if err := m.init(post, types, flowCtx, output); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Run is part of the processor interface.
+func (m *materializer) Run(ctx context.Context, wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+	ctx, span := processorSpan(ctx, "materializer")
+	defer tracing.FinishSpan(span)
+
+	// closed is set if emitHelper closed the output, in which case there is
+	// nothing left to do.
+	var closed bool
+	err := exec.CatchVectorizedRuntimeError(func() {
+		m.input.Init()
+		for {
+			batch := m.input.Next()
+			n := batch.Length()
+			if n == 0 {
+				return
+			}
+			sel := batch.Selection()
+			for k := uint16(0); k < n; k++ {
+				i := k
+				if sel != nil {
+					i = sel[k]
+				}
+				for c := range m.types {
+					m.row[c] = sqlbase.DatumToEncDatum(
+						m.types[c], colVecToDatum(batch.ColVec(c), i, &m.types[c], &m.da),
+					)
+				}
+				if !emitHelper(ctx, &m.out, m.row, ProducerMetadata{}, m.inputs...) {
+					closed = true
+					return
+				}
+			}
+		}
+	})
+	if closed {
+		return
+	}
+	if err != nil {
+		DrainAndClose(ctx, m.out.output, err, m.inputs...)
+		return
+	}
+	sendTraceData(ctx, m.out.output)
+	m.out.Close()
+}
+
+// colVecToDatum returns the ith value of vec as a Datum of the given column
+// type.
+func colVecToDatum(
+	vec exec.ColVec, i uint16, columnType *sqlbase.ColumnType, alloc *sqlbase.DatumAlloc,
+) tree.Datum {
+	if vec.NullAt(i) {
+		return tree.DNull
+	}
+	switch columnType.SemanticType {
+	case sqlbase.ColumnType_BOOL:
+		return tree.MakeDBool(tree.DBool(vec.Bool()[i]))
+	case sqlbase.ColumnType_INT:
+		return alloc.NewDInt(tree.DInt(vec.Int64()[i]))
+	case sqlbase.ColumnType_FLOAT:
+		return alloc.NewDFloat(tree.DFloat(vec.Float64()[i]))
+	case sqlbase.ColumnType_BYTES:
+		return alloc.NewDBytes(tree.DBytes(vec.Bytes()[i]))
+	case sqlbase.ColumnType_STRING:
+		return alloc.NewDString(tree.DString(vec.Bytes()[i]))
+	default:
+		panic("unsupported column type " + columnType.SQLString())
+	}
+}
@@ -29,6 +29,8 @@ type DistSQLMetrics struct {
 	FlowsTotal    *metric.Counter
 	MaxBytesHist  *metric.Histogram
 	CurBytesCount *metric.Counter
+
+	DiskSpillsTotal *metric.Counter
 }
 
 // MetricStruct implements the metrics.Struct interface.
@@ -55,6 +57,9 @@ var (
 	metaMemCurBytes = metric.Metadata{
 		Name: "sql.mem.distsql.current",
 		Help: "Current sql statement memory usage for distsql"}
+	metaDiskSpillsTotal = metric.Metadata{
+		Name: "sql.distsql.temp_storage.spills",
+		Help: "Number of times a distributed SQL processor fell back to temp storage"}
 )
 
 // See pkg/sql/mem_metrics.go
@@ -70,6 +75,8 @@ func MakeDistSQLMetrics(histogramWindow time.Duration) DistSQLMetrics {
 		FlowsTotal:    metric.NewCounter(metaFlowsTotal),
 		MaxBytesHist:  metric.NewHistogram(metaMemMaxBytes, histogramWindow, log10int64times1000, 3),
 		CurBytesCount: metric.NewCounter(metaMemCurBytes),
+
+		DiskSpillsTotal: metric.NewCounter(metaDiskSpillsTotal),
 	}
 }
 
@@ -390,6 +390,9 @@ func newProcessor(
 	inputs []RowSource,
 	outputs []RowReceiver,
 ) (Processor, error) {
+	if p, err := newVectorizedProcessor(flowCtx, core, post, inputs, outputs); p != nil || err != nil {
+		return p, err
+	}
 	if core.Noop != nil {
 		if err := checkNumInOut(inputs, outputs, 1, 1); err != nil {
 			return nil, err
@@ -88,6 +88,12 @@ var settingUseTempStorageJoins = settings.RegisterBoolSetting(
 	true,
 )
 
+var settingUseTempStorageAggregations = settings.RegisterBoolSetting(
+	"sql.distsql.temp_storage.aggregations",
+	"set to true to enable use of disk for distributed sql aggregations",
+	true,
+)
+
 var settingWorkMemBytes = settings.RegisterByteSizeSetting(
 	"sql.distsql.temp_storage.workmem",
 	"maximum amount of memory in bytes a processor can use before falling back to temp storage",
@@ -289,6 +295,7 @@ func (ds *ServerImpl) setupFlow(
 		nodeID:         nodeID,
 		TempStorage:    ds.TempStorage,
 		diskMonitor:    ds.DiskMonitor,
+		metrics:        ds.Metrics,
 		JobRegistry:    ds.ServerConfig.JobRegistry,
 	}
 
@@ -97,9 +97,9 @@ func (s *sorter) Run(ctx context.Context, wg *sync.WaitGroup) {
 	useTempStorage := settingUseTempStorageSorts.Get(&st.SV) ||
 		s.flowCtx.testingKnobs.MemoryLimitBytes > 0
 	rowContainerMon := s.flowCtx.EvalCtx.Mon
-	if s.matchLen == 0 && s.count == 0 && useTempStorage {
-		// We will use the sortAllStrategy in this case and potentially fall
-		// back to disk.
+	if s.count == 0 && useTempStorage {
+		// We will use the sortAllStrategy or the sortChunksStrategy in this case
+		// and potentially fall back to disk.
 		// Limit the memory use by creating a child monitor with a hard limit.
 		// The strategy will overflow to disk if this limit is not enough.
 		limit := s.flowCtx.testingKnobs.MemoryLimitBytes
@@ -139,7 +139,7 @@ func (s *sorter) Run(ctx context.Context, wg *sync.WaitGroup) {
 		// chunk and then output.
 		// TODO(irfansharif): Add optimization for case where both ordering match
 		// length and limit is specified.
-		ss = newSortChunksStrategy(&sv)
+		ss = newSortChunksStrategy(&sv, useTempStorage)
 	}
 
 	sortErr := ss.Execute(ctx, s)
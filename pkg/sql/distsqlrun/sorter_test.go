@@ -219,6 +219,8 @@ func TestSorter(t *testing.T) {
 		// initialization.
 		// 2048: A memory limit that should not be hit; the strategy will not
 		// use disk.
+		// Strategies that sort chunks of the input fall back to disk on a
+		// per-chunk basis.
 		for _, memLimit := range []int64{0, 1, 1150, 2048} {
 			t.Run(fmt.Sprintf("%sMemLimit=%d", c.name, memLimit), func(t *testing.T) {
 				in := NewRowBuffer(c.types, c.input, RowBufferArgs{})
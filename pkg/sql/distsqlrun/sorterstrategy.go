@@ -67,36 +67,18 @@ func (ss *sortAllStrategy) Execute(ctx context.Context, s *sorter) error {
 	// We return the memory error if the row is nil because this case implies
 	// that we received the memory error from a code path that was not adding
 	// a row (e.g. from an upstream processor).
-	if pgErr, ok := pgerror.GetPGCause(err); !(ok && pgErr.Code == pgerror.CodeOutOfMemoryError) || row == nil {
+	if !isMemoryError(err) || row == nil {
 		return err
 	}
 	if !ss.useTempStorage {
 		return errors.Wrap(err, "external storage for large queries disabled")
 	}
 	log.VEventf(ctx, 2, "falling back to disk")
-	diskContainer := makeDiskRowContainer(
-		ctx, s.flowCtx.diskMonitor, ss.rows.types, ss.rows.ordering, s.tempStorage,
-	)
-	defer diskContainer.Close(ctx)
-
-	// Transfer the rows from memory to disk. Note that this frees up the
-	// memory taken up by ss.rows.
-	i := ss.rows.NewIterator(ctx)
-	defer i.Close()
-	for i.Rewind(); ; i.Next() {
-		if ok, err := i.Valid(); err != nil {
-			return err
-		} else if !ok {
-			break
-		}
-		memRow, err := i.Row()
-		if err != nil {
-			return err
-		}
-		if err := diskContainer.AddRow(ctx, memRow); err != nil {
-			return err
-		}
+	diskContainer, err := spillToDisk(ctx, s, ss.rows)
+	if err != nil {
+		return err
 	}
+	defer diskContainer.Close(ctx)
 
 	// Add the row that caused the memory container to run out of memory.
 	if err := diskContainer.AddRow(ctx, row); err != nil {
@@ -237,23 +219,94 @@ func (ss *sortTopKStrategy) Execute(ctx context.Context, s *sorter) error {
 	return nil
 }
 
-// If we're scanning an index with a prefix matching an ordering prefix, we only accumulate values
-// for equal fields in this prefix, sort the accumulated chunk and then output.
+// spillToDisk creates a diskRowContainer with the same schema and ordering as
+// the given memRowContainer and transfers all of its rows to it. Note that this
+// frees up the memory taken up by the rows in the memRowContainer. The caller
+// is responsible for closing the returned container.
+func spillToDisk(
+	ctx context.Context, s *sorter, rows *memRowContainer,
+) (diskRowContainer, error) {
+	s.flowCtx.noteDiskSpill()
+	diskContainer := makeDiskRowContainer(
+		ctx, s.flowCtx.diskMonitor, rows.types, rows.ordering, s.tempStorage,
+	)
+	i := rows.NewIterator(ctx)
+	defer i.Close()
+	for i.Rewind(); ; i.Next() {
+		if ok, err := i.Valid(); err != nil {
+			diskContainer.Close(ctx)
+			return diskRowContainer{}, err
+		} else if !ok {
+			break
+		}
+		memRow, err := i.Row()
+		if err != nil {
+			diskContainer.Close(ctx)
+			return diskRowContainer{}, err
+		}
+		if err := diskContainer.AddRow(ctx, memRow); err != nil {
+			diskContainer.Close(ctx)
+			return diskRowContainer{}, err
+		}
+	}
+	return diskContainer, nil
+}
+
+// isMemoryError returns true if err is the error returned when a memory
+// monitor's budget is exceeded.
+func isMemoryError(err error) bool {
+	pgErr, ok := pgerror.GetPGCause(err)
+	return ok && pgErr.Code == pgerror.CodeOutOfMemoryError
+}
+
+// If we're scanning an index with a prefix matching an ordering prefix, we
+// only accumulate values for equal fields in this prefix, sort the accumulated
+// chunk and then output. If a single chunk doesn't fit in memory and use of
+// temporary storage is enabled, that chunk is sorted on disk instead.
 type sortChunksStrategy struct {
-	rows  *memRowContainer
-	alloc sqlbase.DatumAlloc
+	rows           *memRowContainer
+	alloc          sqlbase.DatumAlloc
+	useTempStorage bool
 }
 
 var _ sorterStrategy = &sortChunksStrategy{}
 
-func newSortChunksStrategy(rows *memRowContainer) sorterStrategy {
+func newSortChunksStrategy(rows *memRowContainer, useTempStorage bool) sorterStrategy {
 	return &sortChunksStrategy{
-		rows: rows,
+		rows:           rows,
+		useTempStorage: useTempStorage,
 	}
 }
 
 func (ss *sortChunksStrategy) Execute(ctx context.Context, s *sorter) error {
 	defer ss.rows.Close(ctx)
+
+	nextRow, err := s.input.NextRow()
+	if err != nil || nextRow == nil {
+		return err
+	}
+
+	for {
+		var consumerDone bool
+		nextRow, consumerDone, err = ss.sortChunk(ctx, s, nextRow)
+		if err != nil || consumerDone {
+			return err
+		}
+		if nextRow == nil {
+			// We've reached the end of the table.
+			return nil
+		}
+	}
+}
+
+// sortChunk accumulates the chunk of rows starting with the given row, i.e.
+// all the rows that share the same values for the first s.matchLen ordering
+// columns, and outputs them in sorted order. It returns the first row of the
+// next chunk (nil if the input is exhausted), and whether the consumer
+// indicated that no more rows are needed.
+func (ss *sortChunksStrategy) sortChunk(
+	ctx context.Context, s *sorter, row sqlbase.EncDatumRow,
+) (nextRow sqlbase.EncDatumRow, consumerDone bool, _ error) {
 	// pivoted is a helper function that determines if the given row shares the same values for the
 	// first s.matchLen ordering columns with the given pivot.
 	pivoted := func(row, pivot sqlbase.EncDatumRow) (bool, error) {
@@ -267,76 +320,96 @@ func (ss *sortChunksStrategy) Execute(ctx context.Context, s *sorter) error {
 		return true, nil
 	}
 
-	nextRow, err := s.input.NextRow()
-	if err != nil || nextRow == nil {
-		return err
-	}
+	// The chunk is accumulated in memory, unless it turns out to be too large,
+	// in which case it is moved to a disk-backed container.
+	var chunk sortableRowContainer = ss.rows
+	var diskChunk *diskRowContainer
+	defer func() {
+		if diskChunk != nil {
+			diskChunk.Close(ctx)
+		}
+	}()
 
+	pivot := row
+	nextRow = row
+	// We will accumulate rows to form a chunk such that they all share the same values
+	// for the first s.matchLen ordering columns.
 	for {
-		pivot := nextRow
-
-		// We will accumulate rows to form a chunk such that they all share the same values
-		// for the first s.matchLen ordering columns.
-		for {
-			if log.V(3) {
-				log.Infof(ctx, "pushing row %s", nextRow.String(ss.rows.types))
-			}
-			if err := ss.rows.AddRow(ctx, nextRow); err != nil {
-				return err
-			}
-
-			nextRow, err = s.input.NextRow()
-			if err != nil {
-				return err
-			}
-			if nextRow == nil {
-				break
+		if log.V(3) {
+			log.Infof(ctx, "pushing row %s", nextRow.String(ss.rows.types))
+		}
+		if err := chunk.AddRow(ctx, nextRow); err != nil {
+			if diskChunk != nil || !ss.useTempStorage || !isMemoryError(err) {
+				return nil, false, err
 			}
-
-			p, err := pivoted(nextRow, pivot)
+			log.VEventf(ctx, 2, "chunk does not fit in memory; falling back to disk")
+			d, err := spillToDisk(ctx, s, ss.rows)
 			if err != nil {
-				return err
+				return nil, false, err
 			}
-			if p {
-				continue
+			diskChunk = &d
+			chunk = diskChunk
+			// Add the row that caused the memory container to run out of memory.
+			if err := chunk.AddRow(ctx, nextRow); err != nil {
+				return nil, false, err
 			}
+		}
 
-			// We verify if the nextRow here is infact 'greater' than pivot.
-			if cmp, err := nextRow.Compare(
-				ss.rows.types, &ss.alloc, s.ordering, ss.rows.evalCtx, pivot,
-			); err != nil {
-				return err
-			} else if cmp < 0 {
-				return errors.Errorf(
-					"incorrectly ordered row %s before %s",
-					pivot.String(ss.rows.types),
-					nextRow.String(ss.rows.types),
-				)
-			}
+		var err error
+		nextRow, err = s.input.NextRow()
+		if err != nil {
+			return nil, false, err
+		}
+		if nextRow == nil {
 			break
 		}
 
-		// Sort the rows that have been pushed onto the buffer.
-		ss.rows.Sort(ctx)
+		p, err := pivoted(nextRow, pivot)
+		if err != nil {
+			return nil, false, err
+		}
+		if p {
+			continue
+		}
 
-		// Stream out sorted rows in order to row receiver.
-		for ss.rows.Len() > 0 {
-			consumerStatus, err := s.out.EmitRow(ctx, ss.rows.EncRow(0))
-			if err != nil || consumerStatus != NeedMoreRows {
-				// We don't need any more rows; clear out ss so to not hold on to that
-				// memory.
-				ss = &sortChunksStrategy{}
-				return err
-			}
-			ss.rows.PopFirst()
+		// We verify if the nextRow here is infact 'greater' than pivot.
+		if cmp, err := nextRow.Compare(
+			ss.rows.types, &ss.alloc, s.ordering, ss.rows.evalCtx, pivot,
+		); err != nil {
+			return nil, false, err
+		} else if cmp < 0 {
+			return nil, false, errors.Errorf(
+				"incorrectly ordered row %s before %s",
+				pivot.String(ss.rows.types),
+				nextRow.String(ss.rows.types),
+			)
 		}
-		ss.rows.Clear(ctx)
+		break
+	}
 
-		if nextRow == nil {
-			// We've reached the end of the table.
+	// Sort the rows that have been pushed onto the buffer. In the disk-backed
+	// case, the rows are already kept in sorted order.
+	chunk.Sort(ctx)
+
+	// Stream out sorted rows in order to row receiver. Note that iterating over
+	// the in-memory container deletes the rows as they are output.
+	i := chunk.NewIterator(ctx)
+	defer i.Close()
+	for i.Rewind(); ; i.Next() {
+		if ok, err := i.Valid(); err != nil {
+			return nil, false, err
+		} else if !ok {
 			break
 		}
+		row, err := i.Row()
+		if err != nil {
+			return nil, false, err
+		}
+		consumerStatus, err := s.out.EmitRow(ctx, row)
+		if err != nil || consumerStatus != NeedMoreRows {
+			return nil, true, err
+		}
 	}
-
-	return nil
+	ss.rows.Clear(ctx)
+	return nextRow, false, nil
 }
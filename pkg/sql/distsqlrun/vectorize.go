@@ -0,0 +1,413 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+var settingVectorize = settings.RegisterBoolSetting(
+	"sql.distsql.vectorize.enabled",
+	"set to true to run supported processors with the experimental columnar execution engine",
+	false,
+)
+
+// newVectorizedProcessor attempts to build a columnar implementation of the
+// given processor, which consists of columnarizers for each of the inputs, a
+// tree of exec.Operators implementing the processor core, and a materializer
+// that performs the post-processing stage.
+//
+// It returns a nil Processor (and no error) if columnar execution is disabled
+// or the processor isn't supported by the columnar engine, in which case the
+// caller should fall back to the row-based implementation.
+func newVectorizedProcessor(
+	flowCtx *FlowCtx,
+	core *ProcessorCoreUnion,
+	post *PostProcessSpec,
+	inputs []RowSource,
+	outputs []RowReceiver,
+) (Processor, error) {
+	if flowCtx.Settings == nil || !settingVectorize.Get(&flowCtx.Settings.SV) {
+		return nil, nil
+	}
+	if len(outputs) != 1 {
+		return nil, nil
+	}
+	for _, input := range inputs {
+		if _, ok := exec.FromColumnTypes(input.Types()); !ok {
+			return nil, nil
+		}
+	}
+
+	switch {
+	case core.Noop != nil:
+		return newVectorizedNoop(flowCtx, post, inputs, outputs[0])
+	case core.TableReader != nil:
+		return newVectorizedTableReader(flowCtx, core.TableReader, post, outputs[0])
+	case core.Aggregator != nil:
+		return newVectorizedAggregator(flowCtx, core.Aggregator, post, inputs, outputs[0])
+	case core.HashJoiner != nil:
+		return newVectorizedHashJoiner(flowCtx, core.HashJoiner, post, inputs, outputs[0])
+	}
+	return nil, nil
+}
+
+// newVectorizedNoop plans a noop processor whose post-processing filter
+// and render expressions are supported by the columnar engine as a chain of
+// columnar selection and projection operators. The rest of the
+// post-processing is done by the materializer.
+func newVectorizedNoop(
+	flowCtx *FlowCtx, post *PostProcessSpec, inputs []RowSource, output RowReceiver,
+) (Processor, error) {
+	if len(inputs) != 1 {
+		return nil, nil
+	}
+	col, err := newColumnarizer(inputs[0], output)
+	if err != nil {
+		return nil, err
+	}
+	op, types, remaining, ok, err := planPostProcessOps(flowCtx, col, inputs[0].Types(), post)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return newMaterializer(flowCtx, op, types, inputs, remaining, output)
+}
+
+// vectorizedTableReader is a scan whose post-processing runs on the columnar
+// engine. The rows produced by a row-based tableReader are columnarized and
+// fed through the selection and projection operators implementing the
+// post-processing, and the materializer converts the result back into rows.
+type vectorizedTableReader struct {
+	tr *tableReader
+	m  *materializer
+}
+
+var _ Processor = &vectorizedTableReader{}
+
+// newVectorizedTableReader plans a columnar scan if all the columns of the
+// table are supported by the columnar engine and at least part of the
+// post-processing can run on it.
+//
+// The tableReader doesn't do any post-processing, so all the columns of the
+// table are decoded and the filter and limits are applied downstream.
+func newVectorizedTableReader(
+	flowCtx *FlowCtx, spec *TableReaderSpec, post *PostProcessSpec, output RowReceiver,
+) (Processor, error) {
+	types := make([]sqlbase.ColumnType, len(spec.Table.Columns))
+	for i := range types {
+		types[i] = spec.Table.Columns[i].Type
+	}
+	if _, ok := exec.FromColumnTypes(types); !ok {
+		return nil, nil
+	}
+	rows := &RowChannel{}
+	rows.Init(types)
+	col, err := newColumnarizer(rows, output)
+	if err != nil {
+		return nil, err
+	}
+	op, opTypes, remaining, ok, err := planPostProcessOps(flowCtx, col, types, post)
+	if err != nil || !ok {
+		return nil, err
+	}
+	tr, err := newTableReader(flowCtx, spec, &PostProcessSpec{}, rows)
+	if err != nil {
+		return nil, err
+	}
+	m, err := newMaterializer(flowCtx, op, opTypes, []RowSource{rows}, remaining, output)
+	if err != nil {
+		return nil, err
+	}
+	return &vectorizedTableReader{tr: tr, m: m}, nil
+}
+
+// OutputTypes is part of the processor interface.
+func (v *vectorizedTableReader) OutputTypes() []sqlbase.ColumnType {
+	return v.m.OutputTypes()
+}
+
+// Run is part of the processor interface.
+func (v *vectorizedTableReader) Run(ctx context.Context, wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+	var scanWG sync.WaitGroup
+	scanWG.Add(1)
+	go v.tr.Run(ctx, &scanWG)
+	// The materializer drains or closes the RowChannel when it's done, which
+	// causes the tableReader to stop.
+	v.m.Run(ctx, nil /* wg */)
+	scanWG.Wait()
+}
+
+// planPostProcessOps plans the parts of post that are supported by the
+// columnar engine as operators on top of input, whose batches have the given
+// column types. It returns the resulting operator, the column types of its
+// batches and the post-processing left for the materializer, or false if no
+// part of post could be planned.
+//
+// The render expressions are only planned if the filter is, since they would
+// otherwise be evaluated on rows that don't pass the filter.
+func planPostProcessOps(
+	flowCtx *FlowCtx, input exec.Operator, types []sqlbase.ColumnType, post *PostProcessSpec,
+) (_ exec.Operator, _ []sqlbase.ColumnType, _ *PostProcessSpec, ok bool, _ error) {
+	op := input
+	remaining := *post
+	if post.Filter.Expr != "" {
+		var filter exprHelper
+		if err := filter.init(post.Filter, types, flowCtx.NewEvalCtx()); err != nil {
+			return nil, nil, nil, false, err
+		}
+		if sel, selOK := planSelectionOps(op, types, filter.expr); selOK {
+			op, ok = sel, true
+			remaining.Filter = Expression{}
+		}
+	}
+	if len(post.RenderExprs) == 0 || remaining.Filter.Expr != "" {
+		return op, types, &remaining, ok, nil
+	}
+
+	renderOp, renderTypes := op, types
+	outputCols := make([]uint32, len(post.RenderExprs))
+	for i, expr := range post.RenderExprs {
+		var render exprHelper
+		if err := render.init(expr, types, flowCtx.NewEvalCtx()); err != nil {
+			return nil, nil, nil, false, err
+		}
+		var colIdx int
+		var projOK bool
+		renderOp, colIdx, renderTypes, projOK = planProjectionOps(renderOp, renderTypes, render.expr)
+		if !projOK {
+			return op, types, &remaining, ok, nil
+		}
+		outputCols[i] = uint32(colIdx)
+	}
+	remaining.RenderExprs = nil
+	remaining.Projection = true
+	remaining.OutputColumns = outputCols
+	return renderOp, renderTypes, &remaining, true, nil
+}
+
+// planProjectionOps returns a tree of projection operators on top of input
+// that computes the value of expr, along with the index of the column that
+// holds the result and the column types of the resulting batches. It returns
+// false if the expression cannot be evaluated by the columnar engine.
+//
+// IndexedVars don't need an operator; the result of every other expression is
+// appended to the batches as a new column.
+func planProjectionOps(
+	input exec.Operator, types []sqlbase.ColumnType, expr tree.TypedExpr,
+) (_ exec.Operator, colIdx int, _ []sqlbase.ColumnType, ok bool) {
+	switch t := expr.(type) {
+	case *tree.ParenExpr:
+		return planProjectionOps(input, types, t.TypedInnerExpr())
+	case *tree.IndexedVar:
+		return input, t.Idx, types, true
+	case *tree.BinaryExpr:
+		resType, err := sqlbase.DatumTypeToColumnType(t.ResolvedType())
+		if err != nil {
+			return nil, 0, nil, false
+		}
+		var op exec.Operator
+		var leftIdx int
+		op, leftIdx, types, ok = planProjectionOps(input, types, t.TypedLeft())
+		if !ok || types[leftIdx].SemanticType != resType.SemanticType {
+			return nil, 0, nil, false
+		}
+		var constArg interface{}
+		switch d := t.TypedRight().(type) {
+		case *tree.DInt:
+			constArg = int64(*d)
+		case *tree.DFloat:
+			constArg = float64(*d)
+		}
+		typ := exec.FromColumnType(resType)
+		if constArg != nil {
+			op, err = exec.NewProjConstOp(typ, t.Operator, op, leftIdx, constArg, len(types))
+		} else {
+			var rightIdx int
+			op, rightIdx, types, ok = planProjectionOps(op, types, t.TypedRight())
+			if !ok || types[rightIdx].SemanticType != resType.SemanticType {
+				return nil, 0, nil, false
+			}
+			op, err = exec.NewProjOp(typ, t.Operator, op, leftIdx, rightIdx, len(types))
+		}
+		if err != nil {
+			return nil, 0, nil, false
+		}
+		outTypes := make([]sqlbase.ColumnType, len(types), len(types)+1)
+		copy(outTypes, types)
+		return op, len(types), append(outTypes, resType), true
+	}
+	return nil, 0, nil, false
+}
+
+// planSelectionOps returns a tree of selection operators on top of input
+// implementing the filter expr, or false if the expression cannot be
+// evaluated by the columnar engine.
+func planSelectionOps(
+	input exec.Operator, types []sqlbase.ColumnType, expr tree.TypedExpr,
+) (exec.Operator, bool) {
+	switch t := expr.(type) {
+	case *tree.ParenExpr:
+		return planSelectionOps(input, types, t.TypedInnerExpr())
+	case *tree.AndExpr:
+		left, ok := planSelectionOps(input, types, t.TypedLeft())
+		if !ok {
+			return nil, false
+		}
+		return planSelectionOps(left, types, t.TypedRight())
+	case *tree.IndexedVar:
+		if types[t.Idx].SemanticType != sqlbase.ColumnType_BOOL {
+			return nil, false
+		}
+		return exec.NewSelBoolOp(input, t.Idx), true
+	case *tree.ComparisonExpr:
+		switch t.Operator {
+		case tree.EQ, tree.NE, tree.LT, tree.LE, tree.GT, tree.GE:
+		default:
+			return nil, false
+		}
+		ivar, ok := t.TypedLeft().(*tree.IndexedVar)
+		if !ok {
+			return nil, false
+		}
+		var constArg interface{}
+		switch d := t.TypedRight().(type) {
+		case *tree.DInt:
+			constArg = int64(*d)
+		case *tree.DFloat:
+			constArg = float64(*d)
+		case *tree.DString:
+			constArg = []byte(*d)
+		case *tree.DBytes:
+			constArg = []byte(*d)
+		default:
+			return nil, false
+		}
+		op, err := exec.NewSelConstOp(
+			exec.FromColumnType(types[ivar.Idx]), t.Operator, input, ivar.Idx, constArg,
+		)
+		if err != nil {
+			return nil, false
+		}
+		return op, true
+	}
+	return nil, false
+}
+
+// newVectorizedAggregator plans an aggregator that groups on at most one INT
+// column and only computes IDENT, SUM_INT and COUNT_ROWS aggregations.
+func newVectorizedAggregator(
+	flowCtx *FlowCtx,
+	spec *AggregatorSpec,
+	post *PostProcessSpec,
+	inputs []RowSource,
+	output RowReceiver,
+) (Processor, error) {
+	if len(inputs) != 1 || len(spec.GroupCols) > 1 {
+		return nil, nil
+	}
+	types := inputs[0].Types()
+	isInt := func(col uint32) bool {
+		return int(col) < len(types) && types[col].SemanticType == sqlbase.ColumnType_INT
+	}
+	groupCol := -1
+	if len(spec.GroupCols) == 1 {
+		if !isInt(spec.GroupCols[0]) {
+			return nil, nil
+		}
+		groupCol = int(spec.GroupCols[0])
+	}
+	aggs := make([]exec.AggSpec, len(spec.Aggregations))
+	outputTypes := make([]sqlbase.ColumnType, len(spec.Aggregations))
+	for i, agg := range spec.Aggregations {
+		if agg.Distinct || agg.FilterColIdx != nil {
+			return nil, nil
+		}
+		switch agg.Func {
+		case AggregatorSpec_IDENT:
+			if len(agg.ColIdx) != 1 || int(agg.ColIdx[0]) != groupCol {
+				return nil, nil
+			}
+			aggs[i] = exec.AggSpec{Func: exec.AggIdent, ColIdx: groupCol}
+		case AggregatorSpec_SUM_INT:
+			if len(agg.ColIdx) != 1 || !isInt(agg.ColIdx[0]) {
+				return nil, nil
+			}
+			aggs[i] = exec.AggSpec{Func: exec.AggSumInt, ColIdx: int(agg.ColIdx[0])}
+		case AggregatorSpec_COUNT_ROWS:
+			aggs[i] = exec.AggSpec{Func: exec.AggCountRows}
+		default:
+			return nil, nil
+		}
+		outputTypes[i] = sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}
+	}
+
+	col, err := newColumnarizer(inputs[0], output)
+	if err != nil {
+		return nil, err
+	}
+	op, err := exec.NewHashAggregatorInt64(col, groupCol, aggs)
+	if err != nil {
+		return nil, err
+	}
+	return newMaterializer(flowCtx, op, outputTypes, inputs, post, output)
+}
+
+// newVectorizedHashJoiner plans an inner hash join on a single INT equality
+// column with no ON expression.
+func newVectorizedHashJoiner(
+	flowCtx *FlowCtx,
+	spec *HashJoinerSpec,
+	post *PostProcessSpec,
+	inputs []RowSource,
+	output RowReceiver,
+) (Processor, error) {
+	if len(inputs) != 2 || spec.Type != JoinType_INNER || spec.OnExpr.Expr != "" ||
+		spec.MergedColumns || len(spec.LeftEqColumns) != 1 || len(spec.RightEqColumns) != 1 {
+		return nil, nil
+	}
+	leftTypes, rightTypes := inputs[0].Types(), inputs[1].Types()
+	leftEqCol, rightEqCol := int(spec.LeftEqColumns[0]), int(spec.RightEqColumns[0])
+	if leftEqCol >= len(leftTypes) || rightEqCol >= len(rightTypes) ||
+		leftTypes[leftEqCol].SemanticType != sqlbase.ColumnType_INT ||
+		rightTypes[rightEqCol].SemanticType != sqlbase.ColumnType_INT {
+		return nil, nil
+	}
+
+	left, err := newColumnarizer(inputs[0], output)
+	if err != nil {
+		return nil, err
+	}
+	right, err := newColumnarizer(inputs[1], output)
+	if err != nil {
+		return nil, err
+	}
+	op := exec.NewHashJoinEqInnerInt64Op(
+		left, right, left.typs, right.typs, leftEqCol, rightEqCol,
+	)
+	outputTypes := make([]sqlbase.ColumnType, 0, len(leftTypes)+len(rightTypes))
+	outputTypes = append(outputTypes, leftTypes...)
+	outputTypes = append(outputTypes, rightTypes...)
+	return newMaterializer(flowCtx, op, outputTypes, inputs, post, output)
+}
@@ -0,0 +1,317 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlrun
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// runProcessorWithVectorize runs the given processor on the inputs, with
+// columnar execution enabled or disabled, and returns the sorted string
+// representations of the output rows along with whether the columnar engine
+// was used.
+func runProcessorWithVectorize(
+	t *testing.T,
+	vectorize bool,
+	core ProcessorCoreUnion,
+	post PostProcessSpec,
+	inputTypes [][]sqlbase.ColumnType,
+	inputRows []sqlbase.EncDatumRows,
+) (_ []string, vectorized bool) {
+	st := cluster.MakeTestingClusterSettings()
+	settingVectorize.Override(&st.SV, vectorize)
+	evalCtx := tree.MakeTestingEvalContext()
+	defer evalCtx.Stop(context.Background())
+	flowCtx := FlowCtx{
+		Settings: st,
+		EvalCtx:  evalCtx,
+	}
+
+	inputs := make([]RowSource, len(inputRows))
+	for i := range inputRows {
+		inputs[i] = NewRowBuffer(inputTypes[i], inputRows[i], RowBufferArgs{})
+	}
+	out := &RowBuffer{}
+	p, err := newProcessor(&flowCtx, &core, &post, inputs, []RowReceiver{out})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, vectorized = p.(*materializer)
+	p.Run(context.Background(), nil /* wg */)
+	if !out.ProducerClosed {
+		t.Fatalf("output RowReceiver not closed")
+	}
+
+	var res []string
+	for _, row := range out.GetRowsNoMeta(t) {
+		res = append(res, row.String(p.OutputTypes()))
+	}
+	sort.Strings(res)
+	return res, vectorized
+}
+
+func TestVectorizedMatchesRowEngine(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numRows = 3000
+	var left, right sqlbase.EncDatumRows
+	for i := 0; i < numRows; i++ {
+		left = append(left, sqlbase.EncDatumRow{
+			sqlbase.DatumToEncDatum(intType, tree.NewDInt(tree.DInt(i%97))),
+			sqlbase.DatumToEncDatum(intType, tree.NewDInt(tree.DInt(i))),
+		})
+		var d tree.Datum = tree.NewDInt(tree.DInt(i % 13))
+		if i%7 == 0 {
+			d = tree.DNull
+		}
+		right = append(right, sqlbase.EncDatumRow{
+			sqlbase.DatumToEncDatum(intType, d),
+			sqlbase.DatumToEncDatum(intType, tree.NewDInt(tree.DInt(-i))),
+		})
+	}
+
+	testCases := []struct {
+		name   string
+		core   ProcessorCoreUnion
+		post   PostProcessSpec
+		types  [][]sqlbase.ColumnType
+		inputs []sqlbase.EncDatumRows
+	}{
+		{
+			name:   "filter",
+			core:   ProcessorCoreUnion{Noop: &NoopCoreSpec{}},
+			post:   PostProcessSpec{Filter: Expression{Expr: "@1 > 10 AND @2 != 50"}},
+			types:  [][]sqlbase.ColumnType{twoIntCols},
+			inputs: []sqlbase.EncDatumRows{left},
+		},
+		{
+			name: "filter-with-projection",
+			core: ProcessorCoreUnion{Noop: &NoopCoreSpec{}},
+			post: PostProcessSpec{
+				Filter:        Expression{Expr: "(@1 <= 3)"},
+				Projection:    true,
+				OutputColumns: []uint32{1},
+			},
+			types:  [][]sqlbase.ColumnType{twoIntCols},
+			inputs: []sqlbase.EncDatumRows{left},
+		},
+		{
+			name: "render",
+			core: ProcessorCoreUnion{Noop: &NoopCoreSpec{}},
+			post: PostProcessSpec{
+				Filter:      Expression{Expr: "@1 > 10"},
+				RenderExprs: []Expression{{Expr: "@1 + @2"}, {Expr: "(@2 * 3) - @1"}, {Expr: "@1"}},
+			},
+			types:  [][]sqlbase.ColumnType{twoIntCols},
+			inputs: []sqlbase.EncDatumRows{right},
+		},
+		{
+			// The filter runs on the columnar engine but the render is done by
+			// the materializer.
+			name: "filter-with-unsupported-render",
+			core: ProcessorCoreUnion{Noop: &NoopCoreSpec{}},
+			post: PostProcessSpec{
+				Filter:      Expression{Expr: "@1 > 10"},
+				RenderExprs: []Expression{{Expr: "@2 // 7"}},
+			},
+			types:  [][]sqlbase.ColumnType{twoIntCols},
+			inputs: []sqlbase.EncDatumRows{left},
+		},
+		{
+			name: "aggregator",
+			core: ProcessorCoreUnion{Aggregator: &AggregatorSpec{
+				GroupCols: []uint32{0},
+				Aggregations: []AggregatorSpec_Aggregation{
+					{Func: AggregatorSpec_IDENT, ColIdx: []uint32{0}},
+					{Func: AggregatorSpec_SUM_INT, ColIdx: []uint32{1}},
+					{Func: AggregatorSpec_COUNT_ROWS},
+				},
+			}},
+			types:  [][]sqlbase.ColumnType{twoIntCols},
+			inputs: []sqlbase.EncDatumRows{right},
+		},
+		{
+			name: "scalar-aggregator",
+			core: ProcessorCoreUnion{Aggregator: &AggregatorSpec{
+				Aggregations: []AggregatorSpec_Aggregation{
+					{Func: AggregatorSpec_SUM_INT, ColIdx: []uint32{0}},
+					{Func: AggregatorSpec_COUNT_ROWS},
+				},
+			}},
+			types:  [][]sqlbase.ColumnType{twoIntCols},
+			inputs: []sqlbase.EncDatumRows{right},
+		},
+		{
+			name: "hashjoiner",
+			core: ProcessorCoreUnion{HashJoiner: &HashJoinerSpec{
+				LeftEqColumns:  []uint32{0},
+				RightEqColumns: []uint32{0},
+				Type:           JoinType_INNER,
+			}},
+			post:   PostProcessSpec{Projection: true, OutputColumns: []uint32{1, 3}},
+			types:  [][]sqlbase.ColumnType{twoIntCols, twoIntCols},
+			inputs: []sqlbase.EncDatumRows{left, right},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expected, _ := runProcessorWithVectorize(t, false, tc.core, tc.post, tc.types, tc.inputs)
+			actual, vectorized := runProcessorWithVectorize(t, true, tc.core, tc.post, tc.types, tc.inputs)
+			if !vectorized {
+				t.Fatal("expected the columnar engine to be used")
+			}
+			if len(expected) == 0 {
+				t.Fatal("test case produced no rows")
+			}
+			if fmt.Sprint(expected) != fmt.Sprint(actual) {
+				t.Fatalf("expected:\n%v\nfound:\n%v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestVectorizedFallsBackForUnsupportedPlans(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rows := sqlbase.EncDatumRows{
+		{sqlbase.DatumToEncDatum(intType, tree.NewDInt(1)), sqlbase.DatumToEncDatum(intType, tree.NewDInt(2))},
+	}
+	// An OR filter is not supported by the columnar engine, so the row-based
+	// noop processor must be planned instead.
+	res, vectorized := runProcessorWithVectorize(
+		t, true, /* vectorize */
+		ProcessorCoreUnion{Noop: &NoopCoreSpec{}},
+		PostProcessSpec{Filter: Expression{Expr: "@1 = 1 OR @2 = 1"}},
+		[][]sqlbase.ColumnType{twoIntCols},
+		[]sqlbase.EncDatumRows{rows},
+	)
+	if vectorized {
+		t.Fatal("expected the row engine to be used")
+	}
+	if len(res) != 1 {
+		t.Fatalf("expected 1 row, found %v", res)
+	}
+}
+
+func TestVectorizedTableReader(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlutils.CreateTable(t, sqlDB, "t",
+		"a INT PRIMARY KEY, b INT, c FLOAT, s STRING",
+		2000,
+		sqlutils.ToRowFn(
+			sqlutils.RowIdxFn,
+			func(row int) tree.Datum { return tree.NewDInt(tree.DInt(row % 10)) },
+			func(row int) tree.Datum { return tree.NewDFloat(tree.DFloat(row) / 4) },
+			sqlutils.RowEnglishFn,
+		))
+	td := sqlbase.GetTableDescriptor(kvDB, "test", "t")
+
+	testCases := []struct {
+		name string
+		post PostProcessSpec
+	}{
+		{
+			name: "filter",
+			post: PostProcessSpec{
+				Filter:        Expression{Expr: "@2 < 3 AND @1 >= 100"},
+				Projection:    true,
+				OutputColumns: []uint32{0, 3},
+			},
+		},
+		{
+			name: "render",
+			post: PostProcessSpec{
+				Filter:      Expression{Expr: "@4 != 'five'"},
+				RenderExprs: []Expression{{Expr: "@1 * @2"}, {Expr: "@3 + 1.5"}, {Expr: "@4"}},
+			},
+		},
+		{
+			name: "limit",
+			post: PostProcessSpec{
+				Filter:        Expression{Expr: "@2 = 7"},
+				Projection:    true,
+				OutputColumns: []uint32{0},
+				Offset:        3,
+				Limit:         10,
+			},
+		},
+	}
+
+	run := func(t *testing.T, vectorize bool, post PostProcessSpec) (_ []string, vectorized bool) {
+		st := cluster.MakeTestingClusterSettings()
+		settingVectorize.Override(&st.SV, vectorize)
+		evalCtx := tree.MakeTestingEvalContext()
+		defer evalCtx.Stop(context.Background())
+		flowCtx := FlowCtx{
+			EvalCtx:  evalCtx,
+			Settings: st,
+			// Pass a DB without a TxnCoordSender.
+			txn:    client.NewTxn(client.NewDB(s.DistSender(), s.Clock()), s.NodeID()),
+			nodeID: s.NodeID(),
+		}
+		spec := TableReaderSpec{Table: *td, Spans: []TableReaderSpan{{Span: td.PrimaryIndexSpan()}}}
+		out := &RowBuffer{}
+		p, err := newProcessor(
+			&flowCtx, &ProcessorCoreUnion{TableReader: &spec}, &post, nil /* inputs */, []RowReceiver{out},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, vectorized = p.(*vectorizedTableReader)
+		p.Run(context.Background(), nil /* wg */)
+		if !out.ProducerClosed {
+			t.Fatalf("output RowReceiver not closed")
+		}
+		var res []string
+		for _, row := range out.GetRowsNoMeta(t) {
+			res = append(res, row.String(p.OutputTypes()))
+		}
+		return res, vectorized
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expected, _ := run(t, false, tc.post)
+			actual, vectorized := run(t, true, tc.post)
+			if !vectorized {
+				t.Fatal("expected the columnar engine to be used")
+			}
+			if len(expected) == 0 {
+				t.Fatal("test case produced no rows")
+			}
+			if fmt.Sprint(expected) != fmt.Sprint(actual) {
+				t.Fatalf("expected:\n%v\nfound:\n%v", expected, actual)
+			}
+		})
+	}
+}
@@ -59,6 +59,8 @@ func (p *planner) DropDatabase(ctx context.Context, n *tree.DropDatabase) (planN
 	if dbDesc == nil {
 		if n.IfExists {
 			// Noop.
+			p.addNotice(NoticeSeverityNotice, "",
+				"database %q does not exist, skipping", string(n.Name))
 			return &zeroNode{}, nil
 		}
 		return nil, sqlbase.NewUndefinedDatabaseError(string(n.Name))
@@ -100,9 +102,15 @@ func (p *planner) DropDatabase(ctx context.Context, n *tree.DropDatabase) (planN
 			return nil, errors.Errorf("table %q was described by database %q, but does not exist",
 				tbNames[i].String(), n.Name)
 		}
-		// Recursively check permissions on all dependent views, since some may
-		// be in different databases.
+		// Recursively check permissions on all dependent views and tables,
+		// since some may be in different databases.
 		for _, ref := range tbDesc.DependedOnBy {
+			if tbDesc.IsSequence() {
+				if err := p.canRemoveSequenceDependent(ctx, tbDesc, ref, tree.DropCascade); err != nil {
+					return nil, err
+				}
+				continue
+			}
 			if err := p.canRemoveDependentView(ctx, tbDesc, ref, tree.DropCascade); err != nil {
 				return nil, err
 			}
@@ -146,11 +154,16 @@ func (p *planner) accumulateDependentTables(
 	ctx context.Context, dependentTables map[sqlbase.ID]bool, desc *sqlbase.TableDescriptor,
 ) error {
 	for _, ref := range desc.DependedOnBy {
-		dependentTables[ref.ID] = true
 		dependentDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, ref.ID)
 		if err != nil {
 			return err
 		}
+		if !dependentDesc.IsView() {
+			// Tables only depend on the sequences used by their columns. They
+			// are not dropped with them.
+			continue
+		}
+		dependentTables[ref.ID] = true
 		if err := p.accumulateDependentTables(ctx, dependentTables, dependentDesc); err != nil {
 			return err
 		}
@@ -169,6 +182,12 @@ func (n *dropDatabaseNode) Start(params runParams) error {
 				return err
 			}
 			tbNameStrings = append(tbNameStrings, cascadedViews...)
+		} else if tbDesc.IsSequence() {
+			cascadedViews, err := p.dropSequenceImpl(ctx, tbDesc, tree.DropCascade)
+			if err != nil {
+				return err
+			}
+			tbNameStrings = append(tbNameStrings, cascadedViews...)
 		} else {
 			cascadedViews, err := p.dropTableImpl(ctx, tbDesc)
 			if err != nil {
@@ -216,12 +235,12 @@ func (n *dropDatabaseNode) Start(params runParams) error {
 		EventLogDropDatabase,
 		int32(n.dbDesc.ID),
 		int32(p.evalCtx.NodeID),
-		struct {
-			DatabaseName          string
-			Statement             string
-			User                  string
-			DroppedTablesAndViews []string
-		}{n.n.Name.String(), n.n.String(), p.session.User, tbNameStrings},
+		&sqlbase.DropDatabaseEvent{
+			DatabaseName:          n.n.Name.String(),
+			Statement:             n.n.String(),
+			User:                  p.session.User,
+			DroppedTablesAndViews: tbNameStrings,
+		},
 	)
 }
 
@@ -257,6 +276,8 @@ func (p *planner) DropIndex(ctx context.Context, n *tree.DropIndex) (planNode, e
 			// don't exist.
 			if n.IfExists {
 				// Skip this index and don't return an error.
+				p.addNotice(NoticeSeverityNotice, "",
+					"index %q does not exist, skipping", string(index.Index))
 				continue
 			}
 			// Index does not exist, but we want it to error out.
@@ -424,15 +445,14 @@ func (p *planner) dropIndexByName(
 		EventLogDropIndex,
 		int32(tableDesc.ID),
 		int32(p.evalCtx.NodeID),
-		struct {
-			TableName           string
-			IndexName           string
-			Statement           string
-			User                string
-			MutationID          uint32
-			CascadeDroppedViews []string
-		}{tableDesc.Name, string(idxName), jobDesc, p.session.User, uint32(mutationID),
-			droppedViews},
+		&sqlbase.DropIndexEvent{
+			TableName:           tableDesc.Name,
+			IndexName:           string(idxName),
+			Statement:           jobDesc,
+			User:                p.session.User,
+			MutationID:          uint32(mutationID),
+			CascadeDroppedViews: droppedViews,
+		},
 	); err != nil {
 		return err
 	}
@@ -471,6 +491,8 @@ func (p *planner) DropView(ctx context.Context, n *tree.DropView) (planNode, err
 		}
 		if droppedDesc == nil {
 			if n.IfExists {
+				p.addNotice(NoticeSeverityNotice, "",
+					"view %q does not exist, skipping", tree.ErrString(tn))
 				continue
 			}
 			// View does not exist, but we want it to: error out.
@@ -533,12 +555,12 @@ func (n *dropViewNode) Start(params runParams) error {
 			EventLogDropView,
 			int32(droppedDesc.ID),
 			int32(params.evalCtx.NodeID),
-			struct {
-				ViewName            string
-				Statement           string
-				User                string
-				CascadeDroppedViews []string
-			}{droppedDesc.Name, n.n.String(), params.p.session.User, cascadeDroppedViews},
+			&sqlbase.DropViewEvent{
+				ViewName:            droppedDesc.Name,
+				Statement:           n.n.String(),
+				User:                params.p.session.User,
+				CascadeDroppedViews: cascadeDroppedViews,
+			},
 		); err != nil {
 			return err
 		}
@@ -572,6 +594,8 @@ func (p *planner) DropSequence(ctx context.Context, n *tree.DropSequence) (planN
 		}
 		if droppedDesc == nil {
 			if n.IfExists {
+				p.addNotice(NoticeSeverityNotice, "",
+					"sequence %q does not exist, skipping", tree.ErrString(tn))
 				continue
 			}
 			// Sequence does not exist, but we want it to: error out.
@@ -584,6 +608,16 @@ func (p *planner) DropSequence(ctx context.Context, n *tree.DropSequence) (planN
 		td = append(td, droppedDesc)
 	}
 
+	// Ensure the sequences aren't used by other tables or views, or that if
+	// they are then `cascade` was specified.
+	for _, droppedDesc := range td {
+		for _, ref := range droppedDesc.DependedOnBy {
+			if err := p.canRemoveSequenceDependent(ctx, droppedDesc, ref, n.DropBehavior); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if len(td) == 0 {
 		return &zeroNode{}, nil
 	}
@@ -600,7 +634,7 @@ func (n *dropSequenceNode) Start(params runParams) error {
 		if droppedDesc == nil {
 			continue
 		}
-		err := params.p.dropSequenceImpl(ctx, droppedDesc, n.n.DropBehavior)
+		cascadeDroppedViews, err := params.p.dropSequenceImpl(ctx, droppedDesc, n.n.DropBehavior)
 		if err != nil {
 			return err
 		}
@@ -613,11 +647,12 @@ func (n *dropSequenceNode) Start(params runParams) error {
 			EventLogDropSequence,
 			int32(droppedDesc.ID),
 			int32(params.evalCtx.NodeID),
-			struct {
-				SequenceName string
-				Statement    string
-				User         string
-			}{droppedDesc.Name, n.n.String(), params.p.session.User},
+			&sqlbase.DropSequenceEvent{
+				SequenceName:        droppedDesc.Name,
+				Statement:           n.n.String(),
+				User:                params.p.session.User,
+				CascadeDroppedViews: cascadeDroppedViews,
+			},
 		); err != nil {
 			return err
 		}
@@ -655,6 +690,8 @@ func (p *planner) DropTable(ctx context.Context, n *tree.DropTable) (planNode, e
 		}
 		if droppedDesc == nil {
 			if n.IfExists {
+				p.addNotice(NoticeSeverityNotice, "",
+					"table %q does not exist, skipping", tree.ErrString(tn))
 				continue
 			}
 			// Table does not exist, but we want it to: error out.
@@ -711,7 +748,8 @@ func (p *planner) canRemoveFK(
 		return nil, err
 	}
 	if behavior != tree.DropCascade {
-		return nil, fmt.Errorf("%q is referenced by foreign key from table %q", from, table.Name)
+		return nil, sqlbase.NewDependentObjectError(
+			fmt.Sprintf("%q is referenced by foreign key from table %q", from, table.Name))
 	}
 	if err := p.CheckPrivilege(table, privilege.CREATE); err != nil {
 		return nil, err
@@ -772,6 +810,44 @@ func (p *planner) canRemoveDependentViewGeneric(
 	return nil
 }
 
+// canRemoveSequenceDependent checks that a relation using a sequence can be
+// modified by dropping the sequence: a view using it is dropped, and the
+// default expressions of the columns of a table using it are removed.
+func (p *planner) canRemoveSequenceDependent(
+	ctx context.Context,
+	seqDesc *sqlbase.TableDescriptor,
+	ref sqlbase.TableDescriptor_Reference,
+	behavior tree.DropBehavior,
+) error {
+	tableDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, ref.ID)
+	if err != nil {
+		return errors.Wrapf(err, "error resolving dependent relation ID %d", ref.ID)
+	}
+	if tableDesc.IsView() {
+		return p.canRemoveDependentView(ctx, seqDesc, ref, behavior)
+	}
+	if behavior != tree.DropCascade {
+		tableName := tableDesc.Name
+		if tableDesc.ParentID != seqDesc.ParentID {
+			tableName, err = p.getQualifiedTableName(ctx, tableDesc)
+			if err != nil {
+				return err
+			}
+		}
+		colName := "?"
+		if len(ref.ColumnIDs) > 0 {
+			if col, err := tableDesc.FindColumnByID(ref.ColumnIDs[0]); err == nil {
+				colName = col.Name
+			}
+		}
+		msg := fmt.Sprintf("cannot drop sequence %q because the default of column %q of table %q depends on it",
+			seqDesc.Name, colName, tableName)
+		hint := fmt.Sprintf("you can drop the default of %s.%s instead.", tableName, colName)
+		return sqlbase.NewDependentObjectErrorWithHint(msg, hint)
+	}
+	return p.CheckPrivilege(tableDesc, privilege.CREATE)
+}
+
 func (p *planner) removeFK(
 	ctx context.Context, ref sqlbase.ForeignKeyReference, table *sqlbase.TableDescriptor,
 ) error {
@@ -790,6 +866,8 @@ func (p *planner) removeFK(
 	if err != nil {
 		return err
 	}
+	p.addNotice(NoticeSeverityNotice, "",
+		"drop cascades to constraint %q on table %q", idx.ForeignKey.Name, table.Name)
 	idx.ForeignKey = sqlbase.ForeignKeyReference{}
 	return p.saveNonmutationAndNotify(ctx, table)
 }
@@ -821,6 +899,7 @@ func (p *planner) removeDependentView(
 	// that refer to the view that's being removed.
 	tableDesc.DependedOnBy = removeMatchingReferences(tableDesc.DependedOnBy, viewDesc.ID)
 	// Then proceed to actually drop the view and log an event for it.
+	p.addNotice(NoticeSeverityNotice, "", "drop cascades to view %q", viewDesc.Name)
 	return p.dropViewImpl(ctx, viewDesc, tree.DropCascade)
 }
 
@@ -843,12 +922,12 @@ func (n *dropTableNode) Start(params runParams) error {
 			EventLogDropTable,
 			int32(droppedDesc.ID),
 			int32(params.evalCtx.NodeID),
-			struct {
-				TableName           string
-				Statement           string
-				User                string
-				CascadeDroppedViews []string
-			}{droppedDesc.Name, n.n.String(), params.p.session.User, droppedViews},
+			&sqlbase.DropTableEvent{
+				TableName:           droppedDesc.Name,
+				Statement:           n.n.String(),
+				User:                params.p.session.User,
+				CascadeDroppedViews: droppedViews,
+			},
 		); err != nil {
 			return err
 		}
@@ -922,6 +1001,28 @@ func (p *planner) dropTableImpl(
 		}
 	}
 
+	// Remove the back-references from the sequences used by the columns.
+	affectedSequences := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
+	for i := range tableDesc.Columns {
+		if err := p.removeSequenceDependencies(
+			ctx, tableDesc, &tableDesc.Columns[i], affectedSequences,
+		); err != nil {
+			return droppedViews, err
+		}
+	}
+	for _, m := range tableDesc.Mutations {
+		if col := m.GetColumn(); col != nil {
+			if err := p.removeSequenceDependencies(ctx, tableDesc, col, affectedSequences); err != nil {
+				return droppedViews, err
+			}
+		}
+	}
+	for _, seqDesc := range affectedSequences {
+		if err := p.saveNonmutationAndNotify(ctx, seqDesc); err != nil {
+			return droppedViews, err
+		}
+	}
+
 	// Drop all views that depend on this table, assuming that we wouldn't have
 	// made it to this point if `cascade` wasn't enabled.
 	for _, ref := range tableDesc.DependedOnBy {
@@ -935,6 +1036,7 @@ func (p *planner) dropTableImpl(
 		if viewDesc.Dropped() {
 			continue
 		}
+		p.addNotice(NoticeSeverityNotice, "", "drop cascades to view %q", viewDesc.Name)
 		cascadedViews, err := p.dropViewImpl(ctx, viewDesc, tree.DropCascade)
 		if err != nil {
 			return droppedViews, err
@@ -1082,6 +1184,11 @@ func (p *planner) dropViewImpl(
 			if err != nil {
 				return cascadeDroppedViews, err
 			}
+			// This view is already getting dropped. Don't do it twice.
+			if dependentDesc.Dropped() {
+				continue
+			}
+			p.addNotice(NoticeSeverityNotice, "", "drop cascades to view %q", dependentDesc.Name)
 			cascadedViews, err := p.dropViewImpl(ctx, dependentDesc, behavior)
 			if err != nil {
 				return cascadeDroppedViews, err
@@ -1101,19 +1208,84 @@ func (p *planner) dropViewImpl(
 	return cascadeDroppedViews, nil
 }
 
+// dropSequenceImpl does the work of dropping a sequence. With `cascade`, the
+// views using the sequence are dropped and the default expressions of the
+// columns using it are removed. Returns the names of the views that were
+// dropped due to `cascade` behavior.
 func (p *planner) dropSequenceImpl(
 	ctx context.Context, seqDesc *sqlbase.TableDescriptor, behavior tree.DropBehavior,
-) error {
-	err := p.initiateDropTable(ctx, seqDesc)
-	if err != nil {
-		return err
+) ([]string, error) {
+	var cascadeDroppedViews []string
+
+	if behavior == tree.DropCascade {
+		for _, ref := range seqDesc.DependedOnBy {
+			dependentDesc, err := sqlbase.GetTableDescFromID(ctx, p.txn, ref.ID)
+			if err != nil {
+				return cascadeDroppedViews,
+					errors.Errorf("error resolving dependent relation ID %d: %v", ref.ID, err)
+			}
+			// The dependent relation is also being dropped.
+			if dependentDesc.Dropped() {
+				continue
+			}
+			if dependentDesc.IsView() {
+				p.addNotice(NoticeSeverityNotice, "", "drop cascades to view %q", dependentDesc.Name)
+				cascadedViews, err := p.dropViewImpl(ctx, dependentDesc, behavior)
+				if err != nil {
+					return cascadeDroppedViews, err
+				}
+				cascadeDroppedViews = append(cascadeDroppedViews, cascadedViews...)
+				cascadeDroppedViews = append(cascadeDroppedViews, dependentDesc.Name)
+				continue
+			}
+			if err := p.removeSequenceDefaults(ctx, seqDesc, dependentDesc, ref.ColumnIDs); err != nil {
+				return cascadeDroppedViews, err
+			}
+		}
+	}
+	seqDesc.DependedOnBy = nil
+
+	if err := p.initiateDropTable(ctx, seqDesc); err != nil {
+		return cascadeDroppedViews, err
 	}
 
 	p.session.setTestingVerifyMetadata(func(systemConfig config.SystemConfig) error {
 		return verifyDropTableMetadata(systemConfig, seqDesc.ID, "sequence")
 	})
 
-	return nil
+	return cascadeDroppedViews, nil
+}
+
+// removeSequenceDefaults removes the default expressions of the columns of a
+// table using a sequence being dropped.
+func (p *planner) removeSequenceDefaults(
+	ctx context.Context,
+	seqDesc, tableDesc *sqlbase.TableDescriptor,
+	colIDs []sqlbase.ColumnID,
+) error {
+	// The default expressions may use other sequences, whose back-references
+	// are removed as well. The dropped sequence is left to the caller.
+	affectedSequences := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
+	for _, colID := range colIDs {
+		col, err := tableDesc.FindColumnByID(colID)
+		if err != nil || !containsDescID(col.UsesSequenceIDs, seqDesc.ID) {
+			// The column was dropped, or its default was already removed.
+			continue
+		}
+		if err := p.removeSequenceDependencies(ctx, tableDesc, col, affectedSequences); err != nil {
+			return err
+		}
+		col.DefaultExpr = nil
+		p.addNotice(NoticeSeverityNotice, "",
+			"drop cascades to default value for column %q of table %q", col.Name, tableDesc.Name)
+	}
+	delete(affectedSequences, seqDesc.ID)
+	for _, otherSeqDesc := range affectedSequences {
+		if err := p.saveNonmutationAndNotify(ctx, otherSeqDesc); err != nil {
+			return err
+		}
+	}
+	return p.saveNonmutationAndNotify(ctx, tableDesc)
 }
 
 // removeMatchingReferences removes all refs from the provided slice that
@@ -1162,8 +1334,11 @@ func (p *planner) getViewDescForCascade(
 }
 
 type dropUserNode struct {
+	n        *tree.DropUser
 	ifExists bool
-	names    func() ([]string, error)
+	// isRole is set for DROP ROLE.
+	isRole bool
+	names  func() ([]string, error)
 	// The number of users deleted.
 	numDeleted int
 }
@@ -1194,6 +1369,24 @@ func (n *dropUserNode) Start(params runParams) error {
 					tree.Name(db.Name).Format(&usedBy, tree.FmtSimple)
 				}
 			}
+			used := false
+			for _, rule := range db.DefaultPrivileges {
+				if _, ok := userNames[rule.Role]; ok {
+					used = true
+				}
+				for _, u := range rule.Tables.Users {
+					if _, ok := userNames[u.User]; ok {
+						used = true
+					}
+				}
+			}
+			if used {
+				if usedBy.Len() > 0 {
+					usedBy.WriteString(", ")
+				}
+				usedBy.WriteString("the default privileges in ")
+				tree.Name(db.Name).Format(&usedBy, tree.FmtSimple)
+			}
 			return nil
 		}); err != nil {
 		return err
@@ -1212,6 +1405,25 @@ func (n *dropUserNode) Start(params runParams) error {
 					tn.Format(&usedBy, tree.FmtSimple)
 				}
 			}
+			for _, col := range table.Columns {
+				if col.Privileges == nil {
+					continue
+				}
+				for _, u := range col.Privileges.Users {
+					if _, ok := userNames[u.User]; ok {
+						if usedBy.Len() > 0 {
+							usedBy.WriteString(", ")
+						}
+						tn := tree.TableName{
+							DatabaseName: tree.Name(db.Name),
+							TableName:    tree.Name(table.Name),
+						}
+						tn.Format(&usedBy, tree.FmtSimple)
+						usedBy.WriteByte('.')
+						tree.Name(col.Name).Format(&usedBy, tree.FmtSimple)
+					}
+				}
+			}
 			return nil
 		}); err != nil {
 		return err
@@ -1225,11 +1437,54 @@ func (n *dropUserNode) Start(params runParams) error {
 			tree.Name(name).Format(&nameList, tree.FmtSimple)
 		}
 		return pgerror.NewErrorf(pgerror.CodeGroupingError,
-			"cannot drop user%s %s: grants still exist on %s",
-			util.Pluralize(int64(nameList.Len())), nameList.String(), usedBy.String(),
+			"cannot drop %s%s %s: grants still exist on %s",
+			n.typeName(), util.Pluralize(int64(len(names))), nameList.String(), usedBy.String(),
 		)
 	}
 
+	var owned bytes.Buffer
+	if err := forEachDatabaseDesc(params.ctx, params.p,
+		func(db *sqlbase.DatabaseDescriptor) error {
+			if _, ok := userNames[db.GetPrivileges().Owner]; ok {
+				if owned.Len() > 0 {
+					owned.WriteString(", ")
+				}
+				tree.Name(db.Name).Format(&owned, tree.FmtSimple)
+			}
+			return nil
+		}); err != nil {
+		return err
+	}
+	if err := forEachTableDescAll(params.ctx, params.p, "",
+		func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+			if _, ok := userNames[table.GetPrivileges().Owner]; ok {
+				tn := tree.TableName{
+					DatabaseName: tree.Name(db.Name),
+					TableName:    tree.Name(table.Name),
+				}
+				if owned.Len() > 0 {
+					owned.WriteString(", ")
+				}
+				tn.Format(&owned, tree.FmtSimple)
+			}
+			return nil
+		}); err != nil {
+		return err
+	}
+	if owned.Len() > 0 {
+		var nameList bytes.Buffer
+		for i, name := range names {
+			if i > 0 {
+				nameList.WriteString(", ")
+			}
+			tree.Name(name).Format(&nameList, tree.FmtSimple)
+		}
+		return pgerror.NewErrorf(pgerror.CodeDependentObjectsStillExistError,
+			"cannot drop %s%s %s: objects still owned: %s",
+			n.typeName(), util.Pluralize(int64(len(names))), nameList.String(), owned.String(),
+		).SetHintf("use REASSIGN OWNED to transfer their ownership")
+	}
+
 	numDeleted := 0
 	for normalizedUsername := range userNames {
 		// Note: protected users like security.RootUser are not included in system.users,
@@ -1251,7 +1506,43 @@ func (n *dropUserNode) Start(params runParams) error {
 		}
 
 		if rowsAffected == 0 && !n.ifExists {
-			return errors.Errorf("user %s does not exist", normalizedUsername)
+			return errors.Errorf("%s %s does not exist", n.typeName(), normalizedUsername)
+		}
+
+		if _, err := internalExecutor.ExecuteStatementInTransaction(
+			params.ctx,
+			"drop-user",
+			params.p.txn,
+			"DELETE FROM system.role_options WHERE username=$1",
+			normalizedUsername,
+		); err != nil {
+			return err
+		}
+
+		// The memberships of the user and the members of the role are dropped
+		// with it.
+		if _, err := internalExecutor.ExecuteStatementInTransaction(
+			params.ctx,
+			"drop-user",
+			params.p.txn,
+			"DELETE FROM system.role_members WHERE role=$1 OR member=$1",
+			normalizedUsername,
+		); err != nil {
+			return err
+		}
+
+		if rowsAffected > 0 {
+			eventType := EventLogDropUser
+			if n.isRole {
+				eventType = EventLogDropRole
+			}
+			if err := logRoleEvent(params, eventType, &sqlbase.DropRoleEvent{
+				RoleName:  normalizedUsername,
+				Statement: n.n.String(),
+				User:      params.p.session.User,
+			}); err != nil {
+				return err
+			}
 		}
 
 		numDeleted += rowsAffected
@@ -1262,12 +1553,19 @@ func (n *dropUserNode) Start(params runParams) error {
 	return nil
 }
 
+func (n *dropUserNode) typeName() string {
+	if n.isRole {
+		return "role"
+	}
+	return "user"
+}
+
 func (*dropUserNode) Next(runParams) (bool, error)   { return false, nil }
 func (*dropUserNode) Close(context.Context)          {}
 func (*dropUserNode) Values() tree.Datums            { return tree.Datums{} }
 func (n *dropUserNode) FastPathResults() (int, bool) { return n.numDeleted, true }
 
-// DropUser drops a list of users.
+// DropUser drops a list of users or roles.
 // Privileges: DELETE on system.users.
 func (p *planner) DropUser(ctx context.Context, n *tree.DropUser) (planNode, error) {
 	tDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), &tree.TableName{DatabaseName: "system", TableName: "users"})
@@ -1279,13 +1577,15 @@ func (p *planner) DropUser(ctx context.Context, n *tree.DropUser) (planNode, err
 		return nil, err
 	}
 
-	names, err := p.TypeAsStringArray(n.Names, "DROP USER")
+	names, err := p.TypeAsStringArray(n.Names, n.StatementTag())
 	if err != nil {
 		return nil, err
 	}
 
 	return &dropUserNode{
+		n:        n,
 		ifExists: n.IfExists,
+		isRole:   n.IsRole,
 		names:    names,
 	}, nil
 }
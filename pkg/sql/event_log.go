@@ -22,6 +22,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
 
@@ -35,6 +36,11 @@ const (
 	EventLogCreateDatabase EventLogType = "create_database"
 	// EventLogDropDatabase is recorded when a database is dropped.
 	EventLogDropDatabase EventLogType = "drop_database"
+	// EventLogRenameDatabase is recorded when a database is renamed.
+	EventLogRenameDatabase EventLogType = "rename_database"
+	// EventLogAlterDatabase is recorded when the regions of a database are
+	// changed.
+	EventLogAlterDatabase EventLogType = "alter_database"
 
 	// EventLogCreateTable is recorded when a table is created.
 	EventLogCreateTable EventLogType = "create_table"
@@ -42,6 +48,11 @@ const (
 	EventLogDropTable EventLogType = "drop_table"
 	// EventLogAlterTable is recorded when a table is altered.
 	EventLogAlterTable EventLogType = "alter_table"
+	// EventLogRenameTable is recorded when a table, view or sequence is
+	// renamed.
+	EventLogRenameTable EventLogType = "rename_table"
+	// EventLogTruncateTable is recorded when a table is truncated.
+	EventLogTruncateTable EventLogType = "truncate_table"
 
 	// EventLogCreateIndex is recorded when an index is created.
 	EventLogCreateIndex EventLogType = "create_index"
@@ -82,10 +93,100 @@ const (
 	// recommissioned.
 	EventLogNodeRecommissioned EventLogType = "node_recommissioned"
 
+	// EventLogSetZoneConfig is recorded when a zone config is changed or
+	// removed.
+	EventLogSetZoneConfig EventLogType = "set_zone_config"
+
 	// EventLogSetClusterSetting is recorded when a cluster setting is changed.
 	EventLogSetClusterSetting EventLogType = "set_cluster_setting"
+
+	// EventLogGrantPrivileges is recorded when privileges are granted on a
+	// database, table, view or sequence.
+	EventLogGrantPrivileges EventLogType = "grant_privileges"
+	// EventLogRevokePrivileges is recorded when privileges are revoked on a
+	// database, table, view or sequence.
+	EventLogRevokePrivileges EventLogType = "revoke_privileges"
+	// EventLogAlterDefaultPrivileges is recorded when the default privileges
+	// of a database are altered.
+	EventLogAlterDefaultPrivileges EventLogType = "alter_default_privileges"
+	// EventLogAlterOwner is recorded when the owner of a database, table, view
+	// or sequence changes.
+	EventLogAlterOwner EventLogType = "alter_owner"
+	// EventLogCreatePolicy is recorded when a row-level security policy is
+	// created.
+	EventLogCreatePolicy EventLogType = "create_policy"
+	// EventLogDropPolicy is recorded when a row-level security policy is
+	// dropped.
+	EventLogDropPolicy EventLogType = "drop_policy"
+
+	// EventLogCreateUser is recorded when a user is created.
+	EventLogCreateUser EventLogType = "create_user"
+	// EventLogCreateRole is recorded when a role is created.
+	EventLogCreateRole EventLogType = "create_role"
+	// EventLogDropUser is recorded when a user is dropped.
+	EventLogDropUser EventLogType = "drop_user"
+	// EventLogDropRole is recorded when a role is dropped.
+	EventLogDropRole EventLogType = "drop_role"
+	// EventLogAlterUser is recorded when the password, the options or the
+	// quotas of a user or role are changed.
+	EventLogAlterUser EventLogType = "alter_user"
+	// EventLogGrantRole is recorded when members are added to roles, or are
+	// given the admin option on them.
+	EventLogGrantRole EventLogType = "grant_role"
+	// EventLogRevokeRole is recorded when members are removed from roles, or
+	// lose the admin option on them.
+	EventLogRevokeRole EventLogType = "revoke_role"
 )
 
+// eventLogInfoTypes holds the types of the payloads of the events recorded
+// by the schema changes, the changes of privileges and roles, and the cluster
+// setting changes, which crdb_internal.eventlog decodes. The node events
+// aren't listed: their payloads are recorded as is.
+var eventLogInfoTypes = map[EventLogType]func() sqlbase.EventLogInfo{
+	EventLogCreateDatabase: func() sqlbase.EventLogInfo { return &sqlbase.CreateDatabaseEvent{} },
+	EventLogDropDatabase:   func() sqlbase.EventLogInfo { return &sqlbase.DropDatabaseEvent{} },
+	EventLogRenameDatabase: func() sqlbase.EventLogInfo { return &sqlbase.RenameDatabaseEvent{} },
+	EventLogAlterDatabase:  func() sqlbase.EventLogInfo { return &sqlbase.AlterDatabaseEvent{} },
+
+	EventLogCreateTable:   func() sqlbase.EventLogInfo { return &sqlbase.CreateTableEvent{} },
+	EventLogDropTable:     func() sqlbase.EventLogInfo { return &sqlbase.DropTableEvent{} },
+	EventLogAlterTable:    func() sqlbase.EventLogInfo { return &sqlbase.AlterTableEvent{} },
+	EventLogRenameTable:   func() sqlbase.EventLogInfo { return &sqlbase.RenameTableEvent{} },
+	EventLogTruncateTable: func() sqlbase.EventLogInfo { return &sqlbase.TruncateTableEvent{} },
+
+	EventLogCreateIndex: func() sqlbase.EventLogInfo { return &sqlbase.CreateIndexEvent{} },
+	EventLogDropIndex:   func() sqlbase.EventLogInfo { return &sqlbase.DropIndexEvent{} },
+
+	EventLogCreateView: func() sqlbase.EventLogInfo { return &sqlbase.CreateViewEvent{} },
+	EventLogDropView:   func() sqlbase.EventLogInfo { return &sqlbase.DropViewEvent{} },
+
+	EventLogCreateSequence: func() sqlbase.EventLogInfo { return &sqlbase.CreateSequenceEvent{} },
+	EventLogDropSequence:   func() sqlbase.EventLogInfo { return &sqlbase.DropSequenceEvent{} },
+	EventLogAlterSequence:  func() sqlbase.EventLogInfo { return &sqlbase.AlterSequenceEvent{} },
+
+	EventLogReverseSchemaChange:  func() sqlbase.EventLogInfo { return &sqlbase.ReverseSchemaChangeEvent{} },
+	EventLogFinishSchemaChange:   func() sqlbase.EventLogInfo { return &sqlbase.FinishSchemaChangeEvent{} },
+	EventLogFinishSchemaRollback: func() sqlbase.EventLogInfo { return &sqlbase.FinishSchemaChangeEvent{} },
+
+	EventLogSetZoneConfig:     func() sqlbase.EventLogInfo { return &sqlbase.SetZoneConfigEvent{} },
+	EventLogSetClusterSetting: func() sqlbase.EventLogInfo { return &sqlbase.SetClusterSettingEvent{} },
+
+	EventLogGrantPrivileges:        func() sqlbase.EventLogInfo { return &sqlbase.ChangePrivilegesEvent{} },
+	EventLogRevokePrivileges:       func() sqlbase.EventLogInfo { return &sqlbase.ChangePrivilegesEvent{} },
+	EventLogAlterDefaultPrivileges: func() sqlbase.EventLogInfo { return &sqlbase.ChangePrivilegesEvent{} },
+	EventLogAlterOwner:             func() sqlbase.EventLogInfo { return &sqlbase.AlterOwnerEvent{} },
+	EventLogCreatePolicy:           func() sqlbase.EventLogInfo { return &sqlbase.CreatePolicyEvent{} },
+	EventLogDropPolicy:             func() sqlbase.EventLogInfo { return &sqlbase.DropPolicyEvent{} },
+
+	EventLogCreateUser: func() sqlbase.EventLogInfo { return &sqlbase.CreateRoleEvent{} },
+	EventLogCreateRole: func() sqlbase.EventLogInfo { return &sqlbase.CreateRoleEvent{} },
+	EventLogDropUser:   func() sqlbase.EventLogInfo { return &sqlbase.DropRoleEvent{} },
+	EventLogDropRole:   func() sqlbase.EventLogInfo { return &sqlbase.DropRoleEvent{} },
+	EventLogAlterUser:  func() sqlbase.EventLogInfo { return &sqlbase.AlterRoleEvent{} },
+	EventLogGrantRole:  func() sqlbase.EventLogInfo { return &sqlbase.ChangeRoleMembershipEvent{} },
+	EventLogRevokeRole: func() sqlbase.EventLogInfo { return &sqlbase.ChangeRoleMembershipEvent{} },
+}
+
 // An EventLogger exposes methods used to record events to the event table.
 type EventLogger struct {
 	InternalExecutor
@@ -100,7 +201,8 @@ func MakeEventLogger(leaseMgr *LeaseManager) EventLogger {
 }
 
 // InsertEventRecord inserts a single event into the event log as part of the
-// provided transaction.
+// provided transaction. The info of the events listed in eventLogInfoTypes
+// must be of the type listed there.
 func (ev EventLogger) InsertEventRecord(
 	ctx context.Context,
 	txn *client.Txn,
@@ -0,0 +1,239 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// AggFunc is an aggregate function supported by the columnar aggregator.
+type AggFunc int
+
+const (
+	// AggIdent returns the grouping column of a group. It is only valid on the
+	// grouping column.
+	AggIdent AggFunc = iota
+	// AggSumInt returns the sum of an Int64 column, or NULL if all the values
+	// in the group are NULL.
+	AggSumInt
+	// AggCountRows returns the number of tuples in a group.
+	AggCountRows
+)
+
+// AggSpec describes a single aggregation computed by the columnar aggregator.
+type AggSpec struct {
+	Func AggFunc
+	// ColIdx is the input column the aggregation is computed on. It is ignored
+	// for AggCountRows.
+	ColIdx int
+}
+
+// hashAggregatorInt64 groups its input on a single Int64 column (or, if there
+// is no grouping column, computes scalar aggregations over the whole input).
+// All the output columns are of type Int64. Groups are output in the order in
+// which they were first seen in the input.
+type hashAggregatorInt64 struct {
+	input Operator
+
+	// groupCol is the index of the grouping column, or -1 for scalar
+	// aggregations.
+	groupCol int
+	aggs     []AggSpec
+
+	// done is set once the entire input has been aggregated.
+	done bool
+
+	groups    map[int64]int
+	nullGroup int
+	// keys and keyNulls hold the grouping value of each group.
+	keys     []int64
+	keyNulls []bool
+	// counts holds the number of tuples seen by each group; sums and sumSet
+	// hold the running sum and whether any non-NULL value was seen, per
+	// aggregation and group.
+	counts []int64
+	sums   [][]int64
+	sumSet [][]bool
+
+	// groupIdxs is scratch space holding the group of each tuple in a batch.
+	groupIdxs []int
+
+	output ColBatch
+	// outIdx is the next group to output.
+	outIdx int
+}
+
+var _ Operator = &hashAggregatorInt64{}
+
+// NewHashAggregatorInt64 returns an Operator that computes the given
+// aggregations over its input grouped on the Int64 column groupCol. If
+// groupCol is -1, the aggregations are scalar and exactly one tuple is
+// produced, even if the input is empty.
+func NewHashAggregatorInt64(input Operator, groupCol int, aggs []AggSpec) (Operator, error) {
+	for _, a := range aggs {
+		switch a.Func {
+		case AggIdent:
+			if groupCol < 0 || a.ColIdx != groupCol {
+				return nil, errors.Errorf("ident aggregation only supported on the grouping column")
+			}
+		case AggSumInt, AggCountRows:
+		default:
+			return nil, errors.Errorf("unsupported aggregation %d", a.Func)
+		}
+	}
+	return &hashAggregatorInt64{
+		input:     input,
+		groupCol:  groupCol,
+		aggs:      aggs,
+		nullGroup: -1,
+	}, nil
+}
+
+func (a *hashAggregatorInt64) Init() {
+	a.input.Init()
+
+	a.groups = make(map[int64]int)
+	a.sums = make([][]int64, len(a.aggs))
+	a.sumSet = make([][]bool, len(a.aggs))
+	a.groupIdxs = make([]int, ColBatchSize)
+	outTypes := make([]T, len(a.aggs))
+	for i := range outTypes {
+		outTypes[i] = Int64
+	}
+	a.output = NewMemBatch(outTypes)
+	if a.groupCol < 0 {
+		a.newGroup(0, false)
+	}
+}
+
+// newGroup allocates the state for a new group and returns its index.
+func (a *hashAggregatorInt64) newGroup(key int64, null bool) int {
+	g := len(a.keys)
+	a.keys = append(a.keys, key)
+	a.keyNulls = append(a.keyNulls, null)
+	a.counts = append(a.counts, 0)
+	for i := range a.aggs {
+		a.sums[i] = append(a.sums[i], 0)
+		a.sumSet[i] = append(a.sumSet[i], false)
+	}
+	return g
+}
+
+func (a *hashAggregatorInt64) aggregate() {
+	for {
+		batch := a.input.Next()
+		n := batch.Length()
+		if n == 0 {
+			break
+		}
+		sel := batch.Selection()
+
+		// Compute the group of each tuple.
+		groupIdxs := a.groupIdxs[:n]
+		if a.groupCol < 0 {
+			for k := range groupIdxs {
+				groupIdxs[k] = 0
+			}
+		} else {
+			vec := batch.ColVec(a.groupCol)
+			keys := vec.Int64()
+			for k := uint16(0); k < n; k++ {
+				i := k
+				if sel != nil {
+					i = sel[k]
+				}
+				if vec.NullAt(i) {
+					if a.nullGroup < 0 {
+						a.nullGroup = a.newGroup(0, true)
+					}
+					groupIdxs[k] = a.nullGroup
+					continue
+				}
+				g, ok := a.groups[keys[i]]
+				if !ok {
+					g = a.newGroup(keys[i], false)
+					a.groups[keys[i]] = g
+				}
+				groupIdxs[k] = g
+			}
+		}
+
+		for _, g := range groupIdxs {
+			a.counts[g]++
+		}
+		for j, agg := range a.aggs {
+			if agg.Func != AggSumInt {
+				continue
+			}
+			vec := batch.ColVec(agg.ColIdx)
+			col := vec.Int64()
+			sums, sumSet := a.sums[j], a.sumSet[j]
+			for k, g := range groupIdxs {
+				i := uint16(k)
+				if sel != nil {
+					i = sel[k]
+				}
+				if vec.NullAt(i) {
+					continue
+				}
+				r, ok := tree.AddWithOverflow(sums[g], col[i])
+				if !ok {
+					RaiseError(errIntOutOfRange)
+				}
+				sums[g] = r
+				sumSet[g] = true
+			}
+		}
+	}
+	a.done = true
+}
+
+func (a *hashAggregatorInt64) Next() ColBatch {
+	if !a.done {
+		a.aggregate()
+	}
+
+	n := len(a.keys) - a.outIdx
+	if n > ColBatchSize {
+		n = ColBatchSize
+	}
+	for j, agg := range a.aggs {
+		outVec := a.output.ColVec(j)
+		outVec.UnsetNulls()
+		out := outVec.Int64()
+		for k := 0; k < n; k++ {
+			g := a.outIdx + k
+			switch agg.Func {
+			case AggIdent:
+				if a.keyNulls[g] {
+					outVec.SetNull(uint16(k))
+				}
+				out[k] = a.keys[g]
+			case AggSumInt:
+				if !a.sumSet[j][g] {
+					outVec.SetNull(uint16(k))
+				}
+				out[k] = a.sums[j][g]
+			case AggCountRows:
+				out[k] = a.counts[g]
+			}
+		}
+	}
+	a.outIdx += n
+	a.output.SetLength(uint16(n))
+	return a.output
+}
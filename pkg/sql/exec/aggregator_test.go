@@ -0,0 +1,73 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestHashAggregatorInt64(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	typs := []T{Int64, Int64}
+	testCases := []struct {
+		name     string
+		groupCol int
+		aggs     []AggSpec
+		input    tuples
+		expected tuples
+	}{
+		{
+			name:     "grouped",
+			groupCol: 0,
+			aggs:     []AggSpec{{Func: AggIdent, ColIdx: 0}, {Func: AggSumInt, ColIdx: 1}, {Func: AggCountRows}},
+			input:    tuples{{1, 2}, {2, 3}, {1, nil}, {nil, 4}, {2, 5}, {nil, 6}, {3, nil}},
+			expected: tuples{{1, 2, 2}, {2, 8, 2}, {nil, 10, 2}, {3, nil, 1}},
+		},
+		{
+			name:     "scalar",
+			groupCol: -1,
+			aggs:     []AggSpec{{Func: AggSumInt, ColIdx: 1}, {Func: AggCountRows}},
+			input:    tuples{{1, 2}, {2, nil}, {3, 4}},
+			expected: tuples{{6, 3}},
+		},
+		{
+			name:     "scalar-empty",
+			groupCol: -1,
+			aggs:     []AggSpec{{Func: AggSumInt, ColIdx: 1}, {Func: AggCountRows}},
+			expected: tuples{{nil, 0}},
+		},
+		{
+			name:     "grouped-empty",
+			groupCol: 0,
+			aggs:     []AggSpec{{Func: AggCountRows}},
+		},
+	}
+	for _, tc := range testCases {
+		for _, batchSize := range batchSizes {
+			t.Run(fmt.Sprintf("%s/batch=%d", tc.name, batchSize), func(t *testing.T) {
+				op, err := NewHashAggregatorInt64(newOpTestInput(batchSize, typs, tc.input), tc.groupCol, tc.aggs)
+				if err != nil {
+					t.Fatal(err)
+				}
+				op.Init()
+				assertTuplesEqual(t, tc.expected, collectTuples(op), true /* ordered */)
+			})
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import "fmt"
+
+// colStore is an append-only, column-oriented store of tuples that outlives
+// the batches it is populated from. It is used by operators that need to
+// buffer their entire input, such as the build side of a hash join.
+type colStore struct {
+	typs []T
+	// cols contains one growing typed slice per column ([]int64, []float64,
+	// []bool or [][]byte).
+	cols []interface{}
+	// nulls contains one slice per column, denoting which tuples are NULL in
+	// that column.
+	nulls [][]bool
+	// n is the number of tuples in the store.
+	n int
+}
+
+func makeColStore(typs []T) colStore {
+	s := colStore{
+		typs:  typs,
+		cols:  make([]interface{}, len(typs)),
+		nulls: make([][]bool, len(typs)),
+	}
+	for i, t := range typs {
+		switch t {
+		case Bool:
+			s.cols[i] = []bool(nil)
+		case Bytes:
+			s.cols[i] = [][]byte(nil)
+		case Int64:
+			s.cols[i] = []int64(nil)
+		case Float64:
+			s.cols[i] = []float64(nil)
+		default:
+			panic(fmt.Sprintf("unhandled type %s", t))
+		}
+	}
+	return s
+}
+
+// appendBatch copies the selected tuples of the batch into the store.
+func (s *colStore) appendBatch(batch ColBatch) {
+	n := batch.Length()
+	sel := batch.Selection()
+	for c, t := range s.typs {
+		vec := batch.ColVec(c)
+		nulls := s.nulls[c]
+		switch t {
+		case Bool:
+			col, src := s.cols[c].([]bool), vec.Bool()
+			for k := uint16(0); k < n; k++ {
+				i := k
+				if sel != nil {
+					i = sel[k]
+				}
+				col = append(col, src[i])
+				nulls = append(nulls, vec.NullAt(i))
+			}
+			s.cols[c] = col
+		case Bytes:
+			col, src := s.cols[c].([][]byte), vec.Bytes()
+			for k := uint16(0); k < n; k++ {
+				i := k
+				if sel != nil {
+					i = sel[k]
+				}
+				// The source batch may reuse its byte slices; make a copy.
+				col = append(col, append([]byte(nil), src[i]...))
+				nulls = append(nulls, vec.NullAt(i))
+			}
+			s.cols[c] = col
+		case Int64:
+			col, src := s.cols[c].([]int64), vec.Int64()
+			for k := uint16(0); k < n; k++ {
+				i := k
+				if sel != nil {
+					i = sel[k]
+				}
+				col = append(col, src[i])
+				nulls = append(nulls, vec.NullAt(i))
+			}
+			s.cols[c] = col
+		case Float64:
+			col, src := s.cols[c].([]float64), vec.Float64()
+			for k := uint16(0); k < n; k++ {
+				i := k
+				if sel != nil {
+					i = sel[k]
+				}
+				col = append(col, src[i])
+				nulls = append(nulls, vec.NullAt(i))
+			}
+			s.cols[c] = col
+		}
+		s.nulls[c] = nulls
+	}
+	s.n += int(n)
+}
+
+// copyTo copies the tuples at the given indexes of the store into the first
+// len(idxs) positions of the output vectors, starting at output column
+// colOffset.
+func (s *colStore) copyTo(out ColBatch, colOffset int, idxs []uint32) {
+	for c, t := range s.typs {
+		outVec := out.ColVec(colOffset + c)
+		nulls := s.nulls[c]
+		switch t {
+		case Bool:
+			src, dst := s.cols[c].([]bool), outVec.Bool()
+			for k, i := range idxs {
+				dst[k] = src[i]
+			}
+		case Bytes:
+			src, dst := s.cols[c].([][]byte), outVec.Bytes()
+			for k, i := range idxs {
+				dst[k] = src[i]
+			}
+		case Int64:
+			src, dst := s.cols[c].([]int64), outVec.Int64()
+			for k, i := range idxs {
+				dst[k] = src[i]
+			}
+		case Float64:
+			src, dst := s.cols[c].([]float64), outVec.Float64()
+			for k, i := range idxs {
+				dst[k] = src[i]
+			}
+		}
+		for k, i := range idxs {
+			if nulls[i] {
+				outVec.SetNull(uint16(k))
+			}
+		}
+	}
+}
+
+// copyVec copies the values at the given indexes of src into the first
+// len(idxs) positions of dst. Both vectors must be of type t.
+func copyVec(t T, src ColVec, dst ColVec, idxs []uint16) {
+	switch t {
+	case Bool:
+		s, d := src.Bool(), dst.Bool()
+		for k, i := range idxs {
+			d[k] = s[i]
+		}
+	case Bytes:
+		s, d := src.Bytes(), dst.Bytes()
+		for k, i := range idxs {
+			d[k] = s[i]
+		}
+	case Int64:
+		s, d := src.Int64(), dst.Int64()
+		for k, i := range idxs {
+			d[k] = s[i]
+		}
+	case Float64:
+		s, d := src.Float64(), dst.Float64()
+		for k, i := range idxs {
+			d[k] = s[i]
+		}
+	default:
+		panic(fmt.Sprintf("unhandled type %s", t))
+	}
+	if src.HasNulls() {
+		for k, i := range idxs {
+			if src.NullAt(i) {
+				dst.SetNull(uint16(k))
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+// hashJoinEqInnerInt64Op performs an inner equality hash join on a single
+// Int64 column of each input. The entire right input is buffered into a hash
+// table (the "build" phase), after which the left input is streamed through
+// it (the "probe" phase). The output schema is the left columns followed by
+// the right columns, and the output preserves the order of the left input.
+type hashJoinEqInnerInt64Op struct {
+	left  Operator
+	right Operator
+
+	leftTypes  []T
+	rightTypes []T
+	leftEqCol  int
+	rightEqCol int
+
+	// built is set once the build phase is complete.
+	built bool
+	// ht maps a key of the right input to the indexes of the tuples in
+	// buildStore that have that key.
+	ht         map[int64][]uint32
+	buildStore colStore
+
+	output ColBatch
+
+	// probeBatch is the left batch that is currently being probed. probeIdx
+	// is the position within that batch of the next tuple to probe, and
+	// matchIdx is the position within the current tuple's matches of the next
+	// match to emit.
+	probeBatch ColBatch
+	probeIdx   uint16
+	matchIdx   int
+
+	// Scratch space for the indexes of the output tuples in the probe batch
+	// and in the build store, respectively.
+	leftIdxs  []uint16
+	rightIdxs []uint32
+}
+
+var _ Operator = &hashJoinEqInnerInt64Op{}
+
+// NewHashJoinEqInnerInt64Op returns a new inner hash join Operator joining
+// left and right on the condition `left.@leftEqCol = right.@rightEqCol`. Both
+// equality columns must be of type Int64.
+func NewHashJoinEqInnerInt64Op(
+	left Operator, right Operator, leftTypes []T, rightTypes []T, leftEqCol int, rightEqCol int,
+) Operator {
+	return &hashJoinEqInnerInt64Op{
+		left:       left,
+		right:      right,
+		leftTypes:  leftTypes,
+		rightTypes: rightTypes,
+		leftEqCol:  leftEqCol,
+		rightEqCol: rightEqCol,
+	}
+}
+
+func (h *hashJoinEqInnerInt64Op) Init() {
+	h.left.Init()
+	h.right.Init()
+
+	h.ht = make(map[int64][]uint32)
+	h.buildStore = makeColStore(h.rightTypes)
+	outTypes := make([]T, 0, len(h.leftTypes)+len(h.rightTypes))
+	outTypes = append(outTypes, h.leftTypes...)
+	outTypes = append(outTypes, h.rightTypes...)
+	h.output = NewMemBatch(outTypes)
+	h.leftIdxs = make([]uint16, ColBatchSize)
+	h.rightIdxs = make([]uint32, ColBatchSize)
+}
+
+// build consumes the entire right input and populates the hash table.
+func (h *hashJoinEqInnerInt64Op) build() {
+	for {
+		batch := h.right.Next()
+		n := batch.Length()
+		if n == 0 {
+			break
+		}
+		start := uint32(h.buildStore.n)
+		h.buildStore.appendBatch(batch)
+		keys := h.buildStore.cols[h.rightEqCol].([]int64)
+		nulls := h.buildStore.nulls[h.rightEqCol]
+		for i := start; i < start+uint32(n); i++ {
+			// NULL never matches anything in an equality join.
+			if nulls[i] {
+				continue
+			}
+			h.ht[keys[i]] = append(h.ht[keys[i]], i)
+		}
+	}
+	h.built = true
+}
+
+func (h *hashJoinEqInnerInt64Op) Next() ColBatch {
+	if !h.built {
+		h.build()
+	}
+
+	for _, vec := range h.output.ColVecs() {
+		vec.UnsetNulls()
+	}
+	n := 0
+	for n < ColBatchSize {
+		if h.probeBatch == nil || h.probeIdx == h.probeBatch.Length() {
+			if n > 0 {
+				// The output refers to tuples in the current probe batch, so we
+				// have to flush it before we can move on to the next one.
+				break
+			}
+			h.probeBatch = h.left.Next()
+			h.probeIdx = 0
+			h.matchIdx = 0
+			if h.probeBatch.Length() == 0 {
+				break
+			}
+		}
+
+		i := h.probeIdx
+		if sel := h.probeBatch.Selection(); sel != nil {
+			i = sel[h.probeIdx]
+		}
+		keyVec := h.probeBatch.ColVec(h.leftEqCol)
+		var matches []uint32
+		if !keyVec.NullAt(i) {
+			matches = h.ht[keyVec.Int64()[i]]
+		}
+		for ; h.matchIdx < len(matches) && n < ColBatchSize; h.matchIdx++ {
+			h.leftIdxs[n] = i
+			h.rightIdxs[n] = matches[h.matchIdx]
+			n++
+		}
+		if h.matchIdx == len(matches) {
+			h.matchIdx = 0
+			h.probeIdx++
+		}
+	}
+
+	if n > 0 {
+		for c, t := range h.leftTypes {
+			copyVec(t, h.probeBatch.ColVec(c), h.output.ColVec(c), h.leftIdxs[:n])
+		}
+		h.buildStore.copyTo(h.output, len(h.leftTypes), h.rightIdxs[:n])
+	}
+	h.output.SetLength(uint16(n))
+	return h.output
+}
@@ -0,0 +1,75 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestHashJoinEqInnerInt64(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	leftTypes := []T{Int64, Bytes}
+	rightTypes := []T{Float64, Int64}
+	left := tuples{{1, "a"}, {2, "b"}, {nil, "c"}, {3, "d"}, {2, "e"}}
+	right := tuples{{0.5, 2}, {1.5, nil}, {2.5, 3}, {3.5, 2}, {4.5, 5}}
+	expected := tuples{
+		{2, "b", 0.5, 2},
+		{2, "b", 3.5, 2},
+		{3, "d", 2.5, 3},
+		{2, "e", 0.5, 2},
+		{2, "e", 3.5, 2},
+	}
+
+	for _, batchSize := range batchSizes {
+		t.Run(fmt.Sprintf("batch=%d", batchSize), func(t *testing.T) {
+			op := NewHashJoinEqInnerInt64Op(
+				newOpTestInput(batchSize, leftTypes, left),
+				newOpTestInput(batchSize, rightTypes, right),
+				leftTypes, rightTypes, 0 /* leftEqCol */, 1, /* rightEqCol */
+			)
+			op.Init()
+			// The output preserves the order of the left input, but the order of
+			// the matches for each left tuple is unspecified.
+			assertTuplesEqual(t, expected, collectTuples(op), false /* ordered */)
+		})
+	}
+}
+
+func TestHashJoinEqInnerInt64LargeOutput(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Every left tuple matches every right tuple, so a single probe tuple
+	// produces more output than fits into one batch.
+	const numRight = ColBatchSize + 10
+	typs := []T{Int64}
+	left := tuples{{1}, {1}}
+	right := make(tuples, numRight)
+	for i := range right {
+		right[i] = tuple{1}
+	}
+	op := NewHashJoinEqInnerInt64Op(
+		newOpTestInput(ColBatchSize, typs, left),
+		newOpTestInput(ColBatchSize, typs, right),
+		typs, typs, 0 /* leftEqCol */, 0, /* rightEqCol */
+	)
+	op.Init()
+	if n := len(collectTuples(op)); n != 2*numRight {
+		t.Fatalf("expected %d tuples, found %d", 2*numRight, n)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package exec contains the columnar ("vectorized") execution engine. Instead
+// of passing rows of EncDatums between processors one at a time, columnar
+// operators pass ColBatches of up to ColBatchSize tuples laid out as
+// type-specialized column vectors, which allows tight loops without per-row
+// interface dispatch or type switches.
+package exec
+
+import "fmt"
+
+// Operator is a column vector operator that produces a ColBatch as output.
+type Operator interface {
+	// Init initializes this operator. Will be called once at operator setup
+	// time. If an operator has an input operator, it's responsible for calling
+	// Init on that input operator as well.
+	Init()
+
+	// Next returns the next ColBatch from this operator. Once the operator is
+	// finished, it will return a ColBatch with length 0. Subsequent calls to
+	// Next at the end of the input will also return ColBatches with length 0.
+	//
+	// Calling Next may invalidate the contents of the last ColBatch returned by
+	// Next.
+	Next() ColBatch
+}
+
+// vectorizedRuntimeError is a wrapper for errors that occur during columnar
+// execution. Operators have no error return path on Next, so errors are
+// propagated by panicking with this type and recovered at the boundary of the
+// columnar flow by CatchVectorizedRuntimeError.
+type vectorizedRuntimeError struct {
+	cause error
+}
+
+// Error is part of the error interface.
+func (e vectorizedRuntimeError) Error() string {
+	return e.cause.Error()
+}
+
+// RaiseError aborts the execution of the current columnar flow with the given
+// error. It must only be called from within an operator's Init or Next
+// methods, below a call to CatchVectorizedRuntimeError.
+func RaiseError(err error) {
+	panic(vectorizedRuntimeError{cause: err})
+}
+
+// CatchVectorizedRuntimeError executes operation, catching any error raised by
+// RaiseError and returning it. Panics that were not caused by RaiseError are
+// re-raised.
+func CatchVectorizedRuntimeError(operation func()) (retErr error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(vectorizedRuntimeError); ok {
+				retErr = e.cause
+				return
+			}
+			panic(r)
+		}
+	}()
+	operation()
+	return nil
+}
+
+// noopOperator is an Operator that simply passes its input batches through.
+// It is useful as a placeholder when a stage of a columnar plan requires no
+// work.
+type noopOperator struct {
+	input Operator
+}
+
+var _ Operator = &noopOperator{}
+
+// NewNoop returns a new noop Operator.
+func NewNoop(input Operator) Operator {
+	return &noopOperator{input: input}
+}
+
+func (n *noopOperator) Init() {
+	n.input.Init()
+}
+
+func (n *noopOperator) Next() ColBatch {
+	return n.input.Next()
+}
+
+// checkColIdx panics with a descriptive message if colIdx isn't a valid
+// column of a batch with the given width. It is used by operators on their
+// first batch to catch planning errors early.
+func checkColIdx(colIdx int, width int) {
+	if colIdx < 0 || colIdx >= width {
+		panic(fmt.Sprintf("column index %d out of range for batch of width %d", colIdx, width))
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+var errIntOutOfRange = pgerror.NewError(pgerror.CodeNumericValueOutOfRangeError, "integer out of range")
+
+// NewProjConstOp returns an Operator that appends a column containing the
+// result of `@colIdx <op> constArg` at outputIdx. If the input column is NULL,
+// the output is NULL as well.
+func NewProjConstOp(
+	t T, op tree.BinaryOperator, input Operator, colIdx int, constArg interface{}, outputIdx int,
+) (Operator, error) {
+	switch t {
+	case Int64:
+		fn, err := int64BinFn(op)
+		if err != nil {
+			return nil, err
+		}
+		c, ok := constArg.(int64)
+		if !ok {
+			return nil, errors.Errorf("expected int64 constant, found %T", constArg)
+		}
+		return &projInt64ConstOp{
+			input: input, colIdx: colIdx, constArg: c, outputIdx: outputIdx, fn: fn,
+		}, nil
+	case Float64:
+		fn, err := float64BinFn(op)
+		if err != nil {
+			return nil, err
+		}
+		c, ok := constArg.(float64)
+		if !ok {
+			return nil, errors.Errorf("expected float64 constant, found %T", constArg)
+		}
+		return &projFloat64ConstOp{
+			input: input, colIdx: colIdx, constArg: c, outputIdx: outputIdx, fn: fn,
+		}, nil
+	}
+	return nil, errors.Errorf("unsupported projection type %s", t)
+}
+
+// NewProjOp returns an Operator that appends a column containing the result
+// of `@col1Idx <op> @col2Idx` at outputIdx. Both input columns must be of type
+// t.
+func NewProjOp(
+	t T, op tree.BinaryOperator, input Operator, col1Idx int, col2Idx int, outputIdx int,
+) (Operator, error) {
+	switch t {
+	case Int64:
+		fn, err := int64BinFn(op)
+		if err != nil {
+			return nil, err
+		}
+		return &projInt64Op{
+			input: input, col1Idx: col1Idx, col2Idx: col2Idx, outputIdx: outputIdx, fn: fn,
+		}, nil
+	case Float64:
+		fn, err := float64BinFn(op)
+		if err != nil {
+			return nil, err
+		}
+		return &projFloat64Op{
+			input: input, col1Idx: col1Idx, col2Idx: col2Idx, outputIdx: outputIdx, fn: fn,
+		}, nil
+	}
+	return nil, errors.Errorf("unsupported projection type %s", t)
+}
+
+// int64BinFn returns the overflow-checked implementation of the given binary
+// operator on int64s. The semantics match the corresponding BinOps in
+// tree.BinOps.
+func int64BinFn(op tree.BinaryOperator) (func(a, b int64) int64, error) {
+	switch op {
+	case tree.Plus:
+		return func(a, b int64) int64 {
+			r, ok := tree.AddWithOverflow(a, b)
+			if !ok {
+				RaiseError(errIntOutOfRange)
+			}
+			return r
+		}, nil
+	case tree.Minus:
+		return func(a, b int64) int64 {
+			if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+				RaiseError(errIntOutOfRange)
+			}
+			return a - b
+		}, nil
+	case tree.Mult:
+		return func(a, b int64) int64 {
+			c := a * b
+			if a == 0 || b == 0 || a == 1 || b == 1 {
+				// ignore
+			} else if a == math.MinInt64 || b == math.MinInt64 {
+				// This test is required to detect math.MinInt64 * -1.
+				RaiseError(errIntOutOfRange)
+			} else if c/b != a {
+				RaiseError(errIntOutOfRange)
+			}
+			return c
+		}, nil
+	}
+	return nil, errors.Errorf("unsupported binary operator %s", op)
+}
+
+func float64BinFn(op tree.BinaryOperator) (func(a, b float64) float64, error) {
+	switch op {
+	case tree.Plus:
+		return func(a, b float64) float64 { return a + b }, nil
+	case tree.Minus:
+		return func(a, b float64) float64 { return a - b }, nil
+	case tree.Mult:
+		return func(a, b float64) float64 { return a * b }, nil
+	}
+	return nil, errors.Errorf("unsupported binary operator %s", op)
+}
+
+type projInt64ConstOp struct {
+	input Operator
+
+	colIdx    int
+	constArg  int64
+	outputIdx int
+	fn        func(a, b int64) int64
+}
+
+var _ Operator = &projInt64ConstOp{}
+
+func (p *projInt64ConstOp) Init() {
+	p.input.Init()
+}
+
+func (p *projInt64ConstOp) Next() ColBatch {
+	batch := p.input.Next()
+	if p.outputIdx == batch.Width() {
+		batch.AppendCol(Int64)
+	}
+	n := batch.Length()
+	if n == 0 {
+		return batch
+	}
+	vec := batch.ColVec(p.colIdx)
+	outVec := batch.ColVec(p.outputIdx)
+	outVec.UnsetNulls()
+	col, projCol := vec.Int64(), outVec.Int64()
+	if sel := batch.Selection(); sel != nil {
+		for _, i := range sel[:n] {
+			if vec.NullAt(i) {
+				outVec.SetNull(i)
+				continue
+			}
+			projCol[i] = p.fn(col[i], p.constArg)
+		}
+	} else {
+		for i := uint16(0); i < n; i++ {
+			if vec.NullAt(i) {
+				outVec.SetNull(i)
+				continue
+			}
+			projCol[i] = p.fn(col[i], p.constArg)
+		}
+	}
+	return batch
+}
+
+type projFloat64ConstOp struct {
+	input Operator
+
+	colIdx    int
+	constArg  float64
+	outputIdx int
+	fn        func(a, b float64) float64
+}
+
+var _ Operator = &projFloat64ConstOp{}
+
+func (p *projFloat64ConstOp) Init() {
+	p.input.Init()
+}
+
+func (p *projFloat64ConstOp) Next() ColBatch {
+	batch := p.input.Next()
+	if p.outputIdx == batch.Width() {
+		batch.AppendCol(Float64)
+	}
+	n := batch.Length()
+	if n == 0 {
+		return batch
+	}
+	vec := batch.ColVec(p.colIdx)
+	outVec := batch.ColVec(p.outputIdx)
+	outVec.UnsetNulls()
+	col, projCol := vec.Float64(), outVec.Float64()
+	if sel := batch.Selection(); sel != nil {
+		for _, i := range sel[:n] {
+			if vec.NullAt(i) {
+				outVec.SetNull(i)
+				continue
+			}
+			projCol[i] = p.fn(col[i], p.constArg)
+		}
+	} else {
+		for i := uint16(0); i < n; i++ {
+			if vec.NullAt(i) {
+				outVec.SetNull(i)
+				continue
+			}
+			projCol[i] = p.fn(col[i], p.constArg)
+		}
+	}
+	return batch
+}
+
+type projInt64Op struct {
+	input Operator
+
+	col1Idx   int
+	col2Idx   int
+	outputIdx int
+	fn        func(a, b int64) int64
+}
+
+var _ Operator = &projInt64Op{}
+
+func (p *projInt64Op) Init() {
+	p.input.Init()
+}
+
+func (p *projInt64Op) Next() ColBatch {
+	batch := p.input.Next()
+	if p.outputIdx == batch.Width() {
+		batch.AppendCol(Int64)
+	}
+	n := batch.Length()
+	if n == 0 {
+		return batch
+	}
+	vec1, vec2 := batch.ColVec(p.col1Idx), batch.ColVec(p.col2Idx)
+	outVec := batch.ColVec(p.outputIdx)
+	outVec.UnsetNulls()
+	col1, col2, projCol := vec1.Int64(), vec2.Int64(), outVec.Int64()
+	if sel := batch.Selection(); sel != nil {
+		for _, i := range sel[:n] {
+			if vec1.NullAt(i) || vec2.NullAt(i) {
+				outVec.SetNull(i)
+				continue
+			}
+			projCol[i] = p.fn(col1[i], col2[i])
+		}
+	} else {
+		for i := uint16(0); i < n; i++ {
+			if vec1.NullAt(i) || vec2.NullAt(i) {
+				outVec.SetNull(i)
+				continue
+			}
+			projCol[i] = p.fn(col1[i], col2[i])
+		}
+	}
+	return batch
+}
+
+type projFloat64Op struct {
+	input Operator
+
+	col1Idx   int
+	col2Idx   int
+	outputIdx int
+	fn        func(a, b float64) float64
+}
+
+var _ Operator = &projFloat64Op{}
+
+func (p *projFloat64Op) Init() {
+	p.input.Init()
+}
+
+func (p *projFloat64Op) Next() ColBatch {
+	batch := p.input.Next()
+	if p.outputIdx == batch.Width() {
+		batch.AppendCol(Float64)
+	}
+	n := batch.Length()
+	if n == 0 {
+		return batch
+	}
+	vec1, vec2 := batch.ColVec(p.col1Idx), batch.ColVec(p.col2Idx)
+	outVec := batch.ColVec(p.outputIdx)
+	outVec.UnsetNulls()
+	col1, col2, projCol := vec1.Float64(), vec2.Float64(), outVec.Float64()
+	if sel := batch.Selection(); sel != nil {
+		for _, i := range sel[:n] {
+			if vec1.NullAt(i) || vec2.NullAt(i) {
+				outVec.SetNull(i)
+				continue
+			}
+			projCol[i] = p.fn(col1[i], col2[i])
+		}
+	} else {
+		for i := uint16(0); i < n; i++ {
+			if vec1.NullAt(i) || vec2.NullAt(i) {
+				outVec.SetNull(i)
+				continue
+			}
+			projCol[i] = p.fn(col1[i], col2[i])
+		}
+	}
+	return batch
+}
@@ -0,0 +1,342 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// NewSelConstOp returns an Operator that filters its input down to the tuples
+// for which `@colIdx <cmp> constArg` is true. Tuples with a NULL in the
+// compared column are always filtered out, since a comparison with NULL is
+// never true. constArg must be of the Go type corresponding to t (int64,
+// float64, bool or []byte).
+func NewSelConstOp(
+	t T, cmp tree.ComparisonOperator, input Operator, colIdx int, constArg interface{},
+) (Operator, error) {
+	switch t {
+	case Int64:
+		fn, err := int64CmpFn(cmp)
+		if err != nil {
+			return nil, err
+		}
+		c, ok := constArg.(int64)
+		if !ok {
+			return nil, errors.Errorf("expected int64 constant, found %T", constArg)
+		}
+		return &selInt64ConstOp{input: input, colIdx: colIdx, constArg: c, cmp: fn}, nil
+	case Float64:
+		fn, err := float64CmpFn(cmp)
+		if err != nil {
+			return nil, err
+		}
+		c, ok := constArg.(float64)
+		if !ok {
+			return nil, errors.Errorf("expected float64 constant, found %T", constArg)
+		}
+		return &selFloat64ConstOp{input: input, colIdx: colIdx, constArg: c, cmp: fn}, nil
+	case Bytes:
+		fn, err := bytesCmpFn(cmp)
+		if err != nil {
+			return nil, err
+		}
+		c, ok := constArg.([]byte)
+		if !ok {
+			return nil, errors.Errorf("expected []byte constant, found %T", constArg)
+		}
+		return &selBytesConstOp{input: input, colIdx: colIdx, constArg: c, cmp: fn}, nil
+	}
+	return nil, errors.Errorf("unsupported selection type %s", t)
+}
+
+// NewSelBoolOp returns an Operator that filters its input down to the tuples
+// for which the bool column colIdx is true.
+func NewSelBoolOp(input Operator, colIdx int) Operator {
+	return &selBoolOp{input: input, colIdx: colIdx}
+}
+
+func int64CmpFn(cmp tree.ComparisonOperator) (func(a, b int64) bool, error) {
+	switch cmp {
+	case tree.EQ:
+		return func(a, b int64) bool { return a == b }, nil
+	case tree.NE:
+		return func(a, b int64) bool { return a != b }, nil
+	case tree.LT:
+		return func(a, b int64) bool { return a < b }, nil
+	case tree.LE:
+		return func(a, b int64) bool { return a <= b }, nil
+	case tree.GT:
+		return func(a, b int64) bool { return a > b }, nil
+	case tree.GE:
+		return func(a, b int64) bool { return a >= b }, nil
+	}
+	return nil, errors.Errorf("unsupported comparison operator %s", cmp)
+}
+
+func float64CmpFn(cmp tree.ComparisonOperator) (func(a, b float64) bool, error) {
+	// Note that NaN handling matches the row engine: NaN is equal to itself
+	// and smaller than any other float.
+	compare := func(a, b float64) int {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		case a == b:
+			return 0
+		case a != a && b != b:
+			return 0
+		case a != a:
+			return -1
+		default:
+			return 1
+		}
+	}
+	switch cmp {
+	case tree.EQ:
+		return func(a, b float64) bool { return compare(a, b) == 0 }, nil
+	case tree.NE:
+		return func(a, b float64) bool { return compare(a, b) != 0 }, nil
+	case tree.LT:
+		return func(a, b float64) bool { return compare(a, b) < 0 }, nil
+	case tree.LE:
+		return func(a, b float64) bool { return compare(a, b) <= 0 }, nil
+	case tree.GT:
+		return func(a, b float64) bool { return compare(a, b) > 0 }, nil
+	case tree.GE:
+		return func(a, b float64) bool { return compare(a, b) >= 0 }, nil
+	}
+	return nil, errors.Errorf("unsupported comparison operator %s", cmp)
+}
+
+func bytesCmpFn(cmp tree.ComparisonOperator) (func(a, b []byte) bool, error) {
+	switch cmp {
+	case tree.EQ:
+		return func(a, b []byte) bool { return bytes.Equal(a, b) }, nil
+	case tree.NE:
+		return func(a, b []byte) bool { return !bytes.Equal(a, b) }, nil
+	case tree.LT:
+		return func(a, b []byte) bool { return bytes.Compare(a, b) < 0 }, nil
+	case tree.LE:
+		return func(a, b []byte) bool { return bytes.Compare(a, b) <= 0 }, nil
+	case tree.GT:
+		return func(a, b []byte) bool { return bytes.Compare(a, b) > 0 }, nil
+	case tree.GE:
+		return func(a, b []byte) bool { return bytes.Compare(a, b) >= 0 }, nil
+	}
+	return nil, errors.Errorf("unsupported comparison operator %s", cmp)
+}
+
+type selInt64ConstOp struct {
+	input Operator
+
+	colIdx   int
+	constArg int64
+	cmp      func(a, b int64) bool
+}
+
+var _ Operator = &selInt64ConstOp{}
+
+func (p *selInt64ConstOp) Init() {
+	p.input.Init()
+}
+
+func (p *selInt64ConstOp) Next() ColBatch {
+	for {
+		batch := p.input.Next()
+		n := batch.Length()
+		if n == 0 {
+			return batch
+		}
+
+		vec := batch.ColVec(p.colIdx)
+		col := vec.Int64()
+		var idx uint16
+		if sel := batch.Selection(); sel != nil {
+			sel = sel[:n]
+			for _, i := range sel {
+				if !vec.NullAt(i) && p.cmp(col[i], p.constArg) {
+					sel[idx] = i
+					idx++
+				}
+			}
+		} else {
+			batch.SetSelection(true)
+			sel := batch.Selection()
+			for i := uint16(0); i < n; i++ {
+				if !vec.NullAt(i) && p.cmp(col[i], p.constArg) {
+					sel[idx] = i
+					idx++
+				}
+			}
+		}
+		if idx > 0 {
+			batch.SetLength(idx)
+			return batch
+		}
+	}
+}
+
+type selFloat64ConstOp struct {
+	input Operator
+
+	colIdx   int
+	constArg float64
+	cmp      func(a, b float64) bool
+}
+
+var _ Operator = &selFloat64ConstOp{}
+
+func (p *selFloat64ConstOp) Init() {
+	p.input.Init()
+}
+
+func (p *selFloat64ConstOp) Next() ColBatch {
+	for {
+		batch := p.input.Next()
+		n := batch.Length()
+		if n == 0 {
+			return batch
+		}
+
+		vec := batch.ColVec(p.colIdx)
+		col := vec.Float64()
+		var idx uint16
+		if sel := batch.Selection(); sel != nil {
+			sel = sel[:n]
+			for _, i := range sel {
+				if !vec.NullAt(i) && p.cmp(col[i], p.constArg) {
+					sel[idx] = i
+					idx++
+				}
+			}
+		} else {
+			batch.SetSelection(true)
+			sel := batch.Selection()
+			for i := uint16(0); i < n; i++ {
+				if !vec.NullAt(i) && p.cmp(col[i], p.constArg) {
+					sel[idx] = i
+					idx++
+				}
+			}
+		}
+		if idx > 0 {
+			batch.SetLength(idx)
+			return batch
+		}
+	}
+}
+
+type selBytesConstOp struct {
+	input Operator
+
+	colIdx   int
+	constArg []byte
+	cmp      func(a, b []byte) bool
+}
+
+var _ Operator = &selBytesConstOp{}
+
+func (p *selBytesConstOp) Init() {
+	p.input.Init()
+}
+
+func (p *selBytesConstOp) Next() ColBatch {
+	for {
+		batch := p.input.Next()
+		n := batch.Length()
+		if n == 0 {
+			return batch
+		}
+
+		vec := batch.ColVec(p.colIdx)
+		col := vec.Bytes()
+		var idx uint16
+		if sel := batch.Selection(); sel != nil {
+			sel = sel[:n]
+			for _, i := range sel {
+				if !vec.NullAt(i) && p.cmp(col[i], p.constArg) {
+					sel[idx] = i
+					idx++
+				}
+			}
+		} else {
+			batch.SetSelection(true)
+			sel := batch.Selection()
+			for i := uint16(0); i < n; i++ {
+				if !vec.NullAt(i) && p.cmp(col[i], p.constArg) {
+					sel[idx] = i
+					idx++
+				}
+			}
+		}
+		if idx > 0 {
+			batch.SetLength(idx)
+			return batch
+		}
+	}
+}
+
+type selBoolOp struct {
+	input Operator
+
+	colIdx int
+}
+
+var _ Operator = &selBoolOp{}
+
+func (p *selBoolOp) Init() {
+	p.input.Init()
+}
+
+func (p *selBoolOp) Next() ColBatch {
+	for {
+		batch := p.input.Next()
+		n := batch.Length()
+		if n == 0 {
+			return batch
+		}
+
+		vec := batch.ColVec(p.colIdx)
+		col := vec.Bool()
+		var idx uint16
+		if sel := batch.Selection(); sel != nil {
+			sel = sel[:n]
+			for _, i := range sel {
+				if !vec.NullAt(i) && col[i] {
+					sel[idx] = i
+					idx++
+				}
+			}
+		} else {
+			batch.SetSelection(true)
+			sel := batch.Selection()
+			for i := uint16(0); i < n; i++ {
+				if !vec.NullAt(i) && col[i] {
+					sel[idx] = i
+					idx++
+				}
+			}
+		}
+		if idx > 0 {
+			batch.SetLength(idx)
+			return batch
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestSelConstOp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	input := tuples{{0, 1.5}, {1, nil}, {2, 2.5}, {nil, 3.5}, {4, 4.5}}
+	typs := []T{Int64, Float64}
+	testCases := []struct {
+		typ      T
+		cmp      tree.ComparisonOperator
+		colIdx   int
+		constArg interface{}
+		expected tuples
+	}{
+		{Int64, tree.LT, 0, int64(2), tuples{{0, 1.5}, {1, nil}}},
+		{Int64, tree.GE, 0, int64(2), tuples{{2, 2.5}, {4, 4.5}}},
+		{Int64, tree.NE, 0, int64(1), tuples{{0, 1.5}, {2, 2.5}, {4, 4.5}}},
+		{Float64, tree.GT, 1, float64(2), tuples{{2, 2.5}, {nil, 3.5}, {4, 4.5}}},
+		{Float64, tree.EQ, 1, float64(7), nil},
+	}
+	for _, tc := range testCases {
+		for _, batchSize := range batchSizes {
+			t.Run(fmt.Sprintf("%s%s%v/batch=%d", tc.typ, tc.cmp, tc.constArg, batchSize), func(t *testing.T) {
+				op, err := NewSelConstOp(
+					tc.typ, tc.cmp, newOpTestInput(batchSize, typs, input), tc.colIdx, tc.constArg,
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				op.Init()
+				assertTuplesEqual(t, tc.expected, collectTuples(op), true /* ordered */)
+			})
+		}
+	}
+}
+
+func TestSelOpChain(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Two chained selections exercise the path where the input already has a
+	// selection vector.
+	input := tuples{{1, true}, {2, false}, {3, true}, {4, nil}, {5, true}}
+	typs := []T{Int64, Bool}
+	for _, batchSize := range batchSizes {
+		var op Operator = newOpTestInput(batchSize, typs, input)
+		op = NewSelBoolOp(op, 1)
+		op, err := NewSelConstOp(Int64, tree.GT, op, 0, int64(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		op.Init()
+		assertTuplesEqual(t, tuples{{3, true}, {5, true}}, collectTuples(op), true /* ordered */)
+	}
+}
+
+func TestProjOp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	input := tuples{{1, 2}, {3, nil}, {nil, 5}}
+	typs := []T{Int64, Int64}
+	for _, batchSize := range batchSizes {
+		op, err := NewProjOp(Int64, tree.Mult, newOpTestInput(batchSize, typs, input), 0, 1, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		op, err = NewProjConstOp(Int64, tree.Plus, op, 2, int64(10), 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		op.Init()
+		expected := tuples{{1, 2, 2, 12}, {3, nil, nil, nil}, {nil, 5, nil, nil}}
+		assertTuplesEqual(t, expected, collectTuples(op), true /* ordered */)
+	}
+}
+
+func TestProjOpOverflow(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	op, err := NewProjConstOp(
+		Int64, tree.Plus, newOpTestInput(ColBatchSize, []T{Int64}, tuples{{1 << 62}}), 0, int64(1<<62), 1,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CatchVectorizedRuntimeError(func() {
+		op.Init()
+		collectTuples(op)
+	})
+	if err != errIntOutOfRange {
+		t.Fatalf("expected %v, found %v", errIntOutOfRange, err)
+	}
+}
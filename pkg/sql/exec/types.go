@@ -0,0 +1,79 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import "github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+
+// T represents an exec physical type - the Go representation of the values of
+// a particular column type.
+type T int
+
+const (
+	// Bool is a column of type bool.
+	Bool T = iota
+	// Bytes is a column of type []byte.
+	Bytes
+	// Int64 is a column of type int64.
+	Int64
+	// Float64 is a column of type float64.
+	Float64
+
+	// Unhandled represents a column type that the columnar engine does not yet
+	// support. Plans involving such columns run on the row engine.
+	Unhandled
+)
+
+func (t T) String() string {
+	switch t {
+	case Bool:
+		return "Bool"
+	case Bytes:
+		return "Bytes"
+	case Int64:
+		return "Int64"
+	case Float64:
+		return "Float64"
+	default:
+		return "Unhandled"
+	}
+}
+
+// FromColumnType returns the T that corresponds to the input ColumnType.
+func FromColumnType(ct sqlbase.ColumnType) T {
+	switch ct.SemanticType {
+	case sqlbase.ColumnType_BOOL:
+		return Bool
+	case sqlbase.ColumnType_BYTES, sqlbase.ColumnType_STRING:
+		return Bytes
+	case sqlbase.ColumnType_INT:
+		return Int64
+	case sqlbase.ColumnType_FLOAT:
+		return Float64
+	}
+	return Unhandled
+}
+
+// FromColumnTypes calls FromColumnType on each element of cts, returning the
+// resulting slice along with whether all of the types are handled.
+func FromColumnTypes(cts []sqlbase.ColumnType) ([]T, bool) {
+	typs := make([]T, len(cts))
+	for i := range cts {
+		typs[i] = FromColumnType(cts[i])
+		if typs[i] == Unhandled {
+			return nil, false
+		}
+	}
+	return typs, true
+}
@@ -0,0 +1,148 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// tuple is a row of values of Go native types; nil represents NULL.
+type tuple []interface{}
+
+// tuples is a set of tuples.
+type tuples []tuple
+
+// opTestInput is an Operator that columnarizes test input in the form of
+// tuples of arbitrary Go types. Each tuple must be of the same length and the
+// types of its values must match typs.
+type opTestInput struct {
+	typs      []T
+	batchSize uint16
+	tuples    tuples
+	batch     ColBatch
+}
+
+var _ Operator = &opTestInput{}
+
+func newOpTestInput(batchSize uint16, typs []T, tuples tuples) *opTestInput {
+	return &opTestInput{typs: typs, batchSize: batchSize, tuples: tuples}
+}
+
+func (s *opTestInput) Init() {
+	s.batch = NewMemBatch(s.typs)
+}
+
+func (s *opTestInput) Next() ColBatch {
+	s.batch.SetSelection(false)
+	n := uint16(len(s.tuples))
+	if n > s.batchSize {
+		n = s.batchSize
+	}
+	tups := s.tuples[:n]
+	s.tuples = s.tuples[n:]
+	for c := range s.typs {
+		vec := s.batch.ColVec(c)
+		vec.UnsetNulls()
+		for i, t := range tups {
+			if t[c] == nil {
+				vec.SetNull(uint16(i))
+				continue
+			}
+			switch s.typs[c] {
+			case Bool:
+				vec.Bool()[i] = t[c].(bool)
+			case Bytes:
+				vec.Bytes()[i] = []byte(t[c].(string))
+			case Int64:
+				vec.Int64()[i] = int64(t[c].(int))
+			case Float64:
+				vec.Float64()[i] = t[c].(float64)
+			}
+		}
+	}
+	s.batch.SetLength(n)
+	return s.batch
+}
+
+// collectTuples runs op to completion and returns the tuples it produced,
+// converted back to the representation used by opTestInput.
+func collectTuples(op Operator) tuples {
+	var res tuples
+	for {
+		batch := op.Next()
+		n := batch.Length()
+		if n == 0 {
+			return res
+		}
+		sel := batch.Selection()
+		for k := uint16(0); k < n; k++ {
+			i := k
+			if sel != nil {
+				i = sel[k]
+			}
+			t := make(tuple, batch.Width())
+			for c := range t {
+				vec := batch.ColVec(c)
+				if vec.NullAt(i) {
+					continue
+				}
+				switch vec.Type() {
+				case Bool:
+					t[c] = vec.Bool()[i]
+				case Bytes:
+					t[c] = string(vec.Bytes()[i])
+				case Int64:
+					t[c] = int(vec.Int64()[i])
+				case Float64:
+					t[c] = vec.Float64()[i]
+				}
+			}
+			res = append(res, t)
+		}
+	}
+}
+
+// assertTuplesEqual fails the test if the two sets of tuples differ. If
+// ordered is false, the tuples are compared as multisets.
+func assertTuplesEqual(t *testing.T, expected, actual tuples, ordered bool) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("expected %d tuples, found %d\nexpected %v\nfound %v",
+			len(expected), len(actual), expected, actual)
+	}
+	if ordered {
+		if !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("expected %v, found %v", expected, actual)
+		}
+		return
+	}
+	counts := make(map[string]int)
+	for _, tup := range expected {
+		counts[fmt.Sprint(tup)]++
+	}
+	for _, tup := range actual {
+		key := fmt.Sprint(tup)
+		if counts[key] == 0 {
+			t.Fatalf("unexpected tuple %v\nexpected %v\nfound %v", tup, expected, actual)
+		}
+		counts[key]--
+	}
+}
+
+// batchSizes are the input batch sizes that operator tests are run with, to
+// exercise both single- and multi-batch inputs.
+var batchSizes = []uint16{1, 2, 3, ColBatchSize}
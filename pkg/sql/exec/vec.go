@@ -0,0 +1,112 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import "fmt"
+
+// ColVec is an interface that represents a column vector that's accessible by
+// Go native types.
+type ColVec interface {
+	// Type returns the physical type of the column.
+	Type() T
+
+	// Bool returns a bool list.
+	Bool() []bool
+	// Int64 returns an int64 slice.
+	Int64() []int64
+	// Float64 returns a float64 slice.
+	Float64() []float64
+	// Bytes returns a [][]byte slice.
+	Bytes() [][]byte
+
+	// HasNulls returns true if the column has any null values.
+	HasNulls() bool
+	// NullAt returns true if the ith value of the column is null.
+	NullAt(i uint16) bool
+	// SetNull sets the ith value of the column to null.
+	SetNull(i uint16)
+	// UnsetNulls sets the column to have no null values.
+	UnsetNulls()
+}
+
+// memColumn is a simple pass-through implementation of ColVec that just casts
+// a generic interface{} to the proper type when requested.
+type memColumn struct {
+	t   T
+	col interface{}
+
+	// nulls is nil until the first null is set, at which point it is
+	// allocated to the length of the column.
+	nulls []bool
+}
+
+var _ ColVec = &memColumn{}
+
+// newMemColumn returns a new memColumn, initialized with a length.
+func newMemColumn(t T, n int) *memColumn {
+	m := &memColumn{t: t}
+	switch t {
+	case Bool:
+		m.col = make([]bool, n)
+	case Bytes:
+		m.col = make([][]byte, n)
+	case Int64:
+		m.col = make([]int64, n)
+	case Float64:
+		m.col = make([]float64, n)
+	default:
+		panic(fmt.Sprintf("unhandled type %s", t))
+	}
+	return m
+}
+
+func (m *memColumn) Type() T {
+	return m.t
+}
+
+func (m *memColumn) Bool() []bool {
+	return m.col.([]bool)
+}
+
+func (m *memColumn) Int64() []int64 {
+	return m.col.([]int64)
+}
+
+func (m *memColumn) Float64() []float64 {
+	return m.col.([]float64)
+}
+
+func (m *memColumn) Bytes() [][]byte {
+	return m.col.([][]byte)
+}
+
+func (m *memColumn) HasNulls() bool {
+	return m.nulls != nil
+}
+
+func (m *memColumn) NullAt(i uint16) bool {
+	return m.nulls != nil && m.nulls[i]
+}
+
+func (m *memColumn) SetNull(i uint16) {
+	if m.nulls == nil {
+		m.nulls = make([]bool, ColBatchSize)
+	}
+	m.nulls[i] = true
+}
+
+func (m *memColumn) UnsetNulls() {
+	m.nulls = nil
+}
@@ -38,6 +38,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/colenc"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlplan"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
@@ -46,6 +47,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -83,6 +85,7 @@ func errWrongNumberOfPreparedStatements(n int) error {
 
 const sqlTxnName string = "sql txn"
 const sqlImplicitTxnName string = "sql txn implicit"
+const sqlStmtName string = "sql stmt"
 const metricsSampleInterval = 10 * time.Second
 
 // Fully-qualified names for metrics.
@@ -191,6 +194,8 @@ type Result struct {
 	// the result set of the result.
 	// TODO(nvanbenschoten): Can this be streamed from the planNode?
 	Rows *sqlbase.RowContainer
+	// Notices are the notices the statement attached to its results.
+	Notices []Notice
 }
 
 // Close ensures that the resources claimed by the result are released.
@@ -242,6 +247,14 @@ type Executor struct {
 
 	distSQLPlanner *DistSQLPlanner
 
+	// admission queues statements when too many are executing on this node.
+	admission admissionQueue
+	// roleQuotas enforces the quotas of the roles on this node.
+	roleQuotas roleQuotaLimiter
+
+	// notifications delivers the notifications sent with NOTIFY.
+	notifications notificationRegistry
+
 	// Application-level SQL statistics
 	sqlStats sqlStats
 
@@ -250,6 +263,18 @@ type Executor struct {
 		syncutil.Mutex
 		counts map[string]int64
 	}
+
+	// Uses of SQL features by the executed statements.
+	featureUsage featureUsage
+
+	// Errors found by the background SCRUB checks run on this node.
+	backgroundScrub backgroundScrubErrors
+
+	// Reads and writes of the indexes by the executed statements.
+	indexUsage indexUsageStats
+
+	// Pending statement diagnostics requests.
+	stmtDiagnostics stmtDiagnosticsRegistry
 }
 
 // NodeInfo contains metadata about the executing node and cluster.
@@ -278,6 +303,12 @@ type ExecutorConfig struct {
 	StatusServer    serverpb.StatusServer
 	SessionRegistry *SessionRegistry
 	JobRegistry     *jobs.Registry
+	// ColumnEncryptionKeys encrypts and decrypts the values of
+	// encrypt_column() and decrypt_column().
+	ColumnEncryptionKeys *colenc.KeyManager
+	// ContentionEvents holds the contention events recorded by the stores of
+	// this node. It may be nil.
+	ContentionEvents *storage.ContentionRegistry
 
 	TestingKnobs              *ExecutorTestingKnobs
 	SchemaChangerTestingKnobs *SchemaChangerTestingKnobs
@@ -399,6 +430,7 @@ func NewExecutor(cfg ExecutorConfig, stopper *stop.Stopper) *Executor {
 		MiscCount:   metric.NewCounter(MetaMisc),
 		QueryCount:  metric.NewCounter(MetaQuery),
 		sqlStats:    sqlStats{st: cfg.Settings, apps: make(map[string]*appStats)},
+		admission:   makeAdmissionQueue(cfg.Settings),
 	}
 }
 
@@ -425,6 +457,8 @@ func (e *Executor) Start(
 		}
 	})
 
+	e.notifications.start(e.cfg.AmbientCtx, e.cfg.Gossip)
+
 	ctx = log.WithLogTag(ctx, "startup", nil)
 	startupSession := NewSession(ctx, SessionArgs{}, e, nil, startupMemMetrics)
 	startupSession.StartUnlimitedMonitor()
@@ -432,6 +466,26 @@ func (e *Executor) Start(
 		log.Fatal(ctx, err)
 	}
 	startupSession.Finish(e)
+
+	for _, w := range backgroundWorkers {
+		w := w
+		e.stopper.RunWorker(ctx, func(ctx context.Context) {
+			w(ctx, e.stopper, e)
+		})
+	}
+}
+
+// BackgroundWorker is a function run by every executor once it is started,
+// until the stopper is stopped. It's primarily intended to allow periodic
+// jobs operating on the SQL data to live outside of the sql package.
+type BackgroundWorker func(ctx context.Context, stopper *stop.Stopper, e *Executor)
+
+var backgroundWorkers []BackgroundWorker
+
+// AddBackgroundWorker adds a worker run by the executors once they are
+// started. It must be called from an init function.
+func AddBackgroundWorker(w BackgroundWorker) {
+	backgroundWorkers = append(backgroundWorkers, w)
 }
 
 // GetVirtualTabler retrieves the VirtualTabler reference for this executor.
@@ -439,6 +493,11 @@ func (e *Executor) GetVirtualTabler() VirtualTabler {
 	return &e.virtualSchemas
 }
 
+// Cfg returns the configuration of the executor.
+func (e *Executor) Cfg() *ExecutorConfig {
+	return &e.cfg
+}
+
 // SetDistSQLSpanResolver changes the SpanResolver used for DistSQL. It is the
 // caller's responsibility to make sure no queries are being run with DistSQL at
 // the same time.
@@ -496,13 +555,18 @@ func (e *Executor) Prepare(
 		return prepared, nil
 	}
 
+	if err := session.statementRestrictions.check(session.User, stmt.AST); err != nil {
+		return nil, err
+	}
+
 	prepared.Statement = stmt.AST
 	prepared.AnonymizedStr = session.appStats.getStrForStmt(stmt)
 
 	if err := placeholderHints.ProcessPlaceholderAnnotations(stmt.AST); err != nil {
 		return nil, err
 	}
-	protoTS, err := isAsOf(session, stmt.AST, e.cfg.Clock.Now())
+	txnTimestamp := e.cfg.Clock.PhysicalTime()
+	protoTS, err := isAsOf(session, stmt.AST, txnTimestamp, e.cfg.Clock.Now())
 	if err != nil {
 		return nil, err
 	}
@@ -538,6 +602,9 @@ func (e *Executor) Prepare(
 		// known to the cache.
 		planner.avoidCachedDescriptors = true
 		txn.SetFixedTimestamp(session.Ctx(), *protoTS)
+		// The AS OF SYSTEM TIME clauses are evaluated again during planning,
+		// and must find the same timestamp.
+		planner.evalCtx.SetTxnTimestamp(txnTimestamp)
 	}
 
 	if filter := e.cfg.TestingKnobs.BeforePrepare; filter != nil {
@@ -800,6 +867,7 @@ func (e *Executor) execParsed(
 		// transaction (implicit txn or explicit txn). We do the corresponding state
 		// reset.
 		if !inTxn {
+			sqlTimestamp := e.cfg.Clock.PhysicalTime()
 			// Detect implicit transactions - they need to be autocommitted.
 			if _, isBegin := stmts[0].AST.(*tree.BeginTransaction); !isBegin {
 				autoCommit = true
@@ -807,7 +875,7 @@ func (e *Executor) execParsed(
 				// Check for AS OF SYSTEM TIME. If it is present but not detected here,
 				// it will raise an error later on.
 				var err error
-				protoTS, err = isAsOf(session, stmtsToExec[0].AST, e.cfg.Clock.Now())
+				protoTS, err = isAsOf(session, stmtsToExec[0].AST, sqlTimestamp, e.cfg.Clock.Now())
 				if err != nil {
 					return err
 				}
@@ -822,9 +890,9 @@ func (e *Executor) execParsed(
 			}
 			txnState.resetForNewSQLTxn(
 				e, session,
-				autoCommit, /* implicitTxn */
-				false,      /* retryIntent */
-				e.cfg.Clock.PhysicalTime(), /* sqlTimestamp */
+				autoCommit,   /* implicitTxn */
+				false,        /* retryIntent */
+				sqlTimestamp, /* sqlTimestamp */
 				session.DefaultIsolationLevel,
 				roachpb.NormalUserPriority,
 			)
@@ -1182,6 +1250,14 @@ func runTxnAttempt(
 	// currently in AutoRetry and we get to the end of the batch, both of which
 	// may not hold).
 	encounteredNonAutoRetryStmt := false
+
+	// Results are only buffered so that they can be discarded if we retry the
+	// statements automatically. If we're not in AutoRetry, we'll never do that,
+	// so let the results be streamed to the client as they're produced.
+	if origState != AutoRetry {
+		txnResults.StreamResults()
+	}
+
 	var i int
 	var stmt Statement
 	for i, stmt = range stmts {
@@ -1328,9 +1404,11 @@ func (e *Executor) execSingleStatement(
 	} else {
 		switch txnState.State() {
 		case Open, AutoRetry:
+			finishStmtTrace := e.maybeStartStatementTrace(session, stmt)
 			err = e.execStmtInOpenTxn(
 				session, stmt, pinfo, firstInTxn,
 				asOfSystemTime, avoidCachedDescriptors, automaticRetryCount, res)
+			finishStmtTrace()
 		case Aborted, RestartWait:
 			err = e.execStmtInAbortedTxn(session, stmt, res)
 		case CommitWait:
@@ -1685,7 +1763,7 @@ func (e *Executor) execStmtInOpenTxn(
 	case *tree.CommitTransaction:
 		// CommitTransaction is executed fully here; there's no planNode for it
 		// and a planner is not involved at all.
-		transition = commitSQLTransaction(txnState, commit, res)
+		transition = commitSQLTransaction(txnState, &e.notifications, commit, res)
 		explicitStateTransition = true
 		return nil
 
@@ -1695,7 +1773,7 @@ func (e *Executor) execStmtInOpenTxn(
 		}
 		// ReleaseSavepoint is executed fully here; there's no planNode for it
 		// and a planner is not involved at all.
-		transition = commitSQLTransaction(txnState, release, res)
+		transition = commitSQLTransaction(txnState, &e.notifications, release, res)
 		explicitStateTransition = true
 		return nil
 
@@ -1775,6 +1853,12 @@ func (e *Executor) execStmtInOpenTxn(
 		stmt.AnonymizedStr = ps.AnonymizedStr
 	}
 
+	// The classes of statements allowed to the user are checked before
+	// planning: the statements of the other classes are not even planned.
+	if err := session.statementRestrictions.check(session.User, stmt.AST); err != nil {
+		return err
+	}
+
 	var p *planner
 	runInParallel := parallelize && !txnState.implicitTxn
 	if runInParallel {
@@ -1831,7 +1915,7 @@ func (e *Executor) execStmtInOpenTxn(
 
 	tResult := &traceResult{tag: res.PGTag(), count: -1}
 	switch res.StatementType() {
-	case tree.RowsAffected, tree.Rows:
+	case tree.RowsAffected, tree.Rows, tree.CopyOut:
 		tResult.count = res.RowsAffected()
 	}
 	sessionEventf(session, "%s done", tResult)
@@ -1911,9 +1995,13 @@ const (
 )
 
 // commitSQLTransaction executes a COMMIT or RELEASE SAVEPOINT statement. The
-// transaction is committed and the statement result is written to res.
+// transaction is committed, its notifications are published and the statement
+// result is written to res.
 func commitSQLTransaction(
-	txnState *txnState, commitType commitType, res StatementResult,
+	txnState *txnState,
+	notifications *notificationRegistry,
+	commitType commitType,
+	res StatementResult,
 ) stateTransition {
 
 	if !txnState.TxnIsOpen() {
@@ -1935,6 +2023,9 @@ func commitSQLTransaction(
 		}
 	}
 
+	notifications.publish(txnState.Ctx, txnState.notifications)
+	txnState.notifications = nil
+
 	var transition stateTransition
 	switch commitType {
 	case release:
@@ -2019,7 +2110,7 @@ func (e *Executor) execClassic(
 		}
 		rowResultWriter.IncrementRowsAffected(count)
 
-	case tree.Rows:
+	case tree.Rows, tree.CopyOut:
 		err := forEachRow(params, plan, func(values tree.Datums) error {
 			for _, val := range values {
 				if err := checkResultType(val.ResolvedType()); err != nil {
@@ -2097,6 +2188,11 @@ func (e *Executor) shouldUseDistSQL(planner *planner, plan planNode) (bool, erro
 	// set.
 	if planner.txn.AnchorKey() != nil {
 		err = errors.New("writing txn")
+	} else if planner.session.StatementMaxRowsRead > 0 {
+		// Rows read by remote table readers are not accounted for.
+		err = errors.New("statement_max_rows_read is set")
+	} else if hasRowsReadQuota(planner.session.roleQuotas) {
+		err = errors.New("subject to a max_rows_read_per_second quota")
 	} else {
 		// Trigger limit propagation.
 		planner.setUnlimited(plan)
@@ -2126,7 +2222,7 @@ func (e *Executor) shouldUseDistSQL(planner *planner, plan planNode) (bool, erro
 func initStatementResult(res StatementResult, stmt Statement, plan planNode) error {
 	stmtAst := stmt.AST
 	res.BeginResult(stmtAst)
-	if stmtAst.StatementType() == tree.Rows {
+	if typ := stmtAst.StatementType(); typ == tree.Rows || typ == tree.CopyOut {
 		columns := planColumns(plan)
 		res.SetColumns(columns)
 		for _, c := range columns {
@@ -2146,12 +2242,20 @@ func (e *Executor) execStmt(
 	session := planner.session
 	ctx := session.Ctx()
 
+	// The statements matching a statement diagnostics request are traced
+	// from their planning on.
+	diagnostics := startStmtDiagnostics(session, stmt)
+	defer diagnostics.abandon()
+
 	planner.phaseTimes[plannerStartLogicalPlan] = timeutil.Now()
 	plan, err := planner.makePlan(ctx, stmt)
 	planner.phaseTimes[plannerEndLogicalPlan] = timeutil.Now()
+	e.recordFeatureUsage(planner, stmt)
 	if err != nil {
+		planner.maybeAuditStatement(ctx, stmt, 0 /* rows */, err)
 		return err
 	}
+	e.recordIndexUsage(ctx, plan)
 
 	defer plan.Close(ctx)
 
@@ -2169,23 +2273,42 @@ func (e *Executor) execStmt(
 		e.cfg.TestingKnobs.BeforeExecute(ctx, stmt.String(), false /* isParallel */)
 	}
 
+	// Statements wait for their role quotas before waiting for admission, so
+	// that throttled statements don't hold admission slots.
+	releaseQuotas, err := e.roleQuotas.admit(ctx, session.roleQuotas)
+	if err != nil {
+		return err
+	}
+	release, err := e.admitStatement(session, useDistSQL)
+	if err != nil {
+		releaseQuotas(0)
+		return err
+	}
+
 	planner.phaseTimes[plannerStartExecStmt] = timeutil.Now()
 	session.setQueryExecutionMode(stmt.queryID, useDistSQL, false /* isParallel */)
+	stopTimer := startStatementTimer(session, stmt.queryMeta)
 	if useDistSQL {
 		err = e.execDistSQL(planner, plan, res)
 	} else {
 		err = e.execClassic(planner, plan, res)
 	}
+	err = stopTimer(err)
 	planner.phaseTimes[plannerEndExecStmt] = timeutil.Now()
+	release()
+	releaseQuotas(planner.rowsRead)
 	e.recordStatementSummary(
-		planner, stmt, useDistSQL, automaticRetryCount, res, err,
+		planner, stmt, plan, useDistSQL, automaticRetryCount, res, err,
 	)
+	diagnostics.finish(planner, stmt, plan, err)
+	planner.maybeAuditStatement(ctx, stmt, res.RowsAffected(), err)
 	if e.cfg.TestingKnobs.AfterExecute != nil {
 		e.cfg.TestingKnobs.AfterExecute(ctx, stmt.String(), res, err)
 	}
 	if err != nil {
 		return err
 	}
+	planner.sendNotices(res)
 	return res.CloseResult()
 }
 
@@ -2210,9 +2333,12 @@ func (e *Executor) execStmtInParallel(
 	}
 
 	plan, err := planner.makePlan(ctx, stmt)
+	e.recordFeatureUsage(planner, stmt)
 	if err != nil {
+		planner.maybeAuditStatement(ctx, stmt, 0 /* rows */, err)
 		return err
 	}
+	e.recordIndexUsage(ctx, plan)
 
 	err = initStatementResult(res, stmt, plan)
 	if err != nil {
@@ -2224,10 +2350,16 @@ func (e *Executor) execStmtInParallel(
 	session.setQueryExecutionMode(stmt.queryID, false /* isDistributed */, true /* isParallel */)
 
 	if err := session.parallelizeQueue.Add(params, plan, func(plan planNode) error {
+		releaseQuotas, err := e.roleQuotas.admit(ctx, session.roleQuotas)
+		if err != nil {
+			return err
+		}
+		defer func() { releaseQuotas(planner.rowsRead) }()
+
 		// TODO(andrei): this should really be a result writer implementation that
 		// does nothing.
 		bufferedWriter := newBufferedWriter(session.makeBoundAccount())
-		err := initStatementResult(bufferedWriter, stmt, plan)
+		err = initStatementResult(bufferedWriter, stmt, plan)
 		if err != nil {
 			return err
 		}
@@ -2239,7 +2371,8 @@ func (e *Executor) execStmtInParallel(
 		planner.phaseTimes[plannerStartExecStmt] = timeutil.Now()
 		err = e.execClassic(planner, plan, bufferedWriter)
 		planner.phaseTimes[plannerEndExecStmt] = timeutil.Now()
-		e.recordStatementSummary(planner, stmt, false, 0, bufferedWriter, err)
+		e.recordStatementSummary(planner, stmt, plan, false, 0, bufferedWriter, err)
+		planner.maybeAuditStatement(ctx, stmt, bufferedWriter.RowsAffected(), err)
 		if e.cfg.TestingKnobs.AfterExecute != nil {
 			e.cfg.TestingKnobs.AfterExecute(ctx, stmt.String(), bufferedWriter, err)
 		}
@@ -2252,6 +2385,7 @@ func (e *Executor) execStmtInParallel(
 		return err
 	}
 
+	planner.sendNotices(res)
 	return res.CloseResult()
 }
 
@@ -2440,6 +2574,10 @@ func EvalAsOfTimestamp(
 		ts.WallTime = int64(*d)
 	case *tree.DDecimal:
 		ts, convErr = decimalToHLC(&d.Decimal)
+	case *tree.DTimestamp:
+		ts.WallTime = d.Time.UnixNano()
+	case *tree.DTimestampTZ:
+		ts.WallTime = d.Time.UnixNano()
 	default:
 		convErr = errors.Errorf("AS OF SYSTEM TIME: expected timestamp, got %s (%T)", d.ResolvedType(), d)
 	}
@@ -2489,50 +2627,60 @@ func decimalToHLC(d *apd.Decimal) (hlc.Timestamp, error) {
 	}, nil
 }
 
-// isAsOf analyzes a select statement to bypass the logic in newPlan(),
+// isAsOf analyzes a SELECT or SHOW DUMP statement to bypass the logic in newPlan(),
 // since that requires the transaction to be started already. If the returned
 // timestamp is not nil, it is the timestamp to which a transaction should
 // be set.
 //
-// max is a lower bound on what the transaction's timestamp will be. Used to
-// check that the user didn't specify a timestamp in the future.
-func isAsOf(session *Session, stmt tree.Statement, max hlc.Timestamp) (*hlc.Timestamp, error) {
-	if ts, err := isShowTraceForAsOf(session, stmt, max); ts != nil || err != nil {
+// txnTimestamp is the SQL timestamp of the transaction, used by the functions
+// of the current time. max is a lower bound on what the transaction's
+// timestamp will be. Used to check that the user didn't specify a timestamp
+// in the future.
+func isAsOf(
+	session *Session, stmt tree.Statement, txnTimestamp time.Time, max hlc.Timestamp,
+) (*hlc.Timestamp, error) {
+	if ts, err := isShowTraceForAsOf(session, stmt, txnTimestamp, max); ts != nil || err != nil {
 		return ts, err
 	}
 
-	s, ok := stmt.(*tree.Select)
-	if !ok {
-		return nil, nil
-	}
-
-	selStmt := s.Select
-	var parenSel *tree.ParenSelect
-	for parenSel, ok = selStmt.(*tree.ParenSelect); ok; parenSel, ok = selStmt.(*tree.ParenSelect) {
-		selStmt = parenSel.Select.Select
-	}
+	var asOf tree.AsOfClause
+	switch s := stmt.(type) {
+	case *tree.Select:
+		selStmt := s.Select
+		var parenSel *tree.ParenSelect
+		var ok bool
+		for parenSel, ok = selStmt.(*tree.ParenSelect); ok; parenSel, ok = selStmt.(*tree.ParenSelect) {
+			selStmt = parenSel.Select.Select
+		}
 
-	sc, ok := selStmt.(*tree.SelectClause)
-	if !ok {
+		sc, ok := selStmt.(*tree.SelectClause)
+		if !ok || sc.From == nil {
+			return nil, nil
+		}
+		asOf = sc.From.AsOf
+	case *tree.ShowDump:
+		asOf = s.AsOf
+	default:
 		return nil, nil
 	}
-	if sc.From == nil || sc.From.AsOf.Expr == nil {
+	if asOf.Expr == nil {
 		return nil, nil
 	}
 
-	evalCtx := session.evalCtx()
-	ts, err := EvalAsOfTimestamp(&evalCtx, sc.From.AsOf, max)
+	p := session.newPlanner(nil /* e */, nil /* txn */)
+	p.evalCtx.SetTxnTimestamp(txnTimestamp)
+	ts, err := EvalAsOfTimestamp(&p.evalCtx, asOf, max)
 	return &ts, err
 }
 
 func isShowTraceForAsOf(
-	session *Session, stmt tree.Statement, max hlc.Timestamp,
+	session *Session, stmt tree.Statement, txnTimestamp time.Time, max hlc.Timestamp,
 ) (*hlc.Timestamp, error) {
 	stf, ok := stmt.(*tree.ShowTrace)
 	if !ok {
 		return nil, nil
 	}
-	return isAsOf(session, stf.Statement, max)
+	return isAsOf(session, stf.Statement, txnTimestamp, max)
 }
 
 // isSavepoint returns true if stmt is a SAVEPOINT statement.
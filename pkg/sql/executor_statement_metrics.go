@@ -63,6 +63,7 @@ type phaseTimes [sessionNumPhases]time.Time
 // recordStatementSummery gathers various details pertaining to the
 // last executed statement/query and performs the associated
 // accounting.
+// - plan is the plan of the query.
 // - distSQLUsed reports whether the query was distributed.
 // - automaticRetryCount is the count of implicit txn retries
 //   so far.
@@ -71,6 +72,7 @@ type phaseTimes [sessionNumPhases]time.Time
 func (e *Executor) recordStatementSummary(
 	planner *planner,
 	stmt Statement,
+	plan planNode,
 	distSQLUsed bool,
 	automaticRetryCount int,
 	resultWriter StatementResult,
@@ -117,6 +119,10 @@ func (e *Executor) recordStatementSummary(
 		stmt, distSQLUsed, automaticRetryCount, numRows, err,
 		parseLat, planLat, runLat, svcLat, execOverhead,
 	)
+	e.maybeLogSlowQuery(
+		planner.session.Ctx(), planner, stmt, plan, distSQLUsed, automaticRetryCount, numRows, err,
+		parseLat, planLat, runLat, svcLat, execOverhead,
+	)
 
 	if log.V(2) {
 		// ages since significant epochs
@@ -22,6 +22,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 )
 
 // expandPlan finalizes type checking of placeholders and expands
@@ -77,6 +78,12 @@ func doExpandPlan(
 			return plan, err
 		}
 
+	case *explainDebugNode:
+		n.plan, err = doExpandPlan(ctx, p, noParams, n.plan)
+		if err != nil {
+			return plan, err
+		}
+
 	case *explainPlanNode:
 		if n.expanded {
 			n.plan, err = doExpandPlan(ctx, p, noParams, n.plan)
@@ -152,10 +159,25 @@ func doExpandPlan(
 
 	case *groupNode:
 		params.desiredOrdering = n.desiredOrdering
+		if len(params.desiredOrdering) == 0 && n.numGroupCols > 0 {
+			// Ask for an ordering on the grouping columns. If the source can
+			// provide one (e.g. via an index), the groupNode can aggregate one
+			// group at a time; otherwise this only influences the choice between
+			// otherwise comparable indexes.
+			params.desiredOrdering = make(sqlbase.ColumnOrdering, n.numGroupCols)
+			for i := range params.desiredOrdering {
+				params.desiredOrdering[i] = sqlbase.ColumnOrderInfo{
+					ColIdx: i, Direction: encoding.Ascending,
+				}
+			}
+		}
 		// Under a group node, there may be arbitrarily more rows
 		// than those required by the context.
 		params.numRowsHint = math.MaxInt64
 		n.plan, err = doExpandPlan(ctx, p, params, n.plan)
+		if err != nil {
+			return plan, err
+		}
 
 		if len(n.desiredOrdering) > 0 {
 			match := planPhysicalProps(n.plan).computeMatch(n.desiredOrdering)
@@ -167,6 +189,17 @@ func doExpandPlan(
 			}
 		}
 
+		if n.numGroupCols > 0 {
+			groupCols := make([]int, n.numGroupCols)
+			for i := range groupCols {
+				groupCols[i] = i
+			}
+			// Note that simplifyOrderings may later trim the ordering claimed by
+			// the source; this only affects how DistSQL plans the query. The local
+			// execution of the source still produces rows in this order.
+			_, n.isStreaming = planPhysicalProps(n.plan).computeGroupingOrdering(groupCols)
+		}
+
 	case *windowNode:
 		n.plan, err = doExpandPlan(ctx, p, noParams, n.plan)
 
@@ -254,8 +287,13 @@ func doExpandPlan(
 	case *valuesNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
+	case *alterUserSetConnectionLimitNode:
 	case *alterUserSetPasswordNode:
+	case *alterUserSetQuotaNode:
+	case *alterUserSetStatementsNode:
+	case *alterUserSetValidUntilNode:
 	case *cancelQueryNode:
+	case *cancelSessionNode:
 	case *scrubNode:
 	case *controlJobNode:
 	case *copyNode:
@@ -273,6 +311,7 @@ func doExpandPlan(
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
+	case *fetchNode:
 	case *valueGenerator:
 	case *setNode:
 	case *setClusterSettingNode:
@@ -457,6 +496,9 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *traceNode:
 		n.plan = p.simplifyOrderings(n.plan, nil)
 
+	case *explainDebugNode:
+		n.plan = p.simplifyOrderings(n.plan, nil)
+
 	case *explainPlanNode:
 		if n.expanded {
 			n.plan = p.simplifyOrderings(n.plan, nil)
@@ -584,8 +626,13 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *valuesNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
+	case *alterUserSetConnectionLimitNode:
 	case *alterUserSetPasswordNode:
+	case *alterUserSetQuotaNode:
+	case *alterUserSetStatementsNode:
+	case *alterUserSetValidUntilNode:
 	case *cancelQueryNode:
+	case *cancelSessionNode:
 	case *scrubNode:
 	case *controlJobNode:
 	case *copyNode:
@@ -603,6 +650,7 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
+	case *fetchNode:
 	case *valueGenerator:
 	case *setNode:
 	case *setClusterSettingNode:
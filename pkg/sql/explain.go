@@ -35,11 +35,15 @@ const (
 	// query would be run in "auto" DISTSQL mode. See explainDistSQLNode for
 	// details.
 	explainDistSQL
+	// explainDDL validates a schema change and describes the work it would
+	// perform without running it. See explainDDL for details.
+	explainDDL
 )
 
 var explainStrings = map[explainMode]string{
 	explainPlan:    "plan",
 	explainDistSQL: "distsql",
+	explainDDL:     "ddl",
 }
 
 // Explain executes the explain statement, providing debugging and analysis
@@ -47,6 +51,10 @@ var explainStrings = map[explainMode]string{
 //
 // Privileges: the same privileges as the statement being explained.
 func (p *planner) Explain(ctx context.Context, n *tree.Explain) (planNode, error) {
+	if n.Analyze {
+		return p.explainAnalyze(ctx, n)
+	}
+
 	mode := explainNone
 
 	optimized := true
@@ -137,6 +145,11 @@ func (p *planner) Explain(ctx context.Context, n *tree.Explain) (planNode, error
 			plan: plan,
 		}, nil
 
+	case explainDDL:
+		// The plan of the schema change is never run.
+		defer plan.Close(ctx)
+		return p.explainDDL(ctx, n.Statement, plan)
+
 	case explainPlan:
 		// We may want to show placeholder types, so ensure no values
 		// are missing.
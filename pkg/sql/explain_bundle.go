@@ -0,0 +1,468 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// StatementBundlePath is the path of the HTTP endpoint serving the bundles
+// collected by EXPLAIN ANALYZE (DEBUG), followed by the ID of a bundle.
+const StatementBundlePath = "/_admin/v1/stmtbundle/"
+
+// explainDebugNode is the planNode of EXPLAIN ANALYZE (DEBUG). It executes
+// the wrapped plan with tracing and stores a bundle of the diagnostics of the
+// statement, a zip archive with its SQL, environment, schema, statistics,
+// plan and trace, in system.statement_bundles. It returns the ID of the
+// bundle and the URL it can be downloaded from.
+type explainDebugNode struct {
+	optColumnsSlot
+
+	stmt tree.Statement
+	plan planNode
+
+	// The single row returned by the node.
+	values tree.Datums
+
+	// done is set if Next() was called.
+	done bool
+}
+
+var explainDebugColumns = sqlbase.ResultColumns{
+	{Name: "bundle_id", Typ: types.Int},
+	{Name: "url", Typ: types.String},
+}
+
+var errExplainDebugTracing = errors.New(
+	"cannot run EXPLAIN ANALYZE (DEBUG) while session tracing is enabled")
+
+// explainAnalyze plans EXPLAIN ANALYZE, whose only supported mode is DEBUG.
+//
+// Privileges: the same privileges as the statement being explained, and
+// membership of the admin role since the bundle contains the cluster
+// settings.
+func (p *planner) explainAnalyze(ctx context.Context, n *tree.Explain) (planNode, error) {
+	if len(n.Options) != 1 || strings.ToLower(n.Options[0]) != "debug" {
+		return nil, fmt.Errorf("EXPLAIN ANALYZE only supports the DEBUG option")
+	}
+	if err := p.requireAdminRole(ctx, "collect statement bundles"); err != nil {
+		return nil, err
+	}
+	plan, err := p.newPlan(ctx, n.Statement, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &explainDebugNode{stmt: n.Statement, plan: plan}, nil
+}
+
+func (n *explainDebugNode) Start(params runParams) error {
+	p := params.p
+	if p.session.Tracing.Enabled() {
+		return errExplainDebugTracing
+	}
+
+	var b statementBundleBuilder
+	if err := p.addBundlePlan(params.ctx, &b, n.stmt, n.plan); err != nil {
+		return err
+	}
+
+	// Run the statement with the session tracing enabled, as SHOW TRACE FOR
+	// does. Like it, the errors of the execution are reported in the bundle
+	// rather than returned.
+	if err := p.session.Tracing.StartTracing(
+		tracing.SnowballRecording, true, /* kvTracingEnabled */
+	); err != nil {
+		return err
+	}
+	execErr := n.execute(params)
+	if err := stopTracing(p.session); err != nil {
+		return err
+	}
+	if err := p.addBundleExecution(&b, execErr); err != nil {
+		return err
+	}
+
+	bundle, err := b.finish()
+	if err != nil {
+		return err
+	}
+	id, err := p.insertStatementBundle(params.ctx, tree.AsString(n.stmt), bundle)
+	if err != nil {
+		return err
+	}
+	url := StatementBundlePath + fmt.Sprint(id)
+	if adminURL := p.ExecCfg().AdminURL; adminURL != nil {
+		url = strings.TrimSuffix(adminURL().String(), "/") + url
+	}
+	n.values = tree.Datums{
+		tree.NewDInt(tree.DInt(id)),
+		tree.NewDString(url),
+	}
+	return nil
+}
+
+// execute runs the wrapped plan to completion, discarding its results, and
+// releases it.
+func (n *explainDebugNode) execute(params runParams) error {
+	defer func() {
+		n.plan.Close(params.ctx)
+		n.plan = nil
+	}()
+	ctx, sp := tracing.ChildSpan(params.ctx, "executing statement")
+	defer sp.Finish()
+	params.ctx = ctx
+	if err := n.plan.Start(params); err != nil {
+		return err
+	}
+	if a, ok := n.plan.(planNodeFastPath); ok {
+		if count, res := a.FastPathResults(); res {
+			log.VEventf(ctx, 2, "fast path - rows affected: %d", count)
+			return nil
+		}
+	}
+	for {
+		hasNext, err := n.plan.Next(params)
+		if err != nil || !hasNext {
+			return err
+		}
+	}
+}
+
+func (n *explainDebugNode) Next(runParams) (bool, error) {
+	if n.done {
+		return false, nil
+	}
+	n.done = true
+	return true, nil
+}
+
+func (n *explainDebugNode) Values() tree.Datums {
+	return n.values
+}
+
+func (n *explainDebugNode) Close(ctx context.Context) {
+	if n.plan != nil {
+		n.plan.Close(ctx)
+	}
+}
+
+// statementBundleBuilder accumulates the files of a bundle.
+type statementBundleBuilder struct {
+	buf bytes.Buffer
+	z   *zip.Writer
+	err error
+}
+
+func (b *statementBundleBuilder) addFile(name, contents string) {
+	if b.err != nil {
+		return
+	}
+	if b.z == nil {
+		b.z = zip.NewWriter(&b.buf)
+	}
+	w, err := b.z.Create(name)
+	if err != nil {
+		b.err = err
+		return
+	}
+	_, b.err = w.Write([]byte(contents))
+}
+
+// finish returns the zip archive of the files of the bundle.
+func (b *statementBundleBuilder) finish() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if err := b.z.Close(); err != nil {
+		return nil, err
+	}
+	return b.buf.Bytes(), nil
+}
+
+// addBundlePlan adds to a bundle the files describing a statement before its
+// execution: its SQL, the session variables, the schema and statistics of the
+// tables it uses, the cluster settings and its plan.
+func (p *planner) addBundlePlan(
+	ctx context.Context, b *statementBundleBuilder, stmt tree.Statement, plan planNode,
+) error {
+	b.addFile("statement.sql", tree.AsString(stmt)+";\n")
+	b.addFile("env.txt", p.bundleEnv())
+	tables := bundleTables(ctx, plan)
+	schema, err := p.bundleSchema(ctx, tables)
+	if err != nil {
+		return err
+	}
+	b.addFile("schema.sql", schema)
+	if err := p.bundleClusterData(ctx, b, tables); err != nil {
+		return err
+	}
+	b.addFile("plan.txt", planToString(ctx, plan))
+	return nil
+}
+
+// addBundleExecution adds to a bundle the session trace of the execution of
+// a statement, which must have been stopped, and the error of the execution
+// if any.
+func (p *planner) addBundleExecution(b *statementBundleBuilder, execErr error) error {
+	traceRows, err := p.session.Tracing.generateSessionTraceVTable()
+	if err != nil {
+		return err
+	}
+	b.addFile("trace.txt", formatBundleTrace(traceRows))
+	if execErr != nil {
+		b.addFile("error.txt", execErr.Error()+"\n")
+	}
+	return nil
+}
+
+// bundleEnv returns the version of the node and the session variables.
+func (p *planner) bundleEnv() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "version: %s\n", build.GetInfo().Short())
+	fmt.Fprintf(&buf, "node: %d\n", p.evalCtx.NodeID)
+	buf.WriteString("\nsession variables:\n")
+	for _, vName := range varNames {
+		fmt.Fprintf(&buf, "%s = %s\n", vName, varGen[vName].Get(p.session))
+	}
+	return buf.String()
+}
+
+// bundleTables returns the descriptors of the tables read or written by the
+// plan, sorted by ID.
+func bundleTables(ctx context.Context, plan planNode) []*sqlbase.TableDescriptor {
+	seen := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
+	_ = walkPlan(ctx, plan, planObserver{
+		enterNode: func(_ context.Context, _ string, plan planNode) bool {
+			var desc *sqlbase.TableDescriptor
+			switch n := plan.(type) {
+			case *scanNode:
+				desc = n.desc
+			case *insertNode:
+				desc = n.tableDesc
+			case *updateNode:
+				desc = n.tableDesc
+			case *deleteNode:
+				desc = n.tableDesc
+			}
+			if desc != nil && !desc.IsVirtualTable() {
+				seen[desc.ID] = desc
+			}
+			return true
+		},
+	})
+	tables := make([]*sqlbase.TableDescriptor, 0, len(seen))
+	for _, desc := range seen {
+		tables = append(tables, desc)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].ID < tables[j].ID })
+	return tables
+}
+
+// bundleSchema returns the statements creating the given tables, each
+// preceded by the creation of its database, so that the schema can be
+// recreated by running them.
+func (p *planner) bundleSchema(
+	ctx context.Context, tables []*sqlbase.TableDescriptor,
+) (string, error) {
+	var buf bytes.Buffer
+	for _, desc := range tables {
+		db, err := sqlbase.GetDatabaseDescFromID(ctx, p.txn, desc.ParentID)
+		if err != nil {
+			return "", err
+		}
+		var stmt string
+		if desc.IsView() {
+			stmt, err = p.showCreateView(ctx, tree.Name(desc.Name), desc)
+		} else {
+			stmt, err = p.showCreateTable(ctx, tree.Name(desc.Name), db.Name, desc)
+		}
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&buf, "CREATE DATABASE IF NOT EXISTS %s;\nSET database = %s;\n%s;\n\n",
+			tree.Name(db.Name), tree.Name(db.Name), stmt)
+	}
+	return buf.String(), nil
+}
+
+// bundleClusterData adds to the bundle the cluster settings which were
+// changed and the statistics of the given tables. They are read in their own
+// transaction, which doesn't conflict with the statement.
+func (p *planner) bundleClusterData(
+	ctx context.Context, b *statementBundleBuilder, tables []*sqlbase.TableDescriptor,
+) error {
+	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	return p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		rows, err := ie.QueryRowsInTransaction(
+			ctx, "statement-bundle-settings", txn,
+			`SELECT name, value FROM system.settings ORDER BY name`,
+		)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		for _, row := range rows {
+			fmt.Fprintf(&buf, "%s = %s\n",
+				tree.AsStringWithFlags(row[0], tree.FmtBareStrings),
+				tree.AsStringWithFlags(row[1], tree.FmtBareStrings))
+		}
+		b.addFile("settings.txt", buf.String())
+
+		for _, desc := range tables {
+			if !desc.IsTable() {
+				continue
+			}
+			jsonStats, err := tableStatsJSON(ctx, ie, txn, desc)
+			if err != nil {
+				return err
+			}
+			j, err := json.MarshalIndent(jsonStats, "", "  ")
+			if err != nil {
+				return err
+			}
+			b.addFile(fmt.Sprintf("stats-%s.json", desc.Name), string(j)+"\n")
+		}
+		return nil
+	})
+}
+
+// tableStatsJSON returns the statistics of a table in the form accepted by
+// ALTER TABLE ... INJECT STATISTICS.
+func tableStatsJSON(
+	ctx context.Context, ie InternalExecutor, txn *client.Txn, desc *sqlbase.TableDescriptor,
+) ([]stats.JSONStatistic, error) {
+	rows, err := ie.QueryRowsInTransaction(
+		ctx, "statement-bundle-stats", txn,
+		`SELECT name, "columnIDs", "createdAt", "rowCount", "distinctCount", "nullCount", histogram
+		   FROM system.table_statistics WHERE "tableID" = $1 ORDER BY "createdAt"`,
+		desc.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]stats.JSONStatistic, 0, len(rows))
+	var a sqlbase.DatumAlloc
+	for _, row := range rows {
+		s := stats.JSONStatistic{
+			CreatedAt:     tree.AsStringWithFlags(row[2], tree.FmtBareStrings),
+			RowCount:      uint64(tree.MustBeDInt(row[3])),
+			DistinctCount: uint64(tree.MustBeDInt(row[4])),
+			NullCount:     uint64(tree.MustBeDInt(row[5])),
+		}
+		if row[0] != tree.DNull {
+			s.Name = string(tree.MustBeDString(row[0]))
+		}
+		var cols []sqlbase.ColumnDescriptor
+		for _, d := range tree.MustBeDArray(row[1]).Array {
+			col, err := desc.FindColumnByID(sqlbase.ColumnID(tree.MustBeDInt(d)))
+			if err != nil {
+				return nil, err
+			}
+			s.Columns = append(s.Columns, col.Name)
+			cols = append(cols, *col)
+		}
+		if row[6] != tree.DNull && len(cols) == 1 {
+			var h stats.HistogramData
+			if err := protoutil.Unmarshal([]byte(*row[6].(*tree.DBytes)), &h); err != nil {
+				return nil, err
+			}
+			for _, bucket := range h.Buckets {
+				upperBound, _, err := sqlbase.DecodeTableKey(
+					&a, cols[0].Type.ToDatumType(), bucket.UpperBound, encoding.Ascending,
+				)
+				if err != nil {
+					return nil, err
+				}
+				s.HistogramBuckets = append(s.HistogramBuckets, stats.JSONHistoBucket{
+					NumEq:      bucket.NumEq,
+					NumRange:   bucket.NumRange,
+					UpperBound: tree.AsStringWithFlags(upperBound, tree.FmtBareStrings),
+				})
+			}
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// formatBundleTrace formats the rows of the session trace, as shown by
+// SHOW TRACE FOR, one message per line.
+func formatBundleTrace(rows []traceRow) string {
+	var buf bytes.Buffer
+	for _, row := range rows {
+		// Skip the indexes of the transaction, span and message.
+		for i, d := range row[3:] {
+			if i > 0 {
+				buf.WriteByte('\t')
+			}
+			if d != tree.DNull {
+				buf.WriteString(tree.AsStringWithFlags(d, tree.FmtBareStrings))
+			}
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// insertStatementBundle stores a bundle in system.statement_bundles and
+// returns its ID. The bundle is stored in its own transaction, so that it is
+// kept even if the transaction of the statement is rolled back.
+func (p *planner) insertStatementBundle(
+	ctx context.Context, stmt string, bundle []byte,
+) (int64, error) {
+	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	var id int64
+	err := p.ExecCfg().DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		id, err = insertStatementBundleInTxn(ctx, ie, txn, stmt, bundle)
+		return err
+	})
+	return id, err
+}
+
+// insertStatementBundleInTxn stores a bundle in system.statement_bundles in
+// the given transaction and returns its ID.
+func insertStatementBundleInTxn(
+	ctx context.Context, ie InternalExecutor, txn *client.Txn, stmt string, bundle []byte,
+) (int64, error) {
+	row, err := ie.QueryRowInTransaction(
+		ctx, "insert-statement-bundle", txn,
+		`INSERT INTO system.statement_bundles (statement, bundle) VALUES ($1, $2) RETURNING id`,
+		stmt, bundle,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int64(tree.MustBeDInt(row[0])), nil
+}
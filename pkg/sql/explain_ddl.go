@@ -0,0 +1,407 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+var explainDDLColumns = sqlbase.ResultColumns{
+	// Object is the schema object changed by a step.
+	{Name: "Object", Typ: types.String},
+	// Operation is the change made to the object.
+	{Name: "Operation", Typ: types.String},
+	// Backfill is the work performed on the data of the table, if any.
+	{Name: "Backfill", Typ: types.String},
+	// Estimated Rows is the number of rows the backfill reads or rewrites,
+	// estimated from the range statistics of the table.
+	{Name: "Estimated Rows", Typ: types.Int},
+	// Blocking describes the impact of the step on concurrent statements.
+	{Name: "Blocking", Typ: types.String},
+}
+
+// The kinds of work performed on the data of a table by a schema change.
+const (
+	ddlBackfillNone       = "none"
+	ddlBackfillColumn     = "column backfill"
+	ddlBackfillIndex      = "index backfill"
+	ddlBackfillIndexDrop  = "index truncation"
+	ddlBackfillValidation = "validation scan"
+	ddlBackfillDrop       = "table deletion"
+)
+
+// The impacts of a schema change on concurrent statements.
+const (
+	// ddlBlockingNone is used for changes to descriptors only, which
+	// concurrent statements observe once their leases are released.
+	ddlBlockingNone = "none"
+	// ddlBlockingOnline is used for backfills, which run in the background
+	// while the table remains readable and writable. The statement returns
+	// once they are done.
+	ddlBlockingOnline = "online"
+	// ddlBlockingTxn is used for scans performed within the transaction of
+	// the statement.
+	ddlBlockingTxn = "in transaction"
+	// ddlBlockingDrop is used for objects that become unavailable
+	// immediately.
+	ddlBlockingDrop = "object unavailable"
+)
+
+// ddlStep describes a change performed by a schema change statement.
+type ddlStep struct {
+	object    string
+	operation string
+	backfill  string
+	blocking  string
+	// table is the table whose rows are backfilled, if any.
+	table *sqlbase.TableDescriptor
+}
+
+// explainDDL implements EXPLAIN (DDL). The plan of the statement was built,
+// which resolved the objects it names and checked the privileges of the
+// user, but it is not run: the steps of the schema change are derived from
+// the statement and the current descriptors, and no descriptor is modified.
+func (p *planner) explainDDL(
+	ctx context.Context, stmt tree.Statement, plan planNode,
+) (planNode, error) {
+	if stmt.StatementType() != tree.DDL {
+		return nil, fmt.Errorf("EXPLAIN (DDL) only supports schema changes, not %s", stmt.StatementTag())
+	}
+
+	var steps []ddlStep
+	var err error
+	switch n := plan.(type) {
+	case *alterTableNode:
+		steps, err = p.explainAlterTable(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+
+	case *createIndexNode:
+		if _, dropped, err := n.tableDesc.FindIndexByName(string(n.n.Name)); err == nil {
+			if dropped {
+				return nil, fmt.Errorf("index %q being dropped, try again later", n.n.Name)
+			}
+			if !n.n.IfNotExists {
+				return nil, fmt.Errorf("duplicate index name: %q", n.n.Name)
+			}
+			break
+		}
+		steps = append(steps, ddlStep{
+			object:    ddlIndexObject(n.tableDesc.Name, string(n.n.Name)),
+			operation: "add index",
+			backfill:  ddlBackfillIndex,
+			blocking:  ddlBlockingOnline,
+			table:     n.tableDesc,
+		})
+
+	case *dropIndexNode:
+		for _, index := range n.idxNames {
+			tableDesc, err := getTableDesc(ctx, p.txn, p.getVirtualTabler(), index.tn)
+			if err != nil {
+				return nil, err
+			}
+			idx, dropped, err := tableDesc.FindIndexByName(string(index.idxName))
+			if err != nil {
+				if n.n.IfExists {
+					continue
+				}
+				return nil, err
+			}
+			if dropped {
+				continue
+			}
+			if idx.ForeignKey.IsSet() && n.n.DropBehavior != tree.DropCascade {
+				return nil, fmt.Errorf("index %q is in use as a foreign key constraint", idx.Name)
+			}
+			steps = append(steps, ddlStep{
+				object:    ddlIndexObject(tableDesc.Name, idx.Name),
+				operation: "drop index",
+				backfill:  ddlBackfillIndexDrop,
+				blocking:  ddlBlockingOnline,
+				table:     tableDesc,
+			})
+		}
+
+	case *dropTableNode:
+		steps = explainDropTables(n.td, "drop table")
+	case *dropViewNode:
+		steps = explainDropTables(n.td, "drop view")
+	case *dropSequenceNode:
+		steps = explainDropTables(n.td, "drop sequence")
+	case *dropDatabaseNode:
+		steps = explainDropTables(n.td, "drop")
+		steps = append(steps, ddlStep{
+			object:    fmt.Sprintf("database %s", n.dbDesc.Name),
+			operation: "drop database",
+			backfill:  ddlBackfillNone,
+			blocking:  ddlBlockingDrop,
+		})
+
+	case *createTableNode:
+		backfill := ddlBackfillNone
+		if n.n.As() {
+			// The rows of the source query are inserted by the statement.
+			backfill = "insert from query"
+		}
+		steps = append(steps, ddlStep{
+			object:    fmt.Sprintf("table %s", n.n.Table.TableName().TableName),
+			operation: "create table",
+			backfill:  backfill,
+			blocking:  ddlBlockingNone,
+		})
+
+	case *zeroNode:
+		// The statement has nothing to do, e.g. DROP ... IF EXISTS on a
+		// missing object.
+
+	default:
+		steps = append(steps, ddlStep{
+			operation: stmt.StatementTag(),
+			backfill:  ddlBackfillNone,
+			blocking:  ddlBlockingNone,
+		})
+	}
+
+	v := p.newContainerValuesNode(explainDDLColumns, len(steps))
+	estimates := make(map[sqlbase.ID]tree.Datum)
+	for _, step := range steps {
+		rows := tree.DNull
+		if step.table != nil {
+			var ok bool
+			if rows, ok = estimates[step.table.ID]; !ok {
+				rows = p.estimateBackfillRows(ctx, step.table)
+				estimates[step.table.ID] = rows
+			}
+		}
+		if _, err := v.rows.AddRow(ctx, tree.Datums{
+			tree.NewDString(step.object),
+			tree.NewDString(step.operation),
+			tree.NewDString(step.backfill),
+			rows,
+			tree.NewDString(step.blocking),
+		}); err != nil {
+			v.Close(ctx)
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// explainAlterTable validates the commands of an ALTER TABLE statement
+// against the descriptor of the table and describes them.
+func (p *planner) explainAlterTable(ctx context.Context, n *alterTableNode) ([]ddlStep, error) {
+	tableDesc := n.tableDesc
+	var steps []ddlStep
+	for _, cmd := range n.n.Cmds {
+		switch t := cmd.(type) {
+		case *tree.AlterTableAddColumn:
+			d := t.ColumnDef
+			col, idx, err := sqlbase.MakeColumnDefDescs(d, &p.semaCtx, &p.evalCtx)
+			if err != nil {
+				return nil, err
+			}
+			if _, dropped, err := tableDesc.FindColumnByName(d.Name); err == nil {
+				if dropped {
+					return nil, fmt.Errorf("column %q being dropped, try again later", col.Name)
+				}
+				if t.IfNotExists {
+					continue
+				}
+				return nil, fmt.Errorf("duplicate column name: %q", col.Name)
+			}
+			step := ddlStep{
+				object:    fmt.Sprintf("column %s.%s", tableDesc.Name, col.Name),
+				operation: "add column",
+				backfill:  ddlBackfillNone,
+				blocking:  ddlBlockingNone,
+			}
+			// The schema changer only rewrites the rows when the new column
+			// has a value.
+			if col.DefaultExpr != nil || !col.Nullable {
+				step.backfill = ddlBackfillColumn
+				step.blocking = ddlBlockingOnline
+				step.table = tableDesc
+			}
+			steps = append(steps, step)
+			if idx != nil {
+				steps = append(steps, ddlStep{
+					object:    ddlIndexObject(tableDesc.Name, idx.Name),
+					operation: "add index",
+					backfill:  ddlBackfillIndex,
+					blocking:  ddlBlockingOnline,
+					table:     tableDesc,
+				})
+			}
+			if d.HasFKConstraint() {
+				// The foreign key is placed on an index being added, and
+				// validated by the schema changer once it is backfilled.
+				steps = append(steps, ddlStep{
+					object:    fmt.Sprintf("column %s.%s", tableDesc.Name, col.Name),
+					operation: "add foreign key",
+					backfill:  ddlBackfillValidation,
+					blocking:  ddlBlockingOnline,
+					table:     tableDesc,
+				})
+			}
+
+		case *tree.AlterTableAddConstraint:
+			switch d := t.ConstraintDef.(type) {
+			case *tree.UniqueConstraintTableDef:
+				if d.PrimaryKey {
+					return nil, fmt.Errorf("multiple primary keys for table %q are not allowed", tableDesc.Name)
+				}
+				steps = append(steps, ddlStep{
+					object:    ddlIndexObject(tableDesc.Name, string(d.Name)),
+					operation: "add unique constraint",
+					backfill:  ddlBackfillIndex,
+					blocking:  ddlBlockingOnline,
+					table:     tableDesc,
+				})
+			case *tree.CheckConstraintTableDef, *tree.ForeignKeyConstraintTableDef:
+				// These constraints are added unvalidated; VALIDATE CONSTRAINT
+				// scans the table.
+				steps = append(steps, ddlStep{
+					object:    fmt.Sprintf("table %s", tableDesc.Name),
+					operation: "add constraint",
+					backfill:  ddlBackfillNone,
+					blocking:  ddlBlockingNone,
+				})
+			default:
+				return nil, fmt.Errorf("unsupported constraint: %T", t.ConstraintDef)
+			}
+
+		case *tree.AlterTableDropColumn:
+			col, dropped, err := tableDesc.FindColumnByName(t.Column)
+			if err != nil {
+				if t.IfExists {
+					continue
+				}
+				return nil, err
+			}
+			if dropped {
+				continue
+			}
+			if tableDesc.PrimaryIndex.ContainsColumnID(col.ID) {
+				return nil, fmt.Errorf("column %q is referenced by the primary key", col.Name)
+			}
+			steps = append(steps, ddlStep{
+				object:    fmt.Sprintf("column %s.%s", tableDesc.Name, col.Name),
+				operation: "drop column",
+				backfill:  ddlBackfillColumn,
+				blocking:  ddlBlockingOnline,
+				table:     tableDesc,
+			})
+
+		case *tree.AlterTableDropConstraint:
+			info, err := tableDesc.GetConstraintInfo(ctx, nil)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := info[string(t.Constraint)]; !ok {
+				if t.IfExists {
+					continue
+				}
+				return nil, fmt.Errorf("constraint %q does not exist", t.Constraint)
+			}
+			steps = append(steps, ddlStep{
+				object:    fmt.Sprintf("constraint %s on %s", t.Constraint, tableDesc.Name),
+				operation: "drop constraint",
+				backfill:  ddlBackfillNone,
+				blocking:  ddlBlockingNone,
+			})
+
+		case *tree.AlterTableValidateConstraint:
+			info, err := tableDesc.GetConstraintInfo(ctx, nil)
+			if err != nil {
+				return nil, err
+			}
+			constraint, ok := info[string(t.Constraint)]
+			if !ok {
+				return nil, fmt.Errorf("constraint %q does not exist", t.Constraint)
+			}
+			step := ddlStep{
+				object:    fmt.Sprintf("constraint %s on %s", t.Constraint, tableDesc.Name),
+				operation: "validate constraint",
+				backfill:  ddlBackfillNone,
+				blocking:  ddlBlockingNone,
+			}
+			if constraint.Unvalidated {
+				step.backfill = ddlBackfillValidation
+				step.blocking = ddlBlockingTxn
+				step.table = tableDesc
+			}
+			steps = append(steps, step)
+
+		default:
+			steps = append(steps, ddlStep{
+				object:    fmt.Sprintf("table %s", tableDesc.Name),
+				operation: tree.AsStringWithFlags(cmd, tree.FmtSimple),
+				backfill:  ddlBackfillNone,
+				blocking:  ddlBlockingNone,
+			})
+		}
+	}
+	return steps, nil
+}
+
+// explainDropTables describes the tables, views and sequences dropped by a
+// statement.
+func explainDropTables(td []*sqlbase.TableDescriptor, operation string) []ddlStep {
+	steps := make([]ddlStep, 0, len(td))
+	for _, desc := range td {
+		step := ddlStep{
+			object:    fmt.Sprintf("%s %s", desc.TypeName(), desc.Name),
+			operation: operation,
+			backfill:  ddlBackfillNone,
+			blocking:  ddlBlockingDrop,
+		}
+		if desc.IsTable() {
+			// The data of the table is deleted once the GC TTL has passed.
+			step.backfill = ddlBackfillDrop
+			step.table = desc
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// ddlIndexObject names an index in the output of EXPLAIN (DDL). The names
+// of the indexes created without one are only generated when they are
+// added to the table.
+func ddlIndexObject(tableName, indexName string) string {
+	if indexName == "" {
+		return fmt.Sprintf("new index on %s", tableName)
+	}
+	return fmt.Sprintf("index %s@%s", tableName, indexName)
+}
+
+// estimateBackfillRows estimates the number of rows of a table from its
+// range statistics, or returns NULL if they are unavailable.
+func (p *planner) estimateBackfillRows(ctx context.Context, desc *sqlbase.TableDescriptor) tree.Datum {
+	count, _, err := p.rangeStatsRowCount(ctx, desc)
+	if err != nil {
+		log.VEventf(ctx, 2, "cannot estimate the rows of %s: %v", desc.Name, err)
+		return tree.DNull
+	}
+	return tree.NewDInt(tree.DInt(count))
+}
@@ -72,8 +72,15 @@ func (v *varConvertVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree
 
 	if varExpr, ok := expr.(tree.VariableExpr); ok {
 		// Ignore sub-queries and placeholders
-		switch expr.(type) {
-		case *subquery, *tree.Placeholder:
+		switch t := expr.(type) {
+		case *subquery:
+			if t.correlation != nil {
+				// A correlated sub-query depends on the columns of the
+				// surrounding query that it refers to; recurse into them.
+				return true, expr
+			}
+			return false, expr
+		case *tree.Placeholder, *outerColumnRef:
 			return false, expr
 		}
 
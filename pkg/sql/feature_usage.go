@@ -0,0 +1,125 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// The uses of SQL features by the statements executed on this node are
+// counted per application, so that operators can find the applications which
+// still rely on deprecated syntax before it is removed. A feature is named
+// after its kind: "statement.<tag>" for the statement types,
+// "builtin.<name>" for the builtin functions and "syntax.<construct>" for
+// the syntax constructs noted by the planner. The counts are exposed in
+// crdb_internal.feature_usage and, merged across the applications, in the
+// diagnostics reports.
+
+const (
+	featureStatementPrefix = "statement."
+	featureBuiltinPrefix   = "builtin."
+	featureSyntaxPrefix    = "syntax."
+)
+
+// featureUsage counts the uses of the SQL features, per application.
+type featureUsage struct {
+	syncutil.Mutex
+	// apps maps the application names to the counts of the features used by
+	// the statements of the application.
+	apps map[string]map[string]int64
+}
+
+// featureUsageCount is the count of the uses of a feature by the statements
+// of an application.
+type featureUsageCount struct {
+	appName string
+	feature string
+	count   int64
+}
+
+// noteFeature notes the use of a syntax construct by the current statement.
+// The features noted while planning the statement are counted when the
+// statement is executed.
+func (p *planner) noteFeature(construct string) {
+	p.features = append(p.features, featureSyntaxPrefix+construct)
+}
+
+// noteBuiltin notes the use of a builtin function by the current statement.
+// It is called for each function call resolved during the type checking of
+// the statement.
+func (p *planner) noteBuiltin(name string) {
+	p.features = append(p.features, featureBuiltinPrefix+name)
+}
+
+// recordFeatureUsage counts the type of the statement and the features noted
+// while planning it.
+func (e *Executor) recordFeatureUsage(planner *planner, stmt Statement) {
+	appName := planner.session.mu.ApplicationName
+	e.featureUsage.Lock()
+	defer e.featureUsage.Unlock()
+	if e.featureUsage.apps == nil {
+		e.featureUsage.apps = make(map[string]map[string]int64)
+	}
+	counts, ok := e.featureUsage.apps[appName]
+	if !ok {
+		counts = make(map[string]int64)
+		e.featureUsage.apps[appName] = counts
+	}
+	counts[featureStatementPrefix+stmt.AST.StatementTag()]++
+	for _, f := range planner.features {
+		counts[f]++
+	}
+}
+
+// counts returns the counts of the uses of the features, sorted by
+// application name and feature.
+func (u *featureUsage) counts() []featureUsageCount {
+	var res []featureUsageCount
+	u.Lock()
+	for appName, counts := range u.apps {
+		for f, c := range counts {
+			res = append(res, featureUsageCount{appName: appName, feature: f, count: c})
+		}
+	}
+	u.Unlock()
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].appName != res[j].appName {
+			return res[i].appName < res[j].appName
+		}
+		return res[i].feature < res[j].feature
+	})
+	return res
+}
+
+// FillFeatureUsageCounts fills the passed map with the executor's current
+// counts of the uses of the SQL features, merged across the applications.
+func (e *Executor) FillFeatureUsageCounts(fill map[string]int64) {
+	e.featureUsage.Lock()
+	for _, counts := range e.featureUsage.apps {
+		for f, c := range counts {
+			fill[f] += c
+		}
+	}
+	e.featureUsage.Unlock()
+}
+
+// ResetFeatureUsageCounts resets the counts of the uses of the SQL features.
+func (e *Executor) ResetFeatureUsageCounts() {
+	e.featureUsage.Lock()
+	e.featureUsage.apps = make(map[string]map[string]int64, len(e.featureUsage.apps))
+	e.featureUsage.Unlock()
+}
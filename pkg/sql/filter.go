@@ -19,7 +19,6 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
-	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
 
 // filterNode implements a filtering stage. It is intended to be used
@@ -30,6 +29,9 @@ type filterNode struct {
 	filter     tree.TypedExpr
 	ivarHelper tree.IndexedVarHelper
 	props      physicalProps
+
+	// compiledFilter is filter compiled at Start.
+	compiledFilter tree.CompiledExpr
 }
 
 func (f *filterNode) computePhysicalProps(evalCtx *tree.EvalContext) {
@@ -54,6 +56,7 @@ func (f *filterNode) IndexedVarNodeFormatter(idx int) tree.NodeFormatter {
 
 // Start implements the planNode interface.
 func (f *filterNode) Start(params runParams) error {
+	f.compiledFilter = tree.CompileFilter(f.filter)
 	return f.source.plan.Start(params)
 }
 
@@ -65,7 +68,7 @@ func (f *filterNode) Next(params runParams) (bool, error) {
 		}
 
 		params.evalCtx.IVarHelper = &f.ivarHelper
-		passesFilter, err := sqlbase.RunFilter(f.filter, params.evalCtx)
+		passesFilter, err := tree.RunCompiledFilter(f.compiledFilter, params.evalCtx)
 		params.evalCtx.IVarHelper = nil
 		if err != nil {
 			return false, err
@@ -0,0 +1,38 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// FollowerReadTimestamp implements the tree.EvalPlanner interface. The
+// timestamp is computed from the transaction timestamp, so that all the
+// AS OF SYSTEM TIME clauses of a statement, which are evaluated several
+// times, find the same one.
+func (p *planner) FollowerReadTimestamp() (time.Time, error) {
+	now := hlc.Timestamp{WallTime: p.evalCtx.GetTxnTimestampRaw().UnixNano()}
+	ts := storagebase.FollowerReadTimestamp(&p.ExecCfg().Settings.SV, now)
+	if ts == (hlc.Timestamp{}) {
+		return time.Time{}, pgerror.NewError(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"follower reads are disabled; see the kv.follower_reads.enabled and "+
+				"kv.closed_timestamp.target_duration cluster settings")
+	}
+	return ts.GoTime(), nil
+}
@@ -17,16 +17,29 @@ package sql
 import (
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
 
+// changePrivileges applies changePrivilege to the privileges of each grantee
+// on the targets or, if columns is set, on the given columns of the target
+// tables. If withColumns is set, changePrivilege is also applied to the
+// privileges on the columns of the target tables. The privileges must be
+// valid for the types of the targets. An event of the given type is recorded
+// for each target.
 func (p *planner) changePrivileges(
 	ctx context.Context,
+	stmt tree.Statement,
+	eventType EventLogType,
 	targets tree.TargetList,
+	columns tree.NameList,
 	grantees tree.NameList,
-	changePrivilege func(*sqlbase.PrivilegeDescriptor, string),
+	privileges privilege.List,
+	changePrivilege func(*sqlbase.PrivilegeDescriptor, string, privilege.ObjectType),
+	withColumns bool,
 ) (planNode, error) {
 	descriptors, err := getDescriptorsFromTargetList(ctx, p.txn, p.getVirtualTabler(), p.session.Database, targets)
 	if err != nil {
@@ -37,9 +50,29 @@ func (p *planner) changePrivileges(
 		if err := p.CheckPrivilege(descriptor, privilege.GRANT); err != nil {
 			return nil, err
 		}
-		privileges := descriptor.GetPrivileges()
-		for _, grantee := range grantees {
-			changePrivilege(privileges, string(grantee))
+		objectType := privilege.Table
+		if _, ok := descriptor.(*sqlbase.DatabaseDescriptor); ok {
+			objectType = privilege.Database
+		}
+		if err := sqlbase.ValidatePrivileges(privileges, objectType); err != nil {
+			return nil, pgerror.NewError(pgerror.CodeInvalidGrantOperationError, err.Error())
+		}
+		if columns != nil {
+			if err := changePrivilegesOnColumns(descriptor, columns, grantees, changePrivilege); err != nil {
+				return nil, err
+			}
+		} else {
+			privs := descriptor.GetPrivileges()
+			for _, grantee := range grantees {
+				changePrivilege(privs, string(grantee), objectType)
+			}
+			if table, ok := descriptor.(*sqlbase.TableDescriptor); ok && withColumns {
+				for i := range table.Columns {
+					if table.Columns[i].Privileges != nil {
+						changeColumnPrivileges(&table.Columns[i], grantees, changePrivilege)
+					}
+				}
+			}
 		}
 
 		switch d := descriptor.(type) {
@@ -67,9 +100,91 @@ func (p *planner) changePrivileges(
 	if err := p.txn.Run(ctx, b); err != nil {
 		return nil, err
 	}
+
+	// Record the privilege changes in the event log. This is an auditable log
+	// event and is recorded in the same transaction as the descriptor updates.
+	for _, descriptor := range descriptors {
+		if err := MakeEventLogger(p.LeaseMgr()).InsertEventRecord(
+			ctx,
+			p.txn,
+			eventType,
+			int32(descriptor.GetID()),
+			int32(p.evalCtx.NodeID),
+			&sqlbase.ChangePrivilegesEvent{
+				TargetName: descriptor.GetName(),
+				Grantees:   grantees.ToStrings(),
+				Privileges: privileges.SortedNames(),
+				Statement:  stmt.String(),
+				User:       p.session.User,
+			},
+		); err != nil {
+			return nil, err
+		}
+	}
 	return &zeroNode{}, nil
 }
 
+// changePrivilegesOnColumns applies changePrivilege to the privileges of each
+// grantee on the given columns of the table.
+func changePrivilegesOnColumns(
+	descriptor sqlbase.DescriptorProto,
+	columns tree.NameList,
+	grantees tree.NameList,
+	changePrivilege func(*sqlbase.PrivilegeDescriptor, string, privilege.ObjectType),
+) error {
+	table, ok := descriptor.(*sqlbase.TableDescriptor)
+	if !ok || !table.IsTable() {
+		return pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+			"%q is not a table", descriptor.GetName())
+	}
+	for _, name := range columns {
+		found := false
+		for i := range table.Columns {
+			if col := &table.Columns[i]; col.Name == string(name) {
+				if col.Privileges == nil {
+					col.Privileges = &sqlbase.PrivilegeDescriptor{}
+				}
+				changeColumnPrivileges(col, grantees, changePrivilege)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
+				"column %q of relation %q does not exist", name, table.Name)
+		}
+	}
+	return nil
+}
+
+// changeColumnPrivileges applies changePrivilege to the privileges of each
+// grantee on the column.
+func changeColumnPrivileges(
+	col *sqlbase.ColumnDescriptor,
+	grantees tree.NameList,
+	changePrivilege func(*sqlbase.PrivilegeDescriptor, string, privilege.ObjectType),
+) {
+	for _, grantee := range grantees {
+		changePrivilege(col.Privileges, string(grantee), privilege.Table)
+	}
+	if len(col.Privileges.Users) == 0 {
+		col.Privileges = nil
+	}
+}
+
+// validateColumnPrivileges verifies that the privileges can be granted on
+// columns.
+func validateColumnPrivileges(privs privilege.List) error {
+	allowed := sqlbase.ColumnPrivileges.ToBitField()
+	for _, priv := range privs {
+		if priv.Mask()&^allowed != 0 {
+			return pgerror.NewErrorf(pgerror.CodeInvalidGrantOperationError,
+				"invalid privilege type %s for column", priv)
+		}
+	}
+	return nil
+}
+
 // Grant adds privileges to users.
 // Current status:
 // - Target: single database, table, or view.
@@ -80,12 +195,20 @@ func (p *planner) changePrivileges(
 //   Notes: postgres requires the object owner.
 //          mysql requires the "grant option" and the same privileges, and sometimes superuser.
 func (p *planner) Grant(ctx context.Context, n *tree.Grant) (planNode, error) {
-	return p.changePrivileges(ctx, n.Targets, n.Grantees, func(privDesc *sqlbase.PrivilegeDescriptor, grantee string) {
-		privDesc.Grant(grantee, n.Privileges)
-	})
+	if n.Columns != nil {
+		if err := validateColumnPrivileges(n.Privileges); err != nil {
+			return nil, err
+		}
+	}
+	return p.changePrivileges(ctx, n, EventLogGrantPrivileges,
+		n.Targets, n.Columns, n.Grantees, n.Privileges,
+		func(privDesc *sqlbase.PrivilegeDescriptor, grantee string, _ privilege.ObjectType) {
+			privDesc.Grant(grantee, n.Privileges)
+		}, false /* withColumns */)
 }
 
-// Revoke removes privileges from users.
+// Revoke removes privileges from users. As in postgres, revoking privileges
+// on a table also revokes them from the columns of the table.
 // Current status:
 // - Target: single database, table, or view.
 // TODO(marc): open questions:
@@ -95,7 +218,177 @@ func (p *planner) Grant(ctx context.Context, n *tree.Grant) (planNode, error) {
 //   Notes: postgres requires the object owner.
 //          mysql requires the "grant option" and the same privileges, and sometimes superuser.
 func (p *planner) Revoke(ctx context.Context, n *tree.Revoke) (planNode, error) {
-	return p.changePrivileges(ctx, n.Targets, n.Grantees, func(privDesc *sqlbase.PrivilegeDescriptor, grantee string) {
-		privDesc.Revoke(grantee, n.Privileges)
-	})
+	if n.Columns != nil {
+		if err := validateColumnPrivileges(n.Privileges); err != nil {
+			return nil, err
+		}
+	}
+	return p.changePrivileges(ctx, n, EventLogRevokePrivileges,
+		n.Targets, n.Columns, n.Grantees, n.Privileges,
+		func(privDesc *sqlbase.PrivilegeDescriptor, grantee string, objectType privilege.ObjectType) {
+			privDesc.Revoke(grantee, n.Privileges, objectType)
+		}, true /* withColumns */)
+}
+
+// checkRoleAdmin verifies that the session user can grant and revoke the
+// membership in the given roles, and that the roles exist. It returns the
+// normalized names of the roles.
+func (p *planner) checkRoleAdmin(
+	ctx context.Context, g roleGraph, roles tree.NameList,
+) ([]string, error) {
+	var adminOf map[string]bool
+	if p.session.User != security.RootUser {
+		adminOf = g.memberOf(p.session.User, false /* inheritingOnly */)
+	}
+	names := make([]string, len(roles))
+	for i, r := range roles {
+		role := r.Normalize()
+		if role == security.RootUser {
+			return nil, pgerror.NewErrorf(pgerror.CodeInvalidGrantOperationError,
+				"role %s cannot be granted or revoked", security.RootUser)
+		}
+		if p.session.User != security.RootUser && !adminOf[role] {
+			return nil, pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+				"%s must have admin option on role %s", p.session.User, role)
+		}
+		// The admin role is built in and can only be granted.
+		if role != security.AdminRole {
+			if err := p.checkRoleExists(ctx, role); err != nil {
+				return nil, err
+			}
+		}
+		names[i] = role
+	}
+	return names, nil
+}
+
+// checkRoleExists errors if there is no user or role with the given
+// normalized name.
+func (p *planner) checkRoleExists(ctx context.Context, name string) error {
+	if name == security.RootUser {
+		return nil
+	}
+	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	row, err := ie.QueryRowInTransaction(
+		ctx, "check-role-exists", p.txn, `SELECT 1 FROM system.users WHERE username = $1`, name,
+	)
+	if err != nil {
+		return err
+	}
+	if len(row) == 0 {
+		return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError, "role %s does not exist", name)
+	}
+	return nil
+}
+
+// GrantRole adds members to roles.
+// Privileges: root, or the admin option on the roles.
+//   Notes: postgres also allows the users with the CREATEROLE attribute.
+func (p *planner) GrantRole(ctx context.Context, n *tree.GrantRole) (planNode, error) {
+	g, err := p.loadRoleGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := p.checkRoleAdmin(ctx, g, n.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	// Granting without the admin option doesn't revoke it from the members
+	// already holding it.
+	stmt := `INSERT INTO system.role_members VALUES ($1, $2, $3) ON CONFLICT (role, member) DO NOTHING`
+	if n.AdminOption {
+		stmt = `UPSERT INTO system.role_members VALUES ($1, $2, $3)`
+	}
+	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	for _, m := range n.Members {
+		member := m.Normalize()
+		if err := p.checkRoleExists(ctx, member); err != nil {
+			return nil, err
+		}
+		for _, role := range roles {
+			if _, ok := g.memberOf(role, false /* inheritingOnly */)[member]; ok || role == member {
+				return nil, pgerror.NewErrorf(pgerror.CodeInvalidGrantOperationError,
+					"making %s a member of %s would create a cycle", member, role)
+			}
+			if _, err := ie.ExecuteStatementInTransaction(
+				ctx, "grant-role", p.txn, stmt, role, member, n.AdminOption,
+			); err != nil {
+				return nil, err
+			}
+			g.addMembership(roleMembership{role: role, member: member, isAdmin: n.AdminOption})
+		}
+	}
+	if err := p.logRoleMembershipEvent(ctx, EventLogGrantRole, n, roles, n.Members, n.AdminOption); err != nil {
+		return nil, err
+	}
+	return &zeroNode{}, nil
+}
+
+// RevokeRole removes members from roles, or only revokes their admin option
+// on the roles with REVOKE ADMIN OPTION FOR.
+// Privileges: root, or the admin option on the roles.
+func (p *planner) RevokeRole(ctx context.Context, n *tree.RevokeRole) (planNode, error) {
+	g, err := p.loadRoleGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := p.checkRoleAdmin(ctx, g, n.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := `DELETE FROM system.role_members WHERE role = $1 AND member = $2`
+	if n.AdminOption {
+		stmt = `UPDATE system.role_members SET "isAdmin" = false WHERE role = $1 AND member = $2`
+	}
+	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	for _, m := range n.Members {
+		member := m.Normalize()
+		if err := p.checkRoleExists(ctx, member); err != nil {
+			return nil, err
+		}
+		for _, role := range roles {
+			if _, err := ie.ExecuteStatementInTransaction(
+				ctx, "revoke-role", p.txn, stmt, role, member,
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := p.logRoleMembershipEvent(ctx, EventLogRevokeRole, n, roles, n.Members, n.AdminOption); err != nil {
+		return nil, err
+	}
+	return &zeroNode{}, nil
+}
+
+// logRoleMembershipEvent records a change of the members of roles in the
+// event log. This is an auditable log event and is recorded in the same
+// transaction as the changes of system.role_members.
+func (p *planner) logRoleMembershipEvent(
+	ctx context.Context,
+	eventType EventLogType,
+	stmt tree.Statement,
+	roles []string,
+	members tree.NameList,
+	adminOption bool,
+) error {
+	memberNames := make([]string, len(members))
+	for i, m := range members {
+		memberNames[i] = m.Normalize()
+	}
+	return MakeEventLogger(p.LeaseMgr()).InsertEventRecord(
+		ctx,
+		p.txn,
+		eventType,
+		0, /* no target */
+		int32(p.evalCtx.NodeID),
+		&sqlbase.ChangeRoleMembershipEvent{
+			Roles:       roles,
+			Members:     memberNames,
+			AdminOption: adminOption,
+			Statement:   stmt.String(),
+			User:        p.session.User,
+		},
+	)
 }
@@ -15,6 +15,7 @@
 package sql
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 
@@ -334,6 +335,16 @@ type groupNode struct {
 	desiredOrdering sqlbase.ColumnOrdering
 	needOnlyOneRow  bool
 	gotOneRow       bool
+
+	// isStreaming is set if the source plan is ordered such that all the rows
+	// of a group are adjacent (see physicalProps.computeGroupingOrdering). In
+	// that case the groupNode only keeps the state of the current group and
+	// emits each group as soon as the next one starts, instead of bucketing the
+	// entire input. It is only set if there is at least one grouping column.
+	isStreaming bool
+	// curBucket is the encoding of the group currently being accumulated in
+	// streaming mode; nil if there is no such group yet.
+	curBucket []byte
 }
 
 func (n *groupNode) Values() tree.Datums {
@@ -345,6 +356,9 @@ func (n *groupNode) Start(params runParams) error {
 }
 
 func (n *groupNode) Next(params runParams) (bool, error) {
+	if n.isStreaming {
+		return n.nextStreaming(params)
+	}
 	var scratch []byte
 	// We're going to consume n.plan until it's exhausted (feeding all the rows to
 	// n.funcs), and then call n.setupOutput.
@@ -371,7 +385,8 @@ func (n *groupNode) Next(params runParams) (bool, error) {
 
 		values := n.plan.Values()
 
-		// TODO(dt): optimization: skip buckets when underlying plan is ordered by grouped values.
+		// If the underlying plan is ordered by the grouped values, nextStreaming
+		// is used instead (see isStreaming).
 
 		bucket := scratch
 		for idx := 0; idx < n.numGroupCols; idx++ {
@@ -385,19 +400,8 @@ func (n *groupNode) Next(params runParams) (bool, error) {
 		n.buckets[string(bucket)] = struct{}{}
 
 		// Feed the aggregateFuncHolders for this bucket the non-grouped values.
-		for _, f := range n.funcs {
-			if f.hasFilter && values[f.filterRenderIdx] != tree.DBoolTrue {
-				continue
-			}
-
-			var value tree.Datum
-			if f.argRenderIdx != noRenderIdx {
-				value = values[f.argRenderIdx]
-			}
-
-			if err := f.add(params.ctx, params.evalCtx, bucket, value); err != nil {
-				return false, err
-			}
+		if err := n.addRow(params, bucket, values); err != nil {
+			return false, err
 		}
 		scratch = bucket[:0]
 
@@ -413,6 +417,100 @@ func (n *groupNode) Next(params runParams) (bool, error) {
 		break
 	}
 	delete(n.buckets, bucket)
+	if err := n.computeResults(params, bucket); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// nextStreaming implements Next when the source plan is ordered on the
+// grouping columns. Rows are accumulated into a single bucket until the group
+// changes, at which point the results for the finished group are emitted and
+// the aggregation state is reset.
+func (n *groupNode) nextStreaming(params runParams) (bool, error) {
+	if n.values == nil {
+		n.values = make(tree.Datums, len(n.funcs))
+	}
+	for !n.populated {
+		if err := params.p.cancelChecker.Check(); err != nil {
+			return false, err
+		}
+		next, err := n.plan.Next(params)
+		if err != nil {
+			return false, err
+		}
+		if !next {
+			n.populated = true
+			break
+		}
+
+		values := n.plan.Values()
+		var bucket []byte
+		for idx := 0; idx < n.numGroupCols; idx++ {
+			bucket, err = sqlbase.EncodeDatum(bucket, values[idx])
+			if err != nil {
+				return false, err
+			}
+		}
+
+		emit := false
+		if n.curBucket != nil && !bytes.Equal(bucket, n.curBucket) {
+			// The current group is complete; compute its results before the
+			// aggregation state is reset for the new group.
+			if err := n.computeResults(params, string(n.curBucket)); err != nil {
+				return false, err
+			}
+			for _, f := range n.funcs {
+				f.clearBuckets(params.ctx)
+			}
+			emit = true
+		}
+		n.curBucket = bucket
+
+		if err := n.addRow(params, bucket, values); err != nil {
+			return false, err
+		}
+		if emit {
+			return true, nil
+		}
+	}
+
+	if n.curBucket == nil {
+		// The last group has already been emitted (or there was no input).
+		return false, nil
+	}
+	// Emit the last group.
+	bucket := n.curBucket
+	n.curBucket = nil
+	if err := n.computeResults(params, string(bucket)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// addRow feeds the aggregateFuncHolders for the given bucket the non-grouped
+// values of a source row.
+func (n *groupNode) addRow(params runParams, bucket []byte, values tree.Datums) error {
+	for _, f := range n.funcs {
+		if f.hasFilter && values[f.filterRenderIdx] != tree.DBoolTrue {
+			continue
+		}
+
+		var value tree.Datum
+		if f.argRenderIdx != noRenderIdx {
+			value = values[f.argRenderIdx]
+		}
+
+		if err := f.add(params.ctx, params.evalCtx, bucket, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// computeResults populates n.values with the results of the aggregation
+// functions for the given bucket.
+func (n *groupNode) computeResults(params runParams, bucket string) error {
 	for i, f := range n.funcs {
 		aggregateFunc, ok := f.buckets[bucket]
 		if !ok {
@@ -424,10 +522,10 @@ func (n *groupNode) Next(params runParams) (bool, error) {
 		var err error
 		n.values[i], err = aggregateFunc.Result()
 		if err != nil {
-			return false, err
+			return err
 		}
 	}
-	return true, nil
+	return nil
 }
 
 // setupOutput runs once after all the input rows have been processed. It sets
@@ -736,6 +834,20 @@ func (a *aggregateFuncHolder) setDistinct() {
 	a.seen = make(map[string]struct{})
 }
 
+// clearBuckets releases the aggregation state of all buckets. It is used in
+// streaming mode, where a bucket is no longer needed once its group has been
+// emitted.
+func (a *aggregateFuncHolder) clearBuckets(ctx context.Context) {
+	for _, aggFunc := range a.buckets {
+		aggFunc.Close(ctx)
+	}
+	a.buckets = make(map[string]tree.AggregateFunc)
+	if a.seen != nil {
+		a.seen = make(map[string]struct{})
+	}
+	a.bucketsMemAcc.Clear(ctx)
+}
+
 func (a *aggregateFuncHolder) close(ctx context.Context) {
 	for _, aggFunc := range a.buckets {
 		aggFunc.Close(ctx)
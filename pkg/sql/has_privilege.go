@@ -0,0 +1,274 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// inspectedPrivileges maps the privilege names accepted by the
+// has_*_privilege builtins for each type of object to the privilege they
+// check. Besides the PostgreSQL names, the privileges of CockroachDB that
+// can be granted on tables and databases are accepted. The PostgreSQL
+// privileges that don't exist here map to 0 and are never held.
+var inspectedPrivileges = map[tree.PrivilegeObjectType]map[string]privilege.Kind{
+	tree.PrivilegeObjectDatabase: {
+		"CREATE":    privilege.CREATE,
+		"CONNECT":   privilege.CONNECT,
+		"TEMPORARY": 0,
+		"TEMP":      0,
+		"DROP":      privilege.DROP,
+		"GRANT":     privilege.GRANT,
+	},
+	tree.PrivilegeObjectSchema: {
+		"CREATE": privilege.CREATE,
+		"USAGE":  privilege.USAGE,
+	},
+	tree.PrivilegeObjectTable: {
+		"SELECT":     privilege.SELECT,
+		"INSERT":     privilege.INSERT,
+		"UPDATE":     privilege.UPDATE,
+		"DELETE":     privilege.DELETE,
+		"TRUNCATE":   privilege.DROP,
+		"REFERENCES": 0,
+		"TRIGGER":    0,
+		"CREATE":     privilege.CREATE,
+		"DROP":       privilege.DROP,
+		"GRANT":      privilege.GRANT,
+	},
+	tree.PrivilegeObjectSequence: {
+		// nextval() increments a sequence like an update.
+		"USAGE":  privilege.UPDATE,
+		"SELECT": privilege.SELECT,
+		"UPDATE": privilege.UPDATE,
+	},
+	tree.PrivilegeObjectColumn: {
+		"SELECT":     privilege.SELECT,
+		"INSERT":     privilege.INSERT,
+		"UPDATE":     privilege.UPDATE,
+		"REFERENCES": 0,
+	},
+}
+
+// virtualPrivileges are the privileges every user holds on the virtual
+// schemas and their tables.
+var virtualPrivileges = privilege.List{privilege.SELECT, privilege.CONNECT, privilege.USAGE}
+
+// inspectedPrivilege is a privilege listed in the argument of a
+// has_*_privilege or pg_has_role builtin.
+type inspectedPrivilege struct {
+	name string
+	// withGrantOption is set if the privilege was followed by WITH GRANT
+	// OPTION or, for pg_has_role, WITH ADMIN OPTION.
+	withGrantOption bool
+}
+
+// parseInspectedPrivileges splits the comma-separated list of privileges
+// given to a has_*_privilege or pg_has_role builtin. The names are case
+// insensitive.
+func parseInspectedPrivileges(privs string, grantOptions ...string) []inspectedPrivilege {
+	var res []inspectedPrivilege
+	for _, s := range strings.Split(privs, ",") {
+		p := inspectedPrivilege{name: strings.ToUpper(strings.Join(strings.Fields(s), " "))}
+		for _, opt := range grantOptions {
+			if strings.HasSuffix(p.name, " "+opt) {
+				p.name, p.withGrantOption = strings.TrimSuffix(p.name, " "+opt), true
+				break
+			}
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
+func errUnrecognizedPrivilege(name string) error {
+	return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+		"unrecognized privilege type: %q", name)
+}
+
+// privilegeHolders returns the given user and the roles whose privileges it
+// inherits, or an error if the user doesn't exist.
+func (p *planner) privilegeHolders(ctx context.Context, user string) ([]string, error) {
+	if err := p.checkInspectedRoleExists(ctx, user); err != nil {
+		return nil, err
+	}
+	holders := []string{user}
+	if user == security.PublicRole {
+		return holders, nil
+	}
+	g, err := p.loadRoleGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for role := range g.memberOf(user, true /* inheritingOnly */) {
+		holders = append(holders, role)
+	}
+	return holders, nil
+}
+
+// checkInspectedRoleExists is like checkRoleExists, but the public role, the
+// node user and the built-in admin role, which aren't users, exist too.
+func (p *planner) checkInspectedRoleExists(ctx context.Context, name string) error {
+	if name == security.PublicRole || name == security.NodeUser || name == security.AdminRole {
+		return nil
+	}
+	return p.checkRoleExists(ctx, name)
+}
+
+// privilegeObjectDescriptors returns the privilege descriptors of the given
+// object: those of the table and of the column for a column, which may be
+// nil if no privilege was granted on the column. isVirtual is set for the
+// virtual schemas and tables, whose privileges are virtualPrivileges.
+func (p *planner) privilegeObjectDescriptors(
+	ctx context.Context, object tree.PrivilegeObject,
+) (privs []*sqlbase.PrivilegeDescriptor, isVirtual bool, err error) {
+	vt := p.getVirtualTabler()
+	switch object.Type {
+	case tree.PrivilegeObjectDatabase, tree.PrivilegeObjectSchema:
+		desc, err := MustGetDatabaseDesc(ctx, p.txn, vt, object.Database)
+		if err != nil {
+			return nil, false, err
+		}
+		return []*sqlbase.PrivilegeDescriptor{desc.Privileges}, isVirtualDescriptor(desc), nil
+	}
+
+	var desc *sqlbase.TableDescriptor
+	if object.Type == tree.PrivilegeObjectSequence {
+		desc, err = getSequenceDesc(ctx, p.txn, vt, object.Table)
+	} else {
+		desc, err = getTableOrViewDesc(ctx, p.txn, vt, object.Table)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if desc == nil {
+		return nil, false, sqlbase.NewUndefinedRelationError(object.Table)
+	}
+	privs = []*sqlbase.PrivilegeDescriptor{desc.Privileges}
+	if object.Type == tree.PrivilegeObjectColumn {
+		var col *sqlbase.ColumnDescriptor
+		for i := range desc.Columns {
+			if !desc.Columns[i].Hidden && desc.Columns[i].Name == object.Column {
+				col = &desc.Columns[i]
+				break
+			}
+		}
+		if col == nil {
+			return nil, false, pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
+				"column %q of relation %q does not exist", object.Column, desc.Name)
+		}
+		privs = append(privs, col.Privileges)
+	}
+	return privs, isVirtualDescriptor(desc), nil
+}
+
+// HasPrivilege implements the tree.EvalPlanner interface.
+func (p *planner) HasPrivilege(
+	ctx context.Context, user string, object tree.PrivilegeObject, privs string,
+) (bool, error) {
+	user = tree.Name(user).Normalize()
+	names := inspectedPrivileges[object.Type]
+	inspected := parseInspectedPrivileges(privs, "WITH GRANT OPTION")
+	for _, priv := range inspected {
+		if _, ok := names[priv.name]; !ok {
+			return false, errUnrecognizedPrivilege(priv.name)
+		}
+	}
+	holders, err := p.privilegeHolders(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	descs, isVirtual, err := p.privilegeObjectDescriptors(ctx, object)
+	if err != nil {
+		return false, err
+	}
+
+	holds := func(kind privilege.Kind) bool {
+		if isVirtual {
+			for _, k := range virtualPrivileges {
+				if k == kind {
+					return true
+				}
+			}
+			return false
+		}
+		for _, desc := range descs {
+			if desc == nil {
+				continue
+			}
+			for _, h := range holders {
+				if desc.CheckPrivilege(h, kind) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, priv := range inspected {
+		kind := names[priv.name]
+		if kind == 0 {
+			continue
+		}
+		if holds(kind) && (!priv.withGrantOption || holds(privilege.GRANT)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HasRole implements the tree.EvalPlanner interface. Like a superuser in
+// PostgreSQL, root holds every privilege on every role, and every user is a
+// member of itself.
+func (p *planner) HasRole(ctx context.Context, user string, role string, privs string) (bool, error) {
+	user, role = tree.Name(user).Normalize(), tree.Name(role).Normalize()
+	inspected := parseInspectedPrivileges(privs, "WITH ADMIN OPTION", "WITH GRANT OPTION")
+	for _, priv := range inspected {
+		if priv.name != "MEMBER" && priv.name != "USAGE" {
+			return false, errUnrecognizedPrivilege(priv.name)
+		}
+	}
+	for _, name := range []string{user, role} {
+		if err := p.checkInspectedRoleExists(ctx, name); err != nil {
+			return false, err
+		}
+	}
+	if user == security.RootUser {
+		return true, nil
+	}
+	g, err := p.loadRoleGraph(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, priv := range inspected {
+		if user == role && !priv.withGrantOption {
+			return true, nil
+		}
+		// USAGE requires the privileges of the role to be inherited, MEMBER
+		// only the membership.
+		isAdmin, ok := g.memberOf(user, priv.name == "USAGE")[role]
+		if ok && (!priv.withGrantOption || isAdmin) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -20,13 +20,12 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
 
-const indexJoinBatchSize = 100
-
 // An indexJoinNode implements joining of results from an index with the rows
 // of a table.
 //
@@ -56,7 +55,7 @@ const indexJoinBatchSize = 100
 //   - using the PK of the indexed table, rows from the indexed
 //     table are fetched using the table scanNode.
 //
-//   The work is batched: we pull joinBatchSize rows from the index
+//   The work is batched: we pull batchSize rows from the index
 //   and use the primary key to construct spans that are looked up in
 //   the table.
 //
@@ -104,6 +103,11 @@ type indexJoinNode struct {
 	// may produce more values than this, e.g. when its filter expression
 	// uses more columns than the PK.
 	primaryKeyColumns []bool
+
+	// batchSize is the number of rows pulled from the index scanNode whose
+	// primary keys are looked up together in the table scanNode. It is set
+	// from distsqlrun.IndexJoinBatchSize when the node is started.
+	batchSize int
 }
 
 // makeIndexJoin build an index join node.
@@ -210,6 +214,7 @@ func (n *indexJoinNode) Values() tree.Datums {
 }
 
 func (n *indexJoinNode) Start(params runParams) error {
+	n.batchSize = int(distsqlrun.IndexJoinBatchSize.Get(&params.p.session.execCfg.Settings.SV))
 	if err := n.table.Start(params); err != nil {
 		return err
 	}
@@ -238,7 +243,7 @@ func (n *indexJoinNode) Next(params runParams) (bool, error) {
 		n.table.scanInitialized = false
 		n.table.spans = n.table.spans[:0]
 
-		for len(n.table.spans) < indexJoinBatchSize {
+		for len(n.table.spans) < n.batchSize {
 			if next, err := n.index.Next(params); !next {
 				// The index is out of rows or an error occurred.
 				if err != nil {
@@ -0,0 +1,138 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// The reads and writes of the indexes by the statements executed on this
+// node are counted, so that users can find the indexes which are
+// maintained by every write but never read. An index is read by a
+// statement when its plan scans the index, and written when the statement
+// inserts, updates or deletes rows in the index; a statement counts once
+// per index, regardless of the number of rows. The counts are exposed in
+// crdb_internal.index_usage_statistics, along with the time of the latest
+// read of each index.
+
+// indexUsageKey identifies an index.
+type indexUsageKey struct {
+	tableID sqlbase.ID
+	indexID sqlbase.IndexID
+}
+
+// indexUsage is the usage of an index by the statements executed on this
+// node.
+type indexUsage struct {
+	reads    int64
+	writes   int64
+	lastRead time.Time
+}
+
+// indexUsageStats counts the reads and writes of the indexes.
+type indexUsageStats struct {
+	syncutil.Mutex
+	indexes map[indexUsageKey]*indexUsage
+}
+
+// recordIndexUsage counts the reads and writes of the indexes by the plan of
+// a statement. It is called once the statement is planned, so the indexes
+// used by a statement which fails during its execution are counted as well.
+func (e *Executor) recordIndexUsage(ctx context.Context, plan planNode) {
+	var reads, writes []indexUsageKey
+	_ = walkPlan(ctx, plan, planObserver{
+		enterNode: func(_ context.Context, _ string, plan planNode) bool {
+			switch n := plan.(type) {
+			case *explainPlanNode, *explainDistSQLNode:
+				// The plans explained by EXPLAIN aren't executed.
+				return false
+			case *scanNode:
+				if !n.desc.IsVirtualTable() {
+					reads = append(reads, indexUsageKey{tableID: n.desc.ID, indexID: n.index.ID})
+				}
+			case *insertNode:
+				switch tw := n.tw.(type) {
+				case *tableInserter:
+					writes = appendWrittenIndexes(writes, tw.ri.Helper.TableDesc, tw.ri.Helper.Indexes)
+				case *tableUpserter:
+					writes = appendWrittenIndexes(writes, tw.ri.Helper.TableDesc, tw.ri.Helper.Indexes)
+				}
+			case *updateNode:
+				writes = appendWrittenIndexes(writes, n.tw.ru.Helper.TableDesc, n.tw.ru.Helper.Indexes)
+			case *deleteNode:
+				writes = appendWrittenIndexes(writes, n.tw.rd.Helper.TableDesc, n.tw.rd.Helper.Indexes)
+			}
+			return true
+		},
+	})
+	if len(reads) == 0 && len(writes) == 0 {
+		return
+	}
+
+	now := timeutil.Now()
+	e.indexUsage.Lock()
+	defer e.indexUsage.Unlock()
+	if e.indexUsage.indexes == nil {
+		e.indexUsage.indexes = make(map[indexUsageKey]*indexUsage)
+	}
+	for _, k := range reads {
+		u := e.indexUsage.getLocked(k)
+		u.reads++
+		u.lastRead = now
+	}
+	for _, k := range writes {
+		e.indexUsage.getLocked(k).writes++
+	}
+}
+
+// appendWrittenIndexes appends the primary index of the table and the
+// secondary indexes maintained by a row writer to res.
+func appendWrittenIndexes(
+	res []indexUsageKey, desc *sqlbase.TableDescriptor, indexes []sqlbase.IndexDescriptor,
+) []indexUsageKey {
+	res = append(res, indexUsageKey{tableID: desc.ID, indexID: desc.PrimaryIndex.ID})
+	for i := range indexes {
+		res = append(res, indexUsageKey{tableID: desc.ID, indexID: indexes[i].ID})
+	}
+	return res
+}
+
+// getLocked returns the usage of an index, which is created if the index
+// wasn't used yet. The mutex must be held.
+func (s *indexUsageStats) getLocked(k indexUsageKey) *indexUsage {
+	u, ok := s.indexes[k]
+	if !ok {
+		u = &indexUsage{}
+		s.indexes[k] = u
+	}
+	return u
+}
+
+// get returns a copy of the usage of an index. The usage of an index which
+// wasn't used is zero.
+func (s *indexUsageStats) get(tableID sqlbase.ID, indexID sqlbase.IndexID) indexUsage {
+	s.Lock()
+	defer s.Unlock()
+	if u, ok := s.indexes[indexUsageKey{tableID: tableID, indexID: indexID}]; ok {
+		return *u
+	}
+	return indexUsage{}
+}
@@ -15,14 +15,19 @@
 package sql
 
 import (
+	"fmt"
 	"sort"
+	"strconv"
+	"unicode"
 
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
 
@@ -34,8 +39,12 @@ const (
 var informationSchema = virtualSchema{
 	name: informationSchemaName,
 	tables: []virtualSchemaTable{
+		informationSchemaColumnPrivileges,
 		informationSchemaColumnsTable,
 		informationSchemaKeyColumnUsageTable,
+		informationSchemaParametersTable,
+		informationSchemaReferentialConstraintsTable,
+		informationSchemaRoutinesTable,
 		informationSchemaSchemataTable,
 		informationSchemaSchemataTablePrivileges,
 		informationSchemaSequences,
@@ -59,6 +68,9 @@ var (
 	// as strings.
 	yesString = tree.NewDString("YES")
 	noString  = tree.NewDString("NO")
+
+	// pgCatalogNameDString is the schema of the builtin functions.
+	pgCatalogNameDString = tree.NewDString(pgCatalogName)
 )
 
 func yesOrNoDatum(b bool) tree.Datum {
@@ -96,6 +108,48 @@ func dIntFnOrNull(fn func() (int32, bool)) tree.Datum {
 	return tree.DNull
 }
 
+var informationSchemaColumnPrivileges = virtualSchemaTable{
+	schema: `
+CREATE TABLE information_schema.column_privileges (
+	GRANTOR STRING,
+	GRANTEE STRING NOT NULL DEFAULT '',
+	TABLE_CATALOG STRING NOT NULL DEFAULT '',
+	TABLE_SCHEMA STRING NOT NULL DEFAULT '',
+	TABLE_NAME STRING NOT NULL DEFAULT '',
+	COLUMN_NAME STRING NOT NULL DEFAULT '',
+	PRIVILEGE_TYPE STRING NOT NULL DEFAULT '',
+	IS_GRANTABLE BOOL
+);
+`,
+	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
+		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+			for i := range table.Columns {
+				column := &table.Columns[i]
+				if column.Privileges == nil {
+					continue
+				}
+				for _, u := range column.Privileges.Show() {
+					for _, privilege := range u.Privileges {
+						if err := addRow(
+							tree.DNull,                   // grantor
+							tree.NewDString(u.User),      // grantee
+							defString,                    // table_catalog
+							tree.NewDString(db.Name),     // table_schema
+							tree.NewDString(table.Name),  // table_name
+							tree.NewDString(column.Name), // column_name
+							tree.NewDString(privilege),   // privilege_type
+							tree.DNull,                   // is_grantable
+						); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			return nil
+		})
+	},
+}
+
 var informationSchemaColumnsTable = virtualSchemaTable{
 	schema: `
 CREATE TABLE information_schema.columns (
@@ -185,7 +239,8 @@ CREATE TABLE information_schema.key_column_usage (
 				return err
 			}
 
-			for name, c := range info {
+			for _, name := range sortedConstraintNames(info) {
+				c := info[name]
 				// Only Primary Key, Foreign Key, and Unique constraints are included.
 				switch c.Kind {
 				case sqlbase.ConstraintTypePK:
@@ -221,6 +276,260 @@ CREATE TABLE information_schema.key_column_usage (
 	},
 }
 
+// sortedConstraintNames returns the names of the constraints of a table in
+// lexicographical order, so that the constraints are listed deterministically.
+func sortedConstraintNames(info map[string]sqlbase.ConstraintDetail) []string {
+	names := make([]string, 0, len(info))
+	for name := range info {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// https://www.postgresql.org/docs/9.6/static/infoschema-parameters.html
+var informationSchemaParametersTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE information_schema.parameters (
+	SPECIFIC_CATALOG STRING NOT NULL DEFAULT '',
+	SPECIFIC_SCHEMA STRING NOT NULL DEFAULT '',
+	SPECIFIC_NAME STRING NOT NULL DEFAULT '',
+	ORDINAL_POSITION INT NOT NULL DEFAULT 0,
+	PARAMETER_MODE STRING NOT NULL DEFAULT '',
+	IS_RESULT STRING NOT NULL DEFAULT '',
+	AS_LOCATOR STRING NOT NULL DEFAULT '',
+	PARAMETER_NAME STRING,
+	DATA_TYPE STRING NOT NULL DEFAULT '',
+	PARAMETER_DEFAULT STRING
+);`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		return forEachBuiltin(func(name string, specificName tree.Datum, builtin *tree.Builtin) error {
+			appendRow := func(pos int, mode tree.Datum, paramName string, typ types.T) error {
+				return addRow(
+					defString,                      // specific_catalog
+					pgCatalogNameDString,           // specific_schema
+					specificName,                   // specific_name
+					tree.NewDInt(tree.DInt(pos)),   // ordinal_position, 1-indexed
+					mode,                           // parameter_mode
+					noString,                       // is_result
+					noString,                       // as_locator
+					dStringOrNull(paramName),       // parameter_name
+					tree.NewDString(typ.SQLName()), // data_type
+					tree.DNull,                     // parameter_default
+				)
+			}
+			switch t := builtin.Types.(type) {
+			case tree.ArgTypes:
+				for i, arg := range t {
+					if err := appendRow(i+1, parameterModeIn, arg.Name, arg.Typ); err != nil {
+						return err
+					}
+				}
+				return nil
+			case tree.VariadicType:
+				return appendRow(1, parameterModeVariadic, "", t.Typ)
+			case tree.HomogeneousType:
+				return appendRow(1, parameterModeVariadic, "", types.Any)
+			}
+			return nil
+		})
+	},
+}
+
+var (
+	parameterModeIn       = tree.NewDString("IN")
+	parameterModeVariadic = tree.NewDString("VARIADIC")
+)
+
+var (
+	fkRuleNoAction   = tree.NewDString("NO ACTION")
+	fkRuleRestrict   = tree.NewDString("RESTRICT")
+	fkRuleSetNull    = tree.NewDString("SET NULL")
+	fkRuleSetDefault = tree.NewDString("SET DEFAULT")
+	fkRuleCascade    = tree.NewDString("CASCADE")
+
+	// Only the MATCH SIMPLE foreign keys are supported, which postgres
+	// reports as NONE.
+	fkMatchOptionNone = tree.NewDString("NONE")
+)
+
+func dStringForFKRule(action sqlbase.ForeignKeyReference_Action) tree.Datum {
+	switch action {
+	case sqlbase.ForeignKeyReference_NO_ACTION:
+		return fkRuleNoAction
+	case sqlbase.ForeignKeyReference_RESTRICT:
+		return fkRuleRestrict
+	case sqlbase.ForeignKeyReference_SET_NULL:
+		return fkRuleSetNull
+	case sqlbase.ForeignKeyReference_SET_DEFAULT:
+		return fkRuleSetDefault
+	case sqlbase.ForeignKeyReference_CASCADE:
+		return fkRuleCascade
+	}
+	panic(errors.Errorf("unexpected ForeignKeyReference_Action: %v", action))
+}
+
+// https://www.postgresql.org/docs/9.6/static/infoschema-referential-constraints.html
+var informationSchemaReferentialConstraintsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE information_schema.referential_constraints (
+	CONSTRAINT_CATALOG STRING NOT NULL DEFAULT '',
+	CONSTRAINT_SCHEMA STRING NOT NULL DEFAULT '',
+	CONSTRAINT_NAME STRING NOT NULL DEFAULT '',
+	UNIQUE_CONSTRAINT_CATALOG STRING NOT NULL DEFAULT '',
+	UNIQUE_CONSTRAINT_SCHEMA STRING NOT NULL DEFAULT '',
+	UNIQUE_CONSTRAINT_NAME STRING,
+	MATCH_OPTION STRING NOT NULL DEFAULT '',
+	UPDATE_RULE STRING NOT NULL DEFAULT '',
+	DELETE_RULE STRING NOT NULL DEFAULT '',
+	TABLE_NAME STRING NOT NULL DEFAULT '',
+	REFERENCED_TABLE_NAME STRING NOT NULL DEFAULT ''
+);`,
+	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
+		return forEachTableDescWithTableLookup(ctx, p, prefix, func(
+			db *sqlbase.DatabaseDescriptor,
+			table *sqlbase.TableDescriptor,
+			tableLookup tableLookupFn,
+		) error {
+			info, err := table.GetConstraintInfoWithLookup(tableLookup.tableOrErr)
+			if err != nil {
+				return err
+			}
+
+			for _, name := range sortedConstraintNames(info) {
+				c := info[name]
+				if c.Kind != sqlbase.ConstraintTypeFK {
+					continue
+				}
+				refDB, _ := tableLookup(c.ReferencedTable.ID)
+				if refDB == nil {
+					return errors.Errorf("could not find the database of referenced table %d",
+						c.ReferencedTable.ID)
+				}
+				if err := addRow(
+					defString,                               // constraint_catalog
+					tree.NewDString(db.Name),                // constraint_schema
+					dStringOrNull(name),                     // constraint_name
+					defString,                               // unique_constraint_catalog
+					tree.NewDString(refDB.Name),             // unique_constraint_schema
+					dStringOrNull(c.ReferencedIndex.Name),   // unique_constraint_name
+					fkMatchOptionNone,                       // match_option
+					dStringForFKRule(c.FK.OnUpdate),         // update_rule
+					dStringForFKRule(c.FK.OnDelete),         // delete_rule
+					tree.NewDString(table.Name),             // table_name
+					tree.NewDString(c.ReferencedTable.Name), // referenced_table_name
+				); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	},
+}
+
+var (
+	routineTypeFunction     = tree.NewDString("FUNCTION")
+	routineBodyExternal     = tree.NewDString("EXTERNAL")
+	routineLanguageInternal = tree.NewDString("INTERNAL")
+	routineParameterStyle   = tree.NewDString("GENERAL")
+	routineSecurityInvoker  = tree.NewDString("INVOKER")
+)
+
+// https://www.postgresql.org/docs/9.6/static/infoschema-routines.html
+var informationSchemaRoutinesTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE information_schema.routines (
+	SPECIFIC_CATALOG STRING NOT NULL DEFAULT '',
+	SPECIFIC_SCHEMA STRING NOT NULL DEFAULT '',
+	SPECIFIC_NAME STRING NOT NULL DEFAULT '',
+	ROUTINE_CATALOG STRING NOT NULL DEFAULT '',
+	ROUTINE_SCHEMA STRING NOT NULL DEFAULT '',
+	ROUTINE_NAME STRING NOT NULL DEFAULT '',
+	ROUTINE_TYPE STRING NOT NULL DEFAULT '',
+	DATA_TYPE STRING,
+	ROUTINE_BODY STRING NOT NULL DEFAULT '',
+	ROUTINE_DEFINITION STRING,
+	EXTERNAL_NAME STRING,
+	EXTERNAL_LANGUAGE STRING,
+	PARAMETER_STYLE STRING,
+	IS_DETERMINISTIC STRING NOT NULL DEFAULT '',
+	IS_NULL_CALL STRING,
+	SECURITY_TYPE STRING
+);`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		return forEachBuiltin(func(name string, specificName tree.Datum, builtin *tree.Builtin) error {
+			// The functions returning sets are reported with the type of their
+			// elements, like postgres does.
+			dataType := tree.DNull
+			if retType := builtin.FixedReturnType(); retType != nil {
+				if t, ok := retType.(types.TTable); ok {
+					retType = types.Any
+					if len(t.Cols) == 1 {
+						retType = t.Cols[0]
+					}
+				}
+				dataType = tree.NewDString(retType.SQLName())
+			}
+			dName := tree.NewDString(name)
+			return addRow(
+				defString,                           // specific_catalog
+				pgCatalogNameDString,                // specific_schema
+				specificName,                        // specific_name
+				defString,                           // routine_catalog
+				pgCatalogNameDString,                // routine_schema
+				dName,                               // routine_name
+				routineTypeFunction,                 // routine_type
+				dataType,                            // data_type
+				routineBodyExternal,                 // routine_body
+				tree.DNull,                          // routine_definition
+				dName,                               // external_name
+				routineLanguageInternal,             // external_language
+				routineParameterStyle,               // parameter_style
+				yesOrNoDatum(!builtin.Impure),       // is_deterministic
+				yesOrNoDatum(!builtin.NullableArgs), // is_null_call
+				routineSecurityInvoker,              // security_type
+			)
+		})
+	},
+}
+
+// forEachBuiltin iterates through the overloads of the builtin functions in
+// lexicographical order of their names. For each overload, fn is called with
+// the name of the function and the specific name of the overload, which
+// identifies it in information_schema like in postgres.
+func forEachBuiltin(
+	fn func(name string, specificName tree.Datum, builtin *tree.Builtin) error,
+) error {
+	h := makeOidHasher()
+	names := make([]string, 0, len(builtins.Builtins))
+	for name := range builtins.Builtins {
+		// builtins.Builtins contains duplicate uppercase and lowercase keys.
+		// Only return the lowercase ones for compatibility with postgres.
+		var first rune
+		for _, c := range name {
+			first = c
+			break
+		}
+		if unicode.IsUpper(first) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		overloads := builtins.Builtins[name]
+		for i := range overloads {
+			builtin := &overloads[i]
+			oid := h.BuiltinOid(name, builtin)
+			specificName := tree.NewDString(fmt.Sprintf("%s_%d", name, int(oid.DInt)))
+			if err := fn(name, specificName, builtin); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 var informationSchemaSchemataTable = virtualSchemaTable{
 	schema: `
 CREATE TABLE information_schema.schemata (
@@ -287,6 +596,9 @@ func dStringForIndexDirection(dir sqlbase.IndexDescriptor_Direction) tree.Datum
 	panic("unreachable")
 }
 
+// sequenceDataType is the type of the values of the sequences.
+var sequenceDataType = tree.NewDString("INT")
+
 // https://www.postgresql.org/docs/9.6/static/infoschema-sequences.html
 var informationSchemaSequences = virtualSchemaTable{
 	schema: `
@@ -305,10 +617,29 @@ CREATE TABLE information_schema.sequences (
     CYCLE_OPTION STRING NOT NULL DEFAULT 'NO'
 );`,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
-		// Sequences are not yet supported: #5811
-		// However, we support an empty information_schema table to enable
-		// clients to observe that there are no sequences.
-		return nil
+		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+			if !table.IsSequence() {
+				return nil
+			}
+			opts := table.SequenceOpts
+			formatInt := func(i int64) tree.Datum {
+				return tree.NewDString(strconv.FormatInt(i, 10))
+			}
+			return addRow(
+				defString,                   // sequence_catalog
+				tree.NewDString(db.Name),    // sequence_schema
+				tree.NewDString(table.Name), // sequence_name
+				sequenceDataType,            // data_type
+				tree.NewDInt(64),            // numeric_precision
+				tree.NewDInt(2),             // numeric_precision_radix
+				tree.NewDInt(0),             // numeric_scale
+				formatInt(opts.Start),       // start_value
+				formatInt(opts.MinValue),    // minimum_value
+				formatInt(opts.MaxValue),    // maximum_value
+				formatInt(opts.Increment),   // increment
+				yesOrNoDatum(opts.Cycle),    // cycle_option
+			)
+		})
 	},
 }
 
@@ -428,7 +759,8 @@ CREATE TABLE information_schema.table_constraints (
 				return err
 			}
 
-			for name, c := range info {
+			for _, name := range sortedConstraintNames(info) {
+				c := info[name]
 				if err := addRow(
 					defString,                       // constraint_catalog
 					tree.NewDString(db.Name),        // constraint_schema
@@ -542,6 +874,8 @@ CREATE TABLE information_schema.tables (
 	},
 }
 
+var viewCheckOptionNone = tree.NewDString("NONE")
+
 var informationSchemaViewsTable = virtualSchemaTable{
 	schema: `
 CREATE TABLE information_schema.views (
@@ -550,11 +884,11 @@ CREATE TABLE information_schema.views (
     TABLE_NAME STRING NOT NULL DEFAULT '',
     VIEW_DEFINITION STRING NOT NULL DEFAULT '',
     CHECK_OPTION STRING NOT NULL DEFAULT '',
-    IS_UPDATABLE BOOL NOT NULL DEFAULT FALSE,
-    IS_INSERTABLE_INTO BOOL NOT NULL DEFAULT FALSE,
-    IS_TRIGGER_UPDATABLE BOOL NOT NULL DEFAULT FALSE,
-    IS_TRIGGER_DELETABLE BOOL NOT NULL DEFAULT FALSE,
-    IS_TRIGGER_INSERTABLE_INTO BOOL NOT NULL DEFAULT FALSE
+    IS_UPDATABLE STRING NOT NULL DEFAULT '',
+    IS_INSERTABLE_INTO STRING NOT NULL DEFAULT '',
+    IS_TRIGGER_UPDATABLE STRING NOT NULL DEFAULT '',
+    IS_TRIGGER_DELETABLE STRING NOT NULL DEFAULT '',
+    IS_TRIGGER_INSERTABLE_INTO STRING NOT NULL DEFAULT ''
 );`,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
 		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
@@ -569,17 +903,20 @@ CREATE TABLE information_schema.views (
 			// while Postgres would more accurately print `SELECT b AS a FROM foo`.
 			// TODO(a-robinson): Insert column aliases into view query once we
 			// have a semantic query representation to work with (#10083).
+			//
+			// The views can't be written to, and don't support triggers nor
+			// WITH CHECK OPTION.
 			return addRow(
 				defString,                        // table_catalog
 				tree.NewDString(db.Name),         // table_schema
 				tree.NewDString(table.Name),      // table_name
 				tree.NewDString(table.ViewQuery), // view_definition
-				tree.DNull,                       // check_option
-				tree.DNull,                       // is_updatable
-				tree.DNull,                       // is_insertable_into
-				tree.DNull,                       // is_trigger_updatable
-				tree.DNull,                       // is_trigger_deletable
-				tree.DNull,                       // is_trigger_insertable_into
+				viewCheckOptionNone,              // check_option
+				noString,                         // is_updatable
+				noString,                         // is_insertable_into
+				noString,                         // is_trigger_updatable
+				noString,                         // is_trigger_deletable
+				noString,                         // is_trigger_insertable_into
 			)
 		})
 	},
@@ -612,7 +949,7 @@ func forEachDatabaseDesc(
 
 	sort.Sort(sortedDBDescs(dbDescs))
 	for _, db := range dbDescs {
-		if userCanSeeDatabase(db, p.session.User) {
+		if userCanSeeDatabase(p, db) {
 			if err := fn(db); err != nil {
 				return err
 			}
@@ -807,7 +1144,7 @@ func forEachTableDescWithTableLookupInternal(
 		sort.Strings(dbTableNames)
 		for _, tableName := range dbTableNames {
 			tableDesc := db.tables[tableName]
-			if userCanSeeTable(tableDesc, p.session.User, allowAdding) {
+			if userCanSeeTable(p, tableDesc, allowAdding) {
 				if err := fn(db.desc, tableDesc, tableLookup); err != nil {
 					return err
 				}
@@ -890,14 +1227,14 @@ func forEachUser(ctx context.Context, origPlanner *planner, fn func(username str
 	return nil
 }
 
-func userCanSeeDatabase(db *sqlbase.DatabaseDescriptor, user string) bool {
-	return userCanSeeDescriptor(db, user)
+func userCanSeeDatabase(p *planner, db *sqlbase.DatabaseDescriptor) bool {
+	return p.userCanSeeDescriptor(db)
 }
 
-func userCanSeeTable(table *sqlbase.TableDescriptor, user string, allowAdding bool) bool {
+func userCanSeeTable(p *planner, table *sqlbase.TableDescriptor, allowAdding bool) bool {
 	if !(table.State == sqlbase.TableDescriptor_PUBLIC ||
 		(allowAdding && table.State == sqlbase.TableDescriptor_ADD)) {
 		return false
 	}
-	return userCanSeeDescriptor(table, user)
+	return p.userCanSeeDescriptor(table)
 }
@@ -89,10 +89,25 @@ func (p *planner) Insert(
 	}
 	isUpsertReturning := false
 	if n.OnConflict != nil {
+		if n.OnConflict.IsUpsertAlias() {
+			p.noteFeature("upsert")
+		} else {
+			p.noteFeature("on_conflict")
+		}
 		if !n.OnConflict.DoNothing {
 			if err := p.CheckPrivilege(en.tableDesc, privilege.UPDATE); err != nil {
 				return nil, err
 			}
+			// The rows updated on conflict would not be checked against the
+			// policies of the table.
+			if policyExpr, err := p.rowLevelSecurityExpr(
+				ctx, en.tableDesc, policyCommandUpdate, false, /* withCheck */
+			); err != nil {
+				return nil, err
+			} else if policyExpr != nil {
+				return nil, pgerror.Unimplemented("upsert row level security",
+					"UPSERT and INSERT ... ON CONFLICT DO UPDATE are not supported on tables with row-level security")
+			}
 		}
 		if _, ok := n.Returning.(*tree.ReturningExprs); ok {
 			isUpsertReturning = true
@@ -181,6 +196,10 @@ func (p *planner) Insert(
 	if n.OnConflict == nil {
 		ti := tableInserterPool.Get().(*tableInserter)
 		*ti = tableInserter{ri: ri, autoCommit: p.autoCommit}
+		// Only a VALUES list can be split into committed chunks: any other
+		// source may read from the transaction being committed.
+		_, fromValues := rows.(*valuesNode)
+		ti.chunker.init(p, fromValues && len(fkTables) == 0)
 		tw = ti
 	} else {
 		updateExprs, conflictIndex, err := upsertExprsAndIndex(en.tableDesc, *n.OnConflict, ri.InsertCols)
@@ -262,7 +281,7 @@ func (p *planner) Insert(
 		tw:                    tw,
 	}
 
-	if err := in.checkHelper.init(ctx, p, tn, en.tableDesc); err != nil {
+	if err := in.checkHelper.init(ctx, p, tn, en.tableDesc, policyCommandInsert); err != nil {
 		return nil, err
 	}
 
@@ -455,7 +474,7 @@ func GenerateInsertRow(
 	for _, col := range tableDesc.Columns {
 		if !col.Nullable {
 			if i, ok := insertColIDtoRowIndex[col.ID]; !ok || rowVals[i] == tree.DNull {
-				return nil, sqlbase.NewNonNullViolationError(col.Name)
+				return nil, sqlbase.NewNonNullViolationError(tableDesc.Name, col.Name)
 			}
 		}
 	}
@@ -27,9 +27,12 @@ import (
 )
 
 // InternalExecutor can be used internally by cockroach to execute SQL
-// statements without needing to open a SQL connection. InternalExecutor assumes
-// that the caller has access to a cockroach KV client to handle connection and
-// transaction management.
+// statements without needing to open a SQL connection. The statements are
+// parameterized with placeholders, and run in a planner set up as for the
+// internal sessions, whose memory is accounted by the internal memory
+// metrics of the lease manager. The ...InTransaction methods run the
+// statements in a transaction managed by the caller; the other methods run
+// them in their own transactions.
 type InternalExecutor struct {
 	LeaseManager *LeaseManager
 }
@@ -71,6 +74,51 @@ func (ie InternalExecutor) QueryRowsInTransaction(
 	return p.queryRows(ctx, statement, qargs...)
 }
 
+// Exec executes the supplied SQL statement in a new transaction, which is
+// retried on retryable errors, and returns the number of rows affected.
+// Statements are currently executed as the root user.
+func (ie InternalExecutor) Exec(
+	ctx context.Context, opName string, statement string, qargs ...interface{},
+) (int, error) {
+	var n int
+	err := ie.LeaseManager.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		n, err = ie.ExecuteStatementInTransaction(ctx, opName, txn, statement, qargs...)
+		return err
+	})
+	return n, err
+}
+
+// QueryRow executes the supplied SQL statement in a new transaction, which is
+// retried on retryable errors, and returns the result. Statements are
+// currently executed as the root user.
+func (ie InternalExecutor) QueryRow(
+	ctx context.Context, opName string, statement string, qargs ...interface{},
+) (tree.Datums, error) {
+	var row tree.Datums
+	err := ie.LeaseManager.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		row, err = ie.QueryRowInTransaction(ctx, opName, txn, statement, qargs...)
+		return err
+	})
+	return row, err
+}
+
+// Query executes the supplied SQL statement in a new transaction, which is
+// retried on retryable errors, and returns the resulting rows. Statements are
+// currently executed as the root user.
+func (ie InternalExecutor) Query(
+	ctx context.Context, opName string, statement string, qargs ...interface{},
+) ([]tree.Datums, error) {
+	var rows []tree.Datums
+	err := ie.LeaseManager.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		rows, err = ie.QueryRowsInTransaction(ctx, opName, txn, statement, qargs...)
+		return err
+	})
+	return rows, err
+}
+
 // GetTableSpan gets the key span for a SQL table, including any indices.
 func (ie InternalExecutor) GetTableSpan(
 	ctx context.Context, user string, txn *client.Txn, dbName, tableName string,
@@ -0,0 +1,84 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestInternalExecutor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE d;
+CREATE TABLE d.t (k INT PRIMARY KEY, v STRING);
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	ie := sql.InternalExecutor{LeaseManager: s.LeaseManager().(*sql.LeaseManager)}
+	n, err := ie.Exec(ctx, "test", `INSERT INTO d.t VALUES ($1, $2), ($3, $4)`, 1, "a", 2, "b'; --")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 rows inserted, got %d", n)
+	}
+
+	// The values are passed as placeholders, not spliced in the statement.
+	row, err := ie.QueryRow(ctx, "test", `SELECT v FROM d.t WHERE k = $1`, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(row) != 1 || string(tree.MustBeDString(row[0])) != "b'; --" {
+		t.Fatalf("unexpected row %v", row)
+	}
+
+	// QueryRow returns no row when the statement returns none.
+	row, err = ie.QueryRow(ctx, "test", `SELECT v FROM d.t WHERE k = $1`, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row != nil {
+		t.Fatalf("expected no row, got %v", row)
+	}
+
+	rows, err := ie.Query(ctx, "test", `SELECT k FROM d.t ORDER BY k`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || tree.MustBeDInt(rows[0][0]) != 1 || tree.MustBeDInt(rows[1][0]) != 2 {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+
+	if _, err := ie.Query(ctx, "test", `SELECT * FROM d.missing`); !testutils.IsError(
+		err, `relation "d.missing" does not exist`,
+	) {
+		t.Fatalf("expected an undefined relation error, got %v", err)
+	}
+}
@@ -212,6 +212,7 @@ func isControllable(p *Payload, op string) error {
 			18139, "import jobs do not support %s", op)
 	case TypeBackup:
 	case TypeRestore:
+	case TypeChangefeed:
 	default:
 		return fmt.Errorf("%s jobs do not support %s", strings.ToLower(typ.String()), op)
 	}
@@ -626,6 +627,14 @@ func (p *Payload) Type() Type {
 		return TypeSchemaChange
 	case *Payload_Import:
 		return TypeImport
+	case *Payload_KeyRotation:
+		return TypeKeyRotation
+	case *Payload_Changefeed:
+		return TypeChangefeed
+	case *Payload_RowLevelTTL:
+		return TypeRowLevelTTL
+	case *Payload_ScrubRepair:
+		return TypeScrubRepair
 	default:
 		panic("Payload.Type called on a payload with an unknown details type")
 	}
@@ -648,6 +657,14 @@ func WrapPayloadDetails(details Details) interface {
 		return &Payload_SchemaChange{SchemaChange: &d}
 	case ImportDetails:
 		return &Payload_Import{Import: &d}
+	case KeyRotationDetails:
+		return &Payload_KeyRotation{KeyRotation: &d}
+	case ChangefeedDetails:
+		return &Payload_Changefeed{Changefeed: &d}
+	case RowLevelTTLDetails:
+		return &Payload_RowLevelTTL{RowLevelTTL: &d}
+	case ScrubRepairDetails:
+		return &Payload_ScrubRepair{ScrubRepair: &d}
 	default:
 		panic(fmt.Sprintf("jobs.WrapPayloadDetails: unknown details type %T", d))
 	}
@@ -669,6 +686,14 @@ func (p *Payload) UnwrapDetails() (Details, error) {
 		return *d.SchemaChange, nil
 	case *Payload_Import:
 		return *d.Import, nil
+	case *Payload_KeyRotation:
+		return *d.KeyRotation, nil
+	case *Payload_Changefeed:
+		return *d.Changefeed, nil
+	case *Payload_RowLevelTTL:
+		return *d.RowLevelTTL, nil
+	case *Payload_ScrubRepair:
+		return *d.ScrubRepair, nil
 	default:
 		return nil, errors.Errorf("jobs.Payload: unsupported details type %T", d)
 	}
@@ -403,6 +403,9 @@ func TestJobLifecycle(t *testing.T) {
 		}{
 			{jobs.TypeSchemaChange, jobs.SchemaChangeDetails{}, "schema change"},
 			{jobs.TypeImport, jobs.ImportDetails{}, "import"},
+			{jobs.TypeKeyRotation, jobs.KeyRotationDetails{}, "key rotation"},
+			{jobs.TypeRowLevelTTL, jobs.RowLevelTTLDetails{}, "row level ttl"},
+			{jobs.TypeScrubRepair, jobs.ScrubRepairDetails{}, "scrub repair"},
 		}
 		for _, tc := range testCases {
 			job, _ := createJob(tc.typ, jobs.WithoutCancel, jobs.Record{
@@ -201,13 +201,9 @@ func AddResumeHook(fn resumeHookFn) {
 }
 
 func (r *Registry) maybeAdoptJob(ctx context.Context, nl nodeLiveness) error {
-	var rows []tree.Datums
-	if err := r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
-		var err error
-		const stmt = `SELECT id, payload FROM system.jobs WHERE status IN ($1, $2) ORDER BY created DESC`
-		rows, err = r.ex.QueryRowsInTransaction(ctx, "adopt-job", txn, stmt, StatusPending, StatusRunning)
-		return err
-	}); err != nil {
+	const stmt = `SELECT id, payload FROM system.jobs WHERE status IN ($1, $2) ORDER BY created DESC`
+	rows, err := r.ex.Query(ctx, "adopt-job", stmt, StatusPending, StatusRunning)
+	if err != nil {
 		return err
 	}
 
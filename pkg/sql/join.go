@@ -130,6 +130,10 @@ type joinNode struct {
 	left  planDataSource
 	right planDataSource
 
+	// leftAsync, if set, runs the left plan in its own goroutine while the
+	// rows on the right are loaded into buckets. See maybeStartAsync.
+	leftAsync *asyncPlan
+
 	// pred represents the join predicate.
 	pred *joinPredicate
 
@@ -464,8 +468,10 @@ func (p *planner) makeJoin(
 
 // Start implements the planNode interface.
 func (n *joinNode) Start(params runParams) error {
-	if err := n.left.plan.Start(params); err != nil {
-		return err
+	if n.leftAsync = params.p.maybeStartAsync(params, n.left.plan); n.leftAsync == nil {
+		if err := n.left.plan.Start(params); err != nil {
+			return err
+		}
 	}
 	if err := n.right.plan.Start(params); err != nil {
 		return err
@@ -556,15 +562,15 @@ func (n *joinNode) Next(params runParams) (res bool, err error) {
 			return false, err
 		}
 
-		leftHasRow, err := n.left.plan.Next(params)
+		leftHasRow, err := n.nextLeft(params)
 		if err != nil {
-			return false, nil
+			return false, err
 		}
 		if !leftHasRow {
 			break
 		}
 
-		lrow := n.left.plan.Values()
+		lrow := n.leftValues()
 		encoding, containsNull, err := n.pred.encode(scratch, lrow, n.pred.leftEqualityIndices)
 		if err != nil {
 			return false, err
@@ -720,9 +726,29 @@ func (n *joinNode) Close(ctx context.Context) {
 	n.bucketsMemAcc.Close(ctx)
 
 	n.right.plan.Close(ctx)
+	if n.leftAsync != nil {
+		n.leftAsync.close(ctx)
+		n.leftAsync = nil
+	}
 	n.left.plan.Close(ctx)
 }
 
+// nextLeft advances the left plan, possibly running asynchronously.
+func (n *joinNode) nextLeft(params runParams) (bool, error) {
+	if n.leftAsync != nil {
+		return n.leftAsync.next(params.ctx)
+	}
+	return n.left.plan.Next(params)
+}
+
+// leftValues returns the current row of the left plan.
+func (n *joinNode) leftValues() tree.Datums {
+	if n.leftAsync != nil {
+		return n.leftAsync.values()
+	}
+	return n.left.plan.Values()
+}
+
 func (n *joinNode) joinOrdering() physicalProps {
 	if len(n.mergeJoinOrdering) == 0 {
 		return physicalProps{}
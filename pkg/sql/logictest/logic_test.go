@@ -249,6 +249,9 @@ type testClusterConfig struct {
 	// if set, queries using distSQL processors that can fall back to disk do
 	// so immediately, using only their disk-based implementation.
 	distSQLUseDisk bool
+	// if set, supported distSQL processors run on the columnar execution
+	// engine.
+	vectorize bool
 	// if set, any logic statement expected to succeed and parallelizable
 	// using RETURNING NOTHING syntax will be parallelized transparently.
 	// See logicStatement.parallelizeStmts.
@@ -278,6 +281,7 @@ var logicTestConfigs = []testClusterConfig{
 	{name: "5node", numNodes: 5, overrideDistSQLMode: "Off"},
 	{name: "5node-distsql", numNodes: 5, overrideDistSQLMode: "On"},
 	{name: "5node-distsql-disk", numNodes: 5, overrideDistSQLMode: "On", distSQLUseDisk: true},
+	{name: "5node-distsql-vectorize", numNodes: 5, overrideDistSQLMode: "On", vectorize: true},
 }
 
 // An index in the above slice.
@@ -735,6 +739,29 @@ func (t *logicTest) setup(cfg testClusterConfig) {
 		})
 	}
 
+	if cfg.vectorize {
+		if _, err := t.cluster.ServerConn(0).Exec(
+			"SET CLUSTER SETTING sql.distsql.vectorize.enabled = true",
+		); err != nil {
+			t.Fatal(err)
+		}
+		// Wait until all servers are aware of the setting.
+		testutils.SucceedsSoon(t.t, func() error {
+			for i := 0; i < t.cluster.NumServers(); i++ {
+				var enabled bool
+				if err := t.cluster.ServerConn(i).QueryRow(
+					"SHOW CLUSTER SETTING sql.distsql.vectorize.enabled",
+				).Scan(&enabled); err != nil {
+					t.Fatal(errors.Wrapf(err, "%d", i))
+				}
+				if !enabled {
+					return errors.Errorf("node %d is still waiting for the vectorize setting", i)
+				}
+			}
+			return nil
+		})
+	}
+
 	// db may change over the lifetime of this function, with intermediate
 	// values cached in t.clients and finally closed in t.close().
 	t.cleanupFuncs = append(t.cleanupFuncs, t.setUser(security.RootUser))
@@ -0,0 +1,202 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// This file contains the support for the mutation_chunk_size session
+// variable. When it is set, INSERT and UPDATE send their writes to KV every
+// mutation_chunk_size rows instead of accumulating the whole statement in a
+// single batch, which would otherwise exceed the maximum raft command size
+// for large statements.
+//
+// An INSERT whose rows come from a VALUES list into a table without outgoing
+// foreign keys, running in an implicit transaction, goes one step further and
+// commits every chunk in its own KV transaction. Its source doesn't read from
+// the database, so nothing else in the statement depends on the transaction
+// that was committed. Such a statement is no longer atomic: if it fails, the
+// chunks committed so far remain visible.
+//
+// An UPDATE of a table without foreign keys, running in an implicit
+// transaction, also commits its chunks when its rows come from a scan of the
+// primary index, without ORDER BY or LIMIT, and its primary key is left
+// unchanged. The scan is then restarted in the transaction of every new
+// chunk, past the last updated row, so that the rows of the committed chunks
+// are not read, and updated, again.
+
+// mutationChunker is embedded in the table writers that support chunking.
+// The zero value disables chunking.
+type mutationChunker struct {
+	// chunkSize is the number of rows after which the pending batch is sent.
+	chunkSize int
+	// commitChunk, if set, commits every chunk instead of only sending it. It
+	// is given the batch holding the chunk's writes and returns the KV
+	// transaction to use for the rest of the statement.
+	commitChunk func(ctx context.Context, b *client.Batch) (*client.Txn, error)
+
+	pendingRows   int
+	committedRows int
+}
+
+// init configures chunking for a table writer created by p.
+func (c *mutationChunker) init(p *planner, canCommitChunks bool) {
+	c.chunkSize = int(p.session.MutationChunkSize)
+	if c.chunkSize > 0 && canCommitChunks && p.autoCommit {
+		c.commitChunk = p.commitMutationChunk
+	}
+}
+
+// rowAdded is called after each row is added to b. It sends or commits the
+// batch when the current chunk is full and returns the batch and transaction
+// the next rows should go to.
+func (c *mutationChunker) rowAdded(
+	ctx context.Context, txn *client.Txn, b *client.Batch, tableDesc *sqlbase.TableDescriptor,
+) (*client.Txn, *client.Batch, error) {
+	if c.chunkSize <= 0 {
+		return txn, b, nil
+	}
+	c.pendingRows++
+	if c.pendingRows < c.chunkSize {
+		return txn, b, nil
+	}
+	rows := c.pendingRows
+	c.pendingRows = 0
+	if c.commitChunk == nil {
+		if err := txn.Run(ctx, b); err != nil {
+			return txn, b, sqlbase.ConvertBatchError(ctx, tableDesc, b)
+		}
+		return txn, txn.NewBatch(), nil
+	}
+	newTxn, err := c.commitChunk(ctx, b)
+	if err != nil {
+		return txn, b, c.wrapErr(sqlbase.ConvertBatchError(ctx, tableDesc, b))
+	}
+	c.committedRows += rows
+	return newTxn, newTxn.NewBatch(), nil
+}
+
+// wrapErr converts retryable errors encountered after some chunks have been
+// committed into errors that aren't retried automatically: replaying the
+// statement would write the committed rows a second time.
+func (c *mutationChunker) wrapErr(err error) error {
+	if c.committedRows == 0 {
+		return err
+	}
+	if _, ok := err.(*roachpb.HandledRetryableTxnError); !ok {
+		return err
+	}
+	return pgerror.NewErrorf(pgerror.CodeTransactionRollbackError,
+		"statement aborted after committing %d rows (mutation_chunk_size): %v",
+		c.committedRows, err)
+}
+
+// commitMutationChunk commits the current implicit transaction together with
+// b and starts a new KV transaction, with the same isolation and priority, for
+// the remainder of the statement.
+func (p *planner) commitMutationChunk(ctx context.Context, b *client.Batch) (*client.Txn, error) {
+	if err := p.txn.CommitInBatch(ctx, b); err != nil {
+		return nil, err
+	}
+	ts := &p.session.TxnState
+	cfg := p.ExecCfg()
+	txn := client.NewTxn(cfg.DB, cfg.NodeID.Get())
+	txn.SetDebugName(sqlImplicitTxnName)
+	ts.mu.Lock()
+	ts.mu.txn = txn
+	ts.mu.Unlock()
+	if err := ts.setIsolationLevel(ts.isolation); err != nil {
+		return nil, err
+	}
+	if err := ts.setPriority(ts.priority); err != nil {
+		return nil, err
+	}
+	p.setTxn(txn)
+	return txn, nil
+}
+
+// chunkedScan returns the scan feeding an update if it can be resumed past the
+// last updated row in a new transaction: it must scan the primary index
+// forward, and its rows must go straight to the update, possibly through a
+// render. It returns nil otherwise.
+func (u *updateNode) chunkedScan() *scanNode {
+	plan := u.run.rows
+	if r, ok := plan.(*renderNode); ok {
+		plan = r.source.plan
+	}
+	scan, ok := plan.(*scanNode)
+	if !ok || scan.index.ID != u.tableDesc.PrimaryIndex.ID || scan.reverse || scan.hardLimit != 0 {
+		return nil
+	}
+	return scan
+}
+
+// resumeChunkedScan restricts the spans of the scan feeding an update whose
+// chunk was just committed to the keys following lastRow, the old values of
+// the row updated last, and restarts the scan in the transaction of the next
+// chunk.
+func (u *updateNode) resumeChunkedScan(lastRow tree.Datums) error {
+	desc := u.tableDesc
+	rowKey, _, err := sqlbase.EncodeIndexKey(
+		desc, &desc.PrimaryIndex, u.tw.ru.FetchColIDtoRowIndex, lastRow,
+		sqlbase.MakeIndexKeyPrefix(desc, desc.PrimaryIndex.ID),
+	)
+	if err != nil {
+		return err
+	}
+	resumeKey := roachpb.Key(rowKey).PrefixEnd()
+	scan := u.run.chunkScan
+	spans := scan.spans[:0]
+	for _, sp := range scan.spans {
+		if sp.EndKey.Compare(resumeKey) <= 0 {
+			continue
+		}
+		if sp.Key.Compare(resumeKey) < 0 {
+			sp.Key = resumeKey
+		}
+		spans = append(spans, sp)
+	}
+	scan.spans = spans
+	scan.scanInitialized = false
+	u.run.chunkScanDone = len(spans) == 0
+	return nil
+}
+
+// setMutationChunkSize implements SET mutation_chunk_size.
+func setMutationChunkSize(_ context.Context, session *Session, values []tree.TypedExpr) error {
+	d, err := evalLimitVal(session, `mutation_chunk_size`, values)
+	if err != nil {
+		return err
+	}
+	v, ok := d.(*tree.DInt)
+	if !ok {
+		return fmt.Errorf("set mutation_chunk_size: requires an integer value: %s is a %s",
+			d, d.ResolvedType())
+	}
+	if *v < 0 {
+		return fmt.Errorf("set mutation_chunk_size: cannot be negative")
+	}
+	session.MutationChunkSize = int64(*v)
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import "fmt"
+
+// NoticeSeverity is the severity of a Notice.
+type NoticeSeverity int
+
+const (
+	// NoticeSeverityNotice is for information the client may want to know
+	// about, e.g. that DROP ... IF EXISTS did not find the object.
+	NoticeSeverityNotice NoticeSeverity = iota
+	// NoticeSeverityWarning is for something the client probably did not
+	// intend, e.g. that part of a statement was ignored.
+	NoticeSeverityWarning
+)
+
+// String returns the severity as spelled in Postgres NoticeResponse messages.
+func (s NoticeSeverity) String() string {
+	switch s {
+	case NoticeSeverityNotice:
+		return "NOTICE"
+	case NoticeSeverityWarning:
+		return "WARNING"
+	default:
+		return fmt.Sprintf("NoticeSeverity(%d)", int(s))
+	}
+}
+
+// Notice is a non-fatal message attached to the results of a statement. It
+// is delivered to pgwire clients as a NoticeResponse message, before the
+// statement's CommandComplete.
+type Notice struct {
+	Severity NoticeSeverity
+	// Code is the SQLSTATE of the notice. See pgerror/codes.go.
+	Code    string
+	Message string
+	Hint    string
+}
+
+// addNotice attaches a notice to the results of the statement being
+// planned or executed. Notices of parallelized statements that are produced
+// after planning are discarded, since their results have already been sent.
+func (p *planner) addNotice(
+	severity NoticeSeverity, code string, format string, args ...interface{},
+) {
+	p.notices = append(p.notices, Notice{
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// sendNotices sends the notices accumulated by the planner to res.
+func (p *planner) sendNotices(res StatementResult) {
+	for _, n := range p.notices {
+		res.AddNotice(n)
+	}
+	p.notices = nil
+}
@@ -0,0 +1,295 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"encoding/json"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// This file contains the support for LISTEN, UNLISTEN and NOTIFY. Every
+// notification is added to gossip under its own key, from where it reaches
+// the other nodes of the cluster, and each node delivers it to its sessions
+// listening on the notification's channel. Since every notification is
+// broadcast to every node, the mechanism is suited to low-rate signals such as
+// cache invalidations, not to general purpose messaging.
+
+const (
+	// notificationTTL is how long notifications are kept in gossip. It must be
+	// long enough for them to reach every node.
+	notificationTTL = time.Minute
+
+	// maxNotificationPayload is the size limit of NOTIFY payloads, the same as
+	// in Postgres.
+	maxNotificationPayload = 8000
+
+	// maxPendingNotifications is the number of notifications queued for a
+	// session whose client doesn't consume them, after which the oldest are
+	// dropped.
+	maxPendingNotifications = 10000
+)
+
+// Notification is an asynchronous notification sent with NOTIFY.
+type Notification struct {
+	// ProcessID identifies the sender. It is the ID of the node the notifying
+	// session is connected to, which is also the process ID given to the
+	// connections of that node.
+	ProcessID int32  `json:"pid"`
+	Channel   string `json:"channel"`
+	Payload   string `json:"payload,omitempty"`
+}
+
+// notificationRegistry keeps track of the sessions of the node listening on
+// each channel and delivers notifications to them.
+type notificationRegistry struct {
+	ambientCtx log.AmbientContext
+	// gossip is nil until start is called, in which case notifications are only
+	// delivered locally.
+	gossip *gossip.Gossip
+
+	mu struct {
+		syncutil.Mutex
+		// seq numbers the notifications sent by the node.
+		seq       int64
+		listeners map[string]map[*sessionNotifications]struct{}
+		// seen holds the keys of the notifications delivered recently, with their
+		// expiration: gossip can run its callback more than once for an info.
+		seen      map[string]time.Time
+		nextPrune time.Time
+	}
+}
+
+// start connects the registry to gossip.
+func (r *notificationRegistry) start(ambientCtx log.AmbientContext, g *gossip.Gossip) {
+	r.ambientCtx = ambientCtx
+	r.gossip = g
+	r.mu.Lock()
+	// The sequence starts from the current time, so that the keys used by a
+	// restarted node don't collide with the ones it used before.
+	r.mu.seq = timeutil.Now().UnixNano()
+	r.mu.Unlock()
+	g.RegisterCallback(gossip.MakePrefixPattern(gossip.KeyNotificationPrefix), r.gossipUpdate)
+}
+
+// gossipUpdate is the gossip callback receiving the notifications of the
+// cluster.
+func (r *notificationRegistry) gossipUpdate(key string, content roachpb.Value) {
+	var n Notification
+	b, err := content.GetBytes()
+	if err == nil {
+		err = json.Unmarshal(b, &n)
+	}
+	if err != nil {
+		log.Warningf(r.ambientCtx.AnnotateCtx(context.TODO()), "invalid notification %s: %s", key, err)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.mu.seen[key]; ok {
+		return
+	}
+	r.markSeenLocked(key)
+	r.deliverLocked(n)
+}
+
+// publish delivers notifications to the listening sessions of the node and
+// broadcasts them to the rest of the cluster.
+func (r *notificationRegistry) publish(ctx context.Context, notifications []Notification) {
+	for _, n := range notifications {
+		r.mu.Lock()
+		r.mu.seq++
+		key := gossip.MakeNotificationKey(roachpb.NodeID(n.ProcessID), r.mu.seq)
+		r.markSeenLocked(key)
+		r.deliverLocked(n)
+		r.mu.Unlock()
+
+		if r.gossip == nil {
+			continue
+		}
+		b, err := json.Marshal(n)
+		if err == nil {
+			err = r.gossip.AddInfo(key, b, notificationTTL)
+		}
+		if err != nil {
+			log.Warningf(ctx, "failed to broadcast notification on channel %q: %s", n.Channel, err)
+		}
+	}
+}
+
+func (r *notificationRegistry) markSeenLocked(key string) {
+	now := timeutil.Now()
+	if r.mu.seen == nil {
+		r.mu.seen = make(map[string]time.Time)
+	}
+	if now.After(r.mu.nextPrune) {
+		for k, expiration := range r.mu.seen {
+			if now.After(expiration) {
+				delete(r.mu.seen, k)
+			}
+		}
+		r.mu.nextPrune = now.Add(notificationTTL)
+	}
+	// Gossip drops infos once their TTL has passed, so they can't come back
+	// after that.
+	r.mu.seen[key] = now.Add(2 * notificationTTL)
+}
+
+func (r *notificationRegistry) deliverLocked(n Notification) {
+	for s := range r.mu.listeners[n.Channel] {
+		s.push(n)
+	}
+}
+
+func (r *notificationRegistry) listen(s *sessionNotifications, channel string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mu.listeners == nil {
+		r.mu.listeners = make(map[string]map[*sessionNotifications]struct{})
+	}
+	l, ok := r.mu.listeners[channel]
+	if !ok {
+		l = make(map[*sessionNotifications]struct{})
+		r.mu.listeners[channel] = l
+	}
+	l[s] = struct{}{}
+}
+
+func (r *notificationRegistry) unlisten(s *sessionNotifications, channel string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l := r.mu.listeners[channel]
+	delete(l, s)
+	if len(l) == 0 {
+		delete(r.mu.listeners, channel)
+	}
+}
+
+// sessionNotifications holds the channels a session listens to and the
+// notifications it received.
+type sessionNotifications struct {
+	registry *notificationRegistry
+	// channels is only accessed by the session's goroutine.
+	channels map[string]struct{}
+
+	mu struct {
+		syncutil.Mutex
+		pending []Notification
+	}
+}
+
+func (sn *sessionNotifications) listen(channel string) {
+	if _, ok := sn.channels[channel]; ok {
+		return
+	}
+	if sn.channels == nil {
+		sn.channels = make(map[string]struct{})
+	}
+	sn.channels[channel] = struct{}{}
+	sn.registry.listen(sn, channel)
+}
+
+func (sn *sessionNotifications) unlisten(channel string) {
+	if _, ok := sn.channels[channel]; !ok {
+		return
+	}
+	delete(sn.channels, channel)
+	sn.registry.unlisten(sn, channel)
+}
+
+func (sn *sessionNotifications) unlistenAll() {
+	for channel := range sn.channels {
+		sn.unlisten(channel)
+	}
+}
+
+func (sn *sessionNotifications) push(n Notification) {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	if len(sn.mu.pending) >= maxPendingNotifications {
+		copy(sn.mu.pending, sn.mu.pending[1:])
+		sn.mu.pending = sn.mu.pending[:len(sn.mu.pending)-1]
+	}
+	sn.mu.pending = append(sn.mu.pending, n)
+}
+
+// PendingNotifications returns the notifications received by the session
+// since the last call.
+func (s *Session) PendingNotifications() []Notification {
+	sn := &s.notifications
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	pending := sn.mu.pending
+	sn.mu.pending = nil
+	return pending
+}
+
+// Listen implements the LISTEN statement.
+// See https://www.postgresql.org/docs/current/static/sql-listen.html for details.
+// Privileges: None.
+func (p *planner) Listen(ctx context.Context, n *tree.Listen) (planNode, error) {
+	p.session.notifications.listen(string(n.Channel))
+	return &zeroNode{}, nil
+}
+
+// Unlisten implements the UNLISTEN statement.
+// See https://www.postgresql.org/docs/current/static/sql-unlisten.html for details.
+// Privileges: None.
+func (p *planner) Unlisten(ctx context.Context, n *tree.Unlisten) (planNode, error) {
+	if n.Channel == "" {
+		p.session.notifications.unlistenAll()
+	} else {
+		p.session.notifications.unlisten(string(n.Channel))
+	}
+	return &zeroNode{}, nil
+}
+
+// Notify implements the NOTIFY statement. Notifications sent in a transaction
+// are held back until it commits.
+// See https://www.postgresql.org/docs/current/static/sql-notify.html for details.
+// Privileges: None.
+func (p *planner) Notify(ctx context.Context, n *tree.Notify) (planNode, error) {
+	if len(n.Payload) >= maxNotificationPayload {
+		return nil, pgerror.NewError(pgerror.CodeInvalidParameterValueError, "payload string too long")
+	}
+	notification := Notification{
+		ProcessID: int32(p.evalCtx.NodeID),
+		Channel:   string(n.Channel),
+		Payload:   n.Payload,
+	}
+	ts := &p.session.TxnState
+	if ts.implicitTxn {
+		p.session.notifications.registry.publish(ctx, []Notification{notification})
+		return &zeroNode{}, nil
+	}
+	// As in Postgres, identical notifications sent in the same transaction are
+	// only delivered once.
+	for _, queued := range ts.notifications {
+		if queued == notification {
+			return &zeroNode{}, nil
+		}
+	}
+	ts.notifications = append(ts.notifications, notification)
+	return &zeroNode{}, nil
+}
@@ -292,6 +292,11 @@ func (p *planner) propagateFilters(
 			return plan, extraFilter, err
 		}
 
+	case *explainDebugNode:
+		if n.plan, err = p.triggerFilterPropagation(ctx, n.plan); err != nil {
+			return plan, extraFilter, err
+		}
+
 	case *delayedNode:
 		if n.plan != nil {
 			if n.plan, err = p.triggerFilterPropagation(ctx, n.plan); err != nil {
@@ -311,8 +316,13 @@ func (p *planner) propagateFilters(
 
 	case *alterTableNode:
 	case *alterSequenceNode:
+	case *alterUserSetConnectionLimitNode:
 	case *alterUserSetPasswordNode:
+	case *alterUserSetQuotaNode:
+	case *alterUserSetStatementsNode:
+	case *alterUserSetValidUntilNode:
 	case *cancelQueryNode:
+	case *cancelSessionNode:
 	case *scrubNode:
 	case *controlJobNode:
 	case *copyNode:
@@ -328,6 +338,7 @@ func (p *planner) propagateFilters(
 	case *dropSequenceNode:
 	case *dropUserNode:
 	case *hookFnNode:
+	case *fetchNode:
 	case *valueGenerator:
 	case *valuesNode:
 	case *setNode:
@@ -74,7 +74,14 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 
 	case *sortNode:
 		if n.needSort && numRows != math.MaxInt64 {
-			v := p.newSortValues(n.ordering, planColumns(n.plan), int(numRows))
+			// The limit only bounds the number of rows kept by the sort; the
+			// input may well have fewer rows, so don't preallocate space for
+			// more than a reasonable number of them.
+			capacity := numRows
+			if capacity > maxSortCapacityHint {
+				capacity = maxSortCapacityHint
+			}
+			v := p.newSortValues(n.ordering, planColumns(n.plan), int(capacity))
 			if soft {
 				n.sortStrategy = newIterativeSortStrategy(v)
 			} else {
@@ -150,6 +157,8 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 		p.setUnlimited(n.plan)
 	case *traceNode:
 		p.setUnlimited(n.plan)
+	case *explainDebugNode:
+		p.setUnlimited(n.plan)
 	case *explainPlanNode:
 		if n.expanded {
 			p.setUnlimited(n.plan)
@@ -164,8 +173,13 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *valuesNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
+	case *alterUserSetConnectionLimitNode:
 	case *alterUserSetPasswordNode:
+	case *alterUserSetQuotaNode:
+	case *alterUserSetStatementsNode:
+	case *alterUserSetValidUntilNode:
 	case *cancelQueryNode:
+	case *cancelSessionNode:
 	case *scrubNode:
 	case *controlJobNode:
 	case *copyNode:
@@ -183,6 +197,7 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
+	case *fetchNode:
 	case *valueGenerator:
 	case *setNode:
 	case *setClusterSettingNode:
@@ -37,6 +37,9 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *traceNode:
 		setNeededColumns(n.plan, allColumns(n.plan))
 
+	case *explainDebugNode:
+		setNeededColumns(n.plan, allColumns(n.plan))
+
 	case *explainPlanNode:
 		if n.optimized {
 			setNeededColumns(n.plan, allColumns(n.plan))
@@ -189,8 +192,13 @@ func setNeededColumns(plan planNode, needed []bool) {
 
 	case *alterTableNode:
 	case *alterSequenceNode:
+	case *alterUserSetConnectionLimitNode:
 	case *alterUserSetPasswordNode:
+	case *alterUserSetQuotaNode:
+	case *alterUserSetStatementsNode:
+	case *alterUserSetValidUntilNode:
 	case *cancelQueryNode:
+	case *cancelSessionNode:
 	case *controlJobNode:
 	case *scrubNode:
 	case *copyNode:
@@ -208,6 +216,7 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *zeroNode:
 	case *unaryNode:
 	case *hookFnNode:
+	case *fetchNode:
 	case *valueGenerator:
 	case *setNode:
 	case *setClusterSettingNode:
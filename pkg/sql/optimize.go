@@ -35,6 +35,10 @@ func (p *planner) optimizePlan(
 	// sub-expressions).
 	setNeededColumns(plan, needed)
 
+	if err := p.checkColumnPrivileges(ctx, plan); err != nil {
+		return plan, err
+	}
+
 	newPlan, err := p.triggerFilterPropagation(ctx, plan)
 	if err != nil {
 		return plan, err
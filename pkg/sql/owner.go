@@ -0,0 +1,277 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// AlterTableOwner changes the owner of a table, view or sequence.
+// Privileges: ownership of the object, and membership in the new owner role.
+//   Notes: postgres requires the same, plus CREATE on the schema.
+func (p *planner) AlterTableOwner(ctx context.Context, n *tree.AlterTableOwner) (planNode, error) {
+	tn, err := n.Name.NormalizeWithDatabaseName(p.session.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	var tableDesc *sqlbase.TableDescriptor
+	if n.IsView {
+		tableDesc, err = getViewDesc(ctx, p.txn, p.getVirtualTabler(), tn)
+	} else if n.IsSequence {
+		tableDesc, err = getSequenceDesc(ctx, p.txn, p.getVirtualTabler(), tn)
+	} else {
+		tableDesc, err = getTableDesc(ctx, p.txn, p.getVirtualTabler(), tn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc == nil {
+		if n.IfExists {
+			// Noop.
+			return &zeroNode{}, nil
+		}
+		return nil, sqlbase.NewUndefinedRelationError(tn)
+	}
+	if tableDesc.State != sqlbase.TableDescriptor_PUBLIC {
+		return nil, sqlbase.NewUndefinedRelationError(tn)
+	}
+
+	if isVirtualDescriptor(tableDesc) || sqlbase.IsReservedID(tableDesc.ID) {
+		return nil, pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+			"cannot change the owner of system object %s", tn)
+	}
+	g, err := p.loadRoleGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkIsOwner(g, tableDesc, tableDesc.Kind(), tn.String()); err != nil {
+		return nil, err
+	}
+	newOwner, err := p.checkNewOwner(ctx, g, n.Owner)
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc.Privileges.GetOwner() == newOwner {
+		// Noop.
+		return &zeroNode{}, nil
+	}
+
+	tableDesc.Privileges.Owner = newOwner
+	if err := tableDesc.Validate(ctx, p.txn); err != nil {
+		return nil, err
+	}
+	if err := tableDesc.SetUpVersion(); err != nil {
+		return nil, err
+	}
+	if err := p.writeTableDesc(ctx, tableDesc); err != nil {
+		return nil, err
+	}
+	if err := p.logAlterOwnerEvent(ctx, n, tableDesc); err != nil {
+		return nil, err
+	}
+	p.notifySchemaChange(tableDesc, sqlbase.InvalidMutationID)
+	return &zeroNode{}, nil
+}
+
+// AlterDatabaseOwner changes the owner of a database.
+// Privileges: ownership of the database, and membership in the new owner
+// role.
+//   Notes: postgres also requires the CREATEDB attribute.
+func (p *planner) AlterDatabaseOwner(
+	ctx context.Context, n *tree.AlterDatabaseOwner,
+) (planNode, error) {
+	if n.Name == "" {
+		return nil, errEmptyDatabaseName
+	}
+	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), string(n.Name))
+	if err != nil {
+		return nil, err
+	}
+	if isVirtualDescriptor(dbDesc) || sqlbase.IsReservedID(dbDesc.ID) {
+		return nil, pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+			"cannot change the owner of system object %s", n.Name)
+	}
+	g, err := p.loadRoleGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkIsOwner(g, dbDesc, "database", string(n.Name)); err != nil {
+		return nil, err
+	}
+	newOwner, err := p.checkNewOwner(ctx, g, n.Owner)
+	if err != nil {
+		return nil, err
+	}
+	if dbDesc.Privileges.GetOwner() == newOwner {
+		// Noop.
+		return &zeroNode{}, nil
+	}
+
+	dbDesc.Privileges.Owner = newOwner
+	if err := dbDesc.Validate(); err != nil {
+		return nil, err
+	}
+	if err := p.txn.Put(ctx, sqlbase.MakeDescMetadataKey(dbDesc.ID), sqlbase.WrapDescriptor(dbDesc)); err != nil {
+		return nil, err
+	}
+	if err := p.logAlterOwnerEvent(ctx, n, dbDesc); err != nil {
+		return nil, err
+	}
+	return &zeroNode{}, nil
+}
+
+// ReassignOwnedBy transfers the ownership of all the databases, tables,
+// views and sequences owned by the given roles to another role.
+// Privileges: membership in the old roles and in the new role.
+//   Notes: postgres only reassigns the objects of the current database and
+//          the shared objects.
+func (p *planner) ReassignOwnedBy(ctx context.Context, n *tree.ReassignOwnedBy) (planNode, error) {
+	g, err := p.loadRoleGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var memberOf map[string]bool
+	if p.session.User != security.RootUser {
+		memberOf = g.memberOf(p.session.User, false /* inheritingOnly */)
+	}
+	oldOwners := make(map[string]struct{}, len(n.OldRoles))
+	for _, name := range n.OldRoles {
+		role := name.Normalize()
+		if err := p.checkRoleExists(ctx, role); err != nil {
+			return nil, err
+		}
+		if p.session.User != security.RootUser && role != p.session.User {
+			if _, ok := memberOf[role]; !ok {
+				return nil, pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+					"user %s must be a member of role %s", p.session.User, role)
+			}
+		}
+		oldOwners[role] = struct{}{}
+	}
+	newOwner, err := p.checkNewOwner(ctx, g, n.NewRole)
+	if err != nil {
+		return nil, err
+	}
+
+	descs, err := getAllDescriptors(ctx, p.txn)
+	if err != nil {
+		return nil, err
+	}
+	b := p.txn.NewBatch()
+	var reassigned []sqlbase.DescriptorProto
+	for _, desc := range descs {
+		if sqlbase.IsReservedID(desc.GetID()) {
+			continue
+		}
+		privs := desc.GetPrivileges()
+		if _, ok := oldOwners[privs.GetOwner()]; !ok || privs.GetOwner() == newOwner {
+			continue
+		}
+		switch d := desc.(type) {
+		case *sqlbase.DatabaseDescriptor:
+			d.Privileges.Owner = newOwner
+			if err := d.Validate(); err != nil {
+				return nil, err
+			}
+		case *sqlbase.TableDescriptor:
+			if d.Dropped() {
+				continue
+			}
+			d.Privileges.Owner = newOwner
+			if err := d.Validate(ctx, p.txn); err != nil {
+				return nil, err
+			}
+			if err := d.SetUpVersion(); err != nil {
+				return nil, err
+			}
+			p.notifySchemaChange(d, sqlbase.InvalidMutationID)
+		}
+		b.Put(sqlbase.MakeDescMetadataKey(desc.GetID()), sqlbase.WrapDescriptor(desc))
+		reassigned = append(reassigned, desc)
+	}
+	if err := p.txn.Run(ctx, b); err != nil {
+		return nil, err
+	}
+	for _, desc := range reassigned {
+		if err := p.logAlterOwnerEvent(ctx, n, desc); err != nil {
+			return nil, err
+		}
+	}
+	return &zeroNode{}, nil
+}
+
+// logAlterOwnerEvent records the change of the owner of a database, table,
+// view or sequence in the event log. This is an auditable log event and is
+// recorded in the same transaction as the descriptor update.
+func (p *planner) logAlterOwnerEvent(
+	ctx context.Context, stmt tree.Statement, desc sqlbase.DescriptorProto,
+) error {
+	return MakeEventLogger(p.LeaseMgr()).InsertEventRecord(
+		ctx,
+		p.txn,
+		EventLogAlterOwner,
+		int32(desc.GetID()),
+		int32(p.evalCtx.NodeID),
+		&sqlbase.AlterOwnerEvent{
+			TargetName: desc.GetName(),
+			Owner:      desc.GetPrivileges().GetOwner(),
+			Statement:  stmt.String(),
+			User:       p.session.User,
+		},
+	)
+}
+
+// checkIsOwner errors if the session user is neither root, nor the owner of
+// the object, nor a member of the owner role.
+func (p *planner) checkIsOwner(
+	g roleGraph, desc sqlbase.DescriptorProto, kind string, name string,
+) error {
+	owner := desc.GetPrivileges().GetOwner()
+	if p.session.User == security.RootUser || p.session.User == owner {
+		return nil
+	}
+	if _, ok := g.memberOf(p.session.User, false /* inheritingOnly */)[owner]; ok {
+		return nil
+	}
+	return pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+		"must be owner of %s %s", kind, name)
+}
+
+// checkNewOwner returns the normalized name of the new owner of objects, and
+// errors if it isn't a user or role the session user is a member of.
+func (p *planner) checkNewOwner(ctx context.Context, g roleGraph, name tree.Name) (string, error) {
+	owner := name.Normalize()
+	if owner == security.PublicRole || owner == security.NodeUser {
+		return "", pgerror.NewErrorf(pgerror.CodeReservedNameError,
+			"role %s cannot own objects", owner)
+	}
+	if err := p.checkRoleExists(ctx, owner); err != nil {
+		return "", err
+	}
+	if p.session.User == security.RootUser || p.session.User == owner {
+		return owner, nil
+	}
+	if _, ok := g.memberOf(p.session.User, false /* inheritingOnly */)[owner]; ok {
+		return owner, nil
+	}
+	return "", pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+		"user %s must be a member of role %s", p.session.User, owner)
+}
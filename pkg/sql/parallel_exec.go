@@ -0,0 +1,263 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+)
+
+// parallelExecMaxGoroutines is the number of goroutines, in addition to the
+// one running the statement, that a locally-executed query may use to run
+// independent stages of its plan concurrently.
+var parallelExecMaxGoroutines = settings.RegisterIntSetting(
+	"sql.parallel_execution.max_goroutines",
+	"maximum number of additional goroutines a query may use to run independent plan stages concurrently",
+	4,
+)
+
+// asyncPlanBufferSize is the number of rows an asyncPlan may produce ahead of
+// its consumer.
+const asyncPlanBufferSize = 64
+
+// asyncRow is a row, or an error, produced by an asyncPlan.
+type asyncRow struct {
+	row tree.Datums
+	err error
+}
+
+// asyncPlan runs a plan in a separate goroutine and buffers its rows until
+// they are consumed. It is used to overlap independent stages of a plan, such
+// as the two branches of a UNION or the two sides of a join, whose inputs
+// would otherwise be read one after the other.
+//
+// The plan is started and iterated with a private copy of the planner, so its
+// nodes do not share allocators, evaluation contexts or cancellation checkers
+// with the rest of the query. Only plans made of nodes that do not otherwise
+// touch the planner or session are run asynchronously; see
+// canRunPlanAsync.
+//
+// The asyncPlan does not own the plan: the caller must call close, which
+// waits for the goroutine to finish, before closing the plan.
+type asyncPlan struct {
+	plan planNode
+	// owner is the planner that started the asyncPlan. Its count of
+	// running async plans is decremented on close.
+	owner *planner
+
+	rows   chan asyncRow
+	cur    tree.Datums
+	cancel context.CancelFunc
+	// done is closed when the goroutine running the plan terminates.
+	done chan struct{}
+
+	// rowAcc is the memory account used by the plan's evaluation context in
+	// place of the query's ActiveMemAcc, which is cleared by the consumer
+	// for every row it produces.
+	rowAcc mon.BoundAccount
+}
+
+// maybeStartAsync starts plan in a separate goroutine if the statement is a
+// SELECT, the session allows parallel execution, is not being traced and has
+// no limit on the rows read, the query has not used up its goroutine budget
+// and the plan is safe to run concurrently with the rest of the query. It
+// returns nil if the plan was not started, in which case the caller should
+// start and iterate it as usual.
+func (p *planner) maybeStartAsync(params runParams, plan planNode) *asyncPlan {
+	if p.session == nil || !p.session.ParallelExecution || p.session.execCfg == nil {
+		return nil
+	}
+	// Keep session traces deterministic: when tracing, the events of the
+	// whole query are recorded in execution order.
+	if p.session.Tracing.Enabled() {
+		return nil
+	}
+	// Rows read by a branch run on its own copy of the planner and would not
+	// count towards statement_max_rows_read or the role quotas.
+	if p.session.StatementMaxRowsRead > 0 || hasRowsReadQuota(p.session.roleQuotas) {
+		return nil
+	}
+	// Mutations could otherwise observe, or not, their own writes depending
+	// on how the goroutines are scheduled.
+	if p.stmt == nil {
+		return nil
+	}
+	if _, ok := p.stmt.AST.(*tree.Select); !ok {
+		return nil
+	}
+	budget := parallelExecMaxGoroutines.Get(&p.session.execCfg.Settings.SV)
+	if int64(p.runningAsyncPlans) >= budget {
+		return nil
+	}
+	if !canRunPlanAsync(params.ctx, plan) {
+		return nil
+	}
+	p.runningAsyncPlans++
+
+	ctx, cancel := context.WithCancel(params.ctx)
+	a := &asyncPlan{
+		plan:   plan,
+		owner:  p,
+		rows:   make(chan asyncRow, asyncPlanBufferSize),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	branch := *p
+	branch.alloc = sqlbase.DatumAlloc{}
+	branch.cancelChecker = sqlbase.NewCancelChecker(ctx)
+	branch.evalCtx = *params.evalCtx
+	branch.evalCtx.IVarHelper = nil
+	if m := branch.evalCtx.Mon; m != nil {
+		a.rowAcc = m.MakeBoundAccount()
+		branch.evalCtx.ActiveMemAcc = &a.rowAcc
+	}
+	branchParams := runParams{
+		ctx:     ctx,
+		evalCtx: &branch.evalCtx,
+		p:       &branch,
+	}
+
+	go a.run(branchParams)
+	return a
+}
+
+// run starts the plan and sends its rows to the consumer. It runs in its own
+// goroutine.
+func (a *asyncPlan) run(params runParams) {
+	defer close(a.done)
+	defer close(a.rows)
+
+	err := func() error {
+		if err := a.plan.Start(params); err != nil {
+			return err
+		}
+		for {
+			next, err := a.plan.Next(params)
+			if err != nil || !next {
+				return err
+			}
+			if params.evalCtx.ActiveMemAcc != nil {
+				params.evalCtx.ActiveMemAcc.Clear(params.ctx)
+			}
+			// The plan may reuse the slice returned by Values, so the row
+			// needs to be copied before it is handed to the consumer.
+			row := append(tree.Datums(nil), a.plan.Values()...)
+			select {
+			case a.rows <- asyncRow{row: row}:
+			case <-params.ctx.Done():
+				return params.ctx.Err()
+			}
+		}
+	}()
+	if err != nil {
+		select {
+		case a.rows <- asyncRow{err: err}:
+		case <-params.ctx.Done():
+		}
+	}
+}
+
+// next advances to the next row produced by the plan.
+func (a *asyncPlan) next(ctx context.Context) (bool, error) {
+	select {
+	case r, ok := <-a.rows:
+		if !ok {
+			return false, nil
+		}
+		if r.err != nil {
+			return false, r.err
+		}
+		a.cur = r.row
+		return true, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// values returns the current row.
+func (a *asyncPlan) values() tree.Datums {
+	return a.cur
+}
+
+// close stops the goroutine running the plan and waits for it to terminate.
+// The plan itself is not closed.
+func (a *asyncPlan) close(ctx context.Context) {
+	a.cancel()
+	<-a.done
+	a.rowAcc.Close(ctx)
+	a.owner.runningAsyncPlans--
+}
+
+// canRunPlanAsync returns whether the plan can be started and iterated
+// concurrently with the rest of the query. This is restricted to plans made
+// only of nodes that access the planner's transaction and nothing else, and
+// whose expressions do not need the planner to be evaluated.
+func canRunPlanAsync(ctx context.Context, plan planNode) bool {
+	ok := true
+	_ = walkPlan(ctx, plan, planObserver{
+		enterNode: func(_ context.Context, _ string, plan planNode) bool {
+			switch plan.(type) {
+			case *scanNode, *indexJoinNode, *filterNode, *renderNode, *limitNode, *valuesNode:
+			default:
+				ok = false
+			}
+			return ok
+		},
+		expr: func(_, _ string, _ int, expr tree.Expr) {
+			if ok && expr != nil {
+				v := asyncExprCheckVisitor{ok: true}
+				tree.WalkExprConst(&v, expr)
+				ok = v.ok
+			}
+		},
+		subqueryNode: func(_ context.Context, _ *subquery) error {
+			ok = false
+			return nil
+		},
+	})
+	return ok
+}
+
+// asyncExprCheckVisitor is a tree.Visitor that checks if an expression can be
+// evaluated outside of the goroutine running the query: it must not contain
+// subqueries, nor functions that depend on the planner or on the order of
+// evaluation.
+type asyncExprCheckVisitor struct {
+	ok bool
+}
+
+var _ tree.Visitor = &asyncExprCheckVisitor{}
+
+func (v *asyncExprCheckVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	if !v.ok {
+		return false, expr
+	}
+	switch t := expr.(type) {
+	case *subquery, *tree.Subquery:
+		v.ok = false
+	case *tree.FuncExpr:
+		if t.IsImpure() || t.IsDistSQLBlacklist() {
+			v.ok = false
+		}
+	}
+	return v.ok, expr
+}
+
+func (v *asyncExprCheckVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
@@ -47,15 +47,21 @@ func TestContextualHelp(t *testing.T) {
 		{`ALTER TABLE blah RENAME TO ??`, `ALTER TABLE`},
 		{`ALTER TABLE blah RENAME TO blih ??`, `ALTER TABLE`},
 		{`ALTER TABLE blah SPLIT AT (SELECT 1) ??`, `ALTER TABLE`},
+		{`ALTER TABLE blah ROTATE ENCRYPTION KEY (x) ??`, `ALTER TABLE`},
+		{`ALTER TABLE blah CONFIGURE ZONE ??`, `ALTER TABLE`},
+		{`ALTER TABLE blah SET LOCALITY ??`, `ALTER TABLE`},
 
 		{`ALTER INDEX foo@bar RENAME ??`, `ALTER INDEX`},
 		{`ALTER INDEX foo@bar RENAME TO blih ??`, `ALTER INDEX`},
 		{`ALTER INDEX foo@bar SPLIT ??`, `ALTER INDEX`},
 		{`ALTER INDEX foo@bar SPLIT AT (SELECT 1) ??`, `ALTER INDEX`},
+		{`ALTER INDEX foo@bar CONFIGURE ZONE USING ??`, `ALTER INDEX`},
 
 		{`ALTER DATABASE foo ??`, `ALTER DATABASE`},
 		{`ALTER DATABASE foo RENAME ??`, `ALTER DATABASE`},
 		{`ALTER DATABASE foo RENAME TO bar ??`, `ALTER DATABASE`},
+		{`ALTER DATABASE foo SET PRIMARY REGION ??`, `ALTER DATABASE`},
+		{`ALTER DATABASE foo ADD REGION ??`, `ALTER DATABASE`},
 
 		{`ALTER VIEW IF ??`, `ALTER VIEW`},
 		{`ALTER VIEW blah ??`, `ALTER VIEW`},
@@ -69,10 +75,18 @@ func TestContextualHelp(t *testing.T) {
 
 		{`ALTER USER IF ??`, `ALTER USER`},
 		{`ALTER USER foo WITH PASSWORD ??`, `ALTER USER`},
+		{`ALTER USER foo WITH CONNECTION LIMIT ??`, `ALTER USER`},
+		{`ALTER USER foo VALID UNTIL ??`, `ALTER USER`},
+		{`ALTER USER foo DENY STATEMENTS ??`, `ALTER USER`},
+		{`ALTER USER foo QUOTA ??`, `ALTER USER`},
+
+		{`ALTER DEFAULT PRIVILEGES ??`, `ALTER DEFAULT PRIVILEGES`},
+		{`ALTER DEFAULT PRIVILEGES FOR ROLE foo ??`, `ALTER DEFAULT PRIVILEGES`},
 
 		{`CANCEL ??`, `CANCEL`},
 		{`CANCEL JOB ??`, `CANCEL JOB`},
 		{`CANCEL QUERY ??`, `CANCEL QUERY`},
+		{`CANCEL SESSION ??`, `CANCEL SESSION`},
 
 		{`CREATE UNIQUE ??`, `CREATE`},
 		{`CREATE UNIQUE INDEX ??`, `CREATE INDEX`},
@@ -88,6 +102,13 @@ func TestContextualHelp(t *testing.T) {
 
 		{`CREATE USER blih ??`, `CREATE USER`},
 		{`CREATE USER blih WITH ??`, `CREATE USER`},
+		{`CREATE USER blih CONNECTION ??`, `CREATE USER`},
+
+		{`CREATE POLICY ??`, `CREATE POLICY`},
+		{`CREATE POLICY p ON t FOR ??`, `CREATE POLICY`},
+
+		{`CREATE ROLE blih ??`, `CREATE ROLE`},
+		{`CREATE ROLE blih WITH LOGIN ??`, `CREATE ROLE`},
 
 		{`CREATE VIEW blah (??`, `CREATE VIEW`},
 		{`CREATE VIEW blah AS (SELECT c FROM x) ??`, `CREATE VIEW`},
@@ -120,6 +141,9 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP INDEX blah, ??`, `DROP INDEX`},
 		{`DROP INDEX blah@blih ??`, `DROP INDEX`},
 
+		{`DROP POLICY ??`, `DROP POLICY`},
+		{`DROP POLICY IF ??`, `DROP POLICY`},
+
 		{`DROP SEQUENCE blah ??`, `DROP SEQUENCE`},
 		{`DROP SEQUENCE IF ??`, `DROP SEQUENCE`},
 		{`DROP SEQUENCE IF EXISTS blih, bloh ??`, `DROP SEQUENCE`},
@@ -135,7 +159,11 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP USER IF ??`, `DROP USER`},
 		{`DROP USER IF EXISTS bloh ??`, `DROP USER`},
 
+		{`DROP ROLE ??`, `DROP ROLE`},
+		{`DROP ROLE IF EXISTS bloh ??`, `DROP ROLE`},
+
 		{`EXPLAIN (??`, `EXPLAIN`},
+		{`EXPLAIN ANALYZE ??`, `EXPLAIN`},
 		{`EXPLAIN SELECT 1 ??`, `SELECT`},
 		{`EXPLAIN INSERT INTO xx (SELECT 1) ??`, `INSERT`},
 		{`EXPLAIN UPSERT INTO xx (SELECT 1) ??`, `UPSERT`},
@@ -156,6 +184,19 @@ func TestContextualHelp(t *testing.T) {
 		{`EXECUTE foo (??`, `EXECUTE`},
 
 		{`DEALLOCATE foo ??`, `DEALLOCATE`},
+
+		{`DECLARE ??`, `DECLARE`},
+		{`DECLARE c CURSOR ??`, `DECLARE`},
+		{`FETCH ??`, `FETCH`},
+		{`FETCH 10 FROM ??`, `FETCH`},
+		{`MOVE ??`, `MOVE`},
+		{`MOVE NEXT ??`, `MOVE`},
+		{`CLOSE ??`, `CLOSE`},
+
+		{`LISTEN ??`, `LISTEN`},
+		{`UNLISTEN ??`, `UNLISTEN`},
+		{`NOTIFY ??`, `NOTIFY`},
+		{`NOTIFY c, ??`, `NOTIFY`},
 		{`DEALLOCATE ALL ??`, `DEALLOCATE`},
 		{`DEALLOCATE PREPARE ??`, `DEALLOCATE`},
 
@@ -182,14 +223,20 @@ func TestContextualHelp(t *testing.T) {
 		{`GRANT ALL ??`, `GRANT`},
 		{`GRANT ALL ON foo TO ??`, `GRANT`},
 		{`GRANT ALL ON foo TO bar ??`, `GRANT`},
+		{`GRANT foo TO bar WITH ??`, `GRANT`},
+		{`GRANT SELECT (a) ON foo TO ??`, `GRANT`},
 
 		{`PAUSE ??`, `PAUSE JOB`},
 
 		{`RESUME ??`, `RESUME JOB`},
 
+		{`REASSIGN ??`, `REASSIGN OWNED`},
+		{`REASSIGN OWNED BY foo ??`, `REASSIGN OWNED`},
+
 		{`REVOKE ALL ??`, `REVOKE`},
 		{`REVOKE ALL ON foo FROM ??`, `REVOKE`},
 		{`REVOKE ALL ON foo FROM bar ??`, `REVOKE`},
+		{`REVOKE ADMIN OPTION ??`, `REVOKE`},
 
 		{`SELECT * FROM ??`, `<SOURCE>`},
 		{`SELECT * FROM (??`, `<SOURCE>`}, // not <selectclause>! joins are allowed.
@@ -209,15 +256,23 @@ func TestContextualHelp(t *testing.T) {
 		{`SHOW SESSIONS ??`, `SHOW SESSIONS`},
 		{`SHOW LOCAL SESSIONS ??`, `SHOW SESSIONS`},
 
+		{`SHOW SYNTAX ??`, `SHOW SYNTAX`},
+
 		{`SHOW QUERIES ??`, `SHOW QUERIES`},
 		{`SHOW LOCAL QUERIES ??`, `SHOW QUERIES`},
 
+		{`SHOW RANGES ??`, `SHOW RANGES`},
+		{`SHOW RANGES FROM TABLE ??`, `SHOW RANGES`},
+		{`SHOW RANGE FROM TABLE t FOR ??`, `SHOW RANGES`},
+
 		{`SHOW TRACE ??`, `SHOW TRACE`},
 		{`SHOW TRACE FOR SESSION ??`, `SHOW TRACE`},
 		{`SHOW TRACE FOR ??`, `SHOW TRACE`},
 
 		{`SHOW JOBS ??`, `SHOW JOBS`},
 
+		{`SHOW SCHEDULES ??`, `SHOW SCHEDULES`},
+
 		{`SHOW BACKUP 'foo' ??`, `SHOW BACKUP`},
 
 		{`SHOW CLUSTER SETTING all ??`, `SHOW CLUSTER SETTING`},
@@ -233,7 +288,11 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SHOW CREATE VIEW blah ??`, `SHOW CREATE VIEW`},
 
+		{`SHOW CREATE SEQUENCE blah ??`, `SHOW CREATE SEQUENCE`},
+
 		{`SHOW DATABASES ??`, `SHOW DATABASES`},
+		{`SHOW DUMP ??`, `SHOW DUMP`},
+		{`SHOW DUMP FROM ??`, `SHOW DUMP`},
 
 		{`SHOW GRANTS ON ??`, `SHOW GRANTS`},
 		{`SHOW GRANTS ON foo FOR ??`, `SHOW GRANTS`},
@@ -254,6 +313,9 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SHOW USERS ??`, `SHOW USERS`},
 
+		{`SHOW ZONE ??`, `SHOW ZONE`},
+		{`SHOW ZONE CONFIGURATION FOR ??`, `SHOW ZONE`},
+
 		{`TRUNCATE foo ??`, `TRUNCATE`},
 		{`TRUNCATE foo, ??`, `TRUNCATE`},
 
@@ -323,6 +385,17 @@ func TestContextualHelp(t *testing.T) {
 
 		{`IMPORT TABLE foo CREATE USING 'foo.sql' CSV DATA ('foo') ??`, `IMPORT`},
 		{`IMPORT TABLE ??`, `IMPORT`},
+
+		{`CREATE CHANGEFEED ??`, `CREATE CHANGEFEED`},
+		{`CREATE CHANGEFEED FOR TABLE foo INTO 'sink' ??`, `CREATE CHANGEFEED`},
+		{`EXPERIMENTAL CHANGEFEED ??`, `CREATE CHANGEFEED`},
+
+		{`CREATE SCHEDULE ??`, `CREATE SCHEDULE FOR BACKUP`},
+		{`CREATE SCHEDULE FOR BACKUP TABLE foo TO 'bar' RECURRING '@daily' ??`, `CREATE SCHEDULE FOR BACKUP`},
+
+		{`EXPORT ??`, `EXPORT`},
+		{`EXPORT INTO CSV 'a' ??`, `EXPORT`},
+		{`EXPORT INTO CSV 'a' FROM SELECT a ??`, `SELECT`},
 	}
 
 	// The following checks that the test definition above exercises all
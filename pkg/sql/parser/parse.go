@@ -27,6 +27,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -52,12 +53,29 @@ func (p *Parser) Parse(sql string) (stmts tree.StatementList, err error) {
 			err = pgerror.NewError(pgerror.CodeSyntaxError, p.scanner.lastError.msg)
 		}
 		err.Hint = p.scanner.lastError.hint
+		if sugg := p.scanner.lastError.suggestion; sugg != "" {
+			if err.Hint != "" {
+				err.Hint = sugg + "\n" + err.Hint
+			} else {
+				err.Hint = sugg
+			}
+		}
 		err.Detail = p.scanner.lastError.detail
 		return nil, err
 	}
 	return p.scanner.stmts, nil
 }
 
+// ErrorPosition returns the position in the input of the token at which the
+// last call to Parse failed, counted in characters from 1 like the error
+// positions of PostgreSQL, or 0 if Parse didn't fail.
+func (p *Parser) ErrorPosition() int {
+	if p.scanner.lastError.msg == "" {
+		return 0
+	}
+	return utf8.RuneCountInString(p.scanner.in[:p.scanner.lastError.pos]) + 1
+}
+
 // Parse parses a sql statement string and returns a list of Statements.
 func Parse(sql string) (tree.StatementList, error) {
 	var p Parser
@@ -197,6 +197,9 @@ func TestParse(t *testing.T) {
 		},
 		{`CREATE TABLE a () INTERLEAVE IN PARENT b (c) PARTITION BY LIST (d) (PARTITION e VALUES IN (1))`},
 		{`CREATE TABLE IF NOT EXISTS a () PARTITION BY LIST (b) (PARTITION c VALUES IN (1))`},
+		{`CREATE TABLE a (b INT) LOCALITY GLOBAL`},
+		{`CREATE TABLE a (b INT) LOCALITY REGIONAL BY TABLE`},
+		{`CREATE TABLE IF NOT EXISTS a (crdb_region STRING, b INT, PRIMARY KEY (crdb_region, b)) LOCALITY REGIONAL BY ROW`},
 		{`CREATE TABLE a (INDEX (b) PARTITION BY LIST (c) (PARTITION d VALUES IN (1)))`},
 		{`CREATE TABLE a (UNIQUE (b) PARTITION BY LIST (c) (PARTITION d VALUES IN (1)))`},
 		{`CREATE INDEX ON a (b) PARTITION BY LIST (c) (PARTITION d VALUES IN (1))`},
@@ -226,6 +229,8 @@ func TestParse(t *testing.T) {
 		{`CREATE VIEW a AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a (x, y) AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a AS TABLE b`},
+		{`CREATE VIEW a SECURITY INVOKER AS SELECT * FROM b`},
+		{`CREATE VIEW a (x, y) SECURITY INVOKER AS SELECT c, d FROM b`},
 
 		{`CREATE SEQUENCE a`},
 		{`CREATE SEQUENCE IF NOT EXISTS a`},
@@ -254,6 +259,9 @@ func TestParse(t *testing.T) {
 		{`DELETE FROM a WHERE a = b ORDER BY c LIMIT d RETURNING e`},
 
 		{`DISCARD ALL`},
+		{`DISCARD PLANS`},
+		{`DISCARD SEQUENCES`},
+		{`DISCARD TEMP`},
 
 		{`DROP DATABASE a`},
 		{`DROP DATABASE IF EXISTS a`},
@@ -279,6 +287,14 @@ func TestParse(t *testing.T) {
 		{`DROP INDEX IF EXISTS a.b@c, d@f`},
 		{`DROP INDEX a.b@c CASCADE`},
 		{`DROP INDEX IF EXISTS a.b@c RESTRICT`},
+
+		{`CREATE POLICY p ON a`},
+		{`CREATE POLICY p ON a.b FOR SELECT TO u, v USING (c = current_user())`},
+		{`CREATE POLICY p ON a FOR INSERT WITH CHECK (c > 0)`},
+		{`CREATE POLICY p ON a FOR ALL USING (c = 1) WITH CHECK (c = 2)`},
+		{`DROP POLICY p ON a`},
+		{`DROP POLICY IF EXISTS p ON a.b`},
+
 		{`DROP VIEW a`},
 		{`DROP VIEW a.b`},
 		{`DROP VIEW a, b`},
@@ -298,12 +314,14 @@ func TestParse(t *testing.T) {
 
 		{`CANCEL JOB a`},
 		{`CANCEL QUERY a`},
+		{`CANCEL SESSION a`},
 		{`RESUME JOB a`},
 		{`PAUSE JOB a`},
 
 		{`EXPLAIN SELECT 1`},
 		{`EXPLAIN EXPLAIN SELECT 1`},
 		{`EXPLAIN (A, B, C) SELECT 1`},
+		{`EXPLAIN ANALYZE (DEBUG) SELECT 1`},
 		{`SELECT * FROM [EXPLAIN SELECT 1]`},
 		{`SELECT * FROM [SHOW TRANSACTION STATUS]`},
 
@@ -315,6 +333,10 @@ func TestParse(t *testing.T) {
 		{`SHOW CLUSTER SETTING all`},
 
 		{`SHOW DATABASES`},
+		{`SHOW DUMP`},
+		{`SHOW DUMP FROM d`},
+		{`SHOW DUMP FROM d AS OF SYSTEM TIME '2017-01-01'`},
+		{`SHOW SYNTAX 'select 1'`},
 		{`SHOW TABLES`},
 		{`SHOW TABLES FROM a`},
 		{`SHOW COLUMNS FROM a`},
@@ -323,9 +345,12 @@ func TestParse(t *testing.T) {
 		{`SHOW INDEXES FROM a.b.c`},
 		{`SHOW CONSTRAINTS FROM a`},
 		{`SHOW CONSTRAINTS FROM a.b.c`},
+		{`SHOW CREATE SEQUENCE a`},
+		{`SHOW CREATE SEQUENCE a.b`},
 		{`SHOW TABLES FROM a; SHOW COLUMNS FROM b`},
 		{`SHOW USERS`},
 		{`SHOW JOBS`},
+		{`SHOW SCHEDULES`},
 		{`SHOW CLUSTER QUERIES`},
 		{`SHOW LOCAL QUERIES`},
 		{`SHOW CLUSTER SESSIONS`},
@@ -337,27 +362,31 @@ func TestParse(t *testing.T) {
 		{`SHOW KV TRACE FOR TABLE foo`},
 		{`SHOW COMPACT TRACE FOR TABLE foo`},
 		{`SHOW COMPACT KV TRACE FOR TABLE foo`},
-		{`SHOW TESTING_RANGES FROM TABLE d.t`},
-		{`SHOW TESTING_RANGES FROM TABLE t`},
-		{`SHOW TESTING_RANGES FROM INDEX d.t@i`},
-		{`SHOW TESTING_RANGES FROM INDEX t@i`},
-		{`SHOW TESTING_RANGES FROM INDEX d.i`},
-		{`SHOW TESTING_RANGES FROM INDEX i`},
+		{`SHOW RANGES FROM TABLE d.t`},
+		{`SHOW RANGES FROM TABLE t`},
+		{`SHOW RANGES FROM INDEX d.t@i`},
+		{`SHOW RANGES FROM INDEX t@i`},
+		{`SHOW RANGES FROM INDEX d.i`},
+		{`SHOW RANGES FROM INDEX i`},
+		{`SHOW RANGE FROM TABLE d.t FOR ROW (1)`},
+		{`SHOW RANGE FROM TABLE t FOR ROW (1, 'a')`},
+		{`SHOW RANGE FROM INDEX d.t@i FOR ROW (1)`},
+		{`SHOW RANGE FROM INDEX t@i FOR ROW ($1, 2)`},
 		{`SHOW EXPERIMENTAL_FINGERPRINTS FROM TABLE d.t`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATIONS`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR RANGE default`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR RANGE meta`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR DATABASE db`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR TABLE db.t`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR TABLE db.t PARTITION p`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR TABLE t`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR TABLE t PARTITION p`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR INDEX db.t@i`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR INDEX db.t@i PARTITION p`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR INDEX t@i`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR INDEX t@i PARTITION p`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR INDEX i`},
-		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR INDEX i PARTITION p`},
+		{`SHOW ZONE CONFIGURATIONS`},
+		{`SHOW ZONE CONFIGURATION FOR RANGE default`},
+		{`SHOW ZONE CONFIGURATION FOR RANGE meta`},
+		{`SHOW ZONE CONFIGURATION FOR DATABASE db`},
+		{`SHOW ZONE CONFIGURATION FOR TABLE db.t`},
+		{`SHOW ZONE CONFIGURATION FOR TABLE db.t PARTITION p`},
+		{`SHOW ZONE CONFIGURATION FOR TABLE t`},
+		{`SHOW ZONE CONFIGURATION FOR TABLE t PARTITION p`},
+		{`SHOW ZONE CONFIGURATION FOR INDEX db.t@i`},
+		{`SHOW ZONE CONFIGURATION FOR INDEX db.t@i PARTITION p`},
+		{`SHOW ZONE CONFIGURATION FOR INDEX t@i`},
+		{`SHOW ZONE CONFIGURATION FOR INDEX t@i PARTITION p`},
+		{`SHOW ZONE CONFIGURATION FOR INDEX i`},
+		{`SHOW ZONE CONFIGURATION FOR INDEX i PARTITION p`},
 
 		// Tables are the default, but can also be specified with
 		// GRANT x ON TABLE y. However, the stringer does not output TABLE.
@@ -367,6 +396,8 @@ func TestParse(t *testing.T) {
 		{`SHOW GRANTS ON DATABASE foo, bar`},
 		{`SHOW GRANTS ON DATABASE foo FOR bar`},
 		{`SHOW GRANTS FOR bar, baz`},
+		{`SHOW GRANTS WITH PATH`},
+		{`SHOW GRANTS ON foo FOR bar WITH PATH`},
 
 		{`PREPARE a AS SELECT 1`},
 		{`PREPARE a (INT) AS SELECT $1`},
@@ -385,6 +416,8 @@ func TestParse(t *testing.T) {
 		{`PREPARE a (STRING) AS RESTORE DATABASE a FROM $1`},
 		{`PREPARE a AS CANCEL QUERY 1`},
 		{`PREPARE a (STRING) AS CANCEL QUERY $1`},
+		{`PREPARE a AS CANCEL SESSION 1`},
+		{`PREPARE a (INT) AS CANCEL SESSION $1`},
 		{`PREPARE a AS CANCEL JOB 1`},
 		{`PREPARE a (INT) AS CANCEL JOB $1`},
 		{`PREPARE a AS PAUSE JOB 1`},
@@ -393,6 +426,12 @@ func TestParse(t *testing.T) {
 		{`PREPARE a (INT) AS RESUME JOB $1`},
 		{`PREPARE a AS IMPORT TABLE a CREATE USING 'b' CSV DATA ('c') WITH temp = 'd'`},
 		{`PREPARE a (STRING, STRING, STRING) AS IMPORT TABLE a CREATE USING $1 CSV DATA ($2) WITH temp = $3`},
+		{`PREPARE a AS EXPORT INTO CSV 'a' FROM TABLE a`},
+		{`PREPARE a (STRING) AS EXPORT INTO CSV $1 FROM TABLE a`},
+		{`PREPARE a AS CREATE CHANGEFEED FOR TABLE a INTO 'b'`},
+		{`PREPARE a (STRING) AS CREATE CHANGEFEED FOR TABLE a INTO $1`},
+		{`PREPARE a (STRING, STRING) AS CREATE SCHEDULE FOR BACKUP TABLE a TO $1 RECURRING $2`},
+		{`PREPARE a AS EXPERIMENTAL CHANGEFEED FOR TABLE a`},
 
 		{`EXECUTE a`},
 		{`EXECUTE a (1)`},
@@ -402,6 +441,25 @@ func TestParse(t *testing.T) {
 		{`DEALLOCATE a`},
 		{`DEALLOCATE ALL`},
 
+		{`DECLARE c CURSOR FOR SELECT a FROM t`},
+		{`DECLARE c CURSOR FOR SELECT a FROM t WHERE b = $1 ORDER BY a`},
+		{`FETCH FORWARD 5 FROM c`},
+		{`FETCH FORWARD ALL FROM c`},
+		{`FETCH BACKWARD 2 FROM c`},
+		{`FETCH ABSOLUTE 3 FROM c`},
+		{`FETCH RELATIVE -1 FROM c`},
+		{`MOVE FORWARD 10 FROM c`},
+		{`MOVE FORWARD ALL FROM c`},
+		{`CLOSE c`},
+		{`CLOSE ALL`},
+
+		{`LISTEN c`},
+		{`UNLISTEN c`},
+		{`UNLISTEN *`},
+		{`NOTIFY c`},
+		{`NOTIFY c, 'payload'`},
+		{`NOTIFY "Cache", e'it\'s\n'`},
+
 		// Tables are the default, but can also be specified with
 		// GRANT x ON TABLE y. However, the stringer does not output TABLE.
 		{`GRANT SELECT ON foo TO root`},
@@ -411,6 +469,8 @@ func TestParse(t *testing.T) {
 		{`GRANT SELECT, INSERT ON DATABASE bar TO foo, bar, baz`},
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO foo, bar, baz`},
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO "test-user"`},
+		{`GRANT CONNECT, USAGE ON DATABASE foo TO public`},
+		{`GRANT SELECT (a, b) ON foo, db.foo TO root, bar`},
 
 		// Tables are the default, but can also be specified with
 		// REVOKE x ON TABLE y. However, the stringer does not output TABLE.
@@ -420,6 +480,18 @@ func TestParse(t *testing.T) {
 		{`REVOKE ALL ON DATABASE foo FROM root, test`},
 		{`REVOKE SELECT, INSERT ON DATABASE bar FROM foo, bar, baz`},
 		{`REVOKE SELECT, INSERT ON DATABASE db1, db2 FROM foo, bar, baz`},
+		{`REVOKE SELECT (a) ON foo FROM root`},
+
+		{`ALTER DEFAULT PRIVILEGES GRANT SELECT ON TABLES TO foo`},
+		{`ALTER DEFAULT PRIVILEGES FOR ROLE foo, bar IN SCHEMA db GRANT ALL ON TABLES TO baz`},
+		{`ALTER DEFAULT PRIVILEGES IN SCHEMA db1, db2 REVOKE INSERT, DELETE ON TABLES FROM foo, bar`},
+		{`REASSIGN OWNED BY foo TO bar`},
+		{`REASSIGN OWNED BY foo, bar TO baz`},
+
+		{`GRANT foo TO bar`},
+		{`GRANT foo, "select" TO bar, baz WITH ADMIN OPTION`},
+		{`REVOKE foo FROM bar`},
+		{`REVOKE ADMIN OPTION FOR foo, "select" FROM bar, baz`},
 
 		{`INSERT INTO a VALUES (1)`},
 		{`INSERT INTO a.b VALUES (1)`},
@@ -777,8 +849,17 @@ func TestParse(t *testing.T) {
 		{`SELECT * FROM "0" JOIN "0" USING (id, "0")`}, // last "0" lost its quotes.
 
 		{`ALTER DATABASE a RENAME TO b`},
+		{`ALTER DATABASE a OWNER TO b`},
+		{`ALTER DATABASE a SET PRIMARY REGION "us-east1"`},
+		{`ALTER DATABASE a ADD REGION "us-west1"`},
 		{`ALTER TABLE a RENAME TO b`},
 		{`ALTER TABLE IF EXISTS a RENAME TO b`},
+		{`ALTER TABLE a OWNER TO b`},
+		{`ALTER TABLE IF EXISTS a.b OWNER TO c`},
+		{`ALTER VIEW a OWNER TO b`},
+		{`ALTER VIEW IF EXISTS a OWNER TO b`},
+		{`ALTER SEQUENCE a OWNER TO b`},
+		{`ALTER SEQUENCE IF EXISTS a OWNER TO b`},
 		{`ALTER INDEX a@b RENAME TO b`},
 		{`ALTER INDEX b RENAME TO b`},
 		{`ALTER INDEX a@primary RENAME TO like`},
@@ -813,6 +894,19 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE a DROP CONSTRAINT b CASCADE`},
 		{`ALTER TABLE a DROP CONSTRAINT IF EXISTS b RESTRICT`},
 		{`ALTER TABLE a VALIDATE CONSTRAINT a`},
+		{`ALTER TABLE a ENABLE ROW LEVEL SECURITY`},
+		{`ALTER TABLE a DISABLE ROW LEVEL SECURITY`},
+		{`ALTER TABLE a EXPERIMENTAL_AUDIT SET READ WRITE`},
+		{`ALTER TABLE a EXPERIMENTAL_AUDIT SET OFF`},
+		{`ALTER TABLE a SET LOCALITY GLOBAL`},
+		{`ALTER TABLE a SET LOCALITY REGIONAL BY ROW`},
+		{`ALTER TABLE a ALTER PRIMARY KEY USING COLUMNS (b)`},
+		{`ALTER TABLE a ALTER PRIMARY KEY USING COLUMNS (b, c DESC)`},
+		{`ALTER TABLE a INJECT STATISTICS '[]'`},
+		{`ALTER TABLE a INJECT STATISTICS $1`},
+		{`ALTER TABLE a SET TTL EXPIRE AT b`},
+		{`ALTER TABLE a SET TTL EXPIRE AFTER '1h'`},
+		{`ALTER TABLE a RESET TTL`},
 
 		{`ALTER TABLE a ALTER COLUMN b SET DEFAULT 42`},
 		{`ALTER TABLE a ALTER COLUMN b SET DEFAULT NULL`},
@@ -822,6 +916,11 @@ func TestParse(t *testing.T) {
 
 		{`COPY t FROM STDIN`},
 		{`COPY t (a, b, c) FROM STDIN`},
+		{`COPY t FROM STDIN WITH (format 'csv', delimiter ';', "null" '', header true)`},
+		{`COPY t (a, b) FROM STDIN WITH (format 'text')`},
+		{`COPY t TO STDOUT`},
+		{`COPY t (a, b) TO STDOUT WITH (format 'csv', header)`},
+		{`COPY (SELECT a FROM t WHERE b > 1) TO STDOUT`},
 
 		{`ALTER TABLE a SPLIT AT VALUES (1)`},
 		{`ALTER TABLE a SPLIT AT SELECT * FROM t`},
@@ -841,6 +940,9 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE d.a SCATTER`},
 		{`ALTER INDEX d.i SCATTER FROM (1) TO (2)`},
 
+		{`ALTER TABLE a ROTATE ENCRYPTION KEY (b)`},
+		{`ALTER TABLE a.b ROTATE ENCRYPTION KEY (c, d)`},
+
 		{`ALTER RANGE default EXPERIMENTAL CONFIGURE ZONE 'foo'`},
 		{`ALTER RANGE meta EXPERIMENTAL CONFIGURE ZONE 'foo'`},
 		{`ALTER DATABASE db EXPERIMENTAL CONFIGURE ZONE 'foo'`},
@@ -856,6 +958,15 @@ func TestParse(t *testing.T) {
 		{`ALTER INDEX i PARTITION p EXPERIMENTAL CONFIGURE ZONE 'foo'`},
 		{`ALTER TABLE t EXPERIMENTAL CONFIGURE ZONE b'foo'`},
 		{`ALTER TABLE t EXPERIMENTAL CONFIGURE ZONE NULL`},
+		{`ALTER RANGE default CONFIGURE ZONE USING num_replicas = 5`},
+		{`ALTER DATABASE db CONFIGURE ZONE USING num_replicas = 3, constraints = '[+region=us]'`},
+		{`ALTER TABLE t CONFIGURE ZONE USING gc.ttlseconds = 3600`},
+		{`ALTER TABLE db.t PARTITION p CONFIGURE ZONE USING lease_preferences = '[[+region=us], [+region=eu]]'`},
+		{`ALTER INDEX t@i CONFIGURE ZONE USING range_min_bytes = $1, range_max_bytes = $2`},
+		{`ALTER INDEX i PARTITION p CONFIGURE ZONE USING constraints = '[]'`},
+		{`ALTER TABLE t CONFIGURE ZONE DISCARD`},
+		{`ALTER INDEX t@i PARTITION p CONFIGURE ZONE DISCARD`},
+		{`ALTER DATABASE db CONFIGURE ZONE DISCARD`},
 
 		{`ALTER SEQUENCE a RENAME TO b`},
 		{`ALTER SEQUENCE IF EXISTS a RENAME TO b`},
@@ -869,6 +980,11 @@ func TestParse(t *testing.T) {
 		{`EXPERIMENTAL SCRUB TABLE x WITH OPTIONS PHYSICAL`},
 		{`EXPERIMENTAL SCRUB TABLE x WITH OPTIONS PHYSICAL, INDEX (index_name)`},
 		{`EXPERIMENTAL SCRUB TABLE x WITH OPTIONS PHYSICAL, INDEX ALL`},
+		{`EXPERIMENTAL SCRUB TABLE x WITH OPTIONS CONSTRAINT ALL`},
+		{`EXPERIMENTAL SCRUB TABLE x WITH OPTIONS CONSTRAINT (cst_name)`},
+		{`EXPERIMENTAL SCRUB TABLE x WITH OPTIONS INDEX ALL, CONSTRAINT (a, b)`},
+		{`EXPERIMENTAL SCRUB TABLE x WITH OPTIONS REPAIR`},
+		{`EXPERIMENTAL SCRUB TABLE x WITH OPTIONS INDEX (index_name), REPAIR`},
 
 		{`BACKUP foo TO 'bar'`},
 		{`BACKUP foo.foo, baz.baz TO 'bar'`},
@@ -878,9 +994,13 @@ func TestParse(t *testing.T) {
 		{`BACKUP DATABASE foo TO 'bar'`},
 		{`BACKUP DATABASE foo, baz TO 'bar'`},
 		{`BACKUP DATABASE foo TO 'bar' AS OF SYSTEM TIME '1' INCREMENTAL FROM 'baz'`},
+		{`BACKUP DATABASE foo TO ($1, $2)`},
+		{`BACKUP DATABASE foo TO ('bar', 'baz') INCREMENTAL FROM 'qux'`},
 		{`RESTORE foo FROM 'bar'`},
 		{`RESTORE foo FROM $1`},
 		{`RESTORE foo FROM $1, $2, 'bar'`},
+		{`RESTORE foo FROM ($1, $2)`},
+		{`RESTORE foo FROM ($1, $2), ('bar', 'baz')`},
 		{`RESTORE foo, baz FROM 'bar'`},
 		{`RESTORE foo, baz FROM 'bar' EXPERIMENTAL AS OF SYSTEM TIME '1'`},
 		{`RESTORE DATABASE foo FROM 'bar'`},
@@ -891,6 +1011,24 @@ func TestParse(t *testing.T) {
 		{`IMPORT TABLE foo CREATE USING 'nodelocal:///some/file' CSV DATA ('path/to/some/file', $1) WITH temp = 'path/to/temp'`},
 		{`IMPORT TABLE foo (id INT PRIMARY KEY, email STRING, age INT) CSV DATA ('path/to/some/file', $1) WITH temp = 'path/to/temp'`},
 		{`IMPORT TABLE foo (id INT, email STRING, age INT) CSV DATA ('path/to/some/file', $1) WITH comma = ',', "nullif" = 'n/a', temp = $2`},
+		{`IMPORT TABLE foo (id INT PRIMARY KEY, email STRING) PGDUMP DATA ('path/to/some/dump.sql') WITH temp = 'path/to/temp'`},
+		{`IMPORT TABLE foo CREATE USING 'nodelocal:///some/file' MYSQLDUMP DATA ('path/to/some/dump.sql') WITH temp = 'path/to/temp'`},
+
+		{`EXPORT INTO CSV 'a' FROM TABLE a`},
+		{`EXPORT INTO CSV 'a' FROM SELECT * FROM a`},
+		{`EXPORT INTO CSV 's3://my/path/%part%.csv' WITH delimiter = '|' FROM TABLE a`},
+		{`EXPORT INTO CSV 's3://my/path/%part%.csv' WITH delimiter = '|' FROM SELECT a, sum(b) FROM c WHERE d = 1 ORDER BY sum(b) DESC LIMIT 10`},
+
+		{`CREATE CHANGEFEED FOR TABLE foo INTO 'sink'`},
+		{`CREATE CHANGEFEED FOR TABLE foo, db.bar INTO 'kafka://localhost:9092?topic_prefix=crdb_'`},
+		{`CREATE CHANGEFEED FOR TABLE foo INTO 'sink' WITH updated, resolved = '10s'`},
+		{`EXPERIMENTAL CHANGEFEED FOR TABLE foo`},
+		{`EXPERIMENTAL CHANGEFEED FOR TABLE foo, bar WITH updated`},
+
+		{`CREATE SCHEDULE FOR BACKUP TABLE foo TO 'bar' RECURRING '@hourly'`},
+		{`CREATE SCHEDULE 'nightly' FOR BACKUP DATABASE foo TO 'bar' RECURRING '0 2 * * *'`},
+		{`CREATE SCHEDULE FOR BACKUP TABLE foo, baz TO 'bar' WITH skip_missing_foreign_keys RECURRING '@daily' FULL BACKUP '@weekly'`},
+		{`CREATE SCHEDULE FOR BACKUP TABLE foo TO 'bar' RECURRING '@daily' FULL BACKUP ALWAYS`},
 		{`SET ROW (1, true, NULL)`},
 
 		// Regression for #15926
@@ -1162,7 +1300,19 @@ func TestParse2(t *testing.T) {
 		{`SHOW SESSION database`, `SHOW database`},
 		{`SHOW SESSION TIME ZONE`, `SHOW timezone`},
 		{`SHOW SESSION TIMEZONE`, `SHOW timezone`},
-		{`EXPERIMENTAL SHOW ALL ZONE CONFIGURATIONS`, `EXPERIMENTAL SHOW ZONE CONFIGURATIONS`},
+		{`SHOW TESTING_RANGES FROM TABLE d.t`, `SHOW RANGES FROM TABLE d.t`},
+		{`SHOW TESTING_RANGES FROM INDEX t@i`, `SHOW RANGES FROM INDEX t@i`},
+		{`SHOW TESTING_RANGES FROM INDEX i`, `SHOW RANGES FROM INDEX i`},
+
+		{`EXPERIMENTAL SHOW ALL ZONE CONFIGURATIONS`, `SHOW ZONE CONFIGURATIONS`},
+		{`EXPERIMENTAL SHOW ZONE CONFIGURATIONS`, `SHOW ZONE CONFIGURATIONS`},
+		{`SHOW ALL ZONE CONFIGURATIONS`, `SHOW ZONE CONFIGURATIONS`},
+		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR RANGE default`, `SHOW ZONE CONFIGURATION FOR RANGE default`},
+		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR TABLE db.t PARTITION p`,
+			`SHOW ZONE CONFIGURATION FOR TABLE db.t PARTITION p`},
+		{`EXPERIMENTAL SHOW ZONE CONFIGURATION FOR INDEX t@i`, `SHOW ZONE CONFIGURATION FOR INDEX t@i`},
+		{`ALTER TABLE t CONFIGURE ZONE USING "gc".ttlseconds = 3600`,
+			`ALTER TABLE t CONFIGURE ZONE USING gc.ttlseconds = 3600`},
 		{`BEGIN`,
 			`BEGIN TRANSACTION`},
 		{`START TRANSACTION`,
@@ -1196,6 +1346,22 @@ func TestParse2(t *testing.T) {
 		{`DEALLOCATE PREPARE ALL`,
 			`DEALLOCATE ALL`},
 
+		{`DECLARE c NO SCROLL CURSOR WITHOUT HOLD FOR SELECT 1`,
+			`DECLARE c CURSOR FOR SELECT 1`},
+		{`FETCH c`, `FETCH FORWARD 1 FROM c`},
+		{`FETCH IN c`, `FETCH FORWARD 1 FROM c`},
+		{`FETCH NEXT FROM c`, `FETCH FORWARD 1 FROM c`},
+		{`FETCH PRIOR c`, `FETCH BACKWARD 1 FROM c`},
+		{`FETCH FIRST FROM c`, `FETCH ABSOLUTE 1 FROM c`},
+		{`FETCH LAST FROM c`, `FETCH ABSOLUTE -1 FROM c`},
+		{`FETCH 100 c`, `FETCH FORWARD 100 FROM c`},
+		{`FETCH ALL IN c`, `FETCH FORWARD ALL FROM c`},
+		{`FETCH FORWARD c`, `FETCH FORWARD 1 FROM c`},
+		{`FETCH BACKWARD ALL c`, `FETCH BACKWARD ALL FROM c`},
+		{`MOVE c`, `MOVE FORWARD 1 FROM c`},
+		{`MOVE 5 IN c`, `MOVE FORWARD 5 FROM c`},
+		{`FETCH next`, `FETCH FORWARD 1 FROM next`},
+
 		{`BACKUP DATABASE foo TO bar`,
 			`BACKUP DATABASE foo TO 'bar'`},
 		{`BACKUP DATABASE foo TO "bar.12" INCREMENTAL FROM "baz.34"`,
@@ -1203,6 +1369,11 @@ func TestParse2(t *testing.T) {
 		{`RESTORE DATABASE foo FROM bar`,
 			`RESTORE DATABASE foo FROM 'bar'`},
 
+		{`ALTER DEFAULT PRIVILEGES FOR USER foo GRANT SELECT ON TABLES TO bar`,
+			`ALTER DEFAULT PRIVILEGES FOR ROLE foo GRANT SELECT ON TABLES TO bar`},
+		{`CREATE VIEW a SECURITY DEFINER AS SELECT * FROM b`,
+			`CREATE VIEW a AS SELECT * FROM b`},
+
 		{`SHOW ALL CLUSTER SETTINGS`, `SHOW CLUSTER SETTING all`},
 
 		{`SHOW SESSIONS`, `SHOW CLUSTER SESSIONS`},
@@ -1224,6 +1395,8 @@ func TestParse2(t *testing.T) {
 		{`RESET CLUSTER SETTING a`, `SET CLUSTER SETTING a = DEFAULT`},
 
 		{`RESET NAMES`, `SET client_encoding = DEFAULT`},
+		{`RESET ALL`, `SET "all" = DEFAULT`},
+		{`DISCARD TEMPORARY`, `DISCARD TEMP`},
 
 		{`CREATE USER foo`,
 			`CREATE USER 'foo'`},
@@ -1231,10 +1404,48 @@ func TestParse2(t *testing.T) {
 			`CREATE USER IF NOT EXISTS 'foo'`},
 		{`CREATE USER foo PASSWORD bar`,
 			`CREATE USER 'foo' WITH PASSWORD 'bar'`},
+		{`CREATE USER foo WITH CONNECTION LIMIT 5`,
+			`CREATE USER 'foo' CONNECTION LIMIT 5`},
+		{`CREATE USER IF NOT EXISTS foo WITH PASSWORD bar CONNECTION LIMIT -1`,
+			`CREATE USER IF NOT EXISTS 'foo' WITH PASSWORD 'bar' CONNECTION LIMIT -1`},
+		{`CREATE USER foo WITH PASSWORD bar VALID UNTIL '2030-01-01'`,
+			`CREATE USER 'foo' WITH PASSWORD 'bar' VALID UNTIL '2030-01-01'`},
+		{`CREATE USER foo CONNECTION LIMIT 5 VALID UNTIL $1`,
+			`CREATE USER 'foo' CONNECTION LIMIT 5 VALID UNTIL $1`},
 		{`DROP USER foo, bar`,
 			`DROP USER 'foo', 'bar'`},
+		{`CREATE ROLE foo`,
+			`CREATE ROLE 'foo'`},
+		{`CREATE ROLE IF NOT EXISTS foo LOGIN NOINHERIT`,
+			`CREATE ROLE IF NOT EXISTS 'foo' WITH LOGIN NOINHERIT`},
+		{`CREATE ROLE foo WITH NOLOGIN INHERIT`,
+			`CREATE ROLE 'foo' WITH NOLOGIN INHERIT`},
+		{`DROP ROLE foo, bar`,
+			`DROP ROLE 'foo', 'bar'`},
+		{`DROP ROLE IF EXISTS foo`,
+			`DROP ROLE IF EXISTS 'foo'`},
+		{`GRANT SELECT TO foo`,
+			`GRANT "select" TO foo`},
 		{`ALTER USER foo WITH PASSWORD bar`,
 			`ALTER USER 'foo' WITH PASSWORD 'bar'`},
+		{`ALTER USER foo CONNECTION LIMIT 3`,
+			`ALTER USER 'foo' WITH CONNECTION LIMIT 3`},
+		{`ALTER USER IF EXISTS foo WITH CONNECTION LIMIT 3`,
+			`ALTER USER IF EXISTS 'foo' WITH CONNECTION LIMIT 3`},
+		{`ALTER USER foo VALID UNTIL '2030-01-01'`,
+			`ALTER USER 'foo' WITH VALID UNTIL '2030-01-01'`},
+		{`ALTER USER IF EXISTS foo WITH VALID UNTIL '2030-01-01'`,
+			`ALTER USER IF EXISTS 'foo' WITH VALID UNTIL '2030-01-01'`},
+		{`ALTER USER foo ALLOW STATEMENTS SELECT, dml`,
+			`ALTER USER 'foo' WITH ALLOW STATEMENTS "select", dml`},
+		{`ALTER USER foo WITH DENY STATEMENTS ddl, export, import`,
+			`ALTER USER 'foo' WITH DENY STATEMENTS ddl, export, import`},
+		{`ALTER USER IF EXISTS foo ALLOW ALL STATEMENTS`,
+			`ALTER USER IF EXISTS 'foo' WITH ALLOW ALL STATEMENTS`},
+		{`ALTER USER foo QUOTA max_queries_per_second = 100`,
+			`ALTER USER 'foo' WITH QUOTA max_queries_per_second = 100`},
+		{`ALTER USER IF EXISTS foo WITH QUOTA max_concurrent_statements = -1`,
+			`ALTER USER IF EXISTS 'foo' WITH QUOTA max_concurrent_statements = -1`},
 
 		{
 			`CREATE TABLE a (b INT, FOREIGN KEY (b) REFERENCES other ON UPDATE NO ACTION ON DELETE NO ACTION)`,
@@ -1304,6 +1515,10 @@ func TestParse2(t *testing.T) {
 			`CREATE TABLE a (b INT, FOREIGN KEY (b) REFERENCES other ON UPDATE SET DEFAULT ON DELETE RESTRICT)`,
 			`CREATE TABLE a (b INT, FOREIGN KEY (b) REFERENCES other ON DELETE RESTRICT ON UPDATE SET DEFAULT)`,
 		},
+		{`COPY t FROM STDIN (FORMAT CSV, NULL 'x')`, `COPY t FROM STDIN WITH (format 'csv', "null" 'x')`},
+		{`COPY t FROM STDIN WITH CSV HEADER DELIMITER AS ';'`, `COPY t FROM STDIN WITH (csv, header, delimiter ';')`},
+		{`COPY t FROM STDIN NULL AS '' CSV`, `COPY t FROM STDIN WITH ("null" '', csv)`},
+		{`COPY t TO STDOUT CSV HEADER`, `COPY t TO STDOUT WITH (csv, header)`},
 	}
 	for _, d := range testData {
 		stmts, err := Parse(d.sql)
@@ -1379,7 +1594,12 @@ func TestParseError(t *testing.T) {
 		{`SELECT2 1`, `syntax error at or near "select2"
 SELECT2 1
 ^
-`},
+HINT: did you mean SELECT?`},
+		{`CREATE TABEL t (a INT)`, `syntax error at or near "tabel"
+CREATE TABEL t (a INT)
+       ^
+HINT: did you mean TABLE?
+try \h CREATE`},
 		{`SELECT 1 FROM (t)`, `syntax error at or near ")"
 SELECT 1 FROM (t)
                 ^
@@ -1609,6 +1829,13 @@ SELECT ANNOTATE_TYPE(1.2+2.3, notatype)
 CREATE USER foo WITH PASSWORD
                              ^
 HINT: try \h CREATE USER`,
+		},
+		{
+			`GRANT foo ON t TO bar`,
+			`invalid privilege type FOO at or near "on"
+GRANT foo ON t TO bar
+          ^
+`,
 		},
 		{
 			`ALTER TABLE t RENAME TO t[TRUE]`,
@@ -1707,6 +1934,26 @@ SELECT 'f'::"blah"
 	}
 }
 
+func TestParseErrorPosition(t *testing.T) {
+	testData := []struct {
+		sql      string
+		expected int
+	}{
+		{`SELECT 1`, 0},
+		{`SELET 1`, 1},
+		{`SELECT * FROM t WHERE k=`, 25},
+		{"SELECT 1\nFROM (t)", 17},
+		{`SELECT 'κόσμε' FROM`, 20},
+	}
+	for _, d := range testData {
+		var p Parser
+		_, _ = p.Parse(d.sql)
+		if pos := p.ErrorPosition(); pos != d.expected {
+			t.Errorf("%s: expected error position %d, but found %d", d.sql, d.expected, pos)
+		}
+	}
+}
+
 func TestParsePanic(t *testing.T) {
 	// Replicates #1801.
 	defer func() {
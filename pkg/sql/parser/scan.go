@@ -47,6 +47,12 @@ type Scanner struct {
 		hint                 string
 		detail               string
 		unimplementedFeature string
+		// pos is the position in the input of the token at which the
+		// error occurred.
+		pos int
+		// suggestion proposes the keywords the token may be a misspelling
+		// of, if any.
+		suggestion string
 	}
 	stmts       []tree.Statement
 	identQuote  int
@@ -165,6 +171,7 @@ func (s *Scanner) SetHelp(msg HelpMessage) {
 
 func (s *Scanner) populateHelpMsg(msg string) {
 	s.lastError.unimplementedFeature = ""
+	s.lastError.suggestion = ""
 	s.lastError.msg = "help token in input"
 	s.lastError.hint = msg
 }
@@ -197,6 +204,12 @@ func (s *Scanner) Error(e string) {
 	fmt.Fprintf(&buf, "%s^", strings.Repeat(" ", s.lastTok.pos-j))
 	s.lastError.detail = buf.String()
 	s.lastError.unimplementedFeature = ""
+	s.lastError.pos = s.lastTok.pos
+	s.lastError.suggestion = ""
+	if s.lastTok.id == IDENT && e == "syntax error" {
+		// The identifier may be a misspelled keyword.
+		s.lastError.suggestion = suggestKeywords(s.lastTok.str)
+	}
 }
 
 func (s *Scanner) scan(lval *sqlSymType) {
@@ -220,6 +220,18 @@ func (u *sqlSymUnion) colTypes() []coltypes.T {
 func (u *sqlSymUnion) int64() int64 {
     return u.val.(int64)
 }
+func (u *sqlSymUnion) int64Ptr() *int64 {
+    return u.val.(*int64)
+}
+func (u *sqlSymUnion) roleOption() tree.RoleOption {
+    return u.val.(tree.RoleOption)
+}
+func (u *sqlSymUnion) roleOptions() []tree.RoleOption {
+    return u.val.([]tree.RoleOption)
+}
+func (u *sqlSymUnion) fetchCursor() *tree.FetchCursor {
+    return u.val.(*tree.FetchCursor)
+}
 func (u *sqlSymUnion) seqOpt() tree.SequenceOption {
     return u.val.(tree.SequenceOption)
 }
@@ -286,9 +298,6 @@ func (u *sqlSymUnion) targetList() tree.TargetList {
 func (u *sqlSymUnion) targetListPtr() *tree.TargetList {
     return u.val.(*tree.TargetList)
 }
-func (u *sqlSymUnion) privilegeType() privilege.Kind {
-    return u.val.(privilege.Kind)
-}
 func (u *sqlSymUnion) privilegeList() privilege.List {
     return u.val.(privilege.List)
 }
@@ -337,6 +346,15 @@ func (u *sqlSymUnion) dropBehavior() tree.DropBehavior {
 func (u *sqlSymUnion) validationBehavior() tree.ValidationBehavior {
     return u.val.(tree.ValidationBehavior)
 }
+func (u *sqlSymUnion) auditMode() tree.AuditMode {
+    return u.val.(tree.AuditMode)
+}
+func (u *sqlSymUnion) locality() tree.Locality {
+    return u.val.(tree.Locality)
+}
+func (u *sqlSymUnion) optLocality() *tree.Locality {
+    return u.val.(*tree.Locality)
+}
 func (u *sqlSymUnion) interleave() *tree.InterleaveDef {
     return u.val.(*tree.InterleaveDef)
 }
@@ -373,6 +391,12 @@ func (u *sqlSymUnion) cmpOp() tree.ComparisonOperator {
 func (u *sqlSymUnion) durationField() tree.DurationField {
     return u.val.(tree.DurationField)
 }
+func (u *sqlSymUnion) partitionedBackup() tree.PartitionedBackup {
+    return u.val.(tree.PartitionedBackup)
+}
+func (u *sqlSymUnion) partitionedBackups() tree.PartitionedBackups {
+    return u.val.(tree.PartitionedBackups)
+}
 func (u *sqlSymUnion) kvOption() tree.KVOption {
     return u.val.(tree.KVOption)
 }
@@ -382,6 +406,15 @@ func (u *sqlSymUnion) kvOptions() []tree.KVOption {
     }
     return nil
 }
+func (u *sqlSymUnion) setZoneConfig() *tree.SetZoneConfig {
+    return u.val.(*tree.SetZoneConfig)
+}
+func (u *sqlSymUnion) zoneConfigSetting() tree.ZoneConfigSetting {
+    return u.val.(tree.ZoneConfigSetting)
+}
+func (u *sqlSymUnion) zoneConfigSettings() tree.ZoneConfigSettings {
+    return u.val.(tree.ZoneConfigSettings)
+}
 func (u *sqlSymUnion) transactionModes() tree.TransactionModes {
     return u.val.(tree.TransactionModes)
 }
@@ -400,6 +433,10 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
     return u.val.(tree.ScrubOption)
 }
 
+func (u *sqlSymUnion) fullBackupClause() *tree.FullBackupClause {
+    return u.val.(*tree.FullBackupClause)
+}
+
 %}
 
 // NB: the %token definitions must come before the %type definitions in this
@@ -419,39 +456,39 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 // below; search this file for "Keyword category lists".
 
 // Ordinary key words in alphabetical order.
-%token <str>   ABORT ACTION ADD
-%token <str>   ALL ALL_EXISTENCE ALTER ANALYSE ANALYZE AND ANY ANNOTATE_TYPE ARRAY AS ASC
+%token <str>   ABORT ABSOLUTE ACTION ADD ADMIN AFTER
+%token <str>   ALL ALL_EXISTENCE ALLOW ALTER ALWAYS ANALYSE ANALYZE AND ANY ANNOTATE_TYPE ARRAY AS ASC
 %token <str>   ASYMMETRIC AT
 
-%token <str>   BACKUP BEGIN BETWEEN BIGINT BIGSERIAL BIT
+%token <str>   BACKUP BACKWARD BEGIN BETWEEN BIGINT BIGSERIAL BIT
 %token <str>   BLOB BOOL BOOLEAN BOTH BY BYTEA BYTES
 
-%token <str>   CACHE CANCEL CASCADE CASE CAST CHAR
+%token <str>   CACHE CANCEL CASCADE CASE CAST CHANGEFEED CHAR
 %token <str>   CHARACTER CHARACTERISTICS CHECK
-%token <str>   CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMIT
+%token <str>   CLOSE CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMIT
 %token <str>   COMMITTED COMPACT CONCAT CONFIGURATION CONFIGURATIONS CONFIGURE
-%token <str>   CONFLICT CONSTRAINT CONSTRAINTS CONTAINS COPY COVERING CREATE
+%token <str>   CONFLICT CONNECTION CONSTRAINT CONSTRAINTS CONTAINS COPY COVERING CREATE
 %token <str>   CROSS CSV CUBE CURRENT CURRENT_CATALOG CURRENT_DATE CURRENT_SCHEMA
 %token <str>   CURRENT_ROLE CURRENT_TIME CURRENT_TIMESTAMP
-%token <str>   CURRENT_USER CYCLE
+%token <str>   CURRENT_USER CURSOR CYCLE
 
 %token <str>   DATA DATABASE DATABASES DATE DAY DEC DECIMAL DEFAULT
-%token <str>   DEALLOCATE DEFERRABLE DELETE DESC
-%token <str>   DISCARD DISTINCT DO DOUBLE DROP
+%token <str>   DEALLOCATE DECLARE DEFERRABLE DEFINER DELETE DENY DESC
+%token <str>   DISABLE DISCARD DISTINCT DO DOUBLE DROP DUMP
 
-%token <str>   ELSE ENCODING END ESCAPE EXCEPT
-%token <str>   EXISTS EXECUTE EXPERIMENTAL_FINGERPRINTS EXPERIMENTAL
-%token <str>   EXPLAIN EXTRACT EXTRACT_DURATION
+%token <str>   ELSE ENABLE ENCODING ENCRYPTION END ESCAPE EXCEPT
+%token <str>   EXISTS EXECUTE EXPERIMENTAL_AUDIT EXPERIMENTAL_FINGERPRINTS EXPERIMENTAL
+%token <str>   EXPIRE EXPLAIN EXPORT EXTRACT EXTRACT_DURATION
 
 %token <str>   FALSE FAMILY FETCH FETCHVAL FETCHTEXT FETCHVAL_PATH FETCHTEXT_PATH FILTER
-%token <str>   FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE_INDEX FOREIGN FROM FULL
+%token <str>   FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE_INDEX FOREIGN FORWARD FROM FULL
 
-%token <str>   GRANT GRANTS GREATEST GROUP GROUPING
+%token <str>   GLOBAL GRANT GRANTS GREATEST GROUP GROUPING
 
-%token <str>   HAVING HELP HIGH HOUR
+%token <str>   HAVING HELP HIGH HOLD HOUR
 
 %token <str>   IMPORT INCREMENT INCREMENTAL IF IFNULL ILIKE IN INET INTERLEAVE
-%token <str>   INDEX INDEXES INITIALLY
+%token <str>   INDEX INDEXES INHERIT INITIALLY INJECT INVOKER
 %token <str>   INNER INSERT INT INT2VECTOR INT2 INT4 INT8 INT64 INTEGER
 %token <str>   INTERSECT INTERVAL INTO IS ISOLATION
 
@@ -459,41 +496,41 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 
 %token <str>   KEY KEYS KV
 
-%token <str>   LATERAL LC_CTYPE LC_COLLATE
-%token <str>   LEADING LEAST LEFT LESS LEVEL LIKE LIMIT LIST LOCAL
-%token <str>   LOCALTIME LOCALTIMESTAMP LOW LSHIFT
+%token <str>   LAST LATERAL LC_CTYPE LC_COLLATE
+%token <str>   LEADING LEAST LEFT LESS LEVEL LIKE LIMIT LIST LISTEN LOCAL
+%token <str>   LOCALITY LOCALTIME LOCALTIMESTAMP LOGIN LOW LSHIFT
 
-%token <str>   MATCH MINVALUE MAXVALUE MINUTE MONTH
+%token <str>   MATCH MINVALUE MAXVALUE MINUTE MONTH MOVE MYSQLDUMP
 
-%token <str>   NAN NAME NAMES NATURAL NEXT NO NO_INDEX_JOIN NORMAL
-%token <str>   NOT NOTHING NULL NULLIF
+%token <str>   NAN NAME NAMES NATURAL NEXT NO NOINHERIT NOLOGIN NO_INDEX_JOIN NORMAL
+%token <str>   NOT NOTHING NOTIFY NULL NULLIF
 %token <str>   NULLS NUMERIC
 
-%token <str>   OF OFF OFFSET OID ON ONLY OPTIONS OR
-%token <str>   ORDER ORDINALITY OUT OUTER OVER OVERLAPS OVERLAY OWNED
+%token <str>   OF OFF OFFSET OID ON ONLY OPTION OPTIONS OR
+%token <str>   ORDER ORDINALITY OUT OUTER OVER OVERLAPS OVERLAY OWNED OWNER
 
-%token <str>   PARENT PARTIAL PARTITION PASSWORD PAUSE PHYSICAL PLACING
-%token <str>   PLANS POSITION PRECEDING PRECISION PREPARE PRIMARY PRIORITY
+%token <str>   PARENT PARTIAL PARTITION PASSWORD PATH PAUSE PGDUMP PHYSICAL PLACING
+%token <str>   PLANS POLICY POSITION PRECEDING PRECISION PREPARE PRIMARY PRIOR PRIORITY PRIVILEGES
 
-%token <str>   QUERIES QUERY
+%token <str>   QUERIES QUERY QUOTA
 
-%token <str>   RANGE READ REAL RECURSIVE REF REFERENCES
-%token <str>   REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
-%token <str>   REMOVE_PATH RENAME REPEATABLE
+%token <str>   RANGE RANGES READ REAL REASSIGN RECURRING RECURSIVE REF REFERENCES
+%token <str>   REGCLASS REGION REGIONAL REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
+%token <str>   RELATIVE REMOVE_PATH RENAME REPAIR REPEATABLE
 %token <str>   RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVOKE RIGHT
-%token <str>   ROLLBACK ROLLUP ROW ROWS RSHIFT
+%token <str>   ROLE ROLLBACK ROLLUP ROTATE ROW ROWS RSHIFT
 
-%token <str>   SAVEPOINT SCATTER SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str>   SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCROLL SCRUB SEARCH SECOND SECURITY SELECT SEQUENCE SEQUENCES
 %token <str>   SERIAL SERIALIZABLE SESSION SESSIONS SESSION_USER SET SETTING SETTINGS
 %token <str>   SHOW SIMILAR SIMPLE SMALLINT SMALLSERIAL SNAPSHOT SOME SOME_EXISTENCE SPLIT SQL
-%token <str>   START STATUS STDIN STRICT STRING STORE STORING SUBSTRING
-%token <str>   SYMMETRIC SYSTEM
+%token <str>   START STATEMENTS STATISTICS STATUS STDIN STDOUT STRICT STRING STORE STORING SUBSTRING
+%token <str>   SYMMETRIC SYNTAX SYSTEM
 
 %token <str>   TABLE TABLES TEMP TEMPLATE TEMPORARY TESTING_RANGES TESTING_RELOCATE TEXT THAN THEN
-%token <str>   TIME TIMESTAMP TIMESTAMPTZ TO TRAILING TRACE TRANSACTION TREAT TRIM TRUE
+%token <str>   TIME TIMESTAMP TIMESTAMPTZ TO TRAILING TRACE TRANSACTION TREAT TRIM TRUE TTL
 %token <str>   TRUNCATE TYPE
 
-%token <str>   UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN
+%token <str>   UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLISTEN UNTIL
 %token <str>   UPDATE UPSERT USE USER USERS USING UUID
 
 %token <str>   VALID VALIDATE VALUE VALUES VARCHAR VARIADIC VIEW VARYING
@@ -534,6 +571,7 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.Statement> alter_sequence_stmt
 %type <tree.Statement> alter_database_stmt
 %type <tree.Statement> alter_user_stmt
+%type <tree.Statement> alter_default_privileges_stmt
 %type <tree.Statement> alter_range_stmt
 
 // ALTER RANGE
@@ -543,16 +581,24 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.Statement> alter_onetable_stmt
 %type <tree.Statement> alter_split_stmt
 %type <tree.Statement> alter_rename_table_stmt
+%type <tree.Statement> alter_owner_table_stmt
+%type <tree.Statement> alter_rotate_key_stmt
 %type <tree.Statement> alter_scatter_stmt
 %type <tree.Statement> alter_testing_relocate_stmt
 %type <tree.Statement> alter_zone_table_stmt
 
 // ALTER DATABASE
 %type <tree.Statement> alter_rename_database_stmt
+%type <tree.Statement> alter_owner_database_stmt
 %type <tree.Statement> alter_zone_database_stmt
+%type <tree.Statement> alter_region_database_stmt
 
 // ALTER USER
 %type <tree.Statement> alter_user_password_stmt
+%type <tree.Statement> alter_user_connection_limit_stmt
+%type <tree.Statement> alter_user_valid_until_stmt
+%type <tree.Statement> alter_user_statements_stmt
+%type <tree.Statement> alter_user_quota_stmt
 
 // ALTER INDEX
 %type <tree.Statement> alter_scatter_index_stmt
@@ -563,9 +609,11 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 
 // ALTER VIEW
 %type <tree.Statement> alter_rename_view_stmt
+%type <tree.Statement> alter_owner_view_stmt
 
 // ALTER SEQUENCE
 %type <tree.Statement> alter_rename_sequence_stmt
+%type <tree.Statement> alter_owner_sequence_stmt
 %type <tree.Statement> alter_sequence_options_stmt
 
 %type <tree.Statement> backup_stmt
@@ -574,6 +622,7 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.Statement> cancel_stmt
 %type <tree.Statement> cancel_job_stmt
 %type <tree.Statement> cancel_query_stmt
+%type <tree.Statement> cancel_session_stmt
 
 // SCRUB
 %type <tree.Statement> scrub_stmt
@@ -582,16 +631,22 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.ScrubOptions> scrub_option_list
 %type <tree.ScrubOption> scrub_option
 
+%type <tree.Statement> close_cursor_stmt
 %type <tree.Statement> commit_stmt
 %type <tree.Statement> copy_from_stmt
+%type <tree.Statement> copy_to_stmt
 
 %type <tree.Statement> create_stmt
 %type <tree.Statement> create_ddl_stmt
+%type <tree.Statement> create_changefeed_stmt
+%type <tree.Statement> create_schedule_for_backup_stmt
 %type <tree.Statement> create_database_stmt
 %type <tree.Statement> create_index_stmt
+%type <tree.Statement> create_policy_stmt
 %type <tree.Statement> create_table_stmt
 %type <tree.Statement> create_table_as_stmt
 %type <tree.Statement> create_user_stmt
+%type <tree.Statement> create_role_stmt
 %type <tree.Statement> create_view_stmt
 %type <tree.Statement> create_sequence_stmt
 %type <tree.Statement> delete_stmt
@@ -601,8 +656,10 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.Statement> drop_ddl_stmt
 %type <tree.Statement> drop_database_stmt
 %type <tree.Statement> drop_index_stmt
+%type <tree.Statement> drop_policy_stmt
 %type <tree.Statement> drop_table_stmt
 %type <tree.Statement> drop_user_stmt
+%type <tree.Statement> drop_role_stmt
 %type <tree.Statement> drop_view_stmt
 %type <tree.Statement> drop_sequence_stmt
 
@@ -612,14 +669,23 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.Statement> explainable_stmt
 %type <tree.Statement> execute_stmt
 %type <tree.Statement> deallocate_stmt
+%type <tree.Statement> declare_cursor_stmt
+%type <tree.Statement> fetch_cursor_stmt
+%type <tree.Statement> move_cursor_stmt
+%type <*tree.FetchCursor> fetch_args
+%type <empty> opt_cursor_options opt_hold opt_from_in from_in
 %type <tree.Statement> grant_stmt
 %type <tree.Statement> insert_stmt
+%type <tree.Statement> export_stmt
 %type <tree.Statement> import_stmt
+%type <tree.Statement> listen_stmt
+%type <tree.Statement> notify_stmt
 %type <tree.Statement> pause_stmt
 %type <tree.Statement> release_stmt
 %type <tree.Statement> reset_stmt reset_session_stmt reset_csetting_stmt
 %type <tree.Statement> resume_stmt
 %type <tree.Statement> restore_stmt
+%type <tree.Statement> reassign_owned_stmt
 %type <tree.Statement> revoke_stmt
 %type <*tree.Select> select_stmt
 %type <tree.Statement> abort_stmt
@@ -641,25 +707,32 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.Statement> show_constraints_stmt
 %type <tree.Statement> show_create_table_stmt
 %type <tree.Statement> show_create_view_stmt
+%type <tree.Statement> show_create_sequence_stmt
 %type <tree.Statement> show_csettings_stmt
 %type <tree.Statement> show_databases_stmt
+%type <tree.Statement> show_dump_stmt
 %type <tree.Statement> show_grants_stmt
 %type <tree.Statement> show_indexes_stmt
 %type <tree.Statement> show_jobs_stmt
+%type <tree.Statement> show_schedules_stmt
 %type <tree.Statement> show_queries_stmt
 %type <tree.Statement> show_session_stmt
 %type <tree.Statement> show_sessions_stmt
+%type <tree.Statement> show_syntax_stmt
 %type <tree.Statement> show_tables_stmt
+%type <tree.Statement> show_ranges_stmt
 %type <tree.Statement> show_testing_stmt
 %type <tree.Statement> show_trace_stmt
 %type <tree.Statement> show_transaction_stmt
 %type <tree.Statement> show_users_stmt
 %type <tree.Statement> show_zone_stmt
+%type <tree.Statement> show_zone_config
 
 %type <str> session_var
 
 %type <tree.Statement> transaction_stmt
 %type <tree.Statement> truncate_stmt
+%type <tree.Statement> unlisten_stmt
 %type <tree.Statement> update_stmt
 %type <tree.Statement> upsert_stmt
 %type <tree.Statement> use_stmt
@@ -667,6 +740,12 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <[]string> opt_incremental
 %type <tree.KVOption> kv_option
 %type <[]tree.KVOption> kv_option_list opt_with_options
+%type <tree.KVOption> copy_option copy_legacy_option
+%type <[]tree.KVOption> copy_option_list copy_legacy_option_list opt_copy_options
+%type <*tree.SetZoneConfig> set_zone_config
+%type <tree.ZoneConfigSetting> zone_config_setting
+%type <tree.ZoneConfigSettings> zone_config_setting_list
+%type <tree.Expr> copy_option_arg
 %type <str> import_data_format
 
 %type <*tree.Select> select_no_parens
@@ -686,9 +765,22 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.DropBehavior> opt_interleave_drop_behavior
 
 %type <tree.ValidationBehavior> opt_validate_behavior
+%type <tree.AuditMode> audit_mode
+%type <tree.Locality> locality
+%type <*tree.Locality> opt_locality
 
 %type <str> opt_template_clause opt_encoding_clause opt_lc_collate_clause opt_lc_ctype_clause
 %type <tree.Expr> opt_password
+%type <tree.Expr> opt_valid_until
+%type <str> opt_policy_command policy_command
+%type <tree.NameList> opt_policy_roles
+%type <tree.NameList> opt_default_privileges_roles opt_default_privileges_schemas
+%type <tree.Expr> opt_policy_using opt_policy_with_check
+%type <*int64> opt_connection_limit
+%type <str> statement_class
+%type <tree.NameList> statement_class_list
+%type <[]tree.RoleOption> opt_role_options role_options
+%type <tree.RoleOption> role_option
 
 %type <tree.IsolationLevel> transaction_iso_level
 %type <tree.UserPriority>  transaction_user_priority
@@ -809,6 +901,8 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.Expr> numeric_only
 %type <tree.AliasClause> alias_clause opt_alias_clause
 %type <bool> opt_ordinality opt_compact
+%type <bool> opt_view_security
+%type <bool> opt_with_path
 %type <*tree.Order> sortby
 %type <tree.IndexElem> index_elem
 %type <tree.TableExpr> table_ref
@@ -846,7 +940,11 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <str>   non_reserved_word_or_sconst
 %type <tree.Expr>  zone_value
 %type <tree.Expr> string_or_placeholder
+%type <tree.Expr> sconst_or_placeholder opt_schedule_label
+%type <*tree.FullBackupClause> opt_full_backup_clause
 %type <tree.Expr> string_or_placeholder_list
+%type <tree.PartitionedBackup> partitioned_backup
+%type <tree.PartitionedBackups> partitioned_backup_list
 
 %type <str>   unreserved_keyword type_func_name_keyword
 %type <str>   col_name_keyword reserved_keyword
@@ -880,8 +978,9 @@ func (u *sqlSymUnion) scrubOption() tree.ScrubOption {
 %type <tree.TargetList>    targets
 %type <*tree.TargetList> on_privilege_target_clause
 %type <tree.NameList>       grantee_list for_grantee_clause
-%type <privilege.List> privileges privilege_list
-%type <privilege.Kind> privilege
+%type <privilege.List> privileges column_privilege
+%type <tree.NameList> privilege_list
+%type <str> privilege
 
 // Precedence: lowest to highest
 %nonassoc  VALUES              // see value_clause
@@ -976,20 +1075,29 @@ stmt:
 | alter_stmt      // help texts in sub-rule
 | backup_stmt     // EXTEND WITH HELP: BACKUP
 | cancel_stmt     // help texts in sub-rule
+| close_cursor_stmt // EXTEND WITH HELP: CLOSE
 | scrub_stmt
 | copy_from_stmt
+| copy_to_stmt
 | create_stmt     // help texts in sub-rule
 | deallocate_stmt // EXTEND WITH HELP: DEALLOCATE
+| declare_cursor_stmt // EXTEND WITH HELP: DECLARE
 | delete_stmt     // EXTEND WITH HELP: DELETE
 | discard_stmt    // EXTEND WITH HELP: DISCARD
 | drop_stmt       // help texts in sub-rule
 | execute_stmt    // EXTEND WITH HELP: EXECUTE
 | explain_stmt    // EXTEND WITH HELP: EXPLAIN
+| export_stmt     // EXTEND WITH HELP: EXPORT
+| fetch_cursor_stmt // EXTEND WITH HELP: FETCH
 | grant_stmt      // EXTEND WITH HELP: GRANT
 | insert_stmt     // EXTEND WITH HELP: INSERT
 | import_stmt     // EXTEND WITH HELP: IMPORT
+| listen_stmt     // EXTEND WITH HELP: LISTEN
+| move_cursor_stmt // EXTEND WITH HELP: MOVE
+| notify_stmt     // EXTEND WITH HELP: NOTIFY
 | pause_stmt      // EXTEND WITH HELP: PAUSE JOB
 | prepare_stmt    // EXTEND WITH HELP: PREPARE
+| reassign_owned_stmt // EXTEND WITH HELP: REASSIGN OWNED
 | restore_stmt    // EXTEND WITH HELP: RESTORE
 | resume_stmt     // EXTEND WITH HELP: RESUME JOB
 | revoke_stmt     // EXTEND WITH HELP: REVOKE
@@ -1004,6 +1112,7 @@ stmt:
 | show_stmt        // help texts in sub-rule
 | transaction_stmt // help texts in sub-rule
 | truncate_stmt    // EXTEND WITH HELP: TRUNCATE
+| unlisten_stmt    // EXTEND WITH HELP: UNLISTEN
 | update_stmt      // EXTEND WITH HELP: UPDATE
 | upsert_stmt      // EXTEND WITH HELP: UPSERT
 | /* EMPTY */
@@ -1017,6 +1126,7 @@ stmt:
 alter_stmt:
   alter_ddl_stmt      // help texts in sub-rule
 | alter_user_stmt     // EXTEND WITH HELP: ALTER USER
+| alter_default_privileges_stmt // EXTEND WITH HELP: ALTER DEFAULT PRIVILEGES
 | ALTER error         // SHOW HELP: ALTER
 
 alter_ddl_stmt:
@@ -1039,11 +1149,25 @@ alter_ddl_stmt:
 //   ALTER TABLE ... DROP CONSTRAINT [IF EXISTS] <constraintname> [RESTRICT | CASCADE]
 //   ALTER TABLE ... ALTER [COLUMN] <colname> {SET DEFAULT <expr> | DROP DEFAULT}
 //   ALTER TABLE ... ALTER [COLUMN] <colname> DROP NOT NULL
+//   ALTER TABLE ... ALTER PRIMARY KEY USING COLUMNS ( <colnames...> )
 //   ALTER TABLE ... RENAME TO <newname>
 //   ALTER TABLE ... RENAME [COLUMN] <colname> TO <newname>
+//   ALTER TABLE ... OWNER TO <rolename>
 //   ALTER TABLE ... VALIDATE CONSTRAINT <constraintname>
+//   ALTER TABLE ... {ENABLE | DISABLE} ROW LEVEL SECURITY
+//   ALTER TABLE ... EXPERIMENTAL_AUDIT SET {READ WRITE | OFF}
+//   ALTER TABLE ... INJECT STATISTICS <json>
+//   ALTER TABLE ... SET LOCALITY {GLOBAL | REGIONAL BY TABLE | REGIONAL BY ROW}
+//   ALTER TABLE ... SET TTL EXPIRE {AT <colname> | AFTER <interval>}
+//   ALTER TABLE ... RESET TTL
 //   ALTER TABLE ... SPLIT AT <selectclause>
 //   ALTER TABLE ... SCATTER [ FROM ( <exprs...> ) TO ( <exprs...> ) ]
+//   ALTER TABLE ... ROTATE ENCRYPTION KEY ( <colnames...> )
+//   ALTER TABLE ... [PARTITION <partition>] CONFIGURE ZONE USING <var> = <value> [, ...]
+//   ALTER TABLE ... [PARTITION <partition>] CONFIGURE ZONE DISCARD
+//
+// Zone config variables:
+//   num_replicas, constraints, lease_preferences, gc.ttlseconds, range_min_bytes, range_max_bytes
 //
 // Column qualifiers:
 //   [CONSTRAINT <constraintname>] {NULL | NOT NULL | UNIQUE | PRIMARY KEY | CHECK (<expr>) | DEFAULT <expr>}
@@ -1059,6 +1183,8 @@ alter_table_stmt:
 | alter_scatter_stmt
 | alter_zone_table_stmt
 | alter_rename_table_stmt
+| alter_owner_table_stmt
+| alter_rotate_key_stmt
 // ALTER TABLE has its error help token here because the ALTER TABLE
 // prefix is spread over multiple non-terminals.
 | ALTER TABLE error // SHOW HELP: ALTER TABLE
@@ -1067,9 +1193,11 @@ alter_table_stmt:
 // %Category: DDL
 // %Text:
 // ALTER VIEW [IF EXISTS] <name> RENAME TO <newname>
+// ALTER VIEW [IF EXISTS] <name> OWNER TO <rolename>
 // %SeeAlso: WEBDOCS/alter-view.html
 alter_view_stmt:
   alter_rename_view_stmt
+| alter_owner_view_stmt
 // ALTER VIEW has its error help token here because the ALTER VIEW
 // prefix is spread over multiple non-terminals.
 | ALTER VIEW error // SHOW HELP: ALTER VIEW
@@ -1084,8 +1212,10 @@ alter_view_stmt:
 //   [START <start>]
 //   [[NO] CYCLE]
 // ALTER SEQUENCE [IF EXISTS] <name> RENAME TO <newname>
+// ALTER SEQUENCE [IF EXISTS] <name> OWNER TO <rolename>
 alter_sequence_stmt:
   alter_rename_sequence_stmt
+| alter_owner_sequence_stmt
 | alter_sequence_options_stmt
 | ALTER SEQUENCE error // SHOW HELP: ALTER SEQUENCE
 
@@ -1103,19 +1233,91 @@ alter_sequence_options_stmt:
 // %Category: Priv
 // %Text:
 // ALTER USER [IF EXISTS] <name> WITH PASSWORD <password>
+// ALTER USER [IF EXISTS] <name> [WITH] CONNECTION LIMIT <limit>
+// ALTER USER [IF EXISTS] <name> [WITH] VALID UNTIL <timestamp>
+// ALTER USER [IF EXISTS] <name> [WITH] {ALLOW | DENY} STATEMENTS <classes...>
+// ALTER USER [IF EXISTS] <name> [WITH] ALLOW ALL STATEMENTS
+// ALTER USER [IF EXISTS] <name> [WITH] QUOTA <quota> = <limit>
+//
+// Classes of statements:
+//   SELECT, DML, DDL, EXPORT, IMPORT, PRIVILEGES, ADMIN
+//
+// Quotas (-1 removes the quota):
+//   MAX_QUERIES_PER_SECOND, MAX_ROWS_READ_PER_SECOND, MAX_CONCURRENT_STATEMENTS
 // %SeeAlso: CREATE USER
 alter_user_stmt:
   alter_user_password_stmt
+| alter_user_connection_limit_stmt
+| alter_user_valid_until_stmt
+| alter_user_statements_stmt
+| alter_user_quota_stmt
 | ALTER USER error // SHOW HELP: ALTER USER
 
+// %Help: ALTER DEFAULT PRIVILEGES - define the privileges of future tables
+// %Category: Priv
+// %Text:
+// ALTER DEFAULT PRIVILEGES [FOR ROLE <roles...>] [IN SCHEMA <databases...>]
+//   GRANT {ALL | <privileges...> } ON TABLES TO <grantees...>
+// ALTER DEFAULT PRIVILEGES [FOR ROLE <roles...>] [IN SCHEMA <databases...>]
+//   REVOKE {ALL | <privileges...> } ON TABLES FROM <grantees...>
+//
+// The privileges are granted on the tables, views and sequences
+// created afterwards by the roles (default: the current user) in the
+// databases (default: the current database).
+// %SeeAlso: GRANT, REVOKE
+alter_default_privileges_stmt:
+  ALTER DEFAULT PRIVILEGES opt_default_privileges_roles opt_default_privileges_schemas GRANT privileges ON TABLES TO grantee_list
+  {
+    $$.val = &tree.AlterDefaultPrivileges{Roles: $4.nameList(), Schemas: $5.nameList(), IsGrant: true, Privileges: $7.privilegeList(), Grantees: $11.nameList()}
+  }
+| ALTER DEFAULT PRIVILEGES opt_default_privileges_roles opt_default_privileges_schemas REVOKE privileges ON TABLES FROM grantee_list
+  {
+    $$.val = &tree.AlterDefaultPrivileges{Roles: $4.nameList(), Schemas: $5.nameList(), IsGrant: false, Privileges: $7.privilegeList(), Grantees: $11.nameList()}
+  }
+| ALTER DEFAULT PRIVILEGES error // SHOW HELP: ALTER DEFAULT PRIVILEGES
+
+opt_default_privileges_roles:
+  FOR ROLE name_list
+  {
+    $$.val = $3.nameList()
+  }
+| FOR USER name_list
+  {
+    $$.val = $3.nameList()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.NameList(nil)
+  }
+
+opt_default_privileges_schemas:
+  IN SCHEMA name_list
+  {
+    $$.val = $3.nameList()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.NameList(nil)
+  }
+
 // %Help: ALTER DATABASE - change the definition of a database
 // %Category: DDL
 // %Text:
 // ALTER DATABASE <name> RENAME TO <newname>
+// ALTER DATABASE <name> OWNER TO <rolename>
+// ALTER DATABASE <name> CONFIGURE ZONE USING <var> = <value> [, ...]
+// ALTER DATABASE <name> CONFIGURE ZONE DISCARD
+// ALTER DATABASE <name> SET PRIMARY REGION <region>
+// ALTER DATABASE <name> ADD REGION <region>
+//
+// Zone config variables:
+//   num_replicas, constraints, lease_preferences, gc.ttlseconds, range_min_bytes, range_max_bytes
 // %SeeAlso: WEBDOCS/alter-database.html
 alter_database_stmt:
   alter_rename_database_stmt
+| alter_owner_database_stmt
 |  alter_zone_database_stmt
+| alter_region_database_stmt
 // ALTER DATABASE has its error help token here because the ALTER DATABASE
 // prefix is spread over multiple non-terminals.
 | ALTER DATABASE error // SHOW HELP: ALTER DATABASE
@@ -1132,6 +1334,11 @@ alter_range_stmt:
 //   ALTER INDEX ... RENAME TO <newname>
 //   ALTER INDEX ... SPLIT AT <selectclause>
 //   ALTER INDEX ... SCATTER [ FROM ( <exprs...> ) TO ( <exprs...> ) ]
+//   ALTER INDEX ... [PARTITION <partition>] CONFIGURE ZONE USING <var> = <value> [, ...]
+//   ALTER INDEX ... [PARTITION <partition>] CONFIGURE ZONE DISCARD
+//
+// Zone config variables:
+//   num_replicas, constraints, lease_preferences, gc.ttlseconds, range_min_bytes, range_max_bytes
 //
 // %SeeAlso: WEBDOCS/alter-index.html
 alter_index_stmt:
@@ -1181,49 +1388,72 @@ alter_testing_relocate_index_stmt:
   }
 
 alter_zone_range_stmt:
-  ALTER RANGE unrestricted_name EXPERIMENTAL CONFIGURE ZONE a_expr_const
+  ALTER RANGE unrestricted_name set_zone_config
   {
-    /* SKIP DOC */
-    $$.val = &tree.SetZoneConfig{
-      ZoneSpecifier: tree.ZoneSpecifier{NamedZone: tree.UnrestrictedName($3)},
-      YAMLConfig: $7.expr(),
-    }
+    s := $4.setZoneConfig()
+    s.ZoneSpecifier = tree.ZoneSpecifier{NamedZone: tree.UnrestrictedName($3)}
+    $$.val = s
   }
 
 alter_zone_database_stmt:
-  ALTER DATABASE name EXPERIMENTAL CONFIGURE ZONE a_expr_const
+  ALTER DATABASE name set_zone_config
   {
-    /* SKIP DOC */
-    $$.val = &tree.SetZoneConfig{
-      ZoneSpecifier: tree.ZoneSpecifier{Database: tree.Name($3)},
-      YAMLConfig: $7.expr(),
-    }
+    s := $4.setZoneConfig()
+    s.ZoneSpecifier = tree.ZoneSpecifier{Database: tree.Name($3)}
+    $$.val = s
   }
 
 alter_zone_table_stmt:
-  ALTER TABLE qualified_name opt_partition EXPERIMENTAL CONFIGURE ZONE a_expr_const
+  ALTER TABLE qualified_name opt_partition set_zone_config
   {
-    /* SKIP DOC */
-    $$.val = &tree.SetZoneConfig{
-      ZoneSpecifier: tree.ZoneSpecifier{
-        TableOrIndex: tree.TableNameWithIndex{Table: $3.normalizableTableName()},
-        Partition: tree.Name($4),
-      },
-      YAMLConfig: $8.expr(),
+    s := $5.setZoneConfig()
+    s.ZoneSpecifier = tree.ZoneSpecifier{
+      TableOrIndex: tree.TableNameWithIndex{Table: $3.normalizableTableName()},
+      Partition: tree.Name($4),
     }
+    $$.val = s
   }
 
 alter_zone_index_stmt:
-  ALTER INDEX table_name_with_index opt_partition EXPERIMENTAL CONFIGURE ZONE a_expr_const
+  ALTER INDEX table_name_with_index opt_partition set_zone_config
   {
-    /* SKIP DOC */
-    $$.val = &tree.SetZoneConfig{
-      ZoneSpecifier: tree.ZoneSpecifier{
-        TableOrIndex: $3.tableWithIdx(),
-        Partition: tree.Name($4),
-      },
-      YAMLConfig: $8.expr(),
+    s := $5.setZoneConfig()
+    s.ZoneSpecifier = tree.ZoneSpecifier{
+      TableOrIndex: $3.tableWithIdx(),
+      Partition: tree.Name($4),
     }
+    $$.val = s
+  }
+
+set_zone_config:
+  CONFIGURE ZONE USING zone_config_setting_list
+  {
+    $$.val = &tree.SetZoneConfig{Settings: $4.zoneConfigSettings()}
+  }
+| CONFIGURE ZONE DISCARD
+  {
+    $$.val = &tree.SetZoneConfig{Discard: true}
+  }
+| EXPERIMENTAL CONFIGURE ZONE a_expr_const
+  {
+    /* SKIP DOC */
+    $$.val = &tree.SetZoneConfig{YAMLConfig: $4.expr()}
+  }
+
+zone_config_setting_list:
+  zone_config_setting
+  {
+    $$.val = tree.ZoneConfigSettings{$1.zoneConfigSetting()}
+  }
+| zone_config_setting_list ',' zone_config_setting
+  {
+    $$.val = append($1.zoneConfigSettings(), $3.zoneConfigSetting())
+  }
+
+zone_config_setting:
+  var_name '=' a_expr
+  {
+    $$.val = tree.ZoneConfigSetting{Key: $1.unresolvedName(), Value: $3.expr()}
   }
 
 alter_scatter_stmt:
@@ -1287,6 +1517,11 @@ alter_table_cmd:
   {
     $$.val = &tree.AlterTableDropNotNull{ColumnKeyword: $2.bool(), Column: tree.Name($3)}
   }
+  // ALTER TABLE <name> ALTER PRIMARY KEY USING COLUMNS ( <colnames...> )
+| ALTER PRIMARY KEY USING COLUMNS '(' index_params ')'
+  {
+    $$.val = &tree.AlterTableAlterPrimaryKey{Columns: $7.idxElems()}
+  }
   // ALTER TABLE <name> ALTER [COLUMN] <colname> SET NOT NULL
 | ALTER opt_column name SET NOT NULL { return unimplemented(sqllex, "alter set non null") }
   // ALTER TABLE <name> DROP [COLUMN] IF EXISTS <colname> [RESTRICT|CASCADE]
@@ -1322,6 +1557,46 @@ alter_table_cmd:
   }
   // ALTER TABLE <name> ALTER CONSTRAINT ...
 | ALTER CONSTRAINT name { return unimplemented(sqllex, "alter constraint") }
+  // ALTER TABLE <name> ENABLE ROW LEVEL SECURITY
+| ENABLE ROW LEVEL SECURITY
+  {
+    $$.val = &tree.AlterTableSetRowLevelSecurity{Enable: true}
+  }
+  // ALTER TABLE <name> DISABLE ROW LEVEL SECURITY
+| DISABLE ROW LEVEL SECURITY
+  {
+    $$.val = &tree.AlterTableSetRowLevelSecurity{Enable: false}
+  }
+  // ALTER TABLE <name> EXPERIMENTAL_AUDIT SET <mode>
+| EXPERIMENTAL_AUDIT SET audit_mode
+  {
+    $$.val = &tree.AlterTableSetAudit{Mode: $3.auditMode()}
+  }
+  // ALTER TABLE <name> SET LOCALITY <locality>
+| SET locality
+  {
+    $$.val = &tree.AlterTableSetLocality{Locality: $2.locality()}
+  }
+  // ALTER TABLE <name> SET TTL EXPIRE AT <colname>
+| SET TTL EXPIRE AT name
+  {
+    $$.val = &tree.AlterTableSetTTL{ExpirationColumn: tree.Name($5)}
+  }
+  // ALTER TABLE <name> SET TTL EXPIRE AFTER <interval>
+| SET TTL EXPIRE AFTER a_expr
+  {
+    $$.val = &tree.AlterTableSetTTL{ExpireAfter: $5.expr()}
+  }
+  // ALTER TABLE <name> RESET TTL
+| RESET TTL
+  {
+    $$.val = &tree.AlterTableResetTTL{}
+  }
+  // ALTER TABLE <name> INJECT STATISTICS <json>
+| INJECT STATISTICS a_expr
+  {
+    $$.val = &tree.AlterTableInjectStats{Stats: $3.expr()}
+  }
   // ALTER TABLE <name> VALIDATE CONSTRAINT ...
 | VALIDATE CONSTRAINT name
   {
@@ -1382,6 +1657,41 @@ opt_validate_behavior:
     $$.val = tree.ValidationDefault
   }
 
+audit_mode:
+  READ WRITE
+  {
+    $$.val = tree.AuditModeReadWrite
+  }
+| OFF
+  {
+    $$.val = tree.AuditModeDisable
+  }
+
+locality:
+  LOCALITY GLOBAL
+  {
+    $$.val = tree.LocalityGlobal
+  }
+| LOCALITY REGIONAL BY TABLE
+  {
+    $$.val = tree.LocalityRegionalByTable
+  }
+| LOCALITY REGIONAL BY ROW
+  {
+    $$.val = tree.LocalityRegionalByRow
+  }
+
+opt_locality:
+  locality
+  {
+    locality := $1.locality()
+    $$.val = &locality
+  }
+| /* EMPTY */
+  {
+    $$.val = (*tree.Locality)(nil)
+  }
+
 opt_collate_clause:
   COLLATE unrestricted_name { return unimplementedWithIssue(sqllex, 9851) }
 | /* EMPTY */ {}
@@ -1404,6 +1714,7 @@ alter_using:
 //
 // Location:
 //    "[scheme]://[host]/[path to backup]?[parameters]"
+//    ( "[scheme]://[host]/[path to backup]?COCKROACH_LOCALITY=<tier>&[parameters]" [, ...] )
 //
 // Options:
 //    INTO_DB
@@ -1411,9 +1722,9 @@ alter_using:
 //
 // %SeeAlso: RESTORE, WEBDOCS/backup.html
 backup_stmt:
-  BACKUP targets TO string_or_placeholder opt_as_of_clause opt_incremental opt_with_options
+  BACKUP targets TO partitioned_backup opt_as_of_clause opt_incremental opt_with_options
   {
-    $$.val = &tree.Backup{Targets: $2.targetList(), To: $4.expr(), IncrementalFrom: $6.exprs(), AsOf: $5.asOfClause(), Options: $7.kvOptions()}
+    $$.val = &tree.Backup{Targets: $2.targetList(), To: $4.partitionedBackup(), IncrementalFrom: $6.exprs(), AsOf: $5.asOfClause(), Options: $7.kvOptions()}
   }
 | BACKUP error // SHOW HELP: BACKUP
 
@@ -1430,6 +1741,7 @@ backup_stmt:
 //
 // Locations:
 //    "[scheme]://[host]/[path to backup]?[parameters]"
+//    ( "[scheme]://[host]/[path to backup]?COCKROACH_LOCALITY=<tier>&[parameters]" [, ...] )
 //
 // Options:
 //    INTO_DB
@@ -1437,21 +1749,83 @@ backup_stmt:
 //
 // %SeeAlso: BACKUP, WEBDOCS/restore.html
 restore_stmt:
-  RESTORE targets FROM string_or_placeholder_list opt_with_options
+  RESTORE targets FROM partitioned_backup_list opt_with_options
   {
-    $$.val = &tree.Restore{Targets: $2.targetList(), From: $4.exprs(), Options: $5.kvOptions()}
+    $$.val = &tree.Restore{Targets: $2.targetList(), From: $4.partitionedBackups(), Options: $5.kvOptions()}
   }
-| RESTORE targets FROM string_or_placeholder_list EXPERIMENTAL as_of_clause opt_with_options
+| RESTORE targets FROM partitioned_backup_list EXPERIMENTAL as_of_clause opt_with_options
   {
-    $$.val = &tree.Restore{Targets: $2.targetList(), From: $4.exprs(), AsOf: $6.asOfClause(), Options: $7.kvOptions()}
+    $$.val = &tree.Restore{Targets: $2.targetList(), From: $4.partitionedBackups(), AsOf: $6.asOfClause(), Options: $7.kvOptions()}
   }
 | RESTORE error // SHOW HELP: RESTORE
 
+// %Help: CREATE SCHEDULE FOR BACKUP - back up data periodically
+// %Category: CCL
+// %Text:
+// CREATE SCHEDULE [<label>]
+// FOR BACKUP <targets...> TO <location>
+// [WITH <option> [= <value>] [, ...]]
+// RECURRING <crontab>
+// [FULL BACKUP {<crontab> | ALWAYS}]
+//
+// Every time the crontab is due, a backup is written to a new subdirectory
+// of the location. The backups are incremental, on top of the previous full
+// backup, except when the FULL BACKUP crontab is due, or always with FULL
+// BACKUP ALWAYS. All the backups are full when FULL BACKUP is omitted.
+//
+// Crontab:
+//    '<minute> <hour> <day of month> <month> <day of week>'
+//    '@hourly', '@daily', '@weekly', '@monthly' or '@yearly'
+//
+// %SeeAlso: BACKUP, SHOW SCHEDULES
+create_schedule_for_backup_stmt:
+  CREATE SCHEDULE opt_schedule_label FOR BACKUP targets TO partitioned_backup opt_with_options RECURRING sconst_or_placeholder opt_full_backup_clause
+  {
+    $$.val = &tree.ScheduledBackup{
+      ScheduleLabel: $3.expr(),
+      Targets: $6.targetList(),
+      To: $8.partitionedBackup(),
+      BackupOptions: $9.kvOptions(),
+      Recurrence: $11.expr(),
+      FullBackup: $12.fullBackupClause(),
+    }
+  }
+| CREATE SCHEDULE error // SHOW HELP: CREATE SCHEDULE FOR BACKUP
+
+opt_schedule_label:
+  sconst_or_placeholder
+| /* EMPTY */
+  {
+    $$.val = tree.Expr(nil)
+  }
+
+opt_full_backup_clause:
+  FULL BACKUP sconst_or_placeholder
+  {
+    $$.val = &tree.FullBackupClause{Recurrence: $3.expr()}
+  }
+| FULL BACKUP ALWAYS
+  {
+    $$.val = &tree.FullBackupClause{AlwaysFull: true}
+  }
+| /* EMPTY */
+  {
+    $$.val = (*tree.FullBackupClause)(nil)
+  }
+
 import_data_format:
   CSV
   {
     $$ = "CSV"
   }
+| PGDUMP
+  {
+    $$ = "PGDUMP"
+  }
+| MYSQLDUMP
+  {
+    $$ = "MYSQLDUMP"
+  }
 
 // %Help: IMPORT - load data from file in a distributed manner
 // %Category: CCL
@@ -1464,12 +1838,16 @@ import_data_format:
 //
 // Formats:
 //    CSV
+//    PGDUMP
+//    MYSQLDUMP
 //
 // Options:
-//    distributed = '...'
+//    distributed
 //    sstsize = '...'
 //    temp = '...'
-//    comma = '...'          [CSV-specific]
+//    into_db = '...'
+//    transform_only
+//    delimiter = '...'      [CSV-specific]
 //    comment = '...'        [CSV-specific]
 //    nullif = '...'         [CSV-specific]
 //
@@ -1485,6 +1863,68 @@ import_stmt:
   }
 | IMPORT error // SHOW HELP: IMPORT
 
+// %Help: EXPORT - export data to file in a distributed manner
+// %Category: CCL
+// %Text:
+// EXPORT INTO <format> <datafile> [WITH <option> [= value] [,...]] FROM <query>
+//
+// Formats:
+//    CSV
+//
+// Options:
+//    delimiter = '...'   [CSV-specific]
+//    nullas = '...'      [CSV-specific]
+//    chunk_rows = '...'
+//
+// %SeeAlso: SELECT
+export_stmt:
+  EXPORT INTO CSV string_or_placeholder opt_with_options FROM select_stmt
+  {
+    $$.val = &tree.Export{Query: $7.slct(), FileFormat: "CSV", File: $4.expr(), Options: $5.kvOptions()}
+  }
+| EXPORT error // SHOW HELP: EXPORT
+
+// %Help: CREATE CHANGEFEED - create change data capture
+// %Category: CCL
+// %Text:
+// CREATE CHANGEFEED FOR <targets> INTO <sink> [WITH <option> [= <value>] [, ...]]
+// EXPERIMENTAL CHANGEFEED FOR <targets> [WITH <option> [= <value>] [, ...]]
+//
+// EXPERIMENTAL CHANGEFEED returns the changes to the client instead of
+// emitting them to a sink, until the statement is canceled.
+//
+// Targets:
+//    TABLE <pattern> [, ...]
+//
+// Sinks:
+//    'kafka://<host>:<port>[?topic_prefix=<prefix>]'
+//
+// Options:
+//    updated                            include the timestamp of each change in its row
+//    resolved = '...'                   periodically emit resolved timestamps at this interval
+//    envelope = 'row'                   emit the new value of each row (default)
+//    envelope = 'key_only'              emit only the key of each changed row
+//    envelope = 'wrapped'               emit the rows before and after each change
+//    format = 'json'                    encode the keys and values as JSON (default)
+//    format = 'experimental_avro'       encode the keys and values as Avro
+//    confluent_schema_registry = '...'  the URL of the registry of the Avro schemas
+//    schema_change_policy = 'backfill'  emit the tables again when their columns change (default)
+//    schema_change_policy = 'stop'      stop the changefeed when the columns of a table change
+//    schema_change_policy = 'ignore'    emit the following changes with the new columns
+//
+// %SeeAlso: SHOW JOBS, PAUSE JOB, CANCEL JOB
+create_changefeed_stmt:
+  CREATE CHANGEFEED FOR targets INTO string_or_placeholder opt_with_options
+  {
+    $$.val = &tree.CreateChangefeed{Targets: $4.targetList(), SinkURI: $6.expr(), Options: $7.kvOptions()}
+  }
+| EXPERIMENTAL CHANGEFEED FOR targets opt_with_options
+  {
+    $$.val = &tree.CreateChangefeed{Targets: $4.targetList(), Options: $5.kvOptions()}
+  }
+| CREATE CHANGEFEED error // SHOW HELP: CREATE CHANGEFEED
+| EXPERIMENTAL CHANGEFEED error // SHOW HELP: CREATE CHANGEFEED
+
 string_or_placeholder:
   non_reserved_word_or_sconst
   {
@@ -1495,6 +1935,16 @@ string_or_placeholder:
     $$.val = tree.NewPlaceholder($1)
   }
 
+sconst_or_placeholder:
+  SCONST
+  {
+    $$.val = tree.NewStrVal($1)
+  }
+| PLACEHOLDER
+  {
+    $$.val = tree.NewPlaceholder($1)
+  }
+
 string_or_placeholder_list:
   string_or_placeholder
   {
@@ -1505,6 +1955,26 @@ string_or_placeholder_list:
     $$.val = append($1.exprs(), $3.expr())
   }
 
+partitioned_backup:
+  string_or_placeholder
+  {
+    $$.val = tree.PartitionedBackup{$1.expr()}
+  }
+| '(' string_or_placeholder_list ')'
+  {
+    $$.val = tree.PartitionedBackup($2.exprs())
+  }
+
+partitioned_backup_list:
+  partitioned_backup
+  {
+    $$.val = tree.PartitionedBackups{$1.partitionedBackup()}
+  }
+| partitioned_backup_list ',' partitioned_backup
+  {
+    $$.val = append($1.partitionedBackups(), $3.partitionedBackup())
+  }
+
 opt_incremental:
   INCREMENTAL FROM string_or_placeholder_list
   {
@@ -1555,31 +2025,141 @@ opt_with_options:
 | /* EMPTY */ {}
 
 copy_from_stmt:
-  COPY qualified_name FROM STDIN
+  COPY qualified_name FROM STDIN opt_copy_options
+  {
+    $$.val = &tree.CopyFrom{Table: $2.normalizableTableName(), Stdin: true, Options: $5.kvOptions()}
+  }
+| COPY qualified_name '(' ')' FROM STDIN opt_copy_options
+  {
+    $$.val = &tree.CopyFrom{Table: $2.normalizableTableName(), Stdin: true, Options: $7.kvOptions()}
+  }
+| COPY qualified_name '(' qualified_name_list ')' FROM STDIN opt_copy_options
+  {
+    $$.val = &tree.CopyFrom{Table: $2.normalizableTableName(), Columns: $4.unresolvedNames(), Stdin: true, Options: $8.kvOptions()}
+  }
+
+copy_to_stmt:
+  COPY qualified_name TO STDOUT opt_copy_options
+  {
+    $$.val = &tree.CopyTo{Table: $2.normalizableTableName(), Options: $5.kvOptions()}
+  }
+| COPY qualified_name '(' qualified_name_list ')' TO STDOUT opt_copy_options
+  {
+    $$.val = &tree.CopyTo{Table: $2.normalizableTableName(), Columns: $4.unresolvedNames(), Options: $8.kvOptions()}
+  }
+| COPY select_with_parens TO STDOUT opt_copy_options
+  {
+    $$.val = &tree.CopyTo{Select: $2.selectStmt().(*tree.ParenSelect).Select, Options: $5.kvOptions()}
+  }
+
+// The options of COPY can be given either as a parenthesized list, or using
+// the syntax of PostgreSQL versions before 9.0 (e.g. WITH CSV HEADER).
+opt_copy_options:
+  WITH '(' copy_option_list ')'
+  {
+    $$.val = $3.kvOptions()
+  }
+| '(' copy_option_list ')'
+  {
+    $$.val = $2.kvOptions()
+  }
+| WITH copy_legacy_option_list
+  {
+    $$.val = $2.kvOptions()
+  }
+| copy_legacy_option_list
+  {
+    $$.val = $1.kvOptions()
+  }
+| /* EMPTY */ {}
+
+copy_option_list:
+  copy_option
+  {
+    $$.val = []tree.KVOption{$1.kvOption()}
+  }
+| copy_option_list ',' copy_option
+  {
+    $$.val = append($1.kvOptions(), $3.kvOption())
+  }
+
+copy_option:
+  name copy_option_arg
+  {
+    $$.val = tree.KVOption{Key: tree.Name($1), Value: $2.expr()}
+  }
+| name
+  {
+    $$.val = tree.KVOption{Key: tree.Name($1)}
+  }
+| NULL SCONST
+  {
+    $$.val = tree.KVOption{Key: tree.Name("null"), Value: tree.NewStrVal($2)}
+  }
+
+copy_option_arg:
+  name
   {
-    $$.val = &tree.CopyFrom{Table: $2.normalizableTableName(), Stdin: true}
+    $$.val = tree.NewStrVal($1)
   }
-| COPY qualified_name '(' ')' FROM STDIN
+| SCONST
   {
-    $$.val = &tree.CopyFrom{Table: $2.normalizableTableName(), Stdin: true}
+    $$.val = tree.NewStrVal($1)
   }
-| COPY qualified_name '(' qualified_name_list ')' FROM STDIN
+| TRUE
   {
-    $$.val = &tree.CopyFrom{Table: $2.normalizableTableName(), Columns: $4.unresolvedNames(), Stdin: true}
+    $$.val = tree.MakeDBool(true)
+  }
+| FALSE
+  {
+    $$.val = tree.MakeDBool(false)
+  }
+
+copy_legacy_option_list:
+  copy_legacy_option
+  {
+    $$.val = []tree.KVOption{$1.kvOption()}
+  }
+| copy_legacy_option_list copy_legacy_option
+  {
+    $$.val = append($1.kvOptions(), $2.kvOption())
+  }
+
+copy_legacy_option:
+  name
+  {
+    $$.val = tree.KVOption{Key: tree.Name($1)}
+  }
+| name SCONST
+  {
+    $$.val = tree.KVOption{Key: tree.Name($1), Value: tree.NewStrVal($2)}
+  }
+| name AS SCONST
+  {
+    $$.val = tree.KVOption{Key: tree.Name($1), Value: tree.NewStrVal($3)}
+  }
+| NULL SCONST
+  {
+    $$.val = tree.KVOption{Key: tree.Name("null"), Value: tree.NewStrVal($2)}
+  }
+| NULL AS SCONST
+  {
+    $$.val = tree.KVOption{Key: tree.Name("null"), Value: tree.NewStrVal($3)}
   }
 
 // %Help: CANCEL
 // %Category: Group
-// %Text: CANCEL JOB, CANCEL QUERY
+// %Text: CANCEL JOB, CANCEL QUERY, CANCEL SESSION
 cancel_stmt:
-  cancel_job_stmt   // EXTEND WITH HELP: CANCEL JOB
-| cancel_query_stmt // EXTEND WITH HELP: CANCEL QUERY
-| CANCEL error      // SHOW HELP: CANCEL
+  cancel_job_stmt     // EXTEND WITH HELP: CANCEL JOB
+| cancel_query_stmt   // EXTEND WITH HELP: CANCEL QUERY
+| cancel_session_stmt // EXTEND WITH HELP: CANCEL SESSION
+| CANCEL error        // SHOW HELP: CANCEL
 
 // %Help: CANCEL JOB - cancel a background job
 // %Category: Misc
 // %Text: CANCEL JOB <jobid>
-// %SeeAlso: SHOW JOBS, PAUSE JOBS, RESUME JOB
+// %SeeAlso: SHOW JOBS, PAUSE JOB, RESUME JOB
 cancel_job_stmt:
   CANCEL JOB a_expr
   {
@@ -1598,19 +2178,35 @@ cancel_query_stmt:
   }
 | CANCEL QUERY error // SHOW HELP: CANCEL QUERY
 
+// %Help: CANCEL SESSION - cancel an open session
+// %Category: Misc
+// %Text: CANCEL SESSION <sessionid>
+// %SeeAlso: SHOW SESSIONS
+cancel_session_stmt:
+  CANCEL SESSION a_expr
+  {
+    $$.val = &tree.CancelSession{ID: $3.expr()}
+  }
+| CANCEL SESSION error // SHOW HELP: CANCEL SESSION
+
 // %Help: CREATE
 // %Category: Group
 // %Text:
 // CREATE DATABASE, CREATE TABLE, CREATE INDEX, CREATE TABLE AS,
-// CREATE USER, CREATE VIEW, CREATE SEQUENCE
+// CREATE USER, CREATE ROLE, CREATE VIEW, CREATE SEQUENCE, CREATE POLICY,
+// CREATE CHANGEFEED, CREATE SCHEDULE FOR BACKUP
 create_stmt:
   create_user_stmt     // EXTEND WITH HELP: CREATE USER
+| create_changefeed_stmt // EXTEND WITH HELP: CREATE CHANGEFEED
+| create_schedule_for_backup_stmt // EXTEND WITH HELP: CREATE SCHEDULE FOR BACKUP
+| create_role_stmt     // EXTEND WITH HELP: CREATE ROLE
 | create_ddl_stmt      // help texts in sub-rule
 | CREATE error         // SHOW HELP: CREATE
 
 create_ddl_stmt:
   create_database_stmt // EXTEND WITH HELP: CREATE DATABASE
 | create_index_stmt    // EXTEND WITH HELP: CREATE INDEX
+| create_policy_stmt   // EXTEND WITH HELP: CREATE POLICY
 | create_table_stmt    // EXTEND WITH HELP: CREATE TABLE
 | create_table_as_stmt // EXTEND WITH HELP: CREATE TABLE
 // Error case for both CREATE TABLE and CREATE TABLE ... AS in one
@@ -1640,29 +2236,44 @@ delete_stmt:
 
 // %Help: DISCARD - reset the session to its initial state
 // %Category: Cfg
-// %Text: DISCARD ALL
+// %Text: DISCARD { ALL | PLANS | SEQUENCES | TEMP | TEMPORARY }
 discard_stmt:
   DISCARD ALL
   {
     $$.val = &tree.Discard{Mode: tree.DiscardModeAll}
   }
-| DISCARD PLANS { return unimplemented(sqllex, "discard plans") }
-| DISCARD SEQUENCES { return unimplemented(sqllex, "discard sequences") }
-| DISCARD TEMP { return unimplemented(sqllex, "discard temp") }
-| DISCARD TEMPORARY { return unimplemented(sqllex, "discard temporary") }
+| DISCARD PLANS
+  {
+    $$.val = &tree.Discard{Mode: tree.DiscardModePlans}
+  }
+| DISCARD SEQUENCES
+  {
+    $$.val = &tree.Discard{Mode: tree.DiscardModeSequences}
+  }
+| DISCARD TEMP
+  {
+    $$.val = &tree.Discard{Mode: tree.DiscardModeTemp}
+  }
+| DISCARD TEMPORARY
+  {
+    $$.val = &tree.Discard{Mode: tree.DiscardModeTemp}
+  }
 | DISCARD error // SHOW HELP: DISCARD
 
 // %Help: DROP
 // %Category: Group
-// %Text: DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP SEQUENCE, DROP USER
+// %Text: DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP SEQUENCE, DROP USER, DROP ROLE,
+// DROP POLICY
 drop_stmt:
   drop_ddl_stmt      // help texts in sub-rule
 | drop_user_stmt     // EXTEND WITH HELP: DROP USER
+| drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
 | DROP error         // SHOW HELP: DROP
 
 drop_ddl_stmt:
   drop_database_stmt // EXTEND WITH HELP: DROP DATABASE
 | drop_index_stmt    // EXTEND WITH HELP: DROP INDEX
+| drop_policy_stmt   // EXTEND WITH HELP: DROP POLICY
 | drop_table_stmt    // EXTEND WITH HELP: DROP TABLE
 | drop_view_stmt     // EXTEND WITH HELP: DROP VIEW
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
@@ -1735,6 +2346,21 @@ drop_index_stmt:
   }
 | DROP INDEX error // SHOW HELP: DROP INDEX
 
+// %Help: DROP POLICY - remove a row-level security policy
+// %Category: DDL
+// %Text: DROP POLICY [IF EXISTS] <name> ON <tablename>
+// %SeeAlso: CREATE POLICY
+drop_policy_stmt:
+  DROP POLICY name ON qualified_name
+  {
+    $$.val = &tree.DropPolicy{Name: tree.Name($3), Table: $5.normalizableTableName(), IfExists: false}
+  }
+| DROP POLICY IF EXISTS name ON qualified_name
+  {
+    $$.val = &tree.DropPolicy{Name: tree.Name($5), Table: $7.normalizableTableName(), IfExists: true}
+  }
+| DROP POLICY error // SHOW HELP: DROP POLICY
+
 // %Help: DROP DATABASE - remove a database
 // %Category: DDL
 // %Text: DROP DATABASE [IF EXISTS] <databasename> [CASCADE | RESTRICT]
@@ -1773,6 +2399,21 @@ drop_user_stmt:
   }
 | DROP USER error // SHOW HELP: DROP USER
 
+// %Help: DROP ROLE - remove a role
+// %Category: Priv
+// %Text: DROP ROLE [IF EXISTS] <role> [, ...]
+// %SeeAlso: CREATE ROLE, REVOKE
+drop_role_stmt:
+  DROP ROLE string_or_placeholder_list
+  {
+    $$.val = &tree.DropUser{Names: $3.exprs(), IfExists: false, IsRole: true}
+  }
+| DROP ROLE IF EXISTS string_or_placeholder_list
+  {
+    $$.val = &tree.DropUser{Names: $5.exprs(), IfExists: true, IsRole: true}
+  }
+| DROP ROLE error // SHOW HELP: DROP ROLE
+
 table_name_list:
   any_name
   {
@@ -1808,13 +2449,18 @@ attrs:
 // %Text:
 // EXPLAIN <statement>
 // EXPLAIN [( [PLAN ,] <planoptions...> )] <statement>
+// EXPLAIN ANALYZE (DEBUG) <statement>
 //
 // Explainable statements:
 //     SELECT, CREATE, DROP, ALTER, INSERT, UPSERT, UPDATE, DELETE,
 //     SHOW, EXPLAIN, EXECUTE
 //
 // Plan options:
-//     TYPES, EXPRS, METADATA, QUALIFY, INDENT, VERBOSE, DIST_SQL
+//     TYPES, EXPRS, METADATA, QUALIFY, INDENT, VERBOSE, DIST_SQL, DDL
+//
+// EXPLAIN ANALYZE (DEBUG) executes the statement and collects a bundle
+// of its SQL, environment, schema, statistics, plan and trace, which can
+// be downloaded from the URL it returns.
 //
 // %SeeAlso: WEBDOCS/explain.html
 explain_stmt:
@@ -1827,19 +2473,29 @@ explain_stmt:
   {
     $$.val = &tree.Explain{Options: $3.strs(), Statement: $5.stmt()}
   }
+| EXPLAIN ANALYZE '(' explain_option_list ')' explainable_stmt
+  {
+    $$.val = &tree.Explain{Analyze: true, Options: $4.strs(), Statement: $6.stmt()}
+  }
 // This second error rule is necessary, because otherwise
 // explainable_stmt also provides "selectclause := '(' error ..."  and
 // cause a help text for the select clause, which will be confusing in
 // the context of EXPLAIN.
 | EXPLAIN '(' error // SHOW HELP: EXPLAIN
+| EXPLAIN ANALYZE error // SHOW HELP: EXPLAIN
 
 preparable_stmt:
   alter_user_stmt   // EXTEND WITH HELP: ALTER USER
 | backup_stmt       // EXTEND WITH HELP: BACKUP
 | cancel_stmt       // help texts in sub-rule
+| create_changefeed_stmt // EXTEND WITH HELP: CREATE CHANGEFEED
+| create_schedule_for_backup_stmt // EXTEND WITH HELP: CREATE SCHEDULE FOR BACKUP
 | create_user_stmt  // EXTEND WITH HELP: CREATE USER
+| create_role_stmt  // EXTEND WITH HELP: CREATE ROLE
 | delete_stmt       // EXTEND WITH HELP: DELETE
 | drop_user_stmt    // EXTEND WITH HELP: DROP USER
+| drop_role_stmt    // EXTEND WITH HELP: DROP ROLE
+| export_stmt       // EXTEND WITH HELP: EXPORT
 | import_stmt       // EXTEND WITH HELP: IMPORT
 | insert_stmt       // EXTEND WITH HELP: INSERT
 | pause_stmt        // EXTEND WITH HELP: PAUSE JOB
@@ -1951,10 +2607,215 @@ deallocate_stmt:
   }
 | DEALLOCATE error // SHOW HELP: DEALLOCATE
 
-// %Help: GRANT - define access privileges
+// %Help: DECLARE - define a cursor
+// %Category: Misc
+// %Text: DECLARE <name> [NO SCROLL] CURSOR [WITHOUT HOLD] FOR <selectclause>
+//
+// Cursors can only be declared inside a transaction block and are
+// closed when the transaction ends.
+// %SeeAlso: FETCH, MOVE, CLOSE,
+// https://www.postgresql.org/docs/current/static/sql-declare.html
+declare_cursor_stmt:
+  DECLARE name opt_cursor_options CURSOR opt_hold FOR select_stmt
+  {
+    $$.val = &tree.DeclareCursor{Name: tree.Name($2), Select: $7.slct()}
+  }
+| DECLARE error // SHOW HELP: DECLARE
+
+opt_cursor_options:
+  NO SCROLL {}
+| SCROLL { return unimplemented(sqllex, "scroll cursor") }
+| /* EMPTY */ {}
+
+opt_hold:
+  WITHOUT HOLD {}
+| WITH HOLD { return unimplemented(sqllex, "cursor with hold") }
+| /* EMPTY */ {}
+
+// %Help: FETCH - retrieve rows from a cursor
+// %Category: Misc
+// %Text:
+// FETCH [ <direction> [ FROM | IN ] ] <cursor>
+//
+// Direction:
+//   NEXT, FIRST, ABSOLUTE <count>, RELATIVE <count>, <count>, ALL,
+//   FORWARD [ <count> | ALL ]
+//
+// Cursors can only scan forward.
+// %SeeAlso: DECLARE, MOVE, CLOSE,
+// https://www.postgresql.org/docs/current/static/sql-fetch.html
+fetch_cursor_stmt:
+  FETCH fetch_args
+  {
+    $$.val = $2.fetchCursor()
+  }
+| FETCH error // SHOW HELP: FETCH
+
+// %Help: MOVE - position a cursor
+// %Category: Misc
+// %Text: MOVE [ <direction> [ FROM | IN ] ] <cursor>
+//
+// MOVE accepts the same directions as FETCH and reports the number of
+// rows skipped instead of returning them.
+// %SeeAlso: DECLARE, FETCH, CLOSE,
+// https://www.postgresql.org/docs/current/static/sql-move.html
+move_cursor_stmt:
+  MOVE fetch_args
+  {
+    n := $2.fetchCursor()
+    n.Move = true
+    $$.val = n
+  }
+| MOVE error // SHOW HELP: MOVE
+
+fetch_args:
+  name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($1), Direction: tree.FetchForward, Count: 1}
+  }
+| from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($2), Direction: tree.FetchForward, Count: 1}
+  }
+| NEXT opt_from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($3), Direction: tree.FetchForward, Count: 1}
+  }
+| PRIOR opt_from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($3), Direction: tree.FetchBackward, Count: 1}
+  }
+| FIRST opt_from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($3), Direction: tree.FetchAbsolute, Count: 1}
+  }
+| LAST opt_from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($3), Direction: tree.FetchAbsolute, Count: -1}
+  }
+| ABSOLUTE signed_iconst64 opt_from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($4), Direction: tree.FetchAbsolute, Count: $2.int64()}
+  }
+| RELATIVE signed_iconst64 opt_from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($4), Direction: tree.FetchRelative, Count: $2.int64()}
+  }
+| signed_iconst64 opt_from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($3), Direction: tree.FetchForward, Count: $1.int64()}
+  }
+| ALL opt_from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($3), Direction: tree.FetchForward, Count: tree.FetchAll}
+  }
+| FORWARD opt_from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($3), Direction: tree.FetchForward, Count: 1}
+  }
+| FORWARD signed_iconst64 opt_from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($4), Direction: tree.FetchForward, Count: $2.int64()}
+  }
+| FORWARD ALL opt_from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($4), Direction: tree.FetchForward, Count: tree.FetchAll}
+  }
+| BACKWARD opt_from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($3), Direction: tree.FetchBackward, Count: 1}
+  }
+| BACKWARD signed_iconst64 opt_from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($4), Direction: tree.FetchBackward, Count: $2.int64()}
+  }
+| BACKWARD ALL opt_from_in name
+  {
+    $$.val = &tree.FetchCursor{Name: tree.Name($4), Direction: tree.FetchBackward, Count: tree.FetchAll}
+  }
+
+from_in:
+  FROM {}
+| IN {}
+
+opt_from_in:
+  from_in {}
+| /* EMPTY */ {}
+
+// %Help: CLOSE - close a cursor
+// %Category: Misc
+// %Text: CLOSE { <name> | ALL }
+// %SeeAlso: DECLARE, FETCH, MOVE,
+// https://www.postgresql.org/docs/current/static/sql-close.html
+close_cursor_stmt:
+  CLOSE name
+  {
+    $$.val = &tree.CloseCursor{Name: tree.Name($2)}
+  }
+| CLOSE ALL
+  {
+    $$.val = &tree.CloseCursor{}
+  }
+| CLOSE error // SHOW HELP: CLOSE
+
+// %Help: LISTEN - listen for notifications
+// %Category: Misc
+// %Text: LISTEN <channel>
+//
+// The notifications sent to the channel with NOTIFY, from any node of the
+// cluster, are delivered to the client while it is not in a transaction.
+// %SeeAlso: NOTIFY, UNLISTEN,
+// https://www.postgresql.org/docs/current/static/sql-listen.html
+listen_stmt:
+  LISTEN name
+  {
+    $$.val = &tree.Listen{Channel: tree.Name($2)}
+  }
+| LISTEN error // SHOW HELP: LISTEN
+
+// %Help: UNLISTEN - stop listening for notifications
+// %Category: Misc
+// %Text: UNLISTEN { <channel> | * }
+// %SeeAlso: LISTEN, NOTIFY,
+// https://www.postgresql.org/docs/current/static/sql-unlisten.html
+unlisten_stmt:
+  UNLISTEN name
+  {
+    $$.val = &tree.Unlisten{Channel: tree.Name($2)}
+  }
+| UNLISTEN '*'
+  {
+    $$.val = &tree.Unlisten{}
+  }
+| UNLISTEN error // SHOW HELP: UNLISTEN
+
+// %Help: NOTIFY - send a notification
+// %Category: Misc
+// %Text: NOTIFY <channel> [, <payload>]
+//
+// Inside a transaction, the notification is only sent if and when the
+// transaction commits.
+// %SeeAlso: LISTEN, UNLISTEN,
+// https://www.postgresql.org/docs/current/static/sql-notify.html
+notify_stmt:
+  NOTIFY name
+  {
+    $$.val = &tree.Notify{Channel: tree.Name($2)}
+  }
+| NOTIFY name ',' SCONST
+  {
+    $$.val = &tree.Notify{Channel: tree.Name($2), Payload: $4}
+  }
+| NOTIFY error // SHOW HELP: NOTIFY
+
+// %Help: GRANT - define access privileges and role memberships
 // %Category: Priv
 // %Text:
-// GRANT {ALL | <privileges...> } ON <targets...> TO <grantees...>
+// Grant privileges:
+//   GRANT {ALL | <privileges...> } ON <targets...> TO <grantees...>
+//   GRANT SELECT (<colnames...>) ON <tables...> TO <grantees...>
+// Grant role membership:
+//   GRANT <roles...> TO <grantees...> [WITH ADMIN OPTION]
 //
 // Privileges:
 //   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE
@@ -1963,18 +2824,48 @@ deallocate_stmt:
 //   DATABASE <databasename> [, ...]
 //   [TABLE] [<databasename> .] { <tablename> | * } [, ...]
 //
-// %SeeAlso: REVOKE, WEBDOCS/grant.html
+// %SeeAlso: REVOKE, CREATE ROLE, WEBDOCS/grant.html
 grant_stmt:
   GRANT privileges ON targets TO grantee_list
   {
     $$.val = &tree.Grant{Privileges: $2.privilegeList(), Grantees: $6.nameList(), Targets: $4.targetList()}
   }
+| GRANT column_privilege '(' name_list ')' ON targets TO grantee_list
+  {
+    $$.val = &tree.Grant{Privileges: $2.privilegeList(), Columns: $4.nameList(), Grantees: $9.nameList(), Targets: $7.targetList()}
+  }
+| GRANT privilege_list TO grantee_list
+  {
+    $$.val = &tree.GrantRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: false}
+  }
+| GRANT privilege_list TO grantee_list WITH ADMIN OPTION
+  {
+    $$.val = &tree.GrantRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: true}
+  }
 | GRANT error // SHOW HELP: GRANT
 
-// %Help: REVOKE - remove access privileges
+// %Help: REASSIGN OWNED - change the owner of the objects owned by roles
+// %Category: Priv
+// %Text: REASSIGN OWNED BY <roles...> TO <rolename>
+//
+// The databases, tables, views and sequences owned by the roles in
+// any database are transferred to the new owner.
+// %SeeAlso: ALTER TABLE, ALTER DATABASE, DROP USER
+reassign_owned_stmt:
+  REASSIGN OWNED BY name_list TO name
+  {
+    $$.val = &tree.ReassignOwnedBy{OldRoles: $4.nameList(), NewRole: tree.Name($6)}
+  }
+| REASSIGN error // SHOW HELP: REASSIGN OWNED
+
+// %Help: REVOKE - remove access privileges and role memberships
 // %Category: Priv
 // %Text:
-// REVOKE {ALL | <privileges...> } ON <targets...> FROM <grantees...>
+// Revoke privileges:
+//   REVOKE {ALL | <privileges...> } ON <targets...> FROM <grantees...>
+//   REVOKE SELECT (<colnames...>) ON <tables...> FROM <grantees...>
+// Revoke role membership:
+//   REVOKE [ADMIN OPTION FOR] <roles...> FROM <grantees...>
 //
 // Privileges:
 //   CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE
@@ -1983,12 +2874,24 @@ grant_stmt:
 //   DATABASE <databasename> [, <databasename>]...
 //   [TABLE] [<databasename> .] { <tablename> | * } [, ...]
 //
-// %SeeAlso: GRANT, WEBDOCS/revoke.html
+// %SeeAlso: GRANT, DROP ROLE, WEBDOCS/revoke.html
 revoke_stmt:
   REVOKE privileges ON targets FROM grantee_list
   {
     $$.val = &tree.Revoke{Privileges: $2.privilegeList(), Grantees: $6.nameList(), Targets: $4.targetList()}
   }
+| REVOKE column_privilege '(' name_list ')' ON targets FROM grantee_list
+  {
+    $$.val = &tree.Revoke{Privileges: $2.privilegeList(), Columns: $4.nameList(), Grantees: $9.nameList(), Targets: $7.targetList()}
+  }
+| REVOKE privilege_list FROM grantee_list
+  {
+    $$.val = &tree.RevokeRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: false}
+  }
+| REVOKE ADMIN OPTION FOR privilege_list FROM grantee_list
+  {
+    $$.val = &tree.RevokeRole{Roles: $5.nameList(), Members: $7.nameList(), AdminOption: true}
+  }
 | REVOKE error // SHOW HELP: REVOKE
 
 targets:
@@ -2009,53 +2912,49 @@ targets:
 privileges:
   ALL
   {
-    $$.val = privilege.List{privilege.ALL}
-  }
-  | privilege_list { }
-
-privilege_list:
-  privilege
-  {
-    $$.val = privilege.List{$1.privilegeType()}
-  }
-  | privilege_list ',' privilege
-  {
-    $$.val = append($1.privilegeList(), $3.privilegeType())
-  }
-
-// This list must match the list of privileges in sql/privilege/privilege.go.
-privilege:
-  CREATE
-  {
-    $$.val = privilege.CREATE
-  }
-| DROP
-  {
-    $$.val = privilege.DROP
-  }
-| GRANT
-  {
-    $$.val = privilege.GRANT
+    $$.val = privilege.List{privilege.ALL}
   }
-| SELECT
+| privilege_list
   {
-    $$.val = privilege.SELECT
+     privList, err := privilege.ListFromStrings($1.nameList().ToStrings())
+     if err != nil {
+       sqllex.Error(err.Error())
+       return 1
+     }
+     $$.val = privList
   }
-| INSERT
+
+column_privilege:
+  privilege
   {
-    $$.val = privilege.INSERT
+     privList, err := privilege.ListFromStrings([]string{$1})
+     if err != nil {
+       sqllex.Error(err.Error())
+       return 1
+     }
+     $$.val = privList
   }
-| DELETE
+
+privilege_list:
+  privilege
   {
-    $$.val = privilege.DELETE
+    $$.val = tree.NameList{tree.Name($1)}
   }
-| UPDATE
+| privilege_list ',' privilege
   {
-    $$.val = privilege.UPDATE
+    $$.val = append($1.nameList(), tree.Name($3))
   }
 
-// TODO(marc): this should not be 'name', but should instead be a
-// type just for usernames.
+// Privileges are parsed as names and resolved by privilege.ListFromStrings,
+// so that GRANT <privileges> ON ... and GRANT <roles> TO ... can share a
+// prefix. Only the reserved keywords among the privileges need to be listed
+// here; the full list is in sql/privilege/privilege.go.
+privilege:
+  name
+| CREATE
+| GRANT
+| SELECT
+
 grantee_list:
   name
   {
@@ -2072,7 +2971,7 @@ reset_stmt:
 
 // %Help: RESET - reset a session variable to its default value
 // %Category: Cfg
-// %Text: RESET [SESSION] <var>
+// %Text: RESET [SESSION] { <var> | ALL }
 // %SeeAlso: RESET CLUSTER SETTING, WEBDOCS/set-vars.html
 reset_session_stmt:
   RESET session_var
@@ -2155,6 +3054,12 @@ scrub_database_stmt:
 //   EXPERIMENTAL SCRUB TABLE ... WITH OPTIONS INDEX ALL
 //   EXPERIMENTAL SCRUB TABLE ... WITH OPTIONS INDEX (<index>...)
 //   EXPERIMENTAL SCRUB TABLE ... WITH OPTIONS PHYSICAL
+//   EXPERIMENTAL SCRUB TABLE ... WITH OPTIONS CONSTRAINT ALL
+//   EXPERIMENTAL SCRUB TABLE ... WITH OPTIONS CONSTRAINT (<constraint>...)
+//   EXPERIMENTAL SCRUB TABLE ... WITH OPTIONS REPAIR
+//
+// With REPAIR, the secondary indexes in which the index checks found errors
+// are repaired by a job, and the errors are reported as repaired.
 // %SeeAlso: SCRUB DATABASE, SRUB
 scrub_table_stmt:
   EXPERIMENTAL SCRUB TABLE qualified_name
@@ -2190,6 +3095,18 @@ scrub_option:
   {
     $$.val = &tree.ScrubOptionPhysical{}
   }
+| CONSTRAINT ALL
+  {
+    $$.val = &tree.ScrubOptionConstraint{}
+  }
+| CONSTRAINT '(' name_list ')'
+  {
+    $$.val = &tree.ScrubOptionConstraint{ConstraintNames: $3.nameList()}
+  }
+| REPAIR
+  {
+    $$.val = &tree.ScrubOptionRepair{}
+  }
 
 // %Help: SET CLUSTER SETTING - change a cluster setting
 // %Category: Cfg
@@ -2391,28 +3308,34 @@ non_reserved_word_or_sconst:
 // %Category: Group
 // %Text:
 // SHOW SESSION, SHOW CLUSTER SETTING, SHOW DATABASES, SHOW TABLES, SHOW COLUMNS, SHOW INDEXES,
-// SHOW CONSTRAINTS, SHOW CREATE TABLE, SHOW CREATE VIEW, SHOW USERS, SHOW TRANSACTION, SHOW BACKUP,
-// SHOW JOBS, SHOW QUERIES, SHOW SESSIONS, SHOW TRACE
+// SHOW CONSTRAINTS, SHOW CREATE TABLE, SHOW CREATE VIEW, SHOW CREATE SEQUENCE, SHOW USERS,
+// SHOW TRANSACTION, SHOW BACKUP, SHOW JOBS, SHOW QUERIES, SHOW SESSIONS, SHOW TRACE, SHOW ZONE,
+// SHOW DUMP, SHOW SCHEDULES, SHOW SYNTAX
 show_stmt:
   show_backup_stmt       // EXTEND WITH HELP: SHOW BACKUP
 | show_columns_stmt      // EXTEND WITH HELP: SHOW COLUMNS
 | show_constraints_stmt  // EXTEND WITH HELP: SHOW CONSTRAINTS
 | show_create_table_stmt // EXTEND WITH HELP: SHOW CREATE TABLE
 | show_create_view_stmt  // EXTEND WITH HELP: SHOW CREATE VIEW
+| show_create_sequence_stmt // EXTEND WITH HELP: SHOW CREATE SEQUENCE
 | show_csettings_stmt    // EXTEND WITH HELP: SHOW CLUSTER SETTING
 | show_databases_stmt    // EXTEND WITH HELP: SHOW DATABASES
+| show_dump_stmt         // EXTEND WITH HELP: SHOW DUMP
 | show_grants_stmt       // EXTEND WITH HELP: SHOW GRANTS
 | show_indexes_stmt      // EXTEND WITH HELP: SHOW INDEXES
 | show_jobs_stmt         // EXTEND WITH HELP: SHOW JOBS
 | show_queries_stmt      // EXTEND WITH HELP: SHOW QUERIES
+| show_ranges_stmt       // EXTEND WITH HELP: SHOW RANGES
+| show_schedules_stmt    // EXTEND WITH HELP: SHOW SCHEDULES
 | show_session_stmt      // EXTEND WITH HELP: SHOW SESSION
 | show_sessions_stmt     // EXTEND WITH HELP: SHOW SESSIONS
+| show_syntax_stmt       // EXTEND WITH HELP: SHOW SYNTAX
 | show_tables_stmt       // EXTEND WITH HELP: SHOW TABLES
 | show_testing_stmt
 | show_trace_stmt        // EXTEND WITH HELP: SHOW TRACE
 | show_transaction_stmt  // EXTEND WITH HELP: SHOW TRANSACTION
 | show_users_stmt        // EXTEND WITH HELP: SHOW USERS
-| show_zone_stmt
+| show_zone_stmt         // EXTEND WITH HELP: SHOW ZONE
 | SHOW error             // SHOW HELP: SHOW
 
 // %Help: SHOW SESSION - display session variables
@@ -2494,17 +3417,49 @@ show_databases_stmt:
   }
 | SHOW DATABASES error // SHOW HELP: SHOW DATABASES
 
+// %Help: SHOW DUMP - dump the schema and data of a database
+// %Category: Misc
+// %Text: SHOW DUMP [FROM <database>] [AS OF SYSTEM TIME <expr>]
+//
+// The output is a list of SQL statements which recreate the tables, views and
+// sequences of the database, followed by their data in COPY format.
+// %SeeAlso: SHOW CREATE TABLE
+show_dump_stmt:
+  SHOW DUMP opt_as_of_clause
+  {
+    $$.val = &tree.ShowDump{AsOf: $3.asOfClause()}
+  }
+| SHOW DUMP FROM name opt_as_of_clause
+  {
+    $$.val = &tree.ShowDump{Database: tree.Name($4), AsOf: $5.asOfClause()}
+  }
+| SHOW DUMP error // SHOW HELP: SHOW DUMP
+
 // %Help: SHOW GRANTS - list grants
 // %Category: Priv
-// %Text: SHOW GRANTS [ON <targets...>] [FOR <users...>]
+// %Text: SHOW GRANTS [ON <targets...>] [FOR <users...>] [WITH PATH]
+//
+// The privileges inherited from roles, from the public role and from the
+// ownership of the objects are listed along with the direct grants. WITH
+// PATH shows how each privilege is obtained.
 // %SeeAlso: WEBDOCS/show-grants.html
 show_grants_stmt:
-  SHOW GRANTS on_privilege_target_clause for_grantee_clause
+  SHOW GRANTS on_privilege_target_clause for_grantee_clause opt_with_path
   {
-    $$.val = &tree.ShowGrants{Targets: $3.targetListPtr(), Grantees: $4.nameList()}
+    $$.val = &tree.ShowGrants{Targets: $3.targetListPtr(), Grantees: $4.nameList(), WithPath: $5.bool()}
   }
 | SHOW GRANTS error // SHOW HELP: SHOW GRANTS
 
+opt_with_path:
+  WITH PATH
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
 // %Help: SHOW INDEXES - list indexes
 // %Category: DDL
 // %Text: SHOW INDEXES FROM <tablename>
@@ -2572,6 +3527,17 @@ show_jobs_stmt:
   }
 | SHOW JOBS error // SHOW HELP: SHOW JOBS
 
+// %Help: SHOW SCHEDULES - list the schedules of periodic jobs
+// %Category: Misc
+// %Text: SHOW SCHEDULES
+// %SeeAlso: CREATE SCHEDULE FOR BACKUP, SHOW JOBS
+show_schedules_stmt:
+  SHOW SCHEDULES
+  {
+    $$.val = &tree.ShowSchedules{}
+  }
+| SHOW SCHEDULES error // SHOW HELP: SHOW SCHEDULES
+
 // %Help: SHOW TRACE - display an execution trace
 // %Category: Misc
 // %Text:
@@ -2620,6 +3586,20 @@ show_sessions_stmt:
     $$.val = &tree.ShowSessions{Cluster: false}
   }
 
+// %Help: SHOW SYNTAX - analyze SQL syntax
+// %Category: Misc
+// %Text: SHOW SYNTAX <string>
+//
+// The string is parsed, and either the statements it contains are shown
+// in their canonical form, or the syntax error is described, with its
+// position in the string and a hint if one is available.
+show_syntax_stmt:
+  SHOW SYNTAX SCONST
+  {
+    $$.val = &tree.ShowSyntax{Statement: $3}
+  }
+| SHOW SYNTAX error // SHOW HELP: SHOW SYNTAX
+
 // %Help: SHOW TABLES - list tables
 // %Category: DDL
 // %Text: SHOW TABLES [FROM <databasename>]
@@ -2679,6 +3659,17 @@ show_create_view_stmt:
   }
 | SHOW CREATE VIEW error // SHOW HELP: SHOW CREATE VIEW
 
+// %Help: SHOW CREATE SEQUENCE - display the CREATE SEQUENCE statement for a sequence
+// %Category: DDL
+// %Text: SHOW CREATE SEQUENCE <seqname>
+// %SeeAlso: CREATE SEQUENCE, SHOW CREATE TABLE
+show_create_sequence_stmt:
+  SHOW CREATE SEQUENCE var_name
+  {
+    $$.val = &tree.ShowCreateSequence{Sequence: $4.normalizableTableName()}
+  }
+| SHOW CREATE SEQUENCE error // SHOW HELP: SHOW CREATE SEQUENCE
+
 // %Help: SHOW USERS - list defined users
 // %Category: Priv
 // %Text: SHOW USERS
@@ -2690,44 +3681,84 @@ show_users_stmt:
   }
 | SHOW USERS error // SHOW HELP: SHOW USERS
 
+// %Help: SHOW ZONE - display zone configurations
+// %Category: Cfg
+// %Text:
+// SHOW ZONE CONFIGURATION FOR RANGE <zonename>
+// SHOW ZONE CONFIGURATION FOR DATABASE <dbname>
+// SHOW ZONE CONFIGURATION FOR TABLE <tablename> [PARTITION <partition>]
+// SHOW ZONE CONFIGURATION FOR INDEX <indexname> [PARTITION <partition>]
+// SHOW [ALL] ZONE CONFIGURATIONS
+// %SeeAlso: ALTER TABLE, ALTER INDEX, ALTER DATABASE
 show_zone_stmt:
-  EXPERIMENTAL SHOW ZONE CONFIGURATION FOR RANGE unrestricted_name
+  show_zone_config
+| EXPERIMENTAL show_zone_config
   {
     /* SKIP DOC */
-    $$.val = &tree.ShowZoneConfig{ZoneSpecifier: tree.ZoneSpecifier{NamedZone: tree.UnrestrictedName($7)}}
+    $$.val = $2.stmt()
+  }
+| SHOW ZONE error // SHOW HELP: SHOW ZONE
+
+show_zone_config:
+  SHOW ZONE CONFIGURATION FOR RANGE unrestricted_name
+  {
+    $$.val = &tree.ShowZoneConfig{ZoneSpecifier: tree.ZoneSpecifier{NamedZone: tree.UnrestrictedName($6)}}
   }
-| EXPERIMENTAL SHOW ZONE CONFIGURATION FOR DATABASE name
+| SHOW ZONE CONFIGURATION FOR DATABASE name
   {
-    /* SKIP DOC */
-    $$.val = &tree.ShowZoneConfig{ZoneSpecifier: tree.ZoneSpecifier{Database: tree.Name($7)}}
+    $$.val = &tree.ShowZoneConfig{ZoneSpecifier: tree.ZoneSpecifier{Database: tree.Name($6)}}
   }
-| EXPERIMENTAL SHOW ZONE CONFIGURATION FOR TABLE qualified_name opt_partition
+| SHOW ZONE CONFIGURATION FOR TABLE qualified_name opt_partition
   {
-    /* SKIP DOC */
     $$.val = &tree.ShowZoneConfig{ZoneSpecifier: tree.ZoneSpecifier{
-      TableOrIndex: tree.TableNameWithIndex{Table: $7.normalizableTableName()},
-      Partition: tree.Name($8),
+      TableOrIndex: tree.TableNameWithIndex{Table: $6.normalizableTableName()},
+      Partition: tree.Name($7),
     }}
   }
-| EXPERIMENTAL SHOW ZONE CONFIGURATION FOR INDEX table_name_with_index opt_partition
+| SHOW ZONE CONFIGURATION FOR INDEX table_name_with_index opt_partition
   {
-    /* SKIP DOC */
     $$.val = &tree.ShowZoneConfig{ZoneSpecifier: tree.ZoneSpecifier{
-      TableOrIndex: $7.tableWithIdx(),
-      Partition: tree.Name($8),
+      TableOrIndex: $6.tableWithIdx(),
+      Partition: tree.Name($7),
     }}
   }
-| EXPERIMENTAL SHOW ZONE CONFIGURATIONS
+| SHOW ZONE CONFIGURATIONS
   {
-    /* SKIP DOC */
     $$.val = &tree.ShowZoneConfig{}
   }
-| EXPERIMENTAL SHOW ALL ZONE CONFIGURATIONS
+| SHOW ALL ZONE CONFIGURATIONS
   {
-    /* SKIP DOC */
     $$.val = &tree.ShowZoneConfig{}
   }
 
+// %Help: SHOW RANGES - list the ranges of a table or index
+// %Category: Misc
+// %Text:
+// SHOW RANGES FROM TABLE <tablename>
+// SHOW RANGES FROM INDEX <tablename>@<indexname>
+// SHOW RANGE FROM TABLE <tablename> FOR ROW ( <exprs...> )
+// SHOW RANGE FROM INDEX <tablename>@<indexname> FOR ROW ( <exprs...> )
+// %SeeAlso: ALTER TABLE, ALTER INDEX
+show_ranges_stmt:
+  SHOW RANGES FROM TABLE qualified_name
+  {
+    $$.val = &tree.ShowRanges{Table: $5.newNormalizableTableName()}
+  }
+| SHOW RANGES FROM INDEX table_name_with_index
+  {
+    $$.val = &tree.ShowRanges{Index: $5.newTableWithIdx()}
+  }
+| SHOW RANGE FROM TABLE qualified_name FOR ROW '(' expr_list ')'
+  {
+    $$.val = &tree.ShowRangeForRow{Table: $5.newNormalizableTableName(), Row: $9.exprs()}
+  }
+| SHOW RANGE FROM INDEX table_name_with_index FOR ROW '(' expr_list ')'
+  {
+    $$.val = &tree.ShowRangeForRow{Index: $5.newTableWithIdx(), Row: $9.exprs()}
+  }
+| SHOW RANGES error // SHOW HELP: SHOW RANGES
+| SHOW RANGE error // SHOW HELP: SHOW RANGES
+
 show_testing_stmt:
   SHOW TESTING_RANGES FROM TABLE qualified_name
   {
@@ -2780,7 +3811,7 @@ pause_stmt:
 // %Help: CREATE TABLE - create a new table
 // %Category: DDL
 // %Text:
-// CREATE TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>]
+// CREATE TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>] [<locality>]
 // CREATE TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] AS <source>
 //
 // Table elements:
@@ -2805,11 +3836,14 @@ pause_stmt:
 // Interleave clause:
 //    INTERLEAVE IN PARENT <tablename> ( <colnames...> ) [CASCADE | RESTRICT]
 //
+// Locality clause:
+//    LOCALITY {GLOBAL | REGIONAL BY TABLE | REGIONAL BY ROW}
+//
 // %SeeAlso: SHOW TABLES, CREATE VIEW, SHOW CREATE TABLE,
 // WEBDOCS/create-table.html
 // WEBDOCS/create-table-as.html
 create_table_stmt:
-  CREATE TABLE any_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by
+  CREATE TABLE any_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by opt_locality
   {
     $$.val = &tree.CreateTable{
       Table: $3.normalizableTableName(),
@@ -2819,9 +3853,10 @@ create_table_stmt:
       AsSource: nil,
       AsColumnNames: nil,
       PartitionBy: $8.partitionBy(),
+      Locality: $9.optLocality(),
     }
   }
-| CREATE TABLE IF NOT EXISTS any_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by
+| CREATE TABLE IF NOT EXISTS any_name '(' opt_table_elem_list ')' opt_interleave opt_partition_by opt_locality
   {
     $$.val = &tree.CreateTable{
       Table: $6.normalizableTableName(),
@@ -2831,6 +3866,7 @@ create_table_stmt:
       AsSource: nil,
       AsColumnNames: nil,
       PartitionBy: $11.partitionBy(),
+      Locality: $12.optLocality(),
     }
   }
 
@@ -3345,21 +4381,44 @@ truncate_stmt:
 
 // %Help: CREATE USER - define a new user
 // %Category: Priv
-// %Text: CREATE USER [IF NOT EXISTS] <name> [ [WITH] PASSWORD <passwd> ]
+// %Text:
+// CREATE USER [IF NOT EXISTS] <name> [WITH] [PASSWORD <passwd>] [CONNECTION LIMIT <limit>]
+//   [VALID UNTIL <timestamp>]
 // %SeeAlso: DROP USER, SHOW USERS, WEBDOCS/create-user.html
 create_user_stmt:
-  CREATE USER string_or_placeholder opt_password
+  CREATE USER string_or_placeholder opt_with opt_password opt_connection_limit opt_valid_until
   {
-    $$.val = &tree.CreateUser{Name: $3.expr(), Password: $4.expr()}
+    $$.val = &tree.CreateUser{Name: $3.expr(), Password: $5.expr(), ConnectionLimit: $6.int64Ptr(), ValidUntil: $7.expr()}
   }
-| CREATE USER IF NOT EXISTS string_or_placeholder opt_password
+| CREATE USER IF NOT EXISTS string_or_placeholder opt_with opt_password opt_connection_limit opt_valid_until
   {
-    $$.val = &tree.CreateUser{Name: $6.expr(), Password: $7.expr(), IfNotExists: true}
+    $$.val = &tree.CreateUser{Name: $6.expr(), Password: $8.expr(), ConnectionLimit: $9.int64Ptr(), ValidUntil: $10.expr(), IfNotExists: true}
   }
 | CREATE USER error // SHOW HELP: CREATE USER
 
 opt_password:
-  opt_with PASSWORD string_or_placeholder
+  PASSWORD string_or_placeholder
+  {
+    $$.val = $2.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = nil
+  }
+
+opt_connection_limit:
+  CONNECTION LIMIT signed_iconst64
+  {
+    limit := $3.int64()
+    $$.val = &limit
+  }
+| /* EMPTY */
+  {
+    $$.val = (*int64)(nil)
+  }
+
+opt_valid_until:
+  VALID UNTIL string_or_placeholder
   {
     $$.val = $3.expr()
   }
@@ -3368,21 +4427,183 @@ opt_password:
     $$.val = nil
   }
 
+// %Help: CREATE POLICY - define a row-level security policy
+// %Category: DDL
+// %Text:
+// CREATE POLICY <name> ON <tablename>
+//    [FOR { ALL | SELECT | INSERT | UPDATE | DELETE }]
+//    [TO <user_or_role> [, ...]]
+//    [USING ( <expr> )]
+//    [WITH CHECK ( <expr> )]
+//
+// The policies of a table only take effect once row-level security is
+// enabled on it with ALTER TABLE ... ENABLE ROW LEVEL SECURITY.
+// %SeeAlso: DROP POLICY, ALTER TABLE
+create_policy_stmt:
+  CREATE POLICY name ON qualified_name opt_policy_command opt_policy_roles opt_policy_using opt_policy_with_check
+  {
+    $$.val = &tree.CreatePolicy{
+      Name: tree.Name($3),
+      Table: $5.normalizableTableName(),
+      Command: $6,
+      Roles: $7.nameList(),
+      Using: $8.expr(),
+      WithCheck: $9.expr(),
+    }
+  }
+| CREATE POLICY error // SHOW HELP: CREATE POLICY
+
+opt_policy_command:
+  FOR policy_command
+  {
+    $$ = $2
+  }
+| /* EMPTY */
+  {
+    $$ = ""
+  }
+
+policy_command:
+  ALL
+  {
+    $$ = "ALL"
+  }
+| SELECT
+  {
+    $$ = "SELECT"
+  }
+| INSERT
+  {
+    $$ = "INSERT"
+  }
+| UPDATE
+  {
+    $$ = "UPDATE"
+  }
+| DELETE
+  {
+    $$ = "DELETE"
+  }
+
+opt_policy_roles:
+  TO name_list
+  {
+    $$.val = $2.nameList()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.NameList(nil)
+  }
+
+opt_policy_using:
+  USING '(' a_expr ')'
+  {
+    $$.val = $3.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.Expr(nil)
+  }
+
+opt_policy_with_check:
+  WITH CHECK '(' a_expr ')'
+  {
+    $$.val = $4.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.Expr(nil)
+  }
+
+// %Help: CREATE ROLE - define a new role
+// %Category: Priv
+// %Text:
+// CREATE ROLE [IF NOT EXISTS] <name> [WITH] [<option>...]
+//
+// Options:
+//   LOGIN, NOLOGIN (default), INHERIT (default), NOINHERIT
+// %SeeAlso: DROP ROLE, GRANT, CREATE USER
+create_role_stmt:
+  CREATE ROLE string_or_placeholder opt_with opt_role_options
+  {
+    $$.val = &tree.CreateUser{Name: $3.expr(), IsRole: true, RoleOptions: $5.roleOptions()}
+  }
+| CREATE ROLE IF NOT EXISTS string_or_placeholder opt_with opt_role_options
+  {
+    $$.val = &tree.CreateUser{Name: $6.expr(), IsRole: true, RoleOptions: $8.roleOptions(), IfNotExists: true}
+  }
+| CREATE ROLE error // SHOW HELP: CREATE ROLE
+
+opt_role_options:
+  role_options
+| /* EMPTY */
+  {
+    $$.val = []tree.RoleOption(nil)
+  }
+
+role_options:
+  role_option
+  {
+    $$.val = []tree.RoleOption{$1.roleOption()}
+  }
+| role_options role_option
+  {
+    $$.val = append($1.roleOptions(), $2.roleOption())
+  }
+
+role_option:
+  LOGIN
+  {
+    $$.val = tree.RoleOptionLogin
+  }
+| NOLOGIN
+  {
+    $$.val = tree.RoleOptionNoLogin
+  }
+| INHERIT
+  {
+    $$.val = tree.RoleOptionInherit
+  }
+| NOINHERIT
+  {
+    $$.val = tree.RoleOptionNoInherit
+  }
+
 // %Help: CREATE VIEW - create a new view
 // %Category: DDL
-// %Text: CREATE VIEW <viewname> [( <colnames...> )] AS <source>
+// %Text:
+// CREATE VIEW <viewname> [( <colnames...> )] [SECURITY {DEFINER | INVOKER}] AS <source>
+//
+// The source runs with the privileges of the owner of the view
+// (SECURITY DEFINER, the default), or with the privileges of the user
+// selecting from the view (SECURITY INVOKER).
 // %SeeAlso: CREATE TABLE, SHOW CREATE VIEW, WEBDOCS/create-view.html
 create_view_stmt:
-  CREATE VIEW any_name opt_column_list AS select_stmt
+  CREATE VIEW any_name opt_column_list opt_view_security AS select_stmt
   {
     $$.val = &tree.CreateView{
       Name: $3.normalizableTableName(),
       ColumnNames: $4.nameList(),
-      AsSource: $6.slct(),
+      SecurityInvoker: $5.bool(),
+      AsSource: $7.slct(),
     }
   }
 | CREATE VIEW error // SHOW HELP: CREATE VIEW
 
+opt_view_security:
+  SECURITY DEFINER
+  {
+    $$.val = false
+  }
+| SECURITY INVOKER
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
 // TODO(a-robinson): CREATE OR REPLACE VIEW support (#2971).
 
 // %Help: CREATE INDEX - create a new index
@@ -3480,6 +4701,22 @@ alter_rename_database_stmt:
     $$.val = &tree.RenameDatabase{Name: tree.Name($3), NewName: tree.Name($6)}
   }
 
+alter_owner_database_stmt:
+  ALTER DATABASE name OWNER TO name
+  {
+    $$.val = &tree.AlterDatabaseOwner{Name: tree.Name($3), Owner: tree.Name($6)}
+  }
+
+alter_region_database_stmt:
+  ALTER DATABASE name SET PRIMARY REGION name
+  {
+    $$.val = &tree.AlterDatabaseSetPrimaryRegion{Name: tree.Name($3), Region: tree.Name($7)}
+  }
+| ALTER DATABASE name ADD REGION name
+  {
+    $$.val = &tree.AlterDatabaseAddRegion{Name: tree.Name($3), Region: tree.Name($6)}
+  }
+
 // https://www.postgresql.org/docs/10/static/sql-alteruser.html
 alter_user_password_stmt:
   ALTER USER string_or_placeholder WITH PASSWORD string_or_placeholder
@@ -3491,6 +4728,78 @@ alter_user_password_stmt:
     $$.val = &tree.AlterUserSetPassword{Name: $5.expr(), Password: $8.expr(), IfExists: true}
   }
 
+alter_user_connection_limit_stmt:
+  ALTER USER string_or_placeholder opt_with CONNECTION LIMIT signed_iconst64
+  {
+    $$.val = &tree.AlterUserSetConnectionLimit{Name: $3.expr(), ConnectionLimit: $7.int64()}
+  }
+| ALTER USER IF EXISTS string_or_placeholder opt_with CONNECTION LIMIT signed_iconst64
+  {
+    $$.val = &tree.AlterUserSetConnectionLimit{Name: $5.expr(), ConnectionLimit: $9.int64(), IfExists: true}
+  }
+
+alter_user_valid_until_stmt:
+  ALTER USER string_or_placeholder opt_with VALID UNTIL string_or_placeholder
+  {
+    $$.val = &tree.AlterUserSetValidUntil{Name: $3.expr(), ValidUntil: $7.expr()}
+  }
+| ALTER USER IF EXISTS string_or_placeholder opt_with VALID UNTIL string_or_placeholder
+  {
+    $$.val = &tree.AlterUserSetValidUntil{Name: $5.expr(), ValidUntil: $9.expr(), IfExists: true}
+  }
+
+alter_user_statements_stmt:
+  ALTER USER string_or_placeholder opt_with ALLOW STATEMENTS statement_class_list
+  {
+    $$.val = &tree.AlterUserSetStatements{Name: $3.expr(), Classes: $7.nameList()}
+  }
+| ALTER USER string_or_placeholder opt_with DENY STATEMENTS statement_class_list
+  {
+    $$.val = &tree.AlterUserSetStatements{Name: $3.expr(), Deny: true, Classes: $7.nameList()}
+  }
+| ALTER USER string_or_placeholder opt_with ALLOW ALL STATEMENTS
+  {
+    $$.val = &tree.AlterUserSetStatements{Name: $3.expr()}
+  }
+| ALTER USER IF EXISTS string_or_placeholder opt_with ALLOW STATEMENTS statement_class_list
+  {
+    $$.val = &tree.AlterUserSetStatements{Name: $5.expr(), Classes: $9.nameList(), IfExists: true}
+  }
+| ALTER USER IF EXISTS string_or_placeholder opt_with DENY STATEMENTS statement_class_list
+  {
+    $$.val = &tree.AlterUserSetStatements{Name: $5.expr(), Deny: true, Classes: $9.nameList(), IfExists: true}
+  }
+| ALTER USER IF EXISTS string_or_placeholder opt_with ALLOW ALL STATEMENTS
+  {
+    $$.val = &tree.AlterUserSetStatements{Name: $5.expr(), IfExists: true}
+  }
+
+alter_user_quota_stmt:
+  ALTER USER string_or_placeholder opt_with QUOTA name '=' signed_iconst64
+  {
+    $$.val = &tree.AlterUserSetQuota{Name: $3.expr(), Quota: tree.Name($6), Limit: $8.int64()}
+  }
+| ALTER USER IF EXISTS string_or_placeholder opt_with QUOTA name '=' signed_iconst64
+  {
+    $$.val = &tree.AlterUserSetQuota{Name: $5.expr(), Quota: tree.Name($8), Limit: $10.int64(), IfExists: true}
+  }
+
+statement_class_list:
+  statement_class
+  {
+    $$.val = tree.NameList{tree.Name($1)}
+  }
+| statement_class_list ',' statement_class
+  {
+    $$.val = append($1.nameList(), tree.Name($3))
+  }
+
+// SELECT is a reserved keyword but also names the class of the statements
+// reading data.
+statement_class:
+  name
+| SELECT
+
 alter_rename_table_stmt:
   ALTER TABLE relation_expr RENAME TO qualified_name
   {
@@ -3533,6 +4842,42 @@ alter_rename_sequence_stmt:
     $$.val = &tree.RenameTable{Name: $5.normalizableTableName(), NewName: $8.normalizableTableName(), IfExists: true, IsSequence: true}
   }
 
+alter_owner_table_stmt:
+  ALTER TABLE relation_expr OWNER TO name
+  {
+    $$.val = &tree.AlterTableOwner{Name: $3.normalizableTableName(), Owner: tree.Name($6), IfExists: false}
+  }
+| ALTER TABLE IF EXISTS relation_expr OWNER TO name
+  {
+    $$.val = &tree.AlterTableOwner{Name: $5.normalizableTableName(), Owner: tree.Name($8), IfExists: true}
+  }
+
+alter_rotate_key_stmt:
+  ALTER TABLE qualified_name ROTATE ENCRYPTION KEY '(' name_list ')'
+  {
+    $$.val = &tree.RotateEncryptionKey{Table: $3.normalizableTableName(), Columns: $8.nameList()}
+  }
+
+alter_owner_view_stmt:
+  ALTER VIEW relation_expr OWNER TO name
+  {
+    $$.val = &tree.AlterTableOwner{Name: $3.normalizableTableName(), Owner: tree.Name($6), IfExists: false, IsView: true}
+  }
+| ALTER VIEW IF EXISTS relation_expr OWNER TO name
+  {
+    $$.val = &tree.AlterTableOwner{Name: $5.normalizableTableName(), Owner: tree.Name($8), IfExists: true, IsView: true}
+  }
+
+alter_owner_sequence_stmt:
+  ALTER SEQUENCE relation_expr OWNER TO name
+  {
+    $$.val = &tree.AlterTableOwner{Name: $3.normalizableTableName(), Owner: tree.Name($6), IfExists: false, IsSequence: true}
+  }
+| ALTER SEQUENCE IF EXISTS relation_expr OWNER TO name
+  {
+    $$.val = &tree.AlterTableOwner{Name: $5.normalizableTableName(), Owner: tree.Name($8), IfExists: true, IsSequence: true}
+  }
+
 alter_rename_index_stmt:
   ALTER INDEX table_name_with_index RENAME TO unrestricted_name
   {
@@ -6809,23 +8154,32 @@ unrestricted_name:
 // "Unreserved" keywords --- available for use as any kind of name.
 unreserved_keyword:
   ABORT
+| ABSOLUTE
 | ACTION
 | ADD
+| ADMIN
+| AFTER
+| ALLOW
 | ALTER
+| ALWAYS
 | AT
 | BACKUP
+| BACKWARD
 | BEGIN
 | BLOB
 | BY
 | CACHE
 | CANCEL
 | CASCADE
+| CHANGEFEED
+| CLOSE
 | CLUSTER
 | COLUMNS
 | COMMIT
 | COMMITTED
 | COMPACT
 | CONFLICT
+| CONNECTION
 | CONFIGURATION
 | CONFIGURATIONS
 | CONFIGURE
@@ -6835,106 +8189,154 @@ unreserved_keyword:
 | CSV
 | CUBE
 | CURRENT
+| CURSOR
 | CYCLE
 | DATA
 | DATABASE
 | DATABASES
 | DAY
 | DEALLOCATE
+| DECLARE
+| DEFINER
 | DELETE
+| DENY
+| DISABLE
 | DISCARD
 | DOUBLE
 | DROP
+| DUMP
+| ENABLE
 | ENCODING
+| ENCRYPTION
 | EXECUTE
 | EXPERIMENTAL
+| EXPERIMENTAL_AUDIT
 | EXPERIMENTAL_FINGERPRINTS
+| EXPIRE
 | EXPLAIN
+| EXPORT
 | FILTER
 | FIRST
 | FOLLOWING
 | FORCE_INDEX
+| FORWARD
+| GLOBAL
 | GRANTS
 | HIGH
+| HOLD
 | HOUR
 | IMPORT
 | INCREMENT
 | INCREMENTAL
 | INDEXES
+| INHERIT
+| INJECT
 | INSERT
 | INT2VECTOR
 | INTERLEAVE
+| INVOKER
 | ISOLATION
 | JOB
 | JOBS
 | KEY
 | KEYS
 | KV
+| LAST
 | LC_COLLATE
 | LC_CTYPE
 | LESS
 | LEVEL
 | LIST
+| LISTEN
 | LOCAL
+| LOCALITY
+| LOGIN
 | LOW
 | MATCH
 | MINUTE
 | MINVALUE
 | MONTH
+| MOVE
+| MYSQLDUMP
 | NAMES
 | NAN
 | NEXT
 | NO
+| NOINHERIT
+| NOLOGIN
 | NORMAL
+| NOTIFY
 | NO_INDEX_JOIN
 | NULLS
 | OF
 | OFF
 | OID
+| OPTION
 | OPTIONS
 | ORDINALITY
 | OVER
 | OWNED
+| OWNER
 | PARENT
 | PARTIAL
 | PARTITION
 | PASSWORD
+| PATH
 | PAUSE
+| PGDUMP
 | PHYSICAL
 | PLANS
+| POLICY
 | PRECEDING
 | PREPARE
+| PRIOR
 | PRIORITY
+| PRIVILEGES
 | QUERIES
 | QUERY
+| QUOTA
 | RANGE
+| RANGES
 | READ
+| REASSIGN
+| RECURRING
 | RECURSIVE
 | REF
 | REGCLASS
+| REGION
+| REGIONAL
 | REGPROC
 | REGPROCEDURE
 | REGNAMESPACE
 | REGTYPE
+| RELATIVE
 | RELEASE
 | RENAME
+| REPAIR
 | REPEATABLE
 | RESET
 | RESTORE
 | RESTRICT
 | RESUME
 | REVOKE
+| ROLE
 | ROLLBACK
 | ROLLUP
+| ROTATE
 | ROWS
+| SCROLL
 | SETTING
 | SETTINGS
 | STATUS
 | SAVEPOINT
 | SCATTER
+| SCHEDULE
+| SCHEDULES
+| SCHEMA
 | SCRUB
 | SEARCH
 | SECOND
+| SECURITY
 | SERIALIZABLE
 | SEQUENCE
 | SEQUENCES
@@ -6946,11 +8348,15 @@ unreserved_keyword:
 | SNAPSHOT
 | SQL
 | START
+| STATEMENTS
+| STATISTICS
 | STDIN
+| STDOUT
 | STORE
 | STORING
 | STRICT
 | SPLIT
+| SYNTAX
 | SYSTEM
 | TABLES
 | TEMP
@@ -6963,10 +8369,13 @@ unreserved_keyword:
 | TRACE
 | TRANSACTION
 | TRUNCATE
+| TTL
 | TYPE
 | UNBOUNDED
 | UNCOMMITTED
 | UNKNOWN
+| UNLISTEN
+| UNTIL
 | UPDATE
 | UPSERT
 | USE
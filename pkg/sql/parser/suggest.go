@@ -0,0 +1,101 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+)
+
+// maxKeywordSuggestions is the maximum number of keywords suggested for a
+// misspelled identifier.
+const maxKeywordSuggestions = 3
+
+// suggestKeywords returns a hint proposing the keywords closest to the given
+// identifier, e.g. `did you mean SELECT?` for "selet", or the empty string if
+// no keyword is close enough for the identifier to be a misspelling of it.
+func suggestKeywords(ident string) string {
+	// Short identifiers are close to too many keywords for the suggestions to
+	// be useful.
+	if len(ident) < 4 {
+		return ""
+	}
+	maxDist := 1
+	if len(ident) >= 8 {
+		maxDist = 2
+	}
+	var suggestions []string
+	for kw := range lex.Keywords {
+		d := editDistance(ident, kw)
+		if d == 0 || d > maxDist {
+			continue
+		}
+		if d < maxDist {
+			// Only keep the closest keywords.
+			maxDist = d
+			suggestions = suggestions[:0]
+		}
+		suggestions = append(suggestions, strings.ToUpper(kw))
+	}
+	if len(suggestions) == 0 {
+		return ""
+	}
+	sort.Strings(suggestions)
+	if len(suggestions) > maxKeywordSuggestions {
+		suggestions = suggestions[:maxKeywordSuggestions]
+	}
+	return "did you mean " + strings.Join(suggestions, " or ") + "?"
+}
+
+// editDistance returns the number of insertions, deletions, substitutions
+// and transpositions of adjacent characters needed to turn a into b.
+func editDistance(a, b string) int {
+	// d[i][j] is the distance between a[:i] and b[:j].
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				if t := d[i-2][j-2] + 1; t < d[i][j] {
+					d[i][j] = t
+				}
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,246 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// minPasswordLength is the minimum number of characters of new passwords.
+var minPasswordLength = settings.RegisterValidatedIntSetting(
+	"server.user_login.min_password_length",
+	"the minimum number of characters of new passwords",
+	1,
+	func(v int64) error {
+		if v < 1 {
+			return errors.Errorf("cannot set server.user_login.min_password_length to a value < 1: %d", v)
+		}
+		return nil
+	},
+)
+
+// minPasswordCharClasses is the minimum number of character classes, among
+// lowercase letters, uppercase letters, digits and other characters, that
+// new passwords contain.
+var minPasswordCharClasses = settings.RegisterValidatedIntSetting(
+	"server.user_login.min_password_character_classes",
+	"the minimum number of character classes (lowercase letters, uppercase letters, "+
+		"digits, other characters) of new passwords",
+	1,
+	func(v int64) error {
+		if v < 1 || v > 4 {
+			return errors.Errorf(
+				"server.user_login.min_password_character_classes must be between 1 and 4: %d", v)
+		}
+		return nil
+	},
+)
+
+// bcryptCost is the cost of the bcrypt hashes of new passwords. Raising it
+// makes brute-forcing stolen hashes more expensive, as well as every
+// password authentication.
+var bcryptCost = settings.RegisterValidatedIntSetting(
+	"server.user_login.bcrypt_cost",
+	"the cost of the bcrypt hashes of new passwords; the passwords hashed with a lower "+
+		"cost are rehashed when their users next log in",
+	int64(security.DefaultBcryptCost),
+	func(v int64) error {
+		if v < int64(security.MinBcryptCost) || v > int64(security.MaxBcryptCost) {
+			return errors.Errorf("server.user_login.bcrypt_cost must be between %d and %d: %d",
+				security.MinBcryptCost, security.MaxBcryptCost, v)
+		}
+		return nil
+	},
+)
+
+// roleOptionValidUntil is the system.role_options option holding the time
+// set with VALID UNTIL, after which the password of the user no longer
+// authenticates it.
+const roleOptionValidUntil = "VALID UNTIL"
+
+// validatePassword checks that a new password satisfies the complexity
+// requirements of the cluster settings.
+func validatePassword(st *cluster.Settings, password string) error {
+	if min := minPasswordLength.Get(&st.SV); int64(len([]rune(password))) < min {
+		return pgerror.NewErrorf(pgerror.CodeInvalidPasswordError,
+			"password must be at least %d characters long", min)
+	}
+	var hasLower, hasUpper, hasDigit, hasOther bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasOther = true
+		}
+	}
+	var classes int64
+	for _, has := range []bool{hasLower, hasUpper, hasDigit, hasOther} {
+		if has {
+			classes++
+		}
+	}
+	if min := minPasswordCharClasses.Get(&st.SV); classes < min {
+		return pgerror.NewErrorf(pgerror.CodeInvalidPasswordError,
+			"password must contain characters of at least %d of the classes lowercase letters, "+
+				"uppercase letters, digits and other characters", min)
+	}
+	return nil
+}
+
+// hashPassword hashes a new password. Passwords are only hashed into SCRAM
+// verifiers once every node of the cluster can check them.
+func hashPassword(st *cluster.Settings, password string) ([]byte, error) {
+	if useSCRAMPasswords(st) {
+		return security.HashPasswordSCRAM(password)
+	}
+	return security.HashPasswordWithCost(password, int(bcryptCost.Get(&st.SV)))
+}
+
+func useSCRAMPasswords(st *cluster.Settings) bool {
+	return passwordEncryption.Get(&st.SV) == passwordEncryptionSCRAM &&
+		st.Version.IsMinSupported(cluster.VersionSCRAMPasswords)
+}
+
+// isWeakPasswordHash returns whether a stored password hash is weaker than
+// the hashes of new passwords: a bcrypt hash when new passwords are hashed
+// into SCRAM verifiers, or a bcrypt hash of a lower cost than
+// server.user_login.bcrypt_cost.
+func isWeakPasswordHash(st *cluster.Settings, hashedPassword []byte) bool {
+	if security.IsSCRAMVerifier(hashedPassword) {
+		return false
+	}
+	if useSCRAMPasswords(st) {
+		return true
+	}
+	cost, err := security.BcryptCost(hashedPassword)
+	return err == nil && int64(cost) < bcryptCost.Get(&st.SV)
+}
+
+// MaybeRehashUserPassword replaces the stored hash of the password of a user
+// who just authenticated with it, if the hash is weaker than the hashes of
+// new passwords. The hash is only replaced if it hasn't changed since the
+// authentication.
+func MaybeRehashUserPassword(
+	ctx context.Context,
+	executor *Executor,
+	metrics *MemoryMetrics,
+	username string,
+	password string,
+	hashedPassword []byte,
+) error {
+	st := executor.cfg.Settings
+	if !isWeakPasswordHash(st, hashedPassword) {
+		return nil
+	}
+	newHashedPassword, err := hashPassword(st, password)
+	if err != nil {
+		return err
+	}
+	normalizedUsername := tree.Name(username).Normalize()
+	return executor.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		p := makeInternalPlanner("rehash-pwd", txn, security.RootUser, metrics)
+		defer finishInternalPlanner(p)
+		_, err := p.exec(ctx,
+			`UPDATE system.users SET "hashedPassword" = $2 WHERE username = $1 AND "hashedPassword" = $3`,
+			normalizedUsername, newHashedPassword, hashedPassword)
+		return err
+	})
+}
+
+// GetUserPasswordValidUntil returns the time set with VALID UNTIL for the
+// given user, or nil if there is none.
+func GetUserPasswordValidUntil(
+	ctx context.Context, executor *Executor, metrics *MemoryMetrics, username string,
+) (*time.Time, error) {
+	normalizedUsername := tree.Name(username).Normalize()
+	if normalizedUsername == security.RootUser {
+		return nil, nil
+	}
+
+	var validUntil *time.Time
+	err := executor.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		validUntil = nil
+		p := makeInternalPlanner("get-valid-until", txn, security.RootUser, metrics)
+		defer finishInternalPlanner(p)
+		const getValidUntil = `SELECT value FROM system.role_options ` +
+			`WHERE username = $1 AND option = $2`
+		values, err := p.QueryRow(ctx, getValidUntil, normalizedUsername, roleOptionValidUntil)
+		if err != nil {
+			return errors.Errorf("error looking up user %s", normalizedUsername)
+		}
+		if len(values) == 0 || values[0] == tree.DNull {
+			return nil
+		}
+		t, err := parseStoredValidUntil(values[0])
+		if err != nil {
+			return errors.Wrapf(err, "invalid password expiration for user %s", normalizedUsername)
+		}
+		validUntil = &t
+		return nil
+	})
+	return validUntil, err
+}
+
+// CheckPasswordValidUntil returns an error if the password of the user
+// expired before now.
+func CheckPasswordValidUntil(username string, validUntil *time.Time) error {
+	if validUntil != nil && !timeutil.Now().Before(*validUntil) {
+		return errors.Errorf("password of user %s has expired", username)
+	}
+	return nil
+}
+
+// parseValidUntil parses the time given to VALID UNTIL into the value stored
+// in system.role_options.
+func parseValidUntil(evalCtx *tree.EvalContext, s string) (string, error) {
+	ts, err := tree.ParseDTimestampTZ(s, evalCtx.GetLocation(), time.Microsecond)
+	if err != nil {
+		return "", err
+	}
+	return ts.Time.UTC().Format(time.RFC3339Nano), nil
+}
+
+// parseStoredValidUntil parses a value of the VALID UNTIL option stored in
+// system.role_options.
+func parseStoredValidUntil(d tree.Datum) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, string(tree.MustBeDString(d)))
+}
+
+// setValidUntil records the time set with VALID UNTIL for a user in
+// system.role_options.
+func setValidUntil(params runParams, normalizedUsername string, validUntil string) error {
+	value, err := parseValidUntil(params.evalCtx, validUntil)
+	if err != nil {
+		return err
+	}
+	return setRoleOption(params, normalizedUsername, roleOptionValidUntil, value)
+}
@@ -15,6 +15,7 @@
 package sql
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"hash"
@@ -22,6 +23,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/lib/pq/oid"
@@ -56,6 +58,7 @@ var pgCatalog = virtualSchema{
 		pgCatalogAmTable,
 		pgCatalogAttrDefTable,
 		pgCatalogAttributeTable,
+		pgCatalogAuthMembersTable,
 		pgCatalogClassTable,
 		pgCatalogCollationTable,
 		pgCatalogConstraintTable,
@@ -117,18 +120,16 @@ CREATE TABLE pg_catalog.pg_attrdef (
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
 		h := makeOidHasher()
 		return forEachTableDesc(ctx, p, prefix, func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
-			colNum := 0
 			return forEachColumnInTable(table, func(column *sqlbase.ColumnDescriptor) error {
-				colNum++
 				if column.DefaultExpr == nil {
 					// pg_attrdef only expects rows for columns with default values.
 					return nil
 				}
 				defSrc := tree.NewDString(*column.DefaultExpr)
 				return addRow(
-					h.ColumnOid(db, table, column),  // oid
-					h.TableOid(db, table),           // adrelid
-					tree.NewDInt(tree.DInt(colNum)), // adnum
+					h.ColumnOid(db, table, column),     // oid
+					h.TableOid(db, table),              // adrelid
+					tree.NewDInt(tree.DInt(column.ID)), // adnum
 					defSrc, // adbin
 					defSrc, // adsrc
 				)
@@ -195,12 +196,11 @@ CREATE TABLE pg_catalog.pg_attribute (
 				)
 			}
 
-			// Columns for table.
-			colNum := 0
+			// Columns for table. Their numbers are the column IDs, which are
+			// what pg_constraint.conkey and pg_index.indkey refer to.
 			if err := forEachColumnInTable(table, func(column *sqlbase.ColumnDescriptor) error {
-				colNum++
 				tableID := h.TableOid(db, table)
-				return addColumn(column, tableID, colNum)
+				return addColumn(column, tableID, int(column.ID))
 			}); err != nil {
 				return err
 			}
@@ -272,12 +272,13 @@ CREATE TABLE pg_catalog.pg_class (
 			} else if table.IsSequence() {
 				relKind = relKindSequence
 			}
+			owner := h.UserOid(table.Privileges.GetOwner())
 			if err := addRow(
 				h.TableOid(db, table),       // oid
 				tree.NewDName(table.Name),   // relname
 				pgNamespaceForDB(db, h).Oid, // relnamespace
 				oidZero,                     // reltype (PG creates a composite type in pg_type for each table)
-				tree.DNull,                  // relowner
+				owner,                       // relowner
 				tree.DNull,                  // relam
 				oidZero,                     // relfilenode
 				oidZero,                     // reltablespace
@@ -311,7 +312,7 @@ CREATE TABLE pg_catalog.pg_class (
 					tree.NewDName(index.Name),                       // relname
 					pgNamespaceForDB(db, h).Oid,                     // relnamespace
 					oidZero,                                         // reltype
-					tree.DNull,                                      // relowner
+					owner,                                           // relowner
 					tree.DNull,                                      // relam
 					oidZero,                                         // relfilenode
 					oidZero,                                         // reltablespace
@@ -393,12 +394,6 @@ var (
 	fkActionSetNull    = tree.NewDString("n")
 	fkActionSetDefault = tree.NewDString("d")
 
-	// Avoid unused warning for constants.
-	_ = fkActionRestrict
-	_ = fkActionCascade
-	_ = fkActionSetNull
-	_ = fkActionSetDefault
-
 	fkMatchTypeFull    = tree.NewDString("f")
 	fkMatchTypePartial = tree.NewDString("p")
 	fkMatchTypeSimple  = tree.NewDString("s")
@@ -408,7 +403,26 @@ var (
 	_ = fkMatchTypePartial
 )
 
+func dStringForFKAction(action sqlbase.ForeignKeyReference_Action) tree.Datum {
+	switch action {
+	case sqlbase.ForeignKeyReference_NO_ACTION:
+		return fkActionNone
+	case sqlbase.ForeignKeyReference_RESTRICT:
+		return fkActionRestrict
+	case sqlbase.ForeignKeyReference_SET_NULL:
+		return fkActionSetNull
+	case sqlbase.ForeignKeyReference_SET_DEFAULT:
+		return fkActionSetDefault
+	case sqlbase.ForeignKeyReference_CASCADE:
+		return fkActionCascade
+	}
+	panic(errors.Errorf("unexpected ForeignKeyReference_Action: %v", action))
+}
+
 // See: https://www.postgresql.org/docs/9.6/static/catalog-pg-constraint.html.
+//
+// Note that condef is an extension of the schema which holds the definition
+// of the constraint returned by pg_get_constraintdef.
 var pgCatalogConstraintTable = virtualSchemaTable{
 	schema: `
 CREATE TABLE pg_catalog.pg_constraint (
@@ -436,7 +450,8 @@ CREATE TABLE pg_catalog.pg_constraint (
 	conffeqop STRING,
 	conexclop STRING,
 	conbin STRING,
-	consrc STRING
+	consrc STRING,
+	condef STRING
 );
 `,
 	populate: func(ctx context.Context, p *planner, prefix string, addRow func(...tree.Datum) error) error {
@@ -451,7 +466,8 @@ CREATE TABLE pg_catalog.pg_constraint (
 				return err
 			}
 
-			for name, c := range info {
+			for _, name := range sortedConstraintNames(info) {
+				c := info[name]
 				oid := tree.DNull
 				contype := tree.DNull
 				conindid := oidZero
@@ -462,6 +478,7 @@ CREATE TABLE pg_catalog.pg_constraint (
 				conkey := tree.DNull
 				confkey := tree.DNull
 				consrc := tree.DNull
+				var condef bytes.Buffer
 
 				// Determine constraint kind-specific fields.
 				switch c.Kind {
@@ -475,6 +492,7 @@ CREATE TABLE pg_catalog.pg_constraint (
 					if err != nil {
 						return err
 					}
+					fmt.Fprintf(&condef, "PRIMARY KEY (%s)", quoteNames(c.Index.ColumnNames...))
 
 				case sqlbase.ConstraintTypeFK:
 					referencedDB, _ := tableLookup(c.ReferencedTable.ID)
@@ -486,18 +504,39 @@ CREATE TABLE pg_catalog.pg_constraint (
 					contype = conTypeFK
 					conindid = h.IndexOid(referencedDB, c.ReferencedTable, c.ReferencedIndex)
 					confrelid = h.TableOid(referencedDB, c.ReferencedTable)
-					confupdtype = fkActionNone
-					confdeltype = fkActionNone
+					confupdtype = dStringForFKAction(c.FK.OnUpdate)
+					confdeltype = dStringForFKAction(c.FK.OnDelete)
 					confmatchtype = fkMatchTypeSimple
+					// The foreign key only covers a prefix of the columns of its
+					// index and of the referenced index.
+					numCols := len(c.Columns)
 					var err error
-					conkey, err = colIDArrayToDatum(c.Index.ColumnIDs)
+					conkey, err = colIDArrayToDatum(c.Index.ColumnIDs[:numCols])
 					if err != nil {
 						return err
 					}
-					confkey, err = colIDArrayToDatum(c.ReferencedIndex.ColumnIDs)
+					confkey, err = colIDArrayToDatum(c.ReferencedIndex.ColumnIDs[:numCols])
 					if err != nil {
 						return err
 					}
+					fkTableName := tree.TableName{
+						DatabaseName:            tree.Name(referencedDB.Name),
+						TableName:               tree.Name(c.ReferencedTable.Name),
+						DBNameOriginallyOmitted: referencedDB.ID == db.ID,
+					}
+					fmt.Fprintf(&condef, "FOREIGN KEY (%s) REFERENCES %s(%s)",
+						quoteNames(c.Columns...),
+						&fkTableName,
+						quoteNames(c.ReferencedIndex.ColumnNames[:numCols]...),
+					)
+					// The actions are listed in the order of PostgreSQL. The values of
+					// ForeignKeyReference_Action match those of tree.ReferenceAction.
+					if c.FK.OnUpdate != sqlbase.ForeignKeyReference_NO_ACTION {
+						fmt.Fprintf(&condef, " ON UPDATE %s", tree.ReferenceAction(c.FK.OnUpdate))
+					}
+					if c.FK.OnDelete != sqlbase.ForeignKeyReference_NO_ACTION {
+						fmt.Fprintf(&condef, " ON DELETE %s", tree.ReferenceAction(c.FK.OnDelete))
+					}
 
 				case sqlbase.ConstraintTypeUnique:
 					oid = h.UniqueConstraintOid(db, table, c.Index)
@@ -508,6 +547,7 @@ CREATE TABLE pg_catalog.pg_constraint (
 					if err != nil {
 						return err
 					}
+					fmt.Fprintf(&condef, "UNIQUE (%s)", quoteNames(c.Index.ColumnNames...))
 
 				case sqlbase.ConstraintTypeCheck:
 					oid = h.CheckConstraintOid(db, table, c.CheckConstraint)
@@ -516,6 +556,7 @@ CREATE TABLE pg_catalog.pg_constraint (
 					// constraint. We should add an array of column indexes to
 					// sqlbase.TableDescriptor_CheckConstraint and use that here.
 					consrc = tree.NewDString(c.Details)
+					fmt.Fprintf(&condef, "CHECK (%s)", c.Details)
 				}
 
 				if err := addRow(
@@ -544,6 +585,7 @@ CREATE TABLE pg_catalog.pg_constraint (
 					tree.DNull,                                 // conexclop
 					consrc,                                     // conbin
 					consrc,                                     // consrc
+					tree.NewDString(condef.String()),           // condef
 				); err != nil {
 					return err
 				}
@@ -604,10 +646,11 @@ CREATE TABLE pg_catalog.pg_database (
 	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
 		h := makeOidHasher()
 		return forEachDatabaseDesc(ctx, p, func(db *sqlbase.DatabaseDescriptor) error {
+			owner := h.UserOid(db.Privileges.GetOwner())
 			return addRow(
 				h.DBOid(db),                // oid
 				tree.NewDName(db.Name),     // datname
-				tree.DNull,                 // datdba
+				owner,                      // datdba
 				builtins.DatEncodingUTFId,  // encoding
 				builtins.DatEncodingEnUTF8, // datcollate
 				builtins.DatEncodingEnUTF8, // datctype
@@ -648,7 +691,9 @@ var (
 // addition of the conindid column. To provide backward compatibility with
 // pgjdbc drivers before https://github.com/pgjdbc/pgjdbc/pull/689, we
 // provide those rows in pg_depend that track the dependency of foreign key
-// constraints on their supporting index entries in pg_class.
+// constraints on their supporting index entries in pg_class. We also provide
+// the rows that track the dependency of column defaults in pg_attrdef on the
+// sequences they use, which ORMs look up to find the sequence of a column.
 var pgCatalogDependTable = virtualSchemaTable{
 	schema: `
 CREATE TABLE pg_catalog.pg_depend (
@@ -693,16 +738,53 @@ CREATE TABLE pg_catalog.pg_depend (
 		}
 		pgClassTableOid := h.TableOid(db, pgClassDesc)
 
+		pgAttrDefDesc, err := getTableDesc(
+			ctx,
+			p.txn,
+			p.getVirtualTabler(),
+			&tree.TableName{
+				DatabaseName: pgCatalogName,
+				TableName:    "pg_attrdef"},
+		)
+		if err != nil {
+			return errors.New("could not find pg_catalog.pg_attrdef")
+		}
+		pgAttrDefTableOid := h.TableOid(db, pgAttrDefDesc)
+
 		return forEachTableDescWithTableLookup(ctx, p, prefix, func(
 			db *sqlbase.DatabaseDescriptor,
 			table *sqlbase.TableDescriptor,
 			tableLookup tableLookupFn,
 		) error {
+			if err := forEachColumnInTable(table, func(column *sqlbase.ColumnDescriptor) error {
+				for _, seqID := range column.UsesSequenceIDs {
+					seqDB, seqDesc := tableLookup(seqID)
+					if seqDesc == nil {
+						continue
+					}
+					if err := addRow(
+						pgAttrDefTableOid,              // classid
+						h.ColumnOid(db, table, column), // objid
+						zeroVal,                        // objsubid
+						pgClassTableOid,                // refclassid
+						h.TableOid(seqDB, seqDesc),     // refobjid
+						zeroVal,                        // refobjsubid
+						depTypeNormal,                  // deptype
+					); err != nil {
+						return err
+					}
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+
 			info, err := table.GetConstraintInfoWithLookup(tableLookup.tableOrErr)
 			if err != nil {
 				return err
 			}
-			for _, c := range info {
+			for _, name := range sortedConstraintNames(info) {
+				c := info[name]
 				if c.Kind != sqlbase.ConstraintTypeFK {
 					continue
 				}
@@ -837,7 +919,7 @@ CREATE TABLE pg_catalog.pg_index (
     indkey INT2VECTOR,
     indcollation INT,
     indclass INT,
-    indoption INT,
+    indoption INT2VECTOR,
     indexprs STRING,
     indpred STRING
 );
@@ -854,6 +936,10 @@ CREATE TABLE pg_catalog.pg_index (
 				if err != nil {
 					return err
 				}
+				indoption, err := indexOptionsToVector(index)
+				if err != nil {
+					return err
+				}
 				return addRow(
 					h.IndexOid(db, table, index), // indexrelid
 					tableOid,                     // indrelid
@@ -871,7 +957,7 @@ CREATE TABLE pg_catalog.pg_index (
 					indkey,                                   // indkey
 					zeroVal,                                  // indcollation
 					zeroVal,                                  // indclass
-					zeroVal,                                  // indoption
+					indoption,                                // indoption
 					tree.DNull,                               // indexprs
 					tree.DNull,                               // indpred
 				)
@@ -880,6 +966,30 @@ CREATE TABLE pg_catalog.pg_index (
 	},
 }
 
+// The flags of the columns of an index in pg_index.indoption.
+const (
+	indoptionDesc       = 1
+	indoptionNullsFirst = 2
+)
+
+// indexOptionsToVector returns an INT2VECTOR containing the flags of the
+// columns of the index.
+func indexOptionsToVector(index *sqlbase.IndexDescriptor) (tree.Datum, error) {
+	d := tree.NewDArray(types.Int)
+	for _, dir := range index.ColumnDirections {
+		// NULLs sort before the other values in ascending order and after them
+		// in descending order.
+		option := indoptionNullsFirst
+		if dir == sqlbase.IndexDescriptor_DESC {
+			option = indoptionDesc
+		}
+		if err := d.Append(tree.NewDInt(tree.DInt(option))); err != nil {
+			return nil, err
+		}
+	}
+	return tree.NewDIntVectorFromDArray(d), nil
+}
+
 // See: https://www.postgresql.org/docs/9.6/static/view-pg-indexes.html.
 //
 // Note that crdb_oid is an extension of the schema to much more easily map
@@ -1011,11 +1121,12 @@ CREATE TABLE pg_catalog.pg_namespace (
 	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
 		h := makeOidHasher()
 		return forEachDatabaseDesc(ctx, p, func(db *sqlbase.DatabaseDescriptor) error {
+			owner := h.UserOid(db.Privileges.GetOwner())
 			return addRow(
-				h.NamespaceOid(db.Name),  // oid
-				tree.NewDString(db.Name), // nspname
-				tree.DNull,               // nspowner
-				tree.DNull,               // nspacl
+				h.NamespaceOid(db.Name), // oid
+				tree.NewDName(db.Name),  // nspname
+				owner,                   // nspowner
+				tree.DNull,              // nspacl
 			)
 		})
 	},
@@ -1227,27 +1338,104 @@ CREATE TABLE pg_catalog.pg_roles (
 		// need to do the same. This shouldn't be an issue, because pg_roles doesn't
 		// include sensitive information such as password hashes.
 		h := makeOidHasher()
+		options, err := forEachRoleOption(ctx, p)
+		if err != nil {
+			return err
+		}
 		return forEachUser(ctx, p,
 			func(username string) error {
 				isRoot := tree.DBool(username == security.RootUser)
+				_, noInherit := options[username][roleOptionNoInherit]
+				_, noLogin := options[username][roleOptionNoLogin]
+				connLimit := negOneVal
+				if l, ok := options[username][roleOptionConnectionLimit]; ok && l != tree.DNull {
+					i, err := tree.ParseDInt(string(tree.MustBeDString(l)))
+					if err != nil {
+						return err
+					}
+					connLimit = i
+				}
+				validUntil := tree.DNull
+				if v, ok := options[username][roleOptionValidUntil]; ok && v != tree.DNull {
+					t, err := parseStoredValidUntil(v)
+					if err != nil {
+						return err
+					}
+					validUntil = tree.MakeDTimestampTZ(t, time.Microsecond)
+				}
 				return addRow(
-					h.UserOid(username),         // oid
-					tree.NewDName(username),     // rolname
-					tree.MakeDBool(isRoot),      // rolsuper
-					tree.MakeDBool(false),       // rolinherit
-					tree.MakeDBool(isRoot),      // rolcreaterole
-					tree.MakeDBool(isRoot),      // rolcreatedb
-					tree.MakeDBool(false),       // rolcatupdate
-					tree.MakeDBool(true),        // rolcanlogin
-					negOneVal,                   // rolconnlimit
-					tree.NewDString("********"), // rolpassword
-					tree.DNull,                  // rolvaliduntil
-					tree.NewDString("{}"),       // rolconfig
+					h.UserOid(username),                    // oid
+					tree.NewDName(username),                // rolname
+					tree.MakeDBool(isRoot),                 // rolsuper
+					tree.MakeDBool(tree.DBool(!noInherit)), // rolinherit
+					tree.MakeDBool(isRoot),                 // rolcreaterole
+					tree.MakeDBool(isRoot),                 // rolcreatedb
+					tree.MakeDBool(false),                  // rolcatupdate
+					tree.MakeDBool(tree.DBool(!noLogin)),   // rolcanlogin
+					connLimit,                              // rolconnlimit
+					tree.NewDString("********"),            // rolpassword
+					validUntil,                             // rolvaliduntil
+					tree.NewDString("{}"),                  // rolconfig
 				)
 			})
 	},
 }
 
+// forEachRoleOption returns the options in system.role_options of each user
+// and role, mapped to their value.
+func forEachRoleOption(ctx context.Context, p *planner) (map[string]map[string]tree.Datum, error) {
+	ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+	rows, err := ie.QueryRowsInTransaction(
+		ctx, "role-options", p.txn, `SELECT username, option, value FROM system.role_options`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	options := make(map[string]map[string]tree.Datum)
+	for _, row := range rows {
+		username := string(tree.MustBeDString(row[0]))
+		if options[username] == nil {
+			options[username] = make(map[string]tree.Datum)
+		}
+		options[username][string(tree.MustBeDString(row[1]))] = row[2]
+	}
+	return options, nil
+}
+
+// See: https://www.postgresql.org/docs/9.6/static/catalog-pg-auth-members.html.
+var pgCatalogAuthMembersTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE pg_catalog.pg_auth_members (
+	roleid OID,
+	member OID,
+	grantor OID,
+	admin_option BOOL
+);
+`,
+	populate: func(ctx context.Context, p *planner, _ string, addRow func(...tree.Datum) error) error {
+		// Like pg_roles, pg_auth_members is readable by all users.
+		h := makeOidHasher()
+		ie := InternalExecutor{LeaseManager: p.LeaseMgr()}
+		rows, err := ie.QueryRowsInTransaction(
+			ctx, "auth-members", p.txn, `SELECT role, member, "isAdmin" FROM system.role_members`,
+		)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := addRow(
+				h.UserOid(string(tree.MustBeDString(row[0]))), // roleid
+				h.UserOid(string(tree.MustBeDString(row[1]))), // member
+				tree.DNull, // grantor
+				row[2],     // admin_option
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 var (
 	varTypeString   = tree.NewDString("string")
 	settingsCtxUser = tree.NewDString("user")
@@ -1281,12 +1469,13 @@ CREATE TABLE pg_catalog.pg_settings (
 			gen := varGen[vName]
 			value := gen.Get(p.session)
 			valueDatum := tree.NewDString(value)
+			defaultDatum := tree.NewDString(gen.getDefault(p.session))
 			if err := addRow(
 				tree.NewDString(strings.ToLower(vName)), // name
 				valueDatum,                              // setting
 				tree.DNull,                              // unit
 				tree.DNull,                              // category
-				tree.DNull,                              // short_desc
+				tree.NewDString(gen.Description),        // short_desc
 				tree.DNull,                              // extra_desc
 				settingsCtxUser,                         // context
 				varTypeString,                           // vartype
@@ -1294,8 +1483,8 @@ CREATE TABLE pg_catalog.pg_settings (
 				tree.DNull,                              // min_val
 				tree.DNull,                              // max_val
 				tree.DNull,                              // enumvals
-				valueDatum,                              // boot_val
-				valueDatum,                              // reset_val
+				defaultDatum,                            // boot_val
+				defaultDatum,                            // reset_val
 				tree.DNull,                              // sourcefile
 				tree.DNull,                              // sourceline
 				tree.MakeDBool(false),                   // pending_restart
@@ -1326,10 +1515,11 @@ CREATE TABLE pg_catalog.pg_tables (
 			if table.IsView() {
 				return nil
 			}
+			owner := tree.NewDName(table.Privileges.GetOwner())
 			return addRow(
 				tree.NewDName(db.Name),    // schemaname
 				tree.NewDName(table.Name), // tablename
-				tree.DNull,                // tableowner
+				owner,                     // tableowner
 				tree.DNull,                // tablespace
 				tree.MakeDBool(tree.DBool(table.IsPhysicalTable())), // hasindexes
 				tree.MakeDBool(false),                               // hasrules
@@ -1600,10 +1790,11 @@ CREATE TABLE pg_catalog.pg_views (
 			// while postgres would more accurately print `SELECT b AS a FROM foo`.
 			// TODO(a-robinson): Insert column aliases into view query once we
 			// have a semantic query representation to work with (#10083).
+			owner := tree.NewDString(desc.Privileges.GetOwner())
 			return addRow(
 				tree.NewDName(db.Name),          // schemaname
 				tree.NewDName(desc.Name),        // viewname
-				tree.DNull,                      // viewowner
+				owner,                           // viewowner
 				tree.NewDString(desc.ViewQuery), // definition
 			)
 		})
@@ -0,0 +1,108 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql"
+)
+
+// AuthConn is the interface through which the authentication methods
+// registered with RegisterAuthMethod exchange messages with the client.
+type AuthConn interface {
+	// SendAuthRequest sends an authentication request of the given type,
+	// followed by data.
+	SendAuthRequest(authType int32, data []byte) error
+	// GetPwdData returns the contents of the next password message, which
+	// carries the client's responses to authentication requests.
+	GetPwdData() ([]byte, error)
+	// User returns the SQL user the client requested to connect as.
+	User() string
+}
+
+// AuthMethod authenticates a client through an exchange over c. It is given
+// the options of the host-based authentication entry that selected it and
+// returns the client's system identity, e.g. a Kerberos principal, which must
+// map to the requested SQL user: through the identity map named by the `map`
+// option if there is one, and by being equal to it otherwise.
+type AuthMethod func(
+	ctx context.Context,
+	c AuthConn,
+	tlsState tls.ConnectionState,
+	execCfg *sql.ExecutorConfig,
+	options map[string]string,
+) (systemIdentity string, err error)
+
+type registeredAuthMethod struct {
+	fn      AuthMethod
+	options []string
+}
+
+// acceptsOption returns whether the method takes the option; every method
+// takes `map`.
+func (m *registeredAuthMethod) acceptsOption(name string) bool {
+	if name == "map" {
+		return true
+	}
+	for _, o := range m.options {
+		if o == name {
+			return true
+		}
+	}
+	return false
+}
+
+var authMethods = map[string]*registeredAuthMethod{}
+
+// RegisterAuthMethod makes an authentication method available to the
+// host-based authentication configuration under the given name, along with
+// the options it takes. It must be called from an init function.
+func RegisterAuthMethod(name string, fn AuthMethod, options ...string) {
+	if _, ok := hbaMethodNames[name]; ok {
+		panic(fmt.Sprintf("authentication method %q already exists", name))
+	}
+	if _, ok := authMethods[name]; ok {
+		panic(fmt.Sprintf("authentication method %q already registered", name))
+	}
+	authMethods[name] = &registeredAuthMethod{fn: fn, options: options}
+}
+
+// SendAuthRequest implements the AuthConn interface.
+func (c *v3Conn) SendAuthRequest(authType int32, data []byte) error {
+	c.writeBuf.initMsg(serverMsgAuth)
+	c.writeBuf.putInt32(authType)
+	c.writeBuf.write(data)
+	if err := c.writeBuf.finishMsg(c.wr); err != nil {
+		return err
+	}
+	return c.wr.Flush()
+}
+
+// GetPwdData implements the AuthConn interface.
+func (c *v3Conn) GetPwdData() ([]byte, error) {
+	if err := c.readPasswordMessage(); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), c.readBuf.msg...), nil
+}
+
+// User implements the AuthConn interface.
+func (c *v3Conn) User() string {
+	return c.sessionArgs.User
+}
@@ -29,8 +29,11 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
@@ -185,6 +188,159 @@ func TestBinaryIntArray(t *testing.T) {
 	}
 }
 
+func TestBinaryInterval(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	buf := writeBuffer{bytecount: metric.NewCounter(metric.Metadata{})}
+	evalCtx := tree.NewTestingEvalContext()
+	defer evalCtx.Stop(context.Background())
+	for _, s := range []string{
+		"0s", "1 day", "-3 months", "1 year 2 months 3 days 04:05:06.789012", "-00:00:00.000001",
+	} {
+		buf.wrapped.Reset()
+		d, err := tree.ParseDInterval(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.writeBinaryDatum(context.Background(), d, time.UTC)
+		if buf.err != nil {
+			t.Fatal(buf.err)
+		}
+		b := buf.wrapped.Bytes()
+		got, err := decodeOidDatum(oid.T_interval, formatBinary, b[4:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Compare(evalCtx, d) != 0 {
+			t.Errorf("expected %s, got %s", d, got)
+		}
+	}
+}
+
+func TestBinaryJSONB(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	const j = `{"a": [1, 2]}`
+	got, err := decodeOidDatum(oid.T_jsonb, formatBinary, append([]byte{pgJSONBVersion}, j...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := got.(*tree.DString); !ok || string(*s) != j {
+		t.Errorf("expected %q, got %s", j, got)
+	}
+	if _, err := decodeOidDatum(oid.T_jsonb, formatBinary, append([]byte{2}, j...)); !testutils.IsError(err, "unsupported jsonb version number") {
+		t.Errorf("expected unsupported version error, got %v", err)
+	}
+}
+
+func TestBinaryArrays(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	buf := writeBuffer{bytecount: metric.NewCounter(metric.Metadata{})}
+	evalCtx := tree.NewTestingEvalContext()
+	defer evalCtx.Stop(context.Background())
+	for _, tc := range []struct {
+		typ   types.T
+		elems []tree.Datum
+	}{
+		{types.Bool, []tree.Datum{tree.DBoolTrue, tree.DNull, tree.DBoolFalse}},
+		{types.Float, []tree.Datum{tree.NewDFloat(1.5), tree.NewDFloat(-2)}},
+		{types.Bytes, []tree.Datum{tree.NewDBytes("foo"), tree.DNull}},
+		{types.Date, []tree.Datum{tree.NewDDate(0), tree.NewDDate(17000)}},
+		{types.Int, []tree.Datum{tree.NewDInt(1), tree.DNull, tree.NewDInt(3)}},
+		{types.String, []tree.Datum{tree.NewDString("a"), tree.NewDString("")}},
+		{types.Decimal, []tree.Datum{tree.DNull, &tree.DDecimal{Decimal: *apd.New(-15, -1)}}},
+		{types.Timestamp, []tree.Datum{tree.MakeDTimestamp(time.Unix(1e9, 1000), time.Microsecond)}},
+		{types.Interval, []tree.Datum{&tree.DInterval{Duration: duration.Duration{Months: 1, Nanos: 1000}}}},
+		{types.Int, nil},
+	} {
+		buf.wrapped.Reset()
+		d := tree.NewDArray(tc.typ)
+		for _, e := range tc.elems {
+			if err := d.Append(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+		buf.writeBinaryDatum(context.Background(), d, time.UTC)
+		if buf.err != nil {
+			t.Fatal(buf.err)
+		}
+		b := buf.wrapped.Bytes()
+		got, err := decodeOidDatum(d.ResolvedType().Oid(), formatBinary, b[4:])
+		if err != nil {
+			t.Fatalf("%s: %s", d, err)
+		}
+		if got.Compare(evalCtx, d) != 0 {
+			t.Errorf("expected %s, got %s", d, got)
+		}
+	}
+}
+
+func TestBinaryDecimalNaN(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	buf := writeBuffer{bytecount: metric.NewCounter(metric.Metadata{})}
+	d := &tree.DDecimal{Decimal: apd.Decimal{Form: apd.NaN}}
+	buf.writeBinaryDatum(context.Background(), d, time.UTC)
+	if buf.err != nil {
+		t.Fatal(buf.err)
+	}
+	expected := []byte{0, 0, 0, 8, 0, 0, 0, 0, 0xC0, 0, 0, 0}
+	if got := buf.wrapped.Bytes(); !bytes.Equal(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	got, err := decodeOidDatum(oid.T_numeric, formatBinary, expected[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec, ok := got.(*tree.DDecimal); !ok || dec.Form != apd.NaN {
+		t.Fatalf("expected NaN, got %s", got)
+	}
+
+	buf.wrapped.Reset()
+	buf.writeBinaryDatum(context.Background(), &tree.DDecimal{Decimal: apd.Decimal{Form: apd.Infinite}}, time.UTC)
+	if !testutils.IsError(buf.err, "unsupported binary serialization of infinite decimal") {
+		t.Fatalf("expected infinite decimal error, got %v", buf.err)
+	}
+}
+
+func TestBinaryEncodeJSONB(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	buf := writeBuffer{bytecount: metric.NewCounter(metric.Metadata{})}
+	d, err := tree.ParseDJSON(`{"a": [1, 2]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.writeBinaryDatum(context.Background(), d, time.UTC)
+	if buf.err != nil {
+		t.Fatal(buf.err)
+	}
+	b := buf.wrapped.Bytes()
+	got, err := decodeOidDatum(oid.T_jsonb, formatBinary, b[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := got.(*tree.DString); !ok || string(*s) != d.(*tree.DJSON).JSON.String() {
+		t.Errorf("expected %s, got %s", d, got)
+	}
+}
+
+func TestBinaryTuple(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	buf := writeBuffer{bytecount: metric.NewCounter(metric.Metadata{})}
+	d := tree.NewDTuple(tree.NewDInt(7), tree.DNull, tree.NewDString("ab"))
+	buf.writeBinaryDatum(context.Background(), d, time.UTC)
+	if buf.err != nil {
+		t.Fatal(buf.err)
+	}
+	expected := []byte{
+		0, 0, 0, 38, // length
+		0, 0, 0, 3, // number of fields
+		0, 0, 0, 20, 0, 0, 0, 8, 0, 0, 0, 0, 0, 0, 0, 7, // int8 7
+		0, 0, 2, 193, 255, 255, 255, 255, // unknown NULL
+		0, 0, 0, 25, 0, 0, 0, 2, 'a', 'b', // text "ab"
+	}
+	if got := buf.wrapped.Bytes(); !bytes.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
 var generateBinaryCmd = flag.String("generate-binary", "", "generate-binary command invocation")
 
 func TestRandomBinaryDecimal(t *testing.T) {
@@ -0,0 +1,177 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"net"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+// This file implements the query cancellation protocol of PostgreSQL. After
+// authentication, every connection is sent a BackendKeyData message holding
+// a process ID and a secret key. To cancel the queries running on that
+// connection, a client opens a new connection and sends a CancelRequest
+// containing the same values instead of a StartupMessage.
+// See: https://www.postgresql.org/docs/current/static/protocol-flow.html#PROTOCOL-FLOW-CANCELING-REQUESTS
+//
+// The process ID is the ID of the node serving the connection. A node
+// receiving a CancelRequest for another node relays the request to that node,
+// so clients can send it to any node of the cluster, e.g. through a load
+// balancer. As in PostgreSQL, CancelRequests are not authenticated beyond the
+// knowledge of the secret key, and nothing is sent back to their sender.
+
+// versionCancel is the protocol version code identifying CancelRequests.
+const versionCancel = 80877102
+
+// cancelRelayTimeout bounds the time spent relaying a CancelRequest to the
+// node serving the connection.
+const cancelRelayTimeout = 5 * time.Second
+
+// cancelKey is the content of a BackendKeyData message.
+type cancelKey struct {
+	processID int32
+	secret    int32
+}
+
+// cancelKeyRegistry keeps track of the cancel keys of the connections served
+// by a node.
+type cancelKeyRegistry struct {
+	nodeID *base.NodeIDContainer
+	gossip *gossip.Gossip
+
+	mu struct {
+		syncutil.Mutex
+		// sessions maps secrets to the session of the connection they were
+		// sent to.
+		sessions map[int32]*sql.Session
+	}
+}
+
+func (r *cancelKeyRegistry) init(nodeID *base.NodeIDContainer, g *gossip.Gossip) {
+	r.nodeID = nodeID
+	r.gossip = g
+	r.mu.sessions = make(map[int32]*sql.Session)
+}
+
+// register assigns a cancel key to session. The returned function must be
+// called when the session is closed.
+func (r *cancelKeyRegistry) register(session *sql.Session) (cancelKey, func(), error) {
+	key := cancelKey{processID: int32(r.nodeID.Get())}
+	var buf [4]byte
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for {
+		if _, err := rand.Read(buf[:]); err != nil {
+			return cancelKey{}, nil, err
+		}
+		key.secret = int32(binary.BigEndian.Uint32(buf[:]))
+		if _, ok := r.mu.sessions[key.secret]; !ok {
+			break
+		}
+	}
+	r.mu.sessions[key.secret] = session
+	return key, func() {
+		r.mu.Lock()
+		delete(r.mu.sessions, key.secret)
+		r.mu.Unlock()
+	}, nil
+}
+
+// cancel serves a CancelRequest, whose contents (following the protocol
+// version code) are in msg.
+func (r *cancelKeyRegistry) cancel(ctx context.Context, msg []byte) error {
+	buf := readBuffer{msg: msg}
+	processID, err := buf.getUint32()
+	if err != nil {
+		return err
+	}
+	secret, err := buf.getUint32()
+	if err != nil {
+		return err
+	}
+	key := cancelKey{processID: int32(processID), secret: int32(secret)}
+	if nodeID := roachpb.NodeID(key.processID); nodeID != r.nodeID.Get() {
+		return r.relay(ctx, nodeID, key)
+	}
+
+	r.mu.Lock()
+	session, ok := r.mu.sessions[key.secret]
+	r.mu.Unlock()
+	if !ok {
+		log.Infof(ctx, "cancel request did not match any connection")
+		return nil
+	}
+	if !session.CancelActiveQueries() && log.V(1) {
+		log.Infof(ctx, "cancel request received by a connection without queries in flight")
+	}
+	return nil
+}
+
+// relay sends a CancelRequest for key to the node with the given ID.
+func (r *cancelKeyRegistry) relay(ctx context.Context, nodeID roachpb.NodeID, key cancelKey) error {
+	if r.gossip == nil {
+		return errors.Errorf("cannot relay cancel request to node %d", nodeID)
+	}
+	addr, err := r.gossip.GetNodeIDAddress(nodeID)
+	if err != nil {
+		return errors.Wrapf(err, "relaying cancel request to node %d", nodeID)
+	}
+	conn, err := net.DialTimeout(addr.Network(), addr.String(), cancelRelayTimeout)
+	if err != nil {
+		return errors.Wrapf(err, "relaying cancel request to node %d", nodeID)
+	}
+	defer conn.Close()
+	if err := conn.SetWriteDeadline(timeutil.Now().Add(cancelRelayTimeout)); err != nil {
+		return err
+	}
+	if _, err := conn.Write(key.cancelRequest()); err != nil {
+		return errors.Wrapf(err, "relaying cancel request to node %d", nodeID)
+	}
+	if log.V(1) {
+		log.Infof(ctx, "relayed cancel request to node %d", nodeID)
+	}
+	return nil
+}
+
+// cancelRequest returns the CancelRequest message for key.
+func (key cancelKey) cancelRequest() []byte {
+	msg := make([]byte, 16)
+	binary.BigEndian.PutUint32(msg[0:], uint32(len(msg)))
+	binary.BigEndian.PutUint32(msg[4:], versionCancel)
+	binary.BigEndian.PutUint32(msg[8:], uint32(key.processID))
+	binary.BigEndian.PutUint32(msg[12:], uint32(key.secret))
+	return msg
+}
+
+// sendBackendKeyData sends the cancel key of the connection to the client.
+func (c *v3Conn) sendBackendKeyData(key cancelKey) error {
+	c.writeBuf.initMsg(serverMsgBackendKeyData)
+	c.writeBuf.putInt32(key.processID)
+	c.writeBuf.putInt32(key.secret)
+	return c.writeBuf.finishMsg(c.wr)
+}
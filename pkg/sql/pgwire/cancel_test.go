@@ -0,0 +1,88 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// sendCancelRequest sends a CancelRequest for the key of c to addr.
+func (c *rawConn) sendCancelRequest(addr string) error {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	msg := []byte{0, 0, 0, 16, 0x04, 0xd2, 0x16, 0x2e}
+	msg = append(msg, c.key...)
+	_, err = conn.Write(msg)
+	return err
+}
+
+func TestPGWireCancelRequest(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tc := serverutils.StartTestCluster(t, 2, /* numNodes */
+		base.TestClusterArgs{
+			ReplicationMode: base.ReplicationManual,
+			ServerArgs:      base.TestServerArgs{Insecure: true},
+		})
+	defer tc.Stopper().Stop(context.TODO())
+
+	// The query runs on the second node, and the cancel request is sent either
+	// to that node or to the first one, which must relay it.
+	for _, cancelIdx := range []int{1, 0} {
+		c, err := dialRaw(tc.Server(1).ServingAddr())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.query("SELECT * FROM generate_series(1, 20000000)"); err != nil {
+			t.Fatal(err)
+		}
+		sentCancel := false
+		for {
+			typ, payload, err := c.recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if typ == 'D' && !sentCancel {
+				if err := c.sendCancelRequest(tc.Server(cancelIdx).ServingAddr()); err != nil {
+					t.Fatal(err)
+				}
+				sentCancel = true
+			}
+			if typ == 'C' {
+				t.Fatalf("cancel request sent to node %d: query was not cancelled", cancelIdx+1)
+			}
+			if typ == 'E' {
+				if errorField(payload, 'C') != pgerror.CodeQueryCanceledError &&
+					!strings.Contains(errorField(payload, 'M'), "query execution canceled") {
+					t.Fatalf("expected query canceled error, got %q", payload)
+				}
+				break
+			}
+		}
+		_ = c.conn.Close()
+	}
+}
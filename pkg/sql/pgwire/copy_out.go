@@ -0,0 +1,113 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"io"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// beginCopyOut switches the client to the COPY OUT data flow of a
+// COPY ... TO STDOUT statement. All the columns are sent in the text format,
+// since the binary format isn't supported. If the data is in the CSV format
+// and has a header, the header is sent too.
+// See: https://www.postgresql.org/docs/current/static/protocol-flow.html#PROTOCOL-COPY
+func (c *v3Conn) beginCopyOut(w io.Writer) error {
+	state := &c.streamingState
+	c.writeBuf.initMsg(serverMsgCopyOutResponse)
+	c.writeBuf.writeByte(byte(formatText))
+	c.writeBuf.putInt16(int16(len(state.columns)))
+	for range state.columns {
+		c.writeBuf.putInt16(int16(formatText))
+	}
+	if err := c.writeBuf.finishMsg(w); err != nil {
+		return err
+	}
+	if !state.copyOutOpts.Header {
+		return nil
+	}
+	c.writeBuf.initMsg(serverMsgCopyData)
+	line := c.copyOutRow[:0]
+	for i, col := range state.columns {
+		if i > 0 {
+			line = append(line, state.copyOutOpts.Delimiter)
+		}
+		line = appendCopyCSVField(line, []byte(col.Name), &state.copyOutOpts)
+	}
+	c.copyOutRow = append(line, '\n')
+	c.writeBuf.write(c.copyOutRow)
+	return c.writeBuf.finishMsg(w)
+}
+
+// writeCopyData writes a row of a COPY TO statement as a CopyData message
+// containing one line of data.
+func (c *v3Conn) writeCopyData(ctx context.Context, row tree.Datums, w io.Writer) error {
+	opts := &c.streamingState.copyOutOpts
+	line := c.copyOutRow[:0]
+	for i, d := range row {
+		if i > 0 {
+			line = append(line, opts.Delimiter)
+		}
+		if d == tree.DNull {
+			line = append(line, opts.Null...)
+			continue
+		}
+		// Fields have the same contents as in DataRow messages using the text
+		// format, so we reuse that encoding and strip its length prefix.
+		c.copyOutBuf.reset()
+		c.copyOutBuf.writeTextDatum(ctx, d, c.session.Location)
+		if c.copyOutBuf.err != nil {
+			return c.copyOutBuf.err
+		}
+		field := c.copyOutBuf.wrapped.Bytes()[4:]
+		if opts.Format == sql.CopyFormatCSV {
+			line = appendCopyCSVField(line, field, opts)
+		} else {
+			line = sql.AppendCopyTextField(line, field, opts.Delimiter)
+		}
+	}
+	c.copyOutRow = append(line, '\n')
+
+	c.writeBuf.initMsg(serverMsgCopyData)
+	c.writeBuf.write(c.copyOutRow)
+	return c.writeBuf.finishMsg(w)
+}
+
+// appendCopyCSVField appends a field in the CSV format to buf. The field is
+// quoted if it contains special characters or could be mistaken for NULL.
+func appendCopyCSVField(buf []byte, field []byte, opts *sql.CopyOptions) []byte {
+	needsQuotes := string(field) == opts.Null
+	for _, ch := range field {
+		if ch == opts.Delimiter || ch == opts.Quote || ch == '\n' || ch == '\r' {
+			needsQuotes = true
+			break
+		}
+	}
+	if !needsQuotes {
+		return append(buf, field...)
+	}
+	buf = append(buf, opts.Quote)
+	for _, ch := range field {
+		if ch == opts.Quote || ch == opts.Escape {
+			buf = append(buf, opts.Escape)
+		}
+		buf = append(buf, ch)
+	}
+	return append(buf, opts.Quote)
+}
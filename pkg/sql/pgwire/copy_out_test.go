@@ -0,0 +1,70 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestAppendCopyTextField(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		field     string
+		delimiter byte
+		expected  string
+	}{
+		{`abc`, '\t', `abc`},
+		{"a\tb", '\t', `a\tb`},
+		{"a\nb\rc", '\t', `a\nb\rc`},
+		{`a\b`, '\t', `a\\b`},
+		{`a|b`, '|', `a\|b`},
+		{`a,b`, '\t', `a,b`},
+	}
+	for _, tc := range testCases {
+		if got := string(sql.AppendCopyTextField(nil, []byte(tc.field), tc.delimiter)); got != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.field, tc.expected, got)
+		}
+	}
+}
+
+func TestAppendCopyCSVField(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	opts, err := sql.ParseCopyOptions(tree.KVOptions{{Key: "format", Value: tree.NewStrVal("csv")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		field    string
+		expected string
+	}{
+		{`abc`, `abc`},
+		{`a,b`, `"a,b"`},
+		{`a"b`, `"a""b"`},
+		{"a\nb", "\"a\nb\""},
+		{``, `""`},
+		{`a\b`, `a\b`},
+	}
+	for _, tc := range testCases {
+		if got := string(appendCopyCSVField(nil, []byte(tc.field), &opts)); got != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.field, tc.expected, got)
+		}
+	}
+}
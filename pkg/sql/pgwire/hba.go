@@ -0,0 +1,252 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// This file implements host-based authentication: a configuration in the
+// format of the pg_hba.conf file of Postgres selects the authentication
+// method of each connection from its type, database, user and address. Each
+// line holds an entry:
+//
+//   host  DATABASE USER ADDRESS METHOD [OPTION=VALUE ...]
+//   local DATABASE USER METHOD [OPTION=VALUE ...]
+//
+// where `host` entries match TCP connections and `local` entries match Unix
+// socket connections (`hostssl` is accepted as a synonym of `host`, since
+// secure nodes only accept SSL connections). DATABASE and USER are `all` or
+// comma-separated lists of names, ADDRESS is `all`, a CIDR or an IP address,
+// and METHOD is one of `cert`, `password`, `trust`, `reject`, `peer` or a
+// method registered with RegisterAuthMethod. `peer`, which is only valid in
+// `local` entries, authenticates clients with their operating system user
+// name and takes the `map` option like registered methods, the only other
+// ones taking options. Text following `#` is a comment.
+//
+// The first entry matching the connection decides its authentication method;
+// connections that don't match any entry are rejected. When no configuration
+// is set, clients authenticate with their certificate if they present one and
+// with their password otherwise.
+
+var hbaConfSetting = settings.RegisterValidatedStringSetting(
+	"server.host_based_authentication.configuration",
+	"host-based authentication configuration, in the format of pg_hba.conf, used to "+
+		"select the authentication method of SQL connections",
+	"",
+	func(s string) error {
+		_, err := parseHBAConf(s)
+		return err
+	},
+)
+
+type hbaMethod int
+
+const (
+	// hbaMethodCert authenticates clients with their TLS certificate.
+	hbaMethodCert hbaMethod = iota
+	// hbaMethodPassword authenticates clients with their password, through a
+	// SCRAM exchange or in cleartext depending on how it is stored.
+	hbaMethodPassword
+	// hbaMethodTrust lets clients in without authentication.
+	hbaMethodTrust
+	// hbaMethodReject refuses the connection.
+	hbaMethodReject
+	// hbaMethodExternal authenticates clients with a method registered with
+	// RegisterAuthMethod.
+	hbaMethodExternal
+	// hbaMethodPeer authenticates clients connected over a Unix socket with
+	// the operating system user name of their process.
+	hbaMethodPeer
+)
+
+var hbaMethodNames = map[string]hbaMethod{
+	"cert":     hbaMethodCert,
+	"password": hbaMethodPassword,
+	"trust":    hbaMethodTrust,
+	"reject":   hbaMethodReject,
+	"peer":     hbaMethodPeer,
+}
+
+type hbaEntry struct {
+	local     bool
+	databases []string
+	users     []string
+	// addr is nil for entries matching any address.
+	addr   *net.IPNet
+	method hbaMethod
+	// external is set for hbaMethodExternal, options for hbaMethodExternal
+	// and hbaMethodPeer.
+	external *registeredAuthMethod
+	options  map[string]string
+}
+
+type hbaConf struct {
+	entries []hbaEntry
+}
+
+// parseHBAConf parses a host-based authentication configuration. The empty
+// configuration has no entries.
+func parseHBAConf(s string) (*hbaConf, error) {
+	conf := &hbaConf{}
+	for i, line := range strings.Split(s, "\n") {
+		if pos := strings.IndexByte(line, '#'); pos >= 0 {
+			line = line[:pos]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		e, err := parseHBAEntry(fields)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", i+1)
+		}
+		conf.entries = append(conf.entries, e)
+	}
+	return conf, nil
+}
+
+func parseHBAEntry(fields []string) (hbaEntry, error) {
+	var e hbaEntry
+	var methodIdx int
+	switch fields[0] {
+	case "host", "hostssl":
+		methodIdx = 4
+	case "local":
+		methodIdx = 3
+		e.local = true
+	default:
+		return e, errors.Errorf("unknown connection type %q", fields[0])
+	}
+	if len(fields) <= methodIdx {
+		return e, errors.Errorf("expected at least %d fields in %s entry, found %d",
+			methodIdx+1, fields[0], len(fields))
+	}
+	var err error
+	if e.databases, err = parseHBANames(fields[1]); err != nil {
+		return e, err
+	}
+	if e.users, err = parseHBANames(fields[2]); err != nil {
+		return e, err
+	}
+	if !e.local && fields[3] != "all" {
+		addr := fields[3]
+		if !strings.Contains(addr, "/") {
+			// A single address.
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				return e, errors.Errorf("invalid address %q", addr)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+			addr = fmt.Sprintf("%s/%d", addr, bits)
+		}
+		_, ipNet, err := net.ParseCIDR(addr)
+		if err != nil {
+			return e, errors.Errorf("invalid address %q", fields[3])
+		}
+		e.addr = ipNet
+	}
+	methodName := fields[methodIdx]
+	options := fields[methodIdx+1:]
+	acceptsOption := func(name string) bool { return name == "map" }
+	if method, ok := hbaMethodNames[methodName]; ok {
+		if method == hbaMethodPeer && !e.local {
+			return e, errors.Errorf("authentication method %q is only valid in local entries", methodName)
+		}
+		if method != hbaMethodPeer && len(options) > 0 {
+			return e, errors.Errorf("authentication method %q doesn't take options", methodName)
+		}
+		e.method = method
+	} else {
+		external, ok := authMethods[methodName]
+		if !ok {
+			return e, errors.Errorf("unknown authentication method %q", methodName)
+		}
+		e.method = hbaMethodExternal
+		e.external = external
+		acceptsOption = external.acceptsOption
+	}
+	e.options = make(map[string]string)
+	for _, option := range options {
+		pos := strings.IndexByte(option, '=')
+		if pos <= 0 {
+			return e, errors.Errorf("invalid option %q", option)
+		}
+		name, value := option[:pos], option[pos+1:]
+		if !acceptsOption(name) {
+			return e, errors.Errorf("authentication method %q doesn't take option %q", methodName, name)
+		}
+		e.options[name] = value
+	}
+	return e, nil
+}
+
+// parseHBANames parses a comma-separated list of names; nil means all. A list
+// without any name is an error rather than all names.
+func parseHBANames(s string) ([]string, error) {
+	if s == "all" {
+		return nil, nil
+	}
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name != "" {
+			names = append(names, tree.Name(name).Normalize())
+		}
+	}
+	if len(names) == 0 {
+		return nil, errors.Errorf("invalid name list %q", s)
+	}
+	return names, nil
+}
+
+func hbaNameMatches(names []string, name string) bool {
+	if names == nil {
+		return true
+	}
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the first entry matching a connection. ip is nil for Unix
+// socket connections.
+func (conf *hbaConf) lookup(ip net.IP, database, user string) (hbaEntry, bool) {
+	database = tree.Name(database).Normalize()
+	for _, e := range conf.entries {
+		if e.local != (ip == nil) {
+			continue
+		}
+		if e.addr != nil && !e.addr.Contains(ip) {
+			continue
+		}
+		if hbaNameMatches(e.databases, database) && hbaNameMatches(e.users, user) {
+			return e, true
+		}
+	}
+	return hbaEntry{}, false
+}
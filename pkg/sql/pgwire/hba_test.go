@@ -0,0 +1,114 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"net"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func init() {
+	RegisterAuthMethod("test", nil, "opt")
+}
+
+func TestParseHBAConf(t *testing.T) {
+	testCases := []struct {
+		conf string
+		err  string
+	}{
+		{``, ``},
+		{"# comment\n\n", ``},
+		{`host all all all cert`, ``},
+		{`hostssl db1,db2 u1,u2 10.0.0.0/8 password # comment`, ``},
+		{`host all all 10.1.2.3 trust`, ``},
+		{`host all all ::1 reject`, ``},
+		{`local all all password`, ``},
+		{`host all all cert`, `line 1: expected at least 5 fields in host entry, found 4`},
+		{`local all all`, `line 1: expected at least 4 fields in local entry, found 3`},
+		{`local all all all cert`, `line 1: unknown authentication method "all"`},
+		{`host all all all cert map=x`, `authentication method "cert" doesn't take options`},
+		{"host all all all cert\nhostnossl all all all cert", `line 2: unknown connection type "hostnossl"`},
+		{`host all all 10.0.0.0/33 cert`, `invalid address "10.0.0.0/33"`},
+		{`host all all localhost cert`, `invalid address "localhost"`},
+		{`host all all all md5`, `unknown authentication method "md5"`},
+		{`local all all peer`, ``},
+		{`local all all peer map=m`, ``},
+		{`host all all all peer`, `authentication method "peer" is only valid in local entries`},
+		{`local all all peer opt=1`, `authentication method "peer" doesn't take option "opt"`},
+		{`host all all all test`, ``},
+		{`local all all test opt=1 map=m`, ``},
+		{`host all all all test opt`, `invalid option "opt"`},
+		{`host all all all test =1`, `invalid option "=1"`},
+		{`host all all all test other=1`, `authentication method "test" doesn't take option "other"`},
+		{`host , all all cert`, `line 1: invalid name list ","`},
+		{`local all ,, trust`, `line 1: invalid name list ",,"`},
+	}
+	for _, tc := range testCases {
+		if _, err := parseHBAConf(tc.conf); !testutils.IsError(err, tc.err) {
+			t.Errorf("%q: expected error %q, got %v", tc.conf, tc.err, err)
+		}
+	}
+}
+
+func TestHBAConfLookup(t *testing.T) {
+	conf, err := parseHBAConf(`
+# TYPE DATABASE USER      ADDRESS     METHOD
+host   all      root      all         cert
+local  all      root                  peer
+local  all      all                   trust
+host   all      Admin     10.0.0.0/8  password
+host   app      all       10.0.0.0/8  cert
+host   all      all       10.1.2.3    trust
+host   all      all       all         reject
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		ip       string
+		database string
+		user     string
+		method   hbaMethod
+	}{
+		{"192.168.0.1", "", "root", hbaMethodCert},
+		{"", "app", "root", hbaMethodPeer},
+		{"", "app", "bob", hbaMethodTrust},
+		{"10.0.0.1", "app", "admin", hbaMethodPassword},
+		{"10.0.0.1", "APP", "bob", hbaMethodCert},
+		{"10.0.0.1", "other", "bob", hbaMethodReject},
+		{"10.1.2.3", "other", "bob", hbaMethodTrust},
+		{"192.168.0.1", "app", "admin", hbaMethodReject},
+	}
+	for _, tc := range testCases {
+		entry, ok := conf.lookup(net.ParseIP(tc.ip), tc.database, tc.user)
+		if !ok {
+			t.Errorf("%+v: no matching entry", tc)
+			continue
+		}
+		if entry.method != tc.method {
+			t.Errorf("%+v: expected method %d, got %d", tc, tc.method, entry.method)
+		}
+	}
+
+	conf, err = parseHBAConf(`host all root all cert`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := conf.lookup(net.ParseIP("10.0.0.1"), "", "bob"); ok {
+		t.Errorf("expected no matching entry")
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// The identity map, in the format of the pg_ident.conf file of Postgres,
+// maps the system identities established by the authentication methods
+// registered with RegisterAuthMethod, such as Kerberos principals, to SQL
+// users. Each line holds a rule:
+//
+//   MAP SYSTEM-IDENTITY SQL-USER
+//
+// where MAP is the name given by the `map` option of host-based
+// authentication entries. A SYSTEM-IDENTITY starting with `/` is a regular
+// expression; `\1` in SQL-USER is then replaced by its first capture group.
+// A client may connect as a SQL user if a rule of the map maps its identity
+// to that user.
+
+var identMapSetting = settings.RegisterValidatedStringSetting(
+	"server.identity_map.configuration",
+	"system-identity to SQL user mappings, in the format of pg_ident.conf, used by the "+
+		"host-based authentication entries with a map option",
+	"",
+	func(s string) error {
+		_, err := parseIdentMap(s)
+		return err
+	},
+)
+
+type identMapRule struct {
+	mapName        string
+	systemIdentity string
+	// re is set when systemIdentity is a regular expression.
+	re      *regexp.Regexp
+	sqlUser string
+}
+
+type identMap struct {
+	rules []identMapRule
+}
+
+func parseIdentMap(s string) (*identMap, error) {
+	m := &identMap{}
+	for i, line := range strings.Split(s, "\n") {
+		if pos := strings.IndexByte(line, '#'); pos >= 0 {
+			line = line[:pos]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, errors.Errorf("line %d: expected 3 fields, found %d", i+1, len(fields))
+		}
+		r := identMapRule{mapName: fields[0], systemIdentity: fields[1], sqlUser: fields[2]}
+		if strings.HasPrefix(r.systemIdentity, "/") {
+			re, err := regexp.Compile(r.systemIdentity[1:])
+			if err != nil {
+				return nil, errors.Wrapf(err, "line %d", i+1)
+			}
+			r.re = re
+		}
+		m.rules = append(m.rules, r)
+	}
+	return m, nil
+}
+
+// allows returns whether the map lets the system identity connect as the
+// (normalized) SQL user.
+func (m *identMap) allows(mapName, systemIdentity, user string) bool {
+	for _, r := range m.rules {
+		if r.mapName != mapName {
+			continue
+		}
+		sqlUser := r.sqlUser
+		if r.re == nil {
+			if r.systemIdentity != systemIdentity {
+				continue
+			}
+		} else {
+			match := r.re.FindStringSubmatch(systemIdentity)
+			if match == nil {
+				continue
+			}
+			if len(match) > 1 {
+				sqlUser = strings.Replace(sqlUser, `\1`, match[1], -1)
+			}
+		}
+		if tree.Name(sqlUser).Normalize() == user {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSystemIdentity verifies that the system identity established by an
+// authentication method may connect as the requested user.
+func (c *v3Conn) checkSystemIdentity(mapName, systemIdentity string) error {
+	user := c.sessionArgs.User
+	if mapName == "" {
+		if tree.Name(systemIdentity).Normalize() != user {
+			return errors.Errorf("system identity %q cannot connect as user %s", systemIdentity, user)
+		}
+		return nil
+	}
+	m, err := parseIdentMap(c.identMap)
+	if err != nil {
+		return errors.Wrap(err, "invalid identity map")
+	}
+	if !m.allows(mapName, systemIdentity, user) {
+		return errors.Errorf("system identity %q cannot connect as user %s with map %q",
+			systemIdentity, user, mapName)
+	}
+	return nil
+}
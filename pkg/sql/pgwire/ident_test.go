@@ -0,0 +1,76 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func TestParseIdentMap(t *testing.T) {
+	testCases := []struct {
+		conf string
+		err  string
+	}{
+		{``, ``},
+		{"# comment\n\n", ``},
+		{`krb alice@EXAMPLE.COM alice # comment`, ``},
+		{`krb /^(.*)@EXAMPLE\.COM$ \1`, ``},
+		{`krb alice`, `line 1: expected 3 fields, found 2`},
+		{"krb a b\nkrb a b c", `line 2: expected 3 fields, found 4`},
+		{`krb /(.* \1`, `line 1: error parsing regexp`},
+	}
+	for _, tc := range testCases {
+		if _, err := parseIdentMap(tc.conf); !testutils.IsError(err, tc.err) {
+			t.Errorf("%q: expected error %q, got %v", tc.conf, tc.err, err)
+		}
+	}
+}
+
+func TestIdentMapAllows(t *testing.T) {
+	m, err := parseIdentMap(`
+# MAP  SYSTEM-IDENTITY             SQL-USER
+krb    alice@EXAMPLE.COM           Alice
+krb    alice@EXAMPLE.COM           root
+krb    /^(.*)@CORP\.EXAMPLE\.COM$  \1
+other  bob@EXAMPLE.COM             bob
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		mapName        string
+		systemIdentity string
+		user           string
+		expected       bool
+	}{
+		{"krb", "alice@EXAMPLE.COM", "alice", true},
+		{"krb", "alice@EXAMPLE.COM", "root", true},
+		{"krb", "alice@EXAMPLE.COM", "bob", false},
+		{"krb", "bob@CORP.EXAMPLE.COM", "bob", true},
+		{"krb", "Carol@CORP.EXAMPLE.COM", "carol", true},
+		{"krb", "bob@EXAMPLE.COM", "bob", false},
+		{"other", "bob@EXAMPLE.COM", "bob", true},
+		{"other", "alice@EXAMPLE.COM", "alice", false},
+		{"missing", "bob@EXAMPLE.COM", "bob", false},
+	}
+	for _, tc := range testCases {
+		if allowed := m.allows(tc.mapName, tc.systemIdentity, tc.user); allowed != tc.expected {
+			t.Errorf("%s: %q as %s: expected %t, got %t",
+				tc.mapName, tc.systemIdentity, tc.user, tc.expected, allowed)
+		}
+	}
+}
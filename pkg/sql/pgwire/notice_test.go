@@ -0,0 +1,84 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestPGWireNotices(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{Insecure: true})
+	defer s.Stopper().Stop(context.TODO())
+
+	c, err := dialRaw(s.ServingAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.conn.Close()
+
+	if err := c.query(
+		"CREATE DATABASE d; DROP TABLE IF EXISTS d.foo; DROP DATABASE IF EXISTS e",
+	); err != nil {
+		t.Fatal(err)
+	}
+	var types []byte
+	var notices []string
+	for {
+		typ, payload, err := c.recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ == 'Z' {
+			break
+		}
+		types = append(types, typ)
+		switch typ {
+		case 'N':
+			if severity := errorField(payload, 'S'); severity != "NOTICE" {
+				t.Errorf("expected severity NOTICE, got %q", severity)
+			}
+			if code := errorField(payload, 'C'); code != "00000" {
+				t.Errorf("expected code 00000, got %q", code)
+			}
+			notices = append(notices, errorField(payload, 'M'))
+		case 'E':
+			t.Fatalf("unexpected error: %q", payload)
+		}
+	}
+	// The notices of each statement precede its CommandComplete.
+	if e, a := "CNCNC", string(types); e != a {
+		t.Fatalf("expected messages %q, got %q", e, a)
+	}
+	expected := []string{
+		`table "d.foo" does not exist, skipping`,
+		`database "e" does not exist, skipping`,
+	}
+	if len(notices) != len(expected) {
+		t.Fatalf("expected notices %q, got %q", expected, notices)
+	}
+	for i := range expected {
+		if notices[i] != expected[i] {
+			t.Errorf("%d: expected notice %q, got %q", i, expected[i], notices[i])
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// recvNotification waits for the next NotificationResponse on c and returns
+// its process ID, channel and payload.
+func (c *rawConn) recvNotification(t *testing.T) (int32, string, string) {
+	if err := c.conn.SetReadDeadline(time.Now().Add(45 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		typ, payload, err := c.recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != 'A' {
+			continue
+		}
+		pid := int32(binary.BigEndian.Uint32(payload))
+		fields := bytes.Split(payload[4:], []byte{0})
+		if len(fields) != 3 {
+			t.Fatalf("malformed notification: %q", payload)
+		}
+		return pid, string(fields[0]), string(fields[1])
+	}
+}
+
+func TestPGWireNotifications(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tc := serverutils.StartTestCluster(t, 2, /* numNodes */
+		base.TestClusterArgs{
+			ReplicationMode: base.ReplicationManual,
+			ServerArgs:      base.TestServerArgs{Insecure: true},
+		})
+	defer tc.Stopper().Stop(context.TODO())
+
+	// The listener is connected to the second node and the notifications are
+	// sent from the first one.
+	c, err := dialRaw(tc.Server(1).ServingAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = c.conn.Close() }()
+	if err := c.query("LISTEN cache"); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		typ, payload, err := c.recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ == 'E' {
+			t.Fatalf("LISTEN failed: %q", payload)
+		}
+		if typ == 'Z' {
+			break
+		}
+	}
+
+	db := tc.ServerConn(0)
+	if _, err := db.Exec(`NOTIFY other, 'ignored'; NOTIFY cache, 'users'`); err != nil {
+		t.Fatal(err)
+	}
+	// The listener is idle, so the notification is delivered without it
+	// sending a query.
+	pid, channel, payload := c.recvNotification(t)
+	if expected := int32(tc.Server(0).NodeID()); pid != expected {
+		t.Errorf("expected process ID %d, got %d", expected, pid)
+	}
+	if channel != "cache" || payload != "users" {
+		t.Errorf("unexpected notification on %q: %q", channel, payload)
+	}
+
+	// Notifications of a transaction that rolls back are discarded, and
+	// duplicates within a transaction are only delivered once.
+	if _, err := db.Exec(`BEGIN; NOTIFY cache, 'dropped'; ROLLBACK`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(
+		`BEGIN; NOTIFY cache, 'a'; NOTIFY cache, 'a'; NOTIFY cache, 'b'; COMMIT`,
+	); err != nil {
+		t.Fatal(err)
+	}
+	received := make(map[string]bool)
+	for len(received) < 2 {
+		_, channel, payload := c.recvNotification(t)
+		if channel != "cache" || (payload != "a" && payload != "b") || received[payload] {
+			t.Fatalf("unexpected notification on %q: %q", channel, payload)
+		}
+		received[payload] = true
+	}
+}
@@ -43,15 +43,21 @@ func NewErrorf(code string, format string, args ...interface{}) *Error {
 	}
 }
 
-// NewDangerousStatementErrorf creates a new Error for "rejected dangerous statements".
+// NewDangerousStatementErrorf creates a new Error for "rejected dangerous
+// statements". The error carries a hint explaining how to run the statement
+// anyway.
 func NewDangerousStatementErrorf(format string, args ...interface{}) *Error {
 	var buf bytes.Buffer
 	buf.WriteString("rejected: ")
 	fmt.Fprintf(&buf, format, args...)
 	buf.WriteString(" (sql_safe_updates = true)")
-	return NewError(CodeWarningError, buf.String())
+	return NewError(CodeWarningError, buf.String()).SetHintf(dangerousStatementHint)
 }
 
+// dangerousStatementHint is the hint attached to errors for rejected
+// dangerous statements.
+const dangerousStatementHint = "to run the statement anyway, first use SET sql_safe_updates = false"
+
 // SetHintf annotates an Error object with a hint.
 func (pg *Error) SetHintf(f string, args ...interface{}) *Error {
 	pg.Hint = fmt.Sprintf(f, args...)
@@ -66,3 +66,17 @@ func TestPGError(t *testing.T) {
 	}
 	checkErr(pErr, expected)
 }
+
+func TestDangerousStatementError(t *testing.T) {
+	pErr := NewDangerousStatementErrorf("DELETE without WHERE clause")
+	const msg = "rejected: DELETE without WHERE clause (sql_safe_updates = true)"
+	if pErr.Message != msg {
+		t.Fatalf("got: %q\nwant: %q", pErr.Message, msg)
+	}
+	if pErr.Code != CodeWarningError {
+		t.Fatalf("got: %q\nwant: %q", pErr.Code, CodeWarningError)
+	}
+	if pErr.Hint != dangerousStatementHint {
+		t.Fatalf("got: %q\nwant: %q", pErr.Hint, dangerousStatementHint)
+	}
+}
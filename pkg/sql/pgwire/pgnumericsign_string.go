@@ -7,11 +7,13 @@ import "fmt"
 const (
 	_pgNumericSign_name_0 = "pgNumericPos"
 	_pgNumericSign_name_1 = "pgNumericNeg"
+	_pgNumericSign_name_2 = "pgNumericNaN"
 )
 
 var (
 	_pgNumericSign_index_0 = [...]uint8{0, 12}
 	_pgNumericSign_index_1 = [...]uint8{0, 12}
+	_pgNumericSign_index_2 = [...]uint8{0, 12}
 )
 
 func (i pgNumericSign) String() string {
@@ -20,6 +22,8 @@ func (i pgNumericSign) String() string {
 		return _pgNumericSign_name_0
 	case i == 16384:
 		return _pgNumericSign_name_1
+	case i == 49152:
+		return _pgNumericSign_name_2
 	default:
 		return fmt.Sprintf("pgNumericSign(%d)", i)
 	}
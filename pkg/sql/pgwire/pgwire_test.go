@@ -23,6 +23,7 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"os/user"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -39,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
@@ -411,6 +413,76 @@ func TestPGUnwrapError(t *testing.T) {
 	}
 }
 
+// TestPGErrorFields checks that the errors for violations of integrity
+// constraints carry the table, column and constraint involved, like the
+// errors of postgres.
+func TestPGErrorFields(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := db.Exec(`
+CREATE DATABASE d;
+CREATE TABLE d.t (
+  a INT PRIMARY KEY,
+  b INT NOT NULL,
+  c INT,
+  CONSTRAINT t_b_key UNIQUE (b),
+  CONSTRAINT t_c_positive CHECK (c > 0)
+);
+CREATE TABLE d.u (x INT, INDEX (x), CONSTRAINT u_x_fk FOREIGN KEY (x) REFERENCES d.t);
+INSERT INTO d.t VALUES (1, 1, 1);
+INSERT INTO d.u VALUES (1);
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		stmt       string
+		code       pq.ErrorCode
+		table      string
+		column     string
+		constraint string
+		detail     string
+	}{
+		{`INSERT INTO d.t VALUES (2, 1, 1)`, pgerror.CodeUniqueViolationError,
+			"t", "", "t_b_key", "Key (b)=(1) already exists."},
+		{`INSERT INTO d.t VALUES (2, NULL, 1)`, pgerror.CodeNotNullViolationError,
+			"t", "b", "", ""},
+		{`INSERT INTO d.t VALUES (2, 2, 0)`, pgerror.CodeCheckViolationError,
+			"t", "", "t_c_positive", ""},
+		{`INSERT INTO d.u VALUES (2)`, pgerror.CodeForeignKeyViolationError,
+			"u", "", "u_x_fk", ""},
+		{`DELETE FROM d.t WHERE a = 1`, pgerror.CodeForeignKeyViolationError,
+			"u", "", "u_x_fk", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.stmt, func(t *testing.T) {
+			_, err := db.Exec(tc.stmt)
+			pqErr, ok := err.(*pq.Error)
+			if !ok {
+				t.Fatalf("expected a pq.Error, got %v", err)
+			}
+			if pqErr.Code != tc.code {
+				t.Errorf("expected code %s, got %s", tc.code, pqErr.Code)
+			}
+			if pqErr.Table != tc.table {
+				t.Errorf("expected table %q, got %q", tc.table, pqErr.Table)
+			}
+			if pqErr.Column != tc.column {
+				t.Errorf("expected column %q, got %q", tc.column, pqErr.Column)
+			}
+			if pqErr.Constraint != tc.constraint {
+				t.Errorf("expected constraint %q, got %q", tc.constraint, pqErr.Constraint)
+			}
+			if pqErr.Detail != tc.detail {
+				t.Errorf("expected detail %q, got %q", tc.detail, pqErr.Detail)
+			}
+		})
+	}
+}
+
 func TestPGPrepareFail(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -1197,6 +1269,12 @@ func TestPGPreparedExec(t *testing.T) {
 				baseTest.SetArgs("01").Error("pq: could not cancel query 00000000000000000000000000000001: query ID 00000000000000000000000000000001 not found"),
 			},
 		},
+		{
+			"CANCEL SESSION $1",
+			[]preparedExecTest{
+				baseTest.SetArgs(1).Error("pq: session ID 1 not found"),
+			},
+		},
 		// An empty string is valid in postgres.
 		{
 			"",
@@ -1609,6 +1687,72 @@ func TestPGWireOverUnixSocket(t *testing.T) {
 	}
 }
 
+func TestPGWirePeerAuthOverUnixSocket(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	if runtime.GOOS != "linux" {
+		t.Skip("peer authentication is only supported on linux")
+	}
+
+	osUser, err := user.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tempDir, err := ioutil.TempDir("", "PGSQL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	const port = "6"
+
+	// Secure nodes accept connections without SSL over the Unix socket.
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		SocketFile: filepath.Join(tempDir, ".s.PGSQL."+port),
+	})
+	defer s.Stopper().Stop(context.TODO())
+
+	for _, stmt := range []string{
+		fmt.Sprintf("CREATE USER %s", server.TestUser),
+		"CREATE USER foo",
+		fmt.Sprintf(`SET CLUSTER SETTING server.identity_map.configuration = 'local %s %s'`,
+			osUser.Username, server.TestUser),
+		`SET CLUSTER SETTING server.host_based_authentication.configuration = ` +
+			`e'host all root all cert\nlocal all all peer map=local'`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec(
+		`SET CLUSTER SETTING server.host_based_authentication.configuration = 'host all all all peer'`,
+	); !testutils.IsError(err, `authentication method "peer" is only valid in local entries`) {
+		t.Fatalf("expected invalid configuration error, got %v", err)
+	}
+
+	pgURL := func(username string) url.URL {
+		return url.URL{
+			Scheme:   "postgres",
+			User:     url.User(username),
+			Host:     net.JoinHostPort("", port),
+			RawQuery: url.Values{"host": []string{tempDir}, "sslmode": []string{"disable"}}.Encode(),
+		}
+	}
+
+	// The configuration is picked up once the settings have propagated.
+	testutils.SucceedsSoon(t, func() error {
+		return trivialQuery(pgURL(server.TestUser))
+	})
+
+	// The operating system user isn't mapped to other users.
+	if err := trivialQuery(pgURL("foo")); !testutils.IsError(err,
+		fmt.Sprintf(`system identity %q cannot connect as user foo`, osUser.Username),
+	) {
+		t.Fatalf("expected peer authentication to fail, got %v", err)
+	}
+}
+
 func TestPGWireAuth(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -1696,6 +1840,216 @@ func TestPGWireAuth(t *testing.T) {
 	})
 }
 
+func TestPGWireHostBasedAuth(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	for _, stmt := range []string{
+		fmt.Sprintf("CREATE USER %s", server.TestUser),
+		"CREATE USER foo WITH PASSWORD 'bar'",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := db.Exec(
+		`SET CLUSTER SETTING server.host_based_authentication.configuration = 'host all all all md5'`,
+	); !testutils.IsError(err, `unknown authentication method "md5"`) {
+		t.Fatalf("expected invalid configuration error, got %v", err)
+	}
+	if _, err := db.Exec(`SET CLUSTER SETTING server.host_based_authentication.configuration = ` +
+		`e'host all root all cert\nhost all testuser all trust\nhost all all all reject'`,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	host, port, err := net.SplitHostPort(s.ServingAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pgURL := func(user *url.Userinfo) url.URL {
+		return url.URL{
+			Scheme:   "postgres",
+			User:     user,
+			Host:     net.JoinHostPort(host, port),
+			RawQuery: "sslmode=require",
+		}
+	}
+
+	// The configuration is picked up once the setting has propagated.
+	testutils.SucceedsSoon(t, func() error {
+		return trivialQuery(pgURL(url.User(server.TestUser)))
+	})
+
+	// Root still authenticates with its certificate.
+	rootPgURL, cleanupFn := sqlutils.PGUrl(t, s.ServingAddr(), t.Name(), url.User(security.RootUser))
+	defer cleanupFn()
+	if err := trivialQuery(rootPgURL); err != nil {
+		t.Fatal(err)
+	}
+
+	// Other users are rejected, even with the right password.
+	err = trivialQuery(pgURL(url.UserPassword("foo", "bar")))
+	if pqErr, ok := err.(*pq.Error); !ok || pqErr.Code != "28000" {
+		t.Fatalf("expected authentication to be rejected, got %v", err)
+	}
+}
+
+func TestPGWireConnectionLimits(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := db.Exec(fmt.Sprintf("CREATE USER %s CONNECTION LIMIT 1", server.TestUser)); err != nil {
+		t.Fatal(err)
+	}
+
+	testUserPgURL, cleanupFn := sqlutils.PGUrl(
+		t, s.ServingAddr(), t.Name(), url.User(server.TestUser))
+	defer cleanupFn()
+
+	// Hold a connection of testuser.
+	testUserDB, err := gosql.Open("postgres", testUserPgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testUserDB.Close()
+	conn, err := testUserDB.Conn(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecContext(context.TODO(), "SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another connection of testuser exceeds its limit.
+	err = trivialQuery(testUserPgURL)
+	if pqErr, ok := err.(*pq.Error); !ok || pqErr.Code != pgerror.CodeTooManyConnectionsError {
+		t.Fatalf("expected too many connections error, got %v", err)
+	}
+
+	// Root is never limited.
+	if _, err := db.Exec(`SET CLUSTER SETTING server.max_connections = 1`); err != nil {
+		t.Fatal(err)
+	}
+	rootPgURL, cleanupFn := sqlutils.PGUrl(t, s.ServingAddr(), t.Name(), url.User(security.RootUser))
+	defer cleanupFn()
+	if err := trivialQuery(rootPgURL); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the connection is closed, testuser can connect again.
+	if _, err := db.Exec(`SET CLUSTER SETTING server.max_connections = DEFAULT`); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := testUserDB.Close(); err != nil {
+		t.Fatal(err)
+	}
+	testutils.SucceedsSoon(t, func() error {
+		return trivialQuery(testUserPgURL)
+	})
+}
+
+func TestPGWireNoLogin(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	// Roles can't log in unless created with LOGIN.
+	if _, err := db.Exec(fmt.Sprintf("CREATE ROLE %s", server.TestUser)); err != nil {
+		t.Fatal(err)
+	}
+
+	testUserPgURL, cleanupFn := sqlutils.PGUrl(
+		t, s.ServingAddr(), t.Name(), url.User(server.TestUser))
+	defer cleanupFn()
+
+	err := trivialQuery(testUserPgURL)
+	if pqErr, ok := err.(*pq.Error); !ok ||
+		pqErr.Code != pgerror.CodeInvalidAuthorizationSpecificationError {
+		t.Fatalf("expected invalid authorization error, got %v", err)
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("DROP ROLE %s", server.TestUser)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(fmt.Sprintf("CREATE ROLE %s LOGIN", server.TestUser)); err != nil {
+		t.Fatal(err)
+	}
+	if err := trivialQuery(testUserPgURL); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPGWirePasswordPolicies(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := db.Exec(`SET CLUSTER SETTING server.user_login.bcrypt_cost = 4`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE USER pwuser WITH PASSWORD 'pencil'`); err != nil {
+		t.Fatal(err)
+	}
+	checkCost := func(expected int) {
+		t.Helper()
+		var hashedPassword []byte
+		if err := db.QueryRow(
+			`SELECT "hashedPassword" FROM system.users WHERE username = 'pwuser'`,
+		).Scan(&hashedPassword); err != nil {
+			t.Fatal(err)
+		}
+		cost, err := security.BcryptCost(hashedPassword)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cost != expected {
+			t.Fatalf("expected a bcrypt cost of %d, got %d", expected, cost)
+		}
+	}
+	login := func() error {
+		c, err := dialRawTLS(s.ServingAddr(), "pwuser", cleartextClient("pencil"))
+		if err != nil {
+			return err
+		}
+		return c.conn.Close()
+	}
+	checkCost(4)
+
+	// Once the cost is raised, the password is rehashed when its user logs in.
+	if _, err := db.Exec(`SET CLUSTER SETTING server.user_login.bcrypt_cost = 5`); err != nil {
+		t.Fatal(err)
+	}
+	if err := login(); err != nil {
+		t.Fatal(err)
+	}
+	checkCost(5)
+
+	// Expired passwords don't authenticate their users.
+	if _, err := db.Exec(`ALTER USER pwuser VALID UNTIL '2000-01-01'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := login(); !testutils.IsError(err, "password of user pwuser has expired") {
+		t.Fatalf("expected password expired error, got %v", err)
+	}
+	if _, err := db.Exec(`ALTER USER pwuser VALID UNTIL '2100-01-01'`); err != nil {
+		t.Fatal(err)
+	}
+	if err := login(); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestPGWireResultChange(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
@@ -0,0 +1,118 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire
+
+import (
+	"bytes"
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql"
+)
+
+// suspendedResult holds the rows of a portal's result that exceeded the row
+// count limit of the Execute message that ran the portal. The executor can't
+// pause a statement, so the first execution of a portal runs its statement to
+// completion and keeps the DataRow messages that the client hasn't asked for
+// yet; the following Execute messages for the portal send them until the
+// result is exhausted.
+// See: https://www.postgresql.org/docs/current/static/protocol-flow.html#PROTOCOL-FLOW-EXT-QUERY
+type suspendedResult struct {
+	pgTag string
+	// rows holds the encoded DataRow messages. ends[i] is the offset in rows at
+	// which the i-th message ends.
+	rows bytes.Buffer
+	ends []int
+	// sent is the number of messages already sent to the client.
+	sent int
+	// memUsage is the memory accounted for on the portal.
+	memUsage int64
+}
+
+// suspendRow moves the DataRow message that has just been written to
+// c.writeBuf to the suspended result of the current portal.
+func (c *v3Conn) suspendRow(ctx context.Context) error {
+	state := &c.streamingState
+	if state.suspended == nil {
+		state.suspended = &suspendedResult{}
+	}
+	s := state.suspended
+	before := s.rows.Len()
+	if err := c.writeBuf.finishMsg(&s.rows); err != nil {
+		return err
+	}
+	s.ends = append(s.ends, s.rows.Len())
+	// Account for the message and its offset.
+	sz := int64(s.rows.Len()-before) + 8
+	if err := state.portal.GrowMemory(ctx, c.session, sz); err != nil {
+		return err
+	}
+	s.memUsage += sz
+	return nil
+}
+
+// discardSuspended releases the rows of a result that's not going to be sent.
+func (c *v3Conn) discardSuspended() {
+	state := &c.streamingState
+	if state.suspended == nil {
+		return
+	}
+	state.portal.ShrinkMemory(c.session.Ctx(), c.session, state.suspended.memUsage)
+	state.suspended = nil
+}
+
+func (c *v3Conn) sendPortalSuspended(buf *bytes.Buffer) error {
+	c.writeBuf.initMsg(serverMsgPortalSuspended)
+	return c.writeBuf.finishMsg(buf)
+}
+
+// resumePortal serves an Execute message for a portal with a suspended result.
+// Up to limit rows are sent (all the remaining rows if limit is 0), followed
+// by PortalSuspended if rows remain and CommandComplete otherwise.
+func (c *v3Conn) resumePortal(
+	ctx context.Context, portal *sql.PreparedPortal, portalMeta *preparedPortalMeta, limit int,
+) error {
+	s := portalMeta.suspended
+	n := len(s.ends) - s.sent
+	if limit != 0 && limit < n {
+		n = limit
+	}
+	if n > 0 {
+		start := 0
+		if s.sent > 0 {
+			start = s.ends[s.sent-1]
+		}
+		c.streamingState.buf.Write(s.rows.Bytes()[start:s.ends[s.sent+n-1]])
+		s.sent += n
+	}
+	if s.sent < len(s.ends) {
+		if err := c.sendPortalSuspended(&c.streamingState.buf); err != nil {
+			return err
+		}
+		return c.done()
+	}
+
+	portalMeta.suspended = nil
+	portal.ShrinkMemory(ctx, c.session, s.memUsage)
+	// As in Postgres, the tag counts the rows sent by this execution.
+	tag := append(c.tagBuf[:0], s.pgTag...)
+	tag = append(tag, ' ')
+	tag = strconv.AppendInt(tag, int64(n), 10)
+	if err := c.sendCommandComplete(tag, &c.streamingState.buf); err != nil {
+		return err
+	}
+	return c.done()
+}
@@ -0,0 +1,102 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// execute sends an Execute message for portal followed by a Sync and returns
+// the types of the messages received up to ReadyForQuery, along with the tag
+// of the CommandComplete message if there was one.
+func (c *rawConn) execute(t *testing.T, portal string, limit uint32) (string, string) {
+	payload := append([]byte(portal), 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(payload[len(portal)+1:], limit)
+	if err := c.send('E', payload); err != nil {
+		t.Fatal(err)
+	}
+	return c.sync(t)
+}
+
+func (c *rawConn) sync(t *testing.T) (string, string) {
+	if err := c.send('S', nil); err != nil {
+		t.Fatal(err)
+	}
+	var types []byte
+	var tag string
+	for {
+		typ, payload, err := c.recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch typ {
+		case 'Z':
+			return string(types), tag
+		case 'C':
+			tag = string(payload[:len(payload)-1])
+		case 'E':
+			t.Fatalf("unexpected error: %q", payload)
+		}
+		types = append(types, typ)
+	}
+}
+
+func TestPGWireExecuteRowLimit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{Insecure: true})
+	defer s.Stopper().Stop(context.TODO())
+
+	c, err := dialRaw(s.ServingAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.conn.Close()
+
+	// Parse and bind SELECT * FROM generate_series(1, 10) to portal p.
+	if err := c.send('P', []byte("\x00SELECT * FROM generate_series(1, 10)\x00\x00\x00")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.send('B', []byte("p\x00\x00\x00\x00\x00\x00\x00\x00")); err != nil {
+		t.Fatal(err)
+	}
+	if types, _ := c.sync(t); types != "12" {
+		t.Fatalf("expected ParseComplete and BindComplete, got %q", types)
+	}
+
+	testCases := []struct {
+		limit         uint32
+		expectedTypes string
+		expectedTag   string
+	}{
+		{3, "DDDs", ""},
+		{5, "DDDDDs", ""},
+		{0, "DDC", "SELECT 2"},
+	}
+	for _, tc := range testCases {
+		types, tag := c.execute(t, "p", tc.limit)
+		if types != tc.expectedTypes || tag != tc.expectedTag {
+			t.Fatalf("limit %d: expected %q (tag %q), got %q (tag %q)",
+				tc.limit, tc.expectedTypes, tc.expectedTag, types, tag)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+)
+
+// rawConn is a minimal client of the v3 protocol, used to exercise the parts
+// of the protocol that drivers don't expose.
+type rawConn struct {
+	conn net.Conn
+	rd   *bufio.Reader
+	// key is the contents of the BackendKeyData message.
+	key []byte
+}
+
+func dialRaw(addr string) (*rawConn, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return startRaw(conn, security.RootUser, nil /* auth */)
+}
+
+// startRaw sends the startup message for user on conn and waits for the
+// server to be ready for queries. The payloads of the authentication
+// requests sent by the server are passed to auth.
+func startRaw(
+	conn net.Conn, user string, auth func(c *rawConn, payload []byte) error,
+) (*rawConn, error) {
+	c := &rawConn{conn: conn, rd: bufio.NewReader(conn)}
+	var startup bytes.Buffer
+	startup.Write([]byte{0, 0, 0, 0, 0, 3, 0, 0})
+	startup.WriteString("user\x00" + user + "\x00\x00")
+	msg := startup.Bytes()
+	binary.BigEndian.PutUint32(msg, uint32(len(msg)))
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+	for {
+		typ, payload, err := c.recv()
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case 'R':
+			if auth != nil {
+				if err := auth(c, payload); err != nil {
+					return nil, err
+				}
+			}
+		case 'K':
+			c.key = payload
+		case 'E':
+			return nil, errors.Errorf("error during startup: %q", payload)
+		case 'Z':
+			if c.key == nil {
+				return nil, errors.New("no BackendKeyData received")
+			}
+			return c, nil
+		}
+	}
+}
+
+func (c *rawConn) send(typ byte, payload []byte) error {
+	msg := make([]byte, 5, 5+len(payload))
+	msg[0] = typ
+	binary.BigEndian.PutUint32(msg[1:], uint32(4+len(payload)))
+	_, err := c.conn.Write(append(msg, payload...))
+	return err
+}
+
+func (c *rawConn) query(sql string) error {
+	return c.send('Q', append([]byte(sql), 0))
+}
+
+func (c *rawConn) recv() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(c.rd, header[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(header[1:])-4)
+	if _, err := io.ReadFull(c.rd, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[0], payload, nil
+}
+
+// errorField extracts a field from the payload of an ErrorResponse.
+func errorField(payload []byte, field byte) string {
+	for len(payload) > 1 {
+		end := bytes.IndexByte(payload[1:], 0)
+		if end < 0 {
+			break
+		}
+		if payload[0] == field {
+			return string(payload[1 : 1+end])
+		}
+		payload = payload[end+2:]
+	}
+	return ""
+}
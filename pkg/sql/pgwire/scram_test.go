@@ -0,0 +1,219 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package pgwire_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// dialRawTLS opens a connection to addr, upgrades it to TLS and starts a
+// session for user.
+func dialRawTLS(
+	addr, user string, auth func(c *rawConn, payload []byte) error,
+) (*rawConn, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	// SSLRequest.
+	if _, err := conn.Write([]byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}); err != nil {
+		return nil, err
+	}
+	var resp [1]byte
+	if _, err := conn.Read(resp[:]); err != nil {
+		return nil, err
+	}
+	if resp[0] != 'S' {
+		return nil, errors.Errorf("server refused TLS: %q", resp[0])
+	}
+	tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true})
+	return startRaw(tlsConn, user, auth)
+}
+
+func scramHMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// cleartextClient returns an authentication handler sending the given
+// password in cleartext.
+func cleartextClient(password string) func(c *rawConn, payload []byte) error {
+	return func(c *rawConn, payload []byte) error {
+		switch code := binary.BigEndian.Uint32(payload); code {
+		case 3: // AuthenticationCleartextPassword
+			return c.send('p', []byte(password+"\x00"))
+		case 0:
+			return nil
+		default:
+			return errors.Errorf("unexpected authentication request %d", code)
+		}
+	}
+}
+
+// scramClient returns an authentication handler performing the client side
+// of a SCRAM-SHA-256 exchange with the given password.
+func scramClient(password string) func(c *rawConn, payload []byte) error {
+	const clientFirstBare = "n=,r=fyko+d2lbbFgONRv9qkxdawL"
+	var serverFirst string
+	var saltedPassword []byte
+	var authMessage string
+	return func(c *rawConn, payload []byte) error {
+		code := binary.BigEndian.Uint32(payload)
+		data := string(payload[4:])
+		switch code {
+		case 10: // AuthenticationSASL
+			if data != "SCRAM-SHA-256\x00\x00" {
+				return errors.Errorf("unexpected SASL mechanisms %q", data)
+			}
+			var msg bytes.Buffer
+			msg.WriteString("SCRAM-SHA-256\x00")
+			var n [4]byte
+			binary.BigEndian.PutUint32(n[:], uint32(len("n,,"+clientFirstBare)))
+			msg.Write(n[:])
+			msg.WriteString("n,," + clientFirstBare)
+			return c.send('p', msg.Bytes())
+
+		case 11: // AuthenticationSASLContinue
+			serverFirst = data
+			var nonce string
+			var salt []byte
+			iterations := 0
+			for _, attr := range strings.Split(serverFirst, ",") {
+				var err error
+				switch {
+				case strings.HasPrefix(attr, "r="):
+					nonce = attr[2:]
+				case strings.HasPrefix(attr, "s="):
+					salt, err = base64.StdEncoding.DecodeString(attr[2:])
+				case strings.HasPrefix(attr, "i="):
+					iterations, err = strconv.Atoi(attr[2:])
+				}
+				if err != nil {
+					return err
+				}
+			}
+			// SaltedPassword := Hi(password, salt, iterations).
+			u := scramHMAC([]byte(password), string(salt)+"\x00\x00\x00\x01")
+			saltedPassword = append([]byte(nil), u...)
+			for i := 1; i < iterations; i++ {
+				u = scramHMAC([]byte(password), string(u))
+				for j := range saltedPassword {
+					saltedPassword[j] ^= u[j]
+				}
+			}
+			clientKey := scramHMAC(saltedPassword, "Client Key")
+			storedKey := sha256.Sum256(clientKey)
+			withoutProof := "c=biws,r=" + nonce
+			authMessage = clientFirstBare + "," + serverFirst + "," + withoutProof
+			signature := scramHMAC(storedKey[:], authMessage)
+			for i := range clientKey {
+				clientKey[i] ^= signature[i]
+			}
+			return c.send('p', []byte(withoutProof+",p="+base64.StdEncoding.EncodeToString(clientKey)))
+
+		case 12: // AuthenticationSASLFinal
+			expected := "v=" + base64.StdEncoding.EncodeToString(
+				scramHMAC(scramHMAC(saltedPassword, "Server Key"), authMessage))
+			if data != expected {
+				return errors.Errorf("invalid server signature %q", data)
+			}
+			return nil
+
+		case 0: // AuthenticationOk
+			return nil
+		}
+		return errors.Errorf("unexpected authentication request %d", code)
+	}
+}
+
+func TestPGWireSCRAMAuth(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	// Passwords set before SCRAM is enabled keep being checked in cleartext.
+	if _, err := db.Exec(`CREATE USER bcrypt WITH PASSWORD 'pencil'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(
+		`SET CLUSTER SETTING server.user_login.password_encryption = 'scram-sha-256'`,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE USER scram WITH PASSWORD 'pencil'`); err != nil {
+		t.Fatal(err)
+	}
+	var hashedPassword []byte
+	if err := db.QueryRow(
+		`SELECT "hashedPassword" FROM system.users WHERE username = 'scram'`,
+	).Scan(&hashedPassword); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(hashedPassword, []byte("SCRAM-SHA-256$4096:")) {
+		t.Fatalf("expected a SCRAM verifier, got %q", hashedPassword)
+	}
+
+	t.Run("scram", func(t *testing.T) {
+		c, err := dialRawTLS(s.ServingAddr(), "scram", scramClient("pencil"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = c.conn.Close()
+
+		if _, err := dialRawTLS(
+			s.ServingAddr(), "scram", scramClient("pen"),
+		); !testutils.IsError(err, "invalid password") {
+			t.Fatalf("expected invalid password error, got %v", err)
+		}
+	})
+
+	t.Run("cleartext", func(t *testing.T) {
+		c, err := dialRawTLS(s.ServingAddr(), "bcrypt", cleartextClient("pencil"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = c.conn.Close()
+
+		// The bcrypt hash is weaker than the hashes of new passwords, so the
+		// password was rehashed into a SCRAM verifier.
+		if err := db.QueryRow(
+			`SELECT "hashedPassword" FROM system.users WHERE username = 'bcrypt'`,
+		).Scan(&hashedPassword); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(hashedPassword, []byte("SCRAM-SHA-256$4096:")) {
+			t.Fatalf("expected a SCRAM verifier, got %q", hashedPassword)
+		}
+	})
+}
@@ -23,6 +23,8 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -93,10 +95,13 @@ type cancelChanMap map[chan struct{}]context.CancelFunc
 type Server struct {
 	AmbientCtx log.AmbientContext
 	cfg        *base.Config
+	st         *cluster.Settings
 	executor   *sql.Executor
 
 	metrics ServerMetrics
 
+	cancelKeys cancelKeyRegistry
+
 	mu struct {
 		syncutil.Mutex
 		// connCancelMap entries represent connections started when the server
@@ -149,7 +154,10 @@ var noteworthyConnMemoryUsageBytes = envutil.EnvOrDefaultInt64("COCKROACH_NOTEWO
 func MakeServer(
 	ambientCtx log.AmbientContext,
 	cfg *base.Config,
+	st *cluster.Settings,
 	executor *sql.Executor,
+	nodeID *base.NodeIDContainer,
+	g *gossip.Gossip,
 	internalMemMetrics *sql.MemoryMetrics,
 	parentMemoryMonitor *mon.BytesMonitor,
 	histogramWindow time.Duration,
@@ -157,6 +165,7 @@ func MakeServer(
 	server := &Server{
 		AmbientCtx: ambientCtx,
 		cfg:        cfg,
+		st:         st,
 		executor:   executor,
 		metrics:    makeServerMetrics(internalMemMetrics, histogramWindow),
 	}
@@ -178,6 +187,8 @@ func MakeServer(
 	server.mu.connCancelMap = make(cancelChanMap)
 	server.mu.Unlock()
 
+	server.cancelKeys.init(nodeID, g)
+
 	return server
 }
 
@@ -192,7 +203,7 @@ func Match(rd io.Reader) bool {
 	if err != nil {
 		return false
 	}
-	return version == version30 || version == versionSSL
+	return version == version30 || version == versionSSL || version == versionCancel
 }
 
 // IsDraining returns true if the server is not currently accepting
@@ -308,6 +319,12 @@ func (s *Server) setDrainingImpl(
 	return nil
 }
 
+// isUnixSocketConn returns whether conn was accepted on a Unix socket.
+func isUnixSocketConn(conn net.Conn) bool {
+	_, ok := conn.LocalAddr().(*net.UnixAddr)
+	return ok
+}
+
 // ServeConn serves a single connection, driving the handshake process
 // and delegating to the appropriate connection type.
 func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
@@ -377,15 +394,22 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 		if err != nil {
 			return err
 		}
-	} else if !s.cfg.Insecure {
+	} else if !s.cfg.Insecure && !isUnixSocketConn(conn) {
 		errSSLRequired = true
 	}
 
+	if version == versionCancel {
+		// CancelRequests are accepted without SSL, as in PostgreSQL: their
+		// secret key is their only credential.
+		return s.cancelKeys.cancel(ctx, buf.msg)
+	}
+
 	if version == version30 {
 		// We make a connection before anything. If there is an error
 		// parsing the connection arguments, the connection will only be
 		// used to send a report of that error.
-		v3conn := makeV3Conn(conn, &s.metrics, &s.sqlMemoryPool, s.executor)
+		v3conn := makeV3Conn(conn, s.st, &s.metrics, &s.sqlMemoryPool, s.executor)
+		v3conn.cancelKeys = &s.cancelKeys
 		defer v3conn.finish(ctx)
 
 		if v3conn.sessionArgs, err = parseOptions(ctx, buf.msg); err != nil {
@@ -401,8 +425,44 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 
 		v3conn.sessionArgs.User = tree.Name(v3conn.sessionArgs.User).Normalize()
 		if err := v3conn.handleAuthentication(ctx, s.cfg.Insecure); err != nil {
-			return v3conn.sendError(pgerror.NewError(pgerror.CodeInvalidPasswordError, err.Error()))
+			if _, ok := pgerror.GetPGCause(err); !ok {
+				err = pgerror.NewError(pgerror.CodeInvalidPasswordError, err.Error())
+			}
+			return v3conn.sendError(err)
+		}
+
+		// The login options, such as the connection limits, are only enforced
+		// once the client has authenticated, lest unauthenticated clients use
+		// up the connections of a user.
+		canLogin, userLimit, err := sql.GetUserLoginOptions(
+			ctx, s.executor, s.metrics.internalMemMetrics, v3conn.sessionArgs.User,
+		)
+		if err != nil {
+			return v3conn.sendError(err)
+		}
+		if !canLogin {
+			return v3conn.sendError(pgerror.NewErrorf(pgerror.CodeInvalidAuthorizationSpecificationError,
+				"role %s is not permitted to log in", v3conn.sessionArgs.User))
+		}
+		v3conn.sessionArgs.StatementRestrictions, err = sql.GetUserStatementRestrictions(
+			ctx, s.executor, s.metrics.internalMemMetrics, v3conn.sessionArgs.User,
+		)
+		if err != nil {
+			return v3conn.sendError(err)
+		}
+		v3conn.sessionArgs.RoleQuotas, err = sql.GetUserRoleQuotas(
+			ctx, s.executor, s.metrics.internalMemMetrics, v3conn.sessionArgs.User,
+		)
+		if err != nil {
+			return v3conn.sendError(err)
+		}
+		releaseConn, err := s.executor.Cfg().SessionRegistry.ReserveConnection(
+			&s.st.SV, v3conn.sessionArgs.User, userLimit,
+		)
+		if err != nil {
+			return v3conn.sendError(err)
 		}
+		defer releaseConn()
 
 		// Reserve some memory for this connection using the server's
 		// monitor. This reduces pressure on the shared pool because the
@@ -419,7 +479,7 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 				baseSQLMemoryBudget, err)
 		}
 
-		err := v3conn.serve(ctx, s.IsDraining, acc)
+		err = v3conn.serve(ctx, s.IsDraining, acc)
 		// If the error that closed the connection is related to an
 		// administrative shutdown, relay that information to the client.
 		if pgErr, ok := pgerror.GetPGCause(err); ok && pgErr.Code == pgerror.CodeAdminShutdownError {
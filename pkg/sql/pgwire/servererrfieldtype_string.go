@@ -10,6 +10,9 @@ const (
 	_serverErrFieldType_name_2 = "serverErrFileldHint"
 	_serverErrFieldType_name_3 = "serverErrFieldSrcLineserverErrFieldMsgPrimary"
 	_serverErrFieldType_name_4 = "serverErrFieldSrcFunctionserverErrFieldSeverity"
+	_serverErrFieldType_name_5 = "serverErrFieldColumnName"
+	_serverErrFieldType_name_6 = "serverErrFieldConstraintName"
+	_serverErrFieldType_name_7 = "serverErrFieldTableName"
 )
 
 var (
@@ -18,6 +21,9 @@ var (
 	_serverErrFieldType_index_2 = [...]uint8{0, 19}
 	_serverErrFieldType_index_3 = [...]uint8{0, 21, 45}
 	_serverErrFieldType_index_4 = [...]uint8{0, 25, 47}
+	_serverErrFieldType_index_5 = [...]uint8{0, 24}
+	_serverErrFieldType_index_6 = [...]uint8{0, 28}
+	_serverErrFieldType_index_7 = [...]uint8{0, 23}
 )
 
 func (i serverErrFieldType) String() string {
@@ -35,6 +41,12 @@ func (i serverErrFieldType) String() string {
 	case 82 <= i && i <= 83:
 		i -= 82
 		return _serverErrFieldType_name_4[_serverErrFieldType_index_4[i]:_serverErrFieldType_index_4[i+1]]
+	case i == 99:
+		return _serverErrFieldType_name_5
+	case i == 110:
+		return _serverErrFieldType_name_6
+	case i == 116:
+		return _serverErrFieldType_name_7
 	default:
 		return fmt.Sprintf("serverErrFieldType(%d)", i)
 	}
@@ -28,6 +28,7 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -71,12 +72,15 @@ type pgNumericSign uint16
 const (
 	pgNumericPos pgNumericSign = 0x0000
 	pgNumericNeg pgNumericSign = 0x4000
-	// pgNumericNan pgNumericSign = 0xC000
+	pgNumericNaN pgNumericSign = 0xC000
 )
 
 // The number of decimal digits per int16 Postgres "digit".
 const pgDecDigits = 4
 
+// The version number prefixed to the binary format of JSONB values.
+const pgJSONBVersion = 1
+
 type pgNumeric struct {
 	ndigits, weight, dscale int16
 	sign                    pgNumericSign
@@ -255,6 +259,20 @@ func (b *writeBuffer) writeBinaryDatum(
 		b.putInt64(int64(math.Float64bits(float64(*v))))
 
 	case *tree.DDecimal:
+		switch v.Form {
+		case apd.NaN, apd.NaNSignaling:
+			b.putInt32(8)
+			b.putInt16(0)
+			b.putInt16(0)
+			// -16384 is 0xC000 (NUMERIC_NAN) as an int16.
+			b.putInt16(int16(-16384))
+			b.putInt16(0)
+			return
+		case apd.Infinite:
+			b.setError(errors.New("unsupported binary serialization of infinite decimal"))
+			return
+		}
+
 		alloc := struct {
 			pgNum pgNumeric
 
@@ -384,25 +402,66 @@ func (b *writeBuffer) writeBinaryDatum(
 		b.putInt32(8)
 		b.putInt64(int64(*v))
 
+	case *tree.DInterval:
+		b.putInt32(16)
+		b.putInt64(v.Nanos / int64(time.Microsecond))
+		b.putInt32(int32(v.Days))
+		b.putInt32(int32(v.Months))
+
+	case *tree.DJSON:
+		// JSONB is sent as its text representation prefixed with a version
+		// byte.
+		b.variablePutbuf.WriteByte(pgJSONBVersion)
+		b.variablePutbuf.WriteString(v.JSON.String())
+		b.writeLengthPrefixedVariablePutbuf()
+
+	case *tree.DTuple:
+		// Tuples are sent as anonymous records: the number of fields followed
+		// by the type OID and length-prefixed value of each field.
+		subWriter := &writeBuffer{wrapped: b.variablePutbuf}
+		subWriter.putInt32(int32(len(v.D)))
+		for _, elem := range v.D {
+			subWriter.putInt32(int32(elem.ResolvedType().Oid()))
+			subWriter.writeBinaryDatum(ctx, elem, sessionLoc)
+		}
+		if subWriter.err != nil {
+			b.setError(subWriter.err)
+			return
+		}
+		b.variablePutbuf = subWriter.wrapped
+		b.writeLengthPrefixedVariablePutbuf()
+
 	case *tree.DArray:
 		if v.ParamTyp.FamilyEqual(types.AnyArray) {
 			b.setError(errors.New("unsupported binary serialization of multidimensional arrays"))
 			return
 		}
 		subWriter := &writeBuffer{wrapped: b.variablePutbuf}
-		// Put the number of dimensions. We currently support 1d arrays only.
-		subWriter.putInt32(1)
+		// Put the number of dimensions. We currently support 1d arrays only;
+		// like Postgres, empty arrays have no dimensions at all.
+		ndims := int32(1)
+		if v.Len() == 0 {
+			ndims = 0
+		}
+		subWriter.putInt32(ndims)
 		hasNulls := 0
 		if v.HasNulls {
 			hasNulls = 1
 		}
 		subWriter.putInt32(int32(hasNulls))
 		subWriter.putInt32(int32(v.ParamTyp.Oid()))
-		subWriter.putInt32(int32(v.Len()))
-		subWriter.putInt32(int32(v.Len()))
+		if ndims > 0 {
+			// The size and lower bound of the only dimension.
+			subWriter.putInt32(int32(v.Len()))
+			subWriter.putInt32(1)
+		}
 		for _, elem := range v.Array {
 			subWriter.writeBinaryDatum(ctx, elem, sessionLoc)
 		}
+		if subWriter.err != nil {
+			b.setError(subWriter.err)
+			return
+		}
 		b.variablePutbuf = subWriter.wrapped
 		b.writeLengthPrefixedVariablePutbuf()
 	case *tree.DOid:
@@ -793,6 +852,8 @@ func decodeOidDatum(id oid.Oid, code formatCode, b []byte) (tree.Datum, error) {
 			case pgNumericPos:
 			case pgNumericNeg:
 				alloc.dd.Neg(&alloc.dd.Decimal)
+			case pgNumericNaN:
+				alloc.dd.Form = apd.NaN
 			default:
 				return nil, errors.Errorf("unsupported numeric sign: %d", alloc.pgNum.sign)
 			}
@@ -824,6 +885,31 @@ func decodeOidDatum(id oid.Oid, code formatCode, b []byte) (tree.Datum, error) {
 			}
 			i := int64(binary.BigEndian.Uint64(b))
 			return tree.MakeDTime(timeofday.TimeOfDay(i)), nil
+		case oid.T_interval:
+			if len(b) < 16 {
+				return nil, errors.Errorf("interval requires 16 bytes for binary format")
+			}
+			micros := int64(binary.BigEndian.Uint64(b))
+			days := int32(binary.BigEndian.Uint32(b[8:]))
+			months := int32(binary.BigEndian.Uint32(b[12:]))
+			return &tree.DInterval{Duration: duration.Duration{
+				Months: int64(months),
+				Days:   int64(days),
+				Nanos:  micros * int64(time.Microsecond),
+			}}, nil
+		case oid.T_jsonb:
+			// The binary format of JSONB is a version number followed by the
+			// text representation of the value.
+			if len(b) < 1 {
+				return nil, errors.Errorf("jsonb requires at least 1 byte for binary format")
+			}
+			if b[0] != pgJSONBVersion {
+				return nil, errors.Errorf("unsupported jsonb version number: %d", b[0])
+			}
+			if err := validateStringBytes(b[1:]); err != nil {
+				return nil, err
+			}
+			return tree.NewDString(string(b[1:])), nil
 		case oid.T_uuid:
 			u, err := tree.ParseDUuidFromBytes(b)
 			if err != nil {
@@ -836,7 +922,11 @@ func decodeOidDatum(id oid.Oid, code formatCode, b []byte) (tree.Datum, error) {
 				return nil, err
 			}
 			return tree.NewDIPAddr(tree.DIPAddr{IPAddr: ipAddr}), nil
-		case oid.T__int2, oid.T__int4, oid.T__int8, oid.T__text, oid.T__name:
+		case oid.T__int2, oid.T__int4, oid.T__int8, oid.T__text, oid.T__name,
+			oid.T__bool, oid.T__bytea, oid.T__date, oid.T__time, oid.T__float4,
+			oid.T__float8, oid.T__interval, oid.T__numeric, oid.T__oid,
+			oid.T__timestamp, oid.T__timestamptz, oid.T__uuid, oid.T__inet,
+			oid.T__varchar:
 			return decodeBinaryArray(b, code)
 		}
 	default:
@@ -885,6 +975,21 @@ func decodeBinaryArray(b []byte, code formatCode) (tree.Datum, error) {
 		_ int32
 	}{}
 	r := bytes.NewBuffer(b)
+	if len(b) == 12 {
+		// An empty array has no dimensions and thus a shorter header.
+		var emptyHdr struct {
+			Ndims   int32
+			_       int32
+			ElemOid int32
+		}
+		if err := binary.Read(r, binary.BigEndian, &emptyHdr); err != nil {
+			return nil, err
+		}
+		if emptyHdr.Ndims != 0 {
+			return nil, errors.Errorf("unsupported number of array dimensions: %d", emptyHdr.Ndims)
+		}
+		return tree.NewDArray(types.OidToType[oid.Oid(emptyHdr.ElemOid)]), nil
+	}
 	if err := binary.Read(r, binary.BigEndian, &hdr); err != nil {
 		return nil, err
 	}
@@ -900,6 +1005,13 @@ func decodeBinaryArray(b []byte, code formatCode) (tree.Datum, error) {
 		if err := binary.Read(r, binary.BigEndian, &vlen); err != nil {
 			return nil, err
 		}
+		if vlen == -1 {
+			// A NULL element.
+			if err := arr.Append(tree.DNull); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		buf := r.Next(int(vlen))
 		elem, err := decodeOidDatum(elemOid, code, buf)
 		if err != nil {
@@ -32,6 +32,8 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -66,17 +68,24 @@ const (
 	clientMsgTerminate   clientMessageType = 'X'
 
 	serverMsgAuth                 serverMessageType = 'R'
+	serverMsgBackendKeyData       serverMessageType = 'K'
 	serverMsgBindComplete         serverMessageType = '2'
 	serverMsgCommandComplete      serverMessageType = 'C'
 	serverMsgCloseComplete        serverMessageType = '3'
+	serverMsgCopyData             serverMessageType = 'd'
+	serverMsgCopyDone             serverMessageType = 'c'
 	serverMsgCopyInResponse       serverMessageType = 'G'
+	serverMsgCopyOutResponse      serverMessageType = 'H'
 	serverMsgDataRow              serverMessageType = 'D'
 	serverMsgEmptyQuery           serverMessageType = 'I'
 	serverMsgErrorResponse        serverMessageType = 'E'
 	serverMsgNoData               serverMessageType = 'n'
+	serverMsgNoticeResponse       serverMessageType = 'N'
+	serverMsgNotificationResponse serverMessageType = 'A'
 	serverMsgParameterDescription serverMessageType = 't'
 	serverMsgParameterStatus      serverMessageType = 'S'
 	serverMsgParseComplete        serverMessageType = '1'
+	serverMsgPortalSuspended      serverMessageType = 's'
 	serverMsgReady                serverMessageType = 'Z'
 	serverMsgRowDescription       serverMessageType = 'T'
 )
@@ -86,14 +95,17 @@ type serverErrFieldType byte
 
 // http://www.postgresql.org/docs/current/static/protocol-error-fields.html
 const (
-	serverErrFieldSeverity    serverErrFieldType = 'S'
-	serverErrFieldSQLState    serverErrFieldType = 'C'
-	serverErrFieldMsgPrimary  serverErrFieldType = 'M'
-	serverErrFileldDetail     serverErrFieldType = 'D'
-	serverErrFileldHint       serverErrFieldType = 'H'
-	serverErrFieldSrcFile     serverErrFieldType = 'F'
-	serverErrFieldSrcLine     serverErrFieldType = 'L'
-	serverErrFieldSrcFunction serverErrFieldType = 'R'
+	serverErrFieldSeverity       serverErrFieldType = 'S'
+	serverErrFieldSQLState       serverErrFieldType = 'C'
+	serverErrFieldMsgPrimary     serverErrFieldType = 'M'
+	serverErrFileldDetail        serverErrFieldType = 'D'
+	serverErrFileldHint          serverErrFieldType = 'H'
+	serverErrFieldTableName      serverErrFieldType = 't'
+	serverErrFieldColumnName     serverErrFieldType = 'c'
+	serverErrFieldConstraintName serverErrFieldType = 'n'
+	serverErrFieldSrcFile        serverErrFieldType = 'F'
+	serverErrFieldSrcLine        serverErrFieldType = 'L'
+	serverErrFieldSrcFunction    serverErrFieldType = 'R'
 )
 
 //go:generate stringer -type=prepareType
@@ -107,11 +119,23 @@ const (
 const (
 	authOK                int32 = 0
 	authCleartextPassword int32 = 3
+	authSASL              int32 = 10
+	authSASLContinue      int32 = 11
+	authSASLFinal         int32 = 12
 )
 
-// connResultsBufferSizeBytes refers to the size of the result set which we
-// buffer into memory prior to flushing to the client.
-const connResultsBufferSizeBytes = 16 << 10
+// connResultsBufferSize refers to the size of the result set which we buffer
+// into memory prior to flushing to the client. Results are streamed to the
+// client as soon as this buffer fills up, which bounds the memory used per
+// connection and the time to the first row. However, once results have been
+// sent, the statements producing them can no longer be retried automatically,
+// so a larger buffer trades memory for fewer retry errors seen by clients.
+var connResultsBufferSize = settings.RegisterByteSizeSetting(
+	"sql.defaults.results_buffer.size",
+	"size of the buffer that accumulates results for a statement or a batch "+
+		"of statements before they are sent to the client",
+	16<<10, /* 16KiB */
+)
 
 // preparedStatementMeta is pgwire-specific metadata which is attached to each
 // sql.PreparedStatement on a v3Conn's sql.Session.
@@ -123,6 +147,9 @@ type preparedStatementMeta struct {
 // sql.PreparedPortal on a v3Conn's sql.Session.
 type preparedPortalMeta struct {
 	outFormats []formatCode
+	// suspended, if set, holds the rows that remain to be sent by the next
+	// executions of the portal.
+	suspended *suspendedResult
 }
 
 // readTimeoutConn overloads net.Conn.Read by periodically calling
@@ -192,10 +219,32 @@ type v3Conn struct {
 	// it gets extra data after an error happened during a COPY operation.
 	doNotSendReadyForQuery bool
 
+	// idle is set while the connection waits for the client's next message
+	// outside of an extended query, when notifications can be sent at any
+	// time.
+	idle bool
+
 	metrics *ServerMetrics
 
 	sqlMemoryPool *mon.BytesMonitor
 
+	// resultsBufferSize is the value of connResultsBufferSize when the
+	// connection was established.
+	resultsBufferSize int
+
+	// hbaConf is the host-based authentication configuration when the
+	// connection was established.
+	hbaConf string
+	// identMap is the identity map when the connection was established.
+	identMap string
+
+	// cancelKeys, if set, is used to give the connection a cancel key.
+	cancelKeys *cancelKeyRegistry
+
+	// copyOutBuf is scratch space used to encode the fields of COPY TO rows.
+	copyOutBuf writeBuffer
+	copyOutRow []byte
+
 	streamingState streamingState
 }
 
@@ -216,6 +265,14 @@ type streamingState struct {
 	// to back the ResultGroup.ResultsSentToClient() interface.
 	hasSentResults bool
 
+	// streaming is set once the current group can no longer be reset, either
+	// because the executor told us so through StreamResults() or because
+	// results had to be sent when the buffer filled up. There's no point in
+	// accumulating results for a group that can't be retried, so from then on
+	// every message is handed to the connection's writer right away instead of
+	// waiting for the buffer to fill up again.
+	streaming bool
+
 	// TODO(tso): this can theoretically be combined with v3conn.writeBuf.
 	// Currently we write to write to the v3conn.writeBuf, then we take those
 	// bytes and write them to buf. We do this since we need to length prefix
@@ -238,6 +295,17 @@ type streamingState struct {
 	// copyIn is set to true if we are currently copying in so that we do not
 	// send tree.RowsAffected command complete tags.
 	copyIn bool
+	// copyOutOpts is the data format of the current result if it's that of a
+	// COPY TO statement.
+	copyOutOpts sql.CopyOptions
+	// flushEveryRow is set if the current statement doesn't finish on its own
+	// and its rows must be sent to the client as soon as they are produced.
+	flushEveryRow bool
+
+	// portal is the portal being executed, if any.
+	portal *sql.PreparedPortal
+	// suspended holds the rows of the current result that exceed limit.
+	suspended *suspendedResult
 }
 
 func (s *streamingState) reset(formatCodes []formatCode, sendDescription bool, limit int) {
@@ -246,6 +314,7 @@ func (s *streamingState) reset(formatCodes []formatCode, sendDescription bool, l
 	s.limit = limit
 	s.emptyQuery = false
 	s.hasSentResults = false
+	s.streaming = false
 	s.txnStartIdx = 0
 	s.err = nil
 	s.copyIn = false
@@ -253,16 +322,23 @@ func (s *streamingState) reset(formatCodes []formatCode, sendDescription bool, l
 }
 
 func makeV3Conn(
-	conn net.Conn, metrics *ServerMetrics, sqlMemoryPool *mon.BytesMonitor, executor *sql.Executor,
+	conn net.Conn,
+	st *cluster.Settings,
+	metrics *ServerMetrics,
+	sqlMemoryPool *mon.BytesMonitor,
+	executor *sql.Executor,
 ) v3Conn {
 	return v3Conn{
-		conn:          conn,
-		rd:            bufio.NewReader(conn),
-		wr:            bufio.NewWriter(conn),
-		writeBuf:      writeBuffer{bytecount: metrics.BytesOutCount},
-		metrics:       metrics,
-		executor:      executor,
-		sqlMemoryPool: sqlMemoryPool,
+		conn:              conn,
+		rd:                bufio.NewReader(conn),
+		wr:                bufio.NewWriter(conn),
+		writeBuf:          writeBuffer{bytecount: metrics.BytesOutCount},
+		metrics:           metrics,
+		executor:          executor,
+		sqlMemoryPool:     sqlMemoryPool,
+		resultsBufferSize: int(connResultsBufferSize.Get(&st.SV)),
+		hbaConf:           hbaConfSetting.Get(&st.SV),
+		identMap:          identMapSetting.Get(&st.SV),
 	}
 }
 
@@ -334,47 +410,105 @@ var statusReportParams = map[string]string{
 // name, if different from the one given initially. Note: at this
 // point the sql.Session does not exist yet! If need exists to access the
 // database to look up authentication data, use the internal executor.
+// The connection must be closed if an error is returned.
 func (c *v3Conn) handleAuthentication(ctx context.Context, insecure bool) error {
-	if tlsConn, ok := c.conn.(*tls.Conn); ok {
+	tlsConn, isTLS := c.conn.(*tls.Conn)
+	// Connections over a Unix socket don't need SSL, but secure nodes
+	// authenticate them all the same.
+	if isTLS || (!insecure && isUnixSocketConn(c.conn)) {
 		var authenticationHook security.UserAuthHook
 
+		var tlsState tls.ConnectionState
+		if isTLS {
+			tlsState = tlsConn.ConnectionState()
+		}
+		entry, err := c.authenticationEntry(len(tlsState.PeerCertificates) > 0)
+		if err != nil {
+			return err
+		}
+		if entry.method == hbaMethodReject {
+			return pgerror.NewErrorf(pgerror.CodeInvalidAuthorizationSpecificationError,
+				"authentication rejected by configuration for user %s", c.sessionArgs.User)
+		}
+
 		// Check that the requested user exists and retrieve the hashed
 		// password in case password authentication is needed.
 		exists, hashedPassword, err := sql.GetUserHashedPassword(
 			ctx, c.executor, c.metrics.internalMemMetrics, c.sessionArgs.User,
 		)
 		if err != nil {
-			return c.sendError(err)
+			return err
 		}
 		if !exists {
-			return c.sendError(errors.Errorf("user %s does not exist", c.sessionArgs.User))
-		}
-
-		tlsState := tlsConn.ConnectionState()
-		// If no certificates are provided, default to password
-		// authentication.
-		if len(tlsState.PeerCertificates) == 0 {
-			password, err := c.sendAuthPasswordRequest()
-			if err != nil {
-				return c.sendError(err)
+			return errors.Errorf("user %s does not exist", c.sessionArgs.User)
+		}
+
+		// password is the cleartext password sent by the client, if any.
+		var password string
+		switch entry.method {
+		case hbaMethodPassword:
+			// Users whose password is stored as a SCRAM verifier go through a
+			// SCRAM exchange, the others send their password in cleartext.
+			if security.IsSCRAMVerifier(hashedPassword) {
+				authenticationHook = security.UserAuthSCRAMHook(insecure, func() error {
+					return c.handleSCRAMAuthentication(hashedPassword)
+				})
+			} else {
+				password, err = c.sendAuthPasswordRequest()
+				if err != nil {
+					return err
+				}
+				authenticationHook = security.UserAuthPasswordHook(
+					insecure, password, hashedPassword,
+				)
 			}
-			authenticationHook = security.UserAuthPasswordHook(
-				insecure, password, hashedPassword,
-			)
-		} else {
+		case hbaMethodCert:
 			// Normalize the username contained in the certificate.
-			tlsState.PeerCertificates[0].Subject.CommonName = tree.Name(
-				tlsState.PeerCertificates[0].Subject.CommonName,
-			).Normalize()
+			if len(tlsState.PeerCertificates) > 0 {
+				tlsState.PeerCertificates[0].Subject.CommonName = tree.Name(
+					tlsState.PeerCertificates[0].Subject.CommonName,
+				).Normalize()
+			}
 			var err error
 			authenticationHook, err = security.UserAuthCertHook(insecure, &tlsState)
 			if err != nil {
-				return c.sendError(err)
+				return err
+			}
+		case hbaMethodTrust:
+			authenticationHook = func(string, bool) error { return nil }
+		case hbaMethodPeer:
+			unixConn, ok := c.conn.(*net.UnixConn)
+			if !ok {
+				return errors.New("peer authentication requires a Unix socket connection without SSL")
+			}
+			peerUser, err := peerUserName(unixConn)
+			if err != nil {
+				return err
+			}
+			if err := c.checkSystemIdentity(entry.options["map"], peerUser); err != nil {
+				return err
+			}
+			authenticationHook = func(string, bool) error { return nil }
+		case hbaMethodExternal:
+			systemIdentity, err := entry.external.fn(
+				ctx, c, tlsState, c.executor.Cfg(), entry.options,
+			)
+			if err != nil {
+				return err
 			}
+			if err := c.checkSystemIdentity(entry.options["map"], systemIdentity); err != nil {
+				return err
+			}
+			authenticationHook = func(string, bool) error { return nil }
 		}
 
 		if err := authenticationHook(c.sessionArgs.User, true /* public */); err != nil {
-			return c.sendError(err)
+			return err
+		}
+		if entry.method == hbaMethodPassword && !insecure {
+			if err := c.checkPassword(ctx, password, hashedPassword); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -383,6 +517,62 @@ func (c *v3Conn) handleAuthentication(ctx context.Context, insecure bool) error
 	return c.writeBuf.finishMsg(c.wr)
 }
 
+// checkPassword enforces the password policies once a user has authenticated
+// with their password: the authentication fails if the password has expired,
+// and the password is rehashed if its stored hash is weaker than the hashes
+// of new passwords. A failure to rehash the password doesn't fail the
+// authentication.
+func (c *v3Conn) checkPassword(ctx context.Context, password string, hashedPassword []byte) error {
+	validUntil, err := sql.GetUserPasswordValidUntil(
+		ctx, c.executor, c.metrics.internalMemMetrics, c.sessionArgs.User,
+	)
+	if err != nil {
+		return err
+	}
+	if err := sql.CheckPasswordValidUntil(c.sessionArgs.User, validUntil); err != nil {
+		return err
+	}
+	// The cleartext password is only known without a SCRAM exchange.
+	if password != "" {
+		if err := sql.MaybeRehashUserPassword(
+			ctx, c.executor, c.metrics.internalMemMetrics, c.sessionArgs.User, password, hashedPassword,
+		); err != nil {
+			log.Warningf(ctx, "unable to rehash the password of user %s: %v", c.sessionArgs.User, err)
+		}
+	}
+	return nil
+}
+
+// authenticationEntry returns the host-based authentication entry selecting
+// the method authenticating the connection. Without a host-based
+// authentication configuration, clients authenticate with their certificate
+// if they present one and with their password otherwise.
+func (c *v3Conn) authenticationEntry(hasCert bool) (hbaEntry, error) {
+	if c.hbaConf == "" {
+		if hasCert {
+			return hbaEntry{method: hbaMethodCert}, nil
+		}
+		return hbaEntry{method: hbaMethodPassword}, nil
+	}
+	conf, err := parseHBAConf(c.hbaConf)
+	if err != nil {
+		return hbaEntry{}, errors.Wrap(err, "invalid host-based authentication configuration")
+	}
+	var ip net.IP
+	host := "local"
+	if addr, ok := c.conn.RemoteAddr().(*net.TCPAddr); ok {
+		ip = addr.IP
+		host = ip.String()
+	}
+	entry, ok := conf.lookup(ip, c.sessionArgs.Database, c.sessionArgs.User)
+	if !ok {
+		return hbaEntry{}, pgerror.NewErrorf(pgerror.CodeInvalidAuthorizationSpecificationError,
+			"no host-based authentication entry for host %q, user %q, database %q",
+			host, c.sessionArgs.User, c.sessionArgs.Database)
+	}
+	return entry, nil
+}
+
 func (c *v3Conn) setupSession(ctx context.Context, reserved mon.BoundAccount) error {
 	c.session = sql.NewSession(
 		ctx, c.sessionArgs, c.executor, c.conn.RemoteAddr(), &c.metrics.SQLMemMetrics,
@@ -426,6 +616,17 @@ func (c *v3Conn) serve(ctx context.Context, draining func() bool, reserved mon.B
 		c.closeSession(ctx)
 	}()
 
+	if c.cancelKeys != nil {
+		key, unregister, err := c.cancelKeys.register(c.session)
+		if err != nil {
+			return err
+		}
+		defer unregister()
+		if err := c.sendBackendKeyData(key); err != nil {
+			return err
+		}
+	}
+
 	// Once a session has been set up, the underlying net.Conn is switched to
 	// a conn that exits if the session's context is cancelled or if the server
 	// is draining and the session does not have an ongoing transaction.
@@ -438,12 +639,23 @@ func (c *v3Conn) serve(ctx context.Context, draining func() bool, reserved mon.B
 		}(); err != nil {
 			return newAdminShutdownErr(err)
 		}
+		// Notifications that arrive while the client is idle are delivered
+		// right away rather than with the next ReadyForQuery.
+		if c.idle {
+			if sent, err := c.sendNotifications(); err != nil || !sent {
+				return err
+			}
+			return c.wr.Flush()
+		}
 		return nil
 	})
 	c.rd = bufio.NewReader(c.conn)
 
 	for {
 		if !c.doingExtendedQueryMessage && !c.doNotSendReadyForQuery {
+			if _, err := c.sendNotifications(); err != nil {
+				return err
+			}
 			c.writeBuf.initMsg(serverMsgReady)
 			var txnStatus byte
 			switch c.session.TxnState.State() {
@@ -478,7 +690,9 @@ func (c *v3Conn) serve(ctx context.Context, draining func() bool, reserved mon.B
 			}
 		}
 		c.doNotSendReadyForQuery = false
+		c.idle = !c.doingExtendedQueryMessage
 		typ, n, err := c.readBuf.readTypedMsg(c.rd)
+		c.idle = false
 		c.metrics.BytesInCount.Inc(int64(n))
 		if err != nil {
 			return err
@@ -598,6 +812,88 @@ func (c *v3Conn) sendAuthPasswordRequest() (string, error) {
 	return c.readBuf.getString()
 }
 
+// handleSCRAMAuthentication runs the SASL exchange authenticating the client
+// with SCRAM-SHA-256 against the stored verifier. It returns an error if the
+// client fails to prove that it knows the password.
+func (c *v3Conn) handleSCRAMAuthentication(hashedPassword []byte) error {
+	scram, err := security.NewSCRAMConversation(hashedPassword)
+	if err != nil {
+		return err
+	}
+	c.writeBuf.initMsg(serverMsgAuth)
+	c.writeBuf.putInt32(authSASL)
+	c.writeBuf.writeTerminatedString(security.SCRAMMechanism)
+	c.writeBuf.nullTerminate()
+	if err := c.writeBuf.finishMsg(c.wr); err != nil {
+		return err
+	}
+
+	// The SASLInitialResponse names the mechanism chosen by the client and
+	// carries the client-first-message.
+	if err := c.readPasswordMessage(); err != nil {
+		return err
+	}
+	mechanism, err := c.readBuf.getString()
+	if err != nil {
+		return err
+	}
+	if mechanism != security.SCRAMMechanism {
+		return errors.Errorf("unsupported SASL mechanism %q", mechanism)
+	}
+	n, err := c.readBuf.getUint32()
+	if err != nil {
+		return err
+	}
+	if int32(n) < 0 {
+		return errors.New("missing SCRAM client-first-message")
+	}
+	clientFirst, err := c.readBuf.getBytes(int(n))
+	if err != nil {
+		return err
+	}
+	serverFirst, err := scram.ClientFirst(string(clientFirst))
+	if err != nil {
+		return err
+	}
+	c.writeBuf.initMsg(serverMsgAuth)
+	c.writeBuf.putInt32(authSASLContinue)
+	c.writeBuf.writeString(serverFirst)
+	if err := c.writeBuf.finishMsg(c.wr); err != nil {
+		return err
+	}
+
+	// The SASLResponse carries the client-final-message.
+	if err := c.readPasswordMessage(); err != nil {
+		return err
+	}
+	serverFinal, err := scram.ClientFinal(string(c.readBuf.msg))
+	if err != nil {
+		return err
+	}
+	c.writeBuf.initMsg(serverMsgAuth)
+	c.writeBuf.putInt32(authSASLFinal)
+	c.writeBuf.writeString(serverFinal)
+	return c.writeBuf.finishMsg(c.wr)
+}
+
+// readPasswordMessage flushes the pending authentication request and reads the
+// client's response into c.readBuf.
+func (c *v3Conn) readPasswordMessage() error {
+	if err := c.wr.Flush(); err != nil {
+		return err
+	}
+	typ, n, err := c.readBuf.readTypedMsg(c.rd)
+	c.metrics.BytesInCount.Inc(int64(n))
+	if err != nil {
+		return err
+	}
+	// All the responses to authentication requests use this message type.
+	if typ != clientMsgPassword {
+		return errors.Errorf("invalid response to authentication request: %s", typ)
+	}
+	return nil
+}
+
 func (c *v3Conn) handleSimpleQuery(buf *readBuffer) error {
 	defer c.session.FinishPlan()
 	query, err := buf.getString()
@@ -756,7 +1052,7 @@ func (c *v3Conn) handleDescribe(ctx context.Context, buf *readBuffer) error {
 			return c.sendError(pgerror.NewErrorf(pgerror.CodeInvalidCursorNameError, "unknown portal %q", name))
 		}
 
-		portalMeta := portal.ProtocolMeta.(preparedPortalMeta)
+		portalMeta := portal.ProtocolMeta.(*preparedPortalMeta)
 
 		if stmtHasNoData(portal.Stmt.Statement) {
 			return c.sendNoData(c.wr)
@@ -925,7 +1221,7 @@ func (c *v3Conn) handleBind(ctx context.Context, buf *readBuffer) error {
 	}
 
 	// Attach pgwire-specific metadata to the PreparedPortal.
-	portal.ProtocolMeta = preparedPortalMeta{outFormats: columnFormatCodes}
+	portal.ProtocolMeta = &preparedPortalMeta{outFormats: columnFormatCodes}
 	c.writeBuf.initMsg(serverMsgBindComplete)
 	return c.writeBuf.finishMsg(c.wr)
 }
@@ -945,8 +1241,14 @@ func (c *v3Conn) handleExecute(buf *readBuffer) error {
 		return err
 	}
 
+	ctx := c.session.Ctx()
+	portalMeta := portal.ProtocolMeta.(*preparedPortalMeta)
+	if portalMeta.suspended != nil {
+		c.streamingState.reset(portalMeta.outFormats, false /* sendDescription */, int(limit))
+		return c.resumePortal(ctx, portal, portalMeta, int(limit))
+	}
+
 	stmt := portal.Stmt
-	portalMeta := portal.ProtocolMeta.(preparedPortalMeta)
 	pinfo := &tree.PlaceholderInfo{
 		TypeHints: stmt.TypeHints,
 		Types:     stmt.Types,
@@ -955,6 +1257,13 @@ func (c *v3Conn) handleExecute(buf *readBuffer) error {
 
 	tracing.AnnotateTrace()
 	c.streamingState.reset(portalMeta.outFormats, false /* sendDescription */, int(limit))
+	c.streamingState.portal = portal
+	defer func() {
+		// Rows that were set aside for a result that didn't complete
+		// successfully are not going to be sent.
+		c.discardSuspended()
+		c.streamingState.portal = nil
+	}()
 	c.session.ResultsWriter = c
 	err = c.executor.ExecutePreparedStatement(c.session, stmt, pinfo)
 	if err != nil {
@@ -962,6 +1271,10 @@ func (c *v3Conn) handleExecute(buf *readBuffer) error {
 			return err
 		}
 	}
+	if s := c.streamingState.suspended; s != nil && c.streamingState.err == nil {
+		portalMeta.suspended = s
+		c.streamingState.suspended = nil
+	}
 	return c.done()
 }
 
@@ -1004,6 +1317,21 @@ func (c *v3Conn) sendError(err error) error {
 		c.writeBuf.writeTerminatedString(pgErr.Hint)
 	}
 
+	if ok && pgErr.TableName != "" {
+		c.writeBuf.putErrFieldMsg(serverErrFieldTableName)
+		c.writeBuf.writeTerminatedString(pgErr.TableName)
+	}
+
+	if ok && pgErr.ColumnName != "" {
+		c.writeBuf.putErrFieldMsg(serverErrFieldColumnName)
+		c.writeBuf.writeTerminatedString(pgErr.ColumnName)
+	}
+
+	if ok && pgErr.ConstraintName != "" {
+		c.writeBuf.putErrFieldMsg(serverErrFieldConstraintName)
+		c.writeBuf.writeTerminatedString(pgErr.ConstraintName)
+	}
+
 	if ok && pgErr.Source != nil {
 		errCtx := pgErr.Source
 		if errCtx.File != "" {
@@ -1032,6 +1360,32 @@ func (c *v3Conn) sendError(err error) error {
 	return c.wr.Flush()
 }
 
+// sendNotice sends a NoticeResponse message for n.
+func (c *v3Conn) sendNotice(n sql.Notice, w io.Writer) error {
+	c.writeBuf.initMsg(serverMsgNoticeResponse)
+
+	c.writeBuf.putErrFieldMsg(serverErrFieldSeverity)
+	c.writeBuf.writeTerminatedString(n.Severity.String())
+
+	code := n.Code
+	if code == "" {
+		code = pgerror.CodeSuccessfulCompletionError
+	}
+	c.writeBuf.putErrFieldMsg(serverErrFieldSQLState)
+	c.writeBuf.writeTerminatedString(code)
+
+	if n.Hint != "" {
+		c.writeBuf.putErrFieldMsg(serverErrFileldHint)
+		c.writeBuf.writeTerminatedString(n.Hint)
+	}
+
+	c.writeBuf.putErrFieldMsg(serverErrFieldMsgPrimary)
+	c.writeBuf.writeTerminatedString(n.Message)
+
+	c.writeBuf.nullTerminate()
+	return c.writeBuf.finishMsg(w)
+}
+
 // sendNoData sends NoData message when there aren't any rows to
 // send. This must be set to true iff we are responding in the
 // Extended Query protocol and the portal or statement will not return
@@ -1043,6 +1397,27 @@ func (c *v3Conn) sendNoData(w io.Writer) error {
 	return c.writeBuf.finishMsg(w)
 }
 
+// sendNotifications writes the notifications received by the session on the
+// channels it listens to. As in Postgres, they are held back while the
+// session is in a transaction. It returns whether any notification was
+// written; the caller is responsible for flushing.
+func (c *v3Conn) sendNotifications() (bool, error) {
+	if c.session.TxnState.State() != sql.NoTxn {
+		return false, nil
+	}
+	notifications := c.session.PendingNotifications()
+	for _, n := range notifications {
+		c.writeBuf.initMsg(serverMsgNotificationResponse)
+		c.writeBuf.putInt32(n.ProcessID)
+		c.writeBuf.writeTerminatedString(n.Channel)
+		c.writeBuf.writeTerminatedString(n.Payload)
+		if err := c.writeBuf.finishMsg(c.wr); err != nil {
+			return false, err
+		}
+	}
+	return len(notifications) > 0, nil
+}
+
 // sendRowDescription sends a row description over the wire for the given
 // slice of columns.
 func (c *v3Conn) sendRowDescription(
@@ -1174,6 +1549,12 @@ func (c *v3Conn) Close() {
 	// per-group method.
 	s.emptyQuery = false
 	s.hasSentResults = false
+	s.streaming = false
+}
+
+// StreamResults implements the ResultsGroup interface.
+func (c *v3Conn) StreamResults() {
+	c.streamingState.streaming = true
 }
 
 // Reset implements the ResultsGroup interface.
@@ -1192,7 +1573,10 @@ func (c *v3Conn) Flush(ctx context.Context) error {
 		return err
 	}
 	// hasSentResults is relative to the Flush() point, so we reset it here.
+	// The results that follow may be retried again, so we go back to
+	// buffering them.
 	c.streamingState.hasSentResults = false
+	c.streamingState.streaming = false
 	return nil
 }
 
@@ -1203,6 +1587,16 @@ func (c *v3Conn) BeginResult(stmt tree.Statement) {
 	state.statementType = stmt.StatementType()
 	state.rowsAffected = 0
 	state.firstRow = true
+	// The rows set aside for a previous attempt of the statement, which is
+	// being retried, are superseded.
+	c.discardSuspended()
+	if copyTo, ok := stmt.(*tree.CopyTo); ok {
+		// The options have been validated when the statement was planned, and
+		// no rows are sent if that failed.
+		state.copyOutOpts, _ = sql.ParseCopyOptions(copyTo.Options)
+	}
+	changefeed, ok := stmt.(*tree.CreateChangefeed)
+	state.flushEveryRow = ok && changefeed.SinkURI == nil
 }
 
 // GetPGTag implements the StatementResult interface.
@@ -1233,17 +1627,16 @@ func (c *v3Conn) CloseResult() error {
 
 	ctx := c.session.Ctx()
 	formatCodes := state.formatCodes
-	limit := state.limit
 
 	if err := c.flush(false /* forceSend */); err != nil {
 		return err
 	}
 
-	if limit != 0 && state.statementType == tree.Rows && state.rowsAffected > state.limit {
-		return c.setError(pgerror.NewErrorf(
-			pgerror.CodeInternalError,
-			"execute row count limits not supported: %d of %d", limit, state.rowsAffected,
-		))
+	if state.suspended != nil {
+		// The result has more rows than the client asked for. The remaining
+		// ones are sent by the next executions of the portal.
+		state.suspended.pgTag = state.pgTag
+		return c.sendPortalSuspended(&state.buf)
 	}
 
 	if state.pgTag == "INSERT" {
@@ -1287,6 +1680,20 @@ func (c *v3Conn) CloseResult() error {
 		}
 		return nil
 
+	case tree.CopyOut:
+		if state.firstRow {
+			if err := c.beginCopyOut(&state.buf); err != nil {
+				return err
+			}
+		}
+		c.writeBuf.initMsg(serverMsgCopyDone)
+		if err := c.writeBuf.finishMsg(&state.buf); err != nil {
+			return err
+		}
+		tag = append(tag, ' ')
+		tag = strconv.AppendInt(tag, int64(state.rowsAffected), 10)
+		return c.sendCommandComplete(tag, &state.buf)
+
 	default:
 		panic(fmt.Sprintf("unexpected result type %v", state.statementType))
 	}
@@ -1324,6 +1731,19 @@ func (c *v3Conn) IncrementRowsAffected(n int) {
 	c.streamingState.rowsAffected += n
 }
 
+// AddNotice implements the StatementResult interface.
+func (c *v3Conn) AddNotice(n sql.Notice) {
+	state := &c.streamingState
+	if state.err != nil {
+		return
+	}
+	// The notice is buffered with the results so that it is discarded with
+	// them if the statement is retried.
+	if err := c.sendNotice(n, &state.buf); err != nil {
+		state.err = sql.NewWireFailureError(err)
+	}
+}
+
 // AddRow implements the StatementResult interface.
 func (c *v3Conn) AddRow(ctx context.Context, row tree.Datums) error {
 	state := &c.streamingState
@@ -1331,7 +1751,7 @@ func (c *v3Conn) AddRow(ctx context.Context, row tree.Datums) error {
 		return state.err
 	}
 
-	if state.statementType != tree.Rows {
+	if state.statementType != tree.Rows && state.statementType != tree.CopyOut {
 		return c.setError(pgerror.NewError(
 			pgerror.CodeInternalError, "cannot use AddRow() with statements that don't return rows"))
 	}
@@ -1339,6 +1759,19 @@ func (c *v3Conn) AddRow(ctx context.Context, row tree.Datums) error {
 	// The final tag will need to know the total row count.
 	state.rowsAffected++
 
+	if state.statementType == tree.CopyOut {
+		if state.firstRow {
+			if err := c.beginCopyOut(&state.buf); err != nil {
+				return err
+			}
+			state.firstRow = false
+		}
+		if err := c.writeCopyData(ctx, row, &state.buf); err != nil {
+			return err
+		}
+		return c.flush(false /* forceSend */)
+	}
+
 	formatCodes := state.formatCodes
 
 	// First row and description needed: do it.
@@ -1366,11 +1799,14 @@ func (c *v3Conn) AddRow(ctx context.Context, row tree.Datums) error {
 		}
 	}
 
+	if state.portal != nil && state.limit != 0 && state.rowsAffected > state.limit {
+		return c.suspendRow(ctx)
+	}
 	if err := c.writeBuf.finishMsg(&state.buf); err != nil {
 		return err
 	}
 
-	return c.flush(false /* forceSend */)
+	return c.flush(state.flushEveryRow /* forceSend */)
 }
 
 func (c *v3Conn) done() error {
@@ -1401,18 +1837,35 @@ func (c *v3Conn) done() error {
 // flush writes the streaming buffer to the underlying connection. If force
 // is true then we will write any data we have buffered, otherwise we will
 // only write when we exceed our buffer size.
+//
+// Once the current group is streaming, the data is handed to the connection's
+// writer on every call, which sends it as soon as its own (small) buffer fills
+// up; we only flush that writer explicitly when forced to or when our buffer
+// size is exceeded.
 func (c *v3Conn) flush(forceSend bool) error {
 	state := &c.streamingState
-	if state.buf.Len() == 0 {
+	if state.buf.Len() == 0 && (!forceSend || c.wr.Buffered() == 0) {
+		// Nothing to send, neither in our buffer nor in the writer's, which
+		// can hold messages if we've been streaming.
 		return nil
 	}
 
-	if forceSend || state.buf.Len() > connResultsBufferSizeBytes {
-		state.hasSentResults = true
-		state.txnStartIdx = 0
-		if _, err := state.buf.WriteTo(c.wr); err != nil {
-			return sql.NewWireFailureError(err)
-		}
+	sendNow := forceSend || state.buf.Len() > c.resultsBufferSize
+	if !sendNow && !state.streaming {
+		return nil
+	}
+
+	state.hasSentResults = true
+	state.txnStartIdx = 0
+	if !forceSend {
+		// The group can't be reset any more, so there's no point in
+		// buffering the rest of its results.
+		state.streaming = true
+	}
+	if _, err := state.buf.WriteTo(c.wr); err != nil {
+		return sql.NewWireFailureError(err)
+	}
+	if sendNow {
 		if err := c.wr.Flush(); err != nil {
 			return sql.NewWireFailureError(err)
 		}
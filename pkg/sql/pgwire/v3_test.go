@@ -23,6 +23,7 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/util"
@@ -43,13 +44,14 @@ func makeTestV3Conn(c net.Conn) v3Conn {
 		sql.ExecutorConfig{
 			AmbientCtx:              log.AmbientContext{Tracer: st.Tracer},
 			Settings:                st,
+			NodeID:                  &base.NodeIDContainer{},
 			HistogramWindowInterval: metric.TestSampleInterval,
 			TestingKnobs:            &sql.ExecutorTestingKnobs{},
 			SessionRegistry:         sql.MakeSessionRegistry(),
 		},
 		nil, /* stopper */
 	)
-	return makeV3Conn(c, &metrics, &mon, exec)
+	return makeV3Conn(c, st, &metrics, &mon, exec)
 }
 
 // TestMaliciousInputs verifies that known malicious inputs sent to
@@ -178,3 +180,73 @@ func TestReadTimeoutConnExits(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+// recordingConn is a net.Conn that records everything written to it.
+type recordingConn struct {
+	net.Conn
+	written bytes.Buffer
+}
+
+func (c *recordingConn) Write(b []byte) (int, error) {
+	return c.written.Write(b)
+}
+
+// TestStreamingAfterResultsSent verifies that a v3Conn stops accumulating
+// results once a group can no longer be reset, and goes back to buffering
+// after the group is flushed.
+func TestStreamingAfterResultsSent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	conn := &recordingConn{}
+	c := makeTestV3Conn(conn)
+	c.resultsBufferSize = 10
+	state := &c.streamingState
+
+	write := func(n int) {
+		state.buf.Write(make([]byte, n))
+		if err := c.flush(false /* forceSend */); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Below the buffer size, nothing is sent.
+	write(5)
+	if c.ResultsSentToClient() || conn.written.Len() != 0 || state.buf.Len() != 5 {
+		t.Fatalf("expected results to be buffered, sent %d bytes", conn.written.Len())
+	}
+
+	// Exceeding the buffer size sends everything and switches to streaming.
+	write(10)
+	if !c.ResultsSentToClient() || conn.written.Len() != 15 {
+		t.Fatalf("expected 15 bytes to be sent, got %d", conn.written.Len())
+	}
+	write(1)
+	if state.buf.Len() != 0 || c.wr.Buffered() != 1 {
+		t.Fatalf("expected results to be streamed, buffered %d+%d bytes",
+			state.buf.Len(), c.wr.Buffered())
+	}
+
+	// Flushing the group starts a new retriable prefix, which is buffered.
+	if err := c.Flush(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if c.ResultsSentToClient() || conn.written.Len() != 16 {
+		t.Fatalf("expected 16 bytes to be sent, got %d", conn.written.Len())
+	}
+	write(1)
+	if state.buf.Len() != 1 {
+		t.Fatalf("expected results to be buffered, buffered %d bytes", state.buf.Len())
+	}
+
+	// The executor can also ask for results to be streamed right away.
+	c.StreamResults()
+	write(1)
+	if !c.ResultsSentToClient() || state.buf.Len() != 0 || c.wr.Buffered() != 2 {
+		t.Fatalf("expected results to be streamed, buffered %d+%d bytes",
+			state.buf.Len(), c.wr.Buffered())
+	}
+	c.Close()
+	if state.streaming {
+		t.Fatal("expected Close to stop streaming")
+	}
+}
@@ -631,6 +631,47 @@ func (pp physicalProps) computeMatchInternal(
 	return len(desired), pos
 }
 
+// computeGroupingOrdering determines whether rows that have equal values on
+// the given columns are guaranteed to be adjacent. This is the case when a
+// prefix of the ordering contains all the (non-constant) columns, in any order
+// and direction, or when that prefix already forms a key.
+//
+// If so, it returns true along with the prefix of the ordering that is needed
+// for the guarantee; this ordering can be passed to trim() to preserve it.
+//
+// Examples:
+//  - ordering 1+,0-,2+ and cols {0, 1}: returns 1+,0-.
+//  - ordering 0+,2+,1+ and cols {0, 1}: not grouped.
+//  - const column 0, ordering 1+ and cols {0, 1}: returns 1+.
+func (pp physicalProps) computeGroupingOrdering(cols []int) (sqlbase.ColumnOrdering, bool) {
+	pp.check()
+	var remaining util.FastIntSet
+	for _, c := range cols {
+		group := pp.eqGroups.Find(c)
+		if !pp.constantCols.Contains(group) {
+			remaining.Add(group)
+		}
+	}
+
+	var result sqlbase.ColumnOrdering
+	var groupsSeen util.FastIntSet
+	for _, o := range pp.ordering {
+		if remaining.Empty() {
+			break
+		}
+		if !remaining.Contains(o.ColIdx) {
+			return nil, false
+		}
+		remaining.Remove(o.ColIdx)
+		groupsSeen.Add(o.ColIdx)
+		result = append(result, o)
+	}
+	if !remaining.Empty() && !pp.isKey(groupsSeen) {
+		return nil, false
+	}
+	return result, true
+}
+
 // trim simplifies pp.ordering, retaining only the column groups that are
 // needed to to match a desired ordering (or a prefix of it); equivalency
 // groups, constant columns, and key sets are left untouched.
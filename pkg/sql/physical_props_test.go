@@ -772,6 +772,104 @@ func TestComputeMergeJoinOrdering(t *testing.T) {
 	}
 }
 
+func TestComputeGroupingOrdering(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		name     string
+		props    physicalProps
+		cols     []int
+		expected sqlbase.ColumnOrdering
+		ok       bool
+	}{
+		{
+			name:     "exact",
+			props:    makePhysProps(makeColumnOrdering(0, asc, 1, desc)),
+			cols:     []int{0, 1},
+			expected: makeColumnOrdering(0, asc, 1, desc),
+			ok:       true,
+		},
+
+		{
+			name:     "permuted-prefix",
+			props:    makePhysProps(makeColumnOrdering(1, asc, 0, desc, 2, asc)),
+			cols:     []int{0, 1},
+			expected: makeColumnOrdering(1, asc, 0, desc),
+			ok:       true,
+		},
+
+		{
+			name:  "gap",
+			props: makePhysProps(makeColumnOrdering(0, asc, 2, asc, 1, asc)),
+			cols:  []int{0, 1},
+			ok:    false,
+		},
+
+		{
+			name:  "no-ordering",
+			props: makePhysProps(),
+			cols:  []int{0},
+			ok:    false,
+		},
+
+		{
+			name: "const",
+			props: makePhysProps(
+				constCols{0},
+				makeColumnOrdering(1, asc),
+			),
+			cols:     []int{0, 1},
+			expected: makeColumnOrdering(1, asc),
+			ok:       true,
+		},
+
+		{
+			name: "all-const",
+			props: makePhysProps(
+				constCols{0, 1},
+			),
+			cols: []int{0, 1},
+			ok:   true,
+		},
+
+		{
+			name: "groups",
+			props: makePhysProps(
+				equivGroups{{0, 3}},
+				makeColumnOrdering(3, desc, 1, asc),
+			),
+			cols:     []int{1, 3},
+			expected: makeColumnOrdering(0, desc, 1, asc),
+			ok:       true,
+		},
+
+		{
+			name: "key",
+			props: makePhysProps(
+				notNullCols{0},
+				weakKeys{{0}},
+				makeColumnOrdering(0, asc),
+			),
+			cols:     []int{0, 1, 2},
+			expected: makeColumnOrdering(0, asc),
+			ok:       true,
+		},
+	}
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			result, ok := tc.props.computeGroupingOrdering(tc.cols)
+			if ok != tc.ok {
+				t.Fatalf("expected ok=%t, got %t", tc.ok, ok)
+			}
+			if !reflect.DeepEqual(tc.expected, result) {
+				t.Errorf("expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
 func TestProjectOrdering(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
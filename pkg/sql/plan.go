@@ -165,9 +165,11 @@ var _ planNode = &dropViewNode{}
 var _ planNode = &dropSequenceNode{}
 var _ planNode = &zeroNode{}
 var _ planNode = &unaryNode{}
+var _ planNode = &explainDebugNode{}
 var _ planNode = &explainDistSQLNode{}
 var _ planNode = &explainPlanNode{}
 var _ planNode = &traceNode{}
+var _ planNode = &fetchNode{}
 var _ planNode = &filterNode{}
 var _ planNode = &groupNode{}
 var _ planNode = &hookFnNode{}
@@ -340,26 +342,52 @@ func (p *planner) newPlan(
 	switch n := stmt.(type) {
 	case *tree.AlterTable:
 		return p.AlterTable(ctx, n)
+	case *tree.AlterTableOwner:
+		return p.AlterTableOwner(ctx, n)
+	case *tree.AlterDatabaseOwner:
+		return p.AlterDatabaseOwner(ctx, n)
+	case *tree.AlterDatabaseSetPrimaryRegion:
+		return p.AlterDatabaseSetPrimaryRegion(ctx, n)
+	case *tree.AlterDatabaseAddRegion:
+		return p.AlterDatabaseAddRegion(ctx, n)
+	case *tree.AlterDefaultPrivileges:
+		return p.AlterDefaultPrivileges(ctx, n)
 	case *tree.AlterSequence:
 		return p.AlterSequence(ctx, n)
+	case *tree.AlterUserSetConnectionLimit:
+		return p.AlterUserSetConnectionLimit(ctx, n)
 	case *tree.AlterUserSetPassword:
 		return p.AlterUserSetPassword(ctx, n)
+	case *tree.AlterUserSetQuota:
+		return p.AlterUserSetQuota(ctx, n)
+	case *tree.AlterUserSetStatements:
+		return p.AlterUserSetStatements(ctx, n)
+	case *tree.AlterUserSetValidUntil:
+		return p.AlterUserSetValidUntil(ctx, n)
 	case *tree.BeginTransaction:
 		return p.BeginTransaction(n)
 	case *tree.CancelQuery:
 		return p.CancelQuery(ctx, n)
+	case *tree.CancelSession:
+		return p.CancelSession(ctx, n)
 	case *tree.CancelJob:
 		return p.CancelJob(ctx, n)
+	case *tree.CloseCursor:
+		return p.CloseCursor(ctx, n)
 	case *tree.Scrub:
 		return p.Scrub(ctx, n)
 	case CopyDataBlock:
 		return p.CopyData(ctx, n)
 	case *tree.CopyFrom:
 		return p.CopyFrom(ctx, n)
+	case *tree.CopyTo:
+		return p.CopyTo(ctx, n)
 	case *tree.CreateDatabase:
 		return p.CreateDatabase(n)
 	case *tree.CreateIndex:
 		return p.CreateIndex(ctx, n)
+	case *tree.CreatePolicy:
+		return p.CreatePolicy(ctx, n)
 	case *tree.CreateTable:
 		return p.CreateTable(ctx, n)
 	case *tree.CreateUser:
@@ -370,6 +398,8 @@ func (p *planner) newPlan(
 		return p.CreateSequence(ctx, n)
 	case *tree.Deallocate:
 		return p.Deallocate(ctx, n)
+	case *tree.DeclareCursor:
+		return p.DeclareCursor(ctx, n)
 	case *tree.Delete:
 		return p.Delete(ctx, n, desiredTypes)
 	case *tree.Discard:
@@ -378,6 +408,8 @@ func (p *planner) newPlan(
 		return p.DropDatabase(ctx, n)
 	case *tree.DropIndex:
 		return p.DropIndex(ctx, n)
+	case *tree.DropPolicy:
+		return p.DropPolicy(ctx, n)
 	case *tree.DropTable:
 		return p.DropTable(ctx, n)
 	case *tree.DropView:
@@ -390,16 +422,26 @@ func (p *planner) newPlan(
 		return p.Execute(ctx, n)
 	case *tree.Explain:
 		return p.Explain(ctx, n)
+	case *tree.FetchCursor:
+		return p.FetchCursor(ctx, n)
 	case *tree.Grant:
 		return p.Grant(ctx, n)
+	case *tree.GrantRole:
+		return p.GrantRole(ctx, n)
 	case *tree.Insert:
 		return p.Insert(ctx, n, desiredTypes)
+	case *tree.Listen:
+		return p.Listen(ctx, n)
+	case *tree.Notify:
+		return p.Notify(ctx, n)
 	case *tree.ParenSelect:
 		return p.newPlan(ctx, n.Select, desiredTypes)
 	case *tree.PauseJob:
 		return p.PauseJob(ctx, n)
 	case *tree.TestingRelocate:
 		return p.TestingRelocate(ctx, n)
+	case *tree.ReassignOwnedBy:
+		return p.ReassignOwnedBy(ctx, n)
 	case *tree.RenameColumn:
 		return p.RenameColumn(ctx, n)
 	case *tree.RenameDatabase:
@@ -412,6 +454,10 @@ func (p *planner) newPlan(
 		return p.ResumeJob(ctx, n)
 	case *tree.Revoke:
 		return p.Revoke(ctx, n)
+	case *tree.RevokeRole:
+		return p.RevokeRole(ctx, n)
+	case *tree.RotateEncryptionKey:
+		return p.RotateEncryptionKey(ctx, n)
 	case *tree.Scatter:
 		return p.Scatter(ctx, n)
 	case *tree.Select:
@@ -441,8 +487,12 @@ func (p *planner) newPlan(
 		return p.ShowCreateTable(ctx, n)
 	case *tree.ShowCreateView:
 		return p.ShowCreateView(ctx, n)
+	case *tree.ShowCreateSequence:
+		return p.ShowCreateSequence(ctx, n)
 	case *tree.ShowDatabases:
 		return p.ShowDatabases(ctx, n)
+	case *tree.ShowDump:
+		return p.ShowDump(ctx, n)
 	case *tree.ShowGrants:
 		return p.ShowGrants(ctx, n)
 	case *tree.ShowIndex:
@@ -451,8 +501,12 @@ func (p *planner) newPlan(
 		return p.ShowQueries(ctx, n)
 	case *tree.ShowJobs:
 		return p.ShowJobs(ctx, n)
+	case *tree.ShowSchedules:
+		return p.ShowSchedules(ctx, n)
 	case *tree.ShowSessions:
 		return p.ShowSessions(ctx, n)
+	case *tree.ShowSyntax:
+		return p.ShowSyntax(ctx, n)
 	case *tree.ShowTables:
 		return p.ShowTables(ctx, n)
 	case *tree.ShowTrace:
@@ -463,6 +517,8 @@ func (p *planner) newPlan(
 		return p.ShowUsers(ctx, n)
 	case *tree.ShowZoneConfig:
 		return p.ShowZoneConfig(ctx, n)
+	case *tree.ShowRangeForRow:
+		return p.ShowRangeForRow(ctx, n)
 	case *tree.ShowRanges:
 		return p.ShowRanges(ctx, n)
 	case *tree.ShowFingerprints:
@@ -476,6 +532,8 @@ func (p *planner) newPlan(
 		return p.Truncate(ctx, n)
 	case *tree.UnionClause:
 		return p.UnionClause(ctx, n, desiredTypes)
+	case *tree.Unlisten:
+		return p.Unlisten(ctx, n)
 	case *tree.Update:
 		return p.Update(ctx, n, desiredTypes)
 	case *tree.ValuesClause:
@@ -496,10 +554,20 @@ func (p *planner) prepare(ctx context.Context, stmt tree.Statement) (planNode, e
 	p.isPreparing = true
 
 	switch n := stmt.(type) {
+	case *tree.AlterUserSetConnectionLimit:
+		return p.AlterUserSetConnectionLimit(ctx, n)
 	case *tree.AlterUserSetPassword:
 		return p.AlterUserSetPassword(ctx, n)
+	case *tree.AlterUserSetQuota:
+		return p.AlterUserSetQuota(ctx, n)
+	case *tree.AlterUserSetStatements:
+		return p.AlterUserSetStatements(ctx, n)
+	case *tree.AlterUserSetValidUntil:
+		return p.AlterUserSetValidUntil(ctx, n)
 	case *tree.CancelQuery:
 		return p.CancelQuery(ctx, n)
+	case *tree.CancelSession:
+		return p.CancelSession(ctx, n)
 	case *tree.CancelJob:
 		return p.CancelJob(ctx, n)
 	case *tree.CreateUser:
@@ -510,12 +578,16 @@ func (p *planner) prepare(ctx context.Context, stmt tree.Statement) (planNode, e
 		return p.DropUser(ctx, n)
 	case *tree.Explain:
 		return p.Explain(ctx, n)
+	case *tree.FetchCursor:
+		return p.FetchCursor(ctx, n)
 	case *tree.Insert:
 		return p.Insert(ctx, n, nil)
 	case *tree.PauseJob:
 		return p.PauseJob(ctx, n)
 	case *tree.ResumeJob:
 		return p.ResumeJob(ctx, n)
+	case *tree.RotateEncryptionKey:
+		return p.RotateEncryptionKey(ctx, n)
 	case *tree.Select:
 		return p.Select(ctx, n, nil)
 	case *tree.SelectClause:
@@ -533,10 +605,14 @@ func (p *planner) prepare(ctx context.Context, stmt tree.Statement) (planNode, e
 		return p.ShowCreateTable(ctx, n)
 	case *tree.ShowCreateView:
 		return p.ShowCreateView(ctx, n)
+	case *tree.ShowCreateSequence:
+		return p.ShowCreateSequence(ctx, n)
 	case *tree.ShowColumns:
 		return p.ShowColumns(ctx, n)
 	case *tree.ShowDatabases:
 		return p.ShowDatabases(ctx, n)
+	case *tree.ShowDump:
+		return p.ShowDump(ctx, n)
 	case *tree.ShowGrants:
 		return p.ShowGrants(ctx, n)
 	case *tree.ShowIndex:
@@ -547,8 +623,12 @@ func (p *planner) prepare(ctx context.Context, stmt tree.Statement) (planNode, e
 		return p.ShowQueries(ctx, n)
 	case *tree.ShowJobs:
 		return p.ShowJobs(ctx, n)
+	case *tree.ShowSchedules:
+		return p.ShowSchedules(ctx, n)
 	case *tree.ShowSessions:
 		return p.ShowSessions(ctx, n)
+	case *tree.ShowSyntax:
+		return p.ShowSyntax(ctx, n)
 	case *tree.ShowTables:
 		return p.ShowTables(ctx, n)
 	case *tree.ShowTrace:
@@ -557,6 +637,8 @@ func (p *planner) prepare(ctx context.Context, stmt tree.Statement) (planNode, e
 		return p.ShowUsers(ctx, n)
 	case *tree.ShowTransactionStatus:
 		return p.ShowTransactionStatus(ctx)
+	case *tree.ShowRangeForRow:
+		return p.ShowRangeForRow(ctx, n)
 	case *tree.ShowRanges:
 		return p.ShowRanges(ctx, n)
 	case *tree.Split:
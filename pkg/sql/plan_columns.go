@@ -53,6 +53,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return n.resultColumns
 	case *delayedNode:
 		return n.columns
+	case *fetchNode:
+		return n.columns
 	case *groupNode:
 		return n.columns
 	case *hookFnNode:
@@ -83,6 +85,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return n.getColumns(mut, scrubColumns)
 	case *explainDistSQLNode:
 		return n.getColumns(mut, explainDistSQLColumns)
+	case *explainDebugNode:
+		return n.getColumns(mut, explainDebugColumns)
 	case *testingRelocateNode:
 		return n.getColumns(mut, relocateNodeColumns)
 	case *scatterNode:
@@ -66,6 +66,8 @@ func collectSpans(params runParams, plan planNode) (reads, writes roachpb.Spans,
 		return collectSpans(params, n.plan)
 	case *traceNode:
 		return collectSpans(params, n.plan)
+	case *explainDebugNode:
+		return collectSpans(params, n.plan)
 	case *limitNode:
 		return collectSpans(params, n.plan)
 	case *sortNode:
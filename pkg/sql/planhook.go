@@ -53,6 +53,7 @@ type PlanHookState interface {
 		opts tree.KVOptions, valuelessOpts map[string]bool,
 	) (func() (map[string]string, error), error)
 	User() string
+	ForEachQueryRow(ctx context.Context, query tree.Statement, fn func(tree.Datums) error) error
 	AuthorizationAccessor
 }
 
@@ -81,7 +82,9 @@ func (*hookFnNode) Close(context.Context) {}
 func (f *hookFnNode) Start(params runParams) error {
 	// TODO(dan): Make sure the resultCollector is set to flush after every row.
 	f.resultsCh = make(chan tree.Datums)
-	f.errCh = make(chan error)
+	// The error channel is buffered so that the goroutine doesn't leak if Next
+	// stops reading from it after the context is canceled.
+	f.errCh = make(chan error, 1)
 	go func() {
 		f.errCh <- f.f(params.ctx, f.resultsCh)
 		close(f.errCh)
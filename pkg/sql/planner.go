@@ -79,9 +79,11 @@ type planner struct {
 	// if it is SNAPSHOT.
 	avoidCachedDescriptors bool
 
-	// If set, the planner should skip checking for the SELECT privilege when
-	// initializing plans to read from a table. This should be used with care.
-	skipSelectPrivilegeChecks bool
+	// viewOwner is the owner of the view whose query is being planned, when
+	// the query runs with the privileges of the owner of the view. The
+	// privileges needed by the query are then checked for the owner instead
+	// of the session user. See getViewPlan and currentUser.
+	viewOwner string
 
 	// autoCommit indicates whether we're planning for a spontaneous transaction.
 	// If autoCommit is true, the plan is allowed (but not required) to
@@ -98,6 +100,36 @@ type planner struct {
 	// query.
 	cancelChecker *sqlbase.CancelChecker
 
+	// rowsRead is the number of rows read by the scanNodes of the current
+	// statement, checked against statement_max_rows_read.
+	rowsRead int64
+
+	// notices are the notices of the current statement, sent to the client
+	// with its results. See addNotice.
+	notices []Notice
+
+	// auditEvents are the accesses of the current statement to the tables
+	// whose accesses are audited. See maybeAudit.
+	auditEvents []auditEvent
+
+	// features are the builtins and syntax constructs used by the current
+	// statement, counted when it is executed. See noteFeature.
+	features []string
+
+	// roles caches the roles whose privileges the current user inherits for
+	// the current statement. See inheritedRoles.
+	roles struct {
+		populated bool
+		user      string
+		inherited []string
+		err       error
+	}
+
+	// runningAsyncPlans is the number of plan stages of the current query
+	// currently running in their own goroutine. It is bounded by
+	// sql.parallel_execution.max_goroutines.
+	runningAsyncPlans int
+
 	// planDeps, if non-nil, collects the table/view dependencies for this query.
 	// Any planNode constructors that resolves a table name or reference in the query
 	// to a descriptor must register this descriptor into planDeps.
@@ -112,6 +144,10 @@ type planner struct {
 	// hasSubqueries collects whether any subqueries expansion has
 	// occurred during logical plan construction.
 	hasSubqueries bool
+	// correlationScope, if non-nil, describes the surrounding query while a
+	// sub-query is being planned, so that the sub-query can refer to the
+	// columns of the surrounding query.
+	correlationScope *correlationScope
 	// isPreparing is true if this planner is currently preparing.
 	isPreparing bool
 	// plannedExecute is true if this planner has planned an EXECUTE statement.
@@ -271,6 +307,12 @@ func (p *planner) ParseTableNameWithIndex(sql string) (tree.TableNameWithIndex,
 	return parser.ParseTableNameWithIndex(sql)
 }
 
+// ParseStatement implements the parser.EvalPlanner interface.
+// We define this here to break the dependency from builtins.go to the parser.
+func (p *planner) ParseStatement(sql string) (tree.Statement, error) {
+	return parser.ParseOne(sql)
+}
+
 // QueryRow implements the parser.EvalPlanner interface.
 func (p *planner) QueryRow(
 	ctx context.Context, sql string, args ...interface{},
@@ -300,6 +342,12 @@ func (p *planner) IncrementSequence(ctx context.Context, seqName *tree.TableName
 		ctx, p.txn.DB(), seqValueKey, descriptor.SequenceOpts.Increment)
 }
 
+// SchemaExists implements the parser.EvalPlanner interface.
+func (p *planner) SchemaExists(ctx context.Context, name string) (bool, error) {
+	desc, err := getDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), name)
+	return desc != nil, err
+}
+
 // queryRows executes a SQL query string where multiple result rows are returned.
 func (p *planner) queryRows(
 	ctx context.Context, sql string, args ...interface{},
@@ -333,6 +381,32 @@ func (p *planner) queryRows(
 	return rows, nil
 }
 
+// ForEachQueryRow plans and runs the given query, calling fn for each result
+// row. The row passed to fn is only valid until fn returns. It implements the
+// PlanHookState interface.
+func (p *planner) ForEachQueryRow(
+	ctx context.Context, query tree.Statement, fn func(tree.Datums) error,
+) error {
+	plan, err := p.newPlan(ctx, query, nil)
+	if err != nil {
+		return err
+	}
+	plan, err = p.optimizePlan(ctx, plan, allColumns(plan))
+	defer plan.Close(ctx)
+	if err != nil {
+		return err
+	}
+	if err := p.startPlan(ctx, plan); err != nil {
+		return err
+	}
+	params := runParams{
+		ctx:     ctx,
+		evalCtx: &p.evalCtx,
+		p:       p,
+	}
+	return forEachRow(params, plan, fn)
+}
+
 // exec executes a SQL query string and returns the number of rows
 // affected.
 func (p *planner) exec(ctx context.Context, sql string, args ...interface{}) (int, error) {
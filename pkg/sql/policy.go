@@ -0,0 +1,356 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// The statements a row-level security policy can apply to.
+const (
+	policyCommandAll    = "ALL"
+	policyCommandSelect = "SELECT"
+	policyCommandInsert = "INSERT"
+	policyCommandUpdate = "UPDATE"
+	policyCommandDelete = "DELETE"
+)
+
+type createPolicyNode struct {
+	n         *tree.CreatePolicy
+	tableDesc *sqlbase.TableDescriptor
+}
+
+// CreatePolicy creates a row-level security policy on a table.
+// Privileges: CREATE on table.
+//   Notes: postgres requires the user to own the table.
+func (p *planner) CreatePolicy(ctx context.Context, n *tree.CreatePolicy) (planNode, error) {
+	tableDesc, err := p.getPolicyTableDesc(ctx, &n.Table)
+	if err != nil {
+		return nil, err
+	}
+	return &createPolicyNode{n: n, tableDesc: tableDesc}, nil
+}
+
+func (n *createPolicyNode) Start(params runParams) error {
+	name := string(n.n.Name)
+	for _, policy := range n.tableDesc.Policies {
+		if policy.Name == name {
+			return pgerror.NewErrorf(pgerror.CodeDuplicateObjectError,
+				"policy %q for table %q already exists", name, n.tableDesc.Name)
+		}
+	}
+
+	policy := sqlbase.TableDescriptor_Policy{Name: name, Command: n.n.Command}
+	if policy.Command == "" {
+		policy.Command = policyCommandAll
+	}
+	switch {
+	case policy.Command == policyCommandInsert && n.n.Using != nil:
+		return pgerror.NewError(pgerror.CodeSyntaxError,
+			"only WITH CHECK expression allowed for INSERT")
+	case (policy.Command == policyCommandSelect || policy.Command == policyCommandDelete) &&
+		n.n.WithCheck != nil:
+		return pgerror.NewError(pgerror.CodeSyntaxError,
+			"WITH CHECK cannot be applied to SELECT or DELETE")
+	}
+
+	for _, r := range n.n.Roles {
+		role := r.Normalize()
+		if err := params.p.checkRoleExists(params.ctx, role); err != nil {
+			return err
+		}
+		policy.Roles = append(policy.Roles, role)
+	}
+
+	for _, e := range []struct {
+		expr tree.Expr
+		dst  **string
+	}{
+		{n.n.Using, &policy.UsingExpr},
+		{n.n.WithCheck, &policy.WithCheckExpr},
+	} {
+		if e.expr == nil {
+			continue
+		}
+		if err := params.p.validatePolicyExpr(params.ctx, e.expr, &n.n.Table, n.tableDesc); err != nil {
+			return err
+		}
+		s := tree.Serialize(e.expr)
+		*e.dst = &s
+	}
+
+	n.tableDesc.Policies = append(n.tableDesc.Policies, policy)
+	if err := params.p.saveNonmutationAndNotify(params.ctx, n.tableDesc); err != nil {
+		return err
+	}
+
+	// Record the policy creation in the event log. This is an auditable log
+	// event and is recorded in the same transaction as the table descriptor
+	// update.
+	return MakeEventLogger(params.p.LeaseMgr()).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogCreatePolicy,
+		int32(n.tableDesc.ID),
+		int32(params.evalCtx.NodeID),
+		&sqlbase.CreatePolicyEvent{
+			TableName:  n.tableDesc.Name,
+			PolicyName: name,
+			Statement:  n.n.String(),
+			User:       params.p.session.User,
+		},
+	)
+}
+
+func (n *createPolicyNode) Next(runParams) (bool, error) { return false, nil }
+func (n *createPolicyNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *createPolicyNode) Close(context.Context)        {}
+
+type dropPolicyNode struct {
+	n         *tree.DropPolicy
+	tableDesc *sqlbase.TableDescriptor
+}
+
+// DropPolicy removes a row-level security policy from a table.
+// Privileges: CREATE on table.
+//   Notes: postgres requires the user to own the table.
+func (p *planner) DropPolicy(ctx context.Context, n *tree.DropPolicy) (planNode, error) {
+	tableDesc, err := p.getPolicyTableDesc(ctx, &n.Table)
+	if err != nil {
+		return nil, err
+	}
+	return &dropPolicyNode{n: n, tableDesc: tableDesc}, nil
+}
+
+func (n *dropPolicyNode) Start(params runParams) error {
+	name := string(n.n.Name)
+	for i, policy := range n.tableDesc.Policies {
+		if policy.Name == name {
+			n.tableDesc.Policies = append(n.tableDesc.Policies[:i], n.tableDesc.Policies[i+1:]...)
+			if err := params.p.saveNonmutationAndNotify(params.ctx, n.tableDesc); err != nil {
+				return err
+			}
+
+			// Record the policy removal in the event log. This is an auditable
+			// log event and is recorded in the same transaction as the table
+			// descriptor update.
+			return MakeEventLogger(params.p.LeaseMgr()).InsertEventRecord(
+				params.ctx,
+				params.p.txn,
+				EventLogDropPolicy,
+				int32(n.tableDesc.ID),
+				int32(params.evalCtx.NodeID),
+				&sqlbase.DropPolicyEvent{
+					TableName:  n.tableDesc.Name,
+					PolicyName: name,
+					Statement:  n.n.String(),
+					User:       params.p.session.User,
+				},
+			)
+		}
+	}
+	if n.n.IfExists {
+		return nil
+	}
+	return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+		"policy %q for table %q does not exist", name, n.tableDesc.Name)
+}
+
+func (n *dropPolicyNode) Next(runParams) (bool, error) { return false, nil }
+func (n *dropPolicyNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *dropPolicyNode) Close(context.Context)        {}
+
+// getPolicyTableDesc looks up the table whose policies are changed and checks
+// that the session user can change them.
+func (p *planner) getPolicyTableDesc(
+	ctx context.Context, name *tree.NormalizableTableName,
+) (*sqlbase.TableDescriptor, error) {
+	tn, err := name.NormalizeWithDatabaseName(p.session.Database)
+	if err != nil {
+		return nil, err
+	}
+	tableDesc, err := MustGetTableDesc(ctx, p.txn, p.getVirtualTabler(), tn, false /* allowAdding */)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckPrivilege(tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	return tableDesc, nil
+}
+
+// validatePolicyExpr checks that a policy expression is a valid boolean
+// expression over the columns of the table.
+func (p *planner) validatePolicyExpr(
+	ctx context.Context,
+	expr tree.Expr,
+	tableName *tree.NormalizableTableName,
+	tableDesc *sqlbase.TableDescriptor,
+) error {
+	// The expressions are also evaluated against the rows written by INSERT
+	// and UPDATE, outside of a query that can run sub-queries.
+	if _, err := tree.SimpleVisit(expr, func(e tree.Expr) (error, bool, tree.Expr) {
+		if _, ok := e.(*tree.Subquery); ok {
+			return pgerror.Unimplemented("policy subquery",
+				"sub-queries are not supported in policy expressions"), false, e
+		}
+		return nil, true, e
+	}); err != nil {
+		return err
+	}
+	tn, err := tableName.Normalize()
+	if err != nil {
+		return err
+	}
+	c := &checkHelper{
+		sourceInfo: newSourceInfoForSingleTable(
+			*tn, sqlbase.ResultColumnsFromColDescs(tableDesc.Columns),
+		),
+	}
+	ivarHelper := tree.MakeIndexedVarHelper(c, len(tableDesc.Columns))
+	typedExpr, err := p.analyzeExpr(ctx, expr, multiSourceInfo{c.sourceInfo}, ivarHelper,
+		types.Bool, true, "POLICY")
+	if err != nil {
+		return err
+	}
+	return p.txCtx.AssertNoAggregationOrWindowing(
+		typedExpr, "policy expressions", p.session.SearchPath,
+	)
+}
+
+// rowLevelSecurityExpr returns the expression the rows of the table accessed
+// by the given statement must satisfy, or nil if row-level security doesn't
+// apply, as for the owner of the table and the members of the owner role.
+// The rows read have to satisfy the USING expression of one of the
+// policies that apply to the statement and current user, and the rows
+// written their WITH CHECK expression, which defaults to the USING
+// expression. If no policy applies, no row can be accessed.
+func (p *planner) rowLevelSecurityExpr(
+	ctx context.Context, desc *sqlbase.TableDescriptor, command string, withCheck bool,
+) (tree.Expr, error) {
+	user := p.currentUser()
+	if !desc.RowLevelSecurity || user == security.RootUser || user == security.NodeUser {
+		return nil, nil
+	}
+	owner := desc.Privileges.GetOwner()
+	if user == owner {
+		return nil, nil
+	}
+	roles, err := p.inheritedRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		if role == owner {
+			return nil, nil
+		}
+	}
+	var exprStrings []string
+	for _, policy := range desc.Policies {
+		if policy.Command != policyCommandAll && policy.Command != command {
+			continue
+		}
+		if !policyAppliesTo(policy, user, roles) {
+			continue
+		}
+		expr := policy.UsingExpr
+		if withCheck && policy.WithCheckExpr != nil {
+			expr = policy.WithCheckExpr
+		}
+		if expr == nil {
+			// A policy without an expression lets all the rows through.
+			return tree.DBoolTrue, nil
+		}
+		exprStrings = append(exprStrings, *expr)
+	}
+	if len(exprStrings) == 0 {
+		return tree.DBoolFalse, nil
+	}
+	exprs, err := parser.ParseExprs(exprStrings)
+	if err != nil {
+		return nil, err
+	}
+	var result tree.Expr
+	for _, expr := range exprs {
+		expr = &tree.ParenExpr{Expr: expr}
+		if result == nil {
+			result = expr
+		} else {
+			result = &tree.OrExpr{Left: result, Right: expr}
+		}
+	}
+	return result, nil
+}
+
+// policyAppliesTo returns whether the policy applies to the user, which
+// inherits the privileges of the given roles.
+func policyAppliesTo(policy sqlbase.TableDescriptor_Policy, user string, roles []string) bool {
+	if len(policy.Roles) == 0 {
+		return true
+	}
+	for _, role := range policy.Roles {
+		if role == user {
+			return true
+		}
+		for _, r := range roles {
+			if role == r {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addRowLevelSecurityFilter restricts the WHERE clause of a DELETE or UPDATE
+// to the rows the policies of the table let it modify.
+func (p *planner) addRowLevelSecurityFilter(
+	ctx context.Context, desc *sqlbase.TableDescriptor, command string, where *tree.Where,
+) (*tree.Where, error) {
+	expr, err := p.rowLevelSecurityExpr(ctx, desc, command, false /* withCheck */)
+	if err != nil || expr == nil {
+		return where, err
+	}
+	if where != nil {
+		expr = &tree.AndExpr{Left: &tree.ParenExpr{Expr: where.Expr}, Right: expr}
+	}
+	return tree.NewWhere(tree.AstWhere, expr), nil
+}
+
+// addRowLevelSecurityScanFilter filters the rows of a table scan with the
+// SELECT policies of the table.
+func (p *planner) addRowLevelSecurityScanFilter(
+	ctx context.Context, desc *sqlbase.TableDescriptor, src planDataSource,
+) (planDataSource, error) {
+	expr, err := p.rowLevelSecurityExpr(ctx, desc, policyCommandSelect, false /* withCheck */)
+	if err != nil || expr == nil {
+		return src, err
+	}
+	f := &filterNode{source: src}
+	f.ivarHelper = tree.MakeIndexedVarHelper(f, len(src.info.sourceColumns))
+	f.filter, err = p.analyzeExpr(ctx, expr, multiSourceInfo{src.info}, f.ivarHelper,
+		types.Bool, true, "POLICY")
+	if err != nil {
+		return planDataSource{}, err
+	}
+	return planDataSource{info: src.info, plan: f}, nil
+}
@@ -248,6 +248,18 @@ type PreparedPortal struct {
 	memAcc WrappableMemoryAccount
 }
 
+// GrowMemory accounts for memory held by the protocol implementation on behalf
+// of the portal, such as results that haven't been sent to the client yet. The
+// memory is released by ShrinkMemory or when the portal is deleted.
+func (p *PreparedPortal) GrowMemory(ctx context.Context, s *Session, n int64) error {
+	return p.memAcc.Wsession(s).Grow(ctx, n)
+}
+
+// ShrinkMemory releases memory accounted for by GrowMemory.
+func (p *PreparedPortal) ShrinkMemory(ctx context.Context, s *Session, n int64) {
+	p.memAcc.Wsession(s).Shrink(ctx, n)
+}
+
 // PreparedPortals is a mapping of PreparedPortal names to their corresponding
 // PreparedPortals.
 type PreparedPortals struct {
@@ -39,6 +39,8 @@ const (
 	INSERT
 	DELETE
 	UPDATE
+	CONNECT
+	USAGE
 )
 
 // Predefined sets of privileges.
@@ -47,6 +49,33 @@ var (
 	ReadWriteData = List{GRANT, SELECT, INSERT, DELETE, UPDATE}
 )
 
+// ObjectType represents the types of objects on which privileges are granted.
+type ObjectType string
+
+// The types of objects on which privileges are granted.
+const (
+	Database ObjectType = "database"
+	Table    ObjectType = "table"
+)
+
+// The privileges that can be granted on each type of object. The privileges
+// on a database that can be granted on tables are also granted on the tables
+// created afterwards in the database. CONNECT allows using a database as the
+// current database, and USAGE allows accessing the objects of a database.
+var (
+	TablePrivileges    = List{ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE}
+	DatabasePrivileges = List{ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, CONNECT, USAGE}
+)
+
+// GetValidPrivilegesForObject returns the privileges that can be granted on
+// the given type of object.
+func GetValidPrivilegesForObject(objectType ObjectType) List {
+	if objectType == Database {
+		return DatabasePrivileges
+	}
+	return TablePrivileges
+}
+
 // Mask returns the bitmask for a given privilege.
 func (k Kind) Mask() uint32 {
 	return 1 << k
@@ -54,7 +83,16 @@ func (k Kind) Mask() uint32 {
 
 // ByValue is just an array of privilege kinds sorted by value.
 var ByValue = [...]Kind{
-	ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE,
+	ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, CONNECT, USAGE,
+}
+
+// byName maps the name of each privilege to the privilege.
+var byName = map[string]Kind{}
+
+func init() {
+	for _, p := range ByValue {
+		byName[p.String()] = p
+	}
 }
 
 // List is a list of privileges.
@@ -138,6 +176,21 @@ func ListFromBitField(m uint32) List {
 	return ret
 }
 
+// ListFromStrings takes a list of privilege names and returns the
+// corresponding list of privileges. Names are case insensitive. An error is
+// returned if a name is not the name of a privilege.
+func ListFromStrings(strs []string) (List, error) {
+	ret := make(List, len(strs))
+	for i, s := range strs {
+		k, ok := byName[strings.ToUpper(s)]
+		if !ok {
+			return nil, fmt.Errorf("invalid privilege type %s", strings.ToUpper(s))
+		}
+		ret[i] = k
+	}
+	return ret, nil
+}
+
 // Lists is a list of privilege lists
 type Lists []List
 
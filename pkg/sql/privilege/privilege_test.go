@@ -36,9 +36,10 @@ func TestPrivilegeDecode(t *testing.T) {
 		{144, privilege.List{privilege.GRANT, privilege.DELETE}, "GRANT, DELETE", "DELETE,GRANT"},
 		{2047,
 			privilege.List{privilege.ALL, privilege.CREATE, privilege.DROP, privilege.GRANT,
-				privilege.SELECT, privilege.INSERT, privilege.DELETE, privilege.UPDATE},
-			"ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE",
-			"ALL,CREATE,DELETE,DROP,GRANT,INSERT,SELECT,UPDATE",
+				privilege.SELECT, privilege.INSERT, privilege.DELETE, privilege.UPDATE,
+				privilege.CONNECT, privilege.USAGE},
+			"ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE, CONNECT, USAGE",
+			"ALL,CONNECT,CREATE,DELETE,DROP,GRANT,INSERT,SELECT,UPDATE,USAGE",
 		},
 	}
 
@@ -60,3 +61,18 @@ func TestPrivilegeDecode(t *testing.T) {
 		}
 	}
 }
+
+func TestListFromStrings(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	pl, err := privilege.ListFromStrings([]string{"select", "INSERT", "All"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "SELECT, INSERT, ALL"; pl.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, pl.String())
+	}
+	if _, err := privilege.ListFromStrings([]string{"select", "foo"}); err == nil ||
+		err.Error() != "invalid privilege type FOO" {
+		t.Fatalf("expected invalid privilege type error, got %v", err)
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// The regions of a multi-region database are the values of the region tier
+// of the localities of the nodes. Their placement is expressed with zone
+// configs constraining the replicas and the leases to the stores of a region:
+//
+// - the database, and hence its REGIONAL BY TABLE tables, are placed in the
+//   primary region;
+// - the replicas of GLOBAL tables are spread over all the regions, with
+//   their leases in the primary region;
+// - the primary index of REGIONAL BY ROW tables is partitioned by the region
+//   of its rows, stored in the crdb_region column, and each partition is
+//   placed in its region.
+const (
+	regionTierKey    = "region"
+	regionColumnName = "crdb_region"
+)
+
+// regionConstraints returns the constraints requiring the stores of the
+// given region.
+func regionConstraints(region string) []config.Constraint {
+	return []config.Constraint{{Type: config.Constraint_REQUIRED, Key: regionTierKey, Value: region}}
+}
+
+// AlterDatabaseSetPrimaryRegion sets the primary region of a database, adding
+// it to its regions if needed, and makes it multi-region.
+// Privileges: CREATE on the database.
+func (p *planner) AlterDatabaseSetPrimaryRegion(
+	ctx context.Context, n *tree.AlterDatabaseSetPrimaryRegion,
+) (planNode, error) {
+	dbDesc, err := p.getRegionDatabaseDesc(ctx, n.Name)
+	if err != nil {
+		return nil, err
+	}
+	region := n.Region.Normalize()
+	if dbDesc.RegionConfig == nil {
+		dbDesc.RegionConfig = &sqlbase.DatabaseDescriptor_RegionConfig{}
+	}
+	if dbDesc.RegionConfig.PrimaryRegion == region {
+		// Noop.
+		return &zeroNode{}, nil
+	}
+	addedRegion := !dbDesc.RegionConfig.HasRegion(region)
+	if addedRegion {
+		dbDesc.RegionConfig.Regions = append(dbDesc.RegionConfig.Regions, region)
+	}
+	dbDesc.RegionConfig.PrimaryRegion = region
+	if err := p.writeRegionConfig(ctx, n, dbDesc, addedRegion); err != nil {
+		return nil, err
+	}
+	return &zeroNode{}, nil
+}
+
+// AlterDatabaseAddRegion adds a region to a multi-region database.
+// Privileges: CREATE on the database.
+func (p *planner) AlterDatabaseAddRegion(
+	ctx context.Context, n *tree.AlterDatabaseAddRegion,
+) (planNode, error) {
+	dbDesc, err := p.getRegionDatabaseDesc(ctx, n.Name)
+	if err != nil {
+		return nil, err
+	}
+	region := n.Region.Normalize()
+	if dbDesc.RegionConfig == nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"database %s has no primary region; use ALTER DATABASE %s SET PRIMARY REGION first",
+			n.Name, n.Name)
+	}
+	if dbDesc.RegionConfig.HasRegion(region) {
+		return nil, pgerror.NewErrorf(pgerror.CodeDuplicateObjectError,
+			"region %q already exists in database %s", region, n.Name)
+	}
+	dbDesc.RegionConfig.Regions = append(dbDesc.RegionConfig.Regions, region)
+	if err := p.writeRegionConfig(ctx, n, dbDesc, true /* addedRegion */); err != nil {
+		return nil, err
+	}
+	return &zeroNode{}, nil
+}
+
+// getRegionDatabaseDesc returns the descriptor of the database whose regions
+// are changed, after checking they can be.
+func (p *planner) getRegionDatabaseDesc(
+	ctx context.Context, name tree.Name,
+) (*sqlbase.DatabaseDescriptor, error) {
+	if name == "" {
+		return nil, errEmptyDatabaseName
+	}
+	dbDesc, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), string(name))
+	if err != nil {
+		return nil, err
+	}
+	if isVirtualDescriptor(dbDesc) || sqlbase.IsReservedID(dbDesc.ID) {
+		return nil, pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+			"cannot change the regions of system database %s", name)
+	}
+	if err := p.CheckPrivilege(dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+	return dbDesc, nil
+}
+
+// writeRegionConfig writes the descriptor of a database whose regions
+// changed, and rewrites the zone configs of the database and of its tables
+// with a locality. When a region is added, the REGIONAL BY ROW tables are
+// also repartitioned to have a partition for it. The change is recorded in
+// the event log.
+func (p *planner) writeRegionConfig(
+	ctx context.Context, stmt tree.Statement, dbDesc *sqlbase.DatabaseDescriptor, addedRegion bool,
+) error {
+	if err := dbDesc.Validate(); err != nil {
+		return err
+	}
+	if err := p.txn.Put(ctx, sqlbase.MakeDescMetadataKey(dbDesc.ID), sqlbase.WrapDescriptor(dbDesc)); err != nil {
+		return err
+	}
+	if err := p.writeDatabaseRegionZoneConfig(ctx, dbDesc); err != nil {
+		return err
+	}
+
+	descs, err := getAllDescriptors(ctx, p.txn)
+	if err != nil {
+		return err
+	}
+	for _, desc := range descs {
+		tableDesc, ok := desc.(*sqlbase.TableDescriptor)
+		if !ok || tableDesc.ParentID != dbDesc.ID || tableDesc.Dropped() || !tableDesc.IsTable() {
+			continue
+		}
+		switch tableDesc.Locality {
+		case sqlbase.TableDescriptor_REGIONAL_BY_TABLE:
+			// The table inherits the zone config of the database.
+			continue
+		case sqlbase.TableDescriptor_REGIONAL_BY_ROW:
+			if addedRegion {
+				if err := partitionByRegion(ctx, &p.evalCtx, dbDesc, tableDesc); err != nil {
+					return err
+				}
+				if err := tableDesc.SetUpVersion(); err != nil {
+					return err
+				}
+				if err := p.writeTableDesc(ctx, tableDesc); err != nil {
+					return err
+				}
+				p.notifySchemaChange(tableDesc, sqlbase.InvalidMutationID)
+			}
+		}
+		if err := p.writeTableLocalityZoneConfig(ctx, dbDesc, tableDesc); err != nil {
+			return err
+		}
+	}
+
+	// Record this database alteration in the event log. This is an auditable
+	// log event and is recorded in the same transaction as the descriptor
+	// update.
+	return MakeEventLogger(p.LeaseMgr()).InsertEventRecord(
+		ctx,
+		p.txn,
+		EventLogAlterDatabase,
+		int32(dbDesc.ID),
+		int32(p.evalCtx.NodeID),
+		&sqlbase.AlterDatabaseEvent{
+			DatabaseName: dbDesc.Name,
+			Statement:    stmt.String(),
+			User:         p.session.User,
+		},
+	)
+}
+
+// getInheritedZoneConfig returns the zone config that applies to the object
+// with the given ID, without its subzones.
+func getInheritedZoneConfig(
+	ctx context.Context, txn *client.Txn, id sqlbase.ID,
+) (config.ZoneConfig, error) {
+	_, zone, _, err := GetZoneConfigInTxn(ctx, txn, uint32(id), nil, "")
+	if err == errNoZoneConfigApplies {
+		return config.DefaultZoneConfig(), nil
+	} else if err != nil {
+		return config.ZoneConfig{}, err
+	}
+	zone.Subzones = nil
+	zone.SubzoneSpans = nil
+	return zone, nil
+}
+
+// writeDatabaseRegionZoneConfig places the replicas and the leases of a
+// multi-region database in its primary region.
+func (p *planner) writeDatabaseRegionZoneConfig(
+	ctx context.Context, dbDesc *sqlbase.DatabaseDescriptor,
+) error {
+	zone, err := getInheritedZoneConfig(ctx, p.txn, dbDesc.ID)
+	if err != nil {
+		return err
+	}
+	primary := dbDesc.RegionConfig.PrimaryRegion
+	zone.Constraints = config.Constraints{Constraints: regionConstraints(primary)}
+	zone.LeasePreferences = []config.LeasePreference{{Constraints: regionConstraints(primary)}}
+	if err := zone.Validate(); err != nil {
+		return fmt.Errorf("could not validate zone config: %s", err)
+	}
+	_, err = p.writeZoneConfig(ctx, dbDesc.ID, nil /* table */, zone)
+	return err
+}
+
+// writeTableLocalityZoneConfig replaces the zone configs of a table of a
+// multi-region database with those of its locality.
+func (p *planner) writeTableLocalityZoneConfig(
+	ctx context.Context, dbDesc *sqlbase.DatabaseDescriptor, tableDesc *sqlbase.TableDescriptor,
+) error {
+	var zone config.ZoneConfig
+	switch tableDesc.Locality {
+	case sqlbase.TableDescriptor_REGIONAL_BY_TABLE:
+		// The table zone config is removed for the table to inherit the zone
+		// config of the database.
+		_, err := p.writeZoneConfig(ctx, tableDesc.ID, tableDesc, zone)
+		return err
+
+	case sqlbase.TableDescriptor_GLOBAL:
+		var err error
+		zone, err = getInheritedZoneConfig(ctx, p.txn, dbDesc.ID)
+		if err != nil {
+			return err
+		}
+		primary := dbDesc.RegionConfig.PrimaryRegion
+		zone.Constraints = config.Constraints{}
+		if n := int32(len(dbDesc.RegionConfig.Regions)); zone.NumReplicas < n {
+			zone.NumReplicas = n
+		}
+		zone.LeasePreferences = []config.LeasePreference{{Constraints: regionConstraints(primary)}}
+
+	case sqlbase.TableDescriptor_REGIONAL_BY_ROW:
+		dbZone, err := getInheritedZoneConfig(ctx, p.txn, dbDesc.ID)
+		if err != nil {
+			return err
+		}
+		// The table zone config is a placeholder for the subzones of the
+		// partitions of the primary index.
+		for _, region := range dbDesc.RegionConfig.Regions {
+			partZone := dbZone
+			partZone.Constraints = config.Constraints{Constraints: regionConstraints(region)}
+			partZone.LeasePreferences = []config.LeasePreference{{Constraints: regionConstraints(region)}}
+			zone.SetSubzone(config.Subzone{
+				IndexID:       uint32(tableDesc.PrimaryIndex.ID),
+				PartitionName: region,
+				Config:        partZone,
+			})
+		}
+	}
+	if err := zone.Validate(); err != nil {
+		return fmt.Errorf("could not validate zone config: %s", err)
+	}
+	_, err := p.writeZoneConfig(ctx, tableDesc.ID, tableDesc, zone)
+	return err
+}
+
+// setTableLocality sets the locality of a table of a multi-region database.
+// The primary index of a REGIONAL BY ROW table, which must start with the
+// crdb_region column, is partitioned by region; the other localities don't
+// partition the table.
+func setTableLocality(
+	ctx context.Context,
+	evalCtx *tree.EvalContext,
+	dbDesc *sqlbase.DatabaseDescriptor,
+	tableDesc *sqlbase.TableDescriptor,
+	locality tree.Locality,
+) error {
+	if dbDesc.RegionConfig == nil {
+		return pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"cannot set the locality of table %s: database %s has no regions",
+			tableDesc.Name, dbDesc.Name)
+	}
+	primary := &tableDesc.PrimaryIndex
+	if tableDesc.Locality != sqlbase.TableDescriptor_REGIONAL_BY_ROW &&
+		primary.Partitioning.NumColumns > 0 {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot set the locality of partitioned table %s", tableDesc.Name)
+	}
+
+	switch locality {
+	case tree.LocalityRegionalByRow:
+		if len(primary.ColumnNames) == 0 || primary.ColumnNames[0] != regionColumnName {
+			return pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+				"the primary key of REGIONAL BY ROW table %s must start with column %s",
+				tableDesc.Name, regionColumnName)
+		}
+		col, err := tableDesc.FindActiveColumnByName(regionColumnName)
+		if err != nil {
+			return err
+		}
+		if col.Type.SemanticType != sqlbase.ColumnType_STRING {
+			return pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+				"column %s of REGIONAL BY ROW table %s must be of type STRING, not %s",
+				regionColumnName, tableDesc.Name, col.Type.SQLString())
+		}
+		tableDesc.Locality = sqlbase.TableDescriptor_REGIONAL_BY_ROW
+		return partitionByRegion(ctx, evalCtx, dbDesc, tableDesc)
+	case tree.LocalityGlobal:
+		tableDesc.Locality = sqlbase.TableDescriptor_GLOBAL
+	default:
+		tableDesc.Locality = sqlbase.TableDescriptor_REGIONAL_BY_TABLE
+	}
+	primary.Partitioning = sqlbase.PartitioningDescriptor{}
+	return nil
+}
+
+// partitionByRegion partitions the primary index of a REGIONAL BY ROW table
+// by its crdb_region column, with a partition named after each region of the
+// database.
+func partitionByRegion(
+	ctx context.Context,
+	evalCtx *tree.EvalContext,
+	dbDesc *sqlbase.DatabaseDescriptor,
+	tableDesc *sqlbase.TableDescriptor,
+) error {
+	partBy := &tree.PartitionBy{Fields: tree.NameList{regionColumnName}}
+	for _, region := range dbDesc.RegionConfig.Regions {
+		partBy.List = append(partBy.List, tree.ListPartition{
+			Name:  tree.UnrestrictedName(region),
+			Exprs: tree.Exprs{tree.NewStrVal(region)},
+		})
+	}
+	var partDesc sqlbase.PartitioningDescriptor
+	if err := addPartitionedBy(
+		ctx, evalCtx, tableDesc, &tableDesc.PrimaryIndex, &partDesc, partBy, 0, /* colOffset */
+	); err != nil {
+		return err
+	}
+	tableDesc.PrimaryIndex.Partitioning = partDesc
+	return nil
+}
@@ -23,7 +23,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -94,18 +97,79 @@ func (p *planner) RenameDatabase(ctx context.Context, n *tree.RenameDatabase) (p
 					return nil, sqlbase.NewDependentObjectError(msg)
 				}
 			}
-			msg := fmt.Sprintf("cannot rename database because view %q depends on table %q", viewName, tbDesc.Name)
+			msg := fmt.Sprintf("cannot rename database because %s %q depends on %s %q",
+				viewDesc.TypeName(), viewName, tbDesc.TypeName(), tbDesc.Name)
+			if !viewDesc.IsView() {
+				return nil, sqlbase.NewDependentObjectError(msg)
+			}
 			hint := fmt.Sprintf("you can drop %s instead.", viewName)
 			return nil, sqlbase.NewDependentObjectErrorWithHint(msg, hint)
 		}
 	}
 
+	if err := p.checkDatabaseNotInUse(ctx, dbDesc.Name); err != nil {
+		return nil, err
+	}
+
 	if err := p.renameDatabase(ctx, dbDesc, string(n.NewName)); err != nil {
 		return nil, err
 	}
+
+	// Log Rename Database event. This is an auditable log event and is
+	// recorded in the same transaction as the database descriptor update.
+	if err := MakeEventLogger(p.LeaseMgr()).InsertEventRecord(
+		ctx,
+		p.txn,
+		EventLogRenameDatabase,
+		int32(dbDesc.ID),
+		int32(p.evalCtx.NodeID),
+		&sqlbase.RenameDatabaseEvent{
+			DatabaseName:    n.Name.String(),
+			NewDatabaseName: n.NewName.String(),
+			Statement:       n.String(),
+			User:            p.session.User,
+		},
+	); err != nil {
+		return nil, err
+	}
 	return &zeroNode{}, nil
 }
 
+// checkDatabaseNotInUse returns an error if the database is the current
+// database of a session. The session would otherwise keep resolving names
+// in a database that doesn't exist anymore, or in another database that
+// is later created with the same name. Sessions that change their current
+// database while the rename runs are not detected.
+func (p *planner) checkDatabaseNotInUse(ctx context.Context, dbName string) error {
+	if p.session.Database == dbName {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"current database cannot be renamed")
+	}
+	response, err := p.session.execCfg.StatusServer.ListSessions(
+		ctx, &serverpb.ListSessionsRequest{Username: security.RootUser},
+	)
+	if err != nil {
+		return err
+	}
+	for _, rpcErr := range response.Errors {
+		// The sessions of the nodes that cannot be reached cannot be checked.
+		log.Warningf(ctx, "cannot check the sessions of node %d before renaming database %q: %s",
+			rpcErr.NodeID, dbName, rpcErr.Message)
+	}
+	numSessions := 0
+	for _, session := range response.Sessions {
+		if session.Database == dbName && session.ID != p.session.id {
+			numSessions++
+		}
+	}
+	if numSessions > 0 {
+		return pgerror.NewErrorf(pgerror.CodeObjectInUseError,
+			"database %q is being accessed by other users", dbName).SetDetailf(
+			"There are %d other session(s) using the database.", numSessions)
+	}
+	return nil
+}
+
 // RenameTable renames the table, view or sequence.
 // Privileges: DROP on source table/view/sequence, CREATE on destination database.
 //   Notes: postgres requires the table owner.
@@ -240,6 +304,23 @@ func (p *planner) RenameTable(ctx context.Context, n *tree.RenameTable) (planNod
 		return expectDescriptor(systemConfig, descKey, descDesc)
 	})
 
+	// Log Rename Table event. This is an auditable log event and is recorded
+	// in the same transaction as the table descriptor update.
+	if err := MakeEventLogger(p.LeaseMgr()).InsertEventRecord(
+		ctx,
+		p.txn,
+		EventLogRenameTable,
+		int32(descID),
+		int32(p.evalCtx.NodeID),
+		&sqlbase.RenameTableEvent{
+			TableName:    oldTn.String(),
+			NewTableName: newTn.String(),
+			Statement:    n.String(),
+			User:         p.session.User,
+		},
+	); err != nil {
+		return nil, err
+	}
 	return &zeroNode{}, nil
 }
 
@@ -305,6 +386,9 @@ func (p *planner) RenameIndex(ctx context.Context, n *tree.RenameIndex) (planNod
 	if err := p.txn.Put(ctx, descKey, sqlbase.WrapDescriptor(tableDesc)); err != nil {
 		return nil, err
 	}
+	if err := p.logRenameInTableEvent(ctx, n, tableDesc); err != nil {
+		return nil, err
+	}
 	p.notifySchemaChange(tableDesc, sqlbase.InvalidMutationID)
 	return &zeroNode{}, nil
 }
@@ -402,6 +486,22 @@ func (p *planner) RenameColumn(ctx context.Context, n *tree.RenameColumn) (planN
 			tableDesc.Checks[i].Expr = after
 		}
 	}
+	for i := range tableDesc.Policies {
+		policy := &tableDesc.Policies[i]
+		for _, s := range []*string{policy.UsingExpr, policy.WithCheckExpr} {
+			if s == nil {
+				continue
+			}
+			expr, err := parser.ParseExpr(*s)
+			if err != nil {
+				return nil, err
+			}
+			if expr, err = tree.SimpleVisit(expr, preFn); err != nil {
+				return nil, err
+			}
+			*s = expr.String()
+		}
+	}
 	// Rename the column in the indexes.
 	tableDesc.RenameColumnDescriptor(col, string(n.NewName))
 
@@ -416,10 +516,33 @@ func (p *planner) RenameColumn(ctx context.Context, n *tree.RenameColumn) (planN
 	if err := p.txn.Put(ctx, descKey, sqlbase.WrapDescriptor(tableDesc)); err != nil {
 		return nil, err
 	}
+	if err := p.logRenameInTableEvent(ctx, n, tableDesc); err != nil {
+		return nil, err
+	}
 	p.notifySchemaChange(tableDesc, sqlbase.InvalidMutationID)
 	return &zeroNode{}, nil
 }
 
+// logRenameInTableEvent records the renaming of a column or an index of a
+// table in the event log. This is an auditable log event and is recorded in
+// the same transaction as the table descriptor update.
+func (p *planner) logRenameInTableEvent(
+	ctx context.Context, stmt tree.Statement, tableDesc *sqlbase.TableDescriptor,
+) error {
+	return MakeEventLogger(p.LeaseMgr()).InsertEventRecord(
+		ctx,
+		p.txn,
+		EventLogAlterTable,
+		int32(tableDesc.ID),
+		int32(p.evalCtx.NodeID),
+		&sqlbase.AlterTableEvent{
+			TableName: tableDesc.Name,
+			Statement: stmt.String(),
+			User:      p.session.User,
+		},
+	)
+}
+
 // TODO(a-robinson): Support renaming objects depended on by views once we have
 // a better encoding for view queries (#10083).
 func (p *planner) dependentViewRenameError(
@@ -440,8 +563,12 @@ func (p *planner) dependentViewRenameError(
 			return sqlbase.NewDependentObjectError(msg)
 		}
 	}
-	msg := fmt.Sprintf("cannot rename %s %q because view %q depends on it",
-		typeName, objName, viewName)
+	msg := fmt.Sprintf("cannot rename %s %q because %s %q depends on it",
+		typeName, objName, viewDesc.TypeName(), viewName)
+	if !viewDesc.IsView() {
+		// A table uses the sequence being renamed in a default expression.
+		return sqlbase.NewDependentObjectError(msg)
+	}
 	hint := fmt.Sprintf("you can drop %s instead.", viewName)
 	return sqlbase.NewDependentObjectErrorWithHint(msg, hint)
 }
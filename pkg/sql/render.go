@@ -273,6 +273,9 @@ func (p *planner) SelectClause(
 	if groupComplex != nil {
 		// group.plan is already r.
 		result = groupComplex
+		if window == nil && distinctPlan == nil {
+			p.maybeCountFromRangeStats(parsed, r, group, groupComplex)
+		}
 	}
 	if window != nil {
 		window.plan = result
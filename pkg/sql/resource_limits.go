@@ -0,0 +1,167 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+)
+
+// This file contains the per-statement resource limits that can be
+// configured on a session through the statement_timeout,
+// statement_max_rows_read and statement_max_memory variables. Each limit is
+// disabled when set to zero and, when exceeded, fails the statement with an
+// error that names the limit.
+
+// newStatementTimeoutError is returned for statements canceled because they
+// ran for longer than statement_timeout.
+func newStatementTimeoutError(timeout time.Duration) error {
+	return pgerror.NewErrorf(pgerror.CodeQueryCanceledError,
+		"query execution canceled due to statement timeout (%s)", timeout)
+}
+
+// newRowsReadLimitError is returned for statements that read more rows than
+// statement_max_rows_read.
+func newRowsReadLimitError(limit int64) error {
+	return pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+		"statement read more than %d rows (statement_max_rows_read)", limit)
+}
+
+// startStatementTimer arranges for the query to be canceled if it runs for
+// longer than the session's statement_timeout. The returned function must be
+// called once the statement is done executing, with the statement's error;
+// it returns the error to report to the client.
+func startStatementTimer(session *Session, q *queryMeta) func(error) error {
+	timeout := session.StatementTimeout
+	if timeout <= 0 || q == nil {
+		return func(err error) error { return err }
+	}
+	timer := time.AfterFunc(timeout, q.cancel)
+	return func(err error) error {
+		if !timer.Stop() && err != nil {
+			// The timer fired, so the error is the consequence of the
+			// cancellation.
+			return newStatementTimeoutError(timeout)
+		}
+		return err
+	}
+}
+
+// countRowRead accounts for a row read by the statement being planned by p and
+// returns an error if this exceeds statement_max_rows_read.
+func (p *planner) countRowRead() error {
+	p.rowsRead++
+	if limit := p.session.StatementMaxRowsRead; limit > 0 && p.rowsRead > limit {
+		return newRowsReadLimitError(limit)
+	}
+	return nil
+}
+
+// evalLimitVal evaluates the value of a SET statement for one of the resource
+// limit variables.
+func evalLimitVal(session *Session, name string, values []tree.TypedExpr) (tree.Datum, error) {
+	if len(values) != 1 {
+		return nil, fmt.Errorf("set %s: requires a single value", name)
+	}
+	evalCtx := session.evalCtx()
+	d, err := values[0].Eval(&evalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return tree.UnwrapDatum(&evalCtx, d), nil
+}
+
+// setStatementTimeout implements SET statement_timeout. As in PostgreSQL,
+// integers are milliseconds; strings and intervals are durations.
+func setStatementTimeout(_ context.Context, session *Session, values []tree.TypedExpr) error {
+	d, err := evalLimitVal(session, `statement_timeout`, values)
+	if err != nil {
+		return err
+	}
+	var timeout time.Duration
+	switch v := d.(type) {
+	case *tree.DInt:
+		timeout = time.Duration(*v) * time.Millisecond
+	case *tree.DString:
+		if ms, err := strconv.ParseInt(string(*v), 10, 64); err == nil {
+			timeout = time.Duration(ms) * time.Millisecond
+		} else if timeout, err = time.ParseDuration(string(*v)); err != nil {
+			return fmt.Errorf("set statement_timeout: invalid duration %q", string(*v))
+		}
+	case *tree.DInterval:
+		nanos, _, _, err := v.Duration.Encode()
+		if err != nil {
+			return err
+		}
+		timeout = time.Duration(nanos)
+	default:
+		return fmt.Errorf("set statement_timeout: bad value: %s", d)
+	}
+	if timeout < 0 {
+		return fmt.Errorf("set statement_timeout: cannot be negative")
+	}
+	session.StatementTimeout = timeout
+	return nil
+}
+
+// setStatementMaxRowsRead implements SET statement_max_rows_read.
+func setStatementMaxRowsRead(_ context.Context, session *Session, values []tree.TypedExpr) error {
+	d, err := evalLimitVal(session, `statement_max_rows_read`, values)
+	if err != nil {
+		return err
+	}
+	v, ok := d.(*tree.DInt)
+	if !ok {
+		return fmt.Errorf("set statement_max_rows_read: requires an integer value: %s is a %s",
+			d, d.ResolvedType())
+	}
+	if *v < 0 {
+		return fmt.Errorf("set statement_max_rows_read: cannot be negative")
+	}
+	session.StatementMaxRowsRead = int64(*v)
+	return nil
+}
+
+// setStatementMaxMemory implements SET statement_max_memory. Integers are
+// bytes; strings are sizes such as '64MiB'.
+func setStatementMaxMemory(_ context.Context, session *Session, values []tree.TypedExpr) error {
+	d, err := evalLimitVal(session, `statement_max_memory`, values)
+	if err != nil {
+		return err
+	}
+	var limit int64
+	switch v := d.(type) {
+	case *tree.DInt:
+		limit = int64(*v)
+	case *tree.DString:
+		if limit, err = humanizeutil.ParseBytes(string(*v)); err != nil {
+			return fmt.Errorf("set statement_max_memory: invalid size %q", string(*v))
+		}
+	default:
+		return fmt.Errorf("set statement_max_memory: bad value: %s", d)
+	}
+	if limit < 0 {
+		return fmt.Errorf("set statement_max_memory: cannot be negative")
+	}
+	session.StatementMaxMemory = limit
+	return nil
+}
@@ -133,6 +133,19 @@ type ResultsGroup interface {
 	// imposed by this interface.
 	ResultsSentToClient() bool
 
+	// StreamResults informs the ResultsGroup that the caller will not Reset()
+	// the results produced from now on until the next Flush() or Close(),
+	// typically because the statements producing them can't be retried
+	// automatically. The implementation is then free to send results to the
+	// consumer as soon as they're produced instead of accumulating them.
+	//
+	// Implementations may also decide to start streaming by themselves once
+	// they have sent some results past the last Flush() point, since
+	// ResultsSentToClient() returns true from then on and the caller is not
+	// allowed to Reset() the group anyway. Either way, the caller learns that
+	// it has lost the ability to retry through ResultsSentToClient().
+	StreamResults()
+
 	// Reset discards all the accumulated results from the last Flush() call
 	// onwards (or from the moment the group was created if Flush() was never
 	// called).
@@ -154,16 +167,19 @@ type StatementResult interface {
 	// results that should be sent to this interface.
 	StatementType() tree.StatementType
 	// SetColumns should be called after BeginResult and before AddRow if the
-	// StatementType is tree.Rows.
+	// StatementType is tree.Rows or tree.CopyOut.
 	SetColumns(columns sqlbase.ResultColumns)
 	// AddRow takes the passed in row and adds it to the current result.
 	AddRow(ctx context.Context, row tree.Datums) error
 	// IncrementRowsAffected increments a counter by n. This is used for all
-	// result types other than tree.Rows.
+	// result types other than tree.Rows and tree.CopyOut.
 	IncrementRowsAffected(n int)
 	// RowsAffected returns either the number of times AddRow was called, or the
 	// sum of all n passed into IncrementRowsAffected.
 	RowsAffected() int
+	// AddNotice attaches a notice to the current result. It is called before
+	// CloseResult.
+	AddNotice(n Notice)
 	// CloseResult ends the current result. The v3Conn will send control codes to
 	// the client informing it that the result for a statement is now complete.
 	//
@@ -214,6 +230,10 @@ func (b *bufferedWriter) ResultsSentToClient() bool {
 	return false
 }
 
+// StreamResults implements the ResultsGroup interface. A bufferedWriter never
+// sends anything to a client, so this is a no-op.
+func (b *bufferedWriter) StreamResults() {}
+
 // Close implements the ResultsGroup interface.
 func (b *bufferedWriter) Close() {
 	// TODO(andrei): The work that's duplicated from CloseResult() should not be
@@ -267,7 +287,7 @@ func (b *bufferedWriter) SetColumns(columns sqlbase.ResultColumns) {
 	}
 	b.currentResult.Columns = columns
 
-	if b.currentResult.Type == tree.Rows {
+	if typ := b.currentResult.Type; typ == tree.Rows || typ == tree.CopyOut {
 		b.currentResult.Rows = sqlbase.NewRowContainer(
 			b.acc, sqlbase.ColTypeInfoFromResCols(columns), 0,
 		)
@@ -276,7 +296,7 @@ func (b *bufferedWriter) SetColumns(columns sqlbase.ResultColumns) {
 
 // RowsAffected implements the StatementResult interface.
 func (b *bufferedWriter) RowsAffected() int {
-	if b.currentResult.Type == tree.Rows {
+	if typ := b.currentResult.Type; typ == tree.Rows || typ == tree.CopyOut {
 		return b.currentResult.Rows.Len()
 	}
 	return b.currentResult.RowsAffected
@@ -305,6 +325,14 @@ func (b *bufferedWriter) IncrementRowsAffected(n int) {
 	b.currentResult.RowsAffected += n
 }
 
+// AddNotice implements the StatementResult interface.
+func (b *bufferedWriter) AddNotice(n Notice) {
+	if !b.resultInProgress {
+		panic("no result in progress")
+	}
+	b.currentResult.Notices = append(b.currentResult.Notices, n)
+}
+
 // AddRow implements the StatementResult interface.
 func (b *bufferedWriter) AddRow(ctx context.Context, row tree.Datums) error {
 	if !b.resultInProgress {
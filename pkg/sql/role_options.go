@@ -0,0 +1,93 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// The system.role_options options set by CREATE ROLE. A user or role without
+// them can log in and inherits the privileges of its roles.
+const (
+	roleOptionNoLogin   = "NOLOGIN"
+	roleOptionNoInherit = "NOINHERIT"
+)
+
+// GetUserLoginOptions returns whether the given user is allowed to log in,
+// and the limit set with CONNECTION LIMIT for it, or nil if there is none.
+func GetUserLoginOptions(
+	ctx context.Context, executor *Executor, metrics *MemoryMetrics, username string,
+) (canLogin bool, connectionLimit *int64, err error) {
+	normalizedUsername := tree.Name(username).Normalize()
+	if normalizedUsername == security.RootUser {
+		return true, nil, nil
+	}
+
+	canLogin = true
+	err = executor.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		canLogin, connectionLimit = true, nil
+		p := makeInternalPlanner("get-login-options", txn, security.RootUser, metrics)
+		defer finishInternalPlanner(p)
+		const getLoginOptions = `SELECT option, value FROM system.role_options ` +
+			`WHERE username = $1 AND option IN ($2, $3)`
+		rows, err := p.queryRows(ctx, getLoginOptions,
+			normalizedUsername, roleOptionNoLogin, roleOptionConnectionLimit)
+		if err != nil {
+			return errors.Errorf("error looking up user %s", normalizedUsername)
+		}
+		for _, row := range rows {
+			switch tree.MustBeDString(row[0]) {
+			case roleOptionNoLogin:
+				canLogin = false
+			case roleOptionConnectionLimit:
+				if row[1] == tree.DNull {
+					continue
+				}
+				l, err := strconv.ParseInt(string(tree.MustBeDString(row[1])), 10, 64)
+				if err != nil {
+					return errors.Wrapf(err, "invalid connection limit for user %s", normalizedUsername)
+				}
+				connectionLimit = &l
+			}
+		}
+		return nil
+	})
+	return canLogin, connectionLimit, err
+}
+
+// setRoleOption records an option of a user or role in system.role_options.
+// The value is NULL for the options without a value.
+func setRoleOption(
+	params runParams, normalizedUsername string, option string, value interface{},
+) error {
+	internalExecutor := InternalExecutor{LeaseManager: params.p.LeaseMgr()}
+	_, err := internalExecutor.ExecuteStatementInTransaction(
+		params.ctx,
+		"set-role-option",
+		params.p.txn,
+		"UPSERT INTO system.role_options VALUES ($1, $2, $3)",
+		normalizedUsername,
+		option,
+		value,
+	)
+	return err
+}
@@ -0,0 +1,310 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// The system.role_options options holding the quotas set with ALTER USER ...
+// QUOTA.
+const (
+	roleOptionMaxQueriesPerSecond     = "MAX QUERIES PER SECOND"
+	roleOptionMaxRowsReadPerSecond    = "MAX ROWS READ PER SECOND"
+	roleOptionMaxConcurrentStatements = "MAX CONCURRENT STATEMENTS"
+)
+
+// roleQuotaOptions maps the names of the quotas in ALTER USER ... QUOTA to
+// their system.role_options option.
+var roleQuotaOptions = map[string]string{
+	"max_queries_per_second":    roleOptionMaxQueriesPerSecond,
+	"max_rows_read_per_second":  roleOptionMaxRowsReadPerSecond,
+	"max_concurrent_statements": roleOptionMaxConcurrentStatements,
+}
+
+// roleQuotaOption validates a quota set with ALTER USER ... QUOTA and returns
+// its system.role_options option. As for CONNECTION LIMIT, -1 removes the
+// quota.
+func roleQuotaOption(quota tree.Name, limit int64) (string, error) {
+	name := quota.Normalize()
+	option, ok := roleQuotaOptions[name]
+	if !ok {
+		names := make([]string, 0, len(roleQuotaOptions))
+		for n := range roleQuotaOptions {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return "", pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"unknown quota %q; valid quotas are %s", name, strings.Join(names, ", "))
+	}
+	if limit < -1 || limit == 0 {
+		return "", pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"invalid %s quota: %d", name, limit)
+	}
+	return option, nil
+}
+
+// RoleQuota holds the quotas set on a role (or user) with ALTER USER ...
+// QUOTA. Each limit is disabled when zero. The quotas apply to the sessions
+// of the role and of its members on each node, which share them.
+type RoleQuota struct {
+	Role                    string
+	MaxQueriesPerSecond     int64
+	MaxRowsReadPerSecond    int64
+	MaxConcurrentStatements int64
+}
+
+// GetUserRoleQuotas returns the quotas the sessions of the given user are
+// subject to: those of the user and of the roles it is a member of, directly
+// or not. Like the statement restrictions, they are read when the user
+// connects. Root is not subject to quotas.
+func GetUserRoleQuotas(
+	ctx context.Context, executor *Executor, metrics *MemoryMetrics, username string,
+) ([]RoleQuota, error) {
+	normalizedUsername := tree.Name(username).Normalize()
+	if normalizedUsername == security.RootUser {
+		return nil, nil
+	}
+
+	var quotas []RoleQuota
+	err := executor.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		quotas = nil
+		p := makeInternalPlanner("get-role-quotas", txn, security.RootUser, metrics)
+		defer finishInternalPlanner(p)
+		const getRoleQuotas = `SELECT username, option, value FROM system.role_options ` +
+			`WHERE option IN ($1, $2, $3) ORDER BY username`
+		rows, err := p.queryRows(ctx, getRoleQuotas, roleOptionMaxQueriesPerSecond,
+			roleOptionMaxRowsReadPerSecond, roleOptionMaxConcurrentStatements)
+		if err != nil {
+			return errors.Errorf("error looking up the quotas of user %s", normalizedUsername)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		members, err := p.queryRows(ctx, `SELECT role, member FROM system.role_members`)
+		if err != nil {
+			return errors.Errorf("error looking up the roles of user %s", normalizedUsername)
+		}
+		g := roleGraph{memberships: make(map[string][]roleMembership)}
+		for _, row := range members {
+			g.addMembership(roleMembership{
+				role:   string(tree.MustBeDString(row[0])),
+				member: string(tree.MustBeDString(row[1])),
+			})
+		}
+		roles := g.memberOf(normalizedUsername, false /* inheritingOnly */)
+		roles[normalizedUsername] = false
+
+		for _, row := range rows {
+			role := string(tree.MustBeDString(row[0]))
+			if _, ok := roles[role]; !ok || row[2] == tree.DNull {
+				continue
+			}
+			limit, err := strconv.ParseInt(string(tree.MustBeDString(row[2])), 10, 64)
+			if err != nil {
+				return errors.Wrapf(err, "invalid quota for role %s", role)
+			}
+			if len(quotas) == 0 || quotas[len(quotas)-1].Role != role {
+				quotas = append(quotas, RoleQuota{Role: role})
+			}
+			q := &quotas[len(quotas)-1]
+			switch tree.MustBeDString(row[1]) {
+			case roleOptionMaxQueriesPerSecond:
+				q.MaxQueriesPerSecond = limit
+			case roleOptionMaxRowsReadPerSecond:
+				q.MaxRowsReadPerSecond = limit
+			case roleOptionMaxConcurrentStatements:
+				q.MaxConcurrentStatements = limit
+			}
+		}
+		return nil
+	})
+	return quotas, err
+}
+
+// hasRowsReadQuota returns whether one of the quotas limits the rows read.
+// The statements subject to it are not distributed and their plans are not
+// run in parallel, since the rows read remotely or by the parallel branches
+// are not accounted for.
+func hasRowsReadQuota(quotas []RoleQuota) bool {
+	for _, q := range quotas {
+		if q.MaxRowsReadPerSecond > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// roleQuotaBuckets is the state of the quotas of a role on a node.
+type roleQuotaBuckets struct {
+	// queries and rows are the tokens of the token buckets limiting the
+	// queries and the rows read per second. They are refilled at the rate of
+	// the quota, up to a second's worth of tokens. rows goes negative when a
+	// statement reads more rows than are available; the following statements
+	// wait until the debt is paid.
+	queries, rows float64
+	refilled      time.Time
+	// running is the number of statements holding a concurrency slot.
+	running int64
+}
+
+// refill adds the tokens accumulated since the last refill.
+func (b *roleQuotaBuckets) refill(q RoleQuota, now time.Time) {
+	elapsed := now.Sub(b.refilled).Seconds()
+	b.refilled = now
+	if elapsed <= 0 {
+		return
+	}
+	if q.MaxQueriesPerSecond > 0 {
+		rate := float64(q.MaxQueriesPerSecond)
+		b.queries = math.Min(b.queries+elapsed*rate, math.Max(rate, 1))
+	}
+	if q.MaxRowsReadPerSecond > 0 {
+		rate := float64(q.MaxRowsReadPerSecond)
+		b.rows = math.Min(b.rows+elapsed*rate, rate)
+	}
+}
+
+// full returns whether the buckets hold as many tokens as they can, in which
+// case they don't need to be remembered.
+func (b *roleQuotaBuckets) full(q RoleQuota) bool {
+	return b.running == 0 &&
+		(q.MaxQueriesPerSecond <= 0 || b.queries >= math.Max(float64(q.MaxQueriesPerSecond), 1)) &&
+		(q.MaxRowsReadPerSecond <= 0 || b.rows >= float64(q.MaxRowsReadPerSecond))
+}
+
+// roleQuotaLimiter enforces the role quotas on a node: every statement takes
+// a token from the bucket of queries of each role it is subject to and, once
+// done executing, as many tokens from the bucket of rows as it read rows.
+type roleQuotaLimiter struct {
+	mu struct {
+		syncutil.Mutex
+		roles map[string]*roleQuotaBuckets
+	}
+}
+
+// tryAdmit admits a statement subject to the given quotas if they allow it at
+// time now. Otherwise, it returns how long to wait before trying again, or an
+// error if too many statements subject to a quota are running.
+func (l *roleQuotaLimiter) tryAdmit(quotas []RoleQuota, now time.Time) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.mu.roles == nil {
+		l.mu.roles = make(map[string]*roleQuotaBuckets)
+	}
+	var wait float64
+	for _, q := range quotas {
+		b := l.buckets(q, now)
+		b.refill(q, now)
+		if q.MaxConcurrentStatements > 0 && b.running >= q.MaxConcurrentStatements {
+			return 0, pgerror.NewErrorf(pgerror.CodeConfigurationLimitExceededError,
+				"too many concurrent statements for role %s (max_concurrent_statements = %d)",
+				q.Role, q.MaxConcurrentStatements)
+		}
+		if q.MaxQueriesPerSecond > 0 && b.queries < 1 {
+			wait = math.Max(wait, (1-b.queries)/float64(q.MaxQueriesPerSecond))
+		}
+		if q.MaxRowsReadPerSecond > 0 && b.rows < 0 {
+			wait = math.Max(wait, -b.rows/float64(q.MaxRowsReadPerSecond))
+		}
+	}
+	if wait > 0 {
+		return time.Duration(wait * float64(time.Second)), nil
+	}
+	for _, q := range quotas {
+		b := l.mu.roles[q.Role]
+		if q.MaxQueriesPerSecond > 0 {
+			b.queries--
+		}
+		b.running++
+	}
+	return 0, nil
+}
+
+// buckets returns the buckets of the role of q, which start full.
+func (l *roleQuotaLimiter) buckets(q RoleQuota, now time.Time) *roleQuotaBuckets {
+	b, ok := l.mu.roles[q.Role]
+	if !ok {
+		b = &roleQuotaBuckets{
+			queries:  math.Max(float64(q.MaxQueriesPerSecond), 1),
+			rows:     float64(q.MaxRowsReadPerSecond),
+			refilled: now,
+		}
+		l.mu.roles[q.Role] = b
+	}
+	return b
+}
+
+// release accounts for the end of a statement admitted by tryAdmit, which
+// read the given number of rows.
+func (l *roleQuotaLimiter) release(quotas []RoleQuota, rowsRead int64, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, q := range quotas {
+		b := l.mu.roles[q.Role]
+		b.refill(q, now)
+		b.running--
+		if q.MaxRowsReadPerSecond > 0 {
+			b.rows -= float64(rowsRead)
+		}
+		if b.full(q) {
+			delete(l.mu.roles, q.Role)
+		}
+	}
+}
+
+// admit blocks until the statement can run under the given quotas or ctx is
+// canceled. If it returns no error, the caller must call the returned
+// function with the number of rows read once the statement is done
+// executing.
+func (l *roleQuotaLimiter) admit(ctx context.Context, quotas []RoleQuota) (func(int64), error) {
+	if len(quotas) == 0 {
+		return func(int64) {}, nil
+	}
+	var timer timeutil.Timer
+	defer timer.Stop()
+	for {
+		wait, err := l.tryAdmit(quotas, timeutil.Now())
+		if err != nil {
+			return nil, err
+		}
+		if wait == 0 {
+			return func(rowsRead int64) { l.release(quotas, rowsRead, timeutil.Now()) }, nil
+		}
+		log.VEventf(ctx, 2, "waiting %s for the role quotas", wait)
+		timer.Reset(wait)
+		select {
+		case <-timer.C:
+			timer.Read = true
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestRoleQuotaLimiter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var l roleQuotaLimiter
+	now := time.Unix(1500000000, 0)
+	admit := func(quotas []RoleQuota) time.Duration {
+		t.Helper()
+		wait, err := l.tryAdmit(quotas, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return wait
+	}
+
+	// The bucket of queries holds a second's worth of tokens.
+	qps := []RoleQuota{{Role: "tenant", MaxQueriesPerSecond: 2}}
+	for i := 0; i < 2; i++ {
+		if wait := admit(qps); wait != 0 {
+			t.Fatalf("%d: expected the statement to be admitted, got a wait of %s", i, wait)
+		}
+		l.release(qps, 0, now)
+	}
+	if wait := admit(qps); wait != 500*time.Millisecond {
+		t.Fatalf("expected a wait of 500ms, got %s", wait)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if wait := admit(qps); wait != 0 {
+		t.Fatalf("expected the statement to be admitted, got a wait of %s", wait)
+	}
+	l.release(qps, 0, now)
+
+	// The rows read are debited once the statements are done, and the
+	// following statements wait until the debt is paid.
+	rows := []RoleQuota{{Role: "reader", MaxRowsReadPerSecond: 100}}
+	if wait := admit(rows); wait != 0 {
+		t.Fatalf("expected the statement to be admitted, got a wait of %s", wait)
+	}
+	l.release(rows, 300, now)
+	if wait := admit(rows); wait != 2*time.Second {
+		t.Fatalf("expected a wait of 2s, got %s", wait)
+	}
+	now = now.Add(2 * time.Second)
+	if wait := admit(rows); wait != 0 {
+		t.Fatalf("expected the statement to be admitted, got a wait of %s", wait)
+	}
+
+	// Statements beyond the concurrency limit are rejected.
+	conc := []RoleQuota{{Role: "tenant", MaxConcurrentStatements: 1}, rows[0]}
+	if wait := admit(conc); wait != 0 {
+		t.Fatalf("expected the statement to be admitted, got a wait of %s", wait)
+	}
+	if _, err := l.tryAdmit(conc, now); !testutils.IsError(err,
+		`too many concurrent statements for role tenant \(max_concurrent_statements = 1\)`) {
+		t.Fatalf("expected a concurrency error, got %v", err)
+	}
+	l.release(conc, 0, now)
+	l.release(rows, 0, now)
+
+	// The buckets are forgotten once they are full again.
+	now = now.Add(time.Hour)
+	if wait := admit(conc); wait != 0 {
+		t.Fatalf("expected the statement to be admitted, got a wait of %s", wait)
+	}
+	l.release(conc, 0, now)
+	if n := len(l.mu.roles); n != 0 {
+		t.Fatalf("expected no buckets to be remembered, got %d", n)
+	}
+}
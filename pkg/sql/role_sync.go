@@ -0,0 +1,158 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// SyncRoleMembers makes the direct members of the given roles the given
+// users, e.g. the members of the groups of the same name in a directory. The
+// memberships are granted and revoked in a single transaction, and those
+// kept keep their admin option. The roles and members that don't exist are
+// skipped, root can't be a member of roles, and the memberships that would
+// create a cycle are not granted. It returns the number of memberships
+// granted and revoked.
+func SyncRoleMembers(
+	ctx context.Context, e *Executor, members map[string][]string,
+) (granted, revoked int, err error) {
+	normalized := make(map[string][]string, len(members))
+	for role, names := range members {
+		role = tree.Name(role).Normalize()
+		for _, name := range names {
+			normalized[role] = append(normalized[role], tree.Name(name).Normalize())
+		}
+	}
+
+	ie := InternalExecutor{LeaseManager: e.cfg.LeaseManager}
+	err = e.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		rows, err := ie.QueryRowsInTransaction(
+			ctx, "sync-role-members", txn, `SELECT username FROM system.users`,
+		)
+		if err != nil {
+			return err
+		}
+		// The admin role is built in.
+		users := map[string]bool{security.AdminRole: true}
+		for _, row := range rows {
+			users[string(tree.MustBeDString(row[0]))] = true
+		}
+		rows, err = ie.QueryRowsInTransaction(
+			ctx, "sync-role-members", txn, `SELECT role, member, "isAdmin" FROM system.role_members`,
+		)
+		if err != nil {
+			return err
+		}
+		g := roleGraph{memberships: make(map[string][]roleMembership)}
+		for _, row := range rows {
+			g.addMembership(roleMembership{
+				role:    string(tree.MustBeDString(row[0])),
+				member:  string(tree.MustBeDString(row[1])),
+				isAdmin: row[2] == tree.DBoolTrue,
+			})
+		}
+
+		grants, revokes := roleMembershipChanges(g, normalized, users)
+		for _, m := range revokes {
+			if _, err := ie.ExecuteStatementInTransaction(
+				ctx, "sync-role-members", txn,
+				`DELETE FROM system.role_members WHERE role = $1 AND member = $2`, m.role, m.member,
+			); err != nil {
+				return err
+			}
+		}
+		for _, m := range grants {
+			if _, err := ie.ExecuteStatementInTransaction(
+				ctx, "sync-role-members", txn,
+				`INSERT INTO system.role_members VALUES ($1, $2, false) ON CONFLICT (role, member) DO NOTHING`,
+				m.role, m.member,
+			); err != nil {
+				return err
+			}
+		}
+		granted, revoked = len(grants), len(revokes)
+		return nil
+	})
+	return granted, revoked, err
+}
+
+// roleMembershipChanges returns the memberships to grant and to revoke for
+// the direct members of the roles in members to be the listed users, given
+// the current memberships in g and the existing users and roles. The
+// memberships are sorted by role and member.
+func roleMembershipChanges(
+	g roleGraph, members map[string][]string, users map[string]bool,
+) (grants, revokes []roleMembership) {
+	roles := make([]string, 0, len(members))
+	for role := range members {
+		if role != security.RootUser && users[role] {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+
+	current := make(map[string]map[string]bool)
+	for member, ms := range g.memberships {
+		for _, m := range ms {
+			if current[m.role] == nil {
+				current[m.role] = make(map[string]bool)
+			}
+			current[m.role][member] = true
+		}
+	}
+
+	// Revoke the memberships first, so that they don't prevent granting the
+	// others because of cycles.
+	for _, role := range roles {
+		wanted := make(map[string]bool)
+		for _, member := range members[role] {
+			wanted[member] = true
+		}
+		var old []string
+		for member := range current[role] {
+			if !wanted[member] {
+				old = append(old, member)
+			}
+		}
+		sort.Strings(old)
+		for _, member := range old {
+			revokes = append(revokes, roleMembership{role: role, member: member})
+			g.removeMembership(role, member)
+		}
+	}
+	for _, role := range roles {
+		names := append([]string(nil), members[role]...)
+		sort.Strings(names)
+		for i, member := range names {
+			if (i > 0 && member == names[i-1]) || current[role][member] ||
+				member == security.RootUser || !users[member] {
+				continue
+			}
+			if _, ok := g.memberOf(role, false /* inheritingOnly */)[member]; ok || member == role {
+				continue
+			}
+			m := roleMembership{role: role, member: member}
+			grants = append(grants, m)
+			g.addMembership(m)
+		}
+	}
+	return grants, revokes
+}
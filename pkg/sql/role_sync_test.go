@@ -0,0 +1,80 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestRoleMembershipChanges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	users := map[string]bool{
+		"root": true, "admin": true,
+		"dev": true, "ops": true, "leads": true,
+		"alice": true, "bob": true, "carol": true,
+	}
+	// roleMembershipChanges updates the graph it is given.
+	makeGraph := func() roleGraph {
+		g := roleGraph{memberships: make(map[string][]roleMembership)}
+		for _, m := range []roleMembership{
+			{role: "dev", member: "alice", isAdmin: true},
+			{role: "dev", member: "bob"},
+			{role: "ops", member: "carol"},
+			{role: "leads", member: "ops"},
+		} {
+			g.addMembership(m)
+		}
+		return g
+	}
+
+	grants, revokes := roleMembershipChanges(makeGraph(), map[string][]string{
+		// alice is kept, bob is revoked, and the unknown users, root and the
+		// duplicates are skipped.
+		"dev": {"carol", "alice", "dave", "root", "carol"},
+		// ops is a member of leads, so making leads a member of ops would
+		// create a cycle, and a role can't be a member of itself.
+		"ops": {"carol", "leads", "ops"},
+		// Unknown roles and root are skipped.
+		"qa":   {"alice"},
+		"root": {"alice"},
+	}, users)
+
+	expGrants := []roleMembership{{role: "dev", member: "carol"}}
+	expRevokes := []roleMembership{{role: "dev", member: "bob"}}
+	if !reflect.DeepEqual(grants, expGrants) {
+		t.Errorf("expected grants %+v, got %+v", expGrants, grants)
+	}
+	if !reflect.DeepEqual(revokes, expRevokes) {
+		t.Errorf("expected revokes %+v, got %+v", expRevokes, revokes)
+	}
+
+	// Revoking a membership allows granting the reverse one.
+	grants, revokes = roleMembershipChanges(makeGraph(), map[string][]string{
+		"leads": {},
+		"ops":   {"carol", "leads"},
+	}, users)
+	expGrants = []roleMembership{{role: "ops", member: "leads"}}
+	expRevokes = []roleMembership{{role: "leads", member: "ops"}}
+	if !reflect.DeepEqual(grants, expGrants) {
+		t.Errorf("expected grants %+v, got %+v", expGrants, grants)
+	}
+	if !reflect.DeepEqual(revokes, expRevokes) {
+		t.Errorf("expected revokes %+v, got %+v", expRevokes, revokes)
+	}
+}
@@ -0,0 +1,452 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+var rowLevelTTLJobInterval = settings.RegisterDurationSetting(
+	"sql.ttl.job_interval",
+	"the amount of time between two passes of a row-level TTL job over its table",
+	5*time.Minute,
+)
+
+var rowLevelTTLDeleteBatchSize = settings.RegisterIntSetting(
+	"sql.ttl.delete_batch_size",
+	"the number of rows scanned for expired rows per transaction by a row-level TTL job",
+	100,
+)
+
+var rowLevelTTLDeleteRateLimit = settings.RegisterIntSetting(
+	"sql.ttl.delete_rate_limit",
+	"the maximum number of expired rows deleted per second by a row-level TTL job (0 for no limit)",
+	0,
+)
+
+const (
+	// rowLevelTTLExpirationColumnName is the name of the hidden column added
+	// by SET TTL EXPIRE AFTER.
+	rowLevelTTLExpirationColumnName = "crdb_internal_expiration"
+	// rowLevelTTLCheckpointInterval is the interval at which a row-level TTL
+	// job checkpoints its progress within a pass.
+	rowLevelTTLCheckpointInterval = 10 * time.Second
+)
+
+// errRowLevelTTLRemoved is returned when the row-level TTL of a job was
+// removed from its table, or its table was dropped. It ends the job
+// successfully.
+var errRowLevelTTLRemoved = errors.New("row-level TTL removed")
+
+// setTableTTL sets the row-level TTL of a table. With EXPIRE AT, rows expire
+// at the time held by a column of the table. With EXPIRE AFTER, a hidden
+// column holding the expiration time of the rows is added, which defaults to
+// the time of the insertion plus the TTL. Setting the first TTL of a table
+// starts the job deleting its expired rows.
+func setTableTTL(
+	params runParams, desc *sqlbase.TableDescriptor, t *tree.AlterTableSetTTL, description string,
+) error {
+	if err := checkRowLevelTTLTable(desc); err != nil {
+		return err
+	}
+	ttl := desc.RowLevelTTL
+	if ttl == nil {
+		ttl = &sqlbase.TableDescriptor_RowLevelTTL{}
+	} else if (ttl.ExpireAfter != "") != (t.ExpireAfter != nil) {
+		return pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"table %s already has a TTL of another kind, use RESET TTL first", desc.Name)
+	}
+
+	if t.ExpireAfter == nil {
+		col, err := desc.FindActiveColumnByName(string(t.ExpirationColumn))
+		if err != nil {
+			return err
+		}
+		switch col.Type.SemanticType {
+		case sqlbase.ColumnType_TIMESTAMP, sqlbase.ColumnType_TIMESTAMPTZ:
+		default:
+			return pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+				"column %s is of type %s, expected TIMESTAMP or TIMESTAMPTZ",
+				col.Name, col.Type.SQLString())
+		}
+		ttl.ExpirationColumnID = col.ID
+	} else {
+		typedExpr, err := tree.TypeCheckAndRequire(
+			t.ExpireAfter, &params.p.semaCtx, types.Interval, "EXPIRE AFTER",
+		)
+		if err != nil {
+			return err
+		}
+		val, err := typedExpr.Eval(params.evalCtx)
+		if err != nil {
+			return err
+		}
+		interval, ok := val.(*tree.DInterval)
+		if !ok || interval.Duration.Compare(duration.Duration{}) <= 0 {
+			return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"TTL must be a positive interval, got %s", val)
+		}
+		defaultExpr := "now() + " + tree.Serialize(interval)
+		if ttl.ExpireAfter == "" {
+			if _, _, err := desc.FindColumnByName(rowLevelTTLExpirationColumnName); err == nil {
+				return pgerror.NewErrorf(pgerror.CodeDuplicateColumnError,
+					"column %q already exists", rowLevelTTLExpirationColumnName)
+			}
+			// The ID of the column is allocated now to be recorded in the TTL.
+			col := sqlbase.ColumnDescriptor{
+				Name:        rowLevelTTLExpirationColumnName,
+				ID:          desc.NextColumnID,
+				Type:        sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_TIMESTAMPTZ},
+				DefaultExpr: &defaultExpr,
+				Hidden:      true,
+			}
+			desc.NextColumnID++
+			desc.AddColumnMutation(col, sqlbase.DescriptorMutation_ADD)
+			ttl.ExpirationColumnID = col.ID
+		} else {
+			col, err := desc.FindActiveColumnByID(ttl.ExpirationColumnID)
+			if err != nil {
+				return pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+					"the expiration column of table %s is being added, try again later", desc.Name)
+			}
+			col.DefaultExpr = &defaultExpr
+		}
+		ttl.ExpireAfter = interval.String()
+	}
+
+	if ttl.JobID == 0 {
+		jobID, err := startRowLevelTTLJob(params, desc, description)
+		if err != nil {
+			return err
+		}
+		ttl.JobID = jobID
+	}
+	desc.RowLevelTTL = ttl
+	return nil
+}
+
+// resetTableTTL removes the row-level TTL of a table, and the hidden
+// expiration column added by EXPIRE AFTER. The job of the TTL stops when it
+// notices that the TTL was removed.
+func resetTableTTL(desc *sqlbase.TableDescriptor) error {
+	ttl := desc.RowLevelTTL
+	if ttl == nil {
+		return nil
+	}
+	if ttl.ExpireAfter != "" {
+		found := false
+		for i := range desc.Columns {
+			if desc.Columns[i].ID == ttl.ExpirationColumnID {
+				desc.AddColumnMutation(desc.Columns[i], sqlbase.DescriptorMutation_DROP)
+				desc.Columns = append(desc.Columns[:i], desc.Columns[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+				"the expiration column of table %s is being added, try again later", desc.Name)
+		}
+	}
+	desc.RowLevelTTL = nil
+	return nil
+}
+
+// checkRowLevelTTLTable checks that the expired rows of a table can be
+// deleted by a row-level TTL job: the rows must not be referenced by foreign
+// keys or have interleaved rows, which the job does not delete.
+func checkRowLevelTTLTable(desc *sqlbase.TableDescriptor) error {
+	for _, idx := range desc.AllNonDropIndexes() {
+		if len(idx.ReferencedBy) > 0 {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot set a TTL on table %s: it is referenced by a foreign key", desc.Name)
+		}
+		if len(idx.InterleavedBy) > 0 {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot set a TTL on table %s: it has interleaved tables", desc.Name)
+		}
+	}
+	return nil
+}
+
+// startRowLevelTTLJob creates the job deleting the expired rows of a table
+// and runs it on this node. It returns the ID of the job.
+func startRowLevelTTLJob(
+	params runParams, desc *sqlbase.TableDescriptor, description string,
+) (int64, error) {
+	cfg := params.p.ExecCfg()
+	job := cfg.JobRegistry.NewJob(jobs.Record{
+		Description:   description,
+		Username:      params.p.User(),
+		DescriptorIDs: sqlbase.IDs{desc.ID},
+		Details:       jobs.RowLevelTTLDetails{TableID: desc.ID},
+	})
+
+	// The job outlives the statement, so it runs under a context that is not
+	// canceled when the statement finishes.
+	bgCtx := cfg.AmbientCtx.AnnotateCtx(context.Background())
+	jobCtx, cancel := context.WithCancel(bgCtx)
+	if err := job.Created(jobCtx, cancel); err != nil {
+		cancel()
+		return 0, err
+	}
+	if err := job.Started(jobCtx); err != nil {
+		cancel()
+		return 0, err
+	}
+	if err := cfg.DistSQLSrv.Stopper.RunAsyncTask(jobCtx, "row-level-ttl", func(ctx context.Context) {
+		defer cancel()
+		ttlErr := runRowLevelTTL(ctx, job, cfg.Settings)
+		// The job context may have been canceled, so record the outcome using
+		// the background context.
+		if err := job.FinishedWith(bgCtx, ttlErr); err != nil {
+			log.Errorf(bgCtx, "row-level TTL job %d: ignoring FinishedWith error: %+v", *job.ID(), err)
+		}
+	}); err != nil {
+		cancel()
+		return 0, err
+	}
+	return *job.ID(), nil
+}
+
+// runRowLevelTTL runs a row-level TTL job until its TTL is removed. Every
+// sql.ttl.job_interval, the job scans the primary index of the table and
+// deletes the rows whose expiration time has passed. A pass is resumed at
+// the span of the details of the job recorded by the last checkpoint.
+func runRowLevelTTL(ctx context.Context, job *jobs.Job, st *cluster.Settings) error {
+	details := job.Record.Details.(jobs.RowLevelTTLDetails)
+	db := job.DB()
+	jobID := *job.ID()
+
+	var limiter *rate.Limiter
+	lastCheckpoint := timeutil.Now()
+	for {
+		if details.ResumeSpan.Key == nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(rowLevelTTLJobInterval.Get(&st.SV)):
+			}
+			if err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+				desc, err := getRowLevelTTLTable(ctx, txn, details.TableID, jobID)
+				if err != nil {
+					return err
+				}
+				details.ResumeSpan = desc.PrimaryIndexSpan()
+				return nil
+			}); err != nil {
+				if err == errRowLevelTTLRemoved {
+					return nil
+				}
+				return err
+			}
+		}
+		batchSize := rowLevelTTLDeleteBatchSize.Get(&st.SV)
+		if batchSize < 1 {
+			batchSize = 1
+		}
+		if limit := rowLevelTTLDeleteRateLimit.Get(&st.SV); limit > 0 {
+			if limiter == nil || limiter.Limit() != rate.Limit(limit) || limiter.Burst() != int(batchSize) {
+				limiter = rate.NewLimiter(rate.Limit(limit), int(batchSize))
+			}
+		} else {
+			limiter = nil
+		}
+
+		resumeKey, n, err := deleteExpiredRowsChunk(ctx, db, jobID, details, batchSize)
+		if err != nil {
+			if err == errRowLevelTTLRemoved {
+				return nil
+			}
+			return err
+		}
+		details.ResumeSpan.Key = resumeKey
+		if resumeKey == nil {
+			details.ResumeSpan = roachpb.Span{}
+		}
+		details.RowsDeleted += n
+		if limiter != nil && n > 0 {
+			if err := limiter.WaitN(ctx, int(n)); err != nil {
+				return err
+			}
+		}
+		if resumeKey != nil && timeutil.Since(lastCheckpoint) < rowLevelTTLCheckpointInterval {
+			continue
+		}
+		checkpoint := details
+		if err := job.Progressed(ctx, job.Payload().FractionCompleted,
+			func(ctx context.Context, d interface{}) {
+				d.(*jobs.Payload_RowLevelTTL).RowLevelTTL = &checkpoint
+			},
+		); err != nil {
+			return err
+		}
+		lastCheckpoint = timeutil.Now()
+	}
+}
+
+// getRowLevelTTLTable returns the descriptor of the table of a row-level TTL
+// job, or errRowLevelTTLRemoved if the table no longer has the TTL of the
+// job.
+func getRowLevelTTLTable(
+	ctx context.Context, txn *client.Txn, tableID sqlbase.ID, jobID int64,
+) (*sqlbase.TableDescriptor, error) {
+	desc, err := sqlbase.GetTableDescFromID(ctx, txn, tableID)
+	if err != nil {
+		if err == sqlbase.ErrDescriptorNotFound {
+			return nil, errRowLevelTTLRemoved
+		}
+		return nil, err
+	}
+	if desc.Dropped() || desc.RowLevelTTL == nil || desc.RowLevelTTL.JobID != jobID {
+		return nil, errRowLevelTTLRemoved
+	}
+	if err := checkRowLevelTTLTable(desc); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
+
+// deleteExpiredRowsChunk scans up to batchSize rows of a table in a
+// transaction and deletes the ones whose expiration time is before the
+// timestamp of the transaction. Rows without an expiration time never
+// expire. It returns the key to resume at, or nil if the pass is over, and
+// the number of rows deleted.
+func deleteExpiredRowsChunk(
+	ctx context.Context, db *client.DB, jobID int64, details jobs.RowLevelTTLDetails, batchSize int64,
+) (roachpb.Key, int64, error) {
+	var resumeKey roachpb.Key
+	var deleted int64
+	err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		resumeKey, deleted = nil, 0
+		desc, err := getRowLevelTTLTable(ctx, txn, details.TableID, jobID)
+		if err != nil {
+			return err
+		}
+		if !desc.PrimaryIndexSpan().Contains(details.ResumeSpan) {
+			// The primary key of the table was changed since the pass started.
+			return nil
+		}
+
+		// The rows are not referenced by foreign keys, as checked by
+		// getRowLevelTTLTable, so there is nothing to check on delete.
+		var alloc sqlbase.DatumAlloc
+		rd, err := sqlbase.MakeRowDeleter(
+			txn, desc, nil /* fkTables */, desc.Columns, false /* checkFKs */, &alloc,
+		)
+		if err != nil {
+			return err
+		}
+		expirationIdx, ok := rd.FetchColIDtoRowIndex[desc.RowLevelTTL.ExpirationColumnID]
+		if !ok {
+			// The expiration column is still being added, so no row has
+			// expired yet.
+			return nil
+		}
+
+		var valNeededForCol util.FastIntSet
+		for _, idx := range rd.FetchColIDtoRowIndex {
+			valNeededForCol.Add(idx)
+		}
+		var fetcher sqlbase.MultiRowFetcher
+		if err := fetcher.Init(
+			false /* reverse */, false /* returnRangeInfo */, &alloc,
+			sqlbase.MultiRowFetcherTableArgs{
+				Desc:            desc,
+				Index:           &desc.PrimaryIndex,
+				ColIdxMap:       rd.FetchColIDtoRowIndex,
+				Cols:            rd.FetchCols,
+				ValNeededForCol: valNeededForCol,
+			},
+		); err != nil {
+			return err
+		}
+		if err := fetcher.StartScan(
+			ctx, txn, roachpb.Spans{details.ResumeSpan}, true /* limitBatches */, batchSize,
+			false, /* traceKV */
+		); err != nil {
+			return err
+		}
+
+		now := txn.Proto().OrigTimestamp.GoTime()
+		b := txn.NewBatch()
+		for i := int64(0); i < batchSize; i++ {
+			datums, _, _, err := fetcher.NextRowDecoded(ctx)
+			if err != nil {
+				return err
+			}
+			if datums == nil {
+				break
+			}
+			var expiration time.Time
+			switch d := datums[expirationIdx].(type) {
+			case *tree.DTimestamp:
+				expiration = d.Time
+			case *tree.DTimestampTZ:
+				expiration = d.Time
+			default:
+				continue
+			}
+			if !expiration.Before(now) {
+				continue
+			}
+			if err := rd.DeleteRow(ctx, b, datums, false /* traceKV */); err != nil {
+				return err
+			}
+			deleted++
+		}
+		resumeKey = fetcher.Key()
+		return txn.CommitInBatch(ctx, b)
+	})
+	return resumeKey, deleted, err
+}
+
+func rowLevelTTLResumeHook(
+	typ jobs.Type, settings *cluster.Settings,
+) func(context.Context, *jobs.Job) error {
+	if typ != jobs.TypeRowLevelTTL {
+		return nil
+	}
+	return func(ctx context.Context, job *jobs.Job) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		if err := job.Created(ctx, cancel); err != nil {
+			return err
+		}
+		return runRowLevelTTL(ctx, job, settings)
+	}
+}
+
+func init() {
+	jobs.AddResumeHook(rowLevelTTLResumeHook)
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestRowLevelTTL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	r := sqlutils.MakeSQLRunner(sqlDB)
+
+	st := s.ClusterSettings()
+	rowLevelTTLJobInterval.Override(&st.SV, 10*time.Millisecond)
+	rowLevelTTLDeleteBatchSize.Override(&st.SV, 7)
+
+	// Rows 1 to 30 have expired, rows 31 to 60 expire in an hour and rows 61
+	// to 100 never expire.
+	r.Exec(t, `CREATE DATABASE d; CREATE TABLE d.t (k INT PRIMARY KEY, e TIMESTAMPTZ, INDEX (e))`)
+	r.Exec(t, `
+INSERT INTO d.t
+  SELECT i, CASE WHEN i <= 30 THEN now() - '1h'::INTERVAL WHEN i <= 60 THEN now() + '1h'::INTERVAL END
+  FROM generate_series(1, 100) AS g(i)`)
+	r.Exec(t, `ALTER TABLE d.t SET TTL EXPIRE AT e`)
+
+	testutils.SucceedsSoon(t, func() error {
+		var count int
+		r.QueryRow(t, `SELECT count(*) FROM d.t`).Scan(&count)
+		if count != 70 {
+			return fmt.Errorf("expected 70 rows, got %d", count)
+		}
+		return nil
+	})
+	r.CheckQueryResults(t, `SELECT min(k) FROM d.t`, [][]string{{"31"}})
+	// The secondary index entries of the rows were deleted as well.
+	r.CheckQueryResults(t, `SELECT count(*) FROM d.t@t_e_idx`, [][]string{{"70"}})
+
+	// Removing the TTL ends the job.
+	r.Exec(t, `ALTER TABLE d.t RESET TTL`)
+	testutils.SucceedsSoon(t, func() error {
+		var status string
+		r.QueryRow(t, `SELECT status FROM crdb_internal.jobs WHERE type = 'ROW LEVEL TTL'`).Scan(&status)
+		if status != string(jobs.StatusSucceeded) {
+			return fmt.Errorf("expected job to succeed, got status %s", status)
+		}
+		return nil
+	})
+
+	// So does dropping the table.
+	r.Exec(t, `ALTER TABLE d.t SET TTL EXPIRE AFTER '1h'`)
+	r.Exec(t, `DROP TABLE d.t`)
+	testutils.SucceedsSoon(t, func() error {
+		var running int
+		r.QueryRow(t, `
+SELECT count(*) FROM crdb_internal.jobs WHERE type = 'ROW LEVEL TTL' AND status != 'succeeded'`,
+		).Scan(&running)
+		if running != 0 {
+			return fmt.Errorf("expected all jobs to succeed, %d did not", running)
+		}
+		return nil
+	})
+}
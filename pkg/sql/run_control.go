@@ -193,3 +193,92 @@ func (p *planner) CancelQuery(ctx context.Context, n *tree.CancelQuery) (planNod
 		queryID: typedQueryID,
 	}, nil
 }
+
+type cancelSessionNode struct {
+	sessionID tree.TypedExpr
+}
+
+func (*cancelSessionNode) Values() tree.Datums { return nil }
+
+func (n *cancelSessionNode) Start(params runParams) error {
+	sessionIDDatum, err := n.sessionID.Eval(params.evalCtx)
+	if err != nil {
+		return err
+	}
+
+	sessionID, ok := tree.AsDInt(sessionIDDatum)
+	if !ok {
+		return fmt.Errorf("%s is not a valid session ID", sessionIDDatum)
+	}
+
+	cancelled, err := params.p.CancelBackend(params.ctx, int64(sessionID), true /* terminate */)
+	if err != nil {
+		return err
+	}
+
+	if !cancelled {
+		return fmt.Errorf("session ID %d not found", sessionID)
+	}
+
+	return nil
+}
+
+func (*cancelSessionNode) Close(context.Context) {}
+
+func (n *cancelSessionNode) Next(runParams) (bool, error) {
+	return false, nil
+}
+
+func (p *planner) CancelSession(ctx context.Context, n *tree.CancelSession) (planNode, error) {
+	typedSessionID, err := p.analyzeExpr(
+		ctx,
+		n.ID,
+		nil,
+		tree.IndexedVarHelper{},
+		types.Int,
+		true, /* requireType */
+		"CANCEL SESSION",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cancelSessionNode{
+		sessionID: typedSessionID,
+	}, nil
+}
+
+// SessionID implements the tree.EvalPlanner interface.
+func (p *planner) SessionID() int64 {
+	return p.session.id
+}
+
+// CancelBackend implements the tree.EvalPlanner interface.
+func (p *planner) CancelBackend(
+	ctx context.Context, sessionID int64, terminate bool,
+) (bool, error) {
+	// The upper 32 bits of the session ID hold the ID of the node owning the
+	// session; IDs that don't name a node can't match any session.
+	nodeID := SessionIDNodeID(sessionID)
+	if nodeID <= 0 {
+		return false, nil
+	}
+
+	request := &serverpb.CancelSessionRequest{
+		NodeId:    fmt.Sprintf("%d", nodeID),
+		SessionID: sessionID,
+		Username:  p.session.User,
+		Terminate: terminate,
+	}
+
+	response, err := p.session.execCfg.StatusServer.CancelSession(ctx, request)
+	if err != nil {
+		return false, err
+	}
+
+	if response.Error != "" {
+		return false, fmt.Errorf("could not cancel session %d: %s", sessionID, response.Error)
+	}
+
+	return response.Cancelled, nil
+}
@@ -17,6 +17,8 @@ package sql_test
 import (
 	gosql "database/sql"
 	"errors"
+	"fmt"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -24,8 +26,10 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -223,3 +227,82 @@ func TestCancelDistSQLQuery(t *testing.T) {
 		t.Fatal("didn't get an error from query that should have been cancelled")
 	}
 }
+
+func TestCancelSession(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tc := serverutils.StartTestCluster(t, 2, /* numNodes */
+		base.TestClusterArgs{
+			ReplicationMode: base.ReplicationManual,
+		})
+	defer tc.Stopper().Stop(context.TODO())
+
+	conn1 := tc.ServerConn(0)
+
+	// Open a connection to the second node as a non-root user, pinned to a
+	// single session so that its ID stays the same.
+	if _, err := conn1.Exec("CREATE USER testuser"); err != nil {
+		t.Fatal(err)
+	}
+	pgURL, cleanup := sqlutils.PGUrl(
+		t, tc.Server(1).ServingAddr(), "TestCancelSession", url.User(server.TestUser))
+	defer cleanup()
+	conn2, err := gosql.Open("postgres", pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn2.Close()
+	conn2.SetMaxOpenConns(1)
+
+	var sessionID int64
+	if err := conn2.QueryRow("SELECT pg_backend_pid()").Scan(&sessionID); err != nil {
+		t.Fatal(err)
+	}
+	if nodeID := sql.SessionIDNodeID(sessionID); nodeID != 2 {
+		t.Fatalf("expected session ID %d to belong to node 2, got %d", sessionID, nodeID)
+	}
+
+	// A non-root user can't signal the sessions of other users.
+	var rootSessionID int64
+	if err := conn1.QueryRow("SELECT pg_backend_pid()").Scan(&rootSessionID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn2.Exec("SELECT pg_terminate_backend($1)", rootSessionID); !testutils.IsError(
+		err, "permission denied",
+	) {
+		t.Fatalf("expected permission error, got %v", err)
+	}
+
+	// Unknown sessions are reported but aren't an error for the builtins.
+	var ok bool
+	if err := conn1.QueryRow("SELECT pg_cancel_backend($1)", sessionID+1000).Scan(&ok); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Fatal("expected pg_cancel_backend to report no such session")
+	}
+	if _, err := conn1.Exec("CANCEL SESSION $1", sessionID+1000); !testutils.IsError(
+		err, "not found",
+	) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	// Root terminates the session from the other node.
+	if err := conn1.QueryRow("SELECT pg_terminate_backend($1)", sessionID).Scan(&ok); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Fatal("expected pg_terminate_backend to find the session")
+	}
+
+	testutils.SucceedsSoon(t, func() error {
+		var count int
+		if err := conn1.QueryRow(
+			"SELECT count(*) FROM crdb_internal.cluster_sessions WHERE session_id = $1", sessionID,
+		).Scan(&count); err != nil {
+			return err
+		}
+		if count != 0 {
+			return fmt.Errorf("session %d is still open", sessionID)
+		}
+		return nil
+	})
+}
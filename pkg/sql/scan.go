@@ -15,18 +15,21 @@
 package sql
 
 import (
+	"bytes"
 	"fmt"
 	"sync"
 
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
@@ -46,6 +49,12 @@ type scanNode struct {
 	specifiedIndex *sqlbase.IndexDescriptor
 	// Set if the NO_INDEX_JOIN hint was given.
 	noIndexJoin bool
+	// Set if the current user can only select some columns of the table. The
+	// needed columns are then checked by checkColumnPrivileges.
+	checkColumnPrivileges bool
+	// The owner of the view the scan is planned for, whose privileges on the
+	// columns are checked, if any. See planner.viewOwner.
+	viewOwner string
 
 	// The table columns, possibly including ones currently in schema changes.
 	cols []sqlbase.ColumnDescriptor
@@ -56,15 +65,21 @@ type scanNode struct {
 	row tree.Datums
 	// For each column in resultColumns, indicates if the value is
 	// needed (used as an optimization when the upper layer doesn't need
-	// all values).
-	// TODO(radu/knz): currently the optimization always loads the
-	// entire row from KV and only skips unnecessary decodes to
-	// Datum. Investigate whether performance is to be gained (e.g. for
-	// tables with wide rows) by reading only certain columns from KV
-	// using point lookups instead of a single range lookup for the
-	// entire row.
+	// all values). Values that are not needed are not decoded, and the
+	// column families that only contain such values are not read at all
+	// by point lookups; see neededFamilies.
+	// TODO(radu/knz): range scans still load the entire row from KV.
 	valNeededForCol util.FastIntSet
 
+	// neededFamilies, if set, is the list of the primary index's column
+	// families that must be read to produce the needed values. It is only
+	// set when it excludes some of the table's families, and is used to
+	// restrict the spans of point lookups to these families.
+	neededFamilies []sqlbase.FamilyID
+	// familySpans is a reusable buffer for the spans restricted to
+	// neededFamilies.
+	familySpans roachpb.Spans
+
 	// Map used to get the index for columns in cols.
 	colIdxMap map[sqlbase.ColumnID]int
 
@@ -79,6 +94,8 @@ type scanNode struct {
 	// tree.IndexedVar leaves generated using filterVars.
 	filter     tree.TypedExpr
 	filterVars tree.IndexedVarHelper
+	// compiledFilter is filter compiled at Start.
+	compiledFilter tree.CompiledExpr
 
 	// origFilter is the original filtering expression, which might have gotten
 	// simplified during index selection. For example "k > 0" is converted to a
@@ -137,6 +154,8 @@ func (n *scanNode) Start(params runParams) error {
 		Cols:             n.cols,
 		ValNeededForCol:  n.valNeededForCol.Copy(),
 	}
+	n.neededFamilies = n.computeNeededFamilies()
+	n.compiledFilter = tree.CompileFilter(n.filter)
 	return n.fetcher.Init(n.reverse, false /* returnRangeInfo */, &params.p.alloc, tableArgs)
 }
 
@@ -148,10 +167,15 @@ func (n *scanNode) Close(context.Context) {
 // initScan sets up the rowFetcher and starts a scan.
 func (n *scanNode) initScan(params runParams) error {
 	limitHint := n.limitHint()
+	spans := n.spans
+	if n.neededFamilies != nil {
+		n.familySpans = n.splitSpansByFamily(n.familySpans[:0], n.spans)
+		spans = n.familySpans
+	}
 	if err := n.fetcher.StartScan(
 		params.ctx,
 		params.p.txn,
-		n.spans,
+		spans,
 		!n.disableBatchLimits,
 		limitHint,
 		params.p.session.Tracing.KVTracingEnabled(),
@@ -162,6 +186,95 @@ func (n *scanNode) initScan(params runParams) error {
 	return nil
 }
 
+// computeNeededFamilies returns the column families of the primary index that
+// contain the needed values, or nil if the scan needs to read every family
+// or cannot restrict itself to some of them.
+func (n *scanNode) computeNeededFamilies() []sqlbase.FamilyID {
+	if n.isSecondaryIndex || n.reverse || len(n.desc.Families) < 2 ||
+		len(n.index.Interleave.Ancestors) > 0 {
+		return nil
+	}
+	// The first family holds the row sentinel, and the value of composite
+	// key columns, so it is always read. It's not a sentinel if it is stored
+	// as a single column value, which is omitted when NULL.
+	f0 := &n.desc.Families[0]
+	if f0.ID != 0 || (len(f0.ColumnIDs) == 1 && f0.ColumnIDs[0] == f0.DefaultColumnID) {
+		return nil
+	}
+	var needed util.FastIntSet
+	needed.Add(0)
+	for i := range n.cols {
+		if !n.valNeededForCol.Contains(i) {
+			continue
+		}
+		colID := n.cols[i].ID
+		if n.index.ContainsColumnID(colID) {
+			// Decoded from the key.
+			continue
+		}
+		found := false
+		for j := range n.desc.Families {
+			family := &n.desc.Families[j]
+			for _, id := range family.ColumnIDs {
+				if id == colID {
+					needed.Add(int(family.ID))
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+	if needed.Len() == len(n.desc.Families) {
+		return nil
+	}
+	families := make([]sqlbase.FamilyID, 0, needed.Len())
+	needed.ForEach(func(id int) {
+		families = append(families, sqlbase.FamilyID(id))
+	})
+	return families
+}
+
+// splitSpansByFamily appends to buf the spans to scan instead of spans: the
+// spans that look up a single row of the primary index are replaced by one
+// span per needed family; the other spans are kept as is.
+func (n *scanNode) splitSpansByFamily(buf roachpb.Spans, spans roachpb.Spans) roachpb.Spans {
+	prefix := sqlbase.MakeIndexKeyPrefix(n.desc, n.index.ID)
+	for _, span := range spans {
+		if !n.isSingleRowSpan(prefix, span) {
+			buf = append(buf, span)
+			continue
+		}
+		for _, id := range n.neededFamilies {
+			key := roachpb.Key(keys.MakeFamilyKey(span.Key[:len(span.Key):len(span.Key)], uint32(id)))
+			buf = append(buf, roachpb.Span{Key: key, EndKey: key.PrefixEnd()})
+		}
+	}
+	return buf
+}
+
+// isSingleRowSpan returns whether span contains exactly the keys of a single
+// row of the (non-interleaved) primary index, whose keys start with prefix.
+func (n *scanNode) isSingleRowSpan(prefix []byte, span roachpb.Span) bool {
+	if !bytes.HasPrefix(span.Key, prefix) || !span.EndKey.Equal(span.Key.PrefixEnd()) {
+		return false
+	}
+	key := span.Key[len(prefix):]
+	for range n.index.ColumnIDs {
+		l, err := encoding.PeekLength(key)
+		if err != nil {
+			return false
+		}
+		key = key[l:]
+	}
+	return len(key) == 0
+}
+
 func (n *scanNode) limitHint() int64 {
 	var limitHint int64
 	if n.hardLimit != 0 {
@@ -195,8 +308,11 @@ func (n *scanNode) Next(params runParams) (bool, error) {
 		if err != nil || n.row == nil {
 			return false, err
 		}
+		if err := params.p.countRowRead(); err != nil {
+			return false, err
+		}
 		params.evalCtx.IVarHelper = &n.filterVars
-		passesFilter, err := sqlbase.RunFilter(n.filter, params.evalCtx)
+		passesFilter, err := tree.RunCompiledFilter(n.compiledFilter, params.evalCtx)
 		if err != nil {
 			return false, err
 		}
@@ -210,6 +326,7 @@ func (n *scanNode) Next(params runParams) (bool, error) {
 
 // Initializes a scanNode with a table descriptor.
 func (n *scanNode) initTable(
+	ctx context.Context,
 	p *planner,
 	desc *sqlbase.TableDescriptor,
 	indexHints *tree.IndexHints,
@@ -218,10 +335,20 @@ func (n *scanNode) initTable(
 ) error {
 	n.desc = desc
 
-	if !p.skipSelectPrivilegeChecks {
-		if err := p.CheckPrivilege(n.desc, privilege.SELECT); err != nil {
+	p.maybeAudit(desc, false /* writing */)
+	if err := p.checkDatabaseUsage(ctx, desc); err != nil {
+		return err
+	}
+	if err := p.CheckPrivilege(n.desc, privilege.SELECT); err != nil {
+		// Without the privilege on the table, the user can still select the
+		// columns it has the privilege on.
+		if ok, colErr := p.anyColumnPrivilege(n.desc, privilege.SELECT); colErr != nil {
+			return colErr
+		} else if !ok {
 			return err
 		}
+		n.checkColumnPrivileges = true
+		n.viewOwner = p.viewOwner
 	}
 
 	if indexHints != nil {
@@ -0,0 +1,327 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/cron"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// A scheduled job is a schedule, stored in system.scheduled_jobs, starting
+// runs of a periodic job at the times its crontab expression is due. The
+// arguments and the state of a schedule are opaque to the scheduler: they are
+// interpreted by the executor registered for the type of the schedule, which
+// starts its runs. Every node runs the scheduler, which claims each due
+// schedule in a transaction that also advances its next run, so that every
+// run is started once.
+
+var schedulerInterval = settings.RegisterNonNegativeDurationSetting(
+	"jobs.scheduler.interval",
+	"the interval between checks for due schedules of periodic jobs (0 to disable)",
+	time.Minute,
+)
+
+// schedulerDisabledPollInterval is how often the scheduler checks whether it
+// was enabled.
+const schedulerDisabledPollInterval = time.Minute
+
+// ScheduledJob is a row of system.scheduled_jobs.
+type ScheduledJob struct {
+	ID    int64
+	Name  string
+	Owner string
+	// NextRun is the time the schedule is next due, or the zero time if it is
+	// never due.
+	NextRun       time.Time
+	ScheduleExpr  string
+	ExecutorType  string
+	ExecutionArgs []byte
+	State         []byte
+	Status        string
+}
+
+// ScheduledJobExecutor starts the runs of the schedules of a type. It is
+// called in the transaction claiming a due schedule, in which it may read
+// the cluster and update the State and the Status of sj, which are written
+// along with the next run of the schedule. It returns the function running
+// the job, called once the transaction commits, which returns the status of
+// the run; or nil to skip the run. If it returns an error the transaction is
+// rolled back, and the error is recorded in the status of the schedule.
+type ScheduledJobExecutor func(
+	ctx context.Context, txn *client.Txn, e *Executor, sj *ScheduledJob, now time.Time,
+) (run func(context.Context) (string, error), err error)
+
+var scheduledJobExecutors = map[string]ScheduledJobExecutor{}
+
+// AddScheduledJobExecutor registers the executor of the schedules of the
+// given type. It must be called from an init function.
+func AddScheduledJobExecutor(executorType string, fn ScheduledJobExecutor) {
+	scheduledJobExecutors[executorType] = fn
+}
+
+func init() {
+	AddBackgroundWorker(runScheduler)
+}
+
+// scheduledJobColumns are the columns of system.scheduled_jobs read by
+// loadScheduledJob.
+const scheduledJobColumns = `schedule_id, schedule_name, owner, next_run, schedule_expr,
+	executor_type, execution_args, schedule_state, schedule_status`
+
+// CreateScheduledJob inserts the schedule sj, due at the first time its
+// crontab expression matches after now. The ID and the NextRun of sj are
+// set.
+func CreateScheduledJob(
+	ctx context.Context, cfg *ExecutorConfig, sj *ScheduledJob, now time.Time,
+) error {
+	sched, err := cron.Parse(sj.ScheduleExpr)
+	if err != nil {
+		return err
+	}
+	sj.NextRun = sched.Next(now)
+	if sj.NextRun.IsZero() {
+		return errors.Errorf("crontab expression %q is never due", sj.ScheduleExpr)
+	}
+	ie := InternalExecutor{LeaseManager: cfg.LeaseManager}
+	row, err := ie.QueryRow(ctx, "create-scheduled-job",
+		`INSERT INTO system.scheduled_jobs
+       (schedule_name, owner, next_run, schedule_expr, executor_type, execution_args,
+        schedule_state, schedule_status)
+     VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING schedule_id`,
+		sj.Name, sj.Owner, sj.NextRun, sj.ScheduleExpr, sj.ExecutorType, sj.ExecutionArgs,
+		nullableBytes(sj.State), nullableString(sj.Status),
+	)
+	if err != nil {
+		return err
+	}
+	sj.ID = int64(tree.MustBeDInt(row[0]))
+	return nil
+}
+
+// runScheduler implements BackgroundWorker.
+func runScheduler(ctx context.Context, stopper *stop.Stopper, e *Executor) {
+	st := e.cfg.Settings
+	var timer timeutil.Timer
+	defer timer.Stop()
+	for {
+		interval := schedulerInterval.Get(&st.SV)
+		if interval == 0 {
+			interval = schedulerDisabledPollInterval
+		}
+		timer.Reset(interval)
+		select {
+		case <-stopper.ShouldQuiesce():
+			return
+		case <-timer.C:
+			timer.Read = true
+		}
+		if schedulerInterval.Get(&st.SV) == 0 {
+			continue
+		}
+		if err := runDueSchedules(ctx, stopper, e, timeutil.Now()); err != nil {
+			log.Warningf(ctx, "could not run the due schedules: %v", err)
+		}
+	}
+}
+
+// runDueSchedules starts the runs of the schedules due at now.
+func runDueSchedules(ctx context.Context, stopper *stop.Stopper, e *Executor, now time.Time) error {
+	ie := InternalExecutor{LeaseManager: e.cfg.LeaseManager}
+	rows, err := ie.Query(ctx, "find-due-schedules",
+		`SELECT schedule_id FROM system.scheduled_jobs WHERE next_run <= $1`, now)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		id := int64(tree.MustBeDInt(row[0]))
+		if err := runSchedule(ctx, stopper, e, id, now); err != nil {
+			log.Warningf(ctx, "could not run schedule %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// runSchedule claims the schedule with the given ID if it is still due at
+// now, and starts its run.
+func runSchedule(
+	ctx context.Context, stopper *stop.Stopper, e *Executor, id int64, now time.Time,
+) error {
+	ie := InternalExecutor{LeaseManager: e.cfg.LeaseManager}
+	var run func(context.Context) (string, error)
+	var startErr error
+	err := e.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		run, startErr = nil, nil
+		sj, err := loadDueScheduledJob(ctx, ie, txn, id, now)
+		if err != nil || sj == nil {
+			return err
+		}
+		if run, startErr = startScheduledJob(ctx, txn, e, sj, now); startErr != nil {
+			return startErr
+		}
+		return updateScheduledJob(ctx, ie, txn, sj, sj.next(now))
+	})
+	if err != nil && startErr != nil {
+		// The failure is recorded in a new transaction, since the changes of the
+		// executor were rolled back, and the schedule is advanced so that it
+		// doesn't fail again before its next run.
+		log.Warningf(ctx, "could not start schedule %d: %v", id, startErr)
+		return e.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			sj, err := loadDueScheduledJob(ctx, ie, txn, id, now)
+			if err != nil || sj == nil {
+				return err
+			}
+			sj.Status = fmt.Sprintf("failed to start: %v", startErr)
+			return updateScheduledJob(ctx, ie, txn, sj, sj.next(now))
+		})
+	}
+	if err != nil || run == nil {
+		return err
+	}
+	return stopper.RunAsyncTask(ctx, "scheduled-job", func(ctx context.Context) {
+		status, err := run(ctx)
+		if err != nil {
+			status = fmt.Sprintf("failed: %v", err)
+		}
+		if _, err := ie.Exec(ctx, "set-schedule-status",
+			`UPDATE system.scheduled_jobs SET schedule_status = $2 WHERE schedule_id = $1`,
+			id, status,
+		); err != nil {
+			log.Warningf(ctx, "could not record the status of schedule %d: %v", id, err)
+		}
+	})
+}
+
+// startScheduledJob calls the executor of sj.
+func startScheduledJob(
+	ctx context.Context, txn *client.Txn, e *Executor, sj *ScheduledJob, now time.Time,
+) (func(context.Context) (string, error), error) {
+	if _, err := cron.Parse(sj.ScheduleExpr); err != nil {
+		return nil, err
+	}
+	fn, ok := scheduledJobExecutors[sj.ExecutorType]
+	if !ok {
+		return nil, errors.Errorf("unknown executor type %q", sj.ExecutorType)
+	}
+	return fn(ctx, txn, e, sj, now)
+}
+
+// next returns the first time the schedule is due after now, or the zero
+// time if it is never due.
+func (sj *ScheduledJob) next(now time.Time) time.Time {
+	sched, err := cron.Parse(sj.ScheduleExpr)
+	if err != nil {
+		return time.Time{}
+	}
+	return sched.Next(now)
+}
+
+// loadDueScheduledJob reads the schedule with the given ID in txn. It
+// returns nil if the schedule doesn't exist or isn't due at now, which
+// happens when another node claimed it first.
+func loadDueScheduledJob(
+	ctx context.Context, ie InternalExecutor, txn *client.Txn, id int64, now time.Time,
+) (*ScheduledJob, error) {
+	row, err := ie.QueryRowInTransaction(ctx, "load-scheduled-job", txn,
+		`SELECT `+scheduledJobColumns+` FROM system.scheduled_jobs WHERE schedule_id = $1`, id)
+	if err != nil || row == nil {
+		return nil, err
+	}
+	sj := &ScheduledJob{
+		ID:            int64(tree.MustBeDInt(row[0])),
+		Name:          string(tree.MustBeDString(row[1])),
+		Owner:         string(tree.MustBeDString(row[2])),
+		ScheduleExpr:  string(tree.MustBeDString(row[4])),
+		ExecutorType:  string(tree.MustBeDString(row[5])),
+		ExecutionArgs: []byte(*row[6].(*tree.DBytes)),
+	}
+	if row[3] != tree.DNull {
+		sj.NextRun = row[3].(*tree.DTimestamp).Time
+	}
+	if row[7] != tree.DNull {
+		sj.State = []byte(*row[7].(*tree.DBytes))
+	}
+	if row[8] != tree.DNull {
+		sj.Status = string(tree.MustBeDString(row[8]))
+	}
+	if sj.NextRun.IsZero() || sj.NextRun.After(now) {
+		return nil, nil
+	}
+	return sj, nil
+}
+
+// updateScheduledJob writes the state and the status of sj in txn, and makes
+// it due at nextRun.
+func updateScheduledJob(
+	ctx context.Context, ie InternalExecutor, txn *client.Txn, sj *ScheduledJob, nextRun time.Time,
+) error {
+	var next interface{}
+	if !nextRun.IsZero() {
+		next = nextRun
+	}
+	_, err := ie.ExecuteStatementInTransaction(ctx, "update-scheduled-job", txn,
+		`UPDATE system.scheduled_jobs
+       SET next_run = $2, schedule_state = $3, schedule_status = $4
+     WHERE schedule_id = $1`,
+		sj.ID, next, nullableBytes(sj.State), nullableString(sj.Status),
+	)
+	return err
+}
+
+func nullableBytes(b []byte) interface{} {
+	if b == nil {
+		return nil
+	}
+	return b
+}
+
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// ExecuteStatementAsUser runs a statement, in its own transaction, in a new
+// session of the given user, and returns the rows of its result.
+func (e *Executor) ExecuteStatementAsUser(
+	ctx context.Context, user string, stmt string,
+) ([]tree.Datums, error) {
+	session := NewSession(ctx, SessionArgs{User: user}, e, nil, e.cfg.LeaseManager.memMetrics)
+	session.StartUnlimitedMonitor()
+	defer session.Finish(e)
+	res, err := e.ExecuteStatementsBuffered(session, stmt, nil, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close(ctx)
+	var rows []tree.Datums
+	if r := res.ResultList[0].Rows; r != nil {
+		for i := 0; i < r.Len(); i++ {
+			rows = append(rows, append(tree.Datums(nil), r.At(i)...))
+		}
+	}
+	return rows, nil
+}
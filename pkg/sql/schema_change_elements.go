@@ -0,0 +1,232 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// A schema change is made of elements, one per mutation: a column or an
+// index being added or dropped, and the foreign key of an index being added.
+// The work needed by the elements between the state machine and the
+// completion of the mutations is run in stages, each stage processing all
+// the elements that need it. A stage depends on the stages before it. The
+// dropped indexes are truncated first, so that the column backfill doesn't
+// need to maintain them. The columns are backfilled before the indexes, since
+// the new indexes may include the new columns. The foreign keys are validated
+// last, once the columns they are on are backfilled and the indexes
+// supporting them are complete, so that no write escapes both the validation
+// and the foreign key checks.
+//
+// Every stage is idempotent: the truncations and backfills checkpoint their
+// progress in the resume spans of the job and resume from them, and the
+// validation of the foreign keys only reads. A schema changer resuming the
+// schema change after a crash, at any point, reruns all the stages. Only the
+// completion of the mutations, which marks the foreign keys as validated,
+// and their reversal, which removes the back-references of the foreign keys
+// from the referenced tables, change the descriptors, each in a single
+// transaction.
+type schemaChangeStage int
+
+const (
+	stageTruncateIndexes schemaChangeStage = iota
+	stageBackfillColumns
+	stageBackfillIndexes
+	stageValidateForeignKeys
+
+	numSchemaChangeStages
+)
+
+var schemaChangeStageName = [...]string{
+	stageTruncateIndexes:     "truncate indexes",
+	stageBackfillColumns:     "backfill columns",
+	stageBackfillIndexes:     "backfill indexes",
+	stageValidateForeignKeys: "validate foreign keys",
+}
+
+func (s schemaChangeStage) String() string {
+	return schemaChangeStageName[s]
+}
+
+// schemaChangeElement is a mutation of a schema change.
+type schemaChangeElement struct {
+	// mutationIdx is the index of the mutation in the mutations of the table,
+	// used for checkpointing.
+	mutationIdx int
+	mutation    sqlbase.DescriptorMutation
+}
+
+// stages returns the stages needed by the element.
+func (e schemaChangeElement) stages() ([]schemaChangeStage, error) {
+	m := e.mutation
+	switch m.Direction {
+	case sqlbase.DescriptorMutation_ADD:
+		switch t := m.Descriptor_.(type) {
+		case *sqlbase.DescriptorMutation_Column:
+			if t.Column.DefaultExpr != nil || !t.Column.Nullable {
+				return []schemaChangeStage{stageBackfillColumns}, nil
+			}
+			return nil, nil
+		case *sqlbase.DescriptorMutation_Index:
+			if fk := t.Index.ForeignKey; fk.IsSet() && fk.Validity == sqlbase.ConstraintValidity_Unvalidated {
+				return []schemaChangeStage{stageBackfillIndexes, stageValidateForeignKeys}, nil
+			}
+			return []schemaChangeStage{stageBackfillIndexes}, nil
+		}
+
+	case sqlbase.DescriptorMutation_DROP:
+		switch m.Descriptor_.(type) {
+		case *sqlbase.DescriptorMutation_Column:
+			return []schemaChangeStage{stageBackfillColumns}, nil
+		case *sqlbase.DescriptorMutation_Index:
+			return []schemaChangeStage{stageTruncateIndexes}, nil
+		}
+	}
+	return nil, errors.Errorf("unsupported mutation: %+v", m)
+}
+
+// schemaChangePlan lists the elements processed by each stage of a schema
+// change.
+type schemaChangePlan [numSchemaChangeStages][]schemaChangeElement
+
+// makeSchemaChangePlan returns the plan of the schema change with the given
+// mutation ID. Mutations are applied in a FIFO order, so only the first set
+// of mutations is considered.
+func makeSchemaChangePlan(
+	tableDesc *sqlbase.TableDescriptor, mutationID sqlbase.MutationID,
+) (schemaChangePlan, error) {
+	var plan schemaChangePlan
+	for i, m := range tableDesc.Mutations {
+		if m.MutationID != mutationID {
+			break
+		}
+		e := schemaChangeElement{mutationIdx: i, mutation: m}
+		stages, err := e.stages()
+		if err != nil {
+			return plan, err
+		}
+		for _, s := range stages {
+			plan[s] = append(plan[s], e)
+		}
+	}
+	return plan, nil
+}
+
+// indexes returns the descriptors of the indexes of the elements processed
+// by a stage.
+func (plan *schemaChangePlan) indexes(s schemaChangeStage) []sqlbase.IndexDescriptor {
+	var indexes []sqlbase.IndexDescriptor
+	for _, e := range plan[s] {
+		if idx := e.mutation.GetIndex(); idx != nil {
+			indexes = append(indexes, *idx)
+		}
+	}
+	return indexes
+}
+
+// validateForeignKeys checks the foreign keys of the indexes being added,
+// which are on columns being added as well. A violation is a permanent
+// error, which makes the schema changer roll back the schema change.
+func (sc *SchemaChanger) validateForeignKeys(
+	ctx context.Context,
+	lease *sqlbase.TableDescriptor_SchemaChangeLease,
+	added []sqlbase.IndexDescriptor,
+) error {
+	if err := sc.ExtendLease(ctx, lease); err != nil {
+		return err
+	}
+	return sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		p := makeInternalPlanner("validate-fk", txn, security.RootUser, sc.leaseMgr.memMetrics)
+		defer finishInternalPlanner(p)
+		p.session.tables.leaseMgr = sc.leaseMgr
+		// The table is read within the transaction, with its mutations.
+		p.avoidCachedDescriptors = true
+
+		tableDesc, err := sqlbase.GetTableDescFromID(ctx, txn, sc.tableID)
+		if err != nil {
+			return err
+		}
+		for i := range added {
+			log.VEventf(ctx, 2, "validating foreign key %q of table %q",
+				added[i].ForeignKey.Name, tableDesc.Name)
+			if err := p.validateForeignKey(
+				ctx, tableDesc, &added[i], publicAndNonPublicColumns,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// removeFKBackReferences removes the back-references to the given indexes of
+// a table from the tables referenced by their foreign keys, in txn. It is
+// used when the indexes being added are reversed, along with their foreign
+// keys. References from the table to itself are expected to have been
+// removed from the descriptor of the table by the caller.
+func removeFKBackReferences(
+	ctx context.Context, txn *client.Txn, tableID sqlbase.ID, indexes []sqlbase.IndexDescriptor,
+) error {
+	for _, idx := range indexes {
+		if idx.ForeignKey.Table == tableID {
+			continue
+		}
+		target, err := sqlbase.GetTableDescFromID(ctx, txn, idx.ForeignKey.Table)
+		if err != nil {
+			return err
+		}
+		if target.Dropped() {
+			// The referenced table is being dropped. No need to modify it further.
+			continue
+		}
+		if !removeFKBackReferenceFromDesc(target, tableID, idx) {
+			continue
+		}
+		if err := target.SetUpVersion(); err != nil {
+			return err
+		}
+		if err := txn.Put(
+			ctx, sqlbase.MakeDescMetadataKey(target.ID), sqlbase.WrapDescriptor(target),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeFKBackReferenceFromDesc removes the back-reference to an index of a
+// table from the descriptor of the table referenced by the foreign key of
+// the index, and returns whether it was found.
+func removeFKBackReferenceFromDesc(
+	target *sqlbase.TableDescriptor, tableID sqlbase.ID, idx sqlbase.IndexDescriptor,
+) bool {
+	targetIdx, err := target.FindIndexByID(idx.ForeignKey.Index)
+	if err != nil {
+		return false
+	}
+	for k, ref := range targetIdx.ReferencedBy {
+		if ref.Table == tableID && ref.Index == idx.ID {
+			targetIdx.ReferencedBy = append(targetIdx.ReferencedBy[:k], targetIdx.ReferencedBy[k+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestMakeSchemaChangePlan(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	def := "1"
+	column := func(name string, defaultExpr *string, dir sqlbase.DescriptorMutation_Direction) sqlbase.DescriptorMutation {
+		return sqlbase.DescriptorMutation{
+			Descriptor_: &sqlbase.DescriptorMutation_Column{Column: &sqlbase.ColumnDescriptor{
+				Name: name, DefaultExpr: defaultExpr, Nullable: true,
+			}},
+			Direction:  dir,
+			MutationID: 1,
+		}
+	}
+	index := func(name string, fk sqlbase.ForeignKeyReference, dir sqlbase.DescriptorMutation_Direction) sqlbase.DescriptorMutation {
+		return sqlbase.DescriptorMutation{
+			Descriptor_: &sqlbase.DescriptorMutation_Index{Index: &sqlbase.IndexDescriptor{
+				Name: name, ForeignKey: fk,
+			}},
+			Direction:  dir,
+			MutationID: 1,
+		}
+	}
+	fk := sqlbase.ForeignKeyReference{
+		Table: 52, Index: 1, Validity: sqlbase.ConstraintValidity_Unvalidated,
+	}
+	add, drop := sqlbase.DescriptorMutation_ADD, sqlbase.DescriptorMutation_DROP
+
+	desc := sqlbase.TableDescriptor{Mutations: []sqlbase.DescriptorMutation{
+		// The mutations of a single schema change, in the order they were
+		// added.
+		column("a", &def, add),
+		index("t_auto_index_fk", fk, add),
+		column("b", nil, add),
+		column("c", nil, drop),
+		index("t_d_idx", sqlbase.ForeignKeyReference{}, drop),
+		// A mutation of the next schema change.
+		func() sqlbase.DescriptorMutation {
+			m := column("e", &def, add)
+			m.MutationID = 2
+			return m
+		}(),
+	}}
+
+	plan, err := makeSchemaChangePlan(&desc, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[schemaChangeStage][]int{
+		stageTruncateIndexes:     {4},
+		stageBackfillColumns:     {0, 3},
+		stageBackfillIndexes:     {1},
+		stageValidateForeignKeys: {1},
+	}
+	for s := schemaChangeStage(0); s < numSchemaChangeStages; s++ {
+		var idxs []int
+		for _, e := range plan[s] {
+			idxs = append(idxs, e.mutationIdx)
+		}
+		if !reflect.DeepEqual(idxs, expected[s]) {
+			t.Errorf("stage %q: expected mutations %v, got %v", s, expected[s], idxs)
+		}
+	}
+
+	// A validated foreign key doesn't need to be validated again.
+	desc.Mutations[1].GetIndex().ForeignKey.Validity = sqlbase.ConstraintValidity_Validated
+	plan, err = makeSchemaChangePlan(&desc, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(plan[stageValidateForeignKeys]); n != 0 {
+		t.Errorf("expected no foreign key to validate, got %d", n)
+	}
+}
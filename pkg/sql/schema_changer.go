@@ -55,9 +55,12 @@ var (
 type SchemaChanger struct {
 	tableID    sqlbase.ID
 	mutationID sqlbase.MutationID
-	nodeID     roachpb.NodeID
-	db         client.DB
-	leaseMgr   *LeaseManager
+	// The mutation queued by this schema change upon its completion, if any,
+	// which is to be run next.
+	nextMutationID sqlbase.MutationID
+	nodeID         roachpb.NodeID
+	db             client.DB
+	leaseMgr       *LeaseManager
 	// The SchemaChangeManager can attempt to execute this schema
 	// changer after this time.
 	execAfter      time.Time
@@ -581,6 +584,7 @@ func (sc *SchemaChanger) waitToUpdateLeases(ctx context.Context, tableID sqlbase
 // Returns the updated of the descriptor.
 func (sc *SchemaChanger) done(ctx context.Context, isRollback bool) (*sqlbase.Descriptor, error) {
 	return sc.leaseMgr.Publish(ctx, sc.tableID, func(desc *sqlbase.TableDescriptor) error {
+		sc.nextMutationID = sqlbase.InvalidMutationID
 		i := 0
 		for _, mutation := range desc.Mutations {
 			if mutation.MutationID != sc.mutationID {
@@ -588,6 +592,11 @@ func (sc *SchemaChanger) done(ctx context.Context, isRollback bool) (*sqlbase.De
 				// mutations if they have the mutation ID we're looking for.
 				break
 			}
+			if idx := mutation.GetIndex(); idx != nil && !isRollback &&
+				mutation.Direction == sqlbase.DescriptorMutation_ADD && idx.ForeignKey.IsSet() {
+				// The foreign key was validated after the index backfill.
+				idx.ForeignKey.Validity = sqlbase.ConstraintValidity_Validated
+			}
 			desc.MakeMutationComplete(mutation)
 			i++
 		}
@@ -606,6 +615,33 @@ func (sc *SchemaChanger) done(ctx context.Context, isRollback bool) (*sqlbase.De
 				break
 			}
 		}
+
+		if swap := desc.PrimaryKeySwap; swap != nil && swap.MutationID == sc.mutationID {
+			// The new indexes of a primary key change replace the current ones,
+			// which are dropped by a mutation of their own.
+			mutationID, err := desc.MakePrimaryKeySwapComplete()
+			if err != nil {
+				return err
+			}
+			var spanList []jobs.ResumeSpanList
+			for _, mutation := range desc.Mutations {
+				if mutation.MutationID == mutationID {
+					spanList = append(spanList, jobs.ResumeSpanList{
+						ResumeSpans: []roachpb.Span{desc.PrimaryIndexSpan()},
+					})
+				}
+			}
+			record := sc.job.Record
+			record.Description = "CLEANUP " + record.Description
+			record.Details = jobs.SchemaChangeDetails{ResumeSpanList: spanList}
+			job := sc.jobRegistry.NewJob(record)
+			if err := job.Created(ctx, jobs.WithoutCancel); err != nil {
+				return err
+			}
+			desc.MutationJobs = append(desc.MutationJobs, sqlbase.TableDescriptor_MutationJob{
+				MutationID: mutationID, JobID: *job.ID()})
+			sc.nextMutationID = mutationID
+		}
 		return nil
 	}, func(txn *client.Txn) error {
 		if err := sc.job.WithTxn(txn).Succeeded(ctx); err != nil {
@@ -628,9 +664,7 @@ func (sc *SchemaChanger) done(ctx context.Context, isRollback bool) (*sqlbase.De
 			schemaChangeEventType,
 			int32(sc.tableID),
 			int32(sc.nodeID),
-			struct {
-				MutationID uint32
-			}{uint32(sc.mutationID)},
+			&sqlbase.FinishSchemaChangeEvent{MutationID: uint32(sc.mutationID)},
 		)
 	})
 }
@@ -692,8 +726,12 @@ func (sc *SchemaChanger) runStateMachineAndBackfill(
 // applying a schema change. If a column being added is reversed and dropped,
 // all new indexes referencing the column will also be dropped.
 func (sc *SchemaChanger) reverseMutations(ctx context.Context, causingError error) error {
+	// The indexes being added with a foreign key, whose back-references are
+	// removed from the referenced tables.
+	var reversedFKIndexes []sqlbase.IndexDescriptor
 	// Reverse the flow of the state machine.
 	_, err := sc.leaseMgr.Publish(ctx, sc.tableID, func(desc *sqlbase.TableDescriptor) error {
+		reversedFKIndexes = nil
 		// Keep track of the column mutations being reversed so that indexes
 		// referencing them can be dropped.
 		columns := make(map[string]struct{})
@@ -732,12 +770,26 @@ func (sc *SchemaChanger) reverseMutations(ctx context.Context, causingError erro
 				if col := mutation.GetColumn(); col != nil {
 					columns[col.Name] = struct{}{}
 				}
+				// The foreign key of an index ADD being reversed is removed.
+				if idx := mutation.GetIndex(); idx != nil && idx.ForeignKey.IsSet() {
+					if idx.ForeignKey.Table == desc.ID {
+						removeFKBackReferenceFromDesc(desc, desc.ID, *idx)
+					}
+					reversedFKIndexes = append(reversedFKIndexes, *idx)
+					idx.ForeignKey = sqlbase.ForeignKeyReference{}
+				}
 
 			case sqlbase.DescriptorMutation_DROP:
 				desc.Mutations[i].Direction = sqlbase.DescriptorMutation_ADD
 			}
 		}
 
+		if swap := desc.PrimaryKeySwap; swap != nil && swap.MutationID == sc.mutationID {
+			// The new indexes of a primary key change are dropped, and the
+			// current ones are kept.
+			desc.PrimaryKeySwap = nil
+		}
+
 		for i := range desc.MutationJobs {
 			if desc.MutationJobs[i].MutationID == sc.mutationID {
 				// Create a roll back job.
@@ -764,6 +816,10 @@ func (sc *SchemaChanger) reverseMutations(ctx context.Context, causingError erro
 		// Publish() will increment the version.
 		return nil
 	}, func(txn *client.Txn) error {
+		if err := removeFKBackReferences(ctx, txn, sc.tableID, reversedFKIndexes); err != nil {
+			return err
+		}
+
 		// Log "Reverse Schema Change" event. Only the causing error and the
 		// mutation ID are logged; this can be correlated with the DDL statement
 		// that initiated the change using the mutation id.
@@ -773,10 +829,10 @@ func (sc *SchemaChanger) reverseMutations(ctx context.Context, causingError erro
 			EventLogReverseSchemaChange,
 			int32(sc.tableID),
 			int32(sc.nodeID),
-			struct {
-				Error      string
-				MutationID uint32
-			}{fmt.Sprintf("%+v", causingError), uint32(sc.mutationID)},
+			&sqlbase.ReverseSchemaChangeEvent{
+				Error:      fmt.Sprintf("%+v", causingError),
+				MutationID: uint32(sc.mutationID),
+			},
 		)
 	})
 	return err
@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/tests"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
@@ -1527,6 +1528,96 @@ CREATE TABLE t.test (k INT PRIMARY KEY, v INT);
 	}
 }
 
+// TestSchemaChangeRollbackOnDataError tests that a schema change which hits an
+// error caused by the data of the table midway through its backfill is rolled
+// back, instead of being retried forever: the partially built index is
+// removed along with its data, and the failure is recorded in the job.
+func TestSchemaChangeRollbackOnDataError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := tests.CreateTestServerParams()
+	const chunkSize = 200
+	// attempt 1: write the first chunk of the index.
+	// attempt 2: hit a data error; roll back the schema change.
+	// attempt 3: purge the index data.
+	var attempts int32
+	const failedAttempt = 2
+	runBeforeBackfillChunk := func(sp roachpb.Span) error {
+		if atomic.AddInt32(&attempts, 1) == failedAttempt {
+			return pgerror.NewError(pgerror.CodeNumericValueOutOfRangeError, "value out of range")
+		}
+		return nil
+	}
+	params.Knobs = base.TestingKnobs{
+		SQLSchemaChanger: &sql.SchemaChangerTestingKnobs{
+			RunBeforeBackfillChunk: runBeforeBackfillChunk,
+			AsyncExecNotification:  asyncSchemaChangerDisabled,
+			BackfillChunkSize:      chunkSize,
+		},
+		DistSQL: &distsqlrun.TestingKnobs{
+			RunBeforeBackfillChunk: runBeforeBackfillChunk,
+		},
+	}
+	server, sqlDB, kvDB := serverutils.StartServer(t, params)
+	defer server.Stopper().Stop(context.TODO())
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.test (k INT PRIMARY KEY, v INT);
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Bulk insert enough rows for the index backfill to take several chunks.
+	const maxValue = 2 * chunkSize
+	if err := bulkInsertIntoTable(sqlDB, maxValue); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sqlDB.Exec(
+		`CREATE INDEX foo ON t.test (v)`,
+	); !testutils.IsError(err, "value out of range") {
+		t.Fatalf("expected the schema change to fail, got %v", err)
+	}
+
+	// The index doesn't exist.
+	if _, err := sqlDB.Query(
+		`SELECT v from t.test@foo`,
+	); !testutils.IsError(err, "index .* not found") {
+		t.Fatal(err)
+	}
+
+	// The descriptor has no mutations left and the partially built index has
+	// been purged.
+	tableDesc := sqlbase.GetTableDescriptor(kvDB, "t", "test")
+	if len(tableDesc.Mutations) != 0 {
+		t.Fatalf("the table has %d mutations remaining", len(tableDesc.Mutations))
+	}
+	if err := checkTableKeyCount(context.TODO(), kvDB, 1, maxValue); err != nil {
+		t.Fatal(err)
+	}
+
+	// The schema change job failed with the error, and its rollback succeeded.
+	for _, tc := range []struct {
+		description string
+		status      string
+		error       string
+	}{
+		{`CREATE INDEX foo ON %`, "failed", "value out of range"},
+		{`ROLL BACK CREATE INDEX foo ON %`, "succeeded", ""},
+	} {
+		var status, jobErr string
+		if err := sqlDB.QueryRow(
+			`SELECT status, error FROM [SHOW JOBS] WHERE description LIKE $1`, tc.description,
+		).Scan(&status, &jobErr); err != nil {
+			t.Fatalf("%s: %v", tc.description, err)
+		}
+		if status != tc.status || !strings.Contains(jobErr, tc.error) {
+			t.Errorf("%s: expected status %q and error %q, got %q and %q",
+				tc.description, tc.status, tc.error, status, jobErr)
+		}
+	}
+}
+
 // TestSchemaChangeReverseMutations tests that schema changes get reversed
 // correctly when one of them violates a constraint.
 func TestSchemaChangeReverseMutations(t *testing.T) {
@@ -1943,6 +2034,99 @@ INSERT INTO t.test (k, v, length) VALUES (0, 1, 1);
 	wg.Wait()
 }
 
+// Test that rows written while the indexes of a primary key change are
+// being backfilled are correctly reflected in the new primary key and
+// in the rewritten secondary indexes.
+func TestAlterPrimaryKeyDuringBackfill(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	backfillNotification := make(chan bool)
+	continueBackfillNotification := make(chan bool)
+	params, _ := tests.CreateTestServerParams()
+	params.Knobs = base.TestingKnobs{
+		SQLSchemaChanger: &sql.SchemaChangerTestingKnobs{
+			BackfillChunkSize: 10,
+		},
+		DistSQL: &distsqlrun.TestingKnobs{
+			RunBeforeBackfillChunk: func(sp roachpb.Span) error {
+				if backfillNotification != nil {
+					// Close channel to notify that the schema change has
+					// been queued and the backfill has started.
+					close(backfillNotification)
+					backfillNotification = nil
+					<-continueBackfillNotification
+				}
+				return nil
+			},
+		},
+	}
+	server, sqlDB, kvDB := serverutils.StartServer(t, params)
+	defer server.Stopper().Stop(context.TODO())
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.test (k INT PRIMARY KEY, v INT NOT NULL, w INT, INDEX w_idx (w));
+INSERT INTO t.test SELECT x, 1000 - x, x FROM generate_series(0, 99) AS g(x);
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run the primary key change in a separate goroutine.
+	notification := backfillNotification
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		if _, err := sqlDB.Exec(`ALTER TABLE t.test ALTER PRIMARY KEY USING COLUMNS (v)`); err != nil {
+			t.Error(err)
+		}
+		wg.Done()
+	}()
+
+	<-notification
+
+	for _, stmt := range []string{
+		`DELETE FROM t.test WHERE k < 10`,
+		`UPDATE t.test SET w = w + 1000 WHERE k >= 90`,
+		`INSERT INTO t.test SELECT x, 1000 - x, x FROM generate_series(100, 109) AS g(x)`,
+	} {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Error(err)
+		}
+	}
+
+	close(continueBackfillNotification)
+
+	wg.Wait()
+
+	checkCount := func(query string, expected int) {
+		t.Helper()
+		var count int
+		if err := sqlDB.QueryRow(query).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != expected {
+			t.Fatalf("%s: expected %d rows, got %d", query, expected, count)
+		}
+	}
+	checkCount(`SELECT count(*) FROM t.test@primary`, 100)
+	checkCount(`SELECT count(*) FROM t.test@w_idx WHERE w >= 0`, 100)
+	checkCount(`SELECT count(*) FROM t.test@w_idx WHERE w >= 1000`, 10)
+	checkCount(`SELECT count(*) FROM t.test@primary WHERE v = 1000 - k`, 100)
+	// The old primary key is kept unique by a new index.
+	checkCount(`SELECT count(*) FROM t.test@test_k_key WHERE k >= 0`, 100)
+
+	// The old indexes have been removed.
+	tableDesc := sqlbase.GetTableDescriptor(kvDB, "t", "test")
+	if l := len(tableDesc.Mutations); l != 0 {
+		t.Fatalf("expected no mutations, got %d", l)
+	}
+	if l := len(tableDesc.Indexes); l != 2 {
+		t.Fatalf("expected 2 secondary indexes, got %d", l)
+	}
+	if cols := tableDesc.PrimaryIndex.ColumnNames; len(cols) != 1 || cols[0] != "v" {
+		t.Fatalf("unexpected primary key columns %v", cols)
+	}
+}
+
 // Test that a schema change backfill that completes on a
 // backfill chunk boundary works correctly. A backfill is done
 // by scanning a table in chunks and backfilling the schema
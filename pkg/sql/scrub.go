@@ -37,6 +37,9 @@ const (
 	// ScrubErrorDanglingIndexReference occurs when a secondary index k/v
 	// points to a non-existing primary k/v.
 	ScrubErrorDanglingIndexReference = "dangling_index_reference"
+	// ScrubErrorCheckConstraintViolation occurs when a row in a table is
+	// violating a check constraint.
+	ScrubErrorCheckConstraintViolation = "check_constraint_violation"
 )
 
 // checkOperation is an interface for scrub check execution. The
@@ -47,9 +50,8 @@ const (
 //  1) Add the option parsing in startScrubTable
 //  2) Queue the checkOperation structs into scrubNode.checkQueue.
 //
-// TODO(joey): Eventually we will add the ability to repair check
-// failures. In that case, we can add a AttemptRepair function that is
-// called after each call to Next.
+// The errors found by the index checks can be repaired with the REPAIR
+// option, see repairIndexErrors.
 type checkOperation interface {
 	// Started indicates if a checkOperation has already been initialized
 	// by Start during the lifetime of the operation.
@@ -78,6 +80,8 @@ type scrubNode struct {
 
 	n *tree.Scrub
 
+	// repair is set by the REPAIR option of SCRUB TABLE.
+	repair     bool
 	checkQueue []checkOperation
 	row        tree.Datums
 }
@@ -122,6 +126,11 @@ func (n *scrubNode) Start(params runParams) error {
 		if err := n.startScrubTable(params.ctx, params.p, tableDesc, tableName); err != nil {
 			return err
 		}
+		if n.repair {
+			if err := n.repairIndexErrors(params, tableDesc); err != nil {
+				return err
+			}
+		}
 	case tree.ScrubDatabase:
 		if err := n.startScrubDatabase(params.ctx, params.p, &n.n.Database); err != nil {
 			return err
@@ -175,6 +184,7 @@ func (n *scrubNode) startScrubTable(
 	// statement.
 	var indexesSet bool
 	var physicalCheckSet bool
+	var constraintsSet bool
 	for _, option := range n.n.Options {
 		switch v := option.(type) {
 		case *tree.ScrubOptionIndex:
@@ -195,25 +205,84 @@ func (n *scrubNode) startScrubTable(
 			}
 			physicalCheckSet = true
 			// TODO(joey): Initialize physical index to check.
+		case *tree.ScrubOptionConstraint:
+			if constraintsSet {
+				return pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"cannot specify CONSTRAINT option more than once")
+			}
+			constraintsSet = true
+			constraintsToCheck, err := createConstraintCheckOperations(
+				v.ConstraintNames, tableDesc, tableName)
+			if err != nil {
+				return err
+			}
+			n.checkQueue = append(n.checkQueue, constraintsToCheck...)
+		case *tree.ScrubOptionRepair:
+			if n.repair {
+				return pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"cannot specify REPAIR option more than once")
+			}
+			n.repair = true
 		default:
 			panic(fmt.Sprintf("Unhandled SCRUB option received: %+v", v))
 		}
 	}
 
-	// When no options are provided the default behaviour is to run
+	// When no checks are provided the default behaviour is to run
 	// exhaustive checks.
-	if len(n.n.Options) == 0 {
+	if !indexesSet && !physicalCheckSet && !constraintsSet {
 		indexesToCheck, err := createIndexCheckOperations(nil /* indexNames */, tableDesc, tableName)
 		if err != nil {
 			return err
 		}
 		n.checkQueue = append(n.checkQueue, indexesToCheck...)
+		constraintsToCheck, err := createConstraintCheckOperations(
+			nil /* constraintNames */, tableDesc, tableName)
+		if err != nil {
+			return err
+		}
+		n.checkQueue = append(n.checkQueue, constraintsToCheck...)
 		// TODO(joey): Initialize physical index to check.
 	}
 
 	return nil
 }
 
+// repairIndexErrors runs the index checks of a SCRUB TABLE with the REPAIR
+// option, and repairs the secondary indexes in which they found errors with
+// a SCRUB repair job, which runs to completion before any result is
+// returned. The errors found by the checks are then returned as repaired.
+func (n *scrubNode) repairIndexErrors(params runParams, tableDesc *sqlbase.TableDescriptor) error {
+	var indexIDs []sqlbase.IndexID
+	var failedChecks []*indexCheckOperation
+	for _, check := range n.checkQueue {
+		o, ok := check.(*indexCheckOperation)
+		if !ok {
+			continue
+		}
+		if err := o.Start(params.ctx, params.p); err != nil {
+			return err
+		}
+		if o.Done(params.ctx) {
+			continue
+		}
+		indexIDs = append(indexIDs, o.indexDesc.ID)
+		failedChecks = append(failedChecks, o)
+	}
+	if len(failedChecks) == 0 {
+		return nil
+	}
+	description := tree.AsStringWithFlags(n.n, tree.FmtSimpleQualified)
+	jobID, err := runScrubRepairJob(params.ctx, params.p, tableDesc, indexIDs, description)
+	if err != nil {
+		return err
+	}
+	for _, o := range failedChecks {
+		o.repairJobID = jobID
+	}
+	return nil
+}
+
 func (n *scrubNode) Next(params runParams) (bool, error) {
 	for len(n.checkQueue) > 0 {
 		nextCheck := n.checkQueue[0]
@@ -327,6 +396,9 @@ type indexCheckOperation struct {
 	// primaryColIdxs maps PrimaryIndex.Columns to the row
 	// indexes in the query result tree.Datums.
 	primaryColIdxs []int
+	// repairJobID is the ID of the SCRUB repair job which repaired the
+	// errors found by the check, if any.
+	repairJobID int64
 }
 
 func newIndexCheckOperation(
@@ -431,6 +503,11 @@ func (o *indexCheckOperation) Next(ctx context.Context, p *planner) (tree.Datums
 	rowDetails := make(map[string]interface{})
 	details["row_data"] = rowDetails
 	details["index_name"] = o.indexDesc.Name
+	repaired := tree.DBoolFalse
+	if o.repairJobID != 0 {
+		details["repair_job_id"] = o.repairJobID
+		repaired = tree.DBoolTrue
+	}
 	if isMissingIndexReferenceError {
 		// Fetch the primary index values from the primary index row data.
 		for rowIdx, col := range o.columns {
@@ -461,7 +538,7 @@ func (o *indexCheckOperation) Next(ctx context.Context, p *planner) (tree.Datums
 		tree.NewDString(o.tableName.Table()),
 		primaryKey,
 		timestamp,
-		tree.DBoolFalse,
+		repaired,
 		detailsJSON,
 	}, nil
 }
@@ -0,0 +1,251 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"sort"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// The background SCRUB checks run EXPERIMENTAL SCRUB TABLE periodically on
+// the tables of the cluster, and keep the errors found by the latest check
+// of each table in memory, where they are exposed in
+// crdb_internal.scrub_errors. Every node checks the tables whose first range
+// it holds the lease of, so that a table is usually checked by a single node.
+// The checks are throttled by a pause after each table, proportional to the
+// time spent checking it.
+
+var backgroundScrubInterval = settings.RegisterNonNegativeDurationSetting(
+	"sql.scrub.background.interval",
+	"the amount of time between two passes of the background SCRUB checks over the tables (0 to disable)",
+	0,
+)
+
+var backgroundScrubIdleRatio = settings.RegisterNonNegativeFloatSetting(
+	"sql.scrub.background.idle_ratio",
+	"the amount of time the background SCRUB checks pause after checking a table, "+
+		"relative to the time spent checking it",
+	1,
+)
+
+// backgroundScrubDisabledPollInterval is how often the background SCRUB
+// checks check whether they were enabled, besides when the interval
+// changes.
+const backgroundScrubDisabledPollInterval = time.Minute
+
+func init() {
+	AddBackgroundWorker(runBackgroundScrub)
+}
+
+// backgroundScrubErrors holds the errors found by the latest background
+// SCRUB check of each table checked by this node.
+type backgroundScrubErrors struct {
+	syncutil.Mutex
+	// tables maps the IDs of the tables to the rows returned by their latest
+	// check, in the format of scrubColumns.
+	tables map[sqlbase.ID][]tree.Datums
+}
+
+// set records the rows returned by the check of a table.
+func (b *backgroundScrubErrors) set(id sqlbase.ID, rows []tree.Datums) {
+	b.Lock()
+	defer b.Unlock()
+	if b.tables == nil {
+		b.tables = make(map[sqlbase.ID][]tree.Datums)
+	}
+	b.tables[id] = rows
+}
+
+// retain forgets the errors of the tables which weren't checked by the
+// latest pass, because they were dropped or their first range moved to
+// another node.
+func (b *backgroundScrubErrors) retain(checked map[sqlbase.ID]struct{}) {
+	b.Lock()
+	defer b.Unlock()
+	for id := range b.tables {
+		if _, ok := checked[id]; !ok {
+			delete(b.tables, id)
+		}
+	}
+}
+
+// rows returns the errors of all the tables, sorted by table ID.
+func (b *backgroundScrubErrors) rows() []tree.Datums {
+	b.Lock()
+	defer b.Unlock()
+	ids := make([]int, 0, len(b.tables))
+	for id := range b.tables {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	var res []tree.Datums
+	for _, id := range ids {
+		res = append(res, b.tables[sqlbase.ID(id)]...)
+	}
+	return res
+}
+
+// runBackgroundScrub implements BackgroundWorker.
+func runBackgroundScrub(ctx context.Context, stopper *stop.Stopper, e *Executor) {
+	st := e.cfg.Settings
+	// A change of the interval restarts the wait for the next pass.
+	changed := make(chan struct{}, 1)
+	backgroundScrubInterval.SetOnChange(&st.SV, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	var timer timeutil.Timer
+	defer timer.Stop()
+	for {
+		interval := backgroundScrubInterval.Get(&st.SV)
+		if interval == 0 {
+			interval = backgroundScrubDisabledPollInterval
+		}
+		timer.Reset(interval)
+		select {
+		case <-stopper.ShouldQuiesce():
+			return
+		case <-changed:
+			continue
+		case <-timer.C:
+			timer.Read = true
+		}
+		if backgroundScrubInterval.Get(&st.SV) == 0 {
+			continue
+		}
+		if err := runBackgroundScrubPass(ctx, stopper, e); err != nil {
+			log.Warningf(ctx, "background SCRUB checks failed: %v", err)
+		}
+	}
+}
+
+// backgroundScrubTarget is a table checked by the background SCRUB checks.
+type backgroundScrubTarget struct {
+	id   sqlbase.ID
+	name tree.TableName
+}
+
+// runBackgroundScrubPass checks the tables whose first range this node holds
+// the lease of, one at a time.
+func runBackgroundScrubPass(ctx context.Context, stopper *stop.Stopper, e *Executor) error {
+	targets, err := backgroundScrubTargets(ctx, e.cfg.DB)
+	if err != nil {
+		return err
+	}
+	checked := make(map[sqlbase.ID]struct{})
+	for _, target := range targets {
+		if ok, err := holdsFirstRangeLease(ctx, e, target.id); err != nil {
+			log.Warningf(ctx, "could not look up the lease of table %s: %v", &target.name, err)
+			continue
+		} else if !ok {
+			continue
+		}
+		start := timeutil.Now()
+		rows, err := e.ExecuteStatementAsUser(
+			ctx, security.RootUser, "EXPERIMENTAL SCRUB TABLE "+target.name.String(),
+		)
+		if err != nil {
+			// The table may have been dropped since the pass started.
+			log.Warningf(ctx, "could not check table %s: %v", &target.name, err)
+			continue
+		}
+		if len(rows) > 0 {
+			log.Warningf(ctx, "found %d errors checking table %s", len(rows), &target.name)
+		}
+		e.backgroundScrub.set(target.id, rows)
+		checked[target.id] = struct{}{}
+
+		pause := time.Duration(
+			float64(timeutil.Since(start)) * backgroundScrubIdleRatio.Get(&e.cfg.Settings.SV))
+		select {
+		case <-stopper.ShouldQuiesce():
+			return nil
+		case <-time.After(pause):
+		}
+	}
+	e.backgroundScrub.retain(checked)
+	return nil
+}
+
+// backgroundScrubTargets returns the public tables of the databases other
+// than the system database.
+func backgroundScrubTargets(ctx context.Context, db *client.DB) ([]backgroundScrubTarget, error) {
+	var targets []backgroundScrubTarget
+	err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		targets = nil
+		descs, err := getAllDescriptors(ctx, txn)
+		if err != nil {
+			return err
+		}
+		dbNames := make(map[sqlbase.ID]string)
+		for _, desc := range descs {
+			if dbDesc, ok := desc.(*sqlbase.DatabaseDescriptor); ok {
+				dbNames[dbDesc.ID] = dbDesc.Name
+			}
+		}
+		for _, desc := range descs {
+			tableDesc, ok := desc.(*sqlbase.TableDescriptor)
+			if !ok || !tableDesc.IsTable() || tableDesc.State != sqlbase.TableDescriptor_PUBLIC ||
+				tableDesc.ParentID == keys.SystemDatabaseID {
+				continue
+			}
+			dbName, ok := dbNames[tableDesc.ParentID]
+			if !ok {
+				continue
+			}
+			targets = append(targets, backgroundScrubTarget{
+				id: tableDesc.ID,
+				name: tree.TableName{
+					DatabaseName: tree.Name(dbName),
+					TableName:    tree.Name(tableDesc.Name),
+				},
+			})
+		}
+		return nil
+	})
+	return targets, err
+}
+
+// holdsFirstRangeLease returns whether a store of this node holds the lease
+// of the range holding the start of the table.
+func holdsFirstRangeLease(ctx context.Context, e *Executor, id sqlbase.ID) (bool, error) {
+	b := &client.Batch{}
+	b.AddRawRequest(&roachpb.LeaseInfoRequest{
+		Span: roachpb.Span{
+			Key: roachpb.Key(keys.MakeTablePrefix(uint32(id))),
+		},
+	})
+	if err := e.cfg.DB.Run(ctx, b); err != nil {
+		return false, err
+	}
+	resp := b.RawResponse().Responses[0].GetInner().(*roachpb.LeaseInfoResponse)
+	return resp.Lease.Replica.NodeID == e.cfg.NodeID.Get(), nil
+}
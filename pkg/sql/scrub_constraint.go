@@ -0,0 +1,229 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// sqlCheckConstraintCheckOperation is a check which validates a CHECK
+// constraint against the rows of a table. It finds the rows violating
+// the constraint, which can exist when the constraint was added
+// without validating the existing rows, or when the table data was
+// corrupted.
+type sqlCheckConstraintCheckOperation struct {
+	started   bool
+	tableName *tree.TableName
+	tableDesc *sqlbase.TableDescriptor
+	checkDesc *sqlbase.TableDescriptor_CheckConstraint
+
+	// Intermediate values.
+	rows     *sqlbase.RowContainer
+	rowIndex int
+
+	// columns is the list of the columns of the table, in the order of
+	// the resulting rows.
+	columns []*sqlbase.ColumnDescriptor
+	// primaryColIdxs maps PrimaryIndex.Columns to the row indexes in the
+	// query result tree.Datums.
+	primaryColIdxs []int
+}
+
+func newSQLCheckConstraintCheckOperation(
+	tableName *tree.TableName,
+	tableDesc *sqlbase.TableDescriptor,
+	checkDesc *sqlbase.TableDescriptor_CheckConstraint,
+) *sqlCheckConstraintCheckOperation {
+	return &sqlCheckConstraintCheckOperation{
+		tableName: tableName,
+		tableDesc: tableDesc,
+		checkDesc: checkDesc,
+	}
+}
+
+// Start will plan and run the check constraint query using the distSQL
+// execution engine.
+func (o *sqlCheckConstraintCheckOperation) Start(ctx context.Context, p *planner) error {
+	var columnNames []string
+	var columnTypes []sqlbase.ColumnType
+	for i := range o.tableDesc.Columns {
+		col := &o.tableDesc.Columns[i]
+		o.columns = append(o.columns, col)
+		columnNames = append(columnNames, tree.Name(col.Name).String())
+		columnTypes = append(columnTypes, col.Type)
+	}
+
+	primaryColIdxs, err := getPrimaryColIdxs(o.tableDesc, o.columns)
+	if err != nil {
+		return err
+	}
+
+	checkQuery := createCheckConstraintQuery(columnNames, o.tableName, o.checkDesc.Expr)
+	plan, err := p.delegateQuery(ctx, "SCRUB TABLE ... WITH OPTIONS CONSTRAINT", checkQuery, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	// All the columns of the table are needed to report the violating
+	// rows.
+	needed := make([]bool, len(planColumns(plan)))
+	for i := range needed {
+		needed[i] = true
+	}
+
+	// Optimize the plan. This is required in order to populate scanNode
+	// spans.
+	plan, err = p.optimizePlan(ctx, plan, needed)
+	if err != nil {
+		plan.Close(ctx)
+		return err
+	}
+	defer plan.Close(ctx)
+
+	rows, err := scrubPlanAndRunDistSQL(ctx, p, plan, columnTypes)
+	if err != nil {
+		rows.Close(ctx)
+		return err
+	}
+
+	o.started = true
+	o.rows = rows
+	o.primaryColIdxs = primaryColIdxs
+	return nil
+}
+
+// Next implements the checkOperation interface.
+func (o *sqlCheckConstraintCheckOperation) Next(
+	ctx context.Context, p *planner,
+) (tree.Datums, error) {
+	row := o.rows.At(o.rowIndex)
+	o.rowIndex++
+
+	var primaryKeyDatums tree.Datums
+	for _, rowIdx := range o.primaryColIdxs {
+		primaryKeyDatums = append(primaryKeyDatums, row[rowIdx])
+	}
+	timestamp := tree.MakeDTimestamp(
+		p.evalCtx.GetStmtTimestamp(), time.Nanosecond)
+
+	details := make(map[string]interface{})
+	rowDetails := make(map[string]interface{})
+	details["row_data"] = rowDetails
+	details["constraint_name"] = o.checkDesc.Name
+	for rowIdx, col := range o.columns {
+		rowDetails[col.Name] = row[rowIdx].String()
+	}
+	detailsJSON, err := tree.MakeDJSON(details)
+	if err != nil {
+		return nil, err
+	}
+
+	return tree.Datums{
+		// TODO(joey): Add the job UUID once the SCRUB command uses jobs.
+		tree.DNull, /* job_uuid */
+		tree.NewDString(ScrubErrorCheckConstraintViolation),
+		tree.NewDString(o.tableName.Database()),
+		tree.NewDString(o.tableName.Table()),
+		tree.NewDString(primaryKeyDatums.String()),
+		timestamp,
+		tree.DBoolFalse,
+		detailsJSON,
+	}, nil
+}
+
+// Started implements the checkOperation interface.
+func (o *sqlCheckConstraintCheckOperation) Started() bool {
+	return o.started
+}
+
+// Done implements the checkOperation interface.
+func (o *sqlCheckConstraintCheckOperation) Done(ctx context.Context) bool {
+	return o.rows == nil || o.rowIndex >= o.rows.Len()
+}
+
+// Close implements the checkOperation interface.
+func (o *sqlCheckConstraintCheckOperation) Close(ctx context.Context) {
+	if o.rows != nil {
+		o.rows.Close(ctx)
+	}
+}
+
+// createCheckConstraintQuery will make the query finding the rows of a
+// table which violate a CHECK constraint. For example, given the
+// following table schema:
+//
+//   CREATE TABLE test (
+//     k INT PRIMARY KEY, v INT,
+//     CONSTRAINT v_positive CHECK (v > 0)
+//   )
+//
+// The generated query to check `v_positive` will be:
+//
+//   SELECT k, v FROM test WHERE NOT (v > 0)
+//
+// A row for which the expression is NULL satisfies the constraint, and
+// is filtered out by the predicate as well.
+func createCheckConstraintQuery(columnNames []string, tableName *tree.TableName, expr string) string {
+	return fmt.Sprintf("SELECT %s FROM %s WHERE NOT (%s)",
+		strings.Join(columnNames, ", "), tableName.String(), expr)
+}
+
+// createConstraintCheckOperations will return the checkOperations for
+// the provided CHECK constraints. If constraintNames is nil, then all
+// the CHECK constraints of the table are returned.
+func createConstraintCheckOperations(
+	constraintNames tree.NameList, tableDesc *sqlbase.TableDescriptor, tableName *tree.TableName,
+) (results []checkOperation, err error) {
+	if constraintNames == nil {
+		for _, check := range tableDesc.Checks {
+			results = append(results, newSQLCheckConstraintCheckOperation(
+				tableName, tableDesc, check,
+			))
+		}
+		return results, nil
+	}
+
+	// Find the constraints corresponding to the user input constraint
+	// names.
+	checks := make(map[string]*sqlbase.TableDescriptor_CheckConstraint)
+	for _, check := range tableDesc.Checks {
+		checks[check.Name] = check
+	}
+	var missingConstraintNames []string
+	for _, name := range constraintNames {
+		check, ok := checks[string(name)]
+		if !ok {
+			missingConstraintNames = append(missingConstraintNames, name.String())
+			continue
+		}
+		results = append(results, newSQLCheckConstraintCheckOperation(
+			tableName, tableDesc, check,
+		))
+	}
+	if len(missingConstraintNames) > 0 {
+		return nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+			"specified CHECK constraints to check that do not exist on table %q: %v",
+			tableDesc.Name, strings.Join(missingConstraintNames, ", "))
+	}
+	return results, nil
+}
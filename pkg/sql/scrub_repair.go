@@ -0,0 +1,541 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"sort"
+	"time"
+
+	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// A SCRUB repair job, run by EXPERIMENTAL SCRUB TABLE ... WITH OPTIONS
+// REPAIR, repairs the secondary indexes of a table in which the index checks
+// found errors. Each secondary index is scanned in turn to delete its
+// dangling entries, which don't match the row they reference; the primary
+// index is then scanned to add the entries missing for its rows. Deleting
+// the dangling entries first frees the keys of the unique indexes they hold.
+// The indexes are scanned in batches, each in a transaction of its own, at a
+// rate limited by sql.scrub.repair.rate_limit.
+
+var scrubRepairBatchSize = settings.RegisterIntSetting(
+	"sql.scrub.repair.batch_size",
+	"the number of index entries or rows checked per transaction by a SCRUB repair job",
+	100,
+)
+
+var scrubRepairRateLimit = settings.RegisterIntSetting(
+	"sql.scrub.repair.rate_limit",
+	"the maximum number of index entries or rows checked per second by a SCRUB repair job (0 for no limit)",
+	0,
+)
+
+// scrubRepairCheckpointInterval is the interval at which a SCRUB repair job
+// checkpoints its progress.
+const scrubRepairCheckpointInterval = 10 * time.Second
+
+// runScrubRepairJob creates a SCRUB repair job repairing secondary indexes of
+// a table, and runs it on this node until it is done. It returns the ID of
+// the job.
+func runScrubRepairJob(
+	ctx context.Context,
+	p *planner,
+	desc *sqlbase.TableDescriptor,
+	indexIDs []sqlbase.IndexID,
+	description string,
+) (int64, error) {
+	if err := checkScrubRepairTable(desc, indexIDs); err != nil {
+		return 0, err
+	}
+	job := p.ExecCfg().JobRegistry.NewJob(jobs.Record{
+		Description:   description,
+		Username:      p.User(),
+		DescriptorIDs: sqlbase.IDs{desc.ID},
+		Details: jobs.ScrubRepairDetails{
+			TableID:        desc.ID,
+			IndexIDs:       indexIDs,
+			CurrentIndexID: indexIDs[0],
+			ResumeSpan:     desc.IndexSpan(indexIDs[0]),
+		},
+	})
+	_, repairErr := repairIndexEntries(ctx, p.ExecCfg().DB, p.ExecCfg().Settings, job)
+	if err := job.FinishedWith(ctx, repairErr); err != nil {
+		return 0, err
+	}
+	if repairErr != nil {
+		return 0, repairErr
+	}
+	return *job.ID(), nil
+}
+
+// checkScrubRepairTable checks that the entries of secondary indexes of a
+// table can be repaired by a SCRUB repair job: no schema change must be in
+// progress, and the table and indexes must not be interleaved. The indexes
+// which no longer exist are ignored.
+func checkScrubRepairTable(desc *sqlbase.TableDescriptor, indexIDs []sqlbase.IndexID) error {
+	if len(desc.Mutations) > 0 {
+		return pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"cannot repair table %s while a schema change is in progress", desc.Name)
+	}
+	if len(desc.PrimaryIndex.Interleave.Ancestors) > 0 {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot repair interleaved table %s", desc.Name)
+	}
+	for _, id := range indexIDs {
+		index, err := desc.FindIndexByID(id)
+		if err != nil {
+			continue
+		}
+		if len(index.Interleave.Ancestors) > 0 {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot repair interleaved index %s", index.Name)
+		}
+		if index.EncodingType == sqlbase.IndexDescriptor_PRIMARY {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot repair index %s: it is the former primary index of table %s",
+				index.Name, desc.Name)
+		}
+	}
+	return nil
+}
+
+// repairIndexEntries runs a SCRUB repair job, starting at the step and span
+// of its details recorded by the last checkpoint. It returns the details of
+// the job once it is done, which count the entries repaired.
+func repairIndexEntries(
+	ctx context.Context, db *client.DB, st *cluster.Settings, job *jobs.Job,
+) (jobs.ScrubRepairDetails, error) {
+	details := job.Record.Details.(jobs.ScrubRepairDetails)
+	if err := job.Created(ctx, jobs.WithoutCancel); err != nil {
+		return details, err
+	}
+	if err := job.Started(ctx); err != nil {
+		return details, err
+	}
+
+	var limiter *rate.Limiter
+	lastCheckpoint := timeutil.Now()
+	for details.CurrentIndexID != 0 {
+		batchSize := scrubRepairBatchSize.Get(&st.SV)
+		if batchSize < 1 {
+			batchSize = 1
+		}
+		if limit := scrubRepairRateLimit.Get(&st.SV); limit > 0 {
+			if limiter == nil || limiter.Limit() != rate.Limit(limit) || limiter.Burst() != int(batchSize) {
+				limiter = rate.NewLimiter(rate.Limit(limit), int(batchSize))
+			}
+		} else {
+			limiter = nil
+		}
+
+		var n int64
+		var err error
+		details, n, err = repairIndexEntriesChunk(ctx, db, details, batchSize)
+		if err != nil {
+			return details, err
+		}
+		if limiter != nil && n > 0 {
+			if err := limiter.WaitN(ctx, int(n)); err != nil {
+				return details, err
+			}
+		}
+		if details.CurrentIndexID != 0 &&
+			timeutil.Since(lastCheckpoint) < scrubRepairCheckpointInterval {
+			continue
+		}
+		checkpoint := details
+		if err := job.Progressed(ctx, scrubRepairFractionCompleted(details),
+			func(ctx context.Context, d interface{}) {
+				d.(*jobs.Payload_ScrubRepair).ScrubRepair = &checkpoint
+			},
+		); err != nil {
+			return details, err
+		}
+		lastCheckpoint = timeutil.Now()
+	}
+	return details, nil
+}
+
+// scrubRepairStep returns the position of the secondary index scanned by the
+// current step of a SCRUB repair job in its indexes, or -1 if the primary
+// index is scanned.
+func scrubRepairStep(details jobs.ScrubRepairDetails) int {
+	for i, id := range details.IndexIDs {
+		if id == details.CurrentIndexID {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextScrubRepairStep moves the details of a SCRUB repair job to the start of
+// the step following the current one, or marks the job done after the scan
+// of the primary index.
+func nextScrubRepairStep(desc *sqlbase.TableDescriptor, details *jobs.ScrubRepairDetails) {
+	i := scrubRepairStep(*details)
+	if i < 0 {
+		details.CurrentIndexID = 0
+		details.ResumeSpan = roachpb.Span{}
+		return
+	}
+	next := desc.PrimaryIndex.ID
+	if i+1 < len(details.IndexIDs) {
+		next = details.IndexIDs[i+1]
+	}
+	details.CurrentIndexID = next
+	details.ResumeSpan = desc.IndexSpan(next)
+}
+
+// scrubRepairFractionCompleted returns the fraction of the steps of a SCRUB
+// repair job which are done.
+func scrubRepairFractionCompleted(details jobs.ScrubRepairDetails) float32 {
+	if details.CurrentIndexID == 0 {
+		return 1
+	}
+	done := scrubRepairStep(details)
+	if done < 0 {
+		done = len(details.IndexIDs)
+	}
+	return float32(done) / float32(len(details.IndexIDs)+1)
+}
+
+// repairIndexEntriesChunk runs the current step of a SCRUB repair job over up
+// to batchSize index entries or rows in a transaction. It returns the details
+// of the job advanced past them, and the number of entries or rows checked.
+func repairIndexEntriesChunk(
+	ctx context.Context, db *client.DB, details jobs.ScrubRepairDetails, batchSize int64,
+) (jobs.ScrubRepairDetails, int64, error) {
+	var res jobs.ScrubRepairDetails
+	var checked int64
+	err := db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		res, checked = details, 0
+		desc, err := sqlbase.GetTableDescFromID(ctx, txn, details.TableID)
+		if err != nil {
+			return err
+		}
+		if desc.Dropped() {
+			return errTableDropped
+		}
+		if err := checkScrubRepairTable(desc, details.IndexIDs); err != nil {
+			return err
+		}
+
+		b := txn.NewBatch()
+		var resumeKey roachpb.Key
+		var repaired int64
+		if scrubRepairStep(details) >= 0 {
+			resumeKey, checked, repaired, err = deleteDanglingIndexEntries(
+				ctx, txn, b, desc, details, batchSize)
+			res.DanglingEntriesDeleted += repaired
+		} else {
+			resumeKey, checked, repaired, err = addMissingIndexEntries(
+				ctx, txn, b, desc, details, batchSize)
+			res.MissingEntriesAdded += repaired
+		}
+		if err != nil {
+			return err
+		}
+		if resumeKey != nil {
+			res.ResumeSpan.Key = resumeKey
+		} else {
+			nextScrubRepairStep(desc, &res)
+		}
+		return txn.CommitInBatch(ctx, b)
+	})
+	if err != nil {
+		return details, 0, err
+	}
+	return res, checked, nil
+}
+
+// scrubRepairIndexColumns returns the columns encoded in the keys of the
+// entries of a secondary index, which include the columns of the primary
+// key, and the map of their IDs to their position.
+func scrubRepairIndexColumns(
+	desc *sqlbase.TableDescriptor, index *sqlbase.IndexDescriptor,
+) ([]sqlbase.ColumnDescriptor, map[sqlbase.ColumnID]int, error) {
+	colIDs := append(append([]sqlbase.ColumnID(nil), index.ColumnIDs...), index.ExtraColumnIDs...)
+	cols := make([]sqlbase.ColumnDescriptor, len(colIDs))
+	colIdxMap := make(map[sqlbase.ColumnID]int, len(colIDs))
+	for i, id := range colIDs {
+		col, err := desc.FindColumnByID(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		cols[i] = *col
+		colIdxMap[id] = i
+	}
+	return cols, colIdxMap, nil
+}
+
+// deleteDanglingIndexEntries scans up to batchSize entries of the secondary
+// index of the current step of a SCRUB repair job, and deletes the entries
+// which don't match the current values of the row they reference, or whose
+// row doesn't exist. It returns the key to resume at, or nil if the index
+// was scanned, the number of entries checked, and the number deleted.
+func deleteDanglingIndexEntries(
+	ctx context.Context,
+	txn *client.Txn,
+	b *client.Batch,
+	desc *sqlbase.TableDescriptor,
+	details jobs.ScrubRepairDetails,
+	batchSize int64,
+) (roachpb.Key, int64, int64, error) {
+	index, err := desc.FindIndexByID(details.CurrentIndexID)
+	if err != nil {
+		// The index was dropped since the job started.
+		return nil, 0, 0, nil
+	}
+	cols, colIdxMap, err := scrubRepairIndexColumns(desc, index)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	var valNeededForCol util.FastIntSet
+	valNeededForCol.AddRange(0, len(cols)-1)
+
+	var alloc sqlbase.DatumAlloc
+	var fetcher sqlbase.MultiRowFetcher
+	if err := fetcher.Init(
+		false /* reverse */, false /* returnRangeInfo */, &alloc,
+		sqlbase.MultiRowFetcherTableArgs{
+			Desc:             desc,
+			Index:            index,
+			ColIdxMap:        colIdxMap,
+			IsSecondaryIndex: true,
+			Cols:             cols,
+			ValNeededForCol:  valNeededForCol,
+		},
+	); err != nil {
+		return nil, 0, 0, err
+	}
+	if err := fetcher.StartScan(
+		ctx, txn, roachpb.Spans{details.ResumeSpan}, true /* limitBatches */, batchSize,
+		false, /* traceKV */
+	); err != nil {
+		return nil, 0, 0, err
+	}
+
+	// The keys of the entries are recomputed from their values, along with
+	// the spans of the rows they reference.
+	primaryPrefix := sqlbase.MakeIndexKeyPrefix(desc, desc.PrimaryIndex.ID)
+	var entryKeys []roachpb.Key
+	var rowSpans roachpb.Spans
+	for i := int64(0); i < batchSize; i++ {
+		datums, _, _, err := fetcher.NextRowDecoded(ctx)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if datums == nil {
+			break
+		}
+		entry, err := sqlbase.EncodeSecondaryIndex(desc, index, colIdxMap, datums)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		rowKey, _, err := sqlbase.EncodeIndexKey(
+			desc, &desc.PrimaryIndex, colIdxMap, datums, primaryPrefix)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		entryKeys = append(entryKeys, entry.Key)
+		rowSpans = append(rowSpans, roachpb.Span{
+			Key: rowKey, EndKey: roachpb.Key(rowKey).PrefixEnd(),
+		})
+	}
+	resumeKey := fetcher.Key()
+	if len(entryKeys) == 0 {
+		return resumeKey, 0, 0, nil
+	}
+
+	// The rows are fetched to compute the keys of their entries. Several
+	// entries may reference the same row.
+	sort.Sort(rowSpans)
+	uniqueSpans := rowSpans[:1]
+	for _, sp := range rowSpans[1:] {
+		if !sp.Key.Equal(uniqueSpans[len(uniqueSpans)-1].Key) {
+			uniqueSpans = append(uniqueSpans, sp)
+		}
+	}
+	var rowFetcher sqlbase.MultiRowFetcher
+	if err := rowFetcher.Init(
+		false /* reverse */, false /* returnRangeInfo */, &alloc,
+		sqlbase.MultiRowFetcherTableArgs{
+			Desc:            desc,
+			Index:           &desc.PrimaryIndex,
+			ColIdxMap:       colIdxMap,
+			Cols:            cols,
+			ValNeededForCol: valNeededForCol,
+		},
+	); err != nil {
+		return nil, 0, 0, err
+	}
+	if err := rowFetcher.StartScan(
+		ctx, txn, uniqueSpans, false /* limitBatches */, 0 /* limitHint */, false, /* traceKV */
+	); err != nil {
+		return nil, 0, 0, err
+	}
+	expected := make(map[string]struct{}, len(uniqueSpans))
+	for {
+		datums, _, _, err := rowFetcher.NextRowDecoded(ctx)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if datums == nil {
+			break
+		}
+		entry, err := sqlbase.EncodeSecondaryIndex(desc, index, colIdxMap, datums)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		expected[string(entry.Key)] = struct{}{}
+	}
+
+	var deleted int64
+	for _, key := range entryKeys {
+		if _, ok := expected[string(key)]; !ok {
+			b.Del(key)
+			deleted++
+		}
+	}
+	return resumeKey, int64(len(entryKeys)), deleted, nil
+}
+
+// addMissingIndexEntries scans up to batchSize rows of the primary index of
+// a table, and adds the entries of the secondary indexes of a SCRUB repair
+// job missing for them. An entry whose key is held by another row of a
+// unique index is not added. It returns the key to resume at, or nil if the
+// primary index was scanned, the number of rows checked, and the number of
+// entries added.
+func addMissingIndexEntries(
+	ctx context.Context,
+	txn *client.Txn,
+	b *client.Batch,
+	desc *sqlbase.TableDescriptor,
+	details jobs.ScrubRepairDetails,
+	batchSize int64,
+) (roachpb.Key, int64, int64, error) {
+	var indexes []*sqlbase.IndexDescriptor
+	for _, id := range details.IndexIDs {
+		// The indexes dropped since the job started are skipped.
+		if index, err := desc.FindIndexByID(id); err == nil {
+			indexes = append(indexes, index)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil, 0, 0, nil
+	}
+	span := details.ResumeSpan
+	if !desc.PrimaryIndexSpan().Contains(span) {
+		// The primary key of the table was changed since the step started.
+		span = desc.PrimaryIndexSpan()
+	}
+
+	colIdxMap := make(map[sqlbase.ColumnID]int, len(desc.Columns))
+	var valNeededForCol util.FastIntSet
+	for i, col := range desc.Columns {
+		colIdxMap[col.ID] = i
+		valNeededForCol.Add(i)
+	}
+	var alloc sqlbase.DatumAlloc
+	var fetcher sqlbase.MultiRowFetcher
+	if err := fetcher.Init(
+		false /* reverse */, false /* returnRangeInfo */, &alloc,
+		sqlbase.MultiRowFetcherTableArgs{
+			Desc:            desc,
+			Index:           &desc.PrimaryIndex,
+			ColIdxMap:       colIdxMap,
+			Cols:            desc.Columns,
+			ValNeededForCol: valNeededForCol,
+		},
+	); err != nil {
+		return nil, 0, 0, err
+	}
+	if err := fetcher.StartScan(
+		ctx, txn, roachpb.Spans{span}, true /* limitBatches */, batchSize,
+		false, /* traceKV */
+	); err != nil {
+		return nil, 0, 0, err
+	}
+
+	var checked int64
+	var entries []sqlbase.IndexEntry
+	seen := make(map[string]struct{})
+	getBatch := txn.NewBatch()
+	for ; checked < batchSize; checked++ {
+		datums, _, _, err := fetcher.NextRowDecoded(ctx)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if datums == nil {
+			break
+		}
+		for _, index := range indexes {
+			entry, err := sqlbase.EncodeSecondaryIndex(desc, index, colIdxMap, datums)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+			if _, ok := seen[string(entry.Key)]; ok {
+				continue
+			}
+			seen[string(entry.Key)] = struct{}{}
+			entries = append(entries, entry)
+			getBatch.Get(entry.Key)
+		}
+	}
+	resumeKey := fetcher.Key()
+	if len(entries) == 0 {
+		return resumeKey, checked, 0, nil
+	}
+	if err := txn.Run(ctx, getBatch); err != nil {
+		return nil, 0, 0, err
+	}
+
+	var added int64
+	for i, result := range getBatch.Results {
+		if result.Rows[0].Value != nil {
+			continue
+		}
+		b.CPut(entries[i].Key, &entries[i].Value, nil /* expValue */)
+		added++
+	}
+	return resumeKey, checked, added, nil
+}
+
+func scrubRepairResumeHook(
+	typ jobs.Type, settings *cluster.Settings,
+) func(context.Context, *jobs.Job) error {
+	if typ != jobs.TypeScrubRepair {
+		return nil
+	}
+	return func(ctx context.Context, job *jobs.Job) error {
+		_, err := repairIndexEntries(ctx, job.DB(), settings, job)
+		return err
+	}
+}
+
+func init() {
+	jobs.AddResumeHook(scrubRepairResumeHook)
+}
@@ -16,16 +16,19 @@ package sql_test
 
 import (
 	gosql "database/sql"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 )
@@ -348,3 +351,216 @@ INSERT INTO t.test VALUES (10, 20, 1337);
 		t.Fatalf("expected erorr details to contain `%s`, got %s", `"data":"314"`, result.details)
 	}
 }
+
+// TestScrubConstraintCheckViolation tests that
+// `SCRUB TABLE ... CONSTRAINT ALL` will find the rows violating a CHECK
+// constraint. To test this, the constraint is added to a table which
+// already has a violating row, which leaves the constraint unvalidated.
+func TestScrubConstraintCheckViolation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := db.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.test (k INT PRIMARY KEY, v INT);
+INSERT INTO t.test VALUES (1, 10), (2, -20), (3, NULL);
+ALTER TABLE t.test ADD CONSTRAINT v_positive CHECK (v > 0);
+`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, stmt := range []string{
+		`EXPERIMENTAL SCRUB TABLE t.test WITH OPTIONS CONSTRAINT ALL`,
+		`EXPERIMENTAL SCRUB TABLE t.test WITH OPTIONS CONSTRAINT (v_positive)`,
+		`EXPERIMENTAL SCRUB TABLE t.test`,
+	} {
+		rows, err := db.Query(stmt)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		results, err := getResultRows(rows)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if len(results) != 1 {
+			t.Fatalf("%s: expected 1 result, got %d. got %#v", stmt, len(results), results)
+		}
+		if result := results[0]; result.errorType != sql.ScrubErrorCheckConstraintViolation {
+			t.Fatalf("expected %q error, instead got: %s",
+				sql.ScrubErrorCheckConstraintViolation, result.errorType)
+		} else if result.database != "t" {
+			t.Fatalf("expected database %q, got %q", "t", result.database)
+		} else if result.table != "test" {
+			t.Fatalf("expected table %q, got %q", "test", result.table)
+		} else if result.primaryKey != "(2)" {
+			t.Fatalf("expected primaryKey %q, got %q", "(2)", result.primaryKey)
+		} else if result.repaired {
+			t.Fatalf("expected repaired %v, got %v", false, result.repaired)
+		} else if !strings.Contains(result.details, `"constraint_name":"v_positive"`) {
+			t.Fatalf("expected error details to contain `%s`, got %s",
+				`"constraint_name":"v_positive"`, result.details)
+		} else if !strings.Contains(result.details, `"v":"-20"`) {
+			t.Fatalf("expected error details to contain `%s`, got %s", `"v":"-20"`, result.details)
+		}
+	}
+}
+
+// TestScrubBackground tests that the background SCRUB checks report the
+// errors they find in crdb_internal.scrub_errors.
+func TestScrubBackground(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := db.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.test (k INT PRIMARY KEY, v INT);
+INSERT INTO t.test VALUES (1, 10), (2, -20);
+ALTER TABLE t.test ADD CONSTRAINT v_positive CHECK (v > 0);
+SET CLUSTER SETTING sql.scrub.background.idle_ratio = 0;
+SET CLUSTER SETTING sql.scrub.background.interval = '10ms';
+`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	testutils.SucceedsSoon(t, func() error {
+		var errorType, table, primaryKey string
+		if err := db.QueryRow(`
+SELECT error_type, table_name, primary_key FROM crdb_internal.scrub_errors
+WHERE database_name = 't'`).Scan(&errorType, &table, &primaryKey); err != nil {
+			return err
+		}
+		if errorType != sql.ScrubErrorCheckConstraintViolation || table != "test" || primaryKey != "(2)" {
+			return errors.Errorf("unexpected error %s on %s%s", errorType, table, primaryKey)
+		}
+		return nil
+	})
+
+	// The errors are cleared once the violating row is fixed.
+	if _, err := db.Exec(`UPDATE t.test SET v = 20 WHERE k = 2`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	testutils.SucceedsSoon(t, func() error {
+		var count int
+		if err := db.QueryRow(
+			`SELECT count(*) FROM crdb_internal.scrub_errors WHERE database_name = 't'`,
+		).Scan(&count); err != nil {
+			return err
+		}
+		if count != 0 {
+			return errors.Errorf("expected no errors, got %d", count)
+		}
+		return nil
+	})
+}
+
+// TestScrubRepair tests that `SCRUB TABLE ... WITH OPTIONS REPAIR` repairs
+// the index errors it finds with a job. To test this, the secondary index
+// k/v of a row is deleted and a dangling index k/v is inserted using the KV
+// client.
+func TestScrubRepair(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := db.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.test (k INT PRIMARY KEY, v INT);
+CREATE INDEX secondary ON t.test (v);
+INSERT INTO t.test VALUES (10, 20), (11, 21), (12, 22);
+SET CLUSTER SETTING sql.scrub.repair.batch_size = 1;
+`); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tableDesc := sqlbase.GetTableDescriptor(kvDB, "t", "test")
+	secondaryIndexDesc := &tableDesc.Indexes[0]
+	colIDtoRowIndex := make(map[sqlbase.ColumnID]int)
+	colIDtoRowIndex[tableDesc.Columns[0].ID] = 0
+	colIDtoRowIndex[tableDesc.Columns[1].ID] = 1
+
+	// Delete the entry of (10, 20), and insert an entry of (13, 314) which
+	// doesn't reference any row.
+	missing, err := sqlbase.EncodeSecondaryIndex(tableDesc, secondaryIndexDesc, colIDtoRowIndex,
+		[]tree.Datum{tree.NewDInt(10), tree.NewDInt(20)})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := kvDB.Del(context.TODO(), missing.Key); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	dangling, err := sqlbase.EncodeSecondaryIndex(tableDesc, secondaryIndexDesc, colIDtoRowIndex,
+		[]tree.Datum{tree.NewDInt(13), tree.NewDInt(314)})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := kvDB.Put(context.TODO(), dangling.Key, &dangling.Value); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rows, err := db.Query(`EXPERIMENTAL SCRUB TABLE t.test WITH OPTIONS INDEX ALL, REPAIR`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	results, err := getResultRows(rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d. got %#v", len(results), results)
+	}
+	errorTypes := map[string]bool{}
+	for _, result := range results {
+		errorTypes[result.errorType] = true
+		if !result.repaired {
+			t.Fatalf("expected repaired %v, got %v", true, result.repaired)
+		} else if !strings.Contains(result.details, `"repair_job_id":`) {
+			t.Fatalf("expected error details to contain the repair job ID, got %s", result.details)
+		}
+	}
+	if !errorTypes[sql.ScrubErrorMissingIndexEntry] || !errorTypes[sql.ScrubErrorDanglingIndexReference] {
+		t.Fatalf("expected %q and %q errors, got %#v",
+			sql.ScrubErrorMissingIndexEntry, sql.ScrubErrorDanglingIndexReference, results)
+	}
+
+	var status string
+	if err := db.QueryRow(
+		`SELECT status FROM crdb_internal.jobs WHERE type = 'SCRUB REPAIR'`,
+	).Scan(&status); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if status != "succeeded" {
+		t.Fatalf("expected the repair job to succeed, got status %s", status)
+	}
+
+	// The index is consistent with the table once repaired.
+	rows, err = db.Query(`EXPERIMENTAL SCRUB TABLE t.test`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if results, err := getResultRows(rows); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if len(results) != 0 {
+		t.Fatalf("expected no results after the repair, got %#v", results)
+	}
+	rows, err = db.Query(`SELECT k FROM t.test@secondary ORDER BY k`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer rows.Close()
+	var keys []int
+	for rows.Next() {
+		var k int
+		if err := rows.Scan(&k); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(keys, []int{10, 11, 12}) {
+		t.Fatalf("expected the index entries of rows [10 11 12], got %v", keys)
+	}
+}
@@ -37,6 +37,9 @@ type nameResolutionVisitor struct {
 	sources    multiSourceInfo
 	iVarHelper tree.IndexedVarHelper
 	searchPath tree.SearchPath
+	// outer, if non-nil, is used to resolve names that are not found in
+	// sources; see correlationScope.
+	outer *correlationScope
 
 	// foundDependentVars is set to true during the analysis if an
 	// expression was found which can change values between rows of the
@@ -117,6 +120,14 @@ func (v *nameResolutionVisitor) VisitPre(expr tree.Expr) (recurse bool, newNode
 	case *tree.ColumnItem:
 		srcIdx, colIdx, err := v.sources.findColumn(t)
 		if err != nil {
+			if v.outer != nil {
+				if outerExpr, ok := v.outer.resolve(t); ok {
+					// The column belongs to the surrounding query: this is a
+					// correlated sub-query.
+					v.foundDependentVars = true
+					return false, outerExpr
+				}
+			}
 			v.err = err
 			return false, expr
 		}
@@ -224,6 +235,7 @@ func (p *planner) resolveNames(
 		sources:            sources,
 		iVarHelper:         ivarHelper,
 		searchPath:         p.session.SearchPath,
+		outer:              p.correlationScope,
 		foundDependentVars: false,
 	}
 	colOffset := 0
@@ -38,7 +38,9 @@ import (
 
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/internal/sqlsmith"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -626,6 +628,48 @@ var Builtins = map[string][]tree.Builtin{
 		},
 	},
 
+	"encrypt_column": {
+		tree.Builtin{
+			Types:            tree.ArgTypes{{"val", types.Bytes}},
+			ReturnType:       tree.FixedReturnType(types.Bytes),
+			Impure:           true,
+			DistsqlBlacklist: true,
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return encryptColumn(ctx, []byte(*args[0].(*tree.DBytes)))
+			},
+			Info: "Encrypts `val` with a data key wrapped by the KMS of the cluster setting " +
+				"`sql.column_encryption.kms_uri`. Decrypt the result with `decrypt_column()`.",
+		},
+		tree.Builtin{
+			Types:            tree.ArgTypes{{"val", types.String}},
+			ReturnType:       tree.FixedReturnType(types.Bytes),
+			Impure:           true,
+			DistsqlBlacklist: true,
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return encryptColumn(ctx, []byte(tree.MustBeDString(args[0])))
+			},
+			Info: "Encrypts `val` with a data key wrapped by the KMS of the cluster setting " +
+				"`sql.column_encryption.kms_uri`. Decrypt the result with `decrypt_column()`.",
+		},
+	},
+
+	"decrypt_column": {
+		tree.Builtin{
+			Types:            tree.ArgTypes{{"val", types.Bytes}},
+			ReturnType:       tree.FixedReturnType(types.Bytes),
+			DistsqlBlacklist: true,
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				plaintext, err := ctx.Planner.DecryptColumnValue(ctx.Ctx(), []byte(*args[0].(*tree.DBytes)))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDBytes(tree.DBytes(plaintext)), nil
+			},
+			Info: "Decrypts a value returned by `encrypt_column()`, whichever data key and version " +
+				"of the master key encrypted it.",
+		},
+	},
+
 	// The SQL parser coerces POSITION to STRPOS.
 	"strpos": {stringBuiltin2("input", "find", func(_ *tree.EvalContext, s, substring string) (tree.Datum, error) {
 		index := strings.Index(s, substring)
@@ -1162,6 +1206,25 @@ CockroachDB supports the following flags:
 		},
 	},
 
+	"follower_read_timestamp": {
+		tree.Builtin{
+			Types:            tree.ArgTypes{},
+			ReturnType:       tree.FixedReturnType(types.TimestampTZ),
+			Category:         categorySystemInfo,
+			Impure:           true,
+			DistsqlBlacklist: true,
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				ts, err := ctx.Planner.FollowerReadTimestamp()
+				if err != nil {
+					return nil, err
+				}
+				return tree.MakeDTimestampTZ(ts, time.Microsecond), nil
+			},
+			Info: "Returns a timestamp old enough for the reads at it to be served by the " +
+				"closest replica rather than the lease holder, for use with AS OF SYSTEM TIME.",
+		},
+	},
+
 	"clock_timestamp": {
 		tree.Builtin{
 			Types:             tree.ArgTypes{},
@@ -1328,7 +1391,8 @@ CockroachDB supports the following flags:
 			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
 				fromTSTZ := args[1].(*tree.DTimestampTZ)
 				timeSpan := strings.ToLower(string(tree.MustBeDString(args[0])))
-				return truncateTimestamp(ctx, fromTSTZ.Time, timeSpan)
+				// Truncate the time as seen in the session's time zone.
+				return truncateTimestamp(ctx, fromTSTZ.Time.In(ctx.GetLocation()), timeSpan)
 			},
 			Info: "Truncates `input` to precision `element`.  Sets all fields that are less\n" +
 				"significant than `element` to zero (or one, for day and month)\n\n" +
@@ -1954,17 +2018,34 @@ CockroachDB supports the following flags:
 
 	// For now, schemas are the same as databases. So, current_schemas
 	// returns the current database (if one has been set by the user)
-	// and the session's database search path.
+	// and the databases of the session's search path, in the order
+	// they are searched. As in Postgres, databases that don't exist
+	// and duplicates are omitted.
 	"current_schemas": {
 		tree.Builtin{
-			Types:      tree.ArgTypes{{"include_pg_catalog", types.Bool}},
-			ReturnType: tree.FixedReturnType(types.TArray{Typ: types.String}),
-			Category:   categorySystemInfo,
+			Types:            tree.ArgTypes{{"include_pg_catalog", types.Bool}},
+			ReturnType:       tree.FixedReturnType(types.TArray{Typ: types.String}),
+			Category:         categorySystemInfo,
+			DistsqlBlacklist: true,
 			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
 				includePgCatalog := *(args[0].(*tree.DBool))
 				schemas := tree.NewDArray(types.String)
+				seen := make(map[string]struct{})
+				add := func(schema string) error {
+					if _, ok := seen[schema]; ok {
+						return nil
+					}
+					seen[schema] = struct{}{}
+					if ctx.Planner != nil {
+						exists, err := ctx.Planner.SchemaExists(ctx.Ctx(), schema)
+						if err != nil || !exists {
+							return err
+						}
+					}
+					return schemas.Append(tree.NewDString(schema))
+				}
 				if len(ctx.Database) != 0 {
-					if err := schemas.Append(tree.NewDString(ctx.Database)); err != nil {
+					if err := add(ctx.Database); err != nil {
 						return nil, err
 					}
 				}
@@ -1975,10 +2056,7 @@ CockroachDB supports the following flags:
 					iter = ctx.SearchPath.IterWithoutImplicitPGCatalog()
 				}
 				for p, ok := iter(); ok; p, ok = iter() {
-					if p == ctx.Database {
-						continue
-					}
-					if err := schemas.Append(tree.NewDString(p)); err != nil {
+					if err := add(p); err != nil {
 						return nil, err
 					}
 				}
@@ -2142,6 +2220,157 @@ CockroachDB supports the following flags:
 				"Incorrect use can severely impact performance.",
 		},
 	},
+
+	"crdb_internal.reset_statement_statistics": {
+		tree.Builtin{
+			Types:      tree.ArgTypes{},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Impure:     true,
+			Fn: func(ctx *tree.EvalContext, _ tree.Datums) (tree.Datum, error) {
+				if err := ctx.Planner.ResetStatementStatistics(ctx.Ctx()); err != nil {
+					return nil, err
+				}
+				return tree.DBoolTrue, nil
+			},
+			Category: categorySystemInfo,
+			Info: "Clears the statement statistics collected on the current node, " +
+				"as shown in crdb_internal.statement_statistics.",
+		},
+	},
+
+	"crdb_internal.statement_fingerprint": {
+		tree.Builtin{
+			Types:      tree.ArgTypes{{"sql", types.String}},
+			ReturnType: tree.FixedReturnType(types.String),
+			// Impure because the statement is parsed by the planner.
+			Impure: true,
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				stmt, err := ctx.Planner.ParseStatement(string(tree.MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDString(tree.StatementFingerprint(stmt)), nil
+			},
+			Category: categorySystemInfo,
+			Info: "Returns the fingerprint of a SQL statement, which is the statement " +
+				"normalized with its constants replaced by _, as shown in " +
+				"crdb_internal.statement_statistics.",
+		},
+	},
+
+	"crdb_internal.statement_fingerprint_id": {
+		tree.Builtin{
+			Types:      tree.ArgTypes{{"sql", types.String}},
+			ReturnType: tree.FixedReturnType(types.String),
+			// Impure because the statement is parsed by the planner.
+			Impure: true,
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				stmt, err := ctx.Planner.ParseStatement(string(tree.MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				id := tree.StatementFingerprintID(tree.StatementFingerprint(stmt))
+				return tree.NewDString(id), nil
+			},
+			Category: categorySystemInfo,
+			Info: "Returns the stable identifier of the fingerprint of a SQL statement, " +
+				"as shown in crdb_internal.statement_statistics.",
+		},
+	},
+
+	"crdb_internal.request_statement_diagnostics": {
+		tree.Builtin{
+			Types: tree.ArgTypes{
+				{"statement_fingerprint", types.String},
+				{"min_execution_latency", types.Interval},
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Impure:     true,
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				fingerprint := string(tree.MustBeDString(args[0]))
+				minLatency := args[1].(*tree.DInterval).Duration
+				if minLatency.Months != 0 || minLatency.Days != 0 {
+					return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+						"min_execution_latency cannot contain days or months: %s", args[1])
+				}
+				if minLatency.Nanos < 0 {
+					return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+						"min_execution_latency cannot be negative: %s", args[1])
+				}
+				id, err := ctx.Planner.RequestStatementDiagnostics(
+					ctx.Ctx(), fingerprint, time.Duration(minLatency.Nanos))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDInt(tree.DInt(id)), nil
+			},
+			Category: categorySystemInfo,
+			Info: "Requests the collection of a statement bundle, stored in " +
+				"system.statement_bundles, for the next execution of the statement " +
+				"fingerprint running for longer than the minimum latency. Returns the ID of " +
+				"the request in system.statement_diagnostics_requests.",
+		},
+	},
+
+	"crdb_internal.generate_random_query": {
+		tree.Builtin{
+			Types:            tree.ArgTypes{{"seed", types.Int}},
+			ReturnType:       tree.FixedReturnType(types.String),
+			Impure:           true,
+			DistsqlBlacklist: true,
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				tables, err := randomQueryTables(ctx)
+				if err != nil {
+					return nil, err
+				}
+				rnd := rand.New(rand.NewSource(int64(tree.MustBeDInt(args[0]))))
+				return tree.NewDString(sqlsmith.NewSmither(rnd, tables).Generate()), nil
+			},
+			Category: categorySystemInfo,
+			Info: "Generates a random query against the tables of the current database, " +
+				"determined by the seed and the schema. This function is used only by " +
+				"CockroachDB's developers for testing purposes.",
+		},
+	},
+}
+
+// randomQueryTables returns the tables of the current database, against
+// which crdb_internal.generate_random_query generates queries.
+func randomQueryTables(ctx *tree.EvalContext) ([]sqlsmith.Table, error) {
+	if ctx.Database == "" {
+		return nil, nil
+	}
+	// The columns are aggregated in the order in which they are listed, which
+	// is grouped by table.
+	r, err := ctx.Planner.QueryRow(ctx.Ctx(), `
+SELECT array_agg(table_name), array_agg(column_name), array_agg(data_type), array_agg(is_nullable = 'YES')
+  FROM information_schema.columns
+ WHERE table_schema = $1`, ctx.Database)
+	if err != nil {
+		return nil, err
+	}
+	if len(r) == 0 || r[0] == tree.DNull {
+		return nil, nil
+	}
+	tableNames := tree.MustBeDArray(r[0]).Array
+	columnNames := tree.MustBeDArray(r[1]).Array
+	dataTypes := tree.MustBeDArray(r[2]).Array
+	nullables := tree.MustBeDArray(r[3]).Array
+	var tables []sqlsmith.Table
+	for i := range tableNames {
+		typ, err := ctx.Planner.ParseType(string(tree.MustBeDString(dataTypes[i])))
+		if err != nil {
+			// The generator doesn't support the columns of this type anyway.
+			continue
+		}
+		table := string(tree.MustBeDString(tableNames[i]))
+		tables = sqlsmith.AppendColumn(tables, "" /* database */, table, sqlsmith.Column{
+			Name:     string(tree.MustBeDString(columnNames[i])),
+			Type:     coltypes.CastTargetToDatumType(typ),
+			Nullable: nullables[i] == tree.DBoolTrue,
+		})
+	}
+	return tables, nil
 }
 
 var substringImpls = []tree.Builtin{
@@ -2472,6 +2701,14 @@ func bytesBuiltin1(
 	}
 }
 
+func encryptColumn(ctx *tree.EvalContext, plaintext []byte) (tree.Datum, error) {
+	ciphertext, err := ctx.Planner.EncryptColumnValue(ctx.Ctx(), plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return tree.NewDBytes(tree.DBytes(ciphertext)), nil
+}
+
 func feedHash(h hash.Hash, args tree.Datums) {
 	for _, datum := range args {
 		if datum == tree.DNull {
@@ -3053,8 +3290,10 @@ func truncateTimestamp(
 		day, hour, min, sec, nsec = dayTrunc, hourTrunc, minTrunc, secTrunc, nsecTrunc
 
 	case "week", "weeks":
-		// Subtract (day of week * nanoseconds per day) to get date as of previous Sunday.
-		previousSunday := fromTime.Add(time.Duration(-1 * int64(fromTime.Weekday()) * int64(time.Hour) * 24))
+		// Subtract the day of week in days, rather than in multiples of 24
+		// hours which aren't whole days across DST transitions, to get the
+		// date as of previous Sunday.
+		previousSunday := fromTime.AddDate(0, 0, -int(fromTime.Weekday()))
 		year, month, day = previousSunday.Year(), previousSunday.Month(), previousSunday.Day()
 		hour, min, sec, nsec = hourTrunc, minTrunc, secTrunc, nsecTrunc
 
@@ -3083,6 +3322,13 @@ func truncateTimestamp(
 		return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, "unsupported timespan: %s", timeSpan)
 	}
 
-	toTime := time.Date(year, month, day, hour, min, sec, nsec, loc)
+	// Prefer the offset of the input, so that truncating a time during the
+	// hour repeated when DST ends doesn't jump to the other occurrence of that
+	// hour, unless the truncated time is on the other side of a transition.
+	_, offset := fromTime.Zone()
+	toTime := time.Date(year, month, day, hour, min, sec, nsec, time.FixedZone("", offset)).In(loc)
+	if _, toOffset := toTime.Zone(); toOffset != offset {
+		toTime = time.Date(year, month, day, hour, min, sec, nsec, loc)
+	}
 	return tree.MakeDTimestampTZ(toTime, time.Microsecond), nil
 }
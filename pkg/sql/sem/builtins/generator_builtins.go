@@ -18,6 +18,8 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -34,6 +36,7 @@ type generatorFactory func(ctx *tree.EvalContext, args tree.Datums) (tree.ValueG
 
 var _ tree.ValueGenerator = &seriesValueGenerator{}
 var _ tree.ValueGenerator = &arrayValueGenerator{}
+var _ tree.ValueGenerator = &checkConsistencyGenerator{}
 
 func initGeneratorBuiltins() {
 	// Add all windows to the Builtins map after a few sanity checks.
@@ -101,6 +104,27 @@ var Generators = map[string][]tree.Builtin{
 				"This function is used only by CockroachDB's developers for testing purposes.",
 		),
 	},
+	"crdb_internal.check_consistency": {
+		makeGeneratorBuiltin(
+			tree.ArgTypes{},
+			checkConsistencyGeneratorType,
+			makeCheckConsistencyGenerator,
+			"Runs a consistency check on all the ranges and produces a virtual table containing "+
+				"the outcome of the check of each range. "+
+				"A replica whose checksum differs from the one of the lease holder is reported "+
+				"in the logs of the lease holder.",
+		),
+		makeGeneratorBuiltin(
+			tree.ArgTypes{{"start_key", types.Bytes}, {"end_key", types.Bytes}},
+			checkConsistencyGeneratorType,
+			makeCheckConsistencyGenerator,
+			"Runs a consistency check on the ranges overlapping the keys from `start_key` to "+
+				"`end_key` and produces a virtual table containing the outcome of the check of "+
+				"each range. "+
+				"A replica whose checksum differs from the one of the lease holder is reported "+
+				"in the logs of the lease holder.",
+		),
+	},
 	"json_array_elements":       {jsonArrayElementsImpl},
 	"jsonb_array_elements":      {jsonArrayElementsImpl},
 	"json_array_elements_text":  {jsonArrayElementsTextImpl},
@@ -440,3 +464,63 @@ func (g *jsonArrayGenerator) Values() tree.Datums {
 		},
 	}
 }
+
+// checkConsistencyGenerator supports the execution of
+// crdb_internal.check_consistency().
+type checkConsistencyGenerator struct {
+	ctx              *tree.EvalContext
+	startKey, endKey []byte
+	results          []tree.RangeConsistencyCheck
+	curIdx           int
+}
+
+var checkConsistencyGeneratorType = types.TTable{
+	Cols:   types.TTuple{types.Int, types.Bytes, types.String, types.String, types.String},
+	Labels: []string{"range_id", "start_key", "start_key_pretty", "status", "detail"},
+}
+
+func makeCheckConsistencyGenerator(
+	ctx *tree.EvalContext, args tree.Datums,
+) (tree.ValueGenerator, error) {
+	g := &checkConsistencyGenerator{ctx: ctx}
+	if len(args) > 0 {
+		g.startKey = []byte(*args[0].(*tree.DBytes))
+		g.endKey = []byte(*args[1].(*tree.DBytes))
+	}
+	return g, nil
+}
+
+// ResolvedType implements the tree.ValueGenerator interface.
+func (*checkConsistencyGenerator) ResolvedType() types.TTable {
+	return checkConsistencyGeneratorType
+}
+
+// Start implements the tree.ValueGenerator interface. The ranges are all
+// checked before the first row is produced.
+func (c *checkConsistencyGenerator) Start() error {
+	var err error
+	c.results, err = c.ctx.Planner.CheckConsistency(c.ctx.Ctx(), c.startKey, c.endKey)
+	c.curIdx = -1
+	return err
+}
+
+// Next implements the tree.ValueGenerator interface.
+func (c *checkConsistencyGenerator) Next() (bool, error) {
+	c.curIdx++
+	return c.curIdx < len(c.results), nil
+}
+
+// Values implements the tree.ValueGenerator interface.
+func (c *checkConsistencyGenerator) Values() tree.Datums {
+	r := c.results[c.curIdx]
+	return tree.Datums{
+		tree.NewDInt(tree.DInt(r.RangeID)),
+		tree.NewDBytes(tree.DBytes(r.StartKey)),
+		tree.NewDString(keys.PrettyPrint(roachpb.Key(r.StartKey))),
+		tree.NewDString(r.Status),
+		tree.NewDString(r.Detail),
+	}
+}
+
+// Close implements the tree.ValueGenerator interface.
+func (c *checkConsistencyGenerator) Close() {}
@@ -136,9 +136,12 @@ func makePGGetViewDef(argTypes tree.ArgTypes) tree.Builtin {
 		DistsqlBlacklist: true,
 		ReturnType:       tree.FixedReturnType(types.String),
 		Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+			// The views of different databases may have the same name, so
+			// they're also matched by namespace.
 			r, err := ctx.Planner.QueryRow(
 				ctx.Ctx(), "SELECT definition FROM pg_catalog.pg_views v JOIN pg_catalog.pg_class c ON "+
-					"c.relname=v.viewname WHERE oid=$1", args[0])
+					"c.relname=v.viewname JOIN pg_catalog.pg_namespace n ON "+
+					"n.oid=c.relnamespace AND n.nspname=v.schemaname WHERE c.oid=$1", args[0])
 			if err != nil {
 				return nil, err
 			}
@@ -151,19 +154,320 @@ func makePGGetViewDef(argTypes tree.ArgTypes) tree.Builtin {
 	}
 }
 
+// getIndexDef returns the definition of an index, as pg_get_indexdef.
+func getIndexDef(ctx *tree.EvalContext, indexOid tree.Datum) (tree.Datum, error) {
+	r, err := ctx.Planner.QueryRow(
+		ctx.Ctx(), "SELECT indexdef FROM pg_catalog.pg_indexes WHERE crdb_oid=$1", indexOid)
+	if err != nil {
+		return nil, err
+	}
+	if len(r) == 0 {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, "unknown index (OID=%s)", indexOid)
+	}
+	return r[0], nil
+}
+
+// formatTypeWithTypmod returns the SQL name of a type, followed by its type
+// modifier unless typmod is -1, as format_type. Like in PostgreSQL, the
+// modifiers of the character types include the 4 bytes of the header of the
+// values, those of numeric pack the precision and the scale after these 4
+// bytes, and those of the time types are their precision.
+func formatTypeWithTypmod(typ types.T, typmod int) string {
+	if typmod < 0 {
+		return typ.SQLName()
+	}
+	if t, ok := typ.(types.TArray); ok {
+		return formatTypeWithTypmod(t.Typ, typmod) + "[]"
+	}
+	switch typ.Oid() {
+	case oid.T_varchar:
+		if typmod >= 4 {
+			return fmt.Sprintf("%s(%d)", typ.SQLName(), typmod-4)
+		}
+	case oid.T_numeric:
+		if typmod >= 4 {
+			typmod -= 4
+			return fmt.Sprintf("%s(%d,%d)", typ.SQLName(), typmod>>16, typmod&0xffff)
+		}
+	case oid.T_time:
+		return fmt.Sprintf("time(%d) without time zone", typmod)
+	case oid.T_timestamp:
+		return fmt.Sprintf("timestamp(%d) without time zone", typmod)
+	case oid.T_timestamptz:
+		return fmt.Sprintf("timestamp(%d) with time zone", typmod)
+	}
+	return typ.SQLName()
+}
+
+// Make a pg_get_constraintdef function with the given arguments.
+func makePGGetConstraintDef(argTypes tree.ArgTypes) tree.Builtin {
+	return tree.Builtin{
+		Types:            argTypes,
+		DistsqlBlacklist: true,
+		ReturnType:       tree.FixedReturnType(types.String),
+		Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+			r, err := ctx.Planner.QueryRow(
+				ctx.Ctx(), "SELECT condef FROM pg_catalog.pg_constraint WHERE oid=$1", args[0])
+			if err != nil {
+				return nil, err
+			}
+			if len(r) == 0 {
+				return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, "unknown constraint (OID=%s)", args[0])
+			}
+			return r[0], nil
+		},
+		Info: notUsableInfo,
+	}
+}
+
+// makeSignalBackendBuiltin creates a builtin that cancels the queries of
+// another session and, if terminate is set, closes it. Only the root user can
+// signal the sessions of other users.
+func makeSignalBackendBuiltin(terminate bool, info string) tree.Builtin {
+	return tree.Builtin{
+		Types:            tree.ArgTypes{{"pid", types.Int}},
+		ReturnType:       tree.FixedReturnType(types.Bool),
+		Impure:           true,
+		DistsqlBlacklist: true,
+		Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+			sessionID := int64(tree.MustBeDInt(args[0]))
+			ok, err := ctx.Planner.CancelBackend(ctx.Ctx(), sessionID, terminate)
+			if err != nil {
+				return nil, err
+			}
+			return tree.MakeDBool(tree.DBool(ok)), nil
+		},
+		Info: info,
+	}
+}
+
+// makeHasPrivilegeBuiltins creates the overloads of a has_*_privilege builtin
+// inspecting the privileges on the given type of object. Each list of
+// objArgs identifies the object, by name or by OID, and the overloads without
+// a user check the privileges of the current user. The user is given by name
+// or by OID, in which case NULL is returned if no user has that OID, like
+// for the objects given by OID.
+func makeHasPrivilegeBuiltins(
+	objType tree.PrivilegeObjectType, objArgs []tree.ArgTypes, info string,
+) []tree.Builtin {
+	var res []tree.Builtin
+	for _, userArg := range []tree.ArgTypes{
+		{},
+		{{"user", types.String}},
+		{{"user", types.Oid}},
+	} {
+		hasUser := len(userArg) > 0
+		for _, objArg := range objArgs {
+			argTypes := append(append(tree.ArgTypes{}, userArg...), objArg...)
+			argTypes = append(argTypes, tree.ArgTypes{{"privilege", types.String}}...)
+			res = append(res, tree.Builtin{
+				Types:            argTypes,
+				ReturnType:       tree.FixedReturnType(types.Bool),
+				DistsqlBlacklist: true,
+				Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+					user := ctx.User
+					if hasUser {
+						var ok bool
+						var err error
+						user, ok, err = resolvePrivilegeUser(ctx, args[0])
+						if err != nil {
+							return nil, err
+						}
+						if !ok {
+							return tree.DNull, nil
+						}
+						args = args[1:]
+					}
+					object, ok, err := resolvePrivilegeObject(ctx, objType, args[:len(args)-1])
+					if err != nil {
+						return nil, err
+					}
+					if !ok {
+						return tree.DNull, nil
+					}
+					privs := string(tree.MustBeDString(args[len(args)-1]))
+					has, err := ctx.Planner.HasPrivilege(ctx.Ctx(), user, object, privs)
+					if err != nil {
+						return nil, err
+					}
+					return tree.MakeDBool(tree.DBool(has)), nil
+				},
+				Info: info,
+			})
+		}
+	}
+	return res
+}
+
+// resolvePrivilegeUser returns the name of the user given to a
+// has_*_privilege or pg_has_role builtin by name or by OID. ok is false if no
+// user has the given OID.
+func resolvePrivilegeUser(ctx *tree.EvalContext, arg tree.Datum) (string, bool, error) {
+	if s, ok := arg.(*tree.DString); ok {
+		return string(*s), true, nil
+	}
+	r, err := ctx.Planner.QueryRow(
+		ctx.Ctx(), "SELECT rolname FROM pg_catalog.pg_roles WHERE oid=$1", arg)
+	if err != nil || len(r) == 0 {
+		return "", false, err
+	}
+	return string(tree.MustBeDString(r[0])), true, nil
+}
+
+// resolvePrivilegeObject returns the object identified by the arguments of a
+// has_*_privilege builtin: the name or OID of a database, schema, table or
+// sequence, and for columns the name or number of the column after the
+// table. ok is false if no object has the given OID or number.
+func resolvePrivilegeObject(
+	ctx *tree.EvalContext, objType tree.PrivilegeObjectType, args tree.Datums,
+) (tree.PrivilegeObject, bool, error) {
+	object := tree.PrivilegeObject{Type: objType}
+	switch objType {
+	case tree.PrivilegeObjectDatabase, tree.PrivilegeObjectSchema:
+		if s, ok := args[0].(*tree.DString); ok {
+			object.Database = string(*s)
+			return object, true, nil
+		}
+		query := "SELECT datname FROM pg_catalog.pg_database WHERE oid=$1"
+		if objType == tree.PrivilegeObjectSchema {
+			query = "SELECT nspname FROM pg_catalog.pg_namespace WHERE oid=$1"
+		}
+		r, err := ctx.Planner.QueryRow(ctx.Ctx(), query, args[0])
+		if err != nil || len(r) == 0 {
+			return object, false, err
+		}
+		object.Database = string(tree.MustBeDString(r[0]))
+		return object, true, nil
+	}
+
+	if s, ok := args[0].(*tree.DString); ok {
+		parsed, err := ctx.Planner.ParseTableNameWithIndex(string(*s))
+		if err != nil {
+			return object, false, err
+		}
+		object.Table, err = ctx.Planner.QualifyWithDatabase(ctx.Ctx(), &parsed.Table)
+		if err != nil {
+			return object, false, err
+		}
+	} else {
+		r, err := ctx.Planner.QueryRow(ctx.Ctx(),
+			"SELECT n.nspname, c.relname FROM pg_catalog.pg_class c "+
+				"JOIN pg_catalog.pg_namespace n ON c.relnamespace=n.oid WHERE c.oid=$1", args[0])
+		if err != nil || len(r) == 0 {
+			return object, false, err
+		}
+		object.Table = &tree.TableName{
+			DatabaseName: tree.Name(tree.MustBeDString(r[0])),
+			TableName:    tree.Name(tree.MustBeDString(r[1])),
+		}
+	}
+	if objType != tree.PrivilegeObjectColumn {
+		return object, true, nil
+	}
+
+	if s, ok := args[1].(*tree.DString); ok {
+		object.Column = string(*s)
+		return object, true, nil
+	}
+	// The column is given by its number, as in pg_attribute.attnum.
+	r, err := ctx.Planner.QueryRow(ctx.Ctx(),
+		"SELECT a.attname FROM pg_catalog.pg_attribute a "+
+			"JOIN pg_catalog.pg_class c ON a.attrelid=c.oid "+
+			"JOIN pg_catalog.pg_namespace n ON c.relnamespace=n.oid "+
+			"WHERE n.nspname=$1 AND c.relname=$2 AND c.relkind!='i' AND a.attnum=$3",
+		object.Table.Database(), object.Table.Table(), args[1])
+	if err != nil || len(r) == 0 {
+		return object, false, err
+	}
+	object.Column = string(tree.MustBeDString(r[0]))
+	return object, true, nil
+}
+
+// makePGHasRoleBuiltins creates the overloads of pg_has_role, where the user
+// and the role can be given by name or by OID.
+func makePGHasRoleBuiltins() []tree.Builtin {
+	const info = "Returns whether the user, by default the current user, is a member of the role " +
+		"(`MEMBER`) or holds its privileges (`USAGE`), optionally `WITH ADMIN OPTION`. " +
+		"`privilege` can be a comma-separated list, in which case the result is true if " +
+		"any of the privileges is held."
+	var res []tree.Builtin
+	for _, userArg := range []tree.ArgTypes{
+		{},
+		{{"user", types.String}},
+		{{"user", types.Oid}},
+	} {
+		hasUser := len(userArg) > 0
+		for _, roleArg := range []tree.ArgTypes{
+			{{"role", types.String}},
+			{{"role", types.Oid}},
+		} {
+			argTypes := append(append(tree.ArgTypes{}, userArg...), roleArg...)
+			argTypes = append(argTypes, tree.ArgTypes{{"privilege", types.String}}...)
+			res = append(res, tree.Builtin{
+				Types:            argTypes,
+				ReturnType:       tree.FixedReturnType(types.Bool),
+				DistsqlBlacklist: true,
+				Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+					user := ctx.User
+					if hasUser {
+						var ok bool
+						var err error
+						user, ok, err = resolvePrivilegeUser(ctx, args[0])
+						if err != nil {
+							return nil, err
+						}
+						if !ok {
+							return tree.DNull, nil
+						}
+						args = args[1:]
+					}
+					role, ok, err := resolvePrivilegeUser(ctx, args[0])
+					if err != nil {
+						return nil, err
+					}
+					if !ok {
+						return tree.DNull, nil
+					}
+					has, err := ctx.Planner.HasRole(
+						ctx.Ctx(), user, role, string(tree.MustBeDString(args[1])))
+					if err != nil {
+						return nil, err
+					}
+					return tree.MakeDBool(tree.DBool(has)), nil
+				},
+				Info: info,
+			})
+		}
+	}
+	return res
+}
+
 var pgBuiltins = map[string][]tree.Builtin{
 	// See https://www.postgresql.org/docs/9.6/static/functions-info.html.
 	"pg_backend_pid": {
 		tree.Builtin{
-			Types:      tree.ArgTypes{},
-			ReturnType: tree.FixedReturnType(types.Int),
-			Fn: func(_ *tree.EvalContext, _ tree.Datums) (tree.Datum, error) {
-				return tree.NewDInt(-1), nil
+			Types:            tree.ArgTypes{},
+			ReturnType:       tree.FixedReturnType(types.Int),
+			DistsqlBlacklist: true,
+			Fn: func(ctx *tree.EvalContext, _ tree.Datums) (tree.Datum, error) {
+				return tree.NewDInt(tree.DInt(ctx.Planner.SessionID())), nil
 			},
-			Info: notUsableInfo,
+			Info: "Returns the ID of the current session, which is unique across the cluster.",
 		},
 	},
 
+	// See https://www.postgresql.org/docs/9.6/static/functions-admin.html.
+	"pg_cancel_backend": {
+		makeSignalBackendBuiltin(false, /* terminate */
+			"Cancels the queries running in the session with the given ID, which may be "+
+				"connected to any node. Returns false if no such session exists."),
+	},
+	"pg_terminate_backend": {
+		makeSignalBackendBuiltin(true, /* terminate */
+			"Closes the session with the given ID, which may be connected to any node. "+
+				"Returns false if no such session exists."),
+	},
+
 	// See https://www.postgresql.org/docs/9.3/static/catalog-pg-database.html.
 	"pg_encoding_to_char": {
 		tree.Builtin{
@@ -214,7 +518,9 @@ var pgBuiltins = map[string][]tree.Builtin{
 	},
 
 	// pg_get_indexdef functions like SHOW CREATE INDEX would if we supported that
-	// statement.
+	// statement. When column_no isn't 0, only the name of the column at that
+	// position in the index is returned, or an empty string if there is no
+	// such column, like PostgreSQL does. The pretty_bool argument is ignored.
 	"pg_get_indexdef": {
 		tree.Builtin{
 			Types: tree.ArgTypes{
@@ -223,37 +529,67 @@ var pgBuiltins = map[string][]tree.Builtin{
 			DistsqlBlacklist: true,
 			ReturnType:       tree.FixedReturnType(types.String),
 			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return getIndexDef(ctx, args[0])
+			},
+			Info: notUsableInfo,
+		},
+		tree.Builtin{
+			Types: tree.ArgTypes{
+				{"index_oid", types.Oid},
+				{"column_no", types.Int},
+				{"pretty_bool", types.Bool},
+			},
+			DistsqlBlacklist: true,
+			ReturnType:       tree.FixedReturnType(types.String),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				def, err := getIndexDef(ctx, args[0])
+				if err != nil || tree.MustBeDInt(args[1]) == 0 {
+					return def, err
+				}
 				r, err := ctx.Planner.QueryRow(
-					ctx.Ctx(), "SELECT indexdef FROM pg_catalog.pg_indexes WHERE crdb_oid=$1", args[0])
+					ctx.Ctx(), "SELECT attname FROM pg_catalog.pg_attribute WHERE attrelid=$1 AND attnum=$2",
+					args[0], args[1])
 				if err != nil {
 					return nil, err
 				}
 				if len(r) == 0 {
-					return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, "unknown index (OID=%s)", args[0])
+					return tree.NewDString(""), nil
 				}
-				return r[0], nil
+				return tree.NewDString(tree.AsString(tree.Name(tree.MustBeDString(r[0])))), nil
 			},
 			Info: notUsableInfo,
 		},
-		// The other overload for this function, pg_get_indexdef(index_oid,
-		// column_no, pretty_bool), is unimplemented, because it isn't used by
-		// supported ORMs.
+	},
+
+	// pg_get_constraintdef returns the definition of a constraint as it would
+	// appear in a CREATE TABLE statement, which ORMs parse to reflect foreign
+	// keys. The pretty_bool argument is ignored.
+	"pg_get_constraintdef": {
+		makePGGetConstraintDef(tree.ArgTypes{{"constraint_oid", types.Oid}}),
+		makePGGetConstraintDef(tree.ArgTypes{{"constraint_oid", types.Oid}, {"pretty_bool", types.Bool}}),
 	},
 
 	// pg_get_viewdef functions like SHOW CREATE VIEW but returns the same format as
 	// PostgreSQL leaving out the actual 'CREATE VIEW table_name AS' portion of the statement.
+	// The pretty_bool and wrap_column arguments are ignored.
 	"pg_get_viewdef": {
 		makePGGetViewDef(tree.ArgTypes{{"view_oid", types.Oid}}),
 		makePGGetViewDef(tree.ArgTypes{{"view_oid", types.Oid}, {"pretty_bool", types.Bool}}),
+		makePGGetViewDef(tree.ArgTypes{{"view_oid", types.Oid}, {"wrap_column", types.Int}}),
 	},
 
 	"pg_typeof": {
 		// TODO(knz): This is a proof-of-concept until types.Any works
 		// properly.
 		tree.Builtin{
-			Types:      tree.ArgTypes{{"val", types.Any}},
-			ReturnType: tree.FixedReturnType(types.String),
+			Types:        tree.ArgTypes{{"val", types.Any}},
+			ReturnType:   tree.FixedReturnType(types.String),
+			NullableArgs: true,
 			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				// Like in PostgreSQL, NULL literals are of the unknown type.
+				if args[0] == tree.DNull {
+					return tree.NewDString(types.Null.SQLName()), nil
+				}
 				return tree.NewDString(args[0].ResolvedType().String()), nil
 			},
 			Info: notUsableInfo,
@@ -295,11 +631,14 @@ var pgBuiltins = map[string][]tree.Builtin{
 				if !ok {
 					return tree.NewDString(fmt.Sprintf("unknown (OID=%s)", oidArg)), nil
 				}
-				return tree.NewDString(typ.SQLName()), nil
+				typmod := -1
+				if args[1] != tree.DNull {
+					typmod = int(tree.MustBeDInt(args[1]))
+				}
+				return tree.NewDString(formatTypeWithTypmod(typ, typmod)), nil
 			},
 			Info: "Returns the SQL name of a data type that is " +
-				"identified by its type OID and possibly a type modifier. " +
-				"Currently, the type modifier is ignored.",
+				"identified by its type OID and possibly a type modifier.",
 		},
 	},
 	"col_description": {
@@ -390,4 +729,52 @@ var pgBuiltins = map[string][]tree.Builtin{
 			Info: notUsableInfo,
 		},
 	},
+
+	// The has_*_privilege builtins return whether a user holds any of a
+	// comma-separated list of privileges on an object.
+	// https://www.postgresql.org/docs/9.6/static/functions-info.html
+	"has_database_privilege": makeHasPrivilegeBuiltins(
+		tree.PrivilegeObjectDatabase,
+		[]tree.ArgTypes{{{"database", types.String}}, {{"database", types.Oid}}},
+		"Returns whether the user, by default the current user, holds any of the "+
+			"comma-separated privileges on the database, among `CREATE`, `CONNECT`, "+
+			"`TEMPORARY` and the privileges that can be granted on databases, optionally "+
+			"`WITH GRANT OPTION`.",
+	),
+	"has_schema_privilege": makeHasPrivilegeBuiltins(
+		tree.PrivilegeObjectSchema,
+		[]tree.ArgTypes{{{"schema", types.String}}, {{"schema", types.Oid}}},
+		"Returns whether the user, by default the current user, holds any of the "+
+			"comma-separated privileges on the schema, among `CREATE` and `USAGE`, "+
+			"optionally `WITH GRANT OPTION`.",
+	),
+	"has_table_privilege": makeHasPrivilegeBuiltins(
+		tree.PrivilegeObjectTable,
+		[]tree.ArgTypes{{{"table", types.String}}, {{"table", types.Oid}}},
+		"Returns whether the user, by default the current user, holds any of the "+
+			"comma-separated privileges on the table, among `SELECT`, `INSERT`, `UPDATE`, "+
+			"`DELETE`, `TRUNCATE`, `REFERENCES`, `TRIGGER` and the privileges that can be "+
+			"granted on tables, optionally `WITH GRANT OPTION`.",
+	),
+	"has_sequence_privilege": makeHasPrivilegeBuiltins(
+		tree.PrivilegeObjectSequence,
+		[]tree.ArgTypes{{{"sequence", types.String}}, {{"sequence", types.Oid}}},
+		"Returns whether the user, by default the current user, holds any of the "+
+			"comma-separated privileges on the sequence, among `USAGE`, `SELECT` and "+
+			"`UPDATE`, optionally `WITH GRANT OPTION`.",
+	),
+	"has_column_privilege": makeHasPrivilegeBuiltins(
+		tree.PrivilegeObjectColumn,
+		[]tree.ArgTypes{
+			{{"table", types.String}, {"column", types.String}},
+			{{"table", types.String}, {"column_number", types.Int}},
+			{{"table", types.Oid}, {"column", types.String}},
+			{{"table", types.Oid}, {"column_number", types.Int}},
+		},
+		"Returns whether the user, by default the current user, holds any of the "+
+			"comma-separated privileges on the column or on its table, among `SELECT`, "+
+			"`INSERT`, `UPDATE` and `REFERENCES`, optionally `WITH GRANT OPTION`. "+
+			"Returns NULL if the table has no column with the given number.",
+	),
+	"pg_has_role": makePGHasRoleBuiltins(),
 }
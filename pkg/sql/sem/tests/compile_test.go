@@ -0,0 +1,146 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+)
+
+type testVarContainer tree.Datums
+
+func (c testVarContainer) IndexedVarEval(idx int, ctx *tree.EvalContext) (tree.Datum, error) {
+	return c[idx].Eval(ctx)
+}
+
+func (c testVarContainer) IndexedVarResolvedType(idx int) types.T {
+	return c[idx].ResolvedType()
+}
+
+func (c testVarContainer) IndexedVarNodeFormatter(idx int) tree.NodeFormatter {
+	return tree.Name(fmt.Sprintf("var%d", idx))
+}
+
+// TestCompile verifies that compiled expressions evaluate to the same results
+// as the expressions they were compiled from.
+func TestCompile(t *testing.T) {
+	vars := testVarContainer{
+		tree.NewDInt(1),
+		tree.NewDInt(2),
+		tree.DNull,
+		tree.NewDString("foo"),
+		tree.DBoolTrue,
+		tree.NewDFloat(1.5),
+	}
+	testData := []string{
+		`@1 = 1`,
+		`@1 != 1`,
+		`@1 < @2`,
+		`@1 <= @2`,
+		`@1 > @2`,
+		`@1 >= @2`,
+		`@1 = @3::INT`,
+		`@3::INT < 1`,
+		`@2 > 1 AND @1 <= 1`,
+		`@2 > 1 AND @1 > 1`,
+		`@3::BOOL AND false`,
+		`@3::BOOL AND true`,
+		`@3::BOOL OR true`,
+		`@3::BOOL OR false`,
+		`@1 = 2 OR @2 = 2`,
+		`NOT (@1 = 1)`,
+		`NOT @3::BOOL`,
+		`(@1 + @2) * 3`,
+		`@1 - @3::INT`,
+		`@6 < 2.0`,
+		`@6 + 1.0 = 2.5`,
+		`@4 LIKE 'f%'`,
+		`@4 NOT LIKE 'f%'`,
+		`@4 > 'bar'`,
+		`@1 IN (1, 2)`,
+		`@1 NOT IN (2, 3)`,
+		`@1 = ANY ARRAY[1, 2]`,
+		`@5 AND @1 = 1`,
+		`@3 IS NULL`,
+		`@1 IS DISTINCT FROM @3::INT`,
+		`@1 IS NOT DISTINCT FROM 1`,
+		`CASE WHEN @1 = 1 THEN @4 ELSE 'bar' END`,
+	}
+	ctx := tree.NewTestingEvalContext()
+	defer ctx.Stop(context.Background())
+	h := tree.MakeIndexedVarHelper(vars, len(vars))
+	ctx.IVarHelper = &h
+	for _, s := range testData {
+		expr, err := parser.ParseExpr(s)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		expr, _ = tree.WalkExpr(&h, expr)
+		typedExpr, err := tree.TypeCheck(expr, &tree.SemaContext{IVarHelper: &h}, types.Any)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		expected, err := typedExpr.Eval(ctx)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		result, err := tree.Compile(typedExpr)(ctx)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if result.Compare(ctx, expected) != 0 {
+			t.Errorf("%s: expected %s, but found %s", s, expected, result)
+		}
+	}
+}
+
+func BenchmarkCompiledFilter(b *testing.B) {
+	vars := testVarContainer{tree.NewDInt(1), tree.NewDInt(2)}
+	ctx := tree.NewTestingEvalContext()
+	defer ctx.Stop(context.Background())
+	h := tree.MakeIndexedVarHelper(vars, len(vars))
+	ctx.IVarHelper = &h
+	expr, err := parser.ParseExpr(`@1 > 0 AND @2 < 10 AND @1 + @2 != 4`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	expr, _ = tree.WalkExpr(&h, expr)
+	typedExpr, err := tree.TypeCheck(expr, &tree.SemaContext{IVarHelper: &h}, types.Any)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("eval", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := typedExpr.Eval(ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("compiled", func(b *testing.B) {
+		compiled := tree.Compile(typedExpr)
+		for i := 0; i < b.N; i++ {
+			if _, err := compiled(ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
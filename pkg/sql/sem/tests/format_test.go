@@ -210,3 +210,40 @@ func TestFormatExpr(t *testing.T) {
 		})
 	}
 }
+
+func TestStatementFingerprint(t *testing.T) {
+	testData := []struct {
+		stmts    []string
+		expected string
+	}{
+		{[]string{
+			`SELECT x FROM t WHERE y IN (1, 2, 3)`,
+			`select   X from T where Y in (4,5)`,
+			`SELECT x FROM t WHERE y IN ('a', 'b', 'c', 'd')`,
+		}, `SELECT x FROM t WHERE y IN (_, _)`},
+		{[]string{
+			`INSERT INTO t VALUES (1, 'a'), (2, 'b')`,
+			`INSERT INTO t VALUES (3, 'c')`,
+		}, `INSERT INTO t VALUES (_, _)`},
+		{[]string{
+			`SELECT x FROM t WHERE y = $1`,
+		}, `SELECT x FROM t WHERE y = $1`},
+	}
+
+	for _, test := range testData {
+		for _, sql := range test.stmts {
+			stmt, err := parser.ParseOne(sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fingerprint := tree.StatementFingerprint(stmt); fingerprint != test.expected {
+				t.Errorf("%s: expected %q, got %q", sql, test.expected, fingerprint)
+			}
+		}
+	}
+
+	// The IDs of the fingerprints must remain stable.
+	if id := tree.StatementFingerprintID(`SELECT x FROM t WHERE y IN (_, _)`); id != "83f7793f56b5fecf" {
+		t.Errorf("unexpected fingerprint ID %s", id)
+	}
+}
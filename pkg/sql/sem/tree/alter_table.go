@@ -58,20 +58,34 @@ type AlterTableCmd interface {
 	alterTableCmd()
 }
 
-func (*AlterTableAddColumn) alterTableCmd()          {}
-func (*AlterTableAddConstraint) alterTableCmd()      {}
-func (*AlterTableDropColumn) alterTableCmd()         {}
-func (*AlterTableDropConstraint) alterTableCmd()     {}
-func (*AlterTableDropNotNull) alterTableCmd()        {}
-func (*AlterTableSetDefault) alterTableCmd()         {}
-func (*AlterTableValidateConstraint) alterTableCmd() {}
+func (*AlterTableAddColumn) alterTableCmd()           {}
+func (*AlterTableAddConstraint) alterTableCmd()       {}
+func (*AlterTableAlterPrimaryKey) alterTableCmd()     {}
+func (*AlterTableDropColumn) alterTableCmd()          {}
+func (*AlterTableDropConstraint) alterTableCmd()      {}
+func (*AlterTableDropNotNull) alterTableCmd()         {}
+func (*AlterTableInjectStats) alterTableCmd()         {}
+func (*AlterTableResetTTL) alterTableCmd()            {}
+func (*AlterTableSetAudit) alterTableCmd()            {}
+func (*AlterTableSetDefault) alterTableCmd()          {}
+func (*AlterTableSetLocality) alterTableCmd()         {}
+func (*AlterTableSetRowLevelSecurity) alterTableCmd() {}
+func (*AlterTableSetTTL) alterTableCmd()              {}
+func (*AlterTableValidateConstraint) alterTableCmd()  {}
 
 var _ AlterTableCmd = &AlterTableAddColumn{}
 var _ AlterTableCmd = &AlterTableAddConstraint{}
+var _ AlterTableCmd = &AlterTableAlterPrimaryKey{}
 var _ AlterTableCmd = &AlterTableDropColumn{}
 var _ AlterTableCmd = &AlterTableDropConstraint{}
 var _ AlterTableCmd = &AlterTableDropNotNull{}
+var _ AlterTableCmd = &AlterTableInjectStats{}
+var _ AlterTableCmd = &AlterTableResetTTL{}
+var _ AlterTableCmd = &AlterTableSetAudit{}
 var _ AlterTableCmd = &AlterTableSetDefault{}
+var _ AlterTableCmd = &AlterTableSetLocality{}
+var _ AlterTableCmd = &AlterTableSetRowLevelSecurity{}
+var _ AlterTableCmd = &AlterTableSetTTL{}
 var _ AlterTableCmd = &AlterTableValidateConstraint{}
 
 // ColumnMutationCmd is the subset of AlterTableCmds that modify an
@@ -125,6 +139,18 @@ func (node *AlterTableAddConstraint) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// AlterTableAlterPrimaryKey represents an ALTER PRIMARY KEY command.
+type AlterTableAlterPrimaryKey struct {
+	Columns IndexElemList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableAlterPrimaryKey) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER PRIMARY KEY USING COLUMNS (")
+	FormatNode(buf, f, node.Columns)
+	buf.WriteByte(')')
+}
+
 // AlterTableDropColumn represents a DROP COLUMN command.
 type AlterTableDropColumn struct {
 	ColumnKeyword bool
@@ -178,6 +204,100 @@ func (node *AlterTableValidateConstraint) Format(buf *bytes.Buffer, f FmtFlags)
 	FormatNode(buf, f, node.Constraint)
 }
 
+// AlterTableSetRowLevelSecurity represents an ENABLE ROW LEVEL SECURITY or
+// DISABLE ROW LEVEL SECURITY command.
+type AlterTableSetRowLevelSecurity struct {
+	Enable bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableSetRowLevelSecurity) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node.Enable {
+		buf.WriteString("ENABLE ROW LEVEL SECURITY")
+	} else {
+		buf.WriteString("DISABLE ROW LEVEL SECURITY")
+	}
+}
+
+// AuditMode represents the accesses to a table that are audited.
+type AuditMode int
+
+const (
+	// AuditModeDisable disables the auditing of the table.
+	AuditModeDisable AuditMode = iota
+	// AuditModeReadWrite audits all the reads and writes of the table.
+	AuditModeReadWrite
+)
+
+var auditModeName = [...]string{
+	AuditModeDisable:   "OFF",
+	AuditModeReadWrite: "READ WRITE",
+}
+
+func (m AuditMode) String() string {
+	return auditModeName[m]
+}
+
+// AlterTableSetAudit represents an EXPERIMENTAL_AUDIT SET command.
+type AlterTableSetAudit struct {
+	Mode AuditMode
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableSetAudit) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("EXPERIMENTAL_AUDIT SET ")
+	buf.WriteString(node.Mode.String())
+}
+
+// AlterTableSetLocality represents a SET LOCALITY command.
+type AlterTableSetLocality struct {
+	Locality Locality
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableSetLocality) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SET ")
+	FormatNode(buf, f, node.Locality)
+}
+
+// AlterTableInjectStats represents an INJECT STATISTICS command.
+type AlterTableInjectStats struct {
+	Stats Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableInjectStats) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("INJECT STATISTICS ")
+	FormatNode(buf, f, node.Stats)
+}
+
+// AlterTableSetTTL represents a SET TTL command. Exactly one of
+// ExpirationColumn and ExpireAfter is set.
+type AlterTableSetTTL struct {
+	ExpirationColumn Name
+	ExpireAfter      Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableSetTTL) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SET TTL EXPIRE ")
+	if node.ExpireAfter != nil {
+		buf.WriteString("AFTER ")
+		FormatNode(buf, f, node.ExpireAfter)
+	} else {
+		buf.WriteString("AT ")
+		FormatNode(buf, f, node.ExpirationColumn)
+	}
+}
+
+// AlterTableResetTTL represents a RESET TTL command.
+type AlterTableResetTTL struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableResetTTL) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("RESET TTL")
+}
+
 // AlterTableSetDefault represents an ALTER COLUMN SET DEFAULT
 // or DROP DEFAULT command.
 type AlterTableSetDefault struct {
@@ -19,7 +19,7 @@ import "bytes"
 // Backup represents a BACKUP statement.
 type Backup struct {
 	Targets         TargetList
-	To              Expr
+	To              PartitionedBackup
 	IncrementalFrom Exprs
 	AsOf            AsOfClause
 	Options         KVOptions
@@ -50,7 +50,7 @@ func (node *Backup) Format(buf *bytes.Buffer, f FmtFlags) {
 // Restore represents a RESTORE statement.
 type Restore struct {
 	Targets TargetList
-	From    Exprs
+	From    PartitionedBackups
 	AsOf    AsOfClause
 	Options KVOptions
 }
@@ -73,6 +73,37 @@ func (node *Restore) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// PartitionedBackup is the list of the URIs a backup is written to. A single
+// URI is a regular backup. Several URIs are a locality-aware backup, whose
+// URIs each specify the locality tier of the nodes writing to them with the
+// COCKROACH_LOCALITY parameter.
+type PartitionedBackup []Expr
+
+// Format implements the NodeFormatter interface.
+func (node PartitionedBackup) Format(buf *bytes.Buffer, f FmtFlags) {
+	if len(node) > 1 {
+		buf.WriteString("(")
+	}
+	FormatNode(buf, f, Exprs(node))
+	if len(node) > 1 {
+		buf.WriteString(")")
+	}
+}
+
+// PartitionedBackups is a list of PartitionedBackup, one per backup
+// restored from.
+type PartitionedBackups []PartitionedBackup
+
+// Format implements the NodeFormatter interface.
+func (node PartitionedBackups) Format(buf *bytes.Buffer, f FmtFlags) {
+	for i, n := range node {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, n)
+	}
+}
+
 // KVOption is a key-value option.
 type KVOption struct {
 	Key   Name
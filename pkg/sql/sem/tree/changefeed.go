@@ -0,0 +1,46 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import "bytes"
+
+// CreateChangefeed represents a CREATE CHANGEFEED statement, or an
+// EXPERIMENTAL CHANGEFEED statement if it has no sink.
+type CreateChangefeed struct {
+	Targets TargetList
+	// SinkURI is nil for a sinkless changefeed, which returns the changes to
+	// the client.
+	SinkURI Expr
+	Options KVOptions
+}
+
+var _ Statement = &CreateChangefeed{}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateChangefeed) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node.SinkURI == nil {
+		buf.WriteString("EXPERIMENTAL CHANGEFEED FOR ")
+		FormatNode(buf, f, node.Targets)
+	} else {
+		buf.WriteString("CREATE CHANGEFEED FOR ")
+		FormatNode(buf, f, node.Targets)
+		buf.WriteString(" INTO ")
+		FormatNode(buf, f, node.SinkURI)
+	}
+	if node.Options != nil {
+		buf.WriteString(" WITH ")
+		FormatNode(buf, f, node.Options)
+	}
+}
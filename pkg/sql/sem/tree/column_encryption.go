@@ -0,0 +1,33 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import "bytes"
+
+// RotateEncryptionKey represents an ALTER TABLE ... ROTATE ENCRYPTION KEY
+// statement.
+type RotateEncryptionKey struct {
+	Table   NormalizableTableName
+	Columns NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RotateEncryptionKey) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER TABLE ")
+	FormatNode(buf, f, &node.Table)
+	buf.WriteString(" ROTATE ENCRYPTION KEY (")
+	FormatNode(buf, f, node.Columns)
+	buf.WriteByte(')')
+}
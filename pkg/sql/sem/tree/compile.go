@@ -0,0 +1,278 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import "github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+
+// CompiledExpr is a typed expression that has been turned into a chain of
+// closures by Compile. Evaluating a CompiledExpr is equivalent to calling
+// Eval on the expression it was compiled from, but avoids re-discovering the
+// shape of the expression tree (interface conversions, operator folding,
+// operand type dispatch) for every row.
+type CompiledExpr func(ctx *EvalContext) (Datum, error)
+
+// Compile compiles a typed expression into a CompiledExpr. Expressions
+// that the compiler does not handle specially, including the operands of
+// those expressions, are evaluated using their Eval method, so any typed
+// expression can be compiled.
+//
+// The expression must not be modified after it has been compiled.
+func Compile(expr TypedExpr) CompiledExpr {
+	switch t := expr.(type) {
+	case *ParenExpr:
+		return Compile(t.TypedInnerExpr())
+
+	case dNull:
+		return func(*EvalContext) (Datum, error) { return DNull, nil }
+
+	case *DBool, *DInt, *DFloat, *DDecimal, *DString, *DBytes:
+		d := t.(Datum)
+		return func(*EvalContext) (Datum, error) { return d, nil }
+
+	case *IndexedVar:
+		idx := t.Idx
+		return func(ctx *EvalContext) (Datum, error) {
+			if ctx.IVarHelper.container == nil || ctx.IVarHelper.container == unboundContainer {
+				panic("indexed var must be bound to a container before evaluation")
+			}
+			return ctx.IVarHelper.container.IndexedVarEval(idx, ctx)
+		}
+
+	case *AndExpr:
+		return compileAnd(Compile(t.TypedLeft()), Compile(t.TypedRight()))
+
+	case *OrExpr:
+		return compileOr(Compile(t.TypedLeft()), Compile(t.TypedRight()))
+
+	case *NotExpr:
+		return compileNot(Compile(t.TypedInnerExpr()))
+
+	case *BinaryExpr:
+		return compileBinary(t)
+
+	case *ComparisonExpr:
+		if c := compileComparison(t); c != nil {
+			return c
+		}
+	}
+	return expr.Eval
+}
+
+// CompileFilter compiles a filter expression. A nil filter is compiled
+// into a nil CompiledExpr, which RunCompiledFilter treats as a filter that
+// passes every row.
+func CompileFilter(filter TypedExpr) CompiledExpr {
+	if filter == nil {
+		return nil
+	}
+	return Compile(filter)
+}
+
+// RunCompiledFilter evaluates a compiled filter and returns whether it
+// passes, i.e. evaluates to true.
+func RunCompiledFilter(filter CompiledExpr, ctx *EvalContext) (bool, error) {
+	if filter == nil {
+		return true, nil
+	}
+	d, err := filter(ctx)
+	if err != nil {
+		return false, err
+	}
+	return d == DBoolTrue, nil
+}
+
+func compileAnd(left, right CompiledExpr) CompiledExpr {
+	return func(ctx *EvalContext) (Datum, error) {
+		l, err := left(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if l != DNull {
+			if v, err := GetBool(l); err != nil {
+				return nil, err
+			} else if !v {
+				return l, nil
+			}
+		}
+		r, err := right(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if r == DNull {
+			return DNull, nil
+		}
+		if v, err := GetBool(r); err != nil {
+			return nil, err
+		} else if !v {
+			return r, nil
+		}
+		return l, nil
+	}
+}
+
+func compileOr(left, right CompiledExpr) CompiledExpr {
+	return func(ctx *EvalContext) (Datum, error) {
+		l, err := left(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if l != DNull {
+			if v, err := GetBool(l); err != nil {
+				return nil, err
+			} else if v {
+				return l, nil
+			}
+		}
+		r, err := right(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if r == DNull {
+			return DNull, nil
+		}
+		if v, err := GetBool(r); err != nil {
+			return nil, err
+		} else if v {
+			return r, nil
+		}
+		if l == DNull {
+			return DNull, nil
+		}
+		return DBoolFalse, nil
+	}
+}
+
+func compileNot(inner CompiledExpr) CompiledExpr {
+	return func(ctx *EvalContext) (Datum, error) {
+		d, err := inner(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if d == DNull {
+			return DNull, nil
+		}
+		v, err := GetBool(d)
+		if err != nil {
+			return nil, err
+		}
+		return MakeDBool(!v), nil
+	}
+}
+
+func compileBinary(expr *BinaryExpr) CompiledExpr {
+	left, right := Compile(expr.TypedLeft()), Compile(expr.TypedRight())
+	fn, nullableArgs := expr.fn.fn, expr.fn.nullableArgs
+	return func(ctx *EvalContext) (Datum, error) {
+		l, err := left(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if l == DNull && !nullableArgs {
+			return DNull, nil
+		}
+		r, err := right(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if r == DNull && !nullableArgs {
+			return DNull, nil
+		}
+		return fn(ctx, l, r)
+	}
+}
+
+// compileComparison compiles comparisons with a plain (non-ANY/ALL) operator
+// that does not treat NULLs specially. It returns nil for all other
+// comparisons, which are left to ComparisonExpr.Eval.
+func compileComparison(expr *ComparisonExpr) CompiledExpr {
+	op := expr.Operator
+	if op.hasSubOperator() {
+		return nil
+	}
+	switch op {
+	case IsDistinctFrom, IsNotDistinctFrom, Is, IsNot:
+		return nil
+	}
+
+	// The folding of the operator only depends on the operator itself, so
+	// it can be done once here instead of once per row.
+	newOp, newLeft, newRight, _, not := foldComparisonExpr(op, expr.TypedLeft(), expr.TypedRight())
+	left, right := Compile(newLeft.(TypedExpr)), Compile(newRight.(TypedExpr))
+	fn := expr.fn.fn
+
+	// Comparisons between integers are by far the most common in filters;
+	// they are evaluated inline.
+	if newLeft.(TypedExpr).ResolvedType() == types.Int &&
+		newRight.(TypedExpr).ResolvedType() == types.Int {
+		var cmp func(a, b DInt) bool
+		switch newOp {
+		case EQ:
+			cmp = func(a, b DInt) bool { return a == b }
+		case LT:
+			cmp = func(a, b DInt) bool { return a < b }
+		case LE:
+			cmp = func(a, b DInt) bool { return a <= b }
+		}
+		if cmp != nil {
+			return func(ctx *EvalContext) (Datum, error) {
+				l, err := left(ctx)
+				if err != nil {
+					return nil, err
+				}
+				r, err := right(ctx)
+				if err != nil {
+					return nil, err
+				}
+				if l == DNull || r == DNull {
+					return DNull, nil
+				}
+				if a, ok := l.(*DInt); ok {
+					if b, ok := r.(*DInt); ok {
+						return MakeDBool(DBool(cmp(*a, *b) != not)), nil
+					}
+				}
+				return evalFoldedComparison(ctx, fn, l, r, not)
+			}
+		}
+	}
+
+	return func(ctx *EvalContext) (Datum, error) {
+		l, err := left(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r, err := right(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if l == DNull || r == DNull {
+			return DNull, nil
+		}
+		return evalFoldedComparison(ctx, fn, l, r, not)
+	}
+}
+
+func evalFoldedComparison(
+	ctx *EvalContext, fn func(*EvalContext, Datum, Datum) (Datum, error), left, right Datum, not bool,
+) (Datum, error) {
+	d, err := fn(ctx, left, right)
+	if err != nil {
+		return nil, err
+	}
+	if b, ok := d.(*DBool); ok {
+		return MakeDBool(*b != DBool(not)), nil
+	}
+	return d, nil
+}
@@ -21,6 +21,7 @@ type CopyFrom struct {
 	Table   NormalizableTableName
 	Columns UnresolvedNames
 	Stdin   bool
+	Options KVOptions
 }
 
 // Format implements the NodeFormatter interface.
@@ -36,4 +37,50 @@ func (node *CopyFrom) Format(buf *bytes.Buffer, f FmtFlags) {
 	if node.Stdin {
 		buf.WriteString("STDIN")
 	}
+	formatCopyOptions(buf, f, node.Options)
+}
+
+// CopyTo represents a COPY TO statement. Either Table or Select is set.
+type CopyTo struct {
+	Table   NormalizableTableName
+	Columns UnresolvedNames
+	Select  *Select
+	Options KVOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CopyTo) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("COPY ")
+	if node.Select != nil {
+		buf.WriteByte('(')
+		FormatNode(buf, f, node.Select)
+		buf.WriteByte(')')
+	} else {
+		FormatNode(buf, f, &node.Table)
+		if len(node.Columns) > 0 {
+			buf.WriteString(" (")
+			FormatNode(buf, f, node.Columns)
+			buf.WriteString(")")
+		}
+	}
+	buf.WriteString(" TO STDOUT")
+	formatCopyOptions(buf, f, node.Options)
+}
+
+func formatCopyOptions(buf *bytes.Buffer, f FmtFlags, opts KVOptions) {
+	if len(opts) == 0 {
+		return
+	}
+	buf.WriteString(" WITH (")
+	for i, o := range opts {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, o.Key)
+		if o.Value != nil {
+			buf.WriteByte(' ')
+			FormatNode(buf, f, o.Value)
+		}
+	}
+	buf.WriteByte(')')
 }
@@ -797,6 +797,7 @@ type CreateTable struct {
 	Table         NormalizableTableName
 	Interleave    *InterleaveDef
 	PartitionBy   *PartitionBy
+	Locality      *Locality
 	Defs          TableDefs
 	AsSource      *Select
 	AsColumnNames NameList // Only to be used in conjunction with AsSource
@@ -833,6 +834,10 @@ func (node *CreateTable) Format(buf *bytes.Buffer, f FmtFlags) {
 		if node.PartitionBy != nil {
 			FormatNode(buf, f, node.PartitionBy)
 		}
+		if node.Locality != nil {
+			buf.WriteByte(' ')
+			FormatNode(buf, f, *node.Locality)
+		}
 	}
 }
 
@@ -913,11 +918,38 @@ const (
 	SeqOptCycle     = "CYCLE"
 )
 
-// CreateUser represents a CREATE USER statement.
+// CreateUser represents a CREATE USER or CREATE ROLE statement.
 type CreateUser struct {
-	Name        Expr
-	Password    Expr // nil if no password specified
-	IfNotExists bool
+	Name            Expr
+	Password        Expr   // nil if no password specified
+	ConnectionLimit *int64 // nil if no connection limit specified
+	ValidUntil      Expr   // nil if no expiration of the password specified
+	IfNotExists     bool
+	IsRole          bool
+	RoleOptions     []RoleOption
+}
+
+// RoleOption is an attribute of a role specified with CREATE ROLE.
+type RoleOption int
+
+// RoleOption values.
+const (
+	RoleOptionLogin RoleOption = iota
+	RoleOptionNoLogin
+	RoleOptionInherit
+	RoleOptionNoInherit
+)
+
+var roleOptionName = [...]string{
+	RoleOptionLogin:     "LOGIN",
+	RoleOptionNoLogin:   "NOLOGIN",
+	RoleOptionInherit:   "INHERIT",
+	RoleOptionNoInherit: "NOINHERIT",
+}
+
+// String implements the fmt.Stringer interface.
+func (o RoleOption) String() string {
+	return roleOptionName[o]
 }
 
 // HasPassword returns if the CreateUser has a password.
@@ -927,11 +959,22 @@ func (node *CreateUser) HasPassword() bool {
 
 // Format implements the NodeFormatter interface.
 func (node *CreateUser) Format(buf *bytes.Buffer, f FmtFlags) {
-	buf.WriteString("CREATE USER ")
+	if node.IsRole {
+		buf.WriteString("CREATE ROLE ")
+	} else {
+		buf.WriteString("CREATE USER ")
+	}
 	if node.IfNotExists {
 		buf.WriteString("IF NOT EXISTS ")
 	}
 	FormatNode(buf, f, node.Name)
+	if len(node.RoleOptions) > 0 {
+		buf.WriteString(" WITH")
+		for _, o := range node.RoleOptions {
+			buf.WriteByte(' ')
+			buf.WriteString(o.String())
+		}
+	}
 	if node.HasPassword() {
 		buf.WriteString(" WITH PASSWORD ")
 		if f.showPasswords {
@@ -940,6 +983,13 @@ func (node *CreateUser) Format(buf *bytes.Buffer, f FmtFlags) {
 			buf.WriteString("*****")
 		}
 	}
+	if node.ConnectionLimit != nil {
+		fmt.Fprintf(buf, " CONNECTION LIMIT %d", *node.ConnectionLimit)
+	}
+	if node.ValidUntil != nil {
+		buf.WriteString(" VALID UNTIL ")
+		FormatNode(buf, f, node.ValidUntil)
+	}
 }
 
 // AlterUserSetPassword represents an ALTER USER ... WITH PASSWORD statement.
@@ -964,11 +1014,100 @@ func (node *AlterUserSetPassword) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// AlterUserSetConnectionLimit represents an ALTER USER ... WITH CONNECTION
+// LIMIT statement.
+type AlterUserSetConnectionLimit struct {
+	Name            Expr
+	ConnectionLimit int64
+	IfExists        bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterUserSetConnectionLimit) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER USER ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Name)
+	fmt.Fprintf(buf, " WITH CONNECTION LIMIT %d", node.ConnectionLimit)
+}
+
+// AlterUserSetValidUntil represents an ALTER USER ... WITH VALID UNTIL
+// statement.
+type AlterUserSetValidUntil struct {
+	Name       Expr
+	ValidUntil Expr
+	IfExists   bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterUserSetValidUntil) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER USER ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" WITH VALID UNTIL ")
+	FormatNode(buf, f, node.ValidUntil)
+}
+
+// AlterUserSetStatements represents an ALTER USER ... WITH ALLOW STATEMENTS,
+// DENY STATEMENTS or ALLOW ALL STATEMENTS statement.
+type AlterUserSetStatements struct {
+	Name     Expr
+	Deny     bool
+	Classes  NameList // nil for ALLOW ALL STATEMENTS
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterUserSetStatements) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER USER ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Name)
+	if node.Classes == nil {
+		buf.WriteString(" WITH ALLOW ALL STATEMENTS")
+		return
+	}
+	if node.Deny {
+		buf.WriteString(" WITH DENY STATEMENTS ")
+	} else {
+		buf.WriteString(" WITH ALLOW STATEMENTS ")
+	}
+	FormatNode(buf, f, node.Classes)
+}
+
+// AlterUserSetQuota represents an ALTER USER ... WITH QUOTA statement.
+type AlterUserSetQuota struct {
+	Name     Expr
+	Quota    Name
+	Limit    int64 // -1 removes the quota
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterUserSetQuota) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER USER ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" WITH QUOTA ")
+	FormatNode(buf, f, node.Quota)
+	fmt.Fprintf(buf, " = %d", node.Limit)
+}
+
 // CreateView represents a CREATE VIEW statement.
 type CreateView struct {
 	Name        NormalizableTableName
 	ColumnNames NameList
-	AsSource    *Select
+	// SecurityInvoker is set if the query of the view runs with the
+	// privileges of the user selecting from the view, instead of the
+	// privileges of its owner.
+	SecurityInvoker bool
+	AsSource        *Select
 }
 
 // Format implements the NodeFormatter interface.
@@ -983,6 +1122,48 @@ func (node *CreateView) Format(buf *bytes.Buffer, f FmtFlags) {
 		buf.WriteByte(')')
 	}
 
+	if node.SecurityInvoker {
+		buf.WriteString(" SECURITY INVOKER")
+	}
+
 	buf.WriteString(" AS ")
 	FormatNode(buf, f, node.AsSource)
 }
+
+// CreatePolicy represents a CREATE POLICY statement.
+type CreatePolicy struct {
+	Name  Name
+	Table NormalizableTableName
+	// Command is the statement the policy applies to (ALL, SELECT, INSERT,
+	// UPDATE or DELETE), or empty if it wasn't specified.
+	Command   string
+	Roles     NameList
+	Using     Expr
+	WithCheck Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreatePolicy) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE POLICY ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" ON ")
+	FormatNode(buf, f, &node.Table)
+	if node.Command != "" {
+		buf.WriteString(" FOR ")
+		buf.WriteString(node.Command)
+	}
+	if len(node.Roles) > 0 {
+		buf.WriteString(" TO ")
+		FormatNode(buf, f, node.Roles)
+	}
+	if node.Using != nil {
+		buf.WriteString(" USING (")
+		FormatNode(buf, f, node.Using)
+		buf.WriteByte(')')
+	}
+	if node.WithCheck != nil {
+		buf.WriteString(" WITH CHECK (")
+		FormatNode(buf, f, node.WithCheck)
+		buf.WriteByte(')')
+	}
+}
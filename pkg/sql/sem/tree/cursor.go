@@ -0,0 +1,109 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// DeclareCursor represents a DECLARE statement.
+type DeclareCursor struct {
+	Name   Name
+	Select *Select
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DeclareCursor) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DECLARE ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" CURSOR FOR ")
+	FormatNode(buf, f, node.Select)
+}
+
+// FetchDirection is the direction of a FETCH or MOVE statement.
+type FetchDirection int
+
+// The values of FetchDirection.
+const (
+	// FetchForward moves the cursor Count rows forward.
+	FetchForward FetchDirection = iota
+	// FetchBackward moves the cursor Count rows backward.
+	FetchBackward
+	// FetchAbsolute moves the cursor to the Count-th row, counting from the
+	// end if Count is negative.
+	FetchAbsolute
+	// FetchRelative moves the cursor to the Count-th row after the current
+	// one, or before it if Count is negative.
+	FetchRelative
+)
+
+// FetchAll is the Count of FETCH ALL, FORWARD ALL and BACKWARD ALL.
+const FetchAll = math.MaxInt64
+
+// FetchCursor represents a FETCH or MOVE statement.
+type FetchCursor struct {
+	Name      Name
+	Direction FetchDirection
+	Count     int64
+	// Move is set for MOVE statements, which position the cursor like FETCH
+	// but don't return the rows.
+	Move bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *FetchCursor) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node.Move {
+		buf.WriteString("MOVE ")
+	} else {
+		buf.WriteString("FETCH ")
+	}
+	switch node.Direction {
+	case FetchForward:
+		buf.WriteString("FORWARD ")
+	case FetchBackward:
+		buf.WriteString("BACKWARD ")
+	case FetchAbsolute:
+		buf.WriteString("ABSOLUTE ")
+	case FetchRelative:
+		buf.WriteString("RELATIVE ")
+	default:
+		panic(fmt.Sprintf("unknown fetch direction %d", node.Direction))
+	}
+	if node.Count == FetchAll && (node.Direction == FetchForward || node.Direction == FetchBackward) {
+		buf.WriteString("ALL")
+	} else {
+		fmt.Fprintf(buf, "%d", node.Count)
+	}
+	buf.WriteString(" FROM ")
+	FormatNode(buf, f, node.Name)
+}
+
+// CloseCursor represents a CLOSE statement.
+type CloseCursor struct {
+	// Name is empty for CLOSE ALL.
+	Name Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CloseCursor) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CLOSE ")
+	if node.Name == "" {
+		buf.WriteString("ALL")
+	} else {
+		FormatNode(buf, f, node.Name)
+	}
+}
@@ -29,6 +29,12 @@ type DiscardMode int
 const (
 	// DiscardModeAll represents a DISCARD ALL statement.
 	DiscardModeAll DiscardMode = iota
+	// DiscardModePlans represents a DISCARD PLANS statement.
+	DiscardModePlans
+	// DiscardModeSequences represents a DISCARD SEQUENCES statement.
+	DiscardModeSequences
+	// DiscardModeTemp represents a DISCARD TEMP statement.
+	DiscardModeTemp
 )
 
 // Format implements the NodeFormatter interface.
@@ -36,6 +42,12 @@ func (node *Discard) Format(buf *bytes.Buffer, f FmtFlags) {
 	switch node.Mode {
 	case DiscardModeAll:
 		buf.WriteString("DISCARD ALL")
+	case DiscardModePlans:
+		buf.WriteString("DISCARD PLANS")
+	case DiscardModeSequences:
+		buf.WriteString("DISCARD SEQUENCES")
+	case DiscardModeTemp:
+		buf.WriteString("DISCARD TEMP")
 	}
 }
 
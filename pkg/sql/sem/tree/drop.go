@@ -145,17 +145,40 @@ func (node *DropSequence) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
-// DropUser represents a DROP USER statement
+// DropUser represents a DROP USER or DROP ROLE statement
 type DropUser struct {
 	Names    Exprs
 	IfExists bool
+	IsRole   bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *DropUser) Format(buf *bytes.Buffer, f FmtFlags) {
-	buf.WriteString("DROP USER ")
+	if node.IsRole {
+		buf.WriteString("DROP ROLE ")
+	} else {
+		buf.WriteString("DROP USER ")
+	}
 	if node.IfExists {
 		buf.WriteString("IF EXISTS ")
 	}
 	FormatNode(buf, f, node.Names)
 }
+
+// DropPolicy represents a DROP POLICY statement.
+type DropPolicy struct {
+	Name     Name
+	Table    NormalizableTableName
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropPolicy) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("DROP POLICY ")
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" ON ")
+	FormatNode(buf, f, &node.Table)
+}
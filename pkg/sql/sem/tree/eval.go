@@ -2134,10 +2134,108 @@ type EvalPlanner interface {
 	// ParseType parses a column type.
 	ParseType(sql string) (coltypes.CastTargetType, error)
 
+	// ParseStatement parses a single SQL statement.
+	ParseStatement(sql string) (Statement, error)
+
 	// IncrementSequence increments the given sequence and returns the result.
 	// It returns an error if the given name is not a sequence.
 	// The caller must ensure that seqName is fully qualified already.
 	IncrementSequence(context context.Context, seqName *TableName) (int64, error)
+
+	// SchemaExists returns whether the schema (i.e. database) exists.
+	SchemaExists(ctx context.Context, name string) (bool, error)
+
+	// SessionID returns the cluster-wide ID of the current session.
+	SessionID() int64
+
+	// CancelBackend cancels the queries running in the session with the given
+	// ID, routing the request to the node owning the session. If terminate is
+	// set, the session is closed as well. It returns false if the session does
+	// not exist.
+	CancelBackend(ctx context.Context, sessionID int64, terminate bool) (bool, error)
+
+	// EncryptColumnValue encrypts a value with the data key of the node, which
+	// is wrapped by the KMS of the cluster.
+	EncryptColumnValue(ctx context.Context, plaintext []byte) ([]byte, error)
+
+	// DecryptColumnValue decrypts a value returned by EncryptColumnValue.
+	DecryptColumnValue(ctx context.Context, ciphertext []byte) ([]byte, error)
+
+	// HasPrivilege returns whether the given user, or one of the roles whose
+	// privileges it inherits, holds any of the privileges listed in privs on
+	// the given object. privs is a comma-separated list of privilege names,
+	// each optionally followed by WITH GRANT OPTION, as accepted by the
+	// has_*_privilege builtins.
+	HasPrivilege(ctx context.Context, user string, object PrivilegeObject, privs string) (bool, error)
+
+	// HasRole returns whether the given user holds any of the privileges on
+	// the given role listed in privs (MEMBER, USAGE, optionally followed by
+	// WITH ADMIN OPTION), as accepted by pg_has_role.
+	HasRole(ctx context.Context, user string, role string, privs string) (bool, error)
+
+	// FollowerReadTimestamp returns a timestamp old enough for the reads at it
+	// to be served by any replica, rather than by the lease holders. It
+	// returns an error if follower reads are disabled.
+	FollowerReadTimestamp() (time.Time, error)
+
+	// ResetStatementStatistics clears the statement statistics collected on
+	// this node.
+	ResetStatementStatistics(ctx context.Context) error
+
+	// CheckConsistency compares the checksums of the replicas of each range
+	// overlapping the span from startKey to endKey, and returns the outcome
+	// of the check of each range.
+	CheckConsistency(ctx context.Context, startKey, endKey []byte) ([]RangeConsistencyCheck, error)
+
+	// RequestStatementDiagnostics registers a request for the collection of
+	// a statement bundle for the next execution of the statement fingerprint
+	// running for longer than minLatency, and returns the ID of the request.
+	RequestStatementDiagnostics(
+		ctx context.Context, fingerprint string, minLatency time.Duration,
+	) (int64, error)
+}
+
+// RangeConsistencyCheck is the outcome of the consistency check of a range,
+// as returned by EvalPlanner.CheckConsistency.
+type RangeConsistencyCheck struct {
+	RangeID  int64
+	StartKey []byte
+	// Status is RangeConsistencyCheckOK if the checksums of the replicas of
+	// the range were compared, and RangeConsistencyCheckFailed otherwise, in
+	// which case Detail holds the reason.
+	Status string
+	Detail string
+}
+
+// The statuses of RangeConsistencyCheck.
+const (
+	RangeConsistencyCheckOK     = "RANGE_CHECKED"
+	RangeConsistencyCheckFailed = "RANGE_CHECK_FAILED"
+)
+
+// PrivilegeObjectType is the type of the object of a has_*_privilege builtin.
+type PrivilegeObjectType int
+
+// PrivilegeObjectType values.
+const (
+	PrivilegeObjectDatabase PrivilegeObjectType = iota
+	PrivilegeObjectSchema
+	PrivilegeObjectTable
+	PrivilegeObjectSequence
+	PrivilegeObjectColumn
+)
+
+// PrivilegeObject identifies the object of a has_*_privilege builtin.
+type PrivilegeObject struct {
+	Type PrivilegeObjectType
+	// Database is the name of the database or schema, for databases and
+	// schemas.
+	Database string
+	// Table is the qualified name of the table or sequence, or of the table
+	// of the column.
+	Table *TableName
+	// Column is the name of the column.
+	Column string
 }
 
 // CtxProvider is anything that can return a Context.
@@ -2874,9 +2972,14 @@ func performCast(ctx *EvalContext, d Datum, t coltypes.CastTargetType) (Datum, e
 		case *DDate:
 			return MakeDTimestampTZFromDate(ctx.GetLocation(), d), nil
 		case *DTimestamp:
-			_, before := d.Time.Zone()
-			_, after := d.Time.In(ctx.GetLocation()).Zone()
-			return MakeDTimestampTZ(d.Time.Add(time.Duration(before-after)*time.Second), time.Microsecond), nil
+			// Interpret the wall clock time in the session's time zone, whose
+			// offset at that time may differ from its offset at the instant
+			// the timestamp represents in UTC around DST transitions.
+			year, month, day := d.Time.Date()
+			hour, min, sec := d.Time.Clock()
+			return MakeDTimestampTZ(time.Date(
+				year, month, day, hour, min, sec, d.Time.Nanosecond(), ctx.GetLocation(),
+			), time.Microsecond), nil
 		case *DInt:
 			return MakeDTimestampTZ(timeutil.Unix(int64(*d), 0), time.Second), nil
 		case *DTimestampTZ:
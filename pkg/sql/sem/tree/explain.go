@@ -21,6 +21,10 @@ import (
 
 // Explain represents an EXPLAIN statement.
 type Explain struct {
+	// Analyze is set for EXPLAIN ANALYZE, which executes the statement. The
+	// only mode it supports is DEBUG, which collects a statement bundle.
+	Analyze bool
+
 	// Options defines how EXPLAIN should operate (VERBOSE, METADATA,
 	// etc.) Which options are valid depends on the explain mode. See
 	// sql/explain.go for details.
@@ -33,6 +37,9 @@ type Explain struct {
 // Format implements the NodeFormatter interface.
 func (node *Explain) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("EXPLAIN ")
+	if node.Analyze {
+		buf.WriteString("ANALYZE ")
+	}
 	if len(node.Options) > 0 {
 		buf.WriteByte('(')
 		for i, opt := range node.Options {
@@ -0,0 +1,41 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import "bytes"
+
+// Export represents an EXPORT statement.
+type Export struct {
+	Query      *Select
+	FileFormat string
+	File       Expr
+	Options    KVOptions
+}
+
+var _ Statement = &Export{}
+
+// Format implements the NodeFormatter interface.
+func (node *Export) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("EXPORT INTO ")
+	buf.WriteString(node.FileFormat)
+	buf.WriteString(" ")
+	FormatNode(buf, f, node.File)
+	if node.Options != nil {
+		buf.WriteString(" WITH ")
+		FormatNode(buf, f, node.Options)
+	}
+	buf.WriteString(" FROM ")
+	FormatNode(buf, f, node.Query)
+}
@@ -0,0 +1,41 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// StatementFingerprint returns the fingerprint of a statement, which
+// identifies the statements differing only by their constants. The statement
+// is pretty-printed with its literals replaced by _, and the lists of
+// literals shortened as described in formatHideConstants, so that the
+// whitespace, the case of the keywords and unquoted names, and the length of
+// the lists of values don't change the fingerprint.
+//
+// The fingerprints are shared by the statement statistics, the statement
+// diagnostics requests and the trace export, so they must remain stable.
+func StatementFingerprint(stmt Statement) string {
+	return AsStringWithFlags(stmt, FmtHideConstants)
+}
+
+// StatementFingerprintID returns a short stable identifier of a statement
+// fingerprint: the FNV-1a 64-bit hash of the fingerprint, in hexadecimal.
+func StatementFingerprintID(fingerprint string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(fingerprint))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
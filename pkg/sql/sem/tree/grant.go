@@ -32,8 +32,11 @@ import (
 // Grant represents a GRANT statement.
 type Grant struct {
 	Privileges privilege.List
-	Targets    TargetList
-	Grantees   NameList
+	// Columns is set if the privileges are granted on columns of the
+	// targets rather than on the targets themselves.
+	Columns  NameList
+	Targets  TargetList
+	Grantees NameList
 }
 
 // TargetList represents a list of targets.
@@ -79,8 +82,70 @@ func (tl TargetList) NormalizeTablesWithDatabase(db string) error {
 func (node *Grant) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("GRANT ")
 	node.Privileges.Format(buf)
+	if node.Columns != nil {
+		buf.WriteString(" (")
+		FormatNode(buf, f, node.Columns)
+		buf.WriteByte(')')
+	}
 	buf.WriteString(" ON ")
 	FormatNode(buf, f, node.Targets)
 	buf.WriteString(" TO ")
 	FormatNode(buf, f, node.Grantees)
 }
+
+// GrantRole represents a GRANT <role> statement.
+type GrantRole struct {
+	Roles       NameList
+	Members     NameList
+	AdminOption bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *GrantRole) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("GRANT ")
+	FormatNode(buf, f, node.Roles)
+	buf.WriteString(" TO ")
+	FormatNode(buf, f, node.Members)
+	if node.AdminOption {
+		buf.WriteString(" WITH ADMIN OPTION")
+	}
+}
+
+// AlterDefaultPrivileges represents an ALTER DEFAULT PRIVILEGES statement.
+type AlterDefaultPrivileges struct {
+	// Roles are the roles whose future tables get the privileges. The
+	// session user if empty.
+	Roles NameList
+	// Schemas are the databases the rules are defined in. The current
+	// database if empty.
+	Schemas    NameList
+	IsGrant    bool
+	Privileges privilege.List
+	Grantees   NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDefaultPrivileges) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER DEFAULT PRIVILEGES")
+	if node.Roles != nil {
+		buf.WriteString(" FOR ROLE ")
+		FormatNode(buf, f, node.Roles)
+	}
+	if node.Schemas != nil {
+		buf.WriteString(" IN SCHEMA ")
+		FormatNode(buf, f, node.Schemas)
+	}
+	if node.IsGrant {
+		buf.WriteString(" GRANT ")
+	} else {
+		buf.WriteString(" REVOKE ")
+	}
+	node.Privileges.Format(buf)
+	buf.WriteString(" ON TABLES")
+	if node.IsGrant {
+		buf.WriteString(" TO ")
+	} else {
+		buf.WriteString(" FROM ")
+	}
+	FormatNode(buf, f, node.Grantees)
+}
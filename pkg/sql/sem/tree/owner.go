@@ -0,0 +1,74 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import "bytes"
+
+// AlterDatabaseOwner represents an ALTER DATABASE ... OWNER TO statement.
+type AlterDatabaseOwner struct {
+	Name  Name
+	Owner Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDatabaseOwner) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER DATABASE ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" OWNER TO ")
+	FormatNode(buf, f, node.Owner)
+}
+
+// AlterTableOwner represents an ALTER TABLE, ALTER VIEW or ALTER SEQUENCE
+// ... OWNER TO statement. Whether the user has asked to alter a table, view
+// or sequence is indicated by the IsView and IsSequence fields.
+type AlterTableOwner struct {
+	Name       NormalizableTableName
+	Owner      Name
+	IfExists   bool
+	IsView     bool
+	IsSequence bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableOwner) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER ")
+	if node.IsView {
+		buf.WriteString("VIEW ")
+	} else if node.IsSequence {
+		buf.WriteString("SEQUENCE ")
+	} else {
+		buf.WriteString("TABLE ")
+	}
+	if node.IfExists {
+		buf.WriteString("IF EXISTS ")
+	}
+	FormatNode(buf, f, &node.Name)
+	buf.WriteString(" OWNER TO ")
+	FormatNode(buf, f, node.Owner)
+}
+
+// ReassignOwnedBy represents a REASSIGN OWNED BY statement.
+type ReassignOwnedBy struct {
+	OldRoles NameList
+	NewRole  Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ReassignOwnedBy) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("REASSIGN OWNED BY ")
+	FormatNode(buf, f, node.OldRoles)
+	buf.WriteString(" TO ")
+	FormatNode(buf, f, node.NewRole)
+}
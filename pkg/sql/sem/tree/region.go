@@ -0,0 +1,77 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import "bytes"
+
+// AlterDatabaseSetPrimaryRegion represents an ALTER DATABASE ... SET PRIMARY
+// REGION statement.
+type AlterDatabaseSetPrimaryRegion struct {
+	Name   Name
+	Region Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDatabaseSetPrimaryRegion) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER DATABASE ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" SET PRIMARY REGION ")
+	FormatNode(buf, f, node.Region)
+}
+
+// AlterDatabaseAddRegion represents an ALTER DATABASE ... ADD REGION
+// statement.
+type AlterDatabaseAddRegion struct {
+	Name   Name
+	Region Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDatabaseAddRegion) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("ALTER DATABASE ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" ADD REGION ")
+	FormatNode(buf, f, node.Region)
+}
+
+// Locality represents the locality of a table of a multi-region database.
+type Locality int
+
+const (
+	// LocalityRegionalByTable homes the table in the primary region of the
+	// database.
+	LocalityRegionalByTable Locality = iota
+	// LocalityGlobal replicates the table in every region of the database.
+	LocalityGlobal
+	// LocalityRegionalByRow homes each row in the region held by its
+	// crdb_region column.
+	LocalityRegionalByRow
+)
+
+var localityName = [...]string{
+	LocalityRegionalByTable: "REGIONAL BY TABLE",
+	LocalityGlobal:          "GLOBAL",
+	LocalityRegionalByRow:   "REGIONAL BY ROW",
+}
+
+func (l Locality) String() string {
+	return localityName[l]
+}
+
+// Format implements the NodeFormatter interface.
+func (l Locality) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("LOCALITY ")
+	buf.WriteString(l.String())
+}
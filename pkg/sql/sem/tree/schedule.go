@@ -0,0 +1,62 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import "bytes"
+
+// FullBackupClause describes when the backups of a schedule are full.
+type FullBackupClause struct {
+	AlwaysFull bool
+	Recurrence Expr
+}
+
+// ScheduledBackup represents a CREATE SCHEDULE FOR BACKUP statement.
+type ScheduledBackup struct {
+	ScheduleLabel Expr
+	Recurrence    Expr
+	FullBackup    *FullBackupClause // nil if all the backups are full.
+	Targets       TargetList
+	To            PartitionedBackup
+	BackupOptions KVOptions
+}
+
+var _ Statement = &ScheduledBackup{}
+
+// Format implements the NodeFormatter interface.
+func (node *ScheduledBackup) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE SCHEDULE ")
+	if node.ScheduleLabel != nil {
+		FormatNode(buf, f, node.ScheduleLabel)
+		buf.WriteString(" ")
+	}
+	buf.WriteString("FOR BACKUP ")
+	FormatNode(buf, f, node.Targets)
+	buf.WriteString(" TO ")
+	FormatNode(buf, f, node.To)
+	if node.BackupOptions != nil {
+		buf.WriteString(" WITH ")
+		FormatNode(buf, f, node.BackupOptions)
+	}
+	buf.WriteString(" RECURRING ")
+	FormatNode(buf, f, node.Recurrence)
+	if node.FullBackup != nil {
+		buf.WriteString(" FULL BACKUP ")
+		if node.FullBackup.AlwaysFull {
+			buf.WriteString("ALWAYS")
+		} else {
+			FormatNode(buf, f, node.FullBackup.Recurrence)
+		}
+	}
+}
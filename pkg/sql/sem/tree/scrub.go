@@ -83,11 +83,15 @@ type ScrubOption interface {
 }
 
 // scrubOptionType implements the ScrubOption interface
-func (*ScrubOptionIndex) scrubOptionType()    {}
-func (*ScrubOptionPhysical) scrubOptionType() {}
+func (*ScrubOptionIndex) scrubOptionType()      {}
+func (*ScrubOptionPhysical) scrubOptionType()   {}
+func (*ScrubOptionConstraint) scrubOptionType() {}
+func (*ScrubOptionRepair) scrubOptionType()     {}
 
-func (n *ScrubOptionIndex) String() string    { return AsString(n) }
-func (n *ScrubOptionPhysical) String() string { return AsString(n) }
+func (n *ScrubOptionIndex) String() string      { return AsString(n) }
+func (n *ScrubOptionPhysical) String() string   { return AsString(n) }
+func (n *ScrubOptionConstraint) String() string { return AsString(n) }
+func (n *ScrubOptionRepair) String() string     { return AsString(n) }
 
 // ScrubOptionIndex represents an INDEX scrub check.
 type ScrubOptionIndex struct {
@@ -113,3 +117,29 @@ type ScrubOptionPhysical struct{}
 func (n *ScrubOptionPhysical) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("PHYSICAL")
 }
+
+// ScrubOptionConstraint represents a CONSTRAINT scrub check.
+type ScrubOptionConstraint struct {
+	ConstraintNames NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (n *ScrubOptionConstraint) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CONSTRAINT ")
+	if n.ConstraintNames != nil {
+		buf.WriteByte('(')
+		n.ConstraintNames.Format(buf, f)
+		buf.WriteByte(')')
+	} else {
+		buf.WriteString("ALL")
+	}
+}
+
+// ScrubOptionRepair represents the REPAIR scrub option, which repairs the
+// errors found by the index checks.
+type ScrubOptionRepair struct{}
+
+// Format implements the NodeFormatter interface.
+func (n *ScrubOptionRepair) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("REPAIR")
+}
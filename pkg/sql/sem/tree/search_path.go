@@ -21,6 +21,12 @@ import (
 // PgCatalogName is the name of the pg_catalog system database.
 const PgCatalogName = "pg_catalog"
 
+// PgTempSchemaName is the alias of the session's temporary schema. Postgres
+// searches it first for relations, whether it is mentioned in the search path
+// or not. Temporary objects aren't supported, so sessions have no temporary
+// schema and the pg_temp entries of search paths are skipped.
+const PgTempSchemaName = "pg_temp"
+
 // SearchPath represents a list of namespaces to search builtins in.
 // The names must be normalized (as per Name.Normalize) already.
 type SearchPath struct {
@@ -61,9 +67,11 @@ func (s SearchPath) Iter() func() (next string, ok bool) {
 			i++
 			return PgCatalogName, true
 		}
-		if i < len(s.paths) {
+		for i < len(s.paths) {
 			i++
-			return s.paths[i-1], true
+			if s.paths[i-1] != PgTempSchemaName {
+				return s.paths[i-1], true
+			}
 		}
 		return "", false
 	}
@@ -73,9 +81,11 @@ func (s SearchPath) Iter() func() (next string, ok bool) {
 func (s SearchPath) IterWithoutImplicitPGCatalog() func() (next string, ok bool) {
 	i := 0
 	return func() (next string, ok bool) {
-		if i < len(s.paths) {
+		for i < len(s.paths) {
 			i++
-			return s.paths[i-1], true
+			if s.paths[i-1] != PgTempSchemaName {
+				return s.paths[i-1], true
+			}
 		}
 		return "", false
 	}
@@ -30,6 +30,8 @@ func TestImpliedSearchPath(t *testing.T) {
 		{[]string{`pg_catalog`}, []string{`pg_catalog`}, []string{`pg_catalog`}},
 		{[]string{`foobar`, `pg_catalog`}, []string{`foobar`, `pg_catalog`}, []string{`foobar`, `pg_catalog`}},
 		{[]string{`foobar`}, []string{`pg_catalog`, `foobar`}, []string{`foobar`}},
+		{[]string{`pg_temp`}, []string{`pg_catalog`}, []string{}},
+		{[]string{`foobar`, `pg_temp`, `pg_catalog`, `baz`}, []string{`foobar`, `pg_catalog`, `baz`}, []string{`foobar`, `pg_catalog`, `baz`}},
 	}
 
 	for _, tc := range testCases {
@@ -26,6 +26,8 @@ package tree
 import (
 	"bytes"
 	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 )
 
 // ShowVar represents a SHOW statement.
@@ -141,6 +143,15 @@ func (node *ShowJobs) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("SHOW JOBS")
 }
 
+// ShowSchedules represents a SHOW SCHEDULES statement
+type ShowSchedules struct {
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowSchedules) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SHOW SCHEDULES")
+}
+
 // ShowSessions represents a SHOW SESSIONS statement
 type ShowSessions struct {
 	Cluster bool
@@ -189,6 +200,8 @@ func (node *ShowTables) Format(buf *bytes.Buffer, f FmtFlags) {
 type ShowGrants struct {
 	Targets  *TargetList
 	Grantees NameList
+	// WithPath is set to show how the privileges are obtained.
+	WithPath bool
 }
 
 // Format implements the NodeFormatter interface.
@@ -202,6 +215,28 @@ func (node *ShowGrants) Format(buf *bytes.Buffer, f FmtFlags) {
 		buf.WriteString(" FOR ")
 		FormatNode(buf, f, node.Grantees)
 	}
+	if node.WithPath {
+		buf.WriteString(" WITH PATH")
+	}
+}
+
+// ShowDump represents a SHOW DUMP statement.
+type ShowDump struct {
+	Database Name
+	AsOf     AsOfClause
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowDump) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SHOW DUMP")
+	if node.Database != "" {
+		buf.WriteString(" FROM ")
+		FormatNode(buf, f, node.Database)
+	}
+	if node.AsOf.Expr != nil {
+		buf.WriteByte(' ')
+		FormatNode(buf, f, node.AsOf)
+	}
 }
 
 // ShowCreateTable represents a SHOW CREATE TABLE statement.
@@ -226,6 +261,28 @@ func (node *ShowCreateView) Format(buf *bytes.Buffer, f FmtFlags) {
 	FormatNode(buf, f, &node.View)
 }
 
+// ShowCreateSequence represents a SHOW CREATE SEQUENCE statement.
+type ShowCreateSequence struct {
+	Sequence NormalizableTableName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowCreateSequence) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SHOW CREATE SEQUENCE ")
+	FormatNode(buf, f, &node.Sequence)
+}
+
+// ShowSyntax represents a SHOW SYNTAX statement.
+type ShowSyntax struct {
+	Statement string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowSyntax) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SHOW SYNTAX ")
+	lex.EncodeSQLStringWithFlags(buf, node.Statement, f.encodeFlags)
+}
+
 // ShowTransactionStatus represents a SHOW TRANSACTION STATUS statement.
 type ShowTransactionStatus struct {
 }
@@ -244,7 +301,7 @@ func (node *ShowUsers) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("SHOW USERS")
 }
 
-// ShowRanges represents a SHOW TESTING_RANGES statement.
+// ShowRanges represents a SHOW RANGES statement.
 // Only one of Table and Index can be set.
 type ShowRanges struct {
 	Table *NormalizableTableName
@@ -253,7 +310,28 @@ type ShowRanges struct {
 
 // Format implements the NodeFormatter interface.
 func (node *ShowRanges) Format(buf *bytes.Buffer, f FmtFlags) {
-	buf.WriteString("SHOW TESTING_RANGES FROM ")
+	buf.WriteString("SHOW RANGES FROM ")
+	if node.Index != nil {
+		buf.WriteString("INDEX ")
+		FormatNode(buf, f, node.Index)
+	} else {
+		buf.WriteString("TABLE ")
+		FormatNode(buf, f, node.Table)
+	}
+}
+
+// ShowRangeForRow represents a SHOW RANGE FOR ROW statement.
+// Only one of Table and Index can be set.
+type ShowRangeForRow struct {
+	Table *NormalizableTableName
+	Index *TableNameWithIndex
+	// Row contains the values of a prefix of the index columns.
+	Row Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowRangeForRow) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SHOW RANGE FROM ")
 	if node.Index != nil {
 		buf.WriteString("INDEX ")
 		FormatNode(buf, f, node.Index)
@@ -261,6 +339,9 @@ func (node *ShowRanges) Format(buf *bytes.Buffer, f FmtFlags) {
 		buf.WriteString("TABLE ")
 		FormatNode(buf, f, node.Table)
 	}
+	buf.WriteString(" FOR ROW (")
+	FormatNode(buf, f, node.Row)
+	buf.WriteString(")")
 }
 
 // ShowFingerprints represents a SHOW EXPERIMENTAL_FINGERPRINTS statement.
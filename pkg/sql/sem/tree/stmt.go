@@ -55,6 +55,9 @@ const (
 	Rows
 	// CopyIn indicates a COPY FROM statement.
 	CopyIn
+	// CopyOut indicates a COPY TO statement, which returns rows that are sent
+	// to the client as COPY data.
+	CopyOut
 	// Unknown indicates that the statement does not have a known
 	// return style at the time of parsing. This is not first in the
 	// enumeration because it is more convenient to have Ack as a zero
@@ -120,12 +123,53 @@ func (*AlterTable) StatementTag() string { return "ALTER TABLE" }
 
 func (*AlterTable) hiddenFromShowQueries() {}
 
+// StatementType implements the Statement interface.
+func (*AlterDatabaseOwner) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterDatabaseOwner) StatementTag() string { return "ALTER DATABASE OWNER" }
+
+// StatementType implements the Statement interface.
+func (*AlterDatabaseSetPrimaryRegion) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterDatabaseSetPrimaryRegion) StatementTag() string {
+	return "ALTER DATABASE SET PRIMARY REGION"
+}
+
+// StatementType implements the Statement interface.
+func (*AlterDatabaseAddRegion) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterDatabaseAddRegion) StatementTag() string { return "ALTER DATABASE ADD REGION" }
+
+// StatementType implements the Statement interface.
+func (*AlterDefaultPrivileges) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterDefaultPrivileges) StatementTag() string { return "ALTER DEFAULT PRIVILEGES" }
+
+func (*AlterDefaultPrivileges) hiddenFromStats() {}
+
 // StatementType implements the Statement interface.
 func (*AlterSequence) StatementType() StatementType { return DDL }
 
 // StatementTag returns a short string identifying the type of statement.
 func (*AlterSequence) StatementTag() string { return "ALTER SEQUENCE" }
 
+// StatementType implements the Statement interface.
+func (*AlterTableOwner) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (n *AlterTableOwner) StatementTag() string {
+	if n.IsView {
+		return "ALTER VIEW OWNER"
+	} else if n.IsSequence {
+		return "ALTER SEQUENCE OWNER"
+	}
+	return "ALTER TABLE OWNER"
+}
+
 // StatementType implements the Statement interface.
 func (*AlterUserSetPassword) StatementType() StatementType { return RowsAffected }
 
@@ -134,6 +178,30 @@ func (*AlterUserSetPassword) StatementTag() string { return "ALTER USER" }
 
 func (*AlterUserSetPassword) hiddenFromShowQueries() {}
 
+// StatementType implements the Statement interface.
+func (*AlterUserSetConnectionLimit) StatementType() StatementType { return RowsAffected }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterUserSetConnectionLimit) StatementTag() string { return "ALTER USER" }
+
+// StatementType implements the Statement interface.
+func (*AlterUserSetValidUntil) StatementType() StatementType { return RowsAffected }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterUserSetValidUntil) StatementTag() string { return "ALTER USER" }
+
+// StatementType implements the Statement interface.
+func (*AlterUserSetStatements) StatementType() StatementType { return RowsAffected }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterUserSetStatements) StatementTag() string { return "ALTER USER" }
+
+// StatementType implements the Statement interface.
+func (*AlterUserSetQuota) StatementType() StatementType { return RowsAffected }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterUserSetQuota) StatementTag() string { return "ALTER USER" }
+
 // StatementType implements the Statement interface.
 func (*Backup) StatementType() StatementType { return Rows }
 
@@ -162,6 +230,23 @@ func (*CancelQuery) StatementType() StatementType { return Ack }
 // StatementTag returns a short string identifying the type of statement.
 func (*CancelQuery) StatementTag() string { return "CANCEL QUERY" }
 
+// StatementType implements the Statement interface.
+func (*CancelSession) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CancelSession) StatementTag() string { return "CANCEL SESSION" }
+
+// StatementType implements the Statement interface.
+func (*CloseCursor) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (n *CloseCursor) StatementTag() string {
+	if n.Name == "" {
+		return "CLOSE CURSOR ALL"
+	}
+	return "CLOSE CURSOR"
+}
+
 // StatementType implements the Statement interface.
 func (*CommitTransaction) StatementType() StatementType { return Ack }
 
@@ -176,6 +261,23 @@ func (*CopyFrom) StatementType() StatementType { return CopyIn }
 // StatementTag returns a short string identifying the type of statement.
 func (*CopyFrom) StatementTag() string { return "COPY" }
 
+// StatementType implements the Statement interface.
+func (*CopyTo) StatementType() StatementType { return CopyOut }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CopyTo) StatementTag() string { return "COPY" }
+
+// StatementType implements the Statement interface.
+func (*CreateChangefeed) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (n *CreateChangefeed) StatementTag() string {
+	if n.SinkURI == nil {
+		return "EXPERIMENTAL CHANGEFEED"
+	}
+	return "CREATE CHANGEFEED"
+}
+
 // StatementType implements the Statement interface.
 func (*CreateDatabase) StatementType() StatementType { return DDL }
 
@@ -203,10 +305,21 @@ func (n *CreateTable) StatementTag() string {
 func (*CreateUser) StatementType() StatementType { return RowsAffected }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*CreateUser) StatementTag() string { return "CREATE USER" }
+func (n *CreateUser) StatementTag() string {
+	if n.IsRole {
+		return "CREATE ROLE"
+	}
+	return "CREATE USER"
+}
 
 func (*CreateUser) hiddenFromShowQueries() {}
 
+// StatementType implements the Statement interface.
+func (*CreatePolicy) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreatePolicy) StatementTag() string { return "CREATE POLICY" }
+
 // StatementType implements the Statement interface.
 func (*CreateView) StatementType() StatementType { return DDL }
 
@@ -233,6 +346,12 @@ func (n *Deallocate) StatementTag() string {
 
 func (*Deallocate) hiddenFromStats() {}
 
+// StatementType implements the Statement interface.
+func (*DeclareCursor) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DeclareCursor) StatementTag() string { return "DECLARE CURSOR" }
+
 // StatementType implements the Statement interface.
 func (*Discard) StatementType() StatementType { return Ack }
 
@@ -263,6 +382,12 @@ func (*DropTable) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*DropTable) StatementTag() string { return "DROP TABLE" }
 
+// StatementType implements the Statement interface.
+func (*DropPolicy) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropPolicy) StatementTag() string { return "DROP POLICY" }
+
 // StatementType implements the Statement interface.
 func (*DropView) StatementType() StatementType { return DDL }
 
@@ -279,7 +404,12 @@ func (*DropSequence) StatementTag() string { return "DROP SEQUENCE" }
 func (*DropUser) StatementType() StatementType { return RowsAffected }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*DropUser) StatementTag() string { return "DROP USER" }
+func (n *DropUser) StatementTag() string {
+	if n.IsRole {
+		return "DROP ROLE"
+	}
+	return "DROP USER"
+}
 
 // StatementType implements the Statement interface.
 func (*Execute) StatementType() StatementType { return Unknown }
@@ -295,6 +425,28 @@ func (*Explain) StatementTag() string { return "EXPLAIN" }
 
 func (*Explain) hiddenFromStats() {}
 
+// StatementType implements the Statement interface.
+func (*Export) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Export) StatementTag() string { return "EXPORT" }
+
+// StatementType implements the Statement interface.
+func (n *FetchCursor) StatementType() StatementType {
+	if n.Move {
+		return RowsAffected
+	}
+	return Rows
+}
+
+// StatementTag returns a short string identifying the type of statement.
+func (n *FetchCursor) StatementTag() string {
+	if n.Move {
+		return "MOVE"
+	}
+	return "FETCH"
+}
+
 // StatementType implements the Statement interface.
 func (*Grant) StatementType() StatementType { return DDL }
 
@@ -303,6 +455,14 @@ func (*Grant) StatementTag() string { return "GRANT" }
 
 func (*Grant) hiddenFromStats() {}
 
+// StatementType implements the Statement interface.
+func (*GrantRole) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*GrantRole) StatementTag() string { return "GRANT" }
+
+func (*GrantRole) hiddenFromStats() {}
+
 // StatementType implements the Statement interface.
 func (n *Insert) StatementType() StatementType { return n.Returning.statementType() }
 
@@ -315,6 +475,18 @@ func (n *Import) StatementType() StatementType { return Rows }
 // StatementTag returns a short string identifying the type of statement.
 func (*Import) StatementTag() string { return "IMPORT" }
 
+// StatementType implements the Statement interface.
+func (*Listen) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Listen) StatementTag() string { return "LISTEN" }
+
+// StatementType implements the Statement interface.
+func (*Notify) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Notify) StatementTag() string { return "NOTIFY" }
+
 // StatementType implements the Statement interface.
 func (*ParenSelect) StatementType() StatementType { return Rows }
 
@@ -335,6 +507,12 @@ func (*Prepare) StatementTag() string { return "PREPARE" }
 
 func (*Prepare) hiddenFromStats() {}
 
+// StatementType implements the Statement interface.
+func (*ReassignOwnedBy) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ReassignOwnedBy) StatementTag() string { return "REASSIGN OWNED" }
+
 // StatementType implements the Statement interface.
 func (*ReleaseSavepoint) StatementType() StatementType { return Ack }
 
@@ -402,6 +580,14 @@ func (*Revoke) StatementTag() string { return "REVOKE" }
 
 func (*Revoke) hiddenFromStats() {}
 
+// StatementType implements the Statement interface.
+func (*RevokeRole) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*RevokeRole) StatementTag() string { return "REVOKE" }
+
+func (*RevokeRole) hiddenFromStats() {}
+
 // StatementType implements the Statement interface.
 func (*RollbackToSavepoint) StatementType() StatementType { return Ack }
 
@@ -418,6 +604,12 @@ func (*RollbackTransaction) StatementTag() string { return "ROLLBACK" }
 
 func (*RollbackTransaction) hiddenFromStats() {}
 
+// StatementType implements the Statement interface.
+func (*RotateEncryptionKey) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*RotateEncryptionKey) StatementTag() string { return "ROTATE ENCRYPTION KEY" }
+
 // StatementType implements the Statement interface.
 func (*Savepoint) StatementType() StatementType { return Ack }
 
@@ -430,6 +622,14 @@ func (*Scatter) StatementType() StatementType { return Rows }
 // StatementTag returns a short string identifying the type of statement.
 func (*Scatter) StatementTag() string { return "SCATTER" }
 
+// StatementType implements the Statement interface.
+func (*ScheduledBackup) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ScheduledBackup) StatementTag() string { return "CREATE SCHEDULE FOR BACKUP" }
+
+func (*ScheduledBackup) hiddenFromShowQueries() {}
+
 // StatementType implements the Statement interface.
 func (*Scrub) StatementType() StatementType { return Rows }
 
@@ -529,6 +729,15 @@ func (*ShowCreateView) StatementTag() string { return "SHOW CREATE VIEW" }
 func (*ShowCreateView) hiddenFromStats()                   {}
 func (*ShowCreateView) independentFromParallelizedPriors() {}
 
+// StatementType implements the Statement interface.
+func (*ShowCreateSequence) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowCreateSequence) StatementTag() string { return "SHOW CREATE SEQUENCE" }
+
+func (*ShowCreateSequence) hiddenFromStats()                   {}
+func (*ShowCreateSequence) independentFromParallelizedPriors() {}
+
 // StatementType implements the Statement interface.
 func (*ShowBackup) StatementType() StatementType { return Rows }
 
@@ -547,6 +756,15 @@ func (*ShowDatabases) StatementTag() string { return "SHOW DATABASES" }
 func (*ShowDatabases) hiddenFromStats()                   {}
 func (*ShowDatabases) independentFromParallelizedPriors() {}
 
+// StatementType implements the Statement interface.
+func (*ShowDump) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowDump) StatementTag() string { return "SHOW DUMP" }
+
+func (*ShowDump) hiddenFromStats()                   {}
+func (*ShowDump) independentFromParallelizedPriors() {}
+
 // StatementType implements the Statement interface.
 func (*ShowTrace) StatementType() StatementType { return Rows }
 
@@ -591,6 +809,15 @@ func (*ShowJobs) StatementTag() string { return "SHOW JOBS" }
 func (*ShowJobs) hiddenFromStats()                   {}
 func (*ShowJobs) independentFromParallelizedPriors() {}
 
+// StatementType implements the Statement interface.
+func (*ShowSchedules) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowSchedules) StatementTag() string { return "SHOW SCHEDULES" }
+
+func (*ShowSchedules) hiddenFromStats()                   {}
+func (*ShowSchedules) independentFromParallelizedPriors() {}
+
 // StatementType implements the Statement interface.
 func (*ShowSessions) StatementType() StatementType { return Rows }
 
@@ -600,6 +827,15 @@ func (*ShowSessions) StatementTag() string { return "SHOW SESSIONS" }
 func (*ShowSessions) hiddenFromStats()                   {}
 func (*ShowSessions) independentFromParallelizedPriors() {}
 
+// StatementType implements the Statement interface.
+func (*ShowSyntax) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowSyntax) StatementTag() string { return "SHOW SYNTAX" }
+
+func (*ShowSyntax) hiddenFromStats()                   {}
+func (*ShowSyntax) independentFromParallelizedPriors() {}
+
 // StatementType implements the Statement interface.
 func (*ShowTransactionStatus) StatementType() StatementType { return Rows }
 
@@ -628,10 +864,18 @@ func (*ShowZoneConfig) StatementTag() string { return "SHOW ZONE CONFIGURATION"
 func (*ShowRanges) StatementType() StatementType { return Rows }
 
 // StatementTag returns a short string identifying the type of statement.
-func (*ShowRanges) StatementTag() string { return "SHOW TESTING_RANGES" }
+func (*ShowRanges) StatementTag() string { return "SHOW RANGES" }
 
 func (*ShowRanges) hiddenFromStats() {}
 
+// StatementType implements the Statement interface.
+func (*ShowRangeForRow) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowRangeForRow) StatementTag() string { return "SHOW RANGE" }
+
+func (*ShowRangeForRow) hiddenFromStats() {}
+
 // StatementType implements the Statement interface.
 func (*ShowFingerprints) StatementType() StatementType { return Rows }
 
@@ -682,94 +926,131 @@ func (*UnionClause) StatementType() StatementType { return Rows }
 // StatementTag returns a short string identifying the type of statement.
 func (*UnionClause) StatementTag() string { return "UNION" }
 
+// StatementType implements the Statement interface.
+func (*Unlisten) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Unlisten) StatementTag() string { return "UNLISTEN" }
+
 // StatementType implements the Statement interface.
 func (ValuesClause) StatementType() StatementType { return Rows }
 
 // StatementTag returns a short string identifying the type of statement.
 func (ValuesClause) StatementTag() string { return "VALUES" }
 
-func (n *AlterTable) String() string               { return AsString(n) }
-func (n AlterTableCmds) String() string            { return AsString(n) }
-func (n *AlterTableAddColumn) String() string      { return AsString(n) }
-func (n *AlterTableAddConstraint) String() string  { return AsString(n) }
-func (n *AlterTableDropColumn) String() string     { return AsString(n) }
-func (n *AlterTableDropConstraint) String() string { return AsString(n) }
-func (n *AlterTableDropNotNull) String() string    { return AsString(n) }
-func (n *AlterTableSetDefault) String() string     { return AsString(n) }
-func (n *AlterUserSetPassword) String() string     { return AsString(n) }
-func (n *AlterSequence) String() string            { return AsString(n) }
-func (n *Backup) String() string                   { return AsString(n) }
-func (n *BeginTransaction) String() string         { return AsString(n) }
-func (n *CancelJob) String() string                { return AsString(n) }
-func (n *CancelQuery) String() string              { return AsString(n) }
-func (n *CommitTransaction) String() string        { return AsString(n) }
-func (n *CopyFrom) String() string                 { return AsString(n) }
-func (n *CreateDatabase) String() string           { return AsString(n) }
-func (n *CreateIndex) String() string              { return AsString(n) }
-func (n *CreateTable) String() string              { return AsString(n) }
-func (n *CreateSequence) String() string           { return AsString(n) }
-func (n *CreateUser) String() string               { return AsString(n) }
-func (n *CreateView) String() string               { return AsString(n) }
-func (n *Deallocate) String() string               { return AsString(n) }
-func (n *Delete) String() string                   { return AsString(n) }
-func (n *DropDatabase) String() string             { return AsString(n) }
-func (n *DropIndex) String() string                { return AsString(n) }
-func (n *DropTable) String() string                { return AsString(n) }
-func (n *DropView) String() string                 { return AsString(n) }
-func (n *DropSequence) String() string             { return AsString(n) }
-func (n *DropUser) String() string                 { return AsString(n) }
-func (n *Execute) String() string                  { return AsString(n) }
-func (n *Explain) String() string                  { return AsString(n) }
-func (n *Grant) String() string                    { return AsString(n) }
-func (n *Insert) String() string                   { return AsString(n) }
-func (n *Import) String() string                   { return AsString(n) }
-func (n *ParenSelect) String() string              { return AsString(n) }
-func (n *PauseJob) String() string                 { return AsString(n) }
-func (n *Prepare) String() string                  { return AsString(n) }
-func (n *ReleaseSavepoint) String() string         { return AsString(n) }
-func (n *TestingRelocate) String() string          { return AsString(n) }
-func (n *RenameColumn) String() string             { return AsString(n) }
-func (n *RenameDatabase) String() string           { return AsString(n) }
-func (n *RenameIndex) String() string              { return AsString(n) }
-func (n *RenameTable) String() string              { return AsString(n) }
-func (n *Restore) String() string                  { return AsString(n) }
-func (n *ResumeJob) String() string                { return AsString(n) }
-func (n *Revoke) String() string                   { return AsString(n) }
-func (n *RollbackToSavepoint) String() string      { return AsString(n) }
-func (n *RollbackTransaction) String() string      { return AsString(n) }
-func (n *Savepoint) String() string                { return AsString(n) }
-func (n *Scatter) String() string                  { return AsString(n) }
-func (n *Scrub) String() string                    { return AsString(n) }
-func (n *Select) String() string                   { return AsString(n) }
-func (n *SelectClause) String() string             { return AsString(n) }
-func (n *SetClusterSetting) String() string        { return AsString(n) }
-func (n *SetZoneConfig) String() string            { return AsString(n) }
-func (n *SetDefaultIsolation) String() string      { return AsString(n) }
-func (n *SetTransaction) String() string           { return AsString(n) }
-func (n *SetVar) String() string                   { return AsString(n) }
-func (n *ShowBackup) String() string               { return AsString(n) }
-func (n *ShowClusterSetting) String() string       { return AsString(n) }
-func (n *ShowColumns) String() string              { return AsString(n) }
-func (n *ShowConstraints) String() string          { return AsString(n) }
-func (n *ShowCreateTable) String() string          { return AsString(n) }
-func (n *ShowCreateView) String() string           { return AsString(n) }
-func (n *ShowDatabases) String() string            { return AsString(n) }
-func (n *ShowGrants) String() string               { return AsString(n) }
-func (n *ShowIndex) String() string                { return AsString(n) }
-func (n *ShowJobs) String() string                 { return AsString(n) }
-func (n *ShowQueries) String() string              { return AsString(n) }
-func (n *ShowRanges) String() string               { return AsString(n) }
-func (n *ShowSessions) String() string             { return AsString(n) }
-func (n *ShowTables) String() string               { return AsString(n) }
-func (n *ShowTrace) String() string                { return AsString(n) }
-func (n *ShowTransactionStatus) String() string    { return AsString(n) }
-func (n *ShowUsers) String() string                { return AsString(n) }
-func (n *ShowVar) String() string                  { return AsString(n) }
-func (n *ShowZoneConfig) String() string           { return AsString(n) }
-func (n *ShowFingerprints) String() string         { return AsString(n) }
-func (n *Split) String() string                    { return AsString(n) }
-func (l StatementList) String() string             { return AsString(l) }
-func (n *Truncate) String() string                 { return AsString(n) }
-func (n *UnionClause) String() string              { return AsString(n) }
-func (n *Update) String() string                   { return AsString(n) }
-func (n *ValuesClause) String() string             { return AsString(n) }
+func (n *AlterTable) String() string                    { return AsString(n) }
+func (n AlterTableCmds) String() string                 { return AsString(n) }
+func (n *AlterTableAddColumn) String() string           { return AsString(n) }
+func (n *AlterTableAddConstraint) String() string       { return AsString(n) }
+func (n *AlterTableDropColumn) String() string          { return AsString(n) }
+func (n *AlterTableDropConstraint) String() string      { return AsString(n) }
+func (n *AlterTableDropNotNull) String() string         { return AsString(n) }
+func (n *AlterTableSetDefault) String() string          { return AsString(n) }
+func (n *AlterTableOwner) String() string               { return AsString(n) }
+func (n *AlterUserSetPassword) String() string          { return AsString(n) }
+func (n *AlterUserSetConnectionLimit) String() string   { return AsString(n) }
+func (n *AlterUserSetValidUntil) String() string        { return AsString(n) }
+func (n *AlterUserSetStatements) String() string        { return AsString(n) }
+func (n *AlterUserSetQuota) String() string             { return AsString(n) }
+func (n *AlterDatabaseOwner) String() string            { return AsString(n) }
+func (n *AlterDatabaseSetPrimaryRegion) String() string { return AsString(n) }
+func (n *AlterDatabaseAddRegion) String() string        { return AsString(n) }
+func (n *AlterDefaultPrivileges) String() string        { return AsString(n) }
+func (n *AlterSequence) String() string                 { return AsString(n) }
+func (n *Backup) String() string                        { return AsString(n) }
+func (n *BeginTransaction) String() string              { return AsString(n) }
+func (n *CancelJob) String() string                     { return AsString(n) }
+func (n *CancelQuery) String() string                   { return AsString(n) }
+func (n *CancelSession) String() string                 { return AsString(n) }
+func (n *CloseCursor) String() string                   { return AsString(n) }
+func (n *CommitTransaction) String() string             { return AsString(n) }
+func (n *CopyFrom) String() string                      { return AsString(n) }
+func (n *CopyTo) String() string                        { return AsString(n) }
+func (n *CreateChangefeed) String() string              { return AsString(n) }
+func (n *CreateDatabase) String() string                { return AsString(n) }
+func (n *CreateIndex) String() string                   { return AsString(n) }
+func (n *CreatePolicy) String() string                  { return AsString(n) }
+func (n *CreateTable) String() string                   { return AsString(n) }
+func (n *CreateSequence) String() string                { return AsString(n) }
+func (n *CreateUser) String() string                    { return AsString(n) }
+func (n *CreateView) String() string                    { return AsString(n) }
+func (n *Deallocate) String() string                    { return AsString(n) }
+func (n *DeclareCursor) String() string                 { return AsString(n) }
+func (n *Delete) String() string                        { return AsString(n) }
+func (n *DropDatabase) String() string                  { return AsString(n) }
+func (n *DropIndex) String() string                     { return AsString(n) }
+func (n *DropPolicy) String() string                    { return AsString(n) }
+func (n *DropTable) String() string                     { return AsString(n) }
+func (n *DropView) String() string                      { return AsString(n) }
+func (n *DropSequence) String() string                  { return AsString(n) }
+func (n *DropUser) String() string                      { return AsString(n) }
+func (n *Execute) String() string                       { return AsString(n) }
+func (n *Explain) String() string                       { return AsString(n) }
+func (n *Export) String() string                        { return AsString(n) }
+func (n *FetchCursor) String() string                   { return AsString(n) }
+func (n *Grant) String() string                         { return AsString(n) }
+func (n *GrantRole) String() string                     { return AsString(n) }
+func (n *Insert) String() string                        { return AsString(n) }
+func (n *Import) String() string                        { return AsString(n) }
+func (n *Listen) String() string                        { return AsString(n) }
+func (n *Notify) String() string                        { return AsString(n) }
+func (n *ParenSelect) String() string                   { return AsString(n) }
+func (n *PauseJob) String() string                      { return AsString(n) }
+func (n *Prepare) String() string                       { return AsString(n) }
+func (n *ReassignOwnedBy) String() string               { return AsString(n) }
+func (n *ReleaseSavepoint) String() string              { return AsString(n) }
+func (n *TestingRelocate) String() string               { return AsString(n) }
+func (n *RenameColumn) String() string                  { return AsString(n) }
+func (n *RenameDatabase) String() string                { return AsString(n) }
+func (n *RenameIndex) String() string                   { return AsString(n) }
+func (n *RenameTable) String() string                   { return AsString(n) }
+func (n *Restore) String() string                       { return AsString(n) }
+func (n *ResumeJob) String() string                     { return AsString(n) }
+func (n *Revoke) String() string                        { return AsString(n) }
+func (n *RevokeRole) String() string                    { return AsString(n) }
+func (n *RollbackToSavepoint) String() string           { return AsString(n) }
+func (n *RollbackTransaction) String() string           { return AsString(n) }
+func (n *RotateEncryptionKey) String() string           { return AsString(n) }
+func (n *Savepoint) String() string                     { return AsString(n) }
+func (n *Scatter) String() string                       { return AsString(n) }
+func (n *ScheduledBackup) String() string               { return AsString(n) }
+func (n *Scrub) String() string                         { return AsString(n) }
+func (n *Select) String() string                        { return AsString(n) }
+func (n *SelectClause) String() string                  { return AsString(n) }
+func (n *SetClusterSetting) String() string             { return AsString(n) }
+func (n *SetZoneConfig) String() string                 { return AsString(n) }
+func (n *SetDefaultIsolation) String() string           { return AsString(n) }
+func (n *SetTransaction) String() string                { return AsString(n) }
+func (n *SetVar) String() string                        { return AsString(n) }
+func (n *ShowBackup) String() string                    { return AsString(n) }
+func (n *ShowClusterSetting) String() string            { return AsString(n) }
+func (n *ShowColumns) String() string                   { return AsString(n) }
+func (n *ShowConstraints) String() string               { return AsString(n) }
+func (n *ShowCreateTable) String() string               { return AsString(n) }
+func (n *ShowCreateView) String() string                { return AsString(n) }
+func (n *ShowCreateSequence) String() string            { return AsString(n) }
+func (n *ShowDatabases) String() string                 { return AsString(n) }
+func (n *ShowDump) String() string                      { return AsString(n) }
+func (n *ShowGrants) String() string                    { return AsString(n) }
+func (n *ShowIndex) String() string                     { return AsString(n) }
+func (n *ShowJobs) String() string                      { return AsString(n) }
+func (n *ShowQueries) String() string                   { return AsString(n) }
+func (n *ShowRangeForRow) String() string               { return AsString(n) }
+func (n *ShowRanges) String() string                    { return AsString(n) }
+func (n *ShowSchedules) String() string                 { return AsString(n) }
+func (n *ShowSessions) String() string                  { return AsString(n) }
+func (n *ShowSyntax) String() string                    { return AsString(n) }
+func (n *ShowTables) String() string                    { return AsString(n) }
+func (n *ShowTrace) String() string                     { return AsString(n) }
+func (n *ShowTransactionStatus) String() string         { return AsString(n) }
+func (n *ShowUsers) String() string                     { return AsString(n) }
+func (n *ShowVar) String() string                       { return AsString(n) }
+func (n *ShowZoneConfig) String() string                { return AsString(n) }
+func (n *ShowFingerprints) String() string              { return AsString(n) }
+func (n *Split) String() string                         { return AsString(n) }
+func (l StatementList) String() string                  { return AsString(l) }
+func (n *Truncate) String() string                      { return AsString(n) }
+func (n *UnionClause) String() string                   { return AsString(n) }
+func (n *Unlisten) String() string                      { return AsString(n) }
+func (n *Update) String() string                        { return AsString(n) }
+func (n *ValuesClause) String() string                  { return AsString(n) }
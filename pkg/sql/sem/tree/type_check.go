@@ -43,6 +43,10 @@ type SemaContext struct {
 	// already.
 	SearchPath SearchPath
 
+	// FunctionUsed, if set, is called with the name of the function of each
+	// function call resolved during type checking.
+	FunctionUsed func(name string)
+
 	// privileged, if true, enables "unsafe" builtins, e.g. those
 	// from the crdb_internal namespace. Must be set only for
 	// the root user.
@@ -507,6 +511,10 @@ func (expr *FuncExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr, e
 		return nil, pgerror.NewErrorf(pgerror.CodeAmbiguousFunctionError, "ambiguous call: %s, candidates are:\n%s", sig, fnsStr)
 	}
 
+	if ctx != nil && ctx.FunctionUsed != nil {
+		ctx.FunctionUsed(def.Name)
+	}
+
 	if expr.WindowDef != nil {
 		for i, partition := range expr.WindowDef.Partitions {
 			typedPartition, err := partition.TypeCheck(ctx, types.Any)
@@ -634,6 +634,7 @@ func walkReturningClause(v Visitor, clause ReturningClause) (ReturningClause, bo
 // CopyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *Backup) CopyNode() *Backup {
 	stmtCopy := *stmt
+	stmtCopy.To = append(PartitionedBackup(nil), stmt.To...)
 	stmtCopy.IncrementalFrom = append(Exprs(nil), stmt.IncrementalFrom...)
 	stmtCopy.Options = append(KVOptions(nil), stmt.Options...)
 	return &stmtCopy
@@ -651,13 +652,13 @@ func (stmt *Backup) WalkStmt(v Visitor) Statement {
 			ret.AsOf.Expr = e
 		}
 	}
-	{
-		e, changed := WalkExpr(v, stmt.To)
+	for i, expr := range stmt.To {
+		e, changed := WalkExpr(v, expr)
 		if changed {
 			if ret == stmt {
 				ret = stmt.CopyNode()
 			}
-			ret.To = e
+			ret.To[i] = e
 		}
 	}
 	for i, expr := range stmt.IncrementalFrom {
@@ -681,6 +682,35 @@ func (stmt *Backup) WalkStmt(v Visitor) Statement {
 	return ret
 }
 
+// CopyNode makes a copy of this Statement without recursing in any child Statements.
+func (stmt *CreateChangefeed) CopyNode() *CreateChangefeed {
+	stmtCopy := *stmt
+	stmtCopy.Options = append(KVOptions(nil), stmt.Options...)
+	return &stmtCopy
+}
+
+// WalkStmt is part of the WalkableStmt interface.
+func (stmt *CreateChangefeed) WalkStmt(v Visitor) Statement {
+	ret := stmt
+	if stmt.SinkURI != nil {
+		e, changed := WalkExpr(v, stmt.SinkURI)
+		if changed {
+			ret = stmt.CopyNode()
+			ret.SinkURI = e
+		}
+	}
+	{
+		opts, changed := walkKVOptions(v, stmt.Options)
+		if changed {
+			if ret == stmt {
+				ret = stmt.CopyNode()
+			}
+			ret.Options = opts
+		}
+	}
+	return ret
+}
+
 // CopyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *Delete) CopyNode() *Delete {
 	stmtCopy := *stmt
@@ -756,6 +786,44 @@ func (stmt *Insert) WalkStmt(v Visitor) Statement {
 	return ret
 }
 
+// CopyNode makes a copy of this Statement without recursing in any child Statements.
+func (stmt *Export) CopyNode() *Export {
+	stmtCopy := *stmt
+	stmtCopy.Options = append(KVOptions(nil), stmt.Options...)
+	return &stmtCopy
+}
+
+// WalkStmt is part of the WalkableStmt interface.
+func (stmt *Export) WalkStmt(v Visitor) Statement {
+	ret := stmt
+	if stmt.Query != nil {
+		s, changed := WalkStmt(v, stmt.Query)
+		if changed {
+			ret = stmt.CopyNode()
+			ret.Query = s.(*Select)
+		}
+	}
+	if stmt.File != nil {
+		e, changed := WalkExpr(v, stmt.File)
+		if changed {
+			if ret == stmt {
+				ret = stmt.CopyNode()
+			}
+			ret.File = e
+		}
+	}
+	{
+		opts, changed := walkKVOptions(v, stmt.Options)
+		if changed {
+			if ret == stmt {
+				ret = stmt.CopyNode()
+			}
+			ret.Options = opts
+		}
+	}
+	return ret
+}
+
 // CopyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *Import) CopyNode() *Import {
 	stmtCopy := *stmt
@@ -809,7 +877,10 @@ func (stmt *ParenSelect) WalkStmt(v Visitor) Statement {
 // CopyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *Restore) CopyNode() *Restore {
 	stmtCopy := *stmt
-	stmtCopy.From = append(Exprs(nil), stmt.From...)
+	stmtCopy.From = make(PartitionedBackups, len(stmt.From))
+	for i, b := range stmt.From {
+		stmtCopy.From[i] = append(PartitionedBackup(nil), b...)
+	}
 	stmtCopy.Options = append(KVOptions(nil), stmt.Options...)
 	return &stmtCopy
 }
@@ -826,13 +897,15 @@ func (stmt *Restore) WalkStmt(v Visitor) Statement {
 			ret.AsOf.Expr = e
 		}
 	}
-	for i, expr := range stmt.From {
-		e, changed := WalkExpr(v, expr)
-		if changed {
-			if ret == stmt {
-				ret = stmt.CopyNode()
+	for i, backup := range stmt.From {
+		for j, expr := range backup {
+			e, changed := WalkExpr(v, expr)
+			if changed {
+				if ret == stmt {
+					ret = stmt.CopyNode()
+				}
+				ret.From[i][j] = e
 			}
-			ret.From[i] = e
 		}
 	}
 	{
@@ -871,6 +944,67 @@ func walkOrderBy(v Visitor, order OrderBy) (OrderBy, bool) {
 	return order, copied
 }
 
+// CopyNode makes a copy of this Statement without recursing in any child Statements.
+func (stmt *ScheduledBackup) CopyNode() *ScheduledBackup {
+	stmtCopy := *stmt
+	if stmt.FullBackup != nil {
+		fullBackup := *stmt.FullBackup
+		stmtCopy.FullBackup = &fullBackup
+	}
+	stmtCopy.To = append(PartitionedBackup(nil), stmt.To...)
+	stmtCopy.BackupOptions = append(KVOptions(nil), stmt.BackupOptions...)
+	return &stmtCopy
+}
+
+// WalkStmt is part of the WalkableStmt interface.
+func (stmt *ScheduledBackup) WalkStmt(v Visitor) Statement {
+	ret := stmt
+	if stmt.ScheduleLabel != nil {
+		e, changed := WalkExpr(v, stmt.ScheduleLabel)
+		if changed {
+			ret = stmt.CopyNode()
+			ret.ScheduleLabel = e
+		}
+	}
+	{
+		e, changed := WalkExpr(v, stmt.Recurrence)
+		if changed {
+			if ret == stmt {
+				ret = stmt.CopyNode()
+			}
+			ret.Recurrence = e
+		}
+	}
+	if stmt.FullBackup != nil && stmt.FullBackup.Recurrence != nil {
+		e, changed := WalkExpr(v, stmt.FullBackup.Recurrence)
+		if changed {
+			if ret == stmt {
+				ret = stmt.CopyNode()
+			}
+			ret.FullBackup.Recurrence = e
+		}
+	}
+	for i, expr := range stmt.To {
+		e, changed := WalkExpr(v, expr)
+		if changed {
+			if ret == stmt {
+				ret = stmt.CopyNode()
+			}
+			ret.To[i] = e
+		}
+	}
+	{
+		opts, changed := walkKVOptions(v, stmt.BackupOptions)
+		if changed {
+			if ret == stmt {
+				ret = stmt.CopyNode()
+			}
+			ret.BackupOptions = opts
+		}
+	}
+	return ret
+}
+
 // CopyNode makes a copy of this Statement without recursing in any child Statements.
 func (stmt *Select) CopyNode() *Select {
 	stmtCopy := *stmt
@@ -1121,12 +1255,15 @@ func (stmt *ValuesClause) WalkStmt(v Visitor) Statement {
 }
 
 var _ WalkableStmt = &Backup{}
+var _ WalkableStmt = &CreateChangefeed{}
 var _ WalkableStmt = &Delete{}
 var _ WalkableStmt = &Explain{}
+var _ WalkableStmt = &Export{}
 var _ WalkableStmt = &Insert{}
 var _ WalkableStmt = &Import{}
 var _ WalkableStmt = &ParenSelect{}
 var _ WalkableStmt = &Restore{}
+var _ WalkableStmt = &ScheduledBackup{}
 var _ WalkableStmt = &Select{}
 var _ WalkableStmt = &SelectClause{}
 var _ WalkableStmt = &SetClusterSetting{}
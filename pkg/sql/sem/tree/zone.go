@@ -64,8 +64,7 @@ func (node ZoneSpecifier) Format(buf *bytes.Buffer, f FmtFlags) {
 
 func (node ZoneSpecifier) String() string { return AsString(node) }
 
-// ShowZoneConfig represents an EXPERIMENTAL SHOW ZONE CONFIGURATION...
-// statement.
+// ShowZoneConfig represents a SHOW ZONE CONFIGURATION... statement.
 type ShowZoneConfig struct {
 	ZoneSpecifier
 }
@@ -73,24 +72,58 @@ type ShowZoneConfig struct {
 // Format implements the NodeFormatter interface.
 func (node *ShowZoneConfig) Format(buf *bytes.Buffer, f FmtFlags) {
 	if node.ZoneSpecifier == (ZoneSpecifier{}) {
-		buf.WriteString("EXPERIMENTAL SHOW ZONE CONFIGURATIONS")
+		buf.WriteString("SHOW ZONE CONFIGURATIONS")
 	} else {
-		buf.WriteString("EXPERIMENTAL SHOW ZONE CONFIGURATION FOR ")
+		buf.WriteString("SHOW ZONE CONFIGURATION FOR ")
 		FormatNode(buf, f, node.ZoneSpecifier)
 	}
 }
 
-// SetZoneConfig represents an ALTER DATABASE/TABLE... EXPERIMENTAL CONFIGURE
-// ZONE statement.
+// ZoneConfigSetting is a field of a zone config set by an ALTER ... CONFIGURE
+// ZONE USING statement, e.g. num_replicas = 3.
+type ZoneConfigSetting struct {
+	Key   UnresolvedName
+	Value Expr
+}
+
+// ZoneConfigSettings is a list of zone config fields and their values.
+type ZoneConfigSettings []ZoneConfigSetting
+
+// Format implements the NodeFormatter interface.
+func (node ZoneConfigSettings) Format(buf *bytes.Buffer, f FmtFlags) {
+	for i, s := range node {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		FormatNode(buf, f, s.Key)
+		buf.WriteString(" = ")
+		FormatNode(buf, f, s.Value)
+	}
+}
+
+// SetZoneConfig represents an ALTER DATABASE/TABLE... CONFIGURE ZONE
+// statement. Exactly one of YAMLConfig, set by EXPERIMENTAL CONFIGURE ZONE,
+// Settings, set by CONFIGURE ZONE USING, and Discard, set by CONFIGURE ZONE
+// DISCARD, is set.
 type SetZoneConfig struct {
 	ZoneSpecifier
 	YAMLConfig Expr
+	Settings   ZoneConfigSettings
+	Discard    bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *SetZoneConfig) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("ALTER ")
 	FormatNode(buf, f, node.ZoneSpecifier)
-	buf.WriteString(" EXPERIMENTAL CONFIGURE ZONE ")
-	FormatNode(buf, f, node.YAMLConfig)
+	switch {
+	case node.Discard:
+		buf.WriteString(" CONFIGURE ZONE DISCARD")
+	case node.Settings != nil:
+		buf.WriteString(" CONFIGURE ZONE USING ")
+		FormatNode(buf, f, node.Settings)
+	default:
+		buf.WriteString(" EXPERIMENTAL CONFIGURE ZONE ")
+		FormatNode(buf, f, node.YAMLConfig)
+	}
 }
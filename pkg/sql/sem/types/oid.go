@@ -242,6 +242,25 @@ func (t TOidWrapper) String() string {
 // Oid implements the T interface.
 func (t TOidWrapper) Oid() oid.Oid { return t.oid }
 
+// oidWrapperSQLNames are the standard names of the Oids which differ from
+// those of the wrapped types.
+var oidWrapperSQLNames = map[oid.Oid]string{
+	oid.T_int2:       "smallint",
+	oid.T_int4:       "integer",
+	oid.T_float4:     "real",
+	oid.T_varchar:    "character varying",
+	oid.T_name:       "name",
+	oid.T_int2vector: "int2vector",
+}
+
+// SQLName implements the T interface.
+func (t TOidWrapper) SQLName() string {
+	if s, ok := oidWrapperSQLNames[t.oid]; ok {
+		return s
+	}
+	return t.T.SQLName()
+}
+
 // WrapTypeWithOid wraps a T with a custom Oid.
 func WrapTypeWithOid(t T, oid oid.Oid) T {
 	switch v := t.(type) {
@@ -0,0 +1,250 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// updateSequenceDependencies records the sequences used by the default
+// expression of a column in the column, and the back-references to the
+// column in the sequences. The modified sequence descriptors are added to
+// affected, to be saved by the caller.
+func (p *planner) updateSequenceDependencies(
+	ctx context.Context,
+	tableDesc *sqlbase.TableDescriptor,
+	col *sqlbase.ColumnDescriptor,
+	affected map[sqlbase.ID]*sqlbase.TableDescriptor,
+) error {
+	var seqIDs []sqlbase.ID
+	if col.DefaultExpr != nil {
+		names, err := getUsedSequenceNames(*col.DefaultExpr, p.semaCtx.SearchPath)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			seqDesc, err := p.resolveUsedSequence(ctx, name)
+			if err != nil {
+				return err
+			}
+			if !containsDescID(seqIDs, seqDesc.ID) {
+				seqIDs = append(seqIDs, seqDesc.ID)
+			}
+		}
+	}
+	if len(seqIDs) > 0 && col.ID == 0 {
+		// The column is being added. Its ID is allocated now to be recorded in
+		// the back-references.
+		col.ID = tableDesc.NextColumnID
+		tableDesc.NextColumnID++
+	}
+
+	for _, id := range col.UsesSequenceIDs {
+		if containsDescID(seqIDs, id) {
+			continue
+		}
+		if err := p.modifySequenceDependedOnBy(ctx, id, affected, func(seqDesc *sqlbase.TableDescriptor) {
+			removeSequenceReference(seqDesc, tableDesc.ID, col.ID)
+		}); err != nil {
+			return err
+		}
+	}
+	for _, id := range seqIDs {
+		if containsDescID(col.UsesSequenceIDs, id) {
+			continue
+		}
+		if err := p.modifySequenceDependedOnBy(ctx, id, affected, func(seqDesc *sqlbase.TableDescriptor) {
+			addSequenceReference(seqDesc, tableDesc.ID, col.ID)
+		}); err != nil {
+			return err
+		}
+	}
+	col.UsesSequenceIDs = seqIDs
+	return nil
+}
+
+// removeSequenceDependencies removes the back-references to a column from
+// the sequences used by its default expression. The modified sequence
+// descriptors are added to affected, to be saved by the caller.
+func (p *planner) removeSequenceDependencies(
+	ctx context.Context,
+	tableDesc *sqlbase.TableDescriptor,
+	col *sqlbase.ColumnDescriptor,
+	affected map[sqlbase.ID]*sqlbase.TableDescriptor,
+) error {
+	for _, id := range col.UsesSequenceIDs {
+		if err := p.modifySequenceDependedOnBy(ctx, id, affected, func(seqDesc *sqlbase.TableDescriptor) {
+			removeSequenceReference(seqDesc, tableDesc.ID, col.ID)
+		}); err != nil {
+			return err
+		}
+	}
+	col.UsesSequenceIDs = nil
+	return nil
+}
+
+// modifySequenceDependedOnBy applies fn to the descriptor of a sequence,
+// unless the sequence is being dropped, and adds it to affected.
+func (p *planner) modifySequenceDependedOnBy(
+	ctx context.Context,
+	seqID sqlbase.ID,
+	affected map[sqlbase.ID]*sqlbase.TableDescriptor,
+	fn func(seqDesc *sqlbase.TableDescriptor),
+) error {
+	seqDesc, ok := affected[seqID]
+	if !ok {
+		var err error
+		seqDesc, err = sqlbase.GetTableDescFromID(ctx, p.txn, seqID)
+		if err != nil {
+			return err
+		}
+		if seqDesc.Dropped() {
+			// The sequence is being dropped. No need to modify it further.
+			return nil
+		}
+		affected[seqID] = seqDesc
+	}
+	fn(seqDesc)
+	return nil
+}
+
+// resolveUsedSequence returns the descriptor of a sequence named in a
+// default expression, resolved like nextval() resolves it.
+func (p *planner) resolveUsedSequence(
+	ctx context.Context, name string,
+) (*sqlbase.TableDescriptor, error) {
+	parsed, err := parser.ParseTableNameWithIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	tn, err := p.QualifyWithDatabase(ctx, &parsed.Table)
+	if err != nil {
+		return nil, err
+	}
+	seqDesc, err := getSequenceDesc(ctx, p.txn, p.getVirtualTabler(), tn)
+	if err != nil {
+		return nil, err
+	}
+	if seqDesc == nil {
+		return nil, sqlbase.NewUndefinedRelationError(tn)
+	}
+	return seqDesc, nil
+}
+
+// getUsedSequenceNames returns the names of the sequences given as constant
+// arguments to nextval() in a default expression.
+func getUsedSequenceNames(defaultExpr string, searchPath tree.SearchPath) ([]string, error) {
+	expr, err := parser.ParseExpr(defaultExpr)
+	if err != nil {
+		return nil, err
+	}
+	v := sequenceNamesVisitor{searchPath: searchPath}
+	tree.WalkExprConst(&v, expr)
+	return v.names, nil
+}
+
+// sequenceNamesVisitor is a tree.Visitor collecting the names of the
+// sequences given as constant arguments to nextval().
+type sequenceNamesVisitor struct {
+	searchPath tree.SearchPath
+	names      []string
+}
+
+var _ tree.Visitor = &sequenceNamesVisitor{}
+
+func (v *sequenceNamesVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	f, ok := expr.(*tree.FuncExpr)
+	if !ok || len(f.Exprs) != 1 {
+		return true, expr
+	}
+	def, err := f.Func.Resolve(v.searchPath)
+	if err != nil || def.Name != "nextval" {
+		return true, expr
+	}
+	arg := f.Exprs[0]
+	if a, ok := arg.(*tree.AnnotateTypeExpr); ok {
+		arg = a.Expr
+	}
+	switch t := arg.(type) {
+	case *tree.StrVal:
+		v.names = append(v.names, t.RawString())
+	case *tree.DString:
+		v.names = append(v.names, string(*t))
+	}
+	return true, expr
+}
+
+func (v *sequenceNamesVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// addSequenceReference adds a back-reference to a column of a table to the
+// descriptor of a sequence.
+func addSequenceReference(
+	seqDesc *sqlbase.TableDescriptor, tableID sqlbase.ID, colID sqlbase.ColumnID,
+) {
+	for i := range seqDesc.DependedOnBy {
+		ref := &seqDesc.DependedOnBy[i]
+		if ref.ID != tableID {
+			continue
+		}
+		for _, id := range ref.ColumnIDs {
+			if id == colID {
+				return
+			}
+		}
+		ref.ColumnIDs = append(ref.ColumnIDs, colID)
+		return
+	}
+	seqDesc.DependedOnBy = append(seqDesc.DependedOnBy, sqlbase.TableDescriptor_Reference{
+		ID:        tableID,
+		ColumnIDs: []sqlbase.ColumnID{colID},
+	})
+}
+
+// removeSequenceReference removes the back-reference to a column of a table
+// from the descriptor of a sequence.
+func removeSequenceReference(
+	seqDesc *sqlbase.TableDescriptor, tableID sqlbase.ID, colID sqlbase.ColumnID,
+) {
+	refs := seqDesc.DependedOnBy[:0]
+	for _, ref := range seqDesc.DependedOnBy {
+		if ref.ID == tableID {
+			colIDs := ref.ColumnIDs[:0]
+			for _, id := range ref.ColumnIDs {
+				if id != colID {
+					colIDs = append(colIDs, id)
+				}
+			}
+			if len(colIDs) == 0 {
+				continue
+			}
+			ref.ColumnIDs = colIDs
+		}
+		refs = append(refs, ref)
+	}
+	seqDesc.DependedOnBy = refs
+}
+
+func containsDescID(ids []sqlbase.ID, id sqlbase.ID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -192,6 +192,9 @@ type Session struct {
 	// Session parameters, user-configurable.
 	//
 
+	// CountFromRangeStats controls whether unfiltered COUNT(*) queries may
+	// be answered from range MVCC statistics instead of a scan.
+	CountFromRangeStats countRangeStatsMode
 	// Database indicates the "current" database for the purpose of
 	// resolving names. See searchAndQualifyDatabase() for details.
 	Database string
@@ -212,6 +215,29 @@ type Session struct {
 	// SafeUpdates causes errors when the client
 	// sends syntax that may have unwanted side effects.
 	SafeUpdates bool
+	// ParallelExecution allows independent stages of a locally-executed
+	// plan to run concurrently.
+	ParallelExecution bool
+	// QualityOfService is the priority class of the session's statements when
+	// they wait for admission on a saturated node.
+	QualityOfService qualityOfService
+	// TraceExport controls the export of the traces of the session's
+	// statements to the configured trace collector.
+	TraceExport traceExportMode
+	// StatementTimeout, StatementMaxRowsRead and StatementMaxMemory limit the
+	// resources used by each statement; zero means no limit. The memory limit
+	// applies to the transaction monitor, which only holds the memory of the
+	// statement currently executing, and is picked up when a transaction
+	// starts.
+	StatementTimeout     time.Duration
+	StatementMaxRowsRead int64
+	StatementMaxMemory   int64
+	// MutationChunkSize, if positive, is the number of rows after which INSERT
+	// and UPDATE send their pending writes. See mutation_chunks.go.
+	MutationChunkSize int64
+	// compatVars holds the values SET for variables that are accepted for
+	// client compatibility but have no effect. See makeCompatVar.
+	compatVars map[string]string
 
 	//
 	// Session parameters, non-user-configurable.
@@ -224,6 +250,17 @@ type Session struct {
 	// ClientAddr is the client's IP address and port.
 	ClientAddr string
 
+	// id identifies the session across the cluster. It is assigned by the
+	// SessionRegistry and is what pg_backend_pid() returns.
+	id int64
+
+	// statementRestrictions are the classes of statements the user may
+	// execute, checked before planning each statement.
+	statementRestrictions StatementRestrictions
+	// roleQuotas are the quotas the statements of the session are subject
+	// to.
+	roleQuotas []RoleQuota
+
 	//
 	// State structures for the logical SQL session.
 	//
@@ -322,6 +359,10 @@ type Session struct {
 		// statistics. Change via resetApplicationName().
 		ApplicationName string
 
+		// Database is the current database of the session, as Database. Change
+		// via setDatabase().
+		Database string
+
 		//
 		// State structures for the logical SQL session.
 		//
@@ -356,10 +397,29 @@ type Session struct {
 	sqlStats *sqlStats
 	// appStats track per-application SQL usage statistics.
 	appStats *appStats
+	// featureUsage counts the uses of the SQL features by the statements of
+	// all the sessions on each node.
+	featureUsage *featureUsage
+	// backgroundScrub holds the errors found by the background SCRUB checks
+	// run on each node.
+	backgroundScrub *backgroundScrubErrors
+	// indexUsage counts the reads and writes of the indexes by the statements
+	// of all the sessions on each node.
+	indexUsage *indexUsageStats
+	// stmtDiagnostics holds the pending statement diagnostics requests known
+	// to each node.
+	stmtDiagnostics *stmtDiagnosticsRegistry
+	// appNameTag is the value of the `appname` log tag of the session's
+	// context. Change via resetApplicationName().
+	appNameTag applicationNameTag
 	// phaseTimes tracks session-level phase times. It is copied-by-value
 	// to each planner in session.newPlanner.
 	phaseTimes phaseTimes
 
+	// notifications holds the channels the session listens to, with LISTEN,
+	// and the notifications it received on them.
+	notifications sessionNotifications
+
 	// noCopy is placed here to guarantee that Session objects are not
 	// copied.
 	//
@@ -379,6 +439,12 @@ type SessionArgs struct {
 	Database        string
 	User            string
 	ApplicationName string
+	// StatementRestrictions are the classes of statements the user may
+	// execute, as returned by GetUserStatementRestrictions.
+	StatementRestrictions StatementRestrictions
+	// RoleQuotas are the quotas the statements of the session are subject
+	// to, as returned by GetUserRoleQuotas.
+	RoleQuotas []RoleQuota
 }
 
 // SessionRegistry stores a set of all sessions on this node.
@@ -386,16 +452,30 @@ type SessionArgs struct {
 type SessionRegistry struct {
 	syncutil.Mutex
 	store map[*Session]struct{}
+	// lastID is the node-local part of the last assigned session ID.
+	lastID uint32
+	// numConns counts the client connections to this node of users other
+	// than root and conns those of each user, as reserved with
+	// ReserveConnection.
+	numConns int64
+	conns    map[string]int64
 }
 
 // MakeSessionRegistry creates a new SessionRegistry with an empty set
 // of sessions.
 func MakeSessionRegistry() *SessionRegistry {
-	return &SessionRegistry{store: make(map[*Session]struct{})}
+	return &SessionRegistry{
+		store: make(map[*Session]struct{}),
+		conns: make(map[string]int64),
+	}
 }
 
 func (r *SessionRegistry) register(s *Session) {
 	r.Lock()
+	// Session IDs carry the node ID in their upper 32 bits, so that a
+	// cancellation request can be routed to the node owning the session.
+	r.lastID++
+	s.id = int64(s.execCfg.NodeID.Get())<<32 | int64(r.lastID)
 	r.store[s] = struct{}{}
 	r.Unlock()
 }
@@ -435,6 +515,52 @@ func (r *SessionRegistry) CancelQuery(queryIDStr string, username string) (bool,
 	return false, fmt.Errorf("query ID %s not found", queryID)
 }
 
+// CancelSession looks up the session with the given ID in the session registry
+// and cancels its active queries. If terminate is set, the session is closed
+// as well. It returns false if no such session exists.
+func (r *SessionRegistry) CancelSession(
+	sessionID int64, username string, terminate bool,
+) (bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	for session := range r.store {
+		if session.id != sessionID {
+			continue
+		}
+		if !(username == security.RootUser || username == session.User) {
+			return false, fmt.Errorf("permission denied: user %s does not own the session", username)
+		}
+
+		session.CancelActiveQueries()
+		if terminate {
+			// Cancelling the session context makes the connection exit the next
+			// time it waits for client input.
+			session.cancel()
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// SessionIDNodeID returns the ID of the node owning the session with the
+// given ID.
+func SessionIDNodeID(sessionID int64) roachpb.NodeID {
+	return roachpb.NodeID(sessionID >> 32)
+}
+
+// CancelActiveQueries cancels all the queries in flight on the session. It
+// returns false if there were none.
+func (s *Session) CancelActiveQueries() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, queryMeta := range s.mu.ActiveQueries {
+		queryMeta.cancel()
+	}
+	return len(s.mu.ActiveQueries) > 0
+}
+
 // SerializeAll returns a slice of all sessions in the registry, converted to serverpb.Sessions.
 func (r *SessionRegistry) SerializeAll() []serverpb.Session {
 	r.Lock()
@@ -458,17 +584,21 @@ func NewSession(
 	distSQLMode := DistSQLExecMode(DistSQLClusterExecMode.Get(&e.cfg.Settings.SV))
 
 	s := &Session{
-		Database:         args.Database,
-		DistSQLMode:      distSQLMode,
-		SearchPath:       sqlbase.DefaultSearchPath,
-		Location:         time.UTC,
-		User:             args.User,
-		virtualSchemas:   e.virtualSchemas,
-		execCfg:          &e.cfg,
-		distSQLPlanner:   e.distSQLPlanner,
-		parallelizeQueue: MakeParallelizeQueue(NewSpanBasedDependencyAnalyzer()),
-		memMetrics:       memMetrics,
-		sqlStats:         &e.sqlStats,
+		DistSQLMode:       distSQLMode,
+		ParallelExecution: true,
+		SearchPath:        sqlbase.DefaultSearchPath,
+		Location:          time.UTC,
+		User:              args.User,
+		virtualSchemas:    e.virtualSchemas,
+		execCfg:           &e.cfg,
+		distSQLPlanner:    e.distSQLPlanner,
+		parallelizeQueue:  MakeParallelizeQueue(NewSpanBasedDependencyAnalyzer()),
+		memMetrics:        memMetrics,
+		sqlStats:          &e.sqlStats,
+		featureUsage:      &e.featureUsage,
+		backgroundScrub:   &e.backgroundScrub,
+		indexUsage:        &e.indexUsage,
+		stmtDiagnostics:   &e.stmtDiagnostics,
 		defaults: sessionDefaults{
 			applicationName: args.ApplicationName,
 			database:        args.Database,
@@ -479,10 +609,14 @@ func NewSession(
 		},
 	}
 	s.phaseTimes[sessionInit] = timeutil.Now()
+	s.statementRestrictions = args.StatementRestrictions
+	s.roleQuotas = args.RoleQuotas
 	s.resetApplicationName(args.ApplicationName)
+	s.setDatabase(args.Database)
 	s.PreparedStatements = makePreparedStatements(s)
 	s.PreparedPortals = makePreparedPortals(s)
 	s.Tracing.session = s
+	s.notifications.registry = &e.notifications
 	s.mu.ActiveQueries = make(map[uint128.Uint128]*queryMeta)
 	s.ActiveSyncQueries = make([]uint128.Uint128, 0)
 
@@ -495,6 +629,7 @@ func NewSession(
 	if traceSessionEventLogEnabled.Get(&e.cfg.Settings.SV) {
 		s.eventLog = trace.NewEventLog(fmt.Sprintf("sql [%s]", args.User), remoteStr)
 	}
+	ctx = log.WithLogTag(ctx, "appname", &s.appNameTag)
 	s.context, s.cancel = contextutil.WithCancel(ctx)
 
 	e.cfg.SessionRegistry.register(s)
@@ -567,6 +702,7 @@ func (s *Session) Finish(e *Executor) {
 	}
 	// Clear this session from the sessions registry.
 	e.cfg.SessionRegistry.deregister(s)
+	s.notifications.unlistenAll()
 
 	// This will stop the heartbeating of the of the txn record.
 	// TODO(andrei): This shouldn't have any effect, since, if there was a
@@ -628,10 +764,16 @@ func (s *Session) resetPlanner(p *planner, e *Executor, txn *client.Txn) {
 	p.phaseTimes = s.phaseTimes
 	p.stmt = nil
 	p.cancelChecker = sqlbase.NewCancelChecker(s.Ctx())
+	p.rowsRead = 0
+	p.notices = nil
+	p.auditEvents = nil
+	p.features = nil
+	p.roles.populated, p.roles.user, p.roles.inherited, p.roles.err = false, "", nil, nil
 
 	p.semaCtx = tree.MakeSemaContext(s.User == security.RootUser)
 	p.semaCtx.Location = &s.Location
 	p.semaCtx.SearchPath = s.SearchPath
+	p.semaCtx.FunctionUsed = p.noteBuiltin
 
 	p.evalCtx = s.evalCtx()
 	p.evalCtx.Planner = p
@@ -823,10 +965,13 @@ func (s *Session) serialize() serverpb.Session {
 	defer s.TxnState.mu.RUnlock()
 
 	var kvTxnID *uuid.UUID
+	var txnStart *time.Time
 	txn := s.TxnState.mu.txn
 	if txn != nil {
 		id := txn.ID()
 		kvTxnID = &id
+		start := s.TxnState.mu.txnStart.UTC()
+		txnStart = &start
 	}
 
 	activeQueries := make([]serverpb.ActiveQuery, 0, len(s.mu.ActiveQueries))
@@ -847,12 +992,14 @@ func (s *Session) serialize() serverpb.Session {
 
 	for id, query := range s.mu.ActiveQueries {
 		sql := truncateSQL(query.stmt.String())
+		fingerprint := truncateSQL(tree.StatementFingerprint(query.stmt))
 		activeQueries = append(activeQueries, serverpb.ActiveQuery{
 			ID:            id.String(),
 			Start:         query.start.UTC(),
 			Sql:           sql,
 			IsDistributed: query.isDistributed,
 			Phase:         (serverpb.ActiveQuery_Phase)(query.phase),
+			Fingerprint:   fingerprint,
 		})
 	}
 	lastActiveQuery := ""
@@ -861,6 +1008,7 @@ func (s *Session) serialize() serverpb.Session {
 	}
 
 	return serverpb.Session{
+		ID:              s.id,
 		Username:        s.User,
 		ClientAddress:   s.ClientAddr,
 		ApplicationName: s.mu.ApplicationName,
@@ -868,9 +1016,21 @@ func (s *Session) serialize() serverpb.Session {
 		ActiveQueries:   activeQueries,
 		KvTxnID:         kvTxnID,
 		LastActiveQuery: lastActiveQuery,
+		TxnStart:        txnStart,
+		AllocBytes:      s.mon.AllocBytes(),
+		MaxAllocBytes:   s.mon.MaximumBytes(),
+		Database:        s.mu.Database,
 	}
 }
 
+// setDatabase changes the current database of the session.
+func (s *Session) setDatabase(dbName string) {
+	s.Database = dbName
+	s.mu.Lock()
+	s.mu.Database = dbName
+	s.mu.Unlock()
+}
+
 // TxnStateEnum represents the state of a SQL txn.
 type TxnStateEnum int64
 
@@ -952,6 +1112,9 @@ type txnState struct {
 		syncutil.RWMutex
 
 		txn *client.Txn
+
+		// txnStart is the time at which the current SQL transaction started.
+		txnStart time.Time
 	}
 
 	// If we're in a SQL txn, txnResults is the ResultsGroup that statements in
@@ -982,6 +1145,13 @@ type txnState struct {
 	// The schema change closures to run when this txn is done.
 	schemaChangers schemaChangerCollection
 
+	// cursors are the cursors declared in this txn, by name.
+	cursors map[tree.Name]*sqlCursor
+
+	// notifications are the notifications sent in this txn with NOTIFY, which
+	// are published when it commits.
+	notifications []Notification
+
 	sp opentracing.Span
 
 	// The timestamp to report for current_timestamp(), now() etc.
@@ -1066,13 +1236,17 @@ func (ts *txnState) resetForNewSQLTxn(
 		opName = sqlTxnName
 	}
 
+	opts := []opentracing.StartSpanOption{tracing.Recordable}
+	if sampling := s.txnSpanSampling(); sampling != nil {
+		opts = append(opts, sampling)
+	}
 	if parentSp := opentracing.SpanFromContext(ctx); parentSp != nil {
 		// Create a child span for this SQL txn.
 		sp = parentSp.Tracer().StartSpan(
-			opName, opentracing.ChildOf(parentSp.Context()), tracing.Recordable)
+			opName, append(opts, opentracing.ChildOf(parentSp.Context()))...)
 	} else {
 		// Create a root span for this SQL txn.
-		sp = tracer.StartSpan(opName, tracing.Recordable)
+		sp = tracer.StartSpan(opName, opts...)
 	}
 
 	// Start recording for the traceTxnThreshold and debugTrace7881Enabled
@@ -1104,10 +1278,12 @@ func (ts *txnState) resetForNewSQLTxn(
 	ts.SetState(AutoRetry)
 	s.Tracing.onNewSQLTxn(ts.sp)
 
+	ts.mon = mon.MakeMonitorInheritWithLimit("txn", s.StatementMaxMemory, &ts.mon)
 	ts.mon.Start(ctx, &s.mon, mon.BoundAccount{})
 
 	ts.mu.Lock()
 	ts.mu.txn = client.NewTxn(e.cfg.DB, e.cfg.NodeID.Get())
+	ts.mu.txnStart = sqlTimestamp
 	ts.mu.Unlock()
 	if ts.implicitTxn {
 		ts.mu.txn.SetDebugName(sqlImplicitTxnName)
@@ -1142,6 +1318,8 @@ func (ts *txnState) resetStateAndTxn(state TxnStateEnum) {
 			"attempting to move SQL txn to state %s inconsistent with KV txn state: %s "+
 				"(finalized: false)", state, ts.mu.txn.Proto().Status))
 	}
+	ts.closeCursors()
+	ts.notifications = nil
 	ts.SetState(state)
 	ts.mu.Lock()
 	ts.mu.txn = nil
@@ -1152,6 +1330,8 @@ func (ts *txnState) resetStateAndTxn(state TxnStateEnum) {
 // the current SQL txn. This needs to be called before resetForNewSQLTxn() is
 // called for starting another SQL txn.
 func (ts *txnState) finishSQLTxn(s *Session) {
+	ts.closeCursors()
+	ts.notifications = nil
 	ts.mon.Stop(ts.Ctx)
 	if ts.cancel != nil {
 		ts.cancel()
@@ -1246,6 +1426,9 @@ func (ts *txnState) updateStateAndCleanupOnErr(err error, e *Executor) error {
 		// If we got a retriable error, move the SQL txn to the RestartWait state.
 		// Note that TransactionAborted is also a retriable error, handled here;
 		// in this case cleanup for the txn has been done for us under the hood.
+		// Cursors and notifications don't survive a retry.
+		ts.closeCursors()
+		ts.notifications = nil
 		ts.SetState(RestartWait)
 		ts.mu.txn.ResetDeadline()
 	}
@@ -1353,6 +1536,11 @@ func (scc *schemaChangerCollection) execSchemaChanges(
 					// retryable error.
 					continue
 				}
+			} else if sc.nextMutationID != sqlbase.InvalidMutationID {
+				// The schema change queued another one, which is run right away.
+				sc.mutationID, sc.nextMutationID = sc.nextMutationID, sqlbase.InvalidMutationID
+				r.Reset()
+				continue
 			}
 			break
 		}
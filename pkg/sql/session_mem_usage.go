@@ -50,6 +50,16 @@ func (w WrappedMemoryAccount) OpenAndInit(ctx context.Context, initialAllocation
 	return w.mon.OpenAndInitAccount(ctx, w.acc, initialAllocation)
 }
 
+// Grow interfaces between Session and mon.MemoryMonitor.
+func (w WrappedMemoryAccount) Grow(ctx context.Context, extraSize int64) error {
+	return w.mon.GrowAccount(ctx, w.acc, extraSize)
+}
+
+// Shrink interfaces between Session and mon.MemoryMonitor.
+func (w WrappedMemoryAccount) Shrink(ctx context.Context, delta int64) {
+	w.mon.ShrinkAccount(ctx, w.acc, delta)
+}
+
 // Close interfaces between Session and mon.MemoryMonitor.
 func (w WrappedMemoryAccount) Close(ctx context.Context) {
 	w.mon.CloseAccount(ctx, w.acc)
@@ -37,6 +37,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
@@ -303,3 +305,48 @@ CREATE TABLE t.test (k INT PRIMARY KEY, v TEXT);
 			"the EndTransaction with the expected key")
 	}
 }
+
+// Test that the messages logged on behalf of a session are tagged with its
+// current application name.
+func TestApplicationNameLogTag(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const stmt = `SELECT 'log tag'`
+	var mu syncutil.Mutex
+	var msgs []string
+	params, _ := tests.CreateTestServerParams()
+	params.Knobs.SQLExecutor = &sql.ExecutorTestingKnobs{
+		StatementFilter: func(ctx context.Context, stmtStr string, _ sql.ResultsWriter, _ error) error {
+			if stmtStr == stmt {
+				mu.Lock()
+				msgs = append(msgs, log.MakeMessage(ctx, "", nil))
+				mu.Unlock()
+			}
+			return nil
+		},
+	}
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+	// The application name is a session variable.
+	sqlDB.SetMaxOpenConns(1)
+
+	for _, appName := range []string{"app1", "app2"} {
+		if _, err := sqlDB.Exec(`SET application_name = $1`, appName); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %q", msgs)
+	}
+	for i, appName := range []string{"app1", "app2"} {
+		if tag := "appname=" + appName; !strings.Contains(msgs[i], tag) {
+			t.Errorf("expected %q to contain %q", msgs[i], tag)
+		}
+	}
+}
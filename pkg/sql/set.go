@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -51,6 +52,17 @@ func (p *planner) SetVar(ctx context.Context, n *tree.SetVar) (planNode, error)
 
 	name := strings.ToLower(tree.AsStringWithFlags(n.Name, tree.FmtBareIdentifiers))
 
+	if name == "all" {
+		// RESET ALL is parsed as SET all = DEFAULT.
+		if len(n.Values) != 1 {
+			return nil, errors.New("invalid statement: SET ALL")
+		}
+		if _, ok := n.Values[0].(tree.DefaultVal); !ok {
+			return nil, errors.New("invalid statement: SET ALL")
+		}
+		return &setNode{v: sessionVar{Reset: resetAllSessionVars}}, nil
+	}
+
 	var typedValues []tree.TypedExpr
 	if len(n.Values) > 0 {
 		isReset := false
@@ -190,6 +202,19 @@ func (p *planner) SetClusterSetting(
 func (n *setClusterSettingNode) Start(params runParams) error {
 	ie := InternalExecutor{LeaseManager: params.p.LeaseMgr()}
 
+	// Remember the persisted value for the audit trail in the event log.
+	previousValue := "DEFAULT"
+	datums, err := ie.QueryRowInTransaction(
+		params.ctx, "retrieve-prev-setting", params.p.txn,
+		"SELECT value FROM system.settings WHERE name = $1", n.name,
+	)
+	if err != nil {
+		return err
+	}
+	if len(datums) != 0 {
+		previousValue = string(tree.MustBeDString(datums[0]))
+	}
+
 	var reportedValue string
 	if n.value == nil {
 		if _, err := ie.ExecuteStatementInTransaction(
@@ -200,7 +225,6 @@ func (n *setClusterSettingNode) Start(params runParams) error {
 		}
 		reportedValue = "DEFAULT"
 	} else {
-		// TODO(dt): validate and properly encode str according to type.
 		encoded, err := params.p.toSettingString(params.ctx, ie, n.st, n.name, n.setting, n.value)
 		if err != nil {
 			return err
@@ -221,11 +245,12 @@ func (n *setClusterSettingNode) Start(params runParams) error {
 		EventLogSetClusterSetting,
 		0, /* no target */
 		int32(params.evalCtx.NodeID),
-		struct {
-			SettingName string
-			Value       string
-			User        string
-		}{n.name, reportedValue, params.p.session.User},
+		&sqlbase.SetClusterSettingEvent{
+			SettingName:   n.name,
+			Value:         reportedValue,
+			PreviousValue: previousValue,
+			User:          params.p.session.User,
+		},
 	)
 }
 
@@ -349,6 +374,19 @@ func (p *planner) toSettingString(
 	}
 }
 
+// resetAllSessionVars resets every session variable to its default value,
+// as done by RESET ALL.
+func resetAllSessionVars(session *Session) error {
+	for _, vName := range varNames {
+		if v := varGen[vName]; v.Reset != nil {
+			if err := v.Reset(session); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func datumAsString(session *Session, name string, value tree.TypedExpr) (string, error) {
 	evalCtx := session.evalCtx()
 	val, err := value.Eval(&evalCtx)
@@ -406,16 +444,25 @@ func setTimeZone(_ context.Context, session *Session, values []tree.TypedExpr) e
 			if err1 != nil {
 				loc, err1 = timeutil.LoadLocation(strings.ToTitle(location))
 				if err1 != nil {
-					return fmt.Errorf("cannot find time zone %q: %v", location, err)
+					// Not a named time zone: try a POSIX-style offset like
+					// 'UTC+3', which is three hours behind UTC.
+					posixOffset, ok := timeutil.ParsePOSIXTimeZone(location)
+					if !ok {
+						return fmt.Errorf("cannot find time zone %q: %v", location, err)
+					}
+					loc = timeutil.FixedOffsetTimeZoneToLocation(posixOffset, location)
 				}
 			}
 		}
 
 	case *tree.DInterval:
-		offset, _, _, err = v.Duration.Div(time.Second.Nanoseconds()).Encode()
-		if err != nil {
-			return err
+		if v.Months != 0 {
+			return fmt.Errorf("invalid interval value for time zone: month not allowed")
+		}
+		if v.Days != 0 {
+			return fmt.Errorf("invalid interval value for time zone: day not allowed")
 		}
+		offset = v.Nanos / time.Second.Nanoseconds()
 
 	case *tree.DInt:
 		offset = int64(*v) * 60 * 60
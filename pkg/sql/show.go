@@ -15,7 +15,6 @@
 package sql
 
 import (
-	"bytes"
 	"fmt"
 	"strings"
 
@@ -244,7 +243,8 @@ func (p *planner) ShowCreateTable(ctx context.Context, n *tree.ShowCreateTable)
 	// We make the check whether the name points to a table or not in
 	// SQL, so as to avoid a double lookup (a first one to check if the
 	// descriptor is of the right type, another to populate the
-	// create_statements vtable).
+	// create_statements vtable). Sequences are tables too, so their
+	// CREATE SEQUENCE statement is shown as well.
 	const showCreateTableQuery = `
      SELECT %[3]s AS "Table",
             IFNULL(create_statement,
@@ -252,7 +252,8 @@ func (p *planner) ShowCreateTable(ctx context.Context, n *tree.ShowCreateTable)
                                              %[1]s || '.' || %[2]s || ' is not a table')::string
             ) AS "CreateTable"
        FROM (SELECT create_statement FROM %[4]s.crdb_internal.create_statements
-              WHERE database_name = %[1]s AND descriptor_name = %[2]s AND descriptor_type = 'table'
+              WHERE database_name = %[1]s AND descriptor_name = %[2]s
+                AND descriptor_type IN ('table', 'sequence')
               UNION ALL VALUES (NULL) ORDER BY 1 DESC) LIMIT 1
   `
 	return p.showTableDetails(ctx, "SHOW CREATE TABLE", n.Table, showCreateTableQuery)
@@ -278,6 +279,29 @@ func (p *planner) ShowCreateView(ctx context.Context, n *tree.ShowCreateView) (p
 	return p.showTableDetails(ctx, "SHOW CREATE VIEW", n.View, showCreateViewQuery)
 }
 
+// ShowCreateSequence returns a CREATE SEQUENCE statement for the specified
+// sequence.
+// Privileges: Any privilege on sequence.
+func (p *planner) ShowCreateSequence(
+	ctx context.Context, n *tree.ShowCreateSequence,
+) (planNode, error) {
+	// We make the check whether the name points to a sequence or not in
+	// SQL, so as to avoid a double lookup (a first one to check if the
+	// descriptor is of the right type, another to populate the
+	// create_statements vtable).
+	const showCreateSequenceQuery = `
+     SELECT %[3]s AS "Sequence",
+            IFNULL(create_statement,
+                   crdb_internal.force_error('` + pgerror.CodeUndefinedTableError + `',
+                                             %[1]s || '.' || %[2]s || ' is not a sequence')::string
+            ) AS "CreateSequence"
+       FROM (SELECT create_statement FROM %[4]s.crdb_internal.create_statements
+              WHERE database_name = %[1]s AND descriptor_name = %[2]s AND descriptor_type = 'sequence'
+              UNION ALL VALUES (NULL) ORDER BY 1 DESC) LIMIT 1
+  `
+	return p.showTableDetails(ctx, "SHOW CREATE SEQUENCE", n.Sequence, showCreateSequenceQuery)
+}
+
 // ShowTrace shows the current stored session trace.
 // Privileges: None.
 func (p *planner) ShowTrace(ctx context.Context, n *tree.ShowTrace) (planNode, error) {
@@ -325,6 +349,7 @@ WHERE message LIKE 'fetched: %'
    OR message LIKE 'output row: %'
    OR message LIKE 'execution failed: %'
    OR message LIKE 'r%: sending batch %'
+   OR message LIKE 'r%: received response to batch %'
 `
 	}
 
@@ -409,116 +434,6 @@ func (p *planner) ShowDatabases(ctx context.Context, n *tree.ShowDatabases) (pla
 		nil, nil)
 }
 
-// ShowGrants returns grant details for the specified objects and users.
-// Privileges: None.
-//   Notes: postgres does not have a SHOW GRANTS statement.
-//          mysql only returns the user's privileges.
-func (p *planner) ShowGrants(ctx context.Context, n *tree.ShowGrants) (planNode, error) {
-	var params []string
-	var initCheck func(context.Context) error
-
-	const dbPrivQuery = `SELECT TABLE_SCHEMA AS "Database", GRANTEE AS "User", PRIVILEGE_TYPE AS "Privileges" ` +
-		`FROM "".information_schema.schema_privileges`
-	const tablePrivQuery = `SELECT TABLE_SCHEMA AS "Database", TABLE_NAME AS "Table", GRANTEE AS "User", PRIVILEGE_TYPE AS "Privileges" ` +
-		`FROM "".information_schema.table_privileges`
-
-	var source bytes.Buffer
-	var cond bytes.Buffer
-	var orderBy string
-
-	if n.Targets != nil && n.Targets.Databases != nil {
-		// Get grants of database from information_schema.schema_privileges
-		// if the type of target is database.
-		dbNames := n.Targets.Databases.ToStrings()
-
-		initCheck = func(ctx context.Context) error {
-			for _, db := range dbNames {
-				if err := checkDBExists(ctx, p, db); err != nil {
-					return err
-				}
-			}
-			return nil
-		}
-
-		for _, db := range dbNames {
-			params = append(params, lex.EscapeSQLString(db))
-		}
-
-		fmt.Fprint(&source, dbPrivQuery)
-		orderBy = "1,2,3"
-		if len(params) == 0 {
-			// There are no rows, but we can't simply return emptyNode{} because
-			// the result columns must still be defined.
-			cond.WriteString(`WHERE false`)
-		} else {
-			fmt.Fprintf(&cond, `WHERE "Database" IN (%s)`, strings.Join(params, ","))
-		}
-	} else {
-		fmt.Fprint(&source, tablePrivQuery)
-		orderBy = "1,2,3,4"
-
-		if n.Targets != nil {
-			// Get grants of table from information_schema.table_privileges
-			// if the type of target is table.
-			var allTables tree.TableNames
-
-			for _, tableTarget := range n.Targets.Tables {
-				tableGlob, err := tableTarget.NormalizeTablePattern()
-				if err != nil {
-					return nil, err
-				}
-				tables, err := expandTableGlob(ctx, p.txn, p.getVirtualTabler(),
-					p.session.Database, tableGlob)
-				if err != nil {
-					return nil, err
-				}
-				allTables = append(allTables, tables...)
-			}
-
-			initCheck = func(ctx context.Context) error {
-				for i := range allTables {
-					if err := checkTableExists(ctx, p, &allTables[i]); err != nil {
-						return err
-					}
-				}
-				return nil
-			}
-
-			for i := range allTables {
-				params = append(params, fmt.Sprintf("(%s,%s)",
-					lex.EscapeSQLString(allTables[i].Database()),
-					lex.EscapeSQLString(allTables[i].Table())))
-			}
-
-			if len(params) == 0 {
-				// The glob pattern has expanded to zero matching tables.
-				// There are no rows, but we can't simply return emptyNode{} because
-				// the result columns must still be defined.
-				cond.WriteString(`WHERE false`)
-			} else {
-				fmt.Fprintf(&cond, `WHERE ("Database", "Table") IN (%s)`, strings.Join(params, ","))
-			}
-		} else {
-			// No target: also look at databases.
-			source.WriteString(` UNION ALL ` +
-				`SELECT "Database", NULL::STRING AS "Table", "User", "Privileges" FROM (`)
-			source.WriteString(dbPrivQuery)
-			source.WriteByte(')')
-		}
-	}
-
-	if n.Grantees != nil {
-		params = params[:0]
-		for _, grantee := range n.Grantees.ToStrings() {
-			params = append(params, lex.EscapeSQLString(grantee))
-		}
-		fmt.Fprintf(&cond, ` AND "User" IN (%s)`, strings.Join(params, ","))
-	}
-	return p.delegateQuery(ctx, "SHOW GRANTS",
-		fmt.Sprintf("SELECT * FROM (%s) %s ORDER BY %s", source.String(), cond.String(), orderBy),
-		initCheck, nil)
-}
-
 // ShowIndex returns all the indexes for a table.
 // Privileges: Any privilege on table.
 //   Notes: postgres does not have a SHOW INDEXES statement.
@@ -632,6 +547,16 @@ func (p *planner) ShowJobs(ctx context.Context, n *tree.ShowJobs) (planNode, err
 		nil, nil)
 }
 
+// ShowSchedules returns all the schedules of periodic jobs.
+// Privileges: SELECT on system.scheduled_jobs.
+func (p *planner) ShowSchedules(ctx context.Context, n *tree.ShowSchedules) (planNode, error) {
+	return p.delegateQuery(ctx, "SHOW SCHEDULES",
+		`SELECT schedule_id AS id, schedule_name AS name, owner, created, next_run,
+            schedule_expr AS recurrence, executor_type, schedule_status AS status
+       FROM system.scheduled_jobs`,
+		nil, nil)
+}
+
 func (p *planner) ShowSessions(ctx context.Context, n *tree.ShowSessions) (planNode, error) {
 	query := `TABLE crdb_internal.node_sessions`
 	if n.Cluster {
@@ -21,6 +21,8 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/pkg/errors"
@@ -41,7 +43,11 @@ func (p *planner) showCreateView(
 		}
 		tree.Name(col.Name).Format(&buf, tree.FmtSimple)
 	}
-	fmt.Fprintf(&buf, ") AS %s", desc.ViewQuery)
+	buf.WriteByte(')')
+	if desc.ViewSecurityInvoker {
+		buf.WriteString(" SECURITY INVOKER")
+	}
+	fmt.Fprintf(&buf, " AS %s", desc.ViewQuery)
 	return buf.String(), nil
 }
 
@@ -99,13 +105,15 @@ func (p *planner) showCreateTable(
 				tree.Name(fk.Name),
 				quoteNames(idx.ColumnNames[0:idx.ForeignKey.SharedPrefixLen]...),
 				&fkTableName,
-				quoteNames(fkIdx.ColumnNames...),
+				quoteNames(fkIdx.ColumnNames[:idx.ForeignKey.SharedPrefixLen]...),
 			)
+			// The values of ForeignKeyReference_Action match those of
+			// tree.ReferenceAction, which prints the SQL keywords.
 			if fk.OnDelete != sqlbase.ForeignKeyReference_NO_ACTION {
-				fmt.Fprintf(&buf, " ON DELETE %s", fk.OnDelete.String())
+				fmt.Fprintf(&buf, " ON DELETE %s", tree.ReferenceAction(fk.OnDelete))
 			}
 			if fk.OnUpdate != sqlbase.ForeignKeyReference_NO_ACTION {
-				fmt.Fprintf(&buf, " ON UPDATE %s", fk.OnUpdate.String())
+				fmt.Fprintf(&buf, " ON UPDATE %s", tree.ReferenceAction(fk.OnUpdate))
 			}
 		}
 		if idx.ID != desc.PrimaryIndex.ID {
@@ -150,10 +158,19 @@ func (p *planner) showCreateTable(
 	if err := p.showCreateInterleave(ctx, &desc.PrimaryIndex, &buf, dbPrefix); err != nil {
 		return "", err
 	}
-	if err := showCreatePartitioning(
-		a, desc, &desc.PrimaryIndex, &desc.PrimaryIndex.Partitioning, &buf, 0 /* indent */, 0, /* colOffset */
-	); err != nil {
-		return "", err
+	// The partitioning of the primary index of REGIONAL BY ROW tables follows
+	// from their locality.
+	switch desc.Locality {
+	case sqlbase.TableDescriptor_GLOBAL:
+		buf.WriteString(" LOCALITY GLOBAL")
+	case sqlbase.TableDescriptor_REGIONAL_BY_ROW:
+		buf.WriteString(" LOCALITY REGIONAL BY ROW")
+	default:
+		if err := showCreatePartitioning(
+			a, desc, &desc.PrimaryIndex, &desc.PrimaryIndex.Partitioning, &buf, 0 /* indent */, 0, /* colOffset */
+		); err != nil {
+			return "", err
+		}
 	}
 
 	return buf.String(), nil
@@ -234,20 +251,13 @@ func showCreatePartitioning(
 	} else {
 		return errors.Errorf(`invalid partition descriptor: %v`, partDesc)
 	}
-	buf.WriteString(` (`)
-	for i := 0; i < int(partDesc.NumColumns); i++ {
-		if i != 0 {
-			fmt.Printf(", ")
-		}
-		fmt.Fprintf(buf, idxDesc.ColumnNames[colOffset+i])
-	}
-	buf.WriteString(`) (`)
+	fmt.Fprintf(buf, ` (%s) (`,
+		quoteNames(idxDesc.ColumnNames[colOffset:colOffset+int(partDesc.NumColumns)]...))
 	for i, part := range partDesc.List {
 		if i != 0 {
 			buf.WriteString(`, `)
 		}
-		fmt.Fprintf(buf, "\n%s\tPARTITION ", indentStr)
-		fmt.Fprintf(buf, part.Name)
+		fmt.Fprintf(buf, "\n%s\tPARTITION %s", indentStr, quoteNames(part.Name))
 		buf.WriteString(` VALUES IN (`)
 		for j, values := range part.Values {
 			if j != 0 {
@@ -275,8 +285,7 @@ func showCreatePartitioning(
 		if i != 0 {
 			buf.WriteString(`, `)
 		}
-		fmt.Fprintf(buf, "\n%s\tPARTITION ", indentStr)
-		fmt.Fprintf(buf, part.Name)
+		fmt.Fprintf(buf, "\n%s\tPARTITION %s", indentStr, quoteNames(part.Name))
 		buf.WriteString(` VALUES < `)
 		datums, _, err := sqlbase.TranslateValueEncodingToSpan(
 			a, tableDesc, idxDesc, partDesc, part.UpperBound, fakePrefixDatums,
@@ -291,3 +300,100 @@ func showCreatePartitioning(
 	fmt.Fprintf(buf, "\n%s)", indentStr)
 	return nil
 }
+
+// showCreateSequence returns a valid SQL representation of the CREATE
+// SEQUENCE statement which recreates the given sequence, starting at the
+// given value.
+func showCreateSequence(tn tree.Name, desc *sqlbase.TableDescriptor, start int64) string {
+	opts := desc.SequenceOpts
+	var buf bytes.Buffer
+	buf.WriteString("CREATE SEQUENCE ")
+	tn.Format(&buf, tree.FmtSimple)
+	fmt.Fprintf(&buf, " MINVALUE %d MAXVALUE %d INCREMENT %d START %d",
+		opts.MinValue, opts.MaxValue, opts.Increment, start)
+	if opts.Cycle {
+		buf.WriteString(" CYCLE")
+	}
+	return buf.String()
+}
+
+// showCreateZoneConfigs returns the ALTER ... CONFIGURE ZONE statements which
+// recreate the zone configs of the given table and of its indexes and
+// partitions.
+func (p *planner) showCreateZoneConfigs(
+	ctx context.Context, tn tree.Name, desc *sqlbase.TableDescriptor,
+) ([]string, error) {
+	if desc.ID == keys.VirtualDescriptorID {
+		return nil, nil
+	}
+	zone, err := getZoneConfigRaw(ctx, p.txn, desc.ID)
+	if err != nil {
+		return nil, err
+	}
+	tableName := tree.NormalizableTableName{
+		TableNameReference: &tree.TableName{TableName: tn, DBNameOriginallyOmitted: true},
+	}
+	var stmts []string
+	if !zone.IsSubzonePlaceholder() {
+		stmts = append(stmts, tree.AsString(&tree.SetZoneConfig{
+			ZoneSpecifier: tree.ZoneSpecifier{
+				TableOrIndex: tree.TableNameWithIndex{Table: tableName},
+			},
+			Settings: zoneConfigSettings(zone),
+		}))
+	}
+	for _, s := range zone.Subzones {
+		idx, err := desc.FindIndexByID(sqlbase.IndexID(s.IndexID))
+		if err != nil {
+			// The index was dropped, and its subzone is about to be removed.
+			continue
+		}
+		zs := tree.ZoneSpecifier{
+			TableOrIndex: tree.TableNameWithIndex{Table: tableName},
+			Partition:    tree.Name(s.PartitionName),
+		}
+		if idx.ID != desc.PrimaryIndex.ID || s.PartitionName == "" {
+			zs.TableOrIndex.Index = tree.UnrestrictedName(idx.Name)
+		}
+		stmts = append(stmts, tree.AsString(&tree.SetZoneConfig{
+			ZoneSpecifier: zs,
+			Settings:      zoneConfigSettings(s.Config),
+		}))
+	}
+	return stmts, nil
+}
+
+// zoneConfigSettings returns the CONFIGURE ZONE USING settings which recreate
+// the given zone config.
+func zoneConfigSettings(zone config.ZoneConfig) tree.ZoneConfigSettings {
+	constraintsString := func(cs []config.Constraint) string {
+		strs := make([]string, len(cs))
+		for i, c := range cs {
+			strs[i] = c.String()
+		}
+		return "[" + strings.Join(strs, ", ") + "]"
+	}
+	setting := func(key string, value tree.Expr) tree.ZoneConfigSetting {
+		var name tree.UnresolvedName
+		for _, part := range strings.Split(key, ".") {
+			name = append(name, tree.Name(part))
+		}
+		return tree.ZoneConfigSetting{Key: name, Value: value}
+	}
+	settings := tree.ZoneConfigSettings{
+		setting("range_min_bytes", tree.NewDInt(tree.DInt(zone.RangeMinBytes))),
+		setting("range_max_bytes", tree.NewDInt(tree.DInt(zone.RangeMaxBytes))),
+		setting("gc.ttlseconds", tree.NewDInt(tree.DInt(zone.GC.TTLSeconds))),
+		setting("num_replicas", tree.NewDInt(tree.DInt(zone.NumReplicas))),
+		setting("constraints", tree.NewDString(constraintsString(zone.Constraints.Constraints))),
+	}
+	if len(zone.LeasePreferences) > 0 {
+		prefs := make([]string, len(zone.LeasePreferences))
+		for i, pref := range zone.LeasePreferences {
+			prefs[i] = constraintsString(pref.Constraints)
+		}
+		settings = append(settings,
+			setting("lease_preferences", tree.NewDString("["+strings.Join(prefs, ", ")+"]")))
+	}
+	return settings
+}
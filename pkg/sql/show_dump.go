@@ -0,0 +1,245 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// ShowDump returns the statements which recreate the given database: the
+// CREATE statements of its sequences, tables and views, followed by the
+// contents of its tables as COPY ... FROM stdin blocks. Every row of the
+// result is one line of the dump. Everything is read in the transaction of
+// the statement, so the dump is consistent, and AS OF SYSTEM TIME can be used
+// to dump the database as it was in the past.
+// Privileges: SELECT on every table, view and sequence of the database.
+func (p *planner) ShowDump(ctx context.Context, n *tree.ShowDump) (planNode, error) {
+	if n.AsOf.Expr != nil && !p.asOfSystemTime {
+		return nil, fmt.Errorf("AS OF SYSTEM TIME must be provided on a top level SHOW DUMP statement")
+	}
+
+	dbName := string(n.Database)
+	if dbName == "" {
+		dbName = p.session.Database
+		if dbName == "" {
+			return nil, errNoDatabase
+		}
+	}
+	db, err := MustGetDatabaseDesc(ctx, p.txn, p.getVirtualTabler(), dbName)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := p.dumpOrder(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range tables {
+		if err := p.CheckPrivilege(desc, privilege.SELECT); err != nil {
+			return nil, err
+		}
+	}
+
+	header := sqlbase.ResultColumns{{Name: "dump", Typ: types.String}}
+	fn := func(ctx context.Context, resultsCh chan<- tree.Datums) error {
+		emit := func(line string) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case resultsCh <- tree.Datums{tree.NewDString(line)}:
+				return nil
+			}
+		}
+
+		for _, desc := range tables {
+			var stmt string
+			var err error
+			switch {
+			case desc.IsSequence():
+				// There is no way to set the value of a sequence, so the
+				// sequence is recreated to start where it left off.
+				kv, err := p.txn.Get(ctx, keys.MakeSequenceKey(uint32(desc.ID)))
+				if err != nil {
+					return err
+				}
+				stmt = showCreateSequence(
+					tree.Name(desc.Name), desc, kv.ValueInt()+desc.SequenceOpts.Increment,
+				)
+			case desc.IsView():
+				stmt, err = p.showCreateView(ctx, tree.Name(desc.Name), desc)
+			default:
+				stmt, err = p.showCreateTable(ctx, tree.Name(desc.Name), db.Name, desc)
+			}
+			if err != nil {
+				return err
+			}
+			if err := emit(stmt + ";"); err != nil {
+				return err
+			}
+		}
+
+		for _, desc := range tables {
+			if !desc.IsTable() {
+				continue
+			}
+			if err := p.dumpTableData(ctx, db, desc, emit); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return &hookFnNode{f: fn, header: header}, nil
+}
+
+// dumpOrder returns the public tables, views and sequences of the given
+// database in an order in which they can be recreated: sequences first, then
+// every table and view after the tables it references through foreign keys,
+// interleaving or its view query. Otherwise, the descriptors are sorted by
+// name.
+func (p *planner) dumpOrder(
+	ctx context.Context, db *sqlbase.DatabaseDescriptor,
+) ([]*sqlbase.TableDescriptor, error) {
+	descs, err := getAllDescriptors(ctx, p.txn)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
+	var sorted []*sqlbase.TableDescriptor
+	for _, desc := range descs {
+		if table, ok := desc.(*sqlbase.TableDescriptor); ok &&
+			table.ParentID == db.ID && table.State == sqlbase.TableDescriptor_PUBLIC {
+			byID[table.ID] = table
+			sorted = append(sorted, table)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if a, b := sorted[i].IsSequence(), sorted[j].IsSequence(); a != b {
+			return a
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[sqlbase.ID]int)
+	ordered := make([]*sqlbase.TableDescriptor, 0, len(sorted))
+	var visit func(desc *sqlbase.TableDescriptor) error
+	visit = func(desc *sqlbase.TableDescriptor) error {
+		switch state[desc.ID] {
+		case visited:
+			return nil
+		case visiting:
+			return errors.Errorf("cannot dump database %q: the references of table %q form a cycle",
+				db.Name, desc.Name)
+		}
+		state[desc.ID] = visiting
+
+		var deps []sqlbase.ID
+		for _, idx := range append([]sqlbase.IndexDescriptor{desc.PrimaryIndex}, desc.Indexes...) {
+			if fk := idx.ForeignKey; fk.IsSet() && fk.Table != desc.ID {
+				deps = append(deps, fk.Table)
+			}
+			for _, ancestor := range idx.Interleave.Ancestors {
+				if ancestor.TableID != desc.ID {
+					deps = append(deps, ancestor.TableID)
+				}
+			}
+		}
+		deps = append(deps, desc.DependsOn...)
+		for _, id := range deps {
+			// References to other databases are left as they are.
+			if dep, ok := byID[id]; ok {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+
+		state[desc.ID] = visited
+		ordered = append(ordered, desc)
+		return nil
+	}
+	for _, desc := range sorted {
+		if err := visit(desc); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// dumpTableData emits the contents of the given table as a COPY ... FROM
+// stdin block in the text format, one line at a time.
+func (p *planner) dumpTableData(
+	ctx context.Context,
+	db *sqlbase.DatabaseDescriptor,
+	desc *sqlbase.TableDescriptor,
+	emit func(string) error,
+) error {
+	cols := desc.VisibleColumns()
+	colNames := make([]string, len(cols))
+	for i := range cols {
+		colNames[i] = cols[i].Name
+	}
+	tn := tree.TableName{DatabaseName: tree.Name(db.Name), TableName: tree.Name(desc.Name)}
+	query, err := parser.ParseOne(fmt.Sprintf("SELECT %s FROM %s", quoteNames(colNames...), &tn))
+	if err != nil {
+		return err
+	}
+
+	if err := emit(fmt.Sprintf(
+		"COPY %s (%s) FROM stdin;", quoteNames(desc.Name), quoteNames(colNames...),
+	)); err != nil {
+		return err
+	}
+	var line []byte
+	if err := p.ForEachQueryRow(ctx, query, func(row tree.Datums) error {
+		line = line[:0]
+		for i, d := range row {
+			if i > 0 {
+				line = append(line, '\t')
+			}
+			line = appendDumpField(line, d)
+		}
+		return emit(string(line))
+	}); err != nil {
+		return err
+	}
+	return emit(`\.`)
+}
+
+// appendDumpField appends a datum to buf as a field in the COPY text format,
+// as it is read by COPY FROM.
+func appendDumpField(buf []byte, d tree.Datum) []byte {
+	if d == tree.DNull {
+		return append(buf, `\N`...)
+	}
+	if b, ok := d.(*tree.DBytes); ok {
+		// COPY FROM reads the raw bytes, not a bytes literal.
+		return AppendCopyTextField(buf, []byte(*b), '\t')
+	}
+	return AppendCopyTextField(buf, []byte(tree.AsStringWithFlags(d, tree.FmtBareStrings)), '\t')
+}
@@ -0,0 +1,254 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
+
+// ShowGrants returns the privileges of the users on the specified objects:
+// the privileges granted to them, and those they obtain through the roles
+// whose privileges they inherit, through the public role, and as the owners
+// of the objects. See grantsLister for the users listed.
+// Privileges: None.
+//   Notes: postgres does not have a SHOW GRANTS statement.
+//          mysql only returns the user's privileges.
+func (p *planner) ShowGrants(ctx context.Context, n *tree.ShowGrants) (planNode, error) {
+	showDatabases := n.Targets == nil || n.Targets.Databases != nil
+	showTables := n.Targets == nil || n.Targets.Databases == nil
+
+	var dbNames map[string]bool
+	var tables tree.TableNames
+	if n.Targets != nil && n.Targets.Databases != nil {
+		dbNames = make(map[string]bool)
+		for _, db := range n.Targets.Databases.ToStrings() {
+			dbNames[db] = true
+		}
+	} else if n.Targets != nil {
+		for _, tableTarget := range n.Targets.Tables {
+			tableGlob, err := tableTarget.NormalizeTablePattern()
+			if err != nil {
+				return nil, err
+			}
+			expanded, err := expandTableGlob(ctx, p.txn, p.getVirtualTabler(),
+				p.session.Database, tableGlob)
+			if err != nil {
+				return nil, err
+			}
+			tables = append(tables, expanded...)
+		}
+	}
+	tableNames := make(map[[2]string]bool)
+	for i := range tables {
+		tableNames[[2]string{tables[i].Database(), tables[i].Table()}] = true
+	}
+
+	columns := sqlbase.ResultColumns{{Name: "Database", Typ: types.String}}
+	if showTables {
+		columns = append(columns, sqlbase.ResultColumn{Name: "Table", Typ: types.String})
+	}
+	columns = append(columns,
+		sqlbase.ResultColumn{Name: "User", Typ: types.String},
+		sqlbase.ResultColumn{Name: "Privileges", Typ: types.String},
+	)
+	if n.WithPath {
+		columns = append(columns, sqlbase.ResultColumn{Name: "Grant path", Typ: types.String})
+	}
+
+	return &delayedNode{
+		name:    n.String(),
+		columns: columns,
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			for db := range dbNames {
+				if err := checkDBExists(ctx, p, db); err != nil {
+					return nil, err
+				}
+			}
+			for i := range tables {
+				if err := checkTableExists(ctx, p, &tables[i]); err != nil {
+					return nil, err
+				}
+			}
+			g, err := p.loadRoleGraph(ctx)
+			if err != nil {
+				return nil, err
+			}
+			l := makeGrantsLister(g, n.Grantees.ToStrings(), n.WithPath)
+
+			v := p.newContainerValuesNode(columns, 0)
+			addRows := func(db, table tree.Datum, privs *sqlbase.PrivilegeDescriptor) error {
+				return l.forEachGrant(privs, func(user, priv, path string) error {
+					row := tree.Datums{db}
+					if showTables {
+						row = append(row, table)
+					}
+					row = append(row, tree.NewDString(user), tree.NewDString(priv))
+					if n.WithPath {
+						row = append(row, tree.NewDString(path))
+					}
+					_, err := v.rows.AddRow(ctx, row)
+					return err
+				})
+			}
+			if showDatabases {
+				if err := forEachDatabaseDesc(ctx, p, func(db *sqlbase.DatabaseDescriptor) error {
+					if dbNames != nil && !dbNames[db.Name] {
+						return nil
+					}
+					return addRows(tree.NewDString(db.Name), tree.DNull, db.Privileges)
+				}); err != nil {
+					v.Close(ctx)
+					return nil, err
+				}
+			}
+			if showTables {
+				if err := forEachTableDesc(ctx, p, "",
+					func(db *sqlbase.DatabaseDescriptor, table *sqlbase.TableDescriptor) error {
+						if n.Targets != nil && !tableNames[[2]string{db.Name, table.Name}] {
+							return nil
+						}
+						return addRows(tree.NewDString(db.Name), tree.NewDString(table.Name), table.Privileges)
+					}); err != nil {
+					v.Close(ctx)
+					return nil, err
+				}
+			}
+
+			ordering := make(sqlbase.ColumnOrdering, len(columns))
+			for i := range ordering {
+				ordering[i] = sqlbase.ColumnOrderInfo{ColIdx: i, Direction: encoding.Ascending}
+			}
+			return &sortNode{plan: v, ordering: ordering, columns: v.columns}, nil
+		},
+	}, nil
+}
+
+// grantsLister lists the privileges of the users on objects. When no users
+// are specified, the users holding privileges on an object directly or as
+// its owner are listed, along with the members of the roles holding them.
+// The privileges granted to the public role are then listed for the public
+// role alone, instead of for every user. When users are specified, all their
+// privileges are listed, including those of the public role.
+type grantsLister struct {
+	graph roleGraph
+	// users are the users whose privileges are listed, or nil.
+	users []string
+	// members are the users and roles which are members of some role.
+	members []string
+	// withPath is set if the privileges obtained in different ways are
+	// listed separately, with their paths.
+	withPath bool
+	// paths caches the inheritance paths of the users.
+	paths map[string]map[string][]string
+}
+
+func makeGrantsLister(g roleGraph, users []string, withPath bool) grantsLister {
+	l := grantsLister{
+		graph:    g,
+		users:    users,
+		withPath: withPath,
+		paths:    make(map[string]map[string][]string),
+	}
+	for member := range g.memberships {
+		l.members = append(l.members, member)
+	}
+	return l
+}
+
+// forEachGrant calls fn for each privilege of the users in the given
+// privilege descriptor. The path of a privilege is the chain of users and
+// roles from the user to the holder of the privilege, followed by "(owner)"
+// for the privileges of the owner of the object. Unless withPath is set, a
+// privilege obtained in several ways is only reported once.
+func (l *grantsLister) forEachGrant(
+	privs *sqlbase.PrivilegeDescriptor, fn func(user, priv, path string) error,
+) error {
+	granted := make(map[string][]string)
+	for _, u := range privs.Show() {
+		granted[u.User] = u.Privileges
+	}
+	// An empty owner stands for root, which holds its privileges directly.
+	owner := privs.Owner
+
+	users := l.users
+	if users == nil {
+		users = append(users, l.members...)
+		for user := range granted {
+			users = append(users, user)
+		}
+		if owner != "" {
+			users = append(users, owner)
+		}
+	}
+
+	listed := make(map[string]bool)
+	seen := make(map[[2]string]bool)
+	report := func(user, priv string, chain []string, suffix string) error {
+		if !l.withPath {
+			if seen[[2]string{user, priv}] {
+				return nil
+			}
+			seen[[2]string{user, priv}] = true
+		}
+		return fn(user, priv, strings.Join(chain, " -> ")+suffix)
+	}
+	for _, user := range users {
+		if listed[user] {
+			continue
+		}
+		listed[user] = true
+
+		chains := [][]string{{user}}
+		for _, roles := range l.inheritancePaths(user) {
+			chains = append(chains, append([]string{user}, roles...))
+		}
+		if l.users != nil && user != security.PublicRole {
+			chains = append(chains, []string{user, security.PublicRole})
+		}
+		for _, chain := range chains {
+			holder := chain[len(chain)-1]
+			for _, priv := range granted[holder] {
+				if err := report(user, priv, chain, ""); err != nil {
+					return err
+				}
+			}
+			if owner != "" && owner == holder {
+				if err := report(user, privilege.ALL.String(), chain, " (owner)"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// inheritancePaths returns the cached inheritance paths of a user.
+func (l *grantsLister) inheritancePaths(user string) map[string][]string {
+	paths, ok := l.paths[user]
+	if !ok {
+		paths = l.graph.inheritancePaths(user)
+		l.paths[user] = paths
+	}
+	return paths
+}
@@ -12,23 +12,29 @@
 // implied. See the License for the specific language governing
 // permissions and limitations under the License.
 
-// This file implements the SHOW TESTING_RANGES statement:
-//   SHOW TESTING_RANGES FROM TABLE t
-//   SHOW TESTING_RANGES FROM INDEX t@idx
+// This file implements the SHOW RANGES and SHOW RANGE FOR ROW statements:
+//   SHOW RANGES FROM TABLE t
+//   SHOW RANGES FROM INDEX t@idx
+//   SHOW RANGE FROM TABLE t FOR ROW (1, 2)
+//   SHOW RANGE FROM INDEX t@idx FOR ROW ('a')
 //
 // These statements show the ranges corresponding to the given table or index,
-// along with the list of replicas and the lease holder.
+// or the range containing the given row, along with the list of replicas, the
+// lease holder and the size of the range. SHOW TESTING_RANGES is an alias of
+// SHOW RANGES.
 
 package sql
 
 import (
 	"sort"
+	"strconv"
 
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -36,6 +42,8 @@ import (
 	"github.com/pkg/errors"
 )
 
+// ShowRanges shows the ranges of a table or index.
+// Privileges: SELECT on table.
 func (p *planner) ShowRanges(ctx context.Context, n *tree.ShowRanges) (planNode, error) {
 	tableDesc, index, err := p.getTableAndIndex(ctx, n.Table, n.Index, privilege.SELECT)
 	if err != nil {
@@ -43,16 +51,68 @@ func (p *planner) ShowRanges(ctx context.Context, n *tree.ShowRanges) (planNode,
 	}
 	// Note: for interleaved tables, the ranges we report will include rows from
 	// interleaving.
+	span := tableDesc.IndexSpan(index.ID)
 	return &showRangesNode{
-		span:   tableDesc.IndexSpan(index.ID),
-		values: make([]tree.Datum, len(showRangesColumns)),
+		span:      span,
+		indexSpan: span,
+		values:    make([]tree.Datum, len(showRangesColumns)),
+	}, nil
+}
+
+// ShowRangeForRow shows the range containing a row of a table or index.
+// Privileges: SELECT on table.
+func (p *planner) ShowRangeForRow(
+	ctx context.Context, n *tree.ShowRangeForRow,
+) (planNode, error) {
+	tableDesc, index, err := p.getTableAndIndex(ctx, n.Table, n.Index, privilege.SELECT)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(n.Row) == 0:
+		return nil, errors.Errorf("no columns in SHOW RANGE FOR ROW expression")
+	case len(n.Row) > len(index.ColumnIDs):
+		return nil, errors.Errorf("too many columns in SHOW RANGE FOR ROW expression")
+	}
+	// The values can be given for a prefix of the index columns.
+	values := make([]tree.Datum, len(n.Row))
+	for i, expr := range n.Row {
+		c, err := tableDesc.FindColumnByID(index.ColumnIDs[i])
+		if err != nil {
+			return nil, err
+		}
+		typedExpr, err := p.analyzeExpr(
+			ctx, expr, nil, tree.IndexedVarHelper{}, c.Type.ToDatumType(), true, "SHOW RANGE",
+		)
+		if err != nil {
+			return nil, err
+		}
+		values[i], err = typedExpr.Eval(&p.evalCtx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	rowKey, err := getRowKey(tableDesc, index, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return &showRangesNode{
+		span:      roachpb.Span{Key: rowKey, EndKey: roachpb.Key(rowKey).Next()},
+		indexSpan: tableDesc.IndexSpan(index.ID),
+		values:    make([]tree.Datum, len(showRangesColumns)),
 	}, nil
 }
 
 type showRangesNode struct {
 	optColumnsSlot
 
+	// span is the span whose ranges are shown.
 	span roachpb.Span
+	// indexSpan is the span of the index, outside of which the range
+	// boundaries are shown as NULL.
+	indexSpan roachpb.Span
 
 	// descriptorKVs are KeyValues returned from scanning the
 	// relevant meta keys.
@@ -78,12 +138,18 @@ var showRangesColumns = sqlbase.ResultColumns{
 	},
 	{
 		Name: "Replicas",
-		// The INTs in the array are Store IDs.
+		// The INTs in the array are Node IDs.
 		Typ: types.TArray{Typ: types.Int},
 	},
 	{
 		Name: "Lease Holder",
-		// The store ID for the lease holder.
+		// The node ID for the lease holder.
+		Typ: types.Int,
+	},
+	{
+		Name: "Range Size",
+		// The size of the keys and values of the range on the lease holder, in
+		// bytes.
 		Typ: types.Int,
 	},
 }
@@ -107,19 +173,21 @@ func (n *showRangesNode) Next(params runParams) (bool, error) {
 		n.values[i] = tree.DNull
 	}
 
-	if n.rowIdx > 0 {
-		n.values[0] = tree.NewDString(sqlbase.PrettyKey(desc.StartKey.AsRawKey(), 2))
+	// The boundaries of the ranges which extend beyond the index are not
+	// shown, since they can't be pretty-printed as index values.
+	if startKey := desc.StartKey.AsRawKey(); n.indexSpan.Key.Compare(startKey) < 0 {
+		n.values[0] = tree.NewDString(sqlbase.PrettyKey(startKey, 2))
 	}
 
-	if n.rowIdx < len(n.descriptorKVs)-1 {
-		n.values[1] = tree.NewDString(sqlbase.PrettyKey(desc.EndKey.AsRawKey(), 2))
+	if endKey := desc.EndKey.AsRawKey(); endKey.Compare(n.indexSpan.EndKey) < 0 {
+		n.values[1] = tree.NewDString(sqlbase.PrettyKey(endKey, 2))
 	}
 
 	n.values[2] = tree.NewDInt(tree.DInt(desc.RangeID))
 
 	var replicas []int
 	for _, rd := range desc.Replicas {
-		replicas = append(replicas, int(rd.StoreID))
+		replicas = append(replicas, int(rd.NodeID))
 	}
 	sort.Ints(replicas)
 
@@ -143,7 +211,20 @@ func (n *showRangesNode) Next(params runParams) (bool, error) {
 		return false, errors.Wrap(err, "error getting lease info")
 	}
 	resp := b.RawResponse().Responses[0].GetInner().(*roachpb.LeaseInfoResponse)
-	n.values[4] = tree.NewDInt(tree.DInt(resp.Lease.Replica.StoreID))
+	leaseHolder := resp.Lease.Replica.NodeID
+	n.values[4] = tree.NewDInt(tree.DInt(leaseHolder))
+
+	// Get the size of the range from the lease holder.
+	stats, err := params.p.session.execCfg.StatusServer.SpanStats(params.ctx,
+		&serverpb.SpanStatsRequest{
+			NodeID:   strconv.Itoa(int(leaseHolder)),
+			StartKey: desc.StartKey,
+			EndKey:   desc.EndKey,
+		})
+	if err != nil {
+		return false, errors.Wrap(err, "error getting range size")
+	}
+	n.values[5] = tree.NewDInt(tree.DInt(stats.TotalStats.Total()))
 
 	n.rowIdx++
 	return true, nil
@@ -0,0 +1,96 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"strconv"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+var showSyntaxColumns = sqlbase.ResultColumns{
+	{Name: "field", Typ: types.String},
+	{Name: "text", Typ: types.String},
+}
+
+// ShowSyntax shows the statements of a string in their canonical form, or
+// describes the syntax error which prevents the string from being parsed.
+// Privileges: None.
+func (p *planner) ShowSyntax(ctx context.Context, n *tree.ShowSyntax) (planNode, error) {
+	return &delayedNode{
+		name:    "SHOW SYNTAX " + lex.EscapeSQLString(n.Statement),
+		columns: showSyntaxColumns,
+		constructor: func(ctx context.Context, p *planner) (planNode, error) {
+			v := p.newContainerValuesNode(showSyntaxColumns, 0)
+			if err := runShowSyntax(n.Statement, func(field, text string) error {
+				_, err := v.rows.AddRow(ctx, tree.Datums{tree.NewDString(field), tree.NewDString(text)})
+				return err
+			}); err != nil {
+				v.Close(ctx)
+				return nil, err
+			}
+			return v, nil
+		},
+	}, nil
+}
+
+// runShowSyntax parses the given string and reports either a "sql" field for
+// each statement it contains, or the "error", "code", "position", "detail"
+// and "hint" fields of the syntax error. The position is counted in
+// characters from the start of the string, starting at 1.
+func runShowSyntax(sql string, report func(field, text string) error) error {
+	var p parser.Parser
+	stmts, err := p.Parse(sql)
+	if err == nil {
+		for _, stmt := range stmts {
+			if err := report("sql", tree.AsStringWithFlags(stmt, tree.FmtParsable)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	pgErr, ok := pgerror.GetPGCause(err)
+	if !ok {
+		return report("error", err.Error())
+	}
+	if err := report("error", pgErr.Message); err != nil {
+		return err
+	}
+	if err := report("code", pgErr.Code); err != nil {
+		return err
+	}
+	if pos := p.ErrorPosition(); pos > 0 {
+		if err := report("position", strconv.Itoa(pos)); err != nil {
+			return err
+		}
+	}
+	if pgErr.Detail != "" {
+		if err := report("detail", pgErr.Detail); err != nil {
+			return err
+		}
+	}
+	if pgErr.Hint != "" {
+		return report("hint", pgErr.Hint)
+	}
+	return nil
+}
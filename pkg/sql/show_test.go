@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
 	"github.com/cockroachdb/cockroach/pkg/sql/tests"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -229,6 +230,19 @@ func TestShowCreateTable(t *testing.T) {
 	FAMILY "primary" (x)
 ) INTERLEAVE IN PARENT o.foo (x)`,
 		},
+		// Check that the referential actions of FKs are shown as SQL.
+		{
+			stmt: `CREATE TABLE %s (
+	x INT,
+	CONSTRAINT fk_ref FOREIGN KEY (x) REFERENCES items (c) ON DELETE CASCADE ON UPDATE SET NULL
+)`,
+			expect: `CREATE TABLE %s (
+	x INT NULL,
+	CONSTRAINT fk_ref FOREIGN KEY (x) REFERENCES items (c) ON DELETE CASCADE ON UPDATE SET NULL,
+	INDEX t11_auto_index_fk_ref (x ASC),
+	FAMILY "primary" (x, rowid)
+)`,
+		},
 	}
 	for i, test := range tests {
 		t.Run(fmt.Sprint(i), func(t *testing.T) {
@@ -275,6 +289,57 @@ func TestShowCreateTable(t *testing.T) {
 	}
 }
 
+func TestShowCreateTableZoneConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, _ := tests.CreateTestServerParams()
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := sqlDB.Exec(`
+		CREATE DATABASE d;
+		SET DATABASE = d;
+		CREATE TABLE t (a INT PRIMARY KEY);
+		ALTER TABLE t CONFIGURE ZONE USING range_min_bytes = 1000000, range_max_bytes = 2000000,
+			gc.ttlseconds = 600, num_replicas = 5, constraints = '[+region=us, -ssd]';
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	// The zone configs are shown as comments, so that the output can be run
+	// as is.
+	const expect = `CREATE TABLE t (
+	a INT NOT NULL,
+	CONSTRAINT "primary" PRIMARY KEY (a ASC),
+	FAMILY "primary" (a)
+)
+-- ALTER TABLE t CONFIGURE ZONE USING range_min_bytes = 1000000, range_max_bytes = 2000000, ` +
+		`gc.ttlseconds = 600, num_replicas = 5, constraints = '[+region=us, -ssd]';`
+
+	showCreate := func() string {
+		var scanName, create string
+		if err := sqlDB.QueryRow(`SHOW CREATE TABLE t`).Scan(&scanName, &create); err != nil {
+			t.Fatal(err)
+		}
+		return create
+	}
+	if create := showCreate(); create != expect {
+		t.Fatalf("got: %s\nexpected: %s", create, expect)
+	}
+
+	// Re-create the table along with its zone config to make sure the
+	// statements are round-trippable.
+	if _, err := sqlDB.Exec(`DROP TABLE t`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(strings.Replace(expect, "\n-- ", ";\n", -1)); err != nil {
+		t.Fatalf("reinsert failure: %s", err)
+	}
+	if create := showCreate(); create != expect {
+		t.Fatalf("round trip statement: %s\ngot: %s", expect, create)
+	}
+}
+
 func TestShowCreateView(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -369,6 +434,200 @@ func TestShowCreateView(t *testing.T) {
 	}
 }
 
+func TestShowCreateSequence(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, _ := tests.CreateTestServerParams()
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := sqlDB.Exec(`
+		CREATE DATABASE d;
+		SET DATABASE = d;
+		CREATE TABLE t (a INT PRIMARY KEY);
+		CREATE SEQUENCE seq;
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		create   string
+		expected string
+	}{
+		{
+			`CREATE SEQUENCE %s`,
+			`CREATE SEQUENCE %s MINVALUE 1 MAXVALUE 9223372036854775807 INCREMENT 1 START 1`,
+		},
+		{
+			`CREATE SEQUENCE %s INCREMENT -2 START -10`,
+			`CREATE SEQUENCE %s MINVALUE -9223372036854775808 MAXVALUE -1 INCREMENT -2 START -10`,
+		},
+		{
+			`CREATE SEQUENCE %s MINVALUE 5 MAXVALUE 10 START 5 CYCLE`,
+			`CREATE SEQUENCE %s MINVALUE 5 MAXVALUE 10 INCREMENT 1 START 5 CYCLE`,
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			name := fmt.Sprintf("s%d", i)
+			stmt := fmt.Sprintf(test.create, name)
+			expect := fmt.Sprintf(test.expected, name)
+			if _, err := sqlDB.Exec(stmt); err != nil {
+				t.Fatal(err)
+			}
+			// The statement recreates the sequence as it was created, regardless
+			// of the values it has since produced.
+			if _, err := sqlDB.Exec(fmt.Sprintf("SELECT nextval('%s')", name)); err != nil {
+				t.Fatal(err)
+			}
+			row := sqlDB.QueryRow(fmt.Sprintf("SHOW CREATE SEQUENCE %s", name))
+			var scanName, create string
+			if err := row.Scan(&scanName, &create); err != nil {
+				t.Fatal(err)
+			}
+			if scanName != name {
+				t.Fatalf("expected sequence name %s, got %s", name, scanName)
+			}
+			if create != expect {
+				t.Fatalf("statement: %s\ngot: %s\nexpected: %s", stmt, create, expect)
+			}
+			if _, err := sqlDB.Exec(fmt.Sprintf("DROP SEQUENCE %s", name)); err != nil {
+				t.Fatal(err)
+			}
+			// Re-insert to make sure it's round-trippable.
+			name += "_2"
+			expect = fmt.Sprintf(test.expected, name)
+			if _, err := sqlDB.Exec(expect); err != nil {
+				t.Fatalf("reinsert failure: %s: %s", expect, err)
+			}
+			row = sqlDB.QueryRow(fmt.Sprintf("SHOW CREATE SEQUENCE %s", name))
+			if err := row.Scan(&scanName, &create); err != nil {
+				t.Fatal(err)
+			}
+			if create != expect {
+				t.Fatalf("round trip statement: %s\ngot: %s", expect, create)
+			}
+			if _, err := sqlDB.Exec(fmt.Sprintf("DROP SEQUENCE %s", name)); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	if _, err := sqlDB.Exec(`SHOW CREATE SEQUENCE t`); !testutils.IsError(err, `d.t is not a sequence`) {
+		t.Fatalf("expected not a sequence error, got %v", err)
+	}
+	// SHOW CREATE TABLE also shows the sequences, which are tables.
+	var scanName, create string
+	if err := sqlDB.QueryRow(`SHOW CREATE TABLE seq`).Scan(&scanName, &create); err != nil {
+		t.Fatal(err)
+	}
+	if expect := `CREATE SEQUENCE seq MINVALUE 1 MAXVALUE 9223372036854775807 INCREMENT 1 START 1`; create != expect {
+		t.Fatalf("expected %s, got %s", expect, create)
+	}
+}
+
+func TestShowDump(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, _ := tests.CreateTestServerParams()
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := sqlDB.Exec(`
+		CREATE DATABASE d;
+		SET DATABASE = d;
+		CREATE SEQUENCE s;
+		SELECT nextval('s');
+		SELECT nextval('s');
+		CREATE TABLE parent (a INT PRIMARY KEY, b STRING, c BYTES);
+		CREATE TABLE a_child (x INT PRIMARY KEY, a INT REFERENCES parent, INDEX (a));
+		CREATE VIEW v AS SELECT a FROM parent;
+		INSERT INTO parent VALUES (1, e'tab\there', b'\x01'), (2, NULL, NULL);
+		INSERT INTO a_child VALUES (10, 1);
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	showCreate := func(kind, name string) string {
+		var scanName, create string
+		if err := sqlDB.QueryRow(fmt.Sprintf("SHOW CREATE %s %s", kind, name)).Scan(
+			&scanName, &create,
+		); err != nil {
+			t.Fatal(err)
+		}
+		return create + ";"
+	}
+	showDump := func(stmt string) []string {
+		rows, err := sqlDB.Query(stmt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var lines []string
+		for rows.Next() {
+			var line string
+			if err := rows.Scan(&line); err != nil {
+				t.Fatal(err)
+			}
+			lines = append(lines, line)
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return lines
+	}
+
+	// The sequence comes first, and the referenced table before the table
+	// which references it, even though it sorts after it.
+	schema := []string{
+		"CREATE SEQUENCE s MINVALUE 1 MAXVALUE 9223372036854775807 INCREMENT 1 START 3;",
+		showCreate("TABLE", "parent"),
+		showCreate("TABLE", "a_child"),
+		showCreate("VIEW", "v"),
+	}
+	parentData := []string{
+		"COPY parent (a, b, c) FROM stdin;",
+		"1\ttab\\there\t\x01",
+		"2\t\\N\t\\N",
+	}
+	childData := []string{
+		`\.`,
+		"COPY a_child (x, a) FROM stdin;",
+		"10\t1",
+		`\.`,
+	}
+
+	var ts string
+	if err := sqlDB.QueryRow(`SELECT cluster_logical_timestamp()`).Scan(&ts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(`INSERT INTO parent VALUES (3, 'three', NULL)`); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected []string
+	expected = append(expected, schema...)
+	expected = append(expected, parentData...)
+	expected = append(expected, "3\tthree\t\\N")
+	expected = append(expected, childData...)
+	if lines := showDump(`SHOW DUMP`); !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+
+	expected = append([]string(nil), schema...)
+	expected = append(expected, parentData...)
+	expected = append(expected, childData...)
+	if lines := showDump(
+		fmt.Sprintf(`SHOW DUMP FROM d AS OF SYSTEM TIME '%s'`, ts),
+	); !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+
+	if _, err := sqlDB.Exec(`SHOW DUMP FROM nope`); !testutils.IsError(err, `database "nope" does not exist`) {
+		t.Fatalf("expected undefined database error, got %v", err)
+	}
+}
+
 func TestShowQueries(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -0,0 +1,137 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// slowQueryLogThreshold is the service latency above which the statements
+// are logged to the slow query log.
+var slowQueryLogThreshold = settings.RegisterDurationSetting(
+	"sql.log.slow_query.latency_threshold",
+	"when set to non-zero, log the statements whose service latency exceeds "+
+		"the threshold to the slow query log",
+	0,
+)
+
+// slowQueryLogger receives the entries of the slow query log. Unlike the
+// audit log, its entries aren't synced to disk before the statements return,
+// since that would make the slow statements slower still.
+var slowQueryLogger = log.NewSecondaryLogger("sql-slow", false /* forceSyncWrites */)
+
+// slowQueryLogEntry is an entry of the slow query log, logged as JSON. The
+// latencies are in seconds.
+type slowQueryLogEntry struct {
+	Statement       string  `json:"statement"`
+	Plan            string  `json:"plan"`
+	User            string  `json:"user"`
+	ApplicationName string  `json:"application_name"`
+	SessionID       int64   `json:"session_id"`
+	ClientAddress   string  `json:"client_address"`
+	Distributed     bool    `json:"distributed"`
+	Retries         int     `json:"retries"`
+	Rows            int     `json:"rows"`
+	Error           string  `json:"error,omitempty"`
+	ServiceLatency  float64 `json:"service_latency"`
+	ParseLatency    float64 `json:"parse_latency"`
+	PlanLatency     float64 `json:"plan_latency"`
+	RunLatency      float64 `json:"run_latency"`
+	OverheadLatency float64 `json:"overhead_latency"`
+}
+
+// maybeLogSlowQuery logs the statement to the slow query log if its service
+// latency exceeds sql.log.slow_query.latency_threshold.
+func (e *Executor) maybeLogSlowQuery(
+	ctx context.Context,
+	planner *planner,
+	stmt Statement,
+	plan planNode,
+	distSQLUsed bool,
+	automaticRetryCount int,
+	numRows int,
+	err error,
+	parseLat, planLat, runLat, svcLat, ovhLat float64,
+) {
+	threshold := slowQueryLogThreshold.Get(&e.cfg.Settings.SV)
+	if threshold == 0 || svcLat < threshold.Seconds() {
+		return
+	}
+	session := planner.session
+	entry := slowQueryLogEntry{
+		Statement:       stmt.String(),
+		Plan:            planSummary(ctx, plan),
+		User:            session.User,
+		ApplicationName: session.appNameTag.String(),
+		SessionID:       session.id,
+		ClientAddress:   session.ClientAddr,
+		Distributed:     distSQLUsed,
+		Retries:         automaticRetryCount,
+		Rows:            numRows,
+		ServiceLatency:  svcLat,
+		ParseLatency:    parseLat,
+		PlanLatency:     planLat,
+		RunLatency:      runLat,
+		OverheadLatency: ovhLat,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	b, jsonErr := json.Marshal(entry)
+	if jsonErr != nil {
+		log.Warningf(ctx, "unable to log slow query: %v", jsonErr)
+		return
+	}
+	slowQueryLogger.Logf(ctx, "%s", b)
+}
+
+// planSummary returns a compact representation of the shape of a plan, made
+// of the names of its nodes, with the children of each node between
+// parentheses, for instance "render(join(scan, scan))".
+func planSummary(ctx context.Context, plan planNode) string {
+	var buf bytes.Buffer
+	// children holds the number of children visited so far for each node on
+	// the path to the current node.
+	var children []int
+	_ = walkPlan(ctx, plan, planObserver{
+		enterNode: func(_ context.Context, name string, _ planNode) bool {
+			if n := len(children); n > 0 {
+				if children[n-1] == 0 {
+					buf.WriteByte('(')
+				} else {
+					buf.WriteString(", ")
+				}
+				children[n-1]++
+			}
+			buf.WriteString(name)
+			children = append(children, 0)
+			return true
+		},
+		leaveNode: func(string) {
+			n := len(children)
+			if children[n-1] > 0 {
+				buf.WriteByte(')')
+			}
+			children = children[:n-1]
+		},
+	})
+	return buf.String()
+}
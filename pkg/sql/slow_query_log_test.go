@@ -0,0 +1,102 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func TestSlowQueryLog(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := log.Scope(t)
+	defer s.Close(t)
+
+	srv, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer srv.Stopper().Stop(context.TODO())
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.kv (k INT PRIMARY KEY, v INT);
+SET application_name = 'slow';
+SET CLUSTER SETTING sql.log.slow_query.latency_threshold = '1ns';
+SELECT v FROM t.kv WHERE k = 1;
+`); err != nil {
+		t.Fatal(err)
+	}
+	log.Flush()
+
+	files, err := log.ListLogFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contents []byte
+	for _, f := range files {
+		if !strings.Contains(f.Name, "-sql-slow.") {
+			continue
+		}
+		r, err := log.GetLogReader(f.Name, true /* restricted */)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, b...)
+	}
+
+	const stmt = `SELECT v FROM t.kv WHERE k = 1`
+	for _, line := range strings.Split(string(contents), "\n") {
+		i := strings.Index(line, "{")
+		if i < 0 {
+			continue
+		}
+		var entry struct {
+			Statement       string  `json:"statement"`
+			Plan            string  `json:"plan"`
+			User            string  `json:"user"`
+			ApplicationName string  `json:"application_name"`
+			ServiceLatency  float64 `json:"service_latency"`
+		}
+		if err := json.Unmarshal([]byte(line[i:]), &entry); err != nil {
+			t.Fatalf("invalid slow query log entry %q: %v", line, err)
+		}
+		if entry.Statement != stmt {
+			continue
+		}
+		if entry.User != "root" || entry.ApplicationName != "slow" {
+			t.Errorf("unexpected user %q and application %q", entry.User, entry.ApplicationName)
+		}
+		if !strings.Contains(entry.Plan, "scan") {
+			t.Errorf("expected a scan in the plan, got %q", entry.Plan)
+		}
+		if entry.ServiceLatency <= 0 {
+			t.Errorf("expected a positive service latency, got %f", entry.ServiceLatency)
+		}
+		return
+	}
+	t.Fatalf("slow query log is missing %q:\n%s", stmt, contents)
+}
@@ -229,7 +229,8 @@ func (p *planner) orderBy(
 		}
 
 		if index == -1 {
-			return nil, errors.Errorf("column %s does not exist", expr)
+			return nil, pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
+				"column %s does not exist", expr)
 		}
 		ordering = append(ordering,
 			sqlbase.ColumnOrderInfo{ColIdx: index, Direction: direction})
@@ -715,6 +716,10 @@ var _ valueIterator = &sortValues{}
 var _ sort.Interface = &sortValues{}
 var _ heap.Interface = &sortValues{}
 
+// maxSortCapacityHint is the maximum number of rows for which a sortValues
+// preallocates space when the number of rows to sort is bounded by a limit.
+const maxSortCapacityHint = 1024
+
 func (p *planner) newSortValues(
 	ordering sqlbase.ColumnOrdering, columns sqlbase.ResultColumns, capacity int,
 ) *sortValues {
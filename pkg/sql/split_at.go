@@ -384,7 +384,7 @@ func (p *planner) Scatter(ctx context.Context, n *tree.Scatter) (planNode, error
 				return nil, err
 			}
 		}
-		toVals := make([]tree.Datum, len(n.From))
+		toVals := make([]tree.Datum, len(n.To))
 		for i, expr := range n.To {
 			typedExpr, err := p.analyzeExpr(
 				ctx, expr, nil, tree.IndexedVarHelper{}, desiredTypes[i], true, "SCATTER",
@@ -163,12 +163,12 @@ func TestScatter(t *testing.T) {
 
 	// Ensure that scattering leaves each node with at least 20% of the leases.
 	r.Exec(t, "ALTER TABLE test.t SCATTER")
-	rows := r.Query(t, "SHOW TESTING_RANGES FROM TABLE test.t")
+	rows := r.Query(t, "SHOW RANGES FROM TABLE test.t")
 	// See showRangesColumns for the schema.
 	if cols, err := rows.Columns(); err != nil {
 		t.Fatal(err)
-	} else if len(cols) != 5 {
-		t.Fatalf("expected 4 columns, got %#v", cols)
+	} else if len(cols) != 6 {
+		t.Fatalf("expected 6 columns, got %#v", cols)
 	}
 	vals := []interface{}{
 		new(interface{}),
@@ -176,6 +176,7 @@ func TestScatter(t *testing.T) {
 		new(interface{}),
 		new(interface{}),
 		new(int),
+		new(interface{}),
 	}
 	leaseHolders := map[int]int{1: 0, 2: 0, 3: 0, 4: 0}
 	numRows := 0
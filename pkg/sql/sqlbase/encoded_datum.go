@@ -427,11 +427,17 @@ func (r EncDatumRows) String(types []ColumnType) string {
 // (preferably of the same length).
 type EncDatumRowAlloc struct {
 	buf []EncDatum
+	// chunkRows is the number of rows that fit in the last allocated chunk.
+	chunkRows int
 	// Preallocate a small initial batch (helps cases where
 	// we only allocate a few small rows).
 	prealloc [16]EncDatum
 }
 
+// maxEncDatumRowAllocSize bounds the number of EncDatums in the chunks
+// allocated by EncDatumRowAlloc, unless a single row is larger than that.
+const maxEncDatumRowAllocSize = 1024
+
 // AllocRow allocates an EncDatumRow with the given number of columns.
 func (a *EncDatumRowAlloc) AllocRow(cols int) EncDatumRow {
 	if a.buf == nil {
@@ -440,13 +446,20 @@ func (a *EncDatumRowAlloc) AllocRow(cols int) EncDatumRow {
 	}
 	if len(a.buf) < cols {
 		// If the rows are small, allocate storage for a bunch of rows at once.
-		bufLen := cols
-		if cols <= 16 {
-			bufLen *= 16
-		} else if cols <= 64 {
-			bufLen *= 4
+		// The number of rows per chunk doubles with every chunk, so that
+		// callers that allocate many rows (e.g. to buffer the results of a
+		// large scan) allocate few, larger chunks.
+		if a.chunkRows == 0 {
+			a.chunkRows = 1
+			if cols <= 16 {
+				a.chunkRows = 16
+			} else if cols <= 64 {
+				a.chunkRows = 4
+			}
+		} else if 2*a.chunkRows*cols <= maxEncDatumRowAllocSize {
+			a.chunkRows *= 2
 		}
-		a.buf = make([]EncDatum, bufLen)
+		a.buf = make([]EncDatum, a.chunkRows*cols)
 	}
 	// Chop off a row from buf, and limit its capacity to avoid corrupting the
 	// following row in the unlikely case that the caller appends to the slice.
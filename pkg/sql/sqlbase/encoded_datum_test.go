@@ -445,7 +445,7 @@ func TestEncDatumRowAlloc(t *testing.T) {
 	defer evalCtx.Stop(context.Background())
 	rng, _ := randutil.NewPseudoRand()
 	for _, cols := range []int{1, 2, 4, 10, 40, 100} {
-		for _, rows := range []int{1, 2, 3, 5, 10, 20} {
+		for _, rows := range []int{1, 2, 3, 5, 10, 20, 500} {
 			colTypes := RandColumnTypes(rng, cols)
 			in := make(EncDatumRows, rows)
 			for i := 0; i < rows; i++ {
@@ -68,24 +68,37 @@ func NewTransactionCommittedError() error {
 	return pgerror.NewError(pgerror.CodeInvalidTransactionStateError, txnCommittedMsg)
 }
 
-// NewNonNullViolationError creates an error for a violation of a non-NULL constraint.
-func NewNonNullViolationError(columnName string) error {
-	return pgerror.NewErrorf(pgerror.CodeNotNullViolationError, "null value in column %q violates not-null constraint", columnName)
+// NewNonNullViolationError creates an error for a violation of a non-NULL
+// constraint on a column of a table.
+func NewNonNullViolationError(tableName, columnName string) error {
+	err := pgerror.NewErrorf(pgerror.CodeNotNullViolationError,
+		"null value in column %q violates not-null constraint", columnName)
+	err.TableName = tableName
+	err.ColumnName = columnName
+	return err
 }
 
 // NewUniquenessConstraintViolationError creates an error that represents a
-// violation of a UNIQUE constraint.
-func NewUniquenessConstraintViolationError(index *IndexDescriptor, vals []tree.Datum) error {
+// violation of a UNIQUE constraint. Like postgres, the error details the
+// conflicting key.
+func NewUniquenessConstraintViolationError(
+	tableDesc *TableDescriptor, index *IndexDescriptor, vals []tree.Datum,
+) error {
 	valStrs := make([]string, 0, len(vals))
 	for _, val := range vals {
 		valStrs = append(valStrs, val.String())
 	}
 
-	return pgerror.NewErrorf(pgerror.CodeUniqueViolationError,
+	err := pgerror.NewErrorf(pgerror.CodeUniqueViolationError,
 		"duplicate key value (%s)=(%s) violates unique constraint %q",
 		strings.Join(index.ColumnNames, ","),
 		strings.Join(valStrs, ","),
 		index.Name)
+	err.SetDetailf("Key (%s)=(%s) already exists.",
+		strings.Join(index.ColumnNames, ", "), strings.Join(valStrs, ", "))
+	err.TableName = tableDesc.Name
+	err.ConstraintName = index.Name
+	return err
 }
 
 // IsUniquenessConstraintViolationError returns true if the error is for a
@@ -101,11 +114,27 @@ func NewInvalidSchemaDefinitionError(err error) error {
 }
 
 // IsPermanentSchemaChangeError returns true if the error results in
-// a permanent failure of a schema change.
+// a permanent failure of a schema change. These are the errors caused by the
+// data of the table or by the definition of the schema change, such as
+// integrity constraint violations and errors evaluating the new values of a
+// column, which would be hit again if the schema change were retried. The
+// schema change is rolled back instead.
 func IsPermanentSchemaChangeError(err error) bool {
-	return errHasCode(err, pgerror.CodeNotNullViolationError) ||
-		errHasCode(err, pgerror.CodeUniqueViolationError) ||
-		errHasCode(err, pgerror.CodeInvalidSchemaDefinitionError)
+	pgErr, ok := pgerror.GetPGCause(err)
+	if !ok {
+		return false
+	}
+	if errHasClass(pgErr, pgerror.CodeDataExceptionError) ||
+		errHasClass(pgErr, pgerror.CodeIntegrityConstraintViolationError) {
+		return true
+	}
+	return pgErr.Code == pgerror.CodeInvalidSchemaDefinitionError
+}
+
+// errHasClass returns true if the error code is in the same class as the
+// given code, i.e. if their first two characters are the same.
+func errHasClass(pgErr *pgerror.Error, code string) bool {
+	return len(pgErr.Code) == len(code) && pgErr.Code[:2] == code[:2]
 }
 
 // NewUndefinedDatabaseError creates an error that represents a missing database.
@@ -301,7 +330,7 @@ func ConvertBatchError(ctx context.Context, tableDesc *TableDescriptor, b *clien
 		if err != nil {
 			return err
 		}
-		return NewUniquenessConstraintViolationError(index, datums)
+		return NewUniquenessConstraintViolationError(tableDesc, index, datums)
 	}
 	return origPErr.GoError()
 }
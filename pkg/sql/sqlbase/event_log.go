@@ -0,0 +1,173 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+)
+
+// EventLogInfo is the payload of an event recorded in system.eventlog by a
+// schema change, a change of privileges or roles, or a cluster setting
+// change.
+type EventLogInfo interface {
+	protoutil.Message
+	// EventDetails returns the name of the object the event is about, the
+	// user who caused the event and the statement that caused it. They are
+	// empty for the events that don't record them, like the events of the
+	// schema changer.
+	EventDetails() (target, user, statement string)
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *CreateDatabaseEvent) EventDetails() (string, string, string) {
+	return m.DatabaseName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *DropDatabaseEvent) EventDetails() (string, string, string) {
+	return m.DatabaseName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *RenameDatabaseEvent) EventDetails() (string, string, string) {
+	return m.DatabaseName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *AlterDatabaseEvent) EventDetails() (string, string, string) {
+	return m.DatabaseName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *CreateTableEvent) EventDetails() (string, string, string) {
+	return m.TableName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *DropTableEvent) EventDetails() (string, string, string) {
+	return m.TableName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *AlterTableEvent) EventDetails() (string, string, string) {
+	return m.TableName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *RenameTableEvent) EventDetails() (string, string, string) {
+	return m.TableName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *TruncateTableEvent) EventDetails() (string, string, string) {
+	return m.TableName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *CreateIndexEvent) EventDetails() (string, string, string) {
+	return m.TableName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *DropIndexEvent) EventDetails() (string, string, string) {
+	return m.TableName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *CreateViewEvent) EventDetails() (string, string, string) {
+	return m.ViewName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *DropViewEvent) EventDetails() (string, string, string) {
+	return m.ViewName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *CreateSequenceEvent) EventDetails() (string, string, string) {
+	return m.SequenceName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *AlterSequenceEvent) EventDetails() (string, string, string) {
+	return m.SequenceName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *DropSequenceEvent) EventDetails() (string, string, string) {
+	return m.SequenceName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *FinishSchemaChangeEvent) EventDetails() (string, string, string) {
+	return "", "", ""
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *ReverseSchemaChangeEvent) EventDetails() (string, string, string) {
+	return "", "", ""
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *SetZoneConfigEvent) EventDetails() (string, string, string) {
+	return m.Target, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *SetClusterSettingEvent) EventDetails() (string, string, string) {
+	return m.SettingName, m.User, ""
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *ChangePrivilegesEvent) EventDetails() (string, string, string) {
+	return m.TargetName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *AlterOwnerEvent) EventDetails() (string, string, string) {
+	return m.TargetName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *CreatePolicyEvent) EventDetails() (string, string, string) {
+	return m.TableName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *DropPolicyEvent) EventDetails() (string, string, string) {
+	return m.TableName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *CreateRoleEvent) EventDetails() (string, string, string) {
+	return m.RoleName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *DropRoleEvent) EventDetails() (string, string, string) {
+	return m.RoleName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *AlterRoleEvent) EventDetails() (string, string, string) {
+	return m.RoleName, m.User, m.Statement
+}
+
+// EventDetails implements the EventLogInfo interface.
+func (m *ChangeRoleMembershipEvent) EventDetails() (string, string, string) {
+	return strings.Join(m.Roles, ", "), m.User, m.Statement
+}
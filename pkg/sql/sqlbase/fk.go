@@ -164,7 +164,7 @@ func (f *fkBatchChecker) runCheck(
 				for valueIdx, colID := range fk.searchIdx.ColumnIDs[:fk.prefixLen] {
 					fkValues[valueIdx] = newRow[fk.ids[colID]]
 				}
-				return pgerror.NewErrorf(pgerror.CodeForeignKeyViolationError,
+				return fk.violationError(
 					"foreign key violation: value %s not found in %s@%s %s",
 					fkValues, fk.searchTable.Name, fk.searchIdx.Name, fk.searchIdx.ColumnNames[:fk.prefixLen])
 			}
@@ -172,7 +172,7 @@ func (f *fkBatchChecker) runCheck(
 			// If we're deleting, then there's a violation if the scan found something.
 			if !fk.rf.kvEnd {
 				if oldRow == nil {
-					return pgerror.NewErrorf(pgerror.CodeForeignKeyViolationError,
+					return fk.violationError(
 						"foreign key violation: non-empty columns %s referenced in table %q",
 						fk.writeIdx.ColumnNames[:fk.prefixLen], fk.searchTable.Name)
 				}
@@ -180,7 +180,7 @@ func (f *fkBatchChecker) runCheck(
 				for valueIdx, colID := range fk.searchIdx.ColumnIDs[:fk.prefixLen] {
 					fkValues[valueIdx] = oldRow[fk.ids[colID]]
 				}
-				return pgerror.NewErrorf(pgerror.CodeForeignKeyViolationError,
+				return fk.violationError(
 					"foreign key violation: values %v in columns %s referenced in table %q",
 					fkValues, fk.writeIdx.ColumnNames[:fk.prefixLen], fk.searchTable.Name)
 			}
@@ -217,7 +217,7 @@ func makeFKInsertHelper(
 	}
 	for _, idx := range table.AllNonDropIndexes() {
 		if idx.ForeignKey.IsSet() {
-			fk, err := makeBaseFKHelper(txn, otherTables, idx, idx.ForeignKey, colMap, alloc, CheckInserts)
+			fk, err := makeBaseFKHelper(txn, otherTables, table.Name, idx, idx.ForeignKey, colMap, alloc, CheckInserts)
 			if err == errSkipUnusedFK {
 				continue
 			}
@@ -309,7 +309,7 @@ func makeFKDeleteHelper(
 				// and thus does not need to be checked for FK violations.
 				continue
 			}
-			fk, err := makeBaseFKHelper(txn, otherTables, idx, ref, colMap, alloc, CheckDeletes)
+			fk, err := makeBaseFKHelper(txn, otherTables, table.Name, idx, ref, colMap, alloc, CheckDeletes)
 			if err == errSkipUnusedFK {
 				continue
 			}
@@ -407,22 +407,42 @@ type baseFKHelper struct {
 	searchTable  *TableDescriptor // the table being searched (for err msg)
 	searchIdx    *IndexDescriptor // the index that must (not) contain a value
 	prefixLen    int
+	writeTable   string           // the table we want to modify (for err msg)
 	writeIdx     IndexDescriptor  // the index we want to modify
 	searchPrefix []byte           // prefix of keys in searchIdx
 	ids          map[ColumnID]int // col IDs
 	dir          FKCheck          // direction of check
 }
 
+// violationError creates a pgerror.CodeForeignKeyViolationError for the
+// foreign key checked by the helper. Like postgres, the error names the
+// referencing table and its constraint.
+func (f baseFKHelper) violationError(format string, args ...interface{}) error {
+	err := pgerror.NewErrorf(pgerror.CodeForeignKeyViolationError, format, args...)
+	if f.dir == CheckInserts {
+		err.TableName = f.writeTable
+		err.ConstraintName = f.writeIdx.ForeignKey.Name
+	} else {
+		err.TableName = f.searchTable.Name
+		err.ConstraintName = f.searchIdx.ForeignKey.Name
+	}
+	return err
+}
+
 func makeBaseFKHelper(
 	txn *client.Txn,
 	otherTables TableLookupsByID,
+	writeTable string,
 	writeIdx IndexDescriptor,
 	ref ForeignKeyReference,
 	colMap map[ColumnID]int,
 	alloc *DatumAlloc,
 	dir FKCheck,
 ) (baseFKHelper, error) {
-	b := baseFKHelper{txn: txn, writeIdx: writeIdx, searchTable: otherTables[ref.Table].Table, dir: dir}
+	b := baseFKHelper{
+		txn: txn, writeTable: writeTable, writeIdx: writeIdx,
+		searchTable: otherTables[ref.Table].Table, dir: dir,
+	}
 	if b.searchTable == nil {
 		return b, errors.Errorf("referenced table %d not in provided table map %+v", ref.Table, otherTables)
 	}
@@ -436,12 +456,16 @@ func makeBaseFKHelper(
 		b.prefixLen = len(writeIdx.ColumnIDs)
 	}
 	b.searchIdx = searchIdx
+	// The searched index may be an index being added along with some of its
+	// columns, when it is on the referencing side of a foreign key added by
+	// ALTER TABLE. Its keys are decoded with the columns being added.
+	fetchTable := withMutationColumns(b.searchTable)
 	tableArgs := MultiRowFetcherTableArgs{
-		Desc:             b.searchTable,
+		Desc:             fetchTable,
 		Index:            b.searchIdx,
-		ColIdxMap:        ColIDtoRowIndexFromCols(b.searchTable.Columns),
+		ColIdxMap:        ColIDtoRowIndexFromCols(fetchTable.Columns),
 		IsSecondaryIndex: b.searchIdx.ID != b.searchTable.PrimaryIndex.ID,
-		Cols:             b.searchTable.Columns,
+		Cols:             fetchTable.Columns,
 	}
 	err = b.rf.Init(false /* reverse */, false /* returnRangeInfo */, alloc, tableArgs)
 	if err != nil {
@@ -464,6 +488,23 @@ func makeBaseFKHelper(
 	return b, nil
 }
 
+// withMutationColumns returns desc, or a copy of it in which the columns in
+// its mutations are public if it has any.
+func withMutationColumns(desc *TableDescriptor) *TableDescriptor {
+	var cols []ColumnDescriptor
+	for _, m := range desc.Mutations {
+		if c := m.GetColumn(); c != nil {
+			cols = append(cols, *c)
+		}
+	}
+	if len(cols) == 0 {
+		return desc
+	}
+	withCols := *desc
+	withCols.Columns = append(append([]ColumnDescriptor(nil), desc.Columns...), cols...)
+	return &withCols
+}
+
 func (f baseFKHelper) spanForValues(values tree.Datums) (roachpb.Span, error) {
 	var key roachpb.Key
 	if values != nil {
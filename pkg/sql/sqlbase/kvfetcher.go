@@ -95,6 +95,12 @@ type txnKVFetcher struct {
 	fetchEnd  bool
 	batchIdx  int
 	responses []roachpb.ResponseUnion
+	// lastBatchSize is the limit used for the last batch. fetchedKeys and
+	// fetchedBytes keep track of the number and total size of the keys and
+	// values retrieved so far. They are used to size the next batch.
+	lastBatchSize int64
+	fetchedKeys   int64
+	fetchedBytes  int64
 	kvs       []roachpb.KeyValue
 
 	// As the kvFetcher fetches batches of kvs, it accumulates information on the
@@ -112,25 +118,34 @@ func (f *txnKVFetcher) getRangesInfo() []roachpb.RangeInfo {
 	return f.rangeInfos
 }
 
+// kvBatchTargetBytes is the approximate amount of key/value data that a large
+// scan aims to retrieve in a single batch. Once a scan has fetched a batch of
+// the default size, the following batches double in size as long as the
+// average size of the keys and values seen so far indicates that they won't
+// exceed this target, up to kvBatchMaxGrowth times the default batch size.
+const kvBatchTargetBytes = 16 << 20 // 16MB
+
+// kvBatchMaxGrowth bounds the size of adaptively grown batches as a multiple
+// of kvBatchSize.
+const kvBatchMaxGrowth = 8
+
 // getBatchSize returns the max size of the next batch.
 func (f *txnKVFetcher) getBatchSize() int64 {
 	if !f.useBatchLimit {
 		return 0
 	}
-	if f.firstBatchLimit == 0 || f.firstBatchLimit >= kvBatchSize {
-		return kvBatchSize
-	}
-
-	// We grab the first batch according to the limit. If it turns out that we
-	// need another batch, we grab a bigger batch. If that's still not enough,
-	// we revert to the default batch size.
-	switch f.batchIdx {
-	case 0:
+	if f.batchIdx == 0 {
+		if f.firstBatchLimit == 0 || f.firstBatchLimit >= kvBatchSize {
+			return kvBatchSize
+		}
+		// We grab the first batch according to the limit.
 		return f.firstBatchLimit
+	}
 
-	case 1:
-		// Make the second batch 10 times larger (but at most the default batch
-		// size and at least 1/10 of the default batch size). Sample
+	if f.lastBatchSize < kvBatchSize {
+		// The first batch was limited and it turned out that we need another
+		// batch. Make the next batch 10 times larger (but at most the default
+		// batch size and at least 1/10 of the default batch size). Sample
 		// progressions of batch sizes:
 		//
 		//  First batch | Second batch | Subsequent batches
@@ -139,19 +154,33 @@ func (f *txnKVFetcher) getBatchSize() int64 {
 		//       100    |     1,000     |     10,000
 		//       500    |     5,000     |     10,000
 		//      1000    |    10,000     |     10,000
-		secondBatch := f.firstBatchLimit * 10
+		size := f.lastBatchSize * 10
 		switch {
-		case secondBatch < kvBatchSize/10:
+		case size < kvBatchSize/10:
 			return kvBatchSize / 10
-		case secondBatch > kvBatchSize:
+		case size > kvBatchSize:
 			return kvBatchSize
 		default:
-			return secondBatch
+			return size
 		}
+	}
 
-	default:
-		return kvBatchSize
+	// This is a large scan. Double the batch size to reduce the number of round
+	// trips, unless the keys and values are large enough that the batch would
+	// take up too much memory.
+	size := f.lastBatchSize * 2
+	if maxSize := kvBatchSize * kvBatchMaxGrowth; size > maxSize {
+		size = maxSize
+	}
+	if f.fetchedKeys > 0 {
+		if avgBytes := f.fetchedBytes / f.fetchedKeys; avgBytes > 0 && size*avgBytes > kvBatchTargetBytes {
+			size = kvBatchTargetBytes / avgBytes
+		}
+	}
+	if size < kvBatchSize {
+		size = kvBatchSize
 	}
+	return size
 }
 
 // makeKVFetcher initializes a kvFetcher for the given spans.
@@ -208,6 +237,7 @@ func makeKVFetcher(
 func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	var ba roachpb.BatchRequest
 	ba.Header.MaxSpanRequestKeys = f.getBatchSize()
+	f.lastBatchSize = ba.Header.MaxSpanRequestKeys
 	ba.Header.ReturnRangeInfo = f.returnRangeInfo
 	ba.Requests = make([]roachpb.RequestUnion, len(f.spans))
 	if f.reverse {
@@ -266,6 +296,18 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 			sawResumeSpan = true
 		}
 
+		var rows []roachpb.KeyValue
+		switch t := reply.(type) {
+		case *roachpb.ScanResponse:
+			rows = t.Rows
+		case *roachpb.ReverseScanResponse:
+			rows = t.Rows
+		}
+		f.fetchedKeys += int64(len(rows))
+		for i := range rows {
+			f.fetchedBytes += int64(len(rows[i].Key) + len(rows[i].Value.RawBytes))
+		}
+
 		// Fill up the RangeInfos, in case we got any.
 		if f.returnRangeInfo {
 			for _, ri := range header.RangeInfos {
@@ -312,3 +354,23 @@ func (f *txnKVFetcher) nextKV(ctx context.Context) (bool, roachpb.KeyValue, erro
 		}
 	}
 }
+
+// SpanKVFetcher is a kvFetcher that returns a set slice of kvs.
+type SpanKVFetcher struct {
+	KVs []roachpb.KeyValue
+}
+
+// nextKV implements the kvFetcher interface.
+func (f *SpanKVFetcher) nextKV(ctx context.Context) (bool, roachpb.KeyValue, error) {
+	if len(f.KVs) == 0 {
+		return false, roachpb.KeyValue{}, nil
+	}
+	var kv roachpb.KeyValue
+	kv, f.KVs = f.KVs[0], f.KVs[1:]
+	return true, kv, nil
+}
+
+// getRangesInfo implements the kvFetcher interface.
+func (f *SpanKVFetcher) getRangesInfo() []roachpb.RangeInfo {
+	panic("getRangesInfo() called on SpanKVFetcher")
+}
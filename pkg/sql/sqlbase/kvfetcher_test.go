@@ -0,0 +1,82 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestKVFetcherBatchSize(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	defer SetKVBatchSize(10000)()
+
+	testCases := []struct {
+		firstBatchLimit int64
+		// kvBytes is the size of each key/value pair returned by the scan.
+		kvBytes  int64
+		expected []int64
+	}{
+		{
+			firstBatchLimit: 0,
+			kvBytes:         100,
+			expected:        []int64{10000, 20000, 40000, 80000, 80000},
+		},
+		{
+			firstBatchLimit: 1,
+			kvBytes:         100,
+			expected:        []int64{1, 1000, 10000, 20000, 40000},
+		},
+		{
+			firstBatchLimit: 500,
+			kvBytes:         100,
+			expected:        []int64{500, 5000, 10000, 20000, 40000},
+		},
+		{
+			// Large keys and values limit the growth of the batches.
+			firstBatchLimit: 0,
+			kvBytes:         1000,
+			expected:        []int64{10000, 16777, 16777, 16777},
+		},
+		{
+			// Batches never get smaller than the default batch size.
+			firstBatchLimit: 0,
+			kvBytes:         10000,
+			expected:        []int64{10000, 10000, 10000},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d/%d", tc.firstBatchLimit, tc.kvBytes), func(t *testing.T) {
+			f := txnKVFetcher{useBatchLimit: true, firstBatchLimit: tc.firstBatchLimit}
+			var sizes []int64
+			for range tc.expected {
+				size := f.getBatchSize()
+				sizes = append(sizes, size)
+				// Simulate a full batch being fetched.
+				f.lastBatchSize = size
+				f.fetchedKeys += size
+				f.fetchedBytes += size * tc.kvBytes
+				f.batchIdx++
+			}
+			if !reflect.DeepEqual(sizes, tc.expected) {
+				t.Errorf("expected batch sizes %v, found %v", tc.expected, sizes)
+			}
+		})
+	}
+}
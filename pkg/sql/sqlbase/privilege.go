@@ -104,6 +104,20 @@ func NewDefaultPrivilegeDescriptor() *PrivilegeDescriptor {
 	}
 }
 
+// DefaultPublicDatabasePrivileges are the privileges of the public role on
+// new databases: like in postgres, every user can connect to them and access
+// their objects, subject to the privileges on the objects.
+var DefaultPublicDatabasePrivileges = privilege.List{privilege.CONNECT, privilege.USAGE}
+
+// NewDefaultDatabasePrivilegeDescriptor returns the privilege descriptor of
+// a new database: ALL privileges for the root user, and the
+// DefaultPublicDatabasePrivileges for the public role.
+func NewDefaultDatabasePrivilegeDescriptor() *PrivilegeDescriptor {
+	p := NewDefaultPrivilegeDescriptor()
+	p.Grant(security.PublicRole, DefaultPublicDatabasePrivileges)
+	return p
+}
+
 // Grant adds new privileges to this descriptor for a given list of users.
 // TODO(marc): if all privileges other than ALL are set, should we collapse
 // them into ALL?
@@ -126,7 +140,11 @@ func (p *PrivilegeDescriptor) Grant(user string, privList privilege.List) {
 }
 
 // Revoke removes privileges from this descriptor for a given list of users.
-func (p *PrivilegeDescriptor) Revoke(user string, privList privilege.List) {
+// The type of the object of the descriptor determines the privileges kept by
+// the users with ALL privileges.
+func (p *PrivilegeDescriptor) Revoke(
+	user string, privList privilege.List, objectType privilege.ObjectType,
+) {
 	userPriv, ok := p.findUser(user)
 	if !ok || userPriv.Privileges == 0 {
 		// Removing privileges from a user without privileges is a no-op.
@@ -146,7 +164,7 @@ func (p *PrivilegeDescriptor) Revoke(user string, privList privilege.List) {
 		// User has 'ALL' privilege. Remove it and set
 		// all other privileges one.
 		userPriv.Privileges = 0
-		for _, v := range privilege.ByValue {
+		for _, v := range privilege.GetValidPrivilegesForObject(objectType) {
 			if v != privilege.ALL {
 				userPriv.Privileges |= v.Mask()
 			}
@@ -183,6 +201,12 @@ func (p PrivilegeDescriptor) Validate(id ID) error {
 				security.RootUser, allowedPrivileges)
 		}
 
+		// System objects are owned by root, which doesn't have all the
+		// privileges on them.
+		if p.Owner != "" {
+			return fmt.Errorf("system object must not have owner %s", p.Owner)
+		}
+
 		// For all users, no other privileges must be granted.
 		if !isPrivilegeSet(rootPriv.Privileges, privilege.ALL) {
 			for _, u := range p.Users {
@@ -200,6 +224,23 @@ func (p PrivilegeDescriptor) Validate(id ID) error {
 	return nil
 }
 
+// ColumnPrivileges are the privileges that can be granted on a column.
+var ColumnPrivileges = privilege.List{privilege.SELECT}
+
+// ValidateColumn is called when writing the descriptor of a table with
+// privileges granted on the given column. Only the ColumnPrivileges can be
+// granted on a column.
+func (p PrivilegeDescriptor) ValidateColumn(column string) error {
+	allowed := ColumnPrivileges.ToBitField()
+	for _, u := range p.Users {
+		if remaining := u.Privileges &^ allowed; remaining != 0 {
+			return fmt.Errorf("user %s must not have %s privileges on column %q",
+				u.User, privilege.ListFromBitField(remaining), column)
+		}
+	}
+	return nil
+}
+
 // UserPrivilegeString is a pair of strings describing the
 // privileges for a given user.
 type UserPrivilegeString struct {
@@ -225,12 +266,35 @@ func (p PrivilegeDescriptor) Show() []UserPrivilegeString {
 	return ret
 }
 
-// CheckPrivilege returns true if 'user' has 'privilege' on this descriptor.
+// GetOwner returns the owner of the object: root if no owner is recorded.
+func (p PrivilegeDescriptor) GetOwner() string {
+	if p.Owner == "" {
+		return security.RootUser
+	}
+	return p.Owner
+}
+
+// CheckPrivilege returns true if 'user' has 'privilege' on this descriptor,
+// either as the owner of the object, directly or through the public role.
 func (p PrivilegeDescriptor) CheckPrivilege(user string, priv privilege.Kind) bool {
+	if p.Owner != "" && p.Owner == user {
+		return true
+	}
+	if user == security.NodeUser {
+		if _, ok := p.findUser(user); !ok {
+			// User "node" has all privileges.
+			return true
+		}
+	}
+	return p.checkUserPrivilege(user, priv) || p.checkUserPrivilege(security.PublicRole, priv)
+}
+
+// checkUserPrivilege returns true if 'user' is granted 'privilege' on this
+// descriptor.
+func (p PrivilegeDescriptor) checkUserPrivilege(user string, priv privilege.Kind) bool {
 	userPriv, ok := p.findUser(user)
 	if !ok {
-		// User "node" has all privileges.
-		return user == security.NodeUser
+		return false
 	}
 	// ALL is always good.
 	if isPrivilegeSet(userPriv.Privileges, privilege.ALL) {
@@ -239,8 +303,24 @@ func (p PrivilegeDescriptor) CheckPrivilege(user string, priv privilege.Kind) bo
 	return isPrivilegeSet(userPriv.Privileges, priv)
 }
 
-// AnyPrivilege returns true if 'user' has any privilege on this descriptor.
+// ValidatePrivileges checks that the privileges can be granted on the given
+// type of object.
+func ValidatePrivileges(privList privilege.List, objectType privilege.ObjectType) error {
+	allowed := privilege.GetValidPrivilegesForObject(objectType).ToBitField()
+	for _, priv := range privList {
+		if priv.Mask()&^allowed != 0 {
+			return fmt.Errorf("invalid privilege type %s for %s", priv, objectType)
+		}
+	}
+	return nil
+}
+
+// AnyPrivilege returns true if 'user' owns the object or has any privilege
+// on this descriptor.
 func (p PrivilegeDescriptor) AnyPrivilege(user string) bool {
+	if p.Owner != "" && p.Owner == user {
+		return true
+	}
 	userPriv, ok := p.findUser(user)
 	if !ok {
 		return false
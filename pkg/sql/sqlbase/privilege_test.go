@@ -69,7 +69,7 @@ func TestPrivilege(t *testing.T) {
 				descriptor.Grant(tc.grantee, tc.grant)
 			}
 			if tc.revoke != nil {
-				descriptor.Revoke(tc.grantee, tc.revoke)
+				descriptor.Revoke(tc.grantee, tc.revoke, privilege.Table)
 			}
 		}
 		show := descriptor.Show()
@@ -159,6 +159,89 @@ func TestAnyPrivilege(t *testing.T) {
 	}
 }
 
+// TestPublicRolePrivileges verifies that the privileges granted to the public
+// role apply to every user, and that revoking ALL from a user keeps the
+// privileges valid for the type of the object.
+func TestPublicRolePrivileges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	descriptor := NewDefaultDatabasePrivilegeDescriptor()
+	for _, priv := range DefaultPublicDatabasePrivileges {
+		if !descriptor.CheckPrivilege("foo", priv) {
+			t.Errorf("expected foo to have %s privilege", priv)
+		}
+	}
+	if descriptor.CheckPrivilege("foo", privilege.CREATE) {
+		t.Error("unexpected CREATE privilege for foo")
+	}
+	if descriptor.AnyPrivilege("foo") {
+		t.Error("unexpected privileges for foo")
+	}
+
+	descriptor.Grant("foo", privilege.List{privilege.ALL})
+	descriptor.Revoke("foo", privilege.List{privilege.USAGE}, privilege.Database)
+	descriptor.Revoke(security.PublicRole, privilege.List{privilege.USAGE}, privilege.Database)
+	if descriptor.CheckPrivilege("foo", privilege.USAGE) {
+		t.Error("unexpected USAGE privilege for foo")
+	}
+	if !descriptor.CheckPrivilege("foo", privilege.CONNECT) {
+		t.Error("expected foo to have CONNECT privilege")
+	}
+
+	table := NewDefaultPrivilegeDescriptor()
+	table.Grant("foo", privilege.List{privilege.ALL})
+	table.Revoke("foo", privilege.List{privilege.SELECT}, privilege.Table)
+	if table.CheckPrivilege("foo", privilege.CONNECT) {
+		t.Error("unexpected CONNECT privilege for foo on table")
+	}
+}
+
+func TestOwnerPrivileges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	descriptor := NewDefaultPrivilegeDescriptor()
+	if owner := descriptor.GetOwner(); owner != security.RootUser {
+		t.Errorf("expected the default owner to be %s, got %s", security.RootUser, owner)
+	}
+	if descriptor.AnyPrivilege("foo") {
+		t.Error("unexpected privilege for foo")
+	}
+
+	descriptor.Owner = "foo"
+	for _, priv := range []privilege.Kind{privilege.SELECT, privilege.DROP, privilege.GRANT} {
+		if !descriptor.CheckPrivilege("foo", priv) {
+			t.Errorf("expected the owner foo to have %s privilege", priv)
+		}
+	}
+	if !descriptor.AnyPrivilege("foo") {
+		t.Error("expected the owner foo to have privileges")
+	}
+	if descriptor.CheckPrivilege("bar", privilege.SELECT) {
+		t.Error("unexpected SELECT privilege for bar")
+	}
+	if err := descriptor.Validate(ID(keys.MaxReservedDescID + 1)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidatePrivileges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	testCases := []struct {
+		privileges privilege.List
+		objectType privilege.ObjectType
+		err        string
+	}{
+		{privilege.List{privilege.ALL}, privilege.Table, ""},
+		{privilege.List{privilege.SELECT, privilege.INSERT}, privilege.Table, ""},
+		{privilege.List{privilege.CONNECT}, privilege.Table, "invalid privilege type CONNECT for table"},
+		{privilege.List{privilege.SELECT, privilege.USAGE}, privilege.Table, "invalid privilege type USAGE for table"},
+		{privilege.List{privilege.CONNECT, privilege.USAGE, privilege.CREATE}, privilege.Database, ""},
+	}
+	for _, tc := range testCases {
+		if err := ValidatePrivileges(tc.privileges, tc.objectType); !testutils.IsError(err, tc.err) {
+			t.Errorf("%s on %s: expected error %q, got %v", tc.privileges, tc.objectType, tc.err, err)
+		}
+	}
+}
+
 // TestPrivilegeValidate exercises validation for non-system descriptors.
 func TestPrivilegeValidate(t *testing.T) {
 	defer leaktest.AfterTest(t)()
@@ -175,7 +258,7 @@ func TestPrivilegeValidate(t *testing.T) {
 	if err := descriptor.Validate(id); err != nil {
 		t.Fatal(err)
 	}
-	descriptor.Revoke(security.RootUser, privilege.List{privilege.SELECT})
+	descriptor.Revoke(security.RootUser, privilege.List{privilege.SELECT}, privilege.Table)
 	if err := descriptor.Validate(id); err == nil {
 		t.Fatal("unexpected success")
 	}
@@ -185,12 +268,26 @@ func TestPrivilegeValidate(t *testing.T) {
 	if err := descriptor.Validate(id); err == nil {
 		t.Fatal("unexpected success")
 	}
-	descriptor.Revoke(security.RootUser, privilege.List{privilege.ALL})
+	descriptor.Revoke(security.RootUser, privilege.List{privilege.ALL}, privilege.Table)
 	if err := descriptor.Validate(id); err == nil {
 		t.Fatal("unexpected success")
 	}
 }
 
+func TestPrivilegeValidateColumn(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	descriptor := &PrivilegeDescriptor{}
+	descriptor.Grant("foo", privilege.List{privilege.SELECT})
+	if err := descriptor.ValidateColumn("a"); err != nil {
+		t.Fatal(err)
+	}
+	descriptor.Grant("bar", privilege.List{privilege.INSERT})
+	if err := descriptor.ValidateColumn("a"); !testutils.IsError(err,
+		`user bar must not have INSERT privileges on column "a"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 // TestSystemPrivilegeValidate exercises validation for system config
 // descriptors. We use a dummy system table installed for testing
 // purposes.
@@ -225,6 +322,13 @@ func TestSystemPrivilegeValidate(t *testing.T) {
 			t.Fatal(err)
 		}
 
+		// Invalid: foo owns the system object.
+		descriptor.Owner = "foo"
+		if err := descriptor.Validate(id); !testutils.IsError(err, "system object must not have owner foo") {
+			t.Fatalf("expected an owner error, got err=%v", err)
+		}
+		descriptor.Owner = ""
+
 		// Invalid: foo has more privileges than root.
 		descriptor.Grant("foo", privilege.List{privilege.GRANT})
 		if err := descriptor.Validate(id); !testutils.IsError(err, fooNoGrantPrivilegeErr) {
@@ -247,7 +351,7 @@ func TestSystemPrivilegeValidate(t *testing.T) {
 		}
 
 		// Valid: foo can have privileges revoked, including privileges it doesn't currently have.
-		descriptor.Revoke("foo", privilege.List{privilege.GRANT, privilege.UPDATE, privilege.ALL})
+		descriptor.Revoke("foo", privilege.List{privilege.GRANT, privilege.UPDATE, privilege.ALL}, privilege.Table)
 		if err := descriptor.Validate(id); err != nil {
 			t.Fatal(err)
 		}
@@ -267,7 +371,7 @@ func TestSystemPrivilegeValidate(t *testing.T) {
 		}
 
 		// Valid: root's invalid privileges are revoked and replaced with allowable privileges.
-		descriptor.Revoke(security.RootUser, privilege.List{privilege.UPDATE})
+		descriptor.Revoke(security.RootUser, privilege.List{privilege.UPDATE}, privilege.Table)
 		descriptor.Grant(security.RootUser, privilege.List{privilege.ALL})
 		if err := descriptor.Validate(id); err != nil {
 			t.Fatal(err)
@@ -281,7 +385,7 @@ func TestSystemPrivilegeValidate(t *testing.T) {
 
 		// TODO(marc): validate fails here because we do not aggregate
 		// privileges into ALL when all are set.
-		descriptor.Revoke(security.RootUser, privilege.List{privilege.SELECT})
+		descriptor.Revoke(security.RootUser, privilege.List{privilege.SELECT}, privilege.Table)
 		descriptor.Grant(security.RootUser, privilege.List{privilege.SELECT})
 		if err := descriptor.Validate(id); !testutils.IsError(err, rootWrongPrivilegesErr) {
 			t.Fatalf("expected err=%s, got err=%v", rootWrongPrivilegesErr, err)
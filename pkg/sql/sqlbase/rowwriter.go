@@ -360,6 +360,7 @@ type RowUpdater struct {
 	UpdateCols            []ColumnDescriptor
 	updateColIDtoRowIndex map[ColumnID]int
 	deleteOnlyIndex       map[int]struct{}
+	writeOnlyIndex        map[int]struct{}
 	primaryKeyColChange   bool
 
 	// rd and ri are used when the update this RowUpdater is created for modifies
@@ -455,7 +456,7 @@ func MakeRowUpdater(
 		tableCols = append(tableCols, tableDesc.Columns...)
 	}
 
-	var deleteOnlyIndex map[int]struct{}
+	var deleteOnlyIndex, writeOnlyIndex map[int]struct{}
 	for _, m := range tableDesc.Mutations {
 		if index := m.GetIndex(); index != nil {
 			if needsUpdate(*index) {
@@ -470,6 +471,11 @@ func MakeRowUpdater(
 					deleteOnlyIndex[len(indexes)-1] = struct{}{}
 
 				case DescriptorMutation_DELETE_AND_WRITE_ONLY:
+					if writeOnlyIndex == nil {
+						// Allocate at most once.
+						writeOnlyIndex = make(map[int]struct{}, len(tableDesc.Mutations))
+					}
+					writeOnlyIndex[len(indexes)-1] = struct{}{}
 				}
 			}
 		} else if col := m.GetColumn(); col != nil {
@@ -482,6 +488,7 @@ func MakeRowUpdater(
 		UpdateCols:            updateCols,
 		updateColIDtoRowIndex: updateColIDtoRowIndex,
 		deleteOnlyIndex:       deleteOnlyIndex,
+		writeOnlyIndex:        writeOnlyIndex,
 		primaryKeyColChange:   primaryKeyColChange,
 		marshalled:            make([]roachpb.Value, len(updateCols)),
 		newValues:             make([]tree.Datum, len(tableCols)),
@@ -757,6 +764,15 @@ func (ru *RowUpdater) UpdateRow(
 			}
 			b.Del(secondaryIndexEntry.Key)
 		} else if !bytes.Equal(newSecondaryIndexEntry.Value.RawBytes, secondaryIndexEntry.Value.RawBytes) {
+			if _, ok := ru.writeOnlyIndex[i]; ok {
+				// The entry of an index being backfilled might not have been
+				// written yet, so it can't be expected to hold the old value.
+				if traceKV {
+					log.VEventf(ctx, 2, "Put %s -> %v", newSecondaryIndexEntry.Key, newSecondaryIndexEntry.Value.PrettyPrint())
+				}
+				b.Put(newSecondaryIndexEntry.Key, &newSecondaryIndexEntry.Value)
+				continue
+			}
 			expValue = &secondaryIndexEntry.Value
 		} else {
 			continue
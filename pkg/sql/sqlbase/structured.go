@@ -691,10 +691,21 @@ func (desc *TableDescriptor) allocateIndexIDs(columnNames map[string]ColumnID) e
 			// by ContainsColumnID.
 			index.ExtraColumnIDs = nil
 			index.StoreColumnIDs = nil
+			// An index encoded as a primary index identifies its rows by its own
+			// columns, and the indexes rewritten by a primary key change by the
+			// columns of the new primary index.
+			primaryIndex := &desc.PrimaryIndex
+			if index.EncodingType == IndexDescriptor_PRIMARY {
+				primaryIndex = index
+			} else if newPrimaryIndex := desc.primaryKeySwapIndexFor(index.ID); newPrimaryIndex != nil {
+				primaryIndex = newPrimaryIndex
+			}
 			var extraColumnIDs []ColumnID
-			for _, primaryColID := range desc.PrimaryIndex.ColumnIDs {
-				if !index.ContainsColumnID(primaryColID) {
-					extraColumnIDs = append(extraColumnIDs, primaryColID)
+			if primaryIndex != index {
+				for _, primaryColID := range primaryIndex.ColumnIDs {
+					if !index.ContainsColumnID(primaryColID) {
+						extraColumnIDs = append(extraColumnIDs, primaryColID)
+					}
 				}
 			}
 			index.ExtraColumnIDs = extraColumnIDs
@@ -704,7 +715,7 @@ func (desc *TableDescriptor) allocateIndexIDs(columnNames map[string]ColumnID) e
 				if err != nil {
 					return err
 				}
-				if desc.PrimaryIndex.ContainsColumnID(col.ID) {
+				if primaryIndex.ContainsColumnID(col.ID) {
 					continue
 				}
 				if index.ContainsColumnID(col.ID) {
@@ -1073,6 +1084,12 @@ func (desc *TableDescriptor) ValidateTable() error {
 			return fmt.Errorf("column %q invalid ID (%d) > next column ID (%d)",
 				column.Name, column.ID, desc.NextColumnID)
 		}
+
+		if column.Privileges != nil {
+			if err := column.Privileges.ValidateColumn(column.Name); err != nil {
+				return err
+			}
+		}
 	}
 
 	for _, m := range desc.Mutations {
@@ -1094,6 +1111,12 @@ func (desc *TableDescriptor) ValidateTable() error {
 		}
 	}
 
+	if ttl := desc.RowLevelTTL; ttl != nil {
+		if _, ok := columnIDs[ttl.ExpirationColumnID]; !ok {
+			return fmt.Errorf("TTL expiration column %d does not exist", ttl.ExpirationColumnID)
+		}
+	}
+
 	// TODO(dt): Validate each column only appears at-most-once in any FKs.
 
 	// Only validate column families and indexes if this is actually a table, not
@@ -2031,6 +2054,92 @@ func (desc *TableDescriptor) MakeMutationComplete(m DescriptorMutation) {
 	}
 }
 
+// primaryKeySwapIndexFor returns the index which becomes the primary index
+// once the primary key change in progress completes, if the index with the
+// given ID is added by the primary key change to reference it, or nil
+// otherwise.
+func (desc *TableDescriptor) primaryKeySwapIndexFor(id IndexID) *IndexDescriptor {
+	swap := desc.PrimaryKeySwap
+	if swap == nil || id == swap.NewPrimaryIndexID {
+		return nil
+	}
+	for _, m := range desc.Mutations {
+		if index := m.GetIndex(); index != nil && index.ID == id && m.MutationID == swap.MutationID {
+			if index, err := desc.FindIndexByID(swap.NewPrimaryIndexID); err == nil {
+				return index
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// MakePrimaryKeySwapComplete updates the descriptor upon completion of the
+// mutation of a primary key change: the new primary index replaces the
+// primary index, and each rewritten secondary index replaces the index it was
+// built from and takes its name. The unique index on the columns of the old
+// primary key, if any, is already public. The replaced indexes are dropped by
+// a new mutation, whose ID is returned. While it is dropped, the old primary index
+// is written as the new one was while it was built.
+func (desc *TableDescriptor) MakePrimaryKeySwapComplete() (MutationID, error) {
+	swap := desc.PrimaryKeySwap
+	if swap == nil {
+		return InvalidMutationID, errors.New("no primary key change in progress")
+	}
+	removeIndex := func(id IndexID) (IndexDescriptor, error) {
+		for i := range desc.Indexes {
+			if desc.Indexes[i].ID == id {
+				index := desc.Indexes[i]
+				desc.Indexes = append(desc.Indexes[:i], desc.Indexes[i+1:]...)
+				return index, nil
+			}
+		}
+		return IndexDescriptor{}, errors.Errorf("index-id \"%d\" is not public", id)
+	}
+
+	newPrimary, err := removeIndex(swap.NewPrimaryIndexID)
+	if err != nil {
+		return InvalidMutationID, err
+	}
+	oldPrimary := desc.PrimaryIndex
+	newPrimary.Name = oldPrimary.Name
+	newPrimary.EncodingType = IndexDescriptor_SECONDARY
+	newPrimary.StoreColumnNames = nil
+	newPrimary.StoreColumnIDs = nil
+	desc.PrimaryIndex = newPrimary
+
+	oldPrimary.EncodingType = IndexDescriptor_PRIMARY
+	for _, col := range desc.Columns {
+		if !oldPrimary.ContainsColumnID(col.ID) {
+			oldPrimary.StoreColumnNames = append(oldPrimary.StoreColumnNames, col.Name)
+			oldPrimary.StoreColumnIDs = append(oldPrimary.StoreColumnIDs, col.ID)
+		}
+	}
+	desc.addMutation(DescriptorMutation{
+		Descriptor_: &DescriptorMutation_Index{Index: &oldPrimary},
+		Direction:   DescriptorMutation_DROP,
+	})
+
+	for i, oldID := range swap.OldIndexIDs {
+		oldIndex, err := removeIndex(oldID)
+		if err != nil {
+			return InvalidMutationID, err
+		}
+		newIndex, err := desc.FindIndexByID(swap.NewIndexIDs[i])
+		if err != nil {
+			return InvalidMutationID, err
+		}
+		newIndex.Name = oldIndex.Name
+		desc.addMutation(DescriptorMutation{
+			Descriptor_: &DescriptorMutation_Index{Index: &oldIndex},
+			Direction:   DescriptorMutation_DROP,
+		})
+	}
+
+	desc.PrimaryKeySwap = nil
+	return desc.FinalizeMutation()
+}
+
 // AddColumnMutation adds a column mutation to desc.Mutations.
 func (desc *TableDescriptor) AddColumnMutation(
 	c ColumnDescriptor, direction DescriptorMutation_Direction,
@@ -2400,10 +2509,26 @@ func (desc *DatabaseDescriptor) Validate() error {
 	if desc.ID == 0 {
 		return fmt.Errorf("invalid database ID %d", desc.ID)
 	}
+	if rc := desc.RegionConfig; rc != nil {
+		if !rc.HasRegion(rc.PrimaryRegion) {
+			return fmt.Errorf("primary region %q is not a region of database %s",
+				rc.PrimaryRegion, desc.Name)
+		}
+	}
 	// Validate the privilege descriptor.
 	return desc.Privileges.Validate(desc.GetID())
 }
 
+// HasRegion returns whether the given region is a region of the database.
+func (rc *DatabaseDescriptor_RegionConfig) HasRegion(region string) bool {
+	for _, r := range rc.Regions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
 // GetID returns the ID of the descriptor.
 func (desc *Descriptor) GetID() ID {
 	switch t := desc.Union.(type) {
@@ -161,6 +161,74 @@ CREATE TABLE system.table_statistics (
 	PRIMARY KEY ("tableID", "statisticID"),
 	FAMILY ("tableID", "statisticID", name, "columnIDs", "createdAt", "rowCount", "distinctCount", "nullCount", histogram)
 );`
+
+	// role_options stores the options of users set with CREATE USER and ALTER
+	// USER, such as their connection limit, one row per user and option.
+	RoleOptionsTableSchema = `
+CREATE TABLE system.role_options (
+	username STRING NOT NULL,
+	option   STRING NOT NULL,
+	value    STRING,
+	PRIMARY KEY (username, option),
+	FAMILY (username, option, value)
+);`
+
+	// role_members stores the memberships granted with GRANT <role> TO
+	// <member>, and whether they were granted WITH ADMIN OPTION.
+	RoleMembersTableSchema = `
+CREATE TABLE system.role_members (
+	role      STRING NOT NULL,
+	member    STRING NOT NULL,
+	"isAdmin" BOOL NOT NULL,
+	PRIMARY KEY (role, member),
+	INDEX (member),
+	FAMILY (role, member, "isAdmin")
+);`
+
+	// scheduled_jobs stores the schedules created with CREATE SCHEDULE, which
+	// start jobs periodically. The arguments and the state of a schedule are
+	// opaque to the scheduler, and interpreted by its executor.
+	ScheduledJobsTableSchema = `
+CREATE TABLE system.scheduled_jobs (
+	schedule_id     INT       DEFAULT unique_rowid() PRIMARY KEY,
+	schedule_name   STRING    NOT NULL,
+	created         TIMESTAMP NOT NULL DEFAULT now(),
+	owner           STRING    NOT NULL,
+	next_run        TIMESTAMP,
+	schedule_expr   STRING    NOT NULL,
+	executor_type   STRING    NOT NULL,
+	execution_args  BYTES     NOT NULL,
+	schedule_state  BYTES,
+	schedule_status STRING,
+	FAMILY (schedule_id, schedule_name, created, owner, next_run, schedule_expr, executor_type, execution_args, schedule_state, schedule_status)
+);`
+
+	// statement_bundles stores the bundles collected with EXPLAIN ANALYZE
+	// (DEBUG), zip archives of the diagnostics of a statement.
+	StatementBundlesTableSchema = `
+CREATE TABLE system.statement_bundles (
+	id        INT       DEFAULT unique_rowid() PRIMARY KEY,
+	statement STRING    NOT NULL,
+	created   TIMESTAMP NOT NULL DEFAULT now(),
+	bundle    BYTES     NOT NULL,
+	FAMILY (id, statement, created, bundle)
+);`
+
+	// statement_diagnostics_requests stores the requests to collect a bundle
+	// of the next execution of the statements with a fingerprint which runs
+	// for at least a minimum latency. Once a bundle is collected, the request
+	// is completed and refers to the bundle in statement_bundles.
+	StatementDiagnosticsRequestsTableSchema = `
+CREATE TABLE system.statement_diagnostics_requests (
+	id                    INT       DEFAULT unique_rowid() PRIMARY KEY,
+	completed             BOOL      NOT NULL,
+	statement_fingerprint STRING    NOT NULL,
+	min_execution_latency INTERVAL  NOT NULL,
+	requested             TIMESTAMP NOT NULL DEFAULT now(),
+	statement_bundle_id   INT,
+	collected             TIMESTAMP,
+	FAMILY (id, completed, statement_fingerprint, min_execution_latency, requested, statement_bundle_id, collected)
+);`
 )
 
 func pk(name string) IndexDescriptor {
@@ -212,9 +280,14 @@ var SystemAllowedPrivileges = map[ID]privilege.Lists{
 	// users will be able to modify system tables' schemas at will. CREATE and
 	// DROP privileges are allowed on the above system tables for backwards
 	// compatibility reasons only!
-	keys.JobsTableID:            {privilege.ReadWriteData},
-	keys.WebSessionsTableID:     {privilege.ReadWriteData},
-	keys.TableStatisticsTableID: {privilege.ReadWriteData},
+	keys.JobsTableID:                         {privilege.ReadWriteData},
+	keys.WebSessionsTableID:                  {privilege.ReadWriteData},
+	keys.TableStatisticsTableID:              {privilege.ReadWriteData},
+	keys.RoleOptionsTableID:                  {privilege.ReadWriteData},
+	keys.RoleMembersTableID:                  {privilege.ReadWriteData},
+	keys.ScheduledJobsTableID:                {privilege.ReadWriteData},
+	keys.StatementBundlesTableID:             {privilege.ReadWriteData},
+	keys.StatementDiagnosticsRequestsTableID: {privilege.ReadWriteData},
 }
 
 // SystemDesiredPrivileges returns the desired privilege list (i.e., the
@@ -232,6 +305,8 @@ var (
 	colTypeString    = ColumnType{SemanticType: ColumnType_STRING}
 	colTypeBytes     = ColumnType{SemanticType: ColumnType_BYTES}
 	colTypeTimestamp = ColumnType{SemanticType: ColumnType_TIMESTAMP}
+	colTypeBool      = ColumnType{SemanticType: ColumnType_BOOL}
+	colTypeInterval  = ColumnType{SemanticType: ColumnType_INTERVAL}
 	colTypeIntArray  = ColumnType{SemanticType: ColumnType_ARRAY, ArrayContents: &colTypeInt.SemanticType,
 		ArrayDimensions: []int32{-1}}
 	singleASC = []IndexDescriptor_Direction{IndexDescriptor_ASC}
@@ -688,6 +763,190 @@ var (
 		FormatVersion:  InterleavedFormatVersion,
 		NextMutationID: 1,
 	}
+
+	// RoleOptionsTable is the descriptor for the role_options table.
+	RoleOptionsTable = TableDescriptor{
+		Name:     "role_options",
+		ID:       keys.RoleOptionsTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "username", ID: 1, Type: colTypeString},
+			{Name: "option", ID: 2, Type: colTypeString},
+			{Name: "value", ID: 3, Type: colTypeString, Nullable: true},
+		},
+		NextColumnID: 4,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_username_option_value",
+				ID:          0,
+				ColumnNames: []string{"username", "option", "value"},
+				ColumnIDs:   []ColumnID{1, 2, 3},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:             "primary",
+			ID:               1,
+			Unique:           true,
+			ColumnNames:      []string{"username", "option"},
+			ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC, IndexDescriptor_ASC},
+			ColumnIDs:        []ColumnID{1, 2},
+		},
+		NextIndexID:    2,
+		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemDesiredPrivileges(keys.RoleOptionsTableID)),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// RoleMembersTable is the descriptor for the role_members table.
+	RoleMembersTable = TableDescriptor{
+		Name:     "role_members",
+		ID:       keys.RoleMembersTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "role", ID: 1, Type: colTypeString},
+			{Name: "member", ID: 2, Type: colTypeString},
+			{Name: "isAdmin", ID: 3, Type: colTypeBool},
+		},
+		NextColumnID: 4,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_role_member_isAdmin",
+				ID:          0,
+				ColumnNames: []string{"role", "member", "isAdmin"},
+				ColumnIDs:   []ColumnID{1, 2, 3},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:             "primary",
+			ID:               1,
+			Unique:           true,
+			ColumnNames:      []string{"role", "member"},
+			ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC, IndexDescriptor_ASC},
+			ColumnIDs:        []ColumnID{1, 2},
+		},
+		Indexes: []IndexDescriptor{
+			{
+				Name:             "role_members_member_idx",
+				ID:               2,
+				Unique:           false,
+				ColumnNames:      []string{"member"},
+				ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC},
+				ColumnIDs:        []ColumnID{2},
+				ExtraColumnIDs:   []ColumnID{1},
+			},
+		},
+		NextIndexID:    3,
+		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemDesiredPrivileges(keys.RoleMembersTableID)),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// ScheduledJobsTable is the descriptor for the scheduled_jobs table.
+	ScheduledJobsTable = TableDescriptor{
+		Name:     "scheduled_jobs",
+		ID:       keys.ScheduledJobsTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "schedule_id", ID: 1, Type: colTypeInt, DefaultExpr: &uniqueRowIDString},
+			{Name: "schedule_name", ID: 2, Type: colTypeString},
+			{Name: "created", ID: 3, Type: colTypeTimestamp, DefaultExpr: &nowString},
+			{Name: "owner", ID: 4, Type: colTypeString},
+			{Name: "next_run", ID: 5, Type: colTypeTimestamp, Nullable: true},
+			{Name: "schedule_expr", ID: 6, Type: colTypeString},
+			{Name: "executor_type", ID: 7, Type: colTypeString},
+			{Name: "execution_args", ID: 8, Type: colTypeBytes},
+			{Name: "schedule_state", ID: 9, Type: colTypeBytes, Nullable: true},
+			{Name: "schedule_status", ID: 10, Type: colTypeString, Nullable: true},
+		},
+		NextColumnID: 11,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name: "fam_0_schedule_id_schedule_name_created_owner_next_run_schedule_expr_executor_type_execution_args_schedule_state_schedule_status",
+				ID:   0,
+				ColumnNames: []string{
+					"schedule_id", "schedule_name", "created", "owner", "next_run",
+					"schedule_expr", "executor_type", "execution_args", "schedule_state", "schedule_status",
+				},
+				ColumnIDs: []ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("schedule_id"),
+		NextIndexID:    2,
+		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemDesiredPrivileges(keys.ScheduledJobsTableID)),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// StatementBundlesTable is the descriptor for the statement_bundles table.
+	StatementBundlesTable = TableDescriptor{
+		Name:     "statement_bundles",
+		ID:       keys.StatementBundlesTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "id", ID: 1, Type: colTypeInt, DefaultExpr: &uniqueRowIDString},
+			{Name: "statement", ID: 2, Type: colTypeString},
+			{Name: "created", ID: 3, Type: colTypeTimestamp, DefaultExpr: &nowString},
+			{Name: "bundle", ID: 4, Type: colTypeBytes},
+		},
+		NextColumnID: 5,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_id_statement_created_bundle",
+				ID:          0,
+				ColumnNames: []string{"id", "statement", "created", "bundle"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("id"),
+		NextIndexID:    2,
+		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemDesiredPrivileges(keys.StatementBundlesTableID)),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// StatementDiagnosticsRequestsTable is the descriptor for the
+	// statement_diagnostics_requests table.
+	StatementDiagnosticsRequestsTable = TableDescriptor{
+		Name:     "statement_diagnostics_requests",
+		ID:       keys.StatementDiagnosticsRequestsTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "id", ID: 1, Type: colTypeInt, DefaultExpr: &uniqueRowIDString},
+			{Name: "completed", ID: 2, Type: colTypeBool},
+			{Name: "statement_fingerprint", ID: 3, Type: colTypeString},
+			{Name: "min_execution_latency", ID: 4, Type: colTypeInterval},
+			{Name: "requested", ID: 5, Type: colTypeTimestamp, DefaultExpr: &nowString},
+			{Name: "statement_bundle_id", ID: 6, Type: colTypeInt, Nullable: true},
+			{Name: "collected", ID: 7, Type: colTypeTimestamp, Nullable: true},
+		},
+		NextColumnID: 8,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name: "fam_0_id_completed_statement_fingerprint_min_execution_latency_requested_statement_bundle_id_collected",
+				ID:   0,
+				ColumnNames: []string{
+					"id", "completed", "statement_fingerprint", "min_execution_latency",
+					"requested", "statement_bundle_id", "collected",
+				},
+				ColumnIDs: []ColumnID{1, 2, 3, 4, 5, 6, 7},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("id"),
+		NextIndexID:    2,
+		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemDesiredPrivileges(keys.StatementDiagnosticsRequestsTableID)),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
 )
 
 // Create the key/value pair for the default zone config entry.
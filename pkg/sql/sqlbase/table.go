@@ -28,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -954,11 +955,47 @@ func ExtractIndexKey(
 	return indexKey, err
 }
 
-const datumAllocSize = 16 // Arbitrary, could be tuned.
+const (
+	// datumAllocSize is the size of the first chunk allocated for each datum
+	// type.
+	datumAllocSize = 16 // Arbitrary, could be tuned.
+	// maxDatumAllocSize bounds the size of the chunks, which double every
+	// time a chunk of the same type is exhausted. Large scans use the same
+	// DatumAlloc for millions of datums; growing the chunks keeps the number
+	// of heap objects they generate low, while queries that only decode a
+	// handful of datums keep allocating small chunks. Note that a chunk is
+	// kept alive for as long as any of its datums is referenced.
+	maxDatumAllocSize = 1024
+)
+
+// datumAllocType identifies the datum types that DatumAlloc allocates in
+// chunks.
+type datumAllocType int
+
+const (
+	dIntChunk datumAllocType = iota
+	dFloatChunk
+	dStringChunk
+	dBytesChunk
+	dDecimalChunk
+	dDateChunk
+	dTimeChunk
+	dTimestampChunk
+	dTimestampTZChunk
+	dIntervalChunk
+	dUuidChunk
+	dIPAddrChunk
+	dJSONChunk
+	dOidChunk
+	numDatumAllocTypes
+)
 
 // DatumAlloc provides batch allocation of datum pointers, amortizing the cost
 // of the allocations.
 type DatumAlloc struct {
+	// chunkSizes is the size of the last chunk allocated for each datum type.
+	chunkSizes [numDatumAllocTypes]int
+
 	dintAlloc         []tree.DInt
 	dfloatAlloc       []tree.DFloat
 	dstringAlloc      []tree.DString
@@ -977,11 +1014,23 @@ type DatumAlloc struct {
 	env               tree.CollationEnvironment
 }
 
+// nextChunkSize returns the size of the next chunk to allocate for the given
+// datum type.
+func (a *DatumAlloc) nextChunkSize(typ datumAllocType) int {
+	size := &a.chunkSizes[typ]
+	if *size == 0 {
+		*size = datumAllocSize
+	} else if *size < maxDatumAllocSize {
+		*size *= 2
+	}
+	return *size
+}
+
 // NewDInt allocates a DInt.
 func (a *DatumAlloc) NewDInt(v tree.DInt) *tree.DInt {
 	buf := &a.dintAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DInt, datumAllocSize)
+		*buf = make([]tree.DInt, a.nextChunkSize(dIntChunk))
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -993,7 +1042,7 @@ func (a *DatumAlloc) NewDInt(v tree.DInt) *tree.DInt {
 func (a *DatumAlloc) NewDFloat(v tree.DFloat) *tree.DFloat {
 	buf := &a.dfloatAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DFloat, datumAllocSize)
+		*buf = make([]tree.DFloat, a.nextChunkSize(dFloatChunk))
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -1005,7 +1054,7 @@ func (a *DatumAlloc) NewDFloat(v tree.DFloat) *tree.DFloat {
 func (a *DatumAlloc) NewDString(v tree.DString) *tree.DString {
 	buf := &a.dstringAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DString, datumAllocSize)
+		*buf = make([]tree.DString, a.nextChunkSize(dStringChunk))
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -1022,7 +1071,7 @@ func (a *DatumAlloc) NewDName(v tree.DString) tree.Datum {
 func (a *DatumAlloc) NewDBytes(v tree.DBytes) *tree.DBytes {
 	buf := &a.dbytesAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DBytes, datumAllocSize)
+		*buf = make([]tree.DBytes, a.nextChunkSize(dBytesChunk))
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -1034,7 +1083,7 @@ func (a *DatumAlloc) NewDBytes(v tree.DBytes) *tree.DBytes {
 func (a *DatumAlloc) NewDDecimal(v tree.DDecimal) *tree.DDecimal {
 	buf := &a.ddecimalAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DDecimal, datumAllocSize)
+		*buf = make([]tree.DDecimal, a.nextChunkSize(dDecimalChunk))
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -1046,7 +1095,7 @@ func (a *DatumAlloc) NewDDecimal(v tree.DDecimal) *tree.DDecimal {
 func (a *DatumAlloc) NewDDate(v tree.DDate) *tree.DDate {
 	buf := &a.ddateAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DDate, datumAllocSize)
+		*buf = make([]tree.DDate, a.nextChunkSize(dDateChunk))
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -1058,7 +1107,7 @@ func (a *DatumAlloc) NewDDate(v tree.DDate) *tree.DDate {
 func (a *DatumAlloc) NewDTime(v tree.DTime) *tree.DTime {
 	buf := &a.dtimeAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DTime, datumAllocSize)
+		*buf = make([]tree.DTime, a.nextChunkSize(dTimeChunk))
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -1070,7 +1119,7 @@ func (a *DatumAlloc) NewDTime(v tree.DTime) *tree.DTime {
 func (a *DatumAlloc) NewDTimestamp(v tree.DTimestamp) *tree.DTimestamp {
 	buf := &a.dtimestampAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DTimestamp, datumAllocSize)
+		*buf = make([]tree.DTimestamp, a.nextChunkSize(dTimestampChunk))
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -1082,7 +1131,7 @@ func (a *DatumAlloc) NewDTimestamp(v tree.DTimestamp) *tree.DTimestamp {
 func (a *DatumAlloc) NewDTimestampTZ(v tree.DTimestampTZ) *tree.DTimestampTZ {
 	buf := &a.dtimestampTzAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DTimestampTZ, datumAllocSize)
+		*buf = make([]tree.DTimestampTZ, a.nextChunkSize(dTimestampTZChunk))
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -1094,7 +1143,7 @@ func (a *DatumAlloc) NewDTimestampTZ(v tree.DTimestampTZ) *tree.DTimestampTZ {
 func (a *DatumAlloc) NewDInterval(v tree.DInterval) *tree.DInterval {
 	buf := &a.dintervalAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DInterval, datumAllocSize)
+		*buf = make([]tree.DInterval, a.nextChunkSize(dIntervalChunk))
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -1106,7 +1155,7 @@ func (a *DatumAlloc) NewDInterval(v tree.DInterval) *tree.DInterval {
 func (a *DatumAlloc) NewDUuid(v tree.DUuid) *tree.DUuid {
 	buf := &a.duuidAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DUuid, datumAllocSize)
+		*buf = make([]tree.DUuid, a.nextChunkSize(dUuidChunk))
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -1118,7 +1167,7 @@ func (a *DatumAlloc) NewDUuid(v tree.DUuid) *tree.DUuid {
 func (a *DatumAlloc) NewDIPAddr(v tree.DIPAddr) *tree.DIPAddr {
 	buf := &a.dipnetAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DIPAddr, datumAllocSize)
+		*buf = make([]tree.DIPAddr, a.nextChunkSize(dIPAddrChunk))
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -1130,7 +1179,7 @@ func (a *DatumAlloc) NewDIPAddr(v tree.DIPAddr) *tree.DIPAddr {
 func (a *DatumAlloc) NewDJSON(v tree.DJSON) *tree.DJSON {
 	buf := &a.djsonAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DJSON, datumAllocSize)
+		*buf = make([]tree.DJSON, a.nextChunkSize(dJSONChunk))
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -1142,7 +1191,7 @@ func (a *DatumAlloc) NewDJSON(v tree.DJSON) *tree.DJSON {
 func (a *DatumAlloc) NewDOid(v tree.DOid) tree.Datum {
 	buf := &a.doidAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DOid, datumAllocSize)
+		*buf = make([]tree.DOid, a.nextChunkSize(dOidChunk))
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -1525,7 +1574,8 @@ func (a byID) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a byID) Less(i, j int) bool { return a[i].id < a[j].id }
 
 // EncodeSecondaryIndex encodes key/values for a secondary index. colMap maps
-// ColumnIDs to indices in `values`.
+// ColumnIDs to indices in `values`. An index with the PRIMARY encoding type is
+// encoded as the primary index of a table with a single column family.
 func EncodeSecondaryIndex(
 	tableDesc *TableDescriptor,
 	secondaryIndex *IndexDescriptor,
@@ -1560,7 +1610,12 @@ func EncodeSecondaryIndex(
 	entry.Key = keys.MakeFamilyKey(entry.Key, 0)
 
 	var entryValue []byte
-	if secondaryIndex.Unique {
+	if secondaryIndex.EncodingType == IndexDescriptor_PRIMARY {
+		// The value is the tuple of the columns which are not part of the key,
+		// and of the composite key columns, as in a primary index. The key has
+		// no extra columns: it is unique and its columns are not nullable.
+		entryValue = []byte{}
+	} else if secondaryIndex.Unique {
 		// Note that a unique secondary index that contains a NULL column value
 		// will have extraKey appended to the key and stored in the value. We
 		// require extraKey to be appended to the key in order to make the key
@@ -1599,7 +1654,11 @@ func EncodeSecondaryIndex(
 			return IndexEntry{}, err
 		}
 	}
-	entry.Value.SetBytes(entryValue)
+	if secondaryIndex.EncodingType == IndexDescriptor_PRIMARY {
+		entry.Value.SetTuple(entryValue)
+	} else {
+		entry.Value.SetBytes(entryValue)
+	}
 
 	return entry, nil
 }
@@ -2054,8 +2113,10 @@ func CheckValueWidth(typ ColumnType, val tree.Datum, name string) error {
 	case ColumnType_STRING:
 		if v, ok := tree.AsDString(val); ok {
 			if typ.Width > 0 && utf8.RuneCountInString(string(v)) > int(typ.Width) {
-				return fmt.Errorf("value too long for type %s (column %q)",
-					typ.SQLString(), name)
+				err := pgerror.NewErrorf(pgerror.CodeStringDataRightTruncationError,
+					"value too long for type %s (column %q)", typ.SQLString(), name)
+				err.ColumnName = name
+				return err
 			}
 		}
 	case ColumnType_INT:
@@ -2079,7 +2140,10 @@ func CheckValueWidth(typ ColumnType, val tree.Datum, name string) error {
 				// We're performing bounds checks inline with Go's implementation of min and max ints in Math.go.
 				shifted := v >> width
 				if (v >= 0 && shifted > 0) || (v < 0 && shifted < -1) {
-					return fmt.Errorf("integer out of range for type %s (column %q)", typ.VisibleType, name)
+					err := pgerror.NewErrorf(pgerror.CodeNumericValueOutOfRangeError,
+						"integer out of range for type %s (column %q)", typ.VisibleType, name)
+					err.ColumnName = name
+					return err
 				}
 			}
 		}
@@ -813,3 +813,28 @@ func TestEquivSignature(t *testing.T) {
 		})
 	}
 }
+
+func TestDatumAllocChunkSizes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var a DatumAlloc
+	const n = 10000
+	ints := make([]*tree.DInt, n)
+	for i := range ints {
+		ints[i] = a.NewDInt(tree.DInt(i))
+	}
+	// The datums must not overlap.
+	for i, d := range ints {
+		if int(*d) != i {
+			t.Fatalf("datum %d was overwritten with %d", i, *d)
+		}
+	}
+	if s := a.chunkSizes[dIntChunk]; s != maxDatumAllocSize {
+		t.Errorf("expected chunks of %d DInts after %d allocations, found %d", maxDatumAllocSize, n, s)
+	}
+	// Other types are unaffected by the allocation of DInts.
+	_ = a.NewDString("foo")
+	if s := a.chunkSizes[dStringChunk]; s != datumAllocSize {
+		t.Errorf("expected a chunk of %d DStrings, found %d", datumAllocSize, s)
+	}
+}
@@ -45,4 +45,23 @@ type InternalExecutor interface {
 	QueryRowsInTransaction(
 		ctx context.Context, opName string, txn *client.Txn, statement string, qargs ...interface{},
 	) ([]tree.Datums, error)
+
+	// Exec executes the supplied SQL statement in a new transaction, which is
+	// retried on retryable errors, and returns the number of rows affected.
+	// Statements are currently executed as the root user.
+	Exec(ctx context.Context, opName string, statement string, qargs ...interface{}) (int, error)
+
+	// QueryRow executes the supplied SQL statement in a new transaction, which
+	// is retried on retryable errors, and returns the result. Statements are
+	// currently executed as the root user.
+	QueryRow(
+		ctx context.Context, opName string, statement string, qargs ...interface{},
+	) (tree.Datums, error)
+
+	// Query executes the supplied SQL statement in a new transaction, which is
+	// retried on retryable errors, and returns the resulting rows. Statements
+	// are currently executed as the root user.
+	Query(
+		ctx context.Context, opName string, statement string, qargs ...interface{},
+	) ([]tree.Datums, error)
 }
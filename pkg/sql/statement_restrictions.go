@@ -0,0 +1,247 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// The system.role_options options holding the comma-separated classes of
+// statements set with ALTER USER ... ALLOW STATEMENTS and DENY STATEMENTS.
+const (
+	roleOptionAllowStatements = "ALLOW STATEMENTS"
+	roleOptionDenyStatements  = "DENY STATEMENTS"
+)
+
+// The classes of statements that can be allowed or denied to a user.
+const (
+	// stmtClassSelect is the class of the statements reading data: SELECT,
+	// SHOW, EXPLAIN of such statements and the cursors.
+	stmtClassSelect = "select"
+	// stmtClassDML is the class of the statements modifying data: INSERT,
+	// UPSERT, UPDATE, DELETE, TRUNCATE, COPY FROM and NOTIFY.
+	stmtClassDML = "dml"
+	// stmtClassDDL is the class of the statements creating, altering or
+	// dropping schema objects.
+	stmtClassDDL = "ddl"
+	// stmtClassExport is the class of the statements copying data out of the
+	// cluster: BACKUP and COPY TO.
+	stmtClassExport = "export"
+	// stmtClassImport is the class of the statements loading data into the
+	// cluster: IMPORT and RESTORE.
+	stmtClassImport = "import"
+	// stmtClassPrivileges is the class of the statements managing users,
+	// roles, privileges, ownership and row-level security policies.
+	stmtClassPrivileges = "privileges"
+	// stmtClassAdmin is the class of the statements administering the
+	// cluster: cluster settings, zone configurations, jobs, sessions, queries
+	// and ranges.
+	stmtClassAdmin = "admin"
+)
+
+var statementClasses = []string{
+	stmtClassSelect,
+	stmtClassDML,
+	stmtClassDDL,
+	stmtClassExport,
+	stmtClassImport,
+	stmtClassPrivileges,
+	stmtClassAdmin,
+}
+
+// statementClass returns the class of the given statement, or "" for the
+// statements controlling transactions and sessions, which every user may
+// execute. The statements prepared, explained or declared as cursors are
+// classified as the statement they contain.
+func statementClass(stmt tree.Statement) string {
+	switch s := stmt.(type) {
+	case *tree.BeginTransaction, *tree.CommitTransaction, *tree.RollbackTransaction,
+		*tree.Savepoint, *tree.ReleaseSavepoint, *tree.RollbackToSavepoint,
+		*tree.SetTransaction, *tree.SetDefaultIsolation, *tree.ShowTransactionStatus,
+		*tree.SetVar, *tree.ShowVar, *tree.Discard, *tree.Deallocate, *tree.Execute,
+		*tree.CloseCursor, *tree.Listen, *tree.Unlisten:
+		return ""
+	case *tree.Prepare:
+		return statementClass(s.Statement)
+	case *tree.Explain:
+		return statementClass(s.Statement)
+	case *tree.DeclareCursor:
+		return statementClass(s.Select)
+	case *tree.Select, *tree.ParenSelect, *tree.SelectClause, *tree.UnionClause,
+		*tree.ValuesClause, *tree.FetchCursor:
+		return stmtClassSelect
+	case *tree.Insert, *tree.Update, *tree.Delete, *tree.Truncate, *tree.CopyFrom,
+		*tree.Notify:
+		return stmtClassDML
+	case *tree.Backup, *tree.CopyTo, *tree.Export, *tree.CreateChangefeed, *tree.ScheduledBackup:
+		return stmtClassExport
+	case *tree.Import, *tree.Restore:
+		return stmtClassImport
+	case *tree.CreateUser, *tree.DropUser, *tree.AlterUserSetPassword,
+		*tree.AlterUserSetConnectionLimit, *tree.AlterUserSetValidUntil,
+		*tree.AlterUserSetStatements, *tree.AlterUserSetQuota, *tree.Grant, *tree.Revoke,
+		*tree.GrantRole, *tree.RevokeRole, *tree.AlterDefaultPrivileges, *tree.AlterTableOwner,
+		*tree.AlterDatabaseOwner, *tree.ReassignOwnedBy, *tree.CreatePolicy,
+		*tree.DropPolicy:
+		return stmtClassPrivileges
+	case *tree.SetClusterSetting, *tree.SetZoneConfig, *tree.CancelJob, *tree.PauseJob,
+		*tree.ResumeJob, *tree.CancelQuery, *tree.CancelSession, *tree.Split,
+		*tree.Scatter, *tree.TestingRelocate, *tree.Scrub:
+		return stmtClassAdmin
+	}
+	switch stmt.StatementType() {
+	case tree.DDL:
+		return stmtClassDDL
+	case tree.Rows:
+		// The remaining statements returning rows are the SHOW statements.
+		return stmtClassSelect
+	default:
+		return stmtClassAdmin
+	}
+}
+
+// normalizeStatementClasses validates the classes given to ALLOW STATEMENTS
+// or DENY STATEMENTS and returns the value stored in system.role_options.
+func normalizeStatementClasses(classes tree.NameList) (string, error) {
+	normalized := make([]string, 0, len(classes))
+	for _, c := range classes {
+		class := c.Normalize()
+		valid := false
+		for _, s := range statementClasses {
+			if class == s {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return "", pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"unknown class of statements %q; valid classes are %s",
+				class, strings.Join(statementClasses, ", "))
+		}
+		normalized = append(normalized, class)
+	}
+	return strings.Join(normalized, ","), nil
+}
+
+// StatementRestrictions are the classes of statements a user may execute,
+// as set with ALTER USER ... ALLOW STATEMENTS and DENY STATEMENTS. They are
+// read when the user connects: the changes apply to the sessions opened
+// afterwards.
+type StatementRestrictions struct {
+	// Allowed, if non-nil, lists the only classes the user may execute.
+	Allowed []string
+	// Denied lists the classes the user may not execute.
+	Denied []string
+}
+
+// check returns an error if the given statement is not allowed by r to the
+// given user.
+func (r StatementRestrictions) check(user string, stmt tree.Statement) error {
+	if r.Allowed == nil && r.Denied == nil {
+		return nil
+	}
+	class := statementClass(stmt)
+	if class == "" {
+		return nil
+	}
+	allowed := r.Allowed == nil
+	for _, c := range r.Allowed {
+		if c == class {
+			allowed = true
+		}
+	}
+	for _, c := range r.Denied {
+		if c == class {
+			allowed = false
+		}
+	}
+	if !allowed {
+		return pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+			"user %s is not allowed to execute %s statements (%s)",
+			user, class, stmt.StatementTag())
+	}
+	return nil
+}
+
+// GetUserStatementRestrictions returns the classes of statements the given
+// user may execute. Root may execute every statement.
+func GetUserStatementRestrictions(
+	ctx context.Context, executor *Executor, metrics *MemoryMetrics, username string,
+) (StatementRestrictions, error) {
+	normalizedUsername := tree.Name(username).Normalize()
+	if normalizedUsername == security.RootUser {
+		return StatementRestrictions{}, nil
+	}
+
+	var r StatementRestrictions
+	err := executor.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		r = StatementRestrictions{}
+		p := makeInternalPlanner("get-statement-restrictions", txn, security.RootUser, metrics)
+		defer finishInternalPlanner(p)
+		const getStatementRestrictions = `SELECT option, value FROM system.role_options ` +
+			`WHERE username = $1 AND option IN ($2, $3)`
+		rows, err := p.queryRows(ctx, getStatementRestrictions,
+			normalizedUsername, roleOptionAllowStatements, roleOptionDenyStatements)
+		if err != nil {
+			return errors.Errorf("error looking up user %s", normalizedUsername)
+		}
+		for _, row := range rows {
+			if row[1] == tree.DNull {
+				continue
+			}
+			classes := strings.Split(string(tree.MustBeDString(row[1])), ",")
+			switch tree.MustBeDString(row[0]) {
+			case roleOptionAllowStatements:
+				r.Allowed = classes
+			case roleOptionDenyStatements:
+				r.Denied = classes
+			}
+		}
+		return nil
+	})
+	return r, err
+}
+
+// setStatementRestrictions records the classes of statements allowed or
+// denied to a user in system.role_options, where option is
+// roleOptionAllowStatements or roleOptionDenyStatements and value the result
+// of normalizeStatementClasses. An empty option removes both the allowed and
+// the denied classes.
+func setStatementRestrictions(
+	params runParams, normalizedUsername string, option string, value string,
+) error {
+	if option != "" {
+		return setRoleOption(params, normalizedUsername, option, value)
+	}
+	internalExecutor := InternalExecutor{LeaseManager: params.p.LeaseMgr()}
+	_, err := internalExecutor.ExecuteStatementInTransaction(
+		params.ctx,
+		"reset-statement-restrictions",
+		params.p.txn,
+		"DELETE FROM system.role_options WHERE username = $1 AND option IN ($2, $3)",
+		normalizedUsername,
+		roleOptionAllowStatements,
+		roleOptionDenyStatements,
+	)
+	return err
+}
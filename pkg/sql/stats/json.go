@@ -0,0 +1,39 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+// JSONStatistic is the JSON form of a row of system.table_statistics, as
+// accepted by ALTER TABLE ... INJECT STATISTICS. Columns are referenced by
+// name so that statistics collected on one cluster can be injected into a
+// table with the same schema on another one.
+type JSONStatistic struct {
+	Name          string   `json:"name,omitempty"`
+	CreatedAt     string   `json:"created_at"`
+	Columns       []string `json:"columns"`
+	RowCount      uint64   `json:"row_count"`
+	DistinctCount uint64   `json:"distinct_count"`
+	NullCount     uint64   `json:"null_count"`
+	// HistogramBuckets is only allowed when there is a single column.
+	HistogramBuckets []JSONHistoBucket `json:"histo_buckets,omitempty"`
+}
+
+// JSONHistoBucket is the JSON form of a HistogramData_Bucket. The upper
+// bound is the string representation of a value of the column's type
+// instead of its key encoding.
+type JSONHistoBucket struct {
+	NumEq      int64  `json:"num_eq"`
+	NumRange   int64  `json:"num_range"`
+	UpperBound string `json:"upper_bound"`
+}
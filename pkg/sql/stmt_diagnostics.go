@@ -0,0 +1,339 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+)
+
+// A statement diagnostics request, stored in
+// system.statement_diagnostics_requests, asks for the collection of a
+// statement bundle, as collected by EXPLAIN ANALYZE (DEBUG), for the next
+// execution of a statement fingerprint running for longer than a minimum
+// latency. Every node polls the pending requests. The executions of the
+// statements matching a request are traced; once an execution exceeds the
+// minimum latency, its bundle is stored in system.statement_bundles and the
+// request is completed in the same transaction, so that a single bundle is
+// collected even if several nodes run matching statements concurrently.
+
+var stmtDiagnosticsPollInterval = settings.RegisterNonNegativeDurationSetting(
+	"sql.stmt_diagnostics.poll_interval",
+	"the interval between checks for new statement diagnostics requests (0 to disable)",
+	10*time.Second,
+)
+
+// stmtDiagnosticsDisabledPollInterval is how often the poller checks whether
+// it was enabled.
+const stmtDiagnosticsDisabledPollInterval = time.Minute
+
+// stmtDiagnosticsRequest is a pending statement diagnostics request.
+type stmtDiagnosticsRequest struct {
+	id         int64
+	minLatency time.Duration
+}
+
+// stmtDiagnosticsRegistry holds the pending statement diagnostics requests
+// known to this node.
+type stmtDiagnosticsRegistry struct {
+	mu struct {
+		syncutil.Mutex
+		// requests maps the statement fingerprints to their oldest pending
+		// request.
+		requests map[string]stmtDiagnosticsRequest
+	}
+}
+
+func init() {
+	AddBackgroundWorker(pollStmtDiagnosticsRequests)
+}
+
+// pollStmtDiagnosticsRequests implements BackgroundWorker.
+func pollStmtDiagnosticsRequests(ctx context.Context, stopper *stop.Stopper, e *Executor) {
+	st := e.cfg.Settings
+	var timer timeutil.Timer
+	defer timer.Stop()
+	for {
+		interval := stmtDiagnosticsPollInterval.Get(&st.SV)
+		if interval == 0 {
+			interval = stmtDiagnosticsDisabledPollInterval
+		}
+		timer.Reset(interval)
+		select {
+		case <-stopper.ShouldQuiesce():
+			return
+		case <-timer.C:
+			timer.Read = true
+		}
+		if stmtDiagnosticsPollInterval.Get(&st.SV) == 0 {
+			continue
+		}
+		if err := e.stmtDiagnostics.poll(ctx, &e.cfg); err != nil {
+			log.Warningf(ctx, "could not poll the statement diagnostics requests: %v", err)
+		}
+	}
+}
+
+// poll replaces the requests known to this node with the pending requests.
+func (r *stmtDiagnosticsRegistry) poll(ctx context.Context, cfg *ExecutorConfig) error {
+	ie := InternalExecutor{LeaseManager: cfg.LeaseManager}
+	rows, err := ie.Query(ctx, "poll-stmt-diagnostics-requests",
+		`SELECT id, statement_fingerprint, min_execution_latency
+       FROM system.statement_diagnostics_requests
+      WHERE NOT completed
+      ORDER BY id DESC`)
+	if err != nil {
+		return err
+	}
+	requests := make(map[string]stmtDiagnosticsRequest, len(rows))
+	for _, row := range rows {
+		// The requests are sorted newest first, so that the oldest request of
+		// a fingerprint is kept.
+		fingerprint := string(tree.MustBeDString(row[1]))
+		requests[fingerprint] = stmtDiagnosticsRequest{
+			id:         int64(tree.MustBeDInt(row[0])),
+			minLatency: time.Duration(row[2].(*tree.DInterval).Nanos),
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mu.requests = requests
+	return nil
+}
+
+// addRequest registers a new request on this node, without waiting for the
+// next poll. The oldest request of a fingerprint is kept.
+func (r *stmtDiagnosticsRegistry) addRequest(fingerprint string, req stmtDiagnosticsRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cur, ok := r.mu.requests[fingerprint]; ok && cur.id < req.id {
+		return
+	}
+	if r.mu.requests == nil {
+		r.mu.requests = make(map[string]stmtDiagnosticsRequest)
+	}
+	r.mu.requests[fingerprint] = req
+}
+
+// removeRequest removes a request from the requests known to this node.
+func (r *stmtDiagnosticsRegistry) removeRequest(fingerprint string, id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cur, ok := r.mu.requests[fingerprint]; ok && cur.id == id {
+		delete(r.mu.requests, fingerprint)
+	}
+}
+
+// findRequest returns the pending request of a fingerprint, if any.
+func (r *stmtDiagnosticsRegistry) findRequest(fingerprint string) (stmtDiagnosticsRequest, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	req, ok := r.mu.requests[fingerprint]
+	return req, ok
+}
+
+// empty returns whether no requests are pending on this node.
+func (r *stmtDiagnosticsRegistry) empty() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.mu.requests) == 0
+}
+
+// stmtDiagnosticsCollection is the collection of the diagnostics of an
+// execution of a statement matching a pending request. A nil collection
+// collects nothing.
+type stmtDiagnosticsCollection struct {
+	registry    *stmtDiagnosticsRegistry
+	session     *Session
+	fingerprint string
+	request     stmtDiagnosticsRequest
+	// done is set once the session tracing is stopped.
+	done bool
+}
+
+// startStmtDiagnostics starts the session tracing for the execution of a
+// statement matching a pending request. It returns nil if the statement
+// matches no request, or if the session tracing is already enabled by the
+// client.
+func startStmtDiagnostics(session *Session, stmt Statement) *stmtDiagnosticsCollection {
+	r := session.stmtDiagnostics
+	if r == nil || r.empty() {
+		return nil
+	}
+	if _, ok := stmt.AST.(tree.HiddenFromStats); ok {
+		return nil
+	}
+	if session.Tracing.Enabled() {
+		return nil
+	}
+	fingerprint := stmtFingerprint(stmt)
+	req, ok := r.findRequest(fingerprint)
+	if !ok {
+		return nil
+	}
+	if err := session.Tracing.StartTracing(
+		tracing.SnowballRecording, true, /* kvTracingEnabled */
+	); err != nil {
+		log.Warningf(session.Ctx(), "could not trace the statement of diagnostics request %d: %v",
+			req.id, err)
+		return nil
+	}
+	return &stmtDiagnosticsCollection{
+		registry:    r,
+		session:     session,
+		fingerprint: fingerprint,
+		request:     req,
+	}
+}
+
+// abandon stops the session tracing if the collection wasn't finished, when
+// the execution of the statement ended early.
+func (c *stmtDiagnosticsCollection) abandon() {
+	if c == nil || c.done {
+		return
+	}
+	c.done = true
+	if err := stopTracing(c.session); err != nil {
+		log.Warningf(c.session.Ctx(), "%v", err)
+	}
+}
+
+// finish stops the session tracing once the statement is executed. If the
+// execution ran for longer than the minimum latency of the request, the
+// bundle of the statement is collected and the request is completed. The
+// errors are logged rather than returned, so that they don't fail the
+// statement.
+func (c *stmtDiagnosticsCollection) finish(
+	p *planner, stmt Statement, plan planNode, execErr error,
+) {
+	if c == nil || c.done {
+		return
+	}
+	c.done = true
+	ctx := c.session.Ctx()
+	if err := stopTracing(c.session); err != nil {
+		log.Warningf(ctx, "%v", err)
+		return
+	}
+	latency := p.phaseTimes[plannerEndExecStmt].Sub(p.phaseTimes[sessionStartParse])
+	if latency <= c.request.minLatency {
+		return
+	}
+	// The request is removed before the bundle is stored, so that the
+	// concurrent executions on this node don't collect it as well. If the
+	// bundle can't be stored, the request is found again by the next poll.
+	c.registry.removeRequest(c.fingerprint, c.request.id)
+
+	var b statementBundleBuilder
+	err := p.addBundlePlan(ctx, &b, stmt.AST, plan)
+	if err == nil {
+		err = p.addBundleExecution(&b, execErr)
+	}
+	var bundle []byte
+	if err == nil {
+		bundle, err = b.finish()
+	}
+	if err == nil {
+		err = completeStmtDiagnosticsRequest(
+			ctx, p.ExecCfg(), c.request.id, tree.AsString(stmt.AST), bundle)
+	}
+	if err != nil {
+		log.Warningf(ctx, "could not collect the statement bundle of diagnostics request %d: %v",
+			c.request.id, err)
+	}
+}
+
+var errStmtDiagnosticsRequestCompleted = errors.New(
+	"statement diagnostics request already completed")
+
+// completeStmtDiagnosticsRequest stores a bundle and completes the request it
+// was collected for. Nothing is stored if the request was already completed
+// by another execution.
+func completeStmtDiagnosticsRequest(
+	ctx context.Context, cfg *ExecutorConfig, id int64, stmt string, bundle []byte,
+) error {
+	ie := InternalExecutor{LeaseManager: cfg.LeaseManager}
+	err := cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		bundleID, err := insertStatementBundleInTxn(ctx, ie, txn, stmt, bundle)
+		if err != nil {
+			return err
+		}
+		n, err := ie.ExecuteStatementInTransaction(ctx, "complete-stmt-diagnostics-request", txn,
+			`UPDATE system.statement_diagnostics_requests
+          SET completed = true, statement_bundle_id = $2, collected = now()
+        WHERE id = $1 AND NOT completed`,
+			id, bundleID,
+		)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return errStmtDiagnosticsRequestCompleted
+		}
+		return nil
+	})
+	if err == errStmtDiagnosticsRequestCompleted {
+		return nil
+	}
+	return err
+}
+
+// RequestStatementDiagnostics is part of the tree.EvalPlanner interface.
+//
+// Privileges: membership of the admin role, since the bundles contain the
+// cluster settings.
+func (p *planner) RequestStatementDiagnostics(
+	ctx context.Context, fingerprint string, minLatency time.Duration,
+) (int64, error) {
+	if err := p.requireAdminRole(ctx, "request statement diagnostics"); err != nil {
+		return 0, err
+	}
+	// The fingerprint is normalized the way the fingerprints of the executed
+	// statements are.
+	stmt, err := parser.ParseOne(fingerprint)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid statement fingerprint")
+	}
+	fingerprint = tree.StatementFingerprint(stmt)
+
+	row, err := p.QueryRow(ctx,
+		`INSERT INTO system.statement_diagnostics_requests
+         (completed, statement_fingerprint, min_execution_latency)
+       VALUES (false, $1, $2) RETURNING id`,
+		fingerprint, &tree.DInterval{Duration: duration.Duration{Nanos: minLatency.Nanoseconds()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	id := int64(tree.MustBeDInt(row[0]))
+	if p.session.stmtDiagnostics != nil {
+		p.session.stmtDiagnostics.addRequest(
+			fingerprint, stmtDiagnosticsRequest{id: id, minLatency: minLatency})
+	}
+	return id, nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	prometheusgo "github.com/prometheus/client_model/go"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+)
+
+// stmtFingerprintMetricsTopN is the number of statement fingerprints whose
+// metrics are exported to prometheus. The fingerprints are labels of the
+// metrics, so they are restricted to the ones executed the most to bound the
+// number of time series of the scrapers.
+var stmtFingerprintMetricsTopN = settings.RegisterIntSetting(
+	"sql.metrics.statement_details.prometheus_top_n",
+	"number of statement fingerprints, among the most executed ones, whose execution "+
+		"counts and latencies are exported to prometheus (0 to disable)",
+	0,
+)
+
+var (
+	metaStmtFingerprintCount = metric.Metadata{
+		Name: "sql.statements.fingerprint.count",
+		Help: "Number of executions of the statement fingerprint"}
+	metaStmtFingerprintFailureCount = metric.Metadata{
+		Name: "sql.statements.fingerprint.failure.count",
+		Help: "Number of failed executions of the statement fingerprint"}
+	metaStmtFingerprintServiceLatency = metric.Metadata{
+		Name: "sql.statements.fingerprint.service.latency",
+		Help: "Latency of the executions of the statement fingerprint, in nanoseconds"}
+)
+
+// stmtFingerprintMetrics exports the statistics collected for the statement
+// fingerprints with the most executions, per application, as prometheus
+// metrics labeled with the application name and the fingerprint. The metrics
+// are reset with the statistics, which prometheus handles as counter resets.
+type stmtFingerprintMetrics struct {
+	s *sqlStats
+}
+
+var _ metric.PrometheusIterable = stmtFingerprintMetrics{}
+
+// FingerprintMetrics returns the per-fingerprint metrics of the statements,
+// which are exported to prometheus but aren't recorded as time series.
+func (e *Executor) FingerprintMetrics() metric.PrometheusIterable {
+	return stmtFingerprintMetrics{s: &e.sqlStats}
+}
+
+// EachPrometheusMetric implements the metric.PrometheusIterable interface.
+func (m stmtFingerprintMetrics) EachPrometheusMetric(f func(metric.PrometheusExportable)) {
+	n := int(stmtFingerprintMetricsTopN.Get(&m.s.st.SV))
+	if n <= 0 {
+		return
+	}
+
+	type appFingerprintStats struct {
+		appName string
+		*stmtFingerprintStats
+	}
+	m.s.Lock()
+	apps := make(map[string]*appStats, len(m.s.apps))
+	for appName, a := range m.s.apps {
+		apps[appName] = a
+	}
+	m.s.Unlock()
+	var stats []appFingerprintStats
+	for appName, a := range apps {
+		for _, s := range a.fingerprintStats() {
+			stats = append(stats, appFingerprintStats{appName: appName, stmtFingerprintStats: s})
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].data.Count != stats[j].data.Count {
+			return stats[i].data.Count > stats[j].data.Count
+		}
+		if stats[i].appName != stats[j].appName {
+			return stats[i].appName < stats[j].appName
+		}
+		return stats[i].fingerprint < stats[j].fingerprint
+	})
+	if len(stats) > n {
+		stats = stats[:n]
+	}
+
+	for _, s := range stats {
+		labeled := func(meta metric.Metadata) metric.Metadata {
+			meta.AddLabel("application_name", s.appName)
+			meta.AddLabel("fingerprint", s.fingerprint)
+			return meta
+		}
+		f(&stmtFingerprintCounter{
+			Metadata: labeled(metaStmtFingerprintCount),
+			count:    s.data.Count,
+		})
+		f(&stmtFingerprintCounter{
+			Metadata: labeled(metaStmtFingerprintFailureCount),
+			count:    s.failureCount,
+		})
+		f(&stmtFingerprintLatency{
+			Metadata: labeled(metaStmtFingerprintServiceLatency),
+			stats:    s.stmtFingerprintStats,
+		})
+	}
+}
+
+// stmtFingerprintCounter is a counter of the executions of a statement
+// fingerprint.
+type stmtFingerprintCounter struct {
+	metric.Metadata
+	count int64
+}
+
+// GetType implements the metric.PrometheusExportable interface.
+func (c *stmtFingerprintCounter) GetType() *prometheusgo.MetricType {
+	return prometheusgo.MetricType_COUNTER.Enum()
+}
+
+// ToPrometheusMetric implements the metric.PrometheusExportable interface.
+func (c *stmtFingerprintCounter) ToPrometheusMetric() *prometheusgo.Metric {
+	return &prometheusgo.Metric{
+		Counter: &prometheusgo.Counter{Value: proto.Float64(float64(c.count))},
+	}
+}
+
+// stmtFingerprintLatency is the histogram of the service latencies of the
+// executions of a statement fingerprint.
+type stmtFingerprintLatency struct {
+	metric.Metadata
+	stats *stmtFingerprintStats
+}
+
+// GetType implements the metric.PrometheusExportable interface.
+func (l *stmtFingerprintLatency) GetType() *prometheusgo.MetricType {
+	return prometheusgo.MetricType_HISTOGRAM.Enum()
+}
+
+// ToPrometheusMetric implements the metric.PrometheusExportable interface.
+func (l *stmtFingerprintLatency) ToPrometheusMetric() *prometheusgo.Metric {
+	// The latencies of the statements are recorded in microseconds, while
+	// the other latency metrics are in nanoseconds.
+	return &prometheusgo.Metric{
+		Histogram: metric.ToPrometheusHistogram(l.stats.serviceLat, int64(time.Microsecond)),
+	}
+}
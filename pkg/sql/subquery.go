@@ -40,6 +40,13 @@ type subquery struct {
 	started  bool
 	plan     planNode
 	result   tree.Datum
+
+	// correlation is set if the sub-query refers to columns of the
+	// surrounding query. Such a sub-query cannot be pre-evaluated; instead it
+	// is evaluated for every row of the surrounding query (see
+	// subqueryCorrelation.eval). In that case plan is only used to describe
+	// the sub-query (e.g. in EXPLAIN) and is never run.
+	correlation *subqueryCorrelation
 }
 
 type subqueryExecMode int
@@ -81,7 +88,31 @@ func (s *subquery) Format(buf *bytes.Buffer, f tree.FmtFlags) {
 func (s *subquery) String() string { return tree.AsString(s) }
 
 func (s *subquery) Walk(v tree.Visitor) tree.Expr {
-	return s
+	if s.correlation == nil {
+		return s
+	}
+	// The references to the surrounding query are regular expressions of the
+	// surrounding query, so visitors (e.g. to rebind IndexedVars) must see
+	// them.
+	var newVars []tree.TypedExpr
+	for i, e := range s.correlation.outerVars {
+		newExpr, _ := tree.WalkExpr(v, e)
+		if newExpr != e {
+			if newVars == nil {
+				newVars = make([]tree.TypedExpr, len(s.correlation.outerVars))
+				copy(newVars, s.correlation.outerVars)
+			}
+			newVars[i] = newExpr.(tree.TypedExpr)
+		}
+	}
+	if newVars == nil {
+		return s
+	}
+	sCopy := *s
+	correlation := *s.correlation
+	correlation.outerVars = newVars
+	sCopy.correlation = &correlation
+	return &sCopy
 }
 
 func (s *subquery) Variable() {}
@@ -100,7 +131,10 @@ func (s *subquery) TypeCheck(_ *tree.SemaContext, desired types.T) (tree.TypedEx
 
 func (s *subquery) ResolvedType() types.T { return s.typ }
 
-func (s *subquery) Eval(_ *tree.EvalContext) (tree.Datum, error) {
+func (s *subquery) Eval(evalCtx *tree.EvalContext) (tree.Datum, error) {
+	if s.correlation != nil {
+		return s.correlation.eval(evalCtx, s)
+	}
 	if s.result == nil {
 		panic("subquery was not pre-evaluated properly")
 	}
@@ -246,6 +280,11 @@ func (v *subqueryPlanVisitor) subqueryNode(ctx context.Context, sq *subquery) er
 	if !sq.expanded {
 		panic("subquery was not expanded properly")
 	}
+	if sq.correlation != nil {
+		// Correlated sub-queries are evaluated for every row of the
+		// surrounding query instead.
+		return nil
+	}
 	if !sq.started {
 		if err := v.p.startPlan(ctx, sq.plan); err != nil {
 			return err
@@ -314,6 +353,10 @@ func collectSubquerySpans(params runParams, plan planNode) (roachpb.Spans, error
 type subqueryVisitor struct {
 	*planner
 	columns int
+	// sources and iVarHelper, if sources is non-nil, describe the
+	// surrounding query. They are used to support correlated sub-queries.
+	sources    multiSourceInfo
+	iVarHelper tree.IndexedVarHelper
 	path    []tree.Expr // parent expressions
 	pathBuf [4]tree.Expr
 	err     error
@@ -354,7 +397,16 @@ func (v *subqueryVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.E
 	// Calling newPlan() might recursively invoke expandSubqueries, so we need to preserve
 	// the state of the visitor across the call to newPlan().
 	visitorCopy := v.planner.subqueryVisitor
+	// Let the sub-query refer to the columns of the surrounding query, if
+	// there is one. Only the immediately surrounding query is visible.
+	var scope *correlationScope
+	if v.sources != nil {
+		scope = &correlationScope{sources: v.sources}
+	}
+	prevScope := v.planner.correlationScope
+	v.planner.correlationScope = scope
 	plan, err := v.planner.newPlan(v.ctx, sq.Select, nil)
+	v.planner.correlationScope = prevScope
 	v.planner.subqueryVisitor = visitorCopy
 	if err != nil {
 		v.err = err
@@ -362,6 +414,17 @@ func (v *subqueryVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.E
 	}
 
 	result := &subquery{subquery: sq, plan: plan}
+	if scope != nil && len(scope.cols) > 0 {
+		result.correlation = &subqueryCorrelation{
+			p:         v.planner,
+			scope:     scope,
+			outerVars: make([]tree.TypedExpr, len(scope.cols)),
+			cache:     make(map[string]tree.Datum),
+		}
+		for i, col := range scope.cols {
+			result.correlation.outerVars[i] = v.iVarHelper.IndexedVar(col)
+		}
+	}
 
 	if exists != nil {
 		result.execMode = execModeExists
@@ -409,10 +472,19 @@ func (v *subqueryVisitor) VisitPost(expr tree.Expr) tree.Expr {
 	return expr
 }
 
+// replaceSubqueries replaces the sub-queries in expr by subquery nodes. If
+// sources is non-nil, the sub-queries may refer to the columns of these
+// sources, using the given IndexedVarHelper.
 func (p *planner) replaceSubqueries(
-	ctx context.Context, expr tree.Expr, columns int,
+	ctx context.Context,
+	expr tree.Expr,
+	columns int,
+	sources multiSourceInfo,
+	iVarHelper tree.IndexedVarHelper,
 ) (tree.Expr, error) {
-	p.subqueryVisitor = subqueryVisitor{planner: p, columns: columns, ctx: ctx}
+	p.subqueryVisitor = subqueryVisitor{
+		planner: p, columns: columns, sources: sources, iVarHelper: iVarHelper, ctx: ctx,
+	}
 	p.subqueryVisitor.path = p.subqueryVisitor.pathBuf[:0]
 	expr, _ = tree.WalkExpr(&p.subqueryVisitor, expr)
 	return expr, p.subqueryVisitor.err
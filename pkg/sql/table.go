@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -593,6 +594,12 @@ func (p *planner) getAliasedTableName(n tree.TableExpr) (*tree.TableName, error)
 func (p *planner) createSchemaChangeJob(
 	ctx context.Context, tableDesc *sqlbase.TableDescriptor, stmt string,
 ) (sqlbase.MutationID, error) {
+	// The indexes built by a primary key change are rewritten from the
+	// current schema of the table, which can't change until they replace it.
+	if swap := tableDesc.PrimaryKeySwap; swap != nil && swap.MutationID != tableDesc.NextMutationID {
+		return sqlbase.InvalidMutationID, pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"table %q is having its primary key changed, try again later", tableDesc.Name)
+	}
 	span := tableDesc.PrimaryIndexSpan()
 	mutationID, err := tableDesc.FinalizeMutation()
 	if err != nil {
@@ -95,6 +95,7 @@ var _ tableWriter = (*tableDeleter)(nil)
 type tableInserter struct {
 	ri         sqlbase.RowInserter
 	autoCommit bool
+	chunker    mutationChunker
 
 	// Set by init.
 	txn *client.Txn
@@ -112,7 +113,12 @@ func (ti *tableInserter) init(txn *client.Txn) error {
 func (ti *tableInserter) row(
 	ctx context.Context, values tree.Datums, traceKV bool,
 ) (tree.Datums, error) {
-	return nil, ti.ri.InsertRow(ctx, ti.b, values, false, traceKV)
+	if err := ti.ri.InsertRow(ctx, ti.b, values, false, traceKV); err != nil {
+		return nil, err
+	}
+	var err error
+	ti.txn, ti.b, err = ti.chunker.rowAdded(ctx, ti.txn, ti.b, ti.ri.Helper.TableDesc)
+	return nil, err
 }
 
 func (ti *tableInserter) finalize(ctx context.Context, _ bool) (*sqlbase.RowContainer, error) {
@@ -127,7 +133,7 @@ func (ti *tableInserter) finalize(ctx context.Context, _ bool) (*sqlbase.RowCont
 	}
 
 	if err != nil {
-		return nil, sqlbase.ConvertBatchError(ctx, ti.ri.Helper.TableDesc, ti.b)
+		return nil, ti.chunker.wrapErr(sqlbase.ConvertBatchError(ctx, ti.ri.Helper.TableDesc, ti.b))
 	}
 	return nil, nil
 }
@@ -144,6 +150,7 @@ func (ti *tableInserter) fkSpanCollector() sqlbase.FkSpanCollector {
 type tableUpdater struct {
 	ru         sqlbase.RowUpdater
 	autoCommit bool
+	chunker    mutationChunker
 
 	// Set by init.
 	txn *client.Txn
@@ -165,7 +172,12 @@ func (tu *tableUpdater) row(
 ) (tree.Datums, error) {
 	oldValues := values[:len(tu.ru.FetchCols)]
 	updateValues := values[len(tu.ru.FetchCols):]
-	return tu.ru.UpdateRow(ctx, tu.b, oldValues, updateValues, traceKV)
+	newValues, err := tu.ru.UpdateRow(ctx, tu.b, oldValues, updateValues, traceKV)
+	if err != nil {
+		return nil, err
+	}
+	tu.txn, tu.b, err = tu.chunker.rowAdded(ctx, tu.txn, tu.b, tu.ru.Helper.TableDesc)
+	return newValues, err
 }
 
 func (tu *tableUpdater) finalize(ctx context.Context, _ bool) (*sqlbase.RowContainer, error) {
@@ -715,49 +727,75 @@ func (td *tableDeleter) fastPathAvailable(ctx context.Context) bool {
 	return true
 }
 
-// fastDelete adds to the batch the kv operations necessary to delete sql rows
-// without knowing the values that are currently present. fastDelete calls
-// finalize, so it should not be called after.
+// fastDelete runs the kv operations necessary to delete sql rows without
+// knowing the values that are currently present: the spans of the scan are
+// deleted with DelRange, in chunks of at most TableTruncateChunkSize keys so
+// that neither the batches nor the keys they return grow with the number of
+// rows. In an auto-txn, the spans are instead deleted by the batch committing
+// the transaction, which can't be limited since the remaining spans would be
+// left undeleted, so that small deletions still commit in one phase.
+// fastDelete calls finalize, so it should not be called after.
 func (td *tableDeleter) fastDelete(
 	ctx context.Context, scan *scanNode, traceKV bool,
 ) (rowCount int, err error) {
-
-	for _, span := range scan.spans {
-		log.VEvent(ctx, 2, "fast delete: skipping scan")
-		if traceKV {
-			log.VEventf(ctx, 2, "DelRange %s - %s", span.Key, span.EndKey)
+	log.VEvent(ctx, 2, "fast delete: skipping scan")
+
+	// prev is the prefix of the last row counted. It is kept across chunks
+	// since the keys of a row may be returned by consecutive chunks.
+	var prev []byte
+	for spans := scan.spans; len(spans) > 0; {
+		for _, span := range spans {
+			if traceKV {
+				log.VEventf(ctx, 2, "DelRange %s - %s", span.Key, span.EndKey)
+			}
+			td.b.DelRange(span.Key, span.EndKey, true /* returnKeys */)
 		}
-		td.b.DelRange(span.Key, span.EndKey, true)
-	}
-
-	_, err = td.finalize(ctx, traceKV)
-	if err != nil {
-		return 0, err
-	}
-
-	for _, r := range td.b.Results {
-		var prev []byte
-		for _, i := range r.Keys {
-			// If prefix is same, don't bother decoding key.
-			if len(prev) > 0 && bytes.HasPrefix(i, prev) {
-				continue
+		if td.autoCommit {
+			if _, err := td.finalize(ctx, traceKV); err != nil {
+				return 0, err
 			}
-
-			after, ok, err := scan.fetcher.ReadIndexKey(i)
-			if err != nil {
+		} else {
+			td.b.Header.MaxSpanRequestKeys = TableTruncateChunkSize
+			if err := td.txn.Run(ctx, td.b); err != nil {
 				return 0, err
 			}
-			if !ok {
-				return 0, errors.Errorf("key did not match descriptor")
+		}
+
+		var resumeSpans roachpb.Spans
+		for _, r := range td.b.Results {
+			for _, i := range r.Keys {
+				// If prefix is same, don't bother decoding key.
+				if len(prev) > 0 && bytes.HasPrefix(i, prev) {
+					continue
+				}
+
+				after, ok, err := scan.fetcher.ReadIndexKey(i)
+				if err != nil {
+					return 0, err
+				}
+				if !ok {
+					return 0, errors.Errorf("key did not match descriptor")
+				}
+				k := i[:len(i)-len(after)]
+				if !bytes.Equal(k, prev) {
+					prev = k
+					rowCount++
+				}
 			}
-			k := i[:len(i)-len(after)]
-			if !bytes.Equal(k, prev) {
-				prev = k
-				rowCount++
+			if r.ResumeSpan.Key != nil {
+				resumeSpans = append(resumeSpans, r.ResumeSpan)
 			}
 		}
+		spans = resumeSpans
+		td.b = td.txn.NewBatch()
 	}
 
+	// The batch of an auto-txn has already been committed with the deletions.
+	if !td.autoCommit {
+		if _, err := td.finalize(ctx, traceKV); err != nil {
+			return 0, err
+		}
+	}
 	td.b = nil
 	return rowCount, nil
 }
@@ -0,0 +1,107 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tests_test
+
+import (
+	"flag"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/sqlsmith"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/tests"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+var flagSmithQueries = flag.Int(
+	"smith-queries", 100, "number of random queries compared between the DistSQL modes",
+)
+
+// TestRandomQueriesDistSQL compares the results of random queries executed
+// with and without DistSQL.
+func TestRandomQueriesDistSQL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.TODO()
+	params, _ := tests.CreateTestServerParams()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE smith`)
+	sqlDB.Exec(t, `
+CREATE TABLE smith.a (k INT PRIMARY KEY, i INT, f FLOAT, s STRING, d DECIMAL, b BOOL)`)
+	sqlDB.Exec(t, `
+INSERT INTO smith.a
+SELECT i, i % 7, i::FLOAT / 3, (i % 4)::STRING, i::DECIMAL / 8, i % 2 = 0
+  FROM (SELECT generate_series(1, 100) AS i)`)
+	sqlDB.Exec(t, `UPDATE smith.a SET i = NULL, s = NULL WHERE k % 10 = 0`)
+	// The JSONB column isn't supported by the generator.
+	sqlDB.Exec(t, `
+CREATE TABLE smith.b (k INT PRIMARY KEY, ts TIMESTAMP, dt DATE, iv INTERVAL, bs BYTES, j JSONB)`)
+	sqlDB.Exec(t, `
+INSERT INTO smith.b
+SELECT i, '2018-01-01'::TIMESTAMP + i * '1h'::INTERVAL, '2018-01-01'::DATE + i % 30,
+       (i % 5) * '1d'::INTERVAL, (i % 3)::STRING::BYTES, '{}'
+  FROM (SELECT generate_series(1, 50) AS i)`)
+
+	tables, err := sqlsmith.LoadTables(ctx, db, "smith", parser.ParseType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("expected 2 tables, got %v", tables)
+	}
+
+	rng, seed := randutil.NewPseudoRand()
+	t.Logf("seed: %d", seed)
+	smither := sqlsmith.NewSmither(rng, tables)
+	for i := 0; i < *flagSmithQueries; i++ {
+		query := smither.Generate()
+		if err := sqlsmith.CompareModes(ctx, db, query, sqlsmith.DistSQLModes); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The builtin generates the same query for the same seed, against the
+	// tables of the current database.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `SET database = smith`); err != nil {
+		t.Fatal(err)
+	}
+	seed = rng.Int63()
+	var queries [2]string
+	for i := range queries {
+		if err := conn.QueryRowContext(
+			ctx, `SELECT crdb_internal.generate_random_query($1)`, seed,
+		).Scan(&queries[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if queries[0] != queries[1] {
+		t.Fatalf("expected the same query for seed %d, got:\n%s\n%s", seed, queries[0], queries[1])
+	}
+	if _, err := parser.ParseOne(queries[0]); err != nil {
+		t.Fatal(err)
+	}
+}
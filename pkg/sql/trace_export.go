@@ -0,0 +1,180 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+// The traces of SQL statements are exported to the trace collector configured
+// with trace.lightstep.token, trace.zipkin.collector or
+// trace.jaeger.collector. When every statement is exported, which is the
+// default, the traces cover whole transactions. Otherwise, the transactions
+// aren't exported and each sampled statement starts its own trace, which
+// follows the statement through the KV and DistSQL RPCs to the other nodes.
+
+// traceExportSampleRate is the probability with which a statement is sampled
+// for trace export.
+var traceExportSampleRate = settings.RegisterValidatedFloatSetting(
+	"sql.trace.export.sample_rate",
+	"the fraction of SQL statements whose traces are exported to the configured trace collector",
+	1,
+	func(v float64) error {
+		if v < 0 || v > 1 {
+			return errors.Errorf("the sample rate must be between 0 and 1, got %f", v)
+		}
+		return nil
+	},
+)
+
+// traceExportStatementRegexp restricts the statements sampled for trace
+// export to the fingerprints it matches.
+var traceExportStatementRegexp = settings.RegisterValidatedStringSetting(
+	"sql.trace.export.statement_regexp",
+	"if set, only the SQL statements whose fingerprints match this regular expression "+
+		"are sampled for trace export",
+	"",
+	func(s string) error {
+		_, err := regexp.Compile(s)
+		return err
+	},
+)
+
+// traceExportMode is the value of the trace_export session variable.
+type traceExportMode int
+
+const (
+	// traceExportAuto samples the statements according to the cluster
+	// settings.
+	traceExportAuto traceExportMode = iota
+	// traceExportOn exports the traces of all the transactions of the session.
+	traceExportOn
+	// traceExportOff exports none of the traces of the session.
+	traceExportOff
+)
+
+func (m traceExportMode) String() string {
+	switch m {
+	case traceExportAuto:
+		return "auto"
+	case traceExportOn:
+		return "on"
+	case traceExportOff:
+		return "off"
+	default:
+		return fmt.Sprintf("invalid (%d)", m)
+	}
+}
+
+// traceExportRegexpKey is the key of the compiled
+// sql.trace.export.statement_regexp in the executor's regexp cache.
+type traceExportRegexpKey string
+
+// Pattern implements the tree.RegexpCacheKey interface.
+func (k traceExportRegexpKey) Pattern() (string, error) {
+	return string(k), nil
+}
+
+// txnSpanSampling returns the StartSpanOption controlling the export of the
+// span of a new transaction of the session, or nil if the span is exported as
+// usual.
+func (s *Session) txnSpanSampling() opentracing.StartSpanOption {
+	switch s.TraceExport {
+	case traceExportOn:
+		return tracing.Sampled
+	case traceExportOff:
+		return tracing.Unsampled
+	}
+	sv := &s.execCfg.Settings.SV
+	if traceExportSampleRate.Get(sv) == 1 && traceExportStatementRegexp.Get(sv) == "" {
+		return nil
+	}
+	// The statements are sampled individually.
+	return tracing.Unsampled
+}
+
+// sampleStatementTrace returns whether a statement, whose transaction isn't
+// exported, is sampled for trace export.
+func (e *Executor) sampleStatementTrace(session *Session, stmt Statement) bool {
+	if session.TraceExport != traceExportAuto {
+		return false
+	}
+	if tr, ok := e.cfg.AmbientCtx.Tracer.(*tracing.Tracer); !ok || !tr.HasShadowTracer() {
+		return false
+	}
+	sv := &e.cfg.Settings.SV
+	rate := traceExportSampleRate.Get(sv)
+	pattern := traceExportStatementRegexp.Get(sv)
+	if rate == 1 && pattern == "" {
+		// The transaction is exported as a whole.
+		return false
+	}
+	if rate < 1 && rand.Float64() >= rate {
+		return false
+	}
+	if pattern == "" {
+		return true
+	}
+	re, err := e.reCache.GetRegexp(traceExportRegexpKey(pattern))
+	if err != nil {
+		log.Warningf(session.Ctx(), "invalid sql.trace.export.statement_regexp: %v", err)
+		return false
+	}
+	return re.MatchString(stmtFingerprint(stmt))
+}
+
+// maybeStartStatementTrace starts the span of a statement sampled for trace
+// export, and makes it the span of the transaction's context while the
+// statement executes. The returned function finishes the span and restores
+// the context.
+func (e *Executor) maybeStartStatementTrace(session *Session, stmt Statement) func() {
+	if !e.sampleStatementTrace(session, stmt) {
+		return func() {}
+	}
+	ts := &session.TxnState
+	txnCtx := ts.Ctx
+	sp := e.cfg.AmbientCtx.Tracer.StartSpan(
+		sqlStmtName, opentracing.ChildOf(ts.sp.Context()), tracing.Sampled)
+	// The constants are hidden since the traces leave the cluster.
+	sp.SetTag("statement", stmtFingerprint(stmt))
+	stmtCtx := opentracing.ContextWithSpan(txnCtx, sp)
+	ts.Ctx = stmtCtx
+	return func() {
+		// The statement may have finished the transaction.
+		if ts.Ctx == stmtCtx {
+			ts.Ctx = txnCtx
+		}
+		sp.Finish()
+	}
+}
+
+// stmtFingerprint returns the fingerprint of a statement, which is the
+// statement with its constants hidden.
+func stmtFingerprint(stmt Statement) string {
+	if stmt.AnonymizedStr != "" {
+		return stmt.AnonymizedStr
+	}
+	return tree.StatementFingerprint(stmt.AST)
+}
@@ -37,8 +37,8 @@ const TableTruncateChunkSize = indexTruncateChunkSize
 //          mysql requires DROP (for mysql >= 5.1.16, DELETE before that).
 func (p *planner) Truncate(ctx context.Context, n *tree.Truncate) (planNode, error) {
 	// Since truncation may cascade to a given table any number of times, start by
-	// building the unique set (by ID) of tables to truncate.
-	toTruncate := make(map[sqlbase.ID]struct{}, len(n.Tables))
+	// building the unique set (by ID) of tables to truncate, with their names.
+	toTruncate := make(map[sqlbase.ID]string, len(n.Tables))
 	// toTraverse is the list of tables whose references need to be traversed
 	// while constructing the list of tables that should be truncated.
 	toTraverse := make([]sqlbase.TableDescriptor, 0, len(n.Tables))
@@ -69,7 +69,7 @@ func (p *planner) Truncate(ctx context.Context, n *tree.Truncate) (planNode, err
 			return nil, err
 		}
 
-		toTruncate[tableDesc.ID] = struct{}{}
+		toTruncate[tableDesc.ID] = tableDesc.Name
 		toTraverse = append(toTraverse, *tableDesc)
 	}
 
@@ -97,7 +97,7 @@ func (p *planner) Truncate(ctx context.Context, n *tree.Truncate) (planNode, err
 				if err := p.CheckPrivilege(other, privilege.DROP); err != nil {
 					return nil, err
 				}
-				toTruncate[other.ID] = struct{}{}
+				toTruncate[other.ID] = other.Name
 				toTraverse = append(toTraverse, *other)
 			}
 		}
@@ -110,10 +110,27 @@ func (p *planner) Truncate(ctx context.Context, n *tree.Truncate) (planNode, err
 
 	// TODO(knz): move truncate logic to Start/Next so it can be used with SHOW TRACE FOR.
 	traceKV := p.session.Tracing.KVTracingEnabled()
-	for id := range toTruncate {
+	for id, name := range toTruncate {
 		if err := p.truncateTable(p.session.Ctx(), id, traceKV); err != nil {
 			return nil, err
 		}
+
+		// Log Truncate Table event. This is an auditable log event and is
+		// recorded in the same transaction as the table descriptor update.
+		if err := MakeEventLogger(p.LeaseMgr()).InsertEventRecord(
+			ctx,
+			p.txn,
+			EventLogTruncateTable,
+			int32(id),
+			int32(p.evalCtx.NodeID),
+			&sqlbase.TruncateTableEvent{
+				TableName: name,
+				Statement: n.String(),
+				User:      p.session.User,
+			},
+		); err != nil {
+			return nil, err
+		}
 	}
 
 	return &zeroNode{}, nil
@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 )
 
 // unionNode is a planNode whose rows are the result of one of three set
@@ -66,6 +67,9 @@ type unionNode struct {
 	// right and left are the data source operands.
 	// right is read first, to populate the `emit` field.
 	right, left planNode
+	// leftAsync, if set, runs the left plan in its own goroutine while the
+	// rows on the right are consumed. See maybeStartAsync.
+	leftAsync *asyncPlan
 	// inverted, when true, indicates that the right plan corresponds to
 	// the left operand in the input SQL syntax, and vice-versa.
 	inverted bool
@@ -75,6 +79,10 @@ type unionNode struct {
 	// emit contains the rows seen on the right so far and performs the
 	// selection/filtering logic.
 	emit unionNodeEmit
+	// emitKeys is the map underlying emit. It is used to detect when a new
+	// key is stored so that its memory can be accounted for in emitMemAcc.
+	emitKeys   map[string]int
+	emitMemAcc mon.BoundAccount
 	// scratch is a preallocated buffer for formatting the key of the
 	// current row on the right.
 	scratch []byte
@@ -86,24 +94,25 @@ func (p *planner) UnionClause(
 ) (planNode, error) {
 	var emitAll = false
 	var emit unionNodeEmit
+	emitKeys := make(map[string]int)
 	switch n.Type {
 	case tree.UnionOp:
 		if n.All {
 			emitAll = true
 		} else {
-			emit = make(unionNodeEmitDistinct)
+			emit = unionNodeEmitDistinct(emitKeys)
 		}
 	case tree.IntersectOp:
 		if n.All {
-			emit = make(intersectNodeEmitAll)
+			emit = intersectNodeEmitAll(emitKeys)
 		} else {
-			emit = make(intersectNodeEmitDistinct)
+			emit = intersectNodeEmitDistinct(emitKeys)
 		}
 	case tree.ExceptOp:
 		if n.All {
-			emit = make(exceptNodeEmitAll)
+			emit = exceptNodeEmitAll(emitKeys)
 		} else {
-			emit = make(exceptNodeEmitDistinct)
+			emit = exceptNodeEmitDistinct(emitKeys)
 		}
 	default:
 		return nil, errors.Errorf("%v is not supported", n.Type)
@@ -150,12 +159,14 @@ func (p *planner) UnionClause(
 	}
 
 	node := &unionNode{
-		right:    right,
-		left:     left,
-		inverted: inverted,
-		emitAll:  emitAll,
-		emit:     emit,
-		scratch:  make([]byte, 0),
+		right:      right,
+		left:       left,
+		inverted:   inverted,
+		emitAll:    emitAll,
+		emit:       emit,
+		emitKeys:   emitKeys,
+		emitMemAcc: p.session.TxnState.makeBoundAccount(),
+		scratch:    make([]byte, 0),
 	}
 	return node, nil
 }
@@ -164,6 +175,9 @@ func (n *unionNode) Values() tree.Datums {
 	if n.right != nil {
 		return n.right.Values()
 	}
+	if n.leftAsync != nil {
+		return n.leftAsync.values()
+	}
 	if n.left != nil {
 		return n.left.Values()
 	}
@@ -183,7 +197,12 @@ func (n *unionNode) readRight(params runParams) (bool, error) {
 		// TODO(dan): Sending the entire encodeDTuple to be stored in the map would
 		// use a lot of memory for big rows or big resultsets. Consider using a hash
 		// of the bytes instead.
-		if n.emit.emitRight(n.scratch) {
+		numKeys := len(n.emitKeys)
+		emit := n.emit.emitRight(n.scratch)
+		if err := n.accountForNewKey(params.ctx, numKeys); err != nil {
+			return false, err
+		}
+		if emit {
 			return true, nil
 		}
 	}
@@ -197,32 +216,69 @@ func (n *unionNode) readRight(params runParams) (bool, error) {
 }
 
 func (n *unionNode) readLeft(params runParams) (bool, error) {
-	next, err := n.left.Next(params)
-	for ; next; next, err = n.left.Next(params) {
+	next, err := n.nextLeft(params)
+	for ; next; next, err = n.nextLeft(params) {
 		if n.emitAll {
 			return true, nil
 		}
 		n.scratch = n.scratch[:0]
-		if n.scratch, err = sqlbase.EncodeDatums(n.scratch, n.left.Values()); err != nil {
+		if n.scratch, err = sqlbase.EncodeDatums(n.scratch, n.Values()); err != nil {
+			return false, err
+		}
+		numKeys := len(n.emitKeys)
+		emit := n.emit.emitLeft(n.scratch)
+		if err := n.accountForNewKey(params.ctx, numKeys); err != nil {
 			return false, err
 		}
-		if n.emit.emitLeft(n.scratch) {
+		if emit {
 			return true, nil
 		}
 	}
 	if err != nil {
 		return false, err
 	}
-	n.left.Close(params.ctx)
-	n.left = nil
+	n.closeLeft(params.ctx)
 	return false, nil
 }
 
+// nextLeft advances the left plan, possibly running asynchronously.
+func (n *unionNode) nextLeft(params runParams) (bool, error) {
+	if n.leftAsync != nil {
+		return n.leftAsync.next(params.ctx)
+	}
+	return n.left.Next(params)
+}
+
+// closeLeft closes the left plan, after stopping the goroutine running it if
+// any.
+func (n *unionNode) closeLeft(ctx context.Context) {
+	if n.leftAsync != nil {
+		n.leftAsync.close(ctx)
+		n.leftAsync = nil
+	}
+	n.left.Close(ctx)
+	n.left = nil
+}
+
+// accountForNewKey registers the memory used by the key in n.scratch if the
+// last call to the emitter stored it, i.e. if the number of keys changed from
+// numKeys.
+func (n *unionNode) accountForNewKey(ctx context.Context, numKeys int) error {
+	if len(n.emitKeys) == numKeys {
+		return nil
+	}
+	return n.emitMemAcc.Grow(ctx, int64(len(n.scratch)))
+}
+
 func (n *unionNode) Start(params runParams) error {
+	n.leftAsync = params.p.maybeStartAsync(params, n.left)
 	if err := n.right.Start(params); err != nil {
 		return err
 	}
-	return n.left.Start(params)
+	if n.leftAsync == nil {
+		return n.left.Start(params)
+	}
+	return nil
 }
 
 func (n *unionNode) Next(params runParams) (bool, error) {
@@ -244,9 +300,9 @@ func (n *unionNode) Close(ctx context.Context) {
 		n.right = nil
 	}
 	if n.left != nil {
-		n.left.Close(ctx)
-		n.left = nil
+		n.closeLeft(ctx)
 	}
+	n.emitMemAcc.Close(ctx)
 }
 
 // unionNodeEmit represents the emitter logic for one of the six combinations of
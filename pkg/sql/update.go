@@ -62,6 +62,10 @@ func (p *planner) makeEditNode(
 				priv, tableDesc.Kind(), tn, tableDesc.Kind())
 	}
 
+	p.maybeAudit(tableDesc, true /* writing */)
+	if err := p.checkDatabaseUsage(ctx, tableDesc); err != nil {
+		return editNodeBase{}, err
+	}
 	if err := p.CheckPrivilege(tableDesc, priv); err != nil {
 		return editNodeBase{}, err
 	}
@@ -125,6 +129,13 @@ type updateNode struct {
 	run struct {
 		// The following fields are populated during Start().
 		editNodeRun
+
+		// chunkScan is the scan of the primary index feeding the update, when
+		// its chunks are committed. It is resumed past the last updated row in
+		// the transaction of every new chunk. chunkScanDone is set once no
+		// span is left to resume it at.
+		chunkScan     *scanNode
+		chunkScanDone bool
 	}
 }
 
@@ -228,7 +239,9 @@ func (p *planner) Update(
 	setExprs := make([]*tree.UpdateExpr, len(n.Exprs))
 	for i, expr := range n.Exprs {
 		// Replace the sub-query nodes.
-		newExpr, err := p.replaceSubqueries(ctx, expr.Expr, len(expr.Names))
+		newExpr, err := p.replaceSubqueries(
+			ctx, expr.Expr, len(expr.Names), nil /* sources */, tree.IndexedVarHelper{},
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -251,8 +264,16 @@ func (p *planner) Update(
 		return nil, err
 	}
 
+	// The policies of the table are checked on the updated rows, like CHECK
+	// constraints.
+	policyExpr, err := p.rowLevelSecurityExpr(ctx, en.tableDesc, policyCommandUpdate, true /* withCheck */)
+	if err != nil {
+		return nil, err
+	}
+
 	var requestedCols []sqlbase.ColumnDescriptor
-	if _, retExprs := n.Returning.(*tree.ReturningExprs); retExprs || len(en.tableDesc.Checks) > 0 {
+	if _, retExprs := n.Returning.(*tree.ReturningExprs); retExprs ||
+		len(en.tableDesc.Checks) > 0 || policyExpr != nil {
 		// TODO(dan): This could be made tighter, just the rows needed for RETURNING
 		// exprs.
 		requestedCols = en.tableDesc.Columns
@@ -268,15 +289,31 @@ func (p *planner) Update(
 		return nil, err
 	}
 	tw := tableUpdater{ru: ru, autoCommit: p.autoCommit}
+	// The chunks can only be committed if the rows to update are visited in
+	// primary key order and keep their primary key, see resumeChunkedScan.
+	// The foreign key checks and cascades would read from the transaction
+	// being committed.
+	updatesPrimaryKey := false
+	for _, col := range updateCols {
+		if en.tableDesc.PrimaryIndex.ContainsColumnID(col.ID) {
+			updatesPrimaryKey = true
+		}
+	}
+	tw.chunker.init(p, len(fkTables) == 0 && n.OrderBy == nil && n.Limit == nil &&
+		!updatesPrimaryKey && !sqlbase.IsSystemConfigID(en.tableDesc.ID))
 
 	tracing.AnnotateTrace()
 
 	// We construct a query containing the columns being updated, and then later merge the values
 	// they are being updated with into that renderNode to ideally reuse some of the queries.
+	where, err := p.addRowLevelSecurityFilter(ctx, en.tableDesc, policyCommandUpdate, n.Where)
+	if err != nil {
+		return nil, err
+	}
 	rows, err := p.SelectClause(ctx, &tree.SelectClause{
 		Exprs: sqlbase.ColumnsSelectors(ru.FetchCols),
 		From:  &tree.From{Tables: []tree.TableExpr{n.Table}},
-		Where: n.Where,
+		Where: where,
 	}, n.OrderBy, n.Limit, nil /*desiredTypes*/, publicAndNonPublicColumns)
 	if err != nil {
 		return nil, err
@@ -387,7 +424,7 @@ func (p *planner) Update(
 		tw:            tw,
 		sourceSlots:   sourceSlots,
 	}
-	if err := un.checkHelper.init(ctx, p, tn, en.tableDesc); err != nil {
+	if err := un.checkHelper.init(ctx, p, tn, en.tableDesc, policyCommandUpdate); err != nil {
 		return nil, err
 	}
 	if err := un.run.initEditNode(
@@ -401,6 +438,16 @@ func (u *updateNode) Start(params runParams) error {
 	if err := u.run.startEditNode(params, &u.editNodeBase); err != nil {
 		return err
 	}
+	if u.tw.chunker.commitChunk != nil {
+		u.run.chunkScan = u.chunkedScan()
+		if u.run.chunkScan == nil {
+			// The scan feeding the update can't be resumed in a new
+			// transaction, so the chunks are only sent.
+			u.tw.chunker.commitChunk = nil
+		} else {
+			u.run.chunkScan.softLimit = int64(u.tw.chunker.chunkSize)
+		}
+	}
 	return u.run.tw.init(params.p.txn)
 }
 
@@ -412,7 +459,16 @@ func (u *updateNode) Close(ctx context.Context) {
 }
 
 func (u *updateNode) Next(params runParams) (bool, error) {
-	next, err := u.run.rows.Next(params)
+	next, err := u.next(params)
+	return next, u.tw.chunker.wrapErr(err)
+}
+
+func (u *updateNode) next(params runParams) (bool, error) {
+	var next bool
+	var err error
+	if !u.run.chunkScanDone {
+		next, err = u.run.rows.Next(params)
+	}
 	if !next {
 		if err == nil {
 			if err := params.p.cancelChecker.Check(); err != nil {
@@ -462,17 +518,24 @@ func (u *updateNode) Next(params runParams) (bool, error) {
 	for i, col := range u.tw.ru.UpdateCols {
 		val := updateValues[i]
 		if !col.Nullable && val == tree.DNull {
-			return false, sqlbase.NewNonNullViolationError(col.Name)
+			return false, sqlbase.NewNonNullViolationError(u.tw.ru.Helper.TableDesc.Name, col.Name)
 		}
 	}
 
 	// Update the row values.
+	txn := u.tw.txn
 	newValues, err := u.tw.row(
 		params.ctx, append(oldValues, updateValues...), params.p.session.Tracing.KVTracingEnabled(),
 	)
 	if err != nil {
 		return false, err
 	}
+	if u.tw.txn != txn {
+		// The chunk was committed.
+		if err := u.resumeChunkedScan(oldValues); err != nil {
+			return false, err
+		}
+	}
 
 	resultRow, err := u.rh.cookResultRow(newValues)
 	if err != nil {
@@ -23,9 +23,13 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/pkg/errors"
@@ -52,15 +56,61 @@ type sessionVar struct {
 	// Reset performs mutations (usually on session) to effect the change
 	// desired by RESET commands.
 	Reset func(*Session) error
+
+	// Default returns the value the variable takes after RESET. It is
+	// reported by SHOW ALL and pg_catalog.pg_settings. If nil, the default is
+	// the current value, as for variables that cannot be changed.
+	Default func(*Session) string
+
+	// Description is a short description of the variable, reported by SHOW
+	// ALL and pg_catalog.pg_settings.
+	Description string
+}
+
+// getDefault returns the value the variable takes after RESET.
+func (v sessionVar) getDefault(session *Session) string {
+	if v.Default != nil {
+		return v.Default(session)
+	}
+	return v.Get(session)
 }
 
-// nopVar is a placeholder for a number of settings sent by various client
-// drivers which we do not support, but should simply ignore rather than
-// throwing an error when trying to SET or SHOW them.
-var nopVar = sessionVar{
-	Set:   func(context.Context, *Session, []tree.TypedExpr) error { return nil },
-	Get:   func(*Session) string { return "" },
-	Reset: func(*Session) error { return nil },
+// makeCompatVar creates a variable for one of the settings sent by various
+// client drivers which we do not support, but should simply ignore rather
+// than throwing an error when trying to SET or SHOW them. The value last
+// SET is remembered for the session so that clients reading it back see
+// what they asked for.
+func makeCompatVar(name, defaultValue, description string) sessionVar {
+	return sessionVar{
+		Description: description,
+		Default:     func(*Session) string { return defaultValue },
+		Set: func(_ context.Context, session *Session, values []tree.TypedExpr) error {
+			evalCtx := session.evalCtx()
+			strs := make([]string, len(values))
+			for i, v := range values {
+				d, err := v.Eval(&evalCtx)
+				if err != nil {
+					return err
+				}
+				strs[i] = tree.AsStringWithFlags(d, tree.FmtBareStrings)
+			}
+			if session.compatVars == nil {
+				session.compatVars = make(map[string]string)
+			}
+			session.compatVars[name] = strings.Join(strs, ", ")
+			return nil
+		},
+		Get: func(session *Session) string {
+			if s, ok := session.compatVars[name]; ok {
+				return s
+			}
+			return defaultValue
+		},
+		Reset: func(session *Session) error {
+			delete(session.compatVars, name)
+			return nil
+		},
+	}
 }
 
 // varGen is the main definition array for all session variables.
@@ -69,6 +119,8 @@ var varGen = map[string]sessionVar{
 	// Set by clients to improve query logging.
 	// See https://www.postgresql.org/docs/10/static/runtime-config-logging.html#GUC-APPLICATION-NAME
 	`application_name`: {
+		Description: "The application name reported in statistics and logs.",
+		Default:     func(session *Session) string { return session.defaults.applicationName },
 		Set: func(_ context.Context, session *Session, values []tree.TypedExpr) error {
 			s, err := getStringVal(session, `application_name`, values)
 			if err != nil {
@@ -89,15 +141,35 @@ var varGen = map[string]sessionVar{
 		},
 	},
 
+	// Supported for PG compatibility only.
+	// See https://www.postgresql.org/docs/10/static/runtime-config-client.html#GUC-BYTEA-OUTPUT
+	`bytea_output`: {
+		Description: "The output format for bytea values.",
+		Get:         func(*Session) string { return "hex" },
+		Set: func(_ context.Context, session *Session, values []tree.TypedExpr) error {
+			s, err := getStringVal(session, `bytea_output`, values)
+			if err != nil {
+				return err
+			}
+			if strings.ToLower(s) != "hex" {
+				return fmt.Errorf("set bytea_output: \"%s\" not supported", s)
+			}
+			return nil
+		},
+		Reset: func(*Session) error { return nil },
+	},
+
 	// Supported for PG compatibility only.
 	// Controls returned message verbosity. We don't support this.
 	// See https://www.postgresql.org/docs/9.6/static/runtime-config-compatible.html
-	`client_min_messages`: nopVar,
+	`client_min_messages`: makeCompatVar(`client_min_messages`, "notice",
+		"The message levels that are sent to the client."),
 
 	// Supported for PG compatibility only.
 	// See https://www.postgresql.org/docs/9.6/static/multibyte.html
 	// Also aliased to SET NAMES.
 	`client_encoding`: {
+		Description: "The client-side character set encoding.",
 		Get: func(*Session) string {
 			return "UTF8"
 		},
@@ -115,10 +187,42 @@ var varGen = map[string]sessionVar{
 		Reset: func(*Session) error { return nil },
 	},
 
+	// CockroachDB extension.
+	`count_from_range_stats`: {
+		Description: "Whether COUNT(*) without filters may be computed from range statistics.",
+		Default:     func(*Session) string { return countRangeStatsOff.String() },
+		Set: func(_ context.Context, session *Session, values []tree.TypedExpr) error {
+			s, err := getStringVal(session, `count_from_range_stats`, values)
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(s) {
+			case "off":
+				session.CountFromRangeStats = countRangeStatsOff
+			case "exact":
+				session.CountFromRangeStats = countRangeStatsExact
+			case "estimate":
+				session.CountFromRangeStats = countRangeStatsEstimate
+			default:
+				return fmt.Errorf("set count_from_range_stats: \"%s\" not supported", s)
+			}
+			return nil
+		},
+		Get: func(session *Session) string {
+			return session.CountFromRangeStats.String()
+		},
+		Reset: func(session *Session) error {
+			session.CountFromRangeStats = countRangeStatsOff
+			return nil
+		},
+	},
+
 	// CockroachDB extension.
 	// TODO(knz): may need to be replaced by 1st element of search_path for
 	// pg compatibility.
 	`database`: {
+		Description: "The current database.",
+		Default:     func(session *Session) string { return session.defaults.database },
 		Set: func(ctx context.Context, session *Session, values []tree.TypedExpr) error {
 			dbName, err := getStringVal(session, `database`, values)
 			if err != nil {
@@ -129,17 +233,26 @@ var varGen = map[string]sessionVar{
 				// Verify database descriptor exists.
 				session.TxnState.mu.RLock()
 				defer session.TxnState.mu.RUnlock()
-				if _, err := MustGetDatabaseDesc(ctx, session.TxnState.mu.txn, &session.virtualSchemas, dbName); err != nil {
+				desc, err := MustGetDatabaseDesc(ctx, session.TxnState.mu.txn, &session.virtualSchemas, dbName)
+				if err != nil {
 					return err
 				}
+				// The users need the CONNECT privilege on the databases they use,
+				// granted to them or to public.
+				if !isVirtualDescriptor(desc) && desc.ID != keys.SystemDatabaseID &&
+					session.User != security.RootUser {
+					if err := CheckPrivilege(session.User, desc, privilege.CONNECT); err != nil {
+						return err
+					}
+				}
 			}
-			session.Database = dbName
+			session.setDatabase(dbName)
 
 			return nil
 		},
 		Get: func(session *Session) string { return session.Database },
 		Reset: func(session *Session) error {
-			session.Database = session.defaults.database
+			session.setDatabase(session.defaults.database)
 			return nil
 		},
 	},
@@ -147,6 +260,7 @@ var varGen = map[string]sessionVar{
 	// Supported for PG compatibility only.
 	// See https://www.postgresql.org/docs/10/static/runtime-config-client.html#GUC-DATESTYLE
 	`datestyle`: {
+		Description: "The display format for date and time values.",
 		Get: func(*Session) string {
 			return "ISO"
 		},
@@ -165,6 +279,8 @@ var varGen = map[string]sessionVar{
 
 	// See https://www.postgresql.org/docs/10/static/runtime-config-client.html#GUC-DEFAULT-TRANSACTION-ISOLATION
 	`default_transaction_isolation`: {
+		Description: "The isolation level of new transactions.",
+		Default:     func(*Session) string { return enginepb.IsolationType(0).ToLowerCaseString() },
 		Set: func(_ context.Context, session *Session, values []tree.TypedExpr) error {
 			// It's unfortunate that clients want us to support both SET
 			// SESSION CHARACTERISTICS AS TRANSACTION ..., which takes the
@@ -194,8 +310,20 @@ var varGen = map[string]sessionVar{
 		},
 	},
 
+	// Supported for PG driver compatibility only.
+	// See https://www.postgresql.org/docs/10/static/runtime-config-client.html#GUC-DEFAULT-TRANSACTION-READ-ONLY
+	`default_transaction_read_only`: {
+		Description: "Whether new transactions are read-only.",
+		// We don't support setting this in any way.
+		Get: func(*Session) string { return "off" },
+	},
+
 	// CockroachDB extension.
 	`distsql`: {
+		Description: "The distributed SQL execution mode.",
+		Default: func(session *Session) string {
+			return DistSQLExecMode(DistSQLClusterExecMode.Get(&session.execCfg.Settings.SV)).String()
+		},
 		Set: func(_ context.Context, session *Session, values []tree.TypedExpr) error {
 			s, err := getStringVal(session, `distsql`, values)
 			if err != nil {
@@ -227,11 +355,20 @@ var varGen = map[string]sessionVar{
 
 	// Supported for PG compatibility only.
 	// See https://www.postgresql.org/docs/10/static/runtime-config-client.html
-	`extra_float_digits`: nopVar,
+	`extra_float_digits`: makeCompatVar(`extra_float_digits`, "0",
+		"The number of digits displayed for floating-point values."),
+
+	// Supported for PG driver compatibility only.
+	// See https://www.postgresql.org/docs/10/static/runtime-config-preset.html#GUC-INTEGER-DATETIMES
+	`integer_datetimes`: {
+		Description: "Whether date and time values are stored as integers.",
+		Get:         func(*Session) string { return "on" },
+	},
 
 	// Supported for PG compatibility only.
 	// See https://www.postgresql.org/docs/10/static/runtime-config-client.html
 	`intervalstyle`: {
+		Description: "The display format for interval values.",
 		Get: func(*Session) string {
 			return "postgres"
 		},
@@ -248,21 +385,96 @@ var varGen = map[string]sessionVar{
 		Reset: func(*Session) error { return nil },
 	},
 
+	// Supported for PG driver compatibility only. CockroachDB does not limit
+	// the length of identifiers; this is the value reported to clients.
+	// See https://www.postgresql.org/docs/10/static/runtime-config-preset.html#GUC-MAX-IDENTIFIER-LENGTH
+	`max_identifier_length`: {
+		Description: "The maximum identifier length reported to clients.",
+		Get:         func(*Session) string { return "128" },
+	},
+
 	// Supported for PG compatibility only.
 	// See https://www.postgresql.org/docs/10/static/runtime-config-preset.html#GUC-MAX-INDEX-KEYS
 	`max_index_keys`: {
-		Get: func(*Session) string { return "32" },
+		Description: "The maximum number of index keys.",
+		Get:         func(*Session) string { return "32" },
+	},
+
+	// CockroachDB extension. See mutation_chunks.go.
+	`mutation_chunk_size`: {
+		Description: "The number of rows written per batch by mutations (0 for the built-in default).",
+		Default:     func(*Session) string { return "0" },
+		Set:         setMutationChunkSize,
+		Get: func(session *Session) string {
+			return strconv.FormatInt(session.MutationChunkSize, 10)
+		},
+		Reset: func(session *Session) error {
+			session.MutationChunkSize = 0
+			return nil
+		},
 	},
 
 	// CockroachDB extension.
 	`node_id`: {
-		Get: func(session *Session) string { return fmt.Sprintf("%d", session.tables.leaseMgr.nodeID.Get()) },
+		Description: "The ID of the node the session is connected to.",
+		Get:         func(session *Session) string { return fmt.Sprintf("%d", session.tables.leaseMgr.nodeID.Get()) },
+	},
+
+	// CockroachDB extension.
+	`parallel_execution`: {
+		Description: "Whether RETURNING NOTHING statements may be executed in parallel.",
+		Default:     func(*Session) string { return strconv.FormatBool(true) },
+		Get:         func(session *Session) string { return strconv.FormatBool(session.ParallelExecution) },
+		Set: func(_ context.Context, session *Session, values []tree.TypedExpr) error {
+			b, err := getSingleBool("parallel_execution", session, values)
+			if err != nil {
+				return err
+			}
+			session.ParallelExecution = (b == tree.DBoolTrue)
+			return nil
+		},
+		Reset: func(session *Session) error {
+			session.ParallelExecution = true
+			return nil
+		},
+	},
+
+	// CockroachDB extension.
+	`quality_of_service`: {
+		Description: "The quality of service class of the session.",
+		Default:     func(*Session) string { return qosRegular.String() },
+		Set: func(_ context.Context, session *Session, values []tree.TypedExpr) error {
+			s, err := getStringVal(session, `quality_of_service`, values)
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(s) {
+			case "regular":
+				session.QualityOfService = qosRegular
+			case "background":
+				session.QualityOfService = qosBackground
+			case "critical":
+				session.QualityOfService = qosCritical
+			default:
+				return fmt.Errorf("set quality_of_service: \"%s\" not supported", s)
+			}
+			return nil
+		},
+		Get: func(session *Session) string {
+			return session.QualityOfService.String()
+		},
+		Reset: func(session *Session) error {
+			session.QualityOfService = qosRegular
+			return nil
+		},
 	},
 
 	// CockroachDB extension (inspired by MySQL).
 	// See https://dev.mysql.com/doc/refman/5.7/en/server-system-variables.html#sysvar_sql_safe_updates
 	`sql_safe_updates`: {
-		Get: func(session *Session) string { return strconv.FormatBool(session.SafeUpdates) },
+		Description: "Whether mutations without a WHERE clause are rejected.",
+		Default:     func(*Session) string { return strconv.FormatBool(false) },
+		Get:         func(session *Session) string { return strconv.FormatBool(session.SafeUpdates) },
 		Set: func(_ context.Context, session *Session, values []tree.TypedExpr) error {
 			b, err := getSingleBool("sql_safe_updates", session, values)
 			if err != nil {
@@ -271,10 +483,16 @@ var varGen = map[string]sessionVar{
 			session.SafeUpdates = (b == tree.DBoolTrue)
 			return nil
 		},
+		Reset: func(session *Session) error {
+			session.SafeUpdates = false
+			return nil
+		},
 	},
 
 	// See https://www.postgresql.org/docs/10/static/ddl-schemas.html#DDL-SCHEMAS-PATH
 	`search_path`: {
+		Description: "The schema search order for unqualified names.",
+		Default:     func(*Session) string { return sqlbase.DefaultSearchPath.String() },
 		Set: func(_ context.Context, session *Session, values []tree.TypedExpr) error {
 			// https://www.postgresql.org/docs/9.6/static/runtime-config-client.html
 			paths := make([]string, len(values))
@@ -295,28 +513,39 @@ var varGen = map[string]sessionVar{
 		},
 	},
 
+	// Supported for PG compatibility only.
+	// See https://www.postgresql.org/docs/10/static/runtime-config-preset.html#GUC-SERVER-ENCODING
+	`server_encoding`: {
+		Description: "The server-side character set encoding.",
+		Get:         func(*Session) string { return "UTF8" },
+	},
+
 	// Supported for PG compatibility only.
 	// See https://www.postgresql.org/docs/10/static/runtime-config-preset.html#GUC-SERVER-VERSION
 	`server_version`: {
-		Get: func(*Session) string { return PgServerVersion },
+		Description: "The version of PostgreSQL the server is compatible with.",
+		Get:         func(*Session) string { return PgServerVersion },
 	},
 
 	// Supported for PG compatibility only.
 	// See https://www.postgresql.org/docs/10/static/runtime-config-preset.html#GUC-SERVER-VERSION-NUM
 	`server_version_num`: {
-		Get: func(*Session) string { return PgServerVersionNum },
+		Description: "The version of PostgreSQL the server is compatible with, as a number.",
+		Get:         func(*Session) string { return PgServerVersionNum },
 	},
 
 	// CockroachDB extension.
 	// In PG this is a pseudo-function used with SELECT, not SHOW.
 	// See https://www.postgresql.org/docs/10/static/functions-info.html
 	`session_user`: {
-		Get: func(session *Session) string { return session.User },
+		Description: "The name of the user of the session.",
+		Get:         func(session *Session) string { return session.User },
 	},
 
 	// Supported for PG compatibility only.
 	// See https://www.postgresql.org/docs/10/static/runtime-config-compatible.html#GUC-STANDARD-CONFORMING-STRINGS
 	`standard_conforming_strings`: {
+		Description: "Whether backslashes in ordinary string literals are literal.",
 		Set: func(_ context.Context, session *Session, values []tree.TypedExpr) error {
 			// If true, escape backslash literals in strings. We do this by default,
 			// and we do not support the opposite behavior.
@@ -334,8 +563,58 @@ var varGen = map[string]sessionVar{
 		Reset: func(*Session) error { return nil },
 	},
 
+	// CockroachDB extension.
+	`statement_max_memory`: {
+		Description: "The memory limit for a single statement (0 for no limit).",
+		Default:     func(*Session) string { return "0" },
+		Set:         setStatementMaxMemory,
+		Get: func(session *Session) string {
+			if session.StatementMaxMemory == 0 {
+				return "0"
+			}
+			return humanizeutil.IBytes(session.StatementMaxMemory)
+		},
+		Reset: func(session *Session) error {
+			session.StatementMaxMemory = 0
+			return nil
+		},
+	},
+
+	// CockroachDB extension.
+	`statement_max_rows_read`: {
+		Description: "The limit on rows read by a single statement (0 for no limit).",
+		Default:     func(*Session) string { return "0" },
+		Set:         setStatementMaxRowsRead,
+		Get: func(session *Session) string {
+			return strconv.FormatInt(session.StatementMaxRowsRead, 10)
+		},
+		Reset: func(session *Session) error {
+			session.StatementMaxRowsRead = 0
+			return nil
+		},
+	},
+
+	// See https://www.postgresql.org/docs/10/static/runtime-config-client.html#GUC-STATEMENT-TIMEOUT
+	`statement_timeout`: {
+		Description: "The maximum duration of a single statement (0 for no limit).",
+		Default:     func(*Session) string { return "0" },
+		Set:         setStatementTimeout,
+		Get: func(session *Session) string {
+			if session.StatementTimeout == 0 {
+				return "0"
+			}
+			return session.StatementTimeout.String()
+		},
+		Reset: func(session *Session) error {
+			session.StatementTimeout = 0
+			return nil
+		},
+	},
+
 	// See https://www.postgresql.org/docs/10/static/runtime-config-client.html#GUC-TIMEZONE
 	`timezone`: {
+		Description: "The time zone for displaying and interpreting time stamps.",
+		Default:     func(*Session) string { return time.UTC.String() },
 		Get: func(session *Session) string {
 			// If the time zone is a "fixed offset" one, initialized from an offset
 			// and not a standard name, then we use a magic format in the Location's
@@ -357,6 +636,7 @@ var varGen = map[string]sessionVar{
 	// This is not directly documented in PG's docs but does indeed behave this way.
 	// See https://github.com/postgres/postgres/blob/REL_10_STABLE/src/backend/utils/misc/guc.c#L3401-L3409
 	`transaction_isolation`: {
+		Description: "The isolation level of the current transaction.",
 		Get: func(session *Session) string {
 			session.TxnState.mu.RLock()
 			defer session.TxnState.mu.RUnlock()
@@ -367,6 +647,7 @@ var varGen = map[string]sessionVar{
 	// CockroachDB extension.
 	// Modeled after transaction_isolation.
 	`transaction_priority`: {
+		Description: "The priority of the current transaction.",
 		Get: func(session *Session) string {
 			session.TxnState.mu.RLock()
 			defer session.TxnState.mu.RUnlock()
@@ -377,18 +658,52 @@ var varGen = map[string]sessionVar{
 	// CockroachDB extension.
 	// Modeled after transaction_isolation.
 	`transaction_status`: {
-		Get: func(session *Session) string { return getTransactionState(&session.TxnState) },
+		Description: "The state of the current transaction.",
+		Get:         func(session *Session) string { return getTransactionState(&session.TxnState) },
 	},
 
 	// Supported for PG driver compatibility only.
 	// See https://www.postgresql.org/docs/10/static/hot-standby.html#HOT-STANDBY-USERS
 	`transaction_read_only`: {
+		Description: "Whether the current transaction is read-only.",
 		// We don't support setting this in any way.
 		Get: func(*Session) string { return "off" },
 	},
 
+	// CockroachDB extension.
+	`trace_export`: {
+		Description: "Whether the session's traces are exported to the trace collector.",
+		Default:     func(*Session) string { return traceExportAuto.String() },
+		Set: func(_ context.Context, session *Session, values []tree.TypedExpr) error {
+			s, err := getStringVal(session, `trace_export`, values)
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(s) {
+			case "auto":
+				session.TraceExport = traceExportAuto
+			case "on":
+				session.TraceExport = traceExportOn
+			case "off":
+				session.TraceExport = traceExportOff
+			default:
+				return fmt.Errorf("set trace_export: \"%s\" not supported", s)
+			}
+			return nil
+		},
+		Get: func(session *Session) string {
+			return session.TraceExport.String()
+		},
+		Reset: func(session *Session) error {
+			session.TraceExport = traceExportAuto
+			return nil
+		},
+	},
+
 	// CockroachDB extension.
 	`tracing`: {
+		Description: "The session tracing mode.",
+		Default:     func(*Session) string { return "off" },
 		Get: func(session *Session) string {
 			if session.Tracing.Enabled() {
 				val := "on"
@@ -184,10 +184,17 @@ func RecomputeViewDependencies(ctx context.Context, txn *client.Txn, e *Executor
 
 	affected := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
 
-	// Clear all the backward dependencies.
+	// Clear all the backward dependencies of views. The references from the
+	// columns of tables to the sequences they use are kept.
 	for tableID, table := range tables {
 		if len(table.DependedOnBy) > 0 {
-			table.DependedOnBy = nil
+			refs := table.DependedOnBy[:0]
+			for _, ref := range table.DependedOnBy {
+				if dependent, ok := tables[ref.ID]; ok && !dependent.IsView() {
+					refs = append(refs, ref)
+				}
+			}
+			table.DependedOnBy = refs
 			affected[tableID] = table
 		}
 	}
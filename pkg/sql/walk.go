@@ -315,6 +315,9 @@ func (v *planVisitor) visit(plan planNode) {
 		for i, cexpr := range n.checkHelper.exprs {
 			subplans = v.expr(name, "check", i, cexpr, subplans)
 		}
+		if n.checkHelper.policyExpr != nil {
+			subplans = v.expr(name, "policy check", -1, n.checkHelper.policyExpr, subplans)
+		}
 		for i, rexpr := range n.rh.exprs {
 			subplans = v.expr(name, "returning", i, rexpr, subplans)
 		}
@@ -402,6 +405,11 @@ func (v *planVisitor) visit(plan planNode) {
 	case *traceNode:
 		v.visit(n.plan)
 
+	case *explainDebugNode:
+		if n.plan != nil {
+			v.visit(n.plan)
+		}
+
 	case *explainPlanNode:
 		if v.observer.attr != nil {
 			v.observer.attr(name, "expanded", strconv.FormatBool(n.expanded))
@@ -412,6 +420,10 @@ func (v *planVisitor) visit(plan planNode) {
 		subplans := v.expr(name, "queryID", -1, n.queryID, nil)
 		v.subqueries(name, subplans)
 
+	case *cancelSessionNode:
+		subplans := v.expr(name, "sessionID", -1, n.sessionID, nil)
+		v.subqueries(name, subplans)
+
 	case *controlJobNode:
 		subplans := v.expr(name, "jobID", -1, n.jobID, nil)
 		v.subqueries(name, subplans)
@@ -518,56 +530,63 @@ func nodeName(plan planNode) string {
 // strings are constant and not precomputed so that the type names can
 // be changed without changing the output of "EXPLAIN".
 var planNodeNames = map[reflect.Type]string{
-	reflect.TypeOf(&alterTableNode{}):           "alter table",
-	reflect.TypeOf(&alterSequenceNode{}):        "alter sequence",
-	reflect.TypeOf(&alterUserSetPasswordNode{}): "alter user",
-	reflect.TypeOf(&cancelQueryNode{}):          "cancel query",
-	reflect.TypeOf(&controlJobNode{}):           "control job",
-	reflect.TypeOf(&copyNode{}):                 "copy",
-	reflect.TypeOf(&createDatabaseNode{}):       "create database",
-	reflect.TypeOf(&createIndexNode{}):          "create index",
-	reflect.TypeOf(&createTableNode{}):          "create table",
-	reflect.TypeOf(&createUserNode{}):           "create user",
-	reflect.TypeOf(&createViewNode{}):           "create view",
-	reflect.TypeOf(&createSequenceNode{}):       "create sequence",
-	reflect.TypeOf(&delayedNode{}):              "virtual table",
-	reflect.TypeOf(&deleteNode{}):               "delete",
-	reflect.TypeOf(&distinctNode{}):             "distinct",
-	reflect.TypeOf(&dropDatabaseNode{}):         "drop database",
-	reflect.TypeOf(&dropIndexNode{}):            "drop index",
-	reflect.TypeOf(&dropTableNode{}):            "drop table",
-	reflect.TypeOf(&dropViewNode{}):             "drop view",
-	reflect.TypeOf(&dropSequenceNode{}):         "drop sequence",
-	reflect.TypeOf(&dropUserNode{}):             "drop user",
-	reflect.TypeOf(&explainDistSQLNode{}):       "explain dist_sql",
-	reflect.TypeOf(&explainPlanNode{}):          "explain plan",
-	reflect.TypeOf(&traceNode{}):                "show trace for",
-	reflect.TypeOf(&filterNode{}):               "filter",
-	reflect.TypeOf(&groupNode{}):                "group",
-	reflect.TypeOf(&unaryNode{}):                "emptyrow",
-	reflect.TypeOf(&hookFnNode{}):               "plugin",
-	reflect.TypeOf(&indexJoinNode{}):            "index-join",
-	reflect.TypeOf(&insertNode{}):               "insert",
-	reflect.TypeOf(&joinNode{}):                 "join",
-	reflect.TypeOf(&limitNode{}):                "limit",
-	reflect.TypeOf(&ordinalityNode{}):           "ordinality",
-	reflect.TypeOf(&testingRelocateNode{}):      "testingRelocate",
-	reflect.TypeOf(&renderNode{}):               "render",
-	reflect.TypeOf(&scanNode{}):                 "scan",
-	reflect.TypeOf(&scatterNode{}):              "scatter",
-	reflect.TypeOf(&scrubNode{}):                "scrub",
-	reflect.TypeOf(&setNode{}):                  "set",
-	reflect.TypeOf(&setClusterSettingNode{}):    "set cluster setting",
-	reflect.TypeOf(&setZoneConfigNode{}):        "configure zone",
-	reflect.TypeOf(&showZoneConfigNode{}):       "show zone configuration",
-	reflect.TypeOf(&showRangesNode{}):           "showRanges",
-	reflect.TypeOf(&showFingerprintsNode{}):     "showFingerprints",
-	reflect.TypeOf(&sortNode{}):                 "sort",
-	reflect.TypeOf(&splitNode{}):                "split",
-	reflect.TypeOf(&unionNode{}):                "union",
-	reflect.TypeOf(&updateNode{}):               "update",
-	reflect.TypeOf(&valueGenerator{}):           "generator",
-	reflect.TypeOf(&valuesNode{}):               "values",
-	reflect.TypeOf(&windowNode{}):               "window",
-	reflect.TypeOf(&zeroNode{}):                 "norows",
+	reflect.TypeOf(&alterTableNode{}):                  "alter table",
+	reflect.TypeOf(&alterSequenceNode{}):               "alter sequence",
+	reflect.TypeOf(&alterUserSetConnectionLimitNode{}): "alter user",
+	reflect.TypeOf(&alterUserSetPasswordNode{}):        "alter user",
+	reflect.TypeOf(&alterUserSetQuotaNode{}):           "alter user",
+	reflect.TypeOf(&alterUserSetStatementsNode{}):      "alter user",
+	reflect.TypeOf(&alterUserSetValidUntilNode{}):      "alter user",
+	reflect.TypeOf(&cancelQueryNode{}):                 "cancel query",
+	reflect.TypeOf(&cancelSessionNode{}):               "cancel session",
+	reflect.TypeOf(&controlJobNode{}):                  "control job",
+	reflect.TypeOf(&copyNode{}):                        "copy",
+	reflect.TypeOf(&createDatabaseNode{}):              "create database",
+	reflect.TypeOf(&createIndexNode{}):                 "create index",
+	reflect.TypeOf(&createTableNode{}):                 "create table",
+	reflect.TypeOf(&createUserNode{}):                  "create user",
+	reflect.TypeOf(&createViewNode{}):                  "create view",
+	reflect.TypeOf(&createSequenceNode{}):              "create sequence",
+	reflect.TypeOf(&delayedNode{}):                     "virtual table",
+	reflect.TypeOf(&deleteNode{}):                      "delete",
+	reflect.TypeOf(&distinctNode{}):                    "distinct",
+	reflect.TypeOf(&dropDatabaseNode{}):                "drop database",
+	reflect.TypeOf(&dropIndexNode{}):                   "drop index",
+	reflect.TypeOf(&dropTableNode{}):                   "drop table",
+	reflect.TypeOf(&dropViewNode{}):                    "drop view",
+	reflect.TypeOf(&dropSequenceNode{}):                "drop sequence",
+	reflect.TypeOf(&dropUserNode{}):                    "drop user",
+	reflect.TypeOf(&explainDebugNode{}):                "explain analyze",
+	reflect.TypeOf(&explainDistSQLNode{}):              "explain dist_sql",
+	reflect.TypeOf(&explainPlanNode{}):                 "explain plan",
+	reflect.TypeOf(&traceNode{}):                       "show trace for",
+	reflect.TypeOf(&fetchNode{}):                       "fetch",
+	reflect.TypeOf(&filterNode{}):                      "filter",
+	reflect.TypeOf(&groupNode{}):                       "group",
+	reflect.TypeOf(&unaryNode{}):                       "emptyrow",
+	reflect.TypeOf(&hookFnNode{}):                      "plugin",
+	reflect.TypeOf(&indexJoinNode{}):                   "index-join",
+	reflect.TypeOf(&insertNode{}):                      "insert",
+	reflect.TypeOf(&joinNode{}):                        "join",
+	reflect.TypeOf(&limitNode{}):                       "limit",
+	reflect.TypeOf(&ordinalityNode{}):                  "ordinality",
+	reflect.TypeOf(&testingRelocateNode{}):             "testingRelocate",
+	reflect.TypeOf(&renderNode{}):                      "render",
+	reflect.TypeOf(&scanNode{}):                        "scan",
+	reflect.TypeOf(&scatterNode{}):                     "scatter",
+	reflect.TypeOf(&scrubNode{}):                       "scrub",
+	reflect.TypeOf(&setNode{}):                         "set",
+	reflect.TypeOf(&setClusterSettingNode{}):           "set cluster setting",
+	reflect.TypeOf(&setZoneConfigNode{}):               "configure zone",
+	reflect.TypeOf(&showZoneConfigNode{}):              "show zone configuration",
+	reflect.TypeOf(&showRangesNode{}):                  "showRanges",
+	reflect.TypeOf(&showFingerprintsNode{}):            "showFingerprints",
+	reflect.TypeOf(&sortNode{}):                        "sort",
+	reflect.TypeOf(&splitNode{}):                       "split",
+	reflect.TypeOf(&unionNode{}):                       "union",
+	reflect.TypeOf(&updateNode{}):                      "update",
+	reflect.TypeOf(&valueGenerator{}):                  "generator",
+	reflect.TypeOf(&valuesNode{}):                      "values",
+	reflect.TypeOf(&windowNode{}):                      "window",
+	reflect.TypeOf(&zeroNode{}):                        "norows",
 }
@@ -16,6 +16,7 @@ package sql
 
 import (
 	"fmt"
+	"math"
 
 	"golang.org/x/net/context"
 	yaml "gopkg.in/yaml.v2"
@@ -153,40 +154,152 @@ func getZoneConfigRaw(
 	return zone, nil
 }
 
+// zoneConfigSetting describes a zone config field that can be set with
+// CONFIGURE ZONE USING.
+type zoneConfigSetting struct {
+	typ types.T
+	set func(*config.ZoneConfig, tree.Datum) error
+}
+
+// supportedZoneConfigSettings maps the names of the zone config fields that
+// can be set with CONFIGURE ZONE USING to their setter. The constraints and
+// lease preferences are given in the YAML notation of the zone configs, e.g.
+// '[+region=us, -ssd]' and '[[+region=us], [+region=eu]]'.
+var supportedZoneConfigSettings = map[string]zoneConfigSetting{
+	"range_min_bytes": {types.Int, func(c *config.ZoneConfig, d tree.Datum) error {
+		c.RangeMinBytes = int64(tree.MustBeDInt(d))
+		return nil
+	}},
+	"range_max_bytes": {types.Int, func(c *config.ZoneConfig, d tree.Datum) error {
+		c.RangeMaxBytes = int64(tree.MustBeDInt(d))
+		return nil
+	}},
+	"num_replicas": {types.Int, func(c *config.ZoneConfig, d tree.Datum) error {
+		n, err := zoneConfigInt32("num_replicas", d)
+		c.NumReplicas = n
+		return err
+	}},
+	"gc.ttlseconds": {types.Int, func(c *config.ZoneConfig, d tree.Datum) error {
+		n, err := zoneConfigInt32("gc.ttlseconds", d)
+		c.GC.TTLSeconds = n
+		return err
+	}},
+	"constraints": {types.String, func(c *config.ZoneConfig, d tree.Datum) error {
+		var constraints config.Constraints
+		if err := yaml.UnmarshalStrict([]byte(tree.MustBeDString(d)), &constraints); err != nil {
+			return fmt.Errorf("could not parse constraints: %s", err)
+		}
+		c.Constraints = constraints
+		return nil
+	}},
+	"lease_preferences": {types.String, func(c *config.ZoneConfig, d tree.Datum) error {
+		var preferences []config.LeasePreference
+		if err := yaml.UnmarshalStrict([]byte(tree.MustBeDString(d)), &preferences); err != nil {
+			return fmt.Errorf("could not parse lease preferences: %s", err)
+		}
+		c.LeasePreferences = preferences
+		return nil
+	}},
+}
+
+func zoneConfigInt32(key string, d tree.Datum) (int32, error) {
+	n := int64(tree.MustBeDInt(d))
+	if n < math.MinInt32 || n > math.MaxInt32 {
+		return 0, fmt.Errorf("%s is out of range: %d", key, n)
+	}
+	return int32(n), nil
+}
+
+type typedZoneConfigSetting struct {
+	key   string
+	value tree.TypedExpr
+}
+
 type setZoneConfigNode struct {
+	n             *tree.SetZoneConfig
 	zoneSpecifier tree.ZoneSpecifier
 	yamlConfig    tree.TypedExpr
+	settings      []typedZoneConfigSetting
+	discard       bool
 
 	numAffected int
 }
 
 func (p *planner) SetZoneConfig(ctx context.Context, n *tree.SetZoneConfig) (planNode, error) {
-	yamlConfig, err := p.analyzeExpr(
-		ctx, n.YAMLConfig, nil, tree.IndexedVarHelper{}, types.String, false, "configure zone")
-	if err != nil {
-		return nil, err
+	var yamlConfig tree.TypedExpr
+	if n.YAMLConfig != nil {
+		// EXPERIMENTAL CONFIGURE ZONE is superseded by CONFIGURE ZONE USING.
+		p.noteFeature("experimental_configure_zone")
+		var err error
+		yamlConfig, err = p.analyzeExpr(
+			ctx, n.YAMLConfig, nil, tree.IndexedVarHelper{}, types.String, false, "configure zone")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	settings := make([]typedZoneConfigSetting, 0, len(n.Settings))
+	for _, s := range n.Settings {
+		key := s.Key.String()
+		setting, ok := supportedZoneConfigSettings[key]
+		if !ok {
+			return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"unsupported zone config parameter: %q", key)
+		}
+		for _, other := range settings {
+			if other.key == key {
+				return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"duplicate zone config parameter: %q", key)
+			}
+		}
+		value, err := p.analyzeExpr(
+			ctx, s.Value, nil, tree.IndexedVarHelper{}, setting.typ, true, "configure zone")
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, typedZoneConfigSetting{key: key, value: value})
 	}
+
 	return &setZoneConfigNode{
+		n:             n,
 		zoneSpecifier: n.ZoneSpecifier,
 		yamlConfig:    yamlConfig,
+		settings:      settings,
+		discard:       n.Discard,
 	}, nil
 }
 
 func (n *setZoneConfigNode) Start(params runParams) error {
 	var yamlConfig *string
-	datum, err := n.yamlConfig.Eval(params.evalCtx)
-	if err != nil {
-		return err
+	deleteZone := n.discard
+	if n.yamlConfig != nil {
+		datum, err := n.yamlConfig.Eval(params.evalCtx)
+		if err != nil {
+			return err
+		}
+		switch val := datum.(type) {
+		case *tree.DString:
+			yamlConfig = (*string)(val)
+		case *tree.DBytes:
+			yamlConfig = (*string)(val)
+		default:
+			if datum != tree.DNull {
+				return fmt.Errorf("zone config must be of type string or bytes, not %T", val)
+			}
+			deleteZone = true
+		}
 	}
-	switch val := datum.(type) {
-	case *tree.DString:
-		yamlConfig = (*string)(val)
-	case *tree.DBytes:
-		yamlConfig = (*string)(val)
-	default:
-		if datum != tree.DNull {
-			return fmt.Errorf("zone config must be of type string or bytes, not %T", val)
+	values := make([]tree.Datum, len(n.settings))
+	for i, s := range n.settings {
+		datum, err := s.value.Eval(params.evalCtx)
+		if err != nil {
+			return err
+		}
+		if datum == tree.DNull {
+			return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"zone config parameter %s cannot be NULL", s.key)
 		}
+		values[i] = datum
 	}
 
 	if n.zoneSpecifier.TargetsIndex() {
@@ -204,7 +317,7 @@ func (n *setZoneConfigNode) Start(params runParams) error {
 		return pgerror.NewErrorf(pgerror.CodeCheckViolationError,
 			`cannot set zone configs for system config tables; `+
 				`try setting your config on the entire "system" database instead`)
-	} else if targetID == keys.RootNamespaceID && yamlConfig == nil {
+	} else if targetID == keys.RootNamespaceID && deleteZone {
 		return pgerror.NewErrorf(pgerror.CodeCheckViolationError,
 			"cannot remove default zone")
 	}
@@ -226,7 +339,7 @@ func (n *setZoneConfigNode) Start(params runParams) error {
 		return err
 	}
 
-	if yamlConfig == nil {
+	if deleteZone {
 		if index != nil {
 			didDelete := zone.DeleteSubzone(uint32(index.ID), partition)
 			if !didDelete {
@@ -242,8 +355,15 @@ func (n *setZoneConfigNode) Start(params runParams) error {
 		if subzone != nil {
 			newZone = subzone.Config
 		}
-		if err := yaml.UnmarshalStrict([]byte(*yamlConfig), &newZone); err != nil {
-			return fmt.Errorf("could not parse zone config: %s", err)
+		if yamlConfig != nil {
+			if err := yaml.UnmarshalStrict([]byte(*yamlConfig), &newZone); err != nil {
+				return fmt.Errorf("could not parse zone config: %s", err)
+			}
+		}
+		for i, s := range n.settings {
+			if err := supportedZoneConfigSettings[s.key].set(&newZone, values[i]); err != nil {
+				return err
+			}
 		}
 		if index == nil {
 			zone = newZone
@@ -255,6 +375,10 @@ func (n *setZoneConfigNode) Start(params runParams) error {
 			if err != nil {
 				return err
 			}
+			// Subzone configs never contain nested subzones, which newZone has
+			// inherited if it started off as the table zone config.
+			newZone.Subzones = nil
+			newZone.SubzoneSpans = nil
 			zone.SetSubzone(config.Subzone{
 				IndexID:       uint32(index.ID),
 				PartitionName: partition,
@@ -266,30 +390,58 @@ func (n *setZoneConfigNode) Start(params runParams) error {
 		}
 	}
 
+	n.numAffected, err = params.p.writeZoneConfig(params.ctx, targetID, table, zone)
+	if err != nil {
+		return err
+	}
+
+	// Record the zone config change in the event log. This is an auditable log
+	// event and is recorded in the same transaction as the system.zones
+	// update.
+	return MakeEventLogger(params.p.LeaseMgr()).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogSetZoneConfig,
+		int32(targetID),
+		int32(params.evalCtx.NodeID),
+		&sqlbase.SetZoneConfigEvent{
+			Target:    config.CLIZoneSpecifier(n.zoneSpecifier),
+			Statement: n.n.String(),
+			User:      params.p.session.User,
+		},
+	)
+}
+
+// writeZoneConfig writes the zone config with the given ID, after generating
+// the spans of its subzones from the table descriptor. A subzone placeholder
+// without subzones is deleted instead. It returns the number of rows of
+// system.zones affected.
+func (p *planner) writeZoneConfig(
+	ctx context.Context, targetID sqlbase.ID, table *sqlbase.TableDescriptor, zone config.ZoneConfig,
+) (int, error) {
 	if len(zone.Subzones) > 0 {
+		var err error
 		zone.SubzoneSpans, err = GenerateSubzoneSpans(table, zone.Subzones)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
 
-	internalExecutor := InternalExecutor{LeaseManager: params.p.LeaseMgr()}
+	internalExecutor := InternalExecutor{LeaseManager: p.LeaseMgr()}
 
 	if zone.IsSubzonePlaceholder() && len(zone.Subzones) == 0 {
-		n.numAffected, err = internalExecutor.ExecuteStatementInTransaction(
-			params.ctx, "set zone", params.p.txn,
+		return internalExecutor.ExecuteStatementInTransaction(
+			ctx, "set zone", p.txn,
 			"DELETE FROM system.zones WHERE id = $1", targetID)
-		return err
 	}
 
 	buf, err := protoutil.Marshal(&zone)
 	if err != nil {
-		return fmt.Errorf("could not marshal zone config: %s", err)
+		return 0, fmt.Errorf("could not marshal zone config: %s", err)
 	}
-	n.numAffected, err = internalExecutor.ExecuteStatementInTransaction(
-		params.ctx, "set zone", params.p.txn,
+	return internalExecutor.ExecuteStatementInTransaction(
+		ctx, "set zone", p.txn,
 		"UPSERT INTO system.zones (id, config) VALUES ($1, $2)", targetID, buf)
-	return err
 }
 
 func (n *setZoneConfigNode) Next(runParams) (bool, error) { return false, nil }
@@ -188,6 +188,129 @@ func TestValidSetShowZones(t *testing.T) {
 	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.t", tableRow)
 }
 
+func TestSetZoneConfigSettings(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, _ := tests.CreateTestServerParams()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE d; USE d; CREATE TABLE t ()`)
+
+	parseConstraints := func(short ...string) []config.Constraint {
+		constraints := make([]config.Constraint, len(short))
+		for i, c := range short {
+			if err := constraints[i].FromString(c); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return constraints
+	}
+
+	// The fields that are not set are inherited from the zone config that
+	// applied so far.
+	dbZone := config.DefaultZoneConfig()
+	dbZone.NumReplicas = 5
+	dbZone.Constraints = config.Constraints{Constraints: parseConstraints("+region=us", "-ssd")}
+	dbRow := sqlutils.ZoneRow{
+		ID:           keys.MaxReservedDescID + 1,
+		CLISpecifier: "d",
+		Config:       dbZone,
+	}
+	sqlDB.Exec(t, `ALTER DATABASE d CONFIGURE ZONE USING num_replicas = 5, constraints = '[+region=us, -ssd]'`)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "DATABASE d", dbRow)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.t", dbRow)
+
+	tableZone := dbZone
+	tableZone.GC.TTLSeconds = 3600
+	tableZone.LeasePreferences = []config.LeasePreference{
+		{Constraints: parseConstraints("+region=us-east")},
+		{Constraints: parseConstraints("+region=us-west")},
+	}
+	tableRow := sqlutils.ZoneRow{
+		ID:           keys.MaxReservedDescID + 2,
+		CLISpecifier: "d.t",
+		Config:       tableZone,
+	}
+	sqlDB.Exec(t, `ALTER TABLE t CONFIGURE ZONE USING
+		gc.ttlseconds = 3600, lease_preferences = '[[+region=us-east], [+region=us-west]]'`)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.t", tableRow)
+
+	sqlDB.Exec(t, `ALTER TABLE t CONFIGURE ZONE USING num_replicas = $1, range_max_bytes = $2`, 7, 1<<30)
+	tableRow.Config.NumReplicas = 7
+	tableRow.Config.RangeMaxBytes = 1 << 30
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.t", tableRow)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "DATABASE d", dbRow)
+
+	sqlDB.Exec(t, `ALTER TABLE t CONFIGURE ZONE DISCARD`)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.t", dbRow)
+	sqlDB.Exec(t, `ALTER DATABASE d CONFIGURE ZONE DISCARD`)
+	sqlutils.VerifyAllZoneConfigs(t, sqlDB, sqlutils.ZoneRow{
+		ID:           keys.RootNamespaceID,
+		CLISpecifier: ".default",
+		Config:       config.DefaultZoneConfig(),
+	})
+}
+
+func TestSetIndexZoneConfigSettings(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, _ := tests.CreateTestServerParams()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE d; USE d; CREATE TABLE t (k INT PRIMARY KEY, v INT, INDEX i (v))`)
+
+	required := func(key, value string) []config.Constraint {
+		return []config.Constraint{{Type: config.Constraint_REQUIRED, Key: key, Value: value}}
+	}
+
+	tableZone := config.DefaultZoneConfig()
+	tableZone.Constraints = config.Constraints{Constraints: required("region", "us")}
+	tableZone.LeasePreferences = []config.LeasePreference{{Constraints: required("region", "us")}}
+	tableRow := sqlutils.ZoneRow{
+		ID:           keys.MaxReservedDescID + 2,
+		CLISpecifier: "d.t",
+		Config:       tableZone,
+	}
+	sqlDB.Exec(t, `ALTER TABLE t CONFIGURE ZONE USING
+		constraints = '[+region=us]', lease_preferences = '[[+region=us]]'`)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.t", tableRow)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "INDEX d.t@i", tableRow)
+
+	// The secondary index can move its leases, and its replicas, independently
+	// of the table, inheriting the fields that are not set.
+	iZone := tableZone
+	iZone.LeasePreferences = []config.LeasePreference{
+		{Constraints: required("region", "eu")},
+		{Constraints: required("region", "us")},
+	}
+	iRow := sqlutils.ZoneRow{
+		ID:           keys.MaxReservedDescID + 2,
+		CLISpecifier: "d.t@i",
+		Config:       iZone,
+	}
+	sqlDB.Exec(t, `ALTER INDEX t@i CONFIGURE ZONE USING lease_preferences = '[[+region=eu], [+region=us]]'`)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "INDEX d.t@i", iRow)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.t", tableRow)
+
+	iRow.Config.Constraints = config.Constraints{Constraints: required("region", "eu")}
+	sqlDB.Exec(t, `ALTER INDEX t@i CONFIGURE ZONE USING constraints = '[+region=eu]'`)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "INDEX d.t@i", iRow)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "TABLE d.t", tableRow)
+
+	sqlDB.Exec(t, `ALTER INDEX t@i CONFIGURE ZONE DISCARD`)
+	sqlutils.VerifyZoneConfigForTarget(t, sqlDB, "INDEX d.t@i", tableRow)
+	sqlDB.Exec(t, `ALTER TABLE t CONFIGURE ZONE DISCARD`)
+	sqlutils.VerifyAllZoneConfigs(t, sqlDB, sqlutils.ZoneRow{
+		ID:           keys.RootNamespaceID,
+		CLISpecifier: ".default",
+		Config:       config.DefaultZoneConfig(),
+	})
+}
+
 func TestInvalidSetShowZones(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -223,6 +346,46 @@ func TestInvalidSetShowZones(t *testing.T) {
 			"ALTER TABLE foo EXPERIMENTAL CONFIGURE ZONE ''",
 			"relation \"foo\" does not exist",
 		},
+		{
+			"ALTER RANGE default CONFIGURE ZONE DISCARD",
+			"cannot remove default zone",
+		},
+		{
+			"ALTER RANGE default CONFIGURE ZONE USING foo = 1",
+			`unsupported zone config parameter: "foo"`,
+		},
+		{
+			"ALTER RANGE default CONFIGURE ZONE USING num_replicas = 3, num_replicas = 5",
+			`duplicate zone config parameter: "num_replicas"`,
+		},
+		{
+			"ALTER RANGE default CONFIGURE ZONE USING num_replicas = NULL",
+			"zone config parameter num_replicas cannot be NULL",
+		},
+		{
+			"ALTER RANGE default CONFIGURE ZONE USING num_replicas = 2",
+			"at least 3 replicas are required",
+		},
+		{
+			"ALTER RANGE default CONFIGURE ZONE USING gc.ttlseconds = 10000000000",
+			"gc.ttlseconds is out of range",
+		},
+		{
+			"ALTER RANGE default CONFIGURE ZONE USING constraints = 'ssd'",
+			"could not parse constraints",
+		},
+		{
+			"ALTER RANGE default CONFIGURE ZONE USING lease_preferences = '[[ssd]]'",
+			`lease preference constraints must be required \(\+\) or prohibited \(-\)`,
+		},
+		{
+			"ALTER TABLE foo CONFIGURE ZONE USING num_replicas = 3",
+			"relation \"foo\" does not exist",
+		},
+		{
+			"SHOW ZONE CONFIGURATION FOR TABLE foo",
+			"relation \"foo\" does not exist",
+		},
 		{
 			"EXPERIMENTAL SHOW ZONE CONFIGURATION FOR RANGE foo",
 			"\"foo\" is not a built-in zone",
@@ -15,7 +15,6 @@
 package sqlmigrations
 
 import (
-	"fmt"
 	"time"
 
 	"golang.org/x/net/context"
@@ -95,6 +94,40 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		newDescriptors: 1,
 		newRanges:      1,
 	},
+	{
+		name:           "create system.role_options table",
+		workFn:         createRoleOptionsTable,
+		newDescriptors: 1,
+		newRanges:      1,
+	},
+	{
+		name:           "create system.role_members table",
+		workFn:         createRoleMembersTable,
+		newDescriptors: 1,
+		newRanges:      1,
+	},
+	{
+		name:   "grant CONNECT and USAGE on existing databases to public",
+		workFn: grantPublicDatabasePrivileges,
+	},
+	{
+		name:           "create system.scheduled_jobs table",
+		workFn:         createScheduledJobsTable,
+		newDescriptors: 1,
+		newRanges:      1,
+	},
+	{
+		name:           "create system.statement_bundles table",
+		workFn:         createStatementBundlesTable,
+		newDescriptors: 1,
+		newRanges:      1,
+	},
+	{
+		name:           "create system.statement_diagnostics_requests table",
+		workFn:         createStatementDiagnosticsRequestsTable,
+		newDescriptors: 1,
+		newRanges:      1,
+	},
 }
 
 // migrationDescriptor describes a single migration hook that's used to modify
@@ -376,6 +409,26 @@ func createTableStatisticsTable(ctx context.Context, r runner) error {
 	return createSystemTable(ctx, r, sqlbase.TableStatisticsTable)
 }
 
+func createRoleOptionsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.RoleOptionsTable)
+}
+
+func createRoleMembersTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.RoleMembersTable)
+}
+
+func createScheduledJobsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.ScheduledJobsTable)
+}
+
+func createStatementBundlesTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.StatementBundlesTable)
+}
+
+func createStatementDiagnosticsRequestsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.StatementDiagnosticsRequestsTable)
+}
+
 func createSystemTable(ctx context.Context, r runner, desc sqlbase.TableDescriptor) error {
 	// We install the table at the KV layer so that we can choose a known ID in
 	// the reserved ID space. (The SQL layer doesn't allow this.)
@@ -462,17 +515,19 @@ func populateVersionSetting(ctx context.Context, r runner) error {
 	// (overwriting also seems reasonable, but what for).
 	// We don't allow users to perform version changes until we have run
 	// the insert below.
+	pl := tree.MakePlaceholderInfo()
+	pl.SetValue("1", tree.NewDString(string(b)))
 	if res, err := r.sqlExecutor.ExecuteStatementsBuffered(
 		session,
-		fmt.Sprintf(`INSERT INTO system.settings (name, value, "lastUpdated", "valueType") VALUES ('version', x'%x', NOW(), 'm') ON CONFLICT(name) DO NOTHING`, b),
-		nil, 1,
+		`INSERT INTO system.settings (name, value, "lastUpdated", "valueType") VALUES ('version', $1, NOW(), 'm') ON CONFLICT(name) DO NOTHING`,
+		&pl, 1,
 	); err == nil {
 		res.Close(ctx)
 	} else if err != nil {
 		return err
 	}
 
-	pl := tree.MakePlaceholderInfo()
+	pl = tree.MakePlaceholderInfo()
 	pl.SetValue("1", tree.NewDString(v.String()))
 	if res, err := r.sqlExecutor.ExecuteStatementsBuffered(
 		session, "SET CLUSTER SETTING version = $1", &pl, 1); err == nil {
@@ -483,6 +538,14 @@ func populateVersionSetting(ctx context.Context, r runner) error {
 	return nil
 }
 
+// grantPublicDatabasePrivileges grants the privileges that every user has
+// on new databases on the databases created before they were checked.
+func grantPublicDatabasePrivileges(ctx context.Context, r runner) error {
+	return r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		return sql.GrantDefaultPublicDatabasePrivileges(ctx, txn)
+	})
+}
+
 // repopulateViewDeps recomputes the dependencies of all views, as
 // they might not have been computed properly previously.
 // (#17269 #17306)
@@ -679,6 +679,40 @@ func (a *Allocator) TransferLeaseTarget(
 	return candidates[a.randGen.Intn(len(candidates))]
 }
 
+// preferredLeaseholders returns the replicas from the provided list whose
+// stores satisfy the first of the lease preferences that any of them
+// satisfies. It returns nil if no replica satisfies any of the preferences.
+func (a *Allocator) preferredLeaseholders(
+	leasePreferences []config.LeasePreference, existing []roachpb.ReplicaDescriptor,
+) []roachpb.ReplicaDescriptor {
+	for _, preference := range leasePreferences {
+		var preferred []roachpb.ReplicaDescriptor
+		for _, repl := range existing {
+			storeDesc, ok := a.storePool.getStoreDescriptor(repl.StoreID)
+			if !ok {
+				continue
+			}
+			if ok, _ := constraintCheck(storeDesc, config.Constraints{Constraints: preference.Constraints}); ok {
+				preferred = append(preferred, repl)
+			}
+		}
+		if len(preferred) > 0 {
+			return preferred
+		}
+	}
+	return nil
+}
+
+// containsStore returns whether one of the replicas is on the given store.
+func containsStore(replicas []roachpb.ReplicaDescriptor, storeID roachpb.StoreID) bool {
+	for _, repl := range replicas {
+		if repl.StoreID == storeID {
+			return true
+		}
+	}
+	return false
+}
+
 // ShouldTransferLease returns true if the specified store is overfull in terms
 // of leases with respect to the other stores matching the specified
 // attributes.
@@ -1232,6 +1232,73 @@ func TestAllocatorTransferLeaseTargetMultiStore(t *testing.T) {
 	}
 }
 
+func TestAllocatorPreferredLeaseholders(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper, g, _, a, _ := createTestAllocator( /* deterministic */ true)
+	defer stopper.Stop(context.Background())
+
+	// Stores 1 and 2 are in region us, store 3 in region eu. Store 2 has an ssd.
+	regions := []string{"us", "us", "eu"}
+	var stores []*roachpb.StoreDescriptor
+	for i, region := range regions {
+		stores = append(stores, &roachpb.StoreDescriptor{
+			StoreID: roachpb.StoreID(i + 1),
+			Node: roachpb.NodeDescriptor{
+				NodeID: roachpb.NodeID(i + 1),
+				Locality: roachpb.Locality{
+					Tiers: []roachpb.Tier{{Key: "region", Value: region}},
+				},
+			},
+		})
+	}
+	stores[1].Attrs = roachpb.Attributes{Attrs: []string{"ssd"}}
+	sg := gossiputil.NewStoreGossiper(g)
+	sg.GossipStores(stores, t)
+
+	existing := []roachpb.ReplicaDescriptor{
+		{StoreID: 1},
+		{StoreID: 2},
+		{StoreID: 3},
+	}
+	preference := func(constraints ...string) config.LeasePreference {
+		var lp config.LeasePreference
+		for _, short := range constraints {
+			var c config.Constraint
+			if err := c.FromString(short); err != nil {
+				t.Fatal(err)
+			}
+			lp.Constraints = append(lp.Constraints, c)
+		}
+		return lp
+	}
+
+	testCases := []struct {
+		preferences []config.LeasePreference
+		existing    []roachpb.ReplicaDescriptor
+		expected    []roachpb.StoreID
+	}{
+		{nil, existing, nil},
+		{[]config.LeasePreference{preference("+region=eu")}, existing, []roachpb.StoreID{3}},
+		{[]config.LeasePreference{preference("+region=us")}, existing, []roachpb.StoreID{1, 2}},
+		{[]config.LeasePreference{preference("+region=us", "-ssd")}, existing, []roachpb.StoreID{1}},
+		{[]config.LeasePreference{preference("+region=asia")}, existing, nil},
+		// The first preference satisfied by a replica wins.
+		{[]config.LeasePreference{preference("+region=asia"), preference("+ssd"), preference("+region=eu")},
+			existing, []roachpb.StoreID{2}},
+		{[]config.LeasePreference{preference("+region=eu"), preference("+region=us")},
+			existing[:2], []roachpb.StoreID{1, 2}},
+	}
+	for i, c := range testCases {
+		var storeIDs []roachpb.StoreID
+		for _, repl := range a.preferredLeaseholders(c.preferences, c.existing) {
+			storeIDs = append(storeIDs, repl.StoreID)
+		}
+		if !reflect.DeepEqual(storeIDs, c.expected) {
+			t.Errorf("%d: expected %v, got %v", i, c.expected, storeIDs)
+		}
+	}
+}
+
 func TestAllocatorShouldTransferLease(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	stopper, g, _, a, _ := createTestAllocator( /* deterministic */ true)
@@ -47,6 +47,7 @@ type EvalContext interface {
 	GetTxnWaitQueue() *txnwait.Queue
 
 	NodeID() roachpb.NodeID
+	GetNodeLocality() roachpb.Locality
 	StoreID() roachpb.StoreID
 	GetRangeID() roachpb.RangeID
 
@@ -0,0 +1,192 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package bulk ingests large amounts of data by building SSTables and
+// linking them directly into the storage engine of the replicas with
+// AddSSTable requests, rather than by proposing a Raft command per key. It is
+// used by IMPORT and RESTORE, through the Import command, and by index
+// backfills.
+package bulk
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// SSTBatcher builds an SSTable from key/value entries and ingests it with
+// AddSSTable. The entries must be added in increasing key order, as required
+// by the SSTable writer. The SSTable may span several ranges: it is split at
+// the range boundaries when it is sent.
+type SSTBatcher struct {
+	db *client.DB
+	st *cluster.Settings
+
+	sstWriter     engine.RocksDBSstFileWriter
+	batchStartKey []byte
+	batchEndKey   []byte
+}
+
+// MakeSSTBatcher returns a new SSTBatcher. It must be closed by the caller.
+func MakeSSTBatcher(db *client.DB, st *cluster.Settings) (*SSTBatcher, error) {
+	sstWriter, err := engine.MakeRocksDBSstFileWriter()
+	if err != nil {
+		return nil, errors.Wrap(err, "making sstBatcher")
+	}
+	return &SSTBatcher{db: db, st: st, sstWriter: sstWriter}, nil
+}
+
+// Add adds an entry to the batch.
+func (b *SSTBatcher) Add(key engine.MVCCKey, value []byte) error {
+	// Update the range currently represented in this batch, as
+	// necessary.
+	if len(b.batchStartKey) == 0 || bytes.Compare(key.Key, b.batchStartKey) < 0 {
+		b.batchStartKey = append(b.batchStartKey[:0], key.Key...)
+	}
+	if len(b.batchEndKey) == 0 || bytes.Compare(key.Key, b.batchEndKey) > 0 {
+		b.batchEndKey = append(b.batchEndKey[:0], key.Key...)
+	}
+	return b.sstWriter.Add(engine.MVCCKeyValue{Key: key, Value: value})
+}
+
+// Size returns the size of the data added to the batch.
+func (b *SSTBatcher) Size() int64 {
+	return b.sstWriter.DataSize
+}
+
+// Finish ingests the batch. The batcher cannot be added to afterwards.
+func (b *SSTBatcher) Finish(ctx context.Context) error {
+	if len(b.batchStartKey) == 0 {
+		return nil
+	}
+	start := roachpb.Key(b.batchStartKey)
+	// The end key of the AddSSTable request is exclusive, but batchEndKey is
+	// currently the largest key in the batch. Increment it.
+	end := roachpb.Key(b.batchEndKey).Next()
+	sstBytes, err := b.sstWriter.Finish()
+	if err != nil {
+		return errors.Wrapf(err, "finishing constructed sstable")
+	}
+	return AddSSTable(ctx, b.db, b.st, start, end, sstBytes)
+}
+
+// Close releases the resources of the batcher.
+func (b *SSTBatcher) Close() {
+	b.sstWriter.Close()
+}
+
+// maxAddSSTableRetries is the number of times an AddSSTable request is
+// retried when its result is ambiguous or when the ranges it addresses have
+// changed.
+const maxAddSSTableRetries = 10
+
+// AddSSTable ingests the given SSTable, whose keys are in [start, end). An
+// AddSSTable request must address a single range, so the SSTable is split at
+// the boundaries of the ranges in the span first. A request that fails after
+// the ranges have split again is retried the same way.
+func AddSSTable(
+	ctx context.Context, db *client.DB, st *cluster.Settings, start, end roachpb.Key, sstBytes []byte,
+) error {
+	var err error
+	for i := 0; i <= maxAddSSTableRetries; i++ {
+		var rangeEnd roachpb.Key
+		rangeEnd, err = lookupRangeEnd(ctx, db, st, start)
+		if err != nil {
+			return err
+		}
+		if bytes.Compare(end, rangeEnd) > 0 {
+			log.VEventf(ctx, 2, "splitting sstable [%s,%s) at %s", start, end, rangeEnd)
+			return splitAndAddSSTable(ctx, db, st, start, end, rangeEnd, sstBytes)
+		}
+
+		log.VEventf(ctx, 2, "sending AddSSTable [%s,%s)", start, end)
+		err = db.AddSSTable(ctx, start, end, sstBytes)
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(*roachpb.AmbiguousResultError); !ok {
+			// The range may have split since it was looked up, in which case the
+			// request fails verifying the keys of the SSTable against the span of
+			// the range it was truncated to. Look the range up again.
+			if newEnd, lookupErr := lookupRangeEnd(ctx, db, st, start); lookupErr != nil ||
+				!newEnd.Equal(rangeEnd) {
+				continue
+			}
+			return errors.Wrapf(err, "addsstable [%s,%s)", start, end)
+		}
+		log.Warningf(ctx, "addsstable [%s,%s) attempt %d failed: %+v", start, end, i, err)
+	}
+	return errors.Wrapf(err, "addsstable [%s,%s)", start, end)
+}
+
+// lookupRangeEnd returns the end key of the range containing key.
+func lookupRangeEnd(
+	ctx context.Context, db *client.DB, st *cluster.Settings, key roachpb.Key,
+) (roachpb.Key, error) {
+	descs, _, err := client.RangeLookupForVersion(
+		ctx, st, db.GetSender(), key,
+		roachpb.CONSISTENT, 0 /* prefetchNum */, false, /* prefetchReverse */
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(descs) == 0 {
+		return nil, errors.Errorf("no range contains key %s", key)
+	}
+	return descs[0].EndKey.AsRawKey(), nil
+}
+
+// splitAndAddSSTable splits the given SSTable, whose keys are in [start,
+// end), at splitKey and ingests both parts.
+func splitAndAddSSTable(
+	ctx context.Context,
+	db *client.DB,
+	st *cluster.Settings,
+	start, end, splitKey roachpb.Key,
+	sstBytes []byte,
+) error {
+	reader := engine.MakeRocksDBSstFileReader()
+	defer reader.Close()
+	if err := reader.IngestExternalFile(sstBytes); err != nil {
+		return err
+	}
+
+	for _, span := range []roachpb.Span{{Key: start, EndKey: splitKey}, {Key: splitKey, EndKey: end}} {
+		b, err := MakeSSTBatcher(db, st)
+		if err != nil {
+			return err
+		}
+		err = reader.Iterate(
+			engine.MVCCKey{Key: span.Key}, engine.MVCCKey{Key: span.EndKey},
+			func(kv engine.MVCCKeyValue) (bool, error) {
+				return false, b.Add(kv.Key, kv.Value)
+			},
+		)
+		if err == nil {
+			err = b.Finish(ctx)
+		}
+		b.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
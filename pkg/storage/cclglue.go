@@ -39,6 +39,7 @@ func makeUnimplementedCommand(method roachpb.Method) Command {
 
 var writeBatchCmd = makeUnimplementedCommand(roachpb.WriteBatch)
 var addSSTableCmd = makeUnimplementedCommand(roachpb.AddSSTable)
+var addSSTableImplemented bool
 var exportCmd = makeUnimplementedCommand(roachpb.Export)
 var importCmdFn ImportCmdFunc = func(context.Context, batcheval.CommandArgs) (*roachpb.ImportResponse, error) {
 	return &roachpb.ImportResponse{}, errors.Errorf("unimplemented command: %s", roachpb.Import)
@@ -56,6 +57,13 @@ func SetWriteBatchCmd(cmd Command) {
 func SetAddSSTableCmd(cmd Command) {
 	// This is safe if SetAddSSTableCmd is only called at init time.
 	commands[roachpb.AddSSTable] = cmd
+	addSSTableImplemented = true
+}
+
+// AddSSTableImplemented returns whether SetAddSSTableCmd was called, that is,
+// whether this node can evaluate AddSSTable commands.
+func AddSSTableImplemented() bool {
+	return addSSTableImplemented
 }
 
 // SetExportCmd allows setting the function that will be called as the
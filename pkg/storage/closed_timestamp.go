@@ -0,0 +1,161 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// The lease holder of a range closes timestamps: it forwards the writes it
+// evaluates above the closed timestamp, and attaches the closed timestamp to
+// the commands it proposes. Once a replica has applied a command, it has
+// applied all the writes at or below its closed timestamp, since they were
+// all proposed before it (the commands applied out of order are rejected and
+// evaluated again), and it can serve the reads at or below it without the
+// lease. The lease holders of the following leases write above their lease
+// start, hence above the closed timestamps of the previous leases.
+//
+// A range's replicas only learn of the closed timestamps through the commands
+// proposed by the lease holder, so the reads of the ranges without recent
+// writes are still served by the lease holder.
+
+// closedTimestampTracker tracks the writes being evaluated by the lease
+// holder, to close timestamps they are all above. The writes evaluated in the
+// current epoch are forwarded above next, and those evaluated in the previous
+// epoch above a timestamp at or below next. Once all the writes of the
+// previous epoch are proposed, next is closed and a new epoch starts.
+type closedTimestampTracker struct {
+	// closed is the timestamp below which no write is evaluated anymore, and
+	// at or below which all the writes are proposed.
+	closed hlc.Timestamp
+	// next is the timestamp the writes of the current epoch are forwarded
+	// above.
+	next hlc.Timestamp
+	// epoch is the current epoch, and refs and prevRefs the number of writes
+	// of the current and of the previous epoch being evaluated.
+	epoch          int64
+	refs, prevRefs int
+}
+
+// track registers a write about to be evaluated. It returns the timestamp
+// the write must be forwarded above, and the epoch with which to release it
+// once it is proposed or fails.
+func (t *closedTimestampTracker) track() (hlc.Timestamp, int64) {
+	t.refs++
+	return t.next, t.epoch
+}
+
+// release unregisters a write registered in the given epoch.
+func (t *closedTimestampTracker) release(epoch int64) {
+	switch epoch {
+	case t.epoch:
+		t.refs--
+	case t.epoch - 1:
+		t.prevRefs--
+	default:
+		panic("released a write of an epoch which was already closed")
+	}
+}
+
+// close closes the timestamps it can, starting a new epoch whose writes are
+// forwarded above target, and returns the closed timestamp.
+func (t *closedTimestampTracker) close(target hlc.Timestamp) hlc.Timestamp {
+	if t.prevRefs == 0 {
+		t.closed.Forward(t.next)
+		t.next.Forward(target)
+		t.prevRefs, t.refs = t.refs, 0
+		t.epoch++
+	}
+	return t.closed
+}
+
+// trackWriteForClosedTimestamp forwards the timestamp of a write about to be
+// evaluated above the closed timestamps, returning whether it did. The
+// returned function must be called once the write is proposed or fails.
+func (r *Replica) trackWriteForClosedTimestamp(ba *roachpb.BatchRequest) (func(), bool) {
+	r.mu.Lock()
+	minTS, epoch := r.mu.closedTimestampTracker.track()
+	r.mu.Unlock()
+
+	var bumped bool
+	if minTS != (hlc.Timestamp{}) {
+		nextTS := minTS.Next()
+		if ba.Txn != nil {
+			if ba.Txn.Timestamp.Less(nextTS) {
+				txn := ba.Txn.Clone()
+				bumped = txn.Timestamp.Forward(nextTS)
+				ba.Txn = &txn
+			}
+		} else {
+			bumped = ba.Timestamp.Forward(nextTS)
+		}
+	}
+
+	var released bool
+	return func() {
+		if released {
+			return
+		}
+		released = true
+		r.mu.Lock()
+		r.mu.closedTimestampTracker.release(epoch)
+		r.mu.Unlock()
+	}, bumped
+}
+
+// closeTimestampLocked returns the closed timestamp to attach to a command
+// about to be proposed.
+func (r *Replica) closeTimestampLocked() hlc.Timestamp {
+	target := storagebase.ClosedTimestampTargetDuration.Get(&r.store.cfg.Settings.SV)
+	now := r.store.Clock().Now()
+	if target == 0 || now.WallTime <= target.Nanoseconds() {
+		return r.mu.closedTimestampTracker.closed
+	}
+	return r.mu.closedTimestampTracker.close(hlc.Timestamp{WallTime: now.WallTime - target.Nanoseconds()})
+}
+
+// canServeFollowerRead returns whether a replica which doesn't hold the lease
+// can serve a read-only batch, since its timestamp, and the end of its
+// uncertainty interval, are at or below the closed timestamp of the commands
+// applied by the replica.
+func (r *Replica) canServeFollowerRead(
+	ctx context.Context, ba *roachpb.BatchRequest, pErr *roachpb.Error,
+) bool {
+	if _, ok := pErr.GetDetail().(*roachpb.NotLeaseHolderError); !ok {
+		return false
+	}
+	if !storagebase.FollowerReadsEnabled.Get(&r.store.cfg.Settings.SV) {
+		return false
+	}
+	ts := ba.Timestamp
+	if ba.Txn != nil {
+		ts.Forward(ba.Txn.MaxTimestamp)
+	}
+	r.mu.RLock()
+	closed := r.mu.closedTimestamp
+	r.mu.RUnlock()
+	if closed.Less(ts) {
+		log.VEventf(ctx, 2, "can't serve follower read at %s: closed timestamp %s", ts, closed)
+		return false
+	}
+	log.Event(ctx, "serving follower read")
+	r.store.metrics.FollowerReadsCount.Inc(1)
+	return true
+}
@@ -0,0 +1,68 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestClosedTimestampTracker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ts := func(walltime int64) hlc.Timestamp {
+		return hlc.Timestamp{WallTime: walltime}
+	}
+	var tr closedTimestampTracker
+
+	// Nothing is closed until a first epoch went by.
+	if closed := tr.close(ts(10)); closed != ts(0) {
+		t.Fatalf("expected nothing to be closed, got %s", closed)
+	}
+	minTS1, epoch1 := tr.track()
+	if minTS1 != ts(10) {
+		t.Fatalf("expected the write to be forwarded above 10, got %s", minTS1)
+	}
+	if closed := tr.close(ts(20)); closed != ts(10) {
+		t.Fatalf("expected 10 to be closed, got %s", closed)
+	}
+
+	// The write of the previous epoch prevents closing the next timestamp.
+	minTS2, epoch2 := tr.track()
+	if minTS2 != ts(20) {
+		t.Fatalf("expected the write to be forwarded above 20, got %s", minTS2)
+	}
+	if closed := tr.close(ts(30)); closed != ts(10) {
+		t.Fatalf("expected 10 to stay closed, got %s", closed)
+	}
+	tr.release(epoch1)
+	if closed := tr.close(ts(30)); closed != ts(20) {
+		t.Fatalf("expected 20 to be closed, got %s", closed)
+	}
+
+	// The closed timestamp doesn't regress when the target does.
+	tr.release(epoch2)
+	if closed := tr.close(ts(25)); closed != ts(30) {
+		t.Fatalf("expected 30 to be closed, got %s", closed)
+	}
+	if closed := tr.close(ts(25)); closed != ts(30) {
+		t.Fatalf("expected 30 to stay closed, got %s", closed)
+	}
+	if minTS, _ := tr.track(); minTS != ts(30) {
+		t.Fatalf("expected the write to be forwarded above 30, got %s", minTS)
+	}
+}
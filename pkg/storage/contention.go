@@ -0,0 +1,166 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// maxContentionEvents is the number of most recent contention events kept
+// by a ContentionRegistry.
+const maxContentionEvents = 1000
+
+// ContentionEvent describes a request which had to wait for a conflicting
+// transaction, either because it ran into one of its intents, or because
+// its push of the transaction was queued behind it.
+type ContentionEvent struct {
+	// Key is the key of the conflicting intent, or the key of the blocking
+	// transaction record for a deadlock.
+	Key roachpb.Key
+	// WaitingTxnID is the ID of the transaction of the waiting request. It is
+	// nil for a non-transactional request.
+	WaitingTxnID uuid.UUID
+	// BlockingTxnID is the ID of the conflicting transaction.
+	BlockingTxnID uuid.UUID
+	// Start is when the request started waiting.
+	Start time.Time
+	// Duration is how long the request waited.
+	Duration time.Duration
+	// Deadlock is set if the wait ended because a deadlock between the two
+	// transactions was detected and broken.
+	Deadlock bool
+}
+
+// ContentionIndexStats are the cumulative contention events on the keys of
+// an index of a SQL table.
+type ContentionIndexStats struct {
+	TableID uint64
+	IndexID uint64
+	// NumEvents and TotalDuration cover the waits on intents.
+	NumEvents     int64
+	TotalDuration time.Duration
+	// NumDeadlocks counts the deadlocks broken on the keys of the index.
+	NumDeadlocks int64
+}
+
+// ContentionRegistry keeps the contention events recorded by the stores of
+// a node: the most recent events, and the cumulative events on each SQL
+// index, which are kept until the node restarts. A nil registry ignores the
+// events.
+type ContentionRegistry struct {
+	mu struct {
+		syncutil.Mutex
+		// events is a ring buffer of the most recent events; next is the
+		// position of the next event in it once it is full.
+		events []ContentionEvent
+		next   int
+		// indexes maps the table and index IDs to the cumulative events.
+		indexes map[[2]uint64]*ContentionIndexStats
+	}
+}
+
+// NewContentionRegistry creates a ContentionRegistry.
+func NewContentionRegistry() *ContentionRegistry {
+	r := &ContentionRegistry{}
+	r.mu.indexes = make(map[[2]uint64]*ContentionIndexStats)
+	return r
+}
+
+// Record adds a contention event to the registry.
+func (r *ContentionRegistry) Record(ev ContentionEvent) {
+	if r == nil {
+		return
+	}
+	tableID, indexID, isIndexKey := decodeContentionIndex(ev.Key)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.mu.events) < maxContentionEvents {
+		r.mu.events = append(r.mu.events, ev)
+	} else {
+		r.mu.events[r.mu.next] = ev
+		r.mu.next = (r.mu.next + 1) % maxContentionEvents
+	}
+	if !isIndexKey {
+		return
+	}
+	s, ok := r.mu.indexes[[2]uint64{tableID, indexID}]
+	if !ok {
+		s = &ContentionIndexStats{TableID: tableID, IndexID: indexID}
+		r.mu.indexes[[2]uint64{tableID, indexID}] = s
+	}
+	if ev.Deadlock {
+		s.NumDeadlocks++
+	} else {
+		s.NumEvents++
+		s.TotalDuration += ev.Duration
+	}
+}
+
+// Events returns the most recent contention events, oldest first.
+func (r *ContentionRegistry) Events() []ContentionEvent {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make([]ContentionEvent, 0, len(r.mu.events))
+	res = append(res, r.mu.events[r.mu.next:]...)
+	return append(res, r.mu.events[:r.mu.next]...)
+}
+
+// IndexStats returns the cumulative contention events on the SQL indexes,
+// sorted by table and index ID.
+func (r *ContentionRegistry) IndexStats() []ContentionIndexStats {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	res := make([]ContentionIndexStats, 0, len(r.mu.indexes))
+	for _, s := range r.mu.indexes {
+		res = append(res, *s)
+	}
+	r.mu.Unlock()
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].TableID != res[j].TableID {
+			return res[i].TableID < res[j].TableID
+		}
+		return res[i].IndexID < res[j].IndexID
+	})
+	return res
+}
+
+// decodeContentionIndex returns the table and index IDs of a key of a SQL
+// index.
+func decodeContentionIndex(key roachpb.Key) (tableID, indexID uint64, ok bool) {
+	if key.Compare(keys.TableDataMin) < 0 || key.Compare(keys.TableDataMax) >= 0 {
+		return 0, 0, false
+	}
+	rest, tableID, err := keys.DecodeTablePrefix(key)
+	if err != nil {
+		return 0, 0, false
+	}
+	if _, indexID, err = encoding.DecodeUvarintAscending(rest); err != nil {
+		return 0, 0, false
+	}
+	return tableID, indexID, true
+}
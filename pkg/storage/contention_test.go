@@ -0,0 +1,74 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestContentionRegistry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	indexKey := func(tableID uint32, indexID uint64, row int64) roachpb.Key {
+		k := encoding.EncodeUvarintAscending(keys.MakeTablePrefix(tableID), indexID)
+		return roachpb.Key(encoding.EncodeVarintAscending(k, row))
+	}
+
+	r := NewContentionRegistry()
+	for i := 0; i < maxContentionEvents+10; i++ {
+		r.Record(ContentionEvent{
+			Key:      indexKey(51, uint64(1+i%2), int64(i)),
+			Duration: time.Second,
+		})
+	}
+	r.Record(ContentionEvent{Key: indexKey(52, 1, 0), Deadlock: true})
+	r.Record(ContentionEvent{Key: roachpb.Key("a"), Duration: time.Second})
+
+	// Only the most recent events are kept, oldest first.
+	events := r.Events()
+	if e, a := maxContentionEvents, len(events); e != a {
+		t.Fatalf("expected %d events, got %d", e, a)
+	}
+	if e, a := indexKey(51, 1, 12), events[0].Key; !e.Equal(a) {
+		t.Errorf("expected the oldest event on %s, got %s", e, a)
+	}
+	if e, a := roachpb.Key("a"), events[len(events)-1].Key; !e.Equal(a) {
+		t.Errorf("expected the newest event on %s, got %s", e, a)
+	}
+
+	// The cumulative events are kept for all the events on SQL indexes.
+	expected := []ContentionIndexStats{
+		{TableID: 51, IndexID: 1, NumEvents: 505, TotalDuration: 505 * time.Second},
+		{TableID: 51, IndexID: 2, NumEvents: 505, TotalDuration: 505 * time.Second},
+		{TableID: 52, IndexID: 1, NumDeadlocks: 1},
+	}
+	if stats := r.IndexStats(); !reflect.DeepEqual(expected, stats) {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+
+	// A nil registry ignores the events.
+	var nilRegistry *ContentionRegistry
+	nilRegistry.Record(ContentionEvent{Key: indexKey(51, 1, 0)})
+	if events := nilRegistry.Events(); len(events) != 0 {
+		t.Errorf("expected no events, got %+v", events)
+	}
+}
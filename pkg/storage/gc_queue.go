@@ -33,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/abortspan"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -609,7 +610,22 @@ func (gcq *gcQueue) processImpl(
 		return errors.Errorf("could not find zone config for range %s: %s", repl, err)
 	}
 
-	gcKeys, info, err := RunGC(ctx, desc, snap, now, zone.GC,
+	// Keep the history protected by the protected timestamp records
+	// overlapping the range. Garbage is only collected once all of them are
+	// known, since a record missed here might be protecting it.
+	records, err := protectedts.GetRecords(ctx, gcq.store.DB())
+	if err != nil {
+		return errors.Wrapf(err, "could not read protected timestamps for range %s", repl)
+	}
+	protected := protectedts.EarliestProtectedTimestamp(records, roachpb.Span{
+		Key: desc.StartKey.AsRawKey(), EndKey: desc.EndKey.AsRawKey(),
+	})
+	policy := protectedGCPolicy(now, zone.GC, protected)
+	if policy != zone.GC {
+		log.Eventf(ctx, "protected timestamp %s extends GC TTL to %ds", protected, policy.TTLSeconds)
+	}
+
+	gcKeys, info, err := RunGC(ctx, desc, snap, now, policy,
 		func(now hlc.Timestamp, txn *roachpb.Transaction, typ roachpb.PushTxnType) {
 			pushTxn(ctx, gcq.store.DB(), now, txn, typ)
 		},
@@ -652,6 +668,26 @@ func (gcq *gcQueue) processImpl(
 	return nil
 }
 
+// protectedGCPolicy returns the GC policy which keeps the history at and
+// after the protected timestamp, if any. The GC threshold computed from the
+// policy, now - TTL, is moved below the protected timestamp by extending the
+// TTL.
+func protectedGCPolicy(
+	now hlc.Timestamp, policy config.GCPolicy, protected hlc.Timestamp,
+) config.GCPolicy {
+	if protected == (hlc.Timestamp{}) || policy.TTLSeconds <= 0 {
+		return policy
+	}
+	ttlSeconds := (now.WallTime-protected.WallTime)/1E9 + 1
+	if ttlSeconds > math.MaxInt32 {
+		ttlSeconds = math.MaxInt32
+	}
+	if ttlSeconds > int64(policy.TTLSeconds) {
+		policy.TTLSeconds = int32(ttlSeconds)
+	}
+	return policy
+}
+
 // GCInfo contains statistics and insights from a GC run.
 type GCInfo struct {
 	// Now is the timestamp used for age computations.
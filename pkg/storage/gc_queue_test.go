@@ -25,10 +25,13 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -536,6 +539,132 @@ func TestGCQueueProcess(t *testing.T) {
 	}
 }
 
+// TestGCQueueProtectedTimestamp verifies that the GC queue keeps the history
+// protected by a protected timestamp record.
+func TestGCQueueProtectedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+	tc.Start(t, stopper)
+
+	tc.manualClock.Increment(48 * 60 * 60 * 1E9) // 2d past the epoch
+	now := tc.Clock().Now().WallTime
+
+	ts1 := makeTS(now-2*24*60*60*1E9+1, 0) // 2d old
+	ts2 := makeTS(now-25*60*60*1E9, 0)     // 25h old, past the GC TTL
+	ts3 := makeTS(now-1E9, 0)              // 1s old
+	protectedKey := roachpb.Key("a")
+	key := roachpb.Key("b")
+	for _, k := range []roachpb.Key{protectedKey, key} {
+		for _, ts := range []hlc.Timestamp{ts1, ts2, ts3} {
+			pArgs := putArgs(k, []byte("value"))
+			if _, err := tc.SendWrappedWith(roachpb.Header{Timestamp: ts}, &pArgs); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Protect the history of the first key after ts1, which needs the value
+	// written at ts1.
+	ctx := context.Background()
+	record := &protectedts.Record{
+		ID:        uuid.MakeV4(),
+		Timestamp: ts1.Add(60*60*1E9, 0),
+		Spans:     []roachpb.Span{{Key: protectedKey, EndKey: protectedKey.Next()}},
+	}
+	if err := tc.store.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		return protectedts.Protect(ctx, txn, record)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, ok := tc.gossip.GetSystemConfig()
+	if !ok {
+		t.Fatal("config not set")
+	}
+	gcQ := newGCQueue(tc.store, tc.gossip)
+	if err := gcQ.processImpl(ctx, tc.repl, cfg, tc.Clock().Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The records overlap the range, so its garbage is only collected up to
+	// the protected timestamp: no version of either key is collected.
+	kvs, err := engine.Scan(tc.store.Engine(), engine.MakeMVCCMetadataKey(protectedKey),
+		engine.MakeMVCCMetadataKey(keys.MaxKey), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expKVs := []engine.MVCCKey{
+		{Key: protectedKey, Timestamp: ts3},
+		{Key: protectedKey, Timestamp: ts2},
+		{Key: protectedKey, Timestamp: ts1},
+		{Key: key, Timestamp: ts3},
+		{Key: key, Timestamp: ts2},
+		{Key: key, Timestamp: ts1},
+	}
+	if len(kvs) != len(expKVs) {
+		t.Fatalf("expected %d keys; got %d", len(expKVs), len(kvs))
+	}
+	for i, kv := range kvs {
+		if !kv.Key.Equal(expKVs[i]) {
+			t.Errorf("%d: expected %s; got %s", i, expKVs[i], kv.Key)
+		}
+	}
+
+	// Once the record is released, the garbage is collected.
+	if err := tc.store.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		return protectedts.Release(ctx, txn, record.ID)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := gcQ.processImpl(ctx, tc.repl, cfg, tc.Clock().Now()); err != nil {
+		t.Fatal(err)
+	}
+	kvs, err = engine.Scan(tc.store.Engine(), engine.MakeMVCCMetadataKey(protectedKey),
+		engine.MakeMVCCMetadataKey(keys.MaxKey), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 4 {
+		t.Fatalf("expected 4 keys after releasing the record; got %d", len(kvs))
+	}
+}
+
+func TestProtectedGCPolicy(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	now := makeTS(100*1E9, 0)
+	policy := config.GCPolicy{TTLSeconds: 50}
+	testCases := []struct {
+		protected hlc.Timestamp
+		policy    config.GCPolicy
+		expTTL    int32
+	}{
+		// No protected timestamp.
+		{hlc.Timestamp{}, policy, 50},
+		// The protected timestamp is after the threshold.
+		{makeTS(60*1E9, 0), policy, 50},
+		// The protected timestamp is before the threshold.
+		{makeTS(20*1E9, 0), policy, 81},
+		{makeTS(20*1E9+1, 0), policy, 80},
+		{makeTS(20*1E9-1, 0), policy, 81},
+		// Garbage isn't collected at all.
+		{makeTS(20*1E9, 0), config.GCPolicy{}, 0},
+	}
+	for i, c := range testCases {
+		p := protectedGCPolicy(now, c.policy, c.protected)
+		if p.TTLSeconds != c.expTTL {
+			t.Errorf("%d: expected TTL %d; got %d", i, c.expTTL, p.TTLSeconds)
+		}
+		if c.protected != (hlc.Timestamp{}) && c.expTTL > 0 {
+			if threshold := engine.MakeGarbageCollector(now, p).Threshold; !threshold.Less(c.protected) {
+				t.Errorf("%d: threshold %s is not before the protected timestamp %s",
+					i, threshold, c.protected)
+			}
+		}
+	}
+}
+
 func TestGCQueueTransactionTable(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -104,6 +104,11 @@ var (
 		Name: "leases.epoch",
 		Help: "Number of replica leaseholders using epoch-based leases"}
 
+	// Follower read metrics.
+	metaFollowerReadsCount = metric.Metadata{
+		Name: "follower_reads.success",
+		Help: "Number of reads served by replicas which don't hold the lease"}
+
 	// Storage metrics.
 	metaLiveBytes = metric.Metadata{
 		Name: "livebytes",
@@ -503,6 +508,9 @@ type StoreMetrics struct {
 	LeaseExpirationCount      *metric.Gauge
 	LeaseEpochCount           *metric.Gauge
 
+	// Follower read metrics.
+	FollowerReadsCount *metric.Counter
+
 	// Storage metrics.
 	LiveBytes       *metric.Gauge
 	KeyBytes        *metric.Gauge
@@ -694,6 +702,9 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		LeaseExpirationCount:      metric.NewGauge(metaLeaseExpirationCount),
 		LeaseEpochCount:           metric.NewGauge(metaLeaseEpochCount),
 
+		// Follower read metrics.
+		FollowerReadsCount: metric.NewCounter(metaFollowerReadsCount),
+
 		// Storage metrics.
 		LiveBytes:       metric.NewGauge(metaLiveBytes),
 		KeyBytes:        metric.NewGauge(metaKeyBytes),
@@ -0,0 +1,149 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package protectedts lets long-running operations, such as backups and
+// changefeeds, protect the MVCC history they need from garbage collection,
+// without raising the GC TTL of the zones they read.
+//
+// A protected timestamp record protects the history of a set of spans at and
+// after its timestamp. The records are stored in the system keyspace. Before
+// collecting the garbage of a range, the GC queue reads the records and keeps
+// the history protected by those overlapping the range, as if the GC TTL of
+// the range were shorter. A record only protects the history that has not
+// been collected yet when it is written, so its timestamp must be within the
+// GC window of the spans it protects.
+//
+// The owner of a record must release it once the history is no longer
+// needed, otherwise the garbage of the spans accumulates.
+package protectedts
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/uint128"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// MetaTypeJob is the MetaType of the records owned by jobs. Their Meta is the
+// ID of the job, encoded by EncodeJobMeta.
+const MetaTypeJob = "jobs"
+
+// ErrNotFound is returned when a record doesn't exist.
+var ErrNotFound = errors.New("protected timestamp record not found")
+
+// Protect writes the record r in txn, replacing the record with the same ID
+// if there is one.
+func Protect(ctx context.Context, txn *client.Txn, r *Record) error {
+	if r.Timestamp == (hlc.Timestamp{}) {
+		return errors.New("cannot protect a zero timestamp")
+	}
+	if len(r.Spans) == 0 {
+		return errors.New("cannot protect an empty set of spans")
+	}
+	return txn.Put(ctx, keys.ProtectedTimestampKey(r.ID), r)
+}
+
+// Release removes the record with the given ID in txn.
+func Release(ctx context.Context, txn *client.Txn, id uuid.UUID) error {
+	key := keys.ProtectedTimestampKey(id)
+	kv, err := txn.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !kv.Exists() {
+		return ErrNotFound
+	}
+	return txn.Del(ctx, key)
+}
+
+// GetRecord reads the record with the given ID in txn.
+func GetRecord(ctx context.Context, txn *client.Txn, id uuid.UUID) (*Record, error) {
+	var r Record
+	kv, err := txn.Get(ctx, keys.ProtectedTimestampKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if !kv.Exists() {
+		return nil, ErrNotFound
+	}
+	if err := kv.ValueProto(&r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetRecords reads all the records.
+func GetRecords(ctx context.Context, db *client.DB) ([]Record, error) {
+	kvs, err := db.Scan(ctx, keys.ProtectedTimestampPrefix, keys.ProtectedTimestampKeyMax, 0)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, len(kvs))
+	for i, kv := range kvs {
+		if err := kv.ValueProto(&records[i]); err != nil {
+			return nil, errors.Wrapf(err, "decoding protected timestamp record %s", kv.Key)
+		}
+	}
+	return records, nil
+}
+
+// EarliestProtectedTimestamp returns the earliest timestamp protected in span
+// by the given records, or the zero timestamp if none of them overlaps span.
+func EarliestProtectedTimestamp(records []Record, span roachpb.Span) hlc.Timestamp {
+	var earliest hlc.Timestamp
+	for _, r := range records {
+		if earliest != (hlc.Timestamp{}) && !r.Timestamp.Less(earliest) {
+			continue
+		}
+		for _, s := range r.Spans {
+			if s.Overlaps(span) {
+				earliest = r.Timestamp
+				break
+			}
+		}
+	}
+	return earliest
+}
+
+// JobRecordID returns the ID of the record of a job. A job protects a single
+// timestamp at a time, which it moves by replacing its record.
+func JobRecordID(jobID int64) uuid.UUID {
+	return uuid.FromUint128(uint128.FromInts(0, uint64(jobID)))
+}
+
+// EncodeJobMeta returns the Meta of the record of a job.
+func EncodeJobMeta(jobID int64) []byte {
+	meta := make([]byte, 8)
+	binary.BigEndian.PutUint64(meta, uint64(jobID))
+	return meta
+}
+
+// MakeJobRecord returns the record of a job protecting the given spans at and
+// after ts.
+func MakeJobRecord(jobID int64, ts hlc.Timestamp, spans []roachpb.Span) *Record {
+	return &Record{
+		ID:        JobRecordID(jobID),
+		Timestamp: ts,
+		MetaType:  MetaTypeJob,
+		Meta:      EncodeJobMeta(jobID),
+		Spans:     spans,
+	}
+}
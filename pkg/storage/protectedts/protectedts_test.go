@@ -0,0 +1,127 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package protectedts_test
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestProtectedTimestampRecords(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	s, _, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	span := func(start, end string) roachpb.Span {
+		return roachpb.Span{Key: roachpb.Key(start), EndKey: roachpb.Key(end)}
+	}
+	protect := func(r *protectedts.Record) error {
+		return kvDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return protectedts.Protect(ctx, txn, r)
+		})
+	}
+	r1 := protectedts.MakeJobRecord(1, hlc.Timestamp{WallTime: 10}, []roachpb.Span{span("a", "c")})
+	r2 := protectedts.MakeJobRecord(2, hlc.Timestamp{WallTime: 20}, []roachpb.Span{span("b", "d"), span("x", "z")})
+	for _, r := range []*protectedts.Record{r1, r2} {
+		if err := protect(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := protect(protectedts.MakeJobRecord(3, hlc.Timestamp{}, r1.Spans)); !testutils.IsError(
+		err, "cannot protect a zero timestamp",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := protect(protectedts.MakeJobRecord(3, r1.Timestamp, nil)); !testutils.IsError(
+		err, "cannot protect an empty set of spans",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := kvDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		r, err := protectedts.GetRecord(ctx, txn, r2.ID)
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(r, r2) {
+			t.Errorf("expected %+v; got %+v", r2, r)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	earliest := func(sp roachpb.Span) hlc.Timestamp {
+		records, err := protectedts.GetRecords(ctx, kvDB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return protectedts.EarliestProtectedTimestamp(records, sp)
+	}
+	for _, c := range []struct {
+		span roachpb.Span
+		exp  hlc.Timestamp
+	}{
+		{span("a", "b"), r1.Timestamp},
+		{span("a", "z"), r1.Timestamp},
+		{span("c", "d"), r2.Timestamp},
+		{span("y", "zz"), r2.Timestamp},
+		{span("d", "x"), hlc.Timestamp{}},
+	} {
+		if ts := earliest(c.span); ts != c.exp {
+			t.Errorf("%s: expected %s; got %s", c.span, c.exp, ts)
+		}
+	}
+
+	// Moving the timestamp of a record replaces it.
+	r1.Timestamp = hlc.Timestamp{WallTime: 30}
+	if err := protect(r1); err != nil {
+		t.Fatal(err)
+	}
+	if ts := earliest(span("a", "z")); ts != r2.Timestamp {
+		t.Errorf("expected %s; got %s", r2.Timestamp, ts)
+	}
+
+	release := func(r *protectedts.Record) error {
+		return kvDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return protectedts.Release(ctx, txn, r.ID)
+		})
+	}
+	if err := release(r2); err != nil {
+		t.Fatal(err)
+	}
+	if err := release(r2); err != protectedts.ErrNotFound {
+		t.Fatalf("expected %v; got %v", protectedts.ErrNotFound, err)
+	}
+	if ts := earliest(span("a", "z")); ts != r1.Timestamp {
+		t.Errorf("expected %s; got %s", r1.Timestamp, ts)
+	}
+	if ts := earliest(span("x", "z")); ts != (hlc.Timestamp{}) {
+		t.Errorf("expected no protected timestamp; got %s", ts)
+	}
+}
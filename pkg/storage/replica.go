@@ -340,6 +340,12 @@ type Replica struct {
 		state storagebase.ReplicaState
 		// Counter used for assigning lease indexes for proposals.
 		lastAssignedLeaseIndex uint64
+		// The highest closed timestamp of the applied commands, at or below
+		// which the replica can serve reads without the lease.
+		closedTimestamp hlc.Timestamp
+		// Tracks the writes evaluated by the lease holder, to close the
+		// timestamps attached to its proposals.
+		closedTimestampTracker closedTimestampTracker
 		// Last index/term persisted to the raft log (not necessarily
 		// committed). Note that lastTerm may be 0 (and thus invalid) even when
 		// lastIndex is known, in which case the term will have to be retrieved
@@ -1551,6 +1557,11 @@ func (r *Replica) NodeID() roachpb.NodeID {
 	return r.store.nodeDesc.NodeID
 }
 
+// GetNodeLocality returns the locality of the node this replica belongs to.
+func (r *Replica) GetNodeLocality() roachpb.Locality {
+	return r.store.nodeDesc.Locality
+}
+
 // setDesc atomically sets the range's descriptor. This method calls
 // processRangeDescriptorUpdate() to make the Store handle the descriptor
 // update. Requires raftMu to be locked.
@@ -2557,10 +2568,14 @@ func (r *Replica) executeAdminBatch(
 func (r *Replica) executeReadOnlyBatch(
 	ctx context.Context, ba roachpb.BatchRequest,
 ) (br *roachpb.BatchResponse, pErr *roachpb.Error) {
-	// If the read is consistent, the read requires the range lease.
+	// If the read is consistent, the read requires the range lease, unless
+	// it is old enough to be served by any replica.
 	if ba.ReadConsistency != roachpb.INCONSISTENT {
 		if _, pErr = r.redirectOnOrAcquireLease(ctx); pErr != nil {
-			return nil, pErr
+			if !r.canServeFollowerRead(ctx, &ba, pErr) {
+				return nil, pErr
+			}
+			pErr = nil
 		}
 	}
 
@@ -2767,9 +2782,22 @@ func (r *Replica) tryExecuteWriteBatch(
 	// commands which require this command to move its timestamp
 	// forward. Or, in the case of a transactional write, the txn
 	// timestamp and possible write-too-old bool.
-	if bumped, pErr := r.applyTimestampCache(ctx, &ba); pErr != nil {
+	bumped, pErr := r.applyTimestampCache(ctx, &ba)
+	if pErr != nil {
 		return nil, pErr, proposalNoRetry
-	} else if bumped {
+	}
+	// Forward the timestamp of the command above the closed timestamps, which
+	// the other replicas may already be serving reads at.
+	// The command is released once proposed, which lets the lease holder
+	// close its timestamp.
+	releaseClosedTimestamp := func() {}
+	if !ba.IsLeaseRequest() {
+		var closedBumped bool
+		releaseClosedTimestamp, closedBumped = r.trackWriteForClosedTimestamp(&ba)
+		defer releaseClosedTimestamp()
+		bumped = bumped || closedBumped
+	}
+	if bumped {
 		// If we bump the transaction's timestamp, we must absolutely
 		// tell the client in a response transaction (for otherwise it
 		// doesn't know about the incremented timestamp). Response
@@ -2791,6 +2819,7 @@ func (r *Replica) tryExecuteWriteBatch(
 	log.Event(ctx, "applied timestamp cache")
 
 	ch, tryAbandon, undoQuotaAcquisition, pErr := r.propose(ctx, lease, ba, endCmds, spans)
+	releaseClosedTimestamp()
 	defer func() {
 		// NB: We may be double free-ing here, consider the following cases:
 		//  - The request was evaluated and the command resulted in an error, but a
@@ -2984,6 +3013,9 @@ func (r *Replica) insertProposalLocked(
 	proposal.command.MaxLeaseIndex = r.mu.lastAssignedLeaseIndex
 	proposal.command.ProposerReplica = proposerReplica
 	proposal.command.ProposerLease = proposerLease
+	if !proposal.Request.IsLeaseRequest() {
+		proposal.command.ClosedTimestamp = r.closeTimestampLocked()
+	}
 	if log.V(4) {
 		log.Infof(proposal.ctx, "submitting proposal %x: maxLeaseIndex=%d",
 			proposal.idKey, proposal.command.MaxLeaseIndex)
@@ -4728,6 +4760,19 @@ func (r *Replica) processRaftCommand(
 		// before notifying a potentially waiting client.
 		r.handleEvalResultRaftMuLocked(ctx, lResult,
 			raftCmd.ReplicatedEvalResult, raftIndex, leaseIndex)
+
+		// The commands which applied carry the closed timestamp of their
+		// proposer. A merge brings in the writes of the subsumed range, whose
+		// timestamps may be anywhere, so it resets the closed timestamp.
+		if forcedErr == nil {
+			r.mu.Lock()
+			if raftCmd.ReplicatedEvalResult.Merge != nil {
+				r.mu.closedTimestamp = hlc.Timestamp{}
+			} else {
+				r.mu.closedTimestamp.Forward(raftCmd.ClosedTimestamp)
+			}
+			r.mu.Unlock()
+		}
 	}
 
 	if proposedLocally {
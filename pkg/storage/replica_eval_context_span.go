@@ -81,6 +81,11 @@ func (rec *SpanSetReplicaEvalContext) NodeID() roachpb.NodeID {
 	return rec.i.NodeID()
 }
 
+// GetNodeLocality returns the node locality.
+func (rec *SpanSetReplicaEvalContext) GetNodeLocality() roachpb.Locality {
+	return rec.i.GetNodeLocality()
+}
+
 // Tracer returns the tracer.
 func (rec *SpanSetReplicaEvalContext) Tracer() opentracing.Tracer {
 	return rec.i.Tracer()
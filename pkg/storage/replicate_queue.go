@@ -194,11 +194,17 @@ func (rq *replicateQueue) shouldQueue(
 
 	// If the lease is valid, check to see if we should transfer it.
 	if lease, _ := repl.GetLease(); repl.IsLeaseValid(lease, now) {
-		if rq.canTransferLease() &&
-			rq.allocator.ShouldTransferLease(
+		if rq.canTransferLease() {
+			preferred := rq.allocator.preferredLeaseholders(zone.LeasePreferences, desc.Replicas)
+			if len(preferred) > 0 && !containsStore(preferred, lease.Replica.StoreID) {
+				log.VEventf(ctx, 2, "lease holder does not satisfy the lease preferences, enqueuing")
+				return true, 0
+			}
+			if rq.allocator.ShouldTransferLease(
 				ctx, zone.Constraints, desc.Replicas, lease.Replica.StoreID, desc.RangeID, repl.leaseholderStats) {
-			log.VEventf(ctx, 2, "lease transfer needed, enqueuing")
-			return true, 0
+				log.VEventf(ctx, 2, "lease transfer needed, enqueuing")
+				return true, 0
+			}
 		}
 	}
 
@@ -536,6 +542,20 @@ func (rq *replicateQueue) transferLease(
 	opts transferLeaseOptions,
 ) (bool, error) {
 	candidates := filterBehindReplicas(repl.RaftStatus(), desc.Replicas, 0 /* brandNewReplicaID */)
+	if preferred := rq.allocator.preferredLeaseholders(zone.LeasePreferences, candidates); len(preferred) > 0 {
+		if !containsStore(preferred, repl.store.StoreID()) {
+			// The lease holder doesn't satisfy the lease preferences, so the lease
+			// is moved to a replica that does regardless of the lease counts.
+			candidates = preferred
+			opts.checkTransferLeaseSource = false
+			opts.checkCandidateFullness = false
+		} else if opts.checkTransferLeaseSource || len(preferred) > 1 {
+			// Otherwise, the lease only moves between the replicas that satisfy
+			// them, unless it must leave the lease holder and no other replica
+			// does.
+			candidates = preferred
+		}
+	}
 	if target := rq.allocator.TransferLeaseTarget(
 		ctx,
 		zone.Constraints,
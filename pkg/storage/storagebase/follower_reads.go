@@ -0,0 +1,65 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storagebase
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// ClosedTimestampTargetDuration is how far behind the present the lease
+// holders close timestamps: they stop writing at or below the closed
+// timestamps, which lets the other replicas serve the reads at or below them.
+var ClosedTimestampTargetDuration = settings.RegisterNonNegativeDurationSetting(
+	"kv.closed_timestamp.target_duration",
+	"if nonzero, how far behind the present the lease holders stop accepting writes, "+
+		"allowing the other replicas to serve the reads of older timestamps",
+	30*time.Second,
+)
+
+// FollowerReadsEnabled controls whether the replicas which don't hold the
+// lease serve the reads at or below their closed timestamp.
+var FollowerReadsEnabled = settings.RegisterBoolSetting(
+	"kv.follower_reads.enabled",
+	"allow the reads of timestamps old enough to be served by the closest replica "+
+		"instead of the lease holder",
+	true,
+)
+
+// followerReadLag is the multiple of the closed timestamp target duration
+// behind the present at which reads are expected to be servable by any
+// replica: the lease holders close timestamps when they propose commands, so
+// the closed timestamps lag a bit more than their target behind the present.
+const followerReadLag = 1.5
+
+// FollowerReadTimestamp returns the timestamp at which reads are expected to
+// be servable by any replica, or a zero timestamp if follower reads are
+// disabled.
+func FollowerReadTimestamp(sv *settings.Values, now hlc.Timestamp) hlc.Timestamp {
+	target := ClosedTimestampTargetDuration.Get(sv)
+	if !FollowerReadsEnabled.Get(sv) || target == 0 {
+		return hlc.Timestamp{}
+	}
+	return hlc.Timestamp{WallTime: now.WallTime - int64(followerReadLag*float64(target))}
+}
+
+// CanUseFollowerRead returns whether a read at the given timestamp is
+// expected to be servable by any replica.
+func CanUseFollowerRead(sv *settings.Values, now, ts hlc.Timestamp) bool {
+	followerTS := FollowerReadTimestamp(sv, now)
+	return followerTS != (hlc.Timestamp{}) && !followerTS.Less(ts)
+}
@@ -618,6 +618,11 @@ type StoreConfig struct {
 	// which is non-zero.
 	IntentResolverTaskLimit int
 
+	// ContentionEvents records the requests which have to wait for
+	// conflicting transactions. It is shared by the stores of a node, and may
+	// be nil.
+	ContentionEvents *ContentionRegistry
+
 	TestingKnobs StoreTestingKnobs
 
 	// concurrentSnapshotApplyLimit specifies the maximum number of empty
@@ -2625,7 +2630,11 @@ func (s *Store) Send(
 					clonedTxn := h.Txn.Clone()
 					h.Txn = &clonedTxn
 				}
-				if pErr = s.intentResolver.processWriteIntentError(ctx, pErr, args, h, pushType); pErr != nil {
+				wiErr := pErr.GetDetail().(*roachpb.WriteIntentError)
+				waitStart := timeutil.Now()
+				pErr = s.intentResolver.processWriteIntentError(ctx, pErr, args, h, pushType)
+				s.recordIntentContention(h.Txn, wiErr.Intents, waitStart)
+				if pErr != nil {
 					// Do not propagate ambiguous results; assume success and retry original op.
 					if _, ok := pErr.GetDetail().(*roachpb.AmbiguousResultError); !ok {
 						// Preserve the error index.
@@ -2648,6 +2657,22 @@ func (s *Store) Send(
 	}
 }
 
+// recordIntentContention records the wait of a request, which started at
+// the given time, for the transactions holding the given intents.
+func (s *Store) recordIntentContention(
+	txn *roachpb.Transaction, intents []roachpb.Intent, start time.Time,
+) {
+	ev := ContentionEvent{Start: start, Duration: timeutil.Since(start)}
+	if txn != nil {
+		ev.WaitingTxnID = txn.ID
+	}
+	for _, intent := range intents {
+		ev.Key = intent.Key
+		ev.BlockingTxnID = intent.Txn.ID
+		s.cfg.ContentionEvents.Record(ev)
+	}
+}
+
 // maybeWaitForPushee potentially diverts the incoming request to
 // the txnwait.Queue, where it will wait for updates to the target
 // transaction.
@@ -2659,6 +2684,7 @@ func (s *Store) maybeWaitForPushee(
 	// txn response or else allow this request to proceed.
 	if ba.IsSinglePushTxnRequest() {
 		pushReq := ba.Requests[0].GetInner().(*roachpb.PushTxnRequest)
+		waitStart := timeutil.Now()
 		pushResp, pErr := repl.txnWaitQueue.MaybeWaitForPush(repl.AnnotateCtx(ctx), repl, pushReq)
 		// Copy the request in anticipation of setting the force arg and
 		// updating the Now timestamp (see below).
@@ -2666,6 +2692,14 @@ func (s *Store) maybeWaitForPushee(
 		if pErr == txnwait.ErrDeadlock {
 			// We've experienced a deadlock; set Force=true on push request.
 			pushReqCopy.Force = true
+			s.cfg.ContentionEvents.Record(ContentionEvent{
+				Key:           pushReq.PusheeTxn.Key,
+				WaitingTxnID:  pushReq.PusherTxn.ID,
+				BlockingTxnID: pushReq.PusheeTxn.ID,
+				Start:         waitStart,
+				Duration:      timeutil.Since(waitStart),
+				Deadlock:      true,
+			})
 		} else if pErr != nil {
 			return nil, pErr
 		} else if pushResp != nil {
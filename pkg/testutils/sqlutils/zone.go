@@ -26,7 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 )
 
-// ZoneRow represents a row returned by EXPERIMENTAL SHOW ZONE CONFIGURATION.
+// ZoneRow represents a row returned by SHOW ZONE CONFIGURATION.
 type ZoneRow struct {
 	ID           uint32
 	CLISpecifier string
@@ -53,7 +53,7 @@ func (row ZoneRow) sqlRowString() ([]string, error) {
 // DeleteZoneConfig deletes the specified zone config through the SQL interface.
 func DeleteZoneConfig(t testing.TB, sqlDB *SQLRunner, target string) {
 	t.Helper()
-	sqlDB.Exec(t, fmt.Sprintf("ALTER %s EXPERIMENTAL CONFIGURE ZONE NULL", target))
+	sqlDB.Exec(t, fmt.Sprintf("ALTER %s CONFIGURE ZONE DISCARD", target))
 }
 
 // SetZoneConfig updates the specified zone config through the SQL interface.
@@ -82,7 +82,7 @@ func VerifyZoneConfigForTarget(t testing.TB, sqlDB *SQLRunner, target string, ro
 	if err != nil {
 		t.Fatal(err)
 	}
-	sqlDB.CheckQueryResults(t, fmt.Sprintf("EXPERIMENTAL SHOW ZONE CONFIGURATION FOR %s", target),
+	sqlDB.CheckQueryResults(t, fmt.Sprintf("SHOW ZONE CONFIGURATION FOR %s", target),
 		[][]string{sqlRow})
 }
 
@@ -0,0 +1,214 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package cron parses crontab expressions and computes the times at which
+// they are due.
+//
+// An expression has five fields, separated by spaces: the minute (0-59), the
+// hour (0-23), the day of the month (1-31), the month (1-12 or JAN-DEC) and
+// the day of the week (0-7 or SUN-SAT, both 0 and 7 being Sunday). A field
+// is a comma-separated list of values, of ranges of values such as 1-5, or
+// of *, each optionally followed by a step such as */15. When both the day of
+// the month and the day of the week are restricted, a day matches if either
+// matches. The expressions @yearly (or @annually), @monthly, @weekly, @daily
+// (or @midnight) and @hourly are shorthands for the usual expressions.
+//
+// All times are in UTC.
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schedule is a parsed crontab expression.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are set when the day of the month and the day of
+	// the week are unrestricted.
+	domStar, dowStar bool
+}
+
+type bounds struct {
+	name     string
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	minuteBounds = bounds{name: "minute", min: 0, max: 59}
+	hourBounds   = bounds{name: "hour", min: 0, max: 23}
+	domBounds    = bounds{name: "day of month", min: 1, max: 31}
+	monthBounds  = bounds{name: "month", min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Sunday is both 0 and 7.
+	dowBounds = bounds{name: "day of week", min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a crontab expression.
+func Parse(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if strings.HasPrefix(spec, "@") {
+		var ok bool
+		if spec, ok = macros[strings.ToLower(spec)]; !ok {
+			return nil, errors.Errorf("invalid crontab expression %q: unknown shorthand", expr)
+		}
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Errorf(
+			"invalid crontab expression %q: expected 5 fields, found %d", expr, len(fields))
+	}
+
+	var s Schedule
+	var err error
+	if s.minute, _, err = parseField(fields[0], minuteBounds); err != nil {
+		return nil, errors.Wrapf(err, "invalid crontab expression %q", expr)
+	}
+	if s.hour, _, err = parseField(fields[1], hourBounds); err != nil {
+		return nil, errors.Wrapf(err, "invalid crontab expression %q", expr)
+	}
+	if s.dom, s.domStar, err = parseField(fields[2], domBounds); err != nil {
+		return nil, errors.Wrapf(err, "invalid crontab expression %q", expr)
+	}
+	if s.month, _, err = parseField(fields[3], monthBounds); err != nil {
+		return nil, errors.Wrapf(err, "invalid crontab expression %q", expr)
+	}
+	if s.dow, s.dowStar, err = parseField(fields[4], dowBounds); err != nil {
+		return nil, errors.Wrapf(err, "invalid crontab expression %q", expr)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1 << 0
+	}
+	return &s, nil
+}
+
+// parseField returns the set of the values of a field, and whether the field
+// is an unrestricted *.
+func parseField(field string, b bounds) (uint64, bool, error) {
+	var set uint64
+	for _, item := range strings.Split(field, ",") {
+		rng, step := item, uint(1)
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			rng = item[:i]
+			s, err := strconv.ParseUint(item[i+1:], 10, 8)
+			if err != nil || s == 0 {
+				return 0, false, errors.Errorf("invalid step in %s %q", b.name, item)
+			}
+			step = uint(s)
+		}
+
+		var lo, hi uint
+		switch {
+		case rng == "*":
+			lo, hi = b.min, b.max
+		case strings.IndexByte(rng, '-') > 0:
+			i := strings.IndexByte(rng, '-')
+			var err error
+			if lo, err = parseValue(rng[:i], b); err != nil {
+				return 0, false, err
+			}
+			if hi, err = parseValue(rng[i+1:], b); err != nil {
+				return 0, false, err
+			}
+			if lo > hi {
+				return 0, false, errors.Errorf("invalid range in %s %q", b.name, item)
+			}
+		default:
+			var err error
+			if lo, err = parseValue(rng, b); err != nil {
+				return 0, false, err
+			}
+			hi = lo
+			if step > 1 {
+				// N/S means every S starting at N.
+				hi = b.max
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, field == "*", nil
+}
+
+func parseValue(s string, b bounds) (uint, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || uint(v) < b.min || uint(v) > b.max {
+		return 0, errors.Errorf("invalid %s %q: expected a value between %d and %d",
+			b.name, s, b.min, b.max)
+	}
+	return uint(v), nil
+}
+
+// maxSearchYears bounds the search of the next time a schedule is due. It
+// covers the leap years, so only the schedules which are never due, such as
+// the 30th of February, reach it.
+const maxSearchYears = 5
+
+// Next returns the first time after t at which the schedule is due, or the
+// zero time if it is never due.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
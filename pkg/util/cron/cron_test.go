@@ -0,0 +1,90 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		expr string
+		err  string
+	}{
+		{"", "expected 5 fields, found 0"},
+		{"* * * *", "expected 5 fields, found 4"},
+		{"@fortnightly", "unknown shorthand"},
+		{"60 * * * *", `invalid minute "60"`},
+		{"* 24 * * *", `invalid hour "24"`},
+		{"* * 0 * *", `invalid day of month "0"`},
+		{"* * * 13 *", `invalid month "13"`},
+		{"* * * * 8", `invalid day of week "8"`},
+		{"* * * foo *", `invalid month "foo"`},
+		{"*/0 * * * *", `invalid step in minute "\*/0"`},
+		{"5-1 * * * *", `invalid range in minute "5-1"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := Parse(tc.expr)
+			if !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2018-03-14 is a Wednesday.
+	from := time.Date(2018, 3, 14, 10, 17, 30, 0, time.UTC)
+	testCases := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2018, 3, 14, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2018, 3, 14, 10, 30, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2018, 3, 14, 10, 25, 0, 0, time.UTC)},
+		{"0,17 * * * *", time.Date(2018, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2018, 3, 15, 2, 30, 0, 0, time.UTC)},
+		{"0 9-17 * * *", time.Date(2018, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2018, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * sun", time.Date(2018, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2018, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * mon-fri", time.Date(2018, 3, 15, 0, 0, 0, 0, time.UTC)},
+		// When both days are restricted, either matches.
+		{"0 0 1 * 5", time.Date(2018, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 feb *", time.Time{}},
+		{"@hourly", time.Date(2018, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2018, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2018, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			s, err := Parse(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next := s.Next(from); !next.Equal(tc.expected) {
+				t.Fatalf("expected %s, got %s", tc.expected, next)
+			}
+		})
+	}
+}
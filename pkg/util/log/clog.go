@@ -571,6 +571,7 @@ func init() {
 	// commands set their default separately in cli/flags.go
 	logging.stderrThreshold = Severity_INFO
 	logging.fileThreshold = Severity_INFO
+	logging.prefix = program
 
 	logging.setVState(0, nil, false)
 	logging.exitFunc = os.Exit
@@ -595,6 +596,11 @@ func StartGCDaemon() {
 // Flush flushes all pending log I/O.
 func Flush() {
 	logging.lockAndFlushAll()
+	secondaryLogRegistry.mu.Lock()
+	defer secondaryLogRegistry.mu.Unlock()
+	for _, l := range secondaryLogRegistry.mu.loggers {
+		l.logger.lockAndFlushAll()
+	}
 }
 
 // SetSync configures whether logging synchronizes all writes.
@@ -610,6 +616,9 @@ func SetSync(sync bool) {
 type loggingT struct {
 	noStderrRedirect bool
 
+	// prefix is the prefix of the names of the log files.
+	prefix string
+
 	// Level flag for output to stderr. Handled atomically.
 	stderrThreshold Severity
 	// Level flag for output to files.
@@ -907,7 +916,7 @@ func (sb *syncBuffer) rotateFile(now time.Time) error {
 		}
 	}
 	var err error
-	sb.file, sb.lastRotation, _, err = create(now, sb.lastRotation)
+	sb.file, sb.lastRotation, _, err = create(sb.logger.prefix, now, sb.lastRotation)
 	sb.nbytes = 0
 	if err != nil {
 		return err
@@ -916,8 +925,9 @@ func (sb *syncBuffer) rotateFile(now time.Time) error {
 	// Redirect stderr to the current INFO log file in order to capture panic
 	// stack traces that are written by the Go runtime to stderr. Note that if
 	// --logtostderr is true we'll never enter this code path and panic stack
-	// traces will go to the original stderr as you would expect.
-	if logging.stderrThreshold > Severity_INFO && !logging.noStderrRedirect {
+	// traces will go to the original stderr as you would expect. The files of
+	// the secondary loggers never capture stderr.
+	if sb.logger == &logging && logging.stderrThreshold > Severity_INFO && !logging.noStderrRedirect {
 		// NB: any concurrent output to stderr may straddle the old and new
 		// files. This doesn't apply to log messages as we won't reach this code
 		// unless we're not logging to stderr.
@@ -956,7 +966,7 @@ func (sb *syncBuffer) rotateFile(now time.Time) error {
 	}
 
 	select {
-	case logging.gcNotify <- struct{}{}:
+	case sb.logger.gcNotify <- struct{}{}:
 	default:
 	}
 	return nil
@@ -976,6 +986,10 @@ func (l *loggingT) closeFileLocked() error {
 		}
 		l.file = nil
 	}
+	if l != &logging {
+		// Only the main logger redirects stderr.
+		return nil
+	}
 	return restoreStderr()
 }
 
@@ -1056,8 +1070,16 @@ func (l *loggingT) gcOldFiles() {
 		return
 	}
 
+	// Only the files of this logger are collected.
+	var ownFiles []FileInfo
+	for _, f := range allFiles {
+		if f.Details.Program == removePeriods(l.prefix) {
+			ownFiles = append(ownFiles, f)
+		}
+	}
+
 	logFilesCombinedMaxSize := atomic.LoadInt64(&LogFilesCombinedMaxSize)
-	files := selectFiles(allFiles, math.MaxInt64)
+	files := selectFiles(ownFiles, math.MaxInt64)
 	if len(files) == 0 {
 		return
 	}
@@ -0,0 +1,74 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"os"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/caller"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// SecondaryLogger is a logging channel whose entries go to their own log
+// files, next to the files of the main logger in the log directory. Nothing
+// is logged when no log directory is set. The files of secondary loggers
+// are not garbage collected.
+type SecondaryLogger struct {
+	logger loggingT
+}
+
+var secondaryLogRegistry struct {
+	mu struct {
+		syncutil.Mutex
+		loggers []*SecondaryLogger
+	}
+}
+
+// NewSecondaryLogger creates a secondary logger whose file names start with
+// the program name followed by the given suffix, for instance
+// cockroach-sql-audit.<host>.<user>.<timestamp>.<pid>.log. If
+// forceSyncWrites is set, each entry is synced to disk before the logging
+// call returns.
+func NewSecondaryLogger(fileNameSuffix string, forceSyncWrites bool) *SecondaryLogger {
+	l := &SecondaryLogger{
+		logger: loggingT{
+			prefix:     program + "-" + fileNameSuffix,
+			syncWrites: forceSyncWrites,
+			exitFunc:   os.Exit,
+		},
+	}
+	l.logger.stderrThreshold = Severity_NONE
+	l.logger.fileThreshold = Severity_INFO
+	if !forceSyncWrites {
+		go l.logger.flushDaemon()
+	}
+
+	secondaryLogRegistry.mu.Lock()
+	defer secondaryLogRegistry.mu.Unlock()
+	secondaryLogRegistry.mu.loggers = append(secondaryLogRegistry.mu.loggers, l)
+	return l
+}
+
+// Logf logs an entry to the files of the secondary logger.
+// It extracts log tags from the context and logs them along with the given
+// message. Arguments are handled in the manner of fmt.Printf; a newline is
+// appended.
+func (l *SecondaryLogger) Logf(ctx context.Context, format string, args ...interface{}) {
+	file, line, _ := caller.Lookup(1)
+	msg := MakeMessage(ctx, format, args)
+	l.logger.outputLogEntry(Severity_INFO, file, line, msg)
+}
@@ -0,0 +1,62 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestSecondaryLog(t *testing.T) {
+	s := ScopeWithoutShowLogs(t)
+	defer s.Close(t)
+	setFlags()
+
+	l := NewSecondaryLogger("test-secondary", false /* forceSyncWrites */)
+	Infof(context.Background(), "main entry")
+	l.Logf(context.Background(), "secondary %d", 1)
+	Flush()
+
+	sb, ok := l.logger.file.(*syncBuffer)
+	if !ok {
+		t.Fatalf("buffer wasn't created")
+	}
+	name := filepath.Base(sb.file.Name())
+	if e := removePeriods(program) + "-test-secondary."; !strings.HasPrefix(name, e) {
+		t.Fatalf("expected the file name %s to start with %s", name, e)
+	}
+	contents, err := ioutil.ReadFile(sb.file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(contents), "secondary 1") {
+		t.Errorf("secondary log is missing the entry:\n%s", contents)
+	}
+	if strings.Contains(string(contents), "main entry") {
+		t.Errorf("secondary log contains an entry of the main log:\n%s", contents)
+	}
+
+	contents, err = ioutil.ReadFile(logging.file.(*syncBuffer).file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(contents), "secondary 1") {
+		t.Errorf("main log contains an entry of the secondary log:\n%s", contents)
+	}
+}
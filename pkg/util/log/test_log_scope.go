@@ -166,7 +166,20 @@ func dirTestOverride(expected, newDir string) error {
 	// When we change the directory we close the current logging
 	// output, so that a rotation to the new directory is forced on
 	// the next logging event.
-	return logging.closeFileLocked()
+	if err := logging.closeFileLocked(); err != nil {
+		return err
+	}
+	secondaryLogRegistry.mu.Lock()
+	defer secondaryLogRegistry.mu.Unlock()
+	for _, l := range secondaryLogRegistry.mu.loggers {
+		l.logger.mu.Lock()
+		err := l.logger.closeFileLocked()
+		l.logger.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func isDirEmpty(dirname string) (bool, error) {
@@ -252,11 +252,23 @@ func (h *Histogram) GetType() *prometheusgo.MetricType {
 
 // ToPrometheusMetric returns a filled-in prometheus metric of the right type.
 func (h *Histogram) ToPrometheusMetric() *prometheusgo.Metric {
-	hist := &prometheusgo.Histogram{}
-
 	h.mu.Lock()
 	maybeTick(h.mu.sliding)
-	bars := h.mu.cumulative.Distribution()
+	hist := ToPrometheusHistogram(h.mu.cumulative, 1)
+	h.mu.Unlock()
+
+	return &prometheusgo.Metric{
+		Histogram: hist,
+	}
+}
+
+// ToPrometheusHistogram returns the prometheus representation of an HDR
+// histogram whose values are multiplied by scale, e.g. to convert them to the
+// unit of the other metrics.
+func ToPrometheusHistogram(h *hdrhistogram.Histogram, scale int64) *prometheusgo.Histogram {
+	hist := &prometheusgo.Histogram{}
+
+	bars := h.Distribution()
 	hist.Bucket = make([]*prometheusgo.Bucket, 0, len(bars))
 
 	var cumCount uint64
@@ -266,7 +278,7 @@ func (h *Histogram) ToPrometheusMetric() *prometheusgo.Metric {
 			// No need to expose trivial buckets.
 			continue
 		}
-		upperBound := float64(bar.To)
+		upperBound := float64(bar.To * scale)
 		sum += upperBound * float64(bar.Count)
 
 		cumCount += uint64(bar.Count)
@@ -279,11 +291,7 @@ func (h *Histogram) ToPrometheusMetric() *prometheusgo.Metric {
 	}
 	hist.SampleCount = &cumCount
 	hist.SampleSum = &sum // can do better here; we approximate in the loop
-	h.mu.Unlock()
-
-	return &prometheusgo.Metric{
-		Histogram: hist,
-	}
+	return hist
 }
 
 // A Counter holds a single mutable atomic value.
@@ -82,6 +82,27 @@ func (pm *PrometheusExporter) ScrapeRegistry(registry *Registry) {
 	}
 }
 
+// PrometheusIterable is a collection of metrics that are exported to
+// prometheus but not recorded in the time series database, typically because
+// their labels take too many values.
+type PrometheusIterable interface {
+	// EachPrometheusMetric calls the given closure with each metric of the
+	// collection.
+	EachPrometheusMetric(func(PrometheusExportable))
+}
+
+// ScrapeIterable scrapes the metrics of the collection to the metric family
+// map, like ScrapeRegistry.
+func (pm *PrometheusExporter) ScrapeIterable(it PrometheusIterable) {
+	it.EachPrometheusMetric(func(prom PrometheusExportable) {
+		m := prom.ToPrometheusMetric()
+		m.Label = prom.GetLabels()
+
+		family := pm.findOrCreateFamily(prom)
+		family.Metric = append(family.Metric, m)
+	})
+}
+
 // PrintAsText writes all metrics in the families map to the io.Writer in
 // prometheus' text format. It removes individual metrics from the families
 // as it goes, readying the families for another found of registry additions.
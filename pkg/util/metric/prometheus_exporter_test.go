@@ -86,3 +86,44 @@ func TestPrometheusExporter(t *testing.T) {
 		}
 	}
 }
+
+type testIterable []PrometheusExportable
+
+func (it testIterable) EachPrometheusMetric(f func(PrometheusExportable)) {
+	for _, m := range it {
+		f(m)
+	}
+}
+
+func TestPrometheusExporterIterable(t *testing.T) {
+	c1Meta := Metadata{Name: "iterable.counter"}
+	c1Meta.AddLabel("key", "one")
+	c2Meta := Metadata{Name: "iterable.counter"}
+	c2Meta.AddLabel("key", "two")
+	c1, c2 := NewCounter(c1Meta), NewCounter(c2Meta)
+	c1.Inc(1)
+	c2.Inc(2)
+
+	pe := MakePrometheusExporter()
+	pe.ScrapeIterable(testIterable{c1, c2})
+
+	fam, ok := pe.families["iterable_counter"]
+	if !ok {
+		t.Fatal("exporter does not have the iterable_counter family")
+	}
+	if len(fam.Metric) != 2 {
+		t.Fatalf("expected 2 metrics, got %d", len(fam.Metric))
+	}
+	for i, expected := range []struct {
+		key   string
+		value float64
+	}{{"one", 1}, {"two", 2}} {
+		m := fam.Metric[i]
+		if len(m.Label) != 1 || m.Label[0].GetValue() != expected.key {
+			t.Errorf("%d: expected label key=%s, got %v", i, expected.key, m.Label)
+		}
+		if v := m.GetCounter().GetValue(); v != expected.value {
+			t.Errorf("%d: expected value %f, got %f", i, expected.value, v)
+		}
+	}
+}
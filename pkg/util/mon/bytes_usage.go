@@ -664,6 +664,22 @@ func (mm *BytesMonitor) adjustBudget(ctx context.Context) {
 	}
 }
 
+// AllocBytes returns the number of bytes currently allocated in the
+// BytesMonitor.
+func (mm *BytesMonitor) AllocBytes() int64 {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	return mm.mu.curAllocated
+}
+
+// MaximumBytes returns the high water mark of the bytes allocated in the
+// BytesMonitor since it was started.
+func (mm *BytesMonitor) MaximumBytes() int64 {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	return mm.mu.maxAllocated
+}
+
 // GetCurrentAllocationForTesting returns the number of bytes that have
 // currently been allocated in the BytesMonitor. Intended for use in testing.
 func (mm *BytesMonitor) GetCurrentAllocationForTesting() int64 {
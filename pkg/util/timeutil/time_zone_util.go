@@ -70,3 +70,59 @@ func ParseFixedOffsetTimeZone(location string) (offset int, origRepr string, suc
 	}
 	return offset, strings.TrimSuffix(strings.TrimPrefix(origRepr, "("), ")"), true
 }
+
+// ParsePOSIXTimeZone parses a POSIX-style time zone specification without
+// daylight saving time rules, "STDoffset", where STD is an optional zone
+// abbreviation (three or more letters, or any characters between angle
+// brackets) and offset is [+|-]hh[:mm[:ss]]. As in POSIX, and unlike ISO 8601,
+// positive offsets are west of Greenwich: "UTC+3" is three hours behind UTC.
+// The offset returned is in seconds east of Greenwich. The bool returned is
+// true if parsing was successful.
+func ParsePOSIXTimeZone(s string) (offset int, success bool) {
+	i := 0
+	if strings.HasPrefix(s, "<") {
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			return 0, false
+		}
+		i = end + 1
+	} else {
+		for i < len(s) && (s[i] >= 'a' && s[i] <= 'z' || s[i] >= 'A' && s[i] <= 'Z') {
+			i++
+		}
+		if i > 0 && i < 3 {
+			return 0, false
+		}
+	}
+	rest := s[i:]
+	sign := 1
+	if len(rest) > 0 && (rest[0] == '+' || rest[0] == '-') {
+		if rest[0] == '-' {
+			sign = -1
+		}
+		rest = rest[1:]
+	}
+	parts := strings.Split(rest, ":")
+	if len(parts) > 3 {
+		return 0, false
+	}
+	var fields [3]int
+	for j, p := range parts {
+		if len(p) == 0 || len(p) > 2 {
+			return 0, false
+		}
+		for k := 0; k < len(p); k++ {
+			if p[k] < '0' || p[k] > '9' {
+				return 0, false
+			}
+		}
+		fields[j], _ = strconv.Atoi(p)
+		if j > 0 && fields[j] > 59 {
+			return 0, false
+		}
+	}
+	if fields[0] > 24 {
+		return 0, false
+	}
+	return -sign * (fields[0]*3600 + fields[1]*60 + fields[2]), true
+}
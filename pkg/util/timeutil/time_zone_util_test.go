@@ -0,0 +1,52 @@
+// Copyright 2017 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timeutil
+
+import "testing"
+
+func TestParsePOSIXTimeZone(t *testing.T) {
+	testCases := []struct {
+		s       string
+		offset  int
+		success bool
+	}{
+		{"UTC+3", -3 * 3600, true},
+		{"utc-3", 3 * 3600, true},
+		{"EST5", -5 * 3600, true},
+		{"<+0530>-05:30", 5*3600 + 30*60, true},
+		{"<-03>3", -3 * 3600, true},
+		{"+8", -8 * 3600, true},
+		{"-08:00", 8 * 3600, true},
+		{"10", -10 * 3600, true},
+		{"XYZ-01:02:03", 3723, true},
+		{"", 0, false},
+		{"UTC", 0, false},
+		{"AB5", 0, false},
+		{"EST5EDT", 0, false},
+		{"EST5EDT,M3.2.0,M11.1.0", 0, false},
+		{"UTC+25", 0, false},
+		{"UTC+1:60", 0, false},
+		{"UTC+1:2:3:4", 0, false},
+		{"UTC+123", 0, false},
+		{"<+05", 0, false},
+		{"America/New_York", 0, false},
+	}
+	for _, tc := range testCases {
+		offset, success := ParsePOSIXTimeZone(tc.s)
+		if success != tc.success || offset != tc.offset {
+			t.Errorf("%q: expected (%d, %t), got (%d, %t)", tc.s, tc.offset, tc.success, offset, success)
+		}
+	}
+}
@@ -58,6 +58,16 @@ func (m *zipkinManager) Close(tr opentracing.Tracer) {
 	_ = m.collector.Close()
 }
 
+// jaegerManager manages a Zipkin tracer whose collector is a Jaeger collector,
+// which accepts spans in the Zipkin format.
+type jaegerManager struct {
+	zipkinManager
+}
+
+func (*jaegerManager) Name() string {
+	return "jaeger"
+}
+
 type shadowTracer struct {
 	opentracing.Tracer
 	manager shadowTracerManager
@@ -112,13 +122,27 @@ func createLightStepTracer(token string) (shadowTracerManager, opentracing.Trace
 }
 
 func createZipkinTracer(collectorAddr string) (shadowTracerManager, opentracing.Tracer) {
+	collector, tr := createZipkinHTTPTracer(
+		"Zipkin collector", fmt.Sprintf("http://%s/api/v1/spans", collectorAddr))
+	return &zipkinManager{collector: collector}, tr
+}
+
+func createJaegerTracer(collectorAddr string) (shadowTracerManager, opentracing.Tracer) {
+	collector, tr := createZipkinHTTPTracer(
+		"Jaeger collector", fmt.Sprintf("http://%s/api/traces?format=zipkin.thrift", collectorAddr))
+	return &jaegerManager{zipkinManager{collector: collector}}, tr
+}
+
+// createZipkinHTTPTracer creates a Zipkin tracer sending its spans to the
+// given URL.
+func createZipkinHTTPTracer(name, url string) (zipkin.Collector, opentracing.Tracer) {
 	// Create our HTTP collector.
 	collector, err := zipkin.NewHTTPCollector(
-		fmt.Sprintf("http://%s/api/v1/spans", collectorAddr),
+		url,
 		zipkin.HTTPLogger(zipkin.LoggerFunc(func(keyvals ...interface{}) error {
 			// These logs are from the collector (e.g. errors sending data, dropped
 			// traces). We can't use `log` from this package so print them to stderr.
-			toPrint := append([]interface{}{"Zipkin collector"}, keyvals...)
+			toPrint := append([]interface{}{name}, keyvals...)
 			fmt.Fprintln(os.Stderr, toPrint)
 			return nil
 		})),
@@ -135,5 +159,5 @@ func createZipkinTracer(collectorAddr string) (shadowTracerManager, opentracing.
 	if err != nil {
 		panic(err)
 	}
-	return &zipkinManager{collector: collector}, zipkinTr
+	return collector, zipkinTr
 }
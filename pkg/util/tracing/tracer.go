@@ -78,6 +78,12 @@ var zipkinCollector = settings.RegisterStringSetting(
 	envutil.EnvOrDefaultString("COCKROACH_TEST_ZIPKIN_COLLECTOR", ""),
 )
 
+var jaegerCollector = settings.RegisterStringSetting(
+	"trace.jaeger.collector",
+	"if set, traces go to the given Jaeger collector (example: '127.0.0.1:14268'); ignored if trace.lightstep.token or trace.zipkin.collector is set.",
+	envutil.EnvOrDefaultString("COCKROACH_TEST_JAEGER_COLLECTOR", ""),
+)
+
 // Tracer is our own custom implementation of opentracing.Tracer. It supports:
 //
 //  - forwarding events to x/net/trace instances
@@ -87,7 +93,8 @@ var zipkinCollector = settings.RegisterStringSetting(
 //    events can be retrieved at any time.
 //
 //  - lightstep traces. This is implemented by maintaining a "shadow" lightstep
-//    span inside each of our spans.
+//    span inside each of our spans. Zipkin and Jaeger traces work the same
+//    way.
 //
 // Even when tracing is disabled, we still use this Tracer (with x/net/trace and
 // lightstep disabled) because of its recording capability (snowball
@@ -133,6 +140,8 @@ func (t *Tracer) Configure(sv *settings.Values) {
 			t.setShadowTracer(createLightStepTracer(lsToken))
 		} else if zipkinAddr := zipkinCollector.Get(sv); zipkinAddr != "" {
 			t.setShadowTracer(createZipkinTracer(zipkinAddr))
+		} else if jaegerAddr := jaegerCollector.Get(sv); jaegerAddr != "" {
+			t.setShadowTracer(createJaegerTracer(jaegerAddr))
 		} else {
 			t.setShadowTracer(nil, nil)
 		}
@@ -148,6 +157,7 @@ func (t *Tracer) Configure(sv *settings.Values) {
 	enableNetTrace.SetOnChange(sv, reconfigure)
 	lightstepToken.SetOnChange(sv, reconfigure)
 	zipkinCollector.SetOnChange(sv, reconfigure)
+	jaegerCollector.SetOnChange(sv, reconfigure)
 }
 
 func (t *Tracer) useNetTrace() bool {
@@ -185,6 +195,12 @@ func (t *Tracer) getShadowTracer() *shadowTracer {
 	return (*shadowTracer)(atomic.LoadPointer(&t.shadowTracer))
 }
 
+// HasShadowTracer returns true if the spans are exported to a shadow tracer
+// (Lightstep, Zipkin or Jaeger).
+func (t *Tracer) HasShadowTracer() bool {
+	return t.getShadowTracer() != nil
+}
+
 type recordableOption struct{}
 
 // Recordable is a StartSpanOption that forces creation of a real span.
@@ -196,6 +212,20 @@ var Recordable opentracing.StartSpanOption = recordableOption{}
 
 func (recordableOption) Apply(*opentracing.StartSpanOptions) {}
 
+type samplingOption bool
+
+// Sampled is a StartSpanOption that exports the span to the shadow tracer, if
+// one is configured, even when its parent span isn't exported. Such a span
+// starts a new trace in the shadow tracer.
+var Sampled opentracing.StartSpanOption = samplingOption(true)
+
+// Unsampled is a StartSpanOption that prevents the span from being exported to
+// the shadow tracer, even when its parent span is. The descendants of the span
+// aren't exported either, unless they are started with the Sampled option.
+var Unsampled opentracing.StartSpanOption = samplingOption(false)
+
+func (samplingOption) Apply(*opentracing.StartSpanOptions) {}
+
 // StartSpan is part of the opentracing.Tracer interface.
 func (t *Tracer) StartSpan(
 	operationName string, opts ...opentracing.StartSpanOption,
@@ -219,10 +249,16 @@ func (t *Tracer) StartSpan(
 
 	var sso opentracing.StartSpanOptions
 	var recordable bool
+	// If sampling is set, sampled overrides the export of the span to the
+	// shadow tracer.
+	var sampling, sampled bool
 	for _, o := range opts {
 		o.Apply(&sso)
-		if _, ok := o.(recordableOption); ok {
+		switch o := o.(type) {
+		case recordableOption:
 			recordable = true
+		case samplingOption:
+			sampling, sampled = true, bool(o)
 		}
 	}
 
@@ -261,6 +297,13 @@ func (t *Tracer) StartSpan(
 		// trace when the shadow tracer changes.
 		shadowTr = parentCtx.shadowTr
 	}
+	if sampling {
+		if !sampled {
+			shadowTr = nil
+		} else if shadowTr == nil {
+			shadowTr = t.getShadowTracer()
+		}
+	}
 
 	// If tracing is disabled, the Recordable option wasn't passed, and we're not
 	// part of a recording or snowball trace, avoid overhead and return a noop
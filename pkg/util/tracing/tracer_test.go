@@ -15,6 +15,7 @@
 package tracing
 
 import (
+	"net/http"
 	"testing"
 
 	lightstep "github.com/lightstep/lightstep-tracer-go"
@@ -297,3 +298,58 @@ func TestLightstepContext(t *testing.T) {
 		}
 	}
 }
+
+type testShadowManager struct{}
+
+func (testShadowManager) Name() string {
+	return "test"
+}
+
+func (testShadowManager) Close(opentracing.Tracer) {}
+
+func TestShadowSampling(t *testing.T) {
+	tr := NewTracer()
+	tr.setShadowTracer(testShadowManager{}, opentracing.NoopTracer{})
+	if !tr.HasShadowTracer() {
+		t.Fatal("expected a shadow tracer")
+	}
+
+	exported := func(sp opentracing.Span) bool {
+		s, ok := sp.(*span)
+		return ok && s.shadowTr != nil
+	}
+
+	root := tr.StartSpan("root", Recordable)
+	if !exported(root) {
+		t.Fatal("expected the root span to be exported")
+	}
+	unsampled := tr.StartSpan("unsampled", opentracing.ChildOf(root.Context()), Recordable, Unsampled)
+	if exported(unsampled) {
+		t.Fatal("expected the unsampled span not to be exported")
+	}
+	if child := StartChildSpan("child", unsampled, false /* separateRecording */); exported(child) {
+		t.Fatal("expected the child of the unsampled span not to be exported")
+	}
+
+	// The shadow context isn't propagated for the unsampled span.
+	carrier := make(opentracing.HTTPHeadersCarrier)
+	if err := tr.Inject(unsampled.Context(), opentracing.HTTPHeaders, carrier); err != nil {
+		t.Fatal(err)
+	}
+	if http.Header(carrier).Get(fieldNameShadowType) != "" {
+		t.Fatalf("unexpected shadow context in %v", carrier)
+	}
+
+	sampled := tr.StartSpan("sampled", opentracing.ChildOf(unsampled.Context()), Sampled)
+	if !exported(sampled) {
+		t.Fatal("expected the sampled span to be exported")
+	}
+	if child := StartChildSpan("child", sampled, false /* separateRecording */); !exported(child) {
+		t.Fatal("expected the child of the sampled span to be exported")
+	}
+
+	tr.setShadowTracer(nil, nil)
+	if sampled := tr.StartSpan("sampled", Recordable, Sampled); exported(sampled) {
+		t.Fatal("expected no export without a shadow tracer")
+	}
+}